@@ -0,0 +1,443 @@
+#!/usr/bin/env node
+/**
+ * broctl - operator CLI for scripted admin operations.
+ *
+ * Talks to MongoDB directly using the same repositories as the API, so it
+ * needs the same environment (MONGODB_URI etc.) as the server.
+ *
+ * Usage: npm run broctl -- <command> [--flag value ...]
+ */
+import fs from 'fs';
+import mongoose from 'mongoose';
+import connectDB from '@/lib/database/mongodb';
+import { AdminRepository } from '@/lib/database/repositories/admin';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { StatusRepository } from '@/lib/database/repositories/status';
+import { MediaRepository } from '@/lib/database/repositories/media';
+import { Message } from '@/lib/database/models/message';
+import { MessageBucketRepository } from '@/lib/database/repositories/message-bucket';
+import { migrationRunner } from '@/lib/database/migrations';
+import { messageBucketService } from '@/lib/database/message-buckets';
+import { shardingService } from '@/lib/database/sharding';
+import { createStorageProvider, StorageProviderName } from '@/lib/media/storage';
+import { runConformanceSuite } from '@/lib/media/storage/conformance';
+import { dataResidencyService, serializeRegionMigration } from '@/lib/compliance/data-residency';
+import { DATA_REGIONS, DataRegion } from '@/lib/database/models/user';
+import { ROLE_PERMISSIONS } from '@/lib/security/permissions';
+import { DataSanitizer } from '@/lib/security/sanitization';
+import { CryptoUtils } from '@/lib/utils/crypto';
+
+type Flags = Record<string, string | boolean>;
+
+interface Command {
+  description: string;
+  usage: string;
+  run: (flags: Flags) => Promise<void>;
+}
+
+const ADMIN_ROLES = ['super_admin', 'admin', 'moderator', 'support'] as const;
+
+// Parse "--key value" and "--switch" style arguments
+function parseFlags(args: string[]): Flags {
+  const flags: Flags = {};
+
+  for (let i = 0; i < args.length; i++) {
+    const arg = args[i];
+    if (!arg.startsWith('--')) continue;
+
+    const key = arg.slice(2);
+    const next = args[i + 1];
+    if (next !== undefined && !next.startsWith('--')) {
+      flags[key] = next;
+      i++;
+    } else {
+      flags[key] = true;
+    }
+  }
+
+  return flags;
+}
+
+function requireFlag(flags: Flags, name: string): string {
+  const value = flags[name];
+  if (typeof value !== 'string' || value.length === 0) {
+    throw new Error(`Missing required flag --${name}`);
+  }
+  return value;
+}
+
+function numberFlag(flags: Flags, name: string, defaultValue: number): number {
+  const value = flags[name];
+  if (typeof value !== 'string') return defaultValue;
+
+  const parsed = parseInt(value, 10);
+  if (isNaN(parsed) || parsed < 0) {
+    throw new Error(`Flag --${name} must be a non-negative integer`);
+  }
+  return parsed;
+}
+
+const commands: Record<string, Command> = {
+  'admin:create': {
+    description: 'Create an admin account',
+    usage: '--email <email> --username <name> [--name <display>] [--role <role>] [--password <pw>]',
+    run: async (flags) => {
+      const email = requireFlag(flags, 'email').toLowerCase();
+      const username = requireFlag(flags, 'username');
+      const role = (flags.role as string) || 'admin';
+
+      if (!ADMIN_ROLES.includes(role as any)) {
+        throw new Error(`Invalid role "${role}". Expected one of: ${ADMIN_ROLES.join(', ')}`);
+      }
+
+      const adminRepository = new AdminRepository();
+      if (await adminRepository.findByEmail(email)) {
+        throw new Error(`Admin with email ${email} already exists`);
+      }
+
+      // Generate a password when none is supplied so it never lands in shell history
+      const password = typeof flags.password === 'string'
+        ? flags.password
+        : CryptoUtils.generateRandomString(20);
+
+      const admin = await adminRepository.create({
+        email,
+        username,
+        displayName: (flags.name as string) || username,
+        role: role as typeof ADMIN_ROLES[number],
+        permissions: ROLE_PERMISSIONS[role as keyof typeof ROLE_PERMISSIONS] || [],
+        isActive: true,
+      }, password);
+
+      console.log(`Created ${role} ${admin.email} (${admin._id})`);
+      if (typeof flags.password !== 'string') {
+        console.log(`Generated password: ${password}`);
+      }
+    },
+  },
+
+  'admin:reset-password': {
+    description: 'Reset an admin account password',
+    usage: '--email <email> [--password <pw>]',
+    run: async (flags) => {
+      const email = requireFlag(flags, 'email');
+      const adminRepository = new AdminRepository();
+
+      const admin = await adminRepository.findByEmail(email);
+      if (!admin) {
+        throw new Error(`Admin ${email} not found`);
+      }
+
+      const password = typeof flags.password === 'string'
+        ? flags.password
+        : CryptoUtils.generateRandomString(20);
+
+      await adminRepository.setPassword(admin._id, password);
+
+      console.log(`Password reset for ${admin.email}`);
+      if (typeof flags.password !== 'string') {
+        console.log(`Generated password: ${password}`);
+      }
+    },
+  },
+
+  'user:ban': {
+    description: 'Ban a user by phone number',
+    usage: '--phone <number> --reason <text> [--days <n>]',
+    run: async (flags) => {
+      const phoneNumber = DataSanitizer.sanitizePhoneNumber(requireFlag(flags, 'phone'));
+      const reason = requireFlag(flags, 'reason');
+      const days = numberFlag(flags, 'days', 0);
+
+      const userRepository = new UserRepository();
+      const user = await userRepository.findByPhoneNumber(phoneNumber);
+      if (!user) {
+        throw new Error(`User ${phoneNumber} not found`);
+      }
+
+      const expiresAt = days > 0 ? new Date(Date.now() + days * 24 * 60 * 60 * 1000) : undefined;
+      await userRepository.banUser(user._id, reason, expiresAt);
+
+      console.log(`Banned ${phoneNumber}${expiresAt ? ` until ${expiresAt.toISOString()}` : ''}`);
+    },
+  },
+
+  'chat:export': {
+    description: 'Export a chat and its messages as JSON',
+    usage: '--chat <chatId> [--out <file>]',
+    run: async (flags) => {
+      const chatId = requireFlag(flags, 'chat');
+      const chatRepository = new ChatRepository();
+
+      const chat = await chatRepository.findById(chatId);
+      if (!chat) {
+        throw new Error(`Chat ${chatId} not found`);
+      }
+
+      const messages: any[] = await Message.find({ chatId }).lean().exec();
+      for await (const bucket of await new MessageBucketRepository().cursorForChat(chatId)) {
+        messages.push(...bucket.messages);
+      }
+      messages.sort((a, b) => a.createdAt.getTime() - b.createdAt.getTime());
+
+      const output = JSON.stringify({
+        exportedAt: new Date().toISOString(),
+        chat: chat.toObject(),
+        messageCount: messages.length,
+        messages,
+      }, null, 2);
+
+      if (typeof flags.out === 'string') {
+        fs.writeFileSync(flags.out, output);
+        console.log(`Exported ${messages.length} messages to ${flags.out}`);
+      } else {
+        process.stdout.write(output + '\n');
+      }
+    },
+  },
+
+  'chat:storage': {
+    description: 'Switch a chat between per-message and bucketed storage',
+    usage: '--chat <chatId> --mode <documents|bucketed> [--compact]',
+    run: async (flags) => {
+      const chatId = requireFlag(flags, 'chat');
+      const mode = requireFlag(flags, 'mode');
+      if (mode !== 'documents' && mode !== 'bucketed') {
+        throw new Error('--mode must be documents or bucketed');
+      }
+
+      const chat = await new ChatRepository().update(chatId, { messageStorage: mode });
+      if (!chat) {
+        throw new Error(`Chat ${chatId} not found`);
+      }
+      // Switching back leaves existing buckets in place; they are still read
+      // and are unpacked as their messages change
+      console.log(`Chat ${chatId} now uses ${mode} storage`);
+
+      if (mode === 'bucketed' && flags.compact) {
+        const moved = await messageBucketService.compactChat(chatId);
+        console.log(`Moved ${moved} messages into buckets`);
+      }
+    },
+  },
+
+  'storage:check': {
+    description: 'Run the storage conformance suite against a provider\'s bucket',
+    usage: '[--provider <s3|gcs|azure>] (defaults to STORAGE_PROVIDER)',
+    run: async (flags) => {
+      const name = typeof flags.provider === 'string' ? flags.provider as StorageProviderName : undefined;
+      const provider = createStorageProvider(name);
+      console.log(`Checking ${provider.name} bucket ${provider.bucket}`);
+
+      const results = await runConformanceSuite(provider);
+      for (const result of results) {
+        console.log(`${result.passed ? 'PASS' : 'FAIL'} ${result.name} (${result.duration}ms)${result.error ? `: ${result.error}` : ''}`);
+      }
+
+      const failed = results.filter(result => !result.passed).length;
+      if (failed > 0) {
+        throw new Error(`${failed} of ${results.length} checks failed`);
+      }
+    },
+  },
+
+  'residency:migrate': {
+    description: 'Move a user\'s files or a chat\'s message archive to another data region',
+    usage: '(--user <userId> | --chat <chatId>) --region <eu|us|apac> [--wait]',
+    run: async (flags) => {
+      const region = requireFlag(flags, 'region') as DataRegion;
+      if (!DATA_REGIONS.includes(region)) {
+        throw new Error(`--region must be one of ${DATA_REGIONS.join(', ')}`);
+      }
+      const subject = typeof flags.chat === 'string' ? 'chat' : 'user';
+      const subjectId = requireFlag(flags, subject);
+
+      let migration = await dataResidencyService.requestMigration(subject, subjectId, region);
+      console.log(`Queued migration ${migration._id} of ${subject} ${subjectId} to ${region}`);
+
+      // The migration runs in this process; without --wait it carries on in
+      // the server after its next restart
+      while (flags.wait && (migration.status === 'queued' || migration.status === 'processing')) {
+        await new Promise(resolve => setTimeout(resolve, 2000));
+        migration = (await dataResidencyService.getMigration(migration._id.toString()))!;
+        console.log(`${migration.status}: ${migration.progress.moved}/${migration.progress.total}`);
+      }
+      if (migration.status === 'failed') {
+        throw new Error(migration.error || 'Migration failed');
+      }
+    },
+  },
+
+  'residency:status': {
+    description: 'Show a region migration',
+    usage: '--migration <migrationId>',
+    run: async (flags) => {
+      const migration = await dataResidencyService.getMigration(requireFlag(flags, 'migration'));
+      if (!migration) {
+        throw new Error('Migration not found');
+      }
+      console.log(JSON.stringify(serializeRegionMigration(migration), null, 2));
+    },
+  },
+
+  'cleanup': {
+    description: 'Expire old statuses and remove orphaned media records',
+    usage: '[--statuses] [--media] (defaults to both)',
+    run: async (flags) => {
+      const runAll = !flags.statuses && !flags.media;
+
+      if (runAll || flags.statuses) {
+        const expired = await new StatusRepository().cleanupExpiredStatus();
+        console.log(`Expired statuses: ${expired}`);
+      }
+
+      if (runAll || flags.media) {
+        const removed = await new MediaRepository().cleanupUnused();
+        console.log(`Removed unused media records: ${removed}`);
+      }
+    },
+  },
+
+  'seed': {
+    description: 'Seed synthetic users, chats and messages for load testing',
+    usage: '[--users <n>] [--chats <n>] [--messages <n>]',
+    run: async (flags) => {
+      const userCount = numberFlag(flags, 'users', 10);
+      const chatCount = numberFlag(flags, 'chats', 5);
+      const messagesPerChat = numberFlag(flags, 'messages', 20);
+
+      const userRepository = new UserRepository();
+      const chatRepository = new ChatRepository();
+      const messageRepository = new MessageRepository();
+      const runId = CryptoUtils.generateRandomString(6, '0123456789');
+
+      const users = [];
+      for (let i = 0; i < userCount; i++) {
+        users.push(await userRepository.create({
+          phoneNumber: `+1999${runId}${i.toString().padStart(4, '0')}`,
+          displayName: `Load User ${runId}-${i}`,
+          isVerified: true,
+        }));
+      }
+
+      let messageTotal = 0;
+      for (let i = 0; i < chatCount && users.length >= 2; i++) {
+        const a = users[i % users.length];
+        const b = users[(i + 1) % users.length];
+        const chat = await chatRepository.create({
+          type: 'direct',
+          participants: [a._id, b._id],
+        });
+
+        for (let j = 0; j < messagesPerChat; j++) {
+          const sender = j % 2 === 0 ? a : b;
+          const message = await messageRepository.create({
+            chatId: chat._id,
+            senderId: sender._id,
+            content: `Synthetic message ${j} (${runId})`,
+            type: 'text',
+          });
+          await chatRepository.updateLastActivity(chat._id, message._id);
+          messageTotal++;
+        }
+      }
+
+      console.log(`Seed run ${runId}: ${users.length} users, ${chatCount} chats, ${messageTotal} messages`);
+    },
+  },
+
+  'sharding:status': {
+    description: 'Show whether the cluster is sharded and each collection\'s shard key',
+    usage: '',
+    run: async () => {
+      const status = await shardingService.getStatus();
+      console.log(status.sharded ? 'Connected through mongos' : 'Not a sharded cluster');
+
+      for (const collection of status.collections) {
+        const state = collection.shardedWith
+          ? `sharded on ${JSON.stringify(collection.shardedWith)}`
+          : `not sharded (planned ${JSON.stringify(collection.key)})`;
+        console.log(`${collection.name.padEnd(24)} ${state}`);
+      }
+    },
+  },
+
+  'sharding:apply': {
+    description: 'Shard the large collections on their planned keys',
+    usage: '',
+    run: async () => {
+      const applied = await shardingService.apply();
+      console.log(applied.length > 0 ? `Sharded: ${applied.join(', ')}` : 'Nothing to shard');
+    },
+  },
+
+  'migrations:status': {
+    description: 'Show applied and pending database migrations',
+    usage: '',
+    run: async () => {
+      const status = await migrationRunner.getStatus();
+      if (status.length === 0) {
+        console.log('No migrations registered');
+        return;
+      }
+
+      for (const migration of status) {
+        const state = migration.applied
+          ? `applied ${migration.appliedAt?.toISOString()} (${migration.duration}ms)`
+          : 'pending';
+        console.log(`${migration.name.padEnd(40)} ${state}`);
+      }
+    },
+  },
+
+  'migrations:run': {
+    description: 'Apply pending database migrations',
+    usage: '',
+    run: async () => {
+      const applied = await migrationRunner.runPending();
+      console.log(applied.length > 0 ? `Applied: ${applied.join(', ')}` : 'Nothing to apply');
+    },
+  },
+};
+
+function printHelp(): void {
+  console.log('Usage: broctl <command> [flags]\n');
+  console.log('Commands:');
+  for (const [name, command] of Object.entries(commands)) {
+    console.log(`  ${name.padEnd(22)} ${command.description}`);
+    if (command.usage) {
+      console.log(`  ${''.padEnd(22)} ${command.usage}`);
+    }
+  }
+}
+
+async function main(): Promise<number> {
+  const [commandName, ...rest] = process.argv.slice(2);
+
+  if (!commandName || commandName === 'help' || commandName === '--help') {
+    printHelp();
+    return 0;
+  }
+
+  const command = commands[commandName];
+  if (!command) {
+    console.error(`Unknown command: ${commandName}\n`);
+    printHelp();
+    return 1;
+  }
+
+  try {
+    await connectDB();
+    await command.run(parseFlags(rest));
+    return 0;
+  } catch (error) {
+    console.error(`broctl ${commandName} failed: ${error instanceof Error ? error.message : String(error)}`);
+    return 1;
+  } finally {
+    await mongoose.disconnect();
+  }
+}
+
+main().then(code => process.exit(code));
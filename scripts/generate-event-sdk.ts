@@ -0,0 +1,238 @@
+#!/usr/bin/env node
+/**
+ * generate-event-sdk - client event definitions from the socket protocol.
+ *
+ * Writes the JSON Schema for every socket event plus TypeScript and Dart
+ * definitions generated from it, for the web and mobile client teams.
+ *
+ * Usage: npm run events:generate -- [--out generated/events]
+ */
+import fs from 'fs';
+import path from 'path';
+import { buildEventJsonSchema } from '@/lib/realtime/protocol';
+
+interface JsonSchema {
+  type?: string | string[];
+  properties?: Record<string, JsonSchema>;
+  required?: string[];
+  additionalProperties?: boolean | JsonSchema;
+  items?: JsonSchema;
+  anyOf?: JsonSchema[];
+  enum?: (string | number)[];
+  const?: string | number | boolean;
+  description?: string;
+}
+
+interface EventEntry {
+  description: string;
+  since: number;
+  payload: JsonSchema | null;
+}
+
+type Registry = Record<string, EventEntry>;
+
+// "message:reaction:added" -> "MessageReactionAdded"
+function pascalCase(event: string): string {
+  return event
+    .split(/[^a-zA-Z0-9]+/)
+    .filter(Boolean)
+    .map(part => part[0].toUpperCase() + part.slice(1))
+    .join('');
+}
+
+function camelCase(event: string): string {
+  const pascal = pascalCase(event);
+  return pascal[0].toLowerCase() + pascal.slice(1);
+}
+
+function schemaTypes(schema: JsonSchema): string[] {
+  if (!schema.type) return [];
+  return Array.isArray(schema.type) ? schema.type : [schema.type];
+}
+
+// ---------------------------------------------------------------------------
+// TypeScript
+
+function tsType(schema: JsonSchema, indent: string): string {
+  if (schema.const !== undefined) return JSON.stringify(schema.const);
+  if (schema.enum) return schema.enum.map(value => JSON.stringify(value)).join(' | ');
+  if (schema.anyOf) return schema.anyOf.map(option => tsType(option, indent)).join(' | ');
+
+  const types = schemaTypes(schema);
+  if (types.length === 0) return 'unknown';
+
+  return types.map(type => {
+    switch (type) {
+      case 'string': return 'string';
+      case 'integer':
+      case 'number': return 'number';
+      case 'boolean': return 'boolean';
+      case 'null': return 'null';
+      case 'array': return `Array<${schema.items ? tsType(schema.items, indent) : 'unknown'}>`;
+      case 'object': return tsObject(schema, indent);
+      default: return 'unknown';
+    }
+  }).join(' | ');
+}
+
+function tsObject(schema: JsonSchema, indent: string): string {
+  const inner = `${indent}  `;
+  const required = new Set(schema.required || []);
+  const lines = Object.entries(schema.properties || {}).map(([key, value]) =>
+    `${inner}${JSON.stringify(key)}${required.has(key) ? '' : '?'}: ${tsType(value, inner)};`
+  );
+
+  if (schema.additionalProperties !== false) {
+    lines.push(`${inner}[key: string]: unknown;`);
+  }
+
+  return lines.length === 0 ? 'Record<string, never>' : `{\n${lines.join('\n')}\n${indent}}`;
+}
+
+function renderTypeScript(version: number, server: Registry, client: Registry): string {
+  const out: string[] = [
+    '// Generated by scripts/generate-event-sdk.ts. Do not edit.',
+    '',
+    `export const EVENT_PROTOCOL_VERSION = ${version};`,
+    '',
+  ];
+
+  const renderRegistry = (registry: Registry, suffix: string, mapName: string) => {
+    Object.entries(registry).forEach(([event, entry]) => {
+      out.push(`/** ${entry.description} (since v${entry.since}) */`);
+      out.push(`export type ${pascalCase(event)}${suffix} = ${entry.payload ? tsType(entry.payload, '') : 'void'};`);
+      out.push('');
+    });
+
+    out.push(`export interface ${mapName} {`);
+    Object.keys(registry).forEach(event => {
+      out.push(`  ${JSON.stringify(event)}: ${pascalCase(event)}${suffix};`);
+    });
+    out.push('}', '');
+  };
+
+  renderRegistry(server, 'Event', 'ServerEvents');
+  renderRegistry(client, 'Request', 'ClientEvents');
+
+  out.push(
+    'export type ServerEventName = keyof ServerEvents;',
+    'export type ClientEventName = keyof ClientEvents;',
+    ''
+  );
+
+  return out.join('\n');
+}
+
+// ---------------------------------------------------------------------------
+// Dart
+
+function dartType(schema: JsonSchema): string {
+  if (schema.anyOf) return 'dynamic';
+  if (schema.enum || typeof schema.const === 'string') return 'String';
+
+  const types = schemaTypes(schema).filter(type => type !== 'null');
+  if (types.length !== 1) return 'dynamic';
+
+  switch (types[0]) {
+    case 'string': return 'String';
+    case 'integer': return 'int';
+    case 'number': return 'num';
+    case 'boolean': return 'bool';
+    case 'array': return 'List<dynamic>';
+    case 'object': return 'Map<String, dynamic>';
+    default: return 'dynamic';
+  }
+}
+
+// Payload wrappers expose typed getters over the decoded JSON map so unknown
+// fields from newer servers are preserved rather than dropped
+function renderDartClass(name: string, entry: EventEntry): string[] {
+  const lines = [
+    `/// ${entry.description} (since v${entry.since})`,
+    `class ${name} {`,
+    `  const ${name}(this.json);`,
+    '',
+    `  factory ${name}.fromJson(Map<String, dynamic> json) => ${name}(json);`,
+    '',
+    '  final Map<String, dynamic> json;',
+  ];
+
+  const payload = entry.payload;
+  const required = new Set(payload?.required || []);
+
+  Object.entries(payload?.properties || {}).forEach(([key, value]) => {
+    const type = dartType(value);
+    const nullable = type !== 'dynamic' && (!required.has(key) || schemaTypes(value).includes('null'));
+    const cast = type === 'dynamic' ? '' : ` as ${type}${nullable ? '?' : ''}`;
+    lines.push('', `  ${type}${nullable ? '?' : ''} get ${camelCase(key)} => json['${key}']${cast};`);
+  });
+
+  lines.push('', '  Map<String, dynamic> toJson() => json;', '}', '');
+  return lines;
+}
+
+function renderDart(version: number, server: Registry, client: Registry): string {
+  const out: string[] = [
+    '// Generated by scripts/generate-event-sdk.ts. Do not edit.',
+    '',
+    `const int eventProtocolVersion = ${version};`,
+    '',
+  ];
+
+  const renderNames = (registry: Registry, className: string) => {
+    out.push(`abstract class ${className} {`);
+    Object.entries(registry).forEach(([event, entry]) => {
+      out.push(`  /// ${entry.description}`);
+      out.push(`  static const ${camelCase(event)} = '${event}';`);
+    });
+    out.push('}', '');
+  };
+
+  renderNames(server, 'ServerEvents');
+  renderNames(client, 'ClientEvents');
+
+  Object.entries(server).forEach(([event, entry]) => {
+    if (entry.payload && schemaTypes(entry.payload).includes('object')) {
+      out.push(...renderDartClass(`${pascalCase(event)}Event`, entry));
+    }
+  });
+  Object.entries(client).forEach(([event, entry]) => {
+    if (entry.payload && schemaTypes(entry.payload).includes('object')) {
+      out.push(...renderDartClass(`${pascalCase(event)}Request`, entry));
+    }
+  });
+
+  return out.join('\n');
+}
+
+// ---------------------------------------------------------------------------
+
+function main() {
+  const args = process.argv.slice(2);
+  const outIndex = args.indexOf('--out');
+  const outDir = path.resolve(outIndex >= 0 && args[outIndex + 1] ? args[outIndex + 1] : 'generated/events');
+
+  const schema = buildEventJsonSchema();
+  const server = schema.serverEvents as Registry;
+  const client = schema.clientEvents as Registry;
+
+  fs.mkdirSync(outDir, { recursive: true });
+
+  const files: Record<string, string> = {
+    'events.schema.json': JSON.stringify(schema, null, 2) + '\n',
+    'events.ts': renderTypeScript(schema.version, server, client),
+    'events.dart': renderDart(schema.version, server, client),
+  };
+
+  Object.entries(files).forEach(([name, content]) => {
+    fs.writeFileSync(path.join(outDir, name), content);
+    console.log(`wrote ${path.join(outDir, name)}`);
+  });
+
+  console.log(
+    `protocol v${schema.version}: ${Object.keys(server).length} server events, ` +
+    `${Object.keys(client).length} client events`
+  );
+}
+
+main();
@@ -0,0 +1,253 @@
+#!/usr/bin/env node
+/**
+ * loadtest - soak test for the Socket.IO hub and messaging path.
+ *
+ * Creates synthetic users and group chats directly in MongoDB, connects N
+ * Socket.IO clients against a running server, sends messages at a fixed rate
+ * and measures send-ack latency, fanout delivery latency and drop rate.
+ *
+ * Usage: npm run loadtest -- --url http://localhost:3000 --clients 200 \
+ *          --group-size 10 --rate 1 --duration 60
+ */
+import mongoose from 'mongoose';
+import { io as connect, Socket } from 'socket.io-client';
+import connectDB from '@/lib/database/mongodb';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { jwtService } from '@/lib/auth/jwt';
+import { IUser } from '@/lib/database/models/user';
+import { CryptoUtils } from '@/lib/utils/crypto';
+
+interface LoadTestOptions {
+  url: string;
+  clients: number;
+  groupSize: number;
+  rate: number; // messages per second per client
+  duration: number; // seconds
+  drainTimeout: number; // seconds to wait for in-flight fanout after sending stops
+}
+
+interface ClientState {
+  index: number;
+  socket: Socket;
+  chatId: string;
+  seq: number;
+  connected: boolean;
+}
+
+const MARKER = 'lt';
+
+class LatencyRecorder {
+  private values: number[] = [];
+
+  record(value: number): void {
+    this.values.push(value);
+  }
+
+  get count(): number {
+    return this.values.length;
+  }
+
+  percentile(p: number): number {
+    if (this.values.length === 0) return 0;
+    const sorted = [...this.values].sort((a, b) => a - b);
+    const index = Math.ceil((p / 100) * sorted.length) - 1;
+    return sorted[Math.max(0, index)];
+  }
+
+  summary(): string {
+    return `n=${this.count} p50=${this.percentile(50).toFixed(1)}ms ` +
+      `p95=${this.percentile(95).toFixed(1)}ms p99=${this.percentile(99).toFixed(1)}ms ` +
+      `max=${this.percentile(100).toFixed(1)}ms`;
+  }
+}
+
+function parseOptions(args: string[]): LoadTestOptions {
+  const flags: Record<string, string> = {};
+  for (let i = 0; i < args.length; i += 2) {
+    if (args[i].startsWith('--') && args[i + 1] !== undefined) {
+      flags[args[i].slice(2)] = args[i + 1];
+    }
+  }
+
+  const options: LoadTestOptions = {
+    url: flags.url || process.env.LOADTEST_URL || 'http://localhost:3000',
+    clients: parseInt(flags.clients || '50', 10),
+    groupSize: parseInt(flags['group-size'] || '5', 10),
+    rate: parseFloat(flags.rate || '1'),
+    duration: parseInt(flags.duration || '30', 10),
+    drainTimeout: parseInt(flags['drain-timeout'] || '10', 10),
+  };
+
+  if (options.clients < 2 || options.groupSize < 2) {
+    throw new Error('--clients and --group-size must both be at least 2');
+  }
+
+  return options;
+}
+
+// Create users, one group chat per groupSize users, and access tokens for each user
+async function provision(options: LoadTestOptions): Promise<{ users: IUser[]; chatIds: string[]; tokens: string[] }> {
+  const userRepository = new UserRepository();
+  const chatRepository = new ChatRepository();
+  const runId = CryptoUtils.generateRandomString(6, '0123456789');
+
+  const users: IUser[] = [];
+  for (let i = 0; i < options.clients; i++) {
+    users.push(await userRepository.create({
+      phoneNumber: `+1998${runId}${i.toString().padStart(4, '0')}`,
+      displayName: `Loadtest ${runId}-${i}`,
+      isVerified: true,
+    }));
+  }
+
+  const chatIds: string[] = [];
+  for (let i = 0; i < users.length; i += options.groupSize) {
+    const members = users.slice(i, i + options.groupSize);
+    const chat = await chatRepository.create({
+      type: 'group',
+      participants: members.map(u => u._id),
+      groupInfo: {
+        name: `Loadtest ${runId} #${chatIds.length}`,
+        admins: [members[0]._id],
+        settings: {
+          whoCanSendMessages: 'everyone',
+          whoCanEditGroupInfo: 'admins',
+          whoCanAddMembers: 'admins',
+        },
+      },
+    });
+    chatIds.push(chat._id.toString());
+  }
+
+  const tokens = users.map((user, i) => jwtService.generateAccessToken(user, `loadtest-${runId}-${i}`));
+
+  console.log(`Provisioned run ${runId}: ${users.length} users, ${chatIds.length} chats`);
+  return { users, chatIds, tokens };
+}
+
+async function run(options: LoadTestOptions): Promise<void> {
+  await connectDB();
+  const { chatIds, tokens } = await provision(options);
+
+  const ackLatency = new LatencyRecorder();
+  const deliveryLatency = new LatencyRecorder();
+  const pendingAcks = new Map<string, number>(); // tempId -> sentAt
+  let sent = 0;
+  let expectedDeliveries = 0;
+  let received = 0;
+  let errors = 0;
+  let connectFailures = 0;
+
+  const clients: ClientState[] = await Promise.all(tokens.map((token, index) => new Promise<ClientState>((resolve) => {
+    const chatId = chatIds[Math.floor(index / options.groupSize)];
+    const socket = connect(options.url, {
+      auth: { token },
+      transports: ['websocket'],
+      reconnection: false,
+    });
+    const state: ClientState = { index, socket, chatId, seq: 0, connected: false };
+
+    socket.on('connect', () => {
+      state.connected = true;
+      socket.emit('chat:join', { chatId });
+      resolve(state);
+    });
+
+    socket.on('connect_error', () => {
+      connectFailures++;
+      resolve(state);
+    });
+
+    socket.on('message:sent', (data: { tempId?: string }) => {
+      const sentAt = data.tempId ? pendingAcks.get(data.tempId) : undefined;
+      if (sentAt !== undefined) {
+        ackLatency.record(Date.now() - sentAt);
+        pendingAcks.delete(data.tempId!);
+      }
+    });
+
+    socket.on('message:new', (message: { content?: string }) => {
+      const parts = message.content?.split(':');
+      if (!parts || parts[0] !== MARKER) return;
+
+      // Only count fanout to other members, not the sender's own echo
+      const senderIndex = parseInt(parts[1], 10);
+      if (senderIndex === index) return;
+
+      received++;
+      deliveryLatency.record(Date.now() - parseInt(parts[3], 10));
+    });
+
+    socket.on('error', () => {
+      errors++;
+    });
+  })));
+
+  const connected = clients.filter(c => c.connected);
+  console.log(`Connected ${connected.length}/${clients.length} clients (${connectFailures} failures)`);
+
+  // Wait for chat:join to settle before sending
+  await new Promise(resolve => setTimeout(resolve, 1000));
+
+  const membersPerChat = new Map<string, number>();
+  connected.forEach(c => membersPerChat.set(c.chatId, (membersPerChat.get(c.chatId) || 0) + 1));
+
+  const intervalMs = 1000 / options.rate;
+  const timers = connected.map(client => setInterval(() => {
+    const tempId = `${client.index}-${client.seq}`;
+    const sentAt = Date.now();
+    pendingAcks.set(tempId, sentAt);
+
+    client.socket.emit('message:send', {
+      chatId: client.chatId,
+      content: `${MARKER}:${client.index}:${client.seq}:${sentAt}`,
+      type: 'text',
+      tempId,
+    });
+
+    client.seq++;
+    sent++;
+    expectedDeliveries += (membersPerChat.get(client.chatId) || 1) - 1;
+  }, intervalMs));
+
+  const progress = setInterval(() => {
+    console.log(`sent=${sent} received=${received}/${expectedDeliveries} errors=${errors} ack[${ackLatency.summary()}]`);
+  }, 5000);
+
+  await new Promise(resolve => setTimeout(resolve, options.duration * 1000));
+  timers.forEach(clearInterval);
+
+  // Give in-flight fanout a chance to arrive
+  const drainDeadline = Date.now() + options.drainTimeout * 1000;
+  while (received < expectedDeliveries && Date.now() < drainDeadline) {
+    await new Promise(resolve => setTimeout(resolve, 250));
+  }
+  clearInterval(progress);
+
+  clients.forEach(c => c.socket.close());
+
+  const dropped = Math.max(0, expectedDeliveries - received);
+  const dropRate = expectedDeliveries > 0 ? (dropped / expectedDeliveries) * 100 : 0;
+
+  console.log('\n=== Load test results ===');
+  console.log(`clients:            ${connected.length}/${options.clients}`);
+  console.log(`messages sent:      ${sent}`);
+  console.log(`unacknowledged:     ${pendingAcks.size}`);
+  console.log(`socket errors:      ${errors}`);
+  console.log(`send ack latency:   ${ackLatency.summary()}`);
+  console.log(`fanout latency:     ${deliveryLatency.summary()}`);
+  console.log(`fanout delivered:   ${received}/${expectedDeliveries}`);
+  console.log(`drop rate:          ${dropRate.toFixed(2)}%`);
+}
+
+run(parseOptions(process.argv.slice(2)))
+  .then(async () => {
+    await mongoose.disconnect();
+    process.exit(0);
+  })
+  .catch(async (error) => {
+    console.error('loadtest failed:', error instanceof Error ? error.message : error);
+    await mongoose.disconnect();
+    process.exit(1);
+  });
@@ -0,0 +1,57 @@
+#!/usr/bin/env node
+/**
+ * worker - runs background jobs apart from the web server.
+ *
+ * Consumes the shared job queue (pushes, thumbnails, chat imports, bulk
+ * moderation), delivers reminders, scheduled calls and legal notice
+ * announcements, and runs the database and storage maintenance jobs, so
+ * they can be scaled separately from the API. Run the web server with
+ * PROCESS_ROLE=api alongside; it needs the same environment (MONGODB_URI
+ * etc.) as the server, including REDIS_URL for its socket events to reach
+ * users.
+ *
+ * Usage: npm run worker
+ */
+import mongoose from 'mongoose';
+import connectDB from '@/lib/database/mongodb';
+import { startBackgroundWorkers, stopBackgroundWorkers } from '@/lib/workers';
+import { logger } from '@/lib/monitoring/logging';
+
+const HEARTBEAT_INTERVAL = 60 * 1000;
+
+async function main() {
+  process.env.PROCESS_ROLE = 'worker';
+
+  await connectDB();
+  await startBackgroundWorkers();
+  logger.info('Worker started', { pid: process.pid });
+
+  // The workers' timers don't keep the process alive on their own
+  const heartbeat = setInterval(() => {
+    logger.debug('Worker alive', { pid: process.pid, memory: process.memoryUsage().heapUsed });
+  }, HEARTBEAT_INTERVAL);
+
+  let stopping = false;
+  const shutdown = async (signal: string) => {
+    if (stopping) return;
+    stopping = true;
+    logger.info('Worker stopping', { signal });
+
+    clearInterval(heartbeat);
+    try {
+      await stopBackgroundWorkers();
+      await mongoose.disconnect();
+    } catch (error) {
+      logger.error('Worker shutdown error', error);
+    }
+    process.exit(0);
+  };
+
+  process.on('SIGTERM', () => shutdown('SIGTERM'));
+  process.on('SIGINT', () => shutdown('SIGINT'));
+}
+
+main().catch(error => {
+  logger.error('Worker failed to start', error);
+  process.exit(1);
+});
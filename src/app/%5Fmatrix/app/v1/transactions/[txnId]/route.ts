@@ -0,0 +1,45 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { matrixAdapter } from '@/lib/federation/matrix';
+import { federationService } from '@/lib/federation';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Matrix application service transaction push (served at
+// /_matrix/app/v1/transactions/{txnId}; "%5F" escapes the leading underscore)
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ txnId: string }> }
+) {
+  try {
+    const token = request.headers.get('authorization')?.replace(/^Bearer\s+/i, '') ||
+      request.nextUrl.searchParams.get('access_token');
+
+    if (!matrixAdapter.verifyHomeserverToken(token)) {
+      return NextResponse.json(
+        { errcode: 'M_FORBIDDEN', error: 'Invalid homeserver token' },
+        { status: 403 }
+      );
+    }
+
+    if (!federationService.isEnabled()) {
+      return NextResponse.json({});
+    }
+
+    await connectDB();
+    await federationService.start();
+
+    const { txnId } = await params;
+    const body = await request.json();
+    await matrixAdapter.handleTransaction(txnId, Array.isArray(body.events) ? body.events : []);
+
+    return NextResponse.json({});
+
+  } catch (error) {
+    logger.error('Matrix transaction error', error);
+
+    return NextResponse.json(
+      { errcode: 'M_UNKNOWN', error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,41 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { apiKeyService, ApiKeyError } from '@/lib/auth/api-keys';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke an API key; requests using it fail immediately
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ keyId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { keyId } = await params;
+    const adminId = (request as any).user?.userId;
+
+    await apiKeyService.revoke(adminId, keyId, (request as any).organizationId);
+
+    return NextResponse.json({ message: 'API key revoked' });
+
+  } catch (error) {
+    if (error instanceof ApiKeyError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke API key error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,81 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createApiKeySchema } from '@/lib/database/schemas/api-key';
+import { apiKeyService, serializeApiKey, ApiKeyError } from '@/lib/auth/api-keys';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// List API keys issued to integrations; org admins see their organization's
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const includeRevoked = request.nextUrl.searchParams.get('includeRevoked') === 'true';
+    const keys = await apiKeyService.list(includeRevoked, (request as any).organizationId);
+
+    return NextResponse.json({ keys: keys.map(serializeApiKey) });
+
+  } catch (error) {
+    logger.error('List API keys error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Issue a key. The key itself is only returned here.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createApiKeySchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { key, secret } = await apiKeyService.create(adminId, validationResult.data, (request as any).organizationId);
+
+    return NextResponse.json({
+      message: 'API key created. Copy it now, it will not be shown again.',
+      key: serializeApiKey(key),
+      secret,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ApiKeyError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create API key error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,65 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { decideAppealSchema } from '@/lib/database/schemas/moderation';
+import { appealService, serializeAppeal, AppealError } from '@/lib/moderation/appeals';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Approve (lifting the suspension) or deny an appeal. The user is told by
+// email or SMS, along with the note if one is given.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ appealId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { appealId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = decideAppealSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const appeal = await appealService.decide(appealId, validationResult.data, adminId);
+
+    return NextResponse.json({
+      message: appeal.status === 'approved' ? 'Appeal approved' : 'Appeal denied',
+      appeal: serializeAppeal(appeal, true),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Decide appeal error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.BAN_USERS])];
@@ -0,0 +1,57 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { appealService, serializeAppeal, AppealError } from '@/lib/moderation/appeals';
+import { serializeModerationAction } from '@/lib/moderation/actions';
+import { getPiiPolicy, redactUserRecord } from '@/lib/admin/redaction';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// An appeal with its decision history, the suspension it is against and the
+// user's full moderation record
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ appealId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { appealId } = await params;
+    const { appeal, user, action, history, previousAppeals } = await appealService.getDetail(appealId);
+
+    return NextResponse.json({
+      appeal: serializeAppeal(appeal, true),
+      user: user && redactUserRecord({
+        id: user._id.toString(),
+        displayName: user.displayName,
+        phoneNumber: user.phoneNumber,
+        avatar: user.avatar,
+        isBanned: user.isBanned,
+        banReason: user.banReason,
+        banExpiresAt: user.banExpiresAt,
+        createdAt: user.createdAt,
+      }, getPiiPolicy((request as any).user?.permissions)),
+      action: action && serializeModerationAction(action),
+      moderationHistory: history.map(serializeModerationAction),
+      previousAppeals: previousAppeals.map(previous => serializeAppeal(previous, true)),
+    });
+
+  } catch (error) {
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get appeal error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,52 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { appealService, serializeAppeal } from '@/lib/moderation/appeals';
+import { AppealStatus } from '@/lib/database/models/appeal';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const STATUSES: AppealStatus[] = ['pending', 'approved', 'denied'];
+
+// Appeal queue: pending appeals oldest first by default, or decided ones
+// with ?status=approved|denied
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const status = (searchParams.get('status') || 'pending') as AppealStatus;
+    if (!STATUSES.includes(status)) {
+      return NextResponse.json(
+        { error: `status must be one of ${STATUSES.join(', ')}` },
+        { status: 400 }
+      );
+    }
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { appeals, total } = await appealService.list(status, limit, offset);
+
+    return NextResponse.json({
+      appeals: appeals.map(appeal => serializeAppeal(appeal)),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List appeals error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
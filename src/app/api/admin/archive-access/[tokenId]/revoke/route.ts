@@ -0,0 +1,67 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { revokeArchiveAccessSchema } from '@/lib/database/schemas/archive-access';
+import { archiveAccessService, serializeArchiveAccessToken, ArchiveAccessError } from '@/lib/compliance/archive-access';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke an archive access token; reads with it fail immediately
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ tokenId: string }> }
+) {
+  try {
+    await connectDB();
+    const { tokenId } = await params;
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = revokeArchiveAccessSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const token = await archiveAccessService.revoke(
+      (request as any).user?.userId,
+      tokenId,
+      validationResult.data.reason,
+      (request as any).organizationId
+    );
+
+    return NextResponse.json({
+      message: 'Archive access token revoked',
+      token: serializeArchiveAccessToken(token),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ArchiveAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke archive access token error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
@@ -0,0 +1,39 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { archiveAccessService, serializeArchiveAccessToken, ArchiveAccessError } from '@/lib/compliance/archive-access';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// One archive access token, with how much it has been used
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ tokenId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { tokenId } = await params;
+    const token = await archiveAccessService.get(tokenId, (request as any).organizationId);
+
+    return NextResponse.json({ token: serializeArchiveAccessToken(token) });
+
+  } catch (error) {
+    if (error instanceof ArchiveAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get archive access token error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
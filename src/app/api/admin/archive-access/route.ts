@@ -0,0 +1,103 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { createArchiveAccessSchema } from '@/lib/database/schemas/archive-access';
+import { archiveAccessService, serializeArchiveAccessToken, ArchiveAccessError } from '@/lib/compliance/archive-access';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Archive access tokens, newest first; org admins see their organization's
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+    const chatId = searchParams.get('chatId');
+
+    const { tokens, total } = await archiveAccessService.list(
+      {
+        chatId: chatId && Types.ObjectId.isValid(chatId) ? chatId : undefined,
+        includeRevoked: searchParams.get('includeRevoked') === 'true',
+      },
+      limit,
+      (page - 1) * limit,
+      (request as any).organizationId
+    );
+
+    return NextResponse.json({
+      tokens: tokens.map(serializeArchiveAccessToken),
+      pagination: {
+        page,
+        limit,
+        total,
+        pages: Math.ceil(total / limit),
+      },
+    });
+
+  } catch (error) {
+    logger.error('List archive access tokens error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Issue a read-only token for a chat's history. The token itself is only
+// returned here.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createArchiveAccessSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { token, secret } = await archiveAccessService.create(adminId, validationResult.data, (request as any).organizationId);
+
+    return NextResponse.json({
+      message: 'Archive access token issued. Copy it now, it will not be shown again.',
+      token: serializeArchiveAccessToken(token),
+      secret,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ArchiveAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Issue archive access token error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
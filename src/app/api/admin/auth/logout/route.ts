@@ -0,0 +1,30 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminSsoService } from '@/lib/auth/admin-sso';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// End the caller's admin dashboard session
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const sessionId = (request as any).adminSessionId;
+    if (sessionId) {
+      await adminSsoService.logout(sessionId);
+    }
+
+    return NextResponse.json({ message: 'Signed out' });
+
+  } catch (error) {
+    logger.error('Admin logout error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin()];
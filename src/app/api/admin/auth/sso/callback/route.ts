@@ -0,0 +1,76 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminSsoService, AdminSignIn, AdminSsoError } from '@/lib/auth/admin-sso';
+import { environmentConfig } from '@/lib/config/environment';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// OIDC redirect back from the identity provider
+export async function GET(request: NextRequest) {
+  const searchParams = request.nextUrl.searchParams;
+  const code = searchParams.get('code');
+  const state = searchParams.get('state');
+
+  if (searchParams.get('error') || !code || !state) {
+    return respond(new AdminSsoError(searchParams.get('error_description') || 'Sign-in was cancelled', 401));
+  }
+
+  try {
+    await connectDB();
+    return respond(await adminSsoService.completeOidc(code, state, clientOf(request)));
+  } catch (error) {
+    return respond(error);
+  }
+}
+
+// SAML response posted back by the identity provider
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const form = await request.formData();
+    const samlResponse = form.get('SAMLResponse');
+    const relayState = form.get('RelayState');
+    if (typeof samlResponse !== 'string' || typeof relayState !== 'string') {
+      return respond(new AdminSsoError('Invalid SAML response', 400));
+    }
+
+    return respond(await adminSsoService.completeSaml(samlResponse, relayState, clientOf(request)));
+  } catch (error) {
+    return respond(error);
+  }
+}
+
+function clientOf(request: NextRequest) {
+  return {
+    ip: request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip') || undefined,
+    userAgent: request.headers.get('user-agent') || undefined,
+  };
+}
+
+// Hand the session to the dashboard in the URL fragment, which never
+// reaches a server, or as JSON when no dashboard URL is configured
+function respond(result: AdminSignIn | unknown) {
+  const dashboardUrl = environmentConfig.get().ADMIN_SSO_REDIRECT_URL;
+
+  if (!(result instanceof Error) && result && typeof result === 'object' && 'token' in result) {
+    const signIn = result as AdminSignIn;
+    const session = {
+      token: signIn.token,
+      expiresAt: signIn.expiresAt.toISOString(),
+      ...(signIn.returnTo && { returnTo: signIn.returnTo }),
+    };
+    return dashboardUrl
+      ? NextResponse.redirect(`${dashboardUrl}#${new URLSearchParams(session)}`, 303)
+      : NextResponse.json(session);
+  }
+
+  const error = result instanceof AdminSsoError ? result : null;
+  if (!error) {
+    logger.error('Admin SSO callback error', result);
+  }
+
+  const message = error?.message || 'Internal server error';
+  return dashboardUrl
+    ? NextResponse.redirect(`${dashboardUrl}#${new URLSearchParams({ error: message })}`, 303)
+    : NextResponse.json({ error: message }, { status: error?.status || 500 });
+}
@@ -0,0 +1,27 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminSsoService, AdminSsoError } from '@/lib/auth/admin-sso';
+import { logger } from '@/lib/monitoring/logging';
+
+// Start signing in at the identity provider. returnTo is a dashboard path
+// handed back after sign-in.
+export async function GET(request: NextRequest) {
+  try {
+    const returnTo = request.nextUrl.searchParams.get('returnTo') || undefined;
+    return NextResponse.redirect(await adminSsoService.beginLogin(returnTo));
+
+  } catch (error) {
+    if (error instanceof AdminSsoError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Admin SSO login error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
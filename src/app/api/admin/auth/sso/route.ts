@@ -0,0 +1,8 @@
+import { NextResponse } from 'next/server';
+import { adminSsoService } from '@/lib/auth/admin-sso';
+
+// How admins sign in: through SSO, and whether phone+password still works.
+// Public, for the dashboard's sign-in page.
+export async function GET() {
+  return NextResponse.json(adminSsoService.getStatus());
+}
@@ -0,0 +1,104 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateAutomationRuleSchema } from '@/lib/database/schemas/automation';
+import { automationService, serializeAutomationRule, AutomationError } from '@/lib/integrations/automation';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+function errorResponse(error: unknown, context: string) {
+  if (error instanceof AutomationError) {
+    return NextResponse.json(
+      { error: error.message },
+      { status: error.status }
+    );
+  }
+
+  logger.error(context, error);
+
+  return NextResponse.json(
+    { error: 'Internal server error' },
+    { status: 500 }
+  );
+}
+
+// A rule and its stats
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ ruleId: string }> }
+) {
+  try {
+    await connectDB();
+    const { ruleId } = await params;
+
+    const rule = await automationService.get(ruleId);
+
+    return NextResponse.json({ rule: serializeAutomationRule(rule) });
+
+  } catch (error) {
+    return errorResponse(error, 'Automation rule fetch error');
+  }
+}
+
+// Edit a rule, turn it on or off, or rotate its signing secret
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ ruleId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { ruleId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateAutomationRuleSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const rule = await automationService.update(ruleId, adminId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Rule updated',
+      rule: serializeAutomationRule(rule),
+      ...(validationResult.data.rotateSecret && { signingSecret: rule.signingSecret }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return errorResponse(error, 'Update automation rule error');
+  }
+}
+
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ ruleId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { ruleId } = await params;
+    await automationService.delete(ruleId, (request as any).user?.userId);
+
+    return NextResponse.json({ message: 'Rule deleted' });
+
+  } catch (error) {
+    return errorResponse(error, 'Delete automation rule error');
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,99 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createAutomationRuleSchema } from '@/lib/database/schemas/automation';
+import { AUTOMATION_TRIGGERS, AutomationTrigger } from '@/lib/database/models/automation-rule';
+import { automationService, serializeAutomationRule, AutomationError } from '@/lib/integrations/automation';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Automation rules with their stats, newest first. Filters: ?event=,
+// ?enabled=true|false.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 100);
+    const event = searchParams.get('event') as AutomationTrigger | null;
+    const enabled = searchParams.get('enabled');
+
+    const rules = await automationService.list(
+      {
+        event: event && AUTOMATION_TRIGGERS.includes(event) ? event : undefined,
+        enabled: enabled === 'true' ? true : enabled === 'false' ? false : undefined,
+      },
+      limit,
+      (page - 1) * limit
+    );
+
+    return NextResponse.json({
+      rules: rules.map(serializeAutomationRule),
+      pagination: { page, limit },
+    });
+
+  } catch (error) {
+    logger.error('Automation rules fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a rule. The response has the secret webhook notifications are
+// signed with; it isn't shown again.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createAutomationRuleSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const rule = await automationService.create(adminId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Rule created',
+      rule: serializeAutomationRule(rule),
+      signingSecret: rule.signingSecret,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof AutomationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create automation rule error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,39 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { bulkActionService, serializeBulkAction, BulkActionError } from '@/lib/moderation/bulk-actions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Progress of a bulk action and the result of each item
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ bulkActionId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { bulkActionId } = await params;
+    const bulkAction = await bulkActionService.getBulkAction(bulkActionId);
+
+    return NextResponse.json({ bulkAction: serializeBulkAction(bulkAction, true) });
+
+  } catch (error) {
+    if (error instanceof BulkActionError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get bulk action error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,41 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { bulkActionService, serializeBulkAction } from '@/lib/moderation/bulk-actions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Bulk actions newest first, with their progress but not per-item results
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { bulkActions, total } = await bulkActionService.list(limit, offset);
+
+    return NextResponse.json({
+      bulkActions: bulkActions.map(bulkAction => serializeBulkAction(bulkAction)),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List bulk actions error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
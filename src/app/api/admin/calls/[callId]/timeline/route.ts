@@ -0,0 +1,78 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { CallRepository } from '@/lib/database/repositories/call';
+import { callEventLog, serializeCallEvent } from '@/lib/webrtc/call-events';
+import { callRatingService } from '@/lib/webrtc/call-ratings';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const callRepository = new CallRepository();
+
+// Everything that happened in a call, in order: rings, answers, joins and
+// leaves, mute and hold changes, host controls, key rotations, quality
+// alerts and what was done about them, and how it ended; then how the
+// participants rated it. Rating comments are redacted; reveal them through
+// /api/admin/pii/reveal.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const call = await callRepository.findByCallId(callId);
+    if (!call) {
+      return NextResponse.json(
+        { error: 'Call not found' },
+        { status: 404 }
+      );
+    }
+
+    const [events, ratings] = await Promise.all([
+      callEventLog.getTimeline(callId),
+      callRatingService.getCallRatings(callId),
+    ]);
+
+    return NextResponse.json({
+      call: {
+        callId: call.callId,
+        type: call.type,
+        status: call.status,
+        initiator: call.initiator,
+        participants: call.participants,
+        chatId: call.chatId,
+        isGroupCall: call.isGroupCall,
+        encrypted: call.encrypted,
+        startTime: call.startTime,
+        endTime: call.endTime,
+        duration: call.duration,
+        endReason: call.endReason,
+      },
+      events: events.map(serializeCallEvent),
+      ratings: ratings.map(rating => ({
+        user: rating.userId,
+        rating: rating.rating,
+        issues: rating.issues,
+        hasFeedback: !!rating.feedback,
+        region: rating.region,
+        platform: rating.platform,
+        appVersion: rating.appVersion,
+        metrics: rating.metrics,
+        createdAt: rating.createdAt,
+      })),
+    });
+
+  } catch (error) {
+    logger.error('Call timeline error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
@@ -0,0 +1,60 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { callRatingService } from '@/lib/webrtc/call-ratings';
+import { callQualityReportQuerySchema } from '@/lib/database/schemas/call';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const DEFAULT_PERIOD = 30 * 24 * 60 * 60 * 1000;
+
+// Post-call ratings over a period, grouped by ?groupBy=region|appVersion|
+// platform|callType: average and spread of stars, tagged issues, and what
+// was measured during the rated calls. ?from= / ?to= default to the last
+// 30 days.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = callQualityReportQuerySchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const to = validationResult.data.to || new Date();
+    const from = validationResult.data.from || new Date(to.getTime() - DEFAULT_PERIOD);
+    if (from >= to) {
+      return NextResponse.json(
+        { error: '"from" must be before "to"' },
+        { status: 400 }
+      );
+    }
+
+    const report = await callRatingService.getReport(from, to, validationResult.data.groupBy);
+
+    return NextResponse.json(report);
+
+  } catch (error) {
+    logger.error('Call quality report error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
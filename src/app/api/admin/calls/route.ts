@@ -0,0 +1,67 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminCallListSchema } from '@/lib/database/schemas/admin-list';
+import { adminListService } from '@/lib/admin/lists';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Calls, newest first by default. Filters: ?status=, ?type=, ?endReason=,
+// ?participant=, ?chatId=, ?group= (true|false) and ?from= / ?to= on start
+// time. Sort with ?sortBy=startTime|duration and ?sortOrder=; page with
+// ?page= and ?limit=. ?format=csv exports every match.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = adminCallListSchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const query = validationResult.data;
+
+    if (query.format === 'csv') {
+      logger.info('Admin list exported', { list: 'calls', mode: query.mode, adminId: (request as any).user?.userId });
+
+      return new NextResponse(adminListService.exportCalls(query), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="calls-${new Date().toISOString().slice(0, 10)}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    const { data, pagination } = await adminListService.listCalls(query);
+
+    return NextResponse.json({
+      calls: data,
+      pagination,
+      sort: { sortBy: query.sortBy, sortOrder: query.sortOrder },
+    });
+
+  } catch (error) {
+    logger.error('List calls error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
@@ -0,0 +1,64 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { addCaseCommunicationSchema } from '@/lib/database/schemas/moderation';
+import { caseService, serializeSupportCase, CaseError } from '@/lib/moderation/cases';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Record an email, in-app message or call with the user on a case
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ caseId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { caseId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = addCaseCommunicationSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const supportCase = await caseService.addCommunication(caseId, validationResult.data, adminId);
+
+    return NextResponse.json(
+      { case: serializeSupportCase(supportCase, true) },
+      { status: 201 }
+    );
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Add case communication error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,95 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateSupportCaseSchema } from '@/lib/database/schemas/moderation';
+import { caseService, serializeSupportCase, serializeAdminNote, CaseError } from '@/lib/moderation/cases';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A support case with its communications and notes
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ caseId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { caseId } = await params;
+    const { supportCase, notes } = await caseService.getCase(caseId);
+
+    return NextResponse.json({
+      case: serializeSupportCase(supportCase, true),
+      notes: notes.map(serializeAdminNote),
+    });
+
+  } catch (error) {
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get support case error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Change a case's title, status or assignee, or link more reports and
+// moderation actions to it
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ caseId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { caseId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateSupportCaseSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const supportCase = await caseService.updateCase(caseId, validationResult.data, adminId);
+
+    return NextResponse.json({ case: serializeSupportCase(supportCase, true) });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update support case error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,52 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { retentionService } from '@/lib/monitoring/retention';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { toCsv } from '@/lib/utils/csv';
+import connectDB from '@/lib/database/mongodb';
+
+// User growth: signups and active users per day over the last ?days
+// (default 30), current DAU/WAU/MAU and the latest retention cohorts.
+// ?format=csv downloads the daily series.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const days = Math.min(Math.max(parseInt(searchParams.get('days') || '30', 10) || 30, 1), 365);
+
+    const [growth, cohorts] = await Promise.all([
+      retentionService.getGrowth(days),
+      retentionService.getCohorts(8),
+    ]);
+
+    if (searchParams.get('format') === 'csv') {
+      return new NextResponse(toCsv(['day', 'signups', 'activeUsers'], growth.series), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="user-growth-${days}d.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    return NextResponse.json({
+      days,
+      growth,
+      cohorts,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Analytics endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
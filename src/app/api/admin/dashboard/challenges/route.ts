@@ -0,0 +1,36 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { challengeService } from '@/lib/security/challenge';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const days = Math.min(Math.max(parseInt(request.nextUrl.searchParams.get('days') || '7', 10) || 7, 1), 30);
+    const [settings, endpoints] = await Promise.all([
+      challengeService.getSettings(),
+      challengeService.getStats(days),
+    ]);
+
+    return NextResponse.json({
+      settings,
+      days,
+      endpoints,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Challenge stats endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
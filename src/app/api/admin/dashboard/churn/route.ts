@@ -0,0 +1,92 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { retentionService } from '@/lib/monitoring/retention';
+import { getPiiPolicy, redactUserRecord } from '@/lib/admin/redaction';
+import { ExportAnonymizer, isExportMode } from '@/lib/pipelines/anonymize';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { toCsv } from '@/lib/utils/csv';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const MAX_EXPORT_ROWS = 10000;
+
+const CSV_COLUMNS = ['userId', 'displayName', 'phoneNumber', 'signedUpAt', 'lastActiveDay', 'activeDays', 'inactiveDays', 'banned'];
+
+function clampInt(value: string | null, fallback: number, min: number, max: number): number {
+  return Math.min(Math.max(parseInt(value || '', 10) || fallback, min), max);
+}
+
+// Users who went quiet: active within ?lookbackDays (default 30) before
+// the last ?inactiveDays (default 14) and not since. ?format=csv exports
+// the whole list; with ?mode=anonymized the export has pseudonymous IDs
+// and no names or phone numbers.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const csv = searchParams.get('format') === 'csv';
+    const mode = searchParams.get('mode') || 'full';
+    const inactiveDays = clampInt(searchParams.get('inactiveDays'), 14, 1, 180);
+    const lookbackDays = clampInt(searchParams.get('lookbackDays'), 30, 1, 180);
+    if (!isExportMode(mode)) {
+      return NextResponse.json(
+        { error: 'mode must be full or anonymized' },
+        { status: 400 }
+      );
+    }
+
+    const limit = csv
+      ? MAX_EXPORT_ROWS
+      : clampInt(searchParams.get('limit'), PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE, 1, PAGINATION_CONSTANTS.MAX_PAGE_SIZE);
+    const offset = csv ? 0 : Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { total, users: rows } = await retentionService.getChurnedUsers({ inactiveDays, lookbackDays, limit, offset });
+    const policy = getPiiPolicy((request as any).user?.permissions);
+    const users = rows.map(row => redactUserRecord(row, policy));
+
+    if (csv) {
+      const anonymizer = mode === 'anonymized' ? new ExportAnonymizer() : null;
+
+      return new NextResponse(
+        anonymizer
+          ? toCsv(
+            anonymizer.columns('churnedUser', CSV_COLUMNS),
+            rows.map(row => anonymizer.record('churnedUser', row))
+          )
+          : toCsv(CSV_COLUMNS, users),
+        {
+          headers: {
+            'Content-Type': 'text/csv; charset=utf-8',
+            'Content-Disposition': `attachment; filename="churned-users-${inactiveDays}d${anonymizer ? '-anonymized' : ''}.csv"`,
+            'Cache-Control': 'no-store',
+          },
+        }
+      );
+    }
+
+    return NextResponse.json({
+      inactiveDays,
+      lookbackDays,
+      total,
+      users,
+      pagination: {
+        limit,
+        offset,
+        hasMore: offset + users.length < total,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Churned users endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
@@ -0,0 +1,77 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { compareVersions } from '@/lib/config/client-versions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// App versions across devices active in the last ?days (default 30), and
+// how many would be turned away by the current minimums
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const days = Math.min(Math.max(parseInt(request.nextUrl.searchParams.get('days') || '30', 10) || 30, 1), 90);
+    const activeSince = new Date(Date.now() - days * 24 * 60 * 60 * 1000);
+
+    const [snapshot, rows] = await Promise.all([
+      adminConfigService.get(),
+      new UserRepository().getClientVersionDistribution(activeSince),
+    ]);
+    const minimumVersions: Record<string, string | undefined> = snapshot.clients.minimumVersions || {};
+
+    const platforms: Record<string, {
+      devices: number;
+      unreported: number;
+      belowMinimum: number;
+      minimumVersion?: string;
+      versions: { appVersion: string; devices: number; supported: boolean }[];
+    }> = {};
+
+    rows.forEach(row => {
+      const minimum = minimumVersions[row.platform];
+      const platform = platforms[row.platform] ||= {
+        devices: 0,
+        unreported: 0,
+        belowMinimum: 0,
+        minimumVersion: minimum,
+        versions: [],
+      };
+
+      platform.devices += row.devices;
+      if (!row.appVersion) {
+        platform.unreported += row.devices;
+        return;
+      }
+
+      const supported = !minimum || compareVersions(row.appVersion, minimum) >= 0;
+      if (!supported) {
+        platform.belowMinimum += row.devices;
+      }
+      platform.versions.push({ appVersion: row.appVersion, devices: row.devices, supported });
+    });
+
+    Object.values(platforms).forEach(platform => {
+      platform.versions.sort((a, b) => compareVersions(b.appVersion, a.appVersion));
+    });
+
+    return NextResponse.json({
+      days,
+      platforms,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Client version stats endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
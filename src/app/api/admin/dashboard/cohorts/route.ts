@@ -0,0 +1,117 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { retentionService } from '@/lib/monitoring/retention';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { toCsv } from '@/lib/utils/csv';
+import { bucketSmallCohorts, isExportMode } from '@/lib/pipelines/anonymize';
+import connectDB from '@/lib/database/mongodb';
+
+const CSV_COLUMNS = [
+  'weekStart', 'signups',
+  'day1Eligible', 'day1Retained', 'day1Rate',
+  'day7Eligible', 'day7Retained', 'day7Rate',
+  'day30Eligible', 'day30Retained', 'day30Rate',
+  'complete',
+];
+
+// Weekly signup cohorts with day 1/7/30 retention for the last ?weeks
+// (default 12). ?format=csv downloads the table; with ?mode=anonymized,
+// weeks with fewer than ANALYTICS_MIN_COHORT_SIZE signups are merged into
+// "other".
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const weeks = Math.min(Math.max(parseInt(searchParams.get('weeks') || '12', 10) || 12, 1), 26);
+    const mode = searchParams.get('mode') || 'full';
+    if (!isExportMode(mode)) {
+      return NextResponse.json(
+        { error: 'mode must be full or anonymized' },
+        { status: 400 }
+      );
+    }
+
+    const cohorts = await retentionService.getCohorts(weeks);
+
+    if (searchParams.get('format') === 'csv') {
+      const rows = cohorts.map(cohort => ({
+        weekStart: cohort.weekStart,
+        signups: cohort.signups,
+        day1Eligible: cohort.retention.day1.eligible,
+        day1Retained: cohort.retention.day1.retained,
+        day1Rate: cohort.retention.day1.rate,
+        day7Eligible: cohort.retention.day7.eligible,
+        day7Retained: cohort.retention.day7.retained,
+        day7Rate: cohort.retention.day7.rate,
+        day30Eligible: cohort.retention.day30.eligible,
+        day30Retained: cohort.retention.day30.retained,
+        day30Rate: cohort.retention.day30.rate,
+        complete: cohort.complete,
+      }));
+
+      const exported = mode === 'anonymized'
+        ? bucketSmallCohorts(rows, {
+          size: 'signups',
+          groupBy: ['weekStart'],
+          sum: [
+            'signups',
+            'day1Eligible', 'day1Retained',
+            'day7Eligible', 'day7Retained',
+            'day30Eligible', 'day30Retained',
+          ],
+        })
+        : rows;
+
+      return new NextResponse(toCsv(CSV_COLUMNS, exported), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="retention-cohorts-${weeks}w${mode === 'anonymized' ? '-anonymized' : ''}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    return NextResponse.json({
+      weeks,
+      cohorts,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Retention cohorts endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Recompute open cohorts now instead of waiting for the hourly job
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const computed = await retentionService.recompute();
+
+    logger.info('Retention cohorts recomputed on request', {
+      userId: (request as any).user?.userId,
+      cohorts: computed,
+    });
+
+    return NextResponse.json({ message: 'Cohorts recomputed', cohorts: computed });
+
+  } catch (error) {
+    logger.error('Retention cohort recompute error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
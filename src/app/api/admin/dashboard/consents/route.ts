@@ -0,0 +1,32 @@
+import { NextResponse } from 'next/server';
+import { consentService } from '@/lib/compliance/consents';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Consent coverage per type: how many users accepted the current version,
+// are still on an older one, or never decided
+export async function GET() {
+  try {
+    await connectDB();
+
+    const consents = await consentService.getCoverage();
+
+    return NextResponse.json({
+      consents,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Consent coverage endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
@@ -0,0 +1,33 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { deliveryLatencyTracker } from '@/lib/monitoring/delivery-latency';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+
+export async function GET(request: NextRequest) {
+  try {
+    const segments = deliveryLatencyTracker.getSummary();
+    const slo = deliveryLatencyTracker.getSLO();
+
+    return NextResponse.json({
+      slo,
+      segments,
+      breaches: segments.filter(segment =>
+        segment.endToEnd.count >= slo.minSamples &&
+        (segment.endToEnd.p95 > slo.p95Ms || segment.endToEnd.p99 > slo.p99Ms)
+      ),
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Delivery latency endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
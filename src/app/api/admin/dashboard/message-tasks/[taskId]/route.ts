@@ -0,0 +1,63 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { messageTaskQueue, MessageTaskError } from '@/lib/realtime/message-tasks';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+
+// Run a dead-lettered task again
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ taskId: string }> }
+) {
+  try {
+    const { taskId } = await params;
+    await messageTaskQueue.retryDeadLetter(taskId);
+
+    return NextResponse.json({ message: 'Task queued' });
+
+  } catch (error) {
+    if (error instanceof MessageTaskError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Message task retry error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ taskId: string }> }
+) {
+  try {
+    const { taskId } = await params;
+    await messageTaskQueue.discardDeadLetter(taskId);
+
+    return NextResponse.json({ message: 'Task discarded' });
+
+  } catch (error) {
+    if (error instanceof MessageTaskError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Message task discard error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
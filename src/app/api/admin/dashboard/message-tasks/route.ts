@@ -0,0 +1,29 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { messageTaskQueue } from '@/lib/realtime/message-tasks';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+
+// Queue depth and workers on this server, plus the shared dead letters
+export async function GET(request: NextRequest) {
+  try {
+    const limit = Math.min(Number(request.nextUrl.searchParams.get('limit')) || 100, 1000);
+
+    return NextResponse.json({
+      stats: messageTaskQueue.getStats(),
+      deadLetters: await messageTaskQueue.listDeadLetters(limit),
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Message tasks endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
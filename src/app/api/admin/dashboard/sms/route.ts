@@ -0,0 +1,84 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { smsReportingService } from '@/lib/communication/sms-reporting';
+import { SmsStatsDimension } from '@/lib/database/repositories/sms-message';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { toCsv } from '@/lib/utils/csv';
+import { bucketSmallCohorts, isExportMode } from '@/lib/pipelines/anonymize';
+import connectDB from '@/lib/database/mongodb';
+
+const DIMENSIONS: SmsStatsDimension[] = ['day', 'country', 'carrier'];
+
+// SMS volume, delivery and cost over the last ?days (default 7), grouped by
+// ?groupBy= (comma-separated day, country, carrier; default country), plus
+// anomaly flags for the last 24 hours. ?format=csv downloads the rows; with
+// ?mode=anonymized, groups with fewer than ANALYTICS_MIN_COHORT_SIZE
+// messages are merged into "other".
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const days = Math.min(Math.max(parseInt(searchParams.get('days') || '7', 10) || 7, 1), 90);
+    const mode = searchParams.get('mode') || 'full';
+    const requested = (searchParams.get('groupBy') || 'country').split(',').map(value => value.trim());
+    const dimensions = DIMENSIONS.filter(dimension => requested.includes(dimension));
+    if (dimensions.length === 0) {
+      return NextResponse.json(
+        { error: `groupBy must be one or more of ${DIMENSIONS.join(', ')}` },
+        { status: 400 }
+      );
+    }
+    if (!isExportMode(mode)) {
+      return NextResponse.json(
+        { error: 'mode must be full or anonymized' },
+        { status: 400 }
+      );
+    }
+
+    const report = await smsReportingService.getReport(days, dimensions);
+
+    if (searchParams.get('format') === 'csv') {
+      const columns = [
+        ...dimensions,
+        'sent', 'delivered', 'failed', 'pending', 'segments',
+        'deliveryRate', 'failureRate', 'cost', 'costPerMessage', 'priceUnit',
+      ];
+
+      const rows = mode === 'anonymized'
+        ? bucketSmallCohorts(report.rows, {
+          size: 'sent',
+          groupBy: dimensions,
+          sum: ['sent', 'delivered', 'failed', 'pending', 'segments', 'cost'],
+        })
+        : report.rows;
+
+      return new NextResponse(toCsv(columns, rows), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="sms-${dimensions.join('-')}-${days}d${mode === 'anonymized' ? '-anonymized' : ''}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    return NextResponse.json({
+      days,
+      groupBy: dimensions,
+      ...report,
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('SMS report endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
@@ -0,0 +1,38 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { dataResidencyService, serializeRegionMigration } from '@/lib/compliance/data-residency';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Progress of a region migration
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ migrationId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { migrationId } = await params;
+    const migration = await dataResidencyService.getMigration(migrationId);
+    if (!migration) {
+      return NextResponse.json(
+        { error: 'Migration not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ migration: serializeRegionMigration(migration) });
+
+  } catch (error) {
+    logger.error('Get region migration error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_USERS])];
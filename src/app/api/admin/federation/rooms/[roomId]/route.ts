@@ -0,0 +1,135 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { federationService } from '@/lib/federation';
+import { FederationRepository } from '@/lib/database/repositories/federation';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const federationRepository = new FederationRepository();
+
+const updateBridgeSchema = z.object({
+  direction: z.enum(['both', 'outbound', 'inbound']).optional(),
+  enabled: z.boolean().optional(),
+});
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ roomId: string }> }
+) {
+  try {
+    await connectDB();
+    const { roomId } = await params;
+
+    const room = await federationRepository.findRoomById(roomId);
+    if (!room) {
+      return NextResponse.json(
+        { error: 'Federated room not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ room });
+
+  } catch (error) {
+    logger.error('Federation room fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ roomId: string }> }
+) {
+  try {
+    await connectDB();
+    const { roomId } = await params;
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = updateBridgeSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const room = await federationService.updateBridge(roomId, validationResult.data);
+    if (!room) {
+      return NextResponse.json(
+        { error: 'Federated room not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Federated room updated', {
+      userId: (request as any).user?.userId,
+      roomId,
+      fields: Object.keys(validationResult.data),
+    });
+
+    return NextResponse.json({
+      message: 'Federated room updated successfully',
+      room,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Federation room update error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ roomId: string }> }
+) {
+  try {
+    await connectDB();
+    const { roomId } = await params;
+
+    const deleted = await federationService.unbridgeChat(roomId);
+    if (!deleted) {
+      return NextResponse.json(
+        { error: 'Federated room not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Federated room removed', {
+      userId: (request as any).user?.userId,
+      roomId,
+    });
+
+    return NextResponse.json({ message: 'Federated room removed successfully' });
+
+  } catch (error) {
+    logger.error('Federation room delete error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,117 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { federationService, FederationError } from '@/lib/federation';
+import { FederationRepository } from '@/lib/database/repositories/federation';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const federationRepository = new FederationRepository();
+
+const createBridgeSchema = z.object({
+  chatId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid chat ID'),
+  protocol: z.enum(['matrix', 'xmpp']),
+  remoteRoomId: z.string().min(3).max(255),
+  direction: z.enum(['both', 'outbound', 'inbound']).default('both'),
+});
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '20', 10) || 20, 1), 100);
+    const protocol = searchParams.get('protocol');
+    const enabled = searchParams.get('enabled');
+
+    const { rooms, total } = await federationRepository.listRooms(
+      {
+        protocol: protocol === 'matrix' || protocol === 'xmpp' ? protocol : undefined,
+        enabled: enabled === null ? undefined : enabled === 'true',
+      },
+      limit,
+      (page - 1) * limit
+    );
+
+    return NextResponse.json({
+      status: federationService.getStatus(),
+      rooms,
+      pagination: {
+        page,
+        limit,
+        total,
+        pages: Math.ceil(total / limit),
+      },
+    });
+
+  } catch (error) {
+    logger.error('Federation rooms fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = createBridgeSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { chatId, protocol, remoteRoomId, direction } = validationResult.data;
+    const adminId = (request as any).user?.userId;
+
+    const room = await federationService.bridgeChat(chatId, protocol, remoteRoomId, direction, adminId);
+
+    logger.info('Chat federated', {
+      userId: adminId,
+      chatId,
+      protocol,
+      remoteRoomId: room.remoteRoomId,
+    });
+
+    return NextResponse.json({
+      message: 'Chat bridged successfully',
+      room,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof FederationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Federation bridge create error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
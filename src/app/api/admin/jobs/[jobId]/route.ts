@@ -0,0 +1,88 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { jobQueue, serializeJob, JobError } from '@/lib/jobs';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Run a failed or cancelled job again
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ jobId: string }> }
+) {
+  try {
+    await connectDB();
+    const { jobId } = await params;
+
+    if (!Types.ObjectId.isValid(jobId)) {
+      return NextResponse.json(
+        { error: 'Job not found' },
+        { status: 404 }
+      );
+    }
+
+    const job = await jobQueue.retry(jobId);
+
+    logger.info('Job retried', { jobId, name: job.name, adminId: (request as any).user?.userId });
+
+    return NextResponse.json({ job: serializeJob(job) });
+
+  } catch (error) {
+    if (error instanceof JobError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Job retry error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Cancel a pending job, or give up on a failed one
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ jobId: string }> }
+) {
+  try {
+    await connectDB();
+    const { jobId } = await params;
+
+    if (!Types.ObjectId.isValid(jobId)) {
+      return NextResponse.json(
+        { error: 'Job not found' },
+        { status: 404 }
+      );
+    }
+
+    await jobQueue.cancel(jobId);
+
+    logger.info('Job cancelled', { jobId, adminId: (request as any).user?.userId });
+
+    return NextResponse.json({ message: 'Job cancelled' });
+
+  } catch (error) {
+    if (error instanceof JobError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Job cancel error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
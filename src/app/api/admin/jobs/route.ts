@@ -0,0 +1,51 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { jobQueue, serializeJob } from '@/lib/jobs';
+import { JobStatus } from '@/lib/database/models/job';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const JOB_STATUSES: JobStatus[] = ['pending', 'processing', 'completed', 'failed', 'cancelled'];
+
+// Background jobs, newest first, with counts per job type and status
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+    const status = searchParams.get('status') as JobStatus | null;
+    const name = searchParams.get('name');
+
+    const [jobs, stats] = await Promise.all([
+      jobQueue.list(
+        {
+          status: status && JOB_STATUSES.includes(status) ? status : undefined,
+          name: name || undefined,
+        },
+        limit,
+        (page - 1) * limit
+      ),
+      jobQueue.getStats(),
+    ]);
+
+    return NextResponse.json({
+      stats,
+      jobs: jobs.map(serializeJob),
+      pagination: { page, limit },
+    });
+
+  } catch (error) {
+    logger.error('Jobs fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
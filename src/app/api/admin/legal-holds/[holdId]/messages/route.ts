@@ -0,0 +1,60 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { legalHoldService, serializeHeldMessage, LegalHoldError } from '@/lib/compliance/legal-holds';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Messages deleted for everyone while under the hold, as they were before
+// deletion. Every read is audit-logged.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ holdId: string }> }
+) {
+  try {
+    await connectDB();
+    const { holdId } = await params;
+
+    if (!Types.ObjectId.isValid(holdId)) {
+      return NextResponse.json(
+        { error: 'Hold not found' },
+        { status: 404 }
+      );
+    }
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+
+    const messages = await legalHoldService.listHeldMessages(
+      holdId,
+      (request as any).user?.userId,
+      limit,
+      (page - 1) * limit
+    );
+
+    return NextResponse.json({
+      messages: messages.map(serializeHeldMessage),
+      pagination: { page, limit },
+    });
+
+  } catch (error) {
+    if (error instanceof LegalHoldError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Held messages fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication; the service also requires a super admin
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
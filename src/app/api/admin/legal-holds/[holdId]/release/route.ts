@@ -0,0 +1,71 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { releaseLegalHoldSchema } from '@/lib/database/schemas/legal-hold';
+import { legalHoldService, serializeLegalHold, LegalHoldError } from '@/lib/compliance/legal-holds';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Release a legal hold. Deletion resumes for what it covered; messages
+// already in the vault stay there.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ holdId: string }> }
+) {
+  try {
+    await connectDB();
+    const { holdId } = await params;
+
+    if (!Types.ObjectId.isValid(holdId)) {
+      return NextResponse.json(
+        { error: 'Hold not found' },
+        { status: 404 }
+      );
+    }
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = releaseLegalHoldSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const hold = await legalHoldService.release(holdId, (request as any).user?.userId, validationResult.data.reason);
+
+    return NextResponse.json({
+      message: 'Legal hold released',
+      hold: serializeLegalHold(hold),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalHoldError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Release legal hold error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication; the service also requires a super admin
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
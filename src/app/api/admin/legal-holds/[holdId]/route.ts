@@ -0,0 +1,47 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { legalHoldService, serializeLegalHold, LegalHoldError } from '@/lib/compliance/legal-holds';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A legal hold
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ holdId: string }> }
+) {
+  try {
+    await connectDB();
+    const { holdId } = await params;
+
+    if (!Types.ObjectId.isValid(holdId)) {
+      return NextResponse.json(
+        { error: 'Hold not found' },
+        { status: 404 }
+      );
+    }
+
+    const hold = await legalHoldService.get(holdId, (request as any).user?.userId);
+
+    return NextResponse.json({ hold: serializeLegalHold(hold) });
+
+  } catch (error) {
+    if (error instanceof LegalHoldError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Legal hold fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication; the service also requires a super admin
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,111 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { placeLegalHoldSchema } from '@/lib/database/schemas/legal-hold';
+import { LegalHoldStatus, LegalHoldSubject } from '@/lib/database/models/legal-hold';
+import { legalHoldService, serializeLegalHold, LegalHoldError } from '@/lib/compliance/legal-holds';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const STATUSES: LegalHoldStatus[] = ['active', 'released'];
+const SUBJECTS: LegalHoldSubject[] = ['user', 'chat'];
+
+// Legal holds, newest first. Super admins only.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+    const status = searchParams.get('status') as LegalHoldStatus | null;
+    const subject = searchParams.get('subject') as LegalHoldSubject | null;
+    const subjectId = searchParams.get('subjectId');
+
+    const holds = await legalHoldService.list(
+      adminId,
+      {
+        status: status && STATUSES.includes(status) ? status : undefined,
+        subject: subject && SUBJECTS.includes(subject) ? subject : undefined,
+        subjectId: subjectId && Types.ObjectId.isValid(subjectId) ? subjectId : undefined,
+      },
+      limit,
+      (page - 1) * limit
+    );
+
+    return NextResponse.json({
+      holds: holds.map(serializeLegalHold),
+      pagination: { page, limit },
+    });
+
+  } catch (error) {
+    if (error instanceof LegalHoldError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Legal holds fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Place a user or chat under legal hold
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = placeLegalHoldSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const hold = await legalHoldService.place(adminId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Legal hold placed',
+      hold: serializeLegalHold(hold),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalHoldError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Place legal hold error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication; the service also requires a super admin
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,118 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { legalNoticeService, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { csvRow } from '@/lib/utils/csv';
+import connectDB from '@/lib/database/mongodb';
+
+const CSV_COLUMNS = ['userId', 'noticeKey', 'version', 'acceptedAt', 'ip', 'userAgent', 'deviceId'] as const;
+
+function parseDate(value: string | null): Date | undefined | null {
+  if (!value) return undefined;
+  const date = new Date(value);
+  return isNaN(date.getTime()) ? null : date;
+}
+
+// Export acceptance records for audits as CSV (default) or JSON, filtered
+// by ?version=, ?from= and ?to= (ISO dates). Streamed, so large exports
+// don't sit in memory.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ noticeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { noticeId } = await params;
+    const searchParams = request.nextUrl.searchParams;
+    const format = searchParams.get('format') === 'json' ? 'json' : 'csv';
+    const version = parseInt(searchParams.get('version') || '', 10) || undefined;
+    const from = parseDate(searchParams.get('from'));
+    const to = parseDate(searchParams.get('to'));
+    if (from === null || to === null) {
+      return NextResponse.json(
+        { error: 'from and to must be ISO dates' },
+        { status: 400 }
+      );
+    }
+
+    const notice = await legalNoticeService.getById(noticeId);
+    const cursor = legalNoticeService.exportAcceptances(notice._id, { version, from, to });
+    const encoder = new TextEncoder();
+    let count = 0;
+
+    const stream = new ReadableStream<Uint8Array>({
+      async start(controller) {
+        try {
+          controller.enqueue(encoder.encode(format === 'csv' ? csvRow([...CSV_COLUMNS]) : '['));
+
+          for await (const acceptance of cursor) {
+            const record = {
+              userId: acceptance.userId.toString(),
+              noticeKey: acceptance.noticeKey,
+              version: acceptance.version,
+              acceptedAt: acceptance.acceptedAt,
+              ip: acceptance.ip,
+              userAgent: acceptance.userAgent,
+              deviceId: acceptance.deviceId,
+            };
+            const line = format === 'csv'
+              ? csvRow(CSV_COLUMNS.map(column => record[column]))
+              : `${count > 0 ? ',' : ''}\n${JSON.stringify(record)}`;
+            controller.enqueue(encoder.encode(line));
+            count++;
+          }
+
+          if (format === 'json') {
+            controller.enqueue(encoder.encode('\n]\n'));
+          }
+          controller.close();
+
+          logger.info('Legal acceptances exported', {
+            noticeId,
+            key: notice.key,
+            version,
+            format,
+            count,
+            adminId: (request as any).user?.userId,
+          });
+        } catch (error) {
+          logger.error('Legal acceptance export stream error', error, { noticeId });
+          controller.error(error);
+        }
+      },
+      async cancel() {
+        await cursor.close();
+      },
+    });
+
+    const filename = `${notice.key}${version ? `-v${version}` : ''}-acceptances.${format}`;
+
+    return new NextResponse(stream, {
+      headers: {
+        'Content-Type': format === 'csv' ? 'text/csv; charset=utf-8' : 'application/json',
+        'Content-Disposition': `attachment; filename="${filename}"`,
+        'Cache-Control': 'no-store',
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Export legal acceptances error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
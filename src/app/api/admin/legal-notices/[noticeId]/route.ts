@@ -0,0 +1,130 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateLegalNoticeSchema } from '@/lib/database/schemas/legal-notice';
+import { legalNoticeService, serializeLegalNotice, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A notice with the text of every version and acceptance figures
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ noticeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { noticeId } = await params;
+    const notice = await legalNoticeService.getById(noticeId);
+    const acceptance = await legalNoticeService.getAcceptanceStats(notice);
+
+    return NextResponse.json({
+      notice: serializeLegalNotice(notice, true),
+      acceptance,
+    });
+
+  } catch (error) {
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Rename the notice or change whether it is mandatory. The text is changed
+// by publishing a new version.
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ noticeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { noticeId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateLegalNoticeSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const notice = await legalNoticeService.update(noticeId, validationResult.data);
+
+    logger.info('Legal notice updated', { noticeId, adminId: (request as any).user?.userId });
+
+    return NextResponse.json({
+      message: 'Legal notice updated',
+      notice: serializeLegalNotice(notice),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Archive the notice. Acceptance records are kept for audits.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ noticeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { noticeId } = await params;
+    await legalNoticeService.archive(noticeId);
+
+    return NextResponse.json({ message: 'Legal notice archived' });
+
+  } catch (error) {
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Archive legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
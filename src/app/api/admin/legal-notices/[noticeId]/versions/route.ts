@@ -0,0 +1,65 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { publishLegalNoticeVersionSchema } from '@/lib/database/schemas/legal-notice';
+import { legalNoticeService, serializeLegalNotice, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Publish a new version. For mandatory notices, users have to accept it
+// again once effectiveAt passes.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ noticeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { noticeId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = publishLegalNoticeVersionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const adminId = (request as any).user?.userId;
+    const notice = await legalNoticeService.publishVersion(noticeId, validationResult.data, adminId);
+
+    return NextResponse.json({
+      message: 'Version published',
+      notice: serializeLegalNotice(notice),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Publish legal notice version error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,78 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createLegalNoticeSchema } from '@/lib/database/schemas/legal-notice';
+import { legalNoticeService, serializeLegalNotice, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const includeArchived = request.nextUrl.searchParams.get('includeArchived') === 'true';
+    const notices = await legalNoticeService.list(includeArchived);
+
+    return NextResponse.json({ notices: notices.map(notice => serializeLegalNotice(notice)) });
+
+  } catch (error) {
+    logger.error('List legal notices error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a notice, optionally publishing its first version right away
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = createLegalNoticeSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const adminId = (request as any).user?.userId;
+    const notice = await legalNoticeService.create(validationResult.data, adminId);
+
+    return NextResponse.json({
+      message: 'Legal notice created',
+      notice: serializeLegalNotice(notice, true),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,43 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { hashBlocklistService, HashBlocklistError } from '@/lib/media/hash-blocklist';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Take a hash off the blocklist
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ entryId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { entryId } = await params;
+    const adminId = (request as any).user?.userId;
+
+    await hashBlocklistService.remove(entryId, adminId);
+
+    return NextResponse.json({
+      message: 'Hash removed from blocklist',
+    });
+
+  } catch (error) {
+    if (error instanceof HashBlocklistError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Remove blocked media hash error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.DELETE_ANY_MESSAGE])];
@@ -0,0 +1,95 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { addBlockedHashSchema } from '@/lib/database/schemas/moderation';
+import { hashBlocklistService, serializeBlockedMediaHash, HashBlocklistError } from '@/lib/media/hash-blocklist';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Blocklisted image hashes, newest first
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { entries, total } = await hashBlocklistService.list(limit, offset);
+
+    return NextResponse.json({
+      entries: entries.map(serializeBlockedMediaHash),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List blocked media hashes error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Blocklist an image, by its perceptual hash or by the ID of an uploaded
+// copy. Future uploads that are near duplicates of it are refused; the
+// response says how many existing uploads share the exact hash.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = addBlockedHashSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { entry, existingUploads } = await hashBlocklistService.add(validationResult.data, adminId);
+
+    return NextResponse.json({
+      message: 'Hash added to blocklist',
+      entry: serializeBlockedMediaHash(entry),
+      existingUploads,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof HashBlocklistError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Add blocked media hash error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.DELETE_ANY_MESSAGE])];
@@ -0,0 +1,67 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminMediaListSchema } from '@/lib/database/schemas/admin-list';
+import { adminListService } from '@/lib/admin/lists';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Uploaded files, newest first by default. Filters: ?type=, ?uploadedBy=,
+// ?chatId=, ?storageRegion=, ?mimeType= and ?from= / ?to= on upload date.
+// Sort with ?sortBy=createdAt|size and ?sortOrder=; page with ?page= and
+// ?limit=. ?format=csv exports every match.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = adminMediaListSchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const query = validationResult.data;
+
+    if (query.format === 'csv') {
+      logger.info('Admin list exported', { list: 'files', mode: query.mode, adminId: (request as any).user?.userId });
+
+      return new NextResponse(adminListService.exportMedia(query), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="files-${new Date().toISOString().slice(0, 10)}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    const { data, pagination } = await adminListService.listMedia(query);
+
+    return NextResponse.json({
+      files: data,
+      pagination,
+      sort: { sortBy: query.sortBy, sortOrder: query.sortOrder },
+    });
+
+  } catch (error) {
+    logger.error('List files error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
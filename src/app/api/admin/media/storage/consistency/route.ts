@@ -0,0 +1,61 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { storageReferenceService } from '@/lib/media/storage-references';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// How much deduplication saves: stored files, references to them and the
+// bytes not stored twice
+export async function GET() {
+  try {
+    await connectDB();
+
+    return NextResponse.json({
+      stats: await storageReferenceService.getStats(),
+    });
+
+  } catch (error) {
+    logger.error('Storage reference stats error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Run a batch of the reference count check now instead of waiting for the
+// background job; ?limit= sets the batch size
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const limit = Math.min(
+      Math.max(parseInt(request.nextUrl.searchParams.get('limit') || '', 10) || 200, 1),
+      1000
+    );
+    const report = await storageReferenceService.checkConsistency(limit);
+
+    logger.info('Storage consistency check run', {
+      userId: (request as any).user?.userId,
+      ...report,
+    });
+
+    return NextResponse.json({
+      message: 'Consistency check completed',
+      report,
+    });
+
+  } catch (error) {
+    logger.error('Storage consistency check error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
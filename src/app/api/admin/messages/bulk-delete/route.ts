@@ -0,0 +1,54 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { bulkDeleteMessagesSchema } from '@/lib/database/schemas/moderation';
+import { bulkActionService, serializeBulkAction } from '@/lib/moderation/bulk-actions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Delete many messages for everyone at once. Runs in the background;
+// follow it at GET /api/admin/bulk-actions/:bulkActionId.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = bulkDeleteMessagesSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const bulkAction = await bulkActionService.request('delete_messages', validationResult.data.messageIds, {}, adminId);
+
+    return NextResponse.json(
+      { bulkAction: serializeBulkAction(bulkAction) },
+      { status: 202 }
+    );
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Bulk delete messages error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.DELETE_ANY_MESSAGE])];
@@ -0,0 +1,143 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { messageRecord } from '@/lib/pipelines/records';
+import { ExportAnonymizer, isExportMode } from '@/lib/pipelines/anonymize';
+import { encryptionService } from '@/lib/security/encryption';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const messageRepository = new MessageRepository();
+
+const DEFAULT_LIMIT = 10000;
+const MAX_LIMIT = 100000;
+
+function parseDate(value: string | null): Date | undefined | null {
+  if (!value) return undefined;
+  const date = new Date(value);
+  return isNaN(date.getTime()) ? null : date;
+}
+
+// Stream message metadata as NDJSON for data pipelines, oldest first. Text,
+// locations and contacts are never included. Each line carries a cursor;
+// pass the last one seen as ?cursor= to resume. The final line is
+// {"type":"end","cursor":...,"hasMore":...}. Filters: ?chatId=, ?from=,
+// ?to= (ISO dates) and ?limit= (records per response). ?mode=anonymized
+// hashes IDs with the current analytics salt and coarsens timestamps, for
+// sharing outside the company; its cursors are encrypted, so they resume
+// the export without giving away message IDs.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    let cursorParam = searchParams.get('cursor');
+    const chatIdParam = searchParams.get('chatId');
+    const mode = searchParams.get('mode') || 'full';
+    const from = parseDate(searchParams.get('from'));
+    const to = parseDate(searchParams.get('to'));
+    const limit = Math.min(
+      Math.max(parseInt(searchParams.get('limit') || '', 10) || DEFAULT_LIMIT, 1),
+      MAX_LIMIT
+    );
+
+    if (from === null || to === null) {
+      return NextResponse.json(
+        { error: 'from and to must be ISO dates' },
+        { status: 400 }
+      );
+    }
+    if (!isExportMode(mode)) {
+      return NextResponse.json(
+        { error: 'mode must be full or anonymized' },
+        { status: 400 }
+      );
+    }
+    if (encryptionService.isEncryptedSecret(cursorParam)) {
+      try {
+        cursorParam = encryptionService.decryptSecret(cursorParam);
+      } catch {
+        return NextResponse.json(
+          { error: 'Invalid cursor or chatId' },
+          { status: 400 }
+        );
+      }
+    }
+    if ((cursorParam && !Types.ObjectId.isValid(cursorParam)) || (chatIdParam && !Types.ObjectId.isValid(chatIdParam))) {
+      return NextResponse.json(
+        { error: 'Invalid cursor or chatId' },
+        { status: 400 }
+      );
+    }
+
+    const cursor = messageRepository.cursorForExport({
+      after: cursorParam ? new Types.ObjectId(cursorParam) : undefined,
+      chatId: chatIdParam ? new Types.ObjectId(chatIdParam) : undefined,
+      from,
+      to,
+    });
+    const anonymizer = mode === 'anonymized' ? new ExportAnonymizer() : null;
+    const encoder = new TextEncoder();
+    let count = 0;
+    let last = searchParams.get('cursor');
+
+    const stream = new ReadableStream<Uint8Array>({
+      async start(controller) {
+        try {
+          let hasMore = false;
+
+          for await (const message of cursor) {
+            if (count >= limit) {
+              hasMore = true;
+              break;
+            }
+
+            const record = messageRecord(message);
+            last = anonymizer ? encryptionService.encryptSecret(record.id) : record.id;
+            const line = anonymizer ? anonymizer.record('message', record) : record;
+            controller.enqueue(encoder.encode(`${JSON.stringify({ type: 'message', cursor: last, ...line })}\n`));
+            count++;
+          }
+
+          await cursor.close();
+          controller.enqueue(encoder.encode(`${JSON.stringify({ type: 'end', cursor: last, hasMore, count })}\n`));
+          controller.close();
+
+          logger.info('Messages exported', {
+            adminId: (request as any).user?.userId,
+            count,
+            hasMore,
+            chatId: chatIdParam,
+            mode,
+          });
+        } catch (error) {
+          logger.error('Message export stream error', error);
+          controller.error(error);
+        }
+      },
+      async cancel() {
+        await cursor.close();
+      },
+    });
+
+    return new NextResponse(stream, {
+      headers: {
+        'Content-Type': 'application/x-ndjson',
+        'Cache-Control': 'no-store',
+      },
+    });
+
+  } catch (error) {
+    logger.error('Export messages error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
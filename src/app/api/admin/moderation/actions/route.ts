@@ -0,0 +1,49 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { moderationService, serializeModerationAction } from '@/lib/moderation/actions';
+import { ModerationActionKind } from '@/lib/database/models/moderation-action';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Bans and restrictions in force, newest first; ?action=ban|restrict narrows it
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const action = searchParams.get('action') as ModerationActionKind | null;
+    if (action && action !== 'ban' && action !== 'restrict') {
+      return NextResponse.json(
+        { error: 'action must be ban or restrict' },
+        { status: 400 }
+      );
+    }
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { actions, total } = await moderationService.listActive(action || undefined, limit, offset);
+
+    return NextResponse.json({
+      actions: actions.map(serializeModerationAction),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List moderation actions error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
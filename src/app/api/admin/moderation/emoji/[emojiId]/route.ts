@@ -0,0 +1,65 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { removeGroupEmojiSchema } from '@/lib/database/schemas/moderation';
+import { customEmojiService, serializeGroupEmoji, CustomEmojiError } from '@/lib/media/custom-emoji';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Take a custom emoji down. Its image stops resolving everywhere, including
+// in messages and reactions that already used it.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ emojiId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { emojiId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = removeGroupEmojiSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const emoji = await customEmojiService.moderate(emojiId, adminId, validationResult.data.reason);
+
+    return NextResponse.json({
+      message: 'Emoji taken down',
+      emoji: serializeGroupEmoji(emoji),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CustomEmojiError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Take down custom emoji error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.DELETE_ANY_MESSAGE])];
@@ -0,0 +1,60 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { customEmojiService, serializeGroupEmoji } from '@/lib/media/custom-emoji';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Custom emoji uploaded to groups, newest first, for review. Filters:
+// ?chatId= and ?status=active|removed.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+    const chatId = searchParams.get('chatId') || undefined;
+    const status = searchParams.get('status') || undefined;
+
+    if ((chatId && !Types.ObjectId.isValid(chatId)) || (status && status !== 'active' && status !== 'removed')) {
+      return NextResponse.json(
+        { error: 'Invalid chatId or status' },
+        { status: 400 }
+      );
+    }
+
+    const { emoji, total } = await customEmojiService.listForModeration(
+      { chatId, status: status as 'active' | 'removed' | undefined },
+      limit,
+      offset
+    );
+    const resolved = await customEmojiService.resolve(emoji.map(item => item._id));
+
+    return NextResponse.json({
+      emoji: emoji.map(item => ({
+        ...serializeGroupEmoji(item, resolved.get(item._id.toString())),
+        group: item.chatId,
+      })),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List custom emoji error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
@@ -0,0 +1,66 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { reviewMessageSchema } from '@/lib/database/schemas/moderation';
+import { moderationService, ModerationError } from '@/lib/moderation/actions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Decide on a held message: approve delivers it to the chat, reject keeps it
+// visible to the sender only
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ messageId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { messageId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = reviewMessageSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const message = await moderationService.reviewMessage(messageId, validationResult.data.decision, adminId);
+
+    return NextResponse.json({
+      message: validationResult.data.decision === 'approve' ? 'Message approved' : 'Message rejected',
+      messageId: message._id.toString(),
+      moderation: message.moderation,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ModerationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Review message error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.DELETE_ANY_MESSAGE])];
@@ -0,0 +1,45 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { moderationService } from '@/lib/moderation/actions';
+import { getPiiPolicy, redactMessage } from '@/lib/admin/redaction';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Messages from users restricted in review mode, waiting for a decision.
+// Oldest first, since their recipients have been waiting longest. Content
+// is redacted; reveal it through /api/admin/pii/reveal.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { messages, total } = await moderationService.getReviewQueue(limit, offset);
+    const policy = getPiiPolicy((request as any).user?.permissions);
+
+    return NextResponse.json({
+      messages: messages.map(message => redactMessage(message, policy)),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('Get moderation queue error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_MESSAGES])];
@@ -0,0 +1,92 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { organizationAdminSchema } from '@/lib/database/schemas/organization';
+import { organizationService, OrganizationError } from '@/lib/organizations';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Make an admin an org admin: from then on they only see and manage this
+// organization, with the permissions of their role. Platform admins only.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  return await changeAdmin(request, params, 'assign');
+}
+
+// Make an org admin a platform admin again. Platform admins only.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  return await changeAdmin(request, params, 'unassign');
+}
+
+async function changeAdmin(
+  request: NextRequest,
+  params: Promise<{ orgId: string }>,
+  change: 'assign' | 'unassign'
+) {
+  try {
+    await connectDB();
+
+    if ((request as any).organizationId) {
+      return NextResponse.json(
+        { error: 'Only platform admins can assign org admins' },
+        { status: 403 }
+      );
+    }
+
+    const { orgId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = organizationAdminSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    // Check the organization exists either way
+    await organizationService.get(orgId);
+    await organizationService.setAdminOrganization(
+      validationResult.data.adminId,
+      change === 'assign' ? orgId : null,
+      (request as any).user?.userId
+    );
+
+    return NextResponse.json({
+      message: change === 'assign' ? 'Admin assigned to organization' : 'Admin is a platform admin again',
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Organization admin change error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,129 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { canManageOrganization, OrganizationError } from '@/lib/organizations';
+import { brandingService, BrandingAsset, BrandingError } from '@/lib/organizations/branding';
+import { parseMultipartStream, getMultipartBoundary } from '@/lib/media/multipart-stream';
+import {
+  PayloadTooLargeError,
+  checkContentLength,
+  getBodyLimit,
+  limitStream,
+  payloadTooLargeResponse,
+} from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const ASSETS: BrandingAsset[] = ['logo', 'favicon'];
+
+// Upload the organization's logo or favicon as multipart/form-data (PNG,
+// JPEG, WebP or ICO), replacing the current one
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string; asset: string }> }
+) {
+  const limit = getBodyLimit(request.nextUrl.pathname);
+
+  try {
+    await connectDB();
+
+    const { orgId, asset } = await params;
+    if (!canManageOrganization((request as any).organizationId, orgId) || !ASSETS.includes(asset as BrandingAsset)) {
+      return NextResponse.json(
+        { error: 'Not found' },
+        { status: 404 }
+      );
+    }
+
+    const tooLarge = checkContentLength(request, limit);
+    if (tooLarge) {
+      return tooLarge;
+    }
+
+    const boundary = getMultipartBoundary(request.headers.get('content-type'));
+    if (!boundary || !request.body) {
+      return NextResponse.json(
+        { error: 'Expected multipart/form-data body' },
+        { status: 400 }
+      );
+    }
+
+    const { file } = await parseMultipartStream(limitStream(request.body, limit), boundary, request.signal);
+    const chunks: Buffer[] = [];
+    for await (const chunk of file.stream) {
+      chunks.push(chunk as Buffer);
+    }
+
+    const organization = await brandingService.uploadAsset(orgId, (request as any).user?.userId, asset as BrandingAsset, {
+      buffer: Buffer.concat(chunks),
+      filename: file.filename,
+      mimeType: file.mimeType,
+    });
+
+    return NextResponse.json({
+      message: 'Branding updated',
+      branding: await brandingService.serialize(organization),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof BrandingError || error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Upload branding asset error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Remove the organization's logo or favicon, falling back to the default
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string; asset: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { orgId, asset } = await params;
+    if (!canManageOrganization((request as any).organizationId, orgId) || !ASSETS.includes(asset as BrandingAsset)) {
+      return NextResponse.json(
+        { error: 'Not found' },
+        { status: 404 }
+      );
+    }
+
+    const organization = await brandingService.removeAsset(orgId, (request as any).user?.userId, asset as BrandingAsset);
+
+    return NextResponse.json({
+      message: 'Branding updated',
+      branding: await brandingService.serialize(organization),
+    });
+
+  } catch (error) {
+    if (error instanceof BrandingError || error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Remove branding asset error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,113 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateBrandingSchema } from '@/lib/database/schemas/organization';
+import { organizationService, canManageOrganization, OrganizationError } from '@/lib/organizations';
+import { brandingService, BrandingError } from '@/lib/organizations/branding';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The organization's branding along with its custom domains
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { orgId } = await params;
+    if (!canManageOrganization((request as any).organizationId, orgId)) {
+      return NextResponse.json(
+        { error: 'Organization not found' },
+        { status: 404 }
+      );
+    }
+
+    const organization = await organizationService.get(orgId);
+    const branding = await brandingService.serialize(organization);
+
+    return NextResponse.json({
+      branding: { ...branding, domains: organization.branding?.domains || [] },
+    });
+
+  } catch (error) {
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Organization branding fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Change the app name, colors or custom domains. Org admins can brand
+// their own organization.
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { orgId } = await params;
+    if (!canManageOrganization((request as any).organizationId, orgId)) {
+      return NextResponse.json(
+        { error: 'Organization not found' },
+        { status: 404 }
+      );
+    }
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateBrandingSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const organization = await brandingService.update(orgId, (request as any).user?.userId, validationResult.data);
+    const branding = await brandingService.serialize(organization);
+
+    return NextResponse.json({
+      message: 'Branding updated',
+      branding: { ...branding, domains: organization.branding?.domains || [] },
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof BrandingError || error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update organization branding error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,91 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { organizationMembersSchema } from '@/lib/database/schemas/organization';
+import { organizationService, OrganizationError } from '@/lib/organizations';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Move users from the shared pool into the organization, within its user
+// quota. Platform admins only.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  return await changeMembers(request, params, 'add');
+}
+
+// Move users back to the shared pool. Platform admins only.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  return await changeMembers(request, params, 'remove');
+}
+
+async function changeMembers(
+  request: NextRequest,
+  params: Promise<{ orgId: string }>,
+  change: 'add' | 'remove'
+) {
+  try {
+    await connectDB();
+
+    if ((request as any).organizationId) {
+      return NextResponse.json(
+        { error: 'Only platform admins can move users between organizations' },
+        { status: 403 }
+      );
+    }
+
+    const { orgId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = organizationMembersSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const adminId = (request as any).user?.userId;
+    const { userIds } = validationResult.data;
+    const count = change === 'add'
+      ? await organizationService.addMembers(orgId, userIds, adminId)
+      : await organizationService.removeMembers(orgId, userIds, adminId);
+
+    return NextResponse.json({
+      message: change === 'add' ? 'Users added to organization' : 'Users removed from organization',
+      count,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Organization members change error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
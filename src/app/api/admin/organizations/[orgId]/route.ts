@@ -0,0 +1,114 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateOrganizationSchema } from '@/lib/database/schemas/organization';
+import {
+  organizationService,
+  serializeOrganization,
+  canManageOrganization,
+  OrganizationError,
+} from '@/lib/organizations';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// An organization with its user and group counts
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { orgId } = await params;
+    if (!canManageOrganization((request as any).organizationId, orgId)) {
+      return NextResponse.json(
+        { error: 'Organization not found' },
+        { status: 404 }
+      );
+    }
+
+    const organization = await organizationService.get(orgId);
+    const usage = await organizationService.getUsage(orgId);
+
+    return NextResponse.json({ organization: serializeOrganization(organization, usage) });
+
+  } catch (error) {
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Organization fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Rename, suspend or reactivate an organization, or change its feature
+// switches and quotas. Platform admins only.
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ orgId: string }> }
+) {
+  try {
+    await connectDB();
+
+    if ((request as any).organizationId) {
+      return NextResponse.json(
+        { error: 'Only platform admins can change organizations' },
+        { status: 403 }
+      );
+    }
+
+    const { orgId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateOrganizationSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const organization = await organizationService.update(orgId, (request as any).user?.userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Organization updated',
+      organization: serializeOrganization(organization),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update organization error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,115 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createOrganizationSchema } from '@/lib/database/schemas/organization';
+import { OrganizationStatus } from '@/lib/database/models/organization';
+import { organizationService, serializeOrganization, OrganizationError } from '@/lib/organizations';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const STATUSES: OrganizationStatus[] = ['active', 'suspended'];
+
+// Organizations, newest first. Org admins only see their own.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminOrganizationId = (request as any).organizationId;
+    if (adminOrganizationId) {
+      const organization = await organizationService.get(adminOrganizationId);
+      return NextResponse.json({
+        organizations: [serializeOrganization(organization)],
+        pagination: { page: 1, limit: 1, total: 1 },
+      });
+    }
+
+    const searchParams = request.nextUrl.searchParams;
+    const page = Math.max(parseInt(searchParams.get('page') || '1', 10) || 1, 1);
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+    const status = searchParams.get('status') as OrganizationStatus | null;
+
+    const { organizations, total } = await organizationService.list(
+      { status: status && STATUSES.includes(status) ? status : undefined },
+      limit,
+      (page - 1) * limit
+    );
+
+    return NextResponse.json({
+      organizations: organizations.map(organization => serializeOrganization(organization)),
+      pagination: { page, limit, total },
+    });
+
+  } catch (error) {
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Organizations fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create an organization. Platform admins only.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    if ((request as any).organizationId) {
+      return NextResponse.json(
+        { error: 'Only platform admins can create organizations' },
+        { status: 403 }
+      );
+    }
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = createOrganizationSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const organization = await organizationService.create((request as any).user?.userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Organization created',
+      organization: serializeOrganization(organization),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof OrganizationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create organization error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
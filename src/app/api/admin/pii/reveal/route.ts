@@ -0,0 +1,54 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { revealPiiSchema } from '@/lib/database/schemas/pii';
+import { piiRevealService } from '@/lib/admin/redaction';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Reveal what the admin views redact: users' phone numbers, message content
+// or call rating comments, up to 50 at a time. Takes a justification, which
+// is kept in the audit log with each record revealed.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = revealPiiSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const revealed = await piiRevealService.reveal(adminId, validationResult.data, (request as any).organizationId);
+
+    return NextResponse.json(revealed, {
+      headers: { 'Cache-Control': 'no-store' },
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Reveal personal data error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.REVEAL_PII])];
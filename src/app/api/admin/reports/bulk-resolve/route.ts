@@ -0,0 +1,61 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { bulkResolveReportsSchema } from '@/lib/database/schemas/moderation';
+import { bulkActionService, serializeBulkAction } from '@/lib/moderation/bulk-actions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Resolve or dismiss many reports at once; reports already closed are
+// skipped. Runs in the background; follow it at
+// GET /api/admin/bulk-actions/:bulkActionId.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = bulkResolveReportsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { reportIds, status, resolution } = validationResult.data;
+    const bulkAction = await bulkActionService.request(
+      'resolve_reports',
+      reportIds,
+      { reportStatus: status, resolution },
+      adminId
+    );
+
+    return NextResponse.json(
+      { bulkAction: serializeBulkAction(bulkAction) },
+      { status: 202 }
+    );
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Bulk resolve reports error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_USERS])];
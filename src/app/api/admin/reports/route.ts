@@ -0,0 +1,68 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminReportListSchema } from '@/lib/database/schemas/admin-list';
+import { adminListService } from '@/lib/admin/lists';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Abuse reports, newest first by default. Filters: ?status=, ?type=,
+// ?priority=, ?reporterId=, ?reportedUserId=, ?assignedTo= and ?from= /
+// ?to= on report date. Sort with ?sortBy=createdAt|updatedAt|resolvedAt
+// and ?sortOrder=; page with ?page= and ?limit=. ?format=csv exports
+// every match.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = adminReportListSchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const query = validationResult.data;
+
+    if (query.format === 'csv') {
+      logger.info('Admin list exported', { list: 'reports', mode: query.mode, adminId: (request as any).user?.userId });
+
+      return new NextResponse(adminListService.exportReports(query), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="reports-${new Date().toISOString().slice(0, 10)}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    const { data, pagination } = await adminListService.listReports(query);
+
+    return NextResponse.json({
+      reports: data,
+      pagination,
+      sort: { sortBy: query.sortBy, sortOrder: query.sortOrder },
+    });
+
+  } catch (error) {
+    logger.error('List reports error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
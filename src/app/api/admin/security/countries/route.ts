@@ -0,0 +1,42 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { loginApprovalService } from '@/lib/security/login-approvals';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Sign-ins, failures and flagged sign-ins per country over the last ?days
+// (default 7), with the country rules currently in force
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const days = Math.min(Math.max(parseInt(request.nextUrl.searchParams.get('days') || '7', 10) || 7, 1), 90);
+    const [countries, { security }] = await Promise.all([
+      loginApprovalService.getCountryStats(days),
+      adminConfigService.get(),
+    ]);
+
+    return NextResponse.json({
+      days,
+      countries,
+      rules: {
+        blockedCountries: security.blockedCountries || [],
+        allowedCountries: security.allowedCountries || [],
+      },
+      generatedAt: new Date(),
+    });
+
+  } catch (error) {
+    logger.error('Security country stats error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_ANALYTICS])];
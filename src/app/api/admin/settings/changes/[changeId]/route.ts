@@ -0,0 +1,100 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { configChangeService, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const reviewSchema = z.object({
+  decision: z.enum(['approve', 'reject']),
+  note: z.string().trim().max(1000).optional(),
+});
+
+// A proposed configuration change with its diff
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ changeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { changeId } = await params;
+    const change = await configChangeService.getChange(changeId);
+
+    return NextResponse.json({ change: serializeConfigChange(change) });
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get config change error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Approve (and apply) or reject a pending change
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ changeId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = reviewSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { changeId } = await params;
+    const { decision, note } = validationResult.data;
+    const adminId = (request as any).user?.userId;
+    const { change, snapshot } = await configChangeService.review(changeId, adminId, decision, note);
+
+    return NextResponse.json({
+      message: decision === 'approve' ? 'Change applied' : 'Change rejected',
+      change: serializeConfigChange(change),
+      ...(snapshot && { version: snapshot.version }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Review config change error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
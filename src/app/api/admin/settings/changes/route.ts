@@ -0,0 +1,52 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configChangeService, serializeConfigChange } from '@/lib/config/config-changes';
+import { AdminConfigChangeStatus } from '@/lib/database/models/admin-config-change';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const STATUSES: AdminConfigChangeStatus[] = ['pending', 'applied', 'rejected', 'superseded'];
+
+// Proposed configuration changes newest first; ?status=pending for the
+// ones waiting for review
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+    const status = searchParams.get('status') as AdminConfigChangeStatus | null;
+    if (status && !STATUSES.includes(status)) {
+      return NextResponse.json(
+        { error: `status must be one of ${STATUSES.join(', ')}` },
+        { status: 400 }
+      );
+    }
+
+    const { changes, total } = await configChangeService.listChanges(status || undefined, limit, offset);
+
+    return NextResponse.json({
+      changes: changes.map(serializeConfigChange),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List config changes error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
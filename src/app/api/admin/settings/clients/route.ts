@@ -0,0 +1,126 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { configChangeService, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { parseVersion } from '@/lib/config/client-versions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const version = z.string().max(32).refine(value => !!parseVersion(value), 'Use a version like "2.14.1"');
+
+const perPlatform = <T extends z.ZodTypeAny>(schema: T) => z.object({
+  ios: schema.optional(),
+  android: schema.optional(),
+  web: schema.optional(),
+});
+
+const clientConfigSchema = z.object({
+  minimumVersions: perPlatform(version).optional(),
+  updateUrls: perPlatform(z.string().url()).optional(),
+});
+
+export async function GET() {
+  try {
+    await connectDB();
+    const snapshot = await adminConfigService.get();
+
+    return NextResponse.json({
+      clients: snapshot.clients,
+      version: snapshot.version,
+      updatedAt: snapshot.updatedAt,
+    });
+
+  } catch (error) {
+    logger.error('Client settings fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Replaces each map that is sent; leave a platform out to stop enforcing it
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = clientConfigSchema.safeParse(body.clients ?? body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const values = validationResult.data;
+    const adminId = (request as any).user?.userId;
+    // With the version last read, concurrent edits fail instead of overwriting
+    const expectedVersion = typeof body.version === 'number' ? body.version : undefined;
+    const reason = typeof body.reason === 'string' ? body.reason.slice(0, 500) : undefined;
+    // ?environment=staging changes that environment's overrides instead
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const submission = await configChangeService.submitUpdate('clients', values, adminId, { expectedVersion, reason, environment });
+    if (!submission.applied) {
+      // Held until a second admin approves it
+      return NextResponse.json(
+        { message: 'Change submitted for approval', change: serializeConfigChange(submission.change) },
+        { status: 202 }
+      );
+    }
+    const { snapshot } = submission;
+
+    logger.info('Client settings updated', {
+      userId: adminId,
+      version: snapshot.version,
+      ...(environment && { environment }),
+      minimumVersions: snapshot.clients.minimumVersions,
+    });
+
+    return NextResponse.json({
+      message: 'Settings updated successfully',
+      clients: snapshot.clients,
+      version: snapshot.version,
+      ...(environment && { environment }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Client settings update error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
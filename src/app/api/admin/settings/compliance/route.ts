@@ -0,0 +1,141 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { configChangeService, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const consentTypeSchema = z.object({
+  key: z.string().regex(/^[a-z0-9_-]{1,64}$/, 'Use lowercase letters, digits, "-" and "_"'),
+  title: z.string().trim().min(1).max(120),
+  description: z.string().max(2000).optional(),
+  documentUrl: z.string().url().optional(),
+  version: z.number().int().min(1),
+  mandatory: z.boolean().default(false),
+});
+
+const complianceConfigSchema = z.object({
+  consentTypes: z.array(consentTypeSchema).max(20).refine(
+    types => new Set(types.map(type => type.key)).size === types.length,
+    'Consent keys must be unique'
+  ).optional(),
+});
+
+export async function GET() {
+  try {
+    await connectDB();
+    const snapshot = await adminConfigService.get();
+
+    return NextResponse.json({
+      compliance: snapshot.compliance,
+      version: snapshot.version,
+      updatedAt: snapshot.updatedAt,
+    });
+
+  } catch (error) {
+    logger.error('Compliance settings fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Replaces the consent list. Raising a type's version asks every user to
+// accept it again; versions can't go down.
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = complianceConfigSchema.safeParse(body.compliance ?? body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const values = validationResult.data;
+    const current = (await adminConfigService.get()).compliance.consentTypes || [];
+    const downgraded = (values.consentTypes || []).find(type => {
+      const existing = current.find(existingType => existingType.key === type.key);
+      return existing && type.version < existing.version;
+    });
+    if (downgraded) {
+      return NextResponse.json(
+        { error: `Version of ${downgraded.key} can't be lowered` },
+        { status: 400 }
+      );
+    }
+
+    const adminId = (request as any).user?.userId;
+    // With the version last read, concurrent edits fail instead of overwriting
+    const expectedVersion = typeof body.version === 'number' ? body.version : undefined;
+    const reason = typeof body.reason === 'string' ? body.reason.slice(0, 500) : undefined;
+    // ?environment=staging changes that environment's overrides instead
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const submission = await configChangeService.submitUpdate('compliance', values, adminId, { expectedVersion, reason, environment });
+    if (!submission.applied) {
+      // Held until a second admin approves it
+      return NextResponse.json(
+        { message: 'Change submitted for approval', change: serializeConfigChange(submission.change) },
+        { status: 202 }
+      );
+    }
+    const { snapshot } = submission;
+
+    logger.info('Compliance settings updated', {
+      userId: adminId,
+      version: snapshot.version,
+      ...(environment && { environment }),
+      consentTypes: (snapshot.compliance.consentTypes || []).map(type => `${type.key}@${type.version}`),
+    });
+
+    return NextResponse.json({
+      message: 'Settings updated successfully',
+      compliance: snapshot.compliance,
+      version: snapshot.version,
+      ...(environment && { environment }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Compliance settings update error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,45 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configEnvironmentService } from '@/lib/config/config-environments';
+import { ConfigChangeError } from '@/lib/config/config-changes';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Settings that differ between two environments, e.g.
+// ?from=staging&to=production; "base" is the configuration without overlay
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const from = searchParams.get('from') || 'base';
+    const to = searchParams.get('to');
+    if (!to) {
+      return NextResponse.json(
+        { error: 'to is required' },
+        { status: 400 }
+      );
+    }
+
+    return NextResponse.json(await configEnvironmentService.diff(from, to));
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Config diff error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,40 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configEnvironmentService } from '@/lib/config/config-environments';
+import { ConfigChangeError } from '@/lib/config/config-changes';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// An environment's overrides and the configuration it runs with. Overrides
+// are changed through the settings endpoints with ?environment=.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ environment: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { environment } = await params;
+
+    return NextResponse.json(await configEnvironmentService.get(environment));
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get config environment error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,26 @@
+import { NextResponse } from 'next/server';
+import { configEnvironmentService } from '@/lib/config/config-environments';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The base configuration and the environment overlays on top of it
+export async function GET() {
+  try {
+    await connectDB();
+
+    return NextResponse.json(await configEnvironmentService.list());
+
+  } catch (error) {
+    logger.error('List config environments error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
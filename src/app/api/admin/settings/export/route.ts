@@ -0,0 +1,54 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configEnvironmentService } from '@/lib/config/config-environments';
+import { ConfigChangeError } from '@/lib/config/config-changes';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Download an environment's configuration as YAML, for import elsewhere.
+// ?environment= picks the overlay (base by default); ?effective=true
+// exports everything it resolves to instead of its overrides only.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const environment = searchParams.get('environment') || 'base';
+    const effective = searchParams.get('effective') === 'true';
+
+    const yaml = await configEnvironmentService.export(environment, effective);
+
+    logger.info('Admin configuration exported', {
+      environment,
+      effective,
+      adminId: (request as any).user?.userId,
+    });
+
+    return new NextResponse(yaml, {
+      headers: {
+        'Content-Type': 'application/yaml; charset=utf-8',
+        'Content-Disposition': `attachment; filename="config-${environment}-${new Date().toISOString().slice(0, 10)}.yaml"`,
+        'Cache-Control': 'no-store',
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Config export error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,122 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { configChangeService, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const featureConfigSchema = z.object({
+  publicFileLinks: z.boolean().optional(),
+  publicLinkMaxDays: z.number().int().min(0).max(365).optional(), // 0 = no limit
+  guestLinks: z.boolean().optional(),
+});
+
+export async function GET() {
+  try {
+    await connectDB();
+    const snapshot = await adminConfigService.get();
+
+    return NextResponse.json({
+      features: snapshot.features,
+      version: snapshot.version,
+      updatedAt: snapshot.updatedAt,
+      effective: {
+        publicFileLinks: snapshot.features.publicFileLinks !== false,
+        publicLinkMaxDays: snapshot.features.publicLinkMaxDays || null,
+        guestLinks: snapshot.features.guestLinks !== false,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Feature settings fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = featureConfigSchema.safeParse(body.features ?? body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const values = validationResult.data;
+    const adminId = (request as any).user?.userId;
+    // With the version last read, concurrent edits fail instead of overwriting
+    const expectedVersion = typeof body.version === 'number' ? body.version : undefined;
+    const reason = typeof body.reason === 'string' ? body.reason.slice(0, 500) : undefined;
+    // ?environment=staging changes that environment's overrides instead
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const submission = await configChangeService.submitUpdate('features', values, adminId, { expectedVersion, reason, environment });
+    if (!submission.applied) {
+      // Held until a second admin approves it
+      return NextResponse.json(
+        { message: 'Change submitted for approval', change: serializeConfigChange(submission.change) },
+        { status: 202 }
+      );
+    }
+    const { snapshot } = submission;
+
+    logger.info('Feature settings updated', {
+      userId: adminId,
+      version: snapshot.version,
+      ...(environment && { environment }),
+      fields: Object.keys(values),
+    });
+
+    return NextResponse.json({
+      message: 'Settings updated successfully',
+      features: snapshot.features,
+      version: snapshot.version,
+      ...(environment && { environment }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Feature settings update error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,130 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { configChangeService, serializeConfigRevision, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { PayloadTooLargeError, payloadTooLargeResponse, readOptionalJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const rollbackSchema = z.object({
+  version: z.number().int().min(0).optional(), // current version last read
+  reason: z.string().trim().max(500).optional(),
+});
+
+function parseVersion(value: string): number | null {
+  const version = Number(value);
+  return Number.isInteger(version) && version >= 0 ? version : null;
+}
+
+// The whole configuration (or with ?environment=, that overlay) as it
+// stood at a version, secrets masked
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ version: string }> }
+) {
+  try {
+    await connectDB();
+
+    const version = parseVersion((await params).version);
+    if (version === null) {
+      return NextResponse.json({ error: 'Version not found' }, { status: 404 });
+    }
+
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const revision = await configChangeService.getRevision(version, environment);
+
+    return NextResponse.json({ revision: serializeConfigRevision(revision) });
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get config revision error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Roll the configuration back to this version, as a new version. Needs a
+// second admin's approval unless done by a super admin.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ version: string }> }
+) {
+  try {
+    await connectDB();
+
+    const body = await readOptionalJsonWithLimit(request);
+    const validationResult = rollbackSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const version = parseVersion((await params).version);
+    if (version === null) {
+      return NextResponse.json({ error: 'Version not found' }, { status: 404 });
+    }
+
+    const adminId = (request as any).user?.userId;
+    const { version: expectedVersion, reason } = validationResult.data;
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const submission = await configChangeService.submitRollback(version, adminId, { expectedVersion, reason, environment });
+    if (!submission.applied) {
+      return NextResponse.json(
+        { message: 'Rollback submitted for approval', change: serializeConfigChange(submission.change) },
+        { status: 202 }
+      );
+    }
+
+    return NextResponse.json({
+      message: 'Configuration rolled back',
+      version: submission.snapshot.version,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Config rollback error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
@@ -0,0 +1,51 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configChangeService, serializeConfigRevision, ConfigChangeError } from '@/lib/config/config-changes';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Configuration versions newest first: who changed which section, and who
+// approved it. ?environment=staging for the versions of that overlay.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const environment = searchParams.get('environment') || undefined;
+
+    const { revisions, total } = await configChangeService.listRevisions(environment, limit, offset);
+
+    return NextResponse.json({
+      revisions: revisions.map(serializeConfigRevision),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List config history error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
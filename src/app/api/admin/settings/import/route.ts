@@ -0,0 +1,88 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { configEnvironmentService } from '@/lib/config/config-environments';
+import { serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Import a YAML export (the request body) into an environment: fields in
+// the file are set, others are left alone; null clears an overlay's
+// override. ?environment= picks the target (base by default), ?dryRun=true
+// only returns the diff and ?version= is the target version last read.
+// Like any change, it needs a second admin unless done by a super admin.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const searchParams = request.nextUrl.searchParams;
+    const environment = searchParams.get('environment') || undefined;
+    const dryRun = searchParams.get('dryRun') === 'true';
+    const version = searchParams.get('version');
+    const expectedVersion = version !== null && /^\d+$/.test(version) ? Number(version) : undefined;
+    const reason = searchParams.get('reason')?.slice(0, 500) || undefined;
+    const adminId = (request as any).user?.userId;
+
+    const result = await configEnvironmentService.import(await request.text(), adminId, {
+      environment,
+      expectedVersion,
+      reason,
+      dryRun,
+    });
+
+    if ('issues' in result) {
+      return NextResponse.json(
+        { error: 'Validation failed', details: result.issues },
+        { status: 400 }
+      );
+    }
+
+    if (!result.submission) {
+      return NextResponse.json({ dryRun: true, diff: result.diff });
+    }
+
+    if (!result.submission.applied) {
+      return NextResponse.json(
+        { message: 'Import submitted for approval', change: serializeConfigChange(result.submission.change) },
+        { status: 202 }
+      );
+    }
+
+    logger.info('Admin configuration imported', {
+      userId: adminId,
+      environment: environment || 'base',
+      version: result.submission.snapshot.version,
+    });
+
+    return NextResponse.json({
+      message: 'Configuration imported',
+      version: result.submission.snapshot.version,
+    });
+
+  } catch (error) {
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Config import error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
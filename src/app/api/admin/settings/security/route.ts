@@ -0,0 +1,132 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { z } from 'zod';
+import { adminConfigService, maskSecrets } from '@/lib/config/admin-config';
+import { configChangeService, serializeConfigChange, ConfigChangeError } from '@/lib/config/config-changes';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { challengeService } from '@/lib/security/challenge';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+const countryCodes = z.array(
+  z.string().trim().toUpperCase().regex(/^[A-Z]{2}$/, 'Use ISO 3166-1 alpha-2 country codes')
+).max(250).transform(codes => [...new Set(codes)]);
+
+const securityConfigSchema = z.object({
+  challengeProvider: z.enum(['none', 'hcaptcha', 'turnstile', 'pow']).optional(),
+  challengeIpThreshold: z.number().int().min(0).max(1000).optional(),
+  challengePowDifficulty: z.number().int().min(8).max(32).optional(),
+  blockedCountries: countryCodes.optional(),
+  allowedCountries: countryCodes.optional(), // empty lets every country in
+  challengeSiteKey: z.string().trim().max(200).nullable().optional(), // null falls back to the environment
+  // Stored encrypted and only ever shown masked; sending the mask back
+  // leaves the stored key as it is
+  challengeSecretKey: z.string().trim().max(200).nullable().optional()
+    .transform(value => value?.startsWith('********') ? undefined : value),
+});
+
+export async function GET() {
+  try {
+    await connectDB();
+    const snapshot = await adminConfigService.get();
+
+    return NextResponse.json({
+      security: maskSecrets('security', snapshot.security),
+      version: snapshot.version,
+      updatedAt: snapshot.updatedAt,
+      effective: {
+        challenge: await challengeService.getSettings(),
+      },
+    });
+
+  } catch (error) {
+    logger.error('Security settings fetch error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+    const validationResult = securityConfigSchema.safeParse(body.security ?? body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const values = validationResult.data;
+    const adminId = (request as any).user?.userId;
+    // With the version last read, concurrent edits fail instead of overwriting
+    const expectedVersion = typeof body.version === 'number' ? body.version : undefined;
+    const reason = typeof body.reason === 'string' ? body.reason.slice(0, 500) : undefined;
+    // ?environment=staging changes that environment's overrides instead
+    const environment = request.nextUrl.searchParams.get('environment') || undefined;
+    const submission = await configChangeService.submitUpdate('security', values, adminId, { expectedVersion, reason, environment });
+    if (!submission.applied) {
+      // Held until a second admin approves it
+      return NextResponse.json(
+        { message: 'Change submitted for approval', change: serializeConfigChange(submission.change) },
+        { status: 202 }
+      );
+    }
+    const { snapshot } = submission;
+
+    logger.info('Security settings updated', {
+      userId: adminId,
+      version: snapshot.version,
+      ...(environment && { environment }),
+      fields: Object.keys(values),
+    });
+
+    return NextResponse.json({
+      message: 'Settings updated successfully',
+      security: maskSecrets('security', snapshot.security),
+      version: snapshot.version,
+      ...(environment && { environment }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ConfigChangeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Security settings update error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_SYSTEM])];
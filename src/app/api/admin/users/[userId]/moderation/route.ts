@@ -0,0 +1,44 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { moderationService, serializeModerationAction, ModerationError } from '@/lib/moderation/actions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Every ban and restriction a user has had, newest first, with counts of
+// their messages withheld by moderation
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const actions = await moderationService.getHistory(userId);
+    const withheldMessages = await moderationService.getWithheldCounts(userId);
+
+    return NextResponse.json({
+      actions: actions.map(serializeModerationAction),
+      withheldMessages,
+    });
+
+  } catch (error) {
+    if (error instanceof ModerationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get moderation history error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
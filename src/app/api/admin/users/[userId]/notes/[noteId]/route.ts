@@ -0,0 +1,93 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateAdminNoteSchema } from '@/lib/database/schemas/moderation';
+import { caseService, serializeAdminNote, CaseError } from '@/lib/moderation/cases';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Pin or unpin a note, or (its author only) change the text
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string; noteId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId, noteId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateAdminNoteSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const note = await caseService.updateNote(userId, noteId, validationResult.data, adminId);
+
+    return NextResponse.json({ note: serializeAdminNote(note) });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update admin note error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Delete a note; only its author can
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string; noteId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId, noteId } = await params;
+    const adminId = (request as any).user?.userId;
+
+    await caseService.deleteNote(userId, noteId, adminId);
+
+    return NextResponse.json({ message: 'Note deleted' });
+
+  } catch (error) {
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Delete admin note error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
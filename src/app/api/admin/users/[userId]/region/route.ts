@@ -0,0 +1,102 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { changeDataRegionSchema } from '@/lib/database/schemas/user';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { dataResidencyService, serializeRegionMigration, DataResidencyError } from '@/lib/compliance/data-residency';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The data region a user's files are kept in and their region migrations
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    const migrations = await dataResidencyService.getMigrations(userId);
+
+    return NextResponse.json({
+      enabled: dataResidencyService.isEnabled(),
+      region: dataResidencyService.resolveRegion(user),
+      assigned: !!user.dataRegion, // false when derived from the phone number
+      migrations: migrations.map(serializeRegionMigration),
+    });
+
+  } catch (error) {
+    logger.error('Get user data region error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Move a user to another data region. Their new uploads go there right
+// away; existing files are copied over in the background.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = changeDataRegionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const migration = await dataResidencyService.requestMigration('user', userId, validationResult.data.region, adminId);
+
+    return NextResponse.json({
+      message: 'Region migration queued',
+      migration: serializeRegionMigration(migration),
+    }, { status: 202 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof DataResidencyError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Change user data region error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_USERS])];
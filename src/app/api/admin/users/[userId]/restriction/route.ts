@@ -0,0 +1,117 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { restrictUserSchema, liftModerationActionSchema } from '@/lib/database/schemas/moderation';
+import { moderationService, serializeModerationAction, ModerationError } from '@/lib/moderation/actions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit, readOptionalJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Put a user in restricted mode: their messages are shown only to themselves
+// (mode "shadow") or held for review (mode "review"), they can't call and are
+// hidden from search. Replaces any restriction in force.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = restrictUserSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const action = await moderationService.restrict(userId, validationResult.data, adminId);
+
+    return NextResponse.json({
+      message: 'User restricted',
+      action: serializeModerationAction(action),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ModerationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Restrict user error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Lift a user's restriction ahead of its expiry. Messages sent while
+// restricted stay withheld; held ones remain in the review queue.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readOptionalJsonWithLimit(request);
+
+    const validationResult = liftModerationActionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    await moderationService.unrestrict(userId, validationResult.data.reason, adminId);
+
+    return NextResponse.json({ message: 'Restriction lifted' });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ModerationError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Lift restriction error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.BAN_USERS])];
@@ -0,0 +1,131 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { retentionOverrideSchema } from '@/lib/database/schemas/user';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { messageRetentionService, serializeRetentionPolicy, MessageRetentionError } from '@/lib/compliance/message-retention';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A user's message retention period, their own and any override
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      retention: serializeRetentionPolicy(user, messageRetentionService.getPolicy(user)),
+    });
+
+  } catch (error) {
+    logger.error('Get user message retention error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Override the user's own period. days: null exempts the account from purges.
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = retentionOverrideSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { days, reason } = validationResult.data;
+    const user = await messageRetentionService.setOverride(userId, adminId, days, reason);
+
+    return NextResponse.json({
+      message: 'Retention override set',
+      retention: serializeRetentionPolicy(user, messageRetentionService.getPolicy(user)),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof MessageRetentionError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Set retention override error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Drop the override; the user's own period applies again
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const adminId = (request as any).user?.userId;
+    const user = await messageRetentionService.clearOverride(userId, adminId);
+
+    return NextResponse.json({
+      message: 'Retention override cleared',
+      retention: serializeRetentionPolicy(user, messageRetentionService.getPolicy(user)),
+    });
+
+  } catch (error) {
+    if (error instanceof MessageRetentionError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Clear retention override error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.MANAGE_USERS])];
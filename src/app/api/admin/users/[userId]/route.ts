@@ -0,0 +1,41 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { caseService, CaseError } from '@/lib/moderation/cases';
+import { getPiiPolicy } from '@/lib/admin/redaction';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A user as staff see them: the account, staff tags and notes, support
+// cases, reports about them, moderation history and the admin audit trail
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { userId } = await params;
+    const detail = await caseService.getUserDetail(userId, getPiiPolicy((request as any).user?.permissions));
+
+    return NextResponse.json(detail);
+
+  } catch (error) {
+    if (error instanceof CaseError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get admin user detail error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,69 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { bulkUserActionSchema } from '@/lib/database/schemas/moderation';
+import { bulkActionService, serializeBulkAction } from '@/lib/moderation/bulk-actions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Ban or restrict many users at once: { action: 'ban' | 'restrict', userIds,
+// reason, ... } with the fields of a single ban or restriction. A ban with
+// durationHours is a suspension. Runs in the background; follow it at
+// GET /api/admin/bulk-actions/:bulkActionId.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const adminId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = bulkUserActionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const input = validationResult.data;
+    const bulkAction = await bulkActionService.request(
+      input.action === 'ban' ? 'ban_users' : 'restrict_users',
+      input.userIds,
+      {
+        reason: input.reason,
+        note: input.note,
+        durationHours: input.durationHours,
+        mode: input.action === 'restrict' ? input.mode : undefined,
+        ...(input.reportId && { reportId: new Types.ObjectId(input.reportId) }),
+      },
+      adminId
+    );
+
+    return NextResponse.json(
+      { bulkAction: serializeBulkAction(bulkAction) },
+      { status: 202 }
+    );
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Bulk user action error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.BAN_USERS])];
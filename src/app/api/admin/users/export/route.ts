@@ -0,0 +1,56 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminUserListSchema } from '@/lib/database/schemas/admin-list';
+import { adminListService } from '@/lib/admin/lists';
+import { getPiiPolicy } from '@/lib/admin/redaction';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// CSV export of the user list, taking the same filters and sort as
+// GET /api/admin/users
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = adminUserListSchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const query = validationResult.data;
+
+    logger.info('Admin list exported', { list: 'users', mode: query.mode, adminId: (request as any).user?.userId });
+
+    return new NextResponse(adminListService.exportUsers(query, getPiiPolicy((request as any).user?.permissions)), {
+      headers: {
+        'Content-Type': 'text/csv; charset=utf-8',
+        'Content-Disposition': `attachment; filename="users-${new Date().toISOString().slice(0, 10)}.csv"`,
+        'Cache-Control': 'no-store',
+      },
+    });
+
+  } catch (error) {
+    logger.error('Export users error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,75 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminUserListSchema } from '@/lib/database/schemas/admin-list';
+import { adminListService } from '@/lib/admin/lists';
+import { getPiiPolicy } from '@/lib/admin/redaction';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { Permission } from '@/lib/security/permissions';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Users, newest first by default. Filters: ?search= (prefix of display
+// name, username or phone number), ?banned=, ?restricted=, ?verified=,
+// ?online=, ?bridged= (true|false), ?countryCode=, ?dataRegion=,
+// ?platform=, ?tag= (staff tag), ?organizationId= and ?from= / ?to= on
+// signup date; org admins only ever see their own organization's. Sort
+// with ?sortBy=createdAt|lastSeen|displayName and ?sortOrder=; page with
+// ?page= and ?limit=. ?format=csv exports every match.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const params = Object.fromEntries(
+      [...request.nextUrl.searchParams].filter(([, value]) => value !== '')
+    );
+    const validationResult = adminUserListSchema.safeParse(params);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const query = validationResult.data;
+    const adminOrganizationId = (request as any).organizationId;
+    if (adminOrganizationId) {
+      query.organizationId = adminOrganizationId;
+    }
+
+    if (query.format === 'csv') {
+      logger.info('Admin list exported', { list: 'users', mode: query.mode, adminId: (request as any).user?.userId });
+
+      return new NextResponse(adminListService.exportUsers(query, getPiiPolicy((request as any).user?.permissions)), {
+        headers: {
+          'Content-Type': 'text/csv; charset=utf-8',
+          'Content-Disposition': `attachment; filename="users-${new Date().toISOString().slice(0, 10)}.csv"`,
+          'Cache-Control': 'no-store',
+        },
+      });
+    }
+
+    const { data, pagination } = await adminListService.listUsers(query, getPiiPolicy((request as any).user?.permissions));
+
+    return NextResponse.json({
+      users: data,
+      pagination,
+      sort: { sortBy: query.sortBy, sortOrder: query.sortOrder },
+    });
+
+  } catch (error) {
+    logger.error('List users error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply admin authentication
+export const middleware = [authMiddleware.authenticateAdmin([Permission.VIEW_USERS])];
@@ -0,0 +1,63 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { archiveAccessService, serializeArchivedMessage, ArchiveAccessError } from '@/lib/compliance/archive-access';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Read-only view of the chat an archive access token was issued for,
+// newest first. Takes the token as a bearer token instead of a session;
+// every read is audit-logged.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const authorization = request.headers.get('authorization');
+    const secret = authorization?.startsWith('Bearer ') ? authorization.slice(7).trim() : '';
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(Math.max(parseInt(searchParams.get('limit') || '50', 10) || 50, 1), 200);
+    const beforeParam = searchParams.get('before');
+    const before = beforeParam ? new Date(beforeParam) : undefined;
+    if (before && isNaN(before.getTime())) {
+      return NextResponse.json(
+        { error: 'Invalid before date' },
+        { status: 400 }
+      );
+    }
+
+    const { token, chat, messages, hasMore } = await archiveAccessService.readMessages(secret, {
+      before,
+      limit,
+      ip: request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip') || undefined,
+    });
+
+    return NextResponse.json({
+      chat: {
+        id: chat._id.toString(),
+        type: chat.type,
+        name: chat.groupInfo?.name,
+      },
+      window: { from: token.messagesFrom, to: token.messagesTo },
+      expiresAt: token.expiresAt,
+      messages: messages.map(serializeArchivedMessage),
+      hasMore,
+      nextBefore: hasMore ? messages[messages.length - 1].createdAt : null,
+    }, {
+      headers: { 'Cache-Control': 'no-store' },
+    });
+
+  } catch (error) {
+    if (error instanceof ArchiveAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Archive read error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,33 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { brandingService } from '@/lib/organizations/branding';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Branding for clients to theme themselves with, found by ?organization=
+// (ID or slug) or else by the custom domain the request came in on.
+// Unknown organizations get the default branding. Public, no auth.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const organization = request.nextUrl.searchParams.get('organization');
+    const host = request.headers.get('x-forwarded-host') || request.headers.get('host');
+
+    const branding = await brandingService.resolve({ organization, host });
+
+    return NextResponse.json({ branding }, {
+      headers: {
+        'Cache-Control': 'public, max-age=300',
+        'Vary': 'Host, X-Forwarded-Host',
+      },
+    });
+
+  } catch (error) {
+    logger.error('Branding endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
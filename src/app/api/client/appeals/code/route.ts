@@ -0,0 +1,66 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { loginSchema } from '@/lib/database/schemas/auth';
+import { appealService, AppealError } from '@/lib/moderation/appeals';
+import { DataSanitizer } from '@/lib/security/sanitization';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Send a verification code to a suspended account so its owner can appeal.
+// The response is the same whether or not the number is suspended.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = loginSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { phoneNumber, countryCode } = validationResult.data;
+    const result = await appealService.requestCode(DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode));
+    if (result.cooldownUntil) {
+      return NextResponse.json(
+        { error: 'Please wait before requesting another code', cooldownUntil: result.cooldownUntil },
+        { status: 429 }
+      );
+    }
+
+    return NextResponse.json({
+      message: 'If this account is suspended, a verification code has been sent',
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Appeal code request error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply rate limiting
+export const middleware = [authMiddleware.otpRateLimit()];
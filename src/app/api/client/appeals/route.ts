@@ -0,0 +1,82 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { submitAppealSchema } from '@/lib/database/schemas/moderation';
+import { appealService, serializeAppeal, AppealError } from '@/lib/moderation/appeals';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The suspension and the appeal against it. Authenticated with the appeal
+// token from /api/client/appeals/verify, since suspended users can't sign in.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const user = await appealService.authenticate(request.headers.get('authorization'));
+
+    return NextResponse.json(await appealService.getStatus(user));
+
+  } catch (error) {
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get appeal error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Appeal the suspension. Only one appeal per suspension.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const user = await appealService.authenticate(request.headers.get('authorization'));
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = submitAppealSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const appeal = await appealService.submit(user, validationResult.data.text);
+
+    return NextResponse.json({
+      message: 'Appeal submitted',
+      appeal: serializeAppeal(appeal),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Submit appeal error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
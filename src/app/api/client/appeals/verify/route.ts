@@ -0,0 +1,65 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { verifyOTPSchema } from '@/lib/database/schemas/auth';
+import { appealService, AppealError } from '@/lib/moderation/appeals';
+import { DataSanitizer } from '@/lib/security/sanitization';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Exchange the code for an appeal token, sent as a bearer token to
+// /api/client/appeals. Also returns the suspension and any appeal against it.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = verifyOTPSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { phoneNumber, countryCode, otp } = validationResult.data;
+    const { token, expiresAt, user } = await appealService.verifyCode(
+      DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode),
+      otp
+    );
+
+    return NextResponse.json({
+      appealToken: token,
+      expiresAt,
+      ...await appealService.getStatus(user),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof AppealError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Appeal code verification error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply rate limiting
+export const middleware = [authMiddleware.otpRateLimit()];
@@ -0,0 +1,32 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { challengeService, ChallengeEndpoint } from '@/lib/security/challenge';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const ENDPOINTS: ChallengeEndpoint[] = ['register', 'resend-otp'];
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const endpoint = request.nextUrl.searchParams.get('endpoint') as ChallengeEndpoint;
+    if (!ENDPOINTS.includes(endpoint)) {
+      return NextResponse.json(
+        { error: 'Invalid endpoint' },
+        { status: 400 }
+      );
+    }
+
+    const challenge = await challengeService.describe(request, endpoint);
+
+    return NextResponse.json({ challenge });
+
+  } catch (error) {
+    logger.error('Challenge endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,65 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { joinAsGuestSchema } from '@/lib/database/schemas/group';
+import { guestAccessService, serializeGuest, GuestAccessError } from '@/lib/auth/guest-access';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Join a group through a guest link with just a display name. Returns a
+// guest session that stops working when the guest's time is up.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = joinAsGuestSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { user, group, tokens } = await guestAccessService.join(validationResult.data);
+
+    return NextResponse.json({
+      message: 'Joined as guest',
+      user: serializeGuest(user),
+      groupId: group._id.toString(),
+      tokens: {
+        accessToken: tokens.accessToken,
+        refreshToken: tokens.refreshToken,
+        expiresIn: tokens.expiresIn,
+      },
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof GuestAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Guest join error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply rate limiting
+export const middleware = [authMiddleware.authRateLimit()];
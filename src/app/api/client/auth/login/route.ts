@@ -5,15 +5,27 @@ import { otpService } from '@/lib/auth/otp';
 import { DataSanitizer } from '@/lib/security/sanitization';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
+import { geoIpService } from '@/lib/security/geoip';
+import { ERROR_CODES } from '@/lib/utils/constants';
 import connectDB from '@/lib/database/mongodb';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
 
 export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
     
+    // Country rules from the security settings
+    const { location } = await geoIpService.locateRequest(request);
+    if (await geoIpService.isBlocked(location)) {
+      return NextResponse.json(
+        { error: 'Sign-in is not available in your region', code: ERROR_CODES.REGION_BLOCKED },
+        { status: 403 }
+      );
+    }
+
     // Validate request body
     const validationResult = loginSchema.safeParse(body);
     if (!validationResult.success) {
@@ -29,8 +41,8 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    const { phoneNumber } = validationResult.data;
-    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber);
+    const { phoneNumber, countryCode } = validationResult.data;
+    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode);
     
     const userRepository = new UserRepository();
 
@@ -76,12 +88,16 @@ export async function POST(request: NextRequest) {
       otpResult = await otpService.sendEmailOTP(
         user.email,
         user.displayName,
-        'login'
+        'login',
+        undefined,
+        user.language
       );
     } else {
       otpResult = await otpService.sendSMSOTP(
         sanitizedPhoneNumber,
-        'login'
+        'login',
+        undefined,
+        user.language
       );
     }
 
@@ -128,6 +144,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Login endpoint error', error);
     analyticsService.trackError(error as Error );
     
@@ -1,6 +1,7 @@
 import { NextRequest, NextResponse } from 'next/server';
 import { UserRepository } from '@/lib/database/repositories/user';
 import { jwtService } from '@/lib/auth/jwt';
+import { PayloadTooLargeError, payloadTooLargeResponse, readOptionalJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
@@ -33,7 +34,7 @@ export async function POST(request: NextRequest) {
     const { userId, deviceId, jti } = tokenInfo.payload;
     
     // Get logout type from body
-    const body = await request.json().catch(() => ({}));
+    const body = await readOptionalJsonWithLimit(request);
     const { logoutType = 'current' } = body; // 'current', 'device', 'all'
 
     const userRepository = new UserRepository();
@@ -81,6 +82,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Logout endpoint error', error);
     analyticsService.trackError(error as Error );
     
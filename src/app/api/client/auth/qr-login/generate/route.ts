@@ -33,6 +33,7 @@ export async function GET(request: NextRequest) {
     return NextResponse.json({
       qrId: qrResult.qrId,
       qrCode: qrResult.qrCode,
+      pollToken: qrResult.pollToken, // send as qrPollToken to the socket, or X-QR-Poll-Token when polling
       expiresAt: qrResult.expiresAt,
       checkUrl: qrResult.checkUrl,
     });
@@ -14,8 +14,9 @@ export async function GET(request: NextRequest) {
       );
     }
 
-    // Get QR status
-    const status = await qrAuthService.getQRStatus(qrId);
+    // Get QR status; with the poll token, a confirmed login includes the tokens
+    const pollToken = request.headers.get('x-qr-poll-token') || undefined;
+    const status = await qrAuthService.getQRStatus(qrId, pollToken);
 
     if (!status) {
       return NextResponse.json(
@@ -28,6 +29,7 @@ export async function GET(request: NextRequest) {
       status: status.status,
       userInfo: status.userInfo,
       expiresAt: status.expiresAt,
+      ...(status.tokens && { deviceId: status.deviceId, tokens: status.tokens }),
     });
 
   } catch (error) {
@@ -1,5 +1,6 @@
 import { NextRequest, NextResponse } from 'next/server';
 import { qrAuthService } from '@/lib/auth/qr-auth';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
@@ -9,7 +10,7 @@ export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
     const { qrText, action = 'scan' } = body; // action: 'scan', 'confirm', 'reject'
 
     if (!qrText) {
@@ -44,7 +45,8 @@ export async function POST(request: NextRequest) {
         
       case 'confirm':
         const { qrId } = body;
-        const deviceId = body.deviceId || 'mobile-app';
+        // The approving phone, recorded as the one that linked the desktop
+        const deviceId = (request as any).user?.deviceId || body.deviceId || 'mobile-app';
         
         result = await qrAuthService.confirmQRLogin(qrId, userId, deviceId);
         
@@ -96,6 +98,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('QR verify endpoint error', error);
     analyticsService.trackError(error as Error);
     
@@ -106,5 +111,6 @@ export async function POST(request: NextRequest) {
   }
 }
 
-// Apply authentication middleware
-export const middleware = [authMiddleware.authenticate()];
+// Apply authentication middleware (not from sessions still waiting for
+// sign-in approval, which can't link devices)
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
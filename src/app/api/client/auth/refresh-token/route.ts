@@ -2,6 +2,8 @@ import { NextRequest, NextResponse } from 'next/server';
 import { UserRepository } from '@/lib/database/repositories/user';
 import { refreshTokenSchema } from '@/lib/database/schemas/auth';
 import { jwtService } from '@/lib/auth/jwt';
+import { isGuestExpired } from '@/lib/auth/guest-access';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
 import connectDB from '@/lib/database/mongodb';
@@ -10,7 +12,7 @@ export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
     
     // Validate request body
     const validationResult = refreshTokenSchema.safeParse(body);
@@ -72,6 +74,15 @@ export async function POST(request: NextRequest) {
       );
     }
 
+    // Guests can't stay on past the end of their access
+    if (isGuestExpired(user)) {
+      await jwtService.invalidateAllUserTokens(userId);
+      return NextResponse.json(
+        { error: 'Your guest access has ended' },
+        { status: 403 }
+      );
+    }
+
     // Generate new token pair
     const newTokens = await jwtService.refreshTokens(refreshToken, user);
     if (!newTokens) {
@@ -102,6 +113,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Token refresh endpoint error', error);
     analyticsService.trackError(error as Error);
     
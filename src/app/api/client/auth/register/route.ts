@@ -3,9 +3,14 @@ import { UserRepository } from '@/lib/database/repositories/user';
 import { registerSchema } from '@/lib/database/schemas/auth';
 import { otpService } from '@/lib/auth/otp';
 import { DataSanitizer } from '@/lib/security/sanitization';
+import { resolveLocale } from '@/lib/i18n';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
+import { challengeService } from '@/lib/security/challenge';
+import { geoIpService } from '@/lib/security/geoip';
+import { ERROR_CODES } from '@/lib/utils/constants';
 import connectDB from '@/lib/database/mongodb';
 import type { OTPSendResult } from '@/lib/auth/otp';
 
@@ -13,7 +18,22 @@ export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
+
+    // Country rules from the security settings
+    const { location } = await geoIpService.locateRequest(request);
+    if (await geoIpService.isBlocked(location)) {
+      return NextResponse.json(
+        { error: 'Registration is not available in your region', code: ERROR_CODES.REGION_BLOCKED },
+        { status: 403 }
+      );
+    }
+
+    // Require a CAPTCHA / proof-of-work from suspicious clients
+    const challenge = await challengeService.enforce(request, 'register', body.challenge);
+    if (!challenge.passed) {
+      return NextResponse.json(challenge.error, { status: challenge.status });
+    }
     
     // Validate request body
     const validationResult = registerSchema.safeParse(body);
@@ -30,11 +50,11 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    const { phoneNumber, email, displayName } = validationResult.data;
+    const { phoneNumber, countryCode, email, displayName } = validationResult.data;
     
     // Sanitize inputs
     const sanitizedData = {
-      phoneNumber: DataSanitizer.sanitizePhoneNumber(phoneNumber),
+      phoneNumber: DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode),
       email: email ? DataSanitizer.sanitizeEmail(email) : undefined,
       displayName: DataSanitizer.sanitizePlainText(displayName),
     };
@@ -67,6 +87,9 @@ export async function POST(request: NextRequest) {
       }
     }
 
+    // New users get OTPs in their browser/device language
+    const locale = resolveLocale(body.language || request.headers.get('accept-language'));
+
     // Send OTP based on preferred method
     const otpMethod = sanitizedData.email ? 'email' : 'sms';
     let otpResult: OTPSendResult;
@@ -75,12 +98,16 @@ export async function POST(request: NextRequest) {
       otpResult = await otpService.sendEmailOTP(
         sanitizedData.email,
         sanitizedData.displayName,
-        'registration'
+        'registration',
+        undefined,
+        locale
       );
     } else {
       otpResult = await otpService.sendSMSOTP(
         sanitizedData.phoneNumber,
-        'registration'
+        'registration',
+        undefined,
+        locale
       );
     }
 
@@ -126,6 +153,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Registration endpoint error', error);
     analyticsService.trackError(error as Error);
     
@@ -2,9 +2,13 @@ import { NextRequest, NextResponse } from 'next/server';
 import { UserRepository } from '@/lib/database/repositories/user';
 import { otpService } from '@/lib/auth/otp';
 import { DataSanitizer } from '@/lib/security/sanitization';
+import { PhoneUtils } from '@/lib/utils/phone';
+import { resolveLocale } from '@/lib/i18n';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
+import { challengeService } from '@/lib/security/challenge';
 import connectDB from '@/lib/database/mongodb';
 import { IUser } from '@/lib/database/models/user';
 
@@ -12,8 +16,15 @@ export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
-    const { phoneNumber, email, purpose = 'verification' } = body;
+    const body = await readJsonWithLimit(request);
+
+    // Require a CAPTCHA / proof-of-work from suspicious clients
+    const challenge = await challengeService.enforce(request, 'resend-otp', body.challenge);
+    if (!challenge.passed) {
+      return NextResponse.json(challenge.error, { status: challenge.status });
+    }
+    
+    const { phoneNumber, countryCode, email, purpose = 'verification' } = body;
 
     if (!phoneNumber && !email) {
       return NextResponse.json(
@@ -29,7 +40,14 @@ export async function POST(request: NextRequest) {
 
     // Find user and determine method
     if (phoneNumber) {
-      const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber);
+      if (!PhoneUtils.isValid(phoneNumber, countryCode)) {
+        return NextResponse.json(
+          { error: 'Invalid phone number format' },
+          { status: 400 }
+        );
+      }
+
+      const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode);
       user = await userRepository.findByPhoneNumber(sanitizedPhoneNumber);
       identifier = sanitizedPhoneNumber;
       method = 'sms';
@@ -70,17 +88,22 @@ export async function POST(request: NextRequest) {
     // Send OTP
     let otpResult;
     const displayName = user?.displayName || body.displayName || 'User';
+    const locale = user?.language || resolveLocale(body.language || request.headers.get('accept-language'));
 
     if (method === 'email') {
       otpResult = await otpService.sendEmailOTP(
         identifier,
         displayName,
-        purpose as any
+        purpose as any,
+        undefined,
+        locale
       );
     } else {
       otpResult = await otpService.sendSMSOTP(
         identifier,
-        purpose as any
+        purpose as any,
+        undefined,
+        locale
       );
     }
 
@@ -126,6 +149,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Resend OTP endpoint error', error);
     analyticsService.trackError(error as Error );
     
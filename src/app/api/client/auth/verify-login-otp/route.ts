@@ -7,14 +7,19 @@ import { DataSanitizer } from '@/lib/security/sanitization';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
 import { CryptoUtils } from '@/lib/utils/crypto';
+import { readClientInfo } from '@/lib/config/client-versions';
+import { loginApprovalService, serializeLoginApproval } from '@/lib/security/login-approvals';
+import { geoIpService } from '@/lib/security/geoip';
+import { ERROR_CODES } from '@/lib/utils/constants';
 import connectDB from '@/lib/database/mongodb';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
 
 export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
     
     // Validate request body
     const validationResult = verifyOTPSchema.safeParse(body);
@@ -31,8 +36,8 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    const { phoneNumber, otp } = validationResult.data;
-    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber);
+    const { phoneNumber, countryCode, otp } = validationResult.data;
+    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode);
     
     const userRepository = new UserRepository();
 
@@ -53,6 +58,24 @@ export async function POST(request: NextRequest) {
       );
     }
 
+    // Where the attempt comes from, for anomaly checks and the security log
+    const { ip, location } = await geoIpService.locateRequest(request);
+    if (await geoIpService.isBlocked(location)) {
+      return NextResponse.json(
+        { error: 'Sign-in is not available in your region', code: ERROR_CODES.REGION_BLOCKED },
+        { status: 403 }
+      );
+    }
+
+    const headerClient = readClientInfo(request.headers);
+    const attempt = {
+      deviceId: body.deviceId || CryptoUtils.generateUUID(),
+      ip,
+      userAgent: request.headers.get('user-agent'),
+      platform: validationResult.data.client?.platform || headerClient?.platform,
+      location,
+    };
+
     // Verify OTP
     const otpMethod = user.email ? 'email' : 'sms';
     const identifier = otpMethod === 'email' ? `email:${user.email}` : `sms:${sanitizedPhoneNumber}`;
@@ -76,6 +99,12 @@ export async function POST(request: NextRequest) {
         attemptsRemaining: otpResult.attemptsRemaining,
       });
 
+      await loginApprovalService.recordFailedLogin(user, attempt, {
+        method: otpMethod,
+        reason: otpResult.error,
+        attemptsRemaining: otpResult.attemptsRemaining,
+      });
+
       return NextResponse.json(
         {
           error: otpResult.error,
@@ -85,12 +114,24 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    // Generate device ID for this session
-    const deviceId = body.deviceId || CryptoUtils.generateUUID();
+    const { deviceId } = attempt;
+
+    // Sign-ins from a new device or country wait for the user's approval
+    // before sensitive actions; checked before this device is recorded
+    const loginApproval = await loginApprovalService.assessLogin(user, attempt);
 
     // Update user online status
     await userRepository.updateOnlineStatus(user._id, true);
 
+    // Record the app version this device signed in with; the headers are
+    // the fallback for clients that don't send it in the body
+    const client = validationResult.data.client
+      || (headerClient && { platform: headerClient.platform, appVersion: headerClient.version });
+    if (client) {
+      await userRepository.recordClientInfo(user._id, { deviceId, ...client });
+    }
+    await userRepository.recordDeviceLocation(user._id, deviceId, { ip: ip || undefined, ...location });
+
     // Generate JWT tokens
     const tokens = jwtService.generateTokenPair(user, deviceId);
 
@@ -124,9 +165,14 @@ export async function POST(request: NextRequest) {
         expiresIn: tokens.expiresIn,
       },
       deviceId,
+      // Set when sensitive actions wait for approval from another device
+      ...(loginApproval && { loginApproval: serializeLoginApproval(loginApproval) }),
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('Login OTP verification endpoint error', error);
     analyticsService.trackError(error as Error );
     
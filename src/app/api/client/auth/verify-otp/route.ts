@@ -4,18 +4,35 @@ import { verifyOTPSchema } from '@/lib/database/schemas/auth';
 import { otpService } from '@/lib/auth/otp';
 import { jwtService } from '@/lib/auth/jwt';
 import { DataSanitizer } from '@/lib/security/sanitization';
+import { PhoneUtils } from '@/lib/utils/phone';
+import { dataResidencyService } from '@/lib/compliance/data-residency';
+import { resolveLocale } from '@/lib/i18n';
 import { logger } from '@/lib/monitoring/logging';
 import { analyticsService } from '@/lib/monitoring/analytics';
 import { CryptoUtils } from '@/lib/utils/crypto';
+import { readClientInfo } from '@/lib/config/client-versions';
+import { geoIpService } from '@/lib/security/geoip';
+import { loginApprovalService } from '@/lib/security/login-approvals';
+import { ERROR_CODES } from '@/lib/utils/constants';
 import connectDB from '@/lib/database/mongodb';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
 import { authMiddleware } from '@/lib/auth/middleware';
 
 export async function POST(request: NextRequest) {
   try {
     await connectDB();
     
-    const body = await request.json();
+    const body = await readJsonWithLimit(request);
     
+    // Country rules from the security settings
+    const { ip, location } = await geoIpService.locateRequest(request);
+    if (await geoIpService.isBlocked(location)) {
+      return NextResponse.json(
+        { error: 'Registration is not available in your region', code: ERROR_CODES.REGION_BLOCKED },
+        { status: 403 }
+      );
+    }
+
     // Validate request body
     const validationResult = verifyOTPSchema.safeParse(body);
     if (!validationResult.success) {
@@ -31,8 +48,8 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    const { phoneNumber, otp } = validationResult.data;
-    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber);
+    const { phoneNumber, countryCode, otp } = validationResult.data;
+    const sanitizedPhoneNumber = DataSanitizer.sanitizePhoneNumber(phoneNumber, countryCode);
     
     // Get registration data from the request or session
     // In a real implementation, you might store this in Redis during registration
@@ -87,11 +104,15 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    // Create user
+    // Create user, pinned to the data region of their country
+    const countryCode = PhoneUtils.parse(sanitizedPhoneNumber)?.countryCode;
     const user = await userRepository.create({
       phoneNumber: sanitizedPhoneNumber,
+      countryCode,
+      dataRegion: dataResidencyService.resolveRegion({ countryCode }) ?? undefined,
       email: email ? DataSanitizer.sanitizeEmail(email) : undefined,
       displayName: DataSanitizer.sanitizePlainText(displayName),
+      language: resolveLocale(body.language || request.headers.get('accept-language')),
       isVerified: true,
       isOnline: true,
       lastSeen: new Date(),
@@ -103,6 +124,25 @@ export async function POST(request: NextRequest) {
     // Generate JWT tokens
     const tokens = jwtService.generateTokenPair(user, deviceId);
 
+    // Record the app version this device signed in with; the headers are
+    // the fallback for clients that don't send it in the body
+    const headerClient = readClientInfo(request.headers);
+    const client = validationResult.data.client
+      || (headerClient && { platform: headerClient.platform, appVersion: headerClient.version });
+    if (client) {
+      await userRepository.recordClientInfo(user._id, { deviceId, ...client });
+    }
+    await userRepository.recordDeviceLocation(user._id, deviceId, { ip: ip || undefined, ...location });
+
+    // The first sign-in is the baseline later sign-ins are compared with
+    await loginApprovalService.assessLogin(user, {
+      deviceId,
+      ip,
+      userAgent: request.headers.get('user-agent'),
+      platform: client?.platform,
+      location,
+    });
+
     // Track successful registration
     analyticsService.trackUserRegistration(user);
 
@@ -132,6 +172,9 @@ export async function POST(request: NextRequest) {
     });
 
   } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
     logger.error('OTP verification endpoint error', error);
     analyticsService.trackError(error as Error );
     
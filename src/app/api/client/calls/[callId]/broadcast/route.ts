@@ -0,0 +1,138 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { liveBroadcastService, serializeBroadcast, serializeBroadcastForHost } from '@/lib/webrtc/broadcasts';
+import { CallControlError } from '@/lib/webrtc/signaling';
+import { startBroadcastSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The call's live broadcast, null when it isn't being broadcast; the host
+// also gets where to publish and how the RTMP egress is doing
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const broadcast = await liveBroadcastService.getForCall(callId, userId);
+
+    return NextResponse.json({
+      available: liveBroadcastService.isEnabled(),
+      broadcast: !broadcast ? null
+        : broadcast.hostId.toString() === userId ? serializeBroadcastForHost(broadcast)
+        : serializeBroadcast(broadcast),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get call broadcast error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Broadcast the call live; host only. Publish the composited call to
+// ingestUrl over WHIP; viewers open watchUrl.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = startBroadcastSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const broadcast = await liveBroadcastService.startBroadcast(callId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Broadcast started',
+      broadcast: serializeBroadcastForHost(broadcast),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Start call broadcast error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Stop the broadcast; host only. The call goes on.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const broadcast = await liveBroadcastService.stopBroadcast(callId, userId);
+
+    return NextResponse.json({
+      message: 'Broadcast stopped',
+      broadcast: serializeBroadcastForHost(broadcast),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Stop call broadcast error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,102 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { captionService } from '@/lib/webrtc/captions';
+import { CallControlError } from '@/lib/webrtc/signaling';
+import { captionPreferencesSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Your caption settings for the call, and whether captions are running
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const preferences = await captionService.getPreferences(callId, userId);
+
+    return NextResponse.json({
+      available: captionService.isEnabled(),
+      active: captionService.isActive(callId),
+      preferences,
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get caption settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Turn captions on or off, set the language you speak, or opt out of
+// being transcribed
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = captionPreferencesSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const preferences = await captionService.setPreferences(callId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Caption settings updated',
+      active: captionService.isActive(callId),
+      preferences,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update caption settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
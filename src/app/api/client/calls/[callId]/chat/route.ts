@@ -0,0 +1,96 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { callChatService } from '@/lib/webrtc/call-chat';
+import { CallControlError } from '@/lib/webrtc/signaling';
+import { callChatSettingsSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The in-call chat so far, for participants who joined late or reconnected
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const history = await callChatService.getHistory(callId, userId);
+
+    return NextResponse.json(history);
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get call chat error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Host only: post the in-call chat to the chat when the call ends, or not
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = callChatSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    await callChatService.setPostSummary(callId, userId, validationResult.data.postSummary);
+
+    return NextResponse.json({
+      message: 'Call chat settings updated',
+      postSummary: validationResult.data.postSummary,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update call chat settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,78 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Put the call on hold
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setHold(callId, userId, true);
+
+    return NextResponse.json({
+      message: 'Call on hold',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Hold call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Resume a held call
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setHold(callId, userId, false);
+
+    return NextResponse.json({
+      message: 'Call resumed',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Resume call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
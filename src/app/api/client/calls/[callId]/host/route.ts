@@ -0,0 +1,69 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { transferHostSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Transfer the host role to another participant
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = transferHostSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const session = await webrtcSignalingService.transferHost(callId, userId, validationResult.data.userId);
+
+    logger.info('Call host transferred', {
+      callId,
+      previousHostId: userId,
+      hostId: validationResult.data.userId,
+    });
+
+    return NextResponse.json({
+      message: 'Host role transferred',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Transfer call host error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,79 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Make the call listen-only: only the host and participants given the
+// floor can talk (host only)
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setListenOnly(callId, userId, true);
+
+    return NextResponse.json({
+      message: 'Call is listen-only',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Set listen-only error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Let everyone talk again (host only)
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setListenOnly(callId, userId, false);
+
+    return NextResponse.json({
+      message: 'Call is open to all speakers',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Clear listen-only error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
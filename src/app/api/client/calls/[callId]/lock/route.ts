@@ -0,0 +1,78 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Lock the call so nobody new can join (host only)
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setLocked(callId, userId, true);
+
+    return NextResponse.json({
+      message: 'Call locked',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Lock call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Unlock the call (host only)
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.setLocked(callId, userId, false);
+
+    return NextResponse.json({
+      message: 'Call unlocked',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Unlock call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
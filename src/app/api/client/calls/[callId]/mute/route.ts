@@ -0,0 +1,67 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { muteParticipantsSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Mute one participant or, as host, everyone else. Unmuting only
+// applies to yourself.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = muteParticipantsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { userId: targetId, all, muted } = validationResult.data;
+    const session = all
+      ? await webrtcSignalingService.muteAll(callId, userId)
+      : await webrtcSignalingService.setMuted(callId, userId, targetId!, muted);
+
+    return NextResponse.json({
+      message: 'Mute state updated',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Mute call participants error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,45 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Remove a participant from the call (host only)
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string; userId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId, userId: participantId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.removeParticipant(callId, userId, participantId);
+
+    logger.info('Call participant removed', { callId, removedBy: userId, participantId });
+
+    return NextResponse.json({
+      message: 'Participant removed',
+      controls: serializeCallControls(session),
+    });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Remove call participant error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,76 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { callRatingService } from '@/lib/webrtc/call-ratings';
+import { CallControlError } from '@/lib/webrtc/signaling';
+import { callQualitySchema } from '@/lib/database/schemas/call';
+import { readClientInfo } from '@/lib/config/client-versions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Rate a call you were in, 1-5 with optional issue tags, until a day after
+// it ended. Rating again replaces your earlier rating.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = callQualitySchema.omit({ callId: true }).safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const client = readClientInfo(request.headers);
+    const rating = await callRatingService.submit(callId, userId, validationResult.data, {
+      region: request.headers.get('x-client-region') || undefined,
+      platform: client?.platform,
+      appVersion: client?.version,
+    });
+
+    return NextResponse.json({
+      message: 'Call rated',
+      rating: {
+        callId: rating.callId,
+        rating: rating.rating,
+        issues: rating.issues,
+        feedback: rating.feedback,
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Rate call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,61 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { CallRepository } from '@/lib/database/repositories/call';
+import { webrtcSignalingService } from '@/lib/webrtc/signaling';
+import { describeCallEncryption } from '@/lib/webrtc/e2ee';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A call the user takes part in, with its settings
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const call = await new CallRepository().findByCallId(callId);
+    if (!call || !call.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Call not found' },
+        { status: 404 }
+      );
+    }
+
+    const session = webrtcSignalingService.getCallSession(callId);
+
+    return NextResponse.json({
+      call: {
+        callId: call.callId,
+        type: call.type,
+        status: call.status,
+        initiator: call.initiator,
+        participants: call.participants,
+        chatId: call.chatId?.toString(),
+        isGroupCall: call.isGroupCall,
+        startTime: call.startTime,
+        endTime: call.endTime,
+        duration: call.duration,
+        endReason: call.endReason,
+        settings: {
+          locked: call.locked,
+          encryption: describeCallEncryption(call.encrypted ?? false, session?.keyEpoch),
+        },
+      },
+    });
+
+  } catch (error) {
+    logger.error('Get call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,102 @@
+import { NextRequest, NextResponse } from 'next/server';
+import {
+  webrtcSignalingService,
+  serializeCallControls,
+  serializeSpeakerQueue,
+  CallControlError,
+} from '@/lib/webrtc/signaling';
+import { callSpeakerSchema } from '@/lib/database/schemas/call';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Raised hands waiting to speak, first in line first (host only)
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const session = await webrtcSignalingService.getSpeakerQueue(callId, userId);
+
+    return NextResponse.json(serializeSpeakerQueue(session));
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get speaker queue error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Give a participant the floor, or take it back (host only)
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ callId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { callId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = callSpeakerSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { userId: targetId, canSpeak } = validationResult.data;
+    const session = await webrtcSignalingService.setSpeaker(callId, userId, targetId, canSpeak);
+
+    return NextResponse.json({
+      message: canSpeak ? 'Participant can speak' : 'Participant can no longer speak',
+      controls: serializeCallControls(session),
+      speakerQueue: serializeSpeakerQueue(session).queue,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update call speaker error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,42 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { liveBroadcastService, serializeBroadcast } from '@/lib/webrtc/broadcasts';
+import { CallControlError } from '@/lib/webrtc/signaling';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// What a watch link shows: the broadcast and where to play it. Send
+// broadcast:watch over the socket to be counted and hear when it ends.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ watchCode: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { watchCode } = await params;
+    const userId = (request as any).user?.userId;
+
+    const broadcast = await liveBroadcastService.resolve(watchCode, userId);
+
+    return NextResponse.json({ broadcast: serializeBroadcast(broadcast) });
+
+  } catch (error) {
+    if (error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get broadcast error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
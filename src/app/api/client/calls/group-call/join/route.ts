@@ -0,0 +1,68 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webrtcSignalingService, serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { joinCallSchema } from '@/lib/database/schemas/call';
+import { coturnManager } from '@/lib/webrtc/coturn';
+import { resolveMediaConstraints } from '@/lib/webrtc/media-constraints';
+import { isLowDataDevice } from '@/lib/auth/linked-devices';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { LockTimeoutError } from '@/lib/database/locks';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Join an ongoing group call. Refused while the host has the call locked.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = joinCallSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const session = await webrtcSignalingService.joinCall(validationResult.data.callId, userId);
+    const user = await new UserRepository().findById(userId);
+
+    return NextResponse.json({
+      message: 'Joined call',
+      controls: serializeCallControls(session),
+      iceServers: coturnManager.getICEServers(userId),
+      media: resolveMediaConstraints(user, undefined, isLowDataDevice(user, deviceId)),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CallControlError || error instanceof LockTimeoutError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Join call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
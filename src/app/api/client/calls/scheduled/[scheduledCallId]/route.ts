@@ -0,0 +1,150 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { updateScheduledCallSchema } from '@/lib/database/schemas/call';
+import {
+  scheduledCallService,
+  serializeScheduledCall,
+  ScheduledCallError,
+} from '@/lib/webrtc/scheduled-calls';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ scheduledCallId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { scheduledCallId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const scheduledCall = await scheduledCallService.getScheduledCall(scheduledCallId);
+    if (!scheduledCall || !(await new ChatRepository().isParticipant(scheduledCall.chatId, userId))) {
+      return NextResponse.json(
+        { error: 'Scheduled call not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ scheduledCall: serializeScheduledCall(scheduledCall) });
+
+  } catch (error) {
+    logger.error('Get scheduled call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Reschedule or edit a call that hasn't started (organizer or group admin)
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ scheduledCallId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { scheduledCallId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateScheduledCallSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const scheduledCall = await scheduledCallService.getScheduledCall(scheduledCallId);
+    if (!scheduledCall) {
+      return NextResponse.json(
+        { error: 'Scheduled call not found' },
+        { status: 404 }
+      );
+    }
+
+    const { startsAt, ...changes } = validationResult.data;
+    const updated = await scheduledCallService.update(scheduledCall, userId, {
+      ...changes,
+      ...(startsAt && { startsAt: new Date(startsAt) }),
+    });
+
+    return NextResponse.json({
+      message: 'Scheduled call updated',
+      scheduledCall: serializeScheduledCall(updated),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ScheduledCallError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update scheduled call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Cancel a call that hasn't started (organizer or group admin)
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ scheduledCallId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { scheduledCallId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const scheduledCall = await scheduledCallService.getScheduledCall(scheduledCallId);
+    if (!scheduledCall) {
+      return NextResponse.json(
+        { error: 'Scheduled call not found' },
+        { status: 404 }
+      );
+    }
+
+    await scheduledCallService.cancel(scheduledCall, userId);
+
+    return NextResponse.json({ message: 'Scheduled call cancelled' });
+
+  } catch (error) {
+    if (error instanceof ScheduledCallError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Cancel scheduled call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,89 @@
+import { NextRequest, NextResponse } from 'next/server';
+import {
+  scheduledCallService,
+  serializeScheduledCall,
+  ScheduledCallError,
+} from '@/lib/webrtc/scheduled-calls';
+import { serializeCallControls, CallControlError } from '@/lib/webrtc/signaling';
+import { coturnManager } from '@/lib/webrtc/coturn';
+import { resolveMediaConstraints } from '@/lib/webrtc/media-constraints';
+import { isLowDataDevice } from '@/lib/auth/linked-devices';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// What a meeting link points to, for the pre-join screen
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ joinCode: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { joinCode } = await params;
+
+    const scheduledCall = await scheduledCallService.getByJoinCode(joinCode);
+    if (!scheduledCall || scheduledCall.status === 'cancelled') {
+      return NextResponse.json(
+        { error: 'Meeting link is not valid' },
+        { status: 404 }
+      );
+    }
+
+    const { title, type, startsAt, endsAt, status } = serializeScheduledCall(scheduledCall);
+    return NextResponse.json({ scheduledCall: { title, type, startsAt, endsAt, status } });
+
+  } catch (error) {
+    logger.error('Get meeting link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Join through a meeting link. Starts the call if it's about to begin.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ joinCode: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { joinCode } = await params;
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+
+    const { scheduledCall, session } = await scheduledCallService.join(joinCode, userId);
+    const user = await new UserRepository().findById(userId);
+
+    return NextResponse.json({
+      message: 'Joined call',
+      callId: session.callId,
+      scheduledCallId: scheduledCall._id.toString(),
+      controls: serializeCallControls(session),
+      iceServers: coturnManager.getICEServers(userId),
+      media: resolveMediaConstraints(user, undefined, isLowDataDevice(user, deviceId)),
+    });
+
+  } catch (error) {
+    if (error instanceof ScheduledCallError || error instanceof CallControlError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Join meeting link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
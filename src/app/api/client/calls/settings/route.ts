@@ -0,0 +1,121 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { callSettingsSchema } from '@/lib/database/schemas/user';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { resolveMediaConstraints } from '@/lib/webrtc/media-constraints';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+import { IUser } from '@/lib/database/models/user';
+
+async function buildResponse(user: IUser) {
+  const config = (await adminConfigService.get()).calls;
+  const settings = user.toObject().callSettings || {};
+
+  return {
+    settings: {
+      noiseSuppression: settings.noiseSuppression ?? null,
+      echoCancellation: settings.echoCancellation ?? null,
+      autoGainControl: settings.autoGainControl ?? null,
+    },
+    canOverride: config.allowUserOverride ?? true,
+    effective: resolveMediaConstraints(user, config),
+  };
+}
+
+// Your audio processing preferences and what calls will actually use
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json(await buildResponse(user));
+
+  } catch (error) {
+    logger.error('Get call settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = callSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if ((await adminConfigService.get()).calls.allowUserOverride === false) {
+      return NextResponse.json(
+        { error: 'Audio processing is managed by the server' },
+        { status: 403 }
+      );
+    }
+
+    const updates: Record<string, any> = {};
+    const unset: Record<string, 1> = {};
+    Object.entries(validationResult.data).forEach(([field, value]) => {
+      if (value === null) {
+        unset[`callSettings.${field}`] = 1;
+      } else if (value !== undefined) {
+        updates[`callSettings.${field}`] = value;
+      }
+    });
+
+    const user = await new UserRepository().update(userId, {
+      ...updates,
+      ...(Object.keys(unset).length > 0 && { $unset: unset }),
+    } as any);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      message: 'Call settings updated successfully',
+      ...(await buildResponse(user)),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update call settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,119 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { chatAppearanceSchema } from '@/lib/database/schemas/chat';
+import { chatAppearanceService, ChatAppearanceError } from '@/lib/media/chat-appearance';
+import { findParticipantSettings } from '@/lib/communication/notification-preferences';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const chat = await new ChatRepository().findById(chatId);
+    if (!chat || !chat.participants.some(p => p._id.toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      chatId,
+      version: findParticipantSettings(chat, userId)?.version ?? 0,
+      appearance: await chatAppearanceService.get(chat, userId),
+    });
+
+  } catch (error) {
+    logger.error('Get chat appearance error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Set the wallpaper (a built-in name, or an image uploaded through
+// /media/upload/image) and theme color; null clears one
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = chatAppearanceSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const result = await chatAppearanceService.update(chatId, userId, validationResult.data);
+    if (!result) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      message: 'Chat appearance updated successfully',
+      chatId,
+      version: findParticipantSettings(result.chat, userId)?.version ?? 0,
+      appearance: result.appearance,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof ChatAppearanceError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update chat appearance error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,46 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { slashCommandService } from '@/lib/integrations/slash-commands';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Slash commands available in the chat, for autocomplete
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const chat = await new ChatRepository().findById(chatId);
+    if (!chat || !chat.participants.some(p => p._id.toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const commands = await slashCommandService.listForChat(chat);
+
+    return NextResponse.json({
+      commands: commands
+        .filter(command => command.enabled)
+        .map(({ name, description, usage, builtin }) => ({ name, description, usage, builtin })),
+    });
+
+  } catch (error) {
+    logger.error('List chat commands error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
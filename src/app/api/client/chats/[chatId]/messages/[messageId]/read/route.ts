@@ -0,0 +1,70 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { readPositionService } from '@/lib/communication/read-positions';
+import { socketManager } from '@/lib/realtime/socket';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Mark a message read, as message:read does over the socket: the sender
+// gets a read receipt and the user's read position in the chat moves up to
+// it on all their devices
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string; messageId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId, messageId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const chat = await new ChatRepository().findById(chatId);
+    if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const messageRepository = new MessageRepository();
+    const message = Types.ObjectId.isValid(messageId) ? await messageRepository.findById(messageId) : null;
+    if (!message || message.chatId.toString() !== chatId) {
+      return NextResponse.json(
+        { error: 'Message not found' },
+        { status: 404 }
+      );
+    }
+
+    const readCount = await messageRepository.markMultipleAsRead([messageId], userId);
+    if (readCount > 0) {
+      socketManager.emitToChat(chatId, 'message:read:receipt', {
+        messageIds: [messageId],
+        readBy: userId,
+        readAt: new Date(),
+      }, userId);
+    }
+
+    await readPositionService.markRead(userId, [messageId]);
+
+    const updated = await new ChatRepository().findById(chatId);
+
+    return NextResponse.json({
+      message: 'Message marked as read',
+      readPosition: updated ? readPositionService.get(updated, userId) : null,
+    });
+
+  } catch (error) {
+    logger.error('Mark message read error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
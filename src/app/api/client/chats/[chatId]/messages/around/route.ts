@@ -0,0 +1,122 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { WITHHELD_MESSAGE_STATES } from '@/lib/database/models/message';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { customEmojiService } from '@/lib/media/custom-emoji';
+import { personalTokenAllows } from '@/lib/auth/personal-tokens';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { MESSAGE_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Open the chat part way through its history: up to radius messages either
+// side of a message (messageId, e.g. a search hit or pinned message) or of
+// a point in time (date, YYYY-MM-DD for the start of that UTC day, or a
+// full timestamp). Messages come oldest first; target is the message to
+// scroll to, for a date the first one sent at or after it. Page on with
+// before (the messages route) or after the ends of the window.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const user = (request as any).user;
+    const userId = user?.userId;
+
+    const chat = personalTokenAllows(user, 'messages:read', chatId) ? await new ChatRepository().findById(chatId) : null;
+    if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const searchParams = request.nextUrl.searchParams;
+    const messageId = searchParams.get('messageId') || searchParams.get('message_id');
+    const dateParam = searchParams.get('date');
+    const radius = Math.min(
+      Math.max(parseInt(searchParams.get('radius') || '') || MESSAGE_CONSTANTS.DEFAULT_AROUND_RADIUS, 1),
+      MESSAGE_CONSTANTS.MAX_AROUND_RADIUS
+    );
+
+    if (!messageId === !dateParam) {
+      return NextResponse.json(
+        { error: 'Pass either messageId or date' },
+        { status: 400 }
+      );
+    }
+
+    const messageRepository = new MessageRepository();
+    let pivot: Date;
+
+    if (messageId) {
+      const target = Types.ObjectId.isValid(messageId) ? await messageRepository.findById(messageId) : null;
+      if (!target || target.chatId.toString() !== chatId || !isVisible(target, userId)) {
+        return NextResponse.json(
+          { error: 'Message not found' },
+          { status: 404 }
+        );
+      }
+      pivot = target.createdAt;
+    } else {
+      pivot = new Date(/^\d{4}-\d{2}-\d{2}$/.test(dateParam!) ? `${dateParam}T00:00:00.000Z` : dateParam!);
+      if (isNaN(pivot.getTime())) {
+        return NextResponse.json(
+          { error: 'Invalid date' },
+          { status: 400 }
+        );
+      }
+    }
+
+    // One extra each way tells whether there is more. The target itself is
+    // the first message from the pivot on.
+    const [older, newer] = await Promise.all([
+      messageRepository.getChatMessages(chatId, radius + 1, pivot, userId),
+      messageRepository.getChatMessagesFrom(chatId, pivot, radius + 2, userId),
+    ]);
+
+    const targetIndex = messageId
+      ? Math.max(newer.findIndex(message => message._id.toString() === messageId), 0)
+      : 0;
+    const before = older.slice(0, radius).reverse();
+    const after = newer.slice(0, targetIndex + radius + 1);
+    const target = newer[targetIndex] ?? older[0];
+
+    const page = await customEmojiService.attach([...before, ...after]);
+
+    return NextResponse.json({
+      messages: page.map(message => pseudonymService.forViewer(chat, message, userId)),
+      targetId: target?._id.toString() ?? null,
+      hasOlder: older.length > radius,
+      hasNewer: newer.length > after.length,
+    });
+
+  } catch (error) {
+    logger.error('Get messages around error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// The same rules as the chat's message list: not deleted, not deleted for
+// the user, and not withheld unless it's their own
+function isVisible(message: Record<string, any>, userId: string): boolean {
+  const withheld: readonly string[] = WITHHELD_MESSAGE_STATES;
+  const senderId = (message.senderId?._id || message.senderId)?.toString();
+  return !message.isDeleted
+    && !(message.deletedFor || []).some((id: Types.ObjectId) => id.toString() === userId)
+    && (!withheld.includes(message.moderation?.state) || senderId === userId);
+}
+
+// Apply authentication middleware; personal access tokens need messages:read
+export const middleware = [
+  authMiddleware.authenticate({ required: true, personalTokenScopes: ['messages:read'] }),
+];
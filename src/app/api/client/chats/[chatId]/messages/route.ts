@@ -0,0 +1,155 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { sendMessageSchema } from '@/lib/database/schemas/message';
+import { sendChatMessage } from '@/lib/realtime/events/messaging';
+import { TrustLimitError } from '@/lib/moderation/trust';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { customEmojiService } from '@/lib/media/custom-emoji';
+import { socketManager } from '@/lib/realtime/socket';
+import { personalTokenAllows } from '@/lib/auth/personal-tokens';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Get a page of the chat's messages, newest first
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const user = (request as any).user;
+    const userId = user?.userId;
+
+    const chat = personalTokenAllows(user, 'messages:read', chatId) ? await new ChatRepository().findById(chatId) : null;
+    if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '') || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const beforeParam = searchParams.get('before');
+    const before = beforeParam ? new Date(beforeParam) : undefined;
+    if (before && isNaN(before.getTime())) {
+      return NextResponse.json(
+        { error: 'Invalid before timestamp' },
+        { status: 400 }
+      );
+    }
+
+    const messages = await new MessageRepository().getChatMessages(chatId, limit + 1, before, userId);
+
+    // Senders in pseudonymous groups and voters on anonymous polls are only
+    // shown to group admins; custom emoji come with their URLs
+    const page = await customEmojiService.attach(messages.slice(0, limit));
+
+    return NextResponse.json({
+      messages: page.map(message => pseudonymService.forViewer(chat, message, userId)),
+      pagination: {
+        limit,
+        hasMore: messages.length > limit,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Get chat messages error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Send a message to the chat, as over the socket
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const user = (request as any).user;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = sendMessageSchema.safeParse({ ...body, chatId });
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!personalTokenAllows(user, 'messages:send', chatId)) {
+      return NextResponse.json(
+        { error: 'Token is not allowed to send messages to this chat' },
+        { status: 403 }
+      );
+    }
+
+    const io = socketManager.getIO();
+    if (!io) {
+      return NextResponse.json(
+        { error: 'Messaging is not available' },
+        { status: 503 }
+      );
+    }
+
+    const result = await sendChatMessage(io, user.userId, validationResult.data);
+    if (!result) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    // A resend of a message already posted gets it back unchanged
+    return NextResponse.json({
+      message: result.message ?? null,
+      command: result.command,
+      ...(result.duplicate && { duplicate: true }),
+    }, { status: result.duplicate ? 200 : 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof TrustLimitError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Send chat message error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware; personal access tokens are accepted and
+// each handler checks the scope it needs
+export const middleware = [
+  authMiddleware.authenticate({ required: true, personalTokenScopes: ['messages:read', 'messages:send'] }),
+];
@@ -0,0 +1,108 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { MessageRepository } from '@/lib/database/repositories/message';
+import { searchChatMessagesSchema } from '@/lib/database/schemas/message';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { customEmojiService } from '@/lib/media/custom-emoji';
+import { personalTokenAllows } from '@/lib/auth/personal-tokens';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { highlightSnippet } from '@/lib/utils/helpers';
+import connectDB from '@/lib/database/mongodb';
+
+// Search the chat's messages, newest first. Every word of q must appear;
+// each hit comes with a snippet and the offsets of the matches in it, and
+// with context > 0, up to that many messages either side of it so clients
+// can jump straight to the hit. Page with before set to the last hit's
+// createdAt.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const user = (request as any).user;
+    const userId = user?.userId;
+
+    const chat = personalTokenAllows(user, 'messages:read', chatId) ? await new ChatRepository().findById(chatId) : null;
+    if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const validationResult = searchChatMessagesSchema.safeParse(Object.fromEntries(request.nextUrl.searchParams));
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { q, senderId, type, from, to, before, limit, context } = validationResult.data;
+    const terms = [...new Set(q.split(/\s+/).filter(Boolean))];
+
+    const messageRepository = new MessageRepository();
+    const hits = await messageRepository.searchChatMessages(chatId, userId, {
+      terms,
+      senderId,
+      type,
+      from,
+      to,
+      before,
+      revealPseudonyms: pseudonymService.isAdmin(chat, userId),
+    }, limit + 1);
+    const page = hits.slice(0, limit);
+
+    // Messages sent before numbering have no seq, and so no context
+    const contexts = await Promise.all(page.map(async hit => {
+      if (context === 0 || !hit.seq) return null;
+      const around = await messageRepository.getChatMessagesBySeq(chatId, Math.max(hit.seq - context, 1), hit.seq + context, userId);
+      const [earlier, later] = await Promise.all([
+        customEmojiService.attach(around.filter(message => message.seq! < hit.seq!)),
+        customEmojiService.attach(around.filter(message => message.seq! > hit.seq!)),
+      ]);
+      return {
+        before: earlier.map(message => pseudonymService.forViewer(chat, message, userId)),
+        after: later.map(message => pseudonymService.forViewer(chat, message, userId)),
+      };
+    }));
+
+    const messages = await customEmojiService.attach(page);
+
+    return NextResponse.json({
+      results: messages.map((message, index) => ({
+        message: pseudonymService.forViewer(chat, message, userId),
+        ...highlightSnippet(message.content || '', terms),
+        ...(contexts[index] && { context: contexts[index] }),
+      })),
+      pagination: {
+        limit,
+        hasMore: hits.length > limit,
+        nextBefore: hits.length > limit ? page[page.length - 1].createdAt : null,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Search chat messages error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware; personal access tokens need messages:read
+export const middleware = [
+  authMiddleware.authenticate({ required: true, personalTokenScopes: ['messages:read'] }),
+];
@@ -0,0 +1,144 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { chatNotificationSettingsSchema } from '@/lib/database/schemas/chat';
+import {
+  findParticipantSettings,
+  resolveNotificationPreferences,
+} from '@/lib/communication/notification-preferences';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+import { IChat } from '@/lib/database/models/chat';
+import { IUser } from '@/lib/database/models/user';
+
+function buildResponse(chat: IChat, user: IUser) {
+  const settings = findParticipantSettings(chat, user._id);
+
+  return {
+    chatId: chat._id.toString(),
+    version: settings?.version ?? 0,
+    settings: {
+      customNotifications: settings?.customNotifications ?? false,
+      notificationSound: settings?.notificationSound,
+      vibration: settings?.vibration,
+    },
+    effective: resolveNotificationPreferences(user, chat.type === 'group' ? 'group' : 'message', chat),
+  };
+}
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const [chat, user] = await Promise.all([
+      new ChatRepository().findById(chatId),
+      new UserRepository().findById(userId),
+    ]);
+    if (!chat || !user || !chat.participants.some(p => p._id.toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json(buildResponse(chat, user));
+
+  } catch (error) {
+    logger.error('Get chat notification settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = chatNotificationSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { version, ...settings } = validationResult.data;
+    const chatRepository = new ChatRepository();
+    const updated = await chatRepository.updateParticipantSettings(chatId, userId, settings, version);
+    if (!updated) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Chat notification settings updated', {
+      userId,
+      chatId,
+      fields: Object.keys(settings),
+    });
+
+    return NextResponse.json({
+      message: 'Notification settings updated successfully',
+      ...buildResponse(updated, user),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update chat notification settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
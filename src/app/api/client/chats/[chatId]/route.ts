@@ -0,0 +1,63 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { resolveNotificationPreferences } from '@/lib/communication/notification-preferences';
+import { serializeActiveCall } from '@/lib/webrtc/active-calls';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { chatAppearanceService } from '@/lib/media/chat-appearance';
+import { readPositionService } from '@/lib/communication/read-positions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const [chat, user] = await Promise.all([
+      new ChatRepository().findById(chatId),
+      new UserRepository().findById(userId),
+    ]);
+    if (!chat || !user || !chat.participants.some(p => p._id.toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Chat not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      chat: {
+        ...chat.toObject(),
+        ...(chat.lastMessage && { lastMessage: pseudonymService.forViewer(chat, chat.lastMessage, userId) }),
+        activeCall: serializeActiveCall(chat),
+        notificationPreferences: resolveNotificationPreferences(
+          user,
+          chat.type === 'group' ? 'group' : 'message',
+          chat
+        ),
+        appearance: await chatAppearanceService.get(chat, userId),
+        // Where to draw the unread divider, the same on every device
+        ...await readPositionService.getWithFirstUnread(chat, userId),
+      },
+    });
+
+  } catch (error) {
+    logger.error('Get chat endpoint error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,125 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { scheduleCallSchema } from '@/lib/database/schemas/call';
+import {
+  scheduledCallService,
+  serializeScheduledCall,
+  ScheduledCallError,
+} from '@/lib/webrtc/scheduled-calls';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Upcoming and running scheduled calls in the chat, soonest first
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const scheduledCalls = await scheduledCallService.getUpcoming(chatId, userId, limit, offset);
+
+    return NextResponse.json({
+      scheduledCalls: scheduledCalls.map(serializeScheduledCall),
+      pagination: {
+        limit,
+        offset,
+        hasMore: scheduledCalls.length === limit,
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof ScheduledCallError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List scheduled calls error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Schedule a call in the chat. Invites the whole chat unless participantIds
+// narrows it down.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ chatId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { chatId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scheduleCallSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const scheduledCall = await scheduledCallService.create(userId, chatId, {
+      ...validationResult.data,
+      startsAt: new Date(validationResult.data.startsAt),
+    });
+
+    logger.info('Call scheduled', {
+      scheduledCallId: scheduledCall._id.toString(),
+      chatId,
+      userId,
+      startsAt: scheduledCall.startsAt,
+    });
+
+    return NextResponse.json({
+      message: 'Call scheduled',
+      scheduledCall: serializeScheduledCall(scheduledCall),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ScheduledCallError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Schedule call error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,72 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { resolveNotificationPreferences } from '@/lib/communication/notification-preferences';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import { serializeActiveCall } from '@/lib/webrtc/active-calls';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { chatAppearanceService } from '@/lib/media/chat-appearance';
+import { readPositionService } from '@/lib/communication/read-positions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const [chats, user] = await Promise.all([
+      new ChatRepository().getUserChats(userId, limit, offset),
+      new UserRepository().findById(userId),
+    ]);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    const appearances = await chatAppearanceService.resolveMany(chats, userId);
+
+    return NextResponse.json({
+      chats: chats.map(chat => ({
+        ...chat.toObject(),
+        ...(chat.lastMessage && { lastMessage: pseudonymService.forViewer(chat, chat.lastMessage, userId) }),
+        activeCall: serializeActiveCall(chat),
+        notificationPreferences: resolveNotificationPreferences(
+          user,
+          chat.type === 'group' ? 'group' : 'message',
+          chat
+        ),
+        appearance: appearances.get(chat._id.toString()) || {},
+        readPosition: readPositionService.get(chat, userId),
+      })),
+      pagination: {
+        limit,
+        offset,
+        hasMore: chats.length === limit,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Get chats endpoint error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
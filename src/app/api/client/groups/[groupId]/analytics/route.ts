@@ -0,0 +1,42 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { groupAnalyticsService, GroupAnalyticsError } from '@/lib/monitoring/group-analytics';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Engagement over the last four weeks: most reacted messages, most active
+// members, daily volume and peak hours. Group admins only; figures are
+// refreshed in the background, see computedAt.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const analytics = await groupAnalyticsService.get(groupId, (request as any).user?.userId);
+
+    return NextResponse.json({ analytics }, {
+      headers: { 'Cache-Control': 'private, max-age=300' },
+    });
+
+  } catch (error) {
+    if (error instanceof GroupAnalyticsError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Group analytics error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
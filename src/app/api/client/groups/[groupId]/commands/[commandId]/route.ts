@@ -0,0 +1,128 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { SlashCommandRepository } from '@/lib/database/repositories/slash-command';
+import { ISlashCommand } from '@/lib/database/models/slash-command';
+import { updateSlashCommandSchema } from '@/lib/database/schemas/slash-command';
+import { slashCommandService, serializeCommand } from '@/lib/integrations/slash-commands';
+import { permissionService } from '@/lib/security/permissions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Resolve a custom command of the group the caller administers
+async function loadCommand(
+  groupId: string,
+  commandId: string,
+  userId: string
+): Promise<{ command: ISlashCommand } | { response: NextResponse }> {
+  if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+    return {
+      response: NextResponse.json(
+        { error: 'Only group admins can manage commands' },
+        { status: 403 }
+      ),
+    };
+  }
+
+  const command = Types.ObjectId.isValid(commandId)
+    ? await new SlashCommandRepository().findById(commandId)
+    : null;
+  if (!command || command.chatId.toString() !== groupId) {
+    return {
+      response: NextResponse.json(
+        { error: 'Command not found' },
+        { status: 404 }
+      ),
+    };
+  }
+
+  return { command };
+}
+
+// Edit a custom command, enable/disable it or rotate its signing secret
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; commandId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, commandId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateSlashCommandSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const result = await loadCommand(groupId, commandId, userId);
+    if ('response' in result) {
+      return result.response;
+    }
+
+    const updated = await slashCommandService.update(result.command, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Command updated',
+      command: serializeCommand(updated!, updated!.enabled),
+      ...(validationResult.data.rotateSecret && { signingSecret: updated!.signingSecret }),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update slash command error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Remove a custom command
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; commandId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, commandId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const result = await loadCommand(groupId, commandId, userId);
+    if ('response' in result) {
+      return result.response;
+    }
+
+    await slashCommandService.delete(result.command);
+
+    logger.info('Slash command deleted', { userId, groupId, command: result.command.name });
+
+    return NextResponse.json({ message: 'Command deleted' });
+
+  } catch (error) {
+    logger.error('Delete slash command error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,184 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import {
+  createSlashCommandSchema,
+  updateCommandSettingsSchema,
+} from '@/lib/database/schemas/slash-command';
+import {
+  slashCommandService,
+  serializeCommand,
+  SlashCommandError,
+} from '@/lib/integrations/slash-commands';
+import { permissionService } from '@/lib/security/permissions';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+function forbidden() {
+  return NextResponse.json(
+    { error: 'Only group admins can manage commands' },
+    { status: 403 }
+  );
+}
+
+// All built-in and custom commands of the group, including disabled ones
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+      return forbidden();
+    }
+
+    const chat = await new ChatRepository().findById(groupId);
+    const commands = await slashCommandService.listForChat(chat!);
+
+    return NextResponse.json({ commands });
+
+  } catch (error) {
+    logger.error('List group commands error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Register a custom command backed by a bot webhook. The signing secret is
+// only returned here.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createSlashCommandSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+      return forbidden();
+    }
+
+    const command = await slashCommandService.create(groupId, userId, validationResult.data);
+
+    logger.info('Slash command created', { userId, groupId, command: command.name });
+
+    return NextResponse.json({
+      message: 'Command created',
+      command: serializeCommand(command, command.enabled),
+      signingSecret: command.signingSecret,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof SlashCommandError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create slash command error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Turn built-in commands on or off for the group
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateCommandSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+      return forbidden();
+    }
+
+    const { disabled, version } = validationResult.data;
+    const chat = await slashCommandService.setDisabledBuiltins(groupId, disabled, version);
+
+    return NextResponse.json({
+      message: 'Command settings updated',
+      commands: await slashCommandService.listForChat(chat!),
+      version: chat!.version,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+    if (error instanceof SlashCommandError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update command settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,233 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { EmailGatewayRepository } from '@/lib/database/repositories/email-gateway';
+import { provisionEmailGatewaySchema, updateEmailGatewaySchema } from '@/lib/database/schemas/email-gateway';
+import {
+  emailGatewayService,
+  serializeEmailGateway,
+  EmailGatewayError,
+} from '@/lib/communication/email-gateway';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit, readOptionalJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const emailGatewayRepository = new EmailGatewayRepository();
+
+// Only group admins manage the group's address
+async function checkGroupAdmin(groupId: string, userId: string): Promise<NextResponse | null> {
+  const chat = await new ChatRepository().findById(groupId);
+  if (!chat || chat.type !== 'group' || !chat.participants.some(p => p._id.toString() === userId)) {
+    return NextResponse.json(
+      { error: 'Group not found' },
+      { status: 404 }
+    );
+  }
+
+  if (!chat.groupInfo?.admins.some(adminId => adminId.toString() === userId)) {
+    return NextResponse.json(
+      { error: 'Only group admins can manage the email address' },
+      { status: 403 }
+    );
+  }
+
+  return null;
+}
+
+function validationError(errors: { path: (string | number)[]; message: string }[]) {
+  return NextResponse.json(
+    {
+      error: 'Validation failed',
+      details: errors.map(err => ({
+        field: err.path.join('.'),
+        message: err.message,
+      })),
+    },
+    { status: 400 }
+  );
+}
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const denied = await checkGroupAdmin(groupId, userId);
+    if (denied) {
+      return denied;
+    }
+
+    const gateway = await emailGatewayRepository.findGatewayByChat(groupId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'Group has no email address' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ gateway: serializeEmailGateway(gateway) });
+
+  } catch (error) {
+    logger.error('Get group email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create the group's address, or rotate it if one exists
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readOptionalJsonWithLimit(request);
+
+    const validationResult = provisionEmailGatewaySchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    if (!emailGatewayService.isEnabled()) {
+      return NextResponse.json(
+        { error: 'Email gateway is not enabled' },
+        { status: 503 }
+      );
+    }
+
+    const denied = await checkGroupAdmin(groupId, userId);
+    if (denied) {
+      return denied;
+    }
+
+    const gateway = await emailGatewayService.provision('group', groupId, userId, validationResult.data);
+
+    logger.info('Group email gateway provisioned', { userId, groupId, gatewayId: gateway._id.toString() });
+
+    return NextResponse.json({
+      message: 'Email address assigned',
+      gateway: serializeEmailGateway(gateway),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof EmailGatewayError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Provision group email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Pause the address or change who may write to it
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateEmailGatewaySchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const denied = await checkGroupAdmin(groupId, userId);
+    if (denied) {
+      return denied;
+    }
+
+    const gateway = await emailGatewayRepository.findGatewayByChat(groupId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'Group has no email address' },
+        { status: 404 }
+      );
+    }
+
+    const updated = await emailGatewayRepository.updateGateway(gateway._id, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Email address updated',
+      gateway: serializeEmailGateway(updated!),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update group email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const denied = await checkGroupAdmin(groupId, userId);
+    if (denied) {
+      return denied;
+    }
+
+    const gateway = await emailGatewayRepository.findGatewayByChat(groupId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'Group has no email address' },
+        { status: 404 }
+      );
+    }
+
+    await emailGatewayRepository.deleteGateway(gateway._id);
+
+    logger.info('Group email gateway removed', { userId, groupId });
+
+    return NextResponse.json({ message: 'Email address removed' });
+
+  } catch (error) {
+    logger.error('Delete group email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
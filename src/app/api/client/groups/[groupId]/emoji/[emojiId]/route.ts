@@ -0,0 +1,48 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { customEmojiService, CustomEmojiError } from '@/lib/media/custom-emoji';
+import { permissionService } from '@/lib/security/permissions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Remove an emoji from the group. Messages that used it keep showing it.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; emojiId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, emojiId } = await params;
+    const userId = (request as any).user?.userId;
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_emoji'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can manage custom emoji' },
+        { status: 403 }
+      );
+    }
+
+    await customEmojiService.remove(groupId, emojiId, userId);
+
+    return NextResponse.json({ message: 'Emoji removed' });
+
+  } catch (error) {
+    if (error instanceof CustomEmojiError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Remove group emoji error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
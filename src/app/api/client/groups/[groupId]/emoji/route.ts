@@ -0,0 +1,126 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { ChatRepository } from '@/lib/database/repositories/chat';
+import { customEmojiService, serializeGroupEmoji, CustomEmojiError } from '@/lib/media/custom-emoji';
+import { parseMultipartStream, getMultipartBoundary } from '@/lib/media/multipart-stream';
+import {
+  PayloadTooLargeError,
+  checkContentLength,
+  getBodyLimit,
+  limitStream,
+  payloadTooLargeResponse,
+} from '@/lib/security/body-limit';
+import { permissionService } from '@/lib/security/permissions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The group's custom emoji, for members' pickers
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const chat = await new ChatRepository().findById(groupId);
+    if (!chat || chat.type !== 'group' || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Group not found' },
+        { status: 404 }
+      );
+    }
+
+    const emoji = await customEmojiService.list(groupId);
+    const resolved = await customEmojiService.resolve(emoji.map(item => item._id));
+
+    return NextResponse.json({
+      emoji: emoji.map(item => serializeGroupEmoji(item, resolved.get(item._id.toString()))),
+    });
+
+  } catch (error) {
+    logger.error('List group emoji error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Upload an emoji as multipart/form-data: a shortcode field followed by the
+// image (PNG, GIF or WebP). Group admins only.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  const limit = getBodyLimit(request.nextUrl.pathname);
+
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const tooLarge = checkContentLength(request, limit);
+    if (tooLarge) {
+      return tooLarge;
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_emoji'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can manage custom emoji' },
+        { status: 403 }
+      );
+    }
+
+    const boundary = getMultipartBoundary(request.headers.get('content-type'));
+    if (!boundary || !request.body) {
+      return NextResponse.json(
+        { error: 'Expected multipart/form-data body' },
+        { status: 400 }
+      );
+    }
+
+    const { fields, file } = await parseMultipartStream(limitStream(request.body, limit), boundary, request.signal);
+    const chunks: Buffer[] = [];
+    for await (const chunk of file.stream) {
+      chunks.push(chunk as Buffer);
+    }
+
+    const emoji = await customEmojiService.upload(groupId, userId, (fields.shortcode || '').trim(), {
+      buffer: Buffer.concat(chunks),
+      filename: file.filename,
+      mimeType: file.mimeType,
+    });
+    const resolved = await customEmojiService.resolve([emoji._id]);
+
+    return NextResponse.json({
+      message: 'Emoji added',
+      emoji: serializeGroupEmoji(emoji, resolved.get(emoji._id.toString())),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof CustomEmojiError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Upload group emoji error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
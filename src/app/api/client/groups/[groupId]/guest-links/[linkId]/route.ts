@@ -0,0 +1,45 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { guestAccessService, serializeGuestLink, GuestAccessError } from '@/lib/auth/guest-access';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke a guest link and remove the guests who joined through it; group
+// admins only
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; linkId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, linkId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const { link, guestsRemoved } = await guestAccessService.revokeLink(groupId, linkId, userId);
+
+    return NextResponse.json({
+      message: 'Guest link revoked',
+      link: serializeGuestLink(link),
+      guestsRemoved,
+    });
+
+  } catch (error) {
+    if (error instanceof GuestAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke guest link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
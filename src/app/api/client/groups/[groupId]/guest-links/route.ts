@@ -0,0 +1,96 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createGuestLinkSchema } from '@/lib/database/schemas/group';
+import { guestAccessService, serializeGuestLink, GuestAccessError } from '@/lib/auth/guest-access';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Guest links of a group, revoked and used-up ones included; group admins only
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const links = await guestAccessService.listLinks(groupId, userId);
+
+    return NextResponse.json({ links: links.map(serializeGuestLink) });
+
+  } catch (error) {
+    if (error instanceof GuestAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List guest links error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a guest link. People who open it can join the group without an
+// account for guestHours, calling and uploading files only if allowed.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createGuestLinkSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { link } = await guestAccessService.createLink(groupId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Guest link created',
+      link: serializeGuestLink(link),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof GuestAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create guest link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
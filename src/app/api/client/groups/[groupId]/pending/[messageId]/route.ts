@@ -0,0 +1,73 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { reviewGroupMessageSchema } from '@/lib/database/schemas/group';
+import { groupApprovalService, GroupApprovalError } from '@/lib/moderation/group-approvals';
+import { permissionService } from '@/lib/security/permissions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Approve a held message, delivering it to the group, or reject it with an
+// optional reason for its author
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; messageId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, messageId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = reviewGroupMessageSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'moderate'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can review messages' },
+        { status: 403 }
+      );
+    }
+
+    const message = await groupApprovalService.review(groupId, messageId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: validationResult.data.decision === 'approve' ? 'Message approved' : 'Message rejected',
+      messageId: message._id.toString(),
+      state: message.moderation?.state,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof GroupApprovalError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Review group message error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,54 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { groupApprovalService } from '@/lib/moderation/group-approvals';
+import { permissionService } from '@/lib/security/permissions';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Messages of a moderated group waiting for an admin, oldest first
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'moderate'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can review messages' },
+        { status: 403 }
+      );
+    }
+
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { messages, total } = await groupApprovalService.listPending(groupId, limit, offset);
+
+    return NextResponse.json({
+      messages,
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('Get group pending messages error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
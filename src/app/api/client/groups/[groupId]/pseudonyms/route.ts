@@ -0,0 +1,48 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { pseudonymService } from '@/lib/security/pseudonyms';
+import { permissionService } from '@/lib/security/permissions';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Pseudonyms of the group's members and who is behind each, for its admins
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'moderate'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can see who is behind pseudonyms' },
+        { status: 403 }
+      );
+    }
+
+    const pseudonyms = await pseudonymService.list(groupId);
+
+    return NextResponse.json({
+      pseudonyms: pseudonyms.map(pseudonym => ({
+        id: pseudonym._id.toString(),
+        name: pseudonym.name,
+        user: pseudonym.userId,
+        createdAt: pseudonym.createdAt,
+      })),
+    });
+
+  } catch (error) {
+    logger.error('List group pseudonyms error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
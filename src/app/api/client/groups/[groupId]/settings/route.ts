@@ -0,0 +1,120 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { GroupRepository } from '@/lib/database/repositories/group';
+import { groupSettingsSchema } from '@/lib/database/schemas/group';
+import { permissionService } from '@/lib/security/permissions';
+import { VersionConflictError } from '@/lib/database/concurrency';
+import { ERROR_CODES } from '@/lib/utils/constants';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const groupRepository = new GroupRepository();
+
+// Group settings, readable by every member
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const chat = await groupRepository.findById(groupId);
+    if (!chat || chat.type !== 'group' || !chat.participants.some((p: any) => (p._id || p).toString() === userId)) {
+      return NextResponse.json(
+        { error: 'Group not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      settings: chat.groupInfo?.settings,
+      version: chat.version,
+    });
+
+  } catch (error) {
+    logger.error('Get group settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Change who can post, edit info and add members, whether members'
+// messages need an admin's approval and whether they post under pseudonyms
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = groupSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'moderate'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can change group settings' },
+        { status: 403 }
+      );
+    }
+
+    const { version, ...settings } = validationResult.data;
+    const chat = await groupRepository.updateGroupSettings(groupId, settings, version);
+
+    const { socketManager } = await import('@/lib/realtime/socket');
+    socketManager.emitToChat(groupId, 'group:updated', {
+      group: await groupRepository.findById(groupId),
+      updatedBy: userId,
+    });
+
+    logger.info('Group settings updated', { userId, groupId, settings });
+
+    return NextResponse.json({
+      message: 'Group settings updated',
+      settings: chat!.groupInfo?.settings,
+      version: chat!.version,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VersionConflictError) {
+      return NextResponse.json(
+        { error: error.message, code: ERROR_CODES.CONFLICT, currentVersion: error.currentVersion },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update group settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
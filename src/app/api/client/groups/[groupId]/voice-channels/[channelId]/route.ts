@@ -0,0 +1,96 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { updateVoiceChannelSchema } from '@/lib/database/schemas/group';
+import { voiceChannelService, serializeVoiceChannel, VoiceChannelError } from '@/lib/webrtc/voice-channels';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Rename a voice channel or change its push-to-talk and speaker settings;
+// group admins only
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; channelId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, channelId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateVoiceChannelSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { group, channel } = await voiceChannelService.update(groupId, channelId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Voice channel updated',
+      channel: serializeVoiceChannel(channel, group),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VoiceChannelError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update voice channel error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Remove a voice channel, disconnecting whoever is in it; group admins only
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; channelId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, channelId } = await params;
+    const userId = (request as any).user?.userId;
+
+    await voiceChannelService.remove(groupId, channelId, userId);
+
+    return NextResponse.json({ message: 'Voice channel removed' });
+
+  } catch (error) {
+    if (error instanceof VoiceChannelError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Remove voice channel error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,64 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { voiceChannelSpeakerSchema } from '@/lib/database/schemas/group';
+import { voiceChannelService, serializeVoiceChannel, VoiceChannelError } from '@/lib/webrtc/voice-channels';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Let a member talk in a speakers-only channel or take it away (canSpeak),
+// or mute them so only an admin can unmute them (muted); group admins only
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; channelId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, channelId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = voiceChannelSpeakerSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { group, channel } = await voiceChannelService.moderate(groupId, channelId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Speaker updated',
+      channel: serializeVoiceChannel(channel, group),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VoiceChannelError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update voice channel speaker error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
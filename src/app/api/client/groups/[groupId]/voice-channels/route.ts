@@ -0,0 +1,98 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createVoiceChannelSchema } from '@/lib/database/schemas/group';
+import { voiceChannelService, serializeVoiceChannel, VoiceChannelError } from '@/lib/webrtc/voice-channels';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The group's voice channels and who is in each; members only. Joining
+// happens over the socket with voice:join.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const { group, channels } = await voiceChannelService.list(groupId, userId);
+
+    return NextResponse.json({
+      channels: channels.map(channel => serializeVoiceChannel(channel, group)),
+    });
+
+  } catch (error) {
+    if (error instanceof VoiceChannelError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List voice channels error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a voice channel; group admins only
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createVoiceChannelSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { group, channel } = await voiceChannelService.create(groupId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Voice channel created',
+      channel: serializeVoiceChannel(channel, group),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof VoiceChannelError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create voice channel error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,134 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { IncomingWebhookRepository } from '@/lib/database/repositories/incoming-webhook';
+import { IIncomingWebhook } from '@/lib/database/models/incoming-webhook';
+import { updateIncomingWebhookSchema } from '@/lib/database/schemas/incoming-webhook';
+import { incomingWebhookService, serializeIncomingWebhook } from '@/lib/integrations/incoming-webhooks';
+import { permissionService } from '@/lib/security/permissions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Resolve a webhook of the group the caller administers
+async function loadWebhook(
+  groupId: string,
+  webhookId: string,
+  userId: string
+): Promise<{ webhook: IIncomingWebhook } | { response: NextResponse }> {
+  if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+    return {
+      response: NextResponse.json(
+        { error: 'Only group admins can manage webhooks' },
+        { status: 403 }
+      ),
+    };
+  }
+
+  const webhook = Types.ObjectId.isValid(webhookId)
+    ? await new IncomingWebhookRepository().findById(webhookId)
+    : null;
+  if (!webhook || webhook.chatId.toString() !== groupId) {
+    return {
+      response: NextResponse.json(
+        { error: 'Webhook not found' },
+        { status: 404 }
+      ),
+    };
+  }
+
+  return { webhook };
+}
+
+// Rename the webhook, change its default avatar or rate limit
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; webhookId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, webhookId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateIncomingWebhookSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const result = await loadWebhook(groupId, webhookId, userId);
+    if ('response' in result) {
+      return result.response;
+    }
+
+    if (result.webhook.revokedAt) {
+      return NextResponse.json(
+        { error: 'Webhook has been revoked' },
+        { status: 409 }
+      );
+    }
+
+    const updated = await incomingWebhookService.update(result.webhook, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Webhook updated',
+      webhook: serializeIncomingWebhook(updated!),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update incoming webhook error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Revoke the webhook; its URL stops accepting messages
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string; webhookId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId, webhookId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const result = await loadWebhook(groupId, webhookId, userId);
+    if ('response' in result) {
+      return result.response;
+    }
+
+    await incomingWebhookService.revoke(result.webhook, userId);
+
+    logger.info('Incoming webhook revoked', { userId, groupId, webhookId });
+
+    return NextResponse.json({ message: 'Webhook revoked' });
+
+  } catch (error) {
+    logger.error('Revoke incoming webhook error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
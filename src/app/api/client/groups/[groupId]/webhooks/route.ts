@@ -0,0 +1,101 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { IncomingWebhookRepository } from '@/lib/database/repositories/incoming-webhook';
+import { createIncomingWebhookSchema } from '@/lib/database/schemas/incoming-webhook';
+import { incomingWebhookService, serializeIncomingWebhook } from '@/lib/integrations/incoming-webhooks';
+import { permissionService } from '@/lib/security/permissions';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// List the group's incoming webhooks
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can manage webhooks' },
+        { status: 403 }
+      );
+    }
+
+    const includeRevoked = request.nextUrl.searchParams.get('includeRevoked') === 'true';
+    const webhooks = await new IncomingWebhookRepository().findByChat(groupId, includeRevoked);
+
+    return NextResponse.json({ webhooks: webhooks.map(serializeIncomingWebhook) });
+
+  } catch (error) {
+    logger.error('List incoming webhooks error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Mint a new webhook URL. The URL is only returned here.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ groupId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { groupId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createIncomingWebhookSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_integrations'))) {
+      return NextResponse.json(
+        { error: 'Only group admins can manage webhooks' },
+        { status: 403 }
+      );
+    }
+
+    const { webhook, url } = await incomingWebhookService.create(groupId, userId, validationResult.data);
+
+    logger.info('Incoming webhook created', { userId, groupId, webhookId: webhook._id.toString() });
+
+    return NextResponse.json({
+      message: 'Webhook created',
+      webhook: serializeIncomingWebhook(webhook),
+      url,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Create incoming webhook error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,40 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { importService, serializeImportJob } from '@/lib/import';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Get the status and progress of an import job
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ jobId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const { jobId } = await params;
+
+    const job = Types.ObjectId.isValid(jobId) ? await importService.getJob(jobId) : null;
+    if (!job || job.userId.toString() !== userId) {
+      return NextResponse.json(
+        { error: 'Import job not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ job: serializeImportJob(job) });
+
+  } catch (error) {
+    logger.error('Get chat import endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
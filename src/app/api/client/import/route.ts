@@ -0,0 +1,144 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Readable } from 'stream';
+import { z } from 'zod';
+import { importService, serializeImportJob, ImportError } from '@/lib/import';
+import { parseMultipartStream, getMultipartBoundary } from '@/lib/media/multipart-stream';
+import {
+  PayloadTooLargeError,
+  checkContentLength,
+  getBodyLimit,
+  limitStream,
+  payloadTooLargeResponse,
+} from '@/lib/security/body-limit';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+
+const importSchema = z.object({
+  source: z.enum(['whatsapp', 'telegram']).optional(),
+  chatId: z.string().regex(/^[0-9a-fA-F]{24}$/).optional(),
+  selfName: z.string().min(1).max(100).optional(),
+  sourceChatName: z.string().min(1).max(255).optional(),
+  dateFormat: z.enum(['dmy', 'mdy']).optional(),
+});
+
+// Upload a WhatsApp (.txt/.zip) or Telegram (result.json/.zip) chat export.
+// The file part must come after the option fields.
+export async function POST(request: NextRequest) {
+  const limit = getBodyLimit(request.nextUrl.pathname);
+
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+
+    const tooLarge = checkContentLength(request, limit);
+    if (tooLarge) {
+      return tooLarge;
+    }
+
+    const boundary = getMultipartBoundary(request.headers.get('content-type'));
+    if (!boundary || !request.body) {
+      return NextResponse.json(
+        { error: 'Expected multipart/form-data body' },
+        { status: 400 }
+      );
+    }
+
+    const { fields, file } = await parseMultipartStream(limitStream(request.body, limit), boundary, request.signal);
+
+    const validationResult = importSchema.safeParse({
+      source: fields.source || undefined,
+      chatId: fields.chatId || undefined,
+      selfName: fields.selfName || undefined,
+      sourceChatName: fields.sourceChatName || undefined,
+      dateFormat: fields.dateFormat || undefined,
+    });
+    if (!validationResult.success) {
+      file.stream.resume();
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const job = await importService.createJob(userId, {
+      ...validationResult.data,
+      stream: file.stream as Readable,
+      fileName: file.filename,
+      mimeType: file.mimeType,
+    });
+
+    logger.info('Chat import queued', { userId, jobId: job._id.toString(), source: job.source });
+
+    return NextResponse.json({
+      message: 'Import queued',
+      job: serializeImportJob(job),
+    }, { status: 202 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+
+    if (error instanceof ImportError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Chat import endpoint error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// List the user's import jobs
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const jobs = await importService.getUserJobs(userId, limit, offset);
+
+    return NextResponse.json({
+      jobs: jobs.map(serializeImportJob),
+      pagination: {
+        limit,
+        offset,
+        hasMore: jobs.length === limit,
+      },
+    });
+
+  } catch (error) {
+    logger.error('List chat imports endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
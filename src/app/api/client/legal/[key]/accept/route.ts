@@ -0,0 +1,68 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { acceptLegalNoticeSchema } from '@/lib/database/schemas/legal-notice';
+import { legalNoticeService, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Accept the version of the notice the user was shown
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ key: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { key } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = acceptLegalNoticeSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const notices = await legalNoticeService.accept(userId, key, validationResult.data.version, {
+      ip: request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip') || undefined,
+      userAgent: request.headers.get('user-agent') || undefined,
+      deviceId: (request as any).user?.deviceId,
+    });
+
+    return NextResponse.json({
+      message: 'Accepted',
+      notices,
+      pending: notices.some(notice => notice.pending),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Accept legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware; reachable while acceptance is pending
+export const middleware = [authMiddleware.authenticate({ required: true, allowPendingConsents: true })];
@@ -0,0 +1,60 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { legalNoticeService, getVersionInForce, getUpcomingVersion, LegalNoticeError } from '@/lib/compliance/legal-notices';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Text of a notice: the version in force, the upcoming one, or ?version=
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ key: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { key } = await params;
+    const notice = await legalNoticeService.getByKey(key);
+
+    const requested = parseInt(request.nextUrl.searchParams.get('version') || '', 10);
+    const version = requested
+      ? notice.versions.find(existing => existing.version === requested)
+      : getVersionInForce(notice) || getUpcomingVersion(notice);
+    if (!version) {
+      return NextResponse.json(
+        { error: 'Version not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      notice: {
+        key: notice.key,
+        title: notice.title,
+        mandatory: notice.mandatory,
+        version: version.version,
+        content: version.content,
+        summary: version.summary,
+        effectiveAt: version.effectiveAt,
+        inForce: version.effectiveAt <= new Date(),
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof LegalNoticeError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Get legal notice error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware; reachable while acceptance is pending
+export const middleware = [authMiddleware.authenticate({ required: true, allowPendingConsents: true })];
@@ -0,0 +1,41 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { legalNoticeService } from '@/lib/compliance/legal-notices';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Legal notices in force or upcoming and whether the caller accepted them.
+// Anything with pending: true must be accepted before the API unlocks.
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    const notices = await legalNoticeService.getStatus(user);
+
+    return NextResponse.json({
+      notices,
+      pending: notices.some(notice => notice.pending),
+    });
+
+  } catch (error) {
+    logger.error('Get legal notices error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware; reachable while acceptance is pending
+export const middleware = [authMiddleware.authenticate({ required: true, allowPendingConsents: true })];
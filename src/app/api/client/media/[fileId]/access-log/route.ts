@@ -0,0 +1,57 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { mediaSharingService, MediaSharingError } from '@/lib/media/sharing';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Who opened the uploader's file through a share, newest first
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const { entries, total } = await mediaSharingService.getAccessLog(fileId, userId, limit, offset);
+
+    return NextResponse.json({
+      accesses: entries.map(entry => ({
+        user: entry.userId,
+        shareId: entry.grantId.toString(),
+        action: entry.action,
+        at: entry.createdAt,
+      })),
+      total,
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    if (error instanceof MediaSharingError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Media access log error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
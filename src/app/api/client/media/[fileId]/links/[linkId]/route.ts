@@ -0,0 +1,43 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { publicLinkService, serializeMediaLink, PublicLinkError } from '@/lib/media/public-links';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke a public link; uploader only
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string; linkId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId, linkId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const link = await publicLinkService.revoke(fileId, linkId, userId);
+
+    return NextResponse.json({
+      message: 'Link revoked',
+      link: serializeMediaLink(link),
+    });
+
+  } catch (error) {
+    if (error instanceof PublicLinkError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke media link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,96 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createMediaLinkSchema } from '@/lib/database/schemas/media';
+import { publicLinkService, serializeMediaLink, PublicLinkError } from '@/lib/media/public-links';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Public links of a file, revoked and used-up ones included; uploader only
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const links = await publicLinkService.list(fileId, userId);
+
+    return NextResponse.json({ links: links.map(serializeMediaLink) });
+
+  } catch (error) {
+    if (error instanceof PublicLinkError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List media links error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a public link to a file, optionally with a password, an expiry and a
+// download cap. Anyone holding the link can download without an account.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createMediaLinkSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const link = await publicLinkService.create(fileId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Link created',
+      link: serializeMediaLink(link),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof PublicLinkError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create media link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
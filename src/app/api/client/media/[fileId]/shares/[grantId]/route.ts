@@ -0,0 +1,44 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { mediaSharingService, serializeMediaGrant, MediaSharingError } from '@/lib/media/sharing';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke a share. The uploader can revoke any share of their file, others
+// only the ones they made.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string; grantId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId, grantId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const grant = await mediaSharingService.revoke(fileId, grantId, userId);
+
+    return NextResponse.json({
+      message: 'Share revoked',
+      share: serializeMediaGrant(grant),
+    });
+
+  } catch (error) {
+    if (error instanceof MediaSharingError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke media share error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
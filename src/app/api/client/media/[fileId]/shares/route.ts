@@ -0,0 +1,97 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { shareMediaSchema } from '@/lib/database/schemas/media';
+import { mediaSharingService, serializeMediaGrant, MediaSharingError } from '@/lib/media/sharing';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Shares of a file: every share for its uploader, otherwise the ones the
+// user made, revoked and expired ones included
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const grants = await mediaSharingService.listGrants(fileId, userId);
+
+    return NextResponse.json({ shares: grants.map(serializeMediaGrant) });
+
+  } catch (error) {
+    if (error instanceof MediaSharingError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('List media shares error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Share a file into other chats or with specific users without uploading it
+// again. Open to the uploader and members of the chat it was uploaded to.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = shareMediaSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const grants = await mediaSharingService.share(fileId, userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'File shared',
+      shares: grants.map(serializeMediaGrant),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof MediaSharingError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Share media error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
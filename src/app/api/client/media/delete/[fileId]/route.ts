@@ -0,0 +1,46 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { MediaRepository } from '@/lib/database/repositories/media';
+import { mediaUploadService } from '@/lib/media/upload';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const mediaRepository = new MediaRepository();
+
+// Delete one of the user's uploads. The stored file goes too unless other
+// uploads of the same file still use it.
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const media = Types.ObjectId.isValid(fileId) ? await mediaRepository.findById(fileId) : null;
+    if (!media || media.uploadedBy.toString() !== userId) {
+      return NextResponse.json(
+        { error: 'File not found' },
+        { status: 404 }
+      );
+    }
+
+    await mediaUploadService.deleteFile(fileId, userId);
+
+    return NextResponse.json({ message: 'File deleted successfully' });
+
+  } catch (error) {
+    logger.error('Delete media error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
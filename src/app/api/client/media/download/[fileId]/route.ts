@@ -0,0 +1,76 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { MediaRepository } from '@/lib/database/repositories/media';
+import { mediaSharingService } from '@/lib/media/sharing';
+import { storageService } from '@/lib/media/storage';
+import { MEDIA_CACHE_CONTROL, etagMatches } from '@/lib/media/cdn';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const mediaRepository = new MediaRepository();
+
+function clientIp(request: NextRequest): string | undefined {
+  return request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip') || undefined;
+}
+
+// Stream a file. Content never changes for a given file, so it is cached
+// privately and revalidated by its checksum.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const media = Types.ObjectId.isValid(fileId) ? await mediaRepository.findById(fileId) : null;
+    if (!media || !(await mediaSharingService.authorize(media, userId, 'download', clientIp(request)))) {
+      return NextResponse.json(
+        { error: 'File not found' },
+        { status: 404 }
+      );
+    }
+
+    const etag = `"${media.checksumSHA256}"`;
+    const cacheHeaders = {
+      'Cache-Control': MEDIA_CACHE_CONTROL,
+      ETag: etag,
+      'Last-Modified': media.createdAt.toUTCString(),
+    };
+
+    if (etagMatches(request.headers.get('if-none-match'), etag)) {
+      return new NextResponse(null, { status: 304, headers: cacheHeaders });
+    }
+
+    const stream = await storageService.getFileStream(media.filename);
+    if (!stream) {
+      return NextResponse.json(
+        { error: 'File not found' },
+        { status: 404 }
+      );
+    }
+
+    return new NextResponse(stream, {
+      headers: {
+        ...cacheHeaders,
+        'Content-Type': media.mimeType,
+        'Content-Length': media.size.toString(),
+        'Content-Disposition': `attachment; filename*=UTF-8''${encodeURIComponent(media.originalName)}`,
+      },
+    });
+
+  } catch (error) {
+    logger.error('Download media error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
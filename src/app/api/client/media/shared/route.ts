@@ -0,0 +1,57 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { mediaSharingService, serializeMediaGrant } from '@/lib/media/sharing';
+import { IMedia } from '@/lib/database/models/media';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import connectDB from '@/lib/database/mongodb';
+
+// Files others shared with the user or into their chats, newest first
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const grants = await mediaSharingService.listSharedWith(userId, limit, offset);
+
+    return NextResponse.json({
+      // Files deleted since they were shared drop out
+      shares: grants.filter(grant => grant.mediaId).map(grant => {
+        const media = grant.mediaId as unknown as IMedia;
+        return {
+          ...serializeMediaGrant(grant),
+          mediaId: media._id.toString(),
+          media: {
+            id: media._id.toString(),
+            type: media.type,
+            mimeType: media.mimeType,
+            size: media.size,
+            originalName: media.originalName,
+            thumbnailUrl: media.thumbnailUrl,
+            metadata: media.metadata,
+          },
+        };
+      }),
+      limit,
+      offset,
+    });
+
+  } catch (error) {
+    logger.error('List shared media error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
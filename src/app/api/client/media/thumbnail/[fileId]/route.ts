@@ -0,0 +1,111 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Types } from 'mongoose';
+import { MediaRepository } from '@/lib/database/repositories/media';
+import { mediaSharingService } from '@/lib/media/sharing';
+import { storageService } from '@/lib/media/storage';
+import { cdnService, THUMBNAIL_CACHE_CONTROL, etagMatches } from '@/lib/media/cdn';
+import {
+  DEFAULT_THUMBNAIL_SIZE,
+  THUMBNAIL_MIME_TYPES,
+  negotiateThumbnailFormat,
+} from '@/lib/media/thumbnail';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const mediaRepository = new MediaRepository();
+
+function clientIp(request: NextRequest): string | undefined {
+  return request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip') || undefined;
+}
+
+// A file's thumbnail in the requested ?size= (default medium) and the best
+// format the Accept header allows: AVIF, then WebP, then JPEG. Redirects to
+// the CDN when there is one, otherwise streams with long-lived cache headers.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ fileId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { fileId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const media = Types.ObjectId.isValid(fileId) ? await mediaRepository.findById(fileId) : null;
+    if (
+      !media || !media.thumbnailUrl ||
+      !(await mediaSharingService.authorize(media, userId, 'thumbnail', clientIp(request)))
+    ) {
+      return NextResponse.json(
+        { error: 'Thumbnail not found' },
+        { status: 404 }
+      );
+    }
+
+    // Uploaded before thumbnail keys were stored
+    if (!media.thumbnailKey) {
+      return NextResponse.redirect(media.thumbnailUrl);
+    }
+
+    const size = request.nextUrl.searchParams.get('size') || DEFAULT_THUMBNAIL_SIZE;
+    const variants = media.thumbnails?.filter(variant => variant.size === size) || [];
+    if (variants.length === 0 && size !== DEFAULT_THUMBNAIL_SIZE) {
+      return NextResponse.json(
+        { error: 'Thumbnail size not available' },
+        { status: 404 }
+      );
+    }
+
+    // Only a single JPEG was made before variants existed
+    const format = negotiateThumbnailFormat(
+      request.headers.get('accept'),
+      variants.map(variant => variant.format)
+    );
+    const key = variants.find(variant => variant.format === format)?.key || media.thumbnailKey;
+
+    const cdnUrl = cdnService.getUrl(key);
+    if (cdnUrl) {
+      return NextResponse.redirect(cdnUrl, {
+        headers: { 'Cache-Control': 'private, max-age=3600', Vary: 'Accept' },
+      });
+    }
+
+    const etag = `"${media.checksumSHA256}-${size}-${format}"`;
+    const cacheHeaders = {
+      'Cache-Control': THUMBNAIL_CACHE_CONTROL,
+      ETag: etag,
+      Vary: 'Accept',
+    };
+
+    if (etagMatches(request.headers.get('if-none-match'), etag)) {
+      return new NextResponse(null, { status: 304, headers: cacheHeaders });
+    }
+
+    const stream = await storageService.getFileStream(key);
+    if (!stream) {
+      return NextResponse.json(
+        { error: 'Thumbnail not found' },
+        { status: 404 }
+      );
+    }
+
+    return new NextResponse(stream, {
+      headers: {
+        ...cacheHeaders,
+        'Content-Type': THUMBNAIL_MIME_TYPES[format],
+      },
+    });
+
+  } catch (error) {
+    logger.error('Get thumbnail error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
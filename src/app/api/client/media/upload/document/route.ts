@@ -0,0 +1,10 @@
+import { NextRequest } from 'next/server';
+import { handleMediaUpload } from '@/lib/media/upload-handler';
+import { authMiddleware } from '@/lib/auth/middleware';
+
+export async function POST(request: NextRequest) {
+  return handleMediaUpload(request, 'document');
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,124 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { doNotDisturbSchema } from '@/lib/database/schemas/user';
+import { getQuietWindow } from '@/lib/communication/do-not-disturb';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+import { IUser } from '@/lib/database/models/user';
+
+function buildResponse(user: IUser) {
+  const settings = user.toObject().doNotDisturb || {};
+  const window = getQuietWindow(user);
+
+  return {
+    settings: {
+      enabled: settings.enabled ?? false,
+      until: settings.until,
+      schedules: settings.schedules || [],
+      mode: settings.mode || 'digest',
+      allowStarredCalls: settings.allowStarredCalls ?? true,
+    },
+    timezone: user.timezone || 'UTC',
+    active: window.active,
+    activeUntil: window.endsAt,
+    activeSource: window.source,
+  };
+}
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json(buildResponse(user));
+
+  } catch (error) {
+    logger.error('Get Do Not Disturb settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = doNotDisturbSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const updates: Record<string, any> = {};
+    const unset: Record<string, 1> = {};
+    Object.entries(validationResult.data).forEach(([field, value]) => {
+      if (value === null) {
+        unset[`doNotDisturb.${field}`] = 1;
+      } else if (value !== undefined) {
+        updates[`doNotDisturb.${field}`] = field === 'until' ? new Date(value as string) : value;
+      }
+    });
+
+    const user = await new UserRepository().update(userId, {
+      ...updates,
+      ...(Object.keys(unset).length > 0 && { $unset: unset }),
+    } as any);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Do Not Disturb settings updated', {
+      userId,
+      fields: Object.keys(validationResult.data),
+    });
+
+    return NextResponse.json({
+      message: 'Do Not Disturb settings updated successfully',
+      ...buildResponse(user),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update Do Not Disturb settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
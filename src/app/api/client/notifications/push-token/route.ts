@@ -0,0 +1,112 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { registerPushTokenSchema } from '@/lib/database/schemas/user';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+
+// Register the current device's tokens. Sends the full set each time: a
+// token left out is removed from the device.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+    if (!deviceId) {
+      return NextResponse.json(
+        { error: 'Session is not bound to a device' },
+        { status: 400 }
+      );
+    }
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = registerPushTokenSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const user = await new UserRepository().upsertDevice(userId, {
+      deviceId,
+      ...validationResult.data,
+    });
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Push tokens registered', {
+      userId,
+      deviceId,
+      platform: validationResult.data.platform,
+      voip: !!validationResult.data.voipToken,
+    });
+
+    return NextResponse.json({
+      message: 'Push tokens registered successfully',
+      deviceId,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Register push token error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Stop pushes to the current device
+export async function DELETE(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+    if (!deviceId) {
+      return NextResponse.json(
+        { error: 'Session is not bound to a device' },
+        { status: 400 }
+      );
+    }
+
+    await new UserRepository().clearDeviceTokens(userId, deviceId);
+
+    logger.info('Push tokens removed', { userId, deviceId });
+
+    return NextResponse.json({
+      message: 'Push tokens removed successfully',
+    });
+
+  } catch (error) {
+    logger.error('Remove push token error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
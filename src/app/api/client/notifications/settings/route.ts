@@ -0,0 +1,108 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { notificationSettingsSchema } from '@/lib/database/schemas/user';
+import { PUSH_CONSTANTS } from '@/lib/utils/constants';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+import { IUser } from '@/lib/database/models/user';
+
+function serializeSettings(user: IUser) {
+  const { pushToken, ...settings } = user.toObject().notificationSettings || {};
+  return settings;
+}
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      settings: serializeSettings(user),
+      availableSounds: PUSH_CONSTANTS.SOUNDS,
+      vibrationPatterns: Object.keys(PUSH_CONSTANTS.VIBRATION_PATTERNS),
+    });
+
+  } catch (error) {
+    logger.error('Get notification settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = notificationSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const updates: Record<string, any> = {};
+    Object.entries(validationResult.data).forEach(([field, value]) => {
+      if (value !== undefined) {
+        updates[`notificationSettings.${field}`] = value;
+      }
+    });
+
+    const user = await new UserRepository().update(userId, updates as any);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    logger.info('Notification settings updated', {
+      userId,
+      fields: Object.keys(validationResult.data),
+    });
+
+    return NextResponse.json({
+      message: 'Notification settings updated successfully',
+      settings: serializeSettings(user),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update notification settings error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,76 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { reminderService, serializeReminder } from '@/lib/reminders';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ reminderId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { reminderId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const reminder = await reminderService.getReminder(reminderId);
+    if (!reminder || reminder.userId.toString() !== userId) {
+      return NextResponse.json(
+        { error: 'Reminder not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ reminder: serializeReminder(reminder) });
+
+  } catch (error) {
+    logger.error('Get reminder error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Cancel a reminder that hasn't fired yet
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ reminderId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { reminderId } = await params;
+    const userId = (request as any).user?.userId;
+
+    const reminder = await reminderService.getReminder(reminderId);
+    if (!reminder || reminder.userId.toString() !== userId) {
+      return NextResponse.json(
+        { error: 'Reminder not found' },
+        { status: 404 }
+      );
+    }
+
+    if (!(await reminderService.cancel(reminder))) {
+      return NextResponse.json(
+        { error: `Reminder is already ${reminder.status}` },
+        { status: 409 }
+      );
+    }
+
+    return NextResponse.json({ message: 'Reminder cancelled' });
+
+  } catch (error) {
+    logger.error('Cancel reminder error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
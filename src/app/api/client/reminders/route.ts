@@ -0,0 +1,126 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { createReminderSchema, reminderStatusFilterSchema } from '@/lib/database/schemas/reminder';
+import { reminderService, serializeReminder, ReminderError } from '@/lib/reminders';
+import { PAGINATION_CONSTANTS } from '@/lib/utils/constants';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// List the user's reminders (?status=upcoming|delivered|cancelled|failed)
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const searchParams = request.nextUrl.searchParams;
+
+    const statusResult = reminderStatusFilterSchema.safeParse(searchParams.get('status') || 'upcoming');
+    if (!statusResult.success) {
+      return NextResponse.json(
+        { error: 'Invalid status filter' },
+        { status: 400 }
+      );
+    }
+
+    const limit = Math.min(
+      parseInt(searchParams.get('limit') || '', 10) || PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE,
+      PAGINATION_CONSTANTS.MAX_PAGE_SIZE
+    );
+    const offset = Math.max(parseInt(searchParams.get('offset') || '0', 10) || 0, 0);
+
+    const reminders = await reminderService.getUserReminders(userId, statusResult.data, limit, offset);
+
+    return NextResponse.json({
+      reminders: reminders.map(serializeReminder),
+      pagination: {
+        limit,
+        offset,
+        hasMore: reminders.length === limit,
+      },
+    });
+
+  } catch (error) {
+    logger.error('List reminders error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Set a reminder, free-form or on a message
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createReminderSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { text, messageId, dueAt, when } = validationResult.data;
+
+    let due = dueAt ? new Date(dueAt) : null;
+    if (when) {
+      const user = await new UserRepository().findById(userId);
+      due = user && reminderService.resolveWhen(when, user);
+      if (!due) {
+        return NextResponse.json(
+          {
+            error: 'Validation failed',
+            details: [{ field: 'when', message: 'Could not understand the time, try "in 2h" or "tomorrow at 9am"' }],
+          },
+          { status: 400 }
+        );
+      }
+    }
+
+    const reminder = await reminderService.create(userId, {
+      dueAt: due!,
+      text,
+      messageId,
+      source: messageId ? 'message' : 'api',
+    });
+
+    return NextResponse.json({
+      message: 'Reminder set',
+      reminder: serializeReminder(reminder),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ReminderError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create reminder error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
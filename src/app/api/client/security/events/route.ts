@@ -0,0 +1,48 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { securityEventListSchema } from '@/lib/database/schemas/auth';
+import { loginApprovalService, serializeSecurityEvent } from '@/lib/security/login-approvals';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The caller's security log: sign-ins, failed attempts and approvals,
+// newest first
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+
+    const validationResult = securityEventListSchema.safeParse(
+      Object.fromEntries(request.nextUrl.searchParams)
+    );
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { limit, offset, type } = validationResult.data;
+    const events = await loginApprovalService.listEvents(userId, limit, offset, type && [type]);
+
+    return NextResponse.json({ events: events.map(serializeSecurityEvent) });
+
+  } catch (error) {
+    logger.error('List security events error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
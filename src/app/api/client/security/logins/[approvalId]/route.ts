@@ -0,0 +1,69 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { loginApprovalDecisionSchema } from '@/lib/database/schemas/auth';
+import {
+  loginApprovalService,
+  serializeLoginApproval,
+  LoginApprovalError,
+} from '@/lib/security/login-approvals';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Approve or deny a sign-in from another of the user's devices. Denying
+// signs the new device out.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ approvalId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { approvalId } = await params;
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = loginApprovalDecisionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const approval = await loginApprovalService.decide(userId, approvalId, validationResult.data.decision, deviceId);
+
+    return NextResponse.json({
+      message: approval.status === 'approved' ? 'Sign-in approved' : 'Sign-in denied, the device has been signed out',
+      login: serializeLoginApproval(approval),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LoginApprovalError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Answer sign-in approval error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware (only from devices that are approved themselves)
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
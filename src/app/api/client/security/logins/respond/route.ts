@@ -0,0 +1,60 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { loginApprovalTokenSchema } from '@/lib/database/schemas/auth';
+import { loginApprovalService, LoginApprovalError } from '@/lib/security/login-approvals';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Approve or deny a sign-in with the secret from the SMS link, for users
+// without another device at hand
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = loginApprovalTokenSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { token, decision } = validationResult.data;
+    const approval = await loginApprovalService.decideWithToken(token, decision);
+
+    return NextResponse.json({
+      message: approval.status === 'approved' ? 'Sign-in approved' : 'Sign-in denied, the device has been signed out',
+      status: approval.status,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LoginApprovalError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Answer sign-in approval by link error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply rate limiting
+export const middleware = [authMiddleware.authRateLimit()];
@@ -0,0 +1,28 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { loginApprovalService, serializeLoginApproval } from '@/lib/security/login-approvals';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Sign-ins from new devices or locations still waiting for an answer
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const approvals = await loginApprovalService.listPending(userId);
+
+    return NextResponse.json({ logins: approvals.map(serializeLoginApproval) });
+
+  } catch (error) {
+    logger.error('List pending sign-ins error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
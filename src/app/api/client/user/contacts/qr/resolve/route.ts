@@ -0,0 +1,52 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { resolveContactCodeSchema } from '@/lib/database/schemas/user';
+import { contactCardService, ContactCardError } from '@/lib/communication/contact-cards';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Look up a scanned add-contact code or link, and optionally add its owner
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const validationResult = resolveContactCodeSchema.safeParse(await readJsonWithLimit(request));
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { code, addContact } = validationResult.data;
+    const profile = await contactCardService.resolve(code, userId, addContact);
+
+    return NextResponse.json({ profile, added: addContact });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ContactCardError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Resolve contact code error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,56 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { contactCardService, ContactCardError } from '@/lib/communication/contact-cards';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The user's add-contact link and its QR code
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const qr = await contactCardService.getQRCode(userId);
+
+    return NextResponse.json(qr);
+
+  } catch (error) {
+    if (error instanceof ContactCardError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Get contact QR code error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Replace the code, revoking links and QR codes shared before
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const qr = await contactCardService.rotate(userId);
+
+    return NextResponse.json({ message: 'Contact code rotated', ...qr });
+
+  } catch (error) {
+    if (error instanceof ContactCardError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Rotate contact code error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,128 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { starContactSchema } from '@/lib/database/schemas/user';
+import { activityStatusService } from '@/lib/communication/activity-status';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Starred contacts; their calls can ring through Do Not Disturb
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const contacts = await new UserRepository().getStarredContacts(userId);
+
+    return NextResponse.json({
+      contacts: contacts.map(contact => ({
+        id: contact._id.toString(),
+        displayName: contact.displayName,
+        avatar: contact.avatar,
+        phoneNumber: contact.phoneNumber,
+        activityStatus: activityStatusService.forViewer(contact, userId),
+      })),
+    });
+
+  } catch (error) {
+    logger.error('List starred contacts error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+async function parseBody(request: NextRequest) {
+  const validationResult = starContactSchema.safeParse(await readJsonWithLimit(request));
+  if (!validationResult.success) {
+    return {
+      response: NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      ),
+    };
+  }
+  return { data: validationResult.data };
+}
+
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const parsed = await parseBody(request);
+    if ('response' in parsed) {
+      return parsed.response;
+    }
+
+    const contactId = parsed.data.userId;
+    if (contactId === userId) {
+      return NextResponse.json(
+        { error: 'You cannot star yourself' },
+        { status: 400 }
+      );
+    }
+
+    const userRepository = new UserRepository();
+    if (!(await userRepository.findById(contactId))) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    await userRepository.starContact(userId, contactId);
+
+    return NextResponse.json({ message: 'Contact starred' });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Star contact error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const parsed = await parseBody(request);
+    if ('response' in parsed) {
+      return parsed.response;
+    }
+
+    await new UserRepository().unstarContact(userId, parsed.data.userId);
+
+    return NextResponse.json({ message: 'Contact unstarred' });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Unstar contact error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
@@ -0,0 +1,40 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { contactCardService, ContactCardError } from '@/lib/communication/contact-cards';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// A user's shareable profile as a vCard; the caller's own without ?userId=
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const viewerId = (request as any).user?.userId;
+    const userId = request.nextUrl.searchParams.get('userId') || viewerId;
+
+    const { vcard, profile } = await contactCardService.exportVCard(userId, viewerId);
+    const filename = (profile.username || profile.displayName).replace(/[^\w.-]+/g, '_') || 'contact';
+
+    return new NextResponse(vcard, {
+      headers: {
+        'Content-Type': 'text/vcard; charset=utf-8',
+        'Content-Disposition': `attachment; filename="${filename}.vcf"`,
+      },
+    });
+
+  } catch (error) {
+    if (error instanceof ContactCardError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Export vCard error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
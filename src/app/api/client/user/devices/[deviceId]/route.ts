@@ -0,0 +1,100 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { linkedDeviceService, LinkedDeviceError } from '@/lib/auth/linked-devices';
+import { deviceSettingsSchema } from '@/lib/database/schemas/user';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Change a device's settings. lowDataMode trims what the server sends it
+// (no inline media previews, compressed frames, media auto-download hints
+// off) and lowers its call bitrate ceilings; open sockets switch at once.
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ deviceId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { deviceId } = await params;
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = deviceSettingsSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    await linkedDeviceService.setLowDataMode(userId, deviceId, validationResult.data.lowDataMode);
+
+    return NextResponse.json({
+      message: 'Device settings updated',
+      deviceId,
+      lowDataMode: validationResult.data.lowDataMode,
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof LinkedDeviceError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update device settings error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Sign a linked device out; its tokens stop working immediately
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ deviceId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { deviceId } = await params;
+    const userId = (request as any).user?.userId;
+    const currentDeviceId = (request as any).user?.deviceId;
+
+    await linkedDeviceService.revoke(userId, deviceId, currentDeviceId);
+
+    return NextResponse.json({ message: 'Device signed out' });
+
+  } catch (error) {
+    if (error instanceof LinkedDeviceError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke linked device error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware (login sessions only, not tokens, and
+// not ones still waiting for sign-in approval) to both methods
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
@@ -0,0 +1,33 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { linkedDeviceService, serializeDevice, LinkedDeviceError } from '@/lib/auth/linked-devices';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Devices signed in to the account, including desktops linked by QR code
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const deviceId = (request as any).user?.deviceId;
+    const devices = await linkedDeviceService.list(userId);
+
+    return NextResponse.json({ devices: devices.map(device => serializeDevice(device, deviceId)) });
+
+  } catch (error) {
+    if (error instanceof LinkedDeviceError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('List devices error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
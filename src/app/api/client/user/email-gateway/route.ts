@@ -0,0 +1,177 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { EmailGatewayRepository } from '@/lib/database/repositories/email-gateway';
+import { provisionEmailGatewaySchema, updateEmailGatewaySchema } from '@/lib/database/schemas/email-gateway';
+import {
+  emailGatewayService,
+  serializeEmailGateway,
+  EmailGatewayError,
+} from '@/lib/communication/email-gateway';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit, readOptionalJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const emailGatewayRepository = new EmailGatewayRepository();
+
+function validationError(errors: { path: (string | number)[]; message: string }[]) {
+  return NextResponse.json(
+    {
+      error: 'Validation failed',
+      details: errors.map(err => ({
+        field: err.path.join('.'),
+        message: err.message,
+      })),
+    },
+    { status: 400 }
+  );
+}
+
+// Get the user's personal email address
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+
+    const gateway = await emailGatewayRepository.findGatewayByUser(userId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'No email address assigned' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({ gateway: serializeEmailGateway(gateway) });
+
+  } catch (error) {
+    logger.error('Get user email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create the user's address, or rotate it if one exists
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readOptionalJsonWithLimit(request);
+
+    const validationResult = provisionEmailGatewaySchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    if (!emailGatewayService.isEnabled()) {
+      return NextResponse.json(
+        { error: 'Email gateway is not enabled' },
+        { status: 503 }
+      );
+    }
+
+    const gateway = await emailGatewayService.provision('user', userId, userId, validationResult.data);
+
+    logger.info('User email gateway provisioned', { userId, gatewayId: gateway._id.toString() });
+
+    return NextResponse.json({
+      message: 'Email address assigned',
+      gateway: serializeEmailGateway(gateway),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof EmailGatewayError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Provision user email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Pause the address or change who may write to it
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateEmailGatewaySchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const gateway = await emailGatewayRepository.findGatewayByUser(userId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'No email address assigned' },
+        { status: 404 }
+      );
+    }
+
+    const updated = await emailGatewayRepository.updateGateway(gateway._id, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Email address updated',
+      gateway: serializeEmailGateway(updated!),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update user email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+
+    const gateway = await emailGatewayRepository.findGatewayByUser(userId);
+    if (!gateway) {
+      return NextResponse.json(
+        { error: 'No email address assigned' },
+        { status: 404 }
+      );
+    }
+
+    await emailGatewayRepository.deleteGateway(gateway._id);
+
+    logger.info('User email gateway removed', { userId });
+
+    return NextResponse.json({ message: 'Email address removed' });
+
+  } catch (error) {
+    logger.error('Delete user email gateway error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware (not for sessions still waiting for sign-in approval)
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
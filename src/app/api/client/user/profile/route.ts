@@ -0,0 +1,141 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { updateProfileSchema } from '@/lib/database/schemas/user';
+import { DataSanitizer } from '@/lib/security/sanitization';
+import { SUPPORTED_LOCALES } from '@/lib/i18n';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import { analyticsService } from '@/lib/monitoring/analytics';
+import connectDB from '@/lib/database/mongodb';
+import { IUser } from '@/lib/database/models/user';
+import { activityStatusService } from '@/lib/communication/activity-status';
+
+function serializeProfile(user: IUser) {
+  return {
+    id: user._id.toString(),
+    phoneNumber: user.phoneNumber,
+    countryCode: user.countryCode,
+    email: user.email,
+    username: user.username,
+    displayName: user.displayName,
+    avatar: user.avatar,
+    status: user.status,
+    activityStatus: activityStatusService.current(user),
+    language: user.language,
+    timezone: user.timezone,
+    isVerified: user.isVerified,
+    createdAt: user.createdAt,
+  };
+}
+
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const userRepository = new UserRepository();
+    const user = await userRepository.findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      profile: serializeProfile(user),
+      supportedLanguages: SUPPORTED_LOCALES,
+    });
+
+  } catch (error) {
+    logger.error('Get profile endpoint error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = updateProfileSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { displayName, status, username, language, timezone } = validationResult.data;
+    const userRepository = new UserRepository();
+
+    if (username) {
+      const existing = await userRepository.findByUsername(username);
+      if (existing && existing._id.toString() !== userId) {
+        return NextResponse.json(
+          { error: 'Username is already taken' },
+          { status: 409 }
+        );
+      }
+    }
+
+    const updates: Partial<IUser> = {};
+    if (displayName !== undefined) updates.displayName = DataSanitizer.sanitizePlainText(displayName);
+    if (status !== undefined) updates.status = DataSanitizer.sanitizePlainText(status);
+    if (username !== undefined) updates.username = username;
+    if (language !== undefined) updates.language = language;
+    if (timezone !== undefined) updates.timezone = timezone;
+
+    const user = await userRepository.update(userId, updates);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    analyticsService.track('profile_updated', {
+      userId,
+      fields: Object.keys(updates),
+    });
+
+    logger.info('Profile updated', {
+      userId,
+      fields: Object.keys(updates),
+    });
+
+    return NextResponse.json({
+      message: 'Profile updated successfully',
+      profile: serializeProfile(user),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Update profile endpoint error', error);
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
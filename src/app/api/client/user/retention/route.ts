@@ -0,0 +1,91 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { UserRepository } from '@/lib/database/repositories/user';
+import { messageRetentionSchema } from '@/lib/database/schemas/user';
+import { messageRetentionService, serializeRetentionPolicy, MessageRetentionError } from '@/lib/compliance/message-retention';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The caller's message retention period, and download links for what
+// recent purges exported if they asked for exports
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const user = await new UserRepository().findById(userId);
+    if (!user) {
+      return NextResponse.json(
+        { error: 'User not found' },
+        { status: 404 }
+      );
+    }
+
+    return NextResponse.json({
+      retention: serializeRetentionPolicy(user, messageRetentionService.getPolicy(user)),
+      exports: await messageRetentionService.getExportUrls(user),
+    });
+
+  } catch (error) {
+    logger.error('Get message retention error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Keep messages for a number of days (null keeps them indefinitely). An
+// admin override, if any, still wins over what is set here.
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = messageRetentionSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const user = await messageRetentionService.setPolicy(userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Message retention updated',
+      retention: serializeRetentionPolicy(user, messageRetentionService.getPolicy(user)),
+    });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof MessageRetentionError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Update message retention error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
@@ -0,0 +1,93 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { activityStatusSchema } from '@/lib/database/schemas/user';
+import { activityStatusService, ActivityStatusError } from '@/lib/communication/activity-status';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The user's activity status, e.g. "🏢 At work"
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const activityStatus = await activityStatusService.get(userId);
+
+    return NextResponse.json({ activityStatus });
+
+  } catch (error) {
+    logger.error('Get activity status error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function PUT(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const validationResult = activityStatusSchema.safeParse(await readJsonWithLimit(request));
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const activityStatus = await activityStatusService.set(userId, validationResult.data);
+
+    return NextResponse.json({ message: 'Activity status set', activityStatus });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ActivityStatusError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Set activity status error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+export async function DELETE(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    await activityStatusService.clear(userId);
+
+    return NextResponse.json({ message: 'Activity status cleared' });
+
+  } catch (error) {
+    if (error instanceof ActivityStatusError) {
+      return NextResponse.json({ error: error.message }, { status: error.status });
+    }
+
+    logger.error('Clear activity status error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware
+export const middleware = [authMiddleware.authenticate()];
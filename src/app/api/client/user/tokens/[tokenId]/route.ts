@@ -0,0 +1,41 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { personalTokenService, PersonalTokenError } from '@/lib/auth/personal-tokens';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Revoke a token; requests using it fail immediately
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ tokenId: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { tokenId } = await params;
+    const userId = (request as any).user?.userId;
+
+    await personalTokenService.revoke(userId, tokenId);
+
+    return NextResponse.json({ message: 'Token revoked' });
+
+  } catch (error) {
+    if (error instanceof PersonalTokenError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Revoke personal access token error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware (login sessions only, not tokens, and
+// not ones still waiting for sign-in approval)
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
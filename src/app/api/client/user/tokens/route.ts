@@ -0,0 +1,87 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { createPersonalAccessTokenSchema } from '@/lib/database/schemas/personal-access-token';
+import {
+  personalTokenService,
+  serializePersonalAccessToken,
+  PersonalTokenError,
+} from '@/lib/auth/personal-tokens';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// List the caller's personal access tokens
+export async function GET(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const includeRevoked = request.nextUrl.searchParams.get('includeRevoked') === 'true';
+
+    const tokens = await personalTokenService.list(userId, includeRevoked);
+
+    return NextResponse.json({ tokens: tokens.map(serializePersonalAccessToken) });
+
+  } catch (error) {
+    logger.error('List personal access tokens error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Create a token. The token itself is only returned here.
+export async function POST(request: NextRequest) {
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = createPersonalAccessTokenSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { token, secret } = await personalTokenService.create(userId, validationResult.data);
+
+    return NextResponse.json({
+      message: 'Token created. Copy it now, it will not be shown again.',
+      token: serializePersonalAccessToken(token),
+      secret,
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof PersonalTokenError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Create personal access token error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
+
+// Apply authentication middleware (login sessions only, not tokens, and
+// not ones still waiting for sign-in approval)
+export const middleware = [authMiddleware.authenticate({ required: true, sensitive: true })];
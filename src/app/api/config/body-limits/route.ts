@@ -0,0 +1,23 @@
+import { NextResponse } from 'next/server';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { getMaxRequestSize } from '@/lib/security/body-limit';
+import { logger } from '@/lib/monitoring/logging';
+
+// Server-wide request body maximum, polled by the edge middleware
+export async function GET() {
+  try {
+    await adminConfigService.get();
+
+    return NextResponse.json({ maxRequestSize: getMaxRequestSize() }, {
+      headers: { 'Cache-Control': 'no-store' },
+    });
+
+  } catch (error) {
+    logger.error('Body limit config endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,29 @@
+import { NextResponse } from 'next/server';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { ClientVersionPolicy } from '@/lib/config/client-versions';
+import { logger } from '@/lib/monitoring/logging';
+
+// Minimum supported app versions, polled by the edge middleware and read by
+// clients at startup
+export async function GET() {
+  try {
+    const { clients } = await adminConfigService.get();
+
+    const policy: ClientVersionPolicy = {
+      minimumVersions: clients.minimumVersions || {},
+      updateUrls: clients.updateUrls || {},
+    };
+
+    return NextResponse.json(policy, {
+      headers: { 'Cache-Control': 'no-store' },
+    });
+
+  } catch (error) {
+    logger.error('Client version config endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,23 @@
+import { NextResponse } from 'next/server';
+import { corsConfig } from '@/lib/config/cors';
+import { adminConfigService } from '@/lib/config/admin-config';
+import { logger } from '@/lib/monitoring/logging';
+
+// Effective CORS policy, polled by the edge middleware
+export async function GET() {
+  try {
+    await adminConfigService.get();
+
+    return NextResponse.json(corsConfig.getPolicy(), {
+      headers: { 'Cache-Control': 'no-store' },
+    });
+
+  } catch (error) {
+    logger.error('CORS config endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,25 @@
+import { NextResponse } from 'next/server';
+import { buildEventJsonSchema } from '@/lib/realtime/protocol';
+import { logger } from '@/lib/monitoring/logging';
+
+// Socket event JSON Schema, for client SDK generation and version checks
+export async function GET() {
+  try {
+    const schema = buildEventJsonSchema();
+
+    return NextResponse.json(schema, {
+      headers: {
+        'Cache-Control': 'public, max-age=300',
+        'X-Event-Protocol-Version': String(schema.version),
+      },
+    });
+
+  } catch (error) {
+    logger.error('Event schema endpoint error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,78 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { webhookMessageSchema } from '@/lib/database/schemas/incoming-webhook';
+import { incomingWebhookService, IncomingWebhookError } from '@/lib/integrations/incoming-webhooks';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Incoming webhook endpoint. The URL itself is the credential, so there is
+// no authentication middleware.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ webhookId: string; token: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { webhookId, token } = await params;
+
+    // Slack clients may send the JSON as a form field named "payload"
+    const contentType = request.headers.get('content-type') || '';
+    let body: unknown;
+    try {
+      body = contentType.startsWith('application/x-www-form-urlencoded')
+        ? JSON.parse(String((await request.formData()).get('payload') || '{}'))
+        : await readJsonWithLimit(request);
+    } catch (error) {
+      if (error instanceof PayloadTooLargeError) {
+        return payloadTooLargeResponse(error.limit, error.received);
+      }
+      return NextResponse.json(
+        { error: 'Invalid JSON payload' },
+        { status: 400 }
+      );
+    }
+
+    const validationResult = webhookMessageSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const message = await incomingWebhookService.post(webhookId, token, validationResult.data);
+
+    return NextResponse.json({
+      ok: true,
+      messageId: message._id.toString(),
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof IncomingWebhookError) {
+      return NextResponse.json(
+        { ok: false, error: error.message },
+        {
+          status: error.status,
+          headers: error.retryAfter !== undefined ? { 'Retry-After': error.retryAfter.toString() } : undefined,
+        }
+      );
+    }
+
+    logger.error('Incoming webhook endpoint error', error);
+
+    return NextResponse.json(
+      { ok: false, error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,97 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { openMediaLinkSchema } from '@/lib/database/schemas/media';
+import { publicLinkService, PublicLinkError } from '@/lib/media/public-links';
+import { storageService } from '@/lib/media/storage';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+async function download(token: string, password?: string): Promise<NextResponse> {
+  const { media } = await publicLinkService.open(token, password);
+
+  const stream = await storageService.getFileStream(media.filename);
+  if (!stream) {
+    return NextResponse.json(
+      { error: 'Link not found' },
+      { status: 404 }
+    );
+  }
+
+  return new NextResponse(stream, {
+    headers: {
+      // Every download counts against the link, so nothing may be cached
+      'Cache-Control': 'private, no-store',
+      'Content-Type': media.mimeType,
+      'Content-Length': media.size.toString(),
+      'Content-Disposition': `attachment; filename*=UTF-8''${encodeURIComponent(media.originalName)}`,
+    },
+  });
+}
+
+function errorResponse(error: unknown): NextResponse {
+  if (error instanceof PublicLinkError) {
+    return NextResponse.json(
+      { error: error.message },
+      { status: error.status }
+    );
+  }
+
+  logger.error('Public link download error', error);
+
+  return NextResponse.json(
+    { error: 'Internal server error' },
+    { status: 500 }
+  );
+}
+
+// Download through a link without a password
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ token: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { token } = await params;
+    return await download(token);
+
+  } catch (error) {
+    return errorResponse(error);
+  }
+}
+
+// Download through a password-protected link. The password goes in the body
+// so it stays out of URLs and access logs.
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ token: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { token } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = openMediaLinkSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    return await download(token, validationResult.data.password);
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return errorResponse(error);
+  }
+}
@@ -0,0 +1,44 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { publicLinkService, PublicLinkError } from '@/lib/media/public-links';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// What a public link points to, shown before downloading. No account needed.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ token: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { token } = await params;
+    const { link, media } = await publicLinkService.getInfo(token);
+
+    return NextResponse.json({
+      file: {
+        name: media.originalName,
+        type: media.type,
+        mimeType: media.mimeType,
+        size: media.size,
+      },
+      passwordRequired: !!link.passwordHash,
+      expiresAt: link.expiresAt,
+      downloadsRemaining: link.maxDownloads ? link.maxDownloads - link.downloadCount : null,
+    });
+
+  } catch (error) {
+    if (error instanceof PublicLinkError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Public link info error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
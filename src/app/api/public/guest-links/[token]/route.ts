@@ -0,0 +1,44 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { guestAccessService, GuestAccessError } from '@/lib/auth/guest-access';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// The group a guest link leads to and what guests can do there, shown
+// before joining. No account needed.
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ token: string }> }
+) {
+  try {
+    await connectDB();
+
+    const { token } = await params;
+    const { link, group } = await guestAccessService.preview(token);
+
+    return NextResponse.json({
+      group: {
+        name: group.groupInfo?.name,
+        avatar: group.groupInfo?.avatar,
+        memberCount: group.participants.length,
+      },
+      guestHours: link.guestHours,
+      capabilities: link.capabilities,
+      expiresAt: link.expiresAt,
+    });
+
+  } catch (error) {
+    if (error instanceof GuestAccessError) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: error.status }
+      );
+    }
+
+    logger.error('Guest link info error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,134 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { scimGroupSchema, scimPatchSchema } from '@/lib/database/schemas/scim';
+import {
+  scimService,
+  serializeScimGroup,
+  scimErrorBody,
+  ScimError,
+  SCIM_CONTENT_TYPE,
+} from '@/lib/integrations/scim';
+import { apiKeyAllows } from '@/lib/auth/api-keys';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const headers = { 'Content-Type': SCIM_CONTENT_TYPE };
+
+type Params = { params: Promise<{ groupId: string }> };
+
+// Get a provisioned group
+export async function GET(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:groups:read')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:groups:read scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { groupId } = await params;
+    const group = await scimService.getGroup((request as any).organizationId, groupId);
+
+    return NextResponse.json(serializeScimGroup(group, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    return handleError(error, 'SCIM get group error');
+  }
+}
+
+// Replace a group's name and members
+export async function PUT(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:groups:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:groups:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { groupId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scimGroupSchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const group = await scimService.replaceGroup((request as any).organizationId, groupId, validationResult.data);
+
+    return NextResponse.json(serializeScimGroup(group, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return handleError(error, 'SCIM replace group error');
+  }
+}
+
+// Update some attributes, e.g. add or remove members
+export async function PATCH(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:groups:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:groups:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { groupId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scimPatchSchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const group = await scimService.patchGroup((request as any).organizationId, groupId, validationResult.data);
+
+    return NextResponse.json(serializeScimGroup(group, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return handleError(error, 'SCIM patch group error');
+  }
+}
+
+// Stop managing a group. The group chat and its messages are kept.
+export async function DELETE(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:groups:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:groups:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { groupId } = await params;
+    await scimService.deleteGroup((request as any).organizationId, groupId);
+
+    return new NextResponse(null, { status: 204 });
+
+  } catch (error) {
+    return handleError(error, 'SCIM delete group error');
+  }
+}
+
+function validationError(errors: { path: (string | number)[]; message: string }[]) {
+  return NextResponse.json(
+    scimErrorBody(400, errors.map(err => `${err.path.join('.')}: ${err.message}`).join('; '), 'invalidValue'),
+    { status: 400, headers }
+  );
+}
+
+function handleError(error: unknown, message: string) {
+  if (error instanceof ScimError) {
+    return NextResponse.json(scimErrorBody(error.status, error.message, error.scimType), { status: error.status, headers });
+  }
+
+  logger.error(message, error);
+
+  return NextResponse.json(scimErrorBody(500, 'Internal server error'), { status: 500, headers });
+}
+
+// Apply API key authentication; handlers check read or write
+export const middleware = [authMiddleware.authenticateApiKey(['scim:groups:read', 'scim:groups:write'])];
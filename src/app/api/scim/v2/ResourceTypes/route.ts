@@ -0,0 +1,26 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { SCIM_CONTENT_TYPE, SCIM_USER_SCHEMA, SCIM_GROUP_SCHEMA, scimListResponse } from '@/lib/integrations/scim';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { AUTH_CONSTANTS } from '@/lib/utils/constants';
+
+// The resource types served: users and groups
+export async function GET(request: NextRequest) {
+  const baseUrl = `${request.nextUrl.origin}/api/scim/v2`;
+
+  const resourceTypes = [
+    { id: 'User', name: 'User', endpoint: '/Users', schema: SCIM_USER_SCHEMA },
+    { id: 'Group', name: 'Group', endpoint: '/Groups', schema: SCIM_GROUP_SCHEMA },
+  ].map(type => ({
+    schemas: ['urn:ietf:params:scim:schemas:core:2.0:ResourceType'],
+    ...type,
+    meta: { resourceType: 'ResourceType', location: `${baseUrl}/ResourceTypes/${type.id}` },
+  }));
+
+  return NextResponse.json(
+    scimListResponse(resourceTypes, resourceTypes.length, 1),
+    { headers: { 'Content-Type': SCIM_CONTENT_TYPE } }
+  );
+}
+
+// Apply API key authentication
+export const middleware = [authMiddleware.authenticateApiKey([...AUTH_CONSTANTS.API_KEY_SCOPES])];
@@ -0,0 +1,30 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { SCIM_CONTENT_TYPE, SCIM_MAX_RESULTS } from '@/lib/integrations/scim';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { AUTH_CONSTANTS } from '@/lib/utils/constants';
+
+// What this SCIM implementation supports, for identity providers that check
+export async function GET(request: NextRequest) {
+  return NextResponse.json({
+    schemas: ['urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig'],
+    patch: { supported: true },
+    bulk: { supported: false, maxOperations: 0, maxPayloadSize: 0 },
+    filter: { supported: true, maxResults: SCIM_MAX_RESULTS },
+    changePassword: { supported: false },
+    sort: { supported: false },
+    etag: { supported: false },
+    authenticationSchemes: [{
+      type: 'oauthbearertoken',
+      name: 'API key',
+      description: 'An API key issued from the admin dashboard, sent as a bearer token',
+      primary: true,
+    }],
+    meta: {
+      resourceType: 'ServiceProviderConfig',
+      location: `${request.nextUrl.origin}/api/scim/v2/ServiceProviderConfig`,
+    },
+  }, { headers: { 'Content-Type': SCIM_CONTENT_TYPE } });
+}
+
+// Apply API key authentication
+export const middleware = [authMiddleware.authenticateApiKey([...AUTH_CONSTANTS.API_KEY_SCOPES])];
@@ -0,0 +1,134 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { scimUserSchema, scimPatchSchema } from '@/lib/database/schemas/scim';
+import {
+  scimService,
+  serializeScimUser,
+  scimErrorBody,
+  ScimError,
+  SCIM_CONTENT_TYPE,
+} from '@/lib/integrations/scim';
+import { apiKeyAllows } from '@/lib/auth/api-keys';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const headers = { 'Content-Type': SCIM_CONTENT_TYPE };
+
+type Params = { params: Promise<{ userId: string }> };
+
+// Get a provisioned user
+export async function GET(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:read')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:read scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { userId } = await params;
+    const user = await scimService.getUser((request as any).organizationId, userId);
+
+    return NextResponse.json(serializeScimUser(user, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    return handleError(error, 'SCIM get user error');
+  }
+}
+
+// Replace a user's attributes
+export async function PUT(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { userId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scimUserSchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const user = await scimService.replaceUser((request as any).organizationId, userId, validationResult.data);
+
+    return NextResponse.json(serializeScimUser(user, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return handleError(error, 'SCIM replace user error');
+  }
+}
+
+// Update some attributes, e.g. active=false to deprovision
+export async function PATCH(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { userId } = await params;
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scimPatchSchema.safeParse(body);
+    if (!validationResult.success) {
+      return validationError(validationResult.error.errors);
+    }
+
+    const user = await scimService.patchUser((request as any).organizationId, userId, validationResult.data);
+
+    return NextResponse.json(serializeScimUser(user, `${request.nextUrl.origin}/api/scim/v2`), { headers });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    return handleError(error, 'SCIM patch user error');
+  }
+}
+
+// Deprovision a user. The account is deactivated, not deleted.
+export async function DELETE(request: NextRequest, { params }: Params) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { userId } = await params;
+    await scimService.deleteUser((request as any).organizationId, userId);
+
+    return new NextResponse(null, { status: 204 });
+
+  } catch (error) {
+    return handleError(error, 'SCIM delete user error');
+  }
+}
+
+function validationError(errors: { path: (string | number)[]; message: string }[]) {
+  return NextResponse.json(
+    scimErrorBody(400, errors.map(err => `${err.path.join('.')}: ${err.message}`).join('; '), 'invalidValue'),
+    { status: 400, headers }
+  );
+}
+
+function handleError(error: unknown, message: string) {
+  if (error instanceof ScimError) {
+    return NextResponse.json(scimErrorBody(error.status, error.message, error.scimType), { status: error.status, headers });
+  }
+
+  logger.error(message, error);
+
+  return NextResponse.json(scimErrorBody(500, 'Internal server error'), { status: 500, headers });
+}
+
+// Apply API key authentication; handlers check read or write
+export const middleware = [authMiddleware.authenticateApiKey(['scim:users:read', 'scim:users:write'])];
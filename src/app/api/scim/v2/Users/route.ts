@@ -0,0 +1,95 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { scimUserSchema } from '@/lib/database/schemas/scim';
+import {
+  scimService,
+  serializeScimUser,
+  scimListResponse,
+  scimErrorBody,
+  ScimError,
+  SCIM_CONTENT_TYPE,
+  SCIM_MAX_RESULTS,
+} from '@/lib/integrations/scim';
+import { apiKeyAllows } from '@/lib/auth/api-keys';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { authMiddleware } from '@/lib/auth/middleware';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+const headers = { 'Content-Type': SCIM_CONTENT_TYPE };
+
+// List provisioned users, optionally filtered (userName eq "...")
+export async function GET(request: NextRequest) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:read')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:read scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const { searchParams } = request.nextUrl;
+    const startIndex = Math.max(1, parseInt(searchParams.get('startIndex') || '1') || 1);
+    const count = Math.min(Math.max(0, parseInt(searchParams.get('count') || '100') || 0), SCIM_MAX_RESULTS);
+
+    const { users, total } = await scimService.listUsers((request as any).organizationId, searchParams.get('filter'), startIndex, count);
+    const baseUrl = `${request.nextUrl.origin}/api/scim/v2`;
+
+    return NextResponse.json(
+      scimListResponse(users.map(user => serializeScimUser(user, baseUrl)), total, startIndex),
+      { headers }
+    );
+
+  } catch (error) {
+    if (error instanceof ScimError) {
+      return NextResponse.json(scimErrorBody(error.status, error.message, error.scimType), { status: error.status, headers });
+    }
+
+    logger.error('SCIM list users error', error);
+
+    return NextResponse.json(scimErrorBody(500, 'Internal server error'), { status: 500, headers });
+  }
+}
+
+// Provision a user
+export async function POST(request: NextRequest) {
+  try {
+    if (!apiKeyAllows((request as any).apiKey, 'scim:users:write')) {
+      return NextResponse.json(scimErrorBody(403, 'API key needs the scim:users:write scope'), { status: 403, headers });
+    }
+
+    await connectDB();
+
+    const body = await readJsonWithLimit(request);
+
+    const validationResult = scimUserSchema.safeParse(body);
+    if (!validationResult.success) {
+      return NextResponse.json(
+        scimErrorBody(
+          400,
+          validationResult.error.errors.map(err => `${err.path.join('.')}: ${err.message}`).join('; '),
+          'invalidValue'
+        ),
+        { status: 400, headers }
+      );
+    }
+
+    const user = await scimService.createUser((request as any).organizationId, validationResult.data);
+    const baseUrl = `${request.nextUrl.origin}/api/scim/v2`;
+
+    return NextResponse.json(serializeScimUser(user, baseUrl), { status: 201, headers });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    if (error instanceof ScimError) {
+      return NextResponse.json(scimErrorBody(error.status, error.message, error.scimType), { status: error.status, headers });
+    }
+
+    logger.error('SCIM create user error', error);
+
+    return NextResponse.json(scimErrorBody(500, 'Internal server error'), { status: 500, headers });
+  }
+}
+
+// Apply API key authentication; handlers check read or write
+export const middleware = [authMiddleware.authenticateApiKey(['scim:users:read', 'scim:users:write'])];
@@ -0,0 +1,38 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { smsService } from '@/lib/communication/sms';
+import { smsReportingService } from '@/lib/communication/sms-reporting';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+// Delivery status callbacks from Twilio for outbound SMS
+export async function POST(request: NextRequest) {
+  try {
+    const form = await request.formData();
+    const params: Record<string, string> = {};
+    form.forEach((value, key) => {
+      if (typeof value === 'string') params[key] = value;
+    });
+
+    // Signed over the URL we registered, not whatever the proxy forwarded
+    const signature = request.headers.get('x-twilio-signature');
+    if (!smsService.verifyStatusCallback(signature, smsReportingService.getStatusCallbackUrl(), params)) {
+      return NextResponse.json(
+        { error: 'Invalid signature' },
+        { status: 403 }
+      );
+    }
+
+    await connectDB();
+    await smsReportingService.handleStatusCallback(params);
+
+    return new NextResponse(null, { status: 204 });
+
+  } catch (error) {
+    logger.error('SMS status callback error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
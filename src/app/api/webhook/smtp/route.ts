@@ -0,0 +1,101 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { emailGatewayService } from '@/lib/communication/email-gateway';
+import { PayloadTooLargeError, payloadTooLargeResponse, readJsonWithLimit } from '@/lib/security/body-limit';
+import { logger } from '@/lib/monitoring/logging';
+import connectDB from '@/lib/database/mongodb';
+
+function splitAddresses(value: string | null | undefined): string[] {
+  return (value || '').split(',').map(address => address.trim()).filter(Boolean);
+}
+
+// Extract the raw MIME message and envelope recipients from the shapes
+// inbound mail providers post:
+// - multipart form with the raw message in "email" (SendGrid, raw mode) or
+//   "body-mime" (Mailgun, MIME mode)
+// - JSON { raw, encoding?: 'base64', recipients? }
+// - the raw message itself (message/rfc822 or text/plain)
+async function readInboundEmail(request: NextRequest): Promise<{ raw: Buffer | string; recipients: string[] } | null> {
+  const contentType = request.headers.get('content-type')?.toLowerCase() || '';
+
+  if (contentType.startsWith('multipart/form-data') || contentType.startsWith('application/x-www-form-urlencoded')) {
+    const form = await request.formData();
+    const raw = form.get('email') || form.get('body-mime') || form.get('raw');
+    if (!raw) return null;
+
+    let recipients = splitAddresses(form.get('recipient') as string | null);
+    const envelope = form.get('envelope');
+    if (recipients.length === 0 && typeof envelope === 'string') {
+      try {
+        const parsed = JSON.parse(envelope);
+        recipients = Array.isArray(parsed.to) ? parsed.to : splitAddresses(parsed.to);
+      } catch {
+        // Fall back to the To/Cc headers
+      }
+    }
+
+    return {
+      raw: typeof raw === 'string' ? raw : Buffer.from(await raw.arrayBuffer()),
+      recipients,
+    };
+  }
+
+  if (contentType.startsWith('application/json')) {
+    const body = await readJsonWithLimit(request);
+    if (typeof body.raw !== 'string') return null;
+
+    return {
+      raw: body.encoding === 'base64' ? Buffer.from(body.raw, 'base64') : body.raw,
+      recipients: Array.isArray(body.recipients) ? body.recipients : [],
+    };
+  }
+
+  return {
+    raw: Buffer.from(await request.arrayBuffer()),
+    recipients: splitAddresses(request.headers.get('x-original-to')),
+  };
+}
+
+// Inbound email webhook for the email-to-chat gateway
+export async function POST(request: NextRequest) {
+  try {
+    const token = request.headers.get('x-webhook-token') || request.nextUrl.searchParams.get('token');
+    if (!emailGatewayService.verifyInboundToken(token)) {
+      return NextResponse.json(
+        { error: 'Invalid webhook token' },
+        { status: 403 }
+      );
+    }
+
+    if (!emailGatewayService.isEnabled()) {
+      return NextResponse.json(
+        { error: 'Email gateway is disabled' },
+        { status: 503 }
+      );
+    }
+
+    const inbound = await readInboundEmail(request);
+    if (!inbound) {
+      return NextResponse.json(
+        { error: 'No email message in request' },
+        { status: 400 }
+      );
+    }
+
+    await connectDB();
+    const delivered = await emailGatewayService.receive(inbound.raw, inbound.recipients);
+
+    // Unknown recipients are acknowledged too, otherwise providers keep retrying
+    return NextResponse.json({ delivered });
+
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+    logger.error('Inbound email webhook error', error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  }
+}
@@ -0,0 +1,16 @@
+// Runs once per server process on startup
+export async function register() {
+  if (process.env.NEXT_RUNTIME !== 'nodejs') return;
+
+  const { default: connectDB } = await import('./lib/database/mongodb');
+  await connectDB();
+
+  const { getProcessRole, startServerWorkers, startBackgroundWorkers } = await import('./lib/workers');
+  await startServerWorkers();
+
+  // With PROCESS_ROLE=api, separate worker processes (npm run worker) take
+  // the background jobs so they don't compete with requests
+  if (getProcessRole() !== 'api') {
+    await startBackgroundWorkers();
+  }
+}
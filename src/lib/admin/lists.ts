@@ -0,0 +1,244 @@
+import { UserRepository } from '../database/repositories/user';
+import { MediaRepository } from '../database/repositories/media';
+import { CallRepository } from '../database/repositories/call';
+import { ReportRepository } from '../database/repositories/report';
+import { SecurityEventRepository } from '../database/repositories/security-event';
+import { IUser } from '../database/models/user';
+import { IMedia } from '../database/models/media';
+import { IReport } from '../database/models/report';
+import { ISecurityEvent } from '../database/models/security-event';
+import {
+  AdminUserListInput,
+  AdminMediaListInput,
+  AdminCallListInput,
+  AdminReportListInput,
+  AdminSecurityEventListInput,
+} from '../database/schemas/admin-list';
+import { callRecord } from '../pipelines/records';
+import { PaginationUtils } from '../utils/pagination';
+import { PAGINATION_CONSTANTS } from '../utils/constants';
+import { csvStream } from '../utils/csv';
+import {
+  AnonymizedRecordKind,
+  ExportAnonymizer,
+  ExportMode,
+  bucketSmallCohortRows,
+  hasQuasiIdentifiers,
+} from '../pipelines/anonymize';
+import { PiiPolicy, redactUserRecord } from './redaction';
+
+const id = (value: any): string | undefined => value?.toString();
+
+// Rows of the admin lists, the same in JSON and CSV. Secrets (push tokens,
+// media encryption keys, call signaling) are never included; phone numbers
+// are redacted by the lists that show them, see ./redaction.
+
+export function adminUserRecord(user: IUser) {
+  return {
+    id: id(user._id)!,
+    phoneNumber: user.phoneNumber,
+    displayName: user.displayName,
+    username: user.username,
+    email: user.email,
+    countryCode: user.countryCode,
+    dataRegion: user.dataRegion,
+    organizationId: id(user.organizationId),
+    isVerified: !!user.isVerified,
+    isOnline: !!user.isOnline,
+    lastSeen: user.lastSeen,
+    isBanned: !!user.isBanned,
+    banReason: user.banReason,
+    banExpiresAt: user.banExpiresAt,
+    restriction: user.restriction?.mode,
+    platforms: [...new Set((user.devices || []).map(device => device.platform))].join(' '),
+    bridge: user.bridge?.protocol,
+    createdAt: user.createdAt,
+  };
+}
+
+export function adminMediaRecord(media: IMedia) {
+  return {
+    id: id(media._id)!,
+    type: media.type,
+    originalName: media.originalName,
+    mimeType: media.mimeType,
+    size: media.size,
+    uploadedBy: id(media.uploadedBy),
+    chatId: id(media.chatId),
+    messageId: id(media.messageId),
+    storageRegion: media.storageRegion,
+    isEncrypted: !!media.isEncrypted,
+    createdAt: media.createdAt,
+  };
+}
+
+export function adminReportRecord(report: IReport) {
+  return {
+    id: id(report._id)!,
+    type: report.type,
+    status: report.status,
+    priority: report.priority,
+    reason: report.reason,
+    reporterId: id(report.reporterId),
+    reportedUserId: id(report.reportedUserId),
+    reportedMessageId: id(report.reportedMessageId),
+    reportedChatId: id(report.reportedChatId),
+    assignedTo: id(report.assignedTo),
+    evidenceCount: report.evidence?.length ?? 0,
+    labels: report.labels?.join(' '),
+    resolution: report.resolution,
+    createdAt: report.createdAt,
+    resolvedAt: report.resolvedAt,
+  };
+}
+
+export function adminSecurityEventRecord(event: ISecurityEvent) {
+  return {
+    id: id(event._id)!,
+    type: event.type,
+    userId: id(event.userId),
+    deviceId: event.deviceId,
+    ip: event.ip,
+    country: event.country,
+    city: event.city,
+    asn: event.asn,
+    asOrganization: event.asOrganization,
+    userAgent: event.userAgent,
+    loginApprovalId: id(event.loginApprovalId),
+    createdAt: event.createdAt,
+  };
+}
+
+// CSV columns, in record order
+const USER_COLUMNS = Object.keys(adminUserRecord({} as IUser));
+const MEDIA_COLUMNS = Object.keys(adminMediaRecord({} as IMedia));
+const CALL_COLUMNS = Object.keys(callRecord({} as any));
+const REPORT_COLUMNS = Object.keys(adminReportRecord({} as IReport));
+const SECURITY_EVENT_COLUMNS = Object.keys(adminSecurityEventRecord({} as ISecurityEvent));
+
+type ListInput = {
+  page: number;
+  limit: number;
+  sortBy: string;
+  sortOrder: 'asc' | 'desc';
+  format: 'json' | 'csv';
+  mode: ExportMode;
+};
+
+// Filters and repository options of a parsed list query
+function splitQuery<T extends ListInput>(query: T) {
+  const { page, limit, sortBy, sortOrder, format, mode, ...filters } = query;
+  return { filters, options: PaginationUtils.toListOptions({ page, limit, sortBy, sortOrder }) };
+}
+
+// CSV export of a list; anonymized exports go through ExportAnonymizer,
+// which decides what is left of each row. Kinds with quasi-identifiers are
+// read whole before the first row goes out, so small cohorts can be bucketed.
+function exportCsv<T>(
+  mode: ExportMode,
+  kind: AnonymizedRecordKind,
+  columns: readonly string[],
+  cursor: AsyncIterable<T> & { close(): Promise<unknown> },
+  toRecord: (doc: T) => Record<string, unknown>
+): ReadableStream<Uint8Array> {
+  if (mode !== 'anonymized') {
+    return csvStream(columns, cursor, toRecord);
+  }
+
+  const anonymizer = new ExportAnonymizer();
+  const toRow = (doc: T) => anonymizer.record(kind, toRecord(doc));
+  if (!hasQuasiIdentifiers(kind)) {
+    return csvStream(anonymizer.columns(kind, columns), cursor, toRow);
+  }
+
+  const bucketed = {
+    async *[Symbol.asyncIterator]() {
+      const rows: Record<string, unknown>[] = [];
+      for await (const doc of cursor) {
+        rows.push(toRow(doc));
+      }
+      yield* bucketSmallCohortRows(kind, rows);
+    },
+    close: () => cursor.close(),
+  };
+  return csvStream(anonymizer.columns(kind, columns), bucketed, row => row);
+}
+
+// Server-side lists for the admin dashboard: users, uploaded files, calls,
+// reports and security events, each filtered, sorted on an indexed field and paged, or
+// exported whole as CSV with the same filters and order. Exports stop at
+// PAGINATION_CONSTANTS.MAX_EXPORT_ROWS rows. Exports are full or anonymized,
+// see ../pipelines/anonymize.
+export class AdminListService {
+  private userRepository = new UserRepository();
+  private mediaRepository = new MediaRepository();
+  private callRepository = new CallRepository();
+  private reportRepository = new ReportRepository();
+  private securityEventRepository = new SecurityEventRepository();
+
+  async listUsers(query: AdminUserListInput, policy: PiiPolicy) {
+    const { filters, options } = splitQuery(query);
+    const { users, total } = await this.userRepository.getUsers(filters, options);
+    return PaginationUtils.createPaginationResult(
+      users.map(user => redactUserRecord(adminUserRecord(user), policy)),
+      total,
+      query
+    );
+  }
+
+  exportUsers(query: AdminUserListInput, policy: PiiPolicy): ReadableStream<Uint8Array> {
+    const { filters, options } = splitQuery(query);
+    const cursor = this.userRepository.cursorForAdmin(filters, options.sort, PAGINATION_CONSTANTS.MAX_EXPORT_ROWS);
+    return exportCsv(query.mode, 'user', USER_COLUMNS, cursor, (user: IUser) => redactUserRecord(adminUserRecord(user), policy));
+  }
+
+  async listMedia(query: AdminMediaListInput) {
+    const { filters, options } = splitQuery(query);
+    const { media, total } = await this.mediaRepository.getForAdmin(filters, options);
+    return PaginationUtils.createPaginationResult(media.map(adminMediaRecord), total, query);
+  }
+
+  exportMedia(query: AdminMediaListInput): ReadableStream<Uint8Array> {
+    const { filters, options } = splitQuery(query);
+    const cursor = this.mediaRepository.cursorForAdmin(filters, options.sort, PAGINATION_CONSTANTS.MAX_EXPORT_ROWS);
+    return exportCsv(query.mode, 'media', MEDIA_COLUMNS, cursor, adminMediaRecord);
+  }
+
+  async listCalls(query: AdminCallListInput) {
+    const { filters, options } = splitQuery(query);
+    const { calls, total } = await this.callRepository.getForAdmin(filters, options);
+    return PaginationUtils.createPaginationResult(calls.map(callRecord), total, query);
+  }
+
+  exportCalls(query: AdminCallListInput): ReadableStream<Uint8Array> {
+    const { filters, options } = splitQuery(query);
+    const cursor = this.callRepository.cursorForAdmin(filters, options.sort, PAGINATION_CONSTANTS.MAX_EXPORT_ROWS);
+    return exportCsv(query.mode, 'call', CALL_COLUMNS, cursor, callRecord);
+  }
+
+  async listReports(query: AdminReportListInput) {
+    const { filters, options } = splitQuery(query);
+    const { reports, total } = await this.reportRepository.getForAdmin(filters, options);
+    return PaginationUtils.createPaginationResult(reports.map(adminReportRecord), total, query);
+  }
+
+  exportReports(query: AdminReportListInput): ReadableStream<Uint8Array> {
+    const { filters, options } = splitQuery(query);
+    const cursor = this.reportRepository.cursorForAdmin(filters, options.sort, PAGINATION_CONSTANTS.MAX_EXPORT_ROWS);
+    return exportCsv(query.mode, 'report', REPORT_COLUMNS, cursor, adminReportRecord);
+  }
+
+  async listSecurityEvents(query: AdminSecurityEventListInput) {
+    const { filters, options } = splitQuery(query);
+    const { events, total } = await this.securityEventRepository.getForAdmin(filters, options);
+    return PaginationUtils.createPaginationResult(events.map(adminSecurityEventRecord), total, query);
+  }
+
+  exportSecurityEvents(query: AdminSecurityEventListInput): ReadableStream<Uint8Array> {
+    const { filters, options } = splitQuery(query);
+    const cursor = this.securityEventRepository.cursorForAdmin(filters, options.sort, PAGINATION_CONSTANTS.MAX_EXPORT_ROWS);
+    return exportCsv(query.mode, 'securityEvent', SECURITY_EVENT_COLUMNS, cursor, adminSecurityEventRecord);
+  }
+}
+
+export const adminListService = new AdminListService();
@@ -0,0 +1,165 @@
+import { Types } from 'mongoose';
+import { UserRepository } from '../database/repositories/user';
+import { MessageRepository } from '../database/repositories/message';
+import { ChatRepository } from '../database/repositories/chat';
+import { CallRepository } from '../database/repositories/call';
+import { CallRatingRepository } from '../database/repositories/call-rating';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { AuditTargetType } from '../database/models/audit-entry';
+import { IMessage } from '../database/models/message';
+import { RevealPiiInput } from '../database/schemas/pii';
+import { Permission } from '../security/permissions';
+import { logger } from '../monitoring/logging';
+
+// What an admin sees of users' personal data. Phone numbers are masked
+// unless their role (or their own permissions) include VIEW_PHONE_NUMBERS.
+// Message content, and what users wrote when rating calls, is never in the
+// admin views; admins with REVEAL_PII can reveal a phone number or content
+// by recording a justification, and every reveal is in the audit log.
+export interface PiiPolicy {
+  phoneNumbers: 'full' | 'masked';
+}
+
+export function getPiiPolicy(permissions: string[] = []): PiiPolicy {
+  return {
+    phoneNumbers: permissions.includes(Permission.VIEW_PHONE_NUMBERS) ? 'full' : 'masked',
+  };
+}
+
+// "+14155550123" -> "+14******23": enough to tell numbers apart and see the
+// country, not enough to call
+export function maskPhoneNumber(phoneNumber?: string): string | undefined {
+  if (!phoneNumber) return phoneNumber;
+  if (phoneNumber.length <= 6) return '*'.repeat(phoneNumber.length);
+  return phoneNumber.slice(0, 3) + '*'.repeat(phoneNumber.length - 5) + phoneNumber.slice(-2);
+}
+
+export function redactUserRecord<T extends { phoneNumber?: string }>(record: T, policy: PiiPolicy): T {
+  if (policy.phoneNumbers === 'full') return record;
+  return { ...record, phoneNumber: maskPhoneNumber(record.phoneNumber) };
+}
+
+// A message as admins see it: everything but what was said. Locations and
+// shared contacts count as content.
+export function redactMessage(message: IMessage, policy: PiiPolicy) {
+  const { content, ...record } = message.toObject();
+  const sender = record.senderId as any;
+
+  return {
+    ...record,
+    contentRedacted: !!content,
+    metadata: record.metadata && { ...record.metadata, location: undefined, contact: undefined },
+    senderId: sender?.phoneNumber !== undefined ? redactUserRecord(sender, policy) : sender,
+  };
+}
+
+// Reveals, one audit entry per record revealed
+export class PiiRevealService {
+  private userRepository = new UserRepository();
+  private messageRepository = new MessageRepository();
+  private chatRepository = new ChatRepository();
+  private callRepository = new CallRepository();
+  private callRatingRepository = new CallRatingRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+
+  // Phone numbers of users, content of messages or call rating comments.
+  // Records that don't exist, or are outside an org admin's organization,
+  // are left out.
+  async reveal(adminId: string, input: RevealPiiInput, adminOrganizationId?: string) {
+    switch (input.targetType) {
+      case 'user':
+        return { users: await this.revealUsers(adminId, input, adminOrganizationId) };
+      case 'message':
+        return { messages: await this.revealMessages(adminId, input, adminOrganizationId) };
+      case 'call':
+        return { calls: await this.revealCalls(adminId, input) };
+    }
+  }
+
+  private async revealUsers(adminId: string, input: RevealPiiInput, adminOrganizationId?: string) {
+    const revealed: { id: string; phoneNumber: string }[] = [];
+
+    for (const id of input.ids.filter(id => Types.ObjectId.isValid(id))) {
+      const user = await this.userRepository.findById(id);
+      if (!user || (adminOrganizationId && user.organizationId?.toString() !== adminOrganizationId)) {
+        continue;
+      }
+
+      await this.audit(adminId, 'user', user._id, 'phoneNumber', input);
+      revealed.push({ id: user._id.toString(), phoneNumber: user.phoneNumber });
+    }
+
+    return revealed;
+  }
+
+  private async revealMessages(adminId: string, input: RevealPiiInput, adminOrganizationId?: string) {
+    const revealed: { id: string; content: string; location?: unknown; contact?: unknown }[] = [];
+
+    for (const id of input.ids.filter(id => Types.ObjectId.isValid(id))) {
+      const message = await this.messageRepository.findById(id);
+      if (!message) continue;
+      if (adminOrganizationId) {
+        const chat = await this.chatRepository.findById(message.chatId);
+        if (chat?.organizationId?.toString() !== adminOrganizationId) continue;
+      }
+
+      await this.audit(adminId, 'message', message._id, 'content', input);
+      revealed.push({
+        id: message._id.toString(),
+        content: message.content,
+        ...(message.metadata?.location && { location: message.metadata.location }),
+        ...(message.metadata?.contact && { contact: message.metadata.contact }),
+      });
+    }
+
+    return revealed;
+  }
+
+  // Calls are looked up by callId; what is revealed is the comments left
+  // with their ratings
+  private async revealCalls(adminId: string, input: RevealPiiInput) {
+    const revealed: { callId: string; feedback: { userId: string; feedback: string }[] }[] = [];
+
+    for (const callId of input.ids) {
+      const call = await this.callRepository.findByCallId(callId);
+      if (!call) continue;
+
+      const ratings = await this.callRatingRepository.findByCall(callId);
+      await this.audit(adminId, 'call', call._id, 'feedback', input);
+      revealed.push({
+        callId,
+        feedback: ratings
+          .filter(rating => rating.feedback)
+          .map(rating => ({
+            userId: ((rating.userId as any)?._id || rating.userId).toString(),
+            feedback: rating.feedback!,
+          })),
+      });
+    }
+
+    return revealed;
+  }
+
+  private async audit(
+    adminId: string,
+    targetType: AuditTargetType,
+    targetId: Types.ObjectId,
+    field: string,
+    input: RevealPiiInput
+  ): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action: `${targetType}.reveal`,
+      targetType,
+      targetId,
+      details: {
+        field,
+        justification: input.justification,
+        caseReference: input.caseReference,
+      },
+    });
+    logger.info('Personal data revealed', { adminId, targetType, targetId: targetId.toString(), field });
+  }
+}
+
+export const piiRevealService = new PiiRevealService();
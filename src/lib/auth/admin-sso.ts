@@ -0,0 +1,493 @@
+import crypto from 'crypto';
+import jwt from 'jsonwebtoken';
+import { Types } from 'mongoose';
+import { SAML } from '@node-saml/node-saml';
+import { AdminRepository } from '../database/repositories/admin';
+import { AdminSessionRepository } from '../database/repositories/admin-session';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { AdminRole, IAdmin } from '../database/models/admin';
+import { IAdminSession } from '../database/models/admin-session';
+import { environmentConfig } from '../config/environment';
+import { redisConfig } from '../config/redis';
+import { jwtService, AdminTokenPayload } from './jwt';
+import { logger } from '../monitoring/logging';
+
+const STATE_TTL = 10 * 60; // seconds to finish signing in at the IdP
+const DISCOVERY_TTL = 60 * 60 * 1000;
+const TOUCH_INTERVAL = 60 * 1000;
+const REQUEST_TIMEOUT = 10 * 1000;
+
+// Highest first; an admin in several mapped groups gets the highest role
+const ROLE_RANK: AdminRole[] = ['super_admin', 'admin', 'moderator', 'support'];
+
+type SsoProtocol = 'oidc' | 'saml';
+
+interface LoginState {
+  nonce: string;
+  codeVerifier: string; // PKCE, OIDC only
+  returnTo?: string;
+}
+
+interface OidcDiscovery {
+  issuer: string;
+  authorization_endpoint: string;
+  token_endpoint: string;
+  jwks_uri: string;
+}
+
+interface IdentityClaims {
+  subject: string;
+  email?: string;
+  displayName?: string;
+  groups: string[];
+}
+
+export interface AdminSignIn {
+  token: string;
+  expiresAt: Date;
+  admin: IAdmin;
+  returnTo?: string;
+}
+
+// Single sign-on for the admin dashboard, over OIDC (authorization code
+// with PKCE) or SAML 2.0 (HTTP-POST binding). Admin accounts are created on
+// first sign-in and their role follows the IdP groups on every sign-in, as
+// mapped by ADMIN_SSO_ROLE_MAP; someone in no mapped group can't sign in.
+// Deactivating an account here still locks it out whatever the IdP says.
+//
+// Each sign-in opens a session that ends after ADMIN_SESSION_MAX_HOURS, or
+// after ADMIN_SESSION_IDLE_MINUTES without a request; an admin keeps at most
+// ADMIN_MAX_SESSIONS, the oldest being signed out. With SSO on, the old
+// phone+password admin access only works while ADMIN_LOCAL_LOGIN_ENABLED is
+// set, as a break-glass for when the IdP is down.
+export class AdminSsoService {
+  private adminRepository = new AdminRepository();
+  private adminSessionRepository = new AdminSessionRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private redis = redisConfig.getClient();
+  private memoryStates = new Map<string, { state: LoginState; expiresAt: number }>();
+  private discovery: { value: OidcDiscovery; loadedAt: number } | null = null;
+  private jwks = new Map<string, crypto.KeyObject>();
+
+  getProtocol(): SsoProtocol | null {
+    const protocol = environmentConfig.get().ADMIN_SSO_PROTOCOL;
+    return protocol === 'none' ? null : protocol;
+  }
+
+  isEnabled(): boolean {
+    return this.getProtocol() !== null;
+  }
+
+  // Whether admin endpoints still take phone+password sessions
+  isLocalLoginAllowed(): boolean {
+    return !this.isEnabled() || environmentConfig.get().ADMIN_LOCAL_LOGIN_ENABLED;
+  }
+
+  getStatus() {
+    return {
+      enabled: this.isEnabled(),
+      protocol: this.getProtocol(),
+      localLogin: this.isLocalLoginAllowed(),
+    };
+  }
+
+  // Where to send the browser to sign in
+  async beginLogin(returnTo?: string): Promise<string> {
+    const protocol = this.requireProtocol();
+    const state = crypto.randomBytes(24).toString('base64url');
+    const loginState: LoginState = {
+      nonce: crypto.randomBytes(16).toString('base64url'),
+      codeVerifier: crypto.randomBytes(32).toString('base64url'),
+      returnTo: this.safeReturnTo(returnTo),
+    };
+    await this.saveState(state, loginState);
+
+    if (protocol === 'saml') {
+      return await this.saml().getAuthorizeUrlAsync(state, undefined, {});
+    }
+
+    const env = environmentConfig.get();
+    const discovery = await this.getDiscovery();
+    const url = new URL(discovery.authorization_endpoint);
+    url.search = new URLSearchParams({
+      response_type: 'code',
+      client_id: env.ADMIN_OIDC_CLIENT_ID!,
+      redirect_uri: this.callbackUrl(),
+      scope: env.ADMIN_OIDC_SCOPES,
+      state,
+      nonce: loginState.nonce,
+      code_challenge: crypto.createHash('sha256').update(loginState.codeVerifier).digest('base64url'),
+      code_challenge_method: 'S256',
+    }).toString();
+    return url.toString();
+  }
+
+  // OIDC redirect back from the IdP
+  async completeOidc(code: string, state: string, client: { ip?: string; userAgent?: string }): Promise<AdminSignIn> {
+    if (this.requireProtocol() !== 'oidc') {
+      throw new AdminSsoError('OIDC sign-in is not enabled', 400);
+    }
+    const loginState = await this.takeState(state);
+
+    const env = environmentConfig.get();
+    const discovery = await this.getDiscovery();
+    const response = await fetch(discovery.token_endpoint, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+      body: new URLSearchParams({
+        grant_type: 'authorization_code',
+        code,
+        redirect_uri: this.callbackUrl(),
+        client_id: env.ADMIN_OIDC_CLIENT_ID!,
+        client_secret: env.ADMIN_OIDC_CLIENT_SECRET || '',
+        code_verifier: loginState.codeVerifier,
+      }),
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok) {
+      logger.warn('OIDC code exchange failed', { status: response.status });
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+
+    const { id_token: idToken } = await response.json();
+    const claims = await this.verifyIdToken(idToken, loginState.nonce);
+    if (claims.email_verified === false) {
+      throw new AdminSsoError('Email address not verified at the identity provider', 403);
+    }
+
+    const identity: IdentityClaims = {
+      subject: String(claims.sub),
+      email: claims.email,
+      displayName: claims.name || claims.preferred_username,
+      groups: this.toGroups(claims[env.ADMIN_SSO_GROUPS_CLAIM]),
+    };
+    return { ...(await this.signIn('oidc', identity, client)), returnTo: loginState.returnTo };
+  }
+
+  // SAML response posted back by the IdP
+  async completeSaml(samlResponse: string, relayState: string, client: { ip?: string; userAgent?: string }): Promise<AdminSignIn> {
+    if (this.requireProtocol() !== 'saml') {
+      throw new AdminSsoError('SAML sign-in is not enabled', 400);
+    }
+    const loginState = await this.takeState(relayState);
+
+    let profile;
+    try {
+      ({ profile } = await this.saml().validatePostResponseAsync({ SAMLResponse: samlResponse }));
+    } catch (error) {
+      logger.warn('SAML response rejected', { error: error instanceof Error ? error.message : String(error) });
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+    if (!profile?.nameID) {
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+
+    const attributes = profile as Record<string, any>;
+    const identity: IdentityClaims = {
+      subject: profile.nameID,
+      email: profile.email || attributes['http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress'],
+      displayName: attributes.displayName || attributes['http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name'],
+      groups: this.toGroups(attributes[environmentConfig.get().ADMIN_SSO_GROUPS_CLAIM]),
+    };
+    return { ...(await this.signIn('saml', identity, client)), returnTo: loginState.returnTo };
+  }
+
+  // Check an admin session token against the session policy
+  async authenticate(token: string): Promise<{ admin: IAdmin; session: IAdminSession; payload: AdminTokenPayload } | null> {
+    const payload = jwtService.verifyAdminToken(token);
+    if (!payload || !Types.ObjectId.isValid(payload.sessionId)) return null;
+
+    const session = await this.adminSessionRepository.findById(payload.sessionId);
+    const now = Date.now();
+    if (
+      !session ||
+      session.revokedAt ||
+      session.expiresAt.getTime() <= now ||
+      session.lastSeenAt.getTime() + this.idleTimeout() <= now
+    ) {
+      return null;
+    }
+
+    const admin = await this.adminRepository.findById(session.adminId);
+    if (!admin || !admin.isActive) {
+      await this.adminSessionRepository.revoke([session._id], 'deactivated');
+      return null;
+    }
+
+    await this.adminSessionRepository.touch(session._id, new Date(now - TOUCH_INTERVAL));
+    return { admin, session, payload };
+  }
+
+  async logout(sessionId: string): Promise<void> {
+    await this.adminSessionRepository.revoke([sessionId], 'logout');
+  }
+
+  private async signIn(protocol: SsoProtocol, identity: IdentityClaims, client: { ip?: string; userAgent?: string }) {
+    const role = this.mapRole(identity.groups);
+    if (!role) {
+      logger.warn('Admin SSO sign-in refused, no mapped group', { protocol, subject: identity.subject, groups: identity.groups });
+      throw new AdminSsoError('Your account has no admin role', 403);
+    }
+
+    const admin = await this.provision(protocol, identity, role);
+    if (!admin.isActive) {
+      throw new AdminSsoError('Admin account is deactivated', 403);
+    }
+
+    const session = await this.openSession(admin, protocol, client);
+    const expiresIn = Math.floor((session.expiresAt.getTime() - Date.now()) / 1000);
+    const token = jwtService.generateAdminToken(admin, session._id.toString(), expiresIn);
+
+    logger.info('Admin signed in through SSO', { adminId: admin._id.toString(), protocol, role, ip: client.ip });
+    return { token, expiresAt: session.expiresAt, admin };
+  }
+
+  // Find the admin for an IdP identity, creating or linking the account on
+  // first sign-in, and bring the role in line with the IdP groups
+  private async provision(protocol: SsoProtocol, identity: IdentityClaims, role: AdminRole): Promise<IAdmin> {
+    const now = new Date();
+    let admin = await this.adminRepository.findBySso(protocol, identity.subject);
+
+    if (!admin && identity.email) {
+      admin = await this.adminRepository.findByEmail(identity.email);
+      if (admin?.sso) {
+        // Linked to another IdP identity already; don't take it over
+        throw new AdminSsoError('Admin account is linked to another sign-in', 409);
+      }
+    }
+
+    if (!admin) {
+      if (!identity.email) {
+        throw new AdminSsoError('The identity provider did not share an email address', 400);
+      }
+
+      const created = await this.adminRepository.create({
+        email: identity.email.toLowerCase(),
+        username: await this.uniqueUsername(identity.email),
+        displayName: identity.displayName || identity.email,
+        role,
+        permissions: [],
+        sso: { protocol, subject: identity.subject, groups: identity.groups, provisionedAt: now, lastLoginAt: now },
+      });
+      await this.audit(created._id, 'admin.sso.provision', { protocol, role, groups: identity.groups });
+      return created;
+    }
+
+    const previousRole = admin.role;
+    const updated = await this.adminRepository.update(admin._id, {
+      role,
+      lastLogin: now,
+      sso: {
+        protocol,
+        subject: identity.subject,
+        groups: identity.groups,
+        provisionedAt: admin.sso?.provisionedAt || now,
+        lastLoginAt: now,
+      },
+    });
+
+    if (!admin.sso) {
+      await this.audit(admin._id, 'admin.sso.link', { protocol, role, groups: identity.groups });
+    } else if (previousRole !== role) {
+      await this.audit(admin._id, 'admin.sso.role_change', { from: previousRole, to: role, groups: identity.groups });
+    }
+    return updated!;
+  }
+
+  private async openSession(admin: IAdmin, method: SsoProtocol, client: { ip?: string; userAgent?: string }): Promise<IAdminSession> {
+    const env = environmentConfig.get();
+
+    // Make room under the limit, signing out the oldest sessions
+    const active = await this.adminSessionRepository.findActive(admin._id);
+    const excess = active.slice(Math.max(env.ADMIN_MAX_SESSIONS - 1, 0));
+    if (excess.length > 0) {
+      await this.adminSessionRepository.revoke(excess.map(session => session._id), 'session_limit');
+    }
+
+    const now = new Date();
+    return await this.adminSessionRepository.create({
+      adminId: admin._id,
+      method,
+      ip: client.ip,
+      userAgent: client.userAgent,
+      lastSeenAt: now,
+      expiresAt: new Date(now.getTime() + env.ADMIN_SESSION_MAX_HOURS * 60 * 60 * 1000),
+    });
+  }
+
+  // ADMIN_SSO_ROLE_MAP is "group=role,group=role"
+  private mapRole(groups: string[]): AdminRole | null {
+    const roles = new Set<string>();
+    for (const entry of environmentConfig.get().ADMIN_SSO_ROLE_MAP.split(',')) {
+      const [group, role] = entry.split('=').map(part => part.trim());
+      if (group && role && groups.includes(group)) roles.add(role);
+    }
+    return ROLE_RANK.find(role => roles.has(role)) || null;
+  }
+
+  private toGroups(value: unknown): string[] {
+    if (Array.isArray(value)) return value.map(String);
+    return typeof value === 'string' && value ? [value] : [];
+  }
+
+  private async verifyIdToken(idToken: string, nonce: string): Promise<Record<string, any>> {
+    const decoded = jwt.decode(idToken, { complete: true });
+    const kid = decoded?.header.kid;
+    if (!decoded || !kid) {
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+
+    const discovery = await this.getDiscovery();
+    let key = this.jwks.get(kid);
+    if (!key) {
+      // Keys rotate; reload once for a key we haven't seen
+      await this.loadJwks(discovery.jwks_uri);
+      key = this.jwks.get(kid);
+    }
+    if (!key) {
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+
+    let claims: Record<string, any>;
+    try {
+      claims = jwt.verify(idToken, key, {
+        issuer: discovery.issuer,
+        audience: environmentConfig.get().ADMIN_OIDC_CLIENT_ID,
+        algorithms: ['RS256', 'PS256', 'ES256'],
+      }) as Record<string, any>;
+    } catch (error) {
+      logger.warn('OIDC ID token rejected', { error: error instanceof Error ? error.message : String(error) });
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+
+    if (claims.nonce !== nonce) {
+      throw new AdminSsoError('Sign-in failed at the identity provider', 401);
+    }
+    return claims;
+  }
+
+  private async getDiscovery(): Promise<OidcDiscovery> {
+    if (this.discovery && Date.now() - this.discovery.loadedAt < DISCOVERY_TTL) {
+      return this.discovery.value;
+    }
+
+    const issuer = environmentConfig.get().ADMIN_OIDC_ISSUER!.replace(/\/$/, '');
+    const response = await fetch(`${issuer}/.well-known/openid-configuration`, {
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok) {
+      throw new Error(`OIDC discovery failed with ${response.status}`);
+    }
+
+    this.discovery = { value: await response.json(), loadedAt: Date.now() };
+    return this.discovery.value;
+  }
+
+  private async loadJwks(uri: string): Promise<void> {
+    const response = await fetch(uri, { signal: AbortSignal.timeout(REQUEST_TIMEOUT) });
+    if (!response.ok) {
+      throw new Error(`OIDC key fetch failed with ${response.status}`);
+    }
+
+    const { keys } = await response.json();
+    this.jwks.clear();
+    for (const jwk of keys || []) {
+      if (jwk.kid && (!jwk.use || jwk.use === 'sig')) {
+        this.jwks.set(jwk.kid, crypto.createPublicKey({ key: jwk, format: 'jwk' }));
+      }
+    }
+  }
+
+  private saml(): SAML {
+    const env = environmentConfig.get();
+    return new SAML({
+      entryPoint: env.ADMIN_SAML_ENTRY_POINT,
+      issuer: env.ADMIN_SAML_ISSUER!,
+      audience: env.ADMIN_SAML_ISSUER!,
+      callbackUrl: this.callbackUrl(),
+      idpCert: env.ADMIN_SAML_IDP_CERT!,
+      wantAssertionsSigned: true,
+      acceptedClockSkewMs: 60 * 1000,
+    });
+  }
+
+  private requireProtocol(): SsoProtocol {
+    const protocol = this.getProtocol();
+    if (!protocol) {
+      throw new AdminSsoError('Single sign-on is not enabled', 404);
+    }
+    return protocol;
+  }
+
+  private callbackUrl(): string {
+    return `${environmentConfig.get().API_URL.replace(/\/$/, '')}/admin/auth/sso/callback`;
+  }
+
+  // Only paths within the dashboard, never another site
+  private safeReturnTo(returnTo?: string): string | undefined {
+    return returnTo && returnTo.startsWith('/') && !returnTo.startsWith('//') ? returnTo : undefined;
+  }
+
+  private idleTimeout(): number {
+    return environmentConfig.get().ADMIN_SESSION_IDLE_MINUTES * 60 * 1000;
+  }
+
+  private async uniqueUsername(email: string): Promise<string> {
+    const base = email.split('@')[0].toLowerCase().replace(/[^a-z0-9._-]/g, '') || 'admin';
+    let username = base;
+    for (let i = 2; await this.adminRepository.findByUsername(username); i++) {
+      username = `${base}${i}`;
+    }
+    return username;
+  }
+
+  private async saveState(state: string, loginState: LoginState): Promise<void> {
+    if (!this.redis) {
+      const now = Date.now();
+      for (const [key, entry] of this.memoryStates) {
+        if (entry.expiresAt <= now) this.memoryStates.delete(key);
+      }
+      this.memoryStates.set(state, { state: loginState, expiresAt: now + STATE_TTL * 1000 });
+      return;
+    }
+    await this.redis.setex(`admin-sso:state:${state}`, STATE_TTL, JSON.stringify(loginState));
+  }
+
+  // Each state can be used once
+  private async takeState(state: string): Promise<LoginState> {
+    let loginState: LoginState | null = null;
+
+    if (!this.redis) {
+      const entry = this.memoryStates.get(state);
+      this.memoryStates.delete(state);
+      loginState = entry && entry.expiresAt > Date.now() ? entry.state : null;
+    } else {
+      const stored = await this.redis.getdel(`admin-sso:state:${state}`);
+      loginState = stored ? JSON.parse(stored) : null;
+    }
+
+    if (!loginState) {
+      throw new AdminSsoError('Sign-in expired, please start again', 400);
+    }
+    return loginState;
+  }
+
+  private async audit(adminId: Types.ObjectId, action: string, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId,
+      action,
+      targetType: 'admin',
+      targetId: adminId,
+      details,
+    });
+  }
+}
+
+export class AdminSsoError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'AdminSsoError';
+  }
+}
+
+export const adminSsoService = new AdminSsoService();
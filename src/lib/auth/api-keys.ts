@@ -0,0 +1,146 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { ApiKeyRepository } from '../database/repositories/api-key';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { IApiKey, ApiKeyScope } from '../database/models/api-key';
+import { CreateApiKeyInput } from '../database/schemas/api-key';
+import { organizationService } from '../organizations';
+import { AUTH_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+
+// What the auth middleware attaches to req.apiKey
+export interface ApiKeyGrant {
+  id: string;
+  scopes: ApiKeyScope[];
+}
+
+function hashKey(key: string): string {
+  return crypto.createHash('sha256').update(key).digest('hex');
+}
+
+export function isApiKey(token: string): boolean {
+  return token.startsWith(AUTH_CONSTANTS.API_KEY_PREFIX);
+}
+
+// Whether the request's API key carries the scope
+export function apiKeyAllows(apiKey: ApiKeyGrant | undefined, scope: ApiKeyScope): boolean {
+  return !!apiKey && apiKey.scopes.includes(scope);
+}
+
+// API keys: bearer keys admins issue to outside systems, such as an identity
+// provider provisioning accounts over SCIM. A key acts as no user and is
+// only accepted by endpoints that ask for one of its scopes, and works within
+// its organization (or the shared pool). Only the hash is stored; the key is
+// shown once at creation. Issuing and revoking keys is audit-logged. Org
+// admins only see and manage their own organization's keys.
+export class ApiKeyService {
+  private apiKeyRepository = new ApiKeyRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+
+  // Mint a key; the returned string is the only copy
+  async create(
+    adminId: string,
+    input: CreateApiKeyInput,
+    adminOrganizationId?: string
+  ): Promise<{ key: IApiKey; secret: string }> {
+    const organizationId = adminOrganizationId || input.organizationId;
+    if (organizationId && !(await organizationService.getCached(organizationId))) {
+      throw new ApiKeyError('Organization not found', 404);
+    }
+
+    const secret = AUTH_CONSTANTS.API_KEY_PREFIX + crypto.randomBytes(32).toString('base64url');
+
+    const key = await this.apiKeyRepository.create({
+      name: input.name,
+      keyHash: hashKey(secret),
+      keyPrefix: secret.slice(0, AUTH_CONSTANTS.API_KEY_PREFIX.length + 6),
+      scopes: [...new Set(input.scopes)],
+      createdBy: new Types.ObjectId(adminId),
+      ...(organizationId && { organizationId: new Types.ObjectId(organizationId) }),
+      expiresAt: input.expiresInDays
+        ? new Date(Date.now() + input.expiresInDays * 24 * 60 * 60 * 1000)
+        : undefined,
+    });
+
+    await this.audit(adminId, 'api_key.create', key._id, { name: key.name, scopes: key.scopes, organizationId });
+    logger.info('API key created', { adminId, keyId: key._id.toString(), scopes: key.scopes });
+
+    return { key, secret };
+  }
+
+  async list(includeRevoked: boolean = false, adminOrganizationId?: string): Promise<IApiKey[]> {
+    return await this.apiKeyRepository.list(includeRevoked, adminOrganizationId);
+  }
+
+  async revoke(adminId: string, keyId: string, adminOrganizationId?: string): Promise<void> {
+    const existing = Types.ObjectId.isValid(keyId) ? await this.apiKeyRepository.findById(keyId) : null;
+    if (!existing || (adminOrganizationId && existing.organizationId?.toString() !== adminOrganizationId)) {
+      throw new ApiKeyError('API key not found', 404);
+    }
+
+    const key = await this.apiKeyRepository.revoke(existing._id);
+    if (!key) {
+      throw new ApiKeyError('API key has already been revoked', 409);
+    }
+
+    await this.audit(adminId, 'api_key.revoke', key._id, { name: key.name });
+    logger.info('API key revoked', { adminId, keyId });
+  }
+
+  // Resolve a presented key. Unknown, revoked and expired keys all return
+  // null.
+  async verify(secret: string, ip?: string): Promise<IApiKey | null> {
+    if (!isApiKey(secret)) {
+      return null;
+    }
+
+    const key = await this.apiKeyRepository.findByHash(hashKey(secret));
+    if (!key || key.revokedAt || (key.expiresAt && key.expiresAt <= new Date())) {
+      return null;
+    }
+
+    this.apiKeyRepository.recordUse(key._id, ip).catch(error => {
+      logger.error('Failed to record API key use', error, { keyId: key._id.toString() });
+    });
+
+    return key;
+  }
+
+  private async audit(adminId: string, action: string, keyId: Types.ObjectId, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action,
+      targetType: 'api_key',
+      targetId: keyId,
+      details,
+    });
+  }
+}
+
+// Admin-facing view of a key (never includes the secret)
+export function serializeApiKey(key: IApiKey) {
+  return {
+    id: key._id.toString(),
+    name: key.name,
+    keyPrefix: key.keyPrefix,
+    scopes: key.scopes,
+    createdBy: key.createdBy.toString(),
+    organizationId: key.organizationId?.toString(),
+    expiresAt: key.expiresAt,
+    expired: !!key.expiresAt && key.expiresAt <= new Date(),
+    revoked: !!key.revokedAt,
+    revokedAt: key.revokedAt,
+    lastUsedAt: key.lastUsedAt,
+    lastUsedIp: key.lastUsedIp,
+    createdAt: key.createdAt,
+  };
+}
+
+export class ApiKeyError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ApiKeyError';
+  }
+}
+
+export const apiKeyService = new ApiKeyService();
@@ -0,0 +1,335 @@
+import { Types } from 'mongoose';
+import { GuestLinkRepository } from '../database/repositories/guest-link';
+import { GroupRepository } from '../database/repositories/group';
+import { UserRepository } from '../database/repositories/user';
+import { IGuestLink, IGuestCapabilities } from '../database/models/guest-link';
+import { IChat } from '../database/models/chat';
+import { IUser } from '../database/models/user';
+import { CreateGuestLinkInput, JoinAsGuestInput } from '../database/schemas/group';
+import { organizationService } from '../organizations';
+import { permissionService } from '../security/permissions';
+import { distributedLock } from '../database/locks';
+import { jwtService, TokenPair } from './jwt';
+import { environmentConfig } from '../config/environment';
+import { GROUP_CONSTANTS, GUEST_CONSTANTS } from '../utils/constants';
+import { CryptoUtils } from '../utils/crypto';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 60 * 1000;
+const BATCH_SIZE = 100;
+
+export type GuestCapability = keyof IGuestCapabilities;
+
+// Whether a guest's time in the chat is over; false for regular accounts
+export function isGuestExpired(user: Pick<IUser, 'guest'>, now: Date = new Date()): boolean {
+  if (!user.guest) return false;
+  return !!user.guest.endedAt || user.guest.expiresAt <= now;
+}
+
+// Whether the account may use a capability guests only get when the link
+// grants it; regular accounts always may
+export function guestCan(user: Pick<IUser, 'guest'>, capability: GuestCapability): boolean {
+  return !user.guest || !!user.guest.capabilities?.[capability];
+}
+
+// Guest links let group admins bring in people without an account, e.g. a
+// vendor for a week. Joining through a link creates a guest account that:
+//   - is in that one group and can't create groups or add anyone
+//   - can't place or join calls or upload files unless the link allows it
+//   - is shown as a guest to the other members
+//   - ends after the link's guest period: the guest is taken out of the
+//     group and their sessions stop working
+// Revoking a link ends the access of everyone who joined through it. Admins
+// can turn guest links off, globally or for an organization.
+export class GuestAccessService {
+  private guestLinkRepository = new GuestLinkRepository();
+  private groupRepository = new GroupRepository();
+  private userRepository = new UserRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Start ending guest access as it expires. Safe to run on several
+  // instances: each guest is ended only once.
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Create a link; group admins only
+  async createLink(groupId: string, userId: string, input: CreateGuestLinkInput): Promise<{ link: IGuestLink; group: IChat }> {
+    const group = await this.getManagedGroup(groupId, userId);
+    await this.assertEnabled(group);
+
+    if (await this.guestLinkRepository.countActive(group._id) >= GUEST_CONSTANTS.MAX_ACTIVE_LINKS) {
+      throw new GuestAccessError(`Groups can have at most ${GUEST_CONSTANTS.MAX_ACTIVE_LINKS} active guest links`, 409);
+    }
+
+    const link = await this.guestLinkRepository.create({
+      chatId: group._id,
+      createdBy: new Types.ObjectId(userId),
+      token: CryptoUtils.generateRandomBytes(24).toString('base64url'),
+      label: input.label,
+      guestHours: input.guestHours,
+      capabilities: { calls: input.allowCalls, fileUpload: input.allowFileUpload },
+      expiresAt: new Date(Date.now() + input.expiresInHours * 60 * 60 * 1000),
+      maxUses: input.maxUses,
+    });
+
+    metricsCollector.incrementCounter('guest_links_created', 1);
+    logger.info('Guest link created', {
+      groupId,
+      userId,
+      linkId: link._id.toString(),
+      expiresAt: link.expiresAt,
+      guestHours: link.guestHours,
+      capabilities: link.capabilities,
+    });
+
+    return { link, group };
+  }
+
+  // Links of a group; group admins only
+  async listLinks(groupId: string, userId: string): Promise<IGuestLink[]> {
+    const group = await this.getManagedGroup(groupId, userId);
+    return await this.guestLinkRepository.findByChat(group._id);
+  }
+
+  // Revoke a link and end the access of the guests who joined through it;
+  // group admins only
+  async revokeLink(groupId: string, linkId: string, userId: string): Promise<{ link: IGuestLink; guestsRemoved: number }> {
+    const group = await this.getManagedGroup(groupId, userId);
+    const link = Types.ObjectId.isValid(linkId) ? await this.guestLinkRepository.findById(linkId) : null;
+    if (!link || !link.chatId.equals(group._id)) {
+      throw new GuestAccessError('Link not found', 404);
+    }
+
+    const revoked = await this.guestLinkRepository.revoke(link._id);
+    if (!revoked) {
+      throw new GuestAccessError('Link has already been revoked', 409);
+    }
+
+    let guestsRemoved = 0;
+    for (const guest of await this.userRepository.findActiveGuestsByLink(link._id)) {
+      if (await this.endAccess(guest, 'revoked')) guestsRemoved++;
+    }
+
+    logger.info('Guest link revoked', { groupId, linkId, userId, guestsRemoved });
+    return { link: revoked, guestsRemoved };
+  }
+
+  // What someone opening a link sees before joining
+  async preview(token: string): Promise<{ link: IGuestLink; group: IChat }> {
+    const { link, group } = await this.resolve(token);
+    await this.assertEnabled(group);
+    return { link, group };
+  }
+
+  // Join a group through a link, getting a guest account and its tokens
+  async join(input: JoinAsGuestInput): Promise<{ user: IUser; group: IChat; tokens: TokenPair }> {
+    const { link, group } = await this.resolve(input.token);
+    await this.assertEnabled(group);
+
+    if (await organizationService.isSuspended(group.organizationId)) {
+      throw new GuestAccessError('Link not found', 404);
+    }
+
+    const claimed = await this.guestLinkRepository.claimUse(link._id);
+    if (!claimed) {
+      throw new GuestAccessError('Link not found', 404);
+    }
+
+    // Guests never outlast the link's guest period
+    const expiresAt = new Date(Date.now() + claimed.guestHours * 60 * 60 * 1000);
+
+    let user: IUser | null = null;
+    try {
+      user = await this.userRepository.createGuest({
+        displayName: input.displayName,
+        organizationId: group.organizationId,
+        guest: {
+          linkId: claimed._id,
+          chatId: group._id,
+          expiresAt,
+          capabilities: { calls: claimed.capabilities.calls, fileUpload: claimed.capabilities.fileUpload },
+        },
+      });
+      const guestId = user._id;
+
+      const added = await distributedLock.withLock(`group:${group._id}`, async () => {
+        const current = await this.groupRepository.findById(group._id);
+        if (!current || current.participants.length >= GROUP_CONSTANTS.MAX_PARTICIPANTS) {
+          return false;
+        }
+        await this.groupRepository.addParticipants(group._id, [guestId]);
+        return true;
+      });
+      if (!added) {
+        throw new GuestAccessError(`Groups can have at most ${GROUP_CONSTANTS.MAX_PARTICIPANTS} members`, 409);
+      }
+    } catch (error) {
+      await this.guestLinkRepository.releaseUse(claimed._id);
+      if (user) await this.userRepository.endGuest(user._id);
+      throw error;
+    }
+
+    const deviceId = input.deviceId || CryptoUtils.generateUUID();
+    const tokens = jwtService.generateTokenPair(user, deviceId);
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToChat(group._id.toString(), 'group:guest:joined', {
+      groupId: group._id.toString(),
+      guest: serializeGuest(user),
+    });
+
+    const { automationService } = await import('../integrations/automation');
+    automationService.membersJoined(group._id.toString(), [user._id.toString()]).catch(error => {
+      logger.error('Failed to run automation rules for guest', error, { groupId: group._id.toString() });
+    });
+
+    metricsCollector.incrementCounter('guests_joined', 1);
+    logger.info('Guest joined group', {
+      groupId: group._id.toString(),
+      linkId: claimed._id.toString(),
+      guestId: user._id.toString(),
+      expiresAt,
+    });
+
+    return { user, group, tokens };
+  }
+
+  // Whether a user may use a capability; for checks that only have the ID
+  async allows(userId: string, capability: GuestCapability): Promise<boolean> {
+    const user = await this.userRepository.findById(userId);
+    return !user || guestCan(user, capability);
+  }
+
+  getUrl(link: IGuestLink): string {
+    return `${environmentConfig.getValue('FRONTEND_URL')}/guest/${link.token}`;
+  }
+
+  // Take the guest out of the group and end their sessions. False when
+  // another instance already did.
+  private async endAccess(guest: IUser, reason: 'expired' | 'revoked'): Promise<boolean> {
+    const ended = await this.userRepository.endGuest(guest._id);
+    if (!ended || !ended.guest) return false;
+
+    const groupId = ended.guest.chatId.toString();
+    await distributedLock.withLock(`group:${groupId}`, () =>
+      this.groupRepository.removeParticipants(groupId, [ended._id])
+    );
+    await jwtService.invalidateAllUserTokens(ended._id.toString());
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToChat(groupId, 'group:guest:left', {
+      groupId,
+      guestId: ended._id.toString(),
+      reason,
+    });
+    socketManager.disconnectUser(ended._id.toString());
+
+    metricsCollector.incrementCounter('guests_ended', 1, { reason });
+    logger.info('Guest access ended', { groupId, guestId: ended._id.toString(), reason });
+    return true;
+  }
+
+  private async resolve(token: string): Promise<{ link: IGuestLink; group: IChat }> {
+    const link = await this.guestLinkRepository.findByToken(token);
+    if (!link || !isUsable(link)) {
+      throw new GuestAccessError('Link not found', 404);
+    }
+
+    const group = await this.groupRepository.findById(link.chatId);
+    if (!group || group.type !== 'group') {
+      throw new GuestAccessError('Link not found', 404);
+    }
+    return { link, group };
+  }
+
+  // Guests are never group admins, so a guest can't make links either
+  private async getManagedGroup(groupId: string, userId: string): Promise<IChat> {
+    const group = Types.ObjectId.isValid(groupId) ? await this.groupRepository.findById(groupId) : null;
+    if (!group || group.type !== 'group' || !(await permissionService.canManageGroup(userId, groupId, 'manage_guests'))) {
+      throw new GuestAccessError('Only group admins can manage guest links', 403);
+    }
+    return group;
+  }
+
+  // Enabled globally and for the group's organization
+  private async assertEnabled(group: IChat): Promise<void> {
+    const features = await organizationService.getFeatures(group.organizationId);
+    if (features.guestLinks === false) {
+      throw new GuestAccessError('Guest links are disabled', 403);
+    }
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const expired = await this.userRepository.findExpiredGuests(new Date(), BATCH_SIZE);
+      for (const guest of expired) {
+        await this.endAccess(guest, 'expired');
+      }
+    } catch (error) {
+      logger.error('Guest expiry worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+function isUsable(link: IGuestLink): boolean {
+  if (link.revokedAt) return false;
+  if (link.expiresAt <= new Date()) return false;
+  return !link.maxUses || link.useCount < link.maxUses;
+}
+
+// How a guest is shown to the other members
+export function serializeGuest(user: IUser) {
+  return {
+    userId: user._id.toString(),
+    displayName: user.displayName,
+    isGuest: true,
+    expiresAt: user.guest?.expiresAt,
+    capabilities: user.guest?.capabilities,
+  };
+}
+
+export function serializeGuestLink(link: IGuestLink) {
+  return {
+    id: link._id.toString(),
+    chatId: link.chatId.toString(),
+    url: guestAccessService.getUrl(link),
+    label: link.label,
+    guestHours: link.guestHours,
+    capabilities: link.capabilities,
+    expiresAt: link.expiresAt,
+    maxUses: link.maxUses,
+    useCount: link.useCount,
+    lastUsedAt: link.lastUsedAt,
+    active: isUsable(link),
+    revokedAt: link.revokedAt,
+    createdAt: link.createdAt,
+  };
+}
+
+export class GuestAccessError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'GuestAccessError';
+  }
+}
+
+export const guestAccessService = new GuestAccessService();
@@ -17,6 +17,17 @@ export interface JWTPayload {
   jti: string; // JWT ID for blacklisting
 }
 
+// Admin dashboard sessions started through SSO, see admin-sso
+export interface AdminTokenPayload {
+  adminId: string;
+  sessionId: string;
+  email: string;
+  displayName: string;
+  iat: number;
+  exp: number;
+  jti: string;
+}
+
 export interface RefreshTokenPayload {
   userId: string;
   deviceId: string;
@@ -175,6 +186,36 @@ export class JWTService {
     }
   }
 
+  // Generate admin session token; expiresIn in seconds
+  generateAdminToken(admin: { _id: Types.ObjectId | string; email: string; displayName: string }, sessionId: string, expiresIn: number): string {
+    const payload: Omit<AdminTokenPayload, 'iat' | 'exp'> = {
+      adminId: admin._id.toString(),
+      sessionId,
+      email: admin.email,
+      displayName: admin.displayName,
+      jti: CryptoUtils.generateUUID(),
+    };
+
+    return jwt.sign(payload, this.jwtSecret, {
+      expiresIn,
+      issuer: 'chatapp',
+      audience: 'chatapp-admin',
+    });
+  }
+
+  // Verify admin session token. Revocation is tracked on the session, not
+  // the token.
+  verifyAdminToken(token: string): AdminTokenPayload | null {
+    try {
+      return jwt.verify(token, this.jwtSecret, {
+        issuer: 'chatapp',
+        audience: 'chatapp-admin',
+      }) as AdminTokenPayload;
+    } catch {
+      return null;
+    }
+  }
+
   // Verify refresh token
   async verifyRefreshToken(token: string): Promise<{
     valid: boolean;
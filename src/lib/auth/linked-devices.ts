@@ -0,0 +1,110 @@
+import { UserRepository } from '../database/repositories/user';
+import { IUser } from '../database/models/user';
+import { jwtService } from './jwt';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+type Device = IUser['devices'][number];
+
+// The devices signed in to an account, for the linked devices screen.
+// Desktops linked by QR code (see qr-auth) can be signed out from any other
+// device: their tokens are revoked and their open sockets dropped.
+export class LinkedDeviceService {
+  private userRepository = new UserRepository();
+
+  async list(userId: string): Promise<Device[]> {
+    const user = await this.userRepository.findById(userId);
+    if (!user) {
+      throw new LinkedDeviceError('User not found', 404);
+    }
+    return [...user.devices].sort((a, b) => b.lastActive.getTime() - a.lastActive.getTime());
+  }
+
+  // Sign a linked device out. The current device signs out with logout.
+  async revoke(userId: string, deviceId: string, currentDeviceId?: string): Promise<Device> {
+    if (deviceId === currentDeviceId) {
+      throw new LinkedDeviceError('Log out to sign this device out', 400);
+    }
+
+    const user = await this.userRepository.findById(userId);
+    const device = user?.devices.find(entry => entry.deviceId === deviceId);
+    if (!device) {
+      throw new LinkedDeviceError('Device not found', 404);
+    }
+    if (!device.linkedAt) {
+      throw new LinkedDeviceError('Only linked devices can be signed out remotely', 400);
+    }
+
+    await jwtService.invalidateDeviceTokens(userId, deviceId);
+    await this.userRepository.removeDevice(userId, deviceId);
+    await this.disconnect(userId, deviceId);
+
+    metricsCollector.incrementCounter('linked_devices_revoked', 1);
+    logger.info('Linked device revoked', { userId, deviceId, revokedBy: currentDeviceId });
+
+    return device;
+  }
+
+  // Turn low-data mode on or off for one of the user's devices, including
+  // its open sockets
+  async setLowDataMode(userId: string, deviceId: string, enabled: boolean): Promise<void> {
+    if (!(await this.userRepository.setDeviceLowDataMode(userId, deviceId, enabled))) {
+      throw new LinkedDeviceError('Device not found', 404);
+    }
+
+    const { socketManager } = await import('../realtime/socket');
+    await socketManager.setDeviceLowData(userId, deviceId, enabled);
+    socketManager.emitToUser(userId, 'device:low-data:changed', { deviceId, lowDataMode: enabled });
+
+    logger.info('Device low-data mode changed', { userId, deviceId, enabled });
+  }
+
+  // Drop the device's sockets and tell the user's other devices
+  private async disconnect(userId: string, deviceId: string): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const io = socketManager.getIO();
+
+    socketManager.emitToUser(userId, 'device:revoked', { deviceId });
+
+    if (!io) return;
+    const sockets = await io.in(`user:${userId}`).fetchSockets();
+    for (const socket of sockets) {
+      if (socket.data.deviceId === deviceId) {
+        socket.disconnect(true);
+      }
+    }
+  }
+}
+
+// Client-facing view of a device
+export function serializeDevice(device: Device, currentDeviceId?: string) {
+  return {
+    deviceId: device.deviceId,
+    platform: device.platform,
+    deviceModel: device.deviceModel,
+    appVersion: device.appVersion,
+    lastActive: device.lastActive,
+    location: device.location && {
+      country: device.location.country,
+      city: device.location.city,
+    },
+    linked: !!device.linkedAt,
+    linkedAt: device.linkedAt,
+    lowDataMode: !!device.lowDataMode,
+    current: device.deviceId === currentDeviceId,
+  };
+}
+
+// Whether the device a request or socket came from is in low-data mode
+export function isLowDataDevice(user: Pick<IUser, 'devices'> | null, deviceId?: string): boolean {
+  return !!deviceId && !!user?.devices.some(device => device.deviceId === deviceId && device.lowDataMode);
+}
+
+export class LinkedDeviceError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'LinkedDeviceError';
+  }
+}
+
+export const linkedDeviceService = new LinkedDeviceService();
@@ -1,10 +1,23 @@
 import { Request, Response, NextFunction } from 'express';
 import { jwtService, JWTPayload } from './jwt';
+import { adminSsoService } from './admin-sso';
+import { personalTokenService, isPersonalAccessToken, PersonalTokenGrant } from './personal-tokens';
+import { apiKeyService, ApiKeyGrant } from './api-keys';
+import { isGuestExpired } from './guest-access';
+import { PersonalAccessTokenScope } from '../database/models/personal-access-token';
+import { ApiKeyScope } from '../database/models/api-key';
 import { UserRepository } from '../database/repositories/user';
+import { IUser } from '../database/models/user';
+import { getPendingConsents } from '../compliance/consents';
+import { legalNoticeService } from '../compliance/legal-notices';
+import { loginApprovalService } from '../security/login-approvals';
+import { adminConfigService } from '../config/admin-config';
+import { organizationService } from '../organizations';
 import { permissionService, Permission } from '../security/permissions';
 import { rateLimitConfig } from '../config/rate-limits';
 import { logger } from '../monitoring/logging';
 import { ErrorHandler } from '../utils/error-handler';
+import { ERROR_CODES } from '../utils/constants';
 
 // Extend Express Request to include user info
 declare global {
@@ -12,8 +25,12 @@ declare global {
     interface Request {
       user?: JWTPayload & {
         permissions: Permission[];
+        personalToken?: PersonalTokenGrant; // set when a personal access token was used
       };
       deviceId?: string;
+      adminSessionId?: string; // set for admin dashboard sessions from SSO
+      apiKey?: ApiKeyGrant; // set for requests made with an admin-issued API key
+      organizationId?: string; // tenant of the user, org admin or API key; unset for the shared pool
     }
   }
 }
@@ -23,6 +40,16 @@ export interface AuthOptions {
   permissions?: Permission[];
   roles?: string[];
   verifiedOnly?: boolean;
+  // Accept personal access tokens carrying any of these scopes; handlers
+  // check the exact scope per operation. Endpoints without it only accept
+  // login sessions.
+  personalTokenScopes?: PersonalAccessTokenScope[];
+  // Let users through who haven't accepted the current mandatory consents
+  // and legal notices (the endpoints that accept them)
+  allowPendingConsents?: boolean;
+  // Refuse sessions whose sign-in from a new device or location the user
+  // hasn't approved yet (account and credential changes)
+  sensitive?: boolean;
 }
 
 class AuthMiddleware {
@@ -45,6 +72,10 @@ class AuthMiddleware {
           return next();
         }
 
+        if (isPersonalAccessToken(token)) {
+          return await this.authenticatePersonalToken(req, token, options, next);
+        }
+
         // Verify token
         const tokenResult = await jwtService.verifyAccessToken(token);
 
@@ -70,11 +101,32 @@ class AuthMiddleware {
           return next(ErrorHandler.authenticationError('Account has been suspended'));
         }
 
+        if (await organizationService.isSuspended(user.organizationId)) {
+          return next(ErrorHandler.authenticationError('Your organization has been suspended'));
+        }
+
+        if (isGuestExpired(user)) {
+          return next(ErrorHandler.authenticationError('Your guest access has ended'));
+        }
+
         // Check verification requirement
         if (options.verifiedOnly && !user.isVerified) {
           return next(ErrorHandler.authorizationError('Account verification required'));
         }
 
+        const consentError = await this.checkConsents(user, options);
+        if (consentError) {
+          return next(consentError);
+        }
+
+        if (options.sensitive && await loginApprovalService.isPending(payload.userId, payload.deviceId)) {
+          return next(ErrorHandler.createError(
+            'Approve this sign-in from one of your other devices first',
+            403,
+            ERROR_CODES.LOGIN_APPROVAL_REQUIRED
+          ));
+        }
+
         // Get user permissions
         const permissions = permissionService.getUserPermissions(user);
 
@@ -92,6 +144,7 @@ class AuthMiddleware {
           permissions,
         };
         req.deviceId = payload.deviceId;
+        req.organizationId = user.organizationId?.toString();
 
         // Log authentication success
         logger.debug('User authenticated successfully', {
@@ -111,6 +164,94 @@ class AuthMiddleware {
     };
   }
 
+  // Personal access token authentication, for endpoints that opt in
+  private async authenticatePersonalToken(
+    req: Request,
+    secret: string,
+    options: AuthOptions,
+    next: NextFunction
+  ) {
+    if (!options.personalTokenScopes || options.personalTokenScopes.length === 0) {
+      return next(ErrorHandler.authorizationError('Personal access tokens are not accepted for this endpoint'));
+    }
+
+    const token = await personalTokenService.verify(secret, req.ip);
+    if (!token) {
+      return next(ErrorHandler.authenticationError('Invalid or expired personal access token'));
+    }
+
+    if (!options.personalTokenScopes.some(scope => token.scopes.includes(scope))) {
+      return next(ErrorHandler.authorizationError(`Token needs one of these scopes: ${options.personalTokenScopes.join(', ')}`));
+    }
+
+    const user = await this.userRepository.findById(token.userId);
+    if (!user || user.isBanned) {
+      return next(ErrorHandler.authenticationError('Account has been suspended'));
+    }
+
+    if (await organizationService.isSuspended(user.organizationId)) {
+      return next(ErrorHandler.authenticationError('Your organization has been suspended'));
+    }
+
+    if (options.verifiedOnly && !user.isVerified) {
+      return next(ErrorHandler.authorizationError('Account verification required'));
+    }
+
+    const consentError = await this.checkConsents(user, options);
+    if (consentError) {
+      return next(consentError);
+    }
+
+    const tokenId = token._id.toString();
+
+    // Tokens act as the user but never carry the user's role permissions
+    req.user = {
+      userId: user._id.toString(),
+      email: user.email,
+      phoneNumber: user.phoneNumber,
+      displayName: user.displayName,
+      isVerified: user.isVerified,
+      deviceId: `pat:${tokenId}`,
+      iat: Math.floor(token.createdAt.getTime() / 1000),
+      exp: Math.floor(token.expiresAt.getTime() / 1000),
+      jti: tokenId,
+      permissions: [],
+      personalToken: {
+        id: tokenId,
+        scopes: token.scopes,
+        chatIds: token.chatIds.map(id => id.toString()),
+      },
+    };
+    req.deviceId = req.user.deviceId;
+    req.organizationId = user.organizationId?.toString();
+
+    logger.debug('Personal access token authenticated', {
+      userId: req.user.userId,
+      tokenId,
+      path: req.path,
+    });
+
+    next();
+  }
+
+  // Block users who still have mandatory consents or legal notices to accept
+  private async checkConsents(user: IUser, options: AuthOptions) {
+    if (options.allowPendingConsents) return null;
+
+    const { compliance } = await adminConfigService.get();
+    const pending = [
+      ...getPendingConsents(user, compliance.consentTypes || []),
+      ...(await legalNoticeService.getPending(user)),
+    ];
+    if (pending.length === 0) return null;
+
+    return ErrorHandler.createError(
+      `Please review and accept: ${pending.map(status => status.title).join(', ')}`,
+      403,
+      ERROR_CODES.CONSENT_REQUIRED
+    );
+  }
+
   // Admin authentication middleware
   authenticateAdmin(requiredPermissions?: Permission[]) {
     return async (req: Request, res: Response, next: NextFunction) => {
@@ -121,6 +262,43 @@ class AuthMiddleware {
           return next(ErrorHandler.authenticationError('Admin authentication required'));
         }
 
+        // Dashboard sessions from single sign-on
+        const sso = await adminSsoService.authenticate(token);
+        if (sso) {
+          if (requiredPermissions && requiredPermissions.length > 0) {
+            if (!permissionService.hasPermissions(sso.admin, requiredPermissions)) {
+              return next(ErrorHandler.authorizationError('Insufficient admin permissions'));
+            }
+          }
+
+          req.user = {
+            userId: sso.admin._id.toString(),
+            email: sso.admin.email,
+            phoneNumber: '',
+            displayName: sso.admin.displayName,
+            isVerified: true,
+            deviceId: sso.session._id.toString(),
+            iat: sso.payload.iat,
+            exp: sso.payload.exp,
+            jti: sso.payload.jti,
+            permissions: permissionService.getUserPermissions(sso.admin),
+          };
+          req.adminSessionId = sso.session._id.toString();
+          req.organizationId = sso.admin.organizationId?.toString();
+
+          logger.info('Admin authenticated', {
+            adminId: sso.admin._id.toString(),
+            path: req.path,
+            permissions: requiredPermissions,
+          });
+          return next();
+        }
+
+        // Phone+password sessions; only a break-glass once SSO is on
+        if (!adminSsoService.isLocalLoginAllowed()) {
+          return next(ErrorHandler.authenticationError('Sign in through single sign-on'));
+        }
+
         // Verify token
         const tokenResult = await jwtService.verifyAccessToken(token);
         if (!tokenResult.valid) {
@@ -151,6 +329,10 @@ class AuthMiddleware {
           permissions: permissionService.getUserPermissions(user),
         };
 
+        if (adminSsoService.isEnabled()) {
+          logger.warn('Admin break-glass local login used', { userId: payload.userId, path: req.path });
+        }
+
         logger.info('Admin authenticated', {
           userId: payload.userId,
           path: req.path,
@@ -168,6 +350,45 @@ class AuthMiddleware {
     };
   }
 
+  // API key authentication for integrations (e.g. SCIM). Keys need any of
+  // the given scopes; handlers check the exact scope per operation. Keys are
+  // only read from the Authorization header.
+  authenticateApiKey(scopes: ApiKeyScope[]) {
+    return async (req: Request, res: Response, next: NextFunction) => {
+      try {
+        const authHeader = req.headers.authorization;
+        if (!authHeader || !authHeader.startsWith('Bearer ')) {
+          return next(ErrorHandler.authenticationError('API key required'));
+        }
+
+        const key = await apiKeyService.verify(authHeader.substring(7), req.ip);
+        if (!key) {
+          return next(ErrorHandler.authenticationError('Invalid or expired API key'));
+        }
+
+        if (!scopes.some(scope => key.scopes.includes(scope))) {
+          return next(ErrorHandler.authorizationError(`API key needs one of these scopes: ${scopes.join(', ')}`));
+        }
+
+        if (await organizationService.isSuspended(key.organizationId)) {
+          return next(ErrorHandler.authenticationError('The organization for this API key has been suspended'));
+        }
+
+        req.apiKey = { id: key._id.toString(), scopes: key.scopes };
+        req.organizationId = key.organizationId?.toString();
+
+        logger.debug('API key authenticated', { keyId: req.apiKey.id, path: req.path });
+        next();
+      } catch (error) {
+        logger.error(
+          'API key authentication error',
+          error instanceof Error ? error : new Error(String(error))
+        );
+        next(ErrorHandler.authenticationError('API key authentication failed'));
+      }
+    };
+  }
+
   // Optional authentication middleware
   authenticateOptional() {
     return this.authenticate({ required: false });
@@ -198,8 +419,18 @@ class AuthMiddleware {
           return next(new Error('User not found or banned'));
         }
 
+        if (isGuestExpired(user)) {
+          return next(new Error('Guest access ended'));
+        }
+
+        if (await organizationService.isSuspended(user.organizationId)) {
+          return next(new Error('Organization suspended'));
+        }
+
         // Attach user info to socket
         socket.userId = payload.userId;
+        socket.organizationId = user.organizationId?.toString();
+        socket.guest = user.guest?.capabilities;
         socket.user = {
           _id: payload.userId,
           displayName: payload.displayName,
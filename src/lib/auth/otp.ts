@@ -64,8 +64,9 @@ export class OTPService {
   async sendEmailOTP(
     email: string,
     userName: string,
-    purpose: 'registration' | 'login' | 'verification' = 'verification',
-    options?: Partial<OTPOptions>
+    purpose: 'registration' | 'login' | 'verification' | 'appeal' = 'verification',
+    options?: Partial<OTPOptions>,
+    locale?: string
   ): Promise<OTPSendResult> {
     try {
       const identifier = `email:${email}:${purpose}`;
@@ -99,7 +100,8 @@ export class OTPService {
         email,
         otp.code,
         userName,
-        Math.ceil((options?.expiresIn || this.defaultOptions.expiresIn) / (60 * 1000))
+        Math.ceil((options?.expiresIn || this.defaultOptions.expiresIn) / (60 * 1000)),
+        locale
       );
 
       if (!emailResult.success) {
@@ -140,8 +142,9 @@ export class OTPService {
   // Send OTP via SMS
   async sendSMSOTP(
     phoneNumber: string,
-    purpose: 'registration' | 'login' | 'verification' = 'verification',
-    options?: Partial<OTPOptions>
+    purpose: 'registration' | 'login' | 'verification' | 'appeal' = 'verification',
+    options?: Partial<OTPOptions>,
+    locale?: string
   ): Promise<OTPSendResult> {
     try {
       const identifier = `sms:${phoneNumber}:${purpose}`;
@@ -174,7 +177,8 @@ export class OTPService {
       const smsResult = await smsService.sendOTPSMS(
         phoneNumber,
         otp.code,
-        Math.ceil((options?.expiresIn || this.defaultOptions.expiresIn) / (60 * 1000))
+        Math.ceil((options?.expiresIn || this.defaultOptions.expiresIn) / (60 * 1000)),
+        locale
       );
 
       if (!smsResult.success) {
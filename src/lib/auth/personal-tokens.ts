@@ -0,0 +1,145 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { PersonalAccessTokenRepository } from '../database/repositories/personal-access-token';
+import { ChatRepository } from '../database/repositories/chat';
+import { IPersonalAccessToken, PersonalAccessTokenScope } from '../database/models/personal-access-token';
+import { CreatePersonalAccessTokenInput } from '../database/schemas/personal-access-token';
+import { AUTH_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+
+// What the auth middleware attaches to req.user for token requests
+export interface PersonalTokenGrant {
+  id: string;
+  scopes: PersonalAccessTokenScope[];
+  chatIds: string[];
+}
+
+function hashToken(token: string): string {
+  return crypto.createHash('sha256').update(token).digest('hex');
+}
+
+export function isPersonalAccessToken(token: string): boolean {
+  return token.startsWith(AUTH_CONSTANTS.PERSONAL_TOKEN_PREFIX);
+}
+
+// Whether a request may do this in a chat. Login sessions always may
+// (membership is checked separately); tokens need the scope and must not be
+// limited to other chats.
+export function personalTokenAllows(
+  user: { personalToken?: PersonalTokenGrant } | undefined,
+  scope: PersonalAccessTokenScope,
+  chatId: string
+): boolean {
+  const grant = user?.personalToken;
+  if (!grant) return true;
+
+  return grant.scopes.includes(scope) && (grant.chatIds.length === 0 || grant.chatIds.includes(chatId));
+}
+
+// Personal access tokens: long-lived bearer tokens users create for their own
+// automation. A token acts as its owner, limited to its scopes and chats, and
+// is only accepted by endpoints that opt in. Only the hash is stored; the
+// token is shown once at creation.
+export class PersonalTokenService {
+  private personalAccessTokenRepository = new PersonalAccessTokenRepository();
+  private chatRepository = new ChatRepository();
+
+  // Mint a token; the returned string is the only copy
+  async create(
+    userId: string,
+    input: CreatePersonalAccessTokenInput
+  ): Promise<{ token: IPersonalAccessToken; secret: string }> {
+    const active = await this.personalAccessTokenRepository.countActive(userId);
+    if (active >= AUTH_CONSTANTS.MAX_PERSONAL_TOKENS) {
+      throw new PersonalTokenError(`You can have at most ${AUTH_CONSTANTS.MAX_PERSONAL_TOKENS} active tokens`, 409);
+    }
+
+    const chatIds = [...new Set(input.chatIds || [])];
+    const memberships = await Promise.all(
+      chatIds.map(chatId => this.chatRepository.isParticipant(chatId, userId))
+    );
+    if (memberships.some(isMember => !isMember)) {
+      throw new PersonalTokenError('Tokens can only be limited to chats you are in', 400);
+    }
+
+    const secret = AUTH_CONSTANTS.PERSONAL_TOKEN_PREFIX + crypto.randomBytes(32).toString('base64url');
+
+    const token = await this.personalAccessTokenRepository.create({
+      userId: new Types.ObjectId(userId),
+      name: input.name,
+      tokenHash: hashToken(secret),
+      tokenPrefix: secret.slice(0, AUTH_CONSTANTS.PERSONAL_TOKEN_PREFIX.length + 6),
+      scopes: [...new Set(input.scopes)],
+      chatIds: chatIds.map(chatId => new Types.ObjectId(chatId)),
+      expiresAt: new Date(Date.now() + input.expiresInDays * 24 * 60 * 60 * 1000),
+    });
+
+    logger.info('Personal access token created', {
+      userId,
+      tokenId: token._id.toString(),
+      scopes: token.scopes,
+    });
+
+    return { token, secret };
+  }
+
+  async list(userId: string, includeRevoked: boolean = false): Promise<IPersonalAccessToken[]> {
+    return await this.personalAccessTokenRepository.findByUser(userId, includeRevoked);
+  }
+
+  async revoke(userId: string, tokenId: string): Promise<void> {
+    const revoked = Types.ObjectId.isValid(tokenId)
+      ? await this.personalAccessTokenRepository.revoke(tokenId, userId)
+      : false;
+    if (!revoked) {
+      throw new PersonalTokenError('Token not found', 404);
+    }
+
+    logger.info('Personal access token revoked', { userId, tokenId });
+  }
+
+  // Resolve a presented token. Unknown, revoked and expired tokens all
+  // return null.
+  async verify(secret: string, ip?: string): Promise<IPersonalAccessToken | null> {
+    if (!isPersonalAccessToken(secret)) {
+      return null;
+    }
+
+    const token = await this.personalAccessTokenRepository.findByHash(hashToken(secret));
+    if (!token || token.revokedAt || token.expiresAt <= new Date()) {
+      return null;
+    }
+
+    this.personalAccessTokenRepository.recordUse(token._id, ip).catch(error => {
+      logger.error('Failed to record personal token use', error, { tokenId: token._id.toString() });
+    });
+
+    return token;
+  }
+}
+
+// Client-facing view of a token (never includes the secret)
+export function serializePersonalAccessToken(token: IPersonalAccessToken) {
+  return {
+    id: token._id.toString(),
+    name: token.name,
+    tokenPrefix: token.tokenPrefix,
+    scopes: token.scopes,
+    chatIds: token.chatIds.map(id => id.toString()),
+    expiresAt: token.expiresAt,
+    expired: token.expiresAt <= new Date(),
+    revoked: !!token.revokedAt,
+    revokedAt: token.revokedAt,
+    lastUsedAt: token.lastUsedAt,
+    createdAt: token.createdAt,
+  };
+}
+
+export class PersonalTokenError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'PersonalTokenError';
+  }
+}
+
+export const personalTokenService = new PersonalTokenService();
@@ -10,6 +10,7 @@ import { DateUtils } from '../utils/date';
 export interface QRAuthSession {
   qrId: string;
   secret: string;
+  pollTokenHash: string; // the waiting desktop proves itself with the matching poll token
   status: 'pending' | 'scanned' | 'confirmed' | 'expired' | 'rejected';
   deviceInfo: {
     platform: string;
@@ -21,12 +22,19 @@ export interface QRAuthSession {
   scannedAt?: Date;
   confirmedAt?: Date;
   userId?: string;
+  linkedDeviceId?: string;
+  tokens?: { // kept until the desktop picks them up by polling
+    accessToken: string;
+    refreshToken: string;
+    expiresIn: number;
+  };
 }
 
 export interface QRGenerationResult {
   qrId: string;
   qrCode: string; // Base64 QR code image
   qrText: string; // Text that was encoded
+  pollToken: string; // given to the desktop only; needed to receive the tokens
   expiresAt: Date;
   checkUrl: string;
 }
@@ -69,11 +77,13 @@ export class QRAuthService {
       // Generate unique QR ID and secret
       const qrId = CryptoUtils.generateUUID();
       const secret = CryptoUtils.generateRandomString(32);
+      const pollToken = CryptoUtils.generateRandomString(32);
       
       // Create session
       const session: QRAuthSession = {
         qrId,
         secret,
+        pollTokenHash: CryptoUtils.hash(pollToken),
         status: 'pending',
         deviceInfo,
         createdAt: new Date(),
@@ -116,6 +126,7 @@ export class QRAuthService {
         qrId,
         qrCode,
         qrText,
+        pollToken,
         expiresAt: session.expiresAt,
         checkUrl,
       };
@@ -257,25 +268,48 @@ export class QRAuthService {
         };
       }
 
-      // Generate tokens for web client
+      // Only the phone links new devices, not another linked device
+      const approvingDevice = user.devices.find(device => device.deviceId === deviceId);
+      if (approvingDevice?.linkedAt) {
+        return {
+          success: false,
+          error: 'Linked devices cannot link other devices',
+        };
+      }
+
+      // Generate tokens for web client and add it to the linked devices
       const webDeviceId = `web-${CryptoUtils.generateRandomString(8)}`;
       const tokens = jwtService.generateTokenPair(user, webDeviceId);
+      const linkedDevice = {
+        deviceId: webDeviceId,
+        platform: 'web' as const,
+        deviceModel: describeUserAgent(session.deviceInfo.userAgent),
+        linkedBy: deviceId,
+        linkedAt: new Date(),
+      };
+      await this.userRepository.linkDevice(userId, linkedDevice, session.deviceInfo.ip);
 
-      // Update session status
+      // Update session status; the tokens wait for a desktop that polls
       session.status = 'confirmed';
       session.confirmedAt = new Date();
+      session.linkedDeviceId = webDeviceId;
+      session.tokens = {
+        accessToken: tokens.accessToken,
+        refreshToken: tokens.refreshToken,
+        expiresIn: tokens.expiresIn,
+      };
       await this.storeSession(session);
 
       // Notify web client with tokens
       socketManager.emitToUser(`qr:${qrId}`, 'qr:confirmed', {
         qrId,
-        tokens: {
-          accessToken: tokens.accessToken,
-          refreshToken: tokens.refreshToken,
-          expiresIn: tokens.expiresIn,
-        },
+        deviceId: webDeviceId,
+        tokens: session.tokens,
       });
 
+      // And the user's other devices, to refresh their linked devices
+      socketManager.emitToUser(userId, 'device:linked', linkedDevice);
+
       // Clean up session after successful login
       setTimeout(() => {
         this.deleteSession(qrId);
@@ -284,6 +318,8 @@ export class QRAuthService {
       logger.info('QR login confirmed successfully', {
         qrId,
         userId,
+        linkedDeviceId: webDeviceId,
+        linkedBy: deviceId,
         deviceInfo: session.deviceInfo,
       });
 
@@ -354,13 +390,22 @@ export class QRAuthService {
     }
   }
 
-  // Get QR session status (web client polling)
-  async getQRStatus(qrId: string): Promise<{
+  // Check the poll token the desktop got with its QR code
+  async verifyPollToken(qrId: string, pollToken: string): Promise<boolean> {
+    const session = await this.getSession(qrId);
+    return !!session?.pollTokenHash && CryptoUtils.constantTimeEqual(session.pollTokenHash, CryptoUtils.hash(pollToken));
+  }
+
+  // Get QR session status (web client polling). With the poll token, a
+  // confirmed session hands out the new device's tokens once.
+  async getQRStatus(qrId: string, pollToken?: string): Promise<{
     status: QRAuthSession['status'];
     userInfo?: {
       displayName: string;
       avatar?: string;
     };
+    deviceId?: string;
+    tokens?: QRAuthSession['tokens'];
     expiresAt: Date;
   } | null> {
     try {
@@ -374,6 +419,14 @@ export class QRAuthService {
         expiresAt: session.expiresAt,
       };
 
+      if (session.status === 'confirmed' && session.tokens && pollToken
+          && CryptoUtils.constantTimeEqual(session.pollTokenHash, CryptoUtils.hash(pollToken))) {
+        result.deviceId = session.linkedDeviceId;
+        result.tokens = session.tokens;
+        await this.deleteSession(qrId);
+        return result;
+      }
+
       // Include user info if scanned
       if (session.status === 'scanned' && session.userId) {
         const user = await this.userRepository.findById(session.userId);
@@ -462,4 +515,20 @@ export class QRAuthService {
   }
 }
 
+// Short label for a linked device, e.g. "Chrome on macOS"
+const BROWSERS: [string, RegExp][] = [
+  ['Edge', /Edg\//], ['Opera', /OPR\//], ['Firefox', /Firefox\//], ['Chrome', /Chrome\//], ['Safari', /Safari\//],
+];
+const OPERATING_SYSTEMS: [string, RegExp][] = [
+  ['Windows', /Windows/], ['macOS', /Mac OS X|Macintosh/], ['ChromeOS', /CrOS/], ['Linux', /Linux/],
+];
+
+function describeUserAgent(userAgent: string): string {
+  const browser = BROWSERS.find(([, pattern]) => pattern.test(userAgent))?.[0];
+  const os = OPERATING_SYSTEMS.find(([, pattern]) => pattern.test(userAgent))?.[0];
+
+  if (browser && os) return `${browser} on ${os}`;
+  return browser || os || 'Web browser';
+}
+
 export const qrAuthService = new QRAuthService();
\ No newline at end of file
@@ -0,0 +1,113 @@
+import { UserRepository } from '../database/repositories/user';
+import { IUser } from '../database/models/user';
+import { ActivityStatusInput } from '../database/schemas/user';
+import { DataSanitizer } from '../security/sanitization';
+import { permissionService } from '../security/permissions';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+export interface ActivityStatus {
+  emoji?: string;
+  text?: string;
+  expiresAt?: Date;
+  updatedAt: Date;
+}
+
+type StatusOwner = Pick<IUser, '_id' | 'contacts' | 'blockedUsers' | 'activityStatus'> & {
+  privacySettings?: Partial<IUser['privacySettings']>;
+};
+
+// Short activity statuses like "🏢 At work" or "🌴 On holiday until Monday",
+// separate from the longer profile about text. They are visible to whoever
+// may see the owner's about text (privacySettings.status). Users who have the
+// owner in their contacts get changes live; expiry isn't pushed, clients hide
+// a status once its expiresAt passes and the server drops it when next read.
+export class ActivityStatusService {
+  private userRepository = new UserRepository();
+
+  async get(userId: string): Promise<ActivityStatus | null> {
+    const user = await this.userRepository.findById(userId);
+    return user ? this.current(user) : null;
+  }
+
+  async set(userId: string, input: ActivityStatusInput): Promise<ActivityStatus> {
+    const activityStatus = {
+      ...(input.emoji && { emoji: input.emoji }),
+      ...(input.text && { text: DataSanitizer.sanitizePlainText(input.text) }),
+      ...(input.expiresAt && { expiresAt: new Date(input.expiresAt) }),
+      updatedAt: new Date(),
+    };
+
+    const user = await this.userRepository.setActivityStatus(userId, activityStatus);
+    if (!user) {
+      throw new ActivityStatusError('User not found', 404);
+    }
+
+    metricsCollector.incrementCounter('activity_status_set', 1, { expires: String(!!input.expiresAt) });
+    logger.info('Activity status set', { userId, expiresAt: activityStatus.expiresAt });
+
+    await this.notify(user);
+    return activityStatus;
+  }
+
+  async clear(userId: string): Promise<void> {
+    const user = await this.userRepository.setActivityStatus(userId, null);
+    if (!user) {
+      throw new ActivityStatusError('User not found', 404);
+    }
+
+    logger.info('Activity status cleared', { userId });
+    await this.notify(user);
+  }
+
+  // The owner's status, or null when unset or expired
+  current(owner: StatusOwner): ActivityStatus | null {
+    const status = owner.activityStatus;
+    if (!status?.updatedAt || (!status.emoji && !status.text)) return null;
+
+    if (status.expiresAt && status.expiresAt.getTime() <= Date.now()) {
+      this.userRepository.clearExpiredActivityStatus(owner._id, status.updatedAt).catch(error => {
+        logger.error('Failed to clear expired activity status', error, { userId: owner._id.toString() });
+      });
+      return null;
+    }
+
+    return {
+      ...(status.emoji && { emoji: status.emoji }),
+      ...(status.text && { text: status.text }),
+      ...(status.expiresAt && { expiresAt: status.expiresAt }),
+      updatedAt: status.updatedAt,
+    };
+  }
+
+  // The owner's status as the viewer may see it
+  forViewer(owner: StatusOwner, viewerId: string): ActivityStatus | null {
+    if (!permissionService.canSeeProfileField(owner, viewerId, owner.privacySettings?.status)) return null;
+    return this.current(owner);
+  }
+
+  // Update the contact lists of everyone allowed to see the change, and the
+  // owner's other devices
+  private async notify(owner: IUser): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const ownerId = owner._id.toString();
+    const activityStatus = this.current(owner);
+
+    const watchers = await this.userRepository.findUsersWithContact(owner._id);
+    const recipients = [ownerId, ...watchers.map(id => id.toString())]
+      .filter(viewerId => permissionService.canSeeProfileField(owner, viewerId, owner.privacySettings?.status));
+
+    for (const viewerId of new Set(recipients)) {
+      socketManager.emitToUser(viewerId, 'contact:activity:updated', { userId: ownerId, activityStatus });
+    }
+  }
+}
+
+export class ActivityStatusError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ActivityStatusError';
+  }
+}
+
+export const activityStatusService = new ActivityStatusService();
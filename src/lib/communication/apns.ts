@@ -0,0 +1,160 @@
+import http2 from 'http2';
+import jwt from 'jsonwebtoken';
+import { environmentConfig } from '../config/environment';
+import { logger } from '../monitoring/logging';
+
+const HOSTS = {
+  production: 'https://api.push.apple.com',
+  sandbox: 'https://api.sandbox.push.apple.com',
+} as const;
+
+// Apple rejects provider tokens older than an hour and throttles ones
+// refreshed more often than every 20 minutes
+const PROVIDER_TOKEN_LIFETIME = 50 * 60 * 1000;
+const REQUEST_TIMEOUT = 10 * 1000;
+
+// Reasons that mean the token will never work again
+const DEAD_TOKEN_REASONS = new Set(['BadDeviceToken', 'Unregistered', 'DeviceTokenNotForTopic']);
+
+export type ApnsEnvironment = keyof typeof HOSTS;
+
+export interface VoipPush {
+  token: string;
+  environment?: ApnsEnvironment;
+  payload: Record<string, unknown>;
+  expiresAt: Date;
+  collapseId?: string;
+}
+
+export interface ApnsResult {
+  success: boolean;
+  token: string;
+  status?: number;
+  reason?: string;
+  apnsId?: string;
+}
+
+// Minimal APNs provider for PushKit pushes. Firebase can't deliver VoIP
+// pushes, so these go straight to Apple over HTTP/2 with token auth.
+export class ApnsClient {
+  private sessions = new Map<ApnsEnvironment, http2.ClientHttp2Session>();
+  private providerToken: { value: string; issuedAt: number } | null = null;
+
+  isConfigured(): boolean {
+    return environmentConfig.isServiceConfigured('apns');
+  }
+
+  // Send a VoIP push. The topic is the app's bundle ID with ".voip".
+  async sendVoip(push: VoipPush): Promise<ApnsResult> {
+    const environment = push.environment
+      || (environmentConfig.getValue('APNS_PRODUCTION') ? 'production' : 'sandbox');
+
+    try {
+      const headers: http2.OutgoingHttpHeaders = {
+        ':method': 'POST',
+        ':path': `/3/device/${push.token}`,
+        authorization: `bearer ${this.getProviderToken()}`,
+        'apns-topic': `${environmentConfig.getValue('APNS_BUNDLE_ID')}.voip`,
+        'apns-push-type': 'voip',
+        'apns-priority': '10',
+        'apns-expiration': Math.floor(push.expiresAt.getTime() / 1000).toString(),
+      };
+      if (push.collapseId) {
+        headers['apns-collapse-id'] = push.collapseId;
+      }
+
+      const response = await this.request(environment, headers, JSON.stringify(push.payload));
+      if (response.status === 200) {
+        return { success: true, token: push.token, status: 200, apnsId: response.apnsId };
+      }
+
+      let reason: string | undefined;
+      try {
+        reason = JSON.parse(response.body).reason;
+      } catch {
+        reason = response.body || undefined;
+      }
+      return { success: false, token: push.token, status: response.status, reason };
+    } catch (error) {
+      logger.error('APNs request failed', error, { environment });
+      return {
+        success: false,
+        token: push.token,
+        reason: error instanceof Error ? error.message : 'Unknown error',
+      };
+    }
+  }
+
+  // Whether a failed push means the token should be dropped
+  isDeadToken(result: ApnsResult): boolean {
+    return result.status === 410 || (!!result.reason && DEAD_TOKEN_REASONS.has(result.reason));
+  }
+
+  private request(
+    environment: ApnsEnvironment,
+    headers: http2.OutgoingHttpHeaders,
+    body: string
+  ): Promise<{ status: number; apnsId?: string; body: string }> {
+    return new Promise((resolve, reject) => {
+      const stream = this.getSession(environment).request(headers);
+      let status = 0;
+      let apnsId: string | undefined;
+      let data = '';
+
+      stream.setEncoding('utf8');
+      stream.setTimeout(REQUEST_TIMEOUT, () => stream.close(http2.constants.NGHTTP2_CANCEL));
+      stream.on('response', responseHeaders => {
+        status = Number(responseHeaders[':status']);
+        apnsId = responseHeaders['apns-id'] as string | undefined;
+      });
+      stream.on('data', chunk => { data += chunk; });
+      stream.on('end', () => status ? resolve({ status, apnsId, body: data }) : reject(new Error('APNs request timed out')));
+      stream.on('error', reject);
+
+      stream.end(body);
+    });
+  }
+
+  // One long-lived connection per environment, as Apple recommends
+  private getSession(environment: ApnsEnvironment): http2.ClientHttp2Session {
+    const existing = this.sessions.get(environment);
+    if (existing && !existing.closed && !existing.destroyed) {
+      return existing;
+    }
+
+    const session = http2.connect(HOSTS[environment]);
+    const forget = () => {
+      if (this.sessions.get(environment) === session) {
+        this.sessions.delete(environment);
+      }
+    };
+    session.on('error', error => {
+      logger.warn('APNs connection error', { environment, error: error.message });
+      forget();
+    });
+    session.on('goaway', forget);
+    session.on('close', forget);
+    session.unref();
+
+    this.sessions.set(environment, session);
+    return session;
+  }
+
+  private getProviderToken(): string {
+    if (this.providerToken && Date.now() - this.providerToken.issuedAt < PROVIDER_TOKEN_LIFETIME) {
+      return this.providerToken.value;
+    }
+
+    const privateKey = environmentConfig.getValue('APNS_PRIVATE_KEY')!.replace(/\\n/g, '\n');
+    const value = jwt.sign({}, privateKey, {
+      algorithm: 'ES256',
+      issuer: environmentConfig.getValue('APNS_TEAM_ID'),
+      keyid: environmentConfig.getValue('APNS_KEY_ID'),
+    });
+
+    this.providerToken = { value, issuedAt: Date.now() };
+    return value;
+  }
+}
+
+export const apnsClient = new ApnsClient();
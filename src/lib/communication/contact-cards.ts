@@ -0,0 +1,215 @@
+import QRCode from 'qrcode';
+import { Types } from 'mongoose';
+import { UserRepository } from '../database/repositories/user';
+import { IUser } from '../database/models/user';
+import { environmentConfig } from '../config/environment';
+import { CryptoUtils } from '../utils/crypto';
+import { permissionService } from '../security/permissions';
+import { activityStatusService, ActivityStatus } from './activity-status';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const CONTACT_CODE_LENGTH = 16;
+const CONTACT_LINK_PATH = '/add/';
+const MAX_ATTEMPTS = 3;
+
+type Visibility = IUser['privacySettings']['profilePhoto'];
+
+// What a viewer gets to see of someone they scanned or exported
+export interface ShareableProfile {
+  id: string;
+  displayName: string;
+  username?: string;
+  avatar?: string;
+  status?: string;
+  activityStatus?: ActivityStatus;
+  phoneNumber?: string;
+  isContact: boolean;
+}
+
+export interface ContactQRCode {
+  code: string;
+  link: string;
+  qrCode: string; // PNG data URL
+}
+
+// Add-contact links, their QR codes and vCards, for exchanging contacts in
+// person. The link carries a random per-user code rather than the phone
+// number or user ID; rotating the code makes printed or screenshotted codes
+// stop working. Whatever is shown about the owner follows their privacy
+// settings, and users who blocked the viewer can't be found this way.
+export class ContactCardService {
+  private userRepository = new UserRepository();
+
+  // The user's add-contact link as a QR code, creating the code on first use
+  async getQRCode(userId: string): Promise<ContactQRCode> {
+    const user = await this.requireUser(userId);
+    const code = user.contactCode || await this.assignCode(userId, null);
+    return await this.render(code);
+  }
+
+  // Replace the user's code; links and QR codes shared before stop resolving
+  async rotate(userId: string): Promise<ContactQRCode> {
+    const user = await this.requireUser(userId);
+    const code = await this.assignCode(userId, user.contactCode ?? null);
+
+    logger.info('Contact code rotated', { userId });
+    return await this.render(code);
+  }
+
+  // The profile behind a scanned code or link, optionally adding it to the
+  // viewer's contacts
+  async resolve(codeOrLink: string, viewerId: string, addContact: boolean = false): Promise<ShareableProfile> {
+    const code = this.parseCode(codeOrLink);
+    const owner = code ? await this.userRepository.findByContactCode(code) : null;
+    if (!owner || this.hasBlocked(owner, viewerId)) {
+      throw new ContactCardError('Contact code not found', 404);
+    }
+    if (owner._id.toString() === viewerId) {
+      throw new ContactCardError('This is your own contact code', 400);
+    }
+
+    // Codes don't resolve across organizations
+    const viewer = await this.requireUser(viewerId);
+    if (owner.organizationId?.toString() !== viewer.organizationId?.toString()) {
+      throw new ContactCardError('Contact code not found', 404);
+    }
+
+    if (addContact) {
+      if (this.hasBlocked(viewer, owner._id.toString())) {
+        throw new ContactCardError('Unblock this user to add them as a contact', 409);
+      }
+      await this.userRepository.addContact(viewerId, owner._id);
+    }
+
+    metricsCollector.incrementCounter('contact_codes_resolved', 1, { added: String(addContact) });
+    logger.info('Contact code resolved', { ownerId: owner._id.toString(), viewerId, addContact });
+
+    return this.shareableProfile(owner, viewerId);
+  }
+
+  // A vCard of the user's profile as the viewer may see it. The user's own
+  // card also carries their add-contact link.
+  async exportVCard(userId: string, viewerId: string): Promise<{ vcard: string; profile: ShareableProfile }> {
+    const owner = Types.ObjectId.isValid(userId) ? await this.userRepository.findById(userId) : null;
+    if (!owner || owner.isBanned || this.hasBlocked(owner, viewerId)) {
+      throw new ContactCardError('User not found', 404);
+    }
+
+    const profile = this.shareableProfile(owner, viewerId);
+    const link = owner._id.toString() === viewerId && owner.contactCode
+      ? this.linkFor(owner.contactCode)
+      : undefined;
+
+    return { vcard: this.toVCard(profile, link), profile };
+  }
+
+  // The owner's profile filtered by their privacy settings. The phone number
+  // is only shared with people the owner has in their own contacts.
+  shareableProfile(owner: IUser, viewerId: string): ShareableProfile {
+    const isSelf = owner._id.toString() === viewerId;
+    const ownerHasViewer = owner.contacts.some(contact => contact.toString() === viewerId);
+    const visible = (setting?: Visibility) => permissionService.canSeeProfileField(owner, viewerId, setting);
+    const activityStatus = activityStatusService.forViewer(owner, viewerId);
+
+    return {
+      id: owner._id.toString(),
+      displayName: owner.displayName,
+      username: owner.username,
+      ...(visible(owner.privacySettings?.profilePhoto) && owner.avatar && { avatar: owner.avatar }),
+      ...(visible(owner.privacySettings?.status) && { status: owner.status }),
+      ...(activityStatus && { activityStatus }),
+      ...((isSelf || ownerHasViewer) && { phoneNumber: owner.phoneNumber }),
+      isContact: ownerHasViewer,
+    };
+  }
+
+  linkFor(code: string): string {
+    return `${environmentConfig.getValue('FRONTEND_URL')}${CONTACT_LINK_PATH}${code}`;
+  }
+
+  // Accepts the bare code or the whole link
+  private parseCode(codeOrLink: string): string | null {
+    const value = codeOrLink.trim();
+    const index = value.lastIndexOf(CONTACT_LINK_PATH);
+    const code = index >= 0 ? value.slice(index + CONTACT_LINK_PATH.length).split(/[?#/]/)[0] : value;
+    return /^[A-Za-z0-9]+$/.test(code) && code.length === CONTACT_CODE_LENGTH ? code : null;
+  }
+
+  private async assignCode(userId: string, expected: string | null): Promise<string> {
+    for (let attempt = 0; attempt < MAX_ATTEMPTS; attempt++) {
+      const code = CryptoUtils.generateRandomString(CONTACT_CODE_LENGTH);
+      try {
+        const updated = await this.userRepository.setContactCode(userId, code, expected);
+        if (updated) return code;
+
+        // Created or rotated concurrently by another request
+        const user = await this.requireUser(userId);
+        if (user.contactCode) return user.contactCode;
+      } catch (error: any) {
+        if (error?.code !== 11000) throw error;
+      }
+    }
+
+    logger.warn('Could not assign a contact code', { userId });
+    throw new Error('Could not assign a contact code');
+  }
+
+  private async render(code: string): Promise<ContactQRCode> {
+    const link = this.linkFor(code);
+    const qrCode = await QRCode.toDataURL(link, {
+      width: 256,
+      margin: 2,
+      color: {
+        dark: '#000000',
+        light: '#FFFFFF',
+      },
+    });
+    return { code, link, qrCode };
+  }
+
+  private toVCard(profile: ShareableProfile, link?: string): string {
+    const lines = [
+      'BEGIN:VCARD',
+      'VERSION:3.0',
+      `FN:${escapeVCard(profile.displayName)}`,
+      `N:;${escapeVCard(profile.displayName)};;;`,
+      ...(profile.username ? [`NICKNAME:${escapeVCard(profile.username)}`] : []),
+      ...(profile.phoneNumber ? [`TEL;TYPE=CELL:${profile.phoneNumber}`] : []),
+      ...(profile.avatar ? [`PHOTO;VALUE=URI:${profile.avatar}`] : []),
+      ...(profile.status ? [`NOTE:${escapeVCard(profile.status)}`] : []),
+      ...(link ? [`URL:${link}`] : []),
+      'END:VCARD',
+    ];
+    return lines.join('\r\n') + '\r\n';
+  }
+
+  private hasBlocked(user: IUser, otherId: string): boolean {
+    return user.blockedUsers.some(blocked => blocked.toString() === otherId);
+  }
+
+  private async requireUser(userId: string): Promise<IUser> {
+    const user = await this.userRepository.findById(userId);
+    if (!user) {
+      throw new ContactCardError('User not found', 404);
+    }
+    return user;
+  }
+}
+
+// RFC 6350 text value escaping
+function escapeVCard(value: string): string {
+  return value
+    .replace(/\\/g, '\\\\')
+    .replace(/\r?\n/g, '\\n')
+    .replace(/([,;])/g, '\\$1');
+}
+
+export class ContactCardError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ContactCardError';
+  }
+}
+
+export const contactCardService = new ContactCardService();
@@ -0,0 +1,90 @@
+import { Types } from 'mongoose';
+import { IUser } from '../database/models/user';
+import { DateUtils } from '../utils/date';
+
+export type QuietKind = 'message' | 'group' | 'call' | 'reminder';
+
+// What the push path should do with a notification
+export type DoNotDisturbDecision = 'deliver' | 'suppress' | 'defer';
+
+export interface QuietWindow {
+  active: boolean;
+  endsAt?: Date; // unset while active means "until switched off"
+  source?: 'manual' | 'schedule';
+}
+
+type DoNotDisturbUser = Pick<IUser, 'doNotDisturb' | 'timezone' | 'starredContacts'>;
+
+function toMinutes(clock: string): number {
+  const [hours, minutes] = clock.split(':').map(part => parseInt(part, 10));
+  return hours * 60 + minutes;
+}
+
+// Check whether the user is inside a quiet period right now. Schedules are
+// evaluated in the user's timezone; a period belongs to the day it starts on.
+export function getQuietWindow(user: DoNotDisturbUser, now: Date = new Date()): QuietWindow {
+  const settings = user.doNotDisturb;
+  if (!settings) {
+    return { active: false };
+  }
+
+  if (settings.enabled && (!settings.until || settings.until.getTime() > now.getTime())) {
+    return { active: true, endsAt: settings.until, source: 'manual' };
+  }
+
+  const timeZone = user.timezone || 'UTC';
+  const local = DateUtils.getZonedParts(now, timeZone);
+  const minuteOfDay = local.hour * 60 + local.minute;
+  const yesterday = (local.weekday + 6) % 7;
+
+  for (const schedule of settings.schedules || []) {
+    const start = toMinutes(schedule.start);
+    const end = toMinutes(schedule.end);
+    const onDay = (day: number) => schedule.days.length === 0 || schedule.days.includes(day);
+    const endsAt = (dayOffset: number) => DateUtils.zonedTimeToUtc(
+      local.year, local.month, local.day + dayOffset, Math.floor(end / 60), end % 60, timeZone
+    );
+
+    if (start < end) {
+      if (onDay(local.weekday) && minuteOfDay >= start && minuteOfDay < end) {
+        return { active: true, endsAt: endsAt(0), source: 'schedule' };
+      }
+    } else {
+      // Runs past midnight, e.g. 22:00-07:00
+      if (onDay(local.weekday) && minuteOfDay >= start) {
+        return { active: true, endsAt: endsAt(1), source: 'schedule' };
+      }
+      if (onDay(yesterday) && minuteOfDay < end) {
+        return { active: true, endsAt: endsAt(0), source: 'schedule' };
+      }
+    }
+  }
+
+  return { active: false };
+}
+
+// Decide whether a push goes out now. Calls from starred contacts ring
+// through when the user allows it; everything else is dropped or held for
+// the digest depending on the user's mode.
+export function evaluateDoNotDisturb(
+  user: DoNotDisturbUser,
+  kind: QuietKind,
+  context: { senderId?: string | Types.ObjectId } = {},
+  now: Date = new Date()
+): DoNotDisturbDecision {
+  if (!getQuietWindow(user, now).active) {
+    return 'deliver';
+  }
+
+  const settings = user.doNotDisturb;
+  if (
+    kind === 'call' &&
+    settings.allowStarredCalls &&
+    context.senderId &&
+    (user.starredContacts || []).some(id => id.toString() === context.senderId!.toString())
+  ) {
+    return 'deliver';
+  }
+
+  return settings.mode === 'digest' ? 'defer' : 'suppress';
+}
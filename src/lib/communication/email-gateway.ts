@@ -0,0 +1,397 @@
+import { Types } from 'mongoose';
+import { simpleParser, AddressObject, ParsedMail } from 'mailparser';
+import crypto from 'crypto';
+import { environmentConfig } from '../config/environment';
+import { EmailGatewayRepository } from '../database/repositories/email-gateway';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { MediaRepository } from '../database/repositories/media';
+import { UserRepository } from '../database/repositories/user';
+import { EmailGatewayKind, IEmailGateway } from '../database/models/email-gateway';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { IUser } from '../database/models/user';
+import { storageService } from '../media/storage';
+import { mediaUploadService } from '../media/upload';
+import { FileValidator } from '../media/validation';
+import { socketManager } from '../realtime/socket';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { CryptoUtils } from '../utils/crypto';
+import { smtpService } from './smtp';
+
+const LOCAL_PART_PATTERN = /^[a-z0-9][a-z0-9._-]{2,40}$/;
+const MAX_OUTBOUND_ATTACHMENT = 10 * 1024 * 1024; // larger media is sent as a link
+const MAX_CONTENT_LENGTH = 4000;
+
+// Reply quote headers like "On Mon, 1 Jan 2024 at 10:00, Alice <a@b.c> wrote:"
+const QUOTE_HEADER = /^(On .+wrote:|-{2,} ?Original Message ?-{2,}|From: .+)$/i;
+
+function addressesOf(field?: AddressObject | AddressObject[]): string[] {
+  if (!field) return [];
+  return (Array.isArray(field) ? field : [field])
+    .flatMap(group => group.value)
+    .map(entry => entry.address?.toLowerCase())
+    .filter((address): address is string => !!address);
+}
+
+// Keep only the new part of a reply: drop ">" quotes, everything after a quote
+// header, and the signature block
+function stripQuotedReply(text: string): string {
+  const lines: string[] = [];
+  for (const line of text.split(/\r?\n/)) {
+    const trimmed = line.trim();
+    if (QUOTE_HEADER.test(trimmed) || trimmed === '--') break;
+    if (trimmed.startsWith('>')) continue;
+    lines.push(line);
+  }
+  return lines.join('\n').trim();
+}
+
+function htmlToText(html: string): string {
+  return html
+    .replace(/<(br|\/p|\/div)[^>]*>/gi, '\n')
+    .replace(/<[^>]+>/g, '')
+    .replace(/&nbsp;/g, ' ')
+    .replace(/&amp;/g, '&')
+    .replace(/&lt;/g, '<')
+    .replace(/&gt;/g, '>')
+    .replace(/\n{3,}/g, '\n\n');
+}
+
+// Connects chats to email. Mail sent to a group's address is posted in the
+// group; mail sent to a user's address opens a direct chat with the sender.
+// Senders are represented by bridge users, and chat replies are mailed back
+// threaded under the original email.
+export class EmailGatewayService {
+  private emailGatewayRepository = new EmailGatewayRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private mediaRepository = new MediaRepository();
+  private userRepository = new UserRepository();
+
+  isEnabled(): boolean {
+    return environmentConfig.getValue('EMAIL_GATEWAY_ENABLED') && !!environmentConfig.getValue('EMAIL_GATEWAY_DOMAIN');
+  }
+
+  getAddress(gateway: IEmailGateway): string {
+    return `${gateway.localPart}@${environmentConfig.getValue('EMAIL_GATEWAY_DOMAIN')}`;
+  }
+
+  verifyInboundToken(token: string | null): boolean {
+    const expected = environmentConfig.getValue('EMAIL_INBOUND_SECRET');
+    return !!token && !!expected && CryptoUtils.constantTimeEqual(token, expected);
+  }
+
+  // Create a gateway for a group or user, or rotate the address of an
+  // existing one
+  async provision(
+    kind: EmailGatewayKind,
+    targetId: string,
+    createdBy: string,
+    options: { localPart?: string; allowedSenders?: string[] } = {}
+  ): Promise<IEmailGateway> {
+    const localPart = options.localPart?.toLowerCase() ||
+      `${kind === 'group' ? 'g' : 'u'}-${crypto.randomBytes(5).toString('hex')}`;
+    if (!LOCAL_PART_PATTERN.test(localPart)) {
+      throw new EmailGatewayError('Invalid address', 400);
+    }
+
+    const taken = await this.emailGatewayRepository.findGatewayByLocalPart(localPart);
+    const existing = kind === 'group'
+      ? await this.emailGatewayRepository.findGatewayByChat(targetId)
+      : await this.emailGatewayRepository.findGatewayByUser(targetId);
+
+    if (taken && !taken._id.equals(existing?._id)) {
+      throw new EmailGatewayError('Address is already taken', 409);
+    }
+
+    if (existing) {
+      const updated = await this.emailGatewayRepository.updateGateway(existing._id, {
+        localPart,
+        ...(options.allowedSenders && { allowedSenders: options.allowedSenders }),
+      });
+      return updated!;
+    }
+
+    return await this.emailGatewayRepository.createGateway({
+      localPart,
+      kind,
+      ...(kind === 'group'
+        ? { chatId: new Types.ObjectId(targetId) }
+        : { userId: new Types.ObjectId(targetId) }),
+      allowedSenders: options.allowedSenders || [],
+      createdBy: new Types.ObjectId(createdBy),
+    });
+  }
+
+  // Parse a raw RFC 5322 message and deliver it to every gateway it is
+  // addressed to. Envelope recipients from the provider take precedence over
+  // the To/Cc headers (they include Bcc). Returns the number of deliveries.
+  async receive(raw: Buffer | string, envelopeRecipients: string[] = []): Promise<number> {
+    if (!this.isEnabled()) return 0;
+
+    const mail = await simpleParser(raw);
+    const domain = environmentConfig.getValue('EMAIL_GATEWAY_DOMAIN').toLowerCase();
+    const from = mail.from?.value[0];
+    const fromAddress = from?.address?.toLowerCase();
+
+    if (!fromAddress) {
+      logger.warn('Inbound email without sender dropped');
+      return 0;
+    }
+
+    // Avoid mail loops with autoresponders and our own outbound mail
+    const autoSubmitted = mail.headers.get('auto-submitted');
+    if ((autoSubmitted && String(autoSubmitted).toLowerCase() !== 'no') || fromAddress.endsWith(`@${domain}`)) {
+      metricsCollector.incrementCounter('email_gateway_dropped', 1, { reason: 'loop' });
+      return 0;
+    }
+
+    const recipients = new Set(
+      (envelopeRecipients.length > 0
+        ? envelopeRecipients.map(address => address.toLowerCase())
+        : [...addressesOf(mail.to), ...addressesOf(mail.cc)])
+        .filter(address => address.endsWith(`@${domain}`))
+    );
+
+    let delivered = 0;
+    for (const recipient of recipients) {
+      const gateway = await this.emailGatewayRepository.findGatewayByLocalPart(recipient.split('@')[0]);
+      if (!gateway || !gateway.enabled) continue;
+
+      if (!this.isSenderAllowed(gateway, fromAddress)) {
+        metricsCollector.incrementCounter('email_gateway_dropped', 1, { reason: 'sender' });
+        continue;
+      }
+
+      try {
+        if (await this.deliver(gateway, mail, fromAddress, from?.name || fromAddress, raw)) {
+          delivered++;
+        }
+      } catch (error) {
+        logger.error('Email gateway delivery failed', error, { gatewayId: gateway._id.toString() });
+        metricsCollector.incrementCounter('email_gateway_errors', 1, { direction: 'inbound' });
+      }
+    }
+
+    return delivered;
+  }
+
+  // Mail a chat message back to the email thread it belongs to: replies to an
+  // emailed message in a group, or any message in a user gateway's direct chat
+  async relayOutbound(message: IMessage): Promise<void> {
+    if (!this.isEnabled()) return;
+
+    const thread = message.replyTo
+      ? await this.emailGatewayRepository.findByMessage(message.replyTo)
+      : await this.emailGatewayRepository.findLatestInChat(message.chatId);
+    if (!thread) return;
+
+    const gateway = await this.emailGatewayRepository.findGatewayById(thread.gatewayId);
+    if (!gateway || !gateway.enabled) return;
+    if (gateway.kind === 'user' && !gateway.userId?.equals(message.senderId)) return;
+    if (gateway.kind === 'group' && !message.replyTo) return;
+
+    const sender = await this.userRepository.findById(message.senderId);
+    if (!sender || sender.bridge) return;
+
+    const domain = environmentConfig.getValue('EMAIL_GATEWAY_DOMAIN');
+    const address = this.getAddress(gateway);
+    const emailMessageId = `<${message._id.toString()}@${domain}>`;
+    const references = [...thread.references, thread.emailMessageId].slice(-20);
+    const subject = /^re:/i.test(thread.subject) ? thread.subject : `Re: ${thread.subject || '(no subject)'}`;
+    const { text, attachments } = await this.buildOutboundBody(message);
+
+    const result = await smtpService.sendEmail({
+      from: `"${sender.displayName.replace(/"/g, '')}" <${address}>`,
+      replyTo: address,
+      to: thread.externalAddress,
+      subject,
+      text,
+      messageId: emailMessageId,
+      inReplyTo: thread.emailMessageId,
+      references,
+      attachments,
+    });
+
+    if (!result.success) {
+      logger.error('Email gateway outbound send failed', result.error, { gatewayId: gateway._id.toString() });
+      metricsCollector.incrementCounter('email_gateway_errors', 1, { direction: 'outbound' });
+      return;
+    }
+
+    await this.emailGatewayRepository.recordThreadMessage({
+      gatewayId: gateway._id,
+      messageId: message._id,
+      chatId: message.chatId,
+      emailMessageId,
+      references,
+      subject,
+      externalAddress: thread.externalAddress,
+      direction: 'outbound',
+    });
+    await this.emailGatewayRepository.recordTraffic(gateway._id, 'outbound');
+    metricsCollector.incrementCounter('email_gateway_messages', 1, { direction: 'outbound', kind: gateway.kind });
+  }
+
+  private isSenderAllowed(gateway: IEmailGateway, address: string): boolean {
+    if (gateway.allowedSenders.length === 0) return true;
+    return gateway.allowedSenders.some(allowed =>
+      allowed.startsWith('@') ? address.endsWith(allowed) : address === allowed
+    );
+  }
+
+  private async deliver(
+    gateway: IEmailGateway,
+    mail: ParsedMail,
+    fromAddress: string,
+    fromName: string,
+    raw: Buffer | string
+  ): Promise<boolean> {
+    // Providers retry webhooks; the Message-ID (or a digest) makes delivery idempotent
+    const emailMessageId = mail.messageId || `<${crypto.createHash('sha256').update(raw).digest('hex')}@inbound>`;
+    if (await this.emailGatewayRepository.hasEmail(gateway._id, emailMessageId)) return false;
+
+    const sender = await this.userRepository.findOrCreateBridgeUser('email', fromAddress, fromName);
+    const chat = await this.resolveChat(gateway, sender);
+    if (!chat) return false;
+
+    const references = typeof mail.references === 'string' ? [mail.references] : mail.references || [];
+    const parent = await this.emailGatewayRepository.findByEmailMessageIds(
+      gateway._id,
+      [mail.inReplyTo, ...references].filter((id): id is string => !!id)
+    );
+
+    const body = stripQuotedReply(mail.text || (mail.html ? htmlToText(mail.html) : ''));
+    const subject = (mail.subject || '').trim();
+    // New threads lead with the subject; replies already have context
+    const content = (parent || !subject ? body : `${subject}\n\n${body}`.trim()).slice(0, MAX_CONTENT_LENGTH);
+
+    const created: IMessage[] = [];
+    if (content) {
+      created.push(await this.messageRepository.create({
+        chatId: chat._id,
+        senderId: sender._id,
+        content,
+        type: 'text',
+        replyTo: parent?.messageId,
+      }));
+    }
+
+    for (const attachment of mail.attachments) {
+      // Inline parts are signature logos and the like
+      if (attachment.related) continue;
+
+      const name = attachment.filename || 'attachment';
+      const type = FileValidator.detectFileType(attachment.contentType, FileValidator.getFileExtension(name).toLowerCase());
+      try {
+        const { media } = await mediaUploadService.uploadFile(
+          attachment.content,
+          name,
+          attachment.contentType,
+          sender._id.toString(),
+          type,
+          { chatId: chat._id.toString() }
+        );
+        created.push(await this.messageRepository.create({
+          chatId: chat._id,
+          senderId: sender._id,
+          content: name,
+          type: media.type,
+          media: media._id,
+          replyTo: created.length === 0 ? parent?.messageId : undefined,
+        }));
+      } catch (error) {
+        logger.warn('Skipping email attachment', {
+          gatewayId: gateway._id.toString(),
+          name,
+          error: error instanceof Error ? error.message : String(error),
+        });
+      }
+    }
+
+    if (created.length === 0) return false;
+
+    await this.emailGatewayRepository.recordThreadMessage({
+      gatewayId: gateway._id,
+      messageId: created[0]._id,
+      chatId: chat._id,
+      emailMessageId,
+      references: [...references, ...(mail.inReplyTo ? [mail.inReplyTo] : [])].slice(-20),
+      subject: subject || parent?.subject || '',
+      externalAddress: fromAddress,
+      direction: 'inbound',
+    });
+    await this.chatRepository.updateLastActivity(chat._id, created[created.length - 1]._id);
+    await this.emailGatewayRepository.recordTraffic(gateway._id, 'inbound');
+
+    for (const message of created) {
+      const populatedMessage = await this.messageRepository.findById(message._id);
+      socketManager.emitToChat(chat._id.toString(), 'message:new', populatedMessage);
+    }
+
+    metricsCollector.incrementCounter('email_gateway_messages', 1, { direction: 'inbound', kind: gateway.kind });
+    return true;
+  }
+
+  private async resolveChat(gateway: IEmailGateway, sender: IUser): Promise<IChat | null> {
+    if (gateway.kind === 'group') {
+      const chat = await this.chatRepository.findById(gateway.chatId!);
+      if (!chat) return null;
+
+      if (!(await this.chatRepository.isParticipant(chat._id, sender._id))) {
+        await this.chatRepository.addParticipants(chat._id, [sender._id]);
+      }
+      return chat;
+    }
+
+    const existing = await this.chatRepository.findDirectChat(gateway.userId!, sender._id);
+    return existing || await this.chatRepository.create({
+      participants: [gateway.userId!, sender._id],
+      type: 'direct',
+    });
+  }
+
+  private async buildOutboundBody(
+    message: IMessage
+  ): Promise<{ text: string; attachments?: { filename: string; content: Buffer; contentType: string }[] }> {
+    const media = message.media ? await this.mediaRepository.findById(message.media) : null;
+    if (!media) {
+      return { text: message.content };
+    }
+
+    if (media.size <= MAX_OUTBOUND_ATTACHMENT) {
+      const content = await storageService.getFileBuffer(media.filename);
+      return {
+        text: message.content === media.originalName ? '' : message.content,
+        attachments: [{ filename: media.originalName, content, contentType: media.mimeType }],
+      };
+    }
+
+    const url = await mediaUploadService.getFileUrl(media._id.toString(), 7 * 24 * 3600);
+    return { text: `${message.content}\n\n${media.originalName}: ${url}` };
+  }
+}
+
+// Client-facing view of a gateway
+export function serializeEmailGateway(gateway: IEmailGateway) {
+  return {
+    id: gateway._id.toString(),
+    address: emailGatewayService.getAddress(gateway),
+    kind: gateway.kind,
+    enabled: gateway.enabled,
+    allowedSenders: gateway.allowedSenders,
+    stats: gateway.stats,
+    createdAt: gateway.createdAt,
+  };
+}
+
+export class EmailGatewayError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'EmailGatewayError';
+  }
+}
+
+export const emailGatewayService = new EmailGatewayService();
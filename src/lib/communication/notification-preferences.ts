@@ -0,0 +1,102 @@
+import { Types } from 'mongoose';
+import { IUser } from '../database/models/user';
+import { IChat } from '../database/models/chat';
+import { PUSH_CONSTANTS } from '../utils/constants';
+
+export type NotificationKind = 'message' | 'group' | 'call';
+export type VibrationPattern = keyof typeof PUSH_CONSTANTS.VIBRATION_PATTERNS;
+
+export interface NotificationPreferences {
+  sound: string;
+  vibration: VibrationPattern;
+  source: 'chat' | 'user';
+}
+
+// Platform-specific delivery options derived from preferences
+export interface PushSoundOptions {
+  sound: string | null; // APNs/FCM sound file, null = silent
+  channelId: string; // Android channel; sound and vibration are fixed per channel on Android 8+
+  vibrateTimings: readonly number[];
+}
+
+const DEFAULT_SOUNDS: Record<NotificationKind, string> = {
+  message: 'message_tone',
+  group: 'group_message',
+  call: 'ringtone',
+};
+
+const BASE_CHANNELS: Record<NotificationKind, string> = {
+  message: PUSH_CONSTANTS.CHANNELS.MESSAGES,
+  group: PUSH_CONSTANTS.CHANNELS.MESSAGES,
+  call: PUSH_CONSTANTS.CHANNELS.CALLS,
+};
+
+// Check if a sound name is one of the bundled tones
+export function isKnownSound(sound: string): boolean {
+  return (PUSH_CONSTANTS.SOUNDS as readonly string[]).includes(sound);
+}
+
+// Resolve the effective sound and vibration for a user, optionally within a
+// chat. Per-chat settings apply only when the participant enabled custom
+// notifications for that chat; calls always use the user's ringtone.
+export function resolveNotificationPreferences(
+  user: Pick<IUser, '_id' | 'notificationSettings'>,
+  kind: NotificationKind,
+  chat?: Pick<IChat, 'participantSettings'> | null
+): NotificationPreferences {
+  const settings = user.notificationSettings;
+  const userSound = kind === 'call'
+    ? settings?.callRingtone
+    : kind === 'group' ? settings?.groupSound : settings?.messageSound;
+
+  const userPreferences: NotificationPreferences = {
+    sound: userSound || DEFAULT_SOUNDS[kind],
+    vibration: settings?.vibration || 'default',
+    source: 'user',
+  };
+
+  if (!chat || kind === 'call') {
+    return userPreferences;
+  }
+
+  const chatSettings = findParticipantSettings(chat, user._id);
+  if (!chatSettings?.customNotifications) {
+    return userPreferences;
+  }
+
+  return {
+    sound: chatSettings.notificationSound || userPreferences.sound,
+    vibration: chatSettings.vibration || userPreferences.vibration,
+    source: 'chat',
+  };
+}
+
+// Map preferences onto push payload options
+export function toPushSoundOptions(
+  preferences: NotificationPreferences,
+  kind: NotificationKind
+): PushSoundOptions {
+  const silent = preferences.sound === 'none';
+  const isDefault = preferences.sound === 'default' || preferences.sound === DEFAULT_SOUNDS[kind];
+
+  // Android can't change a channel's sound after creation, so every custom
+  // sound/vibration combination gets its own channel, e.g.
+  // "chat_messages.chime.short". Clients create these on demand.
+  const channelId = isDefault && preferences.vibration === 'default'
+    ? BASE_CHANNELS[kind]
+    : `${BASE_CHANNELS[kind]}.${preferences.sound}.${preferences.vibration}`;
+
+  return {
+    sound: silent ? null : preferences.sound === 'default' ? 'default' : `${preferences.sound}.mp3`,
+    channelId,
+    vibrateTimings: PUSH_CONSTANTS.VIBRATION_PATTERNS[preferences.vibration],
+  };
+}
+
+export function findParticipantSettings(
+  chat: Pick<IChat, 'participantSettings'>,
+  userId: string | Types.ObjectId
+) {
+  const id = userId.toString();
+  return chat.participantSettings?.find(settings => settings.userId.toString() === id);
+}
@@ -1,6 +1,33 @@
 import admin from 'firebase-admin';
 import { IUser } from '../database/models/user';
 import { INotification } from '../database/models/notification';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { ChatRepository } from '../database/repositories/chat';
+import { NotificationRepository } from '../database/repositories/notification';
+import { UserRepository } from '../database/repositories/user';
+import {
+  NotificationKind,
+  resolveNotificationPreferences,
+  toPushSoundOptions,
+} from './notification-preferences';
+import { evaluateDoNotDisturb, getQuietWindow, QuietKind } from './do-not-disturb';
+import { apnsClient } from './apns';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { t } from '../i18n';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const DIGEST_INTERVAL = 60 * 1000;
+const DIGEST_PREVIEW_COUNT = 3;
+const PREVIEW_LENGTH = 200;
+
+const NOTIFICATION_TYPES: Record<QuietKind, INotification['type']> = {
+  message: 'message',
+  group: 'message',
+  call: 'call',
+  reminder: 'reminder',
+};
 
 interface PushNotificationConfig {
   projectId: string;
@@ -14,9 +41,11 @@ interface PushMessage {
   body: string;
   data?: { [key: string]: string };
   imageUrl?: string;
-  sound?: string;
+  sound?: string | null; // null = silent
   badge?: number;
   clickAction?: string;
+  channelId?: string;
+  vibrateTimings?: readonly number[];
 }
 
 interface PushResult {
@@ -26,8 +55,36 @@ interface PushResult {
   token: string;
 }
 
+interface PushOptions {
+  imageUrl?: string;
+  sound?: string | null;
+  badge?: number;
+  clickAction?: string;
+  channelId?: string;
+  vibrateTimings?: readonly number[];
+}
+
+export interface CallInvite {
+  callId: string;
+  callType: 'voice' | 'video';
+  callerId: string;
+  callerName: string;
+  callerAvatar?: string;
+  chatId?: string;
+  encrypted?: boolean; // media is end-to-end encrypted
+}
+
+// Why a ringing device should stop
+export type CallCancelReason = 'answered_elsewhere' | 'declined_elsewhere' | 'ended' | 'missed';
+
+type UserDevice = IUser['devices'][number];
+
 export class PushNotificationService {
   private messaging: admin.messaging.Messaging;
+  private chatRepository = new ChatRepository();
+  private notificationRepository = new NotificationRepository();
+  private userRepository = new UserRepository();
+  private digestTimer: NodeJS.Timeout | null = null;
 
   constructor(config: PushNotificationConfig) {
     if (!admin.apps.length) {
@@ -56,15 +113,16 @@ export class PushNotificationService {
         data: message.data || {},
         android: {
           notification: {
-            sound: message.sound || 'default',
+            sound: message.sound === null ? undefined : message.sound || 'default',
             clickAction: message.clickAction,
-            channelId: 'chat_messages',
+            channelId: message.channelId || 'chat_messages',
+            ...this.vibrationOptions(message.vibrateTimings),
           },
         },
         apns: {
           payload: {
             aps: {
-              sound: message.sound || 'default',
+              sound: message.sound === null ? undefined : message.sound || 'default',
               badge: message.badge,
               category: message.clickAction,
             },
@@ -102,12 +160,7 @@ export class PushNotificationService {
     title: string,
     body: string,
     data?: { [key: string]: string },
-    options?: {
-      imageUrl?: string;
-      sound?: string;
-      badge?: number;
-      clickAction?: string;
-    }
+    options?: PushOptions
   ): Promise<PushResult[]> {
     try {
       const message: admin.messaging.MulticastMessage = {
@@ -120,15 +173,16 @@ export class PushNotificationService {
         data: data || {},
         android: {
           notification: {
-            sound: options?.sound || 'default',
+            sound: options?.sound === null ? undefined : options?.sound || 'default',
             clickAction: options?.clickAction,
-            channelId: 'chat_messages',
+            channelId: options?.channelId || 'chat_messages',
+            ...this.vibrationOptions(options?.vibrateTimings),
           },
         },
         apns: {
           payload: {
             aps: {
-              sound: options?.sound || 'default',
+              sound: options?.sound === null ? undefined : options?.sound || 'default',
               badge: options?.badge,
               category: options?.clickAction,
             },
@@ -161,36 +215,75 @@ export class PushNotificationService {
     }
   }
 
+  // Push a newly sent message to the chat's other participants, each with
+  // their own sound for the chat and subject to their Do Not Disturb.
+  // skipUserIds are users already looking at the chat. Returns how many
+  // users were notified or had it held for their digest.
+  async notifyChatMessage(message: IMessage, skipUserIds: Set<string> = new Set()): Promise<number> {
+    const chat = await this.chatRepository.findById(message.chatId);
+    if (!chat || (chat.mutedUntil && chat.mutedUntil.getTime() > Date.now())) {
+      return 0;
+    }
+
+    const sender = message.senderId as any;
+    const senderId = (sender._id || sender).toString();
+    // Pseudonymous members are only known by their pseudonym
+    const from = message.pseudonym
+      ? { id: message.pseudonym.id.toString(), name: message.pseudonym.name }
+      : { id: senderId, name: sender.displayName || '' };
+
+    const recipientIds = chat.participants
+      .map((participant: any) => (participant._id || participant).toString())
+      .filter((userId: string) => userId !== senderId && !skipUserIds.has(userId));
+    const recipients = await this.userRepository.findPushRecipients(recipientIds);
+
+    let notified = 0;
+    for (const user of recipients) {
+      if ((user.blockedUsers || []).some(id => id.toString() === senderId)) {
+        continue;
+      }
+
+      const content = this.previewContent(user, message);
+      const chatId = chat._id.toString();
+      const messageId = message._id.toString();
+      if (chat.type === 'group') {
+        await this.sendGroupNotification(user, chat.groupInfo?.name || '', from.id, from.name, content, chatId, messageId, chat);
+      } else {
+        await this.sendMessageNotification(user, from.id, from.name, content, chatId, messageId, chat);
+      }
+      notified++;
+    }
+
+    metricsCollector.incrementCounter('push_chat_messages', notified, { chatType: chat.type });
+    return notified;
+  }
+
   // Send message notification
   async sendMessageNotification(
     user: IUser,
+    senderId: string,
     senderName: string,
     messageContent: string,
     chatId: string,
-    messageId: string
+    messageId: string,
+    chat?: Pick<IChat, 'participantSettings'> | null
   ): Promise<PushResult[]> {
-    const activeTokens = user.devices
-      .filter(device => device.pushToken)
-      .map(device => device.pushToken!);
-
-    if (activeTokens.length === 0) {
-      return [];
-    }
-
-    return await this.sendMulticastPushNotification(
-      activeTokens,
+    return await this.dispatchToUser(
+      user,
+      'message',
       senderName,
       messageContent,
       {
         type: 'message',
         chatId,
         messageId,
-        senderId: senderName,
+        senderId,
       },
       {
-        sound: 'message_tone.mp3',
+        ...this.getSoundOptions(user, 'message', chat),
         clickAction: 'OPEN_CHAT',
-      }
+      },
+      senderId
     );
   }
 
@@ -199,20 +292,14 @@ export class PushNotificationService {
     user: IUser,
     callerName: string,
     callType: 'voice' | 'video',
-    callId: string
+    callId: string,
+    callerId?: string
   ): Promise<PushResult[]> {
-    const activeTokens = user.devices
-      .filter(device => device.pushToken)
-      .map(device => device.pushToken!);
-
-    if (activeTokens.length === 0) {
-      return [];
-    }
-
-    return await this.sendMulticastPushNotification(
-      activeTokens,
-      `Incoming ${callType} call`,
-      `${callerName} is calling you`,
+    return await this.dispatchToUser(
+      user,
+      'call',
+      t(user.language, 'push.call.title', { callType: t(user.language, `call.type.${callType}`) }),
+      t(user.language, 'push.call.body', { callerName }),
       {
         type: 'call',
         callId,
@@ -220,9 +307,10 @@ export class PushNotificationService {
         callerName,
       },
       {
-        sound: 'ringtone.mp3',
+        ...this.getSoundOptions(user, 'call'),
         clickAction: 'ANSWER_CALL',
-      }
+      },
+      callerId
     );
   }
 
@@ -230,35 +318,503 @@ export class PushNotificationService {
   async sendGroupNotification(
     user: IUser,
     groupName: string,
+    senderId: string,
     senderName: string,
     messageContent: string,
+    chatId: string,
+    messageId: string,
+    chat?: Pick<IChat, 'participantSettings'> | null
+  ): Promise<PushResult[]> {
+    return await this.dispatchToUser(
+      user,
+      'group',
+      groupName,
+      t(user.language, 'push.group.body', { senderName, content: messageContent }),
+      {
+        type: 'group_message',
+        chatId,
+        messageId,
+        groupName,
+        senderId,
+        senderName,
+      },
+      {
+        ...this.getSoundOptions(user, 'group', chat),
+        clickAction: 'OPEN_GROUP',
+      },
+      senderId
+    );
+  }
+
+  // Tell the author of a message held in a moderated group that the group's
+  // admins rejected it
+  async sendGroupMessageRejected(
+    user: IUser,
+    groupName: string,
+    chatId: string,
+    messageId: string,
+    reason?: string
+  ): Promise<PushResult[]> {
+    return await this.dispatchToUser(
+      user,
+      'group',
+      groupName,
+      reason
+        ? t(user.language, 'push.groupReview.rejectedWithReason', { reason })
+        : t(user.language, 'push.groupReview.rejected'),
+      {
+        type: 'group_message_rejected',
+        chatId,
+        messageId,
+      },
+      {
+        clickAction: 'OPEN_GROUP',
+      }
+    );
+  }
+
+  // Send reminder notification
+  async sendReminderNotification(
+    user: IUser,
+    text: string,
+    reminderId: string,
     chatId: string
   ): Promise<PushResult[]> {
-    const activeTokens = user.devices
-      .filter(device => device.pushToken)
+    return await this.dispatchToUser(
+      user,
+      'reminder',
+      t(user.language, 'push.reminder.title'),
+      text,
+      {
+        type: 'reminder',
+        reminderId,
+        chatId,
+      },
+      {
+        ...this.getSoundOptions(user, 'message'),
+        clickAction: 'OPEN_CHAT',
+      }
+    );
+  }
+
+  // Remind an invitee of a scheduled call, or tell them it has started
+  async sendScheduledCallNotification(
+    user: IUser,
+    scheduledCall: { id: string; chatId: string; title: string; type: 'voice' | 'video'; startsAt: Date; createdBy: string },
+    stage: 'reminder' | 'started'
+  ): Promise<PushResult[]> {
+    const minutes = Math.max(1, Math.round((scheduledCall.startsAt.getTime() - Date.now()) / 60000));
+
+    return await this.dispatchToUser(
+      user,
+      stage === 'reminder' ? 'reminder' : 'call',
+      t(user.language, 'push.scheduledCall.title', { callType: t(user.language, `call.type.${scheduledCall.type}`) }),
+      t(user.language, `push.scheduledCall.${stage}`, { title: scheduledCall.title, minutes }),
+      {
+        type: 'scheduled_call',
+        stage,
+        scheduledCallId: scheduledCall.id,
+        chatId: scheduledCall.chatId,
+      },
+      {
+        ...this.getSoundOptions(user, stage === 'reminder' ? 'message' : 'call'),
+        clickAction: 'OPEN_CHAT',
+      },
+      scheduledCall.createdBy
+    );
+  }
+
+  // Ask a participant to rate a call that just ended
+  async sendCallRatingRequest(
+    user: IUser,
+    callId: string,
+    callType: 'voice' | 'video',
+    chatId?: string
+  ): Promise<PushResult[]> {
+    return await this.dispatchToUser(
+      user,
+      'reminder',
+      t(user.language, 'push.callRating.title', { callType: t(user.language, `call.type.${callType}`) }),
+      t(user.language, 'push.callRating.body'),
+      {
+        type: 'call_rating',
+        callId,
+        ...(chatId && { chatId }),
+      },
+      {
+        ...this.getSoundOptions(user, 'message'),
+        clickAction: 'OPEN_CALL_RATING',
+      }
+    );
+  }
+
+  // Ring a user's devices for an incoming call. iOS devices with a PushKit
+  // token get a VoIP push so CallKit can show the call from a killed app,
+  // Android gets a high-priority data message for its ConnectionService, and
+  // anything else falls back to a regular notification. Invites expire with
+  // the ring timeout so a device that comes online late doesn't ring.
+  // deviceIds limits the push to those devices.
+  async sendCallInvite(user: IUser, invite: CallInvite, deviceIds?: string[]): Promise<PushResult[]> {
+    const title = t(user.language, 'push.call.title', { callType: t(user.language, `call.type.${invite.callType}`) });
+    const body = t(user.language, 'push.call.body', { callerName: invite.callerName });
+    const alertData = {
+      type: 'call',
+      callId: invite.callId,
+      callType: invite.callType,
+      callerName: invite.callerName,
+    };
+
+    const devices = this.selectDevices(user, deviceIds);
+    if (devices.every(device => !device.pushToken && !device.voipToken)) {
+      return [];
+    }
+    if (await this.holdForDoNotDisturb(user, 'call', title, body, alertData, invite.callerId)) {
+      return [];
+    }
+
+    const expiresAt = new Date(Date.now() + CALL_CONSTANTS.RING_TIMEOUT);
+    const data: { [key: string]: string } = {
+      type: 'call_invite',
+      callId: invite.callId,
+      callType: invite.callType,
+      callerId: invite.callerId,
+      callerName: invite.callerName,
+      ...(invite.callerAvatar && { callerAvatar: invite.callerAvatar }),
+      ...(invite.chatId && { chatId: invite.chatId }),
+      expiresAt: expiresAt.toISOString(),
+    };
+
+    const voipDevices = this.getVoipDevices(devices);
+    const androidTokens = devices
+      .filter(device => device.platform === 'android' && device.pushToken)
+      .map(device => device.pushToken!);
+    const alertTokens = devices
+      .filter(device => device.pushToken && device.platform !== 'android' && !voipDevices.includes(device))
       .map(device => device.pushToken!);
 
-    if (activeTokens.length === 0) {
+    const results = await Promise.all([
+      this.sendVoipPushes(voipDevices, data, expiresAt, invite.callId),
+      this.sendCallDataMessage(androidTokens, data, expiresAt, invite.callId),
+      alertTokens.length > 0
+        ? this.sendMulticastPushNotification(alertTokens, title, body, alertData, {
+          ...this.getSoundOptions(user, 'call'),
+          clickAction: 'ANSWER_CALL',
+        })
+        : Promise.resolve([]),
+    ]);
+
+    metricsCollector.incrementCounter('push_call_invites', 1, {
+      voip: String(voipDevices.length),
+      data: String(androidTokens.length),
+      alert: String(alertTokens.length),
+    });
+    return results.flat();
+  }
+
+  // Stop a user's devices ringing: the call was picked up or declined on
+  // another device, or the caller hung up. Skipped when the invite was held
+  // by Do Not Disturb, since iOS must report a call for every VoIP push.
+  async sendCallCancel(
+    user: IUser,
+    call: { callId: string; callerId: string },
+    reason: CallCancelReason,
+    deviceIds?: string[]
+  ): Promise<PushResult[]> {
+    if (evaluateDoNotDisturb(user, 'call', { senderId: call.callerId }) !== 'deliver') {
+      return [];
+    }
+
+    // Same TTL and collapse key as the invite, so an invite still queued
+    // for an offline device is replaced rather than delivered
+    const expiresAt = new Date(Date.now() + CALL_CONSTANTS.RING_TIMEOUT);
+    const data = {
+      type: 'call_cancel',
+      callId: call.callId,
+      reason,
+    };
+
+    const devices = this.selectDevices(user, deviceIds);
+    const voipDevices = this.getVoipDevices(devices);
+    const dataTokens = devices
+      .filter(device => device.pushToken && !voipDevices.includes(device))
+      .map(device => device.pushToken!);
+
+    const results = await Promise.all([
+      this.sendVoipPushes(voipDevices, data, expiresAt, call.callId),
+      this.sendCallDataMessage(dataTokens, data, expiresAt, call.callId),
+    ]);
+
+    metricsCollector.incrementCounter('push_call_cancels', 1, { reason });
+    return results.flat();
+  }
+
+  // Ask the user's other devices to approve or deny a sign-in. Security
+  // alerts skip Do Not Disturb and never go to the device being approved.
+  async sendLoginApprovalRequest(
+    user: IUser,
+    approval: { approvalId: string; deviceId: string; device: string; location: string }
+  ): Promise<PushResult[]> {
+    const tokens = user.devices
+      .filter(device => device.pushToken && device.deviceId !== approval.deviceId)
+      .map(device => device.pushToken!);
+    if (tokens.length === 0) {
       return [];
     }
 
     return await this.sendMulticastPushNotification(
-      activeTokens,
-      groupName,
-      `${senderName}: ${messageContent}`,
+      tokens,
+      t(user.language, 'push.login.title'),
+      t(user.language, 'push.login.body', { device: approval.device, location: approval.location }),
       {
-        type: 'group_message',
-        chatId,
-        groupName,
-        senderName,
+        type: 'login_approval',
+        loginApprovalId: approval.approvalId,
       },
       {
-        sound: 'group_message.mp3',
-        clickAction: 'OPEN_GROUP',
+        clickAction: 'OPEN_LOGIN_APPROVAL',
+        channelId: 'security',
       }
     );
   }
 
+  // Every push addressed to a user goes through here so Do Not Disturb is
+  // enforced in one place: chat messages from notifyChatMessage are held for
+  // the digest or dropped during quiet hours just like calls and reminders.
+  // senderId lets starred contacts' calls through.
+  private async dispatchToUser(
+    user: IUser,
+    kind: QuietKind,
+    title: string,
+    body: string,
+    data: { [key: string]: string },
+    options: PushOptions,
+    senderId?: string
+  ): Promise<PushResult[]> {
+    const activeTokens = this.getActiveTokens(user);
+    if (activeTokens.length === 0) {
+      return [];
+    }
+
+    if (await this.holdForDoNotDisturb(user, kind, title, body, data, senderId)) {
+      return [];
+    }
+
+    return await this.sendMulticastPushNotification(activeTokens, title, body, data, options);
+  }
+
+  // Apply the user's Do Not Disturb decision. Returns true when the push
+  // must not go out now; deferred pushes are stored for the digest.
+  private async holdForDoNotDisturb(
+    user: IUser,
+    kind: QuietKind,
+    title: string,
+    body: string,
+    data: { [key: string]: string },
+    senderId?: string
+  ): Promise<boolean> {
+    const decision = evaluateDoNotDisturb(user, kind, { senderId });
+    if (decision === 'deliver') {
+      return false;
+    }
+
+    metricsCollector.incrementCounter('push_dnd_held', 1, { kind, decision });
+
+    if (decision === 'defer') {
+      await this.notificationRepository.create({
+        userId: user._id,
+        type: NOTIFICATION_TYPES[kind],
+        title,
+        body,
+        data,
+        deliveryStatus: 'deferred',
+      });
+    }
+    return true;
+  }
+
+  // PushKit pushes to iOS devices; tokens APNs reports dead are dropped
+  private async sendVoipPushes(
+    devices: UserDevice[],
+    payload: { [key: string]: string },
+    expiresAt: Date,
+    collapseId: string
+  ): Promise<PushResult[]> {
+    if (devices.length === 0) {
+      return [];
+    }
+
+    const results = await Promise.all(devices.map(device => apnsClient.sendVoip({
+      token: device.voipToken!,
+      environment: device.apnsEnvironment,
+      payload,
+      expiresAt,
+      collapseId,
+    })));
+
+    const deadTokens = results.filter(result => apnsClient.isDeadToken(result)).map(result => result.token);
+    if (deadTokens.length > 0) {
+      await this.userRepository.removeVoipTokens(deadTokens);
+      logger.info('Removed invalid VoIP tokens', { count: deadTokens.length });
+    }
+
+    return results.map(result => ({
+      success: result.success,
+      messageId: result.apnsId,
+      error: result.reason,
+      token: result.token,
+    }));
+  }
+
+  // Data-only, high-priority FCM message for call signalling. The app
+  // handles it itself instead of the system showing a notification.
+  private async sendCallDataMessage(
+    tokens: string[],
+    data: { [key: string]: string },
+    expiresAt: Date,
+    collapseKey: string
+  ): Promise<PushResult[]> {
+    if (tokens.length === 0) {
+      return [];
+    }
+
+    const ttl = Math.max(0, expiresAt.getTime() - Date.now());
+    try {
+      const response = await this.messaging.sendEachForMulticast({
+        tokens,
+        data,
+        android: {
+          priority: 'high',
+          ttl,
+          collapseKey,
+        },
+        apns: {
+          headers: {
+            'apns-push-type': 'background',
+            'apns-priority': '5',
+            'apns-expiration': Math.floor(expiresAt.getTime() / 1000).toString(),
+            'apns-collapse-id': collapseKey,
+          },
+          payload: {
+            aps: { contentAvailable: true },
+          },
+        },
+        webpush: {
+          headers: {
+            Urgency: 'high',
+            TTL: Math.floor(ttl / 1000).toString(),
+          },
+        },
+      });
+
+      return response.responses.map((result, index) => ({
+        success: result.success,
+        messageId: result.messageId,
+        error: result.error?.message,
+        token: tokens[index],
+      }));
+    } catch (error) {
+      logger.error('Call data message error', error);
+      return tokens.map(token => ({
+        success: false,
+        error: error instanceof Error ? error.message : 'Unknown error',
+        token,
+      }));
+    }
+  }
+
+  // Send one summary push to each user whose quiet period has ended
+  async sendDigests(): Promise<number> {
+    const userIds = await this.notificationRepository.getUsersWithDeferred();
+    let sent = 0;
+
+    for (const userId of userIds) {
+      const user = await this.userRepository.findById(userId);
+      if (!user || getQuietWindow(user).active) {
+        continue;
+      }
+
+      const held = await this.notificationRepository.takeDeferred(userId);
+      const activeTokens = this.getActiveTokens(user);
+      if (held.length === 0 || activeTokens.length === 0) {
+        continue;
+      }
+
+      const preview = held.slice(-DIGEST_PREVIEW_COUNT).map(notification => `${notification.title}: ${notification.body}`);
+      if (held.length > DIGEST_PREVIEW_COUNT) {
+        preview.push(t(user.language, 'push.digest.more', { count: held.length - DIGEST_PREVIEW_COUNT }));
+      }
+
+      await this.sendMulticastPushNotification(
+        activeTokens,
+        t(user.language, 'push.digest.title', { count: held.length }),
+        preview.join('\n'),
+        {
+          type: 'digest',
+          count: held.length.toString(),
+        },
+        this.getSoundOptions(user, 'message')
+      );
+      sent++;
+    }
+
+    if (sent > 0) {
+      metricsCollector.incrementCounter('push_dnd_digests_sent', sent);
+    }
+    return sent;
+  }
+
+  // Flush digests as users' quiet periods end
+  startDigestWorker(): void {
+    if (this.digestTimer) return;
+
+    this.digestTimer = setInterval(() => {
+      this.sendDigests().catch(error => logger.error('Push digest worker error', error));
+    }, DIGEST_INTERVAL);
+    this.digestTimer.unref();
+  }
+
+  private selectDevices(user: IUser, deviceIds?: string[]): UserDevice[] {
+    return deviceIds ? user.devices.filter(device => deviceIds.includes(device.deviceId)) : user.devices;
+  }
+
+  // iOS devices that can be rung through PushKit
+  private getVoipDevices(devices: UserDevice[]): UserDevice[] {
+    if (!apnsClient.isConfigured()) {
+      return [];
+    }
+    return devices.filter(device => device.platform === 'ios' && device.voipToken);
+  }
+
+  // Notification text for a message; attachments and polls get a placeholder
+  private previewContent(user: IUser, message: IMessage): string {
+    if (message.type === 'text' && message.content) {
+      return message.content.length > PREVIEW_LENGTH
+        ? `${message.content.slice(0, PREVIEW_LENGTH)}…`
+        : message.content;
+    }
+    return t(user.language, 'push.message.attachment');
+  }
+
+  private getActiveTokens(user: IUser): string[] {
+    return user.devices
+      .filter(device => device.pushToken)
+      .map(device => device.pushToken!);
+  }
+
+  // Resolve the user's (or chat's) custom sound and vibration
+  private getSoundOptions(user: IUser, kind: NotificationKind, chat?: Pick<IChat, 'participantSettings'> | null) {
+    return toPushSoundOptions(resolveNotificationPreferences(user, kind, chat), kind);
+  }
+
+  private vibrationOptions(vibrateTimings?: readonly number[]) {
+    if (!vibrateTimings) {
+      return { defaultVibrateTimings: true };
+    }
+    // An empty pattern means no vibration
+    return vibrateTimings.length > 0
+      ? { vibrateTimingsMillis: [...vibrateTimings] }
+      : { defaultVibrateTimings: false };
+  }
+
   // Validate push token
   async validatePushToken(token: string): Promise<boolean> {
     try {
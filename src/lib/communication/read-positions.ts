@@ -0,0 +1,82 @@
+import { Types } from 'mongoose';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { IChat } from '../database/models/chat';
+import { findParticipantSettings } from './notification-preferences';
+import { logger } from '../monitoring/logging';
+
+export interface ReadPosition {
+  messageId: string;
+  seq?: number;
+  sentAt: Date;
+  readAt: Date;
+}
+
+// Where each user has read up to in each chat, kept on the server so every
+// device draws the unread divider in the same place. Marking messages read
+// on any device moves the position forward (never back) and tells the
+// user's other devices.
+export class ReadPositionService {
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+
+  // Move the user's positions to the newest of the messages they just read,
+  // per chat. excludeSocketId is the socket that read them, which already
+  // knows.
+  async markRead(userId: string, messageIds: (string | Types.ObjectId)[], excludeSocketId?: string): Promise<void> {
+    const messages = await this.messageRepository.findReadTargets(messageIds);
+
+    const newest = new Map<string, typeof messages[number]>();
+    for (const message of messages) {
+      const chatId = message.chatId.toString();
+      const current = newest.get(chatId);
+      if (!current || message.createdAt > current.createdAt) newest.set(chatId, message);
+    }
+
+    for (const [chatId, message] of newest) {
+      const chat = await this.chatRepository.advanceReadPosition(chatId, userId, {
+        messageId: message._id,
+        seq: message.seq,
+        sentAt: message.createdAt,
+        readAt: new Date(),
+      });
+      if (!chat) continue;
+
+      const { socketManager } = await import('../realtime/socket');
+      socketManager.emitToUser(userId, 'chat:read-position:updated', {
+        chatId,
+        readPosition: this.get(chat, userId),
+      }, excludeSocketId);
+
+      logger.debug('Read position advanced', { userId, chatId, messageId: message._id.toString() });
+    }
+  }
+
+  get(chat: Pick<IChat, 'participantSettings'>, userId: string): ReadPosition | null {
+    const position = findParticipantSettings(chat, userId)?.readPosition;
+    if (!position?.messageId) return null;
+
+    return {
+      messageId: position.messageId.toString(),
+      seq: position.seq,
+      sentAt: position.sentAt,
+      readAt: position.readAt,
+    };
+  }
+
+  // The position plus the first message after it from someone else, or
+  // null when the user is caught up
+  async getWithFirstUnread(chat: IChat, userId: string) {
+    const readPosition = this.get(chat, userId);
+    const firstUnread = await this.messageRepository.findFirstUnread(chat._id, userId, readPosition?.sentAt);
+
+    return {
+      readPosition,
+      firstUnread: firstUnread
+        ? { messageId: firstUnread._id.toString(), seq: firstUnread.seq, sentAt: firstUnread.createdAt }
+        : null,
+    };
+  }
+}
+
+export const readPositionService = new ReadPositionService();
@@ -0,0 +1,271 @@
+import crypto from 'crypto';
+import { SmsMessageRepository, SmsStatsDimension, SmsStatsRow } from '../database/repositories/sms-message';
+import { ISmsMessage, SmsPurpose, SmsStatus } from '../database/models/sms-message';
+import { environmentConfig } from '../config/environment';
+import { PhoneUtils } from '../utils/phone';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const DAY = 24 * 60 * 60 * 1000;
+const CARRIER_CACHE_DAYS = 30;
+
+// Anomaly detection: the last 24 hours against the 7 days before
+const ANOMALY_WINDOW = DAY;
+const ANOMALY_BASELINE_DAYS = 7;
+const MIN_VOLUME = 20; // messages with a final status before a group is judged
+const FAILURE_RATE_FLOOR = 0.1; // never flag failure rates under 10%
+const FAILURE_SPIKE_FACTOR = 2;
+const COST_SPIKE_FACTOR = 1.5;
+const VOLUME_SPIKE_FACTOR = 3; // sudden volume to a country is a sign of SMS pumping
+
+// Twilio's message statuses, folded into ours
+const STATUS_MAP: Record<string, SmsStatus> = {
+  accepted: 'queued',
+  scheduled: 'queued',
+  queued: 'queued',
+  sending: 'queued',
+  sent: 'sent',
+  delivered: 'delivered',
+  read: 'delivered',
+  undelivered: 'undelivered',
+  failed: 'failed',
+  canceled: 'failed',
+};
+
+const FINAL_STATUSES: SmsStatus[] = ['delivered', 'undelivered', 'failed'];
+
+export interface SmsAnomaly {
+  kind: 'failure_spike' | 'cost_spike' | 'volume_spike';
+  dimension: 'country' | 'carrier';
+  value: string | null;
+  recent: number; // failure rate, cost per message or messages per day
+  baseline: number | null;
+  volume: number; // messages in the recent window
+  message: string;
+}
+
+function hashPhone(e164: string): string {
+  return crypto.createHash('sha256').update(e164).digest('hex');
+}
+
+function round(value: number, digits: number = 4): number {
+  const factor = 10 ** digits;
+  return Math.round(value * factor) / factor;
+}
+
+// Records every outbound SMS with its country, carrier, delivery outcome and
+// price, and reports on them so operators can see what SMS costs where and
+// which routes fail. Delivery outcomes and prices arrive later through the
+// provider's status callback.
+export class SmsReportingService {
+  private smsMessageRepository = new SmsMessageRepository();
+
+  // Where the provider posts delivery updates
+  getStatusCallbackUrl(): string {
+    return `${environmentConfig.getValue('API_URL')}/webhook/sms`;
+  }
+
+  // Record a message right after handing it to the provider. Never throws:
+  // reporting must not break sending.
+  async recordSent(entry: {
+    to: string;
+    purpose?: SmsPurpose;
+    providerMessageId?: string;
+    status?: string;
+    segments?: number;
+    price?: string | number | null;
+    priceUnit?: string | null;
+    errorCode?: string | number;
+    errorMessage?: string;
+  }): Promise<void> {
+    try {
+      const phone = PhoneUtils.parse(entry.to);
+      const e164 = phone?.e164 || entry.to;
+      const status = entry.errorCode ? 'failed' : STATUS_MAP[entry.status || 'queued'] || 'queued';
+      const price = entry.price != null ? Math.abs(Number(entry.price)) : undefined;
+
+      await this.smsMessageRepository.create({
+        provider: 'twilio',
+        providerMessageId: entry.providerMessageId,
+        purpose: entry.purpose || 'other',
+        phoneHash: hashPhone(e164),
+        maskedNumber: PhoneUtils.mask(e164),
+        countryCode: phone?.countryCode,
+        status,
+        errorCode: entry.errorCode !== undefined ? String(entry.errorCode) : undefined,
+        errorMessage: entry.errorMessage,
+        segments: entry.segments,
+        ...(price !== undefined && !isNaN(price) && { price, priceUnit: entry.priceUnit || undefined }),
+        sentAt: new Date(),
+        statusUpdatedAt: new Date(),
+      });
+
+      metricsCollector.incrementCounter('sms_sent', 1, { country: phone?.countryCode || 'unknown', status });
+
+      if (entry.providerMessageId && environmentConfig.getValue('TWILIO_CARRIER_LOOKUP')) {
+        await this.attachCarrier(entry.providerMessageId, e164);
+      }
+    } catch (error) {
+      logger.error('Failed to record SMS', error);
+    }
+  }
+
+  // Apply a status callback from the provider
+  async handleStatusCallback(params: Record<string, string>): Promise<ISmsMessage | null> {
+    const providerMessageId = params.MessageSid || params.SmsSid;
+    const status = STATUS_MAP[params.MessageStatus || params.SmsStatus];
+    if (!providerMessageId || !status) {
+      return null;
+    }
+
+    const now = new Date();
+    const update: Partial<ISmsMessage> = {
+      status,
+      statusUpdatedAt: now,
+      ...(status === 'delivered' && { deliveredAt: now }),
+      ...(params.ErrorCode && { errorCode: params.ErrorCode }),
+      ...(params.ErrorMessage && { errorMessage: params.ErrorMessage }),
+    };
+
+    // Price is only final once the message is
+    if (FINAL_STATUSES.includes(status)) {
+      const { smsService } = await import('./sms');
+      const details = await smsService.fetchMessageDetails(providerMessageId);
+      if (details?.price != null) {
+        update.price = Math.abs(Number(details.price));
+        update.priceUnit = details.priceUnit || undefined;
+      }
+      if (details?.segments) {
+        update.segments = details.segments;
+      }
+    }
+
+    const message = await this.smsMessageRepository.updateByProviderId(providerMessageId, update);
+    if (message && (status === 'failed' || status === 'undelivered')) {
+      metricsCollector.incrementCounter('sms_failed', 1, { country: message.countryCode || 'unknown' });
+    }
+    return message;
+  }
+
+  // Volume, outcomes and cost over the last days, with anomaly flags
+  async getReport(days: number, dimensions: SmsStatsDimension[]) {
+    const now = new Date();
+    const from = new Date(now.getTime() - days * DAY);
+
+    const [rows, totals, anomalies] = await Promise.all([
+      this.smsMessageRepository.getStats(from, now, dimensions),
+      this.smsMessageRepository.getStats(from, now, []),
+      this.detectAnomalies(now),
+    ]);
+
+    return {
+      totals: totals[0] ? withRates(totals[0]) : null,
+      rows: rows.map(withRates),
+      anomalies,
+    };
+  }
+
+  // Compare the last 24 hours per country and carrier with the week before
+  async detectAnomalies(now: Date = new Date()): Promise<SmsAnomaly[]> {
+    const recentFrom = new Date(now.getTime() - ANOMALY_WINDOW);
+    const baselineFrom = new Date(recentFrom.getTime() - ANOMALY_BASELINE_DAYS * DAY);
+    const anomalies: SmsAnomaly[] = [];
+
+    for (const dimension of ['country', 'carrier'] as const) {
+      const [recentRows, baselineRows] = await Promise.all([
+        this.smsMessageRepository.getStats(recentFrom, now, [dimension]),
+        this.smsMessageRepository.getStats(baselineFrom, recentFrom, [dimension]),
+      ]);
+
+      recentRows.forEach(recent => {
+        const value = recent[dimension] ?? null;
+        if (dimension === 'carrier' && value === null) return;
+
+        const baseline = baselineRows.find(row => (row[dimension] ?? null) === value);
+        const label = `${dimension} ${value ?? 'unknown'}`;
+
+        const recentFinal = recent.delivered + recent.failed;
+        if (recentFinal >= MIN_VOLUME) {
+          const recentRate = recent.failed / recentFinal;
+          const baselineFinal = baseline ? baseline.delivered + baseline.failed : 0;
+          const baselineRate = baselineFinal > 0 ? baseline!.failed / baselineFinal : null;
+
+          if (recentRate >= FAILURE_RATE_FLOOR && (baselineRate === null || recentRate >= baselineRate * FAILURE_SPIKE_FACTOR)) {
+            anomalies.push({
+              kind: 'failure_spike',
+              dimension,
+              value,
+              recent: round(recentRate),
+              baseline: baselineRate !== null ? round(baselineRate) : null,
+              volume: recent.sent,
+              message: `Failure rate for ${label} is ${Math.round(recentRate * 100)}%` +
+                (baselineRate !== null ? ` (was ${Math.round(baselineRate * 100)}%)` : ''),
+            });
+          }
+        }
+
+        if (recent.sent >= MIN_VOLUME && baseline && baseline.sent > 0) {
+          const recentCost = recent.cost / recent.sent;
+          const baselineCost = baseline.cost / baseline.sent;
+          if (baselineCost > 0 && recentCost >= baselineCost * COST_SPIKE_FACTOR) {
+            anomalies.push({
+              kind: 'cost_spike',
+              dimension,
+              value,
+              recent: round(recentCost),
+              baseline: round(baselineCost),
+              volume: recent.sent,
+              message: `Cost per message for ${label} rose to ${round(recentCost)} ${recent.priceUnit || ''}`.trim(),
+            });
+          }
+        }
+
+        const baselineDaily = (baseline?.sent ?? 0) / ANOMALY_BASELINE_DAYS;
+        if (dimension === 'country' && recent.sent >= MIN_VOLUME && recent.sent >= Math.max(baselineDaily, 1) * VOLUME_SPIKE_FACTOR) {
+          anomalies.push({
+            kind: 'volume_spike',
+            dimension,
+            value,
+            recent: recent.sent,
+            baseline: round(baselineDaily, 1),
+            volume: recent.sent,
+            message: `${recent.sent} messages to ${label} in 24h, usually ${round(baselineDaily, 1)} a day`,
+          });
+        }
+      });
+    }
+
+    return anomalies;
+  }
+
+  // Add carrier details, reusing a recent lookup for the same number
+  private async attachCarrier(providerMessageId: string, e164: string): Promise<void> {
+    const since = new Date(Date.now() - CARRIER_CACHE_DAYS * DAY);
+    let carrier = await this.smsMessageRepository.findRecentCarrier(hashPhone(e164), since);
+
+    if (!carrier) {
+      const { smsService } = await import('./sms');
+      carrier = await smsService.lookupCarrier(e164);
+    }
+
+    if (carrier?.carrier) {
+      await this.smsMessageRepository.updateByProviderId(providerMessageId, {
+        carrier: carrier.carrier,
+        lineType: carrier.lineType,
+      });
+    }
+  }
+}
+
+function withRates(row: SmsStatsRow) {
+  const final = row.delivered + row.failed;
+  return {
+    ...row,
+    cost: round(row.cost),
+    deliveryRate: final > 0 ? round(row.delivered / final) : null,
+    failureRate: final > 0 ? round(row.failed / final) : null,
+    costPerMessage: row.sent > 0 ? round(row.cost / row.sent) : null,
+  };
+}
+
+export const smsReportingService = new SmsReportingService();
@@ -1,5 +1,7 @@
 import twilio from 'twilio';
 import { IUser } from '../database/models/user';
+import { SmsPurpose } from '../database/models/sms-message';
+import { smsReportingService } from './sms-reporting';
 
 interface SMSConfig {
   accountSid: string;
@@ -11,6 +13,7 @@ interface SMSOptions {
   to: string;
   body: string;
   from?: string;
+  purpose?: SmsPurpose; // for cost reporting
 }
 
 interface SMSResult {
@@ -22,10 +25,12 @@ interface SMSResult {
 export class SMSService {
   private client: twilio.Twilio;
   private fromNumber: string;
+  private authToken: string;
 
   constructor(config: SMSConfig) {
     this.client = twilio(config.accountSid, config.authToken);
     this.fromNumber = config.fromNumber;
+    this.authToken = config.authToken;
   }
 
   // Send single SMS
@@ -35,6 +40,17 @@ export class SMSService {
         body: options.body,
         from: options.from || this.fromNumber,
         to: options.to,
+        statusCallback: smsReportingService.getStatusCallbackUrl(),
+      });
+
+      await smsReportingService.recordSent({
+        to: options.to,
+        purpose: options.purpose,
+        providerMessageId: message.sid,
+        status: message.status,
+        segments: Number(message.numSegments) || undefined,
+        price: message.price,
+        priceUnit: message.priceUnit,
       });
 
       return {
@@ -43,6 +59,12 @@ export class SMSService {
       };
     } catch (error) {
       console.error('SMS send error:', error);
+      await smsReportingService.recordSent({
+        to: options.to,
+        purpose: options.purpose,
+        errorCode: (error as { code?: number }).code ?? 'send_error',
+        errorMessage: error instanceof Error ? error.message : undefined,
+      });
       return {
         success: false,
         error: error instanceof Error ? error.message : 'Unknown error',
@@ -67,32 +89,35 @@ export class SMSService {
   async sendOTPSMS(
     phoneNumber: string,
     otp: string,
-    expiresInMinutes: number = 10
+    expiresInMinutes: number = 10,
+    locale?: string
   ): Promise<SMSResult> {
     const { generateOTPSMSTemplate } = await import('./templates/sms');
     
     const message = generateOTPSMSTemplate({
       otp,
       expiresInMinutes,
-    });
+    }, locale);
 
     return await this.sendSMS({
       to: phoneNumber,
       body: message,
+      purpose: 'otp',
     });
   }
 
   // Send welcome SMS
-  async sendWelcomeSMS(user: Pick<IUser, 'phoneNumber' | 'displayName'>): Promise<SMSResult> {
+  async sendWelcomeSMS(user: Pick<IUser, 'phoneNumber' | 'displayName' | 'language'>): Promise<SMSResult> {
     const { generateWelcomeSMSTemplate } = await import('./templates/sms');
     
     const message = generateWelcomeSMSTemplate({
       userName: user.displayName,
-    });
+    }, user.language);
 
     return await this.sendSMS({
       to: user.phoneNumber,
       body: message,
+      purpose: 'welcome',
     });
   }
 
@@ -100,21 +125,70 @@ export class SMSService {
   async sendNotificationSMS(
     phoneNumber: string,
     title: string,
-    body: string
+    body: string,
+    locale?: string
   ): Promise<SMSResult> {
     const { generateNotificationSMSTemplate } = await import('./templates/sms');
     
     const message = generateNotificationSMSTemplate({
       title,
       body,
-    });
+    }, locale);
 
     return await this.sendSMS({
       to: phoneNumber,
       body: message,
+      purpose: 'notification',
+    });
+  }
+
+  // Send new sign-in approval SMS
+  async sendLoginApprovalSMS(
+    phoneNumber: string,
+    data: { device: string; location: string; link: string },
+    locale?: string
+  ): Promise<SMSResult> {
+    const { generateLoginApprovalSMSTemplate } = await import('./templates/sms');
+
+    return await this.sendSMS({
+      to: phoneNumber,
+      body: generateLoginApprovalSMSTemplate(data, locale),
+      purpose: 'notification',
     });
   }
 
+  // Final price and segment count of a sent message
+  async fetchMessageDetails(messageSid: string): Promise<{ price: string | null; priceUnit: string | null; segments?: number } | null> {
+    try {
+      const message = await this.client.messages(messageSid).fetch();
+      return {
+        price: message.price,
+        priceUnit: message.priceUnit,
+        segments: Number(message.numSegments) || undefined,
+      };
+    } catch (error) {
+      console.error('SMS fetch error:', error);
+      return null;
+    }
+  }
+
+  // Carrier and line type of a number (a paid Lookup request)
+  async lookupCarrier(phoneNumber: string): Promise<{ carrier?: string; lineType?: string } | null> {
+    try {
+      const result = await this.client.lookups.v2.phoneNumbers(phoneNumber).fetch({ fields: 'line_type_intelligence' });
+      const intelligence = result.lineTypeIntelligence as { carrier_name?: string; type?: string } | null;
+      return intelligence ? { carrier: intelligence.carrier_name || undefined, lineType: intelligence.type || undefined } : null;
+    } catch (error) {
+      console.error('SMS carrier lookup error:', error);
+      return null;
+    }
+  }
+
+  // Check the signature on a status callback
+  verifyStatusCallback(signature: string | null, url: string, params: Record<string, string>): boolean {
+    return !!signature && twilio.validateRequest(this.authToken, signature, url, params);
+  }
+
   // Validate phone number format
   validatePhoneNumber(phoneNumber: string): boolean {
     // Basic international phone number validation
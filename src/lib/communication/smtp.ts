@@ -1,6 +1,7 @@
 import nodemailer from 'nodemailer';
 import { IUser } from '../database/models/user';
 import { INotification } from '../database/models/notification';
+import { t } from '../i18n';
 
 interface SMTPConfig {
   host: string;
@@ -19,6 +20,9 @@ interface EmailOptions {
   text?: string;
   from?: string;
   replyTo?: string;
+  messageId?: string;
+  inReplyTo?: string;
+  references?: string[];
   attachments?: Array<{
     filename: string;
     content: Buffer;
@@ -56,6 +60,9 @@ export class SMTPService {
         html: options.html,
         text: options.text,
         replyTo: options.replyTo,
+        messageId: options.messageId,
+        inReplyTo: options.inReplyTo,
+        references: options.references,
         attachments: options.attachments,
       };
 
@@ -92,7 +99,8 @@ export class SMTPService {
     email: string,
     otp: string,
     userName: string,
-    expiresInMinutes: number = 10
+    expiresInMinutes: number = 10,
+    locale?: string
   ): Promise<EmailResult> {
     const { generateOTPEmailTemplate } = await import('./templates/email');
     
@@ -100,18 +108,18 @@ export class SMTPService {
       userName,
       otp,
       expiresInMinutes,
-    });
+    }, locale);
 
     return await this.sendEmail({
       to: email,
-      subject: 'Your Verification Code',
+      subject: t(locale, 'email.otp.subject'),
       html: htmlContent,
-      text: `Your verification code is: ${otp}. This code expires in ${expiresInMinutes} minutes.`,
+      text: t(locale, 'email.otp.text', { otp, minutes: expiresInMinutes }),
     });
   }
 
   // Send welcome email
-  async sendWelcomeEmail(user: Pick<IUser, 'email' | 'displayName'>): Promise<EmailResult> {
+  async sendWelcomeEmail(user: Pick<IUser, 'email' | 'displayName' | 'language'>): Promise<EmailResult> {
     if (!user.email) {
       return { success: false, error: 'No email address provided' };
     }
@@ -120,18 +128,18 @@ export class SMTPService {
     
     const htmlContent = generateWelcomeEmailTemplate({
       userName: user.displayName,
-    });
+    }, user.language);
 
     return await this.sendEmail({
       to: user.email,
-      subject: 'Welcome to Our Chat App!',
+      subject: t(user.language, 'email.welcome.subject'),
       html: htmlContent,
     });
   }
 
   // Send notification email
   async sendNotificationEmail(
-    user: Pick<IUser, 'email' | 'displayName'>,
+    user: Pick<IUser, 'email' | 'displayName' | 'language'>,
     notification: Pick<INotification, 'title' | 'body'>
   ): Promise<EmailResult> {
     if (!user.email) {
@@ -144,7 +152,7 @@ export class SMTPService {
       userName: user.displayName,
       notificationTitle: notification.title,
       notificationBody: notification.body,
-    });
+    }, user.language);
 
     return await this.sendEmail({
       to: user.email,
@@ -157,7 +165,8 @@ export class SMTPService {
   async sendPasswordResetEmail(
     email: string,
     resetToken: string,
-    userName: string
+    userName: string,
+    locale?: string
   ): Promise<EmailResult> {
     const { generatePasswordResetEmailTemplate } = await import('./templates/email');
     
@@ -166,11 +175,11 @@ export class SMTPService {
     const htmlContent = generatePasswordResetEmailTemplate({
       userName,
       resetLink,
-    });
+    }, locale);
 
     return await this.sendEmail({
       to: email,
-      subject: 'Password Reset Request',
+      subject: t(locale, 'email.reset.subject'),
       html: htmlContent,
     });
   }
@@ -1,3 +1,5 @@
+import { t } from '../../i18n';
+
 interface OTPEmailTemplateData {
   userName: string;
   otp: string;
@@ -20,14 +22,14 @@ interface PasswordResetEmailTemplateData {
 }
 
 // Generate OTP email template
-export function generateOTPEmailTemplate(data: OTPEmailTemplateData): string {
+export function generateOTPEmailTemplate(data: OTPEmailTemplateData, locale?: string): string {
   return `
     <!DOCTYPE html>
-    <html lang="en">
+    <html lang="${locale || 'en'}">
     <head>
         <meta charset="UTF-8">
         <meta name="viewport" content="width=device-width, initial-scale=1.0">
-        <title>Verification Code</title>
+        <title>${t(locale, 'email.otp.title')}</title>
         <style>
             body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f5f5f5; }
             .container { max-width: 600px; margin: 0 auto; background-color: white; }
@@ -42,22 +44,22 @@ export function generateOTPEmailTemplate(data: OTPEmailTemplateData): string {
     <body>
         <div class="container">
             <div class="header">
-                <h1>🔐 Verification Code</h1>
+                <h1>🔐 ${t(locale, 'email.otp.title')}</h1>
             </div>
             <div class="content">
-                <h2>Hello ${data.userName}!</h2>
-                <p>Your verification code is:</p>
+                <h2>${t(locale, 'email.greeting', { userName: data.userName })}</h2>
+                <p>${t(locale, 'email.otp.intro')}</p>
                 <div class="otp-code">${data.otp}</div>
-                <p>Enter this code to complete your verification.</p>
+                <p>${t(locale, 'email.otp.instructions')}</p>
                 <div class="warning">
-                    <strong>⚠️ Security Notice:</strong><br>
-                    This code expires in ${data.expiresInMinutes} minutes.<br>
-                    Never share this code with anyone.
+                    <strong>⚠️ ${t(locale, 'email.securityNotice')}</strong><br>
+                    ${t(locale, 'email.otp.expires', { minutes: data.expiresInMinutes })}<br>
+                    ${t(locale, 'email.otp.neverShare')}
                 </div>
             </div>
             <div class="footer">
-                <p>If you didn't request this code, please ignore this email.</p>
-                <p>&copy; ${new Date().getFullYear()} Chat App. All rights reserved.</p>
+                <p>${t(locale, 'email.otp.ignore')}</p>
+                <p>${t(locale, 'email.copyright', { year: new Date().getFullYear() })}</p>
             </div>
         </div>
     </body>
@@ -66,14 +68,14 @@ export function generateOTPEmailTemplate(data: OTPEmailTemplateData): string {
 }
 
 // Generate welcome email template
-export function generateWelcomeEmailTemplate(data: WelcomeEmailTemplateData): string {
+export function generateWelcomeEmailTemplate(data: WelcomeEmailTemplateData, locale?: string): string {
   return `
     <!DOCTYPE html>
-    <html lang="en">
+    <html lang="${locale || 'en'}">
     <head>
         <meta charset="UTF-8">
         <meta name="viewport" content="width=device-width, initial-scale=1.0">
-        <title>Welcome to Chat App</title>
+        <title>${t(locale, 'email.welcome.title')}</title>
         <style>
             body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f5f5f5; }
             .container { max-width: 600px; margin: 0 auto; background-color: white; }
@@ -88,39 +90,39 @@ export function generateWelcomeEmailTemplate(data: WelcomeEmailTemplateData): st
     <body>
         <div class="container">
             <div class="header">
-                <h1>🎉 Welcome to Chat App!</h1>
+                <h1>🎉 ${t(locale, 'email.welcome.title')}</h1>
             </div>
             <div class="content">
-                <h2>Hi ${data.userName}!</h2>
-                <p>We're excited to have you join our chat community! Get ready to connect with friends and family like never before.</p>
+                <h2>${t(locale, 'email.greeting', { userName: data.userName })}</h2>
+                <p>${t(locale, 'email.welcome.intro')}</p>
                 
                 <div class="feature">
-                    <h3>💬 Instant Messaging</h3>
-                    <p>Send messages, photos, videos, and voice notes instantly.</p>
+                    <h3>💬 ${t(locale, 'email.welcome.messaging.title')}</h3>
+                    <p>${t(locale, 'email.welcome.messaging.body')}</p>
                 </div>
                 
                 <div class="feature">
-                    <h3>📞 Voice & Video Calls</h3>
-                    <p>Make crystal-clear voice and video calls to anyone, anywhere.</p>
+                    <h3>📞 ${t(locale, 'email.welcome.calls.title')}</h3>
+                    <p>${t(locale, 'email.welcome.calls.body')}</p>
                 </div>
                 
                 <div class="feature">
-                    <h3>👥 Group Chats</h3>
-                    <p>Create groups and chat with multiple people at once.</p>
+                    <h3>👥 ${t(locale, 'email.welcome.groups.title')}</h3>
+                    <p>${t(locale, 'email.welcome.groups.body')}</p>
                 </div>
                 
                 <div class="feature">
-                    <h3>🔒 Privacy & Security</h3>
-                    <p>Your conversations are protected with end-to-end encryption.</p>
+                    <h3>🔒 ${t(locale, 'email.welcome.privacy.title')}</h3>
+                    <p>${t(locale, 'email.welcome.privacy.body')}</p>
                 </div>
                 
                 <center>
-                    <a href="${process.env.FRONTEND_URL}" class="cta-button">Start Chatting Now</a>
+                    <a href="${process.env.FRONTEND_URL}" class="cta-button">${t(locale, 'email.welcome.cta')}</a>
                 </center>
             </div>
             <div class="footer">
-                <p>Need help? Contact our support team anytime.</p>
-                <p>&copy; ${new Date().getFullYear()} Chat App. All rights reserved.</p>
+                <p>${t(locale, 'email.welcome.help')}</p>
+                <p>${t(locale, 'email.copyright', { year: new Date().getFullYear() })}</p>
             </div>
         </div>
     </body>
@@ -129,10 +131,10 @@ export function generateWelcomeEmailTemplate(data: WelcomeEmailTemplateData): st
 }
 
 // Generate notification email template
-export function generateNotificationEmailTemplate(data: NotificationEmailTemplateData): string {
+export function generateNotificationEmailTemplate(data: NotificationEmailTemplateData, locale?: string): string {
   return `
     <!DOCTYPE html>
-    <html lang="en">
+    <html lang="${locale || 'en'}">
     <head>
         <meta charset="UTF-8">
         <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -153,15 +155,15 @@ export function generateNotificationEmailTemplate(data: NotificationEmailTemplat
                 <h1>🔔 ${data.notificationTitle}</h1>
             </div>
             <div class="content">
-                <h2>Hi ${data.userName}!</h2>
+                <h2>${t(locale, 'email.greeting', { userName: data.userName })}</h2>
                 <div class="notification-content">
                     <p>${data.notificationBody}</p>
                 </div>
-                <p>Open the app to see more details and respond.</p>
+                <p>${t(locale, 'email.notification.openApp')}</p>
             </div>
             <div class="footer">
-                <p>You can manage your notification preferences in the app settings.</p>
-                <p>&copy; ${new Date().getFullYear()} Chat App. All rights reserved.</p>
+                <p>${t(locale, 'email.notification.manage')}</p>
+                <p>${t(locale, 'email.copyright', { year: new Date().getFullYear() })}</p>
             </div>
         </div>
     </body>
@@ -170,14 +172,14 @@ export function generateNotificationEmailTemplate(data: NotificationEmailTemplat
 }
 
 // Generate password reset email template
-export function generatePasswordResetEmailTemplate(data: PasswordResetEmailTemplateData): string {
+export function generatePasswordResetEmailTemplate(data: PasswordResetEmailTemplateData, locale?: string): string {
   return `
     <!DOCTYPE html>
-    <html lang="en">
+    <html lang="${locale || 'en'}">
     <head>
         <meta charset="UTF-8">
         <meta name="viewport" content="width=device-width, initial-scale=1.0">
-        <title>Password Reset</title>
+        <title>${t(locale, 'email.reset.title')}</title>
         <style>
             body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f5f5f5; }
             .container { max-width: 600px; margin: 0 auto; background-color: white; }
@@ -192,22 +194,22 @@ export function generatePasswordResetEmailTemplate(data: PasswordResetEmailTempl
     <body>
         <div class="container">
             <div class="header">
-                <h1>🔑 Password Reset</h1>
+                <h1>🔑 ${t(locale, 'email.reset.title')}</h1>
             </div>
             <div class="content">
-                <h2>Hi ${data.userName}!</h2>
-                <p>We received a request to reset your password. Click the button below to create a new password:</p>
-                <a href="${data.resetLink}" class="reset-button">Reset Password</a>
+                <h2>${t(locale, 'email.greeting', { userName: data.userName })}</h2>
+                <p>${t(locale, 'email.reset.intro')}</p>
+                <a href="${data.resetLink}" class="reset-button">${t(locale, 'email.reset.button')}</a>
                 <div class="warning">
-                    <strong>⚠️ Security Notice:</strong><br>
-                    This link expires in 1 hour for security reasons.<br>
-                    If you didn't request this reset, please ignore this email.
+                    <strong>⚠️ ${t(locale, 'email.securityNotice')}</strong><br>
+                    ${t(locale, 'email.reset.expires')}<br>
+                    ${t(locale, 'email.reset.ignore')}
                 </div>
             </div>
             <div class="footer">
-                <p>If the button doesn't work, copy and paste this link:</p>
+                <p>${t(locale, 'email.reset.copyLink')}</p>
                 <p style="word-break: break-all;">${data.resetLink}</p>
-                <p>&copy; ${new Date().getFullYear()} Chat App. All rights reserved.</p>
+                <p>${t(locale, 'email.copyright', { year: new Date().getFullYear() })}</p>
             </div>
         </div>
     </body>
@@ -1,3 +1,5 @@
+import { t } from '../../i18n';
+
 interface OTPSMSTemplateData {
   otp: string;
   expiresInMinutes: number;
@@ -13,44 +15,33 @@ interface NotificationSMSTemplateData {
 }
 
 // Generate OTP SMS template
-export function generateOTPSMSTemplate(data: OTPSMSTemplateData): string {
-  return `Your Chat App verification code is: ${data.otp}
-
-This code expires in ${data.expiresInMinutes} minutes. Do not share this code with anyone.
-
-If you didn't request this code, please ignore this message.`;
+export function generateOTPSMSTemplate(data: OTPSMSTemplateData, locale?: string): string {
+  return t(locale, 'sms.otp', { otp: data.otp, minutes: data.expiresInMinutes });
 }
 
 // Generate welcome SMS template
-export function generateWelcomeSMSTemplate(data: WelcomeSMSTemplateData): string {
-  return `Welcome to Chat App, ${data.userName}! 🎉
-
-Start chatting with friends and family instantly. Download our app and enjoy:
-- Instant messaging
-- Voice & video calls  
-- Group chats
-- Secure & private
-
-Happy chatting!`;
+export function generateWelcomeSMSTemplate(data: WelcomeSMSTemplateData, locale?: string): string {
+  return t(locale, 'sms.welcome', { userName: data.userName });
 }
 
 // Generate notification SMS template
-export function generateNotificationSMSTemplate(data: NotificationSMSTemplateData): string {
+export function generateNotificationSMSTemplate(data: NotificationSMSTemplateData, locale?: string): string {
   return `${data.title}
 
 ${data.body}
 
-Open Chat App to see more details.`;
+${t(locale, 'sms.notification.footer')}`;
 }
 
 // Generate call notification SMS template
 export function generateCallNotificationSMSTemplate(data: {
   callerName: string;
   callType: 'voice' | 'video';
-}): string {
-  return `📞 Missed ${data.callType} call from ${data.callerName}
-
-Open Chat App to call back or see more details.`;
+}, locale?: string): string {
+  return t(locale, 'sms.missedCall', {
+    callType: t(locale, `call.type.${data.callType}`),
+    callerName: data.callerName,
+  });
 }
 
 // Generate group invitation SMS template
@@ -58,10 +49,23 @@ export function generateGroupInvitationSMSTemplate(data: {
   inviterName: string;
   groupName: string;
   inviteLink: string;
-}): string {
-  return `${data.inviterName} invited you to join "${data.groupName}" on Chat App!
-
-Join here: ${data.inviteLink}
+}, locale?: string): string {
+  return t(locale, 'sms.groupInvite', {
+    inviterName: data.inviterName,
+    groupName: data.groupName,
+    inviteLink: data.inviteLink,
+  });
+}
 
-Download Chat App if you don't have it yet.`;
-}
\ No newline at end of file
+// Generate new sign-in approval SMS template
+export function generateLoginApprovalSMSTemplate(data: {
+  device: string;
+  location: string;
+  link: string;
+}, locale?: string): string {
+  return t(locale, 'sms.loginApproval', {
+    device: data.device,
+    location: data.location,
+    link: data.link,
+  });
+}
@@ -0,0 +1,226 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { ArchiveAccessTokenRepository } from '../database/repositories/archive-access-token';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { IArchiveAccessToken } from '../database/models/archive-access-token';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { CreateArchiveAccessInput } from '../database/schemas/archive-access';
+import { AUTH_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+
+function hashToken(token: string): string {
+  return crypto.createHash('sha256').update(token).digest('hex');
+}
+
+export function isArchiveAccessToken(token: string): boolean {
+  return token.startsWith(AUTH_CONSTANTS.ARCHIVE_TOKEN_PREFIX);
+}
+
+// Archive access tokens give support or legal staff a read-only view of one
+// chat's history without making them a member or handing out an admin
+// account. An admin issues a token with a justification; whoever holds it
+// can page through the chat's messages, optionally only those sent within a
+// window, until it expires or is revoked. Only the hash is stored and the
+// token is shown once. Issuing, every read and revoking are audit-logged
+// against the token. Org admins can only issue tokens for their own
+// organization's groups.
+export class ArchiveAccessService {
+  private archiveAccessTokenRepository = new ArchiveAccessTokenRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+
+  // Issue a token; the returned string is the only copy
+  async create(
+    adminId: string,
+    input: CreateArchiveAccessInput,
+    adminOrganizationId?: string
+  ): Promise<{ token: IArchiveAccessToken; chat: IChat; secret: string }> {
+    const chat = await this.chatRepository.findById(input.chatId);
+    if (!chat || (adminOrganizationId && chat.organizationId?.toString() !== adminOrganizationId)) {
+      throw new ArchiveAccessError('Chat not found', 404);
+    }
+
+    const secret = AUTH_CONSTANTS.ARCHIVE_TOKEN_PREFIX + crypto.randomBytes(32).toString('base64url');
+
+    const token = await this.archiveAccessTokenRepository.create({
+      chatId: chat._id,
+      tokenHash: hashToken(secret),
+      tokenPrefix: secret.slice(0, AUTH_CONSTANTS.ARCHIVE_TOKEN_PREFIX.length + 6),
+      justification: input.justification,
+      caseReference: input.caseReference,
+      messagesFrom: input.messagesFrom,
+      messagesTo: input.messagesTo,
+      createdBy: new Types.ObjectId(adminId),
+      organizationId: chat.organizationId,
+      expiresAt: new Date(Date.now() + input.expiresInHours * 60 * 60 * 1000),
+    });
+
+    await this.audit(adminId, 'archive_access.create', token._id, {
+      chatId: input.chatId,
+      justification: input.justification,
+      caseReference: input.caseReference,
+      messagesFrom: input.messagesFrom,
+      messagesTo: input.messagesTo,
+      expiresAt: token.expiresAt,
+    });
+    logger.info('Archive access token issued', { adminId, tokenId: token._id.toString(), chatId: input.chatId });
+
+    return { token, chat, secret };
+  }
+
+  async list(
+    filter: { chatId?: string; includeRevoked?: boolean },
+    limit: number,
+    offset: number,
+    adminOrganizationId?: string
+  ): Promise<{ tokens: IArchiveAccessToken[]; total: number }> {
+    return await this.archiveAccessTokenRepository.list(
+      { ...filter, organizationId: adminOrganizationId },
+      limit,
+      offset
+    );
+  }
+
+  async get(tokenId: string, adminOrganizationId?: string): Promise<IArchiveAccessToken> {
+    const token = Types.ObjectId.isValid(tokenId) ? await this.archiveAccessTokenRepository.findById(tokenId) : null;
+    if (!token || (adminOrganizationId && token.organizationId?.toString() !== adminOrganizationId)) {
+      throw new ArchiveAccessError('Archive access token not found', 404);
+    }
+    return token;
+  }
+
+  async revoke(adminId: string, tokenId: string, reason: string, adminOrganizationId?: string): Promise<IArchiveAccessToken> {
+    const existing = await this.get(tokenId, adminOrganizationId);
+
+    const token = await this.archiveAccessTokenRepository.revoke(existing._id, new Types.ObjectId(adminId), reason);
+    if (!token) {
+      throw new ArchiveAccessError('Archive access token has already been revoked', 409);
+    }
+
+    await this.audit(adminId, 'archive_access.revoke', token._id, { chatId: token.chatId.toString(), reason });
+    logger.info('Archive access token revoked', { adminId, tokenId });
+    return token;
+  }
+
+  // A page of the chat's messages, newest first, within the token's window.
+  // Unknown, revoked and expired tokens are all refused the same way.
+  async readMessages(
+    secret: string,
+    options: { before?: Date; limit: number; ip?: string }
+  ): Promise<{ token: IArchiveAccessToken; chat: IChat; messages: IMessage[]; hasMore: boolean }> {
+    const token = await this.verify(secret);
+    if (!token) {
+      throw new ArchiveAccessError('Invalid or expired archive access token', 401);
+    }
+
+    const chat = await this.chatRepository.findById(token.chatId);
+    if (!chat) {
+      throw new ArchiveAccessError('Chat not found', 404);
+    }
+
+    let before = options.before;
+    if (token.messagesTo && (!before || before > token.messagesTo)) {
+      before = token.messagesTo;
+    }
+
+    // One extra to tell whether there is an older page
+    const page = await this.messageRepository.getChatMessages(token.chatId, options.limit + 1, before);
+    const inWindow = token.messagesFrom
+      ? page.filter(message => message.createdAt >= token.messagesFrom!)
+      : page;
+    const messages = inWindow.slice(0, options.limit);
+
+    await this.archiveAccessTokenRepository.recordUse(token._id, options.ip);
+    await this.audit(token.createdBy.toString(), 'archive_access.read', token._id, {
+      chatId: token.chatId.toString(),
+      before: options.before,
+      count: messages.length,
+      ip: options.ip,
+    });
+
+    return { token, chat, messages, hasMore: inWindow.length > options.limit };
+  }
+
+  private async verify(secret: string): Promise<IArchiveAccessToken | null> {
+    if (!isArchiveAccessToken(secret)) {
+      return null;
+    }
+
+    const token = await this.archiveAccessTokenRepository.findByHash(hashToken(secret));
+    if (!token || token.revokedAt || token.expiresAt <= new Date()) {
+      return null;
+    }
+    return token;
+  }
+
+  // Reads are recorded against the admin who issued the token, since the
+  // reader has no account of their own
+  private async audit(adminId: string, action: string, tokenId: Types.ObjectId, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action,
+      targetType: 'archive_access',
+      targetId: tokenId,
+      details,
+    });
+  }
+}
+
+// Admin-facing view of a token (never includes the secret)
+export function serializeArchiveAccessToken(token: IArchiveAccessToken) {
+  return {
+    id: token._id.toString(),
+    chatId: token.chatId.toString(),
+    tokenPrefix: token.tokenPrefix,
+    justification: token.justification,
+    caseReference: token.caseReference,
+    messagesFrom: token.messagesFrom,
+    messagesTo: token.messagesTo,
+    createdBy: token.createdBy.toString(),
+    organizationId: token.organizationId?.toString(),
+    expiresAt: token.expiresAt,
+    expired: token.expiresAt <= new Date(),
+    revoked: !!token.revokedAt,
+    revokedAt: token.revokedAt,
+    revokedBy: token.revokedBy?.toString(),
+    revokeReason: token.revokeReason,
+    lastUsedAt: token.lastUsedAt,
+    lastUsedIp: token.lastUsedIp,
+    useCount: token.useCount,
+    createdAt: token.createdAt,
+  };
+}
+
+// What the token holder sees of a message. Senders are shown as themselves,
+// pseudonymous groups included.
+export function serializeArchivedMessage(message: IMessage) {
+  const sender = message.senderId as any;
+  return {
+    id: message._id.toString(),
+    seq: message.seq,
+    sender: sender?._id
+      ? { id: sender._id.toString(), displayName: sender.displayName }
+      : { id: sender?.toString() },
+    type: message.type,
+    content: message.content,
+    media: (message.media as any)?._id?.toString() ?? message.media?.toString(),
+    replyTo: (message.replyTo as any)?._id?.toString() ?? message.replyTo?.toString(),
+    forwarded: !!message.forwardedFrom,
+    isEdited: message.isEdited,
+    editedAt: message.editedAt,
+    createdAt: message.createdAt,
+  };
+}
+
+export class ArchiveAccessError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ArchiveAccessError';
+  }
+}
+
+export const archiveAccessService = new ArchiveAccessService();
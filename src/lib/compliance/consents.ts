@@ -0,0 +1,154 @@
+import { UserRepository } from '../database/repositories/user';
+import { IUser } from '../database/models/user';
+import { IConsentType } from '../database/models/admin-config';
+import { adminConfigService } from '../config/admin-config';
+import { ConsentDecisionInput } from '../database/schemas/user';
+import { logger } from '../monitoring/logging';
+
+export interface ConsentStatus {
+  key: string;
+  title: string;
+  description?: string;
+  documentUrl?: string;
+  version: number;
+  mandatory: boolean;
+  accepted: boolean; // accepted at the current version
+  decidedVersion: number | null; // version of the user's latest decision
+  decidedAt: Date | null;
+  pending: boolean; // mandatory and not accepted at the current version
+}
+
+export function getConsentStatus(
+  user: Pick<IUser, 'consents'>,
+  consentTypes: IConsentType[] = adminConfigService.getCached().compliance.consentTypes || []
+): ConsentStatus[] {
+  return consentTypes.map(consentType => {
+    const decision = user.consents?.find(consent => consent.key === consentType.key);
+    const accepted = !!decision?.accepted && decision.version >= consentType.version;
+
+    return {
+      key: consentType.key,
+      title: consentType.title,
+      description: consentType.description,
+      documentUrl: consentType.documentUrl,
+      version: consentType.version,
+      mandatory: consentType.mandatory,
+      accepted,
+      decidedVersion: decision?.version ?? null,
+      decidedAt: decision?.decidedAt ?? null,
+      pending: consentType.mandatory && !accepted,
+    };
+  });
+}
+
+// Mandatory consents the user still has to accept; empty when up to date
+export function getPendingConsents(
+  user: Pick<IUser, 'consents'>,
+  consentTypes?: IConsentType[]
+): ConsentStatus[] {
+  return getConsentStatus(user, consentTypes).filter(status => status.pending);
+}
+
+// Captures users' consent decisions against the consent types configured in
+// the admin compliance settings. Only the latest decision per type is kept
+// on the user, with the version it was made on, so raising a type's version
+// puts everyone back to pending until they accept again.
+export class ConsentService {
+  private userRepository = new UserRepository();
+
+  async getStatus(user: Pick<IUser, 'consents'>): Promise<ConsentStatus[]> {
+    const { compliance } = await adminConfigService.get();
+    return getConsentStatus(user, compliance.consentTypes || []);
+  }
+
+  async record(
+    userId: string,
+    decisions: ConsentDecisionInput[],
+    meta: { ip?: string; userAgent?: string } = {}
+  ): Promise<ConsentStatus[]> {
+    const { compliance } = await adminConfigService.get();
+    const consentTypes = compliance.consentTypes || [];
+
+    // Validate everything before writing anything
+    decisions.forEach(decision => {
+      const consentType = consentTypes.find(type => type.key === decision.key);
+      if (!consentType) {
+        throw new ConsentError(`Unknown consent: ${decision.key}`, 404);
+      }
+      if (decision.version !== consentType.version) {
+        throw new ConsentError(`Consent ${decision.key} is now at version ${consentType.version}; please review it again`, 409);
+      }
+      if (consentType.mandatory && !decision.accepted) {
+        throw new ConsentError(`Consent ${decision.key} is required to use the service`, 400);
+      }
+    });
+
+    let user: IUser | null = null;
+    for (const decision of decisions) {
+      user = await this.userRepository.recordConsent(userId, {
+        key: decision.key,
+        version: decision.version,
+        accepted: decision.accepted,
+        decidedAt: new Date(),
+        ip: meta.ip,
+        userAgent: meta.userAgent,
+      });
+    }
+
+    if (!user) {
+      throw new ConsentError('User not found', 404);
+    }
+
+    logger.info('Consent decisions recorded', {
+      userId,
+      consents: decisions.map(decision => `${decision.key}@${decision.version}:${decision.accepted ? 'accepted' : 'declined'}`),
+    });
+
+    return getConsentStatus(user, consentTypes);
+  }
+
+  // How many users are on each consent type's current version, for admins
+  // watching adoption after a terms change
+  async getCoverage() {
+    const { compliance } = await adminConfigService.get();
+    const consentTypes = compliance.consentTypes || [];
+
+    const [totalUsers, rows] = await Promise.all([
+      this.userRepository.countHumanUsers(),
+      this.userRepository.getConsentDistribution(consentTypes.map(type => type.key)),
+    ]);
+
+    return consentTypes.map(consentType => {
+      const versions = rows.filter(row => row.key === consentType.key);
+      const current = versions.filter(row => row.version >= consentType.version);
+      const acceptedCurrent = current.reduce((sum, row) => sum + row.accepted, 0);
+      const declinedCurrent = current.reduce((sum, row) => sum + row.declined, 0);
+      const outdated = versions
+        .filter(row => row.version < consentType.version)
+        .reduce((sum, row) => sum + row.accepted + row.declined, 0);
+
+      return {
+        key: consentType.key,
+        title: consentType.title,
+        version: consentType.version,
+        mandatory: consentType.mandatory,
+        totalUsers,
+        acceptedCurrent,
+        declinedCurrent,
+        outdated, // decided on an older version
+        undecided: Math.max(totalUsers - acceptedCurrent - declinedCurrent, 0),
+        coverage: totalUsers > 0 ? Math.round((acceptedCurrent / totalUsers) * 1000) / 10 : 0, // percent
+        versions,
+      };
+    });
+  }
+}
+
+export class ConsentError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ConsentError';
+  }
+}
+
+export const consentService = new ConsentService();
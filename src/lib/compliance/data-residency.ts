@@ -0,0 +1,328 @@
+import { Types } from 'mongoose';
+import { RegionMigrationRepository } from '../database/repositories/region-migration';
+import { UserRepository } from '../database/repositories/user';
+import { ChatRepository } from '../database/repositories/chat';
+import { MediaRepository } from '../database/repositories/media';
+import { IRegionMigration, RegionMigrationSubject } from '../database/models/region-migration';
+import { DATA_REGIONS, DataRegion, IUser } from '../database/models/user';
+import { IMedia } from '../database/models/media';
+import { getArchiveModel } from '../database/regional';
+import { environmentConfig } from '../config/environment';
+import { storageService } from '../media/storage';
+import { storageReferenceService } from '../media/storage-references';
+import { cdnService } from '../media/cdn';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const STALL_TIMEOUT = 10 * 60 * 1000; // without a progress update
+const BUCKET_BATCH_SIZE = 50;
+
+// EU and EEA members, plus the UK and Switzerland
+const EU_COUNTRIES = new Set([
+  'AT', 'BE', 'BG', 'HR', 'CY', 'CZ', 'DK', 'EE', 'FI', 'FR', 'DE', 'GR', 'HU', 'IE', 'IT', 'LV', 'LT', 'LU',
+  'MT', 'NL', 'PL', 'PT', 'RO', 'SK', 'SI', 'ES', 'SE', 'IS', 'LI', 'NO', 'GB', 'CH',
+]);
+const APAC_COUNTRIES = new Set([
+  'AU', 'NZ', 'JP', 'KR', 'CN', 'HK', 'MO', 'TW', 'SG', 'MY', 'TH', 'VN', 'PH', 'ID', 'IN', 'PK', 'BD', 'LK',
+  'NP', 'MM', 'KH', 'LA', 'MN', 'BN', 'FJ', 'PG',
+]);
+
+// Data residency: each user belongs to a data region (EU, US or APAC), and
+// their uploads are stored in that region's bucket. Chats can be assigned a
+// region too, which keeps their message archive in the region's database.
+// Users get the region of their phone number's country unless an admin
+// assigns one; moving a user or chat to another region copies their data
+// over in the background and removes it from where it was.
+//
+// DATA_REGIONS lists the regions in use; each region's bucket is configured
+// by suffixing the storage variables, e.g. AWS_S3_BUCKET_EU, and its archive
+// database with MONGODB_URI_EU.
+export class DataResidencyService {
+  private regionMigrationRepository = new RegionMigrationRepository();
+  private userRepository = new UserRepository();
+  private chatRepository = new ChatRepository();
+  private mediaRepository = new MediaRepository();
+  private running = false;
+
+  // Regions in use; none when data residency is off
+  getRegions(): DataRegion[] {
+    return environmentConfig.get().DATA_REGIONS
+      .split(',')
+      .map(region => region.trim().toLowerCase())
+      .filter((region): region is DataRegion => (DATA_REGIONS as readonly string[]).includes(region));
+  }
+
+  isEnabled(): boolean {
+    return this.getRegions().length > 0;
+  }
+
+  getDefaultRegion(): DataRegion {
+    const regions = this.getRegions();
+    const configured = environmentConfig.get().DEFAULT_DATA_REGION;
+    return regions.includes(configured) || regions.length === 0 ? configured : regions[0];
+  }
+
+  // Region for a country, falling back to the default region when the
+  // country's own region isn't in use
+  regionForCountry(countryCode?: string): DataRegion {
+    const code = countryCode?.toUpperCase();
+    const region: DataRegion = code && EU_COUNTRIES.has(code) ? 'eu'
+      : code && APAC_COUNTRIES.has(code) ? 'apac'
+      : 'us';
+    return this.getRegions().includes(region) ? region : this.getDefaultRegion();
+  }
+
+  // Region a user's data belongs in; null when data residency is off
+  resolveRegion(user: Pick<IUser, 'dataRegion' | 'countryCode'>): DataRegion | null {
+    if (!this.isEnabled()) return null;
+    return user.dataRegion || this.regionForCountry(user.countryCode);
+  }
+
+  async getUserRegion(userId: string | Types.ObjectId): Promise<DataRegion | null> {
+    if (!this.isEnabled()) return null;
+
+    const user = await this.userRepository.findById(userId);
+    return user ? this.resolveRegion(user) : this.getDefaultRegion();
+  }
+
+  // Queue moving a user's files or a chat's message archive to a region
+  async requestMigration(
+    subject: RegionMigrationSubject,
+    subjectId: string,
+    toRegion: DataRegion,
+    requestedBy?: string
+  ): Promise<IRegionMigration> {
+    if (!this.getRegions().includes(toRegion)) {
+      throw new DataResidencyError(`Region ${toRegion} is not in use`, 400);
+    }
+
+    let fromRegion: DataRegion | undefined;
+    if (subject === 'user') {
+      const user = await this.userRepository.findById(subjectId);
+      if (!user) {
+        throw new DataResidencyError('User not found', 404);
+      }
+      fromRegion = this.resolveRegion(user) || undefined;
+    } else {
+      const chat = await this.chatRepository.findById(subjectId);
+      if (!chat) {
+        throw new DataResidencyError('Chat not found', 404);
+      }
+      fromRegion = chat.dataRegion;
+    }
+
+    if (await this.regionMigrationRepository.findActive(subjectId)) {
+      throw new DataResidencyError('A region migration is already in progress', 409);
+    }
+
+    const migration = await this.regionMigrationRepository.create({
+      subject,
+      subjectId: new Types.ObjectId(subjectId),
+      fromRegion,
+      toRegion,
+      requestedBy: requestedBy ? new Types.ObjectId(requestedBy) : undefined,
+    });
+
+    metricsCollector.incrementCounter('region_migrations_requested', 1, { subject, region: toRegion });
+    this.processQueue();
+    return migration;
+  }
+
+  async getMigration(migrationId: string): Promise<IRegionMigration | null> {
+    return await this.regionMigrationRepository.findById(migrationId);
+  }
+
+  async getMigrations(subjectId: string): Promise<IRegionMigration[]> {
+    return await this.regionMigrationRepository.getForSubject(subjectId);
+  }
+
+  // Requeue migrations interrupted by a restart and work the queue
+  async resumePending(): Promise<void> {
+    const stalled = await this.regionMigrationRepository.requeueStalled(new Date(Date.now() - STALL_TIMEOUT));
+    if (stalled > 0) {
+      logger.warn('Requeued stalled region migrations', { count: stalled });
+    }
+    this.processQueue();
+  }
+
+  // One migration at a time per instance; they are bandwidth bound
+  private processQueue(): void {
+    if (this.running) return;
+    this.running = true;
+
+    (async () => {
+      let migration: IRegionMigration | null;
+      while ((migration = await this.regionMigrationRepository.claimNext())) {
+        await this.run(migration);
+      }
+    })()
+      .catch(error => logger.error('Region migration queue error', error))
+      .finally(() => {
+        this.running = false;
+      });
+  }
+
+  private async run(migration: IRegionMigration): Promise<void> {
+    const migrationId = migration._id.toString();
+
+    try {
+      if (migration.subject === 'user') {
+        await this.migrateUser(migration);
+      } else {
+        await this.migrateChat(migration);
+      }
+
+      await this.regionMigrationRepository.update(migration._id, {
+        status: 'completed',
+        completedAt: new Date(),
+      });
+      metricsCollector.incrementCounter('region_migrations_completed', 1, { subject: migration.subject });
+      logger.info('Region migration completed', {
+        migrationId,
+        subject: migration.subject,
+        subjectId: migration.subjectId.toString(),
+        toRegion: migration.toRegion,
+      });
+    } catch (error) {
+      logger.error('Region migration failed', error, { migrationId });
+      await this.regionMigrationRepository.update(migration._id, {
+        status: 'failed',
+        error: error instanceof DataResidencyError ? error.message : 'Migration failed',
+        completedAt: new Date(),
+      });
+      metricsCollector.incrementCounter('region_migrations_failed', 1, { subject: migration.subject });
+    }
+  }
+
+  // Copy each of the user's stored files to the new region and repoint
+  // their records. Files shared with other users' uploads stay where they
+  // are for those users. Safe to rerun: moved records are skipped.
+  private async migrateUser(migration: IRegionMigration): Promise<void> {
+    const region = migration.toRegion;
+    const user = await this.userRepository.findById(migration.subjectId);
+    if (!user) {
+      throw new DataResidencyError('User no longer exists', 404);
+    }
+
+    // New uploads go to the new region from here on
+    await this.userRepository.update(user._id, { dataRegion: region });
+
+    const filenames = await this.mediaRepository.findFilenamesOutsideRegion(user._id, region);
+    const progress = { total: migration.progress.moved + filenames.length, moved: migration.progress.moved };
+    await this.regionMigrationRepository.update(migration._id, { progress });
+
+    for (const filename of filenames) {
+      const records = await this.mediaRepository.findByUploaderAndFilename(user._id, filename);
+      if (records.length > 0) {
+        await this.moveFile(records, region);
+      }
+
+      progress.moved++;
+      await this.regionMigrationRepository.update(migration._id, { progress });
+    }
+  }
+
+  private async moveFile(records: IMedia[], region: DataRegion): Promise<void> {
+    const [media] = records;
+    const filename = await storageService.copyFile(media.filename, region);
+
+    // Thumbnails are best effort; a missing one is regenerated on demand
+    const thumbnailKeys = new Map<string, string>();
+    for (const key of new Set([media.thumbnailKey, ...(media.thumbnails || []).map(variant => variant.key)])) {
+      if (!key) continue;
+      try {
+        thumbnailKeys.set(key, await storageService.copyFile(key, region));
+      } catch (error) {
+        logger.warn('Thumbnail not moved', { key, error: error instanceof Error ? error.message : String(error) });
+      }
+    }
+    const thumbnailKey = media.thumbnailKey && thumbnailKeys.get(media.thumbnailKey);
+
+    await this.mediaRepository.updateMany(records.map(record => record._id), {
+      filename,
+      storageRegion: region,
+      url: await storageService.getFileUrl(filename),
+      ...(thumbnailKey && {
+        thumbnailKey,
+        thumbnailUrl: cdnService.getUrl(thumbnailKey) || await storageService.getFileUrl(thumbnailKey),
+      }),
+      ...(media.thumbnails && {
+        thumbnails: media.thumbnails
+          .filter(variant => thumbnailKeys.has(variant.key))
+          .map(variant => ({ ...variant, key: thumbnailKeys.get(variant.key)! })),
+      }),
+    });
+
+    // References move to the copy; the old file is removed once no other
+    // record uses it
+    const moved = await this.mediaRepository.findById(media._id);
+    if (moved) {
+      await storageReferenceService.trackMoved(moved, records.length);
+    }
+    for (const record of records) {
+      await storageReferenceService.release(record);
+    }
+  }
+
+  // Move a chat's message buckets to the region's archive database. Until
+  // it finishes, history reads only see the buckets not yet moved.
+  private async migrateChat(migration: IRegionMigration): Promise<void> {
+    const chatId = migration.subjectId;
+    const source = getArchiveModel(migration.fromRegion);
+    const target = getArchiveModel(migration.toRegion);
+
+    if (source !== target) {
+      const progress = {
+        total: migration.progress.moved + await source.countDocuments({ chatId }).exec(),
+        moved: migration.progress.moved,
+      };
+      await this.regionMigrationRepository.update(migration._id, { progress });
+
+      while (true) {
+        const buckets = await source.find({ chatId }).limit(BUCKET_BATCH_SIZE).lean().exec();
+        if (buckets.length === 0) break;
+
+        // Written before deleting, so an interrupted run leaves duplicates
+        // that the next run overwrites rather than losing buckets
+        await target.bulkWrite(buckets.map(bucket => ({
+          replaceOne: { filter: { _id: bucket._id }, replacement: bucket, upsert: true },
+        })));
+        await source.deleteMany({ _id: { $in: buckets.map(bucket => bucket._id) } }).exec();
+
+        progress.moved += buckets.length;
+        await this.regionMigrationRepository.update(migration._id, { progress });
+      }
+    }
+
+    await this.chatRepository.update(chatId, { dataRegion: migration.toRegion });
+  }
+}
+
+export function serializeRegionMigration(migration: IRegionMigration) {
+  return {
+    id: migration._id.toString(),
+    subject: migration.subject,
+    subjectId: migration.subjectId.toString(),
+    fromRegion: migration.fromRegion ?? null,
+    toRegion: migration.toRegion,
+    status: migration.status,
+    progress: {
+      total: migration.progress.total,
+      moved: migration.progress.moved,
+    },
+    requestedBy: migration.requestedBy?.toString(),
+    error: migration.error,
+    startedAt: migration.startedAt,
+    completedAt: migration.completedAt,
+    createdAt: migration.createdAt,
+  };
+}
+
+export class DataResidencyError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'DataResidencyError';
+  }
+}
+
+export const dataResidencyService = new DataResidencyService();
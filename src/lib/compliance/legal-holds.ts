@@ -0,0 +1,228 @@
+import { Types } from 'mongoose';
+import { LegalHoldRepository } from '../database/repositories/legal-hold';
+import { AdminRepository } from '../database/repositories/admin';
+import { UserRepository } from '../database/repositories/user';
+import { ChatRepository } from '../database/repositories/chat';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { ILegalHold, LegalHoldStatus, LegalHoldSubject } from '../database/models/legal-hold';
+import { IHeldMessage } from '../database/models/held-message';
+import { IMessage } from '../database/models/message';
+import { IMedia } from '../database/models/media';
+import { logger } from '../monitoring/logging';
+
+// Legal holds keep a user's or chat's data from being deleted while it may
+// be needed as evidence. A hold on a user covers them and every chat they
+// are in; a hold on a chat covers that chat. While a hold is active:
+//   - the retention purge skips held users and covered chats
+//   - files uploaded by held users, or shared in covered chats, can't be
+//     deleted
+//   - messages deleted for everyone are copied to the hold vault first, so
+//     the chat shows a tombstone but what was said is kept
+// Only active super admins can place or release holds or read the vault,
+// and each of those is audit-logged.
+export class LegalHoldService {
+  private legalHoldRepository = new LegalHoldRepository();
+  private adminRepository = new AdminRepository();
+  private userRepository = new UserRepository();
+  private chatRepository = new ChatRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+
+  async place(
+    adminId: string,
+    input: { subject: LegalHoldSubject; subjectId: string; reason: string; caseReference?: string }
+  ): Promise<ILegalHold> {
+    await this.requireSuperAdmin(adminId);
+
+    const exists = input.subject === 'user'
+      ? await this.userRepository.findById(input.subjectId)
+      : await this.chatRepository.findById(input.subjectId);
+    if (!exists) {
+      throw new LegalHoldError(input.subject === 'user' ? 'User not found' : 'Chat not found', 404);
+    }
+
+    const hold = await this.legalHoldRepository.create({
+      subject: input.subject,
+      subjectId: new Types.ObjectId(input.subjectId),
+      reason: input.reason,
+      caseReference: input.caseReference,
+      placedBy: new Types.ObjectId(adminId),
+    });
+
+    await this.audit(adminId, 'legal_hold.place', hold._id, {
+      subject: hold.subject,
+      subjectId: hold.subjectId.toString(),
+      reason: hold.reason,
+      caseReference: hold.caseReference,
+    });
+    logger.info('Legal hold placed', { holdId: hold._id.toString(), subject: hold.subject, subjectId: input.subjectId, adminId });
+    return hold;
+  }
+
+  async release(holdId: string, adminId: string, reason: string): Promise<ILegalHold> {
+    await this.requireSuperAdmin(adminId);
+
+    const hold = await this.legalHoldRepository.release(holdId, adminId, reason);
+    if (!hold) {
+      throw new LegalHoldError('Hold not found or already released', 404);
+    }
+
+    await this.audit(adminId, 'legal_hold.release', hold._id, {
+      subject: hold.subject,
+      subjectId: hold.subjectId.toString(),
+      reason,
+    });
+    logger.info('Legal hold released', { holdId, adminId });
+    return hold;
+  }
+
+  async list(
+    adminId: string,
+    filter: { status?: LegalHoldStatus; subject?: LegalHoldSubject; subjectId?: string },
+    limit?: number,
+    offset?: number
+  ): Promise<ILegalHold[]> {
+    await this.requireSuperAdmin(adminId);
+    return await this.legalHoldRepository.list(filter, limit, offset);
+  }
+
+  async get(holdId: string, adminId: string): Promise<ILegalHold> {
+    await this.requireSuperAdmin(adminId);
+
+    const hold = await this.legalHoldRepository.findById(holdId);
+    if (!hold) {
+      throw new LegalHoldError('Hold not found', 404);
+    }
+    return hold;
+  }
+
+  // Messages preserved under a hold. Reading them is audit-logged.
+  async listHeldMessages(holdId: string, adminId: string, limit?: number, offset?: number): Promise<IHeldMessage[]> {
+    const hold = await this.get(holdId, adminId);
+    const messages = await this.legalHoldRepository.listHeldMessages(hold._id, limit, offset);
+
+    await this.audit(adminId, 'legal_hold.vault.read', hold._id, { count: messages.length, offset: offset || 0 });
+    return messages;
+  }
+
+  async isUserHeld(userId: string | Types.ObjectId): Promise<boolean> {
+    return (await this.legalHoldRepository.findActive('user', [userId])).length > 0;
+  }
+
+  // Which of the given chats are covered by a hold, on the chat itself or
+  // on one of its participants
+  async heldChatIds(chatIds: Types.ObjectId[]): Promise<Types.ObjectId[]> {
+    if (chatIds.length === 0) return [];
+
+    const [chatHolds, heldUserIds] = await Promise.all([
+      this.legalHoldRepository.findActive('chat', chatIds),
+      this.legalHoldRepository.activeSubjectIds('user'),
+    ]);
+    const viaParticipants = await this.chatRepository.filterWithParticipants(chatIds, heldUserIds);
+
+    const held = new Map<string, Types.ObjectId>();
+    for (const hold of chatHolds) held.set(hold.subjectId.toString(), hold.subjectId);
+    for (const chatId of viaParticipants) held.set(chatId.toString(), chatId);
+    return Array.from(held.values());
+  }
+
+  // Whether a file is covered, through its uploader or its chat
+  async isMediaHeld(media: IMedia): Promise<boolean> {
+    if (await this.isUserHeld(media.uploadedBy)) return true;
+    return !!media.chatId && (await this.heldChatIds([media.chatId])).length > 0;
+  }
+
+  // Copy a message to the vault before it is deleted for everyone, if a
+  // hold covers it. Call before the delete: if this throws, the delete
+  // must not go ahead.
+  async preserveMessage(message: IMessage, deletedBy: { kind: 'user' | 'admin'; id: string }): Promise<boolean> {
+    const chat = await this.chatRepository.findById(message.chatId);
+    const senderId = (message.senderId as any)?._id ?? message.senderId;
+    const participantIds = (chat?.participants || []).map((participant: any) => participant._id ?? participant);
+
+    const [chatHolds, userHolds] = await Promise.all([
+      this.legalHoldRepository.findActive('chat', [message.chatId]),
+      this.legalHoldRepository.findActive('user', [senderId, ...participantIds]),
+    ]);
+    const holds = [...chatHolds, ...userHolds];
+    if (holds.length === 0) return false;
+
+    await this.legalHoldRepository.preserveMessage({
+      messageId: message._id,
+      holdIds: holds.map(hold => hold._id),
+      chatId: message.chatId,
+      senderId,
+      type: message.type,
+      content: message.content,
+      media: (message.media as any)?._id ?? message.media,
+      replyTo: (message.replyTo as any)?._id ?? message.replyTo,
+      sentAt: message.createdAt,
+      deletedBy: { kind: deletedBy.kind, id: new Types.ObjectId(deletedBy.id) },
+      deletedAt: new Date(),
+    });
+
+    logger.info('Message preserved under legal hold', {
+      messageId: message._id.toString(),
+      holdIds: holds.map(hold => hold._id.toString()),
+    });
+    return true;
+  }
+
+  // Holds are placed by super admins only, whatever permissions an admin
+  // has been granted individually
+  private async requireSuperAdmin(adminId: string): Promise<void> {
+    const admin = Types.ObjectId.isValid(adminId) ? await this.adminRepository.findById(adminId) : null;
+    if (!admin || !admin.isActive || admin.role !== 'super_admin') {
+      throw new LegalHoldError('Only super admins can manage legal holds', 403);
+    }
+  }
+
+  private async audit(adminId: string, action: string, holdId: Types.ObjectId, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action,
+      targetType: 'legal_hold',
+      targetId: holdId,
+      details,
+    });
+  }
+}
+
+export function serializeLegalHold(hold: ILegalHold) {
+  return {
+    id: hold._id.toString(),
+    subject: hold.subject,
+    subjectId: hold.subjectId.toString(),
+    status: hold.status,
+    reason: hold.reason,
+    caseReference: hold.caseReference,
+    placedBy: hold.placedBy.toString(),
+    releasedBy: hold.releasedBy?.toString(),
+    releaseReason: hold.releaseReason,
+    releasedAt: hold.releasedAt,
+    createdAt: hold.createdAt,
+  };
+}
+
+export function serializeHeldMessage(message: IHeldMessage) {
+  return {
+    messageId: message.messageId.toString(),
+    chatId: message.chatId.toString(),
+    senderId: message.senderId.toString(),
+    type: message.type,
+    content: message.content,
+    media: message.media?.toString(),
+    replyTo: message.replyTo?.toString(),
+    sentAt: message.sentAt,
+    deletedBy: { kind: message.deletedBy.kind, id: message.deletedBy.id.toString() },
+    deletedAt: message.deletedAt,
+  };
+}
+
+export class LegalHoldError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'LegalHoldError';
+  }
+}
+
+export const legalHoldService = new LegalHoldService();
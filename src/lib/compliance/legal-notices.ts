@@ -0,0 +1,378 @@
+import { Types } from 'mongoose';
+import { LegalNoticeRepository } from '../database/repositories/legal-notice';
+import { LegalAcceptanceRepository } from '../database/repositories/legal-acceptance';
+import { UserRepository } from '../database/repositories/user';
+import { ILegalNotice } from '../database/models/legal-notice';
+import { IUser } from '../database/models/user';
+import {
+  CreateLegalNoticeInput,
+  UpdateLegalNoticeInput,
+  PublishLegalNoticeVersionInput,
+} from '../database/schemas/legal-notice';
+import { logger } from '../monitoring/logging';
+
+const POLL_INTERVAL = 30 * 1000;
+const CACHE_TTL = 30 * 1000;
+const BATCH_SIZE = 20;
+
+type NoticeVersion = ILegalNotice['versions'][number];
+
+// The version users must have accepted now: the newest one already in effect
+export function getVersionInForce(notice: Pick<ILegalNotice, 'versions'>, now: Date = new Date()): NoticeVersion | null {
+  return notice.versions
+    .filter(version => version.effectiveAt <= now)
+    .reduce<NoticeVersion | null>((newest, version) => (!newest || version.version > newest.version ? version : newest), null);
+}
+
+// A published version that isn't in effect yet
+export function getUpcomingVersion(notice: Pick<ILegalNotice, 'versions'>, now: Date = new Date()): NoticeVersion | null {
+  return notice.versions
+    .filter(version => version.effectiveAt > now)
+    .reduce<NoticeVersion | null>((newest, version) => (!newest || version.version > newest.version ? version : newest), null);
+}
+
+export interface LegalNoticeStatus {
+  key: string;
+  title: string;
+  mandatory: boolean;
+  version: number | null; // in force
+  effectiveAt: Date | null;
+  summary?: string;
+  acceptedVersion: number | null;
+  accepted: boolean; // accepted the version in force (or a newer one)
+  pending: boolean; // mandatory and not accepted; the API is blocked until it is
+  upcoming: { version: number; effectiveAt: Date; summary?: string; accepted: boolean } | null;
+}
+
+export function getLegalNoticeStatus(
+  user: Pick<IUser, 'legalAcceptances'>,
+  notices: ILegalNotice[],
+  now: Date = new Date()
+): LegalNoticeStatus[] {
+  return notices
+    .map(notice => {
+      const inForce = getVersionInForce(notice, now);
+      const upcoming = getUpcomingVersion(notice, now);
+      const acceptedVersion = user.legalAcceptances?.find(acceptance => acceptance.key === notice.key)?.version ?? null;
+      const accepted = !!inForce && acceptedVersion !== null && acceptedVersion >= inForce.version;
+
+      return {
+        key: notice.key,
+        title: notice.title,
+        mandatory: notice.mandatory,
+        version: inForce?.version ?? null,
+        effectiveAt: inForce?.effectiveAt ?? null,
+        summary: inForce?.summary,
+        acceptedVersion,
+        accepted,
+        pending: notice.mandatory && !!inForce && !accepted,
+        upcoming: upcoming && {
+          version: upcoming.version,
+          effectiveAt: upcoming.effectiveAt,
+          summary: upcoming.summary,
+          accepted: acceptedVersion !== null && acceptedVersion >= upcoming.version,
+        },
+      };
+    })
+    .filter(status => status.version !== null || status.upcoming !== null);
+}
+
+// Versioned legal documents and users' acceptance of them. Publishing a new
+// version of a mandatory notice prompts everyone connected, and once the
+// version is in effect the API stays blocked for a user until they accept
+// it. Every acceptance is kept as an audit record that admins can export.
+export class LegalNoticeService {
+  private legalNoticeRepository = new LegalNoticeRepository();
+  private legalAcceptanceRepository = new LegalAcceptanceRepository();
+  private userRepository = new UserRepository();
+  private activeNotices: { notices: ILegalNotice[]; loadedAt: number } | null = null;
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Start announcing versions as they come into force. Safe to run on
+  // several instances: announcements are claimed atomically.
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Notices that aren't archived, cached briefly since every authenticated
+  // request checks them
+  async getActiveNotices(): Promise<ILegalNotice[]> {
+    if (this.activeNotices && Date.now() - this.activeNotices.loadedAt < CACHE_TTL) {
+      return this.activeNotices.notices;
+    }
+
+    const notices = await this.legalNoticeRepository.findAll();
+    this.activeNotices = { notices, loadedAt: Date.now() };
+    return notices;
+  }
+
+  async getStatus(user: Pick<IUser, 'legalAcceptances'>): Promise<LegalNoticeStatus[]> {
+    return getLegalNoticeStatus(user, await this.getActiveNotices());
+  }
+
+  async getPending(user: Pick<IUser, 'legalAcceptances'>): Promise<LegalNoticeStatus[]> {
+    return (await this.getStatus(user)).filter(status => status.pending);
+  }
+
+  async getByKey(key: string): Promise<ILegalNotice> {
+    const notice = await this.legalNoticeRepository.findByKey(key);
+    if (!notice || notice.archivedAt) {
+      throw new LegalNoticeError('Legal notice not found', 404);
+    }
+    return notice;
+  }
+
+  async getById(noticeId: string): Promise<ILegalNotice> {
+    const notice = Types.ObjectId.isValid(noticeId)
+      ? await this.legalNoticeRepository.findById(noticeId)
+      : null;
+    if (!notice) {
+      throw new LegalNoticeError('Legal notice not found', 404);
+    }
+    return notice;
+  }
+
+  async list(includeArchived: boolean = false): Promise<ILegalNotice[]> {
+    return await this.legalNoticeRepository.findAll(includeArchived);
+  }
+
+  async create(input: CreateLegalNoticeInput, adminId?: string): Promise<ILegalNotice> {
+    if (await this.legalNoticeRepository.findByKey(input.key)) {
+      throw new LegalNoticeError(`A legal notice with key ${input.key} already exists`, 409);
+    }
+
+    const notice = await this.legalNoticeRepository.create({
+      key: input.key,
+      title: input.title,
+      mandatory: input.mandatory,
+      versions: [],
+      ...(adminId && { createdBy: new Types.ObjectId(adminId) }),
+    });
+
+    logger.info('Legal notice created', { noticeId: notice._id.toString(), key: notice.key, adminId });
+
+    if (input.initialVersion) {
+      return await this.publishVersion(notice._id.toString(), input.initialVersion, adminId);
+    }
+    return notice;
+  }
+
+  async update(noticeId: string, input: UpdateLegalNoticeInput): Promise<ILegalNotice> {
+    const notice = await this.getById(noticeId);
+    const updated = await this.legalNoticeRepository.update(notice._id, {
+      ...(input.title !== undefined && { title: input.title }),
+      ...(input.mandatory !== undefined && { mandatory: input.mandatory }),
+    });
+
+    this.activeNotices = null;
+    return updated!;
+  }
+
+  async archive(noticeId: string): Promise<void> {
+    const notice = await this.getById(noticeId);
+    const archived = await this.legalNoticeRepository.archive(notice._id);
+    if (!archived) {
+      throw new LegalNoticeError('Legal notice is already archived', 409);
+    }
+
+    this.activeNotices = null;
+    logger.info('Legal notice archived', { noticeId, key: notice.key });
+  }
+
+  // Publish the next version. Published versions are immutable; fix
+  // mistakes by publishing another one.
+  async publishVersion(
+    noticeId: string,
+    input: PublishLegalNoticeVersionInput,
+    adminId?: string
+  ): Promise<ILegalNotice> {
+    const notice = await this.getById(noticeId);
+    if (notice.archivedAt) {
+      throw new LegalNoticeError('Legal notice is archived', 409);
+    }
+
+    const now = new Date();
+    const effectiveAt = input.effectiveAt ? new Date(input.effectiveAt) : now;
+    if (effectiveAt.getTime() < now.getTime() - 60 * 1000) {
+      throw new LegalNoticeError('effectiveAt can not be in the past', 400);
+    }
+
+    const version = notice.versions.reduce((max, existing) => Math.max(max, existing.version), 0) + 1;
+    const updated = await this.legalNoticeRepository.addVersion(notice._id, {
+      version,
+      content: input.content,
+      summary: input.summary,
+      effectiveAt: effectiveAt < now ? now : effectiveAt,
+      publishedAt: now,
+      ...(adminId && { publishedBy: new Types.ObjectId(adminId) }),
+    });
+    if (!updated) {
+      throw new LegalNoticeError('Another version was published at the same time; please retry', 409);
+    }
+
+    this.activeNotices = null;
+    logger.info('Legal notice version published', {
+      noticeId,
+      key: notice.key,
+      version,
+      effectiveAt,
+      adminId,
+    });
+
+    // Versions in force now are announced by the worker; let clients
+    // prompt early for future ones
+    if (effectiveAt > now) {
+      await this.announce(updated, version, false);
+    }
+
+    return updated;
+  }
+
+  // Accept a version in force, or a newer upcoming one
+  async accept(
+    userId: string,
+    key: string,
+    version: number,
+    meta: { ip?: string; userAgent?: string; deviceId?: string } = {}
+  ): Promise<LegalNoticeStatus[]> {
+    const notice = await this.getByKey(key);
+    const accepted = notice.versions.find(existing => existing.version === version);
+    const inForce = getVersionInForce(notice);
+    if (!accepted) {
+      throw new LegalNoticeError('Unknown version', 404);
+    }
+    if (inForce && version < inForce.version) {
+      throw new LegalNoticeError(`Version ${version} is outdated; please review version ${inForce.version}`, 409);
+    }
+
+    const acceptedAt = new Date();
+    await this.legalAcceptanceRepository.record({
+      userId: new Types.ObjectId(userId),
+      noticeId: notice._id,
+      noticeKey: notice.key,
+      version,
+      acceptedAt,
+      ip: meta.ip,
+      userAgent: meta.userAgent,
+      deviceId: meta.deviceId,
+    });
+    const user = await this.userRepository.recordLegalAcceptance(userId, { key: notice.key, version, acceptedAt });
+    if (!user) {
+      throw new LegalNoticeError('User not found', 404);
+    }
+
+    logger.info('Legal notice accepted', { userId, key: notice.key, version });
+
+    return await this.getStatus(user);
+  }
+
+  // Acceptance counts per version, and how many users are on the version in force
+  async getAcceptanceStats(notice: ILegalNotice) {
+    const inForce = getVersionInForce(notice);
+    const [byVersion, currentUsers, totalUsers] = await Promise.all([
+      this.legalAcceptanceRepository.countByVersion(notice._id),
+      inForce ? this.userRepository.countLegalAcceptances(notice.key, inForce.version) : Promise.resolve(0),
+      this.userRepository.countHumanUsers(),
+    ]);
+
+    return {
+      versionInForce: inForce?.version ?? null,
+      totalUsers,
+      acceptedInForce: currentUsers,
+      coverage: totalUsers > 0 ? Math.round((currentUsers / totalUsers) * 1000) / 10 : 0, // percent
+      byVersion,
+    };
+  }
+
+  exportAcceptances(noticeId: Types.ObjectId, filter: { version?: number; from?: Date; to?: Date } = {}) {
+    return this.legalAcceptanceRepository.cursorForExport({ noticeId, ...filter });
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      for (let announced = 0; announced < BATCH_SIZE; announced++) {
+        const due = await this.legalNoticeRepository.claimDueAnnouncement();
+        if (!due) break;
+
+        this.activeNotices = null;
+        await this.announce(due.notice, due.version, true);
+      }
+    } catch (error) {
+      logger.error('Legal notice worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  private async announce(notice: ILegalNotice, versionNumber: number, inForce: boolean): Promise<void> {
+    const version = notice.versions.find(existing => existing.version === versionNumber);
+    if (!version) return;
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToAll('legal:updated', {
+      key: notice.key,
+      title: notice.title,
+      version: version.version,
+      summary: version.summary,
+      effectiveAt: version.effectiveAt.toISOString(),
+      inForce,
+      mandatory: notice.mandatory,
+    });
+
+    logger.info('Legal notice version announced', { key: notice.key, version: versionNumber, inForce });
+  }
+}
+
+// Admin view of a notice; pass includeContent for the full text of every version
+export function serializeLegalNotice(notice: ILegalNotice, includeContent: boolean = false) {
+  const inForce = getVersionInForce(notice);
+  const upcoming = getUpcomingVersion(notice);
+
+  return {
+    id: notice._id.toString(),
+    key: notice.key,
+    title: notice.title,
+    mandatory: notice.mandatory,
+    versionInForce: inForce?.version ?? null,
+    upcomingVersion: upcoming?.version ?? null,
+    versions: notice.versions
+      .map(version => ({
+        version: version.version,
+        summary: version.summary,
+        effectiveAt: version.effectiveAt,
+        publishedAt: version.publishedAt,
+        publishedBy: version.publishedBy?.toString(),
+        announcedAt: version.announcedAt,
+        ...(includeContent && { content: version.content }),
+      }))
+      .sort((a, b) => b.version - a.version),
+    archived: !!notice.archivedAt,
+    archivedAt: notice.archivedAt,
+    createdAt: notice.createdAt,
+    updatedAt: notice.updatedAt,
+  };
+}
+
+export class LegalNoticeError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'LegalNoticeError';
+  }
+}
+
+export const legalNoticeService = new LegalNoticeService();
@@ -0,0 +1,275 @@
+import { Types } from 'mongoose';
+import { UserRepository } from '../database/repositories/user';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { IUser } from '../database/models/user';
+import { distributedLock, LockTimeoutError } from '../database/locks';
+import { storageService } from '../media/storage';
+import { legalHoldService } from './legal-holds';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 60 * 60 * 1000;
+const PURGE_EVERY = 24 * 60 * 60 * 1000; // per user
+const USERS_PER_TICK = 50;
+const BATCH_SIZE = 500;
+const MAX_PER_RUN = 5000; // messages per user per run; the rest go next run
+const LOCK_TTL = 10 * 60 * 1000;
+const EXPORT_KEEP = 30 * 24 * 60 * 60 * 1000; // how long export files stay downloadable
+const EXPORT_URL_EXPIRY = 60 * 60; // seconds
+const DAY = 24 * 60 * 60 * 1000;
+
+export interface RetentionPolicy {
+  days: number | null; // in effect, after any admin override; null keeps messages
+  source: 'user' | 'admin';
+  exportBeforeDelete: boolean;
+}
+
+export interface PurgeResult {
+  removed: number; // sent by the user, removed for everyone
+  hidden: number; // sent by others, deleted for the user
+  exported: boolean;
+}
+
+// Per-account message retention. Users can have their messages deleted once
+// they are older than a number of days: messages they sent are removed for
+// everyone, and messages from others are deleted for them only, leaving the
+// other participants' history alone. Admins can override the period per
+// account, or exempt an account entirely. Legal holds win over both.
+//
+// A background purge visits each account with a period about once a day.
+// With exportBeforeDelete, what a run deletes is first saved to a JSON file
+// the user can download for EXPORT_KEEP. Attached files are not deleted,
+// only the messages that point to them.
+export class MessageRetentionService {
+  private userRepository = new UserRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  getPolicy(user: IUser): RetentionPolicy {
+    const retention = user.messageRetention;
+    const override = retention?.adminOverride;
+    return {
+      days: override ? override.days : retention?.days ?? null,
+      source: override ? 'admin' : 'user',
+      exportBeforeDelete: !!retention?.exportBeforeDelete,
+    };
+  }
+
+  async setPolicy(userId: string, policy: { days?: number | null; exportBeforeDelete?: boolean }): Promise<IUser> {
+    const user = await this.userRepository.setMessageRetention(userId, policy);
+    if (!user) {
+      throw new MessageRetentionError('User not found', 404);
+    }
+
+    logger.info('Message retention updated', { userId, days: policy.days, exportBeforeDelete: policy.exportBeforeDelete });
+    return user;
+  }
+
+  // Override a user's own period; days null exempts them from purges
+  async setOverride(userId: string, adminId: string, days: number | null, reason?: string): Promise<IUser> {
+    const user = await this.userRepository.setRetentionOverride(userId, {
+      days,
+      adminId: new Types.ObjectId(adminId),
+      reason,
+      setAt: new Date(),
+    });
+    if (!user) {
+      throw new MessageRetentionError('User not found', 404);
+    }
+
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action: 'user.retention.override',
+      targetType: 'user',
+      targetId: user._id,
+      details: { days, reason },
+    });
+    return user;
+  }
+
+  async clearOverride(userId: string, adminId: string): Promise<IUser> {
+    const user = await this.userRepository.setRetentionOverride(userId, null);
+    if (!user) {
+      throw new MessageRetentionError('User not found', 404);
+    }
+
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action: 'user.retention.override.clear',
+      targetType: 'user',
+      targetId: user._id,
+    });
+    return user;
+  }
+
+  // Download links for the files saved before recent purges
+  async getExportUrls(user: IUser): Promise<{ url: string; count: number; createdAt: Date }[]> {
+    const cutoff = Date.now() - EXPORT_KEEP;
+    const exports = (user.messageRetention?.exports || []).filter(entry => entry.createdAt.getTime() >= cutoff);
+
+    return await Promise.all(exports.map(async entry => ({
+      url: await storageService.getFileUrl(entry.key, EXPORT_URL_EXPIRY),
+      count: entry.count,
+      createdAt: entry.createdAt,
+    })));
+  }
+
+  // Purge one user's expired messages now. Safe to run on several
+  // instances: each user is purged under a lock.
+  async purgeUser(userId: string): Promise<PurgeResult | null> {
+    try {
+      return await distributedLock.withLock(
+        `message-retention:${userId}`,
+        () => this.purge(userId),
+        { ttl: LOCK_TTL, wait: 0 }
+      );
+    } catch (error) {
+      // Another instance is on it
+      if (error instanceof LockTimeoutError) return null;
+      throw error;
+    }
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const users = await this.userRepository.findDueForRetentionPurge(new Date(Date.now() - PURGE_EVERY), USERS_PER_TICK);
+      for (const user of users) {
+        try {
+          await this.purgeUser(user._id.toString());
+        } catch (error) {
+          logger.error('Message retention purge failed', error, { userId: user._id.toString() });
+          metricsCollector.incrementCounter('message_retention_errors', 1);
+        }
+      }
+    } catch (error) {
+      logger.error('Message retention worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  private async purge(userId: string): Promise<PurgeResult> {
+    const result: PurgeResult = { removed: 0, hidden: 0, exported: false };
+
+    const user = await this.userRepository.findById(userId);
+    const policy = user && this.getPolicy(user);
+    if (!user || !policy?.days) return result;
+
+    // Nothing of a user under legal hold is purged, nor anything in chats a
+    // hold covers; they go on the first run after the hold is released
+    if (await legalHoldService.isUserHeld(userId)) {
+      await this.userRepository.recordRetentionPurge(userId);
+      return result;
+    }
+
+    const before = new Date(Date.now() - policy.days * DAY);
+    const userChatIds = await this.chatRepository.getUserChatIds(userId);
+    const held = new Set((await legalHoldService.heldChatIds(userChatIds)).map(String));
+    const chatIds = userChatIds.filter(chatId => !held.has(chatId.toString()));
+    const expired = await this.messageRepository.findExpiredForUser(userId, chatIds, before, MAX_PER_RUN);
+
+    // Nothing is deleted unless the export was saved
+    if (policy.exportBeforeDelete && expired.length > 0) {
+      await this.writeExport(user, expired);
+      result.exported = true;
+    }
+
+    for (let i = 0; i < expired.length; i += BATCH_SIZE) {
+      const { removed, hidden } = await this.messageRepository.purgeForUser(userId, expired.slice(i, i + BATCH_SIZE));
+      result.removed += removed;
+      result.hidden += hidden;
+    }
+
+    // A run cut short by MAX_PER_RUN is picked up again by the next tick
+    if (expired.length < MAX_PER_RUN) {
+      await this.userRepository.recordRetentionPurge(userId);
+    }
+
+    if (expired.length > 0) {
+      metricsCollector.incrementCounter('message_retention_removed', result.removed);
+      metricsCollector.incrementCounter('message_retention_hidden', result.hidden);
+      logger.info('Message retention purge', { userId, days: policy.days, ...result });
+    }
+    return result;
+  }
+
+  // Save messages about to be purged for the user, and remove exports
+  // older than EXPORT_KEEP
+  private async writeExport(user: IUser, messages: Record<string, any>[]): Promise<void> {
+    const body = JSON.stringify({
+      userId: user._id.toString(),
+      exportedAt: new Date().toISOString(),
+      messages: messages.map(message => ({
+        id: message._id.toString(),
+        chatId: message.chatId.toString(),
+        senderId: message.senderId.toString(),
+        type: message.type,
+        content: message.content,
+        media: message.media?.toString(),
+        createdAt: message.createdAt,
+      })),
+    });
+
+    const upload = await storageService.uploadFile(
+      Buffer.from(body),
+      'retention-export.json',
+      user._id.toString(),
+      { contentType: 'application/json' },
+      'export'
+    );
+
+    const cutoff = new Date(Date.now() - EXPORT_KEEP);
+    await this.userRepository.addRetentionExport(
+      user._id,
+      { key: upload.key, count: messages.length, createdAt: new Date() },
+      cutoff
+    );
+
+    const stale = (user.messageRetention?.exports || []).filter(entry => entry.createdAt < cutoff);
+    for (const entry of stale) {
+      await storageService.deleteFile(entry.key);
+    }
+  }
+}
+
+export function serializeRetentionPolicy(user: IUser, policy: RetentionPolicy) {
+  const override = user.messageRetention?.adminOverride;
+  return {
+    ...policy,
+    ownDays: user.messageRetention?.days ?? null,
+    lastPurgedAt: user.messageRetention?.lastPurgedAt,
+    ...(override && { override: { days: override.days, reason: override.reason, setAt: override.setAt } }),
+  };
+}
+
+export class MessageRetentionError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'MessageRetentionError';
+  }
+}
+
+export const messageRetentionService = new MessageRetentionService();
@@ -0,0 +1,355 @@
+import { EventEmitter } from 'events';
+import { AdminConfig, IAdminConfig, IServerConfig, ISecurityConfig, ICallConfig, IClientConfig, IComplianceConfig, IModerationConfig, IFeatureConfig, IRealtimeConfig } from '../database/models/admin-config';
+import { AdminConfigOverlay, IAdminConfigOverlay } from '../database/models/admin-config-overlay';
+import { AdminConfigRevision } from '../database/models/admin-config-revision';
+import { logger } from '../monitoring/logging';
+import { versionFilter, VersionConflictError } from '../database/concurrency';
+import { encryptionService } from '../security/encryption';
+import { environmentConfig } from './environment';
+
+const CONFIG_KEY = 'global';
+const REFRESH_INTERVAL = 30 * 1000; // 30 seconds
+
+export type AdminConfigSection = 'server' | 'security' | 'calls' | 'clients' | 'compliance' | 'moderation' | 'features' | 'realtime';
+
+export const ADMIN_CONFIG_SECTIONS: AdminConfigSection[] = ['server', 'security', 'calls', 'clients', 'compliance', 'moderation', 'features', 'realtime'];
+
+// Overlay names, as in CONFIG_ENVIRONMENT; the base configuration is
+// called "base" where an environment is named
+export const ENVIRONMENT_NAME = /^[a-z][a-z0-9-]{0,31}$/;
+export const BASE_ENVIRONMENT = 'base';
+
+// Fields holding provider secrets. They are encrypted before they are
+// stored and decrypted as the snapshot loads, so readers of the snapshot
+// see plain values; API responses show them masked.
+export const SECRET_FIELDS: { [S in AdminConfigSection]?: string[] } = {
+  security: ['challengeSecretKey'],
+};
+
+export interface AdminConfigSnapshot {
+  server: Partial<IServerConfig>;
+  security: Partial<ISecurityConfig>;
+  calls: Partial<ICallConfig>;
+  clients: Partial<IClientConfig>;
+  compliance: Partial<IComplianceConfig>;
+  moderation: Partial<IModerationConfig>;
+  features: Partial<IFeatureConfig>;
+  realtime: Partial<IRealtimeConfig>;
+  version: number;
+  updatedAt?: Date;
+  environment?: string; // overlay applied, or loaded on its own
+  overlayVersion?: number;
+}
+
+// Who signed off a change that needed a second admin
+export interface AdminConfigApproval {
+  approvedBy: string;
+  changeId: string;
+}
+
+export interface AdminConfigWriteOptions {
+  environment?: string; // overlay to write; the base configuration when unset
+  adminId?: string;
+  expectedVersion?: number;
+  approval?: AdminConfigApproval;
+}
+
+export type AdminConfigSections = { [S in AdminConfigSection]?: Record<string, unknown> };
+
+// Persisted, hot-reloadable configuration managed from the admin dashboard:
+// a base configuration plus, with CONFIG_ENVIRONMENT set, that
+// environment's overlay on top. Emits 'change' with the new snapshot
+// whenever the stored version of either moves.
+class AdminConfigService extends EventEmitter {
+  private snapshot: AdminConfigSnapshot = { ...emptySections(), version: 0 };
+  private refreshTimer: NodeJS.Timeout | null = null;
+  private loadPromise: Promise<AdminConfigSnapshot> | null = null;
+
+  // Get current snapshot, loading it on first use
+  async get(): Promise<AdminConfigSnapshot> {
+    if (this.snapshot.version === 0 && !this.loadPromise) {
+      this.loadPromise = this.reload().finally(() => {
+        this.loadPromise = null;
+      });
+    }
+    if (this.loadPromise) {
+      await this.loadPromise;
+    }
+    this.startAutoRefresh();
+    return this.snapshot;
+  }
+
+  // Get the cached snapshot without touching the database
+  getCached(): AdminConfigSnapshot {
+    return this.snapshot;
+  }
+
+  // Overlay this instance applies, if any
+  getEnvironment(): string | undefined {
+    const environment = environmentConfig.getValue('CONFIG_ENVIRONMENT');
+    return environment === BASE_ENVIRONMENT ? undefined : environment;
+  }
+
+  // Reload from the database and notify listeners if anything changed
+  async reload(): Promise<AdminConfigSnapshot> {
+    try {
+      const next = await this.resolve(this.getEnvironment());
+
+      if (next.version !== this.snapshot.version || next.overlayVersion !== this.snapshot.overlayVersion) {
+        this.snapshot = next;
+        this.emit('change', next);
+        logger.info('Admin configuration loaded', {
+          version: next.version,
+          ...(next.environment && { environment: next.environment, overlayVersion: next.overlayVersion }),
+        });
+      }
+    } catch (error) {
+      logger.error('Failed to load admin configuration', error);
+    }
+
+    return this.snapshot;
+  }
+
+  // The base configuration, or one environment's overrides, as stored
+  async load(environment?: string): Promise<AdminConfigSnapshot> {
+    const { default: connectDB } = await import('../database/mongodb');
+    await connectDB();
+
+    const doc = environment
+      ? await AdminConfigOverlay.findOne({ environment }).lean<IAdminConfigOverlay>().exec()
+      : await AdminConfig.findOne({ key: CONFIG_KEY }).lean<IAdminConfig>().exec();
+    const sections: Record<string, any> = (environment ? (doc as IAdminConfigOverlay | null)?.sections : doc) || {};
+
+    const snapshot: AdminConfigSnapshot = {
+      ...emptySections(),
+      version: doc ? doc.version : 0,
+      updatedAt: doc?.updatedAt,
+      ...(environment && { environment }),
+    };
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      (snapshot as any)[section] = decryptSection(section, sections[section] || {});
+    }
+    return snapshot;
+  }
+
+  // The configuration an environment runs with: its overrides field by
+  // field on top of the base. version stays the base version.
+  async resolve(environment?: string): Promise<AdminConfigSnapshot> {
+    const base = await this.load();
+    if (!environment) return base;
+
+    const overlay = await this.load(environment);
+    const resolved: AdminConfigSnapshot = {
+      ...base,
+      environment,
+      overlayVersion: overlay.version,
+      updatedAt: overlay.updatedAt && (!base.updatedAt || overlay.updatedAt > base.updatedAt) ? overlay.updatedAt : base.updatedAt,
+    };
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      (resolved as any)[section] = { ...base[section], ...overlay[section] };
+    }
+    return resolved;
+  }
+
+  // Update one section and bump the version. With expectedVersion the update
+  // only applies if the stored configuration is still at that version.
+  async updateSection<S extends AdminConfigSection>(
+    section: S,
+    values: Partial<AdminConfigSnapshot[S]>,
+    options: AdminConfigWriteOptions = {}
+  ): Promise<AdminConfigSnapshot> {
+    return await this.write({ [section]: values }, options, { section });
+  }
+
+  // Update several sections at once, as one version (imports)
+  async updateSections(sections: AdminConfigSections, options: AdminConfigWriteOptions = {}): Promise<AdminConfigSnapshot> {
+    return await this.write(sections, options, {});
+  }
+
+  // Restore every section to how it stood at an earlier version, as a new
+  // version. Only applies if the stored configuration is at expectedVersion.
+  async restoreVersion(version: number, options: AdminConfigWriteOptions & { expectedVersion: number }): Promise<AdminConfigSnapshot> {
+    const { environment, adminId } = options;
+    const revision = await AdminConfigRevision.findOne({ environment: environment ?? null, version })
+      .lean<{ sections: Record<string, unknown> }>()
+      .exec();
+    if (!revision) {
+      throw new Error(`No revision of configuration version ${version}`);
+    }
+
+    const setFields: Record<string, any> = {};
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      setFields[environment ? `sections.${section}` : section] = revision.sections[section] || {};
+    }
+
+    const update = {
+      $set: { ...setFields, ...(adminId ? { updatedBy: adminId } : {}) },
+      $inc: { version: 1 },
+    };
+    const updated = environment
+      ? await AdminConfigOverlay.findOneAndUpdate({ environment, ...versionFilter(options.expectedVersion) }, update, { new: true }).exec()
+      : await AdminConfig.findOneAndUpdate({ key: CONFIG_KEY, ...versionFilter(options.expectedVersion) }, update, { new: true }).exec();
+
+    if (!updated) {
+      throw await this.conflict(environment);
+    }
+
+    await this.recordRevision(updated, options, { rolledBackTo: version });
+    return await this.written(environment);
+  }
+
+  // Set fields of one or more sections. In an overlay, null removes the
+  // override so the base value applies again.
+  private async write(
+    sections: AdminConfigSections,
+    options: AdminConfigWriteOptions,
+    meta: { section?: AdminConfigSection }
+  ): Promise<AdminConfigSnapshot> {
+    const { environment, adminId, expectedVersion } = options;
+    const setFields: Record<string, any> = {};
+    const unsetFields: Record<string, 1> = {};
+
+    for (const [section, values] of Object.entries(sections) as [AdminConfigSection, Record<string, unknown>][]) {
+      Object.entries(values || {}).forEach(([field, value]) => {
+        if (value === undefined) return;
+
+        const path = environment ? `sections.${section}.${field}` : `${section}.${field}`;
+        if (environment && value === null) {
+          unsetFields[path] = 1;
+          return;
+        }
+        // Values of approved changes were encrypted when they were proposed
+        setFields[path] = isSecretField(section, field) && typeof value === 'string' && value !== ''
+          && !encryptionService.isEncryptedSecret(value)
+          ? encryptionService.encryptSecret(value)
+          : value;
+      });
+    }
+
+    const update = {
+      $set: { ...setFields, ...(adminId ? { updatedBy: adminId } : {}) },
+      $inc: { version: 1 },
+      ...(Object.keys(unsetFields).length > 0 && { $unset: unsetFields }),
+    };
+    // Only the first save may create the document
+    const writeOptions = { upsert: expectedVersion === undefined || expectedVersion === 0, new: true };
+
+    let updated: IAdminConfig | IAdminConfigOverlay | null;
+    try {
+      updated = environment
+        ? await AdminConfigOverlay.findOneAndUpdate({ environment, ...versionFilter(expectedVersion) }, update, writeOptions).exec()
+        : await AdminConfig.findOneAndUpdate({ key: CONFIG_KEY, ...versionFilter(expectedVersion) }, update, writeOptions).exec();
+    } catch (error: any) {
+      // The upsert raced with an existing document
+      if (error?.code === 11000) {
+        throw await this.conflict(environment);
+      }
+      throw error;
+    }
+
+    if (!updated) {
+      throw await this.conflict(environment);
+    }
+
+    await this.recordRevision(updated, options, meta);
+    return await this.written(environment);
+  }
+
+  // After a write: the refreshed snapshot, or the overlay written to
+  private async written(environment?: string): Promise<AdminConfigSnapshot> {
+    const snapshot = await this.reload();
+    return environment ? await this.load(environment) : snapshot;
+  }
+
+  // Keep the configuration as written at its new version. The change has
+  // been made already, so a failure here is logged rather than thrown.
+  private async recordRevision(
+    doc: IAdminConfig | IAdminConfigOverlay,
+    options: AdminConfigWriteOptions,
+    meta: { section?: AdminConfigSection; rolledBackTo?: number }
+  ): Promise<void> {
+    const stored = doc.toObject();
+    const source = options.environment ? stored.sections || {} : stored;
+    const sections: Record<string, unknown> = {};
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      sections[section] = source[section] || {};
+    }
+
+    try {
+      await AdminConfigRevision.create({
+        environment: options.environment,
+        version: doc.version,
+        sections,
+        section: meta.section,
+        ...(options.adminId && { updatedBy: options.adminId }),
+        ...(options.approval && { approvedBy: options.approval.approvedBy, changeId: options.approval.changeId }),
+        rolledBackTo: meta.rolledBackTo,
+      });
+    } catch (error) {
+      logger.error('Failed to record admin configuration revision', error, {
+        version: doc.version,
+        environment: options.environment,
+      });
+    }
+  }
+
+  private async conflict(environment?: string): Promise<VersionConflictError> {
+    const current = environment ? await this.load(environment) : await this.reload();
+    return new VersionConflictError('Settings were changed by someone else', current.version);
+  }
+
+  private startAutoRefresh(): void {
+    if (this.refreshTimer) return;
+    this.refreshTimer = setInterval(() => {
+      this.reload();
+    }, REFRESH_INTERVAL);
+    this.refreshTimer.unref?.();
+  }
+}
+
+export const adminConfigService = new AdminConfigService();
+
+// A section as stored, with its secrets decrypted. Values not encrypted yet
+// (written before encryption) are used as they are until the migration
+// encrypts them; values that fail to decrypt are dropped.
+export function decryptSection<S extends AdminConfigSection>(
+  section: S,
+  values: AdminConfigSnapshot[S]
+): AdminConfigSnapshot[S] {
+  const decrypted: Record<string, any> = { ...values };
+  for (const field of SECRET_FIELDS[section] || []) {
+    if (!encryptionService.isEncryptedSecret(decrypted[field])) continue;
+
+    try {
+      decrypted[field] = encryptionService.decryptSecret(decrypted[field]);
+    } catch (error) {
+      logger.error('Failed to decrypt admin configuration secret', error, { field: `${section}.${field}` });
+      delete decrypted[field];
+    }
+  }
+  return decrypted as AdminConfigSnapshot[S];
+}
+
+function emptySections(): Omit<AdminConfigSnapshot, 'version'> {
+  return { server: {}, security: {}, calls: {}, clients: {}, compliance: {}, moderation: {}, features: {}, realtime: {} };
+}
+
+export function isSecretField(section: AdminConfigSection, field: string): boolean {
+  return !!SECRET_FIELDS[section]?.includes(field);
+}
+
+// A section as shown by the admin API: secrets are replaced by a mask with
+// their last four characters
+export function maskSecrets<S extends AdminConfigSection>(
+  section: S,
+  values: AdminConfigSnapshot[S]
+): AdminConfigSnapshot[S] {
+  const masked: Record<string, any> = { ...values };
+  for (const field of SECRET_FIELDS[section] || []) {
+    const value = masked[field];
+    if (typeof value === 'string' && value !== '') {
+      masked[field] = value.length >= 12 ? `********${value.slice(-4)}` : '********';
+    }
+  }
+  return masked as AdminConfigSnapshot[S];
+}
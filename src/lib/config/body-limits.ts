@@ -0,0 +1,49 @@
+// Edge Runtime compatible request body limits (no Node-only imports)
+import { FILE_CONFIGS } from '../media/validation';
+import { GROUP_CONSTANTS, BRANDING_CONSTANTS } from '../utils/constants';
+
+export const DEFAULT_MAX_REQUEST_SIZE = 1024 * 1024; // 1MB
+
+// Allowance for multipart boundaries and form fields around the file part
+const MULTIPART_OVERHEAD = 64 * 1024; // 64KB
+
+// Chat export archives (WhatsApp zip with media, Telegram result.json)
+export const MAX_IMPORT_SIZE = 500 * 1024 * 1024; // 500MB
+
+// Raw inbound email; providers cap messages around 25-40MB after encoding
+const MAX_INBOUND_EMAIL_SIZE = 40 * 1024 * 1024; // 40MB
+
+interface RouteBodyLimit {
+  pattern: RegExp;
+  limit: number;
+}
+
+// Per-route limits; routes not listed here fall back to the server-wide maximum
+export const ROUTE_BODY_LIMITS: RouteBodyLimit[] = [
+  { pattern: /^\/api\/client\/media\/upload\/image$/, limit: FILE_CONFIGS.image.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/media\/upload\/video$/, limit: FILE_CONFIGS.video.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/media\/upload\/audio$/, limit: FILE_CONFIGS.audio.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/media\/upload\/voice-note$/, limit: FILE_CONFIGS.voice.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/media\/upload\/document$/, limit: FILE_CONFIGS.document.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/user\/avatar$/, limit: FILE_CONFIGS.image.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/groups\/[^/]+\/avatar$/, limit: FILE_CONFIGS.image.maxSize + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/groups\/[^/]+\/emoji$/, limit: GROUP_CONSTANTS.CUSTOM_EMOJI_MAX_SIZE + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/admin\/organizations\/[^/]+\/branding\/(logo|favicon)$/, limit: BRANDING_CONSTANTS.ASSET_MAX_SIZE + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/client\/import$/, limit: MAX_IMPORT_SIZE + MULTIPART_OVERHEAD },
+  { pattern: /^\/api\/webhook\/smtp$/, limit: MAX_INBOUND_EMAIL_SIZE },
+];
+
+export function getRouteBodyLimit(pathname: string, defaultLimit: number = DEFAULT_MAX_REQUEST_SIZE): number {
+  const match = ROUTE_BODY_LIMITS.find(route => route.pattern.test(pathname));
+  return match ? match.limit : defaultLimit;
+}
+
+// Structured 413 error body
+export function payloadTooLargeBody(limit: number, received?: number) {
+  return {
+    error: 'Payload too large',
+    code: 'PAYLOAD_TOO_LARGE',
+    limit,
+    ...(received !== undefined && { received }),
+  };
+}
@@ -0,0 +1,87 @@
+// Client version policy shared by the edge middleware and the API. Kept free
+// of Node-only imports so it runs in the edge runtime.
+
+export const CLIENT_PLATFORMS = ['ios', 'android', 'web'] as const;
+
+export type ClientPlatform = typeof CLIENT_PLATFORMS[number];
+
+// Headers every client sends with its requests
+export const CLIENT_PLATFORM_HEADER = 'x-client-platform';
+export const CLIENT_VERSION_HEADER = 'x-client-version';
+
+export interface ClientVersionPolicy {
+  minimumVersions: Partial<Record<ClientPlatform, string>>;
+  updateUrls: Partial<Record<ClientPlatform, string>>;
+}
+
+export interface ClientInfo {
+  platform: ClientPlatform;
+  version: string;
+}
+
+// Paths an outdated client can still reach: the policy itself, auth
+// refresh so the upgrade screen can stay signed in, and everything that
+// isn't called by the apps
+const EXEMPT_PATHS = [
+  /^\/api\/config\//,
+  /^\/api\/admin\//,
+  /^\/api\/webhook/,
+  /^\/api\/client\/auth\/(refresh-token|logout)$/,
+  /^\/api\/health/,
+];
+
+export function isClientPlatform(value: unknown): value is ClientPlatform {
+  return typeof value === 'string' && (CLIENT_PLATFORMS as readonly string[]).includes(value);
+}
+
+// Dotted numeric versions ("2.14.1"); pre-release and build suffixes are
+// ignored. Returns null for anything else.
+export function parseVersion(version: string): number[] | null {
+  const match = version.trim().match(/^v?(\d+(?:\.\d+){0,3})(?:[-+].*)?$/);
+  return match ? match[1].split('.').map(Number) : null;
+}
+
+// Negative when a < b, positive when a > b, 0 when equal. Missing parts
+// count as 0, so "2.1" equals "2.1.0".
+export function compareVersions(a: string, b: string): number {
+  const left = parseVersion(a) || [];
+  const right = parseVersion(b) || [];
+
+  for (let i = 0; i < Math.max(left.length, right.length); i++) {
+    const diff = (left[i] || 0) - (right[i] || 0);
+    if (diff !== 0) return diff;
+  }
+  return 0;
+}
+
+// Read the client headers; null when they're missing or malformed
+export function readClientInfo(headers: Pick<Headers, 'get'>): ClientInfo | null {
+  const platform = headers.get(CLIENT_PLATFORM_HEADER)?.toLowerCase();
+  const version = headers.get(CLIENT_VERSION_HEADER);
+  if (!isClientPlatform(platform) || !version || !parseVersion(version)) {
+    return null;
+  }
+  return { platform, version };
+}
+
+export function isVersionExempt(pathname: string): boolean {
+  return EXEMPT_PATHS.some(pattern => pattern.test(pathname));
+}
+
+// Whether a client is below the minimum supported version for its platform
+export function isUpgradeRequired(policy: ClientVersionPolicy, client: ClientInfo): boolean {
+  const minimum = policy.minimumVersions[client.platform];
+  return !!minimum && compareVersions(client.version, minimum) < 0;
+}
+
+// Structured 426 error body
+export function upgradeRequiredBody(policy: ClientVersionPolicy, client: ClientInfo) {
+  return {
+    error: 'This app version is no longer supported, please update',
+    code: 'UPGRADE_REQUIRED',
+    platform: client.platform,
+    currentVersion: client.version,
+    minimumVersion: policy.minimumVersions[client.platform],
+    ...(policy.updateUrls[client.platform] && { updateUrl: policy.updateUrls[client.platform] }),
+  };
+}
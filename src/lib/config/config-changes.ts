@@ -0,0 +1,402 @@
+import { Types } from 'mongoose';
+import {
+  adminConfigService,
+  AdminConfigSection,
+  AdminConfigSections,
+  AdminConfigSnapshot,
+  ADMIN_CONFIG_SECTIONS,
+  BASE_ENVIRONMENT,
+  ENVIRONMENT_NAME,
+  decryptSection,
+  isSecretField,
+  maskSecrets,
+} from './admin-config';
+import { AdminConfigChangeRepository } from '../database/repositories/admin-config-change';
+import { AdminConfigRevisionRepository } from '../database/repositories/admin-config-revision';
+import { AdminRepository } from '../database/repositories/admin';
+import { IAdminConfigChange, AdminConfigChangeStatus } from '../database/models/admin-config-change';
+import { IAdminConfigRevision } from '../database/models/admin-config-revision';
+import { VersionConflictError } from '../database/concurrency';
+import { encryptionService } from '../security/encryption';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+export type ConfigSubmission =
+  | { applied: true; snapshot: AdminConfigSnapshot }
+  | { applied: false; change: IAdminConfigChange };
+
+export interface ConfigSubmitOptions {
+  environment?: string; // overlay to change; the base configuration when unset
+  expectedVersion?: number; // of the base or the overlay, as last read
+  reason?: string;
+}
+
+type ConfigDiff = IAdminConfigChange['diff'];
+
+// Two-person rule for the admin configuration, base and overlays alike.
+// Super admins change it directly; anyone else proposes a change with its
+// diff, which a second admin approves or rejects. An approved change
+// applies in one write, and only to the version it was proposed against.
+// Every version is kept, and the configuration can be rolled back to any
+// of them the same way.
+export class ConfigChangeService {
+  private changeRepository = new AdminConfigChangeRepository();
+  private revisionRepository = new AdminConfigRevisionRepository();
+  private adminRepository = new AdminRepository();
+
+  // Update one section, or propose the update for review
+  async submitUpdate<S extends AdminConfigSection>(
+    section: S,
+    values: Partial<AdminConfigSnapshot[S]>,
+    adminId: string,
+    options: ConfigSubmitOptions = {}
+  ): Promise<ConfigSubmission> {
+    const environment = parseEnvironment(options.environment);
+    const { expectedVersion } = options;
+
+    if (await this.canApplyDirectly(adminId)) {
+      const snapshot = await adminConfigService.updateSection(section, values, { environment, adminId, expectedVersion });
+      await this.supersedePending(snapshot.version, environment);
+      return { applied: true, snapshot };
+    }
+
+    const current = await this.currentAt(expectedVersion, environment);
+    const diff = this.diffSection(section, current[section], values);
+    if (diff.length === 0) {
+      throw new ConfigChangeError('Nothing would change', 400);
+    }
+
+    return {
+      applied: false,
+      change: await this.propose(
+        { kind: 'update', section, values: this.encryptSecrets(section, values), diff },
+        current.version,
+        adminId,
+        { ...options, environment }
+      ),
+    };
+  }
+
+  // Set fields across sections at once (imports), or propose it
+  async submitImport(sections: AdminConfigSections, adminId: string, options: ConfigSubmitOptions = {}): Promise<ConfigSubmission> {
+    const environment = parseEnvironment(options.environment);
+    const { diff, version } = await this.preview(sections, options);
+    if (diff.length === 0) {
+      throw new ConfigChangeError('Nothing would change', 400);
+    }
+
+    if (await this.canApplyDirectly(adminId)) {
+      const snapshot = await adminConfigService.updateSections(sections, { environment, adminId, expectedVersion: version });
+      await this.supersedePending(snapshot.version, environment);
+      return { applied: true, snapshot };
+    }
+
+    const values: Record<string, unknown> = {};
+    for (const [section, fields] of Object.entries(sections) as [AdminConfigSection, Record<string, unknown>][]) {
+      values[section] = this.encryptSecrets(section, fields);
+    }
+
+    return {
+      applied: false,
+      change: await this.propose({ kind: 'import', values, diff }, version, adminId, { ...options, environment }),
+    };
+  }
+
+  // What setting these fields would change, without changing anything
+  async preview(sections: AdminConfigSections, options: ConfigSubmitOptions = {}): Promise<{ diff: ConfigDiff; version: number }> {
+    const current = await this.currentAt(options.expectedVersion, parseEnvironment(options.environment));
+    const diff = (Object.entries(sections) as [AdminConfigSection, Record<string, unknown>][])
+      .flatMap(([section, fields]) => this.diffSection(section, current[section], fields));
+    return { diff, version: current.version };
+  }
+
+  // Roll the configuration back to an earlier version, or propose it
+  async submitRollback(
+    targetVersion: number,
+    adminId: string,
+    options: ConfigSubmitOptions = {}
+  ): Promise<ConfigSubmission> {
+    const environment = parseEnvironment(options.environment);
+    const revision = await this.getRevision(targetVersion, environment);
+    const current = await this.currentAt(options.expectedVersion, environment);
+    if (targetVersion === current.version) {
+      throw new ConfigChangeError('This is the current version', 400);
+    }
+
+    if (await this.canApplyDirectly(adminId)) {
+      const snapshot = await adminConfigService.restoreVersion(targetVersion, {
+        environment,
+        adminId,
+        expectedVersion: current.version,
+      });
+      await this.supersedePending(snapshot.version, environment);
+      logger.info('Admin configuration rolled back', { adminId, environment, targetVersion, version: snapshot.version });
+      return { applied: true, snapshot };
+    }
+
+    const diff = ADMIN_CONFIG_SECTIONS.flatMap(section =>
+      this.diffSection(section, current[section], this.completeSection(current[section], revision.sections[section]))
+    );
+
+    return {
+      applied: false,
+      change: await this.propose({ kind: 'rollback', targetVersion, diff }, current.version, adminId, { ...options, environment }),
+    };
+  }
+
+  // Approve or reject a pending change. Approval takes an admin other than
+  // the one who proposed it; the proposer may reject (withdraw) their own.
+  async review(
+    changeId: string,
+    adminId: string,
+    decision: 'approve' | 'reject',
+    note?: string
+  ): Promise<{ change: IAdminConfigChange; snapshot?: AdminConfigSnapshot }> {
+    const change = await this.getChange(changeId);
+    if (change.status !== 'pending') {
+      throw new ConfigChangeError(`Change is already ${change.status}`, 409);
+    }
+    if (decision === 'approve' && change.requestedBy.toString() === adminId) {
+      throw new ConfigChangeError('A change must be approved by a different admin', 403);
+    }
+
+    const reviewed = await this.changeRepository.review(
+      change._id,
+      decision === 'approve' ? 'applied' : 'rejected',
+      adminId,
+      note
+    );
+    if (!reviewed) {
+      throw new ConfigChangeError('Change was reviewed by someone else', 409);
+    }
+
+    if (decision === 'reject') {
+      this.count(reviewed);
+      logger.info('Admin configuration change rejected', { changeId, adminId });
+      return { change: reviewed };
+    }
+
+    const requestedBy = change.requestedBy.toString();
+    const options = {
+      environment: change.environment,
+      adminId: requestedBy,
+      expectedVersion: change.baseVersion,
+      approval: { approvedBy: adminId, changeId },
+    };
+    let snapshot: AdminConfigSnapshot;
+    try {
+      switch (change.kind) {
+        case 'update':
+          snapshot = await adminConfigService.updateSection(change.section as AdminConfigSection, change.values as any, options);
+          break;
+        case 'import':
+          snapshot = await adminConfigService.updateSections(change.values as AdminConfigSections, options);
+          break;
+        case 'rollback':
+          snapshot = await adminConfigService.restoreVersion(change.targetVersion!, options);
+          break;
+        default:
+          throw new ConfigChangeError(`Unknown change kind ${change.kind}`, 500);
+      }
+    } catch (error) {
+      if (error instanceof VersionConflictError) {
+        const superseded = await this.changeRepository.update(change._id, { status: 'superseded' });
+        if (superseded) this.count(superseded);
+        throw new ConfigChangeError('The configuration changed after this change was proposed; propose it again', 409);
+      }
+      await this.changeRepository.reopen(change._id);
+      throw error;
+    }
+
+    const applied = await this.changeRepository.update(change._id, { appliedVersion: snapshot.version });
+    await this.supersedePending(snapshot.version, change.environment);
+
+    this.count(reviewed);
+    logger.info('Admin configuration change applied', {
+      changeId,
+      kind: change.kind,
+      environment: change.environment,
+      requestedBy,
+      approvedBy: adminId,
+      version: snapshot.version,
+    });
+
+    return { change: applied || reviewed, snapshot };
+  }
+
+  async getChange(changeId: string): Promise<IAdminConfigChange> {
+    const change = Types.ObjectId.isValid(changeId)
+      ? await this.changeRepository.findById(changeId)
+      : null;
+    if (!change) {
+      throw new ConfigChangeError('Change not found', 404);
+    }
+    return change;
+  }
+
+  async listChanges(status?: AdminConfigChangeStatus, limit: number = 20, offset: number = 0) {
+    return await this.changeRepository.list(status, limit, offset);
+  }
+
+  async getRevision(version: number, environment?: string): Promise<IAdminConfigRevision> {
+    const revision = await this.revisionRepository.findByVersion(version, parseEnvironment(environment));
+    if (!revision) {
+      throw new ConfigChangeError('Version not found', 404);
+    }
+    return revision;
+  }
+
+  async listRevisions(environment?: string, limit: number = 20, offset: number = 0) {
+    return await this.revisionRepository.list(parseEnvironment(environment), limit, offset);
+  }
+
+  // Only active super admins skip review
+  private async canApplyDirectly(adminId: string): Promise<boolean> {
+    const admin = Types.ObjectId.isValid(adminId) ? await this.adminRepository.findById(adminId) : null;
+    return !!admin && admin.isActive && admin.role === 'super_admin';
+  }
+
+  // The stored configuration or overlay, checked against the version the
+  // admin last saw
+  private async currentAt(expectedVersion?: number, environment?: string): Promise<AdminConfigSnapshot> {
+    const current = await adminConfigService.load(environment);
+    if (expectedVersion !== undefined && expectedVersion !== current.version) {
+      throw new VersionConflictError('Settings were changed by someone else', current.version);
+    }
+    return current;
+  }
+
+  private async propose(
+    data: Partial<IAdminConfigChange>,
+    baseVersion: number,
+    adminId: string,
+    options: ConfigSubmitOptions
+  ): Promise<IAdminConfigChange> {
+    const change = await this.changeRepository.create({
+      ...data,
+      environment: options.environment,
+      baseVersion,
+      reason: options.reason,
+      requestedBy: new Types.ObjectId(adminId),
+    });
+
+    this.count(change);
+    logger.info('Admin configuration change proposed', {
+      changeId: change._id.toString(),
+      kind: change.kind,
+      environment: change.environment,
+      adminId,
+      fields: change.diff.map(entry => `${entry.section}.${entry.field}`),
+    });
+
+    return change;
+  }
+
+  // Secrets are not kept in plaintext while a change waits
+  private encryptSecrets(section: AdminConfigSection, values: Record<string, unknown>): Record<string, unknown> {
+    const stored: Record<string, unknown> = {};
+    for (const [field, value] of Object.entries(values)) {
+      if (value === undefined) continue;
+      stored[field] = isSecretField(section, field) && typeof value === 'string' && value !== ''
+        ? encryptionService.encryptSecret(value)
+        : value;
+    }
+    return stored;
+  }
+
+  // Fields whose value would change, secrets masked
+  private diffSection(section: AdminConfigSection, current: Record<string, any>, next: Record<string, any>): ConfigDiff {
+    const plain: Record<string, any> = decryptSection(section, next);
+    const before: Record<string, any> = maskSecrets(section, current);
+    const after: Record<string, any> = maskSecrets(section, plain);
+    const diff: ConfigDiff = [];
+
+    for (const [field, value] of Object.entries(plain)) {
+      if (value === undefined) continue;
+      if (JSON.stringify(current[field] ?? null) === JSON.stringify(value ?? null)) continue;
+
+      diff.push({ section, field, from: before[field], to: after[field] });
+    }
+    return diff;
+  }
+
+  // A stored section with fields set now but not in it cleared, so a
+  // rollback diff shows them going away
+  private completeSection(current: Record<string, any>, stored: Record<string, any> = {}): Record<string, any> {
+    const complete: Record<string, any> = { ...stored };
+    for (const field of Object.keys(current)) {
+      if (!(field in complete)) complete[field] = null;
+    }
+    return complete;
+  }
+
+  private async supersedePending(version: number, environment?: string): Promise<void> {
+    const superseded = await this.changeRepository.supersedeBefore(version, environment);
+    if (superseded > 0) {
+      metricsCollector.incrementCounter('admin_config_changes', superseded, { status: 'superseded' });
+    }
+  }
+
+  private count(change: IAdminConfigChange): void {
+    metricsCollector.incrementCounter('admin_config_changes', 1, { kind: change.kind, status: change.status });
+  }
+}
+
+// An environment name as given to the API: unset, empty or "base" for the
+// base configuration, otherwise an overlay
+export function parseEnvironment(environment?: string | null): string | undefined {
+  if (!environment || environment === BASE_ENVIRONMENT) return undefined;
+  if (!ENVIRONMENT_NAME.test(environment)) {
+    throw new ConfigChangeError('Invalid environment name', 400);
+  }
+  return environment;
+}
+
+export function serializeConfigChange(change: IAdminConfigChange) {
+  return {
+    id: change._id.toString(),
+    kind: change.kind,
+    environment: change.environment,
+    section: change.section,
+    targetVersion: change.targetVersion,
+    diff: change.diff,
+    baseVersion: change.baseVersion,
+    status: change.status,
+    reason: change.reason,
+    requestedBy: change.requestedBy.toString(),
+    reviewedBy: change.reviewedBy?.toString(),
+    reviewNote: change.reviewNote,
+    reviewedAt: change.reviewedAt,
+    appliedVersion: change.appliedVersion,
+    createdAt: change.createdAt,
+  };
+}
+
+// A revision as shown by the admin API, with its contents when loaded
+export function serializeConfigRevision(revision: IAdminConfigRevision) {
+  return {
+    environment: revision.environment,
+    version: revision.version,
+    section: revision.section,
+    updatedBy: revision.updatedBy?.toString(),
+    approvedBy: revision.approvedBy?.toString(),
+    changeId: revision.changeId?.toString(),
+    rolledBackTo: revision.rolledBackTo,
+    createdAt: revision.createdAt,
+    ...(revision.sections && {
+      sections: Object.fromEntries(ADMIN_CONFIG_SECTIONS.map(section => [
+        section,
+        maskSecrets(section, decryptSection(section, (revision.sections[section] || {}) as any)),
+      ])),
+    }),
+  };
+}
+
+export class ConfigChangeError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ConfigChangeError';
+  }
+}
+
+export const configChangeService = new ConfigChangeService();
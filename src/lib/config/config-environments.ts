@@ -0,0 +1,209 @@
+import YAML from 'yaml';
+import {
+  adminConfigService,
+  AdminConfigSection,
+  AdminConfigSections,
+  AdminConfigSnapshot,
+  ADMIN_CONFIG_SECTIONS,
+  BASE_ENVIRONMENT,
+  SECRET_FIELDS,
+  isSecretField,
+  maskSecrets,
+} from './admin-config';
+import { configChangeService, parseEnvironment, ConfigSubmission, ConfigSubmitOptions } from './config-changes';
+import { AdminConfig } from '../database/models/admin-config';
+import { IAdminConfigChange } from '../database/models/admin-config-change';
+import { AdminConfigOverlayRepository } from '../database/repositories/admin-config-overlay';
+
+const MAX_IMPORT_SIZE = 256 * 1024;
+const EXPORT_METADATA = ['environment', 'version', 'effective', 'exportedAt'];
+
+export interface ConfigImportIssue {
+  field: string;
+  message: string;
+}
+
+// Environments of the admin configuration: the base, and overlays that
+// override some of its fields for one environment. Configurations are
+// exported as YAML to promote them between deployments (staging to
+// production) and imported through the usual approval rule. Secrets never
+// leave in an export; each deployment keeps its own.
+export class ConfigEnvironmentService {
+  private overlayRepository = new AdminConfigOverlayRepository();
+
+  // The base and every overlay, and which one this instance runs with
+  async list() {
+    const [base, overlays] = await Promise.all([adminConfigService.load(), this.overlayRepository.list()]);
+
+    return {
+      current: adminConfigService.getEnvironment() || BASE_ENVIRONMENT,
+      environments: [
+        { environment: BASE_ENVIRONMENT, version: base.version, updatedAt: base.updatedAt },
+        ...overlays.map(overlay => ({
+          environment: overlay.environment,
+          version: overlay.version,
+          updatedAt: overlay.updatedAt,
+          overrides: Object.values(overlay.sections || {}).reduce((count, fields) => count + Object.keys(fields || {}).length, 0),
+        })),
+      ],
+    };
+  }
+
+  // An environment's overrides and the configuration it resolves to,
+  // secrets masked
+  async get(environment: string) {
+    const name = parseEnvironment(environment);
+    const [stored, resolved] = await Promise.all([adminConfigService.load(name), adminConfigService.resolve(name)]);
+
+    return {
+      environment: name || BASE_ENVIRONMENT,
+      version: stored.version,
+      updatedAt: stored.updatedAt,
+      ...(name && { overrides: this.maskAll(stored) }),
+      effective: this.maskAll(resolved),
+    };
+  }
+
+  // Fields whose effective value differs between two environments
+  async diff(from: string, to: string) {
+    const [left, right] = await Promise.all([
+      adminConfigService.resolve(parseEnvironment(from)),
+      adminConfigService.resolve(parseEnvironment(to)),
+    ]);
+
+    const differences: IAdminConfigChange['diff'] = [];
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      const fields = new Set([...Object.keys(left[section]), ...Object.keys(right[section])]);
+      const maskedLeft: Record<string, any> = maskSecrets(section, left[section]);
+      const maskedRight: Record<string, any> = maskSecrets(section, right[section]);
+
+      for (const field of fields) {
+        const a = (left[section] as Record<string, unknown>)[field];
+        const b = (right[section] as Record<string, unknown>)[field];
+        if (JSON.stringify(a ?? null) === JSON.stringify(b ?? null)) continue;
+
+        differences.push({ section, field, from: maskedLeft[field], to: maskedRight[field] });
+      }
+    }
+
+    return { from, to, differences };
+  }
+
+  // YAML of an environment: what is stored (an overlay's overrides only),
+  // or with effective, the whole configuration it resolves to
+  async export(environment: string, effective: boolean = false): Promise<string> {
+    const name = parseEnvironment(environment);
+    const snapshot = effective ? await adminConfigService.resolve(name) : await adminConfigService.load(name);
+
+    const sections: Record<string, Record<string, unknown>> = {};
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      const fields: Record<string, unknown> = { ...snapshot[section] };
+      for (const field of SECRET_FIELDS[section] || []) {
+        delete fields[field];
+      }
+      if (Object.keys(fields).length > 0) sections[section] = fields;
+    }
+
+    const document = new YAML.Document({
+      environment: name || BASE_ENVIRONMENT,
+      version: name && effective ? { base: snapshot.version, overlay: snapshot.overlayVersion } : snapshot.version,
+      effective,
+      exportedAt: new Date().toISOString(),
+      sections,
+    });
+    document.commentBefore = ' Admin configuration export. Secrets are not included.';
+    return document.toString();
+  }
+
+  // Parse and check an exported configuration for import into an
+  // environment. With dryRun only the diff is returned.
+  async import(
+    yaml: string,
+    adminId: string,
+    options: ConfigSubmitOptions & { dryRun?: boolean }
+  ): Promise<{ issues: ConfigImportIssue[] } | { diff: IAdminConfigChange['diff']; submission?: ConfigSubmission }> {
+    const name = parseEnvironment(options.environment);
+    const parsed = this.parse(yaml);
+    if ('issues' in parsed) return parsed;
+
+    const submitOptions = { ...options, environment: name };
+    if (options.dryRun) {
+      const { diff } = await configChangeService.preview(parsed.sections, submitOptions);
+      return { diff };
+    }
+
+    const submission = await configChangeService.submitImport(parsed.sections, adminId, submitOptions);
+    return {
+      diff: submission.applied ? [] : submission.change.diff,
+      submission,
+    };
+  }
+
+  // Sections of a YAML export, cast and validated against the stored schema
+  private parse(yaml: string): { sections: AdminConfigSections } | { issues: ConfigImportIssue[] } {
+    if (yaml.length > MAX_IMPORT_SIZE) {
+      return { issues: [{ field: '', message: 'File is too large' }] };
+    }
+
+    let document: any;
+    try {
+      document = YAML.parse(yaml);
+    } catch (error) {
+      return { issues: [{ field: '', message: error instanceof Error ? error.message : 'Invalid YAML' }] };
+    }
+
+    const input = document?.sections ?? document;
+    if (!input || typeof input !== 'object' || Array.isArray(input)) {
+      return { issues: [{ field: 'sections', message: 'Expected a mapping of sections' }] };
+    }
+
+    const issues: ConfigImportIssue[] = [];
+    const sections: AdminConfigSections = {};
+
+    for (const [section, fields] of Object.entries(input)) {
+      // Metadata written by export, when sections are not nested
+      if (input === document && EXPORT_METADATA.includes(section)) continue;
+
+      if (!ADMIN_CONFIG_SECTIONS.includes(section as AdminConfigSection)) {
+        issues.push({ field: section, message: 'Unknown section' });
+        continue;
+      }
+      if (!fields || typeof fields !== 'object' || Array.isArray(fields)) {
+        issues.push({ field: section, message: 'Expected a mapping of fields' });
+        continue;
+      }
+
+      for (const field of Object.keys(fields)) {
+        if (AdminConfig.schema.pathType(`${section}.${field}`) === 'adhocOrUndefined') {
+          issues.push({ field: `${section}.${field}`, message: 'Unknown field' });
+        }
+      }
+      sections[section as AdminConfigSection] = fields as Record<string, unknown>;
+    }
+
+    // Cast through the stored schema so types, enums and bounds are checked
+    const doc = new AdminConfig(sections);
+    const validationError = doc.validateSync();
+    for (const [path, error] of Object.entries(validationError?.errors || {})) {
+      issues.push({ field: path, message: (error as Error).message });
+    }
+    if (issues.length > 0) return { issues };
+
+    const cast: Record<string, any> = doc.toObject();
+    for (const [section, fields] of Object.entries(sections) as [AdminConfigSection, Record<string, unknown>][]) {
+      sections[section] = Object.fromEntries(Object.keys(fields).map(field => [
+        field,
+        // null clears an overlay's override; secrets are kept as given
+        fields[field] === null || isSecretField(section, field) ? fields[field] : cast[section]?.[field],
+      ]));
+    }
+
+    return { sections };
+  }
+
+  private maskAll(snapshot: AdminConfigSnapshot): Record<string, unknown> {
+    return Object.fromEntries(ADMIN_CONFIG_SECTIONS.map(section => [section, maskSecrets(section, snapshot[section])]));
+  }
+}
+
+export const configEnvironmentService = new ConfigEnvironmentService();
@@ -0,0 +1,135 @@
+// Edge Runtime compatible CORS policy helpers (no Node-only imports)
+
+export interface CorsPolicy {
+  origins: string[];
+  methods: string[];
+  allowedHeaders: string[];
+  exposedHeaders: string[];
+  credentials: boolean;
+  maxAge: number;
+  strict: boolean;
+}
+
+export const DEFAULT_CORS_METHODS = ['GET', 'POST', 'PUT', 'DELETE', 'PATCH', 'OPTIONS'];
+
+export const DEFAULT_CORS_HEADERS = [
+  'Origin',
+  'X-Requested-With',
+  'Content-Type',
+  'Accept',
+  'Authorization',
+  'X-Request-ID',
+  'X-Correlation-ID',
+  'App-Version',
+];
+
+export const DEFAULT_EXPOSED_HEADERS = [
+  'X-Request-ID',
+  'X-RateLimit-Limit',
+  'X-RateLimit-Remaining',
+  'X-RateLimit-Reset',
+];
+
+const DEVELOPMENT_ORIGINS = [
+  'http://localhost:3000',
+  'http://localhost:3001',
+  'http://127.0.0.1:3000',
+  'http://127.0.0.1:3001',
+];
+
+// Build the per-environment default policy from environment variables
+export function defaultCorsPolicy(env: {
+  NODE_ENV?: string;
+  FRONTEND_URL?: string;
+  WEBSITE_URL?: string;
+  CORS_ORIGIN?: string;
+  ALLOWED_ORIGINS?: string;
+}): CorsPolicy {
+  const origins: string[] = [];
+
+  if (env.FRONTEND_URL) origins.push(env.FRONTEND_URL);
+  if (env.WEBSITE_URL) origins.push(env.WEBSITE_URL);
+  if (env.CORS_ORIGIN) origins.push(env.CORS_ORIGIN);
+  if (env.ALLOWED_ORIGINS) {
+    origins.push(...env.ALLOWED_ORIGINS.split(',').map(o => o.trim()).filter(Boolean));
+  }
+
+  const isProduction = env.NODE_ENV === 'production';
+  if (!isProduction) {
+    origins.push(...DEVELOPMENT_ORIGINS);
+  }
+
+  return {
+    origins: [...new Set(origins)],
+    methods: DEFAULT_CORS_METHODS,
+    allowedHeaders: DEFAULT_CORS_HEADERS,
+    exposedHeaders: DEFAULT_EXPOSED_HEADERS,
+    credentials: true,
+    maxAge: 86400, // 24 hours
+    strict: isProduction,
+  };
+}
+
+// Validate a policy; strict mode forbids wildcards and plain-http origins
+export function validateCorsPolicy(policy: CorsPolicy): string[] {
+  const errors: string[] = [];
+
+  for (const origin of policy.origins) {
+    if (origin === '*') {
+      if (policy.credentials) {
+        errors.push('Wildcard origin cannot be combined with credentials');
+      }
+      if (policy.strict) {
+        errors.push('Wildcard origin is not allowed in strict mode');
+      }
+      continue;
+    }
+
+    let parsed: URL;
+    try {
+      parsed = new URL(origin);
+    } catch {
+      errors.push(`Invalid origin: ${origin}`);
+      continue;
+    }
+
+    if (parsed.origin !== origin.replace(/\/$/, '')) {
+      errors.push(`Origin must not contain a path: ${origin}`);
+    }
+
+    if (policy.strict && parsed.protocol !== 'https:') {
+      errors.push(`Strict mode requires https origins: ${origin}`);
+    }
+  }
+
+  return errors;
+}
+
+export function isOriginAllowed(policy: CorsPolicy, origin: string): boolean {
+  const normalized = origin.replace(/\/$/, '');
+  if (!policy.strict && policy.origins.includes('*')) {
+    return true;
+  }
+  return policy.origins.some(allowed => allowed.replace(/\/$/, '') === normalized);
+}
+
+// Compute the response headers for a request origin
+export function buildCorsHeaders(policy: CorsPolicy, origin?: string | null): Record<string, string> {
+  const headers: Record<string, string> = {};
+
+  if (origin && isOriginAllowed(policy, origin)) {
+    // Always echo the concrete origin; browsers reject "*" with credentials
+    headers['Access-Control-Allow-Origin'] = origin;
+    headers['Vary'] = 'Origin';
+    if (policy.credentials) {
+      headers['Access-Control-Allow-Credentials'] = 'true';
+    }
+  }
+
+  headers['Access-Control-Allow-Methods'] = policy.methods.join(', ');
+  headers['Access-Control-Allow-Headers'] = policy.allowedHeaders.join(', ');
+  headers['Access-Control-Expose-Headers'] = policy.exposedHeaders.join(', ');
+  headers['Access-Control-Max-Age'] = policy.maxAge.toString();
+
+  return headers;
+}
@@ -11,6 +11,8 @@ const createEnvSchema = (isDevelopment: boolean) => {
     PORT: z.string().transform(Number).default('3000'),
     APP_VERSION: z.string().default('1.0.0'),
     APP_NAME: z.string().default('ChatApp'),
+    CONFIG_ENVIRONMENT: z.string().regex(/^[a-z][a-z0-9-]{0,31}$/).optional(), // admin config overlay applied on top of the base, e.g. staging
+    PROCESS_ROLE: z.enum(['all', 'api', 'worker']).default('all'), // api leaves background jobs to separate worker processes
     
     // URLs
     FRONTEND_URL: z.string().url().default('http://localhost:3000'),
@@ -39,6 +41,25 @@ const createEnvSchema = (isDevelopment: boolean) => {
     AWS_SECRET_ACCESS_KEY: requiredInProduction(z.string().default('dev-secret-key')),
     AWS_S3_BUCKET: requiredInProduction(z.string().default('dev-bucket')),
     AWS_S3_ENDPOINT: z.string().optional(),
+    STORAGE_PROVIDER: z.enum(['s3', 'gcs', 'azure']).default('s3'),
+    GCS_BUCKET: z.string().optional(),
+    GCS_CREDENTIALS: z.string().optional(), // service account key JSON
+    GCS_ENDPOINT: z.string().url().optional(), // for emulators
+    AZURE_STORAGE_ACCOUNT: z.string().optional(),
+    AZURE_STORAGE_KEY: z.string().optional(),
+    AZURE_STORAGE_CONTAINER: z.string().optional(),
+    AZURE_STORAGE_ENDPOINT: z.string().url().optional(), // for Azurite
+    CDN_BASE_URL: z.string().url().optional(), // serves thumbnails and public media
+    DATA_REGIONS: z.string().default(''), // comma-separated, e.g. "eu,us"; empty turns data residency off
+    DEFAULT_DATA_REGION: z.enum(['eu', 'us', 'apac']).default('us'),
+    CDN_PURGE_URL: z.string().url().optional(), // POSTed { files: [urls] } when files are deleted
+    CDN_PURGE_TOKEN: z.string().optional(),
+    THUMBNAIL_SIZES: z.string().default('small:96,medium:300,large:640'), // name:longest edge in px
+    THUMBNAIL_FORMATS: z.string().default('jpeg,webp,avif'),
+    VIDEO_POSTER_TIMESTAMP: z.string().default('10%'), // seconds, or percent of the duration
+    UPLOAD_MAX_CONCURRENT_PER_USER: z.string().transform(Number).default('3'),
+    UPLOAD_MAX_CONCURRENT: z.string().transform(Number).default('50'), // per server process
+    UPLOAD_BANDWIDTH_PER_USER: z.string().transform(Number).default('0'), // bytes per second; 0 = unlimited
     
     // SMTP (optional in dev)
     SMTP_HOST: z.string().default('smtp.gmail.com'),
@@ -53,12 +74,20 @@ const createEnvSchema = (isDevelopment: boolean) => {
     TWILIO_ACCOUNT_SID: requiredInProduction(z.string().default('dev-account-sid')),
     TWILIO_AUTH_TOKEN: requiredInProduction(z.string().default('dev-auth-token')),
     TWILIO_FROM_NUMBER: requiredInProduction(z.string().default('+1234567890')),
+    TWILIO_CARRIER_LOOKUP: z.string().transform(val => val === 'true').default('false'), // paid Lookup call per new number
     
     // Firebase (optional in dev)
     FIREBASE_PROJECT_ID: requiredInProduction(z.string().default('dev-project')),
     FIREBASE_PRIVATE_KEY: requiredInProduction(z.string().default('dev-private-key')),
     FIREBASE_CLIENT_EMAIL: requiredInProduction(z.string().email().default('dev@example.com')),
     
+    // APNs token auth for PushKit (VoIP) call pushes
+    APNS_KEY_ID: z.string().optional(),
+    APNS_TEAM_ID: z.string().optional(),
+    APNS_PRIVATE_KEY: z.string().optional(),
+    APNS_BUNDLE_ID: z.string().optional(),
+    APNS_PRODUCTION: z.string().transform(val => val === 'true').default('false'),
+    
     // CoTURN (optional in dev)
     COTURN_PRIMARY_HOST: z.string().default('turn.example.com'),
     COTURN_SECRET: requiredInProduction(z.string().default('dev-coturn-secret')),
@@ -74,6 +103,9 @@ const createEnvSchema = (isDevelopment: boolean) => {
     RATE_LIMIT_WINDOW_MS: z.string().transform(Number).default('900000'),
     RATE_LIMIT_MAX_REQUESTS: z.string().transform(Number).default('100'),
     
+    // Request limits
+    MAX_REQUEST_SIZE: z.string().transform(Number).default('1048576'),
+    
     // Security
     ENCRYPTION_KEY: isDevelopment
       ? z.string().default('dev-encryption-key-must-be-at-least-64-characters-long-for-secure-operations')
@@ -81,6 +113,88 @@ const createEnvSchema = (isDevelopment: boolean) => {
     CORS_ORIGIN: z.string().optional(),
     ALLOWED_ORIGINS: z.string().optional(),
     
+    // Abuse protection
+    CHALLENGE_PROVIDER: z.enum(['none', 'hcaptcha', 'turnstile', 'pow']).default('none'),
+    CHALLENGE_SITE_KEY: z.string().optional(),
+    CHALLENGE_SECRET_KEY: z.string().optional(),
+    CHALLENGE_IP_THRESHOLD: z.string().transform(Number).default('3'),
+    CHALLENGE_POW_DIFFICULTY: z.string().transform(Number).default('18'),
+    
+    // GeoIP: MaxMind databases first, then CDN headers, then the lookup URL
+    GEOIP_CITY_DB: z.string().optional(), // path to GeoLite2-City.mmdb / GeoIP2-City.mmdb
+    GEOIP_ASN_DB: z.string().optional(), // path to GeoLite2-ASN.mmdb
+    GEOIP_LOOKUP_URL: z.string().optional(), // e.g. https://geo.example.com/{ip}; answers { country_code, city }
+    LOGIN_APPROVAL_HOURS: z.string().transform(Number).default('48'), // sensitive actions stay blocked this long unless answered
+    
+    // MQTT bridge for low-power clients
+    MQTT_ENABLED: z.string().transform(val => val === 'true').default('false'),
+    MQTT_PORT: z.string().transform(Number).default('1883'),
+    MQTT_TOPIC_PREFIX: z.string().default('bro'),
+    
+    // Federation (Matrix application service / XMPP component)
+    FEDERATION_ENABLED: z.string().transform(val => val === 'true').default('false'),
+    MATRIX_HOMESERVER_URL: z.string().url().optional(),
+    MATRIX_SERVER_NAME: z.string().optional(),
+    MATRIX_AS_TOKEN: z.string().optional(),
+    MATRIX_HS_TOKEN: z.string().optional(),
+    MATRIX_USER_PREFIX: z.string().default('bro_'),
+    XMPP_SERVICE: z.string().optional(),
+    XMPP_COMPONENT_DOMAIN: z.string().optional(),
+    XMPP_COMPONENT_PASSWORD: z.string().optional(),
+    
+    // Email-to-chat gateway (inbound mail posted to /api/webhook/smtp)
+    EMAIL_GATEWAY_ENABLED: z.string().transform(val => val === 'true').default('false'),
+    EMAIL_GATEWAY_DOMAIN: z.string().optional(),
+    EMAIL_INBOUND_SECRET: z.string().optional(),
+    
+    // Slash commands (/giphy is only offered when a key is set)
+    GIPHY_API_KEY: z.string().optional(),
+    GIPHY_RATING: z.enum(['g', 'pg', 'pg-13', 'r']).default('pg'),
+    
+    // Live call captions (OpenAI-compatible transcription API)
+    CAPTIONS_ENABLED: z.string().transform(val => val === 'true').default('false'),
+    CAPTIONS_STT_URL: z.string().url().default('https://api.openai.com/v1/audio/transcriptions'),
+    CAPTIONS_STT_API_KEY: z.string().optional(),
+    CAPTIONS_STT_MODEL: z.string().default('whisper-1'),
+    
+    // Live broadcasts of calls through a WHIP/WHEP media server, which also
+    // handles RTMP egress to external platforms
+    BROADCAST_ENABLED: z.string().transform(val => val === 'true').default('false'),
+    BROADCAST_MEDIA_SERVER_URL: z.string().url().optional(),
+    BROADCAST_MEDIA_SERVER_API_KEY: z.string().optional(),
+    
+    // Change data capture for external pipelines (metadata only)
+    CDC_PUBLISHER: z.enum(['none', 'kafka', 'nats']).default('none'),
+    CDC_KAFKA_REST_URL: z.string().url().optional(), // Kafka REST Proxy (v2 API)
+    CDC_NATS_URL: z.string().optional(), // nats://[user:pass@]host:4222
+    CDC_TOPIC_PREFIX: z.string().default('bro'),
+    
+    // Anonymized analytics exports, for sharing outside the company
+    ANALYTICS_SALT_ROTATION_DAYS: z.string().transform(Number).default('30'), // pseudonymous IDs change this often
+    ANALYTICS_MIN_COHORT_SIZE: z.string().transform(Number).default('10'), // smaller groups are merged into "other"
+    
+    // Single sign-on for the admin dashboard. With SSO on, admin endpoints
+    // only take phone+password sessions when break-glass local login is on.
+    ADMIN_SSO_PROTOCOL: z.enum(['none', 'oidc', 'saml']).default('none'),
+    ADMIN_OIDC_ISSUER: z.string().url().optional(), // discovery at <issuer>/.well-known/openid-configuration
+    ADMIN_OIDC_CLIENT_ID: z.string().optional(),
+    ADMIN_OIDC_CLIENT_SECRET: z.string().optional(),
+    ADMIN_OIDC_SCOPES: z.string().default('openid email profile groups'),
+    ADMIN_SAML_ENTRY_POINT: z.string().url().optional(), // IdP single sign-on URL
+    ADMIN_SAML_ISSUER: z.string().optional(), // our SP entity ID
+    ADMIN_SAML_IDP_CERT: z.string().optional(), // PEM or base64 signing certificate of the IdP
+    ADMIN_SSO_GROUPS_CLAIM: z.string().default('groups'), // OIDC claim or SAML attribute listing groups
+    ADMIN_SSO_ROLE_MAP: z.string().default(''), // IdP group to role, e.g. "secops=super_admin,trust-safety=moderator"
+    ADMIN_SSO_REDIRECT_URL: z.string().url().optional(), // dashboard page that receives the session token
+    ADMIN_LOCAL_LOGIN_ENABLED: z.string().transform(val => val === 'true').default('false'), // break-glass
+    ADMIN_SESSION_MAX_HOURS: z.string().transform(Number).default('12'),
+    ADMIN_SESSION_IDLE_MINUTES: z.string().transform(Number).default('30'),
+    ADMIN_MAX_SESSIONS: z.string().transform(Number).default('3'), // per admin; the oldest is signed out
+    
+    // Bucketed message storage for chats switched to it (0 days disables)
+    MESSAGE_BUCKET_AFTER_DAYS: z.string().transform(Number).default('30'),
+    MESSAGE_BUCKET_SIZE: z.string().transform(Number).default('200'),
+    
     // Monitoring
     ANALYTICS_ENABLED: z.string().transform(val => val === 'true').default('true'),
     METRICS_ENABLED: z.string().transform(val => val === 'true').default('true'),
@@ -109,6 +223,8 @@ class EnvironmentConfig {
         PORT: process.env.PORT,
         APP_VERSION: process.env.APP_VERSION,
         APP_NAME: process.env.APP_NAME,
+        CONFIG_ENVIRONMENT: process.env.CONFIG_ENVIRONMENT,
+        PROCESS_ROLE: process.env.PROCESS_ROLE,
         
         FRONTEND_URL: process.env.FRONTEND_URL,
         API_URL: process.env.API_URL,
@@ -127,6 +243,25 @@ class EnvironmentConfig {
         AWS_SECRET_ACCESS_KEY: process.env.AWS_SECRET_ACCESS_KEY,
         AWS_S3_BUCKET: process.env.AWS_S3_BUCKET,
         AWS_S3_ENDPOINT: process.env.AWS_S3_ENDPOINT,
+        STORAGE_PROVIDER: process.env.STORAGE_PROVIDER,
+        GCS_BUCKET: process.env.GCS_BUCKET,
+        GCS_CREDENTIALS: process.env.GCS_CREDENTIALS,
+        GCS_ENDPOINT: process.env.GCS_ENDPOINT,
+        AZURE_STORAGE_ACCOUNT: process.env.AZURE_STORAGE_ACCOUNT,
+        AZURE_STORAGE_KEY: process.env.AZURE_STORAGE_KEY,
+        AZURE_STORAGE_CONTAINER: process.env.AZURE_STORAGE_CONTAINER,
+        AZURE_STORAGE_ENDPOINT: process.env.AZURE_STORAGE_ENDPOINT,
+        CDN_BASE_URL: process.env.CDN_BASE_URL,
+        DATA_REGIONS: process.env.DATA_REGIONS,
+        DEFAULT_DATA_REGION: process.env.DEFAULT_DATA_REGION,
+        CDN_PURGE_URL: process.env.CDN_PURGE_URL,
+        CDN_PURGE_TOKEN: process.env.CDN_PURGE_TOKEN,
+        THUMBNAIL_SIZES: process.env.THUMBNAIL_SIZES,
+        THUMBNAIL_FORMATS: process.env.THUMBNAIL_FORMATS,
+        VIDEO_POSTER_TIMESTAMP: process.env.VIDEO_POSTER_TIMESTAMP,
+        UPLOAD_MAX_CONCURRENT_PER_USER: process.env.UPLOAD_MAX_CONCURRENT_PER_USER,
+        UPLOAD_MAX_CONCURRENT: process.env.UPLOAD_MAX_CONCURRENT,
+        UPLOAD_BANDWIDTH_PER_USER: process.env.UPLOAD_BANDWIDTH_PER_USER,
         
         SMTP_HOST: process.env.SMTP_HOST,
         SMTP_PORT: process.env.SMTP_PORT,
@@ -139,11 +274,18 @@ class EnvironmentConfig {
         TWILIO_ACCOUNT_SID: process.env.TWILIO_ACCOUNT_SID,
         TWILIO_AUTH_TOKEN: process.env.TWILIO_AUTH_TOKEN,
         TWILIO_FROM_NUMBER: process.env.TWILIO_FROM_NUMBER,
+        TWILIO_CARRIER_LOOKUP: process.env.TWILIO_CARRIER_LOOKUP,
         
         FIREBASE_PROJECT_ID: process.env.FIREBASE_PROJECT_ID,
         FIREBASE_PRIVATE_KEY: process.env.FIREBASE_PRIVATE_KEY,
         FIREBASE_CLIENT_EMAIL: process.env.FIREBASE_CLIENT_EMAIL,
         
+        APNS_KEY_ID: process.env.APNS_KEY_ID,
+        APNS_TEAM_ID: process.env.APNS_TEAM_ID,
+        APNS_PRIVATE_KEY: process.env.APNS_PRIVATE_KEY,
+        APNS_BUNDLE_ID: process.env.APNS_BUNDLE_ID,
+        APNS_PRODUCTION: process.env.APNS_PRODUCTION,
+        
         COTURN_PRIMARY_HOST: process.env.COTURN_PRIMARY_HOST,
         COTURN_SECRET: process.env.COTURN_SECRET,
         COTURN_REALM: process.env.COTURN_REALM,
@@ -156,10 +298,80 @@ class EnvironmentConfig {
         RATE_LIMIT_WINDOW_MS: process.env.RATE_LIMIT_WINDOW_MS,
         RATE_LIMIT_MAX_REQUESTS: process.env.RATE_LIMIT_MAX_REQUESTS,
         
+        MAX_REQUEST_SIZE: process.env.MAX_REQUEST_SIZE,
+        
         ENCRYPTION_KEY: process.env.ENCRYPTION_KEY,
         CORS_ORIGIN: process.env.CORS_ORIGIN,
         ALLOWED_ORIGINS: process.env.ALLOWED_ORIGINS,
         
+        CHALLENGE_PROVIDER: process.env.CHALLENGE_PROVIDER,
+        CHALLENGE_SITE_KEY: process.env.CHALLENGE_SITE_KEY,
+        CHALLENGE_SECRET_KEY: process.env.CHALLENGE_SECRET_KEY,
+        CHALLENGE_IP_THRESHOLD: process.env.CHALLENGE_IP_THRESHOLD,
+        CHALLENGE_POW_DIFFICULTY: process.env.CHALLENGE_POW_DIFFICULTY,
+        
+        GEOIP_CITY_DB: process.env.GEOIP_CITY_DB,
+        GEOIP_ASN_DB: process.env.GEOIP_ASN_DB,
+        GEOIP_LOOKUP_URL: process.env.GEOIP_LOOKUP_URL,
+        LOGIN_APPROVAL_HOURS: process.env.LOGIN_APPROVAL_HOURS,
+        
+        MQTT_ENABLED: process.env.MQTT_ENABLED,
+        MQTT_PORT: process.env.MQTT_PORT,
+        MQTT_TOPIC_PREFIX: process.env.MQTT_TOPIC_PREFIX,
+        
+        FEDERATION_ENABLED: process.env.FEDERATION_ENABLED,
+        MATRIX_HOMESERVER_URL: process.env.MATRIX_HOMESERVER_URL,
+        MATRIX_SERVER_NAME: process.env.MATRIX_SERVER_NAME,
+        MATRIX_AS_TOKEN: process.env.MATRIX_AS_TOKEN,
+        MATRIX_HS_TOKEN: process.env.MATRIX_HS_TOKEN,
+        MATRIX_USER_PREFIX: process.env.MATRIX_USER_PREFIX,
+        XMPP_SERVICE: process.env.XMPP_SERVICE,
+        XMPP_COMPONENT_DOMAIN: process.env.XMPP_COMPONENT_DOMAIN,
+        XMPP_COMPONENT_PASSWORD: process.env.XMPP_COMPONENT_PASSWORD,
+        
+        EMAIL_GATEWAY_ENABLED: process.env.EMAIL_GATEWAY_ENABLED,
+        EMAIL_GATEWAY_DOMAIN: process.env.EMAIL_GATEWAY_DOMAIN,
+        EMAIL_INBOUND_SECRET: process.env.EMAIL_INBOUND_SECRET,
+        
+        GIPHY_API_KEY: process.env.GIPHY_API_KEY,
+        GIPHY_RATING: process.env.GIPHY_RATING,
+        
+        CAPTIONS_ENABLED: process.env.CAPTIONS_ENABLED,
+        CAPTIONS_STT_URL: process.env.CAPTIONS_STT_URL,
+        CAPTIONS_STT_API_KEY: process.env.CAPTIONS_STT_API_KEY,
+        CAPTIONS_STT_MODEL: process.env.CAPTIONS_STT_MODEL,
+        
+        BROADCAST_ENABLED: process.env.BROADCAST_ENABLED,
+        BROADCAST_MEDIA_SERVER_URL: process.env.BROADCAST_MEDIA_SERVER_URL,
+        BROADCAST_MEDIA_SERVER_API_KEY: process.env.BROADCAST_MEDIA_SERVER_API_KEY,
+        
+        CDC_PUBLISHER: process.env.CDC_PUBLISHER,
+        CDC_KAFKA_REST_URL: process.env.CDC_KAFKA_REST_URL,
+        CDC_NATS_URL: process.env.CDC_NATS_URL,
+        CDC_TOPIC_PREFIX: process.env.CDC_TOPIC_PREFIX,
+        
+        ANALYTICS_SALT_ROTATION_DAYS: process.env.ANALYTICS_SALT_ROTATION_DAYS,
+        ANALYTICS_MIN_COHORT_SIZE: process.env.ANALYTICS_MIN_COHORT_SIZE,
+        
+        ADMIN_SSO_PROTOCOL: process.env.ADMIN_SSO_PROTOCOL,
+        ADMIN_OIDC_ISSUER: process.env.ADMIN_OIDC_ISSUER,
+        ADMIN_OIDC_CLIENT_ID: process.env.ADMIN_OIDC_CLIENT_ID,
+        ADMIN_OIDC_CLIENT_SECRET: process.env.ADMIN_OIDC_CLIENT_SECRET,
+        ADMIN_OIDC_SCOPES: process.env.ADMIN_OIDC_SCOPES,
+        ADMIN_SAML_ENTRY_POINT: process.env.ADMIN_SAML_ENTRY_POINT,
+        ADMIN_SAML_ISSUER: process.env.ADMIN_SAML_ISSUER,
+        ADMIN_SAML_IDP_CERT: process.env.ADMIN_SAML_IDP_CERT,
+        ADMIN_SSO_GROUPS_CLAIM: process.env.ADMIN_SSO_GROUPS_CLAIM,
+        ADMIN_SSO_ROLE_MAP: process.env.ADMIN_SSO_ROLE_MAP,
+        ADMIN_SSO_REDIRECT_URL: process.env.ADMIN_SSO_REDIRECT_URL,
+        ADMIN_LOCAL_LOGIN_ENABLED: process.env.ADMIN_LOCAL_LOGIN_ENABLED,
+        ADMIN_SESSION_MAX_HOURS: process.env.ADMIN_SESSION_MAX_HOURS,
+        ADMIN_SESSION_IDLE_MINUTES: process.env.ADMIN_SESSION_IDLE_MINUTES,
+        ADMIN_MAX_SESSIONS: process.env.ADMIN_MAX_SESSIONS,
+        
+        MESSAGE_BUCKET_AFTER_DAYS: process.env.MESSAGE_BUCKET_AFTER_DAYS,
+        MESSAGE_BUCKET_SIZE: process.env.MESSAGE_BUCKET_SIZE,
+        
         ANALYTICS_ENABLED: process.env.ANALYTICS_ENABLED,
         METRICS_ENABLED: process.env.METRICS_ENABLED,
         HEALTH_CHECK_ENABLED: process.env.HEALTH_CHECK_ENABLED,
@@ -218,7 +430,7 @@ class EnvironmentConfig {
   }
 
   // Check if service is properly configured
-  isServiceConfigured(service: 'smtp' | 'twilio' | 'aws' | 'firebase' | 'redis'): boolean {
+  isServiceConfigured(service: 'smtp' | 'twilio' | 'aws' | 'firebase' | 'apns' | 'redis'): boolean {
     const config = this.get();
     
     switch (service) {
@@ -230,6 +442,8 @@ class EnvironmentConfig {
         return !!(config.AWS_ACCESS_KEY_ID && config.AWS_SECRET_ACCESS_KEY && config.AWS_ACCESS_KEY_ID !== 'dev-access-key');
       case 'firebase':
         return !!(config.FIREBASE_PROJECT_ID && config.FIREBASE_PRIVATE_KEY && config.FIREBASE_PROJECT_ID !== 'dev-project');
+      case 'apns':
+        return !!(config.APNS_KEY_ID && config.APNS_TEAM_ID && config.APNS_PRIVATE_KEY && config.APNS_BUNDLE_ID);
       case 'redis':
         return !!config.REDIS_URL;
       default:
@@ -0,0 +1,201 @@
+import { Model } from 'mongoose';
+import type { ChangeStream, ChangeStreamDocument } from 'mongodb';
+import { ChangeStreamCheckpointRepository } from './repositories/change-stream-checkpoint';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const RESTART_DELAY = 30 * 1000;
+const UNSUPPORTED_RETRY_DELAY = 10 * 60 * 1000; // in case the deployment becomes a replica set
+const CHANGE_STREAMS_UNSUPPORTED = 40573; // standalone server
+const RESUME_TOKEN_LOST = 286; // ChangeStreamHistoryLost
+const MAX_TIMER_DELAY = 2 ** 31 - 1; // setTimeout limit
+
+export type ChangeOperation = 'insert' | 'update' | 'replace' | 'delete';
+
+export interface ChangeSubscription {
+  name: string; // for logs, and the checkpoint name when resumable
+  model: Model<any>;
+  operations?: ChangeOperation[]; // defaults to inserts, updates and replaces
+  // Persist the resume token after each handled change and pick up from it
+  // after a restart. Subscribers that only wake a worker which also sweeps
+  // on a timer don't need this.
+  resumable?: boolean;
+  handler: (change: ChangeStreamDocument) => Promise<void> | void;
+  // Told when the stream comes up (true) or is lost (false), so callers can
+  // poll in the meantime
+  onStatus?: (live: boolean) => void;
+}
+
+export interface ChangeStreamHandle {
+  close(): Promise<void>;
+}
+
+interface SubscriptionState {
+  subscription: ChangeSubscription;
+  stream: ChangeStream | null;
+  live: boolean;
+  closed: boolean;
+  events: number;
+  lastEventAt?: Date;
+  restartTimer: NodeJS.Timeout | null;
+}
+
+// MongoDB change streams for workers that would otherwise poll. Handlers run
+// one change at a time per subscription; a stream that fails is reopened
+// (from its checkpoint when resumable) and subscribers are told so they can
+// fall back to polling. Change streams need a replica set or sharded
+// cluster; on a standalone server every subscription stays down.
+export class ChangeStreamHub {
+  private checkpointRepository = new ChangeStreamCheckpointRepository();
+  private subscriptions = new Set<SubscriptionState>();
+
+  watch(subscription: ChangeSubscription): ChangeStreamHandle {
+    const state: SubscriptionState = {
+      subscription,
+      stream: null,
+      live: false,
+      closed: false,
+      events: 0,
+      restartTimer: null,
+    };
+    this.subscriptions.add(state);
+    this.follow(state);
+
+    return {
+      close: async () => {
+        state.closed = true;
+        this.subscriptions.delete(state);
+        if (state.restartTimer) clearTimeout(state.restartTimer);
+        await state.stream?.close().catch(() => {});
+      },
+    };
+  }
+
+  getStatus() {
+    return [...this.subscriptions].map(state => ({
+      name: state.subscription.name,
+      live: state.live,
+      events: state.events,
+      lastEventAt: state.lastEventAt,
+    }));
+  }
+
+  private async follow(state: SubscriptionState): Promise<void> {
+    const { subscription } = state;
+    const checkpointName = `watch:${subscription.name}`;
+    const operations = subscription.operations ?? ['insert', 'update', 'replace'];
+    let retryDelay = RESTART_DELAY;
+
+    try {
+      const checkpoint = subscription.resumable
+        ? await this.checkpointRepository.findByName(checkpointName)
+        : null;
+
+      const stream = subscription.model.collection.watch(
+        [{ $match: { operationType: { $in: operations } } }],
+        {
+          fullDocument: 'updateLookup',
+          ...(checkpoint && { resumeAfter: checkpoint.resumeToken }),
+        }
+      );
+      state.stream = stream;
+
+      // Emitted once the server has accepted the stream, before any change
+      stream.once('init', () => this.setLive(state, true));
+
+      for await (const change of stream) {
+        await subscription.handler(change);
+
+        state.events++;
+        state.lastEventAt = new Date();
+        if (subscription.resumable) {
+          await this.checkpointRepository.save(checkpointName, change._id as Record<string, unknown>);
+        }
+      }
+    } catch (error) {
+      retryDelay = this.handleFailure(state, error, checkpointName);
+    } finally {
+      state.stream = null;
+    }
+
+    this.setLive(state, false);
+    this.scheduleRestart(state, retryDelay);
+  }
+
+  // Log a stream failure and decide how long to wait before reopening
+  private handleFailure(state: SubscriptionState, error: any, checkpointName: string): number {
+    if (state.closed) return 0;
+
+    const name = state.subscription.name;
+    if (error?.code === CHANGE_STREAMS_UNSUPPORTED) {
+      logger.warn('Change streams unavailable, falling back to polling', { subscription: name });
+      return UNSUPPORTED_RETRY_DELAY;
+    }
+    if (error?.code === RESUME_TOKEN_LOST) {
+      // The oplog has moved past the checkpoint; changes in between are lost
+      logger.warn('Change stream checkpoint expired, resuming from now', { subscription: name });
+      this.checkpointRepository.delete(checkpointName).catch(() => {});
+      return 0;
+    }
+
+    logger.error('Change stream failed', error, { subscription: name });
+    metricsCollector.incrementCounter('change_stream_failures', 1, { subscription: name });
+    return RESTART_DELAY;
+  }
+
+  private scheduleRestart(state: SubscriptionState, delay: number): void {
+    if (state.closed) return;
+
+    state.restartTimer = setTimeout(() => {
+      state.restartTimer = null;
+      if (!state.closed) this.follow(state);
+    }, delay);
+    state.restartTimer.unref();
+  }
+
+  private setLive(state: SubscriptionState, live: boolean): void {
+    if (state.live === live) return;
+    state.live = live;
+
+    try {
+      state.subscription.onStatus?.(live);
+    } catch (error) {
+      logger.error('Change stream status handler failed', error, { subscription: state.subscription.name });
+    }
+  }
+}
+
+// Runs a callback at the earliest of the times it is asked for, for workers
+// that learn about due work from change streams instead of polling for it
+export class WakeTimer {
+  private timer: NodeJS.Timeout | null = null;
+  private wakeAt: number | null = null;
+
+  constructor(private callback: () => void) {}
+
+  schedule(at: Date | null | undefined): void {
+    if (!at) return;
+
+    const time = at.getTime();
+    if (this.wakeAt !== null && this.wakeAt <= time) return;
+
+    this.clear();
+    this.wakeAt = time;
+    this.timer = setTimeout(() => {
+      this.timer = null;
+      this.wakeAt = null;
+      this.callback();
+    }, Math.min(Math.max(time - Date.now(), 0), MAX_TIMER_DELAY));
+    this.timer.unref();
+  }
+
+  clear(): void {
+    if (this.timer) {
+      clearTimeout(this.timer);
+      this.timer = null;
+    }
+    this.wakeAt = null;
+  }
+}
+
+export const changeStreamHub = new ChangeStreamHub();
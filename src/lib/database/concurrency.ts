@@ -0,0 +1,37 @@
+// Optimistic concurrency for documents edited by several people at once,
+// such as chat and group settings or the admin configuration. Each change
+// bumps the document's version; a client that sends the version it last saw
+// only overwrites if nobody changed the document since, and otherwise gets
+// a 409 with the current version to reload from.
+
+const RETRY_ATTEMPTS = 3;
+const RETRY_DELAY = 20; // ms, plus jitter
+
+// Filter matching the version the caller last saw. Documents written before
+// versions existed have none and count as version 0.
+export function versionFilter(expectedVersion?: number): Record<string, unknown> {
+  if (expectedVersion === undefined) return {};
+  return { version: expectedVersion === 0 ? { $in: [0, null] } : expectedVersion };
+}
+
+// Retry an internal update that lost a race with another writer. Only for
+// updates that give the same result however often they run.
+export async function retryOnConflict<T>(update: () => Promise<T>, attempts: number = RETRY_ATTEMPTS): Promise<T> {
+  for (let attempt = 1; ; attempt++) {
+    try {
+      return await update();
+    } catch (error) {
+      if (!(error instanceof VersionConflictError) || attempt >= attempts) {
+        throw error;
+      }
+      await new Promise(resolve => setTimeout(resolve, RETRY_DELAY * attempt + Math.random() * RETRY_DELAY));
+    }
+  }
+}
+
+export class VersionConflictError extends Error {
+  constructor(message: string, public currentVersion: number, public status: number = 409) {
+    super(message);
+    this.name = 'VersionConflictError';
+  }
+}
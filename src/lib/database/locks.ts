@@ -0,0 +1,148 @@
+import crypto from 'crypto';
+import { redisConfig } from '../config/redis';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const KEY_PREFIX = 'lock';
+const FENCE_TTL = 7 * 24 * 60 * 60; // seconds; far longer than any lock is held
+const DEFAULT_TTL = 15 * 1000;
+const DEFAULT_WAIT = 5 * 1000;
+const RETRY_DELAY = 50; // plus jitter
+
+// Delete or extend the key only while it still holds our token, so a holder
+// whose lock expired can't release or keep the next holder's
+const RELEASE_SCRIPT = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+  return redis.call('del', KEYS[1])
+end
+return 0`;
+const EXTEND_SCRIPT = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+  return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0`;
+
+export interface LockOptions {
+  ttl?: number; // ms before the lock frees itself if the holder dies
+  wait?: number; // ms to keep trying before giving up
+}
+
+export interface Lock {
+  resource: string;
+  // Increases with every acquisition of the resource; stores that record it
+  // can reject writes from a holder whose lock has since expired
+  fence: number;
+  // False once the lock expired and may have been taken by someone else
+  isHeld(): Promise<boolean>;
+  extend(ttl?: number): Promise<boolean>;
+  release(): Promise<void>;
+}
+
+// Mutual exclusion for operations that read and then write shared state, e.g.
+// ending a call or changing group membership, when replicas can handle the
+// same call or group at once. Single Redis instance locks with fencing
+// tokens; without Redis there is one process, and locks are held in memory.
+export class DistributedLockService {
+  private redis = redisConfig.getClient();
+  private memoryLocks = new Map<string, { token: string; expiresAt: number }>();
+  private memoryFences = new Map<string, number>();
+
+  // Take the lock, waiting for the current holder up to options.wait
+  async acquire(resource: string, options: LockOptions = {}): Promise<Lock> {
+    const ttl = options.ttl ?? DEFAULT_TTL;
+    const deadline = Date.now() + (options.wait ?? DEFAULT_WAIT);
+    const token = crypto.randomBytes(16).toString('hex');
+
+    while (true) {
+      const fence = await this.tryAcquire(resource, token, ttl);
+      if (fence !== null) {
+        return this.createLock(resource, token, fence, ttl);
+      }
+
+      if (Date.now() >= deadline) {
+        metricsCollector.incrementCounter('lock_timeouts', 1, { resource: resource.split(':')[0] });
+        throw new LockTimeoutError(`Resource is busy: ${resource}`);
+      }
+      await new Promise(resolve => setTimeout(resolve, RETRY_DELAY + Math.random() * RETRY_DELAY));
+    }
+  }
+
+  // Run fn while holding the lock, releasing it afterwards
+  async withLock<T>(resource: string, fn: (lock: Lock) => Promise<T>, options: LockOptions = {}): Promise<T> {
+    const lock = await this.acquire(resource, options);
+    try {
+      return await fn(lock);
+    } finally {
+      await lock.release();
+    }
+  }
+
+  // The lock's fencing token, or null when someone else holds it
+  private async tryAcquire(resource: string, token: string, ttl: number): Promise<number | null> {
+    const key = `${KEY_PREFIX}:${resource}`;
+
+    if (this.redis) {
+      if (!(await this.redis.set(key, token, 'PX', ttl, 'NX'))) {
+        return null;
+      }
+      const fenceKey = `${KEY_PREFIX}:fence:${resource}`;
+      const results = await this.redis.multi().incr(fenceKey).expire(fenceKey, FENCE_TTL).exec();
+      return Number(results?.[0]?.[1] ?? 0);
+    }
+
+    const current = this.memoryLocks.get(key);
+    if (current && current.expiresAt > Date.now()) {
+      return null;
+    }
+    this.memoryLocks.set(key, { token, expiresAt: Date.now() + ttl });
+    const fence = (this.memoryFences.get(key) ?? 0) + 1;
+    this.memoryFences.set(key, fence);
+    return fence;
+  }
+
+  private createLock(resource: string, token: string, fence: number, ttl: number): Lock {
+    const key = `${KEY_PREFIX}:${resource}`;
+
+    return {
+      resource,
+      fence,
+      isHeld: async () => {
+        if (this.redis) {
+          return (await this.redis.get(key)) === token;
+        }
+        const current = this.memoryLocks.get(key);
+        return current?.token === token && current.expiresAt > Date.now();
+      },
+      extend: async (extendBy = ttl) => {
+        if (this.redis) {
+          return (await this.redis.eval(EXTEND_SCRIPT, 1, key, token, extendBy)) === 1;
+        }
+        const current = this.memoryLocks.get(key);
+        if (current?.token !== token || current.expiresAt <= Date.now()) return false;
+        current.expiresAt = Date.now() + extendBy;
+        return true;
+      },
+      release: async () => {
+        try {
+          if (this.redis) {
+            await this.redis.eval(RELEASE_SCRIPT, 1, key, token);
+          } else if (this.memoryLocks.get(key)?.token === token) {
+            this.memoryLocks.delete(key);
+          }
+        } catch (error) {
+          // It expires on its own
+          logger.error('Failed to release lock', error, { resource });
+        }
+      },
+    };
+  }
+}
+
+export class LockTimeoutError extends Error {
+  constructor(message: string, public status: number = 409) {
+    super(message);
+    this.name = 'LockTimeoutError';
+  }
+}
+
+export const distributedLock = new DistributedLockService();
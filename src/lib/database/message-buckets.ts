@@ -0,0 +1,117 @@
+import { Types } from 'mongoose';
+import { Chat } from './models/chat';
+import { Message } from './models/message';
+import { MessageBucketRepository } from './repositories/message-bucket';
+import { distributedLock, LockTimeoutError } from './locks';
+import { environmentConfig } from '../config/environment';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 60 * 60 * 1000;
+const LOCK_TTL = 10 * 60 * 1000;
+const DAY = 24 * 60 * 60 * 1000;
+
+// Packs the old history of chats on bucketed storage into message buckets.
+// Messages move in full buckets only, oldest first; the rest stay live until
+// there are enough of them. Messages awaiting review and the chat's last
+// message stay live, since they are still looked up on their own.
+export class MessageBucketService {
+  private messageBucketRepository = new MessageBucketRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Safe to run on several instances: each chat is compacted under a lock
+  start(): void {
+    if (this.timer || environmentConfig.get().MESSAGE_BUCKET_AFTER_DAYS <= 0) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Move a chat's old messages into buckets. Returns how many moved.
+  async compactChat(chatId: string | Types.ObjectId): Promise<number> {
+    const env = environmentConfig.get();
+    const cutoff = new Date(Date.now() - env.MESSAGE_BUCKET_AFTER_DAYS * DAY);
+
+    try {
+      return await distributedLock.withLock(
+        `message-buckets:${chatId}`,
+        () => this.compact(chatId, cutoff, env.MESSAGE_BUCKET_SIZE),
+        { ttl: LOCK_TTL, wait: 0 }
+      );
+    } catch (error) {
+      // Another instance is on it
+      if (error instanceof LockTimeoutError) return 0;
+      throw error;
+    }
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const chats = await Chat.find({ messageStorage: 'bucketed' }).select('_id').lean().exec();
+      for (const chat of chats) {
+        try {
+          const moved = await this.compactChat(chat._id);
+          if (moved > 0) {
+            metricsCollector.incrementCounter('messages_bucketed', moved);
+          }
+        } catch (error) {
+          logger.error('Failed to compact chat messages', error, { chatId: chat._id.toString() });
+        }
+      }
+    } catch (error) {
+      logger.error('Message bucket compaction failed', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  private async compact(chatId: string | Types.ObjectId, cutoff: Date, size: number): Promise<number> {
+    const chat = await Chat.findById(chatId).select('lastMessage messageStorage').lean().exec();
+    if (!chat || chat.messageStorage !== 'bucketed') return 0;
+
+    let moved = 0;
+    while (true) {
+      const messages = await Message.find({
+        chatId,
+        createdAt: { $lt: cutoff },
+        'moderation.state': { $ne: 'pending' },
+        ...(chat.lastMessage && { _id: { $ne: chat.lastMessage } }),
+      })
+        .sort({ createdAt: 1, _id: 1 })
+        .limit(size)
+        .lean()
+        .exec();
+      if (messages.length < size) break;
+
+      // A copy may be left in an older bucket by a run that stopped between
+      // writing its bucket and deleting the live messages
+      const ids = messages.map(message => message._id);
+      await this.messageBucketRepository.removeMessages(ids);
+      await this.messageBucketRepository.create({
+        chatId: new Types.ObjectId(chatId.toString()),
+        firstAt: messages[0].createdAt,
+        lastAt: messages[messages.length - 1].createdAt,
+        count: messages.length,
+        messages,
+      });
+      await Message.deleteMany({ _id: { $in: ids } }).exec();
+      moved += messages.length;
+    }
+
+    return moved;
+  }
+}
+
+export const messageBucketService = new MessageBucketService();
@@ -0,0 +1,42 @@
+import { AdminConfig } from '../models/admin-config';
+import { SECRET_FIELDS } from '../../config/admin-config';
+import { encryptionService } from '../../security/encryption';
+import { logger } from '../../monitoring/logging';
+import type { MigrationDefinition } from './index';
+
+// Encrypt provider secrets stored in plaintext before field-level
+// encryption was introduced. Values already encrypted are left alone, so
+// the migration can be re-run. The version is not bumped: what readers
+// see after decryption is unchanged.
+export const encryptAdminConfigSecrets: MigrationDefinition = {
+  name: '2026-10-encrypt-admin-config-secrets',
+  description: 'Encrypt plaintext secrets stored in the admin configuration',
+  up: async () => {
+    let documents = 0;
+    let encrypted = 0;
+
+    const cursor = AdminConfig.find({}).lean<Record<string, any>>().cursor();
+
+    for await (const config of cursor) {
+      documents++;
+      const update: Record<string, string> = {};
+
+      for (const [section, fields] of Object.entries(SECRET_FIELDS)) {
+        for (const field of fields || []) {
+          const value = config[section]?.[field];
+          if (typeof value !== 'string' || value === '' || encryptionService.isEncryptedSecret(value)) continue;
+          update[`${section}.${field}`] = encryptionService.encryptSecret(value);
+        }
+      }
+
+      const count = Object.keys(update).length;
+      if (count === 0) continue;
+
+      await AdminConfig.updateOne({ _id: config._id }, { $set: update }).exec();
+      encrypted += count;
+      logger.info('Encrypted admin configuration secrets', { key: config.key, fields: Object.keys(update) });
+    }
+
+    return { documents, encrypted };
+  },
+};
@@ -0,0 +1,83 @@
+import { Migration, IMigration } from '../models/migration';
+import { logger } from '../../monitoring/logging';
+import { normalizePhoneNumbers } from './normalize-phone-numbers';
+import { encryptAdminConfigSecrets } from './encrypt-admin-config-secrets';
+import { recordAdminConfigBaseline } from './record-admin-config-baseline';
+
+export interface MigrationDefinition {
+  name: string;
+  description: string;
+  up: () => Promise<any>;
+}
+
+export interface MigrationStatus {
+  name: string;
+  description: string;
+  applied: boolean;
+  appliedAt?: Date;
+  duration?: number;
+}
+
+// Registered migrations, applied in array order
+export const migrations: MigrationDefinition[] = [
+  normalizePhoneNumbers,
+  encryptAdminConfigSecrets,
+  recordAdminConfigBaseline,
+];
+
+export class MigrationRunner {
+  // Get status of every registered migration
+  async getStatus(): Promise<MigrationStatus[]> {
+    const applied: IMigration[] = await Migration.find({}).exec();
+    const appliedByName = new Map(applied.map(m => [m.name, m]));
+
+    return migrations.map(migration => {
+      const record = appliedByName.get(migration.name);
+      return {
+        name: migration.name,
+        description: migration.description,
+        applied: !!record,
+        appliedAt: record?.appliedAt,
+        duration: record?.duration,
+      };
+    });
+  }
+
+  // Get migrations that have not been applied yet
+  async getPending(): Promise<MigrationDefinition[]> {
+    const status = await this.getStatus();
+    const pending = new Set(status.filter(s => !s.applied).map(s => s.name));
+    return migrations.filter(m => pending.has(m.name));
+  }
+
+  // Apply all pending migrations in order
+  async runPending(): Promise<string[]> {
+    const pending = await this.getPending();
+    const appliedNames: string[] = [];
+
+    for (const migration of pending) {
+      const startTime = Date.now();
+      logger.info('Applying migration', { migration: migration.name });
+
+      const result = await migration.up();
+
+      await Migration.create({
+        name: migration.name,
+        description: migration.description,
+        appliedAt: new Date(),
+        duration: Date.now() - startTime,
+        result,
+      });
+
+      appliedNames.push(migration.name);
+      logger.info('Migration applied', {
+        migration: migration.name,
+        duration: Date.now() - startTime,
+      });
+    }
+
+    return appliedNames;
+  }
+}
+
+export const migrationRunner = new MigrationRunner();
@@ -0,0 +1,176 @@
+import { Types } from 'mongoose';
+import { User } from '../models/user';
+import { Chat } from '../models/chat';
+import { Message } from '../models/message';
+import { getArchiveModels } from '../regional';
+import { PhoneUtils } from '../../utils/phone';
+import { logger } from '../../monitoring/logging';
+import type { MigrationDefinition } from './index';
+
+interface PhoneRecord {
+  _id: Types.ObjectId;
+  phoneNumber: string;
+  isVerified: boolean;
+  createdAt: Date;
+}
+
+// Rewrite stored phone numbers to E.164 and fold accounts that only differed
+// in formatting (e.g. "+1 (415) 555-2671" and "+14155552671") into one.
+// The oldest verified account wins; the others are suspended and point at it,
+// after their chats, admin roles and sent messages move over to it.
+export const normalizePhoneNumbers: MigrationDefinition = {
+  name: '2026-10-normalize-phone-numbers',
+  description: 'Normalize user phone numbers to E.164 and merge formatting duplicates',
+  up: async () => {
+    const groups = new Map<string, PhoneRecord[]>();
+    let scanned = 0;
+    let invalid = 0;
+
+    // Federation puppets carry placeholder numbers
+    const cursor = User.find({ bridge: { $exists: false } })
+      .select('phoneNumber isVerified createdAt')
+      .lean<PhoneRecord>()
+      .cursor();
+
+    for await (const user of cursor) {
+      scanned++;
+      const normalized = PhoneUtils.normalize(user.phoneNumber);
+      if (!normalized) {
+        invalid++;
+        logger.warn('Unparseable phone number left unchanged', { userId: user._id.toString() });
+        continue;
+      }
+
+      const group = groups.get(normalized) || [];
+      group.push(user);
+      groups.set(normalized, group);
+    }
+
+    let normalizedCount = 0;
+    let merged = 0;
+    const skipped: string[][] = [];
+
+    for (const [e164, users] of groups) {
+      // Prefer a verified account, then one already stored canonically, then oldest
+      users.sort((a, b) =>
+        Number(b.isVerified) - Number(a.isVerified) ||
+        Number(b.phoneNumber === e164) - Number(a.phoneNumber === e164) ||
+        new Date(a.createdAt).getTime() - new Date(b.createdAt).getTime()
+      );
+      const [primary, ...duplicates] = users;
+
+      if (duplicates.length > 0 && await hasDirectChatConflict(users)) {
+        skipped.push(users.map(user => user._id.toString()));
+        logger.warn('Duplicate phone accounts left for manual merge', {
+          userIds: users.map(user => user._id.toString()),
+        });
+        continue;
+      }
+
+      for (const duplicate of duplicates) {
+        const dup = await User.findById(duplicate._id).select('contacts').exec();
+
+        await User.updateOne(
+          { _id: primary._id },
+          { $addToSet: { contacts: { $each: dup?.contacts || [] } } }
+        ).exec();
+        await User.updateMany(
+          { contacts: duplicate._id },
+          { $addToSet: { contacts: primary._id } }
+        ).exec();
+        await User.updateMany(
+          { contacts: duplicate._id },
+          { $pull: { contacts: duplicate._id } }
+        ).exec();
+        await moveChatsAndMessages(duplicate._id, primary._id);
+        await User.updateOne(
+          { _id: duplicate._id },
+          {
+            $set: {
+              mergedInto: primary._id,
+              isBanned: true,
+              banReason: 'Duplicate account merged',
+              isOnline: false,
+              // Frees the canonical number for the primary
+              ...(duplicate.phoneNumber === e164 && { phoneNumber: `merged:${duplicate._id}` }),
+            },
+          }
+        ).exec();
+
+        merged++;
+        logger.info('Merged duplicate phone account', {
+          userId: duplicate._id.toString(),
+          mergedInto: primary._id.toString(),
+        });
+      }
+
+      const update: Record<string, string> = {};
+      if (primary.phoneNumber !== e164) {
+        update.phoneNumber = e164;
+        normalizedCount++;
+      }
+      const countryCode = PhoneUtils.parse(e164)?.countryCode;
+      if (countryCode) {
+        update.countryCode = countryCode;
+      }
+
+      if (Object.keys(update).length > 0) {
+        await User.updateOne({ _id: primary._id }, { $set: update }).exec();
+      }
+    }
+
+    return { scanned, normalized: normalizedCount, merged, invalid, skipped };
+  },
+};
+
+// Merging would leave two direct chats between the same people, or one
+// between the person and themselves, if the accounts talk to each other or
+// more than one of them has a direct chat with someone.
+async function hasDirectChatConflict(users: PhoneRecord[]): Promise<boolean> {
+  const ids = users.map(user => user._id);
+  const chats = await Chat.find({ type: 'direct', participants: { $in: ids } })
+    .select('participants')
+    .lean<{ participants: Types.ObjectId[] }[]>()
+    .exec();
+
+  const accounts = new Set(ids.map(id => id.toString()));
+  const others = new Set<string>();
+  for (const chat of chats) {
+    const inGroup = chat.participants.filter(id => accounts.has(id.toString()));
+    if (inGroup.length !== 1) return true;
+
+    const other = chat.participants.find(id => !accounts.has(id.toString()))?.toString();
+    if (!other || others.has(other)) return true;
+    others.add(other);
+  }
+  return false;
+}
+
+// Put the primary in place of the duplicate in chats, group admin lists and
+// the sender of its messages, archived ones included
+async function moveChatsAndMessages(from: Types.ObjectId, to: Types.ObjectId): Promise<void> {
+  await Chat.updateMany({ participants: from }, { $addToSet: { participants: to } }).exec();
+  await Chat.updateMany({ participants: from }, { $pull: { participants: from } }).exec();
+  await Chat.updateMany({ 'groupInfo.admins': from }, { $addToSet: { 'groupInfo.admins': to } }).exec();
+  await Chat.updateMany({ 'groupInfo.admins': from }, { $pull: { 'groupInfo.admins': from } }).exec();
+
+  // Keep the duplicate's per-chat settings where the primary has none
+  await Chat.updateMany(
+    { 'participantSettings.userId': { $eq: from, $ne: to } },
+    { $set: { 'participantSettings.$[s].userId': to } },
+    { arrayFilters: [{ 's.userId': from }] }
+  ).exec();
+  await Chat.updateMany(
+    { 'participantSettings.userId': from },
+    { $pull: { participantSettings: { userId: from } } }
+  ).exec();
+
+  await Message.updateMany({ senderId: from }, { $set: { senderId: to } }).exec();
+  for (const model of getArchiveModels()) {
+    await model.updateMany(
+      { 'messages.senderId': from },
+      { $set: { 'messages.$[m].senderId': to } },
+      { arrayFilters: [{ 'm.senderId': from }] }
+    ).exec();
+  }
+}
@@ -0,0 +1,35 @@
+import { AdminConfig, IAdminConfig } from '../models/admin-config';
+import { AdminConfigRevision } from '../models/admin-config-revision';
+import { ADMIN_CONFIG_SECTIONS } from '../../config/admin-config';
+import type { MigrationDefinition } from './index';
+
+// Revisions are written with every configuration change from now on; keep
+// the configuration as it stands today too, so it can be rolled back to.
+export const recordAdminConfigBaseline: MigrationDefinition = {
+  name: '2026-10-record-admin-config-baseline',
+  description: 'Record the current admin configuration as the first revision',
+  up: async () => {
+    const config = await AdminConfig.findOne({ key: 'global' }).lean<IAdminConfig>().exec();
+    if (!config) {
+      return { recorded: false };
+    }
+
+    const version = config.version || 0;
+    if (await AdminConfigRevision.exists({ version }).exec()) {
+      return { recorded: false, version };
+    }
+
+    const sections: Record<string, unknown> = {};
+    for (const section of ADMIN_CONFIG_SECTIONS) {
+      sections[section] = config[section] || {};
+    }
+
+    await AdminConfigRevision.create({
+      version,
+      sections,
+      ...(config.updatedBy && { updatedBy: config.updatedBy }),
+    });
+
+    return { recorded: true, version };
+  },
+};
@@ -0,0 +1,66 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type AdminConfigChangeKind = 'update' | 'rollback' | 'import';
+export type AdminConfigChangeStatus = 'pending' | 'applied' | 'rejected' | 'superseded';
+
+// A configuration change proposed by an admin who may not apply changes on
+// their own, waiting for a second admin. It applies only to the version it
+// was proposed against; if the configuration moved on first, it is
+// superseded and has to be proposed again.
+export interface IAdminConfigChange extends Document {
+  _id: Types.ObjectId;
+  kind: AdminConfigChangeKind;
+  environment?: string; // overlay changed; unset for the base configuration
+  section?: string; // updates
+  values?: Record<string, unknown>; // updates, and imports by section; secret values encrypted
+  targetVersion?: number; // rollbacks
+  diff: {
+    section: string;
+    field: string;
+    from?: unknown; // secret values masked
+    to?: unknown;
+  }[];
+  baseVersion: number;
+  status: AdminConfigChangeStatus;
+  reason?: string;
+  requestedBy: Types.ObjectId;
+  reviewedBy?: Types.ObjectId;
+  reviewNote?: string;
+  reviewedAt?: Date;
+  appliedVersion?: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const adminConfigChangeSchema = new Schema<IAdminConfigChange>({
+  kind: { type: String, enum: ['update', 'rollback', 'import'], required: true },
+  environment: { type: String },
+  section: { type: String },
+  values: { type: Schema.Types.Mixed },
+  targetVersion: { type: Number },
+  diff: [{
+    _id: false,
+    section: { type: String, required: true },
+    field: { type: String, required: true },
+    from: { type: Schema.Types.Mixed },
+    to: { type: Schema.Types.Mixed },
+  }],
+  baseVersion: { type: Number, required: true },
+  status: { type: String, enum: ['pending', 'applied', 'rejected', 'superseded'], default: 'pending' },
+  reason: { type: String },
+  requestedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  reviewedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  reviewNote: { type: String },
+  reviewedAt: { type: Date },
+  appliedVersion: { type: Number },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+adminConfigChangeSchema.index({ status: 1, createdAt: -1 });
+adminConfigChangeSchema.index({ requestedBy: 1, createdAt: -1 });
+
+export const AdminConfigChange = mongoose.models.AdminConfigChange ||
+  mongoose.model<IAdminConfigChange>('AdminConfigChange', adminConfigChangeSchema);
@@ -0,0 +1,29 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Admin configuration overrides for one environment (staging, production,
+// ...), applied field by field on top of the base configuration by the
+// instances running with that CONFIG_ENVIRONMENT. Sections hold only the
+// fields overridden, secrets encrypted, in the same shape as the base.
+export interface IAdminConfigOverlay extends Document {
+  _id: Types.ObjectId;
+  environment: string;
+  sections: Record<string, Record<string, unknown>>;
+  version: number;
+  updatedBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const adminConfigOverlaySchema = new Schema<IAdminConfigOverlay>({
+  environment: { type: String, required: true, unique: true },
+  sections: { type: Schema.Types.Mixed, default: {} },
+  version: { type: Number, default: 1 },
+  updatedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+}, {
+  timestamps: true,
+  versionKey: false,
+  minimize: false,
+});
+
+export const AdminConfigOverlay = mongoose.models.AdminConfigOverlay ||
+  mongoose.model<IAdminConfigOverlay>('AdminConfigOverlay', adminConfigOverlaySchema);
@@ -0,0 +1,37 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// The whole admin configuration, or one environment's overlay, as it stood
+// at one version, written with every change so any earlier version can be
+// inspected or restored. Sections are kept as stored, secrets encrypted.
+export interface IAdminConfigRevision extends Document {
+  _id: Types.ObjectId;
+  environment?: string; // overlay; unset for the base configuration
+  version: number;
+  sections: Record<string, Record<string, unknown>>;
+  section?: string; // the section changed; unset for rollbacks, imports and the baseline
+  updatedBy?: Types.ObjectId;
+  approvedBy?: Types.ObjectId; // second admin, for changes that needed one
+  changeId?: Types.ObjectId;
+  rolledBackTo?: number;
+  createdAt: Date;
+}
+
+const adminConfigRevisionSchema = new Schema<IAdminConfigRevision>({
+  environment: { type: String },
+  version: { type: Number, required: true },
+  sections: { type: Schema.Types.Mixed, required: true },
+  section: { type: String },
+  updatedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  approvedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  changeId: { type: Schema.Types.ObjectId, ref: 'AdminConfigChange' },
+  rolledBackTo: { type: Number },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+adminConfigRevisionSchema.index({ environment: 1, version: -1 }, { unique: true });
+
+export const AdminConfigRevision = mongoose.models.AdminConfigRevision ||
+  mongoose.model<IAdminConfigRevision>('AdminConfigRevision', adminConfigRevisionSchema);
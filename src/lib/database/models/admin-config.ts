@@ -0,0 +1,203 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export interface IServerConfig {
+  corsOrigins: string[];
+  corsMethods: string[];
+  corsHeaders: string[];
+  corsCredentials: boolean;
+  corsStrict?: boolean; // defaults to strict in production when unset
+  maxRequestSize?: number; // bytes, for routes without a specific limit
+}
+
+export type ChallengeProvider = 'none' | 'hcaptcha' | 'turnstile' | 'pow';
+
+export interface ISecurityConfig {
+  challengeProvider?: ChallengeProvider;
+  challengeIpThreshold?: number; // IP score at which a challenge is required; 0 = always
+  challengePowDifficulty?: number; // leading zero bits
+  challengeSiteKey?: string; // hCaptcha / Turnstile; override CHALLENGE_SITE_KEY
+  challengeSecretKey?: string; // and CHALLENGE_SECRET_KEY; stored encrypted
+  // Country rules for sign-in and registration (ISO 3166-1 alpha-2).
+  // Blocked countries are always refused; with an allow list, only those
+  // countries are let in. Requests whose country is unknown pass.
+  blockedCountries?: string[];
+  allowedCountries?: string[];
+}
+
+export interface ICallConfig {
+  // Audio processing defaults sent to clients in the call handshake
+  noiseSuppression?: boolean;
+  echoCancellation?: boolean;
+  autoGainControl?: boolean;
+  allowUserOverride?: boolean; // users may change the above for themselves
+  // Caps written into relayed SDP, in kbps
+  maxAudioBitrate?: number;
+  maxVideoBitrate?: number;
+  // End-to-end encrypted calls: never, when the caller asks, or always
+  e2ee?: 'disabled' | 'optional' | 'required';
+  // Automatic responses to poor call quality, see webrtc/quality. Packet
+  // loss is a fraction, latency a round trip in ms.
+  qualityResponses?: boolean; // unset means on
+  degradePacketLoss?: number; // video is scaled down at or above these
+  degradeLatency?: number;
+  audioOnlyPacketLoss?: number; // video is turned off at or above these
+  audioOnlyLatency?: number;
+  relayAfterReports?: number; // poor reports in a row before relaying through TURN
+  recoverAfterReports?: number; // good reports in a row before stepping back up
+}
+
+export interface IClientConfig {
+  // Oldest app version still allowed per platform ("2.14.0"); older clients
+  // get UPGRADE_REQUIRED
+  minimumVersions?: Partial<Record<'ios' | 'android' | 'web', string>>;
+  updateUrls?: Partial<Record<'ios' | 'android' | 'web', string>>; // store links shown with the error
+}
+
+// Something users agree to (terms, privacy policy, marketing). Bump version
+// when the text changes; mandatory ones must be accepted at the current
+// version before the API can be used.
+export interface IConsentType {
+  key: string; // stable identifier, e.g. "terms"
+  title: string;
+  description?: string;
+  documentUrl?: string;
+  version: number;
+  mandatory: boolean;
+}
+
+export interface IComplianceConfig {
+  consentTypes?: IConsentType[];
+}
+
+// Limits on new accounts until they have earned trust: old enough and with
+// enough reputation (people who saved them as a contact, less people who
+// blocked them)
+export interface IModerationConfig {
+  newAccountLimits?: boolean;
+  trustedAccountAgeDays?: number;
+  trustedMinReputation?: number;
+  maxGroupsPerDay?: number; // groups an untrusted account may create per day
+  maxStrangersPerDay?: number; // non-contacts an untrusted account may message per day
+  allowLinksToStrangers?: boolean;
+}
+
+// Switches for features admins can turn off globally; unset means enabled
+export interface IFeatureConfig {
+  publicFileLinks?: boolean; // users may create public download links for their files
+  publicLinkMaxDays?: number; // longest a public link may stay valid; 0 = no limit
+  guestLinks?: boolean; // group admins may invite guests without an account
+}
+
+// Coalescing of typing and presence updates for slow connections, see
+// realtime/throttle. Windows are in ms and grow with the connection's RTT.
+export interface IRealtimeConfig {
+  throttleSlowConnections?: boolean; // unset means on
+  slowRtt?: number; // ms; heartbeat round trips at or above mark a connection slow
+  slowBacklog?: number; // packets waiting in the send buffer that mark a connection slow
+  typingWindow?: number;
+  presenceWindow?: number;
+}
+
+export interface IAdminConfig extends Document {
+  _id: Types.ObjectId;
+  key: string;
+  server: IServerConfig;
+  security: ISecurityConfig;
+  calls: ICallConfig;
+  clients: IClientConfig;
+  compliance: IComplianceConfig;
+  moderation: IModerationConfig;
+  features: IFeatureConfig;
+  realtime: IRealtimeConfig;
+  version: number;
+  updatedBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const adminConfigSchema = new Schema<IAdminConfig>({
+  key: { type: String, required: true, unique: true, default: 'global' },
+  server: {
+    corsOrigins: [{ type: String }],
+    corsMethods: [{ type: String }],
+    corsHeaders: [{ type: String }],
+    corsCredentials: { type: Boolean, default: true },
+    corsStrict: { type: Boolean },
+    maxRequestSize: { type: Number, min: 1024 },
+  },
+  security: {
+    challengeProvider: { type: String, enum: ['none', 'hcaptcha', 'turnstile', 'pow'] },
+    challengeIpThreshold: { type: Number, min: 0 },
+    challengePowDifficulty: { type: Number, min: 8, max: 32 },
+    challengeSiteKey: { type: String },
+    challengeSecretKey: { type: String },
+    blockedCountries: [{ type: String }],
+    allowedCountries: [{ type: String }],
+  },
+  calls: {
+    noiseSuppression: { type: Boolean },
+    echoCancellation: { type: Boolean },
+    autoGainControl: { type: Boolean },
+    allowUserOverride: { type: Boolean },
+    maxAudioBitrate: { type: Number, min: 6 },
+    maxVideoBitrate: { type: Number, min: 50 },
+    e2ee: { type: String, enum: ['disabled', 'optional', 'required'] },
+    qualityResponses: { type: Boolean },
+    degradePacketLoss: { type: Number, min: 0, max: 1 },
+    degradeLatency: { type: Number, min: 0 },
+    audioOnlyPacketLoss: { type: Number, min: 0, max: 1 },
+    audioOnlyLatency: { type: Number, min: 0 },
+    relayAfterReports: { type: Number, min: 1 },
+    recoverAfterReports: { type: Number, min: 1 },
+  },
+  clients: {
+    minimumVersions: {
+      ios: { type: String },
+      android: { type: String },
+      web: { type: String },
+    },
+    updateUrls: {
+      ios: { type: String },
+      android: { type: String },
+      web: { type: String },
+    },
+  },
+  compliance: {
+    consentTypes: [{
+      _id: false,
+      key: { type: String, required: true },
+      title: { type: String, required: true },
+      description: { type: String },
+      documentUrl: { type: String },
+      version: { type: Number, required: true, min: 1 },
+      mandatory: { type: Boolean, default: false },
+    }],
+  },
+  moderation: {
+    newAccountLimits: { type: Boolean },
+    trustedAccountAgeDays: { type: Number, min: 0 },
+    trustedMinReputation: { type: Number, min: 0 },
+    maxGroupsPerDay: { type: Number, min: 0 },
+    maxStrangersPerDay: { type: Number, min: 0 },
+    allowLinksToStrangers: { type: Boolean },
+  },
+  features: {
+    publicFileLinks: { type: Boolean },
+    publicLinkMaxDays: { type: Number, min: 0 },
+    guestLinks: { type: Boolean },
+  },
+  realtime: {
+    throttleSlowConnections: { type: Boolean },
+    slowRtt: { type: Number, min: 50 },
+    slowBacklog: { type: Number, min: 1 },
+    typingWindow: { type: Number, min: 0 },
+    presenceWindow: { type: Number, min: 0 },
+  },
+  version: { type: Number, default: 1 },
+  updatedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+export const AdminConfig = mongoose.models.AdminConfig || mongoose.model<IAdminConfig>('AdminConfig', adminConfigSchema);
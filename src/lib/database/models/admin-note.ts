@@ -0,0 +1,32 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Internal note about a user, written by staff for staff. Never shown to
+// the user.
+export interface IAdminNote extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  authorId: Types.ObjectId;
+  text: string;
+  caseId?: Types.ObjectId; // case the note belongs to, if any
+  pinned: boolean; // shown first on the user's page
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const adminNoteSchema = new Schema<IAdminNote>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  authorId: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  text: { type: String, required: true, maxlength: 5000 },
+  caseId: { type: Schema.Types.ObjectId, ref: 'SupportCase' },
+  pinned: { type: Boolean, default: false },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+adminNoteSchema.index({ userId: 1, pinned: -1, createdAt: -1 });
+adminNoteSchema.index({ caseId: 1, createdAt: 1 }, { sparse: true });
+
+export const AdminNote = mongoose.models.AdminNote ||
+  mongoose.model<IAdminNote>('AdminNote', adminNoteSchema);
@@ -0,0 +1,36 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A signed-in admin dashboard session, see auth/admin-sso
+export interface IAdminSession extends Document {
+  _id: Types.ObjectId;
+  adminId: Types.ObjectId;
+  method: 'oidc' | 'saml';
+  ip?: string;
+  userAgent?: string;
+  lastSeenAt: Date;
+  expiresAt: Date; // the session policy's maximum age
+  revokedAt?: Date;
+  revokedReason?: 'logout' | 'session_limit' | 'deactivated';
+  createdAt: Date;
+}
+
+const adminSessionSchema = new Schema<IAdminSession>({
+  adminId: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  method: { type: String, enum: ['oidc', 'saml'], required: true },
+  ip: { type: String },
+  userAgent: { type: String },
+  lastSeenAt: { type: Date, required: true },
+  expiresAt: { type: Date, required: true },
+  revokedAt: { type: Date },
+  revokedReason: { type: String, enum: ['logout', 'session_limit', 'deactivated'] },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+adminSessionSchema.index({ adminId: 1, createdAt: -1 });
+adminSessionSchema.index({ expiresAt: 1 }, { expireAfterSeconds: 30 * 24 * 60 * 60 }); // kept a month for the record
+
+export const AdminSession = mongoose.models.AdminSession ||
+  mongoose.model<IAdminSession>('AdminSession', adminSessionSchema);
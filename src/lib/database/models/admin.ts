@@ -1,14 +1,19 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
 
+export type AdminRole = 'super_admin' | 'admin' | 'moderator' | 'support';
+
 export interface IAdmin extends Document {
   _id: Types.ObjectId;
   email: string;
   username: string;
   displayName: string;
   avatar?: string;
-  role: 'super_admin' | 'admin' | 'moderator' | 'support';
+  role: AdminRole;
   permissions: string[];
   isActive: boolean;
+  organizationId?: Types.ObjectId; // org admins only manage their own organization; unset for platform admins
+  passwordHash?: string;
+  passwordSalt?: string;
   lastLogin?: Date;
   loginHistory: {
     ip: string;
@@ -20,6 +25,16 @@ export interface IAdmin extends Document {
   twoFactorEnabled: boolean;
   twoFactorSecret?: string;
   
+  // Set on accounts signed in through the identity provider; their role
+  // follows the IdP groups on every sign-in
+  sso?: {
+    protocol: 'oidc' | 'saml';
+    subject: string; // OIDC sub or SAML NameID
+    groups: string[];
+    provisionedAt: Date; // created by the first SSO sign-in, or linked by email
+    lastLoginAt: Date;
+  };
+  
   createdAt: Date;
   updatedAt: Date;
 }
@@ -36,6 +51,9 @@ const adminSchema = new Schema<IAdmin>({
   },
   permissions: [{ type: String }],
   isActive: { type: Boolean, default: true },
+  organizationId: { type: Schema.Types.ObjectId, ref: 'Organization' },
+  passwordHash: { type: String, select: false },
+  passwordSalt: { type: String, select: false },
   lastLogin: { type: Date },
   loginHistory: [{
     ip: { type: String },
@@ -45,6 +63,17 @@ const adminSchema = new Schema<IAdmin>({
   
   twoFactorEnabled: { type: Boolean, default: false },
   twoFactorSecret: { type: String },
+  
+  sso: {
+    type: {
+      protocol: { type: String, enum: ['oidc', 'saml'], required: true },
+      subject: { type: String, required: true },
+      groups: [{ type: String }],
+      provisionedAt: { type: Date, required: true },
+      lastLoginAt: { type: Date, required: true },
+    },
+    default: undefined,
+  },
 }, {
   timestamps: true,
   versionKey: false,
@@ -55,5 +84,7 @@ adminSchema.index({ email: 1 });
 adminSchema.index({ username: 1 });
 adminSchema.index({ role: 1 });
 adminSchema.index({ isActive: 1 });
+adminSchema.index({ organizationId: 1 }, { sparse: true });
+adminSchema.index({ 'sso.protocol': 1, 'sso.subject': 1 }, { unique: true, sparse: true });
 
 export const Admin = mongoose.models.Admin || mongoose.model<IAdmin>('Admin', adminSchema);
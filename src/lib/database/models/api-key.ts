@@ -0,0 +1,42 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type ApiKeyScope = 'scim:users:read' | 'scim:users:write' | 'scim:groups:read' | 'scim:groups:write';
+
+// A key admins issue to an outside system, e.g. an identity provider
+// provisioning users over SCIM. It acts as no user, only within its scopes.
+export interface IApiKey extends Document {
+  _id: Types.ObjectId;
+  name: string;
+  keyHash: string; // SHA-256 of the key; the key itself is shown once
+  keyPrefix: string; // first characters, so admins can tell keys apart
+  scopes: ApiKeyScope[];
+  createdBy: Types.ObjectId; // admin
+  organizationId?: Types.ObjectId; // SCIM provisions into this organization; unset for the shared pool
+  expiresAt?: Date; // unset never expires
+  revokedAt?: Date;
+  lastUsedAt?: Date;
+  lastUsedIp?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const apiKeySchema = new Schema<IApiKey>({
+  name: { type: String, required: true, trim: true },
+  keyHash: { type: String, required: true, unique: true },
+  keyPrefix: { type: String, required: true },
+  scopes: [{ type: String, enum: ['scim:users:read', 'scim:users:write', 'scim:groups:read', 'scim:groups:write'] }],
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  organizationId: { type: Schema.Types.ObjectId, ref: 'Organization' },
+  expiresAt: { type: Date },
+  revokedAt: { type: Date },
+  lastUsedAt: { type: Date },
+  lastUsedIp: { type: String },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+apiKeySchema.index({ createdAt: -1 });
+
+export const ApiKey = mongoose.models.ApiKey || mongoose.model<IApiKey>('ApiKey', apiKeySchema);
@@ -0,0 +1,49 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type AppealStatus = 'pending' | 'approved' | 'denied';
+
+// A banned user's request to have the ban lifted. One per ban.
+export interface IAppeal extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  actionId?: Types.ObjectId; // unset for bans issued before moderation actions were recorded
+  text: string;
+  status: AppealStatus;
+  decisionNote?: string; // sent to the user with the decision
+  decidedBy?: Types.ObjectId;
+  decidedAt?: Date;
+  history: {
+    status: AppealStatus;
+    by?: Types.ObjectId; // admin; unset for the user's own submission
+    note?: string;
+    at: Date;
+  }[];
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const appealSchema = new Schema<IAppeal>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  actionId: { type: Schema.Types.ObjectId, ref: 'ModerationAction' },
+  text: { type: String, required: true },
+  status: { type: String, enum: ['pending', 'approved', 'denied'], default: 'pending' },
+  decisionNote: { type: String },
+  decidedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  decidedAt: { type: Date },
+  history: [{
+    _id: false,
+    status: { type: String, enum: ['pending', 'approved', 'denied'], required: true },
+    by: { type: Schema.Types.ObjectId, ref: 'Admin' },
+    note: { type: String },
+    at: { type: Date, default: Date.now },
+  }],
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+appealSchema.index({ userId: 1, actionId: 1 }, { unique: true });
+appealSchema.index({ status: 1, createdAt: 1 });
+
+export const Appeal = mongoose.models.Appeal || mongoose.model<IAppeal>('Appeal', appealSchema);
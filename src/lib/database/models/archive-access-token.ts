@@ -0,0 +1,54 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A read-only view of one chat's history, issued by an admin for support or
+// legal review, see compliance/archive-access.
+export interface IArchiveAccessToken extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  tokenHash: string; // SHA-256 of the token; the token itself is shown once
+  tokenPrefix: string; // first characters, so admins can tell tokens apart
+  justification: string; // why access was needed
+  caseReference?: string; // ticket or matter number
+  messagesFrom?: Date; // only messages sent in this window are visible; unset is unbounded
+  messagesTo?: Date;
+  createdBy: Types.ObjectId; // admin
+  organizationId?: Types.ObjectId; // the chat's organization when issued
+  expiresAt: Date;
+  revokedAt?: Date;
+  revokedBy?: Types.ObjectId;
+  revokeReason?: string;
+  lastUsedAt?: Date;
+  lastUsedIp?: string;
+  useCount: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const archiveAccessTokenSchema = new Schema<IArchiveAccessToken>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  tokenHash: { type: String, required: true, unique: true },
+  tokenPrefix: { type: String, required: true },
+  justification: { type: String, required: true },
+  caseReference: { type: String },
+  messagesFrom: { type: Date },
+  messagesTo: { type: Date },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  organizationId: { type: Schema.Types.ObjectId, ref: 'Organization' },
+  expiresAt: { type: Date, required: true },
+  revokedAt: { type: Date },
+  revokedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  revokeReason: { type: String },
+  lastUsedAt: { type: Date },
+  lastUsedIp: { type: String },
+  useCount: { type: Number, default: 0 },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+archiveAccessTokenSchema.index({ chatId: 1, createdAt: -1 });
+archiveAccessTokenSchema.index({ organizationId: 1, createdAt: -1 }, { sparse: true });
+archiveAccessTokenSchema.index({ createdAt: -1 });
+
+export const ArchiveAccessToken = mongoose.models.ArchiveAccessToken || mongoose.model<IArchiveAccessToken>('ArchiveAccessToken', archiveAccessTokenSchema);
@@ -0,0 +1,38 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type AuditTargetType = 'user' | 'message' | 'media' | 'report' | 'legal_hold' | 'admin' | 'api_key' | 'organization' | 'archive_access' | 'call' | 'automation_rule';
+
+// Something an admin did to a user, message, file, report or legal hold,
+// including revealing personal data the admin views redact. Bans and
+// restrictions also have their moderation action; this is the one record of
+// every kind of action, in one place.
+export interface IAuditEntry extends Document {
+  _id: Types.ObjectId;
+  adminId: Types.ObjectId;
+  action: string; // e.g. 'user.ban', 'message.delete'
+  targetType: AuditTargetType;
+  targetId: Types.ObjectId;
+  bulkActionId?: Types.ObjectId; // set when done as part of a bulk action
+  details?: Record<string, unknown>;
+  createdAt: Date;
+}
+
+const auditEntrySchema = new Schema<IAuditEntry>({
+  adminId: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  action: { type: String, required: true },
+  targetType: { type: String, enum: ['user', 'message', 'media', 'report', 'legal_hold', 'admin', 'api_key', 'organization', 'archive_access', 'call', 'automation_rule'], required: true },
+  targetId: { type: Schema.Types.ObjectId, required: true },
+  bulkActionId: { type: Schema.Types.ObjectId, ref: 'BulkAction' },
+  details: { type: Schema.Types.Mixed },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+auditEntrySchema.index({ targetType: 1, targetId: 1, createdAt: -1 });
+auditEntrySchema.index({ adminId: 1, createdAt: -1 });
+auditEntrySchema.index({ bulkActionId: 1 }, { sparse: true });
+
+export const AuditEntry = mongoose.models.AuditEntry ||
+  mongoose.model<IAuditEntry>('AuditEntry', auditEntrySchema);
@@ -0,0 +1,140 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export const AUTOMATION_TRIGGERS = ['message_created', 'member_joined', 'report_filed'] as const;
+export const AUTOMATION_ACTIONS = ['send_message', 'add_label', 'notify_webhook', 'apply_moderation'] as const;
+
+export type AutomationTrigger = typeof AUTOMATION_TRIGGERS[number];
+export type AutomationActionType = typeof AUTOMATION_ACTIONS[number];
+
+// Facts about an event conditions can test, see integrations/automation
+export const AUTOMATION_CONDITION_FIELDS = [
+  'chat.type', 'chat.memberCount',
+  'user.isVerified', 'user.accountAgeDays', 'user.countryCode',
+  'message.type', 'message.length', 'message.hasMedia',
+  'report.type', 'report.priority',
+] as const;
+
+export type AutomationConditionField = typeof AUTOMATION_CONDITION_FIELDS[number];
+
+export interface IAutomationCondition {
+  field: AutomationConditionField;
+  operator: 'equals' | 'not_equals' | 'in' | 'not_in' | 'gt' | 'lt';
+  value: string | number | boolean | (string | number)[];
+}
+
+export interface IAutomationAction {
+  type: AutomationActionType;
+  text?: string; // send_message
+  chatId?: Types.ObjectId; // send_message; defaults to the chat the event happened in
+  label?: string; // add_label, on the message or report
+  url?: string; // notify_webhook
+  moderation?: { // apply_moderation, to the user who sent, joined or was reported
+    action: 'restrict' | 'ban';
+    mode?: 'shadow' | 'review';
+    durationHours?: number; // permanent when unset
+    reason: string;
+  };
+}
+
+// An admin-defined "if this then that" rule. When its trigger fires and
+// every condition holds, its actions run in order, server-side.
+export interface IAutomationRule extends Document {
+  _id: Types.ObjectId;
+  name: string;
+  description?: string;
+  enabled: boolean;
+  trigger: {
+    event: AutomationTrigger;
+    chatId?: Types.ObjectId; // message_created and member_joined in one chat only
+    pattern?: { // message_created
+      type: 'contains' | 'regex';
+      value: string;
+      caseSensitive: boolean;
+    };
+    reportTypes?: string[]; // report_filed
+  };
+  conditions: IAutomationCondition[];
+  actions: IAutomationAction[];
+  signingSecret: string; // HMAC key for notify_webhook requests
+  stats: {
+    matched: number;
+    executed: number; // every action ran
+    failed: number;
+    throttled: number; // matched past the rule's rate limit
+    consecutiveFailures: number;
+    lastMatchedAt?: Date;
+    lastFailedAt?: Date;
+    lastError?: string;
+  };
+  disabledReason?: string; // set when turned off after failing repeatedly
+  createdBy: Types.ObjectId;
+  updatedBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const automationRuleSchema = new Schema<IAutomationRule>({
+  name: { type: String, required: true, trim: true },
+  description: { type: String, trim: true },
+  enabled: { type: Boolean, default: true },
+  trigger: {
+    event: { type: String, enum: AUTOMATION_TRIGGERS, required: true },
+    chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+    pattern: {
+      type: {
+        type: { type: String, enum: ['contains', 'regex'], required: true },
+        value: { type: String, required: true },
+        caseSensitive: { type: Boolean, default: false },
+      },
+      default: undefined,
+    },
+    reportTypes: { type: [String], default: undefined },
+  },
+  conditions: [{
+    _id: false,
+    field: { type: String, enum: AUTOMATION_CONDITION_FIELDS, required: true },
+    operator: { type: String, enum: ['equals', 'not_equals', 'in', 'not_in', 'gt', 'lt'], required: true },
+    value: { type: Schema.Types.Mixed, required: true },
+  }],
+  actions: [{
+    _id: false,
+    type: { type: String, enum: AUTOMATION_ACTIONS, required: true },
+    text: { type: String },
+    chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+    label: { type: String },
+    url: { type: String },
+    moderation: {
+      type: {
+        action: { type: String, enum: ['restrict', 'ban'], required: true },
+        mode: { type: String, enum: ['shadow', 'review'] },
+        durationHours: { type: Number },
+        reason: { type: String, required: true },
+      },
+      default: undefined,
+    },
+  }],
+  signingSecret: { type: String, required: true },
+  stats: {
+    matched: { type: Number, default: 0 },
+    executed: { type: Number, default: 0 },
+    failed: { type: Number, default: 0 },
+    throttled: { type: Number, default: 0 },
+    consecutiveFailures: { type: Number, default: 0 },
+    lastMatchedAt: { type: Date },
+    lastFailedAt: { type: Date },
+    lastError: { type: String },
+  },
+  disabledReason: { type: String },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  updatedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+automationRuleSchema.index({ 'trigger.event': 1, enabled: 1 });
+automationRuleSchema.index({ createdAt: -1 });
+
+export const AutomationRule = mongoose.models.AutomationRule ||
+  mongoose.model<IAutomationRule>('AutomationRule', automationRuleSchema);
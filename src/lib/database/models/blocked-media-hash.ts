@@ -0,0 +1,36 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Perceptual hash of known abusive content. Uploads within a few bits of it
+// are refused.
+export interface IBlockedMediaHash extends Document {
+  _id: Types.ObjectId;
+  hash: string; // 64-bit pHash, 16 hex characters
+  reason: string;
+  category?: 'spam' | 'abuse' | 'csam' | 'violence' | 'other';
+  sourceMediaId?: Types.ObjectId; // upload the hash was taken from
+  addedBy: Types.ObjectId;
+  matchCount: number;
+  lastMatchedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const blockedMediaHashSchema = new Schema<IBlockedMediaHash>({
+  hash: { type: String, required: true, match: /^[0-9a-f]{16}$/ },
+  reason: { type: String, required: true },
+  category: { type: String, enum: ['spam', 'abuse', 'csam', 'violence', 'other'] },
+  sourceMediaId: { type: Schema.Types.ObjectId, ref: 'Media' },
+  addedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  matchCount: { type: Number, default: 0 },
+  lastMatchedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+blockedMediaHashSchema.index({ hash: 1 }, { unique: true });
+blockedMediaHashSchema.index({ createdAt: -1 });
+
+export const BlockedMediaHash = mongoose.models.BlockedMediaHash ||
+  mongoose.model<IBlockedMediaHash>('BlockedMediaHash', blockedMediaHashSchema);
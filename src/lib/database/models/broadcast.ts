@@ -0,0 +1,77 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type BroadcastStatus = 'live' | 'ended';
+
+// chat: members of the call's chat; link: anyone signed in with the watch
+// link
+export type BroadcastAudience = 'chat' | 'link';
+
+// An external platform the stream is pushed to over RTMP. The stream key
+// is handed to the media server and never stored.
+export interface IBroadcastEgress {
+  url: string; // ingest URL without the stream key
+  status: 'active' | 'failed';
+  error?: string;
+}
+
+// A call broadcast one way to viewers. The host publishes the composited
+// call to the media server; viewers play it back and never send media,
+// see webrtc/broadcasts.
+export interface IBroadcast extends Document {
+  _id: Types.ObjectId;
+  callId: string;
+  chatId?: Types.ObjectId;
+  hostId: Types.ObjectId;
+  title?: string;
+  audience: BroadcastAudience;
+  watchCode: string;
+  status: BroadcastStatus;
+  streamId: string; // on the media server
+  ingestUrl: string; // WHIP, for the host only
+  playbackUrl: string; // WHEP or HLS, for viewers
+  egress: IBroadcastEgress[];
+  viewerCount: number; // last counted
+  peakViewers: number;
+  totalViewers: number; // joins, repeat viewers included
+  startedAt: Date;
+  endedAt?: Date;
+  endedBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const broadcastSchema = new Schema<IBroadcast>({
+  callId: { type: String, required: true },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+  hostId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  title: { type: String, maxlength: 200 },
+  audience: { type: String, enum: ['chat', 'link'], default: 'chat' },
+  watchCode: { type: String, required: true, unique: true },
+  status: { type: String, enum: ['live', 'ended'], default: 'live' },
+  streamId: { type: String, required: true },
+  ingestUrl: { type: String, required: true },
+  playbackUrl: { type: String, required: true },
+  egress: [{
+    _id: false,
+    url: { type: String, required: true },
+    status: { type: String, enum: ['active', 'failed'], default: 'active' },
+    error: { type: String },
+  }],
+  viewerCount: { type: Number, default: 0 },
+  peakViewers: { type: Number, default: 0 },
+  totalViewers: { type: Number, default: 0 },
+  startedAt: { type: Date, default: Date.now },
+  endedAt: { type: Date },
+  endedBy: { type: Schema.Types.ObjectId, ref: 'User' },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+broadcastSchema.index({ status: 1 });
+
+// At most one live broadcast per call
+broadcastSchema.index({ callId: 1 }, { unique: true, partialFilterExpression: { status: 'live' } });
+
+export const Broadcast = mongoose.models.Broadcast || mongoose.model<IBroadcast>('Broadcast', broadcastSchema);
@@ -0,0 +1,87 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+import { RestrictionMode } from './moderation-action';
+
+export type BulkActionKind = 'ban_users' | 'restrict_users' | 'delete_messages' | 'delete_files' | 'resolve_reports';
+export type BulkActionStatus = 'queued' | 'processing' | 'completed' | 'failed';
+export type BulkActionItemStatus = 'pending' | 'succeeded' | 'failed' | 'skipped';
+
+// A moderator action applied to many users, messages, files or reports,
+// worked through in the background with a result per item
+export interface IBulkAction extends Document {
+  _id: Types.ObjectId;
+  kind: BulkActionKind;
+  status: BulkActionStatus;
+  params: {
+    reason?: string; // bans and restrictions; shown to the user for bans
+    note?: string; // internal
+    durationHours?: number; // bans and restrictions; permanent when unset
+    mode?: RestrictionMode;
+    reportId?: Types.ObjectId; // report that prompted a ban or restriction
+    resolution?: string; // reports
+    reportStatus?: 'resolved' | 'dismissed';
+  };
+  items: {
+    targetId: Types.ObjectId;
+    status: BulkActionItemStatus;
+    error?: string;
+    processedAt?: Date;
+  }[];
+  progress: {
+    total: number;
+    succeeded: number;
+    failed: number;
+    skipped: number;
+  };
+  requestedBy: Types.ObjectId;
+  error?: string;
+  startedAt?: Date;
+  completedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const bulkActionSchema = new Schema<IBulkAction>({
+  kind: {
+    type: String,
+    enum: ['ban_users', 'restrict_users', 'delete_messages', 'delete_files', 'resolve_reports'],
+    required: true,
+  },
+  status: { type: String, enum: ['queued', 'processing', 'completed', 'failed'], default: 'queued' },
+  params: {
+    reason: { type: String },
+    note: { type: String },
+    durationHours: { type: Number },
+    mode: { type: String, enum: ['shadow', 'review'] },
+    reportId: { type: Schema.Types.ObjectId, ref: 'Report' },
+    resolution: { type: String },
+    reportStatus: { type: String, enum: ['resolved', 'dismissed'] },
+  },
+  items: [{
+    _id: false,
+    targetId: { type: Schema.Types.ObjectId, required: true },
+    status: { type: String, enum: ['pending', 'succeeded', 'failed', 'skipped'], default: 'pending' },
+    error: { type: String },
+    processedAt: { type: Date },
+  }],
+  progress: {
+    total: { type: Number, default: 0 },
+    succeeded: { type: Number, default: 0 },
+    failed: { type: Number, default: 0 },
+    skipped: { type: Number, default: 0 },
+  },
+  requestedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  error: { type: String },
+  startedAt: { type: Date },
+  completedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+bulkActionSchema.index({ status: 1, createdAt: 1 });
+bulkActionSchema.index({ requestedBy: 1, createdAt: -1 });
+bulkActionSchema.index({ createdAt: -1 });
+
+export const BulkAction = mongoose.models.BulkAction ||
+  mongoose.model<IBulkAction>('BulkAction', bulkActionSchema);
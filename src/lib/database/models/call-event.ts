@@ -0,0 +1,50 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export const CALL_EVENT_TYPES = [
+  'initiated',
+  'ringing',
+  'answered',
+  'rejected',
+  'participant_joined',
+  'participant_left',
+  'media_changed', // mute, hold or raised hand
+  'controls_changed', // lock or host
+  'keys_rotated',
+  'quality_alert',
+  'quality_action', // a participant was told to degrade, relay or recover
+  'broadcast_started',
+  'broadcast_ended',
+  'ended',
+] as const;
+
+export type CallEventType = typeof CALL_EVENT_TYPES[number];
+
+// One step in a call's lifecycle. The log is append-only; a call's timeline
+// is its events in order, and live session state is rebuilt from it after a
+// restart.
+export interface ICallEvent extends Document {
+  _id: Types.ObjectId;
+  callId: string;
+  type: CallEventType;
+  userId?: Types.ObjectId; // who the event is about
+  actorId?: Types.ObjectId; // who caused it, when someone else
+  data?: Record<string, unknown>;
+  createdAt: Date;
+}
+
+const callEventSchema = new Schema<ICallEvent>({
+  callId: { type: String, required: true },
+  type: { type: String, enum: CALL_EVENT_TYPES, required: true },
+  userId: { type: Schema.Types.ObjectId, ref: 'User' },
+  actorId: { type: Schema.Types.ObjectId, ref: 'User' },
+  data: { type: Schema.Types.Mixed },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+callEventSchema.index({ callId: 1, createdAt: 1, _id: 1 });
+callEventSchema.index({ createdAt: 1 }, { expireAfterSeconds: 90 * 24 * 60 * 60 }); // keep 90 days
+
+export const CallEvent = mongoose.models.CallEvent || mongoose.model<ICallEvent>('CallEvent', callEventSchema);
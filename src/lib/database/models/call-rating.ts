@@ -0,0 +1,64 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export const CALL_ISSUE_TAGS = ['echo', 'lag', 'dropped', 'choppy_audio', 'frozen_video', 'noise'] as const;
+
+export type CallIssueTag = typeof CALL_ISSUE_TAGS[number];
+
+// What the server measured for the rater during the call, see webrtc/quality
+export interface ICallRatingMetrics {
+  reports: number;
+  packetLoss: number; // mean fraction lost
+  latency: number; // mean round trip, ms
+  worstLevel: 'full' | 'reduced' | 'audio_only';
+  relayed: boolean; // told to relay through TURN
+}
+
+// A participant's rating of a call once it ended, with the client it was
+// made on so admins can break quality down by region and app version
+export interface ICallRating extends Document {
+  _id: Types.ObjectId;
+  callId: string;
+  userId: Types.ObjectId;
+  rating: number; // 1-5
+  issues: CallIssueTag[];
+  feedback?: string;
+  callType: 'voice' | 'video';
+  region: string;
+  platform: string;
+  appVersion?: string;
+  metrics?: ICallRatingMetrics;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const callRatingSchema = new Schema<ICallRating>({
+  callId: { type: String, required: true },
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  rating: { type: Number, required: true, min: 1, max: 5 },
+  issues: [{ type: String, enum: CALL_ISSUE_TAGS }],
+  feedback: { type: String, maxlength: 500 },
+  callType: { type: String, enum: ['voice', 'video'], required: true },
+  region: { type: String, default: 'unknown' },
+  platform: { type: String, default: 'unknown' },
+  appVersion: { type: String },
+  metrics: {
+    type: {
+      reports: { type: Number },
+      packetLoss: { type: Number },
+      latency: { type: Number },
+      worstLevel: { type: String, enum: ['full', 'reduced', 'audio_only'] },
+      relayed: { type: Boolean },
+    },
+    default: undefined,
+  },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+callRatingSchema.index({ callId: 1, userId: 1 }, { unique: true });
+callRatingSchema.index({ createdAt: -1 });
+
+export const CallRating = mongoose.models.CallRating ||
+  mongoose.model<ICallRating>('CallRating', callRatingSchema);
@@ -1,4 +1,9 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
+import { ICallRatingMetrics } from './call-rating';
+
+// Why a call ended. 'orphaned' marks calls the server lost track of, e.g.
+// ones still open in the database after a restart.
+export type CallEndReason = 'normal' | 'busy' | 'missed' | 'rejected' | 'orphaned';
 
 export interface ICall extends Document {
   _id: Types.ObjectId;
@@ -10,9 +15,18 @@ export interface ICall extends Document {
   startTime: Date;
   endTime?: Date;
   duration?: number; // in seconds
+  endReason?: CallEndReason;
   chatId?: Types.ObjectId;
   isGroupCall: boolean;
   
+  // Host controls
+  host?: Types.ObjectId; // defaults to the initiator
+  locked: boolean; // no new participants can join
+  listenOnly?: boolean; // only the host and participants given the floor can talk
+  removedParticipants: Types.ObjectId[];
+  
+  encrypted: boolean; // media is end-to-end encrypted (SFrame)
+  
   // WebRTC Data
   signaling: {
     offers: {
@@ -38,6 +52,7 @@ export interface ICall extends Document {
     rating: number; // 1-5
     feedback?: string;
   }[];
+  qualityStats?: (ICallRatingMetrics & { userId: Types.ObjectId })[]; // measured, see webrtc/quality
   
   createdAt: Date;
   updatedAt: Date;
@@ -56,9 +71,16 @@ const callSchema = new Schema<ICall>({
   startTime: { type: Date, default: Date.now },
   endTime: { type: Date },
   duration: { type: Number },
+  endReason: { type: String, enum: ['normal', 'busy', 'missed', 'rejected', 'orphaned'] },
   chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
   isGroupCall: { type: Boolean, default: false },
   
+  host: { type: Schema.Types.ObjectId, ref: 'User' },
+  locked: { type: Boolean, default: false },
+  listenOnly: { type: Boolean, default: false },
+  removedParticipants: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  encrypted: { type: Boolean, default: false },
+  
   signaling: {
     offers: [{
       userId: { type: Schema.Types.ObjectId, ref: 'User' },
@@ -82,6 +104,15 @@ const callSchema = new Schema<ICall>({
     rating: { type: Number, min: 1, max: 5 },
     feedback: { type: String },
   }],
+  qualityStats: [{
+    _id: false,
+    userId: { type: Schema.Types.ObjectId, ref: 'User' },
+    reports: { type: Number },
+    packetLoss: { type: Number },
+    latency: { type: Number },
+    worstLevel: { type: String, enum: ['full', 'reduced', 'audio_only'] },
+    relayed: { type: Boolean },
+  }],
 }, {
   timestamps: true,
   versionKey: false,
@@ -93,5 +124,9 @@ callSchema.index({ initiator: 1 });
 callSchema.index({ participants: 1 });
 callSchema.index({ startTime: -1 });
 callSchema.index({ status: 1 });
+// Admin call list filters, newest first
+callSchema.index({ status: 1, startTime: -1 });
+callSchema.index({ type: 1, startTime: -1 });
+callSchema.index({ endReason: 1, startTime: -1 });
 
 export const Call = mongoose.models.Call || mongoose.model<ICall>('Call', callSchema);
@@ -0,0 +1,27 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Where a change stream consumer left off, so it can resume after a restart
+// without missing or replaying events
+export interface IChangeStreamCheckpoint extends Document {
+  _id: Types.ObjectId;
+  name: string; // consumer and collection, e.g. "cdc:messages"
+  resumeToken: Record<string, unknown>;
+  eventCount: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const changeStreamCheckpointSchema = new Schema<IChangeStreamCheckpoint>({
+  name: { type: String, required: true },
+  resumeToken: { type: Schema.Types.Mixed, required: true },
+  eventCount: { type: Number, default: 0 },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+changeStreamCheckpointSchema.index({ name: 1 }, { unique: true });
+
+export const ChangeStreamCheckpoint = mongoose.models.ChangeStreamCheckpoint ||
+  mongoose.model<IChangeStreamCheckpoint>('ChangeStreamCheckpoint', changeStreamCheckpointSchema);
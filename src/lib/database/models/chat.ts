@@ -1,14 +1,32 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
+import { DATA_REGIONS, DataRegion } from './user';
 
 export interface IChat extends Document {
   _id: Types.ObjectId;
   participants: Types.ObjectId[];
   type: 'direct' | 'group';
   lastMessage?: Types.ObjectId;
+  messageSeq: number; // seq of the newest message, see MessageRepository.create
   lastActivity: Date;
   isArchived: boolean;
   isPinned: boolean;
   mutedUntil?: Date;
+  disabledCommands: string[]; // built-in slash commands turned off in this chat
+  version: number; // bumped by chat-wide settings changes, for optimistic concurrency
+  messageStorage: 'documents' | 'bucketed'; // bucketed packs old messages together, for very busy chats
+  dataRegion?: DataRegion; // database holding the chat's message buckets; the main one when unset
+  organizationId?: Types.ObjectId; // tenant of the group's members; unset in the shared pool
+  activeCall?: { // group call in progress, shown to members not in it
+    callId: string;
+    callType: 'voice' | 'video';
+    startedBy: Types.ObjectId;
+    startedAt: Date;
+    participants: Types.ObjectId[]; // members currently in the call
+  };
+  scim?: { // set on groups whose membership the identity provider manages over SCIM
+    externalId?: string;
+    provisionedAt: Date;
+  };
   createdAt: Date;
   updatedAt: Date;
   
@@ -22,6 +40,8 @@ export interface IChat extends Document {
       whoCanSendMessages: 'everyone' | 'admins';
       whoCanEditGroupInfo: 'everyone' | 'admins';
       whoCanAddMembers: 'everyone' | 'admins';
+      messageApproval: boolean; // moderated group: members' messages wait for an admin to approve them
+      pseudonymousMembers: boolean; // members' messages show under per-group pseudonyms, except to admins
     };
   };
   
@@ -29,8 +49,19 @@ export interface IChat extends Document {
   participantSettings: {
     userId: Types.ObjectId;
     nickname?: string;
-    customNotifications: boolean;
-    wallpaper?: string;
+    customNotifications: boolean; // use the sound/vibration below instead of the user's defaults
+    notificationSound?: string;
+    vibration?: 'default' | 'short' | 'long' | 'heartbeat' | 'none';
+    wallpaper?: string | null; // name of a built-in wallpaper
+    wallpaperMedia?: Types.ObjectId | null; // image the user uploaded, takes precedence over wallpaper
+    themeColor?: string | null; // #rrggbb accent for bubbles and controls
+    readPosition?: { // newest message the user has read, on any device; only moves forward
+      messageId: Types.ObjectId;
+      seq?: number;
+      sentAt: Date;
+      readAt: Date;
+    };
+    version?: number; // bumped by changes to these settings, like the chat's version
   }[];
 }
 
@@ -38,10 +69,27 @@ const chatSchema = new Schema<IChat>({
   participants: [{ type: Schema.Types.ObjectId, ref: 'User', required: true }],
   type: { type: String, enum: ['direct', 'group'], required: true },
   lastMessage: { type: Schema.Types.ObjectId, ref: 'Message' },
+  messageSeq: { type: Number, default: 0 },
   lastActivity: { type: Date, default: Date.now },
   isArchived: { type: Boolean, default: false },
   isPinned: { type: Boolean, default: false },
   mutedUntil: { type: Date },
+  disabledCommands: [{ type: String }],
+  version: { type: Number, default: 0 },
+  messageStorage: { type: String, enum: ['documents', 'bucketed'], default: 'documents' },
+  dataRegion: { type: String, enum: DATA_REGIONS },
+  organizationId: { type: Schema.Types.ObjectId, ref: 'Organization' },
+  activeCall: {
+    callId: { type: String },
+    callType: { type: String, enum: ['voice', 'video'] },
+    startedBy: { type: Schema.Types.ObjectId, ref: 'User' },
+    startedAt: { type: Date },
+    participants: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  },
+  scim: {
+    externalId: { type: String },
+    provisionedAt: { type: Date },
+  },
   
   groupInfo: {
     name: { type: String },
@@ -52,6 +100,8 @@ const chatSchema = new Schema<IChat>({
       whoCanSendMessages: { type: String, enum: ['everyone', 'admins'], default: 'everyone' },
       whoCanEditGroupInfo: { type: String, enum: ['everyone', 'admins'], default: 'admins' },
       whoCanAddMembers: { type: String, enum: ['everyone', 'admins'], default: 'admins' },
+      messageApproval: { type: Boolean, default: false },
+      pseudonymousMembers: { type: Boolean, default: false },
     },
   },
   
@@ -59,7 +109,18 @@ const chatSchema = new Schema<IChat>({
     userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
     nickname: { type: String },
     customNotifications: { type: Boolean, default: false },
+    notificationSound: { type: String },
+    vibration: { type: String, enum: ['default', 'short', 'long', 'heartbeat', 'none'] },
     wallpaper: { type: String },
+    wallpaperMedia: { type: Schema.Types.ObjectId, ref: 'Media' },
+    themeColor: { type: String },
+    readPosition: {
+      messageId: { type: Schema.Types.ObjectId, ref: 'Message' },
+      seq: { type: Number },
+      sentAt: { type: Date },
+      readAt: { type: Date },
+    },
+    version: { type: Number, default: 0 },
   }],
 }, {
   timestamps: true,
@@ -70,6 +131,10 @@ const chatSchema = new Schema<IChat>({
 chatSchema.index({ participants: 1 });
 chatSchema.index({ lastActivity: -1 });
 chatSchema.index({ type: 1 });
+chatSchema.index({ organizationId: 1, type: 1 }, { sparse: true });
 chatSchema.index({ 'groupInfo.name': 'text' });
+chatSchema.index({ 'activeCall.callId': 1 }, { sparse: true });
+chatSchema.index({ 'scim.provisionedAt': 1 }, { sparse: true });
+chatSchema.index({ 'scim.externalId': 1 }, { sparse: true });
 
 export const Chat = mongoose.models.Chat || mongoose.model<IChat>('Chat', chatSchema);
@@ -0,0 +1,85 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type EmailGatewayKind = 'group' | 'user';
+
+// An inbound address (<localPart>@EMAIL_GATEWAY_DOMAIN). Group addresses post
+// into the group; user addresses open a direct chat per external sender.
+export interface IEmailGateway extends Document {
+  _id: Types.ObjectId;
+  localPart: string;
+  kind: EmailGatewayKind;
+  chatId?: Types.ObjectId; // group gateways
+  userId?: Types.ObjectId; // user gateways
+  enabled: boolean;
+  allowedSenders: string[]; // lowercase addresses or @domain suffixes; empty accepts anyone
+  createdBy: Types.ObjectId;
+  stats: {
+    received: number;
+    sent: number;
+    lastReceivedAt?: Date;
+    lastSentAt?: Date;
+  };
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+// Local message <-> RFC 5322 Message-ID mapping, used for dedupe and to
+// thread replies in both directions
+export interface IEmailThreadMessage extends Document {
+  _id: Types.ObjectId;
+  gatewayId: Types.ObjectId;
+  messageId: Types.ObjectId;
+  chatId: Types.ObjectId;
+  emailMessageId: string;
+  references: string[];
+  subject: string;
+  externalAddress: string; // the outside party of the conversation
+  direction: 'inbound' | 'outbound';
+  createdAt: Date;
+}
+
+const emailGatewaySchema = new Schema<IEmailGateway>({
+  localPart: { type: String, required: true, lowercase: true, trim: true },
+  kind: { type: String, enum: ['group', 'user'], required: true },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+  userId: { type: Schema.Types.ObjectId, ref: 'User' },
+  enabled: { type: Boolean, default: true },
+  allowedSenders: [{ type: String, lowercase: true, trim: true }],
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  stats: {
+    received: { type: Number, default: 0 },
+    sent: { type: Number, default: 0 },
+    lastReceivedAt: { type: Date },
+    lastSentAt: { type: Date },
+  },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+const emailThreadMessageSchema = new Schema<IEmailThreadMessage>({
+  gatewayId: { type: Schema.Types.ObjectId, ref: 'EmailGateway', required: true },
+  messageId: { type: Schema.Types.ObjectId, ref: 'Message', required: true },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  emailMessageId: { type: String, required: true },
+  references: [{ type: String }],
+  subject: { type: String, default: '' },
+  externalAddress: { type: String, required: true, lowercase: true },
+  direction: { type: String, enum: ['inbound', 'outbound'], required: true },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+emailGatewaySchema.index({ localPart: 1 }, { unique: true });
+emailGatewaySchema.index({ chatId: 1 }, { unique: true, sparse: true });
+emailGatewaySchema.index({ userId: 1 }, { unique: true, sparse: true });
+emailThreadMessageSchema.index({ gatewayId: 1, emailMessageId: 1 }, { unique: true });
+emailThreadMessageSchema.index({ messageId: 1 });
+emailThreadMessageSchema.index({ chatId: 1, createdAt: -1 });
+
+export const EmailGateway = mongoose.models.EmailGateway ||
+  mongoose.model<IEmailGateway>('EmailGateway', emailGatewaySchema);
+export const EmailThreadMessage = mongoose.models.EmailThreadMessage ||
+  mongoose.model<IEmailThreadMessage>('EmailThreadMessage', emailThreadMessageSchema);
@@ -0,0 +1,79 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type FederationProtocol = 'matrix' | 'xmpp';
+export type FederationDirection = 'both' | 'outbound' | 'inbound';
+
+// A local chat bridged to a Matrix room or XMPP MUC
+export interface IFederatedRoom extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  protocol: FederationProtocol;
+  remoteRoomId: string; // Matrix room id (!abc:server) or MUC JID (room@muc.server)
+  direction: FederationDirection;
+  enabled: boolean;
+  createdBy: Types.ObjectId;
+  stats: {
+    messagesOut: number;
+    messagesIn: number;
+    lastOutboundAt?: Date;
+    lastInboundAt?: Date;
+    lastError?: string;
+    lastErrorAt?: Date;
+  };
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+// Local message <-> remote event mapping, used for dedupe and echo suppression
+export interface IFederatedMessage extends Document {
+  _id: Types.ObjectId;
+  roomId: Types.ObjectId;
+  messageId: Types.ObjectId;
+  protocol: FederationProtocol;
+  remoteEventId: string;
+  direction: 'inbound' | 'outbound';
+  createdAt: Date;
+}
+
+const federatedRoomSchema = new Schema<IFederatedRoom>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  protocol: { type: String, enum: ['matrix', 'xmpp'], required: true },
+  remoteRoomId: { type: String, required: true },
+  direction: { type: String, enum: ['both', 'outbound', 'inbound'], default: 'both' },
+  enabled: { type: Boolean, default: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  stats: {
+    messagesOut: { type: Number, default: 0 },
+    messagesIn: { type: Number, default: 0 },
+    lastOutboundAt: { type: Date },
+    lastInboundAt: { type: Date },
+    lastError: { type: String },
+    lastErrorAt: { type: Date },
+  },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+const federatedMessageSchema = new Schema<IFederatedMessage>({
+  roomId: { type: Schema.Types.ObjectId, ref: 'FederatedRoom', required: true },
+  messageId: { type: Schema.Types.ObjectId, ref: 'Message', required: true },
+  protocol: { type: String, enum: ['matrix', 'xmpp'], required: true },
+  remoteEventId: { type: String, required: true },
+  direction: { type: String, enum: ['inbound', 'outbound'], required: true },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+federatedRoomSchema.index({ chatId: 1, protocol: 1 }, { unique: true });
+federatedRoomSchema.index({ protocol: 1, remoteRoomId: 1 }, { unique: true });
+federatedMessageSchema.index({ protocol: 1, remoteEventId: 1 }, { unique: true });
+federatedMessageSchema.index({ messageId: 1 });
+federatedMessageSchema.index({ createdAt: 1 }, { expireAfterSeconds: 90 * 24 * 60 * 60 });
+
+export const FederatedRoom = mongoose.models.FederatedRoom ||
+  mongoose.model<IFederatedRoom>('FederatedRoom', federatedRoomSchema);
+export const FederatedMessage = mongoose.models.FederatedMessage ||
+  mongoose.model<IFederatedMessage>('FederatedMessage', federatedMessageSchema);
@@ -0,0 +1,75 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Engagement figures for one group over its last windowDays, recomputed in
+// the background, see monitoring/group-analytics. Withheld and deleted
+// messages are not counted.
+export interface IGroupAnalytics extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  windowDays: number;
+  windowStart: Date;
+  totals: {
+    messages: number;
+    reactions: number;
+    activeMembers: number; // members who sent at least one message
+  };
+  topMessages: { // most reacted, most first
+    messageId: Types.ObjectId;
+    senderId: Types.ObjectId;
+    type: string;
+    preview: string;
+    reactions: number;
+    emoji: { emoji: string; count: number }[]; // most used first
+    sentAt: Date;
+  }[];
+  topMembers: { // most messages sent, most first
+    userId: Types.ObjectId;
+    messages: number;
+    reactionsGiven: number;
+    reactionsReceived: number;
+  }[];
+  daily: { day: string; messages: number; reactions: number }[]; // UTC days, oldest first, days without messages omitted
+  hourly: number[]; // messages per UTC hour of day, 24 entries
+  computedAt: Date;
+}
+
+const groupAnalyticsSchema = new Schema<IGroupAnalytics>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true, unique: true },
+  windowDays: { type: Number, required: true },
+  windowStart: { type: Date, required: true },
+  totals: {
+    messages: { type: Number, default: 0 },
+    reactions: { type: Number, default: 0 },
+    activeMembers: { type: Number, default: 0 },
+  },
+  topMessages: [{
+    _id: false,
+    messageId: { type: Schema.Types.ObjectId, ref: 'Message' },
+    senderId: { type: Schema.Types.ObjectId, ref: 'User' },
+    type: { type: String },
+    preview: { type: String },
+    reactions: { type: Number },
+    emoji: [{ _id: false, emoji: { type: String }, count: { type: Number } }],
+    sentAt: { type: Date },
+  }],
+  topMembers: [{
+    _id: false,
+    userId: { type: Schema.Types.ObjectId, ref: 'User' },
+    messages: { type: Number },
+    reactionsGiven: { type: Number },
+    reactionsReceived: { type: Number },
+  }],
+  daily: [{
+    _id: false,
+    day: { type: String },
+    messages: { type: Number },
+    reactions: { type: Number },
+  }],
+  hourly: [{ type: Number }],
+  computedAt: { type: Date, default: Date.now },
+}, {
+  versionKey: false,
+});
+
+export const GroupAnalytics = mongoose.models.GroupAnalytics ||
+  mongoose.model<IGroupAnalytics>('GroupAnalytics', groupAnalyticsSchema);
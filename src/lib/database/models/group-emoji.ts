@@ -0,0 +1,40 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A custom emoji of a group, used as :shortcode: in its messages and as a
+// reaction. Removed emoji are kept so messages that used them still resolve.
+export interface IGroupEmoji extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  shortcode: string; // without the colons
+  media: Types.ObjectId;
+  createdBy: Types.ObjectId;
+  status: 'active' | 'removed';
+  removedBy?: Types.ObjectId; // group admin (a user) or platform admin
+  removedByModerator?: boolean;
+  removedReason?: string;
+  removedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const groupEmojiSchema = new Schema<IGroupEmoji>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  shortcode: { type: String, required: true },
+  media: { type: Schema.Types.ObjectId, ref: 'Media', required: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  status: { type: String, enum: ['active', 'removed'], default: 'active' },
+  removedBy: { type: Schema.Types.ObjectId },
+  removedByModerator: { type: Boolean },
+  removedReason: { type: String },
+  removedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+groupEmojiSchema.index({ chatId: 1, shortcode: 1 }, { unique: true, partialFilterExpression: { status: 'active' } });
+groupEmojiSchema.index({ createdAt: -1 });
+
+export const GroupEmoji = mongoose.models.GroupEmoji ||
+  mongoose.model<IGroupEmoji>('GroupEmoji', groupEmojiSchema);
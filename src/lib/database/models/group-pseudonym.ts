@@ -0,0 +1,29 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A member's pseudonym in a group with pseudonymous members. Only the server
+// and the group's admins can map it back to the user; other members see the
+// name, and the document's _id in place of the sender's.
+export interface IGroupPseudonym extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  userId: Types.ObjectId;
+  name: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const groupPseudonymSchema = new Schema<IGroupPseudonym>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  name: { type: String, required: true },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+groupPseudonymSchema.index({ chatId: 1, userId: 1 }, { unique: true });
+groupPseudonymSchema.index({ chatId: 1, name: 1 }, { unique: true });
+
+export const GroupPseudonym = mongoose.models.GroupPseudonym ||
+  mongoose.model<IGroupPseudonym>('GroupPseudonym', groupPseudonymSchema);
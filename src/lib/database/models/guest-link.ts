@@ -0,0 +1,52 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// What guests who joined through a link may do besides chatting
+export interface IGuestCapabilities {
+  calls: boolean;
+  fileUpload: boolean;
+}
+
+// A link that lets people without an account join a group as a guest for
+// a limited time, see auth/guest-access
+export interface IGuestLink extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  createdBy: Types.ObjectId;
+  token: string; // the link itself; whoever has it can join
+  label?: string; // for the admins, e.g. "Vendor onboarding"
+  guestHours: number; // how long each guest stays in the chat
+  capabilities: IGuestCapabilities;
+  expiresAt: Date;
+  maxUses?: number;
+  useCount: number;
+  lastUsedAt?: Date;
+  revokedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const guestLinkSchema = new Schema<IGuestLink>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  token: { type: String, required: true },
+  label: { type: String },
+  guestHours: { type: Number, required: true, min: 1 },
+  capabilities: {
+    calls: { type: Boolean, default: false },
+    fileUpload: { type: Boolean, default: false },
+  },
+  expiresAt: { type: Date, required: true },
+  maxUses: { type: Number, min: 1 },
+  useCount: { type: Number, default: 0 },
+  lastUsedAt: { type: Date },
+  revokedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+guestLinkSchema.index({ token: 1 }, { unique: true });
+guestLinkSchema.index({ chatId: 1, createdAt: -1 });
+
+export const GuestLink = mongoose.models.GuestLink || mongoose.model<IGuestLink>('GuestLink', guestLinkSchema);
@@ -0,0 +1,48 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A copy of a message deleted for everyone while under a legal hold. The
+// message itself stays behind as a tombstone; this is what it said.
+export interface IHeldMessage extends Document {
+  _id: Types.ObjectId;
+  messageId: Types.ObjectId;
+  holdIds: Types.ObjectId[]; // holds that covered the message when it was deleted
+  chatId: Types.ObjectId;
+  senderId: Types.ObjectId;
+  type: string;
+  content: string;
+  media?: Types.ObjectId;
+  replyTo?: Types.ObjectId;
+  sentAt: Date;
+  deletedBy: {
+    kind: 'user' | 'admin';
+    id: Types.ObjectId;
+  };
+  deletedAt: Date;
+  createdAt: Date;
+}
+
+const heldMessageSchema = new Schema<IHeldMessage>({
+  messageId: { type: Schema.Types.ObjectId, ref: 'Message', required: true, unique: true },
+  holdIds: [{ type: Schema.Types.ObjectId, ref: 'LegalHold' }],
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  senderId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  type: { type: String, required: true },
+  content: { type: String, required: true },
+  media: { type: Schema.Types.ObjectId, ref: 'Media' },
+  replyTo: { type: Schema.Types.ObjectId, ref: 'Message' },
+  sentAt: { type: Date, required: true },
+  deletedBy: {
+    kind: { type: String, enum: ['user', 'admin'], required: true },
+    id: { type: Schema.Types.ObjectId, required: true },
+  },
+  deletedAt: { type: Date, required: true },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+heldMessageSchema.index({ holdIds: 1, deletedAt: -1 });
+
+export const HeldMessage = mongoose.models.HeldMessage ||
+  mongoose.model<IHeldMessage>('HeldMessage', heldMessageSchema);
@@ -0,0 +1,67 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type ImportSource = 'whatsapp' | 'telegram';
+export type ImportJobStatus = 'queued' | 'processing' | 'completed' | 'failed';
+
+export interface IImportJob extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  source: ImportSource;
+  status: ImportJobStatus;
+  fileName: string;
+  fileKey: string; // uploaded export archive in object storage
+  fileSize: number;
+  checksum: string;
+  chatId?: Types.ObjectId; // target chat; a new one is created when not given
+  options: {
+    selfName?: string; // the importer's name as it appears in the export
+    sourceChatName?: string; // which chat to take from a multi-chat Telegram export
+    dateFormat?: 'dmy' | 'mdy'; // WhatsApp dates are locale dependent
+  };
+  progress: {
+    totalMessages: number;
+    processedMessages: number;
+    importedMedia: number;
+    skippedMessages: number;
+  };
+  error?: string;
+  startedAt?: Date;
+  completedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const importJobSchema = new Schema<IImportJob>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  source: { type: String, enum: ['whatsapp', 'telegram'], required: true },
+  status: { type: String, enum: ['queued', 'processing', 'completed', 'failed'], default: 'queued' },
+  fileName: { type: String, required: true },
+  fileKey: { type: String, required: true },
+  fileSize: { type: Number, required: true },
+  checksum: { type: String, required: true },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+  options: {
+    selfName: { type: String },
+    sourceChatName: { type: String },
+    dateFormat: { type: String, enum: ['dmy', 'mdy'] },
+  },
+  progress: {
+    totalMessages: { type: Number, default: 0 },
+    processedMessages: { type: Number, default: 0 },
+    importedMedia: { type: Number, default: 0 },
+    skippedMessages: { type: Number, default: 0 },
+  },
+  error: { type: String },
+  startedAt: { type: Date },
+  completedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+importJobSchema.index({ userId: 1, createdAt: -1 });
+importJobSchema.index({ status: 1, createdAt: 1 });
+importJobSchema.index({ userId: 1, checksum: 1 });
+
+export const ImportJob = mongoose.models.ImportJob || mongoose.model<IImportJob>('ImportJob', importJobSchema);
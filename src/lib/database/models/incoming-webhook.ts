@@ -0,0 +1,46 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A URL that lets an outside system post bot messages into a group
+export interface IIncomingWebhook extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  name: string; // default display name of the bot
+  avatarUrl?: string;
+  tokenHash: string; // SHA-256 of the secret URL token; the token itself is shown once
+  createdBy: Types.ObjectId;
+  rateLimit: {
+    maxRequests: number;
+    windowSeconds: number;
+  };
+  revokedAt?: Date;
+  revokedBy?: Types.ObjectId;
+  lastUsedAt?: Date;
+  messageCount: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const incomingWebhookSchema = new Schema<IIncomingWebhook>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  name: { type: String, required: true, trim: true },
+  avatarUrl: { type: String },
+  tokenHash: { type: String, required: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  rateLimit: {
+    maxRequests: { type: Number, default: 30 },
+    windowSeconds: { type: Number, default: 60 },
+  },
+  revokedAt: { type: Date },
+  revokedBy: { type: Schema.Types.ObjectId, ref: 'User' },
+  lastUsedAt: { type: Date },
+  messageCount: { type: Number, default: 0 },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+incomingWebhookSchema.index({ chatId: 1, createdAt: -1 });
+
+export const IncomingWebhook = mongoose.models.IncomingWebhook ||
+  mongoose.model<IIncomingWebhook>('IncomingWebhook', incomingWebhookSchema);
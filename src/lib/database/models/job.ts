@@ -0,0 +1,51 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type JobStatus = 'pending' | 'processing' | 'completed' | 'failed' | 'cancelled';
+
+// A unit of background work in the durable job queue
+export interface IJob extends Document {
+  _id: Types.ObjectId;
+  name: string; // registered handler, e.g. 'push:reminder'
+  payload: Record<string, any>; // ids only; handlers load what they need
+  status: JobStatus;
+  runAt: Date; // not claimed before this
+  attempts: number;
+  maxAttempts: number;
+  lockedAt?: Date; // when a worker claimed it
+  lockedBy?: string; // worker id, host:pid
+  lastError?: string;
+  finishedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const jobSchema = new Schema<IJob>({
+  name: { type: String, required: true },
+  payload: { type: Schema.Types.Mixed, default: {} },
+  status: {
+    type: String,
+    enum: ['pending', 'processing', 'completed', 'failed', 'cancelled'],
+    default: 'pending',
+  },
+  runAt: { type: Date, default: Date.now },
+  attempts: { type: Number, default: 0 },
+  maxAttempts: { type: Number, default: 5 },
+  lockedAt: { type: Date },
+  lockedBy: { type: String },
+  lastError: { type: String },
+  finishedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+jobSchema.index({ status: 1, runAt: 1 });
+jobSchema.index({ status: 1, lockedAt: 1 });
+jobSchema.index({ name: 1, status: 1, createdAt: -1 });
+jobSchema.index(
+  { finishedAt: 1 },
+  { expireAfterSeconds: 7 * 24 * 60 * 60, partialFilterExpression: { status: 'completed' } }
+); // keep completed jobs a week; failed ones stay until retried or cancelled
+
+export const Job = mongoose.models.Job || mongoose.model<IJob>('Job', jobSchema);
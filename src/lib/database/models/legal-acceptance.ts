@@ -0,0 +1,35 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Audit record of a user accepting one version of a legal notice. Records
+// are append-only and kept after the notice changes, for compliance exports.
+export interface ILegalAcceptance extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  noticeId: Types.ObjectId;
+  noticeKey: string;
+  version: number;
+  acceptedAt: Date;
+  ip?: string;
+  userAgent?: string;
+  deviceId?: string;
+}
+
+const legalAcceptanceSchema = new Schema<ILegalAcceptance>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  noticeId: { type: Schema.Types.ObjectId, ref: 'LegalNotice', required: true },
+  noticeKey: { type: String, required: true },
+  version: { type: Number, required: true },
+  acceptedAt: { type: Date, default: Date.now },
+  ip: { type: String },
+  userAgent: { type: String },
+  deviceId: { type: String },
+}, {
+  versionKey: false,
+});
+
+// Indexes
+legalAcceptanceSchema.index({ userId: 1, noticeId: 1, version: 1 }, { unique: true });
+legalAcceptanceSchema.index({ noticeId: 1, version: 1, acceptedAt: 1 });
+
+export const LegalAcceptance = mongoose.models.LegalAcceptance ||
+  mongoose.model<ILegalAcceptance>('LegalAcceptance', legalAcceptanceSchema);
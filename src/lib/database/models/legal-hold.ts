@@ -0,0 +1,43 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type LegalHoldSubject = 'user' | 'chat';
+export type LegalHoldStatus = 'active' | 'released';
+
+// A legal hold on a user or chat. While active, nothing the hold covers is
+// deleted, see compliance/legal-holds.
+export interface ILegalHold extends Document {
+  _id: Types.ObjectId;
+  subject: LegalHoldSubject;
+  subjectId: Types.ObjectId; // user or chat
+  status: LegalHoldStatus;
+  reason: string;
+  caseReference?: string; // matter or ticket number the hold is for
+  placedBy: Types.ObjectId; // admin
+  releasedBy?: Types.ObjectId;
+  releaseReason?: string;
+  releasedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const legalHoldSchema = new Schema<ILegalHold>({
+  subject: { type: String, enum: ['user', 'chat'], required: true },
+  subjectId: { type: Schema.Types.ObjectId, required: true },
+  status: { type: String, enum: ['active', 'released'], default: 'active' },
+  reason: { type: String, required: true },
+  caseReference: { type: String },
+  placedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  releasedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  releaseReason: { type: String },
+  releasedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+legalHoldSchema.index({ subject: 1, subjectId: 1, status: 1 });
+legalHoldSchema.index({ status: 1, createdAt: -1 });
+
+export const LegalHold = mongoose.models.LegalHold ||
+  mongoose.model<ILegalHold>('LegalHold', legalHoldSchema);
@@ -0,0 +1,51 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A legal document (terms of service, privacy policy, ...) with its
+// published versions. The version in force is the newest one whose
+// effectiveAt has passed; versions are never edited once published.
+export interface ILegalNotice extends Document {
+  _id: Types.ObjectId;
+  key: string; // stable identifier, e.g. "terms"
+  title: string;
+  mandatory: boolean; // users must accept the version in force to use the API
+  versions: {
+    version: number;
+    content: string; // markdown
+    summary?: string; // what changed, shown when asking users to accept again
+    effectiveAt: Date;
+    publishedBy?: Types.ObjectId;
+    publishedAt: Date;
+    announcedAt?: Date; // users were told the version is in force
+  }[];
+  archivedAt?: Date;
+  createdBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const legalNoticeSchema = new Schema<ILegalNotice>({
+  key: { type: String, required: true, unique: true, trim: true },
+  title: { type: String, required: true, trim: true },
+  mandatory: { type: Boolean, default: true },
+  versions: [{
+    _id: false,
+    version: { type: Number, required: true, min: 1 },
+    content: { type: String, required: true },
+    summary: { type: String },
+    effectiveAt: { type: Date, required: true },
+    publishedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+    publishedAt: { type: Date, default: Date.now },
+    announcedAt: { type: Date },
+  }],
+  archivedAt: { type: Date },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+legalNoticeSchema.index({ archivedAt: 1 });
+
+export const LegalNotice = mongoose.models.LegalNotice ||
+  mongoose.model<ILegalNotice>('LegalNotice', legalNoticeSchema);
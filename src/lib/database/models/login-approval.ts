@@ -0,0 +1,58 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type LoginAnomaly = 'new_device' | 'new_location';
+
+// A sign-in from a device or country the user hasn't signed in from before.
+// Until the user approves it (from another device or the SMS link), or it
+// expires, the new session can't take sensitive actions; denying it signs
+// the device out.
+export interface ILoginApproval extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  deviceId: string;
+  anomalies: LoginAnomaly[];
+  ip?: string;
+  country?: string;
+  city?: string;
+  asn?: number;
+  asOrganization?: string;
+  userAgent?: string;
+  platform?: string;
+  status: 'pending' | 'approved' | 'denied';
+  tokenHash: string; // of the secret in the SMS link
+  notifiedBy: ('push' | 'sms')[];
+  decidedAt?: Date;
+  decidedFrom?: string; // deviceId, or 'sms'
+  expiresAt: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const loginApprovalSchema = new Schema<ILoginApproval>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  deviceId: { type: String, required: true },
+  anomalies: [{ type: String, enum: ['new_device', 'new_location'] }],
+  ip: { type: String },
+  country: { type: String },
+  city: { type: String },
+  asn: { type: Number },
+  asOrganization: { type: String },
+  userAgent: { type: String },
+  platform: { type: String },
+  status: { type: String, enum: ['pending', 'approved', 'denied'], default: 'pending' },
+  tokenHash: { type: String, required: true, unique: true },
+  notifiedBy: [{ type: String, enum: ['push', 'sms'] }],
+  decidedAt: { type: Date },
+  decidedFrom: { type: String },
+  expiresAt: { type: Date, required: true },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+loginApprovalSchema.index({ userId: 1, deviceId: 1, status: 1, expiresAt: 1 });
+loginApprovalSchema.index({ userId: 1, createdAt: -1 });
+
+export const LoginApproval = mongoose.models.LoginApproval ||
+  mongoose.model<ILoginApproval>('LoginApproval', loginApprovalSchema);
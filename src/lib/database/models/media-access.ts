@@ -0,0 +1,30 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A read of a file through a sharing grant, for the uploader's audit
+export interface IMediaAccess extends Document {
+  _id: Types.ObjectId;
+  mediaId: Types.ObjectId;
+  userId: Types.ObjectId;
+  grantId: Types.ObjectId;
+  action: 'download' | 'thumbnail';
+  ipAddress?: string;
+  createdAt: Date;
+}
+
+const mediaAccessSchema = new Schema<IMediaAccess>({
+  mediaId: { type: Schema.Types.ObjectId, ref: 'Media', required: true },
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  grantId: { type: Schema.Types.ObjectId, ref: 'MediaGrant', required: true },
+  action: { type: String, enum: ['download', 'thumbnail'], required: true },
+  ipAddress: { type: String },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+mediaAccessSchema.index({ mediaId: 1, createdAt: -1 });
+mediaAccessSchema.index({ createdAt: 1 }, { expireAfterSeconds: 180 * 24 * 60 * 60 }); // keep 180 days
+
+export const MediaAccess = mongoose.models.MediaAccess ||
+  mongoose.model<IMediaAccess>('MediaAccess', mediaAccessSchema);
@@ -0,0 +1,37 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Access to an uploaded file given after the fact, to the members of another
+// chat or to specific users, without uploading it again
+export interface IMediaGrant extends Document {
+  _id: Types.ObjectId;
+  mediaId: Types.ObjectId;
+  grantedBy: Types.ObjectId;
+  chatId?: Types.ObjectId; // set for chat grants
+  userId?: Types.ObjectId; // set for user grants
+  expiresAt?: Date;
+  revokedAt?: Date;
+  revokedBy?: Types.ObjectId;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const mediaGrantSchema = new Schema<IMediaGrant>({
+  mediaId: { type: Schema.Types.ObjectId, ref: 'Media', required: true },
+  grantedBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+  userId: { type: Schema.Types.ObjectId, ref: 'User' },
+  expiresAt: { type: Date },
+  revokedAt: { type: Date },
+  revokedBy: { type: Schema.Types.ObjectId, ref: 'User' },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+mediaGrantSchema.index({ mediaId: 1, revokedAt: 1 });
+mediaGrantSchema.index({ userId: 1, revokedAt: 1, createdAt: -1 });
+mediaGrantSchema.index({ chatId: 1, revokedAt: 1, createdAt: -1 });
+
+export const MediaGrant = mongoose.models.MediaGrant ||
+  mongoose.model<IMediaGrant>('MediaGrant', mediaGrantSchema);
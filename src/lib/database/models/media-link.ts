@@ -0,0 +1,45 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A public download link for a file, usable without an account
+export interface IMediaLink extends Document {
+  _id: Types.ObjectId;
+  mediaId: Types.ObjectId;
+  createdBy: Types.ObjectId;
+  token: string; // the link itself; whoever has it can download
+  passwordHash?: string;
+  passwordSalt?: string;
+  expiresAt?: Date;
+  maxDownloads?: number;
+  downloadCount: number;
+  lastDownloadedAt?: Date;
+  failedPasswordAttempts: number;
+  lockedUntil?: Date; // after too many wrong passwords
+  revokedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const mediaLinkSchema = new Schema<IMediaLink>({
+  mediaId: { type: Schema.Types.ObjectId, ref: 'Media', required: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  token: { type: String, required: true },
+  passwordHash: { type: String },
+  passwordSalt: { type: String },
+  expiresAt: { type: Date },
+  maxDownloads: { type: Number, min: 1 },
+  downloadCount: { type: Number, default: 0 },
+  lastDownloadedAt: { type: Date },
+  failedPasswordAttempts: { type: Number, default: 0 },
+  lockedUntil: { type: Date },
+  revokedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+mediaLinkSchema.index({ token: 1 }, { unique: true });
+mediaLinkSchema.index({ mediaId: 1, createdAt: -1 });
+mediaLinkSchema.index({ createdBy: 1, createdAt: -1 });
+
+export const MediaLink = mongoose.models.MediaLink || mongoose.model<IMediaLink>('MediaLink', mediaLinkSchema);
@@ -1,4 +1,5 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
+import { DATA_REGIONS, DataRegion } from './user';
 
 export interface IMedia extends Document {
   _id: Types.ObjectId;
@@ -8,6 +9,15 @@ export interface IMedia extends Document {
   size: number;
   url: string;
   thumbnailUrl?: string;
+  thumbnailKey?: string; // default size as JPEG
+  thumbnails?: Array<{
+    size: string; // name from THUMBNAIL_SIZES
+    format: 'jpeg' | 'png' | 'webp' | 'avif';
+    key: string;
+    width?: number;
+    height?: number;
+    bytes: number;
+  }>;
   uploadedBy: Types.ObjectId;
   chatId?: Types.ObjectId;
   messageId?: Types.ObjectId;
@@ -20,10 +30,16 @@ export interface IMedia extends Document {
     };
     format?: string;
     bitrate?: number;
+    videoCodec?: string;
+    audioCodec?: string;
+    frameRate?: number;
+    posterAt?: number; // seconds into the video the poster frame was taken
   };
   isEncrypted: boolean;
   encryptionKey?: string;
   checksumSHA256: string;
+  perceptualHash?: string; // images only, for near-duplicate matching
+  storageRegion?: DataRegion; // unset for files stored before data residency, kept in the default region
   createdAt: Date;
   updatedAt: Date;
 }
@@ -35,6 +51,16 @@ const mediaSchema = new Schema<IMedia>({
   size: { type: Number, required: true },
   url: { type: String, required: true },
   thumbnailUrl: { type: String },
+  thumbnailKey: { type: String },
+  thumbnails: [{
+    _id: false,
+    size: { type: String, required: true },
+    format: { type: String, enum: ['jpeg', 'png', 'webp', 'avif'], required: true },
+    key: { type: String, required: true },
+    width: { type: Number },
+    height: { type: Number },
+    bytes: { type: Number, required: true },
+  }],
   uploadedBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
   chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
   messageId: { type: Schema.Types.ObjectId, ref: 'Message' },
@@ -51,10 +77,16 @@ const mediaSchema = new Schema<IMedia>({
     },
     format: { type: String },
     bitrate: { type: Number },
+    videoCodec: { type: String },
+    audioCodec: { type: String },
+    frameRate: { type: Number },
+    posterAt: { type: Number },
   },
   isEncrypted: { type: Boolean, default: false },
   encryptionKey: { type: String },
   checksumSHA256: { type: String, required: true },
+  perceptualHash: { type: String },
+  storageRegion: { type: String, enum: DATA_REGIONS },
 }, {
   timestamps: true,
   versionKey: false,
@@ -66,5 +98,11 @@ mediaSchema.index({ chatId: 1 });
 mediaSchema.index({ messageId: 1 });
 mediaSchema.index({ type: 1 });
 mediaSchema.index({ createdAt: -1 });
+mediaSchema.index({ perceptualHash: 1 }, { sparse: true });
+mediaSchema.index({ uploadedBy: 1, storageRegion: 1 });
+// Admin file list filters, newest first
+mediaSchema.index({ type: 1, createdAt: -1 });
+mediaSchema.index({ storageRegion: 1, createdAt: -1 });
+mediaSchema.index({ size: -1 });
 
 export const Media = mongoose.models.Media || mongoose.model<IMedia>('Media', mediaSchema);
@@ -0,0 +1,35 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A run of consecutive old messages from one busy chat packed into a single
+// document, so the chat's history takes a fraction of the documents and
+// index entries. Messages keep the exact shape they had in the messages
+// collection and move back there when they change.
+export interface IMessageBucket extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  firstAt: Date; // createdAt of the oldest message
+  lastAt: Date; // createdAt of the newest message
+  count: number;
+  messages: Record<string, any>[]; // oldest first
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+export const messageBucketSchema = new Schema<IMessageBucket>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  firstAt: { type: Date, required: true },
+  lastAt: { type: Date, required: true },
+  count: { type: Number, required: true },
+  messages: { type: [Schema.Types.Mixed], default: [] },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+messageBucketSchema.index({ chatId: 1, lastAt: -1 });
+messageBucketSchema.index({ 'messages._id': 1 });
+messageBucketSchema.index({ chatId: 'hashed', _id: 1 }); // shard key, see database/sharding.ts
+
+export const MessageBucket = mongoose.models.MessageBucket ||
+  mongoose.model<IMessageBucket>('MessageBucket', messageBucketSchema);
@@ -1,9 +1,27 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
 
+// Slack-style rich attachment rendered under a webhook message
+export interface WebhookAttachment {
+  fallback?: string;
+  color?: string;
+  pretext?: string;
+  title?: string;
+  titleLink?: string;
+  text?: string;
+  fields?: { title: string; value: string; short?: boolean }[];
+  imageUrl?: string;
+  footer?: string;
+}
+
+// Moderation states in which only the sender can see a message
+export const WITHHELD_MESSAGE_STATES = ['hidden', 'pending', 'rejected'] as const;
+
 export interface IMessage extends Document {
   _id: Types.ObjectId;
   chatId: Types.ObjectId;
+  seq?: number; // position in the chat, from 1 without gaps; unset on messages sent before seqs existed
   senderId: Types.ObjectId;
+  clientMessageId?: string; // UUID from the sender's client, to match its optimistic copy and drop resends
   content: string;
   type: 'text' | 'image' | 'video' | 'audio' | 'document' | 'voice' | 'location' | 'contact' | 'sticker';
   media?: Types.ObjectId;
@@ -14,6 +32,25 @@ export interface IMessage extends Document {
   isDeleted: boolean;
   deletedAt?: Date;
   deletedFor: Types.ObjectId[]; // Users who deleted this message for themselves
+  isImported: boolean; // history brought in from another messenger's export
+  importInfo?: {
+    jobId: Types.ObjectId;
+    source: 'whatsapp' | 'telegram';
+    originalSender: string; // sender name as it appeared in the export
+  };
+  moderation?: { // set on messages from restricted senders, and members' messages in moderated groups
+    state: 'hidden' | 'pending' | 'approved' | 'rejected'; // hidden: shadow restriction, pending: awaiting review
+    actionId?: Types.ObjectId; // restriction the message was held under
+    groupReview?: boolean; // held for the group's admins rather than platform moderators
+    reviewedBy?: Types.ObjectId; // platform admin, or group admin (a user) for group reviews
+    reviewedAt?: Date;
+    reason?: string; // given to the author when a group admin rejects the message
+  };
+  pseudonym?: { // sent in a group with pseudonymous members; only its admins see the real sender
+    id: Types.ObjectId; // the GroupPseudonym, shown to other members in place of senderId
+    name: string;
+  };
+  labels?: string[]; // added by automation rules, for admins
   createdAt: Date;
   updatedAt: Date;
   
@@ -31,7 +68,8 @@ export interface IMessage extends Document {
   // Reactions
   reactions: {
     userId: Types.ObjectId;
-    emoji: string;
+    emoji: string; // :shortcode: for custom emoji
+    customEmojiId?: Types.ObjectId;
     createdAt: Date;
   }[];
   
@@ -49,12 +87,43 @@ export interface IMessage extends Document {
     };
     mentions?: Types.ObjectId[];
     links?: string[];
+    customEmoji?: Types.ObjectId[]; // group emoji used as :shortcode: in the text
+    command?: { // produced by a slash command
+      name: string;
+      invokedBy?: Types.ObjectId; // set when a bot posted the response
+      username?: string;
+      avatarUrl?: string;
+      attachments?: WebhookAttachment[];
+    };
+    poll?: {
+      question: string;
+      options: { text: string; votes: Types.ObjectId[] }[];
+      closed: boolean;
+      anonymous?: boolean; // voters only visible to the group's admins
+    };
+    reminder?: { // delivered by the reminder service
+      reminderId: Types.ObjectId;
+      chatId?: Types.ObjectId; // where the reminder was set
+      messageId?: Types.ObjectId; // the message it points back to
+    };
+    webhook?: { // posted through an incoming webhook
+      webhookId: Types.ObjectId;
+      username?: string; // per-message display name override
+      avatarUrl?: string;
+      attachments?: WebhookAttachment[];
+    };
+    callChat?: { // summary of a call's in-call chat, posted when it ended
+      callId: string;
+      messageCount: number;
+    };
   };
 }
 
 const messageSchema = new Schema<IMessage>({
   chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true, index: true },
+  seq: { type: Number },
   senderId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  clientMessageId: { type: String },
   content: { type: String, required: true },
   type: { 
     type: String, 
@@ -69,6 +138,35 @@ const messageSchema = new Schema<IMessage>({
   isDeleted: { type: Boolean, default: false },
   deletedAt: { type: Date },
   deletedFor: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  isImported: { type: Boolean, default: false },
+  importInfo: {
+    jobId: { type: Schema.Types.ObjectId, ref: 'ImportJob' },
+    source: { type: String, enum: ['whatsapp', 'telegram'] },
+    originalSender: { type: String },
+  },
+  moderation: {
+    type: {
+      state: { type: String, enum: ['hidden', 'pending', 'approved', 'rejected'], required: true },
+      actionId: {
+        type: Schema.Types.ObjectId,
+        ref: 'ModerationAction',
+        required: function (this: { groupReview?: boolean }) { return !this.groupReview; },
+      },
+      groupReview: { type: Boolean },
+      reviewedBy: { type: Schema.Types.ObjectId },
+      reviewedAt: { type: Date },
+      reason: { type: String },
+    },
+    default: undefined,
+  },
+  pseudonym: {
+    type: {
+      id: { type: Schema.Types.ObjectId, ref: 'GroupPseudonym', required: true },
+      name: { type: String, required: true },
+    },
+    default: undefined,
+  },
+  labels: { type: [String], default: undefined },
   
   status: { type: String, enum: ['sent', 'delivered', 'read'], default: 'sent' },
   deliveredTo: [{
@@ -83,6 +181,7 @@ const messageSchema = new Schema<IMessage>({
   reactions: [{
     userId: { type: Schema.Types.ObjectId, ref: 'User' },
     emoji: { type: String },
+    customEmojiId: { type: Schema.Types.ObjectId, ref: 'GroupEmoji' },
     createdAt: { type: Date, default: Date.now },
   }],
   
@@ -99,6 +198,44 @@ const messageSchema = new Schema<IMessage>({
     },
     mentions: [{ type: Schema.Types.ObjectId, ref: 'User' }],
     links: [{ type: String }],
+    customEmoji: { type: [{ type: Schema.Types.ObjectId, ref: 'GroupEmoji' }], default: undefined },
+    command: {
+      name: { type: String },
+      invokedBy: { type: Schema.Types.ObjectId, ref: 'User' },
+      username: { type: String },
+      avatarUrl: { type: String },
+      attachments: { type: [Schema.Types.Mixed], default: undefined },
+    },
+    poll: {
+      type: {
+        question: { type: String },
+        options: [{
+          text: { type: String },
+          votes: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+        }],
+        closed: { type: Boolean, default: false },
+        anonymous: { type: Boolean },
+      },
+      default: undefined,
+    },
+    reminder: {
+      reminderId: { type: Schema.Types.ObjectId, ref: 'Reminder' },
+      chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+      messageId: { type: Schema.Types.ObjectId, ref: 'Message' },
+    },
+    webhook: {
+      webhookId: { type: Schema.Types.ObjectId, ref: 'IncomingWebhook' },
+      username: { type: String },
+      avatarUrl: { type: String },
+      attachments: { type: [Schema.Types.Mixed], default: undefined },
+    },
+    callChat: {
+      type: {
+        callId: { type: String },
+        messageCount: { type: Number },
+      },
+      default: undefined,
+    },
   },
 }, {
   timestamps: true,
@@ -107,10 +244,18 @@ const messageSchema = new Schema<IMessage>({
 
 // Indexes
 messageSchema.index({ chatId: 1, createdAt: -1 });
+messageSchema.index({ chatId: 1, seq: 1 }, { partialFilterExpression: { seq: { $exists: true } } });
+messageSchema.index({ chatId: 'hashed', _id: 1 }); // shard key, see database/sharding.ts
 messageSchema.index({ senderId: 1 });
+// Not unique: unique indexes can't be enforced on the hashed shard key, so
+// sendChatMessage dedupes under a lock instead
+messageSchema.index({ chatId: 1, senderId: 1, clientMessageId: 1 }, { partialFilterExpression: { clientMessageId: { $exists: true } } });
 messageSchema.index({ content: 'text' });
 messageSchema.index({ type: 1 });
 messageSchema.index({ isDeleted: 1 });
+messageSchema.index({ 'importInfo.jobId': 1 }, { sparse: true });
+messageSchema.index({ 'moderation.state': 1, createdAt: 1 }, { sparse: true });
+messageSchema.index({ chatId: 1, 'moderation.state': 1, createdAt: 1 }, { partialFilterExpression: { 'moderation.groupReview': true } });
 
 export const Message = mongoose.models.Message || mongoose.model<IMessage>('Message', messageSchema);
 
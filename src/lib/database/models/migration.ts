@@ -0,0 +1,28 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export interface IMigration extends Document {
+  _id: Types.ObjectId;
+  name: string;
+  description?: string;
+  appliedAt: Date;
+  duration: number; // in milliseconds
+  result?: any;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const migrationSchema = new Schema<IMigration>({
+  name: { type: String, required: true, unique: true },
+  description: { type: String },
+  appliedAt: { type: Date, default: Date.now },
+  duration: { type: Number, default: 0 },
+  result: { type: Schema.Types.Mixed },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+migrationSchema.index({ appliedAt: -1 });
+
+export const Migration = mongoose.models.Migration || mongoose.model<IMigration>('Migration', migrationSchema);
@@ -0,0 +1,48 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type ModerationActionKind = 'ban' | 'restrict';
+export type RestrictionMode = 'shadow' | 'review';
+
+// A ban or restriction applied to a user by a moderator. Kept after it is
+// lifted, so a user's records are the audit trail of how they were handled.
+export interface IModerationAction extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  action: ModerationActionKind;
+  mode?: RestrictionMode; // restrictions only: shadow hides messages from everyone but the sender, review holds them for a moderator
+  reason: string;
+  note?: string; // internal, never shown to the user
+  reportId?: Types.ObjectId; // report that prompted the action
+  issuedBy: Types.ObjectId;
+  expiresAt?: Date; // permanent when unset
+  liftedAt?: Date;
+  liftedBy?: Types.ObjectId; // unset when it simply expired
+  liftReason?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const moderationActionSchema = new Schema<IModerationAction>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  action: { type: String, enum: ['ban', 'restrict'], required: true },
+  mode: { type: String, enum: ['shadow', 'review'] },
+  reason: { type: String, required: true },
+  note: { type: String },
+  reportId: { type: Schema.Types.ObjectId, ref: 'Report' },
+  issuedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  expiresAt: { type: Date },
+  liftedAt: { type: Date },
+  liftedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  liftReason: { type: String },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+moderationActionSchema.index({ userId: 1, createdAt: -1 });
+moderationActionSchema.index({ liftedAt: 1, expiresAt: 1 });
+moderationActionSchema.index({ action: 1, liftedAt: 1, createdAt: -1 });
+
+export const ModerationAction = mongoose.models.ModerationAction ||
+  mongoose.model<IModerationAction>('ModerationAction', moderationActionSchema);
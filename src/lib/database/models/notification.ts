@@ -3,7 +3,7 @@ import mongoose, { Schema, Document, Types } from 'mongoose';
 export interface INotification extends Document {
   _id: Types.ObjectId;
   userId: Types.ObjectId;
-  type: 'message' | 'call' | 'group_invite' | 'contact_request' | 'system' | 'status_view';
+  type: 'message' | 'call' | 'group_invite' | 'contact_request' | 'system' | 'status_view' | 'reminder';
   title: string;
   body: string;
   data?: any;
@@ -16,8 +16,8 @@ export interface INotification extends Document {
   relatedCall?: Types.ObjectId;
   relatedUser?: Types.ObjectId;
   
-  // Delivery status
-  deliveryStatus: 'pending' | 'sent' | 'failed';
+  // Delivery status ('deferred' = held for the Do Not Disturb digest)
+  deliveryStatus: 'pending' | 'sent' | 'failed' | 'deferred';
   sentAt?: Date;
   failureReason?: string;
   
@@ -29,7 +29,7 @@ const notificationSchema = new Schema<INotification>({
   userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
   type: { 
     type: String, 
-    enum: ['message', 'call', 'group_invite', 'contact_request', 'system', 'status_view'],
+    enum: ['message', 'call', 'group_invite', 'contact_request', 'system', 'status_view', 'reminder'],
     required: true 
   },
   title: { type: String, required: true },
@@ -43,7 +43,7 @@ const notificationSchema = new Schema<INotification>({
   relatedCall: { type: Schema.Types.ObjectId, ref: 'Call' },
   relatedUser: { type: Schema.Types.ObjectId, ref: 'User' },
   
-  deliveryStatus: { type: String, enum: ['pending', 'sent', 'failed'], default: 'pending' },
+  deliveryStatus: { type: String, enum: ['pending', 'sent', 'failed', 'deferred'], default: 'pending' },
   sentAt: { type: Date },
   failureReason: { type: String },
 }, {
@@ -56,5 +56,6 @@ notificationSchema.index({ userId: 1, createdAt: -1 });
 notificationSchema.index({ type: 1 });
 notificationSchema.index({ isRead: 1 });
 notificationSchema.index({ deliveryStatus: 1 });
+notificationSchema.index({ deliveryStatus: 1, userId: 1, createdAt: 1 });
 
 export const Notification = mongoose.models.Notification || mongoose.model<INotification>('Notification', notificationSchema);
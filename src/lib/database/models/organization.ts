@@ -0,0 +1,69 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+import { IFeatureConfig } from './admin-config';
+
+export type OrganizationStatus = 'active' | 'suspended';
+
+// A tenant. Users, groups, org admins and API keys carry its ID; members
+// only see and reach others in the same organization, see organizations/.
+export interface IOrganization extends Document {
+  _id: Types.ObjectId;
+  name: string;
+  slug: string;
+  status: OrganizationStatus; // suspended organizations' members can't sign in
+  features: IFeatureConfig; // narrows the global switches for this organization
+  quotas: {
+    maxUsers?: number;
+    maxGroups?: number;
+  };
+  branding?: { // how clients theme themselves for this organization, see organizations/branding
+    appName?: string;
+    logoKey?: string; // storage keys of uploaded assets
+    faviconKey?: string;
+    colors?: {
+      primary?: string; // #rrggbb
+      accent?: string;
+      background?: string;
+    };
+    domains?: string[]; // hosts that resolve to this organization's branding
+  };
+  createdBy: Types.ObjectId; // admin
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const organizationSchema = new Schema<IOrganization>({
+  name: { type: String, required: true, trim: true },
+  slug: { type: String, required: true, unique: true, lowercase: true },
+  status: { type: String, enum: ['active', 'suspended'], default: 'active' },
+  features: {
+    publicFileLinks: { type: Boolean },
+    publicLinkMaxDays: { type: Number, min: 0 },
+    guestLinks: { type: Boolean },
+  },
+  quotas: {
+    maxUsers: { type: Number, min: 0 },
+    maxGroups: { type: Number, min: 0 },
+  },
+  branding: {
+    appName: { type: String },
+    logoKey: { type: String },
+    faviconKey: { type: String },
+    colors: {
+      primary: { type: String },
+      accent: { type: String },
+      background: { type: String },
+    },
+    domains: { type: [String], default: undefined },
+  },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+organizationSchema.index({ status: 1, createdAt: -1 });
+organizationSchema.index({ 'branding.domains': 1 }, { unique: true, sparse: true });
+
+export const Organization = mongoose.models.Organization ||
+  mongoose.model<IOrganization>('Organization', organizationSchema);
@@ -0,0 +1,43 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type PersonalAccessTokenScope = 'messages:read' | 'messages:send';
+
+// A token a user mints for their own scripts and integrations. It acts as
+// the user, but only within its scopes and, optionally, a set of chats.
+export interface IPersonalAccessToken extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  name: string;
+  tokenHash: string; // SHA-256 of the token; the token itself is shown once
+  tokenPrefix: string; // first characters, so users can tell tokens apart
+  scopes: PersonalAccessTokenScope[];
+  chatIds: Types.ObjectId[]; // empty means every chat the user is in
+  expiresAt: Date;
+  revokedAt?: Date;
+  lastUsedAt?: Date;
+  lastUsedIp?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const personalAccessTokenSchema = new Schema<IPersonalAccessToken>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  name: { type: String, required: true, trim: true },
+  tokenHash: { type: String, required: true, unique: true },
+  tokenPrefix: { type: String, required: true },
+  scopes: [{ type: String, enum: ['messages:read', 'messages:send'] }],
+  chatIds: [{ type: Schema.Types.ObjectId, ref: 'Chat' }],
+  expiresAt: { type: Date, required: true },
+  revokedAt: { type: Date },
+  lastUsedAt: { type: Date },
+  lastUsedIp: { type: String },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+personalAccessTokenSchema.index({ userId: 1, createdAt: -1 });
+
+export const PersonalAccessToken = mongoose.models.PersonalAccessToken ||
+  mongoose.model<IPersonalAccessToken>('PersonalAccessToken', personalAccessTokenSchema);
@@ -0,0 +1,51 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+import { DATA_REGIONS, DataRegion } from './user';
+
+export type RegionMigrationSubject = 'user' | 'chat';
+export type RegionMigrationStatus = 'queued' | 'processing' | 'completed' | 'failed';
+
+// Moving a user's files or a chat's message archive to another region
+export interface IRegionMigration extends Document {
+  _id: Types.ObjectId;
+  subject: RegionMigrationSubject;
+  subjectId: Types.ObjectId; // user or chat
+  fromRegion?: DataRegion; // unset when the data was in the default location
+  toRegion: DataRegion;
+  status: RegionMigrationStatus;
+  progress: {
+    total: number; // stored files for users, message buckets for chats
+    moved: number;
+  };
+  requestedBy?: Types.ObjectId; // admin; unset when started from broctl
+  error?: string;
+  startedAt?: Date;
+  completedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const regionMigrationSchema = new Schema<IRegionMigration>({
+  subject: { type: String, enum: ['user', 'chat'], required: true },
+  subjectId: { type: Schema.Types.ObjectId, required: true },
+  fromRegion: { type: String, enum: DATA_REGIONS },
+  toRegion: { type: String, enum: DATA_REGIONS, required: true },
+  status: { type: String, enum: ['queued', 'processing', 'completed', 'failed'], default: 'queued' },
+  progress: {
+    total: { type: Number, default: 0 },
+    moved: { type: Number, default: 0 },
+  },
+  requestedBy: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  error: { type: String },
+  startedAt: { type: Date },
+  completedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+regionMigrationSchema.index({ status: 1, createdAt: 1 });
+regionMigrationSchema.index({ subjectId: 1, createdAt: -1 });
+
+export const RegionMigration = mongoose.models.RegionMigration ||
+  mongoose.model<IRegionMigration>('RegionMigration', regionMigrationSchema);
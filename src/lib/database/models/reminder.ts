@@ -0,0 +1,52 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type ReminderStatus = 'pending' | 'processing' | 'delivered' | 'cancelled' | 'failed';
+
+// A reminder a user set for themselves, free-form or on a message
+export interface IReminder extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  text: string;
+  chatId?: Types.ObjectId; // chat the reminder was set in
+  messageId?: Types.ObjectId; // set for message-level reminders
+  dueAt: Date;
+  source: 'command' | 'message' | 'api';
+  status: ReminderStatus;
+  attempts: number;
+  lockedAt?: Date; // when a worker claimed it
+  deliveredAt?: Date;
+  deliveryMessageId?: Types.ObjectId; // message posted in the user's reminders chat
+  cancelledAt?: Date;
+  failureReason?: string;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const reminderSchema = new Schema<IReminder>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  text: { type: String, required: true, maxlength: 1000 },
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat' },
+  messageId: { type: Schema.Types.ObjectId, ref: 'Message' },
+  dueAt: { type: Date, required: true },
+  source: { type: String, enum: ['command', 'message', 'api'], required: true },
+  status: {
+    type: String,
+    enum: ['pending', 'processing', 'delivered', 'cancelled', 'failed'],
+    default: 'pending',
+  },
+  attempts: { type: Number, default: 0 },
+  lockedAt: { type: Date },
+  deliveredAt: { type: Date },
+  deliveryMessageId: { type: Schema.Types.ObjectId, ref: 'Message' },
+  cancelledAt: { type: Date },
+  failureReason: { type: String },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+reminderSchema.index({ status: 1, dueAt: 1 });
+reminderSchema.index({ userId: 1, status: 1, dueAt: 1 });
+
+export const Reminder = mongoose.models.Reminder || mongoose.model<IReminder>('Reminder', reminderSchema);
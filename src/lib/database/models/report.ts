@@ -24,6 +24,7 @@ export interface IReport extends Document {
     url?: string;
     content?: string;
   }[];
+  labels?: string[]; // added by automation rules
   
   createdAt: Date;
   updatedAt: Date;
@@ -54,6 +55,7 @@ const reportSchema = new Schema<IReport>({
     url: { type: String },
     content: { type: String },
   }],
+  labels: { type: [String], default: undefined },
 }, {
   timestamps: true,
   versionKey: false,
@@ -65,6 +67,10 @@ reportSchema.index({ reportedUserId: 1 });
 reportSchema.index({ status: 1 });
 reportSchema.index({ priority: 1 });
 reportSchema.index({ createdAt: -1 });
+// Admin report list filters, newest first
+reportSchema.index({ status: 1, createdAt: -1 });
+reportSchema.index({ type: 1, createdAt: -1 });
+reportSchema.index({ priority: 1, status: 1, createdAt: -1 });
 
 export const Report = mongoose.models.Report || mongoose.model<IReport>('Report', reportSchema);
 
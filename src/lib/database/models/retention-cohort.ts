@@ -0,0 +1,43 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// Users who signed up in one UTC week and how many came back. eligible
+// counts members whose day N has already passed; retained those active on
+// exactly that day.
+export interface IRetentionCohort extends Document {
+  _id: Types.ObjectId;
+  weekStart: Date; // Monday, UTC midnight
+  signups: number;
+  retention: {
+    day1: { eligible: number; retained: number };
+    day7: { eligible: number; retained: number };
+    day30: { eligible: number; retained: number };
+  };
+  complete: boolean; // every member's day 30 has passed; no more recomputes
+  computedAt: Date;
+}
+
+const retentionCohortSchema = new Schema<IRetentionCohort>({
+  weekStart: { type: Date, required: true, unique: true },
+  signups: { type: Number, default: 0 },
+  retention: {
+    day1: {
+      eligible: { type: Number, default: 0 },
+      retained: { type: Number, default: 0 },
+    },
+    day7: {
+      eligible: { type: Number, default: 0 },
+      retained: { type: Number, default: 0 },
+    },
+    day30: {
+      eligible: { type: Number, default: 0 },
+      retained: { type: Number, default: 0 },
+    },
+  },
+  complete: { type: Boolean, default: false },
+  computedAt: { type: Date, default: Date.now },
+}, {
+  versionKey: false,
+});
+
+export const RetentionCohort = mongoose.models.RetentionCohort ||
+  mongoose.model<IRetentionCohort>('RetentionCohort', retentionCohortSchema);
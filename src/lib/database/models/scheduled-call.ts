@@ -0,0 +1,58 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type ScheduledCallStatus = 'scheduled' | 'started' | 'cancelled' | 'expired';
+
+// A call planned ahead in a chat. At startsAt it becomes a live call
+// (callId); anyone with the join link can come in while it runs.
+export interface IScheduledCall extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  createdBy: Types.ObjectId;
+  title: string;
+  description?: string;
+  type: 'voice' | 'video';
+  participants: Types.ObjectId[]; // invited; rung and reminded
+  startsAt: Date;
+  endsAt: Date;
+  remindAt?: Date | null; // null when no reminder is wanted
+  reminderSentAt?: Date | null; // cleared when the call is rescheduled
+  joinCode: string;
+  status: ScheduledCallStatus;
+  callId?: string; // live call once started
+  startedAt?: Date;
+  cancelledAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const scheduledCallSchema = new Schema<IScheduledCall>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  title: { type: String, required: true, maxlength: 200 },
+  description: { type: String, maxlength: 2000 },
+  type: { type: String, enum: ['voice', 'video'], default: 'video' },
+  participants: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  startsAt: { type: Date, required: true },
+  endsAt: { type: Date, required: true },
+  remindAt: { type: Date },
+  reminderSentAt: { type: Date },
+  joinCode: { type: String, required: true, unique: true },
+  status: {
+    type: String,
+    enum: ['scheduled', 'started', 'cancelled', 'expired'],
+    default: 'scheduled',
+  },
+  callId: { type: String },
+  startedAt: { type: Date },
+  cancelledAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+scheduledCallSchema.index({ chatId: 1, status: 1, startsAt: 1 });
+scheduledCallSchema.index({ status: 1, startsAt: 1 });
+scheduledCallSchema.index({ status: 1, remindAt: 1 });
+
+export const ScheduledCall = mongoose.models.ScheduledCall || mongoose.model<IScheduledCall>('ScheduledCall', scheduledCallSchema);
@@ -0,0 +1,59 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type SecurityEventType =
+  | 'login_succeeded'
+  | 'login_failed'
+  | 'login_flagged' // new device or location, waiting for the user's approval
+  | 'login_approved'
+  | 'login_denied';
+
+// The user's security log: sign-ins, failed sign-in attempts and what
+// happened to sign-ins from new devices or locations
+export interface ISecurityEvent extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  type: SecurityEventType;
+  deviceId?: string;
+  ip?: string;
+  country?: string; // ISO 3166-1 alpha-2
+  city?: string;
+  asn?: number;
+  asOrganization?: string;
+  userAgent?: string;
+  loginApprovalId?: Types.ObjectId;
+  details?: Record<string, unknown>;
+  createdAt: Date;
+}
+
+const securityEventSchema = new Schema<ISecurityEvent>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  type: {
+    type: String,
+    enum: ['login_succeeded', 'login_failed', 'login_flagged', 'login_approved', 'login_denied'],
+    required: true,
+  },
+  deviceId: { type: String },
+  ip: { type: String },
+  country: { type: String },
+  city: { type: String },
+  asn: { type: Number },
+  asOrganization: { type: String },
+  userAgent: { type: String },
+  loginApprovalId: { type: Schema.Types.ObjectId, ref: 'LoginApproval' },
+  details: { type: Schema.Types.Mixed },
+}, {
+  timestamps: { createdAt: true, updatedAt: false },
+  versionKey: false,
+});
+
+// Indexes
+securityEventSchema.index({ userId: 1, createdAt: -1 });
+securityEventSchema.index({ userId: 1, type: 1, deviceId: 1 });
+securityEventSchema.index({ userId: 1, type: 1, country: 1 });
+securityEventSchema.index({ type: 1, createdAt: -1 });
+securityEventSchema.index({ country: 1, createdAt: -1 });
+securityEventSchema.index({ ip: 1, createdAt: -1 });
+securityEventSchema.index({ createdAt: 1 }, { expireAfterSeconds: 365 * 24 * 60 * 60 }); // keep a year
+
+export const SecurityEvent = mongoose.models.SecurityEvent ||
+  mongoose.model<ISecurityEvent>('SecurityEvent', securityEventSchema);
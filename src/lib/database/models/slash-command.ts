@@ -0,0 +1,40 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A custom slash command registered in a chat and answered by a bot webhook
+export interface ISlashCommand extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  name: string; // without the leading slash, lowercase
+  description?: string;
+  usage?: string; // e.g. "[environment] [branch]"
+  url: string; // bot endpoint invocations are POSTed to
+  signingSecret: string; // HMAC key the bot uses to verify requests
+  enabled: boolean;
+  createdBy: Types.ObjectId;
+  lastUsedAt?: Date;
+  invocationCount: number;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const slashCommandSchema = new Schema<ISlashCommand>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  name: { type: String, required: true, lowercase: true, trim: true },
+  description: { type: String, trim: true },
+  usage: { type: String, trim: true },
+  url: { type: String, required: true },
+  signingSecret: { type: String, required: true },
+  enabled: { type: Boolean, default: true },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  lastUsedAt: { type: Date },
+  invocationCount: { type: Number, default: 0 },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+slashCommandSchema.index({ chatId: 1, name: 1 }, { unique: true });
+
+export const SlashCommand = mongoose.models.SlashCommand ||
+  mongoose.model<ISlashCommand>('SlashCommand', slashCommandSchema);
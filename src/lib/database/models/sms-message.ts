@@ -0,0 +1,61 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type SmsStatus = 'queued' | 'sent' | 'delivered' | 'undelivered' | 'failed';
+export type SmsPurpose = 'otp' | 'welcome' | 'notification' | 'other';
+
+// One outbound SMS, for cost and deliverability reporting. The number is
+// only kept hashed and masked.
+export interface ISmsMessage extends Document {
+  _id: Types.ObjectId;
+  provider: 'twilio';
+  providerMessageId?: string;
+  purpose: SmsPurpose;
+  phoneHash: string; // SHA-256 of the E.164 number
+  maskedNumber: string;
+  countryCode?: string; // ISO 3166-1 alpha-2
+  carrier?: string;
+  lineType?: string; // mobile, landline, voip...
+  status: SmsStatus;
+  errorCode?: string;
+  errorMessage?: string;
+  segments?: number;
+  price?: number; // positive, in priceUnit
+  priceUnit?: string;
+  sentAt: Date;
+  statusUpdatedAt: Date;
+  deliveredAt?: Date;
+}
+
+const smsMessageSchema = new Schema<ISmsMessage>({
+  provider: { type: String, enum: ['twilio'], default: 'twilio' },
+  providerMessageId: { type: String },
+  purpose: { type: String, enum: ['otp', 'welcome', 'notification', 'other'], default: 'other' },
+  phoneHash: { type: String, required: true },
+  maskedNumber: { type: String, required: true },
+  countryCode: { type: String, uppercase: true },
+  carrier: { type: String },
+  lineType: { type: String },
+  status: {
+    type: String,
+    enum: ['queued', 'sent', 'delivered', 'undelivered', 'failed'],
+    default: 'queued',
+  },
+  errorCode: { type: String },
+  errorMessage: { type: String },
+  segments: { type: Number },
+  price: { type: Number },
+  priceUnit: { type: String },
+  sentAt: { type: Date, default: Date.now },
+  statusUpdatedAt: { type: Date, default: Date.now },
+  deliveredAt: { type: Date },
+}, {
+  versionKey: false,
+});
+
+// Indexes
+smsMessageSchema.index({ providerMessageId: 1 }, { unique: true, sparse: true });
+smsMessageSchema.index({ phoneHash: 1, sentAt: -1 });
+smsMessageSchema.index({ sentAt: 1 }, { expireAfterSeconds: 180 * 24 * 60 * 60 }); // keep 180 days
+
+export const SmsMessage = mongoose.models.SmsMessage ||
+  mongoose.model<ISmsMessage>('SmsMessage', smsMessageSchema);
@@ -0,0 +1,36 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// A stored blob shared by every media record with the same content. Uploads
+// of a file that is already stored only add a reference; the blob is removed
+// when the last media record pointing at it is deleted.
+export interface IStorageObject extends Document {
+  _id: Types.ObjectId;
+  key: string; // S3 key, the filename of the media records sharing it
+  checksumSHA256: string;
+  size: number;
+  thumbnailKeys: string[]; // removed along with the file
+  refCount: number;
+  verifiedAt?: Date; // last consistency check
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const storageObjectSchema = new Schema<IStorageObject>({
+  key: { type: String, required: true },
+  checksumSHA256: { type: String, required: true },
+  size: { type: Number, required: true },
+  thumbnailKeys: [{ type: String }],
+  refCount: { type: Number, required: true, min: 0 },
+  verifiedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+storageObjectSchema.index({ key: 1 }, { unique: true });
+storageObjectSchema.index({ checksumSHA256: 1 });
+storageObjectSchema.index({ verifiedAt: 1 });
+
+export const StorageObject = mongoose.models.StorageObject ||
+  mongoose.model<IStorageObject>('StorageObject', storageObjectSchema);
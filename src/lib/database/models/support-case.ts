@@ -0,0 +1,57 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+export type SupportCaseStatus = 'open' | 'waiting' | 'closed';
+
+// A piece of staff work about one user: the reports that started it, the
+// moderation actions taken and what was said to the user along the way, so
+// whoever picks it up next has the whole story
+export interface ISupportCase extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  title: string;
+  status: SupportCaseStatus; // waiting: on the user or someone outside support
+  openedBy: Types.ObjectId;
+  assignedTo?: Types.ObjectId;
+  reportIds: Types.ObjectId[];
+  moderationActionIds: Types.ObjectId[];
+  communications: {
+    _id: Types.ObjectId;
+    channel: 'email' | 'in_app' | 'phone' | 'other';
+    direction: 'inbound' | 'outbound';
+    summary: string;
+    adminId: Types.ObjectId;
+    at: Date;
+  }[];
+  closedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const supportCaseSchema = new Schema<ISupportCase>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  title: { type: String, required: true, maxlength: 200 },
+  status: { type: String, enum: ['open', 'waiting', 'closed'], default: 'open' },
+  openedBy: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+  assignedTo: { type: Schema.Types.ObjectId, ref: 'Admin' },
+  reportIds: [{ type: Schema.Types.ObjectId, ref: 'Report' }],
+  moderationActionIds: [{ type: Schema.Types.ObjectId, ref: 'ModerationAction' }],
+  communications: [{
+    channel: { type: String, enum: ['email', 'in_app', 'phone', 'other'], required: true },
+    direction: { type: String, enum: ['inbound', 'outbound'], required: true },
+    summary: { type: String, required: true, maxlength: 5000 },
+    adminId: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+    at: { type: Date, default: Date.now },
+  }],
+  closedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+supportCaseSchema.index({ userId: 1, createdAt: -1 });
+supportCaseSchema.index({ assignedTo: 1, status: 1, updatedAt: -1 });
+supportCaseSchema.index({ reportIds: 1 });
+
+export const SupportCase = mongoose.models.SupportCase ||
+  mongoose.model<ISupportCase>('SupportCase', supportCaseSchema);
@@ -0,0 +1,27 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// One row per user per UTC day they did anything; the source for active
+// user counts and retention
+export interface IUserActivity extends Document {
+  _id: Types.ObjectId;
+  userId: Types.ObjectId;
+  day: Date; // UTC midnight
+  firstSeenAt: Date;
+  lastSeenAt: Date;
+}
+
+const userActivitySchema = new Schema<IUserActivity>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  day: { type: Date, required: true },
+  firstSeenAt: { type: Date, required: true },
+  lastSeenAt: { type: Date, required: true },
+}, {
+  versionKey: false,
+});
+
+// Indexes
+userActivitySchema.index({ userId: 1, day: 1 }, { unique: true });
+userActivitySchema.index({ day: 1 });
+
+export const UserActivity = mongoose.models.UserActivity ||
+  mongoose.model<IUserActivity>('UserActivity', userActivitySchema);
@@ -1,19 +1,63 @@
 import mongoose, { Schema, Document, Types } from 'mongoose';
+import { SUPPORTED_LOCALES, DEFAULT_LOCALE } from '../../i18n';
+import { RestrictionMode } from './moderation-action';
+import { IGuestCapabilities } from './guest-link';
+
+// Regions user data can be kept in, see compliance/data-residency
+export const DATA_REGIONS = ['eu', 'us', 'apac'] as const;
+export type DataRegion = typeof DATA_REGIONS[number];
 
 export interface IUser extends Document {
   _id: Types.ObjectId;
-  phoneNumber: string;
+  phoneNumber: string; // E.164
+  countryCode?: string; // ISO 3166-1 alpha-2 region of phoneNumber
+  dataRegion?: DataRegion; // where the user's files are stored; derived from countryCode when unset
+  organizationId?: Types.ObjectId; // tenant the user belongs to; unset for the default, shared pool
   email?: string;
   username?: string;
+  contactCode?: string; // encoded in the user's add-contact link and QR code; rotating it revokes old ones
   displayName: string;
   avatar?: string;
   status: string;
+  activityStatus?: { // short-lived "At work" style status shown in contact lists
+    emoji?: string;
+    text?: string;
+    expiresAt?: Date; // cleared on read once past
+    updatedAt: Date;
+  };
+  language: string; // preferred locale for server-generated text
+  timezone?: string; // IANA zone, e.g. "Europe/Berlin"; UTC when unset
   isOnline: boolean;
   lastSeen: Date;
   isVerified: boolean;
   isBanned: boolean;
   banReason?: string;
   banExpiresAt?: Date;
+  restriction?: { // restricted mode, applied by a moderation action
+    mode: RestrictionMode;
+    actionId: Types.ObjectId;
+    expiresAt?: Date;
+  };
+  trustedAt?: Date; // new-account limits stopped applying
+  mergedInto?: Types.ObjectId; // set on duplicate accounts folded into another
+  staffTags?: string[]; // set by admins, e.g. "vip" or "repeat-reporter"; never selected by default
+  bridge?: { // set on puppet accounts representing remote users, email senders and integrations
+    protocol: 'matrix' | 'xmpp' | 'email' | 'webhook' | 'bot';
+    remoteId: string;
+  };
+  scim?: { // set on accounts provisioned by the identity provider over SCIM
+    userName: string;
+    externalId?: string; // the provider's own id
+    provisionedAt: Date;
+    deactivatedAt?: Date; // deprovisioned; the account is banned until reactivated
+  };
+  guest?: { // set on time-boxed guest accounts created by a guest link, see auth/guest-access
+    linkId: Types.ObjectId;
+    chatId: Types.ObjectId; // the one chat the guest is in
+    expiresAt: Date;
+    capabilities: IGuestCapabilities;
+    endedAt?: Date; // expired or removed; the account can't sign in again
+  };
   createdAt: Date;
   updatedAt: Date;
   
@@ -33,10 +77,49 @@ export interface IUser extends Document {
     callNotifications: boolean;
     emailNotifications: boolean;
     pushToken?: string;
+    messageSound: string;
+    groupSound: string;
+    callRingtone: string;
+    vibration: 'default' | 'short' | 'long' | 'heartbeat' | 'none';
+  };
+  
+  // Call audio processing; unset follows the server default
+  callSettings: {
+    noiseSuppression?: boolean;
+    echoCancellation?: boolean;
+    autoGainControl?: boolean;
+  };
+  
+  // Do Not Disturb: quiet periods for push notifications, in the user's timezone
+  doNotDisturb: {
+    enabled: boolean; // manual switch
+    until?: Date; // manual DND ends by itself at this time
+    schedules: {
+      days: number[]; // days the period starts on, 0 = Sunday; empty = every day
+      start: string; // "HH:mm"
+      end: string; // "HH:mm"; before start means the period runs past midnight
+    }[];
+    mode: 'suppress' | 'digest'; // drop pushes, or batch them until the period ends
+    allowStarredCalls: boolean; // calls from starred contacts ring through
+  };
+  
+  // Automatic deletion of old messages, see compliance/message-retention
+  messageRetention: {
+    days?: number; // messages older than this are deleted; unset keeps them
+    exportBeforeDelete: boolean; // save purged messages to a file the user can download
+    adminOverride?: { // takes precedence over days
+      days: number | null; // null exempts the account from purges
+      adminId: Types.ObjectId;
+      reason?: string;
+      setAt: Date;
+    };
+    lastPurgedAt?: Date;
+    exports: { key: string; count: number; createdAt: Date }[]; // files saved before purges, newest last
   };
   
   // Contact Lists
   contacts: Types.ObjectId[];
+  starredContacts: Types.ObjectId[];
   blockedUsers: Types.ObjectId[];
   
   // Device Information
@@ -45,22 +128,101 @@ export interface IUser extends Document {
     platform: 'ios' | 'android' | 'web';
     lastActive: Date;
     pushToken?: string;
+    voipToken?: string; // iOS PushKit token, used to ring CallKit
+    apnsEnvironment?: 'production' | 'sandbox';
+    appVersion?: string; // reported at login
+    osVersion?: string;
+    deviceModel?: string;
+    linkedBy?: string; // deviceId of the phone that linked this device by QR code
+    linkedAt?: Date;
+    lowDataMode?: boolean; // reduced payloads and call bitrates, see realtime/low-data
+    location?: { // where the device last signed in from
+      ip?: string;
+      country?: string;
+      city?: string;
+      asn?: number;
+      asOrganization?: string;
+      at: Date;
+    };
+  }[];
+  
+  // Latest decision per consent type
+  consents: {
+    key: string;
+    version: number; // version of the consent text the decision was made on
+    accepted: boolean;
+    decidedAt: Date;
+    ip?: string;
+    userAgent?: string;
+  }[];
+  
+  // Newest accepted version per legal notice; the full history is in LegalAcceptance
+  legalAcceptances: {
+    key: string;
+    version: number;
+    acceptedAt: Date;
   }[];
 }
 
 const userSchema = new Schema<IUser>({
   phoneNumber: { type: String, required: true, unique: true, index: true },
+  countryCode: { type: String, uppercase: true },
+  dataRegion: { type: String, enum: DATA_REGIONS },
+  organizationId: { type: Schema.Types.ObjectId, ref: 'Organization' },
   email: { type: String, sparse: true, unique: true },
   username: { type: String, sparse: true, unique: true },
+  contactCode: { type: String, sparse: true, unique: true },
   displayName: { type: String, required: true },
   avatar: { type: String },
   status: { type: String, default: 'Hey there! I am using WhatsApp.' },
+  activityStatus: {
+    emoji: { type: String },
+    text: { type: String },
+    expiresAt: { type: Date },
+    updatedAt: { type: Date },
+  },
+  language: { type: String, enum: SUPPORTED_LOCALES, default: DEFAULT_LOCALE },
+  timezone: { type: String },
   isOnline: { type: Boolean, default: false },
   lastSeen: { type: Date, default: Date.now },
   isVerified: { type: Boolean, default: false },
   isBanned: { type: Boolean, default: false },
   banReason: { type: String },
   banExpiresAt: { type: Date },
+  restriction: {
+    type: {
+      mode: { type: String, enum: ['shadow', 'review'], required: true },
+      actionId: { type: Schema.Types.ObjectId, ref: 'ModerationAction', required: true },
+      expiresAt: { type: Date },
+    },
+    default: undefined,
+  },
+  trustedAt: { type: Date },
+  mergedInto: { type: Schema.Types.ObjectId, ref: 'User' },
+  staffTags: { type: [String], default: undefined, select: false },
+  bridge: {
+    protocol: { type: String, enum: ['matrix', 'xmpp', 'email', 'webhook', 'bot'] },
+    remoteId: { type: String },
+  },
+  scim: {
+    userName: { type: String },
+    externalId: { type: String },
+    provisionedAt: { type: Date },
+    deactivatedAt: { type: Date },
+  },
+  guest: {
+    type: {
+      linkId: { type: Schema.Types.ObjectId, ref: 'GuestLink', required: true },
+      chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+      expiresAt: { type: Date, required: true },
+      capabilities: {
+        calls: { type: Boolean, default: false },
+        fileUpload: { type: Boolean, default: false },
+      },
+      endedAt: { type: Date },
+    },
+    default: undefined,
+  },
   
   privacySettings: {
     lastSeen: { type: String, enum: ['everyone', 'contacts', 'nobody'], default: 'everyone' },
@@ -76,9 +238,54 @@ const userSchema = new Schema<IUser>({
     callNotifications: { type: Boolean, default: true },
     emailNotifications: { type: Boolean, default: true },
     pushToken: { type: String },
+    messageSound: { type: String, default: 'message_tone' },
+    groupSound: { type: String, default: 'group_message' },
+    callRingtone: { type: String, default: 'ringtone' },
+    vibration: { type: String, enum: ['default', 'short', 'long', 'heartbeat', 'none'], default: 'default' },
+  },
+  
+  callSettings: {
+    noiseSuppression: { type: Boolean },
+    echoCancellation: { type: Boolean },
+    autoGainControl: { type: Boolean },
+  },
+  
+  doNotDisturb: {
+    enabled: { type: Boolean, default: false },
+    until: { type: Date },
+    schedules: [{
+      _id: false,
+      days: [{ type: Number, min: 0, max: 6 }],
+      start: { type: String, required: true },
+      end: { type: String, required: true },
+    }],
+    mode: { type: String, enum: ['suppress', 'digest'], default: 'digest' },
+    allowStarredCalls: { type: Boolean, default: true },
+  },
+  
+  messageRetention: {
+    days: { type: Number, min: 1 },
+    exportBeforeDelete: { type: Boolean, default: false },
+    adminOverride: {
+      type: {
+        days: { type: Number, min: 1, default: null },
+        adminId: { type: Schema.Types.ObjectId, ref: 'Admin', required: true },
+        reason: { type: String },
+        setAt: { type: Date, required: true },
+      },
+      default: undefined,
+    },
+    lastPurgedAt: { type: Date },
+    exports: [{
+      _id: false,
+      key: { type: String, required: true },
+      count: { type: Number, required: true },
+      createdAt: { type: Date, required: true },
+    }],
   },
   
   contacts: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  starredContacts: [{ type: Schema.Types.ObjectId, ref: 'User' }],
   blockedUsers: [{ type: Schema.Types.ObjectId, ref: 'User' }],
   
   devices: [{
@@ -86,6 +293,39 @@ const userSchema = new Schema<IUser>({
     platform: { type: String, enum: ['ios', 'android', 'web'], required: true },
     lastActive: { type: Date, default: Date.now },
     pushToken: { type: String },
+    voipToken: { type: String },
+    apnsEnvironment: { type: String, enum: ['production', 'sandbox'] },
+    appVersion: { type: String },
+    osVersion: { type: String },
+    deviceModel: { type: String },
+    linkedBy: { type: String },
+    linkedAt: { type: Date },
+    lowDataMode: { type: Boolean, default: false },
+    location: {
+      ip: { type: String },
+      country: { type: String },
+      city: { type: String },
+      asn: { type: Number },
+      asOrganization: { type: String },
+      at: { type: Date },
+    },
+  }],
+  
+  consents: [{
+    _id: false,
+    key: { type: String, required: true },
+    version: { type: Number, required: true },
+    accepted: { type: Boolean, required: true },
+    decidedAt: { type: Date, default: Date.now },
+    ip: { type: String },
+    userAgent: { type: String },
+  }],
+  
+  legalAcceptances: [{
+    _id: false,
+    key: { type: String, required: true },
+    version: { type: Number, required: true },
+    acceptedAt: { type: Date, default: Date.now },
   }],
 }, {
   timestamps: true,
@@ -98,6 +338,25 @@ userSchema.index({ email: 1 });
 userSchema.index({ username: 1 });
 userSchema.index({ isOnline: 1 });
 userSchema.index({ lastSeen: 1 });
+userSchema.index({ 'bridge.protocol': 1, 'bridge.remoteId': 1 }, { unique: true, sparse: true });
+userSchema.index({ 'scim.userName': 1 }, { unique: true, sparse: true });
+userSchema.index({ 'scim.externalId': 1 }, { sparse: true });
+userSchema.index({ 'guest.expiresAt': 1 }, { sparse: true });
+userSchema.index({ 'guest.linkId': 1 }, { sparse: true });
+userSchema.index({ 'consents.key': 1, 'consents.version': 1 });
+userSchema.index({ 'restriction.actionId': 1 }, { sparse: true });
+userSchema.index({ contacts: 1 });
+userSchema.index({ blockedUsers: 1 });
+// Admin user list: default sort, name sort and the common filters
+userSchema.index({ createdAt: -1 });
+userSchema.index({ displayName: 1 });
+userSchema.index({ isBanned: 1, createdAt: -1 });
+userSchema.index({ countryCode: 1, createdAt: -1 });
+userSchema.index({ dataRegion: 1, createdAt: -1 });
+userSchema.index({ organizationId: 1, createdAt: -1 }, { sparse: true });
+userSchema.index({ staffTags: 1 }, { sparse: true });
+userSchema.index({ 'messageRetention.days': 1, 'messageRetention.lastPurgedAt': 1 }, { sparse: true });
+userSchema.index({ 'messageRetention.adminOverride.days': 1, 'messageRetention.lastPurgedAt': 1 }, { sparse: true });
 
 export const User = mongoose.models.User || mongoose.model<IUser>('User', userSchema);
 
@@ -0,0 +1,67 @@
+import mongoose, { Schema, Document, Types } from 'mongoose';
+
+// open: every member can talk; speakers: only group admins and the
+// channel's speakers can, everyone else listens
+export type VoiceChannelSpeakerMode = 'open' | 'speakers';
+
+// Someone connected to a voice channel, from one socket
+export interface IVoiceChannelParticipant {
+  userId: Types.ObjectId;
+  socketId: string; // the connection they joined from; gone means they left
+  deviceId?: string;
+  joinedAt: Date;
+  muted: boolean;
+  mutedByAdmin: boolean; // only an admin can take it back
+  pushToTalk: boolean; // the client transmits only while the key is held
+}
+
+// An always-on audio room of a group. Members drop in and out without
+// ringing anyone; the channel lives on with nobody in it until an admin
+// removes it, see webrtc/voice-channels.
+export interface IVoiceChannel extends Document {
+  _id: Types.ObjectId;
+  chatId: Types.ObjectId;
+  name: string;
+  topic?: string;
+  createdBy: Types.ObjectId;
+  pushToTalk: boolean; // participants must use push-to-talk
+  speakerMode: VoiceChannelSpeakerMode;
+  speakers: Types.ObjectId[]; // may talk in 'speakers' mode besides the admins
+  participants: IVoiceChannelParticipant[];
+  removedAt?: Date;
+  createdAt: Date;
+  updatedAt: Date;
+}
+
+const participantSchema = new Schema<IVoiceChannelParticipant>({
+  userId: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  socketId: { type: String, required: true },
+  deviceId: { type: String },
+  joinedAt: { type: Date, default: Date.now },
+  muted: { type: Boolean, default: false },
+  mutedByAdmin: { type: Boolean, default: false },
+  pushToTalk: { type: Boolean, default: false },
+}, { _id: false });
+
+const voiceChannelSchema = new Schema<IVoiceChannel>({
+  chatId: { type: Schema.Types.ObjectId, ref: 'Chat', required: true },
+  name: { type: String, required: true, maxlength: 50 },
+  topic: { type: String, maxlength: 200 },
+  createdBy: { type: Schema.Types.ObjectId, ref: 'User', required: true },
+  pushToTalk: { type: Boolean, default: false },
+  speakerMode: { type: String, enum: ['open', 'speakers'], default: 'open' },
+  speakers: [{ type: Schema.Types.ObjectId, ref: 'User' }],
+  participants: [participantSchema],
+  removedAt: { type: Date },
+}, {
+  timestamps: true,
+  versionKey: false,
+});
+
+// Indexes
+voiceChannelSchema.index({ chatId: 1, removedAt: 1, createdAt: 1 });
+voiceChannelSchema.index({ 'participants.userId': 1 });
+voiceChannelSchema.index({ 'participants.socketId': 1 });
+
+export const VoiceChannel = mongoose.models.VoiceChannel ||
+  mongoose.model<IVoiceChannel>('VoiceChannel', voiceChannelSchema);
@@ -0,0 +1,36 @@
+import mongoose, { Connection, Model } from 'mongoose';
+import { DATA_REGIONS, DataRegion } from './models/user';
+import { MessageBucket, IMessageBucket, messageBucketSchema } from './models/message-bucket';
+
+// Message archives (message buckets) of chats assigned to a region are kept
+// in that region's database when MONGODB_URI_<REGION> is set, e.g.
+// MONGODB_URI_EU. Everything else stays in the main database.
+const connections = new Map<DataRegion, Connection>();
+
+function regionalUri(region: DataRegion): string | undefined {
+  return process.env[`MONGODB_URI_${region.toUpperCase()}`];
+}
+
+// Whether any region has a database of its own
+export function hasRegionalArchives(): boolean {
+  return DATA_REGIONS.some(region => !!regionalUri(region));
+}
+
+// Message bucket model for chats in a region
+export function getArchiveModel(region?: DataRegion): Model<IMessageBucket> {
+  const uri = region && regionalUri(region);
+  if (!region || !uri) return MessageBucket;
+
+  let connection = connections.get(region);
+  if (!connection) {
+    connection = mongoose.createConnection(uri);
+    connections.set(region, connection);
+  }
+  return connection.models.MessageBucket ||
+    connection.model<IMessageBucket>('MessageBucket', messageBucketSchema);
+}
+
+// Every database holding message buckets, for lookups by message id
+export function getArchiveModels(): Model<IMessageBucket>[] {
+  return [MessageBucket, ...DATA_REGIONS.filter(region => !!regionalUri(region)).map(getArchiveModel)];
+}
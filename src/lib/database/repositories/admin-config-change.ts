@@ -0,0 +1,71 @@
+import { Types } from 'mongoose';
+import { AdminConfigChange, IAdminConfigChange, AdminConfigChangeStatus } from '../models/admin-config-change';
+
+export class AdminConfigChangeRepository {
+  // Create config change
+  async create(changeData: Partial<IAdminConfigChange>): Promise<IAdminConfigChange> {
+    const change = new AdminConfigChange(changeData);
+    return await change.save();
+  }
+
+  // Find config change by ID
+  async findById(id: string | Types.ObjectId): Promise<IAdminConfigChange | null> {
+    return await AdminConfigChange.findById(id).exec();
+  }
+
+  // Get config changes newest first, optionally by status
+  async list(
+    status?: AdminConfigChangeStatus,
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<{ changes: IAdminConfigChange[], total: number }> {
+    const query = status ? { status } : {};
+    const [changes, total] = await Promise.all([
+      AdminConfigChange.find(query).select('-values').sort({ createdAt: -1 }).limit(limit).skip(offset).exec(),
+      AdminConfigChange.countDocuments(query).exec(),
+    ]);
+    return { changes, total };
+  }
+
+  // Record a review, only while the change is still pending
+  async review(
+    id: string | Types.ObjectId,
+    status: 'applied' | 'rejected',
+    reviewedBy: string,
+    reviewNote?: string
+  ): Promise<IAdminConfigChange | null> {
+    return await AdminConfigChange.findOneAndUpdate(
+      { _id: id, status: 'pending' },
+      {
+        status,
+        reviewedBy: new Types.ObjectId(reviewedBy),
+        reviewedAt: new Date(),
+        ...(reviewNote && { reviewNote }),
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Put an approved change back up for review after it failed to apply
+  async reopen(id: string | Types.ObjectId): Promise<void> {
+    await AdminConfigChange.updateOne(
+      { _id: id, status: 'applied', appliedVersion: { $exists: false } },
+      { $set: { status: 'pending' }, $unset: { reviewedBy: 1, reviewedAt: 1, reviewNote: 1 } }
+    ).exec();
+  }
+
+  // Update config change
+  async update(id: string | Types.ObjectId, updateData: Partial<IAdminConfigChange>): Promise<IAdminConfigChange | null> {
+    return await AdminConfigChange.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Supersede pending changes proposed against an older version of the
+  // base configuration or an overlay
+  async supersedeBefore(version: number, environment?: string): Promise<number> {
+    const result = await AdminConfigChange.updateMany(
+      { status: 'pending', environment: environment ?? null, baseVersion: { $lt: version } },
+      { status: 'superseded' }
+    ).exec();
+    return result.modifiedCount;
+  }
+}
@@ -0,0 +1,8 @@
+import { AdminConfigOverlay, IAdminConfigOverlay } from '../models/admin-config-overlay';
+
+export class AdminConfigOverlayRepository {
+  // Get every environment overlay by name
+  async list(): Promise<IAdminConfigOverlay[]> {
+    return await AdminConfigOverlay.find().sort({ environment: 1 }).exec();
+  }
+}
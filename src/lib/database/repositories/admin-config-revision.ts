@@ -0,0 +1,22 @@
+import { AdminConfigRevision, IAdminConfigRevision } from '../models/admin-config-revision';
+
+export class AdminConfigRevisionRepository {
+  // Find the revision of a version of the base configuration or an overlay
+  async findByVersion(version: number, environment?: string): Promise<IAdminConfigRevision | null> {
+    return await AdminConfigRevision.findOne({ environment: environment ?? null, version }).exec();
+  }
+
+  // Get revisions newest first, without their contents
+  async list(
+    environment?: string,
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<{ revisions: IAdminConfigRevision[], total: number }> {
+    const query = { environment: environment ?? null };
+    const [revisions, total] = await Promise.all([
+      AdminConfigRevision.find(query).select('-sections').sort({ version: -1 }).limit(limit).skip(offset).exec(),
+      AdminConfigRevision.countDocuments(query).exec(),
+    ]);
+    return { revisions, total };
+  }
+}
@@ -0,0 +1,43 @@
+import { Types } from 'mongoose';
+import { AdminNote, IAdminNote } from '../models/admin-note';
+
+export class AdminNoteRepository {
+  // Create admin note
+  async create(noteData: Partial<IAdminNote>): Promise<IAdminNote> {
+    const note = new AdminNote(noteData);
+    return await note.save();
+  }
+
+  // Find admin note by ID
+  async findById(id: string | Types.ObjectId): Promise<IAdminNote | null> {
+    return await AdminNote.findById(id).exec();
+  }
+
+  // Get notes about a user, pinned first, then newest first
+  async findByUser(userId: string | Types.ObjectId, limit: number = 100): Promise<IAdminNote[]> {
+    return await AdminNote.find({ userId })
+      .populate('authorId', 'displayName username')
+      .sort({ pinned: -1, createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Get the notes on a case, oldest first
+  async findByCase(caseId: string | Types.ObjectId): Promise<IAdminNote[]> {
+    return await AdminNote.find({ caseId })
+      .populate('authorId', 'displayName username')
+      .sort({ createdAt: 1 })
+      .exec();
+  }
+
+  // Update admin note
+  async update(id: string | Types.ObjectId, updateData: Partial<IAdminNote>): Promise<IAdminNote | null> {
+    return await AdminNote.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Delete admin note
+  async delete(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await AdminNote.findByIdAndDelete(id).exec();
+    return !!result;
+  }
+}
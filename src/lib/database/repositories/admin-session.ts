@@ -0,0 +1,39 @@
+import { Types } from 'mongoose';
+import { AdminSession, IAdminSession } from '../models/admin-session';
+
+export class AdminSessionRepository {
+  // Create admin session
+  async create(sessionData: Partial<IAdminSession>): Promise<IAdminSession> {
+    const session = new AdminSession(sessionData);
+    return await session.save();
+  }
+
+  // Find admin session by ID
+  async findById(id: string | Types.ObjectId): Promise<IAdminSession | null> {
+    return await AdminSession.findById(id).exec();
+  }
+
+  // An admin's unexpired, unrevoked sessions, newest first
+  async findActive(adminId: string | Types.ObjectId): Promise<IAdminSession[]> {
+    return await AdminSession.find({ adminId, revokedAt: { $exists: false }, expiresAt: { $gt: new Date() } })
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Record activity, at most once per interval
+  async touch(id: string | Types.ObjectId, notSince: Date): Promise<void> {
+    await AdminSession.updateOne(
+      { _id: id, lastSeenAt: { $lt: notSince } },
+      { lastSeenAt: new Date() }
+    ).exec();
+  }
+
+  // Revoke sessions that are still active
+  async revoke(ids: (string | Types.ObjectId)[], reason: NonNullable<IAdminSession['revokedReason']>): Promise<number> {
+    const result = await AdminSession.updateMany(
+      { _id: { $in: ids }, revokedAt: { $exists: false } },
+      { revokedAt: new Date(), revokedReason: reason }
+    ).exec();
+    return result.modifiedCount;
+  }
+}
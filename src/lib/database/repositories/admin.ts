@@ -0,0 +1,77 @@
+import { Types } from 'mongoose';
+import { Admin, IAdmin } from '../models/admin';
+import { CryptoUtils } from '../../utils/crypto';
+
+export class AdminRepository {
+  // Create admin
+  async create(adminData: Partial<IAdmin>, password?: string): Promise<IAdmin> {
+    const admin = new Admin(adminData);
+
+    if (password) {
+      const { hash, salt } = await CryptoUtils.hashPassword(password);
+      admin.passwordHash = hash;
+      admin.passwordSalt = salt;
+    }
+
+    return await admin.save();
+  }
+
+  // Find admin by ID
+  async findById(id: string | Types.ObjectId): Promise<IAdmin | null> {
+    return await Admin.findById(id).exec();
+  }
+
+  // Find admin by email
+  async findByEmail(email: string): Promise<IAdmin | null> {
+    return await Admin.findOne({ email: email.toLowerCase() }).exec();
+  }
+
+  // Find the admin signed in with an IdP identity
+  async findBySso(protocol: 'oidc' | 'saml', subject: string): Promise<IAdmin | null> {
+    return await Admin.findOne({ 'sso.protocol': protocol, 'sso.subject': subject }).exec();
+  }
+
+  // Find admin by username
+  async findByUsername(username: string): Promise<IAdmin | null> {
+    return await Admin.findOne({ username }).exec();
+  }
+
+  // Update admin
+  async update(id: string | Types.ObjectId, updateData: Partial<IAdmin>): Promise<IAdmin | null> {
+    return await Admin.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Set admin password
+  async setPassword(id: string | Types.ObjectId, password: string): Promise<boolean> {
+    const { hash, salt } = await CryptoUtils.hashPassword(password);
+    const result = await Admin.findByIdAndUpdate(id, {
+      passwordHash: hash,
+      passwordSalt: salt,
+    }).exec();
+    return !!result;
+  }
+
+  // Verify admin password
+  async verifyPassword(email: string, password: string): Promise<IAdmin | null> {
+    const admin = await Admin.findOne({ email: email.toLowerCase(), isActive: true })
+      .select('+passwordHash +passwordSalt')
+      .exec();
+
+    if (!admin || !admin.passwordHash || !admin.passwordSalt) {
+      return null;
+    }
+
+    const isValid = await CryptoUtils.verifyPassword(password, admin.passwordHash, admin.passwordSalt);
+    return isValid ? admin : null;
+  }
+
+  // Get admins with pagination
+  async getAdmins(limit: number = 20, offset: number = 0, filters?: any): Promise<{ admins: IAdmin[], total: number }> {
+    const query = filters || {};
+    const [admins, total] = await Promise.all([
+      Admin.find(query).limit(limit).skip(offset).exec(),
+      Admin.countDocuments(query).exec()
+    ]);
+    return { admins, total };
+  }
+}
@@ -0,0 +1,48 @@
+import { Types } from 'mongoose';
+import { ApiKey, IApiKey } from '../models/api-key';
+
+export class ApiKeyRepository {
+  // Create API key
+  async create(keyData: Partial<IApiKey>): Promise<IApiKey> {
+    const key = new ApiKey(keyData);
+    return await key.save();
+  }
+
+  // Find API key by the hash of its secret
+  async findByHash(keyHash: string): Promise<IApiKey | null> {
+    return await ApiKey.findOne({ keyHash }).exec();
+  }
+
+  // Find API key by ID
+  async findById(id: string | Types.ObjectId): Promise<IApiKey | null> {
+    return await ApiKey.findById(id).exec();
+  }
+
+  // Get keys, newest first; optionally only an organization's
+  async list(includeRevoked: boolean = false, organizationId?: string): Promise<IApiKey[]> {
+    const query: any = {};
+    if (!includeRevoked) query.revokedAt = { $exists: false };
+    if (organizationId) query.organizationId = organizationId;
+
+    return await ApiKey.find(query)
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Revoke a key; it stops working immediately
+  async revoke(id: string | Types.ObjectId): Promise<IApiKey | null> {
+    return await ApiKey.findOneAndUpdate(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date() } },
+      { new: true }
+    ).exec();
+  }
+
+  // Record a use
+  async recordUse(id: string | Types.ObjectId, ip?: string): Promise<void> {
+    await ApiKey.updateOne(
+      { _id: id },
+      { $set: { lastUsedAt: new Date(), ...(ip && { lastUsedIp: ip }) } }
+    ).exec();
+  }
+}
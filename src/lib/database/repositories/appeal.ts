@@ -0,0 +1,64 @@
+import { Types } from 'mongoose';
+import { Appeal, IAppeal, AppealStatus } from '../models/appeal';
+
+export class AppealRepository {
+  // Create appeal
+  async create(appealData: Partial<IAppeal>): Promise<IAppeal> {
+    const appeal = new Appeal(appealData);
+    return await appeal.save();
+  }
+
+  // Find appeal by ID
+  async findById(id: string | Types.ObjectId): Promise<IAppeal | null> {
+    return await Appeal.findById(id).exec();
+  }
+
+  // Find the user's appeal against an action (or against a ban without one)
+  async findForAction(
+    userId: string | Types.ObjectId,
+    actionId?: string | Types.ObjectId
+  ): Promise<IAppeal | null> {
+    return await Appeal.findOne({ userId, actionId: actionId || null }).exec();
+  }
+
+  // Get a user's appeals, newest first
+  async findByUser(userId: string | Types.ObjectId): Promise<IAppeal[]> {
+    return await Appeal.find({ userId }).sort({ createdAt: -1 }).exec();
+  }
+
+  // Get appeals by status; pending ones oldest first, decided ones newest first (admin)
+  async findByStatus(
+    status: AppealStatus,
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<{ appeals: IAppeal[], total: number }> {
+    const [appeals, total] = await Promise.all([
+      Appeal.find({ status })
+        .populate('userId', 'displayName phoneNumber avatar isBanned banReason banExpiresAt')
+        .sort({ createdAt: status === 'pending' ? 1 : -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      Appeal.countDocuments({ status }).exec(),
+    ]);
+    return { appeals, total };
+  }
+
+  // Record a decision, unless the appeal was already decided
+  async decide(
+    id: string | Types.ObjectId,
+    status: 'approved' | 'denied',
+    decidedBy: string | Types.ObjectId,
+    note?: string
+  ): Promise<IAppeal | null> {
+    const decidedAt = new Date();
+    return await Appeal.findOneAndUpdate(
+      { _id: id, status: 'pending' },
+      {
+        $set: { status, decisionNote: note, decidedBy, decidedAt },
+        $push: { history: { status, by: decidedBy, note, at: decidedAt } },
+      },
+      { new: true }
+    ).exec();
+  }
+}
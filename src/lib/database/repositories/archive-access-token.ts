@@ -0,0 +1,63 @@
+import { Types } from 'mongoose';
+import { ArchiveAccessToken, IArchiveAccessToken } from '../models/archive-access-token';
+
+export class ArchiveAccessTokenRepository {
+  // Create archive access token
+  async create(tokenData: Partial<IArchiveAccessToken>): Promise<IArchiveAccessToken> {
+    const token = new ArchiveAccessToken(tokenData);
+    return await token.save();
+  }
+
+  // Find token by the hash of its secret
+  async findByHash(tokenHash: string): Promise<IArchiveAccessToken | null> {
+    return await ArchiveAccessToken.findOne({ tokenHash }).exec();
+  }
+
+  // Find token by ID
+  async findById(id: string | Types.ObjectId): Promise<IArchiveAccessToken | null> {
+    return await ArchiveAccessToken.findById(id).exec();
+  }
+
+  // Get tokens, newest first; optionally for one chat or organization
+  async list(
+    filter: { chatId?: string; organizationId?: string; includeRevoked?: boolean },
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ tokens: IArchiveAccessToken[]; total: number }> {
+    const query: any = {};
+    if (!filter.includeRevoked) query.revokedAt = { $exists: false };
+    if (filter.chatId) query.chatId = filter.chatId;
+    if (filter.organizationId) query.organizationId = filter.organizationId;
+
+    const [tokens, total] = await Promise.all([
+      ArchiveAccessToken.find(query)
+        .sort({ createdAt: -1 })
+        .skip(offset)
+        .limit(limit)
+        .exec(),
+      ArchiveAccessToken.countDocuments(query),
+    ]);
+
+    return { tokens, total };
+  }
+
+  // Revoke a token; it stops working immediately
+  async revoke(id: string | Types.ObjectId, revokedBy: Types.ObjectId, reason: string): Promise<IArchiveAccessToken | null> {
+    return await ArchiveAccessToken.findOneAndUpdate(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date(), revokedBy, revokeReason: reason } },
+      { new: true }
+    ).exec();
+  }
+
+  // Record a read
+  async recordUse(id: string | Types.ObjectId, ip?: string): Promise<void> {
+    await ArchiveAccessToken.updateOne(
+      { _id: id },
+      {
+        $set: { lastUsedAt: new Date(), ...(ip && { lastUsedIp: ip }) },
+        $inc: { useCount: 1 },
+      }
+    ).exec();
+  }
+}
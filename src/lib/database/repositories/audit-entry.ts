@@ -0,0 +1,22 @@
+import { Types } from 'mongoose';
+import { AuditEntry, IAuditEntry, AuditTargetType } from '../models/audit-entry';
+
+export class AuditEntryRepository {
+  // Record audit entry
+  async create(entryData: Partial<IAuditEntry>): Promise<IAuditEntry> {
+    const entry = new AuditEntry(entryData);
+    return await entry.save();
+  }
+
+  // Get the entries about a user, message, file or report, newest first
+  async findByTarget(
+    targetType: AuditTargetType,
+    targetId: string | Types.ObjectId,
+    limit: number = 50
+  ): Promise<IAuditEntry[]> {
+    return await AuditEntry.find({ targetType, targetId })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+}
@@ -0,0 +1,82 @@
+import { Types } from 'mongoose';
+import { AutomationRule, IAutomationRule, AutomationTrigger } from '../models/automation-rule';
+
+export class AutomationRuleRepository {
+  // Create rule
+  async create(ruleData: Partial<IAutomationRule>): Promise<IAutomationRule> {
+    const rule = new AutomationRule(ruleData);
+    return await rule.save();
+  }
+
+  // Find rule by ID
+  async findById(id: string | Types.ObjectId): Promise<IAutomationRule | null> {
+    return await AutomationRule.findById(id).exec();
+  }
+
+  // Get rules, newest first
+  async list(
+    filter: { event?: AutomationTrigger; enabled?: boolean },
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<IAutomationRule[]> {
+    const query: Record<string, unknown> = {};
+    if (filter.event) query['trigger.event'] = filter.event;
+    if (filter.enabled !== undefined) query.enabled = filter.enabled;
+
+    return await AutomationRule.find(query)
+      .sort({ createdAt: -1 })
+      .skip(offset)
+      .limit(limit)
+      .exec();
+  }
+
+  // Enabled rules for a trigger, oldest first so they run in creation order
+  async findEnabled(event: AutomationTrigger): Promise<IAutomationRule[]> {
+    return await AutomationRule.find({ 'trigger.event': event, enabled: true })
+      .sort({ createdAt: 1 })
+      .exec();
+  }
+
+  async count(): Promise<number> {
+    return await AutomationRule.countDocuments().exec();
+  }
+
+  // Update rule
+  async update(id: string | Types.ObjectId, updateData: Partial<IAutomationRule>): Promise<IAutomationRule | null> {
+    return await AutomationRule.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Delete rule
+  async delete(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await AutomationRule.deleteOne({ _id: id }).exec();
+    return result.deletedCount > 0;
+  }
+
+  // Count a match and how it went
+  async recordRun(
+    id: string | Types.ObjectId,
+    outcome: 'executed' | 'failed' | 'throttled',
+    error?: string
+  ): Promise<IAutomationRule | null> {
+    const now = new Date();
+    const update: Record<string, any> = {
+      $inc: { 'stats.matched': 1, [`stats.${outcome}`]: 1 },
+      $set: { 'stats.lastMatchedAt': now },
+    };
+
+    if (outcome === 'failed') {
+      update.$inc['stats.consecutiveFailures'] = 1;
+      update.$set['stats.lastFailedAt'] = now;
+      update.$set['stats.lastError'] = error;
+    } else if (outcome === 'executed') {
+      update.$set['stats.consecutiveFailures'] = 0;
+    }
+
+    return await AutomationRule.findByIdAndUpdate(id, update, { new: true }).exec();
+  }
+
+  // Turn a rule off, e.g. after it failed too often
+  async disable(id: string | Types.ObjectId, reason: string): Promise<void> {
+    await AutomationRule.updateOne({ _id: id }, { enabled: false, disabledReason: reason }).exec();
+  }
+}
@@ -0,0 +1,57 @@
+import { Types } from 'mongoose';
+import { BlockedMediaHash, IBlockedMediaHash } from '../models/blocked-media-hash';
+
+export class BlockedMediaHashRepository {
+  // Create entry
+  async create(entryData: Partial<IBlockedMediaHash>): Promise<IBlockedMediaHash> {
+    const entry = new BlockedMediaHash(entryData);
+    return await entry.save();
+  }
+
+  // Find entry by ID
+  async findById(id: string | Types.ObjectId): Promise<IBlockedMediaHash | null> {
+    return await BlockedMediaHash.findById(id).exec();
+  }
+
+  // Find entry by exact hash
+  async findByHash(hash: string): Promise<IBlockedMediaHash | null> {
+    return await BlockedMediaHash.findOne({ hash }).exec();
+  }
+
+  // Get every hash for matching; the list stays small enough to scan
+  async findAllHashes(): Promise<Array<{ _id: Types.ObjectId; hash: string }>> {
+    return await BlockedMediaHash.find({}, { hash: 1 }).lean<Array<{ _id: Types.ObjectId; hash: string }>>().exec();
+  }
+
+  // Get entries, newest first (admin)
+  async findAll(
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ entries: IBlockedMediaHash[], total: number }> {
+    const [entries, total] = await Promise.all([
+      BlockedMediaHash.find()
+        .populate('addedBy', 'displayName username')
+        .sort({ createdAt: -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      BlockedMediaHash.countDocuments().exec(),
+    ]);
+
+    return { entries, total };
+  }
+
+  // Count an upload that matched
+  async recordMatch(id: string | Types.ObjectId): Promise<void> {
+    await BlockedMediaHash.updateOne(
+      { _id: id },
+      { $inc: { matchCount: 1 }, $set: { lastMatchedAt: new Date() } }
+    ).exec();
+  }
+
+  // Delete entry
+  async delete(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await BlockedMediaHash.findByIdAndDelete(id).exec();
+    return !!result;
+  }
+}
@@ -0,0 +1,47 @@
+import { Types } from 'mongoose';
+import { Broadcast, IBroadcast } from '../models/broadcast';
+
+export class BroadcastRepository {
+  // Create broadcast
+  async create(broadcastData: Partial<IBroadcast>): Promise<IBroadcast> {
+    const broadcast = new Broadcast(broadcastData);
+    return await broadcast.save();
+  }
+
+  // The live broadcast of a call
+  async findLiveByCallId(callId: string): Promise<IBroadcast | null> {
+    return await Broadcast.findOne({ callId, status: 'live' }).exec();
+  }
+
+  // Find broadcast by its watch link code
+  async findByWatchCode(watchCode: string): Promise<IBroadcast | null> {
+    return await Broadcast.findOne({ watchCode }).exec();
+  }
+
+  // Every live broadcast, for reconciling with the calls behind them
+  async findLive(): Promise<IBroadcast[]> {
+    return await Broadcast.find({ status: 'live' }).exec();
+  }
+
+  // Record a viewer count and keep the peak
+  async recordViewers(id: string | Types.ObjectId, viewerCount: number): Promise<void> {
+    await Broadcast.updateOne(
+      { _id: id },
+      { $set: { viewerCount }, $max: { peakViewers: viewerCount } }
+    ).exec();
+  }
+
+  // Count a viewer joining
+  async incrementTotalViewers(id: string | Types.ObjectId): Promise<void> {
+    await Broadcast.updateOne({ _id: id }, { $inc: { totalViewers: 1 } }).exec();
+  }
+
+  // End a live broadcast. Null when it had already ended.
+  async end(id: string | Types.ObjectId, endedBy?: string | Types.ObjectId): Promise<IBroadcast | null> {
+    return await Broadcast.findOneAndUpdate(
+      { _id: id, status: 'live' },
+      { $set: { status: 'ended', endedAt: new Date(), ...(endedBy && { endedBy }) } },
+      { new: true }
+    ).exec();
+  }
+}
@@ -0,0 +1,69 @@
+import { Types } from 'mongoose';
+import { BulkAction, IBulkAction, BulkActionItemStatus } from '../models/bulk-action';
+
+export class BulkActionRepository {
+  // Create bulk action
+  async create(bulkActionData: Partial<IBulkAction>): Promise<IBulkAction> {
+    const bulkAction = new BulkAction(bulkActionData);
+    return await bulkAction.save();
+  }
+
+  // Find bulk action by ID
+  async findById(id: string | Types.ObjectId): Promise<IBulkAction | null> {
+    return await BulkAction.findById(id).exec();
+  }
+
+  // Get bulk actions newest first, without their items (admin)
+  async list(limit: number = 20, offset: number = 0): Promise<{ bulkActions: IBulkAction[], total: number }> {
+    const [bulkActions, total] = await Promise.all([
+      BulkAction.find().select('-items').sort({ createdAt: -1 }).limit(limit).skip(offset).exec(),
+      BulkAction.countDocuments().exec(),
+    ]);
+    return { bulkActions, total };
+  }
+
+  // Mark a bulk action as being processed; null once it finished. Items
+  // already processed keep their result. The job queue decides who runs it.
+  async start(id: string | Types.ObjectId): Promise<IBulkAction | null> {
+    return await BulkAction.findOneAndUpdate(
+      { _id: id, status: { $in: ['queued', 'processing'] } },
+      { status: 'processing', startedAt: new Date() },
+      { new: true }
+    ).exec();
+  }
+
+  // Give up on a bulk action that hasn't finished
+  async fail(id: string | Types.ObjectId, error: string): Promise<IBulkAction | null> {
+    return await BulkAction.findOneAndUpdate(
+      { _id: id, status: { $in: ['queued', 'processing'] } },
+      { status: 'failed', error, completedAt: new Date() },
+      { new: true }
+    ).exec();
+  }
+
+  // Record the result of one pending item and count it
+  async recordItem(
+    id: string | Types.ObjectId,
+    targetId: Types.ObjectId,
+    status: Exclude<BulkActionItemStatus, 'pending'>,
+    error?: string
+  ): Promise<boolean> {
+    const result = await BulkAction.updateOne(
+      { _id: id, items: { $elemMatch: { targetId, status: 'pending' } } },
+      {
+        $set: {
+          'items.$.status': status,
+          'items.$.error': error,
+          'items.$.processedAt': new Date(),
+        },
+        $inc: { [`progress.${status}`]: 1 },
+      }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Update bulk action
+  async update(id: string | Types.ObjectId, updateData: Partial<IBulkAction>): Promise<IBulkAction | null> {
+    return await BulkAction.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+}
@@ -0,0 +1,37 @@
+import { Types } from 'mongoose';
+import { CallEvent, CallEventType, ICallEvent } from '../models/call-event';
+
+export class CallEventRepository {
+  // Append event
+  async create(eventData: Partial<ICallEvent>): Promise<ICallEvent> {
+    const event = new CallEvent(eventData);
+    return await event.save();
+  }
+
+  // Get a call's events in the order they happened
+  async findByCall(callId: string): Promise<ICallEvent[]> {
+    return await CallEvent.find({ callId })
+      .sort({ createdAt: 1, _id: 1 })
+      .exec();
+  }
+
+  // Users who had one of these events in a call, e.g. everyone who was
+  // connected to it
+  async findUserIds(callId: string, types: CallEventType[]): Promise<string[]> {
+    const userIds: Types.ObjectId[] = await CallEvent.distinct('userId', {
+      callId,
+      type: { $in: types },
+      userId: { $exists: true },
+    }).exec();
+    return userIds.map(id => id.toString());
+  }
+
+  // Get a call's events with the users they involve, for display
+  async getTimeline(callId: string): Promise<ICallEvent[]> {
+    return await CallEvent.find({ callId })
+      .populate('userId', 'displayName avatar')
+      .populate('actorId', 'displayName avatar')
+      .sort({ createdAt: 1, _id: 1 })
+      .exec();
+  }
+}
@@ -0,0 +1,81 @@
+import { Types } from 'mongoose';
+import { CallRating, ICallRating, CALL_ISSUE_TAGS } from '../models/call-rating';
+
+export type CallQualityReportDimension = 'region' | 'appVersion' | 'platform' | 'callType';
+
+export interface CallQualityReportRow {
+  key: string | null;
+  ratings: number;
+  averageRating: number;
+  distribution: Record<'1' | '2' | '3' | '4' | '5', number>;
+  issues: Record<string, number>;
+  averagePacketLoss: number | null;
+  averageLatency: number | null;
+  degraded: number; // ratings of calls stepped down from full quality
+  relayed: number;
+}
+
+export class CallRatingRepository {
+  // Rate a call, replacing an earlier rating by the same user
+  async upsert(rating: Partial<ICallRating>): Promise<ICallRating> {
+    return await CallRating.findOneAndUpdate(
+      { callId: rating.callId, userId: rating.userId },
+      { $set: rating },
+      { new: true, upsert: true, setDefaultsOnInsert: true }
+    ).exec();
+  }
+
+  // A call's ratings
+  async findByCall(callId: string): Promise<ICallRating[]> {
+    return await CallRating.find({ callId })
+      .populate('userId', 'displayName avatar')
+      .sort({ createdAt: 1 })
+      .exec();
+  }
+
+  // Whether someone already rated a call
+  async exists(callId: string, userId: string | Types.ObjectId): Promise<boolean> {
+    return !!(await CallRating.exists({ callId, userId }).exec());
+  }
+
+  // Ratings in a period grouped by one client dimension, most rated first
+  async getReport(from: Date, to: Date, groupBy: CallQualityReportDimension): Promise<CallQualityReportRow[]> {
+    const rows = await CallRating.aggregate([
+      { $match: { createdAt: { $gte: from, $lt: to } } },
+      {
+        $group: {
+          _id: `$${groupBy}`,
+          ratings: { $sum: 1 },
+          averageRating: { $avg: '$rating' },
+          ...Object.fromEntries([1, 2, 3, 4, 5].map(star => [
+            `stars${star}`,
+            { $sum: { $cond: [{ $eq: ['$rating', star] }, 1, 0] } },
+          ])),
+          ...Object.fromEntries(CALL_ISSUE_TAGS.map(tag => [
+            `issue_${tag}`,
+            { $sum: { $cond: [{ $in: [tag, { $ifNull: ['$issues', []] }] }, 1, 0] } },
+          ])),
+          averagePacketLoss: { $avg: '$metrics.packetLoss' },
+          averageLatency: { $avg: '$metrics.latency' },
+          degraded: { $sum: { $cond: [{ $in: ['$metrics.worstLevel', ['reduced', 'audio_only']] }, 1, 0] } },
+          relayed: { $sum: { $cond: ['$metrics.relayed', 1, 0] } },
+        },
+      },
+      { $sort: { ratings: -1 } },
+    ]).exec();
+
+    return rows.map(row => ({
+      key: row._id ?? null,
+      ratings: row.ratings,
+      averageRating: Math.round(row.averageRating * 100) / 100,
+      distribution: {
+        '1': row.stars1, '2': row.stars2, '3': row.stars3, '4': row.stars4, '5': row.stars5,
+      },
+      issues: Object.fromEntries(CALL_ISSUE_TAGS.map(tag => [tag, row[`issue_${tag}`]])),
+      averagePacketLoss: row.averagePacketLoss ?? null,
+      averageLatency: typeof row.averageLatency === 'number' ? Math.round(row.averageLatency) : null,
+      degraded: row.degraded,
+      relayed: row.relayed,
+    }));
+  }
+}
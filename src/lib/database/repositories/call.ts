@@ -1,5 +1,6 @@
-import { Types } from 'mongoose';
-import { Call, ICall } from '../models/call';
+import { FilterQuery, Types } from 'mongoose';
+import { Call, CallEndReason, ICall } from '../models/call';
+import { ListOptions } from '../../utils/pagination';
 
 export class CallRepository {
   // Create call
@@ -29,8 +30,39 @@ export class CallRepository {
     return await Call.findByIdAndUpdate(id, updateData, { new: true }).exec();
   }
 
-  // End call
-  async endCall(callId: string, status: 'ended' | 'missed' | 'rejected' | 'busy'): Promise<boolean> {
+  // Add a participant joining an ongoing call
+  async addParticipant(callId: string, userId: string | Types.ObjectId): Promise<boolean> {
+    const result = await Call.updateOne(
+      { callId },
+      { $addToSet: { participants: userId } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Remove a participant; they can't rejoin this call
+  async removeParticipant(callId: string, userId: string | Types.ObjectId): Promise<boolean> {
+    const result = await Call.updateOne(
+      { callId },
+      {
+        $pull: { participants: userId },
+        $addToSet: { removedParticipants: userId },
+      }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Update host controls (host, lock, listen-only)
+  async updateControls(callId: string, controls: Partial<Pick<ICall, 'host' | 'locked' | 'listenOnly'>>): Promise<boolean> {
+    const result = await Call.updateOne({ callId }, controls).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // End call; false when it had already ended
+  async endCall(
+    callId: string,
+    status: 'ended' | 'missed' | 'rejected' | 'busy',
+    endReason: CallEndReason = status === 'ended' ? 'normal' : status
+  ): Promise<boolean> {
     const endTime = new Date();
     const call = await Call.findOne({ callId }).exec();
     
@@ -39,17 +71,25 @@ export class CallRepository {
     const duration = Math.floor((endTime.getTime() - call.startTime.getTime()) / 1000);
 
     const result = await Call.findOneAndUpdate(
-      { callId },
+      { callId, status: { $in: ['initiated', 'ringing', 'answered'] } },
       {
         status,
         endTime,
-        duration: status === 'ended' ? duration : 0
+        duration: status === 'ended' ? duration : 0,
+        endReason,
       }
     ).exec();
 
     return !!result;
   }
 
+  // Calls still open: ringing, or answered and not ended
+  async findUnfinished(): Promise<ICall[]> {
+    return await Call.find({ status: { $in: ['initiated', 'ringing', 'answered'] } })
+      .sort({ startTime: 1 })
+      .exec();
+  }
+
   // Get user call history
   async getUserCallHistory(
     userId: string | Types.ObjectId,
@@ -138,6 +178,11 @@ export class CallRepository {
     return !!result;
   }
 
+  // Keep participants' measured connection stats when the call ends
+  async addQualityStats(callId: string, stats: NonNullable<ICall['qualityStats']>): Promise<void> {
+    await Call.updateOne({ callId }, { $push: { qualityStats: { $each: stats } } }).exec();
+  }
+
   // Get call analytics
   async getCallAnalytics(startDate: Date, endDate: Date): Promise<any> {
     return await Call.aggregate([
@@ -162,5 +207,54 @@ export class CallRepository {
       }
     ]).exec();
   }
+
+  // Filtered, sorted page of calls (admin). Signaling data is left out.
+  async getForAdmin(filters: AdminCallFilters, options: ListOptions): Promise<{ calls: ICall[]; total: number }> {
+    const query = this.adminQuery(filters);
+    const [calls, total] = await Promise.all([
+      Call.find(query).sort(options.sort).skip(options.offset).limit(options.limit).select('-signaling').exec(),
+      Call.countDocuments(query).exec(),
+    ]);
+    return { calls, total };
+  }
+
+  // Every call matching the filters, for exports
+  cursorForAdmin(filters: AdminCallFilters, sort: ListOptions['sort'], limit: number) {
+    return Call.find(this.adminQuery(filters))
+      .sort(sort)
+      .limit(limit)
+      .select('-signaling')
+      .lean<ICall>()
+      .cursor();
+  }
+
+  private adminQuery(filters: AdminCallFilters): FilterQuery<ICall> {
+    const query: FilterQuery<ICall> = {};
+
+    if (filters.status) query.status = filters.status;
+    if (filters.type) query.type = filters.type;
+    if (filters.endReason) query.endReason = filters.endReason;
+    if (filters.participant) query.participants = new Types.ObjectId(filters.participant);
+    if (filters.chatId) query.chatId = new Types.ObjectId(filters.chatId);
+    if (filters.group !== undefined) query.isGroupCall = filters.group;
+    if (filters.from || filters.to) {
+      query.startTime = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(filters.to && { $lte: filters.to }),
+      };
+    }
+
+    return query;
+  }
 }
 
+export interface AdminCallFilters {
+  status?: ICall['status'];
+  type?: ICall['type'];
+  endReason?: CallEndReason;
+  participant?: string;
+  chatId?: string;
+  group?: boolean;
+  from?: Date; // started
+  to?: Date;
+}
@@ -0,0 +1,27 @@
+import { ChangeStreamCheckpoint, IChangeStreamCheckpoint } from '../models/change-stream-checkpoint';
+
+export class ChangeStreamCheckpointRepository {
+  // Find checkpoint by consumer name
+  async findByName(name: string): Promise<IChangeStreamCheckpoint | null> {
+    return await ChangeStreamCheckpoint.findOne({ name }).exec();
+  }
+
+  // Store the latest resume token
+  async save(name: string, resumeToken: Record<string, unknown>, events: number = 1): Promise<void> {
+    await ChangeStreamCheckpoint.updateOne(
+      { name },
+      { $set: { resumeToken }, $inc: { eventCount: events } },
+      { upsert: true }
+    ).exec();
+  }
+
+  // Forget a checkpoint, e.g. when its token has fallen off the oplog
+  async delete(name: string): Promise<void> {
+    await ChangeStreamCheckpoint.deleteOne({ name }).exec();
+  }
+
+  // Get all checkpoints
+  async findAll(): Promise<IChangeStreamCheckpoint[]> {
+    return await ChangeStreamCheckpoint.find().sort({ name: 1 }).exec();
+  }
+}
@@ -1,6 +1,8 @@
 import { Types } from 'mongoose';
 import { Chat, IChat } from '../models/chat';
 import { Message } from '../models/message';
+import { MessageBucketRepository } from './message-bucket';
+import { retryOnConflict, versionFilter, VersionConflictError } from '../concurrency';
 
 export class ChatRepository {
   // Create chat
@@ -12,18 +14,31 @@ export class ChatRepository {
   // Find chat by ID
   async findById(id: string | Types.ObjectId): Promise<IChat | null> {
     return await Chat.findById(id)
-      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
       .populate('lastMessage')
       .exec();
   }
 
+  // Check chat membership without loading the chat
+  async isParticipant(chatId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<boolean> {
+    if (!Types.ObjectId.isValid(chatId)) return false;
+    const result = await Chat.exists({ _id: chatId, participants: userId }).exec();
+    return !!result;
+  }
+
+  // Participant IDs only, without loading the chat
+  async getParticipantIds(chatId: string | Types.ObjectId): Promise<string[]> {
+    const chat = await Chat.findById(chatId).select('participants').lean().exec();
+    return (chat?.participants || []).map(participant => participant.toString());
+  }
+
   // Get user chats
   async getUserChats(userId: string | Types.ObjectId, limit: number = 20, offset: number = 0): Promise<IChat[]> {
     return await Chat.find({
       participants: userId,
       isArchived: false
     })
-    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
     .populate('lastMessage')
     .sort({ lastActivity: -1 })
     .limit(limit)
@@ -31,20 +46,34 @@ export class ChatRepository {
     .exec();
   }
 
+  // Get the IDs of every chat the user is in, archived ones included
+  async getUserChatIds(userId: string | Types.ObjectId): Promise<Types.ObjectId[]> {
+    return await Chat.distinct('_id', { participants: userId }).exec();
+  }
+
+  // Which of the given chats have any of the given users as participants
+  async filterWithParticipants(
+    chatIds: (string | Types.ObjectId)[],
+    userIds: (string | Types.ObjectId)[]
+  ): Promise<Types.ObjectId[]> {
+    if (chatIds.length === 0 || userIds.length === 0) return [];
+    return await Chat.distinct('_id', { _id: { $in: chatIds }, participants: { $in: userIds } }).exec();
+  }
+
   // Find direct chat between two users
   async findDirectChat(user1Id: string | Types.ObjectId, user2Id: string | Types.ObjectId): Promise<IChat | null> {
     return await Chat.findOne({
       type: 'direct',
       participants: { $all: [user1Id, user2Id], $size: 2 }
     })
-    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
     .exec();
   }
 
   // Update chat
   async update(id: string | Types.ObjectId, updateData: Partial<IChat>): Promise<IChat | null> {
     return await Chat.findByIdAndUpdate(id, updateData, { new: true })
-      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
       .exec();
   }
 
@@ -81,10 +110,131 @@ export class ChatRepository {
     return !!result;
   }
 
+  // Update a participant's per-chat settings, creating the entry if missing.
+  // The entry is versioned on its own, so one member's settings never
+  // conflict with another's or with chat-wide changes. With expectedVersion
+  // the change only applies to that version of the entry.
+  async updateParticipantSettings(
+    chatId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    settings: Partial<Omit<IChat['participantSettings'][number], 'userId'>>,
+    expectedVersion?: number
+  ): Promise<IChat | null> {
+    const setFields: Record<string, any> = {};
+    Object.entries(settings).forEach(([field, value]) => {
+      if (value !== undefined) {
+        setFields[`participantSettings.$.${field}`] = value;
+      }
+    });
+
+    const apply = async () => {
+      const updated = await Chat.findOneAndUpdate(
+        {
+          _id: chatId,
+          participants: userId,
+          participantSettings: { $elemMatch: { userId, ...versionFilter(expectedVersion) } },
+        },
+        { $set: setFields, $inc: { 'participantSettings.$.version': 1 } },
+        { new: true }
+      ).exec();
+      if (updated) return updated;
+
+      // A missing entry counts as version 0
+      if (!expectedVersion) {
+        const added = await Chat.findOneAndUpdate(
+          { _id: chatId, participants: userId, 'participantSettings.userId': { $ne: userId } },
+          { $push: { participantSettings: { userId, ...settings, version: 1 } } },
+          { new: true }
+        ).exec();
+        if (added) return added;
+      }
+
+      return await this.throwIfParticipantChanged(chatId, userId);
+    };
+
+    // Without a version, losing the race between adding and updating the
+    // entry is retried; it sets the same values either way
+    return expectedVersion === undefined ? await retryOnConflict(apply) : await apply();
+  }
+
+  // Move a participant's read position forward to a message. Positions
+  // change with every read, so unlike other per-chat settings they don't
+  // bump the version. Null when the position was already at or past it.
+  async advanceReadPosition(
+    chatId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    position: NonNullable<IChat['participantSettings'][number]['readPosition']>
+  ): Promise<IChat | null> {
+    const updated = await Chat.findOneAndUpdate(
+      {
+        _id: chatId,
+        participants: userId,
+        participantSettings: {
+          $elemMatch: {
+            userId,
+            $or: [{ 'readPosition.sentAt': { $exists: false } }, { 'readPosition.sentAt': { $lt: position.sentAt } }],
+          },
+        },
+      },
+      { $set: { 'participantSettings.$.readPosition': position } },
+      { new: true }
+    ).exec();
+    if (updated) return updated;
+
+    return await Chat.findOneAndUpdate(
+      { _id: chatId, participants: userId, 'participantSettings.userId': { $ne: userId } },
+      { $push: { participantSettings: { userId, readPosition: position } } },
+      { new: true }
+    ).exec();
+  }
+
+  // Change chat-wide settings and bump the version. With expectedVersion
+  // the change only applies to that version of the chat.
+  async updateSettings(
+    chatId: string | Types.ObjectId,
+    settings: Record<string, unknown>,
+    expectedVersion?: number
+  ): Promise<IChat | null> {
+    const updated = await Chat.findOneAndUpdate(
+      { _id: chatId, ...versionFilter(expectedVersion) },
+      { $set: settings, $inc: { version: 1 } },
+      { new: true }
+    ).exec();
+
+    return updated ?? await this.throwIfChanged({ _id: chatId });
+  }
+
+  // A versioned update matched nothing: throws if the chat exists, so it
+  // was changed in the meantime, and returns null if it doesn't
+  protected async throwIfChanged(filter: Record<string, unknown>): Promise<null> {
+    const current = await Chat.findOne(filter).select('version').lean<Pick<IChat, 'version'>>().exec();
+    if (current) {
+      throw new VersionConflictError('Chat was changed by someone else', current.version ?? 0);
+    }
+    return null;
+  }
+
+  // throwIfChanged for a participant's settings entry
+  protected async throwIfParticipantChanged(
+    chatId: string | Types.ObjectId,
+    userId: string | Types.ObjectId
+  ): Promise<null> {
+    const current = await Chat.findOne({ _id: chatId, participants: userId })
+      .select('participantSettings')
+      .lean<Pick<IChat, 'participantSettings'>>()
+      .exec();
+    if (current) {
+      const entry = current.participantSettings?.find(settings => settings.userId.toString() === userId.toString());
+      throw new VersionConflictError('Chat settings were changed on another device', entry?.version ?? 0);
+    }
+    return null;
+  }
+
   // Clear chat history
   async clearHistory(chatId: string | Types.ObjectId): Promise<boolean> {
-    // Delete all messages in the chat
+    // Delete all messages in the chat, archived ones included
     await Message.deleteMany({ chatId }).exec();
+    await new MessageBucketRepository().deleteByChat(chatId);
     
     // Update chat last message
     const result = await Chat.findByIdAndUpdate(chatId, {
@@ -131,6 +281,39 @@ export class ChatRepository {
     return !!result;
   }
 
+  // Mark a call as running in the chat
+  async setActiveCall(chatId: string | Types.ObjectId, activeCall: NonNullable<IChat['activeCall']>): Promise<IChat | null> {
+    return await Chat.findByIdAndUpdate(
+      chatId,
+      { $set: { activeCall } },
+      { new: true }
+    ).exec();
+  }
+
+  // Add or remove someone from the running call's participants
+  async updateActiveCallParticipants(
+    callId: string,
+    userId: string | Types.ObjectId,
+    inCall: boolean
+  ): Promise<IChat | null> {
+    return await Chat.findOneAndUpdate(
+      { 'activeCall.callId': callId },
+      inCall
+        ? { $addToSet: { 'activeCall.participants': userId } }
+        : { $pull: { 'activeCall.participants': userId } },
+      { new: true }
+    ).exec();
+  }
+
+  // Clear the marker once the call is over
+  async clearActiveCall(callId: string): Promise<IChat | null> {
+    return await Chat.findOneAndUpdate(
+      { 'activeCall.callId': callId },
+      { $unset: { activeCall: 1 } },
+      { new: true }
+    ).exec();
+  }
+
   // Search chats
   async searchChats(userId: string | Types.ObjectId, query: string): Promise<IChat[]> {
     const searchRegex = new RegExp(query, 'i');
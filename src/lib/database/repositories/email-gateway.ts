@@ -0,0 +1,91 @@
+import { Types } from 'mongoose';
+import {
+  EmailGateway,
+  EmailThreadMessage,
+  IEmailGateway,
+  IEmailThreadMessage,
+} from '../models/email-gateway';
+
+export class EmailGatewayRepository {
+  // Create gateway address
+  async createGateway(gatewayData: Partial<IEmailGateway>): Promise<IEmailGateway> {
+    const gateway = new EmailGateway(gatewayData);
+    return await gateway.save();
+  }
+
+  // Find gateway by ID
+  async findGatewayById(id: string | Types.ObjectId): Promise<IEmailGateway | null> {
+    return await EmailGateway.findById(id).exec();
+  }
+
+  // Find gateway by the local part of its address
+  async findGatewayByLocalPart(localPart: string): Promise<IEmailGateway | null> {
+    return await EmailGateway.findOne({ localPart: localPart.toLowerCase() }).exec();
+  }
+
+  // Find a group's gateway
+  async findGatewayByChat(chatId: string | Types.ObjectId): Promise<IEmailGateway | null> {
+    return await EmailGateway.findOne({ chatId }).exec();
+  }
+
+  // Find a user's personal gateway
+  async findGatewayByUser(userId: string | Types.ObjectId): Promise<IEmailGateway | null> {
+    return await EmailGateway.findOne({ userId }).exec();
+  }
+
+  // Update gateway
+  async updateGateway(id: string | Types.ObjectId, updateData: Partial<IEmailGateway>): Promise<IEmailGateway | null> {
+    return await EmailGateway.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Delete gateway
+  async deleteGateway(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await EmailGateway.findByIdAndDelete(id).exec();
+    return !!result;
+  }
+
+  // Count a relayed email
+  async recordTraffic(id: string | Types.ObjectId, direction: 'inbound' | 'outbound'): Promise<void> {
+    const update = direction === 'inbound'
+      ? { $inc: { 'stats.received': 1 }, $set: { 'stats.lastReceivedAt': new Date() } }
+      : { $inc: { 'stats.sent': 1 }, $set: { 'stats.lastSentAt': new Date() } };
+
+    await EmailGateway.updateOne({ _id: id }, update).exec();
+  }
+
+  // Record a local message <-> email mapping
+  async recordThreadMessage(messageData: Partial<IEmailThreadMessage>): Promise<IEmailThreadMessage> {
+    const message = new EmailThreadMessage(messageData);
+    return await message.save();
+  }
+
+  // Check if an email was already delivered through a gateway
+  async hasEmail(gatewayId: string | Types.ObjectId, emailMessageId: string): Promise<boolean> {
+    const result = await EmailThreadMessage.exists({ gatewayId, emailMessageId }).exec();
+    return !!result;
+  }
+
+  // Find the thread entry for any of the given Message-IDs (In-Reply-To/References)
+  async findByEmailMessageIds(
+    gatewayId: string | Types.ObjectId,
+    emailMessageIds: string[]
+  ): Promise<IEmailThreadMessage | null> {
+    if (emailMessageIds.length === 0) return null;
+
+    return await EmailThreadMessage.findOne({ gatewayId, emailMessageId: { $in: emailMessageIds } })
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Find the thread entry for a local message
+  async findByMessage(messageId: string | Types.ObjectId): Promise<IEmailThreadMessage | null> {
+    return await EmailThreadMessage.findOne({ messageId }).exec();
+  }
+
+  // Latest email exchanged in a chat
+  async findLatestInChat(chatId: string | Types.ObjectId): Promise<IEmailThreadMessage | null> {
+    return await EmailThreadMessage.findOne({ chatId })
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+}
@@ -0,0 +1,94 @@
+import { Types } from 'mongoose';
+import {
+  FederatedRoom,
+  FederatedMessage,
+  IFederatedRoom,
+  IFederatedMessage,
+  FederationProtocol,
+} from '../models/federation';
+
+export class FederationRepository {
+  // Create room mapping
+  async createRoom(roomData: Partial<IFederatedRoom>): Promise<IFederatedRoom> {
+    const room = new FederatedRoom(roomData);
+    return await room.save();
+  }
+
+  // Find room mapping by ID
+  async findRoomById(id: string | Types.ObjectId): Promise<IFederatedRoom | null> {
+    return await FederatedRoom.findById(id).exec();
+  }
+
+  // Find room mappings for a local chat
+  async findRoomsByChat(chatId: string | Types.ObjectId): Promise<IFederatedRoom[]> {
+    return await FederatedRoom.find({ chatId }).exec();
+  }
+
+  // Find room mapping by remote room
+  async findRoomByRemote(protocol: FederationProtocol, remoteRoomId: string): Promise<IFederatedRoom | null> {
+    return await FederatedRoom.findOne({ protocol, remoteRoomId }).exec();
+  }
+
+  // List room mappings
+  async listRooms(
+    filter: { protocol?: FederationProtocol; enabled?: boolean } = {},
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<{ rooms: IFederatedRoom[]; total: number }> {
+    const query: any = {};
+    if (filter.protocol) query.protocol = filter.protocol;
+    if (filter.enabled !== undefined) query.enabled = filter.enabled;
+
+    const [rooms, total] = await Promise.all([
+      FederatedRoom.find(query)
+        .populate('chatId', 'type groupInfo.name')
+        .sort({ createdAt: -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      FederatedRoom.countDocuments(query).exec(),
+    ]);
+
+    return { rooms, total };
+  }
+
+  // Update room mapping
+  async updateRoom(id: string | Types.ObjectId, updateData: Partial<IFederatedRoom>): Promise<IFederatedRoom | null> {
+    return await FederatedRoom.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Delete room mapping
+  async deleteRoom(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await FederatedRoom.findByIdAndDelete(id).exec();
+    return !!result;
+  }
+
+  // Count a relayed message
+  async recordTraffic(id: string | Types.ObjectId, direction: 'inbound' | 'outbound'): Promise<void> {
+    const update = direction === 'inbound'
+      ? { $inc: { 'stats.messagesIn': 1 }, $set: { 'stats.lastInboundAt': new Date() } }
+      : { $inc: { 'stats.messagesOut': 1 }, $set: { 'stats.lastOutboundAt': new Date() } };
+
+    await FederatedRoom.updateOne({ _id: id }, update).exec();
+  }
+
+  // Record the last relay failure
+  async recordError(id: string | Types.ObjectId, error: string): Promise<void> {
+    await FederatedRoom.updateOne(
+      { _id: id },
+      { $set: { 'stats.lastError': error.slice(0, 500), 'stats.lastErrorAt': new Date() } }
+    ).exec();
+  }
+
+  // Record a local <-> remote message mapping
+  async recordMessage(messageData: Partial<IFederatedMessage>): Promise<IFederatedMessage> {
+    const message = new FederatedMessage(messageData);
+    return await message.save();
+  }
+
+  // Check if a remote event was already bridged
+  async hasRemoteEvent(protocol: FederationProtocol, remoteEventId: string): Promise<boolean> {
+    const result = await FederatedMessage.exists({ protocol, remoteEventId }).exec();
+    return !!result;
+  }
+}
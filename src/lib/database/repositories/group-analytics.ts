@@ -0,0 +1,52 @@
+import { Types } from 'mongoose';
+import { GroupAnalytics, IGroupAnalytics } from '../models/group-analytics';
+import { Chat } from '../models/chat';
+
+export class GroupAnalyticsRepository {
+  // Create or replace a group's figures
+  async upsert(chatId: string | Types.ObjectId, data: Partial<IGroupAnalytics>): Promise<IGroupAnalytics> {
+    return await GroupAnalytics.findOneAndUpdate(
+      { chatId },
+      { $set: { ...data, computedAt: new Date() } },
+      { upsert: true, new: true }
+    ).exec();
+  }
+
+  async findByChat(chatId: string | Types.ObjectId): Promise<IGroupAnalytics | null> {
+    return await GroupAnalytics.findOne({ chatId }).exec();
+  }
+
+  // Groups active since activeSince whose figures are missing, or were
+  // computed before refreshBefore and are behind the group's activity or
+  // older than expireBefore
+  async findStaleGroupIds(activeSince: Date, refreshBefore: Date, expireBefore: Date, limit: number): Promise<Types.ObjectId[]> {
+    const rows = await Chat.aggregate([
+      { $match: { type: 'group', lastActivity: { $gte: activeSince } } },
+      {
+        $lookup: {
+          from: GroupAnalytics.collection.name,
+          localField: '_id',
+          foreignField: 'chatId',
+          pipeline: [{ $project: { computedAt: 1 } }],
+          as: 'analytics',
+        },
+      },
+      { $addFields: { computedAt: { $first: '$analytics.computedAt' } } },
+      {
+        $match: {
+          $or: [
+            { computedAt: null },
+            {
+              computedAt: { $lt: refreshBefore },
+              $expr: { $or: [{ $lt: ['$computedAt', '$lastActivity'] }, { $lt: ['$computedAt', expireBefore] }] },
+            },
+          ],
+        },
+      },
+      { $sort: { computedAt: 1 } },
+      { $limit: limit },
+      { $project: { _id: 1 } },
+    ]).exec();
+    return rows.map(row => row._id);
+  }
+}
@@ -0,0 +1,71 @@
+import { Types } from 'mongoose';
+import { GroupEmoji, IGroupEmoji } from '../models/group-emoji';
+
+export class GroupEmojiRepository {
+  // Create an emoji; throws a duplicate key error if the shortcode is taken
+  async create(emojiData: Partial<IGroupEmoji>): Promise<IGroupEmoji> {
+    const emoji = new GroupEmoji(emojiData);
+    return await emoji.save();
+  }
+
+  // Find emoji by ID
+  async findById(id: string | Types.ObjectId): Promise<IGroupEmoji | null> {
+    return await GroupEmoji.findById(id).exec();
+  }
+
+  // Find several emoji by ID, removed ones included
+  async findByIds(ids: (string | Types.ObjectId)[]): Promise<IGroupEmoji[]> {
+    return await GroupEmoji.find({ _id: { $in: ids } }).exec();
+  }
+
+  // Get a group's active emoji by shortcode
+  async findActiveByShortcodes(chatId: string | Types.ObjectId, shortcodes: string[]): Promise<IGroupEmoji[]> {
+    return await GroupEmoji.find({ chatId, shortcode: { $in: shortcodes }, status: 'active' }).exec();
+  }
+
+  // Get every active emoji of a group
+  async listActive(chatId: string | Types.ObjectId): Promise<IGroupEmoji[]> {
+    return await GroupEmoji.find({ chatId, status: 'active' }).sort({ shortcode: 1 }).exec();
+  }
+
+  // Count a group's active emoji
+  async countActive(chatId: string | Types.ObjectId): Promise<number> {
+    return await GroupEmoji.countDocuments({ chatId, status: 'active' }).exec();
+  }
+
+  // Recent uploads across groups, newest first (admin)
+  async listForModeration(
+    filters: { chatId?: string; status?: IGroupEmoji['status'] },
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ emoji: IGroupEmoji[]; total: number }> {
+    const query: any = {};
+    if (filters.chatId) query.chatId = filters.chatId;
+    if (filters.status) query.status = filters.status;
+
+    const [emoji, total] = await Promise.all([
+      GroupEmoji.find(query)
+        .populate('createdBy', 'displayName phoneNumber')
+        .populate('chatId', 'groupInfo.name')
+        .sort({ createdAt: -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      GroupEmoji.countDocuments(query).exec(),
+    ]);
+    return { emoji, total };
+  }
+
+  // Remove an active emoji, unless someone already did
+  async remove(
+    id: string | Types.ObjectId,
+    removal: { removedBy: string | Types.ObjectId; removedByModerator?: boolean; removedReason?: string },
+    chatId?: string | Types.ObjectId
+  ): Promise<IGroupEmoji | null> {
+    return await GroupEmoji.findOneAndUpdate(
+      { _id: id, status: 'active', ...(chatId && { chatId }) },
+      { $set: { status: 'removed', ...removal, removedAt: new Date() } },
+      { new: true }
+    ).exec();
+  }
+}
@@ -0,0 +1,23 @@
+import { Types } from 'mongoose';
+import { GroupPseudonym, IGroupPseudonym } from '../models/group-pseudonym';
+
+export class GroupPseudonymRepository {
+  // Get a member's pseudonym in a group
+  async findByMember(chatId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<IGroupPseudonym | null> {
+    return await GroupPseudonym.findOne({ chatId, userId }).exec();
+  }
+
+  // Create a pseudonym; throws a duplicate key error if the member already
+  // has one or the name is taken in the group
+  async create(chatId: string | Types.ObjectId, userId: string | Types.ObjectId, name: string): Promise<IGroupPseudonym> {
+    return await GroupPseudonym.create({ chatId, userId, name });
+  }
+
+  // Get every pseudonym of a group, with the members behind them
+  async listByChat(chatId: string | Types.ObjectId): Promise<IGroupPseudonym[]> {
+    return await GroupPseudonym.find({ chatId })
+      .populate('userId', 'displayName avatar')
+      .sort({ createdAt: 1 })
+      .exec();
+  }
+}
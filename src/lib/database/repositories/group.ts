@@ -2,6 +2,7 @@ import { Types } from 'mongoose';
 import { Chat, IChat } from '../models/chat';
 import { User } from '../models/user';
 import { ChatRepository } from './chat';
+import { versionFilter } from '../concurrency';
 
 export class GroupRepository extends ChatRepository {
   // Create group
@@ -11,9 +12,11 @@ export class GroupRepository extends ChatRepository {
     participants: (string | Types.ObjectId)[];
     createdBy: string | Types.ObjectId;
     avatar?: string;
+    organizationId?: string | Types.ObjectId;
   }): Promise<IChat> {
     const group = await this.create({
       type: 'group',
+      ...(groupData.organizationId && { organizationId: new Types.ObjectId(groupData.organizationId) }),
       participants: [
         ...groupData.participants.map(id => typeof id === 'string' ? new Types.ObjectId(id) : id),
         typeof groupData.createdBy === 'string' ? new Types.ObjectId(groupData.createdBy) : groupData.createdBy
@@ -31,6 +34,8 @@ export class GroupRepository extends ChatRepository {
           whoCanSendMessages: 'everyone',
           whoCanEditGroupInfo: 'admins',
           whoCanAddMembers: 'admins',
+          messageApproval: false,
+          pseudonymousMembers: false,
         },
       },
     });
@@ -38,6 +43,64 @@ export class GroupRepository extends ChatRepository {
     return group;
   }
 
+  // Find a group whose membership is managed over SCIM, in an organization
+  // (or the shared pool)
+  async findScimGroup(groupId: string | Types.ObjectId, organizationId?: string): Promise<IChat | null> {
+    if (!Types.ObjectId.isValid(groupId)) return null;
+    return await Chat.findOne({
+      _id: groupId,
+      type: 'group',
+      'scim.provisionedAt': { $exists: true },
+      organizationId: organizationId ?? { $exists: false },
+    }).exec();
+  }
+
+  // Groups managed over SCIM in an organization (or the shared pool),
+  // oldest first, with the total matching
+  async findScimGroups(
+    filter: { organizationId?: string; displayName?: string; externalId?: string },
+    limit: number,
+    offset: number
+  ): Promise<{ groups: IChat[]; total: number }> {
+    const query: any = {
+      type: 'group',
+      'scim.provisionedAt': { $exists: true },
+      organizationId: filter.organizationId ?? { $exists: false },
+    };
+    if (filter.displayName !== undefined) query['groupInfo.name'] = filter.displayName;
+    if (filter.externalId !== undefined) query['scim.externalId'] = filter.externalId;
+
+    const [groups, total] = await Promise.all([
+      Chat.find(query).sort({ createdAt: 1 }).skip(offset).limit(limit).exec(),
+      Chat.countDocuments(query).exec(),
+    ]);
+    return { groups, total };
+  }
+
+  // Replace a group's members
+  async setParticipants(groupId: string | Types.ObjectId, participantIds: Types.ObjectId[]): Promise<IChat | null> {
+    return await Chat.findByIdAndUpdate(
+      groupId,
+      { $set: { participants: participantIds } },
+      { new: true }
+    ).exec();
+  }
+
+  // Remove several members at once
+  async removeParticipants(groupId: string | Types.ObjectId, participantIds: Types.ObjectId[]): Promise<boolean> {
+    const result = await Chat.findByIdAndUpdate(
+      groupId,
+      { $pullAll: { participants: participantIds, 'groupInfo.admins': participantIds } }
+    ).exec();
+    return !!result;
+  }
+
+  // Stop managing a group over SCIM; the group and its messages stay
+  async unlinkScim(groupId: string | Types.ObjectId): Promise<boolean> {
+    const result = await Chat.findByIdAndUpdate(groupId, { $unset: { scim: 1 } }).exec();
+    return !!result;
+  }
+
   // Get all groups for a user
   async getUserGroups(userId: string | Types.ObjectId, limit: number = 20, offset: number = 0): Promise<IChat[]> {
     return await Chat.find({
@@ -45,7 +108,7 @@ export class GroupRepository extends ChatRepository {
       participants: userId,
       isArchived: false
     })
-    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+    .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
     .populate('lastMessage')
     .populate('groupInfo.admins', 'displayName avatar')
     .sort({ lastActivity: -1 })
@@ -54,14 +117,16 @@ export class GroupRepository extends ChatRepository {
     .exec();
   }
 
-  // Update group info
+  // Update group info. With expectedVersion the change only applies to
+  // that version of the group; VersionConflictError otherwise.
   async updateGroupInfo(
     groupId: string | Types.ObjectId, 
     updates: {
       name?: string;
       description?: string;
       avatar?: string;
-    }
+    },
+    expectedVersion?: number
   ): Promise<IChat | null> {
     const updateData: any = {};
     
@@ -69,30 +134,45 @@ export class GroupRepository extends ChatRepository {
     if (updates.description !== undefined) updateData['groupInfo.description'] = updates.description;
     if (updates.avatar !== undefined) updateData['groupInfo.avatar'] = updates.avatar;
 
-    return await Chat.findByIdAndUpdate(groupId, updateData, { new: true })
-      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen')
+    const updated = await Chat.findOneAndUpdate(
+      { _id: groupId, ...versionFilter(expectedVersion) },
+      { $set: updateData, $inc: { version: 1 } },
+      { new: true }
+    )
+      .populate('participants', 'displayName avatar phoneNumber isOnline lastSeen guest.expiresAt')
       .populate('groupInfo.admins', 'displayName avatar')
       .exec();
+
+    return updated ?? await this.throwIfChanged({ _id: groupId });
   }
 
-  // Update group settings
+  // Update group settings, versioned like updateGroupInfo
   async updateGroupSettings(
     groupId: string | Types.ObjectId,
     settings: {
       whoCanSendMessages?: 'everyone' | 'admins';
       whoCanEditGroupInfo?: 'everyone' | 'admins';
       whoCanAddMembers?: 'everyone' | 'admins';
-    }
+      messageApproval?: boolean;
+      pseudonymousMembers?: boolean;
+    },
+    expectedVersion?: number
   ): Promise<IChat | null> {
     const updateData: any = {};
     
-    Object.entries(settings).forEach(([key, value]) => {
+    Object.entries(settings).forEach(([field, value]) => {
       if (value !== undefined) {
-        updateData[`groupInfo.settings.${key}`] = value;
+        updateData[`groupInfo.settings.${field}`] = value;
       }
     });
 
-    return await Chat.findByIdAndUpdate(groupId, updateData, { new: true }).exec();
+    const updated = await Chat.findOneAndUpdate(
+      { _id: groupId, ...versionFilter(expectedVersion) },
+      { $set: updateData, $inc: { version: 1 } },
+      { new: true }
+    ).exec();
+
+    return updated ?? await this.throwIfChanged({ _id: groupId });
   }
 
   // Check if user is admin
@@ -0,0 +1,67 @@
+import { Types } from 'mongoose';
+import { GuestLink, IGuestLink } from '../models/guest-link';
+
+export class GuestLinkRepository {
+  // Create link
+  async create(linkData: Partial<IGuestLink>): Promise<IGuestLink> {
+    const link = new GuestLink(linkData);
+    return await link.save();
+  }
+
+  // Find link by ID
+  async findById(id: string | Types.ObjectId): Promise<IGuestLink | null> {
+    return await GuestLink.findById(id).exec();
+  }
+
+  // Find link by token
+  async findByToken(token: string): Promise<IGuestLink | null> {
+    return await GuestLink.findOne({ token }).exec();
+  }
+
+  // Get a chat's links, newest first
+  async findByChat(chatId: string | Types.ObjectId): Promise<IGuestLink[]> {
+    return await GuestLink.find({ chatId }).sort({ createdAt: -1 }).exec();
+  }
+
+  // Count a chat's links that can still be used
+  async countActive(chatId: string | Types.ObjectId): Promise<number> {
+    return await GuestLink.countDocuments({
+      chatId,
+      revokedAt: { $exists: false },
+      expiresAt: { $gt: new Date() },
+      $or: [{ maxUses: { $exists: false } }, { $expr: { $lt: ['$useCount', '$maxUses'] } }],
+    }).exec();
+  }
+
+  // Count a join if the link still allows one
+  async claimUse(id: string | Types.ObjectId): Promise<IGuestLink | null> {
+    const now = new Date();
+    return await GuestLink.findOneAndUpdate(
+      {
+        _id: id,
+        revokedAt: { $exists: false },
+        expiresAt: { $gt: now },
+        $or: [{ maxUses: { $exists: false } }, { $expr: { $lt: ['$useCount', '$maxUses'] } }],
+      },
+      {
+        $inc: { useCount: 1 },
+        $set: { lastUsedAt: now },
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Give back a join that didn't go through
+  async releaseUse(id: string | Types.ObjectId): Promise<void> {
+    await GuestLink.updateOne({ _id: id, useCount: { $gt: 0 } }, { $inc: { useCount: -1 } }).exec();
+  }
+
+  // Revoke a link
+  async revoke(id: string | Types.ObjectId): Promise<IGuestLink | null> {
+    return await GuestLink.findOneAndUpdate(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date() } },
+      { new: true }
+    ).exec();
+  }
+}
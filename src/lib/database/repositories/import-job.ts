@@ -0,0 +1,44 @@
+import { Types } from 'mongoose';
+import { ImportJob, IImportJob } from '../models/import-job';
+
+export class ImportJobRepository {
+  // Create import job
+  async create(jobData: Partial<IImportJob>): Promise<IImportJob> {
+    const job = new ImportJob(jobData);
+    return await job.save();
+  }
+
+  // Find import job by ID
+  async findById(id: string | Types.ObjectId): Promise<IImportJob | null> {
+    return await ImportJob.findById(id).exec();
+  }
+
+  // Get a user's import jobs
+  async getUserJobs(userId: string | Types.ObjectId, limit: number = 20, offset: number = 0): Promise<IImportJob[]> {
+    return await ImportJob.find({ userId })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Find a finished import of the same file
+  async findCompletedByChecksum(userId: string | Types.ObjectId, checksum: string): Promise<IImportJob | null> {
+    return await ImportJob.findOne({ userId, checksum, status: 'completed' }).exec();
+  }
+
+  // Mark a job as being processed, from the start; null once it finished.
+  // The job queue decides who runs it.
+  async start(id: string | Types.ObjectId, progress: IImportJob['progress']): Promise<IImportJob | null> {
+    return await ImportJob.findOneAndUpdate(
+      { _id: id, status: { $in: ['queued', 'processing'] } },
+      { status: 'processing', startedAt: new Date(), progress },
+      { new: true }
+    ).exec();
+  }
+
+  // Update import job
+  async update(id: string | Types.ObjectId, updateData: Partial<IImportJob>): Promise<IImportJob | null> {
+    return await ImportJob.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+}
@@ -0,0 +1,48 @@
+import { Types } from 'mongoose';
+import { IncomingWebhook, IIncomingWebhook } from '../models/incoming-webhook';
+
+export class IncomingWebhookRepository {
+  // Create webhook
+  async create(webhookData: Partial<IIncomingWebhook>): Promise<IIncomingWebhook> {
+    const webhook = new IncomingWebhook(webhookData);
+    return await webhook.save();
+  }
+
+  // Find webhook by ID
+  async findById(id: string | Types.ObjectId): Promise<IIncomingWebhook | null> {
+    return await IncomingWebhook.findById(id).exec();
+  }
+
+  // Get a chat's webhooks, newest first
+  async findByChat(chatId: string | Types.ObjectId, includeRevoked: boolean = false): Promise<IIncomingWebhook[]> {
+    const query: any = { chatId };
+    if (!includeRevoked) query.revokedAt = { $exists: false };
+
+    return await IncomingWebhook.find(query)
+      .populate('createdBy', 'displayName avatar')
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Update webhook
+  async update(id: string | Types.ObjectId, updateData: Partial<IIncomingWebhook>): Promise<IIncomingWebhook | null> {
+    return await IncomingWebhook.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Revoke webhook; the URL stops working immediately
+  async revoke(id: string | Types.ObjectId, revokedBy: string | Types.ObjectId): Promise<boolean> {
+    const result = await IncomingWebhook.updateOne(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date(), revokedBy } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Count a delivered message
+  async recordUse(id: string | Types.ObjectId): Promise<void> {
+    await IncomingWebhook.updateOne(
+      { _id: id },
+      { $inc: { messageCount: 1 }, $set: { lastUsedAt: new Date() } }
+    ).exec();
+  }
+}
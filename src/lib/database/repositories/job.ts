@@ -0,0 +1,127 @@
+import { Types } from 'mongoose';
+import { Job, IJob, JobStatus } from '../models/job';
+
+export class JobRepository {
+  // Create job
+  async create(jobData: Partial<IJob>): Promise<IJob> {
+    const job = new Job(jobData);
+    return await job.save();
+  }
+
+  // Find job by ID
+  async findById(id: string | Types.ObjectId): Promise<IJob | null> {
+    return await Job.findById(id).exec();
+  }
+
+  // List jobs, newest first
+  async list(filter: { status?: JobStatus; name?: string }, limit: number = 50, offset: number = 0): Promise<IJob[]> {
+    return await Job.find({
+      ...(filter.status && { status: filter.status }),
+      ...(filter.name && { name: filter.name }),
+    })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Job counts by name and status
+  async countByStatus(): Promise<{ name: string; status: JobStatus; count: number }[]> {
+    const rows = await Job.aggregate([
+      { $group: { _id: { name: '$name', status: '$status' }, count: { $sum: 1 } } },
+      { $sort: { '_id.name': 1, '_id.status': 1 } },
+    ]).exec();
+    return rows.map(row => ({ name: row._id.name, status: row._id.status, count: row.count }));
+  }
+
+  // When the next pending job of the given kinds is due
+  async findNextRunAt(names: string[]): Promise<Date | null> {
+    const next = await Job.findOne({ status: 'pending', name: { $in: names } })
+      .sort({ runAt: 1 })
+      .select('runAt')
+      .lean<Pick<IJob, 'runAt'>>()
+      .exec();
+    return next?.runAt ?? null;
+  }
+
+  // Atomically claim the most overdue job this worker can run
+  async claimDue(names: string[], workerId: string, now: Date = new Date()): Promise<IJob | null> {
+    return await Job.findOneAndUpdate(
+      { status: 'pending', name: { $in: names }, runAt: { $lte: now } },
+      { $set: { status: 'processing', lockedAt: now, lockedBy: workerId }, $inc: { attempts: 1 } },
+      { sort: { runAt: 1 }, new: true }
+    ).exec();
+  }
+
+  // Release jobs whose worker died or hung mid-run. A job that has used up
+  // its attempts fails instead, or one that crashes its worker every time
+  // would be requeued forever; those are returned so their owners can clean
+  // up.
+  async requeueStalled(staleBefore: Date): Promise<{ requeued: number; failed: IJob[] }> {
+    const stalled = { status: 'processing', lockedAt: { $lt: staleBefore } };
+
+    const exhausted = await Job.find({ ...stalled, $expr: { $gte: ['$attempts', '$maxAttempts'] } }).exec();
+    const failed: IJob[] = [];
+    for (const job of exhausted) {
+      const result = await Job.updateOne(
+        { _id: job._id, status: 'processing', lockedBy: job.lockedBy },
+        { $set: { status: 'failed', lastError: 'Worker stalled', finishedAt: new Date() }, $unset: { lockedAt: 1, lockedBy: 1 } }
+      ).exec();
+      if (result.modifiedCount > 0) failed.push(job);
+    }
+
+    const requeued = await Job.updateMany(
+      stalled,
+      { $set: { status: 'pending' }, $unset: { lockedAt: 1, lockedBy: 1 } }
+    ).exec();
+    return { requeued: requeued.modifiedCount, failed };
+  }
+
+  // Show a running job is still alive; false once the worker lost the job
+  async renewLock(id: string | Types.ObjectId, workerId: string): Promise<boolean> {
+    const result = await Job.updateOne(
+      { _id: id, status: 'processing', lockedBy: workerId },
+      { $set: { lockedAt: new Date() } }
+    ).exec();
+    return result.matchedCount > 0;
+  }
+
+  // Record a successful run. Only the worker holding the job can; after a
+  // stall another worker may have claimed it.
+  async markCompleted(id: string | Types.ObjectId, workerId: string): Promise<void> {
+    await Job.updateOne(
+      { _id: id, status: 'processing', lockedBy: workerId },
+      { $set: { status: 'completed', finishedAt: new Date() }, $unset: { lockedAt: 1, lockedBy: 1 } }
+    ).exec();
+  }
+
+  // Schedule another attempt, or give up on the job; like markCompleted,
+  // only for the worker holding it. False when another worker has it now.
+  async markAttemptFailed(id: string | Types.ObjectId, workerId: string, reason: string, retryAt: Date | null): Promise<boolean> {
+    const result = await Job.updateOne(
+      { _id: id, status: 'processing', lockedBy: workerId },
+      retryAt
+        ? { $set: { status: 'pending', runAt: retryAt, lastError: reason }, $unset: { lockedAt: 1, lockedBy: 1 } }
+        : { $set: { status: 'failed', lastError: reason, finishedAt: new Date() }, $unset: { lockedAt: 1, lockedBy: 1 } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Run a failed or cancelled job again with a fresh set of attempts
+  async retry(id: string | Types.ObjectId): Promise<IJob | null> {
+    return await Job.findOneAndUpdate(
+      { _id: id, status: { $in: ['failed', 'cancelled'] } },
+      { $set: { status: 'pending', runAt: new Date(), attempts: 0 }, $unset: { finishedAt: 1 } },
+      { new: true }
+    ).exec();
+  }
+
+  // Cancel a job that hasn't started yet
+  async cancel(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await Job.updateOne(
+      { _id: id, status: { $in: ['pending', 'failed'] } },
+      { $set: { status: 'cancelled', finishedAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+}
@@ -0,0 +1,49 @@
+import { Types } from 'mongoose';
+import { LegalAcceptance, ILegalAcceptance } from '../models/legal-acceptance';
+
+export interface LegalAcceptanceFilter {
+  noticeId: string | Types.ObjectId;
+  version?: number;
+  from?: Date;
+  to?: Date;
+}
+
+export class LegalAcceptanceRepository {
+  // Record an acceptance; accepting the same version again keeps the first record
+  async record(acceptance: Partial<ILegalAcceptance>): Promise<void> {
+    await LegalAcceptance.updateOne(
+      { userId: acceptance.userId, noticeId: acceptance.noticeId, version: acceptance.version },
+      { $setOnInsert: acceptance },
+      { upsert: true }
+    ).exec();
+  }
+
+  // Get a user's acceptances, newest first
+  async findByUser(userId: string | Types.ObjectId): Promise<ILegalAcceptance[]> {
+    return await LegalAcceptance.find({ userId }).sort({ acceptedAt: -1 }).exec();
+  }
+
+  // Count acceptances per version of a notice
+  async countByVersion(noticeId: string | Types.ObjectId): Promise<{ version: number; accepted: number }[]> {
+    return await LegalAcceptance.aggregate([
+      { $match: { noticeId: new Types.ObjectId(noticeId) } },
+      { $group: { _id: '$version', accepted: { $sum: 1 } } },
+      { $project: { _id: 0, version: '$_id', accepted: 1 } },
+      { $sort: { version: -1 } },
+    ]).exec();
+  }
+
+  // Stream acceptances for an export, oldest first
+  cursorForExport(filter: LegalAcceptanceFilter) {
+    const query: any = { noticeId: filter.noticeId };
+    if (filter.version) query.version = filter.version;
+    if (filter.from || filter.to) {
+      query.acceptedAt = {
+        ...(filter.from && { $gte: filter.from }),
+        ...(filter.to && { $lt: filter.to }),
+      };
+    }
+
+    return LegalAcceptance.find(query).sort({ acceptedAt: 1 }).lean<ILegalAcceptance>().cursor();
+  }
+}
@@ -0,0 +1,78 @@
+import { Types } from 'mongoose';
+import { LegalHold, ILegalHold, LegalHoldStatus, LegalHoldSubject } from '../models/legal-hold';
+import { HeldMessage, IHeldMessage } from '../models/held-message';
+
+export class LegalHoldRepository {
+  // Create legal hold
+  async create(holdData: Partial<ILegalHold>): Promise<ILegalHold> {
+    const hold = new LegalHold(holdData);
+    return await hold.save();
+  }
+
+  // Find legal hold by ID
+  async findById(id: string | Types.ObjectId): Promise<ILegalHold | null> {
+    return await LegalHold.findById(id).exec();
+  }
+
+  // Get holds, newest first
+  async list(
+    filter: { status?: LegalHoldStatus; subject?: LegalHoldSubject; subjectId?: string },
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<ILegalHold[]> {
+    const query: Record<string, unknown> = {};
+    if (filter.status) query.status = filter.status;
+    if (filter.subject) query.subject = filter.subject;
+    if (filter.subjectId) query.subjectId = filter.subjectId;
+
+    return await LegalHold.find(query)
+      .sort({ createdAt: -1 })
+      .skip(offset)
+      .limit(limit)
+      .exec();
+  }
+
+  // Active holds on any of the given users or chats
+  async findActive(subject: LegalHoldSubject, subjectIds: (string | Types.ObjectId)[]): Promise<ILegalHold[]> {
+    if (subjectIds.length === 0) return [];
+    return await LegalHold.find({ subject, subjectId: { $in: subjectIds }, status: 'active' }).exec();
+  }
+
+  // IDs of all users or chats under an active hold
+  async activeSubjectIds(subject: LegalHoldSubject): Promise<Types.ObjectId[]> {
+    return await LegalHold.distinct('subjectId', { subject, status: 'active' }).exec();
+  }
+
+  // Release an active hold; null if it was already released
+  async release(
+    id: string | Types.ObjectId,
+    adminId: string | Types.ObjectId,
+    reason: string
+  ): Promise<ILegalHold | null> {
+    return await LegalHold.findOneAndUpdate(
+      { _id: id, status: 'active' },
+      { status: 'released', releasedBy: adminId, releaseReason: reason, releasedAt: new Date() },
+      { new: true }
+    ).exec();
+  }
+
+  // Keep a copy of a message deleted under a hold. Deleting it again adds
+  // the holds covering it then and keeps the first copy.
+  async preserveMessage(data: Partial<IHeldMessage> & { messageId: Types.ObjectId; holdIds: Types.ObjectId[] }): Promise<void> {
+    const { holdIds, ...copy } = data;
+    await HeldMessage.updateOne(
+      { messageId: data.messageId },
+      { $setOnInsert: copy, $addToSet: { holdIds: { $each: holdIds } } },
+      { upsert: true }
+    ).exec();
+  }
+
+  // Messages preserved under a hold, most recently deleted first
+  async listHeldMessages(holdId: string | Types.ObjectId, limit: number = 50, offset: number = 0): Promise<IHeldMessage[]> {
+    return await HeldMessage.find({ holdIds: holdId })
+      .sort({ deletedAt: -1 })
+      .skip(offset)
+      .limit(limit)
+      .exec();
+  }
+}
@@ -0,0 +1,74 @@
+import { Types } from 'mongoose';
+import { LegalNotice, ILegalNotice } from '../models/legal-notice';
+
+export class LegalNoticeRepository {
+  // Create notice
+  async create(noticeData: Partial<ILegalNotice>): Promise<ILegalNotice> {
+    const notice = new LegalNotice(noticeData);
+    return await notice.save();
+  }
+
+  // Find notice by ID
+  async findById(id: string | Types.ObjectId): Promise<ILegalNotice | null> {
+    return await LegalNotice.findById(id).exec();
+  }
+
+  // Find notice by key
+  async findByKey(key: string): Promise<ILegalNotice | null> {
+    return await LegalNotice.findOne({ key }).exec();
+  }
+
+  // Get notices, optionally with archived ones
+  async findAll(includeArchived: boolean = false): Promise<ILegalNotice[]> {
+    const query: any = {};
+    if (!includeArchived) query.archivedAt = { $exists: false };
+
+    return await LegalNotice.find(query).sort({ key: 1 }).exec();
+  }
+
+  // Update title or mandatory flag
+  async update(id: string | Types.ObjectId, updateData: Partial<ILegalNotice>): Promise<ILegalNotice | null> {
+    return await LegalNotice.findByIdAndUpdate(id, { $set: updateData }, { new: true }).exec();
+  }
+
+  // Append a version, only if no other version took that number meanwhile
+  async addVersion(
+    id: string | Types.ObjectId,
+    version: ILegalNotice['versions'][number]
+  ): Promise<ILegalNotice | null> {
+    return await LegalNotice.findOneAndUpdate(
+      { _id: id, archivedAt: { $exists: false }, 'versions.version': { $ne: version.version } },
+      { $push: { versions: version } },
+      { new: true }
+    ).exec();
+  }
+
+  // Claim a version that is in force but hasn't been announced yet
+  async claimDueAnnouncement(now: Date = new Date()): Promise<{ notice: ILegalNotice; version: number } | null> {
+    const notice = await LegalNotice.findOne({
+      archivedAt: { $exists: false },
+      versions: { $elemMatch: { effectiveAt: { $lte: now }, announcedAt: { $exists: false } } },
+    }).exec();
+    if (!notice) return null;
+
+    const due = notice.versions.find(
+      (version: ILegalNotice['versions'][number]) => version.effectiveAt <= now && !version.announcedAt
+    )!;
+    const claimed = await LegalNotice.findOneAndUpdate(
+      { _id: notice._id, versions: { $elemMatch: { version: due.version, announcedAt: { $exists: false } } } },
+      { $set: { 'versions.$.announcedAt': now } },
+      { new: true }
+    ).exec();
+
+    return claimed ? { notice: claimed, version: due.version } : null;
+  }
+
+  // Archive notice; it stops being shown or enforced
+  async archive(id: string | Types.ObjectId): Promise<ILegalNotice | null> {
+    return await LegalNotice.findOneAndUpdate(
+      { _id: id, archivedAt: { $exists: false } },
+      { $set: { archivedAt: new Date() } },
+      { new: true }
+    ).exec();
+  }
+}
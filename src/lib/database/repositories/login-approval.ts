@@ -0,0 +1,56 @@
+import { Types } from 'mongoose';
+import { LoginApproval, ILoginApproval } from '../models/login-approval';
+
+export class LoginApprovalRepository {
+  // Create login approval
+  async create(approvalData: Partial<ILoginApproval>): Promise<ILoginApproval> {
+    const approval = new LoginApproval(approvalData);
+    return await approval.save();
+  }
+
+  // Find login approval by ID
+  async findById(id: string | Types.ObjectId): Promise<ILoginApproval | null> {
+    return await LoginApproval.findById(id).exec();
+  }
+
+  // Find login approval by the hash of its SMS link secret
+  async findByTokenHash(tokenHash: string): Promise<ILoginApproval | null> {
+    return await LoginApproval.findOne({ tokenHash }).exec();
+  }
+
+  // Get the unexpired approvals a user still has to answer
+  async findPending(userId: string | Types.ObjectId): Promise<ILoginApproval[]> {
+    return await LoginApproval.find({ userId, status: 'pending', expiresAt: { $gt: new Date() } })
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Whether a device's sign-in is still waiting for approval
+  async isPending(userId: string | Types.ObjectId, deviceId: string): Promise<boolean> {
+    const approval = await LoginApproval.exists({
+      userId,
+      deviceId,
+      status: 'pending',
+      expiresAt: { $gt: new Date() },
+    }).exec();
+    return !!approval;
+  }
+
+  // Answer a pending approval; null when it was already answered or expired
+  async decide(
+    id: string | Types.ObjectId,
+    status: 'approved' | 'denied',
+    decidedFrom: string
+  ): Promise<ILoginApproval | null> {
+    return await LoginApproval.findOneAndUpdate(
+      { _id: id, status: 'pending', expiresAt: { $gt: new Date() } },
+      { status, decidedFrom, decidedAt: new Date() },
+      { new: true }
+    ).exec();
+  }
+
+  // Record how the user was told about the sign-in
+  async setNotifiedBy(id: string | Types.ObjectId, notifiedBy: ILoginApproval['notifiedBy']): Promise<void> {
+    await LoginApproval.updateOne({ _id: id }, { notifiedBy }).exec();
+  }
+}
@@ -0,0 +1,107 @@
+import { Types } from 'mongoose';
+import { MediaGrant, IMediaGrant } from '../models/media-grant';
+import { MediaAccess, IMediaAccess } from '../models/media-access';
+
+// Grants in force: not revoked and not expired
+function activeQuery(): any {
+  return {
+    revokedAt: { $exists: false },
+    $or: [{ expiresAt: { $exists: false } }, { expiresAt: { $gt: new Date() } }],
+  };
+}
+
+export class MediaGrantRepository {
+  // Create grant
+  async create(grantData: Partial<IMediaGrant>): Promise<IMediaGrant> {
+    const grant = new MediaGrant(grantData);
+    return await grant.save();
+  }
+
+  // Find grant by ID
+  async findById(id: string | Types.ObjectId): Promise<IMediaGrant | null> {
+    return await MediaGrant.findById(id).exec();
+  }
+
+  // Get a file's grants in force
+  async findActiveByMedia(mediaId: string | Types.ObjectId): Promise<IMediaGrant[]> {
+    return await MediaGrant.find({ mediaId, ...activeQuery() }).exec();
+  }
+
+  // Find the grant in force for this exact target
+  async findActiveTarget(
+    mediaId: string | Types.ObjectId,
+    target: { chatId?: string; userId?: string }
+  ): Promise<IMediaGrant | null> {
+    return await MediaGrant.findOne({
+      mediaId,
+      ...(target.chatId ? { chatId: target.chatId } : { userId: target.userId }),
+      ...activeQuery(),
+    }).exec();
+  }
+
+  // Get a file's grants, newest first; optionally only those one user gave
+  async findByMedia(
+    mediaId: string | Types.ObjectId,
+    grantedBy?: string | Types.ObjectId
+  ): Promise<IMediaGrant[]> {
+    return await MediaGrant.find({ mediaId, ...(grantedBy && { grantedBy }) })
+      .populate('grantedBy', 'displayName avatar')
+      .populate('userId', 'displayName avatar')
+      .populate('chatId', 'type name')
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Get grants in force for a user or their chats, newest first
+  async findSharedWith(
+    userId: string | Types.ObjectId,
+    chatIds: Array<string | Types.ObjectId>,
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<IMediaGrant[]> {
+    return await MediaGrant.find({
+      ...activeQuery(),
+      $and: [{ $or: [{ userId }, { chatId: { $in: chatIds } }] }],
+      grantedBy: { $ne: userId },
+    })
+      .populate('mediaId')
+      .populate('grantedBy', 'displayName avatar')
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Revoke a grant
+  async revoke(id: string | Types.ObjectId, revokedBy: string | Types.ObjectId): Promise<IMediaGrant | null> {
+    return await MediaGrant.findOneAndUpdate(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date(), revokedBy } },
+      { new: true }
+    ).exec();
+  }
+
+  // Record a read through a grant
+  async recordAccess(accessData: Partial<IMediaAccess>): Promise<void> {
+    await MediaAccess.create(accessData);
+  }
+
+  // Get reads through grants of a file, newest first
+  async getAccessLog(
+    mediaId: string | Types.ObjectId,
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ entries: IMediaAccess[], total: number }> {
+    const [entries, total] = await Promise.all([
+      MediaAccess.find({ mediaId })
+        .populate('userId', 'displayName avatar')
+        .sort({ createdAt: -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      MediaAccess.countDocuments({ mediaId }).exec(),
+    ]);
+
+    return { entries, total };
+  }
+}
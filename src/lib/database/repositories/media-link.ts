@@ -0,0 +1,84 @@
+import { Types } from 'mongoose';
+import { MediaLink, IMediaLink } from '../models/media-link';
+
+export class MediaLinkRepository {
+  // Create link
+  async create(linkData: Partial<IMediaLink>): Promise<IMediaLink> {
+    const link = new MediaLink(linkData);
+    return await link.save();
+  }
+
+  // Find link by ID
+  async findById(id: string | Types.ObjectId): Promise<IMediaLink | null> {
+    return await MediaLink.findById(id).exec();
+  }
+
+  // Find link by token
+  async findByToken(token: string): Promise<IMediaLink | null> {
+    return await MediaLink.findOne({ token }).exec();
+  }
+
+  // Get a file's links, newest first
+  async findByMedia(mediaId: string | Types.ObjectId): Promise<IMediaLink[]> {
+    return await MediaLink.find({ mediaId }).sort({ createdAt: -1 }).exec();
+  }
+
+  // Get a user's links, newest first
+  async findByCreator(
+    userId: string | Types.ObjectId,
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<IMediaLink[]> {
+    return await MediaLink.find({ createdBy: userId })
+      .populate('mediaId', 'originalName type mimeType size')
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Count a download if the link still allows one
+  async claimDownload(id: string | Types.ObjectId): Promise<IMediaLink | null> {
+    const now = new Date();
+    return await MediaLink.findOneAndUpdate(
+      {
+        _id: id,
+        revokedAt: { $exists: false },
+        $and: [
+          { $or: [{ expiresAt: { $exists: false } }, { expiresAt: { $gt: now } }] },
+          { $or: [{ maxDownloads: { $exists: false } }, { $expr: { $lt: ['$downloadCount', '$maxDownloads'] } }] },
+        ],
+      },
+      {
+        $inc: { downloadCount: 1 },
+        $set: { lastDownloadedAt: now, failedPasswordAttempts: 0 },
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Count a wrong password, locking the link once there are too many
+  async recordFailedPassword(id: string | Types.ObjectId, maxAttempts: number, lockMs: number): Promise<void> {
+    const link = await MediaLink.findByIdAndUpdate(
+      id,
+      { $inc: { failedPasswordAttempts: 1 } },
+      { new: true }
+    ).exec();
+
+    if (link && link.failedPasswordAttempts >= maxAttempts) {
+      await MediaLink.updateOne(
+        { _id: id },
+        { $set: { lockedUntil: new Date(Date.now() + lockMs), failedPasswordAttempts: 0 } }
+      ).exec();
+    }
+  }
+
+  // Revoke a link
+  async revoke(id: string | Types.ObjectId): Promise<IMediaLink | null> {
+    return await MediaLink.findOneAndUpdate(
+      { _id: id, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date() } },
+      { new: true }
+    ).exec();
+  }
+}
@@ -1,5 +1,7 @@
-import { Types } from 'mongoose';
+import { FilterQuery, Types } from 'mongoose';
 import { Media, IMedia } from '../models/media';
+import { DataRegion } from '../models/user';
+import { ListOptions } from '../../utils/pagination';
 
 export class MediaRepository {
   // Create media record
@@ -13,6 +15,11 @@ export class MediaRepository {
     return await Media.findById(id).exec();
   }
 
+  // Find several media records by ID
+  async findByIds(ids: (string | Types.ObjectId)[]): Promise<IMedia[]> {
+    return await Media.find({ _id: { $in: ids } }).exec();
+  }
+
   // Get chat media
   async getChatMedia(
     chatId: string | Types.ObjectId,
@@ -51,6 +58,11 @@ export class MediaRepository {
       .exec();
   }
 
+  // Update media record
+  async update(id: string | Types.ObjectId, updateData: Partial<IMedia>): Promise<IMedia | null> {
+    return await Media.findByIdAndUpdate(id, { $set: updateData }, { new: true }).exec();
+  }
+
   // Delete media
   async delete(id: string | Types.ObjectId): Promise<boolean> {
     const result = await Media.findByIdAndDelete(id).exec();
@@ -88,8 +100,83 @@ export class MediaRepository {
     return result.deletedCount;
   }
 
-  // Get media by checksum (for deduplication)
-  async findByChecksum(checksum: string): Promise<IMedia | null> {
-    return await Media.findOne({ checksumSHA256: checksum }).exec();
+  // Get media by checksum (for deduplication). With a region, only copies
+  // stored in that region count.
+  async findByChecksum(checksum: string, region?: DataRegion): Promise<IMedia | null> {
+    return await Media.findOne({ checksumSHA256: checksum, ...(region && { storageRegion: region }) }).exec();
+  }
+
+  // Stored files behind a user's uploads that are kept outside a region
+  async findFilenamesOutsideRegion(uploadedBy: string | Types.ObjectId, region: DataRegion): Promise<string[]> {
+    return await Media.distinct('filename', { uploadedBy, storageRegion: { $ne: region } }).exec();
+  }
+
+  // A user's uploads of one stored file
+  async findByUploaderAndFilename(uploadedBy: string | Types.ObjectId, filename: string): Promise<IMedia[]> {
+    return await Media.find({ uploadedBy, filename }).exec();
+  }
+
+  // Point media records at a moved copy of their file
+  async updateMany(ids: (string | Types.ObjectId)[], updateData: Partial<IMedia>): Promise<number> {
+    const result = await Media.updateMany({ _id: { $in: ids } }, updateData).exec();
+    return result.modifiedCount;
+  }
+
+  // Count media records pointing at a stored file
+  async countByFilename(filename: string): Promise<number> {
+    return await Media.countDocuments({ filename }).exec();
+  }
+
+  // Count uploads with exactly this perceptual hash
+  async countByPerceptualHash(hash: string): Promise<number> {
+    return await Media.countDocuments({ perceptualHash: hash }).exec();
+  }
+
+  // Filtered, sorted page of uploads (admin)
+  async getForAdmin(filters: AdminMediaFilters, options: ListOptions): Promise<{ media: IMedia[]; total: number }> {
+    const query = this.adminQuery(filters);
+    const [media, total] = await Promise.all([
+      Media.find(query).sort(options.sort).skip(options.offset).limit(options.limit).select('-encryptionKey').exec(),
+      Media.countDocuments(query).exec(),
+    ]);
+    return { media, total };
   }
+
+  // Every upload matching the filters, for exports
+  cursorForAdmin(filters: AdminMediaFilters, sort: ListOptions['sort'], limit: number) {
+    return Media.find(this.adminQuery(filters))
+      .sort(sort)
+      .limit(limit)
+      .select('-encryptionKey')
+      .lean<IMedia>()
+      .cursor();
+  }
+
+  private adminQuery(filters: AdminMediaFilters): FilterQuery<IMedia> {
+    const query: FilterQuery<IMedia> = {};
+
+    if (filters.type) query.type = filters.type;
+    if (filters.uploadedBy) query.uploadedBy = new Types.ObjectId(filters.uploadedBy);
+    if (filters.chatId) query.chatId = new Types.ObjectId(filters.chatId);
+    if (filters.storageRegion) query.storageRegion = filters.storageRegion;
+    if (filters.mimeType) query.mimeType = filters.mimeType;
+    if (filters.from || filters.to) {
+      query.createdAt = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(filters.to && { $lte: filters.to }),
+      };
+    }
+
+    return query;
+  }
+}
+
+export interface AdminMediaFilters {
+  type?: IMedia['type'];
+  uploadedBy?: string;
+  chatId?: string;
+  storageRegion?: DataRegion;
+  mimeType?: string;
+  from?: Date; // uploaded
+  to?: Date;
 }
@@ -0,0 +1,170 @@
+import { Model, Types } from 'mongoose';
+import { IMessageBucket } from '../models/message-bucket';
+import { Chat, IChat } from '../models/chat';
+import { getArchiveModel, getArchiveModels, hasRegionalArchives } from '../regional';
+
+// Buckets live in the database of their chat's data region, see regional.ts
+export class MessageBucketRepository {
+  // Store a bucket
+  async create(bucket: Pick<IMessageBucket, 'chatId' | 'firstAt' | 'lastAt' | 'count' | 'messages'>): Promise<IMessageBucket> {
+    const model = await this.modelForChat(bucket.chatId);
+    return await model.create(bucket);
+  }
+
+  // A chat's buckets, newest first, optionally only those with messages
+  // before a date
+  async cursorForChat(chatId: string | Types.ObjectId, before?: Date) {
+    const model = await this.modelForChat(chatId);
+    return model.find({ chatId, ...(before && { firstAt: { $lt: before } }) })
+      .sort({ lastAt: -1 })
+      .lean<IMessageBucket>()
+      .cursor();
+  }
+
+  // A chat's buckets with messages from a date on, oldest first
+  async cursorForChatFrom(chatId: string | Types.ObjectId, from: Date) {
+    const model = await this.modelForChat(chatId);
+    return model.find({ chatId, lastAt: { $gte: from } })
+      .sort({ lastAt: 1 })
+      .lean<IMessageBucket>()
+      .cursor();
+  }
+
+  // Archived messages with the given ids, as stored
+  async findMessages(ids: (string | Types.ObjectId)[]): Promise<Record<string, any>[]> {
+    const objectIds = ids.map(id => new Types.ObjectId(id.toString()));
+    const found: Record<string, any>[] = [];
+
+    for (const model of getArchiveModels()) {
+      found.push(...await model.aggregate([
+        { $match: { 'messages._id': { $in: objectIds } } },
+        { $unwind: '$messages' },
+        { $match: { 'messages._id': { $in: objectIds } } },
+        { $replaceRoot: { newRoot: '$messages' } },
+      ]).exec());
+    }
+    return found;
+  }
+
+  // Archived messages of a chat with seq from..to, as stored
+  async findMessagesBySeq(chatId: string | Types.ObjectId, from: number, to: number): Promise<Record<string, any>[]> {
+    const model = await this.modelForChat(chatId);
+    const inRange = { $gte: from, $lte: to };
+    return await model.aggregate([
+      { $match: { chatId: new Types.ObjectId(chatId.toString()), 'messages.seq': inRange } },
+      { $unwind: '$messages' },
+      { $match: { 'messages.seq': inRange } },
+      { $replaceRoot: { newRoot: '$messages' } },
+    ]).exec();
+  }
+
+  // Archived messages in the given chats older than before that the user
+  // still has, oldest first: ones they sent and ones not deleted for them
+  async findExpiredForUser(
+    userId: string | Types.ObjectId,
+    chatIds: Types.ObjectId[],
+    before: Date,
+    limit: number
+  ): Promise<Record<string, any>[]> {
+    const owner = new Types.ObjectId(userId.toString());
+    const match = {
+      'messages.createdAt': { $lt: before },
+      $or: [{ 'messages.senderId': owner }, { 'messages.deletedFor': { $ne: owner } }],
+    };
+    const found: Record<string, any>[] = [];
+
+    for (const model of getArchiveModels()) {
+      found.push(...await model.aggregate([
+        { $match: { chatId: { $in: chatIds }, firstAt: { $lt: before } } },
+        { $unwind: '$messages' },
+        { $match: match },
+        { $replaceRoot: { newRoot: '$messages' } },
+        { $sort: { createdAt: 1 } },
+        { $limit: limit },
+      ]).exec());
+    }
+    return found.sort((a, b) => a.createdAt - b.createdAt).slice(0, limit);
+  }
+
+  // Delete archived messages for one user, leaving them for everyone else
+  async hideMessages(ids: (string | Types.ObjectId)[], userId: string | Types.ObjectId): Promise<number> {
+    const objectIds = ids.map(id => new Types.ObjectId(id.toString()));
+    const owner = new Types.ObjectId(userId.toString());
+    let modified = 0;
+
+    for (const model of getArchiveModels()) {
+      const result = await model.updateMany({ 'messages._id': { $in: objectIds } }, [
+        {
+          $set: {
+            messages: {
+              $map: {
+                input: '$messages',
+                in: {
+                  $cond: [
+                    { $in: ['$$this._id', objectIds] },
+                    { $mergeObjects: ['$$this', { deletedFor: { $setUnion: [{ $ifNull: ['$$this.deletedFor', []] }, [owner]] } }] },
+                    '$$this',
+                  ],
+                },
+              },
+            },
+          },
+        },
+      ]).exec();
+      modified += result.modifiedCount;
+    }
+    return modified;
+  }
+
+  // Remove messages from their buckets, dropping buckets left empty
+  async removeMessages(ids: (string | Types.ObjectId)[]): Promise<number> {
+    const objectIds = ids.map(id => new Types.ObjectId(id.toString()));
+    return await this.removeWhere(
+      { 'messages._id': { $in: objectIds } },
+      { $not: { $in: ['$$this._id', objectIds] } }
+    );
+  }
+
+  // Remove every archived message created by an import job
+  async removeByImportJob(jobId: string | Types.ObjectId): Promise<number> {
+    const objectId = new Types.ObjectId(jobId.toString());
+    return await this.removeWhere(
+      { 'messages.importInfo.jobId': objectId },
+      { $ne: ['$$this.importInfo.jobId', objectId] }
+    );
+  }
+
+  // Delete a chat's archived history
+  async deleteByChat(chatId: string | Types.ObjectId): Promise<number> {
+    let deleted = 0;
+    for (const model of getArchiveModels()) {
+      const result = await model.deleteMany({ chatId }).exec();
+      deleted += result.deletedCount;
+    }
+    return deleted;
+  }
+
+  private async removeWhere(filter: Record<string, unknown>, keep: Record<string, unknown>): Promise<number> {
+    let modified = 0;
+
+    for (const model of getArchiveModels()) {
+      const bucketIds = await model.distinct('_id', filter).exec();
+      if (bucketIds.length === 0) continue;
+
+      const result = await model.updateMany({ _id: { $in: bucketIds } }, [
+        { $set: { messages: { $filter: { input: '$messages', cond: keep } } } },
+        { $set: { count: { $size: '$messages' } } },
+      ]).exec();
+      await model.deleteMany({ _id: { $in: bucketIds }, count: 0 }).exec();
+      modified += result.modifiedCount;
+    }
+    return modified;
+  }
+
+  private async modelForChat(chatId: string | Types.ObjectId): Promise<Model<IMessageBucket>> {
+    if (!hasRegionalArchives()) return getArchiveModel();
+
+    const chat = await Chat.findById(chatId).select('dataRegion').lean<Pick<IChat, 'dataRegion'>>().exec();
+    return getArchiveModel(chat?.dataRegion);
+  }
+}
@@ -1,20 +1,120 @@
 import { Types } from 'mongoose';
-import { Message, IMessage } from '../models/message';
+import { Message, IMessage, WITHHELD_MESSAGE_STATES } from '../models/message';
+import { Chat } from '../models/chat';
+import { MessageBucketRepository } from './message-bucket';
+import { escapeRegex } from '../../utils/helpers';
 
+const POPULATE_MESSAGE = [
+  { path: 'senderId', select: 'displayName avatar' },
+  { path: 'replyTo' },
+  { path: 'media' },
+];
+
+// Messages live one per document, except in chats on bucketed storage,
+// whose older history is packed into buckets (see models/message-bucket).
+// Reads here merge both; changes move archived messages back first.
+// Search, unread counts and exports only cover live messages.
 export class MessageRepository {
-  // Create message
+  private messageBucketRepository = new MessageBucketRepository();
+
+  // Create message, numbering it in its chat
   async create(messageData: Partial<IMessage>): Promise<IMessage> {
-    const message = new Message(messageData);
+    const [seq] = await this.reserveSeqs(messageData.chatId!, 1);
+    const message = new Message({ ...messageData, seq });
     return await message.save();
   }
 
+  // Bulk insert messages, keeping any createdAt supplied (used by imports).
+  // All messages belong to the same chat and are numbered in order.
+  async createMany(messages: Partial<IMessage>[]): Promise<IMessage[]> {
+    if (messages.length === 0) return [];
+    const seqs = await this.reserveSeqs(messages[0].chatId!, messages.length);
+    return await Message.insertMany(messages.map((message, index) => ({ ...message, seq: seqs[index] })), { ordered: true });
+  }
+
+  // Take the next seqs of a chat from its counter. A seq whose message then
+  // fails to save is never reused; clients see it as a gap that fills empty.
+  private async reserveSeqs(chatId: string | Types.ObjectId, count: number): Promise<number[]> {
+    const chat = await Chat.findByIdAndUpdate(chatId, { $inc: { messageSeq: count } }, { new: true })
+      .select('messageSeq')
+      .lean<{ messageSeq: number }>()
+      .exec();
+    if (!chat) return [];
+
+    const first = chat.messageSeq - count + 1;
+    return Array.from({ length: count }, (_, index) => first + index);
+  }
+
+  // Remove every message created by an import job
+  async deleteByImportJob(jobId: string | Types.ObjectId): Promise<number> {
+    const result = await Message.deleteMany({ 'importInfo.jobId': jobId }).exec();
+    await this.messageBucketRepository.removeByImportJob(jobId);
+    return result.deletedCount;
+  }
+
+  // A user's messages older than before that they still have, oldest
+  // first, live and archived: ones they sent and ones not deleted for them
+  async findExpiredForUser(
+    userId: string | Types.ObjectId,
+    chatIds: Types.ObjectId[],
+    before: Date,
+    limit: number
+  ): Promise<Record<string, any>[]> {
+    const live = await Message.find({
+      chatId: { $in: chatIds },
+      createdAt: { $lt: before },
+      $or: [{ senderId: userId }, { deletedFor: { $ne: userId } }],
+    })
+      .sort({ createdAt: 1 })
+      .limit(limit)
+      .lean<Record<string, any>[]>()
+      .exec();
+    const archived = live.length < limit
+      ? await this.messageBucketRepository.findExpiredForUser(userId, chatIds, before, limit - live.length)
+      : [];
+    return [...live, ...archived];
+  }
+
+  // Apply a user's retention period to messages from findExpiredForUser:
+  // ones they sent are removed for everyone, the rest are deleted for them
+  async purgeForUser(userId: string | Types.ObjectId, messages: Record<string, any>[]): Promise<{ removed: number; hidden: number }> {
+    const owner = userId.toString();
+    const sent = messages.filter(message => message.senderId.toString() === owner).map(message => message._id);
+    const received = messages.filter(message => message.senderId.toString() !== owner).map(message => message._id);
+
+    if (sent.length > 0) {
+      await Message.deleteMany({ _id: { $in: sent } }).exec();
+      await this.messageBucketRepository.removeMessages(sent);
+    }
+    if (received.length > 0) {
+      await Message.updateMany({ _id: { $in: received } }, { $addToSet: { deletedFor: userId } }).exec();
+      await this.messageBucketRepository.hideMessages(received, userId);
+    }
+    return { removed: sent.length, hidden: received.length };
+  }
+
   // Find message by ID
   async findById(id: string | Types.ObjectId): Promise<IMessage | null> {
-    return await Message.findById(id)
+    const message = await Message.findById(id)
       .populate('senderId', 'displayName avatar')
       .populate('replyTo')
       .populate('media')
       .exec();
+    if (message) return message;
+
+    const [archived] = await this.messageBucketRepository.findMessages([id]);
+    return archived ? await Message.populate(Message.hydrate(archived), POPULATE_MESSAGE) : null;
+  }
+
+  // A sender's message in a chat by the ID their client gave it
+  async findByClientMessageId(
+    chatId: string | Types.ObjectId,
+    senderId: string | Types.ObjectId,
+    clientMessageId: string
+  ): Promise<IMessage | null> {
+    return await Message.findOne({ chatId, senderId, clientMessageId })
+      .populate(POPULATE_MESSAGE)
+      .exec();
   }
 
   // Get chat messages
@@ -29,33 +129,216 @@ export class MessageRepository {
       isDeleted: false
     };
 
-    // Filter out messages deleted for this user
+    // Filter out messages deleted for this user, and messages withheld by
+    // moderation unless they're the user's own
     if (userId) {
       query.deletedFor = { $ne: userId };
+      query.$or = [
+        { 'moderation.state': { $nin: WITHHELD_MESSAGE_STATES } },
+        { senderId: userId },
+      ];
+    } else {
+      query['moderation.state'] = { $nin: WITHHELD_MESSAGE_STATES };
     }
 
     if (before) {
       query.createdAt = { $lt: before };
     }
 
-    return await Message.find(query)
+    const live = await Message.find(query)
       .populate('senderId', 'displayName avatar')
       .populate('replyTo')
       .populate('media')
       .sort({ createdAt: -1 })
       .limit(limit)
       .exec();
+
+    // With a full page, archived messages only matter if they are newer
+    // than its oldest message
+    const newerThan = live.length >= limit ? live[live.length - 1].createdAt : undefined;
+    const liveIds = new Set(live.map(message => message._id.toString()));
+    const archived = (await this.findArchived(chatId, limit, before, userId, newerThan))
+      .filter(message => !liveIds.has(message._id.toString()));
+    if (archived.length === 0) return live;
+
+    return [...live, ...archived]
+      .sort((a, b) => b.createdAt.getTime() - a.createdAt.getTime())
+      .slice(0, limit);
+  }
+
+  // Chat messages sent at or after a date, oldest first; the other half of
+  // getChatMessages for opening a chat part way through its history
+  async getChatMessagesFrom(
+    chatId: string | Types.ObjectId,
+    from: Date,
+    limit: number,
+    userId: string | Types.ObjectId
+  ): Promise<IMessage[]> {
+    const live = await Message.find({
+      chatId,
+      isDeleted: false,
+      deletedFor: { $ne: userId },
+      $or: [
+        { 'moderation.state': { $nin: WITHHELD_MESSAGE_STATES } },
+        { senderId: userId },
+      ],
+      createdAt: { $gte: from },
+    })
+      .populate(POPULATE_MESSAGE)
+      .sort({ createdAt: 1 })
+      .limit(limit)
+      .exec();
+
+    // With a full page, archived messages only matter if they are older
+    // than its newest message
+    const olderThan = live.length >= limit ? live[live.length - 1].createdAt : undefined;
+    const liveIds = new Set(live.map(message => message._id.toString()));
+    const archived = (await this.findArchivedFrom(chatId, from, limit, userId, olderThan))
+      .filter(message => !liveIds.has(message._id.toString()));
+    if (archived.length === 0) return live;
+
+    return [...live, ...archived]
+      .sort((a, b) => a.createdAt.getTime() - b.createdAt.getTime())
+      .slice(0, limit);
+  }
+
+  // Messages of a chat with seq from..to (inclusive), oldest first, for
+  // clients filling a gap. Seqs missing from the result are messages the
+  // user can't see: deleted, deleted for them, or withheld by moderation.
+  async getChatMessagesBySeq(
+    chatId: string | Types.ObjectId,
+    from: number,
+    to: number,
+    userId: string | Types.ObjectId
+  ): Promise<IMessage[]> {
+    const viewer = userId.toString();
+    const withheld: readonly string[] = WITHHELD_MESSAGE_STATES;
+    const visible = (message: Record<string, any>) =>
+      !message.isDeleted
+      && !(message.deletedFor || []).some((id: Types.ObjectId) => id.toString() === viewer)
+      && (!withheld.includes(message.moderation?.state) || message.senderId.toString() === viewer);
+
+    const live = await Message.find({ chatId, seq: { $gte: from, $lte: to } }).lean().exec();
+    const liveIds = new Set(live.map(message => message._id.toString()));
+    const archived = (await this.messageBucketRepository.findMessagesBySeq(chatId, from, to))
+      .filter(message => !liveIds.has(message._id.toString()));
+
+    const messages = ([...live, ...archived] as Record<string, any>[])
+      .filter(visible)
+      .sort((a, b) => a.seq - b.seq)
+      .map(message => Message.hydrate(message));
+    return await Message.populate(messages, POPULATE_MESSAGE);
+  }
+
+  // Page through a chat's buckets for getChatMessages, applying the same
+  // visibility rules as its query
+  private async findArchived(
+    chatId: string | Types.ObjectId,
+    limit: number,
+    before?: Date,
+    userId?: string | Types.ObjectId,
+    newerThan?: Date
+  ): Promise<IMessage[]> {
+    const viewer = userId?.toString();
+    const withheld: readonly string[] = WITHHELD_MESSAGE_STATES;
+    const visible = (message: Record<string, any>) => {
+      if (message.isDeleted) return false;
+      if (before && message.createdAt >= before) return false;
+      if (newerThan && message.createdAt <= newerThan) return false;
+      if (viewer && (message.deletedFor || []).some((id: Types.ObjectId) => id.toString() === viewer)) return false;
+      return !withheld.includes(message.moderation?.state) || (!!viewer && message.senderId.toString() === viewer);
+    };
+
+    const found: Record<string, any>[] = [];
+    const cursor = await this.messageBucketRepository.cursorForChat(chatId, before);
+    try {
+      for await (const bucket of cursor) {
+        // Newest buckets come first; older ones can't improve a full page
+        if (newerThan && bucket.lastAt <= newerThan) break;
+        if (found.length >= limit && bucket.lastAt < found[limit - 1].createdAt) break;
+
+        found.push(...bucket.messages.filter(visible));
+        found.sort((a, b) => b.createdAt.getTime() - a.createdAt.getTime());
+      }
+    } finally {
+      await cursor.close();
+    }
+    if (found.length === 0) return [];
+
+    const messages = found.slice(0, limit).map(message => Message.hydrate(message));
+    return await Message.populate(messages, POPULATE_MESSAGE);
+  }
+
+  // Page forwards through a chat's buckets for getChatMessagesFrom
+  private async findArchivedFrom(
+    chatId: string | Types.ObjectId,
+    from: Date,
+    limit: number,
+    userId: string | Types.ObjectId,
+    olderThan?: Date
+  ): Promise<IMessage[]> {
+    const viewer = userId.toString();
+    const withheld: readonly string[] = WITHHELD_MESSAGE_STATES;
+    const visible = (message: Record<string, any>) => {
+      if (message.isDeleted) return false;
+      if (message.createdAt < from) return false;
+      if (olderThan && message.createdAt >= olderThan) return false;
+      if ((message.deletedFor || []).some((id: Types.ObjectId) => id.toString() === viewer)) return false;
+      return !withheld.includes(message.moderation?.state) || message.senderId.toString() === viewer;
+    };
+
+    const found: Record<string, any>[] = [];
+    const cursor = await this.messageBucketRepository.cursorForChatFrom(chatId, from);
+    try {
+      for await (const bucket of cursor) {
+        // Oldest buckets come first; newer ones can't improve a full page
+        if (olderThan && bucket.firstAt >= olderThan) break;
+        if (found.length >= limit && bucket.firstAt > found[limit - 1].createdAt) break;
+
+        found.push(...bucket.messages.filter(visible));
+        found.sort((a, b) => a.createdAt.getTime() - b.createdAt.getTime());
+      }
+    } finally {
+      await cursor.close();
+    }
+    if (found.length === 0) return [];
+
+    const messages = found.slice(0, limit).map(message => Message.hydrate(message));
+    return await Message.populate(messages, POPULATE_MESSAGE);
+  }
+
+  // Changes only apply to live documents, so archived messages about to
+  // change move back out of their bucket first
+  private async thaw(ids: (string | Types.ObjectId)[]): Promise<void> {
+    const archived = await this.messageBucketRepository.findMessages(ids);
+    if (archived.length === 0) return;
+
+    // Copied before leaving the bucket: a crash in between leaves a
+    // duplicate, which readers skip, rather than losing the message
+    await Message.collection.insertMany(archived, { ordered: false }).catch((error: any) => {
+      if (error?.code !== 11000) throw error;
+    });
+    await this.messageBucketRepository.removeMessages(archived.map(message => message._id));
   }
 
   // Update message
   async update(id: string | Types.ObjectId, updateData: Partial<IMessage>): Promise<IMessage | null> {
+    await this.thaw([id]);
     return await Message.findByIdAndUpdate(id, updateData, { new: true })
       .populate('senderId', 'displayName avatar')
       .exec();
   }
 
+  // Add a label, once
+  async addLabel(id: string | Types.ObjectId, label: string): Promise<boolean> {
+    await this.thaw([id]);
+    const result = await Message.updateOne({ _id: id }, { $addToSet: { labels: label } }).exec();
+    return result.matchedCount > 0;
+  }
+
   // Delete message (soft delete)
   async delete(id: string | Types.ObjectId, userId?: string | Types.ObjectId): Promise<boolean> {
+    await this.thaw([id]);
     if (userId) {
       // Delete for specific user
       const result = await Message.findByIdAndUpdate(id, {
@@ -74,6 +357,7 @@ export class MessageRepository {
 
   // Mark message as delivered
   async markAsDelivered(messageId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<boolean> {
+    await this.thaw([messageId]);
     const result = await Message.findByIdAndUpdate(messageId, {
       $addToSet: {
         deliveredTo: {
@@ -87,6 +371,7 @@ export class MessageRepository {
 
   // Mark message as read
   async markAsRead(messageId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<boolean> {
+    await this.thaw([messageId]);
     const result = await Message.findByIdAndUpdate(messageId, {
       $addToSet: {
         readBy: {
@@ -101,6 +386,7 @@ export class MessageRepository {
 
   // Mark multiple messages as read
   async markMultipleAsRead(messageIds: (string | Types.ObjectId)[], userId: string | Types.ObjectId): Promise<number> {
+    await this.thaw(messageIds);
     const result = await Message.updateMany(
       { 
         _id: { $in: messageIds },
@@ -119,8 +405,43 @@ export class MessageRepository {
     return result.modifiedCount;
   }
 
+  // Chat, seq and send time of messages, for moving read positions
+  async findReadTargets(messageIds: (string | Types.ObjectId)[]): Promise<Pick<IMessage, '_id' | 'chatId' | 'seq' | 'createdAt'>[]> {
+    return await Message.find({ _id: { $in: messageIds } })
+      .select('chatId seq createdAt')
+      .lean<Pick<IMessage, '_id' | 'chatId' | 'seq' | 'createdAt'>[]>()
+      .exec();
+  }
+
+  // Oldest message the user can see that someone else sent after a date
+  // (or ever, without one): where the unread divider goes
+  async findFirstUnread(
+    chatId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    after?: Date
+  ): Promise<Pick<IMessage, '_id' | 'seq' | 'createdAt'> | null> {
+    return await Message.findOne({
+      chatId,
+      senderId: { $ne: userId },
+      isDeleted: false,
+      deletedFor: { $ne: userId },
+      'moderation.state': { $nin: WITHHELD_MESSAGE_STATES },
+      ...(after && { createdAt: { $gt: after } }),
+    })
+      .sort({ createdAt: 1 })
+      .select('seq createdAt')
+      .lean<Pick<IMessage, '_id' | 'seq' | 'createdAt'>>()
+      .exec();
+  }
+
   // Add reaction
-  async addReaction(messageId: string | Types.ObjectId, userId: string | Types.ObjectId, emoji: string): Promise<boolean> {
+  async addReaction(
+    messageId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    emoji: string,
+    customEmojiId?: string | Types.ObjectId
+  ): Promise<boolean> {
+    await this.thaw([messageId]);
     // Remove existing reaction from this user first
     await Message.findByIdAndUpdate(messageId, {
       $pull: { reactions: { userId } }
@@ -132,6 +453,7 @@ export class MessageRepository {
         reactions: {
           userId,
           emoji,
+          ...(customEmojiId && { customEmojiId }),
           createdAt: new Date()
         }
       }
@@ -139,14 +461,45 @@ export class MessageRepository {
     return !!result;
   }
 
+  // Cast or move a single-choice poll vote; a null option just retracts it
+  async votePoll(
+    messageId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    optionIndex: number | null
+  ): Promise<IMessage | null> {
+    await this.thaw([messageId]);
+
+    // Remove any earlier vote from this user first
+    await Message.updateOne(
+      { _id: messageId, 'metadata.poll.closed': false },
+      { $pull: { 'metadata.poll.options.$[].votes': userId } }
+    ).exec();
+
+    if (optionIndex === null) {
+      return await Message.findById(messageId).exec();
+    }
+
+    return await Message.findOneAndUpdate(
+      { _id: messageId, 'metadata.poll.closed': false, [`metadata.poll.options.${optionIndex}`]: { $exists: true } },
+      { $addToSet: { [`metadata.poll.options.${optionIndex}.votes`]: userId } },
+      { new: true }
+    ).exec();
+  }
+
   // Remove reaction
   async removeReaction(messageId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<boolean> {
+    await this.thaw([messageId]);
     const result = await Message.findByIdAndUpdate(messageId, {
       $pull: { reactions: { userId } }
     }).exec();
     return !!result;
   }
 
+  // Whether a user has ever written in a chat
+  async hasMessageFrom(chatId: string | Types.ObjectId, senderId: string | Types.ObjectId): Promise<boolean> {
+    return !!(await Message.exists({ chatId, senderId }).exec());
+  }
+
   // Get unread count for chat
   async getUnreadCount(chatId: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<number> {
     return await Message.countDocuments({
@@ -154,7 +507,8 @@ export class MessageRepository {
       senderId: { $ne: userId },
       'readBy.userId': { $ne: userId },
       isDeleted: false,
-      deletedFor: { $ne: userId }
+      deletedFor: { $ne: userId },
+      'moderation.state': { $nin: WITHHELD_MESSAGE_STATES }
     }).exec();
   }
 
@@ -168,7 +522,8 @@ export class MessageRepository {
     const searchRegex = new RegExp(query, 'i');
     const searchQuery: any = {
       content: { $regex: searchRegex },
-      isDeleted: false
+      isDeleted: false,
+      'moderation.state': { $nin: WITHHELD_MESSAGE_STATES }
     };
 
     if (chatId) {
@@ -184,6 +539,60 @@ export class MessageRepository {
       .exec();
   }
 
+  // Search one chat for messages containing every term, newest first, with
+  // the same visibility rules as getChatMessages. In pseudonymous groups a
+  // sender filter only matches messages sent under the sender's real name
+  // or their pseudonym ID, unless the viewer may see who is behind them.
+  async searchChatMessages(
+    chatId: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    filters: {
+      terms: string[];
+      senderId?: string;
+      type?: IMessage['type'];
+      from?: Date;
+      to?: Date;
+      before?: Date;
+      revealPseudonyms?: boolean;
+    },
+    limit: number = 20
+  ): Promise<IMessage[]> {
+    const and: any[] = [
+      { $or: [{ 'moderation.state': { $nin: WITHHELD_MESSAGE_STATES } }, { senderId: userId }] },
+      ...filters.terms.map(term => ({ content: { $regex: new RegExp(escapeRegex(term), 'i') } })),
+    ];
+
+    if (filters.senderId) {
+      const senderId = new Types.ObjectId(filters.senderId);
+      and.push({
+        $or: filters.revealPseudonyms || senderId.equals(userId.toString())
+          ? [{ senderId }, { 'pseudonym.id': senderId }]
+          : [{ senderId, pseudonym: { $exists: false } }, { 'pseudonym.id': senderId }],
+      });
+    }
+
+    const query: any = {
+      chatId,
+      isDeleted: false,
+      deletedFor: { $ne: userId },
+      $and: and,
+    };
+    if (filters.type) query.type = filters.type;
+    if (filters.from || filters.to || filters.before) {
+      const upper = [filters.to, filters.before].filter((date): date is Date => !!date);
+      query.createdAt = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(upper.length > 0 && { $lt: new Date(Math.min(...upper.map(date => date.getTime()))) }),
+      };
+    }
+
+    return await Message.find(query)
+      .populate(POPULATE_MESSAGE)
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
   // Get message analytics (admin)
   async getMessageAnalytics(startDate: Date, endDate: Date): Promise<any> {
     return await Message.aggregate([
@@ -217,5 +626,164 @@ export class MessageRepository {
       .limit(limit)
       .exec();
   }
-}
 
+  // Messages held for moderator review, oldest first
+  async getReviewQueue(limit: number = 50, offset: number = 0): Promise<{ messages: IMessage[], total: number }> {
+    const query = { 'moderation.state': 'pending', 'moderation.groupReview': { $ne: true }, isDeleted: false };
+    const [messages, total] = await Promise.all([
+      Message.find(query)
+        .populate('senderId', 'displayName phoneNumber avatar')
+        .populate('chatId', 'type groupInfo.name')
+        .populate('media')
+        .sort({ createdAt: 1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      Message.countDocuments(query).exec(),
+    ]);
+    return { messages, total };
+  }
+
+  // Approve or reject a held message, unless another moderator already did
+  async review(
+    id: string | Types.ObjectId,
+    state: 'approved' | 'rejected',
+    reviewedBy: string | Types.ObjectId
+  ): Promise<IMessage | null> {
+    await this.thaw([id]);
+    return await Message.findOneAndUpdate(
+      { _id: id, 'moderation.state': 'pending', 'moderation.groupReview': { $ne: true } },
+      { $set: { 'moderation.state': state, 'moderation.reviewedBy': reviewedBy, 'moderation.reviewedAt': new Date() } },
+      { new: true }
+    ).exec();
+  }
+
+  // Messages of a moderated group waiting for its admins, oldest first
+  async getGroupReviewQueue(
+    chatId: string | Types.ObjectId,
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ messages: IMessage[], total: number }> {
+    const query = { chatId, 'moderation.state': 'pending', 'moderation.groupReview': true, isDeleted: false };
+    const [messages, total] = await Promise.all([
+      Message.find(query)
+        .populate('senderId', 'displayName avatar')
+        .populate('media')
+        .sort({ createdAt: 1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      Message.countDocuments(query).exec(),
+    ]);
+    return { messages, total };
+  }
+
+  // Approve or reject a message held for a group's admins, unless another
+  // admin already did
+  async reviewInGroup(
+    id: string | Types.ObjectId,
+    chatId: string | Types.ObjectId,
+    state: 'approved' | 'rejected',
+    reviewedBy: string | Types.ObjectId,
+    reason?: string
+  ): Promise<IMessage | null> {
+    await this.thaw([id]);
+    return await Message.findOneAndUpdate(
+      { _id: id, chatId, 'moderation.state': 'pending', 'moderation.groupReview': true },
+      {
+        $set: {
+          'moderation.state': state,
+          'moderation.reviewedBy': reviewedBy,
+          'moderation.reviewedAt': new Date(),
+          ...(reason && { 'moderation.reason': reason }),
+        },
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Count a sender's withheld messages per moderation state
+  async countWithheldBySender(senderId: string | Types.ObjectId): Promise<Record<string, number>> {
+    const rows = await Message.aggregate([
+      { $match: { senderId: new Types.ObjectId(senderId.toString()), 'moderation.state': { $exists: true } } },
+      { $group: { _id: '$moderation.state', count: { $sum: 1 } } },
+    ]).exec();
+    return Object.fromEntries(rows.map(row => [row._id, row.count]));
+  }
+
+  // Engagement in a group since a date, for group analytics. Reactions are
+  // those on messages sent in the window. Withheld and deleted messages are
+  // left out.
+  async getGroupEngagement(chatId: string | Types.ObjectId, since: Date, top: number): Promise<{
+    totals: { messages: number; reactions: number; activeMembers: number };
+    topMessages: { _id: Types.ObjectId; senderId: Types.ObjectId; type: string; content: string; emoji: string[]; reactionCount: number; createdAt: Date }[];
+    topSenders: { _id: Types.ObjectId; messages: number; reactionsReceived: number }[];
+    reactors: { _id: Types.ObjectId; count: number }[];
+    daily: { _id: string; messages: number; reactions: number }[];
+    hourly: { _id: number; messages: number }[];
+  }> {
+    const reactionCount = { $size: { $ifNull: ['$reactions', []] } };
+
+    const [result] = await Message.aggregate([
+      {
+        $match: {
+          chatId: new Types.ObjectId(chatId.toString()),
+          createdAt: { $gte: since },
+          isDeleted: false,
+          'moderation.state': { $nin: WITHHELD_MESSAGE_STATES },
+        },
+      },
+      {
+        $facet: {
+          totals: [
+            { $group: { _id: null, messages: { $sum: 1 }, reactions: { $sum: reactionCount }, senders: { $addToSet: '$senderId' } } },
+            { $project: { _id: 0, messages: 1, reactions: 1, activeMembers: { $size: '$senders' } } },
+          ],
+          topMessages: [
+            { $addFields: { reactionCount } },
+            { $match: { reactionCount: { $gt: 0 } } },
+            { $sort: { reactionCount: -1, createdAt: -1 } },
+            { $limit: top },
+            { $project: { senderId: 1, type: 1, content: 1, emoji: '$reactions.emoji', reactionCount: 1, createdAt: 1 } },
+          ],
+          topSenders: [
+            { $group: { _id: '$senderId', messages: { $sum: 1 }, reactionsReceived: { $sum: reactionCount } } },
+            { $sort: { messages: -1 } },
+            { $limit: top },
+          ],
+          reactors: [
+            { $unwind: '$reactions' },
+            { $group: { _id: '$reactions.userId', count: { $sum: 1 } } },
+          ],
+          daily: [
+            { $group: { _id: { $dateToString: { format: '%Y-%m-%d', date: '$createdAt' } }, messages: { $sum: 1 }, reactions: { $sum: reactionCount } } },
+            { $sort: { _id: 1 } },
+          ],
+          hourly: [
+            { $group: { _id: { $hour: '$createdAt' }, messages: { $sum: 1 } } },
+          ],
+        },
+      },
+    ]).exec();
+
+    return {
+      ...result,
+      totals: result.totals[0] || { messages: 0, reactions: 0, activeMembers: 0 },
+    };
+  }
+
+  // Stream messages for an export in _id order, starting after a cursor
+  cursorForExport(filter: { after?: Types.ObjectId; chatId?: Types.ObjectId; from?: Date; to?: Date }) {
+    const query: any = {};
+    if (filter.after) query._id = { $gt: filter.after };
+    if (filter.chatId) query.chatId = filter.chatId;
+    if (filter.from || filter.to) {
+      query.createdAt = {
+        ...(filter.from && { $gte: filter.from }),
+        ...(filter.to && { $lt: filter.to }),
+      };
+    }
+
+    return Message.find(query).sort({ _id: 1 }).lean<IMessage>().cursor();
+  }
+}
@@ -0,0 +1,75 @@
+import { Types } from 'mongoose';
+import { ModerationAction, IModerationAction, ModerationActionKind } from '../models/moderation-action';
+
+export class ModerationActionRepository {
+  // Create action
+  async create(actionData: Partial<IModerationAction>): Promise<IModerationAction> {
+    const action = new ModerationAction(actionData);
+    return await action.save();
+  }
+
+  // Find action by ID
+  async findById(id: string | Types.ObjectId): Promise<IModerationAction | null> {
+    return await ModerationAction.findById(id).exec();
+  }
+
+  // Find the user's action of this kind that is still in force
+  async findActive(userId: string | Types.ObjectId, action: ModerationActionKind): Promise<IModerationAction | null> {
+    return await ModerationAction.findOne({ userId, action, liftedAt: { $exists: false } })
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Get a user's actions, newest first
+  async findByUser(userId: string | Types.ObjectId, limit: number = 100): Promise<IModerationAction[]> {
+    return await ModerationAction.find({ userId })
+      .populate('issuedBy', 'displayName username')
+      .populate('liftedBy', 'displayName username')
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Get actions in force, optionally of one kind (admin)
+  async findActiveAll(
+    action?: ModerationActionKind,
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ actions: IModerationAction[], total: number }> {
+    const query: any = { liftedAt: { $exists: false } };
+    if (action) query.action = action;
+
+    const [actions, total] = await Promise.all([
+      ModerationAction.find(query)
+        .populate('userId', 'displayName phoneNumber avatar')
+        .populate('issuedBy', 'displayName username')
+        .sort({ createdAt: -1 })
+        .limit(limit)
+        .skip(offset)
+        .exec(),
+      ModerationAction.countDocuments(query).exec(),
+    ]);
+    return { actions, total };
+  }
+
+  // Get actions in force whose expiry has passed
+  async findExpired(now: Date = new Date(), limit: number = 100): Promise<IModerationAction[]> {
+    return await ModerationAction.find({ liftedAt: { $exists: false }, expiresAt: { $lte: now } })
+      .sort({ expiresAt: 1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Mark action lifted, unless it already was
+  async lift(
+    id: string | Types.ObjectId,
+    liftReason: string,
+    liftedBy?: string | Types.ObjectId
+  ): Promise<IModerationAction | null> {
+    return await ModerationAction.findOneAndUpdate(
+      { _id: id, liftedAt: { $exists: false } },
+      { $set: { liftedAt: new Date(), liftReason, ...(liftedBy && { liftedBy }) } },
+      { new: true }
+    ).exec();
+  }
+}
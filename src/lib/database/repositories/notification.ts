@@ -0,0 +1,32 @@
+import { Types } from 'mongoose';
+import { Notification, INotification } from '../models/notification';
+
+export class NotificationRepository {
+  // Create notification
+  async create(notificationData: Partial<INotification>): Promise<INotification> {
+    const notification = new Notification(notificationData);
+    return await notification.save();
+  }
+
+  // Users with notifications held back by Do Not Disturb
+  async getUsersWithDeferred(): Promise<Types.ObjectId[]> {
+    return await Notification.distinct('userId', { deliveryStatus: 'deferred' }).exec();
+  }
+
+  // Take a user's held-back notifications, oldest first, marking them sent.
+  // Returns nothing if another worker got there first.
+  async takeDeferred(userId: string | Types.ObjectId): Promise<INotification[]> {
+    const notifications = await Notification.find({ userId, deliveryStatus: 'deferred' })
+      .sort({ createdAt: 1 })
+      .exec();
+    if (notifications.length === 0) {
+      return [];
+    }
+
+    const result = await Notification.updateMany(
+      { _id: { $in: notifications.map((notification: INotification) => notification._id) }, deliveryStatus: 'deferred' },
+      { $set: { deliveryStatus: 'sent', sentAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0 ? notifications : [];
+  }
+}
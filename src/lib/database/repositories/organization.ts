@@ -0,0 +1,66 @@
+import { Types } from 'mongoose';
+import { Organization, IOrganization, OrganizationStatus } from '../models/organization';
+import { User } from '../models/user';
+import { Chat } from '../models/chat';
+
+export class OrganizationRepository {
+  // Create organization
+  async create(organizationData: Partial<IOrganization>): Promise<IOrganization> {
+    const organization = new Organization(organizationData);
+    return await organization.save();
+  }
+
+  // Find organization by ID
+  async findById(id: string | Types.ObjectId): Promise<IOrganization | null> {
+    return await Organization.findById(id).exec();
+  }
+
+  // Find organization by slug
+  async findBySlug(slug: string): Promise<IOrganization | null> {
+    return await Organization.findOne({ slug }).exec();
+  }
+
+  // Get organizations, newest first, with the total matching
+  async list(
+    filter: { status?: OrganizationStatus },
+    limit: number = 50,
+    offset: number = 0
+  ): Promise<{ organizations: IOrganization[]; total: number }> {
+    const query: Record<string, unknown> = {};
+    if (filter.status) query.status = filter.status;
+
+    const [organizations, total] = await Promise.all([
+      Organization.find(query).sort({ createdAt: -1 }).skip(offset).limit(limit).exec(),
+      Organization.countDocuments(query).exec(),
+    ]);
+    return { organizations, total };
+  }
+
+  // Find the organization serving a custom domain
+  async findByDomain(domain: string): Promise<IOrganization | null> {
+    return await Organization.findOne({ 'branding.domains': domain }).exec();
+  }
+
+  // Update organization; fields in unset are removed
+  async update(
+    id: string | Types.ObjectId,
+    updateData: Record<string, unknown>,
+    unset: string[] = []
+  ): Promise<IOrganization | null> {
+    const update: Record<string, unknown> = {};
+    if (Object.keys(updateData).length > 0) update.$set = updateData;
+    if (unset.length > 0) update.$unset = Object.fromEntries(unset.map(field => [field, 1]));
+
+    return await Organization.findByIdAndUpdate(id, update, { new: true }).exec();
+  }
+
+  // Number of users in the organization
+  async countMembers(id: string | Types.ObjectId): Promise<number> {
+    return await User.countDocuments({ organizationId: id }).exec();
+  }
+
+  // Number of groups in the organization
+  async countGroups(id: string | Types.ObjectId): Promise<number> {
+    return await Chat.countDocuments({ organizationId: id, type: 'group' }).exec();
+  }
+}
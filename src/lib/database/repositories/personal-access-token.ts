@@ -0,0 +1,65 @@
+import { Types } from 'mongoose';
+import { PersonalAccessToken, IPersonalAccessToken } from '../models/personal-access-token';
+
+export class PersonalAccessTokenRepository {
+  // Create token
+  async create(tokenData: Partial<IPersonalAccessToken>): Promise<IPersonalAccessToken> {
+    const token = new PersonalAccessToken(tokenData);
+    return await token.save();
+  }
+
+  // Find token by ID
+  async findById(id: string | Types.ObjectId): Promise<IPersonalAccessToken | null> {
+    return await PersonalAccessToken.findById(id).exec();
+  }
+
+  // Find token by the hash of its secret
+  async findByHash(tokenHash: string): Promise<IPersonalAccessToken | null> {
+    return await PersonalAccessToken.findOne({ tokenHash }).exec();
+  }
+
+  // Get a user's tokens, newest first
+  async findByUser(userId: string | Types.ObjectId, includeRevoked: boolean = false): Promise<IPersonalAccessToken[]> {
+    const query: any = { userId };
+    if (!includeRevoked) query.revokedAt = { $exists: false };
+
+    return await PersonalAccessToken.find(query)
+      .sort({ createdAt: -1 })
+      .exec();
+  }
+
+  // Count tokens that still work
+  async countActive(userId: string | Types.ObjectId): Promise<number> {
+    return await PersonalAccessToken.countDocuments({
+      userId,
+      revokedAt: { $exists: false },
+      expiresAt: { $gt: new Date() },
+    }).exec();
+  }
+
+  // Revoke one of the user's tokens; it stops working immediately
+  async revoke(id: string | Types.ObjectId, userId: string | Types.ObjectId): Promise<boolean> {
+    const result = await PersonalAccessToken.updateOne(
+      { _id: id, userId, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Revoke every token of a user, e.g. when the account is logged out everywhere
+  async revokeAllForUser(userId: string | Types.ObjectId): Promise<number> {
+    const result = await PersonalAccessToken.updateMany(
+      { userId, revokedAt: { $exists: false } },
+      { $set: { revokedAt: new Date() } }
+    ).exec();
+    return result.modifiedCount;
+  }
+
+  // Record a use
+  async recordUse(id: string | Types.ObjectId, ip?: string): Promise<void> {
+    await PersonalAccessToken.updateOne(
+      { _id: id },
+      { $set: { lastUsedAt: new Date(), ...(ip && { lastUsedIp: ip }) } }
+    ).exec();
+  }
+}
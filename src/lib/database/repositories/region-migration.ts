@@ -0,0 +1,52 @@
+import { Types } from 'mongoose';
+import { RegionMigration, IRegionMigration } from '../models/region-migration';
+
+export class RegionMigrationRepository {
+  // Create region migration
+  async create(migrationData: Partial<IRegionMigration>): Promise<IRegionMigration> {
+    const migration = new RegionMigration(migrationData);
+    return await migration.save();
+  }
+
+  // Find region migration by ID
+  async findById(id: string | Types.ObjectId): Promise<IRegionMigration | null> {
+    return await RegionMigration.findById(id).exec();
+  }
+
+  // Queued or running migration of a user or chat
+  async findActive(subjectId: string | Types.ObjectId): Promise<IRegionMigration | null> {
+    return await RegionMigration.findOne({ subjectId, status: { $in: ['queued', 'processing'] } }).exec();
+  }
+
+  // Get a user's or chat's migrations, newest first
+  async getForSubject(subjectId: string | Types.ObjectId, limit: number = 20): Promise<IRegionMigration[]> {
+    return await RegionMigration.find({ subjectId })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Atomically claim the oldest queued migration
+  async claimNext(): Promise<IRegionMigration | null> {
+    return await RegionMigration.findOneAndUpdate(
+      { status: 'queued' },
+      { status: 'processing', startedAt: new Date() },
+      { sort: { createdAt: 1 }, new: true }
+    ).exec();
+  }
+
+  // Requeue migrations whose worker stopped reporting progress. Migrations
+  // pick up where they stopped, so progress is kept.
+  async requeueStalled(staleBefore: Date): Promise<number> {
+    const result = await RegionMigration.updateMany(
+      { status: 'processing', updatedAt: { $lt: staleBefore } },
+      { status: 'queued' }
+    ).exec();
+    return result.modifiedCount;
+  }
+
+  // Update region migration
+  async update(id: string | Types.ObjectId, updateData: Partial<IRegionMigration>): Promise<IRegionMigration | null> {
+    return await RegionMigration.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+}
@@ -0,0 +1,91 @@
+import { Types } from 'mongoose';
+import { Reminder, IReminder, ReminderStatus } from '../models/reminder';
+
+export class ReminderRepository {
+  // Create reminder
+  async create(reminderData: Partial<IReminder>): Promise<IReminder> {
+    const reminder = new Reminder(reminderData);
+    return await reminder.save();
+  }
+
+  // Find reminder by ID
+  async findById(id: string | Types.ObjectId): Promise<IReminder | null> {
+    return await Reminder.findById(id).exec();
+  }
+
+  // Get a user's reminders; upcoming ones soonest first, the rest newest first
+  async getUserReminders(
+    userId: string | Types.ObjectId,
+    statuses: ReminderStatus[],
+    limit: number = 20,
+    offset: number = 0
+  ): Promise<IReminder[]> {
+    const upcoming = statuses.every(status => status === 'pending' || status === 'processing');
+
+    return await Reminder.find({ userId, status: { $in: statuses } })
+      .sort(upcoming ? { dueAt: 1 } : { dueAt: -1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Count a user's reminders that haven't fired yet
+  async countPending(userId: string | Types.ObjectId): Promise<number> {
+    return await Reminder.countDocuments({ userId, status: 'pending' }).exec();
+  }
+
+  // When the next pending reminder is due
+  async findNextDueAt(): Promise<Date | null> {
+    const next = await Reminder.findOne({ status: 'pending' })
+      .sort({ dueAt: 1 })
+      .select('dueAt')
+      .lean<Pick<IReminder, 'dueAt'>>()
+      .exec();
+    return next?.dueAt ?? null;
+  }
+
+  // Atomically claim the most overdue reminder
+  async claimDue(now: Date = new Date()): Promise<IReminder | null> {
+    return await Reminder.findOneAndUpdate(
+      { status: 'pending', dueAt: { $lte: now } },
+      { $set: { status: 'processing', lockedAt: now }, $inc: { attempts: 1 } },
+      { sort: { dueAt: 1 }, new: true }
+    ).exec();
+  }
+
+  // Release reminders whose worker died mid-delivery
+  async requeueStalled(staleBefore: Date): Promise<number> {
+    const result = await Reminder.updateMany(
+      { status: 'processing', lockedAt: { $lt: staleBefore } },
+      { $set: { status: 'pending' }, $unset: { lockedAt: 1 } }
+    ).exec();
+    return result.modifiedCount;
+  }
+
+  // Record a successful delivery
+  async markDelivered(id: string | Types.ObjectId, deliveryMessageId?: Types.ObjectId): Promise<void> {
+    await Reminder.updateOne(
+      { _id: id },
+      { $set: { status: 'delivered', deliveredAt: new Date(), deliveryMessageId }, $unset: { lockedAt: 1 } }
+    ).exec();
+  }
+
+  // Push a reminder back for another attempt, or give up on it
+  async markAttemptFailed(id: string | Types.ObjectId, reason: string, retryAt: Date | null): Promise<void> {
+    await Reminder.updateOne(
+      { _id: id },
+      retryAt
+        ? { $set: { status: 'pending', dueAt: retryAt, failureReason: reason }, $unset: { lockedAt: 1 } }
+        : { $set: { status: 'failed', failureReason: reason }, $unset: { lockedAt: 1 } }
+    ).exec();
+  }
+
+  // Cancel a reminder that hasn't fired yet
+  async cancel(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await Reminder.updateOne(
+      { _id: id, status: 'pending' },
+      { $set: { status: 'cancelled', cancelledAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+}
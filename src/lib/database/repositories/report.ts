@@ -0,0 +1,109 @@
+import { FilterQuery, Types } from 'mongoose';
+import { Report, IReport } from '../models/report';
+import { ListOptions } from '../../utils/pagination';
+
+export class ReportRepository {
+  // Create report
+  async create(reportData: Partial<IReport>): Promise<IReport> {
+    const report = new Report(reportData);
+    return await report.save();
+  }
+
+  // Find report by ID
+  async findById(id: string | Types.ObjectId): Promise<IReport | null> {
+    return await Report.findById(id).exec();
+  }
+
+  // Get reports about a user, newest first
+  async findAboutUser(userId: string | Types.ObjectId, limit: number = 50): Promise<IReport[]> {
+    return await Report.find({ reportedUserId: userId })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // An open report by the same reporter about the same thing
+  async findOpenDuplicate(
+    reporterId: string | Types.ObjectId,
+    target: Pick<IReport, 'reportedUserId' | 'reportedMessageId' | 'reportedChatId'>
+  ): Promise<IReport | null> {
+    return await Report.findOne({
+      reporterId,
+      reportedUserId: target.reportedUserId ?? null,
+      reportedMessageId: target.reportedMessageId ?? null,
+      reportedChatId: target.reportedChatId ?? null,
+      status: { $in: ['pending', 'under_review'] },
+    }).exec();
+  }
+
+  // Add a label, once
+  async addLabel(id: string | Types.ObjectId, label: string): Promise<boolean> {
+    const result = await Report.updateOne({ _id: id }, { $addToSet: { labels: label } }).exec();
+    return result.matchedCount > 0;
+  }
+
+  // Close an open report; null when it is already closed or missing
+  async resolve(
+    id: string | Types.ObjectId,
+    status: 'resolved' | 'dismissed',
+    resolution: string | undefined,
+    adminId: string | Types.ObjectId
+  ): Promise<IReport | null> {
+    return await Report.findOneAndUpdate(
+      { _id: id, status: { $in: ['pending', 'under_review'] } },
+      { status, resolution, resolvedAt: new Date(), assignedTo: adminId },
+      { new: true }
+    ).exec();
+  }
+
+  // Filtered, sorted page of reports (admin)
+  async getForAdmin(filters: AdminReportFilters, options: ListOptions): Promise<{ reports: IReport[]; total: number }> {
+    const query = this.adminQuery(filters);
+    const [reports, total] = await Promise.all([
+      Report.find(query).sort(options.sort).skip(options.offset).limit(options.limit).exec(),
+      Report.countDocuments(query).exec(),
+    ]);
+    return { reports, total };
+  }
+
+  // Every report matching the filters, for exports
+  cursorForAdmin(filters: AdminReportFilters, sort: ListOptions['sort'], limit: number) {
+    return Report.find(this.adminQuery(filters))
+      .sort(sort)
+      .limit(limit)
+      .lean<IReport>()
+      .cursor();
+  }
+
+  private adminQuery(filters: AdminReportFilters): FilterQuery<IReport> {
+    const query: FilterQuery<IReport> = {};
+
+    if (filters.status) query.status = filters.status;
+    if (filters.type) query.type = filters.type;
+    if (filters.priority) query.priority = filters.priority;
+    if (filters.reporterId) query.reporterId = new Types.ObjectId(filters.reporterId);
+    if (filters.reportedUserId) query.reportedUserId = new Types.ObjectId(filters.reportedUserId);
+    if (filters.assignedTo) query.assignedTo = new Types.ObjectId(filters.assignedTo);
+    if (filters.label) query.labels = filters.label;
+    if (filters.from || filters.to) {
+      query.createdAt = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(filters.to && { $lte: filters.to }),
+      };
+    }
+
+    return query;
+  }
+}
+
+export interface AdminReportFilters {
+  status?: IReport['status'];
+  type?: IReport['type'];
+  priority?: IReport['priority'];
+  reporterId?: string;
+  reportedUserId?: string;
+  assignedTo?: string;
+  label?: string;
+  from?: Date; // reported
+  to?: Date;
+}
@@ -0,0 +1,28 @@
+import { RetentionCohort, IRetentionCohort } from '../models/retention-cohort';
+
+export class RetentionCohortRepository {
+  // Create or replace a week's figures
+  async upsert(weekStart: Date, data: Partial<IRetentionCohort>): Promise<void> {
+    await RetentionCohort.updateOne(
+      { weekStart },
+      { $set: { ...data, computedAt: new Date() } },
+      { upsert: true }
+    ).exec();
+  }
+
+  // Get cohorts from a week on, oldest first
+  async findSince(weekStart: Date): Promise<IRetentionCohort[]> {
+    return await RetentionCohort.find({ weekStart: { $gte: weekStart } })
+      .sort({ weekStart: 1 })
+      .exec();
+  }
+
+  // Weeks whose figures are final
+  async findCompleteWeekStarts(since: Date): Promise<Date[]> {
+    const cohorts = await RetentionCohort.find({ weekStart: { $gte: since }, complete: true })
+      .select('weekStart')
+      .lean<{ weekStart: Date }[]>()
+      .exec();
+    return cohorts.map(cohort => cohort.weekStart);
+  }
+}
@@ -0,0 +1,122 @@
+import { Types } from 'mongoose';
+import { ScheduledCall, IScheduledCall } from '../models/scheduled-call';
+
+export class ScheduledCallRepository {
+  // Create scheduled call
+  async create(scheduledCallData: Partial<IScheduledCall>): Promise<IScheduledCall> {
+    const scheduledCall = new ScheduledCall(scheduledCallData);
+    return await scheduledCall.save();
+  }
+
+  // Find scheduled call by ID
+  async findById(id: string | Types.ObjectId): Promise<IScheduledCall | null> {
+    return await ScheduledCall.findById(id).exec();
+  }
+
+  // Find scheduled call by its join link code
+  async findByJoinCode(joinCode: string): Promise<IScheduledCall | null> {
+    return await ScheduledCall.findOne({ joinCode }).exec();
+  }
+
+  // Calls in a chat that haven't finished yet, soonest first
+  async getUpcomingForChat(
+    chatId: string | Types.ObjectId,
+    limit: number = 20,
+    offset: number = 0,
+    now: Date = new Date()
+  ): Promise<IScheduledCall[]> {
+    return await ScheduledCall.find({
+      chatId,
+      status: { $in: ['scheduled', 'started'] },
+      endsAt: { $gt: now },
+    })
+      .sort({ startsAt: 1 })
+      .limit(limit)
+      .skip(offset)
+      .exec();
+  }
+
+  // Count a chat's calls that haven't started yet
+  async countScheduled(chatId: string | Types.ObjectId): Promise<number> {
+    return await ScheduledCall.countDocuments({ chatId, status: 'scheduled' }).exec();
+  }
+
+  // Update a call that hasn't started yet
+  async updateScheduled(id: string | Types.ObjectId, updateData: Partial<IScheduledCall>): Promise<IScheduledCall | null> {
+    return await ScheduledCall.findOneAndUpdate(
+      { _id: id, status: 'scheduled' },
+      { $set: updateData },
+      { new: true }
+    ).exec();
+  }
+
+  // When the worker next has something to do: a reminder to send or a call
+  // to start
+  async findNextDueAt(now: Date = new Date()): Promise<Date | null> {
+    const [reminder, start] = await Promise.all([
+      ScheduledCall.findOne({ status: 'scheduled', remindAt: { $ne: null }, reminderSentAt: null, startsAt: { $gt: now } })
+        .sort({ remindAt: 1 })
+        .select('remindAt')
+        .lean<Pick<IScheduledCall, 'remindAt'>>()
+        .exec(),
+      ScheduledCall.findOne({ status: 'scheduled' })
+        .sort({ startsAt: 1 })
+        .select('startsAt')
+        .lean<Pick<IScheduledCall, 'startsAt'>>()
+        .exec(),
+    ]);
+
+    const times = [reminder?.remindAt, start?.startsAt].filter((time): time is Date => !!time);
+    return times.length > 0 ? new Date(Math.min(...times.map(time => time.getTime()))) : null;
+  }
+
+  // Atomically claim the next call whose reminder is due
+  async claimDueReminder(now: Date = new Date()): Promise<IScheduledCall | null> {
+    return await ScheduledCall.findOneAndUpdate(
+      { status: 'scheduled', remindAt: { $lte: now }, reminderSentAt: null, startsAt: { $gt: now } },
+      { $set: { reminderSentAt: now } },
+      { sort: { remindAt: 1 }, new: true }
+    ).exec();
+  }
+
+  // Atomically mark a call started, either the next due one or a given one
+  // started early from its link
+  async claimStart(callId: string, id?: string | Types.ObjectId, now: Date = new Date()): Promise<IScheduledCall | null> {
+    const filter = id
+      ? { _id: id, status: 'scheduled' }
+      : { status: 'scheduled', startsAt: { $lte: now }, endsAt: { $gt: now } };
+
+    return await ScheduledCall.findOneAndUpdate(
+      filter,
+      { $set: { status: 'started', callId, startedAt: now } },
+      { sort: { startsAt: 1 }, new: true }
+    ).exec();
+  }
+
+  // Put a call back when starting it failed
+  async releaseStart(id: string | Types.ObjectId): Promise<void> {
+    await ScheduledCall.updateOne(
+      { _id: id, status: 'started' },
+      { $set: { status: 'scheduled' }, $unset: { callId: 1, startedAt: 1 } }
+    ).exec();
+  }
+
+  // Give up on calls whose whole slot passed without starting, e.g. while
+  // the server was down
+  async expireMissed(now: Date = new Date()): Promise<number> {
+    const result = await ScheduledCall.updateMany(
+      { status: 'scheduled', endsAt: { $lte: now } },
+      { $set: { status: 'expired' } }
+    ).exec();
+    return result.modifiedCount;
+  }
+
+  // Cancel a call that hasn't started yet
+  async cancel(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await ScheduledCall.updateOne(
+      { _id: id, status: 'scheduled' },
+      { $set: { status: 'cancelled', cancelledAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+}
@@ -0,0 +1,111 @@
+import { FilterQuery, Types } from 'mongoose';
+import { SecurityEvent, ISecurityEvent, SecurityEventType } from '../models/security-event';
+import { ListOptions } from '../../utils/pagination';
+
+export class SecurityEventRepository {
+  // Record security event
+  async create(eventData: Partial<ISecurityEvent>): Promise<ISecurityEvent> {
+    const event = new SecurityEvent(eventData);
+    return await event.save();
+  }
+
+  // Get a user's security log, newest first
+  async findByUser(
+    userId: string | Types.ObjectId,
+    limit: number = 50,
+    offset: number = 0,
+    types?: SecurityEventType[]
+  ): Promise<ISecurityEvent[]> {
+    return await SecurityEvent.find({ userId, ...(types && { type: { $in: types } }) })
+      .sort({ createdAt: -1 })
+      .skip(offset)
+      .limit(limit)
+      .exec();
+  }
+
+  // Whether the user has signed in successfully before, at all or from
+  // this device
+  async hasSucceededLogin(userId: string | Types.ObjectId, deviceId?: string): Promise<boolean> {
+    const event = await SecurityEvent.exists({
+      userId,
+      type: { $in: ['login_succeeded', 'login_approved'] },
+      ...(deviceId && { deviceId }),
+    }).exec();
+    return !!event;
+  }
+
+  // Countries the user has signed in from
+  async getLoginCountries(userId: string | Types.ObjectId): Promise<string[]> {
+    return await SecurityEvent.distinct('country', {
+      userId,
+      type: { $in: ['login_succeeded', 'login_approved'] },
+      country: { $exists: true },
+    }).exec();
+  }
+
+  // Filtered, sorted page of security events across users (admin)
+  async getForAdmin(
+    filters: AdminSecurityEventFilters,
+    options: ListOptions
+  ): Promise<{ events: ISecurityEvent[]; total: number }> {
+    const query = this.adminQuery(filters);
+    const [events, total] = await Promise.all([
+      SecurityEvent.find(query).sort(options.sort).skip(options.offset).limit(options.limit).exec(),
+      SecurityEvent.countDocuments(query).exec(),
+    ]);
+    return { events, total };
+  }
+
+  // Every security event matching the filters, for exports
+  cursorForAdmin(filters: AdminSecurityEventFilters, sort: ListOptions['sort'], limit: number) {
+    return SecurityEvent.find(this.adminQuery(filters))
+      .sort(sort)
+      .limit(limit)
+      .lean<ISecurityEvent>()
+      .cursor();
+  }
+
+  // Count events per country and type since a date
+  async countByCountry(since: Date): Promise<{ country: string | null; type: SecurityEventType; count: number; users: number }[]> {
+    return await SecurityEvent.aggregate([
+      { $match: { createdAt: { $gte: since } } },
+      {
+        $group: {
+          _id: { country: { $ifNull: ['$country', null] }, type: '$type' },
+          count: { $sum: 1 },
+          users: { $addToSet: '$userId' },
+        },
+      },
+      { $project: { _id: 0, country: '$_id.country', type: '$_id.type', count: 1, users: { $size: '$users' } } },
+      { $sort: { count: -1 } },
+    ]).exec();
+  }
+
+  private adminQuery(filters: AdminSecurityEventFilters): FilterQuery<ISecurityEvent> {
+    const query: FilterQuery<ISecurityEvent> = {};
+
+    if (filters.type) query.type = filters.type;
+    if (filters.userId) query.userId = new Types.ObjectId(filters.userId);
+    if (filters.country) query.country = filters.country;
+    if (filters.ip) query.ip = filters.ip;
+    if (filters.asn) query.asn = filters.asn;
+    if (filters.from || filters.to) {
+      query.createdAt = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(filters.to && { $lte: filters.to }),
+      };
+    }
+
+    return query;
+  }
+}
+
+export interface AdminSecurityEventFilters {
+  type?: SecurityEventType;
+  userId?: string;
+  country?: string;
+  ip?: string;
+  asn?: number;
+  from?: Date;
+  to?: Date;
+}
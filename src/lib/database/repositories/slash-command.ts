@@ -0,0 +1,46 @@
+import { Types } from 'mongoose';
+import { SlashCommand, ISlashCommand } from '../models/slash-command';
+
+export class SlashCommandRepository {
+  // Create command
+  async create(commandData: Partial<ISlashCommand>): Promise<ISlashCommand> {
+    const command = new SlashCommand(commandData);
+    return await command.save();
+  }
+
+  // Find command by ID
+  async findById(id: string | Types.ObjectId): Promise<ISlashCommand | null> {
+    return await SlashCommand.findById(id).exec();
+  }
+
+  // Find a chat's command by name
+  async findByName(chatId: string | Types.ObjectId, name: string): Promise<ISlashCommand | null> {
+    return await SlashCommand.findOne({ chatId, name: name.toLowerCase() }).exec();
+  }
+
+  // Get a chat's custom commands, alphabetically
+  async findByChat(chatId: string | Types.ObjectId): Promise<ISlashCommand[]> {
+    return await SlashCommand.find({ chatId })
+      .sort({ name: 1 })
+      .exec();
+  }
+
+  // Update command
+  async update(id: string | Types.ObjectId, updateData: Partial<ISlashCommand>): Promise<ISlashCommand | null> {
+    return await SlashCommand.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Delete command
+  async delete(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await SlashCommand.deleteOne({ _id: id }).exec();
+    return result.deletedCount > 0;
+  }
+
+  // Count an invocation
+  async recordUse(id: string | Types.ObjectId): Promise<void> {
+    await SlashCommand.updateOne(
+      { _id: id },
+      { $inc: { invocationCount: 1 }, $set: { lastUsedAt: new Date() } }
+    ).exec();
+  }
+}
@@ -0,0 +1,71 @@
+import { SmsMessage, ISmsMessage } from '../models/sms-message';
+
+export type SmsStatsDimension = 'day' | 'country' | 'carrier';
+
+export interface SmsStatsRow {
+  day?: string; // YYYY-MM-DD, UTC
+  country?: string | null;
+  carrier?: string | null;
+  sent: number;
+  delivered: number;
+  failed: number; // failed or undelivered
+  pending: number; // no final status yet
+  segments: number;
+  cost: number;
+  priceUnit: string | null;
+}
+
+export class SmsMessageRepository {
+  // Record an outbound SMS
+  async create(messageData: Partial<ISmsMessage>): Promise<ISmsMessage> {
+    const message = new SmsMessage(messageData);
+    return await message.save();
+  }
+
+  // Update by the provider's message ID
+  async updateByProviderId(providerMessageId: string, updateData: Partial<ISmsMessage>): Promise<ISmsMessage | null> {
+    return await SmsMessage.findOneAndUpdate(
+      { providerMessageId },
+      { $set: updateData },
+      { new: true }
+    ).exec();
+  }
+
+  // Carrier details looked up for the same number recently
+  async findRecentCarrier(phoneHash: string, since: Date): Promise<Pick<ISmsMessage, 'carrier' | 'lineType'> | null> {
+    return await SmsMessage.findOne({ phoneHash, sentAt: { $gte: since }, carrier: { $exists: true } })
+      .sort({ sentAt: -1 })
+      .select('carrier lineType')
+      .lean<Pick<ISmsMessage, 'carrier' | 'lineType'>>()
+      .exec();
+  }
+
+  // Volume, outcomes and cost in a time range, grouped by the given dimensions
+  async getStats(from: Date, to: Date, dimensions: SmsStatsDimension[]): Promise<SmsStatsRow[]> {
+    const groupId: Record<string, any> = {};
+    if (dimensions.includes('day')) {
+      groupId.day = { $dateToString: { date: '$sentAt', format: '%Y-%m-%d', timezone: 'UTC' } };
+    }
+    if (dimensions.includes('country')) groupId.country = { $ifNull: ['$countryCode', null] };
+    if (dimensions.includes('carrier')) groupId.carrier = { $ifNull: ['$carrier', null] };
+
+    const rows = await SmsMessage.aggregate([
+      { $match: { sentAt: { $gte: from, $lt: to } } },
+      {
+        $group: {
+          _id: groupId,
+          sent: { $sum: 1 },
+          delivered: { $sum: { $cond: [{ $eq: ['$status', 'delivered'] }, 1, 0] } },
+          failed: { $sum: { $cond: [{ $in: ['$status', ['failed', 'undelivered']] }, 1, 0] } },
+          pending: { $sum: { $cond: [{ $in: ['$status', ['queued', 'sent']] }, 1, 0] } },
+          segments: { $sum: { $ifNull: ['$segments', 1] } },
+          cost: { $sum: { $ifNull: ['$price', 0] } },
+          priceUnit: { $max: '$priceUnit' },
+        },
+      },
+      { $sort: { '_id.day': 1, sent: -1 } },
+    ]).exec();
+
+    return rows.map(({ _id, ...stats }: any) => ({ ..._id, ...stats, priceUnit: stats.priceUnit ?? null }));
+  }
+}
@@ -0,0 +1,93 @@
+import { Types } from 'mongoose';
+import { StorageObject, IStorageObject } from '../models/storage-object';
+
+export class StorageObjectRepository {
+  // Create object
+  async create(objectData: Partial<IStorageObject>): Promise<IStorageObject> {
+    const object = new StorageObject(objectData);
+    return await object.save();
+  }
+
+  // Find object by key
+  async findByKey(key: string): Promise<IStorageObject | null> {
+    return await StorageObject.findOne({ key }).exec();
+  }
+
+  // Add references; null when the object isn't tracked yet
+  async increment(key: string, count: number = 1): Promise<IStorageObject | null> {
+    return await StorageObject.findOneAndUpdate(
+      { key },
+      { $inc: { refCount: count } },
+      { new: true }
+    ).exec();
+  }
+
+  // Record thumbnails made after the object was first tracked
+  async addThumbnailKeys(key: string, thumbnailKeys: string[]): Promise<void> {
+    await StorageObject.updateOne({ key }, { $addToSet: { thumbnailKeys: { $each: thumbnailKeys } } }).exec();
+  }
+
+  // Drop a reference; null when the object isn't tracked
+  async decrement(key: string): Promise<IStorageObject | null> {
+    return await StorageObject.findOneAndUpdate(
+      { key, refCount: { $gt: 0 } },
+      { $inc: { refCount: -1 } },
+      { new: true }
+    ).exec();
+  }
+
+  // Delete the object only if nothing references it any more
+  async deleteIfUnreferenced(id: string | Types.ObjectId): Promise<boolean> {
+    const result = await StorageObject.deleteOne({ _id: id, refCount: { $lte: 0 } }).exec();
+    return result.deletedCount > 0;
+  }
+
+  // Objects due for a consistency check, least recently checked first
+  async findUnverifiedSince(before: Date, limit: number = 100): Promise<IStorageObject[]> {
+    return await StorageObject.find({
+      $or: [
+        { verifiedAt: { $exists: false } },
+        { verifiedAt: { $lt: before } },
+      ],
+    })
+      .sort({ verifiedAt: 1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Record the outcome of a consistency check. Only applies if the count
+  // hasn't moved since it was read, so concurrent uploads aren't lost.
+  async markVerified(
+    id: string | Types.ObjectId,
+    expectedRefCount: number,
+    refCount: number
+  ): Promise<boolean> {
+    const result = await StorageObject.updateOne(
+      { _id: id, refCount: expectedRefCount },
+      { $set: { refCount, verifiedAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Get reference statistics
+  async getStats(): Promise<{ objects: number; references: number; storedBytes: number; savedBytes: number }> {
+    const [stats] = await StorageObject.aggregate([
+      {
+        $group: {
+          _id: null,
+          objects: { $sum: 1 },
+          references: { $sum: '$refCount' },
+          storedBytes: { $sum: '$size' },
+          savedBytes: { $sum: { $multiply: ['$size', { $max: [{ $subtract: ['$refCount', 1] }, 0] }] } },
+        },
+      },
+    ]).exec();
+
+    return {
+      objects: stats?.objects || 0,
+      references: stats?.references || 0,
+      storedBytes: stats?.storedBytes || 0,
+      savedBytes: stats?.savedBytes || 0,
+    };
+  }
+}
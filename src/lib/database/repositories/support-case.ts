@@ -0,0 +1,58 @@
+import { Types, UpdateQuery } from 'mongoose';
+import { SupportCase, ISupportCase } from '../models/support-case';
+
+export class SupportCaseRepository {
+  // Create support case
+  async create(caseData: Partial<ISupportCase>): Promise<ISupportCase> {
+    const supportCase = new SupportCase(caseData);
+    return await supportCase.save();
+  }
+
+  // Find support case by ID
+  async findById(id: string | Types.ObjectId): Promise<ISupportCase | null> {
+    return await SupportCase.findById(id).exec();
+  }
+
+  // Get a user's cases, newest first
+  async findByUser(userId: string | Types.ObjectId, limit: number = 50): Promise<ISupportCase[]> {
+    return await SupportCase.find({ userId })
+      .sort({ createdAt: -1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Update support case
+  async update(id: string | Types.ObjectId, updateData: UpdateQuery<ISupportCase>): Promise<ISupportCase | null> {
+    return await SupportCase.findByIdAndUpdate(id, updateData, { new: true }).exec();
+  }
+
+  // Link reports and moderation actions to a case
+  async link(
+    id: string | Types.ObjectId,
+    reportIds: Types.ObjectId[],
+    moderationActionIds: Types.ObjectId[]
+  ): Promise<ISupportCase | null> {
+    return await SupportCase.findByIdAndUpdate(
+      id,
+      {
+        $addToSet: {
+          reportIds: { $each: reportIds },
+          moderationActionIds: { $each: moderationActionIds },
+        },
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Record a communication with the user on a case
+  async addCommunication(
+    id: string | Types.ObjectId,
+    communication: Omit<ISupportCase['communications'][number], '_id'>
+  ): Promise<ISupportCase | null> {
+    return await SupportCase.findByIdAndUpdate(
+      id,
+      { $push: { communications: communication } },
+      { new: true }
+    ).exec();
+  }
+}
@@ -0,0 +1,123 @@
+import { Types } from 'mongoose';
+import { UserActivity } from '../models/user-activity';
+import { User } from '../models/user';
+
+export class UserActivityRepository {
+  // Mark the user active on a day
+  async recordActivity(userId: string | Types.ObjectId, day: Date, at: Date = new Date()): Promise<void> {
+    await UserActivity.updateOne(
+      { userId, day },
+      { $setOnInsert: { firstSeenAt: at }, $set: { lastSeenAt: at } },
+      { upsert: true }
+    ).exec();
+  }
+
+  // For users who signed up in a range: their UTC signup day and which of
+  // the given day offsets after it they were active on
+  async getSignupActivity(
+    from: Date,
+    to: Date,
+    offsets: number[]
+  ): Promise<{ signupDay: Date; activeOffsets: number[] }[]> {
+    return await User.aggregate([
+      {
+        $match: {
+          createdAt: { $gte: from, $lt: to },
+          'bridge.protocol': { $exists: false },
+          mergedInto: { $exists: false },
+        },
+      },
+      { $project: { signupDay: { $dateTrunc: { date: '$createdAt', unit: 'day', timezone: 'UTC' } } } },
+      {
+        $lookup: {
+          from: UserActivity.collection.name,
+          let: { userId: '$_id', signupDay: '$signupDay' },
+          pipeline: [
+            {
+              $match: {
+                $expr: {
+                  $and: [
+                    { $eq: ['$userId', '$$userId'] },
+                    {
+                      $in: ['$day', offsets.map(offset => ({
+                        $dateAdd: { startDate: '$$signupDay', unit: 'day', amount: offset },
+                      }))],
+                    },
+                  ],
+                },
+              },
+            },
+            { $project: { _id: 0, offset: { $dateDiff: { startDate: '$$signupDay', endDate: '$day', unit: 'day' } } } },
+          ],
+          as: 'activity',
+        },
+      },
+      { $project: { _id: 0, signupDay: 1, activeOffsets: '$activity.offset' } },
+    ]).exec();
+  }
+
+  // Distinct users active in a range of days
+  async countActiveUsers(from: Date, to: Date): Promise<number> {
+    const [result] = await UserActivity.aggregate([
+      { $match: { day: { $gte: from, $lt: to } } },
+      { $group: { _id: '$userId' } },
+      { $count: 'users' },
+    ]).exec();
+    return result?.users ?? 0;
+  }
+
+  // Active users per day
+  async getDailyActiveUsers(from: Date, to: Date): Promise<{ day: Date; users: number }[]> {
+    return await UserActivity.aggregate([
+      { $match: { day: { $gte: from, $lt: to } } },
+      { $group: { _id: '$day', users: { $sum: 1 } } },
+      { $project: { _id: 0, day: '$_id', users: 1 } },
+      { $sort: { day: 1 } },
+    ]).exec();
+  }
+
+  // Users last active in [activeFrom, activeBefore) and not since, most
+  // recently active first
+  async getChurnedUsers(
+    activeFrom: Date,
+    activeBefore: Date,
+    limit: number,
+    offset: number
+  ): Promise<{ total: number; users: { userId: Types.ObjectId; lastActiveDay: Date; activeDays: number; user?: any }[] }> {
+    const [result] = await UserActivity.aggregate([
+      { $match: { day: { $gte: activeFrom } } },
+      { $group: { _id: '$userId', lastActiveDay: { $max: '$day' }, activeDays: { $sum: 1 } } },
+      { $match: { lastActiveDay: { $lt: activeBefore } } },
+      { $sort: { lastActiveDay: -1, _id: 1 } },
+      {
+        $facet: {
+          total: [{ $count: 'count' }],
+          users: [
+            { $skip: offset },
+            { $limit: limit },
+            {
+              $lookup: {
+                from: User.collection.name,
+                localField: '_id',
+                foreignField: '_id',
+                pipeline: [{ $project: { displayName: 1, phoneNumber: 1, createdAt: 1, isBanned: 1 } }],
+                as: 'user',
+              },
+            },
+            {
+              $project: {
+                _id: 0,
+                userId: '$_id',
+                lastActiveDay: 1,
+                activeDays: 1,
+                user: { $first: '$user' },
+              },
+            },
+          ],
+        },
+      },
+    ]).exec();
+
+    return { total: result?.total[0]?.count ?? 0, users: result?.users ?? [] };
+  }
+}
@@ -1,5 +1,7 @@
-import { Types } from 'mongoose';
-import { User, IUser } from '../models/user';
+import { FilterQuery, Types } from 'mongoose';
+import { createHash, randomUUID } from 'crypto';
+import { User, IUser, DataRegion } from '../models/user';
+import { ListOptions } from '../../utils/pagination';
 
 export class UserRepository {
   // Create user
@@ -13,11 +15,181 @@ export class UserRepository {
     return await User.findById(id).exec();
   }
 
+  // Which of the given IDs belong to existing users
+  async existingIds(userIds: (string | Types.ObjectId)[]): Promise<Types.ObjectId[]> {
+    if (userIds.length === 0) return [];
+    return await User.distinct('_id', { _id: { $in: userIds } }).exec();
+  }
+
+  // Organization of each of the given users; missing for the shared pool
+  async getOrganizationIds(userIds: (string | Types.ObjectId)[]): Promise<Map<string, string | undefined>> {
+    const users = await User.find({ _id: { $in: userIds } }).select('organizationId').exec();
+    return new Map(users.map((user: IUser) => [user._id.toString(), user.organizationId?.toString()]));
+  }
+
+  // Move users into an organization, or with null back to the shared pool
+  async setOrganization(userIds: (string | Types.ObjectId)[], organizationId: Types.ObjectId | null): Promise<number> {
+    const result = await User.updateMany(
+      { _id: { $in: userIds } },
+      organizationId ? { $set: { organizationId } } : { $unset: { organizationId: 1 } }
+    ).exec();
+    return result.modifiedCount;
+  }
+
+  // Get preferred locales for a set of users
+  async getLanguages(userIds: (string | Types.ObjectId)[]): Promise<Map<string, string>> {
+    const users = await User.find({ _id: { $in: userIds } }).select('language').exec();
+    return new Map(users.map((user: IUser) => [user._id.toString(), user.language]));
+  }
+
+  // Get display names and avatars for a set of users
+  async getProfiles(userIds: (string | Types.ObjectId)[]): Promise<Map<string, { displayName: string; avatar?: string }>> {
+    const users = await User.find({ _id: { $in: userIds } }).select('displayName avatar').exec();
+    return new Map(users.map((user: IUser) => [user._id.toString(), { displayName: user.displayName, avatar: user.avatar }]));
+  }
+
+  // Users among the given who have a device to push to; puppet accounts never do
+  async findPushRecipients(userIds: (string | Types.ObjectId)[]): Promise<IUser[]> {
+    if (userIds.length === 0) return [];
+    return await User.find({
+      _id: { $in: userIds },
+      'devices.pushToken': { $exists: true, $ne: null },
+      'bridge.protocol': { $exists: false },
+    }).exec();
+  }
+
   // Find user by phone number
   async findByPhoneNumber(phoneNumber: string): Promise<IUser | null> {
     return await User.findOne({ phoneNumber }).exec();
   }
 
+  // Find or create the puppet account for a federated remote user. Puppets
+  // can't log in: their placeholder phone number never receives an OTP.
+  async findOrCreateBridgeUser(
+    protocol: NonNullable<IUser['bridge']>['protocol'],
+    remoteId: string,
+    displayName: string
+  ): Promise<IUser> {
+    const existing = await User.findOne({ 'bridge.protocol': protocol, 'bridge.remoteId': remoteId }).exec();
+    if (existing) {
+      if (displayName && existing.displayName !== displayName) {
+        existing.displayName = displayName;
+        await existing.save();
+      }
+      return existing;
+    }
+
+    const hash = createHash('sha256').update(`${protocol}:${remoteId}`).digest('hex').slice(0, 24);
+    return await User.findOneAndUpdate(
+      { 'bridge.protocol': protocol, 'bridge.remoteId': remoteId },
+      {
+        $setOnInsert: {
+          phoneNumber: `bridge:${protocol}:${hash}`,
+          displayName: displayName || remoteId,
+          status: '',
+          bridge: { protocol, remoteId },
+        },
+      },
+      { upsert: true, new: true }
+    ).exec();
+  }
+
+  // Create a guest account. Guests can't log in with a phone number: their
+  // placeholder never receives an OTP.
+  async createGuest(data: {
+    displayName: string;
+    organizationId?: Types.ObjectId;
+    guest: NonNullable<IUser['guest']>;
+  }): Promise<IUser> {
+    const user = new User({
+      phoneNumber: `guest:${randomUUID()}`,
+      displayName: data.displayName,
+      status: '',
+      isVerified: false,
+      organizationId: data.organizationId,
+      guest: data.guest,
+    });
+    return await user.save();
+  }
+
+  // Guests whose time is up but whose access hasn't been ended yet
+  async findExpiredGuests(now: Date, limit: number): Promise<IUser[]> {
+    return await User.find({
+      'guest.expiresAt': { $lte: now },
+      'guest.endedAt': { $exists: false },
+    }).limit(limit).exec();
+  }
+
+  // Guests who joined through a link and are still in the chat
+  async findActiveGuestsByLink(linkId: string | Types.ObjectId): Promise<IUser[]> {
+    return await User.find({
+      'guest.linkId': linkId,
+      'guest.endedAt': { $exists: false },
+    }).exec();
+  }
+
+  // End a guest's access; null when it had already ended
+  async endGuest(userId: string | Types.ObjectId): Promise<IUser | null> {
+    return await User.findOneAndUpdate(
+      { _id: userId, guest: { $exists: true }, 'guest.endedAt': { $exists: false } },
+      { $set: { 'guest.endedAt': new Date(), isOnline: false } },
+      { new: true }
+    ).exec();
+  }
+
+  // Find an account provisioned over SCIM by its identity provider username
+  async findByScimUserName(userName: string): Promise<IUser | null> {
+    return await User.findOne({ 'scim.userName': userName }).exec();
+  }
+
+  // Accounts provisioned over SCIM in an organization (or the shared pool),
+  // oldest first, with the total matching
+  async findScimUsers(
+    filter: { organizationId?: string; userName?: string; externalId?: string },
+    limit: number,
+    offset: number
+  ): Promise<{ users: IUser[]; total: number }> {
+    const query: FilterQuery<IUser> = {
+      'scim.provisionedAt': { $exists: true },
+      organizationId: filter.organizationId ?? { $exists: false },
+    };
+    if (filter.userName !== undefined) query['scim.userName'] = filter.userName;
+    if (filter.externalId !== undefined) query['scim.externalId'] = filter.externalId;
+
+    const [users, total] = await Promise.all([
+      User.find(query).sort({ createdAt: 1 }).skip(offset).limit(limit).exec(),
+      User.countDocuments(query).exec(),
+    ]);
+    return { users, total };
+  }
+
+  // Deprovision a SCIM account: banned with the given reason until the
+  // identity provider reactivates it
+  async deactivateScimUser(userId: string | Types.ObjectId, reason: string): Promise<IUser | null> {
+    return await User.findOneAndUpdate(
+      { _id: userId, 'scim.provisionedAt': { $exists: true } },
+      {
+        $set: { isBanned: true, banReason: reason, 'scim.deactivatedAt': new Date() },
+        $unset: { banExpiresAt: 1 },
+      },
+      { new: true }
+    ).exec();
+  }
+
+  // Reactivate a SCIM account. Only lifts the ban deprovisioning applied;
+  // a moderation ban placed since stays.
+  async reactivateScimUser(userId: string | Types.ObjectId, reason: string): Promise<IUser | null> {
+    await User.updateOne(
+      { _id: userId, isBanned: true, banReason: reason },
+      { $set: { isBanned: false }, $unset: { banReason: 1, banExpiresAt: 1 } }
+    ).exec();
+    return await User.findByIdAndUpdate(
+      userId,
+      { $unset: { 'scim.deactivatedAt': 1 } },
+      { new: true }
+    ).exec();
+  }
+
   // Find user by email
   async findByEmail(email: string): Promise<IUser | null> {
     return await User.findOne({ email }).exec();
@@ -28,6 +200,20 @@ export class UserRepository {
     return await User.findOne({ username }).exec();
   }
 
+  // Find user by the code in their add-contact link
+  async findByContactCode(contactCode: string): Promise<IUser | null> {
+    return await User.findOne({ contactCode, isBanned: false }).exec();
+  }
+
+  // Set the user's contact code; with expected, only if it is still that one
+  async setContactCode(id: string | Types.ObjectId, contactCode: string, expected?: string | null): Promise<IUser | null> {
+    const filter: FilterQuery<IUser> = { _id: id };
+    if (expected !== undefined) {
+      filter.contactCode = expected ?? { $exists: false };
+    }
+    return await User.findOneAndUpdate(filter, { $set: { contactCode } }, { new: true }).exec();
+  }
+
   // Update user
   async update(id: string | Types.ObjectId, updateData: Partial<IUser>): Promise<IUser | null> {
     return await User.findByIdAndUpdate(id, updateData, { new: true }).exec();
@@ -51,7 +237,8 @@ export class UserRepository {
         { username: { $regex: searchRegex } },
         { phoneNumber: { $regex: searchRegex } }
       ],
-      isBanned: false
+      isBanned: false,
+      restriction: { $exists: false }
     })
     .limit(limit)
     .skip(offset)
@@ -113,6 +300,341 @@ export class UserRepository {
     }).exec();
   }
 
+  // Register or refresh a device's push tokens. A token can only belong to
+  // one device, so it is taken off any other device that still holds it.
+  async upsertDevice(
+    userId: string | Types.ObjectId,
+    device: Pick<IUser['devices'][number], 'deviceId' | 'platform' | 'pushToken' | 'voipToken' | 'apnsEnvironment'>
+  ): Promise<IUser | null> {
+    const tokens = [device.pushToken, device.voipToken].filter((token): token is string => !!token);
+    if (tokens.length > 0) {
+      await User.updateMany(
+        { 'devices.pushToken': { $in: tokens } },
+        { $unset: { 'devices.$[device].pushToken': 1 } },
+        { arrayFilters: [{ 'device.pushToken': { $in: tokens }, 'device.deviceId': { $ne: device.deviceId } }] }
+      ).exec();
+      await User.updateMany(
+        { 'devices.voipToken': { $in: tokens } },
+        { $unset: { 'devices.$[device].voipToken': 1 } },
+        { arrayFilters: [{ 'device.voipToken': { $in: tokens }, 'device.deviceId': { $ne: device.deviceId } }] }
+      ).exec();
+    }
+
+    // Tokens left out are cleared; the app version and other device details
+    // are kept
+    const entry = { ...device, lastActive: new Date() };
+    const tokenFields = ['pushToken', 'voipToken', 'apnsEnvironment'] as const;
+    const updated = await User.findOneAndUpdate(
+      { _id: userId, 'devices.deviceId': device.deviceId },
+      {
+        $set: Object.fromEntries(
+          Object.entries(entry).filter(([, value]) => value !== undefined).map(([key, value]) => [`devices.$.${key}`, value])
+        ),
+        $unset: Object.fromEntries(
+          tokenFields.filter(field => !device[field]).map(field => [`devices.$.${field}`, 1])
+        ),
+      },
+      { new: true }
+    ).exec();
+
+    return updated || await User.findByIdAndUpdate(
+      userId,
+      { $push: { devices: entry } },
+      { new: true }
+    ).exec();
+  }
+
+  // Record the app version a device signed in with
+  async recordClientInfo(
+    userId: string | Types.ObjectId,
+    device: Pick<IUser['devices'][number], 'deviceId' | 'platform' | 'appVersion' | 'osVersion' | 'deviceModel'>
+  ): Promise<void> {
+    const fields = { ...device, lastActive: new Date() };
+    const updated = await User.updateOne(
+      { _id: userId, 'devices.deviceId': device.deviceId },
+      {
+        $set: Object.fromEntries(
+          Object.entries(fields).filter(([, value]) => value !== undefined).map(([key, value]) => [`devices.$.${key}`, value])
+        ),
+      }
+    ).exec();
+
+    if (updated.matchedCount === 0) {
+      await User.updateOne({ _id: userId }, { $push: { devices: fields } }).exec();
+    }
+  }
+
+  // Add a device linked by QR code, replacing any earlier entry with its ID
+  async linkDevice(
+    userId: string | Types.ObjectId,
+    device: Pick<IUser['devices'][number], 'deviceId' | 'platform' | 'deviceModel' | 'linkedBy' | 'linkedAt'>,
+    ip?: string
+  ): Promise<void> {
+    await User.updateOne({ _id: userId }, { $pull: { devices: { deviceId: device.deviceId } } }).exec();
+    await User.updateOne(
+      { _id: userId },
+      {
+        $push: {
+          devices: {
+            ...device,
+            lastActive: new Date(),
+            ...(ip && { location: { ip, at: new Date() } }),
+          },
+        },
+      }
+    ).exec();
+  }
+
+  // Remove a device, returning it if it was there
+  async removeDevice(userId: string | Types.ObjectId, deviceId: string): Promise<IUser['devices'][number] | null> {
+    const user = await User.findOneAndUpdate(
+      { _id: userId, 'devices.deviceId': deviceId },
+      { $pull: { devices: { deviceId } } }
+    ).select('devices').exec();
+    return user?.devices.find(device => device.deviceId === deviceId) || null;
+  }
+
+  // Turn low-data mode on or off for one device; false when the user has no
+  // such device
+  async setDeviceLowDataMode(userId: string | Types.ObjectId, deviceId: string, enabled: boolean): Promise<boolean> {
+    const result = await User.updateOne(
+      { _id: userId, 'devices.deviceId': deviceId },
+      { $set: { 'devices.$.lowDataMode': enabled } }
+    ).exec();
+    return result.matchedCount > 0;
+  }
+
+  // Record where a device signed in from
+  async recordDeviceLocation(
+    userId: string | Types.ObjectId,
+    deviceId: string,
+    location: Omit<NonNullable<IUser['devices'][number]['location']>, 'at'>
+  ): Promise<void> {
+    await User.updateOne(
+      { _id: userId, 'devices.deviceId': deviceId },
+      { $set: { 'devices.$.location': { ...location, at: new Date() } } }
+    ).exec();
+  }
+
+  // Count devices active since a date by platform and app version
+  async getClientVersionDistribution(
+    activeSince: Date
+  ): Promise<{ platform: string; appVersion: string | null; devices: number }[]> {
+    return await User.aggregate([
+      { $match: { 'devices.lastActive': { $gte: activeSince } } },
+      { $unwind: '$devices' },
+      { $match: { 'devices.lastActive': { $gte: activeSince } } },
+      {
+        $group: {
+          _id: { platform: '$devices.platform', appVersion: { $ifNull: ['$devices.appVersion', null] } },
+          devices: { $sum: 1 },
+        },
+      },
+      { $project: { _id: 0, platform: '$_id.platform', appVersion: '$_id.appVersion', devices: 1 } },
+      { $sort: { platform: 1, devices: -1 } },
+    ]).exec();
+  }
+
+  // Replace the user's decision on one consent type
+  async recordConsent(userId: string | Types.ObjectId, consent: IUser['consents'][number]): Promise<IUser | null> {
+    await User.updateOne({ _id: userId }, { $pull: { consents: { key: consent.key } } }).exec();
+    return await User.findByIdAndUpdate(
+      userId,
+      { $push: { consents: consent } },
+      { new: true }
+    ).exec();
+  }
+
+  // Remember the newest version of a legal notice the user accepted
+  async recordLegalAcceptance(
+    userId: string | Types.ObjectId,
+    acceptance: IUser['legalAcceptances'][number]
+  ): Promise<IUser | null> {
+    await User.updateOne(
+      { _id: userId },
+      { $pull: { legalAcceptances: { key: acceptance.key, version: { $lt: acceptance.version } } } }
+    ).exec();
+    await User.updateOne(
+      { _id: userId, legalAcceptances: { $not: { $elemMatch: { key: acceptance.key } } } },
+      { $push: { legalAcceptances: acceptance } }
+    ).exec();
+    return await this.findById(userId);
+  }
+
+  // How many users have accepted a legal notice at or above a version
+  async countLegalAcceptances(key: string, minVersion: number): Promise<number> {
+    return await User.countDocuments({
+      legalAcceptances: { $elemMatch: { key, version: { $gte: minVersion } } },
+    }).exec();
+  }
+
+  // Per consent key and version, how many users accepted or declined
+  async getConsentDistribution(
+    keys: string[]
+  ): Promise<{ key: string; version: number; accepted: number; declined: number }[]> {
+    return await User.aggregate([
+      { $match: { 'consents.key': { $in: keys } } },
+      { $unwind: '$consents' },
+      { $match: { 'consents.key': { $in: keys } } },
+      {
+        $group: {
+          _id: { key: '$consents.key', version: '$consents.version' },
+          accepted: { $sum: { $cond: ['$consents.accepted', 1, 0] } },
+          declined: { $sum: { $cond: ['$consents.accepted', 0, 1] } },
+        },
+      },
+      { $project: { _id: 0, key: '$_id.key', version: '$_id.version', accepted: 1, declined: 1 } },
+      { $sort: { key: 1, version: -1 } },
+    ]).exec();
+  }
+
+  // Signups per UTC day, real accounts only
+  async getSignupsByDay(from: Date, to: Date): Promise<{ day: Date; signups: number }[]> {
+    return await User.aggregate([
+      {
+        $match: {
+          createdAt: { $gte: from, $lt: to },
+          'bridge.protocol': { $exists: false },
+          mergedInto: { $exists: false },
+        },
+      },
+      { $group: { _id: { $dateTrunc: { date: '$createdAt', unit: 'day', timezone: 'UTC' } }, signups: { $sum: 1 } } },
+      { $project: { _id: 0, day: '$_id', signups: 1 } },
+      { $sort: { day: 1 } },
+    ]).exec();
+  }
+
+  // Count real (non-bridge) accounts
+  async countHumanUsers(): Promise<number> {
+    return await User.countDocuments({ 'bridge.protocol': { $exists: false }, mergedInto: { $exists: false } }).exec();
+  }
+
+  // Forget a device's push tokens (logout, or the app turned pushes off)
+  async clearDeviceTokens(userId: string | Types.ObjectId, deviceId: string): Promise<boolean> {
+    const result = await User.updateOne(
+      { _id: userId, 'devices.deviceId': deviceId },
+      { $unset: { 'devices.$.pushToken': 1, 'devices.$.voipToken': 1 } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Drop VoIP tokens APNs reported as no longer valid
+  async removeVoipTokens(tokens: string[]): Promise<void> {
+    await User.updateMany(
+      { 'devices.voipToken': { $in: tokens } },
+      { $unset: { 'devices.$[device].voipToken': 1 } },
+      { arrayFilters: [{ 'device.voipToken': { $in: tokens } }] }
+    ).exec();
+  }
+
+  // Star contact (calls from starred contacts can ring through Do Not Disturb)
+  async starContact(userId: string | Types.ObjectId, contactId: string | Types.ObjectId): Promise<boolean> {
+    const result = await User.findByIdAndUpdate(
+      userId,
+      { $addToSet: { starredContacts: contactId } },
+      { new: true }
+    ).exec();
+    return !!result;
+  }
+
+  // Unstar contact
+  async unstarContact(userId: string | Types.ObjectId, contactId: string | Types.ObjectId): Promise<boolean> {
+    const result = await User.findByIdAndUpdate(
+      userId,
+      { $pull: { starredContacts: contactId } },
+      { new: true }
+    ).exec();
+    return !!result;
+  }
+
+  // Get starred contacts
+  async getStarredContacts(userId: string | Types.ObjectId): Promise<IUser[]> {
+    const user = await User.findById(userId)
+      .populate('starredContacts', 'displayName avatar phoneNumber activityStatus privacySettings.status contacts blockedUsers')
+      .exec();
+    return user?.starredContacts as IUser[] || [];
+  }
+
+  // Set or clear the user's activity status
+  async setActivityStatus(id: string | Types.ObjectId, activityStatus: IUser['activityStatus'] | null): Promise<IUser | null> {
+    const update = activityStatus ? { $set: { activityStatus } } : { $unset: { activityStatus: 1 } };
+    return await User.findByIdAndUpdate(id, update, { new: true }).exec();
+  }
+
+  // Clear the activity status only if it is still the expired one that was read
+  async clearExpiredActivityStatus(id: string | Types.ObjectId, updatedAt: Date): Promise<boolean> {
+    const result = await User.updateOne(
+      { _id: id, 'activityStatus.updatedAt': updatedAt },
+      { $unset: { activityStatus: 1 } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Update the user's own message retention policy; days null keeps messages forever
+  async setMessageRetention(
+    id: string | Types.ObjectId,
+    policy: { days?: number | null; exportBeforeDelete?: boolean }
+  ): Promise<IUser | null> {
+    const $set: Record<string, unknown> = {};
+    const $unset: Record<string, 1> = {};
+    if (policy.days === null) $unset['messageRetention.days'] = 1;
+    else if (policy.days !== undefined) $set['messageRetention.days'] = policy.days;
+    if (policy.exportBeforeDelete !== undefined) $set['messageRetention.exportBeforeDelete'] = policy.exportBeforeDelete;
+
+    return await User.findByIdAndUpdate(id, { $set, $unset }, { new: true }).exec();
+  }
+
+  // Set or clear an admin's retention override
+  async setRetentionOverride(
+    id: string | Types.ObjectId,
+    override: NonNullable<IUser['messageRetention']['adminOverride']> | null
+  ): Promise<IUser | null> {
+    const update = override
+      ? { $set: { 'messageRetention.adminOverride': override } }
+      : { $unset: { 'messageRetention.adminOverride': 1 } };
+    return await User.findByIdAndUpdate(id, update, { new: true }).exec();
+  }
+
+  // Users with a retention period whose last purge is older than before
+  async findDueForRetentionPurge(before: Date, limit: number = 100): Promise<IUser[]> {
+    const due = { $or: [{ 'messageRetention.lastPurgedAt': { $exists: false } }, { 'messageRetention.lastPurgedAt': { $lt: before } }] };
+    return await User.find({
+      $and: [
+        due,
+        {
+          $or: [
+            { 'messageRetention.adminOverride.days': { $gt: 0 } },
+            { 'messageRetention.adminOverride': { $exists: false }, 'messageRetention.days': { $gt: 0 } },
+          ],
+        },
+      ],
+    })
+      .sort({ 'messageRetention.lastPurgedAt': 1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Record a finished purge
+  async recordRetentionPurge(id: string | Types.ObjectId): Promise<void> {
+    await User.updateOne({ _id: id }, { $set: { 'messageRetention.lastPurgedAt': new Date() } }).exec();
+  }
+
+  // Add an export written before a purge, dropping ones created before expiredBefore
+  async addRetentionExport(
+    id: string | Types.ObjectId,
+    entry: IUser['messageRetention']['exports'][number],
+    expiredBefore: Date
+  ): Promise<void> {
+    await User.updateOne({ _id: id }, { $pull: { 'messageRetention.exports': { createdAt: { $lt: expiredBefore } } } }).exec();
+    await User.updateOne({ _id: id }, { $push: { 'messageRetention.exports': entry } }).exec();
+  }
+
+  // IDs of users who have the given user in their contacts
+  async findUsersWithContact(contactId: string | Types.ObjectId): Promise<Types.ObjectId[]> {
+    const users = await User.find({ contacts: contactId, isBanned: false }).select('_id').lean().exec();
+    return users.map(user => user._id as Types.ObjectId);
+  }
+
   // Get blocked users
   async getBlockedUsers(userId: string | Types.ObjectId): Promise<IUser[]> {
     const user = await User.findById(userId).populate('blockedUsers', 'displayName avatar phoneNumber').exec();
@@ -144,13 +666,121 @@ export class UserRepository {
     return !!result;
   }
 
-  // Get users with pagination (admin)
-  async getUsers(limit: number = 20, offset: number = 0, filters?: any): Promise<{ users: IUser[], total: number }> {
-    const query = filters || {};
+  // Put user in restricted mode, replacing any earlier restriction
+  async setRestriction(userId: string | Types.ObjectId, restriction: NonNullable<IUser['restriction']>): Promise<boolean> {
+    const result = await User.findByIdAndUpdate(userId, { $set: { restriction } }).exec();
+    return !!result;
+  }
+
+  // Lift restricted mode, only if it is still the one from this action
+  async clearRestriction(userId: string | Types.ObjectId, actionId: string | Types.ObjectId): Promise<boolean> {
+    const result = await User.updateOne(
+      { _id: userId, 'restriction.actionId': actionId },
+      { $unset: { restriction: 1 } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Reputation inputs: how many people saved the user as a contact, and how
+  // many blocked them
+  async getReputation(userId: string | Types.ObjectId): Promise<{ savedBy: number; blockedBy: number }> {
+    const [savedBy, blockedBy] = await Promise.all([
+      User.countDocuments({ contacts: userId }).exec(),
+      User.countDocuments({ blockedUsers: userId }).exec(),
+    ]);
+    return { savedBy, blockedBy };
+  }
+
+  // Record that the user earned trust
+  async markTrusted(userId: string | Types.ObjectId): Promise<boolean> {
+    const result = await User.updateOne(
+      { _id: userId, trustedAt: { $exists: false } },
+      { $set: { trustedAt: new Date() } }
+    ).exec();
+    return result.modifiedCount > 0;
+  }
+
+  // Get a user's staff tags
+  async getStaffTags(userId: string | Types.ObjectId): Promise<string[] | null> {
+    const user = await User.findById(userId).select('+staffTags').lean<Pick<IUser, 'staffTags'>>().exec();
+    return user ? user.staffTags || [] : null;
+  }
+
+  // Replace a user's staff tags
+  async setStaffTags(userId: string | Types.ObjectId, tags: string[]): Promise<boolean> {
+    const result = await User.updateOne({ _id: userId }, { $set: { staffTags: tags } }).exec();
+    return result.matchedCount > 0;
+  }
+
+  // Filtered, sorted page of users (admin)
+  async getUsers(filters: AdminUserFilters, options: ListOptions): Promise<{ users: IUser[], total: number }> {
+    const query = this.adminQuery(filters);
     const [users, total] = await Promise.all([
-      User.find(query).limit(limit).skip(offset).select('-__v').exec(),
+      User.find(query).sort(options.sort).skip(options.offset).limit(options.limit).select('-__v -devices.pushToken -devices.voipToken').exec(),
       User.countDocuments(query).exec()
     ]);
     return { users, total };
   }
+
+  // Every user matching the filters, for exports
+  cursorForAdmin(filters: AdminUserFilters, sort: ListOptions['sort'], limit: number) {
+    return User.find(this.adminQuery(filters))
+      .sort(sort)
+      .limit(limit)
+      .select('-__v -devices.pushToken -devices.voipToken')
+      .lean<IUser>()
+      .cursor();
+  }
+
+  // Search is a case-sensitive prefix match so it can use the indexes
+  private adminQuery(filters: AdminUserFilters): FilterQuery<IUser> {
+    const query: FilterQuery<IUser> = {};
+
+    if (filters.search) {
+      const prefix = new RegExp(`^${escapeRegex(filters.search)}`);
+      query.$or = [
+        { displayName: prefix },
+        { username: prefix },
+        { phoneNumber: prefix },
+      ];
+    }
+    if (filters.banned !== undefined) query.isBanned = filters.banned;
+    if (filters.restricted !== undefined) query.restriction = { $exists: filters.restricted };
+    if (filters.verified !== undefined) query.isVerified = filters.verified;
+    if (filters.online !== undefined) query.isOnline = filters.online;
+    if (filters.countryCode) query.countryCode = filters.countryCode;
+    if (filters.dataRegion) query.dataRegion = filters.dataRegion;
+    if (filters.platform) query['devices.platform'] = filters.platform;
+    if (filters.tag) query.staffTags = filters.tag;
+    if (filters.organizationId) query.organizationId = filters.organizationId;
+    if (!filters.bridged) query['bridge.protocol'] = { $exists: false };
+    if (filters.from || filters.to) {
+      query.createdAt = {
+        ...(filters.from && { $gte: filters.from }),
+        ...(filters.to && { $lte: filters.to }),
+      };
+    }
+
+    return query;
+  }
+}
+
+export interface AdminUserFilters {
+  search?: string;
+  banned?: boolean;
+  restricted?: boolean;
+  verified?: boolean;
+  online?: boolean;
+  countryCode?: string;
+  dataRegion?: DataRegion;
+  platform?: 'ios' | 'android' | 'web';
+  tag?: string; // staff tag
+  organizationId?: string;
+  bridged?: boolean;
+  from?: Date; // signed up
+  to?: Date;
+}
+
+function escapeRegex(value: string): string {
+  return value.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
 }
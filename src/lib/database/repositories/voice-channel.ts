@@ -0,0 +1,126 @@
+import { Types } from 'mongoose';
+import { VoiceChannel, IVoiceChannel, IVoiceChannelParticipant } from '../models/voice-channel';
+
+export class VoiceChannelRepository {
+  // Create channel
+  async create(channelData: Partial<IVoiceChannel>): Promise<IVoiceChannel> {
+    const channel = new VoiceChannel(channelData);
+    return await channel.save();
+  }
+
+  // Find a channel that hasn't been removed
+  async findActiveById(id: string | Types.ObjectId): Promise<IVoiceChannel | null> {
+    return await VoiceChannel.findOne({ _id: id, removedAt: { $exists: false } }).exec();
+  }
+
+  // A chat's channels, oldest first
+  async findByChat(chatId: string | Types.ObjectId): Promise<IVoiceChannel[]> {
+    return await VoiceChannel.find({ chatId, removedAt: { $exists: false } })
+      .sort({ createdAt: 1 })
+      .exec();
+  }
+
+  // Count a chat's channels
+  async countByChat(chatId: string | Types.ObjectId): Promise<number> {
+    return await VoiceChannel.countDocuments({ chatId, removedAt: { $exists: false } }).exec();
+  }
+
+  // Channels someone is connected to; normally at most one
+  async findByParticipant(userId: string | Types.ObjectId): Promise<IVoiceChannel[]> {
+    return await VoiceChannel.find({ 'participants.userId': userId, removedAt: { $exists: false } }).exec();
+  }
+
+  // Channels with a participant connected from a socket
+  async findBySocket(socketId: string): Promise<IVoiceChannel[]> {
+    return await VoiceChannel.find({ 'participants.socketId': socketId }).exec();
+  }
+
+  // Channels with anyone in them, for the presence sweep
+  async findOccupied(limit: number, afterId?: Types.ObjectId): Promise<IVoiceChannel[]> {
+    return await VoiceChannel.find({
+      'participants.0': { $exists: true },
+      ...(afterId && { _id: { $gt: afterId } }),
+    })
+      .sort({ _id: 1 })
+      .limit(limit)
+      .exec();
+  }
+
+  // Update a channel's settings
+  async update(id: string | Types.ObjectId, updateData: Partial<IVoiceChannel>): Promise<IVoiceChannel | null> {
+    return await VoiceChannel.findOneAndUpdate(
+      { _id: id, removedAt: { $exists: false } },
+      { $set: updateData },
+      { new: true }
+    ).exec();
+  }
+
+  // Remove a channel, returning it as it was so the people in it can be
+  // told
+  async remove(id: string | Types.ObjectId): Promise<IVoiceChannel | null> {
+    return await VoiceChannel.findOneAndUpdate(
+      { _id: id, removedAt: { $exists: false } },
+      { $set: { removedAt: new Date(), participants: [] } },
+      { new: false }
+    ).exec();
+  }
+
+  // Add a participant while the channel has room. Null when it is full,
+  // removed, or they are already in it.
+  async addParticipant(
+    id: string | Types.ObjectId,
+    participant: IVoiceChannelParticipant,
+    maxParticipants: number
+  ): Promise<IVoiceChannel | null> {
+    return await VoiceChannel.findOneAndUpdate(
+      {
+        _id: id,
+        removedAt: { $exists: false },
+        'participants.userId': { $ne: participant.userId },
+        [`participants.${maxParticipants - 1}`]: { $exists: false },
+      },
+      { $push: { participants: participant } },
+      { new: true }
+    ).exec();
+  }
+
+  // Take someone out of a channel, only from the given socket if one is
+  // passed. Null when they weren't in it.
+  async removeParticipant(
+    id: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    socketId?: string
+  ): Promise<IVoiceChannel | null> {
+    const match = { userId: new Types.ObjectId(userId.toString()), ...(socketId && { socketId }) };
+    return await VoiceChannel.findOneAndUpdate(
+      { _id: id, participants: { $elemMatch: match } },
+      { $pull: { participants: match } },
+      { new: true }
+    ).exec();
+  }
+
+  // Change a participant's mute and push-to-talk state
+  async updateParticipant(
+    id: string | Types.ObjectId,
+    userId: string | Types.ObjectId,
+    state: Partial<Pick<IVoiceChannelParticipant, 'muted' | 'mutedByAdmin' | 'pushToTalk'>>
+  ): Promise<IVoiceChannel | null> {
+    const update = Object.fromEntries(
+      Object.entries(state).map(([field, value]) => [`participants.$.${field}`, value])
+    );
+    return await VoiceChannel.findOneAndUpdate(
+      { _id: id, removedAt: { $exists: false }, 'participants.userId': userId },
+      { $set: update },
+      { new: true }
+    ).exec();
+  }
+
+  // Grant or take away the right to talk in 'speakers' mode
+  async setSpeaker(id: string | Types.ObjectId, userId: string | Types.ObjectId, canSpeak: boolean): Promise<IVoiceChannel | null> {
+    return await VoiceChannel.findOneAndUpdate(
+      { _id: id, removedAt: { $exists: false } },
+      canSpeak ? { $addToSet: { speakers: userId } } : { $pull: { speakers: userId } },
+      { new: true }
+    ).exec();
+  }
+}
@@ -0,0 +1,83 @@
+import { z } from 'zod';
+import { PAGINATION_CONSTANTS } from '../../utils/constants';
+import { DATA_REGIONS } from '../models/user';
+
+const objectId = z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid ID');
+const flag = z.enum(['true', 'false']).transform(value => value === 'true');
+
+// Query string shared by the admin list endpoints: ?page= and ?limit= pick
+// the page, ?sortBy= (one of the list's indexed fields) and ?sortOrder= the
+// order, ?format=csv exports every match instead (?mode=anonymized for one
+// that can be shared outside the company), and ?from= / ?to= (ISO dates)
+// bound the list's date field
+function listQuerySchema<S extends z.ZodRawShape>(
+  sortFields: readonly [string, ...string[]],
+  filters: S
+) {
+  return z.object({
+    page: z.coerce.number().int().min(1).default(PAGINATION_CONSTANTS.DEFAULT_PAGE),
+    limit: z.coerce.number().int().min(1).max(PAGINATION_CONSTANTS.MAX_PAGE_SIZE).default(PAGINATION_CONSTANTS.DEFAULT_PAGE_SIZE),
+    sortBy: z.enum(sortFields).default(sortFields[0]),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+    format: z.enum(['json', 'csv']).default('json'),
+    mode: z.enum(['full', 'anonymized']).default('full'), // of CSV exports
+    from: z.coerce.date().optional(),
+    to: z.coerce.date().optional(),
+    ...filters,
+  });
+}
+
+export const adminUserListSchema = listQuerySchema(['createdAt', 'lastSeen', 'displayName'], {
+  search: z.string().trim().min(1).max(100).optional(), // prefix of display name, username or phone number
+  banned: flag.optional(),
+  restricted: flag.optional(),
+  verified: flag.optional(),
+  online: flag.optional(),
+  countryCode: z.string().length(2).toUpperCase().optional(),
+  dataRegion: z.enum(DATA_REGIONS).optional(),
+  platform: z.enum(['ios', 'android', 'web']).optional(),
+  tag: z.string().trim().toLowerCase().max(32).optional(), // staff tag
+  bridged: flag.optional(), // puppet accounts are left out unless asked for
+  organizationId: objectId.optional(), // org admins always get their own organization
+});
+
+export const adminMediaListSchema = listQuerySchema(['createdAt', 'size'], {
+  type: z.enum(['image', 'video', 'audio', 'document', 'voice']).optional(),
+  uploadedBy: objectId.optional(),
+  chatId: objectId.optional(),
+  storageRegion: z.enum(DATA_REGIONS).optional(),
+  mimeType: z.string().max(100).optional(),
+});
+
+export const adminCallListSchema = listQuerySchema(['startTime', 'duration'], {
+  status: z.enum(['initiated', 'ringing', 'answered', 'ended', 'missed', 'rejected', 'busy']).optional(),
+  type: z.enum(['voice', 'video']).optional(),
+  endReason: z.enum(['normal', 'busy', 'missed', 'rejected', 'orphaned']).optional(),
+  participant: objectId.optional(),
+  chatId: objectId.optional(),
+  group: flag.optional(),
+});
+
+export const adminReportListSchema = listQuerySchema(['createdAt', 'updatedAt', 'resolvedAt'], {
+  status: z.enum(['pending', 'under_review', 'resolved', 'dismissed']).optional(),
+  type: z.enum(['spam', 'harassment', 'inappropriate_content', 'fake_account', 'other']).optional(),
+  priority: z.enum(['low', 'medium', 'high', 'critical']).optional(),
+  reporterId: objectId.optional(),
+  reportedUserId: objectId.optional(),
+  assignedTo: objectId.optional(),
+  label: z.string().trim().toLowerCase().max(32).optional(),
+});
+
+export const adminSecurityEventListSchema = listQuerySchema(['createdAt'], {
+  type: z.enum(['login_succeeded', 'login_failed', 'login_flagged', 'login_approved', 'login_denied']).optional(),
+  userId: objectId.optional(),
+  country: z.string().length(2).toUpperCase().optional(),
+  ip: z.string().ip().optional(),
+  asn: z.coerce.number().int().min(1).optional(),
+});
+
+export type AdminUserListInput = z.infer<typeof adminUserListSchema>;
+export type AdminMediaListInput = z.infer<typeof adminMediaListSchema>;
+export type AdminCallListInput = z.infer<typeof adminCallListSchema>;
+export type AdminReportListInput = z.infer<typeof adminReportListSchema>;
+export type AdminSecurityEventListInput = z.infer<typeof adminSecurityEventListSchema>;
@@ -0,0 +1,13 @@
+import { z } from 'zod';
+import { AUTH_CONSTANTS } from '../../utils/constants';
+
+export const createApiKeySchema = z.object({
+  name: z.string().trim().min(1).max(80),
+  scopes: z.array(z.enum(AUTH_CONSTANTS.API_KEY_SCOPES)).min(1),
+  // Omit for a key that doesn't expire
+  expiresInDays: z.number().int().min(1).max(AUTH_CONSTANTS.API_KEY_MAX_DAYS).optional(),
+  // Organization the key provisions into; org admins' keys are always for their own
+  organizationId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid organization ID').optional(),
+});
+
+export type CreateApiKeyInput = z.infer<typeof createApiKeySchema>;
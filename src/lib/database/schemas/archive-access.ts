@@ -0,0 +1,24 @@
+import { z } from 'zod';
+import { AUTH_CONSTANTS } from '../../utils/constants';
+
+const objectId = z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid ID');
+
+export const createArchiveAccessSchema = z.object({
+  chatId: objectId,
+  justification: z.string().trim().min(10).max(1000),
+  caseReference: z.string().trim().max(120).optional(),
+  // Only messages sent in this window are visible; omit either end for no bound
+  messagesFrom: z.coerce.date().optional(),
+  messagesTo: z.coerce.date().optional(),
+  expiresInHours: z.number().int().min(1).max(AUTH_CONSTANTS.ARCHIVE_TOKEN_MAX_HOURS).default(AUTH_CONSTANTS.ARCHIVE_TOKEN_DEFAULT_HOURS),
+}).refine(data => !data.messagesFrom || !data.messagesTo || data.messagesFrom < data.messagesTo, {
+  message: 'messagesFrom must be before messagesTo',
+  path: ['messagesFrom'],
+});
+
+export const revokeArchiveAccessSchema = z.object({
+  reason: z.string().trim().min(1).max(1000),
+});
+
+export type CreateArchiveAccessInput = z.infer<typeof createArchiveAccessSchema>;
+export type RevokeArchiveAccessInput = z.infer<typeof revokeArchiveAccessSchema>;
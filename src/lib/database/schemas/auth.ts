@@ -1,19 +1,42 @@
 import { z } from 'zod';
+import { PhoneUtils } from '../../utils/phone';
+import { CLIENT_PLATFORMS, parseVersion } from '../../config/client-versions';
+
+// Phone numbers may be international (+44 20 7946 0958) or national with a
+// separate ISO country code (020 7946 0958 + GB)
+const phoneFields = {
+  phoneNumber: z.string().min(3, 'Invalid phone number format').max(32, 'Invalid phone number format'),
+  countryCode: z.string().regex(/^[A-Za-z]{2}$/, 'Invalid country code').optional(),
+};
+
+const validPhone = (data: { phoneNumber: string; countryCode?: string }) =>
+  PhoneUtils.isValid(data.phoneNumber, data.countryCode);
+
+const invalidPhone = { message: 'Invalid phone number format', path: ['phoneNumber'] };
 
 export const registerSchema = z.object({
-  phoneNumber: z.string().regex(/^\+?[1-9]\d{1,14}$/, 'Invalid phone number format'),
+  ...phoneFields,
   email: z.string().email('Invalid email format').optional(),
   displayName: z.string().min(1, 'Display name is required').max(50, 'Display name too long'),
+}).refine(validPhone, invalidPhone);
+
+// App build reported at sign-in, for the version registry
+export const clientInfoSchema = z.object({
+  platform: z.enum(CLIENT_PLATFORMS),
+  appVersion: z.string().max(32).refine(version => !!parseVersion(version), 'Use a version like "2.14.1"'),
+  osVersion: z.string().max(64).optional(),
+  deviceModel: z.string().max(100).optional(),
 });
 
 export const verifyOTPSchema = z.object({
-  phoneNumber: z.string().regex(/^\+?[1-9]\d{1,14}$/),
+  ...phoneFields,
   otp: z.string().length(6, 'OTP must be 6 digits'),
-});
+  client: clientInfoSchema.optional(),
+}).refine(validPhone, invalidPhone);
 
 export const loginSchema = z.object({
-  phoneNumber: z.string().regex(/^\+?[1-9]\d{1,14}$/),
-});
+  ...phoneFields,
+}).refine(validPhone, invalidPhone);
 
 export const refreshTokenSchema = z.object({
   refreshToken: z.string().min(1, 'Refresh token is required'),
@@ -28,9 +51,27 @@ export const qrLoginSchema = z.object({
   }),
 });
 
+// Answer to a sign-in from a new device or location
+export const loginApprovalDecisionSchema = z.object({
+  decision: z.enum(['approve', 'deny']),
+});
+
+// Same, through the link in the SMS
+export const loginApprovalTokenSchema = loginApprovalDecisionSchema.extend({
+  token: z.string().min(1).max(64),
+});
+
+export const securityEventListSchema = z.object({
+  limit: z.coerce.number().int().min(1).max(100).default(50),
+  offset: z.coerce.number().int().min(0).default(0),
+  type: z.enum(['login_succeeded', 'login_failed', 'login_flagged', 'login_approved', 'login_denied']).optional(),
+});
+
 export type RegisterInput = z.infer<typeof registerSchema>;
 export type VerifyOTPInput = z.infer<typeof verifyOTPSchema>;
 export type LoginInput = z.infer<typeof loginSchema>;
 export type RefreshTokenInput = z.infer<typeof refreshTokenSchema>;
 export type QRLoginInput = z.infer<typeof qrLoginSchema>;
+export type ClientInfoInput = z.infer<typeof clientInfoSchema>;
+export type LoginApprovalDecisionInput = z.infer<typeof loginApprovalDecisionSchema>;
 
@@ -0,0 +1,112 @@
+import { z } from 'zod';
+import { AUTOMATION_CONSTANTS } from '../../utils/constants';
+import { AUTOMATION_CONDITION_FIELDS } from '../models/automation-rule';
+
+const objectId = z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid ID');
+
+// Patterns run against every message in scope, so regexes that can take
+// exponential time are refused: backreferences and a quantified group that
+// itself contains a quantifier, e.g. (a+)+. Messages are also cut to
+// MAX_MATCH_LENGTH before matching.
+function isSafeRegex(source: string): boolean {
+  try {
+    new RegExp(source);
+  } catch {
+    return false;
+  }
+  if (/\\[1-9]|\\k</.test(source)) return false;
+  return !/\((?:[^()\\]|\\.)*[*+}](?:[^()\\]|\\.)*\)[*+{]/.test(source);
+}
+
+const patternSchema = z.object({
+  type: z.enum(['contains', 'regex']),
+  value: z.string().min(1).max(AUTOMATION_CONSTANTS.MAX_PATTERN_LENGTH),
+  caseSensitive: z.boolean().default(false),
+}).refine(pattern => pattern.type !== 'regex' || isSafeRegex(pattern.value), {
+  message: 'Invalid regex, or one that could take too long to match (nested quantifiers, backreferences)',
+  path: ['value'],
+});
+
+const triggerSchema = z.discriminatedUnion('event', [
+  z.object({
+    event: z.literal('message_created'),
+    chatId: objectId.optional(),
+    pattern: patternSchema.optional(),
+  }),
+  z.object({
+    event: z.literal('member_joined'),
+    chatId: objectId.optional(),
+  }),
+  z.object({
+    event: z.literal('report_filed'),
+    reportTypes: z.array(z.enum(['spam', 'harassment', 'inappropriate_content', 'fake_account', 'other'])).min(1).optional(),
+  }),
+]);
+
+const conditionValue = z.union([z.string().max(200), z.number()]);
+
+const conditionSchema = z.object({
+  field: z.enum(AUTOMATION_CONDITION_FIELDS),
+  operator: z.enum(['equals', 'not_equals', 'in', 'not_in', 'gt', 'lt']),
+  value: z.union([conditionValue, z.boolean(), z.array(conditionValue).min(1).max(50)]),
+}).refine(condition => {
+  if (condition.operator === 'in' || condition.operator === 'not_in') return Array.isArray(condition.value);
+  if (condition.operator === 'gt' || condition.operator === 'lt') return typeof condition.value === 'number';
+  return !Array.isArray(condition.value);
+}, {
+  message: 'in and not_in take a list, gt and lt a number, the others a single value',
+  path: ['value'],
+});
+
+const actionSchema = z.discriminatedUnion('type', [
+  z.object({
+    type: z.literal('send_message'),
+    text: z.string().trim().min(1).max(4000),
+    chatId: objectId.optional(),
+  }),
+  z.object({
+    type: z.literal('add_label'),
+    label: z.string()
+      .trim()
+      .toLowerCase()
+      .regex(/^[a-z0-9][a-z0-9_-]{0,31}$/, 'Labels are 1-32 letters, digits, "-" or "_"'),
+  }),
+  z.object({
+    type: z.literal('notify_webhook'),
+    url: z.string().url().refine(url => /^https?:\/\//i.test(url), 'Only http and https URLs'),
+  }),
+  z.object({
+    type: z.literal('apply_moderation'),
+    moderation: z.object({
+      action: z.enum(['restrict', 'ban']),
+      mode: z.enum(['shadow', 'review']).optional(), // restrict; shadow by default
+      durationHours: z.number().int().min(1).max(24 * 365).optional(),
+      reason: z.string().trim().min(1).max(500),
+    }),
+  }),
+]);
+
+export const createAutomationRuleSchema = z.object({
+  name: z.string().trim().min(1).max(100),
+  description: z.string().trim().max(500).optional(),
+  enabled: z.boolean().default(true),
+  trigger: triggerSchema,
+  conditions: z.array(conditionSchema).max(AUTOMATION_CONSTANTS.MAX_CONDITIONS).default([]),
+  actions: z.array(actionSchema).min(1).max(AUTOMATION_CONSTANTS.MAX_ACTIONS),
+});
+
+// The trigger, conditions and actions are replaced as a whole
+export const updateAutomationRuleSchema = z.object({
+  name: z.string().trim().min(1).max(100).optional(),
+  description: z.string().trim().max(500).nullable().optional(),
+  enabled: z.boolean().optional(),
+  trigger: triggerSchema.optional(),
+  conditions: z.array(conditionSchema).max(AUTOMATION_CONSTANTS.MAX_CONDITIONS).optional(),
+  actions: z.array(actionSchema).min(1).max(AUTOMATION_CONSTANTS.MAX_ACTIONS).optional(),
+  rotateSecret: z.boolean().optional(),
+});
+
+export type CreateAutomationRuleInput = z.infer<typeof createAutomationRuleSchema>;
+export type UpdateAutomationRuleInput = z.infer<typeof updateAutomationRuleSchema>;
+export type AutomationTriggerInput = z.infer<typeof triggerSchema>;
+export type AutomationActionInput = z.infer<typeof actionSchema>;
@@ -1,5 +1,7 @@
 
 import { z } from 'zod';
+import { CALL_CONSTANTS } from '../../utils/constants';
+import { CALL_ISSUE_TAGS } from '../models/call-rating';
 
 export const initiateCallSchema = z.object({
   participantId: z.string().regex(/^[0-9a-fA-F]{24}$/),
@@ -22,14 +24,133 @@ export const iceCandidateSchema = z.object({
   candidate: z.string(),
 });
 
+// A participant's rating once the call ended
 export const callQualitySchema = z.object({
   callId: z.string(),
-  rating: z.number().min(1).max(5),
+  rating: z.number().int().min(1).max(5),
+  issues: z.array(z.enum(CALL_ISSUE_TAGS)).max(CALL_ISSUE_TAGS.length).default([]),
   feedback: z.string().max(500).optional(),
 });
 
+// Ratings for admins: ?from= / ?to= (default the last 30 days), grouped by
+// ?groupBy=region|appVersion|platform|callType
+export const callQualityReportQuerySchema = z.object({
+  from: z.coerce.date().optional(),
+  to: z.coerce.date().optional(),
+  groupBy: z.enum(['region', 'appVersion', 'platform', 'callType']).default('region'),
+});
+
+// Connection stats a participant sends in answer to call:quality-request
+export const callQualityReportSchema = z.object({
+  callId: z.string().min(1),
+  packetLoss: z.number().min(0).max(1), // fraction of inbound packets lost
+  latency: z.number().min(0), // round trip, ms
+  connectionQuality: z.enum(['poor', 'fair', 'good', 'excellent']).optional(),
+  videoQuality: z.enum(['low', 'medium', 'high']).optional(),
+  audioLevel: z.number().optional(),
+  candidateType: z.enum(['host', 'srflx', 'prflx', 'relay']).optional(), // of the selected candidate pair
+});
+
+export const joinCallSchema = z.object({
+  callId: z.string().min(1),
+});
+
+export const muteParticipantsSchema = z.object({
+  userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID').optional(),
+  all: z.boolean().optional(),
+  muted: z.boolean().default(true),
+}).refine(data => !!data.userId !== !!data.all, {
+  message: 'Specify either userId or all',
+  path: ['userId'],
+});
+
+export const transferHostSchema = z.object({
+  userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
+});
+
+export const callSpeakerSchema = z.object({
+  userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
+  canSpeak: z.boolean(),
+});
+
+export const captionPreferencesSchema = z.object({
+  enabled: z.boolean().optional(),
+  language: z.string().regex(/^[a-z]{2,3}(-[A-Z]{2})?$/, 'Use a language code such as "en" or "pt-BR"').optional(),
+  optOut: z.boolean().optional(),
+});
+
+const scheduledCallFields = {
+  title: z.string().trim().min(1).max(200),
+  description: z.string().trim().max(2000).optional(),
+  type: z.enum(['voice', 'video']).default('video'),
+  startsAt: z.string().datetime(),
+  durationMinutes: z.number().int().min(5).max(CALL_CONSTANTS.MAX_DURATION / 60000).optional(),
+  participantIds: z.array(z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'))
+    .max(CALL_CONSTANTS.MAX_GROUP_PARTICIPANTS - 1)
+    .optional(),
+  remindBefore: z.number().int().min(0).max(24 * 60).nullable().optional(), // minutes
+};
+
+export const scheduleCallSchema = z.object(scheduledCallFields);
+
+export const updateScheduledCallSchema = z.object({
+  ...scheduledCallFields,
+  type: scheduledCallFields.type.removeDefault(),
+}).partial();
+
+// A sender's media key for one epoch, wrapped separately for each
+// recipient with the chat's end-to-end key. Opaque to the server.
+export const callKeyPacketSchema = z.object({
+  callId: z.string().min(1),
+  epoch: z.number().int().min(0),
+  keyId: z.number().int().min(0).max(Number.MAX_SAFE_INTEGER), // SFrame KID
+  cipherSuite: z.enum(CALL_CONSTANTS.SFRAME_CIPHER_SUITES),
+  recipients: z.array(z.object({
+    userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
+    deviceId: z.string().max(200).optional(),
+    wrappedKey: z.string().regex(/^[A-Za-z0-9+/_-]+={0,2}$/, 'Must be base64').max(2048),
+  })).min(1).max(CALL_CONSTANTS.MAX_GROUP_PARTICIPANTS * 10),
+});
+
+export const startBroadcastSchema = z.object({
+  title: z.string().trim().max(200).optional(),
+  audience: z.enum(['chat', 'link']).default('chat'),
+  // Pushed to external platforms by the media server
+  rtmpEgress: z.array(z.object({
+    url: z.string().url().regex(/^rtmps?:\/\//, 'Must be an rtmp:// or rtmps:// URL'),
+    streamKey: z.string().min(1).max(500),
+  })).max(CALL_CONSTANTS.MAX_BROADCAST_EGRESS).default([]),
+});
+
+// In-call chat, sent over the socket
+export const callChatMessageSchema = z.object({
+  callId: z.string().min(1),
+  text: z.string().trim().min(1).max(CALL_CONSTANTS.MAX_CHAT_MESSAGE_LENGTH),
+  clientId: z.string().max(100).optional(), // echoed back so the sender can match its pending message
+});
+
+// A reaction to an in-call message, or to the call itself when there is no
+// messageId
+export const callChatReactionSchema = z.object({
+  callId: z.string().min(1),
+  messageId: z.string().min(1).optional(),
+  emoji: z.string().max(16).regex(/^\p{Extended_Pictographic}/u, 'Must start with an emoji'),
+  removed: z.boolean().default(false),
+});
+
+export const callChatSettingsSchema = z.object({
+  postSummary: z.boolean(),
+});
+
 export type InitiateCallInput = z.infer<typeof initiateCallSchema>;
 export type AnswerCallInput = z.infer<typeof answerCallSchema>;
 export type EndCallInput = z.infer<typeof endCallSchema>;
 export type IceCandidateInput = z.infer<typeof iceCandidateSchema>;
 export type CallQualityInput = z.infer<typeof callQualitySchema>;
+export type CallQualityReportInput = z.infer<typeof callQualityReportSchema>;
+export type CallQualityReportQuery = z.infer<typeof callQualityReportQuerySchema>;
+export type MuteParticipantsInput = z.infer<typeof muteParticipantsSchema>;
+export type ScheduleCallInput = z.infer<typeof scheduleCallSchema>;
+export type CallKeyPacketInput = z.infer<typeof callKeyPacketSchema>;
+export type StartBroadcastInput = z.infer<typeof startBroadcastSchema>;
+export type CallChatReactionInput = z.infer<typeof callChatReactionSchema>;
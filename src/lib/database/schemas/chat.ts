@@ -0,0 +1,20 @@
+import { z } from 'zod';
+import { notificationSoundSchema, vibrationPatternSchema } from './user';
+
+export const chatNotificationSettingsSchema = z.object({
+  customNotifications: z.boolean().optional(),
+  notificationSound: notificationSoundSchema.optional(),
+  vibration: vibrationPatternSchema.optional(),
+  version: z.number().int().min(0).optional(), // the version of the user's settings last seen
+});
+
+// null clears a setting
+export const chatAppearanceSchema = z.object({
+  wallpaper: z.string().regex(/^[a-z0-9-]{1,40}$/, 'Invalid wallpaper name').nullable().optional(),
+  wallpaperMediaId: z.string().regex(/^[0-9a-fA-F]{24}$/).nullable().optional(),
+  themeColor: z.string().regex(/^#[0-9a-fA-F]{6}$/, 'Expected a #rrggbb color').nullable().optional(),
+  version: z.number().int().min(0).optional(), // the version of the user's settings last seen
+});
+
+export type ChatNotificationSettingsInput = z.infer<typeof chatNotificationSettingsSchema>;
+export type ChatAppearanceInput = z.infer<typeof chatAppearanceSchema>;
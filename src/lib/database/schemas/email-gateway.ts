@@ -0,0 +1,20 @@
+import { z } from 'zod';
+
+// A full address or an "@example.com" domain suffix
+const allowedSenderSchema = z.string().trim().toLowerCase().refine(
+  value => /^@[a-z0-9.-]+\.[a-z]{2,}$/.test(value) || z.string().email().safeParse(value).success,
+  'Must be an email address or @domain'
+);
+
+export const provisionEmailGatewaySchema = z.object({
+  localPart: z.string().regex(/^[a-zA-Z0-9][a-zA-Z0-9._-]{2,40}$/).optional(),
+  allowedSenders: z.array(allowedSenderSchema).max(50).optional(),
+});
+
+export const updateEmailGatewaySchema = z.object({
+  enabled: z.boolean().optional(),
+  allowedSenders: z.array(allowedSenderSchema).max(50).optional(),
+});
+
+export type ProvisionEmailGatewayInput = z.infer<typeof provisionEmailGatewaySchema>;
+export type UpdateEmailGatewayInput = z.infer<typeof updateEmailGatewaySchema>;
@@ -1,4 +1,5 @@
 import { z } from 'zod';
+import { GUEST_CONSTANTS } from '../../utils/constants';
 
 export const createGroupSchema = z.object({
   name: z.string().min(1).max(50),
@@ -15,6 +16,14 @@ export const groupSettingsSchema = z.object({
   whoCanSendMessages: z.enum(['everyone', 'admins']).optional(),
   whoCanEditGroupInfo: z.enum(['everyone', 'admins']).optional(),
   whoCanAddMembers: z.enum(['everyone', 'admins']).optional(),
+  messageApproval: z.boolean().optional(),
+  pseudonymousMembers: z.boolean().optional(),
+  version: z.number().int().min(0).optional(), // the chat version last seen
+});
+
+export const reviewGroupMessageSchema = z.object({
+  decision: z.enum(['approve', 'reject']),
+  reason: z.string().trim().max(200).optional(),
 });
 
 export const addMembersSchema = z.object({
@@ -29,10 +38,56 @@ export const generateInviteSchema = z.object({
   expiresIn: z.number().min(3600).max(604800).default(86400), // 1 hour to 1 week, default 1 day
 });
 
+export const createGuestLinkSchema = z.object({
+  label: z.string().trim().max(100).optional(),
+  expiresInHours: z.number().int().min(1).max(GUEST_CONSTANTS.MAX_LINK_HOURS).default(GUEST_CONSTANTS.DEFAULT_LINK_HOURS),
+  guestHours: z.number().int().min(1).max(GUEST_CONSTANTS.MAX_GUEST_HOURS).default(GUEST_CONSTANTS.DEFAULT_GUEST_HOURS),
+  maxUses: z.number().int().min(1).max(GUEST_CONSTANTS.MAX_USES).optional(),
+  allowCalls: z.boolean().default(false),
+  allowFileUpload: z.boolean().default(false),
+});
+
+export const joinAsGuestSchema = z.object({
+  token: z.string().min(1).max(100),
+  displayName: z.string().trim().min(1).max(50),
+  deviceId: z.string().min(1).max(100).optional(),
+});
+
+export const createVoiceChannelSchema = z.object({
+  name: z.string().trim().min(1).max(50),
+  topic: z.string().trim().max(200).optional(),
+  pushToTalk: z.boolean().default(false),
+  speakerMode: z.enum(['open', 'speakers']).default('open'),
+});
+
+export const updateVoiceChannelSchema = z.object({
+  name: z.string().trim().min(1).max(50).optional(),
+  topic: z.string().trim().max(200).optional(),
+  pushToTalk: z.boolean().optional(),
+  speakerMode: z.enum(['open', 'speakers']).optional(),
+});
+
+// Admins manage who may talk: speakers in 'speakers' mode, and muting
+// participants so only an admin can unmute them
+export const voiceChannelSpeakerSchema = z.object({
+  userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
+  canSpeak: z.boolean().optional(),
+  muted: z.boolean().optional(),
+}).refine(data => data.canSpeak !== undefined || data.muted !== undefined, {
+  message: 'Specify canSpeak or muted',
+  path: ['canSpeak'],
+});
+
 export type CreateGroupInput = z.infer<typeof createGroupSchema>;
 export type UpdateGroupInput = z.infer<typeof updateGroupSchema>;
 export type GroupSettingsInput = z.infer<typeof groupSettingsSchema>;
+export type ReviewGroupMessageInput = z.infer<typeof reviewGroupMessageSchema>;
 export type AddMembersInput = z.infer<typeof addMembersSchema>;
 export type PromoteUserInput = z.infer<typeof promoteUserSchema>;
 export type GenerateInviteInput = z.infer<typeof generateInviteSchema>;
 
+export type CreateGuestLinkInput = z.infer<typeof createGuestLinkSchema>;
+export type CreateVoiceChannelInput = z.infer<typeof createVoiceChannelSchema>;
+export type UpdateVoiceChannelInput = z.infer<typeof updateVoiceChannelSchema>;
+export type VoiceChannelSpeakerInput = z.infer<typeof voiceChannelSpeakerSchema>;
+export type JoinAsGuestInput = z.infer<typeof joinAsGuestSchema>;
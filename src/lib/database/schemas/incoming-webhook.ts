@@ -0,0 +1,49 @@
+import { z } from 'zod';
+
+const rateLimitSchema = z.object({
+  maxRequests: z.number().int().min(1).max(600),
+  windowSeconds: z.number().int().min(1).max(3600),
+});
+
+export const createIncomingWebhookSchema = z.object({
+  name: z.string().trim().min(1).max(80),
+  avatarUrl: z.string().url().optional(),
+  rateLimit: rateLimitSchema.optional(),
+});
+
+export const updateIncomingWebhookSchema = z.object({
+  name: z.string().trim().min(1).max(80).optional(),
+  avatarUrl: z.string().url().nullable().optional(),
+  rateLimit: rateLimitSchema.optional(),
+});
+
+// Slack-compatible message payload (snake_case, as Slack clients send it)
+export const attachmentSchema = z.object({
+  fallback: z.string().max(1000).optional(),
+  color: z.string().regex(/^(#[0-9a-fA-F]{6}|good|warning|danger)$/).optional(),
+  pretext: z.string().max(1000).optional(),
+  title: z.string().max(300).optional(),
+  title_link: z.string().url().optional(),
+  text: z.string().max(4000).optional(),
+  fields: z.array(z.object({
+    title: z.string().max(300),
+    value: z.string().max(2000),
+    short: z.boolean().optional(),
+  })).max(20).optional(),
+  image_url: z.string().url().optional(),
+  footer: z.string().max(300).optional(),
+});
+
+export const webhookMessageSchema = z.object({
+  text: z.string().max(4000).optional(),
+  username: z.string().trim().min(1).max(80).optional(),
+  icon_url: z.string().url().optional(),
+  attachments: z.array(attachmentSchema).max(20).optional(),
+}).refine(
+  payload => !!payload.text?.trim() || (payload.attachments?.length ?? 0) > 0,
+  { message: 'Either text or attachments is required', path: ['text'] }
+);
+
+export type CreateIncomingWebhookInput = z.infer<typeof createIncomingWebhookSchema>;
+export type UpdateIncomingWebhookInput = z.infer<typeof updateIncomingWebhookSchema>;
+export type WebhookMessageInput = z.infer<typeof webhookMessageSchema>;
@@ -0,0 +1,17 @@
+import { z } from 'zod';
+
+const objectId = z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid ID');
+
+export const placeLegalHoldSchema = z.object({
+  subject: z.enum(['user', 'chat']),
+  subjectId: objectId,
+  reason: z.string().trim().min(1).max(1000),
+  caseReference: z.string().trim().max(120).optional(),
+});
+
+export const releaseLegalHoldSchema = z.object({
+  reason: z.string().trim().min(1).max(1000),
+});
+
+export type PlaceLegalHoldInput = z.infer<typeof placeLegalHoldSchema>;
+export type ReleaseLegalHoldInput = z.infer<typeof releaseLegalHoldSchema>;
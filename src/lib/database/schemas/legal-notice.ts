@@ -0,0 +1,30 @@
+import { z } from 'zod';
+
+const noticeVersionSchema = z.object({
+  content: z.string().trim().min(1).max(200000),
+  summary: z.string().trim().max(2000).optional(),
+  // Defaults to now; a future date lets users accept ahead of time
+  effectiveAt: z.string().datetime().optional(),
+});
+
+export const createLegalNoticeSchema = z.object({
+  key: z.string().regex(/^[a-z0-9_-]{1,64}$/, 'Use lowercase letters, digits, "-" and "_"'),
+  title: z.string().trim().min(1).max(120),
+  mandatory: z.boolean().default(true),
+  initialVersion: noticeVersionSchema.optional(),
+});
+
+export const updateLegalNoticeSchema = z.object({
+  title: z.string().trim().min(1).max(120).optional(),
+  mandatory: z.boolean().optional(),
+});
+
+export const publishLegalNoticeVersionSchema = noticeVersionSchema;
+
+export const acceptLegalNoticeSchema = z.object({
+  version: z.number().int().min(1),
+});
+
+export type CreateLegalNoticeInput = z.infer<typeof createLegalNoticeSchema>;
+export type UpdateLegalNoticeInput = z.infer<typeof updateLegalNoticeSchema>;
+export type PublishLegalNoticeVersionInput = z.infer<typeof publishLegalNoticeVersionSchema>;
@@ -13,5 +13,28 @@ export const mediaQuerySchema = z.object({
   offset: z.number().min(0).default(0),
 });
 
+export const shareMediaSchema = z.object({
+  chatIds: z.array(z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid chat ID')).max(20).default([]),
+  userIds: z.array(z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID')).max(20).default([]),
+  // Never expires when omitted
+  expiresInHours: z.number().int().min(1).max(24 * 365).optional(),
+}).refine(data => data.chatIds.length + data.userIds.length > 0, {
+  message: 'Provide at least one chat or user to share with',
+  path: ['chatIds'],
+});
+
+export const createMediaLinkSchema = z.object({
+  password: z.string().min(4).max(128).optional(),
+  // Never expires when omitted, unless admins cap link lifetime
+  expiresInHours: z.number().int().min(1).max(24 * 365).optional(),
+  maxDownloads: z.number().int().min(1).max(100000).optional(),
+});
+
+export const openMediaLinkSchema = z.object({
+  password: z.string().max(128).optional(),
+});
+
 export type UploadMediaInput = z.infer<typeof uploadMediaSchema>;
 export type MediaQueryInput = z.infer<typeof mediaQuerySchema>;
+export type ShareMediaInput = z.infer<typeof shareMediaSchema>;
+export type CreateMediaLinkInput = z.infer<typeof createMediaLinkSchema>;
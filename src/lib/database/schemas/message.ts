@@ -6,6 +6,7 @@ export const sendMessageSchema = z.object({
   type: z.enum(['text', 'image', 'video', 'audio', 'document', 'voice', 'location', 'contact']).default('text'),
   replyTo: z.string().regex(/^[0-9a-fA-F]{24}$/).optional(),
   mediaId: z.string().regex(/^[0-9a-fA-F]{24}$/).optional(),
+  clientMessageId: z.string().uuid().optional(), // stored and echoed back; resends with it are deduped
   metadata: z.object({
     location: z.object({
       latitude: z.number().min(-90).max(90),
@@ -40,8 +41,21 @@ export const searchMessagesSchema = z.object({
   offset: z.number().min(0).default(0),
 });
 
+// Query string of a search within one chat
+export const searchChatMessagesSchema = z.object({
+  q: z.string().trim().min(1).max(100),
+  senderId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid sender ID').optional(),
+  type: z.enum(['text', 'image', 'video', 'audio', 'document', 'voice', 'location', 'contact', 'sticker']).optional(),
+  from: z.coerce.date().optional(),
+  to: z.coerce.date().optional(),
+  before: z.coerce.date().optional(), // createdAt of the last hit on the previous page
+  limit: z.coerce.number().int().min(1).max(50).default(20),
+  context: z.coerce.number().int().min(0).max(10).default(0), // messages to return either side of each hit
+});
+
 export type SendMessageInput = z.infer<typeof sendMessageSchema>;
 export type EditMessageInput = z.infer<typeof editMessageSchema>;
 export type AddReactionInput = z.infer<typeof addReactionSchema>;
 export type MarkAsReadInput = z.infer<typeof markAsReadSchema>;
 export type SearchMessagesInput = z.infer<typeof searchMessagesSchema>;
+export type SearchChatMessagesInput = z.infer<typeof searchChatMessagesSchema>;
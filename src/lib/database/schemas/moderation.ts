@@ -0,0 +1,147 @@
+import { z } from 'zod';
+
+const moderationActionBaseSchema = z.object({
+  reason: z.string().trim().min(1).max(500), // shown to the user for bans
+  note: z.string().trim().max(2000).optional(),
+  reportId: z.string().regex(/^[0-9a-f]{24}$/i, 'Invalid report ID').optional(),
+  // Permanent when omitted
+  durationHours: z.number().int().min(1).max(24 * 365).optional(),
+});
+
+export const banUserSchema = moderationActionBaseSchema;
+
+export const restrictUserSchema = moderationActionBaseSchema.extend({
+  mode: z.enum(['shadow', 'review']).default('shadow'),
+});
+
+export const liftModerationActionSchema = z.object({
+  reason: z.string().trim().min(1).max(500).default('Lifted by moderator'),
+});
+
+// A user's report about another user, a message or a chat
+export const submitReportSchema = z.object({
+  type: z.enum(['spam', 'harassment', 'inappropriate_content', 'fake_account', 'other']),
+  reason: z.string().trim().min(1).max(500),
+  description: z.string().trim().max(2000).optional(),
+  userId: z.string().regex(/^[0-9a-f]{24}$/i, 'Invalid user ID').optional(),
+  messageId: z.string().regex(/^[0-9a-f]{24}$/i, 'Invalid message ID').optional(),
+  chatId: z.string().regex(/^[0-9a-f]{24}$/i, 'Invalid chat ID').optional(),
+}).refine(data => !!(data.userId || data.messageId || data.chatId), {
+  message: 'Provide a userId, messageId or chatId to report',
+  path: ['userId'],
+});
+
+export const reviewMessageSchema = z.object({
+  decision: z.enum(['approve', 'reject']),
+});
+
+export const removeGroupEmojiSchema = z.object({
+  reason: z.string().trim().min(1).max(500),
+});
+
+export const submitAppealSchema = z.object({
+  text: z.string().trim().min(20, 'Please explain in at least 20 characters').max(4000),
+});
+
+export const decideAppealSchema = z.object({
+  decision: z.enum(['approve', 'deny']),
+  note: z.string().trim().max(1000).optional(), // sent to the user
+});
+
+export const addBlockedHashSchema = z.object({
+  hash: z.string().regex(/^[0-9a-f]{16}$/i, 'Hash must be 16 hex characters').optional(),
+  mediaId: z.string().regex(/^[0-9a-f]{24}$/i, 'Invalid media ID').optional(),
+  reason: z.string().trim().min(1).max(500),
+  category: z.enum(['spam', 'abuse', 'csam', 'violence', 'other']).optional(),
+}).refine(data => !!data.hash !== !!data.mediaId, {
+  message: 'Provide either hash or mediaId',
+  path: ['hash'],
+});
+
+// Bulk actions take up to 1000 distinct IDs
+const bulkIdsSchema = (name: string) => z.array(z.string().regex(/^[0-9a-f]{24}$/i, `Invalid ${name} ID`))
+  .min(1)
+  .max(1000)
+  .transform(ids => [...new Set(ids.map(id => id.toLowerCase()))]);
+
+// A ban with a duration is a suspension
+export const bulkUserActionSchema = z.discriminatedUnion('action', [
+  moderationActionBaseSchema.extend({ action: z.literal('ban'), userIds: bulkIdsSchema('user') }),
+  restrictUserSchema.extend({ action: z.literal('restrict'), userIds: bulkIdsSchema('user') }),
+]);
+
+export const bulkDeleteMessagesSchema = z.object({
+  messageIds: bulkIdsSchema('message'),
+});
+
+export const bulkDeleteFilesSchema = z.object({
+  fileIds: bulkIdsSchema('file'),
+});
+
+export const bulkResolveReportsSchema = z.object({
+  reportIds: bulkIdsSchema('report'),
+  status: z.enum(['resolved', 'dismissed']).default('resolved'),
+  resolution: z.string().trim().max(2000).optional(),
+});
+
+const caseObjectId = (name: string) => z.string().regex(/^[0-9a-f]{24}$/i, `Invalid ${name} ID`);
+
+export const addAdminNoteSchema = z.object({
+  text: z.string().trim().min(1).max(5000),
+  caseId: caseObjectId('case').optional(),
+  pinned: z.boolean().default(false),
+});
+
+export const updateAdminNoteSchema = z.object({
+  text: z.string().trim().min(1).max(5000).optional(),
+  pinned: z.boolean().optional(),
+}).refine(data => data.text !== undefined || data.pinned !== undefined, {
+  message: 'Provide text or pinned',
+  path: ['text'],
+});
+
+export const setStaffTagsSchema = z.object({
+  tags: z.array(
+    z.string().trim().toLowerCase().regex(/^[a-z0-9][a-z0-9_-]{0,31}$/, 'Tags are up to 32 letters, digits, - or _')
+  ).max(20).transform(tags => [...new Set(tags)]),
+});
+
+export const openSupportCaseSchema = z.object({
+  title: z.string().trim().min(1).max(200),
+  reportIds: z.array(caseObjectId('report')).max(50).default([]),
+  moderationActionIds: z.array(caseObjectId('moderation action')).max(50).default([]),
+  assignedTo: caseObjectId('admin').optional(),
+  note: z.string().trim().min(1).max(5000).optional(), // first note on the case
+});
+
+export const updateSupportCaseSchema = z.object({
+  title: z.string().trim().min(1).max(200).optional(),
+  status: z.enum(['open', 'waiting', 'closed']).optional(),
+  assignedTo: caseObjectId('admin').nullable().optional(), // null unassigns
+  // Linked in addition to those already on the case
+  reportIds: z.array(caseObjectId('report')).max(50).optional(),
+  moderationActionIds: z.array(caseObjectId('moderation action')).max(50).optional(),
+}).refine(data => Object.values(data).some(value => value !== undefined), {
+  message: 'Nothing to update',
+  path: ['status'],
+});
+
+export const addCaseCommunicationSchema = z.object({
+  channel: z.enum(['email', 'in_app', 'phone', 'other']),
+  direction: z.enum(['inbound', 'outbound']),
+  summary: z.string().trim().min(1).max(5000),
+  at: z.string().datetime().optional(), // when it happened; now when omitted
+});
+
+export type BanUserInput = z.infer<typeof banUserSchema>;
+export type RestrictUserInput = z.infer<typeof restrictUserSchema>;
+export type SubmitReportInput = z.infer<typeof submitReportSchema>;
+export type DecideAppealInput = z.infer<typeof decideAppealSchema>;
+export type AddBlockedHashInput = z.infer<typeof addBlockedHashSchema>;
+export type BulkUserActionInput = z.infer<typeof bulkUserActionSchema>;
+export type BulkResolveReportsInput = z.infer<typeof bulkResolveReportsSchema>;
+export type AddAdminNoteInput = z.infer<typeof addAdminNoteSchema>;
+export type UpdateAdminNoteInput = z.infer<typeof updateAdminNoteSchema>;
+export type OpenSupportCaseInput = z.infer<typeof openSupportCaseSchema>;
+export type UpdateSupportCaseInput = z.infer<typeof updateSupportCaseSchema>;
+export type AddCaseCommunicationInput = z.infer<typeof addCaseCommunicationSchema>;
@@ -0,0 +1,59 @@
+import { z } from 'zod';
+import { BRANDING_CONSTANTS } from '../../utils/constants';
+
+const objectId = z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid ID');
+
+const featuresSchema = z.object({
+  publicFileLinks: z.boolean().optional(),
+  publicLinkMaxDays: z.number().int().min(0).max(3650).optional(),
+  guestLinks: z.boolean().optional(),
+});
+
+// 0 or unset means no limit
+const quotasSchema = z.object({
+  maxUsers: z.number().int().min(0).optional(),
+  maxGroups: z.number().int().min(0).optional(),
+});
+
+export const createOrganizationSchema = z.object({
+  name: z.string().trim().min(1).max(100),
+  slug: z.string().trim().toLowerCase().regex(/^[a-z0-9][a-z0-9-]{1,47}$/, 'Use 2-48 lowercase letters, digits and dashes'),
+  features: featuresSchema.default({}),
+  quotas: quotasSchema.default({}),
+});
+
+export const updateOrganizationSchema = z.object({
+  name: z.string().trim().min(1).max(100).optional(),
+  status: z.enum(['active', 'suspended']).optional(),
+  features: featuresSchema.optional(),
+  quotas: quotasSchema.optional(),
+});
+
+export const organizationMembersSchema = z.object({
+  userIds: z.array(objectId).min(1).max(500),
+});
+
+export const organizationAdminSchema = z.object({
+  adminId: objectId,
+});
+
+// null clears a setting
+const colorSchema = z.string().regex(/^#[0-9a-fA-F]{6}$/, 'Use a #rrggbb color').nullable().optional();
+
+export const updateBrandingSchema = z.object({
+  appName: z.string().trim().min(1).max(50).nullable().optional(),
+  colors: z.object({
+    primary: colorSchema,
+    accent: colorSchema,
+    background: colorSchema,
+  }).optional(),
+  domains: z.array(
+    z.string().trim().toLowerCase().regex(/^(?=.{1,253}$)([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$/, 'Invalid domain')
+  ).max(BRANDING_CONSTANTS.MAX_DOMAINS).nullable().optional(),
+});
+
+export type CreateOrganizationInput = z.infer<typeof createOrganizationSchema>;
+export type UpdateOrganizationInput = z.infer<typeof updateOrganizationSchema>;
+export type OrganizationMembersInput = z.infer<typeof organizationMembersSchema>;
+export type OrganizationAdminInput = z.infer<typeof organizationAdminSchema>;
+export type UpdateBrandingInput = z.infer<typeof updateBrandingSchema>;
@@ -0,0 +1,13 @@
+import { z } from 'zod';
+import { AUTH_CONSTANTS } from '../../utils/constants';
+
+export const createPersonalAccessTokenSchema = z.object({
+  name: z.string().trim().min(1).max(80),
+  scopes: z.array(z.enum(AUTH_CONSTANTS.PERSONAL_TOKEN_SCOPES)).min(1),
+  // Restrict the token to these chats; omit for all of the user's chats
+  chatIds: z.array(z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid chat ID')).max(50).optional(),
+  expiresInDays: z.number().int().min(1).max(AUTH_CONSTANTS.PERSONAL_TOKEN_MAX_DAYS)
+    .default(AUTH_CONSTANTS.PERSONAL_TOKEN_DEFAULT_DAYS),
+});
+
+export type CreatePersonalAccessTokenInput = z.infer<typeof createPersonalAccessTokenSchema>;
@@ -0,0 +1,12 @@
+import { z } from 'zod';
+
+// Reveal what the admin views redact. ids are user or message IDs, or
+// callIds for calls.
+export const revealPiiSchema = z.object({
+  targetType: z.enum(['user', 'message', 'call']),
+  ids: z.array(z.string().trim().min(1).max(64)).min(1).max(50),
+  justification: z.string().trim().min(10).max(1000),
+  caseReference: z.string().trim().max(120).optional(),
+});
+
+export type RevealPiiInput = z.infer<typeof revealPiiSchema>;
@@ -0,0 +1,19 @@
+import { z } from 'zod';
+
+// Either an exact dueAt or a natural-language "when" ("in 2h", "tomorrow at 9")
+export const createReminderSchema = z.object({
+  text: z.string().trim().min(1).max(1000).optional(),
+  messageId: z.string().regex(/^[0-9a-fA-F]{24}$/).optional(),
+  dueAt: z.string().datetime().optional(),
+  when: z.string().trim().min(1).max(100).optional(),
+}).refine(
+  input => !!input.text || !!input.messageId,
+  { message: 'Either text or messageId is required', path: ['text'] }
+).refine(
+  input => !!input.dueAt !== !!input.when,
+  { message: 'Provide exactly one of dueAt or when', path: ['dueAt'] }
+);
+
+export const reminderStatusFilterSchema = z.enum(['upcoming', 'delivered', 'cancelled', 'failed']);
+
+export type CreateReminderInput = z.infer<typeof createReminderSchema>;
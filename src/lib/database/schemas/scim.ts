@@ -0,0 +1,45 @@
+import { z } from 'zod';
+
+// SCIM 2.0 (RFC 7643/7644) request bodies. Only the attributes mapped onto
+// users and groups are checked; anything else an identity provider sends is
+// ignored.
+
+const multiValued = z.object({
+  value: z.string().trim().min(1),
+  type: z.string().optional(),
+  primary: z.boolean().optional(),
+});
+
+export const scimUserSchema = z.object({
+  userName: z.string().trim().min(1).max(256),
+  externalId: z.string().trim().max(256).optional(),
+  displayName: z.string().trim().max(100).optional(),
+  name: z.object({
+    formatted: z.string().trim().max(100).optional(),
+    givenName: z.string().trim().max(100).optional(),
+    familyName: z.string().trim().max(100).optional(),
+  }).optional(),
+  active: z.boolean().default(true),
+  emails: z.array(multiValued).optional(),
+  // A phone number is required: it is how the user signs in
+  phoneNumbers: z.array(multiValued).min(1, 'A phone number is required'),
+});
+
+export const scimGroupSchema = z.object({
+  displayName: z.string().trim().min(1).max(100),
+  externalId: z.string().trim().max(256).optional(),
+  members: z.array(z.object({ value: z.string().trim().min(1) })).default([]),
+});
+
+export const scimPatchSchema = z.object({
+  Operations: z.array(z.object({
+    // Some providers capitalise the op ("Replace")
+    op: z.string().transform(op => op.toLowerCase()).pipe(z.enum(['add', 'remove', 'replace'])),
+    path: z.string().trim().optional(),
+    value: z.unknown().optional(),
+  })).min(1),
+});
+
+export type ScimUserInput = z.infer<typeof scimUserSchema>;
+export type ScimGroupInput = z.infer<typeof scimGroupSchema>;
+export type ScimPatchInput = z.infer<typeof scimPatchSchema>;
@@ -0,0 +1,42 @@
+import { z } from 'zod';
+import { attachmentSchema } from './incoming-webhook';
+
+const commandName = z.string()
+  .trim()
+  .toLowerCase()
+  .regex(/^[a-z][a-z0-9_-]{0,31}$/, 'Command names are 1-32 letters, digits, "-" or "_", starting with a letter');
+
+export const createSlashCommandSchema = z.object({
+  name: commandName,
+  description: z.string().trim().max(200).optional(),
+  usage: z.string().trim().max(100).optional(),
+  url: z.string().url(),
+});
+
+export const updateSlashCommandSchema = z.object({
+  description: z.string().trim().max(200).nullable().optional(),
+  usage: z.string().trim().max(100).nullable().optional(),
+  url: z.string().url().optional(),
+  enabled: z.boolean().optional(),
+  rotateSecret: z.boolean().optional(),
+});
+
+// Turn built-in commands on or off for one chat
+export const updateCommandSettingsSchema = z.object({
+  disabled: z.array(commandName).max(50),
+  version: z.number().int().min(0).optional(), // the chat version last seen
+});
+
+// What a bot may answer an invocation with (Slack-compatible, snake_case)
+export const commandResponseSchema = z.object({
+  response_type: z.enum(['ephemeral', 'in_channel']).default('ephemeral'),
+  text: z.string().max(4000).optional(),
+  username: z.string().trim().min(1).max(80).optional(),
+  icon_url: z.string().url().optional(),
+  attachments: z.array(attachmentSchema).max(20).optional(),
+});
+
+export type CreateSlashCommandInput = z.infer<typeof createSlashCommandSchema>;
+export type UpdateSlashCommandInput = z.infer<typeof updateSlashCommandSchema>;
+export type UpdateCommandSettingsInput = z.infer<typeof updateCommandSettingsSchema>;
+export type CommandResponseInput = z.infer<typeof commandResponseSchema>;
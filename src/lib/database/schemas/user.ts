@@ -1,9 +1,18 @@
 import { z } from 'zod';
+import { SUPPORTED_LOCALES } from '../../i18n';
+import { PUSH_CONSTANTS, MESSAGE_CONSTANTS } from '../../utils/constants';
+import { DateUtils } from '../../utils/date';
+import { DATA_REGIONS } from '../models/user';
+
+export const notificationSoundSchema = z.enum(PUSH_CONSTANTS.SOUNDS);
+export const vibrationPatternSchema = z.enum(['default', 'short', 'long', 'heartbeat', 'none']);
 
 export const updateProfileSchema = z.object({
   displayName: z.string().min(1).max(50).optional(),
   status: z.string().max(139).optional(),
   username: z.string().min(3).max(30).regex(/^[a-zA-Z0-9_]+$/).optional(),
+  language: z.enum(SUPPORTED_LOCALES).optional(),
+  timezone: z.string().refine(DateUtils.isValidTimeZone, 'Invalid time zone').optional(),
 });
 
 export const privacySettingsSchema = z.object({
@@ -19,6 +28,51 @@ export const notificationSettingsSchema = z.object({
   groupNotifications: z.boolean().optional(),
   callNotifications: z.boolean().optional(),
   emailNotifications: z.boolean().optional(),
+  messageSound: notificationSoundSchema.optional(),
+  groupSound: notificationSoundSchema.optional(),
+  callRingtone: notificationSoundSchema.optional(),
+  vibration: vibrationPatternSchema.optional(),
+});
+
+// null goes back to the server default
+export const callSettingsSchema = z.object({
+  noiseSuppression: z.boolean().nullable().optional(),
+  echoCancellation: z.boolean().nullable().optional(),
+  autoGainControl: z.boolean().nullable().optional(),
+});
+
+export const deviceSettingsSchema = z.object({
+  lowDataMode: z.boolean(),
+});
+
+const clockTimeSchema = z.string().regex(/^([01]\d|2[0-3]):[0-5]\d$/, 'Use HH:mm');
+
+export const doNotDisturbSchema = z.object({
+  enabled: z.boolean().optional(),
+  until: z.string().datetime().nullable().optional(),
+  schedules: z.array(z.object({
+    days: z.array(z.number().int().min(0).max(6)).max(7).default([]),
+    start: clockTimeSchema,
+    end: clockTimeSchema,
+  }).refine(schedule => schedule.start !== schedule.end, {
+    message: 'Start and end must differ',
+    path: ['end'],
+  })).max(10).optional(),
+  mode: z.enum(['suppress', 'digest']).optional(),
+  allowStarredCalls: z.boolean().optional(),
+});
+
+export const registerPushTokenSchema = z.object({
+  platform: z.enum(['ios', 'android', 'web']),
+  pushToken: z.string().min(1).max(4096).optional(),
+  voipToken: z.string().regex(/^[0-9a-fA-F]{64,200}$/, 'Invalid PushKit token').optional(),
+  apnsEnvironment: z.enum(['production', 'sandbox']).optional(),
+}).refine(data => data.pushToken || data.voipToken, {
+  message: 'A push or VoIP token is required',
+  path: ['pushToken'],
+}).refine(data => !data.voipToken || data.platform === 'ios', {
+  message: 'VoIP tokens are only supported on iOS',
+  path: ['voipToken'],
 });
 
 export const searchUsersSchema = z.object({
@@ -31,9 +85,68 @@ export const blockUserSchema = z.object({
   userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
 });
 
+export const starContactSchema = z.object({
+  userId: z.string().regex(/^[0-9a-fA-F]{24}$/, 'Invalid user ID'),
+});
+
+export const activityStatusSchema = z.object({
+  emoji: z.string().max(16).regex(/^\p{Extended_Pictographic}/u, 'Must start with an emoji').optional(),
+  text: z.string().trim().min(1).max(100).optional(),
+  expiresAt: z.string().datetime()
+    .refine(value => new Date(value).getTime() > Date.now(), 'Must be in the future')
+    .optional(),
+}).refine(data => data.emoji !== undefined || data.text !== undefined, {
+  message: 'An emoji or a text is required',
+  path: ['text'],
+});
+
+export const resolveContactCodeSchema = z.object({
+  code: z.string().min(1).max(512), // the bare code or the whole add-contact link
+  addContact: z.boolean().default(false),
+});
+
+export const consentDecisionSchema = z.object({
+  key: z.string().min(1).max(64),
+  version: z.number().int().min(1),
+  accepted: z.boolean(),
+});
+
+export const recordConsentsSchema = z.object({
+  consents: z.array(consentDecisionSchema).min(1).max(20),
+});
+
+export const changeDataRegionSchema = z.object({
+  region: z.enum(DATA_REGIONS),
+});
+
+// null keeps messages indefinitely
+const retentionDaysSchema = z.number().int()
+  .min(MESSAGE_CONSTANTS.MIN_RETENTION_DAYS)
+  .max(MESSAGE_CONSTANTS.MAX_RETENTION_DAYS)
+  .nullable();
+
+export const messageRetentionSchema = z.object({
+  days: retentionDaysSchema.optional(),
+  exportBeforeDelete: z.boolean().optional(),
+}).refine(data => data.days !== undefined || data.exportBeforeDelete !== undefined, {
+  message: 'Nothing to update',
+});
+
+export const retentionOverrideSchema = z.object({
+  days: retentionDaysSchema, // null exempts the account from purges
+  reason: z.string().max(500).optional(),
+});
+
 export type UpdateProfileInput = z.infer<typeof updateProfileSchema>;
 export type PrivacySettingsInput = z.infer<typeof privacySettingsSchema>;
 export type NotificationSettingsInput = z.infer<typeof notificationSettingsSchema>;
+export type DoNotDisturbInput = z.infer<typeof doNotDisturbSchema>;
 export type SearchUsersInput = z.infer<typeof searchUsersSchema>;
 export type BlockUserInput = z.infer<typeof blockUserSchema>;
-
+export type StarContactInput = z.infer<typeof starContactSchema>;
+export type ActivityStatusInput = z.infer<typeof activityStatusSchema>;
+export type ResolveContactCodeInput = z.infer<typeof resolveContactCodeSchema>;
+export type ConsentDecisionInput = z.infer<typeof consentDecisionSchema>;
+export type ChangeDataRegionInput = z.infer<typeof changeDataRegionSchema>;
+export type MessageRetentionInput = z.infer<typeof messageRetentionSchema>;
+export type RetentionOverrideInput = z.infer<typeof retentionOverrideSchema>;
@@ -0,0 +1,97 @@
+import mongoose, { Model } from 'mongoose';
+import { Message } from './models/message';
+import { MessageBucket } from './models/message-bucket';
+import { logger } from '../monitoring/logging';
+
+export interface ShardedCollection {
+  model: Model<any>;
+  key: Record<string, 1 | 'hashed'>;
+}
+
+export interface ShardingStatus {
+  sharded: boolean; // connected through mongos
+  collections: {
+    name: string;
+    key: Record<string, 1 | 'hashed'>;
+    shardedWith?: Record<string, unknown>; // the key it is actually sharded on
+  }[];
+}
+
+// Collections that outgrow a single replica set first. Both are keyed on a
+// hashed chat id, which spreads chats evenly across shards without a
+// hotspot on the newest chats, then the message id, so one chat's history
+// stays ordered and a very busy chat can still be split into several chunks.
+// Every message query filters on chatId, so reads go to a single shard.
+// The matching indexes are declared on the models.
+export const SHARDED_COLLECTIONS: ShardedCollection[] = [
+  { model: Message, key: { chatId: 'hashed', _id: 1 } },
+  { model: MessageBucket, key: { chatId: 'hashed', _id: 1 } },
+];
+
+export class ShardingService {
+  // Whether the deployment is sharded and how each collection is sharded
+  async getStatus(): Promise<ShardingStatus> {
+    const db = this.db();
+    const hello = await db.admin().command({ hello: 1 });
+    const sharded = hello.msg === 'isdbgrid';
+
+    const shardedWith = new Map<string, Record<string, unknown>>();
+    if (sharded) {
+      const config = mongoose.connection.getClient().db('config');
+      const entries = await config.collection('collections')
+        .find({ _id: { $in: SHARDED_COLLECTIONS.map(c => this.namespace(c)) } as any })
+        .toArray();
+      for (const entry of entries) {
+        shardedWith.set(String(entry._id), entry.key);
+      }
+    }
+
+    return {
+      sharded,
+      collections: SHARDED_COLLECTIONS.map(collection => ({
+        name: collection.model.collection.collectionName,
+        key: collection.key,
+        shardedWith: shardedWith.get(this.namespace(collection)),
+      })),
+    };
+  }
+
+  // Build the shard key indexes and shard the collections not sharded yet.
+  // Needs a mongos connection and a user allowed to shard.
+  async apply(): Promise<string[]> {
+    const status = await this.getStatus();
+    if (!status.sharded) {
+      throw new Error('Not connected to a sharded cluster (mongos)');
+    }
+
+    const admin = this.db().admin();
+    await admin.command({ enableSharding: this.db().databaseName });
+
+    const applied: string[] = [];
+    for (const collection of SHARDED_COLLECTIONS) {
+      const name = collection.model.collection.collectionName;
+      if (status.collections.find(c => c.name === name)?.shardedWith) continue;
+
+      await collection.model.createIndexes();
+      await admin.command({ shardCollection: this.namespace(collection), key: collection.key });
+      logger.info('Sharded collection', { collection: name, key: collection.key });
+      applied.push(name);
+    }
+
+    return applied;
+  }
+
+  private namespace(collection: ShardedCollection): string {
+    return `${this.db().databaseName}.${collection.model.collection.collectionName}`;
+  }
+
+  private db() {
+    const db = mongoose.connection.db;
+    if (!db) {
+      throw new Error('Database not connected');
+    }
+    return db;
+  }
+}
+
+export const shardingService = new ShardingService();
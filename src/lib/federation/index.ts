@@ -0,0 +1,292 @@
+import { Types } from 'mongoose';
+import { environmentConfig } from '../config/environment';
+import { FederationRepository } from '../database/repositories/federation';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { MediaRepository } from '../database/repositories/media';
+import { UserRepository } from '../database/repositories/user';
+import { FederationProtocol, FederationDirection, IFederatedRoom } from '../database/models/federation';
+import { IMessage } from '../database/models/message';
+import { mediaUploadService } from '../media/upload';
+import { socketManager } from '../realtime/socket';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { matrixAdapter } from './matrix';
+import { xmppAdapter } from './xmpp';
+import { FederationAdapter, InboundMessage } from './types';
+
+export type { FederationAdapter, InboundMessage, OutboundMessage } from './types';
+
+export interface FederationStatus {
+  enabled: boolean;
+  adapters: {
+    protocol: FederationProtocol;
+    configured: boolean;
+    running: boolean;
+  }[];
+}
+
+const ROOM_CACHE_TTL = 30 * 1000; // 30 seconds
+
+// Bridges selected chats to Matrix rooms and XMPP MUCs. Outbound messages are
+// relayed as per-user puppets; inbound messages are stored as sent by local
+// bridge users that stand in for the remote senders.
+export class FederationService {
+  private federationRepository = new FederationRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private mediaRepository = new MediaRepository();
+  private userRepository = new UserRepository();
+  private adapters: Record<FederationProtocol, FederationAdapter> = {
+    matrix: matrixAdapter,
+    xmpp: xmppAdapter,
+  };
+  private running = new Set<FederationProtocol>();
+  private roomCache = new Map<string, { rooms: IFederatedRoom[]; expiresAt: number }>();
+
+  isEnabled(): boolean {
+    return environmentConfig.getValue('FEDERATION_ENABLED');
+  }
+
+  // Start every configured adapter and rejoin mapped rooms
+  async start(): Promise<void> {
+    if (!this.isEnabled()) return;
+
+    for (const adapter of Object.values(this.adapters)) {
+      if (!adapter.isConfigured() || this.running.has(adapter.protocol)) continue;
+
+      try {
+        await adapter.start((protocol, message) => this.ingest(protocol, message));
+        this.running.add(adapter.protocol);
+
+        const { rooms } = await this.federationRepository.listRooms({ protocol: adapter.protocol, enabled: true }, 1000);
+        for (const room of rooms) {
+          await adapter.joinRoom(room.remoteRoomId).catch(error => {
+            logger.error('Failed to rejoin federated room', error, { protocol: adapter.protocol, remoteRoomId: room.remoteRoomId });
+          });
+        }
+
+        logger.info('Federation adapter started', { protocol: adapter.protocol, rooms: rooms.length });
+      } catch (error) {
+        logger.error('Failed to start federation adapter', error, { protocol: adapter.protocol });
+      }
+    }
+  }
+
+  async stop(): Promise<void> {
+    for (const protocol of this.running) {
+      await this.adapters[protocol].stop();
+    }
+    this.running.clear();
+  }
+
+  getStatus(): FederationStatus {
+    return {
+      enabled: this.isEnabled(),
+      adapters: Object.values(this.adapters).map(adapter => ({
+        protocol: adapter.protocol,
+        configured: adapter.isConfigured(),
+        running: this.running.has(adapter.protocol),
+      })),
+    };
+  }
+
+  // Map a local group chat to a remote room. The bridge bot joins the room
+  // first so misconfigured ids fail here rather than on the first message.
+  async bridgeChat(
+    chatId: string,
+    protocol: FederationProtocol,
+    remoteRoomId: string,
+    direction: FederationDirection,
+    adminId: string
+  ): Promise<IFederatedRoom> {
+    const chat = await this.chatRepository.findById(chatId);
+    if (!chat) {
+      throw new FederationError('Chat not found', 404);
+    }
+    if (chat.type !== 'group') {
+      throw new FederationError('Only group chats can be federated', 400);
+    }
+
+    const adapter = this.requireAdapter(protocol);
+    const canonicalId = await adapter.joinRoom(remoteRoomId);
+
+    if (await this.federationRepository.findRoomByRemote(protocol, canonicalId)) {
+      throw new FederationError('Remote room is already bridged', 409);
+    }
+
+    const room = await this.federationRepository.createRoom({
+      chatId: chat._id,
+      protocol,
+      remoteRoomId: canonicalId,
+      direction,
+      createdBy: new Types.ObjectId(adminId),
+    });
+
+    this.roomCache.delete(chatId);
+    return room;
+  }
+
+  // Change direction or pause a bridge
+  async updateBridge(
+    roomId: string,
+    updates: { direction?: FederationDirection; enabled?: boolean }
+  ): Promise<IFederatedRoom | null> {
+    const room = await this.federationRepository.updateRoom(roomId, updates);
+    if (room) {
+      this.roomCache.delete(room.chatId.toString());
+    }
+    return room;
+  }
+
+  // Remove a bridge and leave the remote room
+  async unbridgeChat(roomId: string): Promise<boolean> {
+    const room = await this.federationRepository.findRoomById(roomId);
+    if (!room) return false;
+
+    if (this.running.has(room.protocol)) {
+      await this.adapters[room.protocol].leaveRoom(room.remoteRoomId).catch(error => {
+        logger.error('Failed to leave federated room', error, { remoteRoomId: room.remoteRoomId });
+      });
+    }
+
+    this.roomCache.delete(room.chatId.toString());
+    return await this.federationRepository.deleteRoom(roomId);
+  }
+
+  // Relay a newly created local message to every bridged room of its chat
+  async relayOutbound(message: IMessage): Promise<void> {
+    if (!this.isEnabled()) return;
+
+    const chatId = message.chatId.toString();
+    const rooms = (await this.getRoomsForChat(chatId))
+      .filter(room => room.enabled && room.direction !== 'inbound' && this.running.has(room.protocol));
+    if (rooms.length === 0) return;
+
+    const sender = await this.userRepository.findById(message.senderId);
+    if (!sender) return;
+
+    const media = message.media ? await this.mediaRepository.findById(message.media) : null;
+    const mediaUrl = media ? await mediaUploadService.getFileUrl(media._id.toString()) : null;
+
+    for (const room of rooms) {
+      // Don't echo a remote user's message back to its own network
+      if (sender.bridge?.protocol === room.protocol) continue;
+
+      try {
+        const remoteEventId = await this.adapters[room.protocol].send({
+          remoteRoomId: room.remoteRoomId,
+          messageId: message._id.toString(),
+          sender: { userId: sender._id.toString(), displayName: sender.displayName },
+          content: message.content,
+          type: message.type,
+          media: media && mediaUrl
+            ? { url: mediaUrl, name: media.originalName, mimeType: media.mimeType, size: media.size }
+            : undefined,
+        });
+
+        await this.federationRepository.recordMessage({
+          roomId: room._id,
+          messageId: message._id,
+          protocol: room.protocol,
+          remoteEventId,
+          direction: 'outbound',
+        });
+        await this.federationRepository.recordTraffic(room._id, 'outbound');
+        metricsCollector.incrementCounter('federation_messages', 1, { protocol: room.protocol, direction: 'outbound' });
+      } catch (error) {
+        logger.error('Federation outbound relay failed', error, { protocol: room.protocol, chatId });
+        await this.federationRepository.recordError(room._id, error instanceof Error ? error.message : String(error));
+        metricsCollector.incrementCounter('federation_errors', 1, { protocol: room.protocol, direction: 'outbound' });
+      }
+    }
+  }
+
+  // Store a remote message in the bridged chat and fan it out
+  async ingest(protocol: FederationProtocol, inbound: InboundMessage): Promise<void> {
+    const room = await this.federationRepository.findRoomByRemote(protocol, inbound.remoteRoomId);
+    if (!room || !room.enabled || room.direction === 'outbound') return;
+
+    if (await this.federationRepository.hasRemoteEvent(protocol, inbound.remoteEventId)) return;
+
+    try {
+      const user = await this.userRepository.findOrCreateBridgeUser(
+        protocol,
+        inbound.sender.remoteId,
+        inbound.sender.displayName
+      );
+      if (!(await this.chatRepository.isParticipant(room.chatId, user._id))) {
+        await this.chatRepository.addParticipants(room.chatId, [user._id]);
+      }
+
+      let mediaId: Types.ObjectId | undefined;
+      let type: IMessage['type'] = 'text';
+      if (inbound.media) {
+        const { media } = await mediaUploadService.uploadFromUrl(inbound.media.url, user._id.toString(), {
+          name: inbound.media.name,
+          mimeType: inbound.media.mimeType,
+          headers: inbound.media.headers,
+          chatId: room.chatId.toString(),
+        });
+        mediaId = media._id;
+        type = media.type;
+      }
+
+      const message = await this.messageRepository.create({
+        chatId: room.chatId,
+        senderId: user._id,
+        content: inbound.content || inbound.media?.name || type,
+        type,
+        media: mediaId,
+      });
+
+      await this.federationRepository.recordMessage({
+        roomId: room._id,
+        messageId: message._id,
+        protocol,
+        remoteEventId: inbound.remoteEventId,
+        direction: 'inbound',
+      });
+      await this.chatRepository.updateLastActivity(room.chatId, message._id);
+      await this.federationRepository.recordTraffic(room._id, 'inbound');
+
+      const populatedMessage = await this.messageRepository.findById(message._id);
+      socketManager.emitToChat(room.chatId.toString(), 'message:new', populatedMessage);
+      metricsCollector.incrementCounter('federation_messages', 1, { protocol, direction: 'inbound' });
+
+      // Relay onward to the chat's other bridged networks
+      await this.relayOutbound(message);
+    } catch (error) {
+      logger.error('Federation inbound ingestion failed', error, { protocol, remoteEventId: inbound.remoteEventId });
+      await this.federationRepository.recordError(room._id, error instanceof Error ? error.message : String(error));
+      metricsCollector.incrementCounter('federation_errors', 1, { protocol, direction: 'inbound' });
+    }
+  }
+
+  private async getRoomsForChat(chatId: string): Promise<IFederatedRoom[]> {
+    const cached = this.roomCache.get(chatId);
+    if (cached && cached.expiresAt > Date.now()) {
+      return cached.rooms;
+    }
+
+    const rooms = await this.federationRepository.findRoomsByChat(chatId);
+    this.roomCache.set(chatId, { rooms, expiresAt: Date.now() + ROOM_CACHE_TTL });
+    return rooms;
+  }
+
+  private requireAdapter(protocol: FederationProtocol): FederationAdapter {
+    if (!this.running.has(protocol)) {
+      throw new FederationError(`Federation over ${protocol} is not configured`, 400);
+    }
+    return this.adapters[protocol];
+  }
+}
+
+export class FederationError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'FederationError';
+  }
+}
+
+export const federationService = new FederationService();
@@ -0,0 +1,245 @@
+import { environmentConfig } from '../config/environment';
+import { CryptoUtils } from '../utils/crypto';
+import { logger } from '../monitoring/logging';
+import { FederationAdapter, InboundHandler, OutboundMessage } from './types';
+
+interface MatrixEvent {
+  type: string;
+  event_id: string;
+  room_id: string;
+  sender: string;
+  content: {
+    msgtype?: string;
+    body?: string;
+    url?: string;
+    info?: { mimetype?: string; size?: number };
+    displayname?: string;
+    membership?: string;
+  };
+}
+
+const MEDIA_MSGTYPES: Record<string, string> = {
+  image: 'm.image',
+  video: 'm.video',
+  audio: 'm.audio',
+  voice: 'm.audio',
+  document: 'm.file',
+};
+
+// Matrix bridge implemented as an application service. Local users are
+// puppeted as @<prefix><userId>:<server>; the homeserver pushes remote events
+// to /_matrix/app/v1/transactions/{txnId}.
+export class MatrixAdapter implements FederationAdapter {
+  readonly protocol = 'matrix' as const;
+  private onMessage: InboundHandler | null = null;
+  private puppets = new Set<string>(); // puppet@room pairs known to be joined
+  private displayNames = new Map<string, string>(); // remote sender -> display name
+  private seenTransactions = new Set<string>();
+
+  isConfigured(): boolean {
+    const env = environmentConfig.get();
+    return !!(env.MATRIX_HOMESERVER_URL && env.MATRIX_SERVER_NAME && env.MATRIX_AS_TOKEN && env.MATRIX_HS_TOKEN);
+  }
+
+  async start(onMessage: InboundHandler): Promise<void> {
+    this.onMessage = onMessage;
+  }
+
+  async stop(): Promise<void> {
+    this.onMessage = null;
+    this.puppets.clear();
+  }
+
+  // Verify the homeserver's token on incoming application service requests
+  verifyHomeserverToken(token: string | null): boolean {
+    const expected = environmentConfig.getValue('MATRIX_HS_TOKEN');
+    return !!token && !!expected && CryptoUtils.constantTimeEqual(token, expected);
+  }
+
+  // Process a transaction pushed by the homeserver. Transactions are retried
+  // until acknowledged, so repeated ids are accepted without reprocessing.
+  async handleTransaction(txnId: string, events: MatrixEvent[]): Promise<void> {
+    if (this.seenTransactions.has(txnId)) return;
+
+    for (const event of events) {
+      try {
+        await this.handleEvent(event);
+      } catch (error) {
+        logger.error('Matrix event ingestion failed', error, { eventId: event.event_id, roomId: event.room_id });
+      }
+    }
+
+    this.seenTransactions.add(txnId);
+    if (this.seenTransactions.size > 1000) {
+      this.seenTransactions.delete(this.seenTransactions.values().next().value!);
+    }
+  }
+
+  async joinRoom(remoteRoomId: string): Promise<string> {
+    const result = await this.request('POST', `/_matrix/client/v3/join/${encodeURIComponent(remoteRoomId)}`, {});
+    return result.room_id;
+  }
+
+  async leaveRoom(remoteRoomId: string): Promise<void> {
+    await this.request('POST', `/_matrix/client/v3/rooms/${encodeURIComponent(remoteRoomId)}/leave`, {});
+  }
+
+  async send(message: OutboundMessage): Promise<string> {
+    const puppet = await this.ensurePuppet(message.sender.userId, message.sender.displayName, message.remoteRoomId);
+    let content: Record<string, unknown> = { msgtype: 'm.text', body: message.content };
+
+    if (message.media) {
+      const mxc = await this.uploadMedia(message.media);
+      content = {
+        msgtype: MEDIA_MSGTYPES[message.type] || 'm.file',
+        body: message.content || message.media.name,
+        url: mxc,
+        info: { mimetype: message.media.mimeType, size: message.media.size },
+      };
+    }
+
+    // The local message id doubles as the transaction id, making retries idempotent
+    const result = await this.request(
+      'PUT',
+      `/_matrix/client/v3/rooms/${encodeURIComponent(message.remoteRoomId)}/send/m.room.message/${message.messageId}`,
+      content,
+      puppet
+    );
+    return result.event_id;
+  }
+
+  private async handleEvent(event: MatrixEvent): Promise<void> {
+    // Track display names so bridge users get readable names
+    if (event.type === 'm.room.member' && event.content.displayname) {
+      this.displayNames.set(event.sender, event.content.displayname);
+      return;
+    }
+
+    if (event.type !== 'm.room.message' || this.isOwnUser(event.sender) || !this.onMessage) {
+      return;
+    }
+
+    const { msgtype, body = '', url, info } = event.content;
+    const media = url && url.startsWith('mxc://') && msgtype !== 'm.text' && msgtype !== 'm.notice'
+      ? {
+          url: this.mediaDownloadUrl(url),
+          name: body,
+          mimeType: info?.mimetype,
+          headers: { Authorization: `Bearer ${environmentConfig.getValue('MATRIX_AS_TOKEN')}` },
+        }
+      : undefined;
+
+    await this.onMessage('matrix', {
+      remoteRoomId: event.room_id,
+      remoteEventId: event.event_id,
+      sender: {
+        remoteId: event.sender,
+        displayName: this.displayNames.get(event.sender) || event.sender.slice(1).split(':')[0],
+      },
+      content: media ? '' : body,
+      media,
+    });
+  }
+
+  // Register the puppet, set its display name and join it to the room once
+  private async ensurePuppet(userId: string, displayName: string, roomId: string): Promise<string> {
+    const prefix = environmentConfig.getValue('MATRIX_USER_PREFIX');
+    const localpart = `${prefix}${userId}`;
+    const mxid = `@${localpart}:${environmentConfig.getValue('MATRIX_SERVER_NAME')}`;
+    const key = `${mxid}|${roomId}`;
+    if (this.puppets.has(key)) return mxid;
+
+    try {
+      await this.request('POST', '/_matrix/client/v3/register', {
+        type: 'm.login.application_service',
+        username: localpart,
+        inhibit_login: true,
+      });
+    } catch (error: any) {
+      if (error.errcode !== 'M_USER_IN_USE') throw error;
+    }
+
+    await this.request('PUT', `/_matrix/client/v3/profile/${encodeURIComponent(mxid)}/displayname`, { displayname: displayName }, mxid);
+
+    try {
+      await this.request('POST', `/_matrix/client/v3/join/${encodeURIComponent(roomId)}`, {}, mxid);
+    } catch (error: any) {
+      if (error.errcode !== 'M_FORBIDDEN') throw error;
+      // Invite-only room: the bot invites the puppet first
+      await this.request('POST', `/_matrix/client/v3/rooms/${encodeURIComponent(roomId)}/invite`, { user_id: mxid });
+      await this.request('POST', `/_matrix/client/v3/join/${encodeURIComponent(roomId)}`, {}, mxid);
+    }
+
+    this.puppets.add(key);
+    return mxid;
+  }
+
+  private async uploadMedia(media: NonNullable<OutboundMessage['media']>): Promise<string> {
+    const download = await fetch(media.url);
+    if (!download.ok) {
+      throw new Error(`Media download failed with status ${download.status}`);
+    }
+
+    const res = await fetch(
+      `${this.baseUrl()}/_matrix/media/v3/upload?filename=${encodeURIComponent(media.name)}`,
+      {
+        method: 'POST',
+        headers: {
+          Authorization: `Bearer ${environmentConfig.getValue('MATRIX_AS_TOKEN')}`,
+          'Content-Type': media.mimeType,
+        },
+        body: Buffer.from(await download.arrayBuffer()),
+      }
+    );
+    const data = await res.json();
+    if (!res.ok) {
+      throw Object.assign(new Error(data.error || 'Matrix media upload failed'), { errcode: data.errcode });
+    }
+    return data.content_uri;
+  }
+
+  private mediaDownloadUrl(mxc: string): string {
+    const [server, mediaId] = mxc.slice('mxc://'.length).split('/');
+    return `${this.baseUrl()}/_matrix/client/v1/media/download/${encodeURIComponent(server)}/${encodeURIComponent(mediaId)}`;
+  }
+
+  // Puppets and the bridge bot live in our exclusive namespace
+  private isOwnUser(mxid: string): boolean {
+    const localpart = mxid.slice(1).split(':')[0];
+    const server = mxid.split(':').slice(1).join(':');
+    return server === environmentConfig.getValue('MATRIX_SERVER_NAME') &&
+      localpart.startsWith(environmentConfig.getValue('MATRIX_USER_PREFIX'));
+  }
+
+  private baseUrl(): string {
+    return environmentConfig.getValue('MATRIX_HOMESERVER_URL')!.replace(/\/$/, '');
+  }
+
+  // Client-server API call as the bot, or as a puppet via ?user_id=
+  private async request(method: string, path: string, body: unknown, asUser?: string): Promise<any> {
+    const url = new URL(`${this.baseUrl()}${path}`);
+    if (asUser) {
+      url.searchParams.set('user_id', asUser);
+    }
+
+    const res = await fetch(url, {
+      method,
+      headers: {
+        Authorization: `Bearer ${environmentConfig.getValue('MATRIX_AS_TOKEN')}`,
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(body),
+    });
+    const data = await res.json().catch(() => ({}));
+
+    if (!res.ok) {
+      throw Object.assign(
+        new Error(`Matrix ${method} ${path} failed: ${data.error || res.status}`),
+        { errcode: data.errcode }
+      );
+    }
+    return data;
+  }
+}
+
+export const matrixAdapter = new MatrixAdapter();
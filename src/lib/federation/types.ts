@@ -0,0 +1,53 @@
+import { FederationProtocol } from '../database/models/federation';
+
+export interface OutboundMessage {
+  remoteRoomId: string;
+  messageId: string;
+  sender: {
+    userId: string;
+    displayName: string;
+  };
+  content: string;
+  type: string;
+  media?: {
+    url: string; // short-lived download URL
+    name: string;
+    mimeType: string;
+    size: number;
+  };
+}
+
+export interface InboundMessage {
+  remoteRoomId: string;
+  remoteEventId: string;
+  sender: {
+    remoteId: string;
+    displayName: string;
+  };
+  content: string;
+  media?: {
+    url: string;
+    name?: string;
+    mimeType?: string;
+    headers?: Record<string, string>; // e.g. auth for the homeserver media repo
+  };
+}
+
+export type InboundHandler = (protocol: FederationProtocol, message: InboundMessage) => Promise<void>;
+
+export interface FederationAdapter {
+  readonly protocol: FederationProtocol;
+
+  // Whether the environment has credentials for this protocol
+  isConfigured(): boolean;
+
+  start(onMessage: InboundHandler): Promise<void>;
+  stop(): Promise<void>;
+
+  // Join the remote room as the bridge bot; returns the canonical room id
+  joinRoom(remoteRoomId: string): Promise<string>;
+  leaveRoom(remoteRoomId: string): Promise<void>;
+
+  // Relay a local message; returns the remote event id
+  send(message: OutboundMessage): Promise<string>;
+}
@@ -0,0 +1,181 @@
+import { component, xml, Component } from '@xmpp/component';
+import type { Element } from '@xmpp/xml';
+import { environmentConfig } from '../config/environment';
+import { logger } from '../monitoring/logging';
+import { FederationAdapter, InboundHandler, OutboundMessage } from './types';
+
+const NS_MUC = 'http://jabber.org/protocol/muc';
+const NS_OOB = 'jabber:x:oob';
+const NS_DELAY = 'urn:xmpp:delay';
+const NS_SID = 'urn:xmpp:sid:0';
+const BOT_LOCALPART = 'bridge';
+const BOT_NICK = 'bridge';
+
+// XMPP bridge implemented as an external component (XEP-0114). Local users
+// join MUCs as <userId>@<component domain> under their display name; the
+// bridge bot occupant receives room traffic for ingestion.
+export class XmppAdapter implements FederationAdapter {
+  readonly protocol = 'xmpp' as const;
+  private xmpp: Component | null = null;
+  private onMessage: InboundHandler | null = null;
+  private rooms = new Set<string>(); // MUCs the bot has joined
+  private puppetNicks = new Map<string, Map<string, string>>(); // room -> userId -> nick
+
+  isConfigured(): boolean {
+    const env = environmentConfig.get();
+    return !!(env.XMPP_SERVICE && env.XMPP_COMPONENT_DOMAIN && env.XMPP_COMPONENT_PASSWORD);
+  }
+
+  async start(onMessage: InboundHandler): Promise<void> {
+    if (this.xmpp) return;
+    this.onMessage = onMessage;
+
+    const env = environmentConfig.get();
+    this.xmpp = component({
+      service: env.XMPP_SERVICE!,
+      domain: env.XMPP_COMPONENT_DOMAIN!,
+      password: env.XMPP_COMPONENT_PASSWORD!,
+    });
+
+    this.xmpp.on('error', error => logger.error('XMPP component error', error));
+    this.xmpp.on('stanza', stanza => {
+      this.handleStanza(stanza).catch(error => {
+        logger.error('XMPP stanza ingestion failed', error, { from: stanza.attrs.from });
+      });
+    });
+    // Occupancy is lost on reconnect; rejoin as puppets are used again
+    this.xmpp.on('online', () => {
+      const rooms = Array.from(this.rooms);
+      this.puppetNicks.clear();
+      rooms.forEach(room => this.sendJoin(this.botJid(), room, BOT_NICK));
+    });
+
+    await this.xmpp.start();
+    logger.info('XMPP component connected', { domain: env.XMPP_COMPONENT_DOMAIN });
+  }
+
+  async stop(): Promise<void> {
+    await this.xmpp?.stop();
+    this.xmpp = null;
+    this.onMessage = null;
+    this.rooms.clear();
+    this.puppetNicks.clear();
+  }
+
+  async joinRoom(remoteRoomId: string): Promise<string> {
+    const room = remoteRoomId.toLowerCase();
+    await this.sendJoin(this.botJid(), room, BOT_NICK);
+    this.rooms.add(room);
+    return room;
+  }
+
+  async leaveRoom(remoteRoomId: string): Promise<void> {
+    const room = remoteRoomId.toLowerCase();
+    const client = this.requireClient();
+
+    await client.send(xml('presence', { from: this.botJid(), to: `${room}/${BOT_NICK}`, type: 'unavailable' }));
+    for (const [userId, nick] of this.puppetNicks.get(room) || []) {
+      await client.send(xml('presence', { from: this.puppetJid(userId), to: `${room}/${nick}`, type: 'unavailable' }));
+    }
+
+    this.rooms.delete(room);
+    this.puppetNicks.delete(room);
+  }
+
+  async send(message: OutboundMessage): Promise<string> {
+    const client = this.requireClient();
+    const room = message.remoteRoomId.toLowerCase();
+    const from = await this.ensurePuppet(message.sender.userId, message.sender.displayName, room);
+
+    // Media goes out as an out-of-band link (XEP-0066); clients render the URL
+    const body = message.media
+      ? [message.content, message.media.url].filter(Boolean).join('\n')
+      : message.content;
+    const children = [xml('body', {}, body)];
+    if (message.media) {
+      children.push(xml('x', { xmlns: NS_OOB }, xml('url', {}, message.media.url)));
+    }
+
+    const id = message.messageId;
+    await client.send(xml('message', { type: 'groupchat', to: room, from, id }, ...children));
+    return id;
+  }
+
+  private async handleStanza(stanza: Element): Promise<void> {
+    if (!stanza.is('message') || stanza.attrs.type !== 'groupchat' || !this.onMessage) return;
+
+    // Each joined puppet gets a copy; only the bot's copy is ingested
+    if (stanza.attrs.to?.split('/')[0] !== this.botJid()) return;
+
+    // Skip history replayed on join
+    if (stanza.getChild('delay', NS_DELAY)) return;
+
+    // Nicks may themselves contain slashes
+    const from = stanza.attrs.from as string;
+    const room = from.split('/')[0];
+    const nick = from.slice(room.length + 1);
+    if (!nick || nick === BOT_NICK) return;
+
+    // Our own puppets' messages reflected back by the MUC
+    const ownNicks = this.puppetNicks.get(room);
+    if (ownNicks && Array.from(ownNicks.values()).includes(nick)) return;
+
+    const body = stanza.getChildText('body') || '';
+    const oobUrl = stanza.getChild('x', NS_OOB)?.getChildText('url');
+    const eventId = stanza.getChild('stanza-id', NS_SID)?.attrs.id || stanza.attrs.id;
+    if (!eventId || (!body && !oobUrl)) return;
+
+    await this.onMessage('xmpp', {
+      remoteRoomId: room,
+      remoteEventId: `${room}/${eventId}`,
+      sender: {
+        remoteId: `${room}/${nick}`,
+        displayName: nick,
+      },
+      content: oobUrl ? body.replace(oobUrl, '').trim() : body,
+      media: oobUrl ? { url: oobUrl } : undefined,
+    });
+  }
+
+  private async ensurePuppet(userId: string, displayName: string, room: string): Promise<string> {
+    const jid = this.puppetJid(userId);
+    const nicks = this.puppetNicks.get(room) || new Map<string, string>();
+    if (nicks.has(userId)) return jid;
+
+    // Nicks must be unique per room; disambiguate clashing display names
+    const taken = new Set(nicks.values());
+    let nick = displayName.trim() || userId;
+    if (taken.has(nick) || nick === BOT_NICK) {
+      nick = `${nick} (${userId.slice(-4)})`;
+    }
+
+    await this.sendJoin(jid, room, nick);
+    nicks.set(userId, nick);
+    this.puppetNicks.set(room, nicks);
+    return jid;
+  }
+
+  private async sendJoin(from: string, room: string, nick: string): Promise<void> {
+    await this.requireClient().send(
+      xml('presence', { from, to: `${room}/${nick}` },
+        xml('x', { xmlns: NS_MUC }, xml('history', { maxstanzas: '0' })))
+    );
+  }
+
+  private requireClient(): Component {
+    if (!this.xmpp) {
+      throw new Error('XMPP component is not connected');
+    }
+    return this.xmpp;
+  }
+
+  private botJid(): string {
+    return `${BOT_LOCALPART}@${environmentConfig.getValue('XMPP_COMPONENT_DOMAIN')}`;
+  }
+
+  private puppetJid(userId: string): string {
+    return `${userId}@${environmentConfig.getValue('XMPP_COMPONENT_DOMAIN')}`;
+  }
+}
+
+export const xmppAdapter = new XmppAdapter();
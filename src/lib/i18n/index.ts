@@ -0,0 +1,72 @@
+import { en } from './locales/en';
+import { es } from './locales/es';
+import { fr } from './locales/fr';
+import { de } from './locales/de';
+import { pt } from './locales/pt';
+import { APP_CONFIG } from '../utils/constants';
+
+export type MessageKey = keyof typeof en;
+export type Catalog = Record<MessageKey, string>;
+
+export const SUPPORTED_LOCALES = ['en', 'es', 'fr', 'de', 'pt'] as const;
+export type Locale = typeof SUPPORTED_LOCALES[number];
+export const DEFAULT_LOCALE: Locale = 'en';
+
+const catalogs: Record<Locale, Partial<Catalog>> = { en, es, fr, de, pt };
+
+// Check if a locale is supported
+export function isSupportedLocale(locale: string): locale is Locale {
+  return (SUPPORTED_LOCALES as readonly string[]).includes(locale);
+}
+
+// Resolve a stored preference, BCP 47 tag ("pt-BR") or Accept-Language
+// header ("fr-CA,fr;q=0.9,en;q=0.8") to a supported locale
+export function resolveLocale(input?: string | null): Locale {
+  if (!input) return DEFAULT_LOCALE;
+
+  const candidates = input
+    .split(',')
+    .map(part => {
+      const [tag, ...params] = part.trim().split(';');
+      const q = params.find(p => p.trim().startsWith('q='));
+      return { tag: tag.toLowerCase(), q: q ? parseFloat(q.split('=')[1]) || 0 : 1 };
+    })
+    .sort((a, b) => b.q - a.q);
+
+  for (const { tag } of candidates) {
+    const base = tag.split('-')[0];
+    if (isSupportedLocale(base)) {
+      return base;
+    }
+  }
+
+  return DEFAULT_LOCALE;
+}
+
+// Translate a key, interpolating {placeholders}. Missing translations fall
+// back to English; {appName} is always available.
+export function t(
+  locale: string | undefined | null,
+  key: MessageKey,
+  params: Record<string, string | number> = {}
+): string {
+  const resolved = resolveLocale(locale);
+  const template = catalogs[resolved][key] ?? en[key];
+  const values: Record<string, string | number> = { appName: APP_CONFIG.NAME, ...params };
+
+  return template.replace(/\{(\w+)\}/g, (match, name) =>
+    values[name] !== undefined ? String(values[name]) : match
+  );
+}
+
+// Format a duration in seconds as m:ss or h:mm:ss
+export function formatDuration(totalSeconds: number): string {
+  const seconds = Math.max(0, Math.floor(totalSeconds));
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.floor((seconds % 3600) / 60);
+  const secs = (seconds % 60).toString().padStart(2, '0');
+
+  return hours > 0
+    ? `${hours}:${minutes.toString().padStart(2, '0')}:${secs}`
+    : `${minutes}:${secs}`;
+}
@@ -0,0 +1,97 @@
+import type { Catalog } from '../index';
+
+export const de: Partial<Catalog> = {
+  // Calls
+  'call.type.voice': 'Sprach',
+  'call.type.video': 'Video',
+  'call.ended': 'Anruf beendet • {duration}',
+  'call.missed': 'Verpasster {callType}anruf',
+  'call.rejected': 'Anruf abgelehnt',
+  'call.busy': 'Leitung besetzt',
+  'call.interrupted': 'Anruf unterbrochen',
+  'call.chatSummary': 'Chat aus dem Anruf',
+  'call.chatSummary.more': '…und {count} weitere',
+
+  // Push notifications
+  'push.call.title': 'Eingehender {callType}anruf',
+  'push.call.body': '{callerName} ruft dich an',
+  'push.group.body': '{senderName}: {content}',
+  'push.message.attachment': 'Hat einen Anhang gesendet',
+  'push.reminder.title': 'Erinnerung',
+  'push.scheduledCall.title': 'Geplanter {callType}anruf',
+  'push.scheduledCall.reminder': '{title} beginnt in {minutes} Min.',
+  'push.scheduledCall.started': '{title} hat begonnen, tippe zum Beitreten',
+  'push.callRating.title': 'Wie war dein {callType}anruf?',
+  'push.callRating.body': 'Bewerte die Anrufqualität mit 1 bis 5 Sternen',
+  'push.digest.title': '{count} Benachrichtigungen während „Nicht stören“',
+  'push.digest.more': '+{count} weitere',
+  'push.login.title': 'Neue Anmeldung bei deinem Konto',
+  'push.login.body': 'Angemeldet auf {device} aus {location}. Warst du das?',
+  'push.groupReview.rejected': 'Deine Nachricht wurde von den Gruppenadmins nicht freigegeben',
+  'push.groupReview.rejectedWithReason': 'Deine Nachricht wurde von den Gruppenadmins nicht freigegeben: {reason}',
+
+  // Sign-in alerts
+  'login.location.unknown': 'einem unbekannten Ort',
+  'login.device.unknown': 'einem neuen Gerät',
+
+  // Reminders
+  'reminder.message': 'Erinnerung: {text}',
+
+  // Appeals
+  'appeal.approved.title': 'Dein Einspruch wurde angenommen',
+  'appeal.approved.body': 'Dein {appName}-Konto wurde wiederhergestellt. Du kannst dich wieder anmelden.',
+  'appeal.denied.title': 'Dein Einspruch wurde abgelehnt',
+  'appeal.denied.body': 'Nach Prüfung bleibt die Sperrung deines {appName}-Kontos bestehen.',
+  'appeal.note': 'Hinweis unseres Teams: {note}',
+
+  // SMS
+  'sms.otp': 'Dein {appName}-Bestätigungscode lautet: {otp}\n\nDieser Code läuft in {minutes} Minuten ab. Gib ihn an niemanden weiter.\n\nWenn du diesen Code nicht angefordert hast, ignoriere diese Nachricht.',
+  'sms.welcome': 'Willkommen bei {appName}, {userName}! 🎉\n\nChatte sofort mit Freunden und Familie. Lade unsere App herunter und genieße:\n- Sofortnachrichten\n- Sprach- und Videoanrufe\n- Gruppenchats\n- Sicherheit und Privatsphäre\n\nViel Spaß beim Chatten!',
+  'sms.notification.footer': 'Öffne {appName}, um mehr zu sehen.',
+  'sms.missedCall': '📞 Verpasster {callType}anruf von {callerName}\n\nÖffne {appName}, um zurückzurufen oder mehr zu sehen.',
+  'sms.groupInvite': '{inviterName} hat dich eingeladen, „{groupName}“ auf {appName} beizutreten!\n\nHier beitreten: {inviteLink}\n\nLade {appName} herunter, falls du es noch nicht hast.',
+  'sms.loginApproval': '{appName}: neue Anmeldung auf {device} aus {location}. Warst du das? Hier bestätigen oder ablehnen: {link}',
+
+  // Email (shared)
+  'email.greeting': 'Hallo {userName}!',
+  'email.securityNotice': 'Sicherheitshinweis:',
+  'email.copyright': '© {year} {appName}. Alle Rechte vorbehalten.',
+
+  // Email: OTP
+  'email.otp.subject': 'Dein Bestätigungscode',
+  'email.otp.title': 'Bestätigungscode',
+  'email.otp.intro': 'Dein Bestätigungscode lautet:',
+  'email.otp.instructions': 'Gib diesen Code ein, um die Bestätigung abzuschließen.',
+  'email.otp.expires': 'Dieser Code läuft in {minutes} Minuten ab.',
+  'email.otp.neverShare': 'Gib diesen Code niemals weiter.',
+  'email.otp.ignore': 'Wenn du diesen Code nicht angefordert hast, ignoriere diese E-Mail.',
+  'email.otp.text': 'Dein Bestätigungscode lautet: {otp}. Dieser Code läuft in {minutes} Minuten ab.',
+
+  // Email: welcome
+  'email.welcome.subject': 'Willkommen bei {appName}!',
+  'email.welcome.title': 'Willkommen bei {appName}!',
+  'email.welcome.intro': 'Schön, dass du dabei bist! Bleib mit Freunden und Familie in Verbindung wie nie zuvor.',
+  'email.welcome.messaging.title': 'Sofortnachrichten',
+  'email.welcome.messaging.body': 'Sende Nachrichten, Fotos, Videos und Sprachnachrichten in Echtzeit.',
+  'email.welcome.calls.title': 'Sprach- und Videoanrufe',
+  'email.welcome.calls.body': 'Führe glasklare Sprach- und Videoanrufe mit allen, überall.',
+  'email.welcome.groups.title': 'Gruppenchats',
+  'email.welcome.groups.body': 'Erstelle Gruppen und chatte mit mehreren Personen gleichzeitig.',
+  'email.welcome.privacy.title': 'Privatsphäre und Sicherheit',
+  'email.welcome.privacy.body': 'Deine Unterhaltungen sind durch Ende-zu-Ende-Verschlüsselung geschützt.',
+  'email.welcome.cta': 'Jetzt chatten',
+  'email.welcome.help': 'Brauchst du Hilfe? Unser Support-Team ist jederzeit für dich da.',
+
+  // Email: notification
+  'email.notification.openApp': 'Öffne die App, um Details zu sehen und zu antworten.',
+  'email.notification.manage': 'Du kannst deine Benachrichtigungseinstellungen in der App verwalten.',
+
+  // Email: password reset
+  'email.reset.subject': 'Anfrage zum Zurücksetzen des Passworts',
+  'email.reset.title': 'Passwort zurücksetzen',
+  'email.reset.intro': 'Wir haben eine Anfrage zum Zurücksetzen deines Passworts erhalten. Klicke auf die Schaltfläche, um ein neues Passwort festzulegen:',
+  'email.reset.button': 'Passwort zurücksetzen',
+  'email.reset.expires': 'Aus Sicherheitsgründen läuft dieser Link in 1 Stunde ab.',
+  'email.reset.ignore': 'Wenn du das Zurücksetzen nicht angefordert hast, ignoriere diese E-Mail.',
+  'email.reset.copyLink': 'Falls die Schaltfläche nicht funktioniert, kopiere diesen Link:',
+};
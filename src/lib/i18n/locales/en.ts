@@ -0,0 +1,97 @@
+// English catalog. This is the reference catalog: every key must exist here,
+// other locales fall back to it for missing keys.
+export const en = {
+  // Calls
+  'call.type.voice': 'voice',
+  'call.type.video': 'video',
+  'call.ended': 'Call ended • {duration}',
+  'call.missed': 'Missed {callType} call',
+  'call.rejected': 'Call declined',
+  'call.busy': 'Line busy',
+  'call.interrupted': 'Call interrupted',
+  'call.chatSummary': 'Chat from the call',
+  'call.chatSummary.more': '…and {count} more',
+
+  // Push notifications
+  'push.call.title': 'Incoming {callType} call',
+  'push.call.body': '{callerName} is calling you',
+  'push.group.body': '{senderName}: {content}',
+  'push.message.attachment': 'Sent an attachment',
+  'push.reminder.title': 'Reminder',
+  'push.scheduledCall.title': 'Scheduled {callType} call',
+  'push.scheduledCall.reminder': '{title} starts in {minutes} min',
+  'push.scheduledCall.started': '{title} has started, tap to join',
+  'push.callRating.title': 'How was your {callType} call?',
+  'push.callRating.body': 'Rate the call quality from 1 to 5 stars',
+  'push.digest.title': '{count} notifications while Do Not Disturb was on',
+  'push.digest.more': '+{count} more',
+  'push.login.title': 'New sign-in to your account',
+  'push.login.body': 'Signed in on {device} from {location}. Was this you?',
+  'push.groupReview.rejected': 'Your message was not approved by the group admins',
+  'push.groupReview.rejectedWithReason': 'Your message was not approved by the group admins: {reason}',
+
+  // Sign-in alerts
+  'login.location.unknown': 'an unknown location',
+  'login.device.unknown': 'a new device',
+
+  // Reminders
+  'reminder.message': 'Reminder: {text}',
+
+  // Appeals
+  'appeal.approved.title': 'Your appeal was approved',
+  'appeal.approved.body': 'Your {appName} account has been restored. You can sign in again.',
+  'appeal.denied.title': 'Your appeal was denied',
+  'appeal.denied.body': 'After review, the suspension of your {appName} account stays in place.',
+  'appeal.note': 'Note from our team: {note}',
+
+  // SMS
+  'sms.otp': 'Your {appName} verification code is: {otp}\n\nThis code expires in {minutes} minutes. Do not share this code with anyone.\n\nIf you didn\'t request this code, please ignore this message.',
+  'sms.welcome': 'Welcome to {appName}, {userName}! 🎉\n\nStart chatting with friends and family instantly. Download our app and enjoy:\n- Instant messaging\n- Voice & video calls\n- Group chats\n- Secure & private\n\nHappy chatting!',
+  'sms.notification.footer': 'Open {appName} to see more details.',
+  'sms.missedCall': '📞 Missed {callType} call from {callerName}\n\nOpen {appName} to call back or see more details.',
+  'sms.groupInvite': '{inviterName} invited you to join "{groupName}" on {appName}!\n\nJoin here: {inviteLink}\n\nDownload {appName} if you don\'t have it yet.',
+  'sms.loginApproval': '{appName}: new sign-in on {device} from {location}. Was this you? Approve or deny it here: {link}',
+
+  // Email (shared)
+  'email.greeting': 'Hi {userName}!',
+  'email.securityNotice': 'Security Notice:',
+  'email.copyright': '© {year} {appName}. All rights reserved.',
+
+  // Email: OTP
+  'email.otp.subject': 'Your Verification Code',
+  'email.otp.title': 'Verification Code',
+  'email.otp.intro': 'Your verification code is:',
+  'email.otp.instructions': 'Enter this code to complete your verification.',
+  'email.otp.expires': 'This code expires in {minutes} minutes.',
+  'email.otp.neverShare': 'Never share this code with anyone.',
+  'email.otp.ignore': 'If you didn\'t request this code, please ignore this email.',
+  'email.otp.text': 'Your verification code is: {otp}. This code expires in {minutes} minutes.',
+
+  // Email: welcome
+  'email.welcome.subject': 'Welcome to {appName}!',
+  'email.welcome.title': 'Welcome to {appName}!',
+  'email.welcome.intro': 'We\'re excited to have you join our chat community! Get ready to connect with friends and family like never before.',
+  'email.welcome.messaging.title': 'Instant Messaging',
+  'email.welcome.messaging.body': 'Send messages, photos, videos, and voice notes instantly.',
+  'email.welcome.calls.title': 'Voice & Video Calls',
+  'email.welcome.calls.body': 'Make crystal-clear voice and video calls to anyone, anywhere.',
+  'email.welcome.groups.title': 'Group Chats',
+  'email.welcome.groups.body': 'Create groups and chat with multiple people at once.',
+  'email.welcome.privacy.title': 'Privacy & Security',
+  'email.welcome.privacy.body': 'Your conversations are protected with end-to-end encryption.',
+  'email.welcome.cta': 'Start Chatting Now',
+  'email.welcome.help': 'Need help? Contact our support team anytime.',
+
+  // Email: notification
+  'email.notification.openApp': 'Open the app to see more details and respond.',
+  'email.notification.manage': 'You can manage your notification preferences in the app settings.',
+
+  // Email: password reset
+  'email.reset.subject': 'Password Reset Request',
+  'email.reset.title': 'Password Reset',
+  'email.reset.intro': 'We received a request to reset your password. Click the button below to create a new password:',
+  'email.reset.button': 'Reset Password',
+  'email.reset.expires': 'This link expires in 1 hour for security reasons.',
+  'email.reset.ignore': 'If you didn\'t request this reset, please ignore this email.',
+  'email.reset.copyLink': 'If the button doesn\'t work, copy and paste this link:',
+};
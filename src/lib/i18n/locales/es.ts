@@ -0,0 +1,97 @@
+import type { Catalog } from '../index';
+
+export const es: Partial<Catalog> = {
+  // Calls
+  'call.type.voice': 'de voz',
+  'call.type.video': 'de video',
+  'call.ended': 'Llamada finalizada • {duration}',
+  'call.missed': 'Llamada {callType} perdida',
+  'call.rejected': 'Llamada rechazada',
+  'call.busy': 'Línea ocupada',
+  'call.interrupted': 'Llamada interrumpida',
+  'call.chatSummary': 'Chat de la llamada',
+  'call.chatSummary.more': '…y {count} más',
+
+  // Push notifications
+  'push.call.title': 'Llamada {callType} entrante',
+  'push.call.body': '{callerName} te está llamando',
+  'push.group.body': '{senderName}: {content}',
+  'push.message.attachment': 'Envió un archivo adjunto',
+  'push.reminder.title': 'Recordatorio',
+  'push.scheduledCall.title': 'Llamada de {callType} programada',
+  'push.scheduledCall.reminder': '{title} empieza en {minutes} min',
+  'push.scheduledCall.started': '{title} ha empezado, toca para unirte',
+  'push.callRating.title': '¿Qué tal tu llamada de {callType}?',
+  'push.callRating.body': 'Valora la calidad de la llamada de 1 a 5 estrellas',
+  'push.digest.title': '{count} notificaciones mientras No molestar estaba activado',
+  'push.digest.more': '+{count} más',
+  'push.login.title': 'Nuevo inicio de sesión en tu cuenta',
+  'push.login.body': 'Sesión iniciada en {device} desde {location}. ¿Fuiste tú?',
+  'push.groupReview.rejected': 'Los administradores del grupo no aprobaron tu mensaje',
+  'push.groupReview.rejectedWithReason': 'Los administradores del grupo no aprobaron tu mensaje: {reason}',
+
+  // Sign-in alerts
+  'login.location.unknown': 'una ubicación desconocida',
+  'login.device.unknown': 'un dispositivo nuevo',
+
+  // Reminders
+  'reminder.message': 'Recordatorio: {text}',
+
+  // Appeals
+  'appeal.approved.title': 'Tu apelación fue aprobada',
+  'appeal.approved.body': 'Tu cuenta de {appName} ha sido restablecida. Ya puedes volver a iniciar sesión.',
+  'appeal.denied.title': 'Tu apelación fue rechazada',
+  'appeal.denied.body': 'Tras revisarla, la suspensión de tu cuenta de {appName} se mantiene.',
+  'appeal.note': 'Nota de nuestro equipo: {note}',
+
+  // SMS
+  'sms.otp': 'Tu código de verificación de {appName} es: {otp}\n\nEste código caduca en {minutes} minutos. No compartas este código con nadie.\n\nSi no solicitaste este código, ignora este mensaje.',
+  'sms.welcome': '¡Bienvenido a {appName}, {userName}! 🎉\n\nEmpieza a chatear con amigos y familiares al instante. Descarga nuestra app y disfruta de:\n- Mensajería instantánea\n- Llamadas de voz y video\n- Chats de grupo\n- Seguridad y privacidad\n\n¡Feliz chat!',
+  'sms.notification.footer': 'Abre {appName} para ver más detalles.',
+  'sms.missedCall': '📞 Llamada {callType} perdida de {callerName}\n\nAbre {appName} para devolver la llamada o ver más detalles.',
+  'sms.groupInvite': '¡{inviterName} te invitó a unirte a "{groupName}" en {appName}!\n\nÚnete aquí: {inviteLink}\n\nDescarga {appName} si aún no la tienes.',
+  'sms.loginApproval': '{appName}: nuevo inicio de sesión en {device} desde {location}. ¿Fuiste tú? Apruébalo o recházalo aquí: {link}',
+
+  // Email (shared)
+  'email.greeting': '¡Hola, {userName}!',
+  'email.securityNotice': 'Aviso de seguridad:',
+  'email.copyright': '© {year} {appName}. Todos los derechos reservados.',
+
+  // Email: OTP
+  'email.otp.subject': 'Tu código de verificación',
+  'email.otp.title': 'Código de verificación',
+  'email.otp.intro': 'Tu código de verificación es:',
+  'email.otp.instructions': 'Introduce este código para completar la verificación.',
+  'email.otp.expires': 'Este código caduca en {minutes} minutos.',
+  'email.otp.neverShare': 'Nunca compartas este código con nadie.',
+  'email.otp.ignore': 'Si no solicitaste este código, ignora este correo.',
+  'email.otp.text': 'Tu código de verificación es: {otp}. Este código caduca en {minutes} minutos.',
+
+  // Email: welcome
+  'email.welcome.subject': '¡Bienvenido a {appName}!',
+  'email.welcome.title': '¡Bienvenido a {appName}!',
+  'email.welcome.intro': '¡Nos alegra que te unas a nuestra comunidad! Prepárate para conectar con amigos y familiares como nunca antes.',
+  'email.welcome.messaging.title': 'Mensajería instantánea',
+  'email.welcome.messaging.body': 'Envía mensajes, fotos, videos y notas de voz al instante.',
+  'email.welcome.calls.title': 'Llamadas de voz y video',
+  'email.welcome.calls.body': 'Haz llamadas de voz y video nítidas a cualquier persona, en cualquier lugar.',
+  'email.welcome.groups.title': 'Chats de grupo',
+  'email.welcome.groups.body': 'Crea grupos y chatea con varias personas a la vez.',
+  'email.welcome.privacy.title': 'Privacidad y seguridad',
+  'email.welcome.privacy.body': 'Tus conversaciones están protegidas con cifrado de extremo a extremo.',
+  'email.welcome.cta': 'Empieza a chatear',
+  'email.welcome.help': '¿Necesitas ayuda? Contacta con nuestro equipo de soporte en cualquier momento.',
+
+  // Email: notification
+  'email.notification.openApp': 'Abre la app para ver más detalles y responder.',
+  'email.notification.manage': 'Puedes gestionar tus preferencias de notificación en los ajustes de la app.',
+
+  // Email: password reset
+  'email.reset.subject': 'Solicitud de restablecimiento de contraseña',
+  'email.reset.title': 'Restablecer contraseña',
+  'email.reset.intro': 'Recibimos una solicitud para restablecer tu contraseña. Haz clic en el botón de abajo para crear una nueva:',
+  'email.reset.button': 'Restablecer contraseña',
+  'email.reset.expires': 'Por seguridad, este enlace caduca en 1 hora.',
+  'email.reset.ignore': 'Si no solicitaste este cambio, ignora este correo.',
+  'email.reset.copyLink': 'Si el botón no funciona, copia y pega este enlace:',
+};
@@ -0,0 +1,97 @@
+import type { Catalog } from '../index';
+
+export const fr: Partial<Catalog> = {
+  // Calls
+  'call.type.voice': 'vocal',
+  'call.type.video': 'vidéo',
+  'call.ended': 'Appel terminé • {duration}',
+  'call.missed': 'Appel {callType} manqué',
+  'call.rejected': 'Appel refusé',
+  'call.busy': 'Ligne occupée',
+  'call.interrupted': 'Appel interrompu',
+  'call.chatSummary': 'Discussion de l\'appel',
+  'call.chatSummary.more': '…et {count} de plus',
+
+  // Push notifications
+  'push.call.title': 'Appel {callType} entrant',
+  'push.call.body': '{callerName} vous appelle',
+  'push.group.body': '{senderName} : {content}',
+  'push.message.attachment': 'A envoyé une pièce jointe',
+  'push.reminder.title': 'Rappel',
+  'push.scheduledCall.title': 'Appel {callType} planifié',
+  'push.scheduledCall.reminder': '{title} commence dans {minutes} min',
+  'push.scheduledCall.started': '{title} a commencé, touchez pour rejoindre',
+  'push.callRating.title': 'Comment était votre appel {callType} ?',
+  'push.callRating.body': 'Notez la qualité de l\'appel de 1 à 5 étoiles',
+  'push.digest.title': '{count} notifications pendant le mode Ne pas déranger',
+  'push.digest.more': '+{count} de plus',
+  'push.login.title': 'Nouvelle connexion à votre compte',
+  'push.login.body': 'Connexion sur {device} depuis {location}. Était-ce vous ?',
+  'push.groupReview.rejected': 'Votre message n\'a pas été approuvé par les administrateurs du groupe',
+  'push.groupReview.rejectedWithReason': 'Votre message n\'a pas été approuvé par les administrateurs du groupe : {reason}',
+
+  // Sign-in alerts
+  'login.location.unknown': 'un lieu inconnu',
+  'login.device.unknown': 'un nouvel appareil',
+
+  // Reminders
+  'reminder.message': 'Rappel : {text}',
+
+  // Appeals
+  'appeal.approved.title': 'Votre appel a été accepté',
+  'appeal.approved.body': 'Votre compte {appName} a été rétabli. Vous pouvez à nouveau vous connecter.',
+  'appeal.denied.title': 'Votre appel a été refusé',
+  'appeal.denied.body': 'Après examen, la suspension de votre compte {appName} est maintenue.',
+  'appeal.note': 'Note de notre équipe : {note}',
+
+  // SMS
+  'sms.otp': 'Votre code de vérification {appName} est : {otp}\n\nCe code expire dans {minutes} minutes. Ne le partagez avec personne.\n\nSi vous n\'avez pas demandé ce code, ignorez ce message.',
+  'sms.welcome': 'Bienvenue sur {appName}, {userName} ! 🎉\n\nDiscutez instantanément avec vos amis et votre famille. Téléchargez notre application et profitez de :\n- Messagerie instantanée\n- Appels vocaux et vidéo\n- Discussions de groupe\n- Sécurité et confidentialité\n\nBonne discussion !',
+  'sms.notification.footer': 'Ouvrez {appName} pour plus de détails.',
+  'sms.missedCall': '📞 Appel {callType} manqué de {callerName}\n\nOuvrez {appName} pour rappeler ou voir plus de détails.',
+  'sms.groupInvite': '{inviterName} vous invite à rejoindre « {groupName} » sur {appName} !\n\nRejoindre : {inviteLink}\n\nTéléchargez {appName} si vous ne l\'avez pas encore.',
+  'sms.loginApproval': '{appName} : nouvelle connexion sur {device} depuis {location}. Était-ce vous ? Approuvez ou refusez ici : {link}',
+
+  // Email (shared)
+  'email.greeting': 'Bonjour {userName} !',
+  'email.securityNotice': 'Avis de sécurité :',
+  'email.copyright': '© {year} {appName}. Tous droits réservés.',
+
+  // Email: OTP
+  'email.otp.subject': 'Votre code de vérification',
+  'email.otp.title': 'Code de vérification',
+  'email.otp.intro': 'Votre code de vérification est :',
+  'email.otp.instructions': 'Saisissez ce code pour terminer la vérification.',
+  'email.otp.expires': 'Ce code expire dans {minutes} minutes.',
+  'email.otp.neverShare': 'Ne partagez jamais ce code.',
+  'email.otp.ignore': 'Si vous n\'avez pas demandé ce code, ignorez cet e-mail.',
+  'email.otp.text': 'Votre code de vérification est : {otp}. Ce code expire dans {minutes} minutes.',
+
+  // Email: welcome
+  'email.welcome.subject': 'Bienvenue sur {appName} !',
+  'email.welcome.title': 'Bienvenue sur {appName} !',
+  'email.welcome.intro': 'Nous sommes ravis de vous compter parmi nous ! Préparez-vous à échanger avec vos proches comme jamais auparavant.',
+  'email.welcome.messaging.title': 'Messagerie instantanée',
+  'email.welcome.messaging.body': 'Envoyez instantanément messages, photos, vidéos et notes vocales.',
+  'email.welcome.calls.title': 'Appels vocaux et vidéo',
+  'email.welcome.calls.body': 'Passez des appels vocaux et vidéo d\'une clarté parfaite, partout dans le monde.',
+  'email.welcome.groups.title': 'Discussions de groupe',
+  'email.welcome.groups.body': 'Créez des groupes et discutez avec plusieurs personnes à la fois.',
+  'email.welcome.privacy.title': 'Confidentialité et sécurité',
+  'email.welcome.privacy.body': 'Vos conversations sont protégées par un chiffrement de bout en bout.',
+  'email.welcome.cta': 'Commencer à discuter',
+  'email.welcome.help': 'Besoin d\'aide ? Contactez notre équipe d\'assistance à tout moment.',
+
+  // Email: notification
+  'email.notification.openApp': 'Ouvrez l\'application pour voir les détails et répondre.',
+  'email.notification.manage': 'Vous pouvez gérer vos préférences de notification dans les paramètres de l\'application.',
+
+  // Email: password reset
+  'email.reset.subject': 'Demande de réinitialisation du mot de passe',
+  'email.reset.title': 'Réinitialisation du mot de passe',
+  'email.reset.intro': 'Nous avons reçu une demande de réinitialisation de votre mot de passe. Cliquez sur le bouton ci-dessous pour en créer un nouveau :',
+  'email.reset.button': 'Réinitialiser le mot de passe',
+  'email.reset.expires': 'Pour des raisons de sécurité, ce lien expire dans 1 heure.',
+  'email.reset.ignore': 'Si vous n\'avez pas demandé cette réinitialisation, ignorez cet e-mail.',
+  'email.reset.copyLink': 'Si le bouton ne fonctionne pas, copiez et collez ce lien :',
+};
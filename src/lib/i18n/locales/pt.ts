@@ -0,0 +1,97 @@
+import type { Catalog } from '../index';
+
+export const pt: Partial<Catalog> = {
+  // Calls
+  'call.type.voice': 'de voz',
+  'call.type.video': 'de vídeo',
+  'call.ended': 'Chamada encerrada • {duration}',
+  'call.missed': 'Chamada {callType} perdida',
+  'call.rejected': 'Chamada recusada',
+  'call.busy': 'Linha ocupada',
+  'call.interrupted': 'Chamada interrompida',
+  'call.chatSummary': 'Chat da chamada',
+  'call.chatSummary.more': '…e mais {count}',
+
+  // Push notifications
+  'push.call.title': 'Chamada {callType} recebida',
+  'push.call.body': '{callerName} está ligando para você',
+  'push.group.body': '{senderName}: {content}',
+  'push.message.attachment': 'Enviou um anexo',
+  'push.reminder.title': 'Lembrete',
+  'push.scheduledCall.title': 'Chamada de {callType} agendada',
+  'push.scheduledCall.reminder': '{title} começa em {minutes} min',
+  'push.scheduledCall.started': '{title} começou, toque para entrar',
+  'push.callRating.title': 'Como foi sua chamada de {callType}?',
+  'push.callRating.body': 'Avalie a qualidade da chamada de 1 a 5 estrelas',
+  'push.digest.title': '{count} notificações enquanto o Não perturbe estava ativo',
+  'push.digest.more': '+{count} mais',
+  'push.login.title': 'Novo login na sua conta',
+  'push.login.body': 'Login em {device} a partir de {location}. Foi você?',
+  'push.groupReview.rejected': 'Sua mensagem não foi aprovada pelos administradores do grupo',
+  'push.groupReview.rejectedWithReason': 'Sua mensagem não foi aprovada pelos administradores do grupo: {reason}',
+
+  // Sign-in alerts
+  'login.location.unknown': 'um local desconhecido',
+  'login.device.unknown': 'um novo dispositivo',
+
+  // Reminders
+  'reminder.message': 'Lembrete: {text}',
+
+  // Appeals
+  'appeal.approved.title': 'Seu recurso foi aprovado',
+  'appeal.approved.body': 'Sua conta do {appName} foi restabelecida. Você já pode entrar novamente.',
+  'appeal.denied.title': 'Seu recurso foi negado',
+  'appeal.denied.body': 'Após análise, a suspensão da sua conta do {appName} foi mantida.',
+  'appeal.note': 'Nota da nossa equipe: {note}',
+
+  // SMS
+  'sms.otp': 'Seu código de verificação do {appName} é: {otp}\n\nEste código expira em {minutes} minutos. Não compartilhe este código com ninguém.\n\nSe você não solicitou este código, ignore esta mensagem.',
+  'sms.welcome': 'Bem-vindo ao {appName}, {userName}! 🎉\n\nConverse com amigos e família instantaneamente. Baixe nosso app e aproveite:\n- Mensagens instantâneas\n- Chamadas de voz e vídeo\n- Conversas em grupo\n- Segurança e privacidade\n\nBoas conversas!',
+  'sms.notification.footer': 'Abra o {appName} para ver mais detalhes.',
+  'sms.missedCall': '📞 Chamada {callType} perdida de {callerName}\n\nAbra o {appName} para retornar a ligação ou ver mais detalhes.',
+  'sms.groupInvite': '{inviterName} convidou você para entrar em "{groupName}" no {appName}!\n\nEntre aqui: {inviteLink}\n\nBaixe o {appName} se ainda não tiver.',
+  'sms.loginApproval': '{appName}: novo login em {device} a partir de {location}. Foi você? Aprove ou negue aqui: {link}',
+
+  // Email (shared)
+  'email.greeting': 'Olá, {userName}!',
+  'email.securityNotice': 'Aviso de segurança:',
+  'email.copyright': '© {year} {appName}. Todos os direitos reservados.',
+
+  // Email: OTP
+  'email.otp.subject': 'Seu código de verificação',
+  'email.otp.title': 'Código de verificação',
+  'email.otp.intro': 'Seu código de verificação é:',
+  'email.otp.instructions': 'Digite este código para concluir a verificação.',
+  'email.otp.expires': 'Este código expira em {minutes} minutos.',
+  'email.otp.neverShare': 'Nunca compartilhe este código com ninguém.',
+  'email.otp.ignore': 'Se você não solicitou este código, ignore este e-mail.',
+  'email.otp.text': 'Seu código de verificação é: {otp}. Este código expira em {minutes} minutos.',
+
+  // Email: welcome
+  'email.welcome.subject': 'Bem-vindo ao {appName}!',
+  'email.welcome.title': 'Bem-vindo ao {appName}!',
+  'email.welcome.intro': 'Estamos felizes em ter você na nossa comunidade! Prepare-se para se conectar com amigos e família como nunca.',
+  'email.welcome.messaging.title': 'Mensagens instantâneas',
+  'email.welcome.messaging.body': 'Envie mensagens, fotos, vídeos e áudios instantaneamente.',
+  'email.welcome.calls.title': 'Chamadas de voz e vídeo',
+  'email.welcome.calls.body': 'Faça chamadas de voz e vídeo nítidas para qualquer pessoa, em qualquer lugar.',
+  'email.welcome.groups.title': 'Conversas em grupo',
+  'email.welcome.groups.body': 'Crie grupos e converse com várias pessoas ao mesmo tempo.',
+  'email.welcome.privacy.title': 'Privacidade e segurança',
+  'email.welcome.privacy.body': 'Suas conversas são protegidas com criptografia de ponta a ponta.',
+  'email.welcome.cta': 'Comece a conversar',
+  'email.welcome.help': 'Precisa de ajuda? Fale com nossa equipe de suporte a qualquer momento.',
+
+  // Email: notification
+  'email.notification.openApp': 'Abra o app para ver mais detalhes e responder.',
+  'email.notification.manage': 'Você pode gerenciar suas preferências de notificação nas configurações do app.',
+
+  // Email: password reset
+  'email.reset.subject': 'Solicitação de redefinição de senha',
+  'email.reset.title': 'Redefinição de senha',
+  'email.reset.intro': 'Recebemos uma solicitação para redefinir sua senha. Clique no botão abaixo para criar uma nova senha:',
+  'email.reset.button': 'Redefinir senha',
+  'email.reset.expires': 'Por segurança, este link expira em 1 hora.',
+  'email.reset.ignore': 'Se você não solicitou esta redefinição, ignore este e-mail.',
+  'email.reset.copyLink': 'Se o botão não funcionar, copie e cole este link:',
+};
@@ -0,0 +1,71 @@
+import JSZip from 'jszip';
+import { ImportSource } from '../database/models/import-job';
+import { ExportFiles, ImportFormatError, NO_FILES } from './types';
+
+export interface ExportContents {
+  source: ImportSource;
+  text: string; // the chat transcript (WhatsApp .txt or Telegram result.json)
+  files: ExportFiles;
+}
+
+// Media paths in both exports are relative to the transcript's folder
+function zipFiles(zip: JSZip, baseDir: string): ExportFiles {
+  const resolve = (path: string) => zip.file(`${baseDir}${path}`);
+
+  return {
+    has: (path: string) => resolve(path) !== null,
+    read: async (path: string) => {
+      const entry = resolve(path);
+      if (!entry) {
+        throw new Error(`File not included in export: ${path}`);
+      }
+      return await entry.async('nodebuffer');
+    },
+  };
+}
+
+function dirname(path: string): string {
+  const separator = path.lastIndexOf('/');
+  return separator === -1 ? '' : path.slice(0, separator + 1);
+}
+
+// Locate the transcript in an uploaded export. Plain .txt/.json uploads are
+// the transcript itself and come without media.
+export async function openExport(buffer: Buffer, fileName: string, source: ImportSource): Promise<ExportContents> {
+  if (!fileName.toLowerCase().endsWith('.zip')) {
+    return { source, text: buffer.toString('utf8'), files: NO_FILES };
+  }
+
+  let zip: JSZip;
+  try {
+    zip = await JSZip.loadAsync(buffer);
+  } catch {
+    throw new ImportFormatError('Export archive is not a valid zip file');
+  }
+
+  const paths = Object.keys(zip.files).filter(path => !zip.files[path].dir && !path.startsWith('__MACOSX/'));
+  const transcript = source === 'telegram'
+    ? paths.find(path => /(^|\/)result\.json$/.test(path))
+    : paths.find(path => /(^|\/)_chat\.txt$/.test(path)) || paths.find(path => /\.txt$/i.test(path));
+
+  if (!transcript) {
+    throw new ImportFormatError(source === 'telegram'
+      ? 'result.json not found in export archive'
+      : 'Chat transcript not found in export archive');
+  }
+
+  return {
+    source,
+    text: await zip.file(transcript)!.async('string'),
+    files: zipFiles(zip, dirname(transcript)),
+  };
+}
+
+// Infer the source from the upload name: WhatsApp names its exports
+// "WhatsApp Chat with X", Telegram Desktop writes result.json.
+export function detectSource(fileName: string): ImportSource | null {
+  const lower = fileName.toLowerCase();
+  if (lower.endsWith('.json')) return 'telegram';
+  if (lower.endsWith('.txt') || lower.startsWith('whatsapp chat')) return 'whatsapp';
+  return null;
+}
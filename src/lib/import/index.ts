@@ -0,0 +1,443 @@
+import { Types } from 'mongoose';
+import { Readable } from 'stream';
+import { ImportJobRepository } from '../database/repositories/import-job';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { IImportJob, ImportSource } from '../database/models/import-job';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { IUser } from '../database/models/user';
+import { storageService } from '../media/storage';
+import { mediaUploadService } from '../media/upload';
+import { dataResidencyService } from '../compliance/data-residency';
+import { FileValidator } from '../media/validation';
+import { socketManager } from '../realtime/socket';
+import { jobQueue } from '../jobs';
+import { PhoneUtils } from '../utils/phone';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { detectSource, openExport } from './archive';
+import { parseTelegramExport } from './telegram';
+import { parseWhatsAppExport, titleFromFileName } from './whatsapp';
+import { ExportFiles, ImportFormatError, ParsedExport, ParsedMessage } from './types';
+
+export { ImportFormatError } from './types';
+
+export interface CreateImportJobInput {
+  stream: Readable;
+  fileName: string;
+  mimeType: string;
+  source?: ImportSource;
+  chatId?: string;
+  selfName?: string;
+  sourceChatName?: string;
+  dateFormat?: 'dmy' | 'mdy';
+}
+
+const BATCH_SIZE = 500;
+const EMPTY_PROGRESS = { totalMessages: 0, processedMessages: 0, importedMedia: 0, skippedMessages: 0 };
+
+const MIME_TYPES: Record<string, string> = {
+  '.jpg': 'image/jpeg',
+  '.jpeg': 'image/jpeg',
+  '.png': 'image/png',
+  '.gif': 'image/gif',
+  '.webp': 'image/webp',
+  '.mp4': 'video/mp4',
+  '.mov': 'video/quicktime',
+  '.webm': 'video/webm',
+  '.mp3': 'audio/mpeg',
+  '.m4a': 'audio/aac',
+  '.aac': 'audio/aac',
+  '.wav': 'audio/wav',
+  '.ogg': 'audio/ogg',
+  '.opus': 'audio/ogg',
+  '.pdf': 'application/pdf',
+  '.doc': 'application/msword',
+  '.docx': 'application/vnd.openxmlformats-officedocument.wordprocessingml.document',
+  '.xls': 'application/vnd.ms-excel',
+  '.xlsx': 'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet',
+  '.txt': 'text/plain',
+};
+
+// Imports chat history from WhatsApp (.txt/.zip) and Telegram (result.json)
+// exports. Uploads are parked in object storage and processed by the shared
+// job queue ('import:run'); progress is pushed to the importer over the
+// socket.
+export class ImportService {
+  private importJobRepository = new ImportJobRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+
+  // Store an uploaded export and queue it for processing
+  async createJob(userId: string, input: CreateImportJobInput): Promise<IImportJob> {
+    const source = input.source || detectSource(input.fileName);
+    if (!source) {
+      input.stream.resume();
+      throw new ImportError('Could not detect export format; specify source', 400);
+    }
+
+    if (input.chatId && !(await this.chatRepository.isParticipant(input.chatId, userId))) {
+      input.stream.resume();
+      throw new ImportError('Not a participant of the target chat', 403);
+    }
+
+    const upload = await storageService.uploadStream(
+      input.stream,
+      input.fileName,
+      userId,
+      { contentType: input.mimeType, region: await dataResidencyService.getUserRegion(userId) ?? undefined },
+      'import'
+    );
+
+    // Re-uploading the same export would duplicate its whole history
+    const previous = await this.importJobRepository.findCompletedByChecksum(userId, upload.checksum);
+    if (previous) {
+      await storageService.deleteFile(upload.key);
+      throw new ImportError('This export has already been imported', 409);
+    }
+
+    const job = await this.importJobRepository.create({
+      userId: new Types.ObjectId(userId),
+      source,
+      fileName: input.fileName,
+      fileKey: upload.key,
+      fileSize: upload.size,
+      checksum: upload.checksum,
+      chatId: input.chatId ? new Types.ObjectId(input.chatId) : undefined,
+      options: {
+        selfName: input.selfName,
+        sourceChatName: input.sourceChatName,
+        dateFormat: input.dateFormat,
+      },
+    });
+
+    try {
+      await jobQueue.enqueue('import:run', { importJobId: job._id.toString() });
+    } catch (error) {
+      await this.importJobRepository.update(job._id, { status: 'failed', error: 'Import failed', completedAt: new Date() });
+      await storageService.deleteFile(upload.key);
+      throw error;
+    }
+
+    metricsCollector.incrementCounter('import_jobs_created', 1, { source });
+    return job;
+  }
+
+  async getJob(jobId: string): Promise<IImportJob | null> {
+    return await this.importJobRepository.findById(jobId);
+  }
+
+  async getUserJobs(userId: string, limit?: number, offset?: number): Promise<IImportJob[]> {
+    return await this.importJobRepository.getUserJobs(userId, limit, offset);
+  }
+
+  // Run a queued import; called by the job queue. A job retried after its
+  // worker died starts over.
+  async process(importJobId: string): Promise<void> {
+    const job = await this.importJobRepository.start(importJobId, EMPTY_PROGRESS);
+    if (!job) return; // already finished
+
+    await this.run(job);
+  }
+
+  // The job queue gave up on an import whose workers kept dying
+  async abandon(importJobId: string): Promise<void> {
+    const job = await this.importJobRepository.findById(importJobId);
+    if (!job || job.status === 'completed' || job.status === 'failed') return;
+
+    await this.fail(job, 'Import failed');
+  }
+
+  private async run(job: IImportJob): Promise<void> {
+    const jobId = job._id.toString();
+    const userId = job.userId.toString();
+    const startTime = Date.now();
+
+    try {
+      // A retried job starts over; drop anything a previous attempt inserted
+      await this.messageRepository.deleteByImportJob(job._id);
+
+      const importer = await this.userRepository.findById(job.userId);
+      if (!importer) {
+        throw new ImportFormatError('Importing user no longer exists');
+      }
+
+      const contents = await openExport(await storageService.getFileBuffer(job.fileKey), job.fileName, job.source);
+      const parsed = this.parse(job, contents.text, contents.files);
+      if (parsed.messages.length === 0) {
+        throw new ImportFormatError('Export contains no importable messages');
+      }
+
+      const progress = {
+        totalMessages: parsed.messages.length,
+        processedMessages: 0,
+        importedMedia: 0,
+        skippedMessages: parsed.skipped,
+      };
+      await this.reportProgress(job, progress);
+
+      const senders = await this.resolveSenders(job, parsed, importer);
+      const chat = await this.resolveChat(job, parsed, importer, senders);
+
+      let lastMessageId: Types.ObjectId | undefined;
+      let importedMessages = 0;
+
+      for (let offset = 0; offset < parsed.messages.length; offset += BATCH_SIZE) {
+        const batch: Partial<IMessage>[] = [];
+
+        for (const entry of parsed.messages.slice(offset, offset + BATCH_SIZE)) {
+          const senderId = senders.get(entry.sender) || importer._id;
+          const media = entry.attachment
+            ? await this.uploadAttachment(entry, contents.files, senderId.toString(), chat._id.toString())
+            : null;
+
+          if (entry.attachment && media) {
+            progress.importedMedia++;
+          }
+          if (!entry.content && !media) {
+            progress.skippedMessages++;
+            continue;
+          }
+
+          batch.push({
+            chatId: chat._id,
+            senderId,
+            content: entry.content || media?.name || entry.attachment || '',
+            type: media?.type || 'text',
+            media: media?.id,
+            status: 'read',
+            isImported: true,
+            importInfo: {
+              jobId: job._id,
+              source: job.source,
+              originalSender: entry.sender,
+            },
+            createdAt: entry.timestamp,
+            updatedAt: entry.timestamp,
+          });
+        }
+
+        if (batch.length > 0) {
+          const inserted = await this.messageRepository.createMany(batch);
+          lastMessageId = inserted[inserted.length - 1]._id;
+          importedMessages += inserted.length;
+        }
+
+        progress.processedMessages = Math.min(offset + BATCH_SIZE, parsed.messages.length);
+        await this.reportProgress(job, progress);
+      }
+
+      if (lastMessageId) {
+        await this.chatRepository.updateLastActivity(chat._id, lastMessageId);
+      }
+
+      await this.importJobRepository.update(job._id, {
+        status: 'completed',
+        completedAt: new Date(),
+        progress,
+      } as Partial<IImportJob>);
+      await storageService.deleteFile(job.fileKey);
+
+      socketManager.emitToUser(userId, 'import:completed', {
+        jobId,
+        chatId: chat._id.toString(),
+        importedMessages,
+        importedMedia: progress.importedMedia,
+        skippedMessages: progress.skippedMessages,
+      });
+
+      metricsCollector.incrementCounter('import_jobs_completed', 1, { source: job.source });
+      metricsCollector.recordGauge('import_job_duration', Date.now() - startTime, { source: job.source });
+      logger.info('Import job completed', { jobId, userId, source: job.source, messages: progress.totalMessages });
+    } catch (error) {
+      const message = error instanceof ImportFormatError ? error.message : 'Import failed';
+      if (!(error instanceof ImportFormatError)) {
+        logger.error('Import job failed', error, { jobId, userId });
+      }
+
+      await this.fail(job, message);
+    }
+  }
+
+  private async fail(job: IImportJob, message: string): Promise<void> {
+    await this.messageRepository.deleteByImportJob(job._id);
+    await this.importJobRepository.update(job._id, {
+      status: 'failed',
+      error: message,
+      completedAt: new Date(),
+    });
+    await storageService.deleteFile(job.fileKey);
+
+    socketManager.emitToUser(job.userId.toString(), 'import:failed', { jobId: job._id.toString(), error: message });
+    metricsCollector.incrementCounter('import_jobs_failed', 1, { source: job.source });
+  }
+
+  private parse(job: IImportJob, text: string, files: ExportFiles): ParsedExport {
+    if (job.source === 'telegram') {
+      return parseTelegramExport(text, files, { sourceChatName: job.options?.sourceChatName });
+    }
+    return parseWhatsAppExport(text, files, {
+      title: titleFromFileName(job.fileName),
+      dateFormat: job.options?.dateFormat,
+    });
+  }
+
+  // Map export sender names to local users: the importer by name, contacts
+  // exported as phone numbers by lookup, and existing chat members by display
+  // name. Unresolved senders are attributed to the importer and keep their
+  // original name in importInfo.
+  private async resolveSenders(
+    job: IImportJob,
+    parsed: ParsedExport,
+    importer: IUser
+  ): Promise<Map<string, Types.ObjectId>> {
+    const selfNames = [job.options?.selfName, importer.displayName]
+      .filter((name): name is string => !!name)
+      .map(name => name.toLowerCase());
+
+    // Participants come populated with their display names
+    const participants: any[] = job.chatId ? (await this.chatRepository.findById(job.chatId))?.participants || [] : [];
+    const members = new Map<string, Types.ObjectId>(
+      participants.map(participant => [participant.displayName?.toLowerCase(), participant._id])
+    );
+
+    const senders = new Map<string, Types.ObjectId>();
+    for (const name of new Set(parsed.messages.map(message => message.sender))) {
+      if (selfNames.includes(name.toLowerCase())) {
+        senders.set(name, importer._id);
+        continue;
+      }
+
+      const phoneNumber = PhoneUtils.normalize(name, importer.countryCode);
+      const user = phoneNumber ? await this.userRepository.findByPhoneNumber(phoneNumber) : null;
+      if (user && (!job.chatId || participants.some(participant => participant._id.equals(user._id)))) {
+        senders.set(name, user._id);
+        continue;
+      }
+
+      const member = members.get(name.toLowerCase());
+      if (member) {
+        senders.set(name, member);
+      }
+    }
+
+    return senders;
+  }
+
+  // Use the requested chat or create one holding the importer and every
+  // sender resolved to a local user
+  private async resolveChat(
+    job: IImportJob,
+    parsed: ParsedExport,
+    importer: IUser,
+    senders: Map<string, Types.ObjectId>
+  ): Promise<IChat> {
+    if (job.chatId) {
+      const chat = await this.chatRepository.findById(job.chatId);
+      if (!chat) {
+        throw new ImportFormatError('Target chat no longer exists');
+      }
+      return chat;
+    }
+
+    const participants = [importer._id];
+    for (const userId of senders.values()) {
+      if (!participants.some(id => id.equals(userId))) {
+        participants.push(userId);
+      }
+    }
+
+    let chat: IChat | null = null;
+    if (participants.length === 2 && !parsed.isGroup) {
+      chat = await this.chatRepository.findDirectChat(participants[0], participants[1]);
+      chat = chat || await this.chatRepository.create({ participants, type: 'direct' });
+    } else {
+      chat = await this.chatRepository.create({
+        participants,
+        type: 'group',
+        groupInfo: {
+          name: (parsed.title || `Imported ${job.source === 'telegram' ? 'Telegram' : 'WhatsApp'} chat`).slice(0, 100),
+          admins: [importer._id],
+        } as IChat['groupInfo'],
+      });
+    }
+
+    // Retries reuse the chat rather than creating another
+    await this.importJobRepository.update(job._id, { chatId: chat._id });
+    job.chatId = chat._id;
+    return chat;
+  }
+
+  private async uploadAttachment(
+    entry: ParsedMessage,
+    files: ExportFiles,
+    uploadedBy: string,
+    chatId: string
+  ): Promise<{ id: Types.ObjectId; type: IMessage['type']; name: string } | null> {
+    const path = entry.attachment!;
+    let name = path.split('/').pop() || path;
+    let extension = FileValidator.getFileExtension(name).toLowerCase();
+
+    // WhatsApp voice notes are Ogg/Opus
+    const isVoiceNote = extension === '.opus';
+    if (isVoiceNote) {
+      name = `${name.slice(0, -extension.length)}.ogg`;
+      extension = '.ogg';
+    }
+
+    const mimeType = MIME_TYPES[extension] || 'application/octet-stream';
+    const type = isVoiceNote ? 'voice' : FileValidator.detectFileType(mimeType, extension);
+
+    try {
+      const { media } = await mediaUploadService.uploadFile(
+        await files.read(path),
+        name,
+        mimeType,
+        uploadedBy,
+        type,
+        { chatId }
+      );
+      return { id: media._id, type: media.type, name };
+    } catch (error) {
+      logger.warn('Skipping import attachment', { path, error: error instanceof Error ? error.message : String(error) });
+      return null;
+    }
+  }
+
+  private async reportProgress(job: IImportJob, progress: IImportJob['progress']): Promise<void> {
+    await this.importJobRepository.update(job._id, { progress } as Partial<IImportJob>);
+    socketManager.emitToUser(job.userId.toString(), 'import:progress', {
+      jobId: job._id.toString(),
+      ...progress,
+    });
+  }
+}
+
+// Client-facing view of an import job
+export function serializeImportJob(job: IImportJob) {
+  return {
+    id: job._id.toString(),
+    source: job.source,
+    status: job.status,
+    fileName: job.fileName,
+    fileSize: job.fileSize,
+    chatId: job.chatId?.toString(),
+    progress: job.progress,
+    error: job.error,
+    createdAt: job.createdAt,
+    startedAt: job.startedAt,
+    completedAt: job.completedAt,
+  };
+}
+
+export class ImportError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ImportError';
+  }
+}
+
+export const importService = new ImportService();
@@ -0,0 +1,122 @@
+import { ExportFiles, ImportFormatError, ParsedExport, ParsedMessage } from './types';
+
+interface TelegramTextEntity {
+  type: string;
+  text: string;
+}
+
+interface TelegramMessage {
+  id: number;
+  type: string; // 'message' or 'service'
+  date: string;
+  date_unixtime?: string;
+  from?: string | null;
+  from_id?: string;
+  text?: string | (string | TelegramTextEntity)[];
+  photo?: string;
+  file?: string;
+}
+
+interface TelegramChat {
+  name?: string;
+  type?: string;
+  id?: number;
+  messages?: TelegramMessage[];
+}
+
+const GROUP_CHAT_TYPES = ['private_group', 'private_supergroup', 'public_supergroup', 'private_channel', 'public_channel'];
+
+// Telegram writes this placeholder when media was excluded from the export
+const FILE_NOT_INCLUDED = /^\(File not included/;
+
+function flattenText(text: TelegramMessage['text']): string {
+  if (!text) return '';
+  if (typeof text === 'string') return text;
+  return text.map(part => (typeof part === 'string' ? part : part.text)).join('');
+}
+
+function toDate(message: TelegramMessage): Date {
+  if (message.date_unixtime) {
+    return new Date(parseInt(message.date_unixtime, 10) * 1000);
+  }
+  // Older exports only carry local time without an offset; taken as UTC
+  return new Date(`${message.date}Z`);
+}
+
+// A full account export holds every chat under chats.list; a single chat
+// export is the chat object itself.
+function selectChat(data: any, sourceChatName?: string): TelegramChat {
+  if (Array.isArray(data?.messages)) {
+    return data;
+  }
+
+  const chats: TelegramChat[] = data?.chats?.list;
+  if (!Array.isArray(chats) || chats.length === 0) {
+    throw new ImportFormatError('No Telegram chats found in export');
+  }
+
+  if (!sourceChatName) {
+    if (chats.length === 1) return chats[0];
+    throw new ImportFormatError('Export contains several chats; sourceChatName is required');
+  }
+
+  const chat = chats.find(candidate => candidate.name?.toLowerCase() === sourceChatName.toLowerCase());
+  if (!chat) {
+    throw new ImportFormatError(`Chat "${sourceChatName}" not found in export`);
+  }
+  return chat;
+}
+
+// Parse a Telegram Desktop JSON export (result.json)
+export function parseTelegramExport(
+  json: string,
+  files: ExportFiles,
+  options: { sourceChatName?: string } = {}
+): ParsedExport {
+  let data: any;
+  try {
+    data = JSON.parse(json);
+  } catch {
+    throw new ImportFormatError('Telegram export is not valid JSON');
+  }
+
+  const chat = selectChat(data, options.sourceChatName);
+  const messages: ParsedMessage[] = [];
+  const senders = new Set<string>();
+  let skipped = 0;
+
+  for (const entry of chat.messages || []) {
+    // Service entries are joins, pins, title changes...
+    if (entry.type !== 'message' || !entry.from) {
+      skipped++;
+      continue;
+    }
+
+    const mediaPath = entry.photo || entry.file;
+    const attachment = mediaPath && !FILE_NOT_INCLUDED.test(mediaPath) && files.has(mediaPath)
+      ? mediaPath
+      : undefined;
+    const content = flattenText(entry.text);
+
+    if (!content && !attachment) {
+      skipped++;
+      continue;
+    }
+
+    senders.add(entry.from_id || entry.from);
+    messages.push({
+      timestamp: toDate(entry),
+      sender: entry.from,
+      content,
+      attachment,
+    });
+  }
+
+  return {
+    source: 'telegram',
+    title: chat.name,
+    isGroup: GROUP_CHAT_TYPES.includes(chat.type || '') || senders.size > 2,
+    messages,
+    skipped,
+  };
+}
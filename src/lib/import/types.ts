@@ -0,0 +1,36 @@
+import { ImportSource } from '../database/models/import-job';
+
+export interface ParsedMessage {
+  timestamp: Date;
+  sender: string;
+  content: string;
+  attachment?: string; // path of the media file inside the export archive
+}
+
+export interface ParsedExport {
+  source: ImportSource;
+  title?: string;
+  isGroup: boolean;
+  messages: ParsedMessage[];
+  skipped: number; // system lines, omitted media and other unimportable entries
+}
+
+// Lazy access to files bundled with the export (zip uploads only)
+export interface ExportFiles {
+  has(path: string): boolean;
+  read(path: string): Promise<Buffer>;
+}
+
+export const NO_FILES: ExportFiles = {
+  has: () => false,
+  read: async (path: string) => {
+    throw new Error(`File not included in export: ${path}`);
+  },
+};
+
+export class ImportFormatError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'ImportFormatError';
+  }
+}
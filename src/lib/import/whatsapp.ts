@@ -0,0 +1,134 @@
+import { ExportFiles, ImportFormatError, ParsedExport, ParsedMessage } from './types';
+
+// Android: "31/12/20, 21:41 - Name: text" or "12/31/20, 9:41 PM - Name: text"
+const ANDROID_LINE = /^(\d{1,2})[/.-](\d{1,2})[/.-](\d{2,4}),? (\d{1,2})[:.](\d{2})(?:[:.](\d{2}))?\s?([AaPp]\.?[Mm]\.?)? - (.*)$/;
+// iOS: "[31/12/2020, 21:41:05] Name: text"
+const IOS_LINE = /^\[(\d{1,2})[/.-](\d{1,2})[/.-](\d{2,4}),? (\d{1,2})[:.](\d{2})(?:[:.](\d{2}))?\s?([AaPp]\.?[Mm]\.?)?\] (.*)$/;
+
+const IOS_ATTACHMENT = /^<attached: (.+)>$/;
+const ANDROID_ATTACHMENT = /^(.+?) \(file attached\)$/;
+const MEDIA_OMITTED = /^<(Media omitted|media omitted)>$/;
+
+// Invisible marks WhatsApp sprinkles into exports
+const INVISIBLE = /[\u200e\u200f\u202a-\u202e]/g;
+
+interface RawLine {
+  day: number;
+  month: number;
+  year: number;
+  hour: number;
+  minute: number;
+  second: number;
+  meridiem?: string;
+  rest: string;
+}
+
+function matchHeader(line: string): RawLine | null {
+  const match = ANDROID_LINE.exec(line) || IOS_LINE.exec(line);
+  if (!match) return null;
+
+  const [, first, second, year, hour, minute, secondOfMinute, meridiem, rest] = match;
+  return {
+    day: parseInt(first, 10),
+    month: parseInt(second, 10),
+    year: parseInt(year, 10),
+    hour: parseInt(hour, 10),
+    minute: parseInt(minute, 10),
+    second: secondOfMinute ? parseInt(secondOfMinute, 10) : 0,
+    meridiem: meridiem?.replace(/\./g, '').toLowerCase(),
+    rest,
+  };
+}
+
+// Exports use the phone's locale, so "03/04/21" is ambiguous. Any component
+// above 12 settles it for the whole file; otherwise fall back to day-first.
+function detectDateFormat(lines: RawLine[]): 'dmy' | 'mdy' {
+  if (lines.some(line => line.day > 12)) return 'dmy';
+  if (lines.some(line => line.month > 12)) return 'mdy';
+  return 'dmy';
+}
+
+function toDate(line: RawLine, format: 'dmy' | 'mdy'): Date {
+  const [day, month] = format === 'dmy' ? [line.day, line.month] : [line.month, line.day];
+  const year = line.year < 100 ? 2000 + line.year : line.year;
+
+  let hour = line.hour;
+  if (line.meridiem === 'pm' && hour < 12) hour += 12;
+  if (line.meridiem === 'am' && hour === 12) hour = 0;
+
+  // Exports carry no timezone; timestamps are taken as UTC
+  return new Date(Date.UTC(year, month - 1, day, hour, line.minute, line.second));
+}
+
+// Parse a WhatsApp "Export chat" text file. Attachments are resolved against
+// the files bundled in the zip when present.
+export function parseWhatsAppExport(
+  text: string,
+  files: ExportFiles,
+  options: { title?: string; dateFormat?: 'dmy' | 'mdy' } = {}
+): ParsedExport {
+  const entries: RawLine[] = [];
+
+  for (const rawLine of text.replace(INVISIBLE, '').replace(/[\u00a0\u202f]/g, ' ').split(/\r?\n/)) {
+    const header = matchHeader(rawLine);
+    if (header) {
+      entries.push(header);
+    } else if (entries.length > 0) {
+      // Continuation of a multi-line message
+      entries[entries.length - 1].rest += `\n${rawLine}`;
+    }
+  }
+
+  if (entries.length === 0) {
+    throw new ImportFormatError('No WhatsApp messages found in export');
+  }
+
+  const format = options.dateFormat || detectDateFormat(entries);
+  const messages: ParsedMessage[] = [];
+  const senders = new Set<string>();
+  let skipped = 0;
+
+  for (const entry of entries) {
+    const separator = entry.rest.indexOf(': ');
+    // Lines without "Name: " are system notices (joins, encryption banner...)
+    if (separator <= 0) {
+      skipped++;
+      continue;
+    }
+
+    const sender = entry.rest.slice(0, separator).trim();
+    const body = entry.rest.slice(separator + 2).trim();
+
+    if (MEDIA_OMITTED.test(body)) {
+      skipped++;
+      continue;
+    }
+
+    const [firstLine, ...captionLines] = body.split('\n');
+    const attachmentName = IOS_ATTACHMENT.exec(firstLine)?.[1] ||
+      ANDROID_ATTACHMENT.exec(firstLine)?.[1] ||
+      (files.has(firstLine.trim()) ? firstLine.trim() : undefined);
+
+    senders.add(sender);
+    messages.push({
+      timestamp: toDate(entry, format),
+      sender,
+      content: attachmentName ? captionLines.join('\n').trim() : body,
+      attachment: attachmentName && files.has(attachmentName) ? attachmentName : undefined,
+    });
+  }
+
+  return {
+    source: 'whatsapp',
+    title: options.title,
+    isGroup: senders.size > 2,
+    messages,
+    skipped,
+  };
+}
+
+// "WhatsApp Chat with Alice.txt" -> "Alice"
+export function titleFromFileName(fileName: string): string | undefined {
+  const match = /WhatsApp Chat (?:with|-) (.+?)(?:\.txt|\.zip)?$/i.exec(fileName);
+  return match?.[1];
+}
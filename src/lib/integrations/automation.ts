@@ -0,0 +1,557 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { redisConfig } from '../config/redis';
+import { AutomationRuleRepository } from '../database/repositories/automation-rule';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { ReportRepository } from '../database/repositories/report';
+import { UserRepository } from '../database/repositories/user';
+import {
+  IAutomationRule,
+  IAutomationAction,
+  IAutomationCondition,
+  AutomationTrigger,
+  AutomationActionType,
+  AutomationConditionField,
+} from '../database/models/automation-rule';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { IReport } from '../database/models/report';
+import { IUser } from '../database/models/user';
+import {
+  CreateAutomationRuleInput,
+  UpdateAutomationRuleInput,
+  AutomationTriggerInput,
+  AutomationActionInput,
+} from '../database/schemas/automation';
+import { moderationService } from '../moderation/actions';
+import { socketManager } from '../realtime/socket';
+import { CryptoUtils } from '../utils/crypto';
+import { AUTOMATION_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const DAY = 24 * 60 * 60 * 1000;
+const RATE_WINDOW_SECONDS = 60;
+
+// findById populates users, so accept either form
+function refId(ref: any): string {
+  return (ref._id || ref).toString();
+}
+
+// An event rules are evaluated against. user is whoever the event is
+// about: the sender, the new member or the reported user.
+export interface AutomationEvent {
+  event: AutomationTrigger;
+  chat?: IChat;
+  user?: IUser;
+  message?: IMessage;
+  report?: IReport;
+}
+
+function conditionFields(event: AutomationEvent): Record<AutomationConditionField, unknown> {
+  const { chat, user, message, report } = event;
+  return {
+    'chat.type': chat?.type,
+    'chat.memberCount': chat?.participants.length,
+    'user.isVerified': user?.isVerified,
+    'user.accountAgeDays': user && Math.floor((Date.now() - user.createdAt.getTime()) / DAY),
+    'user.countryCode': user?.countryCode,
+    'message.type': message?.type,
+    'message.length': message?.content?.length,
+    'message.hasMedia': message && !!message.media,
+    'report.type': report?.type,
+    'report.priority': report?.priority,
+  };
+}
+
+// A fact the event doesn't have only satisfies not_equals and not_in
+function holds(condition: IAutomationCondition, actual: unknown): boolean {
+  const { operator, value } = condition;
+  if (actual === undefined || actual === null) {
+    return operator === 'not_equals' || operator === 'not_in';
+  }
+
+  switch (operator) {
+    case 'equals':
+      return actual === value;
+    case 'not_equals':
+      return actual !== value;
+    case 'in':
+      return Array.isArray(value) && value.includes(actual as string | number);
+    case 'not_in':
+      return Array.isArray(value) && !value.includes(actual as string | number);
+    case 'gt':
+      return typeof actual === 'number' && actual > (value as number);
+    case 'lt':
+      return typeof actual === 'number' && actual < (value as number);
+  }
+}
+
+// Admin "if this then that" rules, run server-side. A rule has a trigger
+// (a message in scope matches a pattern, someone joins a group, a report is
+// filed), conditions on the chat, user, message or report, and actions run
+// in order: post a message as the rule's bot, label the message or report,
+// POST a signed notification to a webhook, or ban or restrict the user.
+//
+// Limits keep a bad rule from doing much harm: patterns are checked for
+// catastrophic backtracking when saved and only see the first
+// MAX_MATCH_LENGTH characters, each rule runs at most MAX_RUNS_PER_MINUTE
+// times, webhooks get WEBHOOK_TIMEOUT, and a rule that fails
+// MAX_CONSECUTIVE_FAILURES times in a row is turned off. Messages from
+// bridged and bot accounts never trigger rules, so rules can't set each
+// other off. Every match is counted on the rule for its stats.
+//
+// Enabled rules are cached for RULE_CACHE_TTL; other instances see changes
+// once their cache expires.
+export class AutomationService {
+  private automationRuleRepository = new AutomationRuleRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private reportRepository = new ReportRepository();
+  private userRepository = new UserRepository();
+  private redis = redisConfig.getClient();
+  private memoryCounters = new Map<string, { count: number; resetAt: number }>();
+  private rules = new Map<AutomationTrigger, { rules: IAutomationRule[]; loadedAt: number }>();
+  private patterns = new Map<string, RegExp>();
+
+  // A message was delivered to its chat
+  async messageCreated(message: IMessage): Promise<void> {
+    const rules = await this.getRules('message_created');
+    if (rules.length === 0 || message.isImported) return;
+    if (message.moderation && message.moderation.state !== 'approved') return;
+
+    const [chat, user] = await Promise.all([
+      this.chatRepository.findById(message.chatId),
+      this.userRepository.findById(refId(message.senderId)),
+    ]);
+    if (!chat || !user || user.bridge) return;
+
+    await this.evaluate(rules, { event: 'message_created', chat, user, message });
+  }
+
+  // Users were added to or joined a group
+  async membersJoined(chatId: string, userIds: string[]): Promise<void> {
+    const rules = await this.getRules('member_joined');
+    if (rules.length === 0) return;
+
+    const chat = await this.chatRepository.findById(chatId);
+    if (!chat) return;
+
+    for (const userId of userIds) {
+      const user = await this.userRepository.findById(userId);
+      if (!user || user.bridge) continue;
+      await this.evaluate(rules, { event: 'member_joined', chat, user });
+    }
+  }
+
+  // A user reported someone or something. Called by reportService once the
+  // report is saved.
+  async reportFiled(report: IReport): Promise<void> {
+    const rules = await this.getRules('report_filed');
+    if (rules.length === 0) return;
+
+    const [user, chat, message] = await Promise.all([
+      report.reportedUserId ? this.userRepository.findById(report.reportedUserId) : null,
+      report.reportedChatId ? this.chatRepository.findById(report.reportedChatId) : null,
+      report.reportedMessageId ? this.messageRepository.findById(report.reportedMessageId) : null,
+    ]);
+
+    await this.evaluate(rules, {
+      event: 'report_filed',
+      report,
+      user: user || undefined,
+      chat: chat || undefined,
+      message: message || undefined,
+    });
+  }
+
+  async list(filter: { event?: AutomationTrigger; enabled?: boolean }, limit: number = 50, offset: number = 0) {
+    return await this.automationRuleRepository.list(filter, limit, offset);
+  }
+
+  async get(ruleId: string): Promise<IAutomationRule> {
+    const rule = Types.ObjectId.isValid(ruleId) ? await this.automationRuleRepository.findById(ruleId) : null;
+    if (!rule) {
+      throw new AutomationError('Rule not found', 404);
+    }
+    return rule;
+  }
+
+  // The signing secret is only returned here and on rotation
+  async create(adminId: string, input: CreateAutomationRuleInput): Promise<IAutomationRule> {
+    if (await this.automationRuleRepository.count() >= AUTOMATION_CONSTANTS.MAX_RULES) {
+      throw new AutomationError(`There can be at most ${AUTOMATION_CONSTANTS.MAX_RULES} rules`, 409);
+    }
+    await this.validate(input.trigger, input.conditions, input.actions);
+
+    const rule = await this.automationRuleRepository.create({
+      name: input.name,
+      description: input.description,
+      enabled: input.enabled,
+      trigger: toTrigger(input.trigger),
+      conditions: input.conditions,
+      actions: input.actions.map(toAction),
+      signingSecret: crypto.randomBytes(32).toString('hex'),
+      createdBy: new Types.ObjectId(adminId),
+    });
+
+    this.rules.clear();
+    await this.audit(adminId, 'create', rule, { name: rule.name, event: rule.trigger.event });
+    return rule;
+  }
+
+  async update(ruleId: string, adminId: string, input: UpdateAutomationRuleInput): Promise<IAutomationRule> {
+    const rule = await this.get(ruleId);
+    const trigger = input.trigger || rule.trigger;
+    const conditions = input.conditions || rule.conditions;
+    const actions = input.actions || rule.actions;
+    if (input.trigger || input.conditions || input.actions) {
+      await this.validate(trigger, conditions, actions);
+    }
+
+    // Turning a rule back on gives it a fresh start
+    const reenabled = input.enabled === true && !rule.enabled;
+    const updated = await this.automationRuleRepository.update(rule._id, {
+      ...(input.name && { name: input.name }),
+      ...(input.description !== undefined && { description: input.description ?? undefined }),
+      ...(input.enabled !== undefined && { enabled: input.enabled }),
+      ...(input.trigger && { trigger: toTrigger(input.trigger) }),
+      ...(input.conditions && { conditions: input.conditions }),
+      ...(input.actions && { actions: input.actions.map(toAction) }),
+      ...(input.rotateSecret && { signingSecret: crypto.randomBytes(32).toString('hex') }),
+      updatedBy: new Types.ObjectId(adminId),
+      ...(reenabled && { 'stats.consecutiveFailures': 0, $unset: { disabledReason: 1 } }),
+    } as Partial<IAutomationRule>);
+    if (!updated) {
+      throw new AutomationError('Rule not found', 404);
+    }
+
+    this.rules.clear();
+    await this.audit(adminId, 'update', updated, {
+      fields: Object.keys(input).filter(key => input[key as keyof UpdateAutomationRuleInput] !== undefined),
+    });
+    return updated;
+  }
+
+  async delete(ruleId: string, adminId: string): Promise<void> {
+    const rule = await this.get(ruleId);
+    await this.automationRuleRepository.delete(rule._id);
+
+    this.rules.clear();
+    await this.audit(adminId, 'delete', rule, { name: rule.name });
+  }
+
+  // Run every rule for an event, one after the other. A rule that fails
+  // doesn't stop the others.
+  private async evaluate(rules: IAutomationRule[], event: AutomationEvent): Promise<void> {
+    const fields = conditionFields(event);
+
+    for (const rule of rules) {
+      if (!this.inScope(rule, event)) continue;
+      if (!rule.conditions.every(condition => holds(condition, fields[condition.field]))) continue;
+
+      try {
+        await this.run(rule, event);
+      } catch (error) {
+        logger.error('Automation rule evaluation failed', error, { ruleId: rule._id.toString() });
+      }
+    }
+  }
+
+  private async run(rule: IAutomationRule, event: AutomationEvent): Promise<void> {
+    const ruleId = rule._id.toString();
+
+    if (await this.consumeRateLimit(ruleId)) {
+      await this.automationRuleRepository.recordRun(rule._id, 'throttled');
+      metricsCollector.incrementCounter('automation_rule_runs', 1, { outcome: 'throttled' });
+      return;
+    }
+
+    try {
+      for (const action of rule.actions) {
+        await this.perform(rule, action, event);
+      }
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      const updated = await this.automationRuleRepository.recordRun(rule._id, 'failed', message.slice(0, 500));
+      metricsCollector.incrementCounter('automation_rule_runs', 1, { outcome: 'failed' });
+      logger.warn('Automation rule failed', { ruleId, event: event.event, error: message });
+
+      if (updated && updated.stats.consecutiveFailures >= AUTOMATION_CONSTANTS.MAX_CONSECUTIVE_FAILURES) {
+        await this.automationRuleRepository.disable(rule._id, `Failed ${updated.stats.consecutiveFailures} times in a row: ${message.slice(0, 200)}`);
+        this.rules.clear();
+        logger.warn('Automation rule turned off after repeated failures', { ruleId });
+      }
+      return;
+    }
+
+    await this.automationRuleRepository.recordRun(rule._id, 'executed');
+    metricsCollector.incrementCounter('automation_rule_runs', 1, { outcome: 'executed' });
+  }
+
+  // Throws when the action can't be carried out; later actions are skipped
+  private async perform(rule: IAutomationRule, action: IAutomationAction, event: AutomationEvent): Promise<void> {
+    switch (action.type) {
+      case 'send_message':
+        return await this.sendMessage(rule, action, event);
+      case 'add_label':
+        if (event.report) {
+          await this.reportRepository.addLabel(event.report._id, action.label!);
+        } else if (event.message) {
+          await this.messageRepository.addLabel(event.message._id, action.label!);
+        } else {
+          throw new Error('Nothing to label');
+        }
+        return;
+      case 'notify_webhook':
+        return await this.notify(rule, action.url!, event);
+      case 'apply_moderation':
+        return await this.moderate(rule, action.moderation!, event);
+    }
+  }
+
+  // Posted by the rule's own bot account. {{user}} is replaced with the
+  // display name of the user the event is about.
+  private async sendMessage(rule: IAutomationRule, action: IAutomationAction, event: AutomationEvent): Promise<void> {
+    const chat = action.chatId ? await this.chatRepository.findById(action.chatId) : event.chat;
+    if (!chat) {
+      throw new Error('Chat not found');
+    }
+
+    const bot = await this.userRepository.findOrCreateBridgeUser('bot', `automation:${rule._id.toString()}`, rule.name);
+    const message = await this.messageRepository.create({
+      chatId: chat._id,
+      senderId: bot._id,
+      content: action.text!.replace(/\{\{\s*user\s*\}\}/g, event.user?.displayName || 'someone'),
+      type: 'text',
+    });
+
+    await this.chatRepository.updateLastActivity(chat._id, message._id);
+
+    const populatedMessage = await this.messageRepository.findById(message._id);
+    socketManager.emitToChat(chat._id.toString(), 'message:new', populatedMessage);
+  }
+
+  // IDs only; the receiver fetches what it is allowed to see. Signed like
+  // slash command invocations, with the rule's signing secret.
+  private async notify(rule: IAutomationRule, url: string, event: AutomationEvent): Promise<void> {
+    const timestamp = Math.floor(Date.now() / 1000).toString();
+    const body = JSON.stringify({
+      rule: { id: rule._id.toString(), name: rule.name },
+      event: event.event,
+      chat_id: event.chat?._id.toString(),
+      user_id: event.user?._id.toString(),
+      message_id: event.message?._id.toString(),
+      report_id: event.report?._id.toString(),
+      occurred_at: new Date().toISOString(),
+    });
+
+    const response = await fetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        'X-Automation-Timestamp': timestamp,
+        'X-Automation-Signature': `v1=${CryptoUtils.hmac(`v1:${timestamp}:${body}`, rule.signingSecret)}`,
+      },
+      body,
+      redirect: 'manual',
+      signal: AbortSignal.timeout(AUTOMATION_CONSTANTS.WEBHOOK_TIMEOUT),
+    });
+
+    if (!response.ok) {
+      throw new Error(`Webhook returned status ${response.status}`);
+    }
+  }
+
+  // Issued in the name of the admin who created the rule. Users already
+  // banned, or already restricted, are left alone.
+  private async moderate(
+    rule: IAutomationRule,
+    moderation: NonNullable<IAutomationAction['moderation']>,
+    event: AutomationEvent
+  ): Promise<void> {
+    const user = event.user;
+    if (!user) {
+      throw new Error('No user to moderate');
+    }
+
+    const userId = user._id.toString();
+    const input = {
+      reason: moderation.reason,
+      note: `Automation rule "${rule.name}"`,
+      durationHours: moderation.durationHours,
+      ...(event.report && { reportId: event.report._id.toString() }),
+    };
+
+    if (moderation.action === 'ban') {
+      if (user.isBanned) return;
+      await moderationService.ban(userId, input, rule.createdBy.toString());
+    } else {
+      if (await moderationService.getRestriction(userId)) return;
+      await moderationService.restrict(userId, { ...input, mode: moderation.mode || 'shadow' }, rule.createdBy.toString());
+    }
+  }
+
+  private inScope(rule: IAutomationRule, event: AutomationEvent): boolean {
+    const { trigger } = rule;
+    if (trigger.chatId && trigger.chatId.toString() !== event.chat?._id.toString()) return false;
+    if (trigger.reportTypes?.length && !trigger.reportTypes.includes(event.report?.type as string)) return false;
+    if (trigger.pattern && !this.matches(trigger.pattern, event.message?.content)) return false;
+    return true;
+  }
+
+  private matches(pattern: NonNullable<IAutomationRule['trigger']['pattern']>, content?: string): boolean {
+    if (!content) return false;
+    const text = content.slice(0, AUTOMATION_CONSTANTS.MAX_MATCH_LENGTH);
+
+    if (pattern.type === 'contains') {
+      return pattern.caseSensitive
+        ? text.includes(pattern.value)
+        : text.toLowerCase().includes(pattern.value.toLowerCase());
+    }
+
+    const flags = pattern.caseSensitive ? '' : 'i';
+    const key = `${flags}/${pattern.value}`;
+    let regex = this.patterns.get(key);
+    if (!regex) {
+      try {
+        regex = new RegExp(pattern.value, flags);
+      } catch {
+        return false;
+      }
+      this.patterns.set(key, regex);
+    }
+    return regex.test(text);
+  }
+
+  // Enabled rules for a trigger, cached
+  private async getRules(event: AutomationTrigger): Promise<IAutomationRule[]> {
+    const cached = this.rules.get(event);
+    if (cached && Date.now() - cached.loadedAt < AUTOMATION_CONSTANTS.RULE_CACHE_TTL) {
+      return cached.rules;
+    }
+
+    const rules = await this.automationRuleRepository.findEnabled(event);
+    this.rules.set(event, { rules, loadedAt: Date.now() });
+    return rules;
+  }
+
+  // Checks the schema can't express: which facts and actions make sense for
+  // the trigger, and that the chats named exist
+  private async validate(
+    trigger: { event: AutomationTrigger; chatId?: string | Types.ObjectId },
+    conditions: { field: string }[],
+    actions: { type: AutomationActionType; chatId?: string | Types.ObjectId }[]
+  ): Promise<void> {
+    for (const condition of conditions) {
+      const subject = condition.field.split('.')[0];
+      if ((subject === 'report' && trigger.event !== 'report_filed') || (subject === 'message' && trigger.event === 'member_joined')) {
+        throw new AutomationError(`${condition.field} is not known for ${trigger.event} rules`, 400);
+      }
+    }
+
+    for (const action of actions) {
+      if (action.type === 'add_label' && trigger.event === 'member_joined') {
+        throw new AutomationError('member_joined rules have no message or report to label', 400);
+      }
+      if (action.type === 'send_message' && trigger.event === 'report_filed' && !action.chatId) {
+        throw new AutomationError('send_message needs a chatId in report_filed rules', 400);
+      }
+    }
+
+    const chatIds = [trigger.chatId, ...actions.map(action => action.chatId)]
+      .filter(chatId => !!chatId)
+      .map(chatId => chatId!.toString());
+    for (const chatId of new Set(chatIds)) {
+      if (!(await this.chatRepository.findById(chatId))) {
+        throw new AutomationError(`Chat ${chatId} not found`, 400);
+      }
+    }
+  }
+
+  // Fixed-window counter per rule; true once the rule is over its limit
+  private async consumeRateLimit(ruleId: string): Promise<boolean> {
+    const window = Math.floor(Date.now() / 1000 / RATE_WINDOW_SECONDS);
+    const key = `automation:rl:${ruleId}:${window}`;
+
+    let count: number;
+    if (this.redis) {
+      const results = await this.redis.multi().incr(key).expire(key, RATE_WINDOW_SECONDS).exec();
+      count = Number(results?.[0]?.[1] ?? 0);
+    } else {
+      const entry = this.memoryCounters.get(key);
+      count = (entry && entry.resetAt > Date.now() ? entry.count : 0) + 1;
+      this.memoryCounters.set(key, { count, resetAt: (window + 1) * RATE_WINDOW_SECONDS * 1000 });
+      if (this.memoryCounters.size > 10000) {
+        this.memoryCounters.forEach((value, counterKey) => {
+          if (value.resetAt <= Date.now()) this.memoryCounters.delete(counterKey);
+        });
+      }
+    }
+
+    return count > AUTOMATION_CONSTANTS.MAX_RUNS_PER_MINUTE;
+  }
+
+  private async audit(
+    adminId: string,
+    verb: 'create' | 'update' | 'delete',
+    rule: IAutomationRule,
+    details: Record<string, unknown>
+  ): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action: `automation_rule.${verb}`,
+      targetType: 'automation_rule',
+      targetId: rule._id,
+      details,
+    });
+    logger.info('Automation rule changed', { adminId, ruleId: rule._id.toString(), action: verb });
+  }
+}
+
+function toTrigger(input: AutomationTriggerInput): IAutomationRule['trigger'] {
+  return {
+    event: input.event,
+    ...('chatId' in input && input.chatId && { chatId: new Types.ObjectId(input.chatId) }),
+    ...('pattern' in input && input.pattern && { pattern: input.pattern }),
+    ...('reportTypes' in input && input.reportTypes && { reportTypes: input.reportTypes }),
+  };
+}
+
+function toAction(input: AutomationActionInput): IAutomationAction {
+  return {
+    ...input,
+    ...('chatId' in input && input.chatId && { chatId: new Types.ObjectId(input.chatId) }),
+  } as IAutomationAction;
+}
+
+// Admin view of a rule (never includes the signing secret)
+export function serializeAutomationRule(rule: IAutomationRule) {
+  return {
+    id: rule._id.toString(),
+    name: rule.name,
+    description: rule.description,
+    enabled: rule.enabled,
+    disabledReason: rule.disabledReason,
+    trigger: rule.trigger,
+    conditions: rule.conditions,
+    actions: rule.actions,
+    stats: rule.stats,
+    createdBy: rule.createdBy,
+    updatedBy: rule.updatedBy,
+    createdAt: rule.createdAt,
+    updatedAt: rule.updatedAt,
+  };
+}
+
+export class AutomationError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'AutomationError';
+  }
+}
+
+export const automationService = new AutomationService();
@@ -0,0 +1,220 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { environmentConfig } from '../config/environment';
+import { redisConfig } from '../config/redis';
+import { IncomingWebhookRepository } from '../database/repositories/incoming-webhook';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { IIncomingWebhook } from '../database/models/incoming-webhook';
+import { IMessage, WebhookAttachment } from '../database/models/message';
+import {
+  CreateIncomingWebhookInput,
+  UpdateIncomingWebhookInput,
+  WebhookMessageInput,
+} from '../database/schemas/incoming-webhook';
+import { socketManager } from '../realtime/socket';
+import { CryptoUtils } from '../utils/crypto';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const MAX_CONTENT_LENGTH = 4000;
+
+function hashToken(token: string): string {
+  return crypto.createHash('sha256').update(token).digest('hex');
+}
+
+export function toAttachments(payload: Pick<WebhookMessageInput, 'attachments'>): WebhookAttachment[] | undefined {
+  return payload.attachments?.map(attachment => ({
+    fallback: attachment.fallback,
+    color: attachment.color,
+    pretext: attachment.pretext,
+    title: attachment.title,
+    titleLink: attachment.title_link,
+    text: attachment.text,
+    fields: attachment.fields,
+    imageUrl: attachment.image_url,
+    footer: attachment.footer,
+  }));
+}
+
+// Plain-text rendering for clients that don't draw attachments, previews and
+// search. Rich clients render metadata.webhook.attachments instead.
+export function renderContent(text: string | undefined, attachments: WebhookAttachment[] = []): string {
+  const parts = text?.trim() ? [text.trim()] : [];
+
+  for (const attachment of attachments) {
+    const lines = [
+      attachment.pretext,
+      attachment.title && attachment.titleLink ? `${attachment.title} (${attachment.titleLink})` : attachment.title,
+      attachment.text,
+      ...(attachment.fields || []).map(field => `${field.title}: ${field.value}`),
+      attachment.footer,
+    ].filter((line): line is string => !!line);
+
+    parts.push(lines.length > 0 ? lines.join('\n') : attachment.fallback || '');
+  }
+
+  return parts.filter(Boolean).join('\n\n').slice(0, MAX_CONTENT_LENGTH);
+}
+
+// Incoming webhooks: secret URLs that let outside systems post bot messages
+// into a group. Each webhook posts as its own bot account; the URL token is
+// only stored hashed and is shown once at creation.
+export class IncomingWebhookService {
+  private incomingWebhookRepository = new IncomingWebhookRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+  private redis = redisConfig.getClient();
+  private memoryCounters = new Map<string, { count: number; resetAt: number }>();
+
+  getUrl(webhookId: string, token: string): string {
+    return `${environmentConfig.getValue('API_URL')}/hooks/${webhookId}/${token}`;
+  }
+
+  // Mint a webhook; the returned URL is the only copy of its token
+  async create(
+    chatId: string,
+    createdBy: string,
+    input: CreateIncomingWebhookInput
+  ): Promise<{ webhook: IIncomingWebhook; url: string }> {
+    const token = crypto.randomBytes(24).toString('base64url');
+
+    const webhook = await this.incomingWebhookRepository.create({
+      chatId: new Types.ObjectId(chatId),
+      name: input.name,
+      avatarUrl: input.avatarUrl,
+      tokenHash: hashToken(token),
+      createdBy: new Types.ObjectId(createdBy),
+      ...(input.rateLimit && { rateLimit: input.rateLimit }),
+    });
+
+    return { webhook, url: this.getUrl(webhook._id.toString(), token) };
+  }
+
+  async update(webhook: IIncomingWebhook, input: UpdateIncomingWebhookInput): Promise<IIncomingWebhook | null> {
+    const updated = await this.incomingWebhookRepository.update(webhook._id, {
+      ...(input.name && { name: input.name }),
+      ...(input.avatarUrl !== undefined && { avatarUrl: input.avatarUrl ?? undefined }),
+      ...(input.rateLimit && { rateLimit: input.rateLimit }),
+    } as Partial<IIncomingWebhook>);
+
+    // Keep the bot account's name in step with the webhook
+    if (updated && input.name) {
+      await this.userRepository.findOrCreateBridgeUser('webhook', updated._id.toString(), updated.name);
+    }
+    return updated;
+  }
+
+  async revoke(webhook: IIncomingWebhook, revokedBy: string): Promise<boolean> {
+    return await this.incomingWebhookRepository.revoke(webhook._id, revokedBy);
+  }
+
+  // Post a message through a webhook URL
+  async post(webhookId: string, token: string, payload: WebhookMessageInput): Promise<IMessage> {
+    const webhook = Types.ObjectId.isValid(webhookId)
+      ? await this.incomingWebhookRepository.findById(webhookId)
+      : null;
+
+    // Unknown, revoked and wrong-token URLs all look the same to the caller
+    if (!webhook || webhook.revokedAt || !CryptoUtils.constantTimeEqual(hashToken(token), webhook.tokenHash)) {
+      throw new IncomingWebhookError('Webhook not found', 404);
+    }
+
+    const retryAfter = await this.consumeRateLimit(webhook);
+    if (retryAfter !== null) {
+      metricsCollector.incrementCounter('incoming_webhook_rate_limited', 1);
+      throw new IncomingWebhookError('Rate limit exceeded', 429, retryAfter);
+    }
+
+    const chat = await this.chatRepository.findById(webhook.chatId);
+    if (!chat) {
+      throw new IncomingWebhookError('Webhook not found', 404);
+    }
+
+    const bot = await this.userRepository.findOrCreateBridgeUser('webhook', webhook._id.toString(), webhook.name);
+    const attachments = toAttachments(payload);
+
+    const message = await this.messageRepository.create({
+      chatId: chat._id,
+      senderId: bot._id,
+      content: renderContent(payload.text, attachments),
+      type: 'text',
+      metadata: {
+        webhook: {
+          webhookId: webhook._id,
+          username: payload.username,
+          avatarUrl: payload.icon_url || webhook.avatarUrl,
+          attachments,
+        },
+      },
+    });
+
+    await this.chatRepository.updateLastActivity(chat._id, message._id);
+    await this.incomingWebhookRepository.recordUse(webhook._id);
+
+    const populatedMessage = await this.messageRepository.findById(message._id);
+    socketManager.emitToChat(chat._id.toString(), 'message:new', populatedMessage);
+
+    metricsCollector.incrementCounter('incoming_webhook_messages', 1);
+    logger.info('Incoming webhook message posted', {
+      webhookId: webhook._id.toString(),
+      chatId: chat._id.toString(),
+    });
+
+    return message;
+  }
+
+  // Fixed-window counter per webhook. Returns seconds until the window
+  // resets when the limit is exceeded, null otherwise.
+  private async consumeRateLimit(webhook: IIncomingWebhook): Promise<number | null> {
+    const { maxRequests, windowSeconds } = webhook.rateLimit;
+    const window = Math.floor(Date.now() / 1000 / windowSeconds);
+    const key = `webhook:rl:${webhook._id.toString()}:${window}`;
+    const retryAfter = (window + 1) * windowSeconds - Math.floor(Date.now() / 1000);
+
+    let count: number;
+    if (this.redis) {
+      const results = await this.redis.multi().incr(key).expire(key, windowSeconds).exec();
+      count = Number(results?.[0]?.[1] ?? 0);
+    } else {
+      const entry = this.memoryCounters.get(key);
+      count = (entry && entry.resetAt > Date.now() ? entry.count : 0) + 1;
+      this.memoryCounters.set(key, { count, resetAt: Date.now() + retryAfter * 1000 });
+      if (this.memoryCounters.size > 10000) {
+        this.memoryCounters.forEach((value, counterKey) => {
+          if (value.resetAt <= Date.now()) this.memoryCounters.delete(counterKey);
+        });
+      }
+    }
+
+    return count > maxRequests ? retryAfter : null;
+  }
+}
+
+// Client-facing view of a webhook (never includes the token)
+export function serializeIncomingWebhook(webhook: IIncomingWebhook) {
+  return {
+    id: webhook._id.toString(),
+    chatId: webhook.chatId.toString(),
+    name: webhook.name,
+    avatarUrl: webhook.avatarUrl,
+    rateLimit: webhook.rateLimit,
+    createdBy: webhook.createdBy,
+    revoked: !!webhook.revokedAt,
+    revokedAt: webhook.revokedAt,
+    lastUsedAt: webhook.lastUsedAt,
+    messageCount: webhook.messageCount,
+    createdAt: webhook.createdAt,
+  };
+}
+
+export class IncomingWebhookError extends Error {
+  constructor(message: string, public status: number, public retryAfter?: number) {
+    super(message);
+    this.name = 'IncomingWebhookError';
+  }
+}
+
+export const incomingWebhookService = new IncomingWebhookService();
@@ -0,0 +1,542 @@
+import { Types } from 'mongoose';
+import { z } from 'zod';
+import { UserRepository } from '../database/repositories/user';
+import { GroupRepository } from '../database/repositories/group';
+import { PersonalAccessTokenRepository } from '../database/repositories/personal-access-token';
+import { IUser } from '../database/models/user';
+import { IChat } from '../database/models/chat';
+import {
+  scimUserSchema,
+  scimGroupSchema,
+  ScimUserInput,
+  ScimGroupInput,
+  ScimPatchInput,
+} from '../database/schemas/scim';
+import { jwtService } from '../auth/jwt';
+import { organizationService, OrganizationError } from '../organizations';
+import { PhoneUtils } from '../utils/phone';
+import { logger } from '../monitoring/logging';
+
+export const SCIM_CONTENT_TYPE = 'application/scim+json';
+export const SCIM_USER_SCHEMA = 'urn:ietf:params:scim:schemas:core:2.0:User';
+export const SCIM_GROUP_SCHEMA = 'urn:ietf:params:scim:schemas:core:2.0:Group';
+const LIST_RESPONSE_SCHEMA = 'urn:ietf:params:scim:api:messages:2.0:ListResponse';
+const ERROR_SCHEMA = 'urn:ietf:params:scim:api:messages:2.0:Error';
+
+// Ban reason on deprovisioned accounts; reactivation only lifts this one
+export const DEPROVISIONED_REASON = 'Deprovisioned by identity provider';
+
+export const SCIM_MAX_RESULTS = 200;
+
+type ScimResource = Record<string, any>;
+
+// SCIM 2.0 provisioning, so an enterprise identity provider can manage
+// accounts and group membership:
+//   - Users are accounts linked to the provider by userName. The phone
+//     number is required since it is how people sign in. Deactivating a user
+//     (active=false or DELETE) bans the account, ends its sessions and
+//     revokes its tokens; reactivating lifts that ban but not any moderation
+//     ban placed since. Accounts are never deleted over SCIM.
+//   - Groups are group chats whose membership the provider controls. They
+//     start without group admins. DELETE stops managing the group but keeps
+//     it and its messages.
+// Only accounts and groups created over SCIM are visible here, and only
+// those in the API key's organization, where new ones are created too.
+// Filters support "eq" on userName/externalId and displayName/externalId.
+export class ScimService {
+  private userRepository = new UserRepository();
+  private groupRepository = new GroupRepository();
+  private personalAccessTokenRepository = new PersonalAccessTokenRepository();
+
+  async listUsers(
+    organizationId: string | undefined,
+    filter: string | null,
+    startIndex: number,
+    count: number
+  ): Promise<{ users: IUser[]; total: number }> {
+    const { userName, externalId } = parseFilter(filter, { username: 'userName', externalid: 'externalId' });
+    return await this.userRepository.findScimUsers({ organizationId, userName, externalId }, count, startIndex - 1);
+  }
+
+  async getUser(organizationId: string | undefined, userId: string): Promise<IUser> {
+    const user = Types.ObjectId.isValid(userId) ? await this.userRepository.findById(userId) : null;
+    if (!user || !user.scim?.provisionedAt || user.organizationId?.toString() !== organizationId) {
+      throw new ScimError('User not found', 404);
+    }
+    return user;
+  }
+
+  async createUser(organizationId: string | undefined, input: ScimUserInput): Promise<IUser> {
+    const fields = this.userFields(input);
+    if (organizationId) {
+      await this.withOrganizationLimits(async () =>
+        organizationService.checkUserQuota(await organizationService.get(organizationId), 1));
+    }
+
+    if (await this.userRepository.findByScimUserName(input.userName)) {
+      throw new ScimError('userName is already in use', 409, 'uniqueness');
+    }
+    if (await this.userRepository.findByPhoneNumber(fields.phoneNumber)) {
+      throw new ScimError('Phone number already belongs to an account', 409, 'uniqueness');
+    }
+
+    let user = await this.withUniqueness(() => this.userRepository.create({
+      ...fields,
+      ...(organizationId && { organizationId: new Types.ObjectId(organizationId) }),
+      scim: {
+        userName: input.userName,
+        externalId: input.externalId,
+        provisionedAt: new Date(),
+      },
+    }));
+
+    if (!input.active) {
+      user = await this.setActive(user, false);
+    }
+
+    logger.info('SCIM user provisioned', { userId: user._id.toString(), userName: input.userName });
+    return user;
+  }
+
+  async replaceUser(organizationId: string | undefined, userId: string, input: ScimUserInput): Promise<IUser> {
+    const user = await this.getUser(organizationId, userId);
+    const fields = this.userFields(input);
+
+    const byUserName = await this.userRepository.findByScimUserName(input.userName);
+    if (byUserName && !byUserName._id.equals(user._id)) {
+      throw new ScimError('userName is already in use', 409, 'uniqueness');
+    }
+    const byPhone = await this.userRepository.findByPhoneNumber(fields.phoneNumber);
+    if (byPhone && !byPhone._id.equals(user._id)) {
+      throw new ScimError('Phone number already belongs to an account', 409, 'uniqueness');
+    }
+
+    const updated = await this.withUniqueness(() => this.userRepository.update(user._id, {
+      ...fields,
+      scim: {
+        userName: input.userName,
+        externalId: input.externalId,
+        provisionedAt: user.scim!.provisionedAt,
+        deactivatedAt: user.scim!.deactivatedAt,
+      },
+    }));
+    if (!updated) {
+      throw new ScimError('User not found', 404);
+    }
+
+    return await this.setActive(updated, input.active);
+  }
+
+  async patchUser(organizationId: string | undefined, userId: string, patch: ScimPatchInput): Promise<IUser> {
+    const user = await this.getUser(organizationId, userId);
+
+    const resource = applyPatch(toUserInput(user), patch);
+    if (typeof resource.active === 'string') {
+      resource.active = resource.active.toLowerCase() === 'true';
+    }
+
+    return await this.replaceUser(organizationId, userId, parseResource(scimUserSchema, resource));
+  }
+
+  // Deprovision. The account is kept, banned, so its history stays intact.
+  async deleteUser(organizationId: string | undefined, userId: string): Promise<void> {
+    const user = await this.getUser(organizationId, userId);
+    await this.setActive(user, false);
+  }
+
+  async listGroups(
+    organizationId: string | undefined,
+    filter: string | null,
+    startIndex: number,
+    count: number
+  ): Promise<{ groups: IChat[]; total: number }> {
+    const { displayName, externalId } = parseFilter(filter, { displayname: 'displayName', externalid: 'externalId' });
+    return await this.groupRepository.findScimGroups({ organizationId, displayName, externalId }, count, startIndex - 1);
+  }
+
+  async getGroup(organizationId: string | undefined, groupId: string): Promise<IChat> {
+    const group = await this.groupRepository.findScimGroup(groupId, organizationId);
+    if (!group) {
+      throw new ScimError('Group not found', 404);
+    }
+    return group;
+  }
+
+  async createGroup(organizationId: string | undefined, input: ScimGroupInput): Promise<IChat> {
+    const members = await this.resolveMembers(organizationId, input);
+    await this.withOrganizationLimits(() => organizationService.checkGroupQuota(organizationId));
+
+    const group = await this.groupRepository.create({
+      type: 'group',
+      ...(organizationId && { organizationId: new Types.ObjectId(organizationId) }),
+      participants: members,
+      groupInfo: {
+        name: input.displayName,
+        admins: [],
+        settings: {
+          whoCanSendMessages: 'everyone',
+          whoCanEditGroupInfo: 'admins',
+          whoCanAddMembers: 'admins',
+          messageApproval: false,
+          pseudonymousMembers: false,
+        },
+      },
+      scim: {
+        externalId: input.externalId,
+        provisionedAt: new Date(),
+      },
+    });
+
+    logger.info('SCIM group provisioned', { groupId: group._id.toString(), members: members.length });
+    return group;
+  }
+
+  async replaceGroup(organizationId: string | undefined, groupId: string, input: ScimGroupInput): Promise<IChat> {
+    const group = await this.getGroup(organizationId, groupId);
+    const members = await this.resolveMembers(organizationId, input);
+
+    const memberIds = new Set(members.map(id => id.toString()));
+    const removed = group.participants.filter(id => !memberIds.has(id.toString()));
+
+    await this.groupRepository.update(group._id, {
+      'groupInfo.name': input.displayName,
+      scim: { externalId: input.externalId, provisionedAt: group.scim!.provisionedAt },
+    } as any);
+    await this.groupRepository.setParticipants(group._id, members);
+    if (removed.length > 0) {
+      // Also drops them from the group admins
+      await this.groupRepository.removeParticipants(group._id, removed);
+    }
+
+    logger.info('SCIM group updated', {
+      groupId,
+      members: members.length,
+      removed: removed.length,
+    });
+    return await this.getGroup(organizationId, groupId);
+  }
+
+  async patchGroup(organizationId: string | undefined, groupId: string, patch: ScimPatchInput): Promise<IChat> {
+    const group = await this.getGroup(organizationId, groupId);
+    const resource = applyPatch(toGroupInput(group), patch);
+    return await this.replaceGroup(organizationId, groupId, parseResource(scimGroupSchema, resource));
+  }
+
+  // Stop managing the group over SCIM; the chat and its members stay
+  async deleteGroup(organizationId: string | undefined, groupId: string): Promise<void> {
+    const group = await this.getGroup(organizationId, groupId);
+    await this.groupRepository.unlinkScim(group._id);
+    logger.info('SCIM group unlinked', { groupId });
+  }
+
+  private async setActive(user: IUser, active: boolean): Promise<IUser> {
+    const deactivated = !!user.scim?.deactivatedAt;
+    if (active === !deactivated) return user;
+
+    const userId = user._id.toString();
+    if (active) {
+      const updated = await this.userRepository.reactivateScimUser(user._id, DEPROVISIONED_REASON);
+      logger.info('SCIM user reactivated', { userId });
+      return updated || user;
+    }
+
+    const updated = await this.userRepository.deactivateScimUser(user._id, DEPROVISIONED_REASON);
+
+    // Sessions are refused from now on; end the ones in progress too
+    await jwtService.invalidateAllUserTokens(userId);
+    await this.personalAccessTokenRepository.revokeAllForUser(user._id);
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.disconnectUser(userId);
+
+    logger.info('SCIM user deactivated', { userId });
+    return updated || user;
+  }
+
+  private userFields(input: ScimUserInput) {
+    const phone = PhoneUtils.parse(primaryValue(input.phoneNumbers)!);
+    if (!phone) {
+      throw new ScimError('Phone numbers must be valid and in international format', 400, 'invalidValue');
+    }
+
+    const email = primaryValue(input.emails)?.toLowerCase();
+    const fullName = [input.name?.givenName, input.name?.familyName].filter(Boolean).join(' ');
+
+    return {
+      phoneNumber: phone.e164,
+      countryCode: phone.countryCode,
+      displayName: input.displayName || input.name?.formatted || fullName || input.userName,
+      ...(email && { email }),
+    };
+  }
+
+  private async resolveMembers(organizationId: string | undefined, input: ScimGroupInput): Promise<Types.ObjectId[]> {
+    const ids = [...new Set(input.members.map(member => member.value))];
+    if (ids.some(id => !Types.ObjectId.isValid(id))) {
+      throw new ScimError('Unknown group member', 400, 'invalidValue');
+    }
+
+    const existing = await this.userRepository.existingIds(ids);
+    if (existing.length !== ids.length || !(await organizationService.allInOrganization(organizationId, ids))) {
+      throw new ScimError('Unknown group member', 400, 'invalidValue');
+    }
+    return existing;
+  }
+
+  // Organization quotas surface as SCIM errors
+  private async withOrganizationLimits(check: () => Promise<void>): Promise<void> {
+    try {
+      await check();
+    } catch (error) {
+      if (error instanceof OrganizationError) {
+        throw new ScimError(error.message, error.status);
+      }
+      throw error;
+    }
+  }
+
+  // Map duplicate-key errors (e.g. an email already in use) to SCIM's 409
+  private async withUniqueness<T>(operation: () => Promise<T>): Promise<T> {
+    try {
+      return await operation();
+    } catch (error: any) {
+      if (error?.code === 11000) {
+        throw new ScimError(`${Object.keys(error.keyPattern || {}).join(', ') || 'Value'} is already in use`, 409, 'uniqueness');
+      }
+      throw error;
+    }
+  }
+}
+
+function primaryValue(values?: { value: string; primary?: boolean }[]): string | undefined {
+  return (values?.find(entry => entry.primary) || values?.[0])?.value;
+}
+
+// Parse `attribute eq "value"`; the only filter identity providers need
+// for provisioning
+function parseFilter(filter: string | null, attributes: Record<string, string>): Record<string, string | undefined> {
+  if (!filter) return {};
+
+  const match = /^\s*([\w.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$/i.exec(filter);
+  const attribute = match && attributes[match[1].toLowerCase()];
+  if (!attribute) {
+    throw new ScimError(`Unsupported filter; use "eq" on ${Object.values(attributes).join(' or ')}`, 400, 'invalidFilter');
+  }
+
+  return { [attribute]: match![2].replace(/\\(.)/g, '$1') };
+}
+
+function parseResource<T>(schema: z.ZodType<T, z.ZodTypeDef, unknown>, resource: ScimResource): T {
+  const result = schema.safeParse(resource);
+  if (!result.success) {
+    throw new ScimError(
+      result.error.errors.map(err => `${err.path.join('.')}: ${err.message}`).join('; '),
+      400,
+      'invalidValue'
+    );
+  }
+  return result.data;
+}
+
+function toUserInput(user: IUser): ScimResource {
+  return {
+    userName: user.scim!.userName,
+    externalId: user.scim!.externalId,
+    displayName: user.displayName,
+    active: !user.scim!.deactivatedAt,
+    emails: user.email ? [{ value: user.email, type: 'work', primary: true }] : [],
+    phoneNumbers: [{ value: user.phoneNumber, type: 'mobile', primary: true }],
+  };
+}
+
+function toGroupInput(group: IChat): ScimResource {
+  return {
+    displayName: group.groupInfo?.name,
+    externalId: group.scim?.externalId,
+    members: group.participants.map(id => ({ value: id.toString() })),
+  };
+}
+
+// Apply PATCH operations (RFC 7644 section 3.5.2) to a resource. Supports
+// plain and sub-attribute paths plus one `eq` value filter, e.g.
+// `members[value eq "..."]` or `emails[type eq "work"].value`.
+function applyPatch(resource: ScimResource, patch: ScimPatchInput): ScimResource {
+  for (const operation of patch.Operations) {
+    if (!operation.path) {
+      if (operation.op === 'remove' || !operation.value || typeof operation.value !== 'object') {
+        throw new ScimError('Operations without a path need an object value', 400, 'noTarget');
+      }
+      for (const [path, value] of Object.entries(operation.value as ScimResource)) {
+        applyOperation(resource, operation.op, path, value);
+      }
+      continue;
+    }
+
+    applyOperation(resource, operation.op, operation.path, operation.value);
+  }
+  return resource;
+}
+
+const PATCH_PATH = /^(\w+)(?:\[\s*(\w+)\s+eq\s+"([^"]*)"\s*\])?(?:\.(\w+))?$/i;
+
+function applyOperation(resource: ScimResource, op: string, path: string, value: unknown): void {
+  const match = PATCH_PATH.exec(path.replace(/^urn:[^:]+(?::[^:]+)*:/, ''));
+  if (!match) {
+    throw new ScimError(`Unsupported path: ${path}`, 400, 'invalidPath');
+  }
+
+  const attribute = attributeKey(resource, match[1]);
+  const sub = match[4];
+
+  // attribute[filterAttribute eq "filterValue"](.sub)
+  if (match[2]) {
+    const list: ScimResource[] = Array.isArray(resource[attribute]) ? resource[attribute] : [];
+    const matches = (entry: ScimResource) => String(entry[attributeKey(entry, match[2])]) === match[3];
+
+    if (op === 'remove') {
+      resource[attribute] = sub
+        ? list.map(entry => (matches(entry) ? omit(entry, attributeKey(entry, sub)) : entry))
+        : list.filter(entry => !matches(entry));
+      return;
+    }
+
+    const matched = list.filter(matches);
+    if (matched.length === 0) {
+      list.push({ [match[2]]: match[3], ...(sub ? { [sub]: value } : (value as ScimResource)) });
+    }
+    for (const entry of matched) {
+      if (sub) entry[attributeKey(entry, sub)] = value;
+      else Object.assign(entry, value);
+    }
+    resource[attribute] = list;
+    return;
+  }
+
+  // attribute.sub; on multi-valued attributes this targets the primary entry
+  if (sub) {
+    const current = resource[attribute];
+    let target: ScimResource;
+    if (Array.isArray(current)) {
+      target = current.find(entry => entry.primary) || current[0];
+      if (!target) {
+        target = { primary: true };
+        current.push(target);
+      }
+    } else {
+      target = resource[attribute] = current && typeof current === 'object' ? current : {};
+    }
+
+    if (op === 'remove') delete target[attributeKey(target, sub)];
+    else target[attributeKey(target, sub)] = value;
+    return;
+  }
+
+  if (op === 'remove') {
+    // Remove listed entries (e.g. members) or the whole attribute
+    if (Array.isArray(resource[attribute]) && Array.isArray(value)) {
+      const removed = new Set(value.map((entry: any) => String(entry?.value)));
+      resource[attribute] = resource[attribute].filter((entry: ScimResource) => !removed.has(String(entry.value)));
+    } else {
+      delete resource[attribute];
+    }
+    return;
+  }
+
+  if (op === 'add' && Array.isArray(resource[attribute])) {
+    const added = Array.isArray(value) ? value : [value];
+    const present = new Set(resource[attribute].map((entry: ScimResource) => String(entry.value)));
+    resource[attribute] = [
+      ...resource[attribute],
+      ...added.filter((entry: any) => !present.has(String(entry?.value))),
+    ];
+    return;
+  }
+
+  resource[attribute] = value;
+}
+
+const ATTRIBUTES = [
+  'userName', 'externalId', 'displayName', 'name', 'active', 'emails', 'phoneNumbers', 'members',
+  'formatted', 'givenName', 'familyName', 'value', 'type', 'primary',
+];
+
+// SCIM attribute names are case-insensitive
+function attributeKey(resource: ScimResource, name: string): string {
+  const lower = name.toLowerCase();
+  return Object.keys(resource).find(key => key.toLowerCase() === lower)
+    ?? ATTRIBUTES.find(key => key.toLowerCase() === lower)
+    ?? name;
+}
+
+function omit(entry: ScimResource, key: string): ScimResource {
+  const { [key]: _removed, ...rest } = entry;
+  return rest;
+}
+
+export function serializeScimUser(user: IUser, baseUrl: string) {
+  const id = user._id.toString();
+  return {
+    schemas: [SCIM_USER_SCHEMA],
+    id,
+    externalId: user.scim?.externalId,
+    userName: user.scim?.userName,
+    displayName: user.displayName,
+    name: { formatted: user.displayName },
+    active: !user.scim?.deactivatedAt,
+    emails: user.email ? [{ value: user.email, type: 'work', primary: true }] : [],
+    phoneNumbers: [{ value: user.phoneNumber, type: 'mobile', primary: true }],
+    meta: {
+      resourceType: 'User',
+      created: user.createdAt,
+      lastModified: user.updatedAt,
+      location: `${baseUrl}/Users/${id}`,
+    },
+  };
+}
+
+export function serializeScimGroup(group: IChat, baseUrl: string) {
+  const id = group._id.toString();
+  return {
+    schemas: [SCIM_GROUP_SCHEMA],
+    id,
+    externalId: group.scim?.externalId,
+    displayName: group.groupInfo?.name,
+    members: group.participants.map(memberId => ({
+      value: memberId.toString(),
+      $ref: `${baseUrl}/Users/${memberId.toString()}`,
+    })),
+    meta: {
+      resourceType: 'Group',
+      created: group.createdAt,
+      lastModified: group.updatedAt,
+      location: `${baseUrl}/Groups/${id}`,
+    },
+  };
+}
+
+export function scimListResponse(resources: unknown[], total: number, startIndex: number) {
+  return {
+    schemas: [LIST_RESPONSE_SCHEMA],
+    totalResults: total,
+    startIndex,
+    itemsPerPage: resources.length,
+    Resources: resources,
+  };
+}
+
+export function scimErrorBody(status: number, detail: string, scimType?: string) {
+  return {
+    schemas: [ERROR_SCHEMA],
+    status: status.toString(),
+    ...(scimType && { scimType }),
+    detail,
+  };
+}
+
+export class ScimError extends Error {
+  constructor(message: string, public status: number, public scimType?: string) {
+    super(message);
+    this.name = 'ScimError';
+  }
+}
+
+export const scimService = new ScimService();
@@ -0,0 +1,450 @@
+import { Types } from 'mongoose';
+import crypto from 'crypto';
+import { environmentConfig } from '../config/environment';
+import { SlashCommandRepository } from '../database/repositories/slash-command';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { ISlashCommand } from '../database/models/slash-command';
+import { IChat } from '../database/models/chat';
+import { IMessage, WebhookAttachment } from '../database/models/message';
+import {
+  commandResponseSchema,
+  CreateSlashCommandInput,
+  UpdateSlashCommandInput,
+} from '../database/schemas/slash-command';
+import { mediaUploadService } from '../media/upload';
+import { reminderService, ReminderError } from '../reminders';
+import { socketManager } from '../realtime/socket';
+import { CryptoUtils } from '../utils/crypto';
+import { toAttachments, renderContent } from './incoming-webhooks';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const COMMAND_PATTERN = /^\/([a-z][a-z0-9_-]{0,31})(?:\s+|$)/i;
+const BOT_TIMEOUT = 3000; // bots must answer within 3 seconds
+const MAX_POLL_OPTIONS = 10;
+
+export interface CommandInvocation {
+  name: string; // lowercase, without the slash
+  args: string;
+}
+
+export interface CommandContext {
+  chat: IChat;
+  userId: string;
+  args: string;
+  replyTo?: string; // message the command was sent in reply to
+  tempId?: string;
+}
+
+// A message a command posts as the invoking user through the normal send path
+export interface CommandMessage {
+  content: string;
+  type?: IMessage['type'];
+  mediaId?: string;
+  metadata?: Record<string, any>;
+}
+
+export type CommandResult =
+  | { type: 'post'; message: CommandMessage }
+  | { type: 'posted'; message: IMessage } // the command already posted (bot replies)
+  | { type: 'ephemeral'; text: string; attachments?: WebhookAttachment[] }
+  | { type: 'none' };
+
+export interface BuiltinCommand {
+  name: string;
+  description: string;
+  usage?: string;
+  isAvailable?: () => boolean; // e.g. requires an API key
+  execute(context: CommandContext): Promise<CommandResult>;
+}
+
+function ephemeral(text: string): CommandResult {
+  return { type: 'ephemeral', text };
+}
+
+// `/poll "Question" "A" "B"` or `/poll Question | A | B`, with a leading
+// --anonymous to hide who voted for what from everyone but group admins
+function parsePollArgs(args: string): { question: string; options: string[] } | null {
+  const quoted = Array.from(args.matchAll(/"([^"]+)"|\u201c([^\u201d]+)\u201d/g))
+    .map(match => (match[1] ?? match[2]).trim());
+  const parts = (quoted.length > 0 ? quoted : args.split('|').map(part => part.trim()))
+    .filter(Boolean);
+
+  const [question, ...rest] = parts;
+  const options = Array.from(new Set(rest.map(option => option.slice(0, 100))));
+  if (!question || options.length < 2 || options.length > MAX_POLL_OPTIONS) {
+    return null;
+  }
+  return { question: question.slice(0, 300), options };
+}
+
+const meCommand: BuiltinCommand = {
+  name: 'me',
+  description: 'Post an action, e.g. "/me waves"',
+  usage: '<action>',
+  async execute({ args }) {
+    if (!args) return ephemeral('Usage: /me <action>');
+    return { type: 'post', message: { content: args, metadata: { command: { name: 'me' } } } };
+  },
+};
+
+const pollCommand: BuiltinCommand = {
+  name: 'poll',
+  description: 'Start a poll',
+  usage: '[--anonymous] "Question" "Option 1" "Option 2" ...',
+  async execute({ chat, args }) {
+    const flag = /^--anonymous\b\s*/.exec(args);
+    const poll = parsePollArgs(flag ? args.slice(flag[0].length) : args);
+    if (!poll) {
+      return ephemeral(`Usage: /poll [--anonymous] "Question" "Option 1" "Option 2" (2-${MAX_POLL_OPTIONS} options)`);
+    }
+
+    // Voters are hidden in groups with pseudonymous members
+    const anonymous = !!flag || !!chat.groupInfo?.settings?.pseudonymousMembers;
+    if (anonymous && chat.type !== 'group') {
+      return ephemeral('Anonymous polls are only available in groups');
+    }
+
+    return {
+      type: 'post',
+      message: {
+        content: [poll.question, ...poll.options.map((option, index) => `${index + 1}. ${option}`)].join('\n'),
+        metadata: {
+          command: { name: 'poll' },
+          poll: {
+            question: poll.question,
+            options: poll.options.map(text => ({ text, votes: [] })),
+            closed: false,
+            ...(anonymous && { anonymous: true }),
+          },
+        },
+      },
+    };
+  },
+};
+
+const giphyCommand: BuiltinCommand = {
+  name: 'giphy',
+  description: 'Post a GIF from GIPHY',
+  usage: '<search terms>',
+  isAvailable: () => !!environmentConfig.getValue('GIPHY_API_KEY'),
+  async execute({ chat, userId, args }) {
+    if (!args) return ephemeral('Usage: /giphy <search terms>');
+
+    const url = new URL('https://api.giphy.com/v1/gifs/translate');
+    url.searchParams.set('api_key', environmentConfig.getValue('GIPHY_API_KEY')!);
+    url.searchParams.set('s', args);
+    url.searchParams.set('rating', environmentConfig.getValue('GIPHY_RATING'));
+
+    const response = await fetch(url, { signal: AbortSignal.timeout(5000) });
+    if (!response.ok) {
+      throw new Error(`GIPHY request failed with status ${response.status}`);
+    }
+
+    const body = await response.json();
+    const gifUrl: string | undefined = body?.data?.images?.downsized?.url || body?.data?.images?.original?.url;
+    if (!gifUrl) {
+      return ephemeral(`No GIFs found for "${args}"`);
+    }
+
+    // Re-host the GIF so clients never fetch from GIPHY directly
+    const { media } = await mediaUploadService.uploadFromUrl(gifUrl, userId, {
+      name: 'giphy.gif',
+      mimeType: 'image/gif',
+      chatId: chat._id.toString(),
+    });
+
+    return {
+      type: 'post',
+      message: {
+        content: args,
+        type: 'image',
+        mediaId: media._id.toString(),
+        metadata: { command: { name: 'giphy' } },
+      },
+    };
+  },
+};
+
+const remindCommand: BuiltinCommand = {
+  name: 'remind',
+  description: 'Set a reminder; reply to a message to be reminded of it',
+  usage: '[me] <when> [to] <what>',
+  async execute({ chat, userId, args, replyTo }) {
+    try {
+      const { confirmation } = await reminderService.createFromText(userId, args, {
+        chatId: chat._id.toString(),
+        messageId: replyTo,
+        source: replyTo ? 'message' : 'command',
+      });
+      return ephemeral(confirmation);
+    } catch (error) {
+      if (error instanceof ReminderError) return ephemeral(error.message);
+      throw error;
+    }
+  },
+};
+
+// Slash commands typed into a chat. Built-ins run in-process; anything else
+// is looked up among the chat's custom commands and dispatched to the bot
+// behind it. Replies are either ephemeral (only the invoker sees them) or
+// posted to the chat.
+export class SlashCommandService {
+  private slashCommandRepository = new SlashCommandRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+  private builtins = new Map<string, BuiltinCommand>();
+
+  constructor() {
+    [meCommand, pollCommand, giphyCommand, remindCommand].forEach(command => this.register(command));
+  }
+
+  // Add a built-in command; other modules register theirs at load time
+  register(command: BuiltinCommand): void {
+    this.builtins.set(command.name, command);
+  }
+
+  getBuiltins(): BuiltinCommand[] {
+    return Array.from(this.builtins.values()).filter(command => !command.isAvailable || command.isAvailable());
+  }
+
+  isBuiltin(name: string): boolean {
+    return this.builtins.has(name.toLowerCase());
+  }
+
+  // Returns the invocation if the text is a command. "//text" is not a
+  // command; see unescape().
+  parse(content: string): CommandInvocation | null {
+    const match = COMMAND_PATTERN.exec(content);
+    if (!match) return null;
+    return { name: match[1].toLowerCase(), args: content.slice(match[0].length).trim() };
+  }
+
+  // "//shrug" is sent as the literal text "/shrug"
+  unescape(content: string): string {
+    return content.startsWith('//') ? content.slice(1) : content;
+  }
+
+  async execute(invocation: CommandInvocation, context: CommandContext): Promise<CommandResult> {
+    const { name } = invocation;
+    const builtin = this.builtins.get(name);
+    let result: CommandResult;
+
+    try {
+      if (context.chat.disabledCommands?.includes(name)) {
+        result = ephemeral(`/${name} is disabled in this chat`);
+      } else if (builtin && (!builtin.isAvailable || builtin.isAvailable())) {
+        result = await builtin.execute(context);
+      } else {
+        const command = await this.slashCommandRepository.findByName(context.chat._id, name);
+        result = command && command.enabled
+          ? await this.dispatch(command, context)
+          : ephemeral(`Unknown command /${name}. Send //${name} to post it as text.`);
+      }
+    } catch (error) {
+      logger.error('Slash command failed', error, { command: name, chatId: context.chat._id.toString() });
+      metricsCollector.incrementCounter('slash_command_errors', 1, { command: builtin ? name : 'custom' });
+      result = ephemeral(`/${name} failed. Please try again.`);
+    }
+
+    metricsCollector.incrementCounter('slash_command_invocations', 1, { command: builtin ? name : 'custom' });
+
+    if (result.type === 'ephemeral') {
+      socketManager.emitToUser(context.userId, 'command:response', {
+        chatId: context.chat._id.toString(),
+        command: name,
+        text: result.text,
+        attachments: result.attachments,
+        tempId: context.tempId,
+      });
+    }
+    return result;
+  }
+
+  // POST the invocation to the command's bot and act on its reply
+  private async dispatch(command: ISlashCommand, context: CommandContext): Promise<CommandResult> {
+    const user = await this.userRepository.findById(context.userId);
+    const timestamp = Math.floor(Date.now() / 1000).toString();
+    const body = JSON.stringify({
+      command: `/${command.name}`,
+      text: context.args,
+      chat_id: context.chat._id.toString(),
+      user_id: context.userId,
+      user_name: user?.displayName,
+    });
+
+    await this.slashCommandRepository.recordUse(command._id);
+
+    let response: Response;
+    try {
+      response = await fetch(command.url, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'X-Command-Timestamp': timestamp,
+          'X-Command-Signature': `v1=${CryptoUtils.hmac(`v1:${timestamp}:${body}`, command.signingSecret)}`,
+        },
+        body,
+        signal: AbortSignal.timeout(BOT_TIMEOUT),
+      });
+    } catch (error) {
+      logger.warn('Slash command bot unreachable', { commandId: command._id.toString(), error: String(error) });
+      return ephemeral(`/${command.name} didn't respond in time`);
+    }
+
+    if (!response.ok) {
+      logger.warn('Slash command bot returned an error', {
+        commandId: command._id.toString(),
+        status: response.status,
+      });
+      return ephemeral(`/${command.name} failed with status ${response.status}`);
+    }
+
+    // An empty 200 acknowledges the command without a reply
+    const text = await response.text();
+    if (!text.trim()) {
+      return { type: 'none' };
+    }
+
+    let payload: unknown;
+    try {
+      payload = JSON.parse(text);
+    } catch {
+      payload = { text };
+    }
+
+    const parsed = commandResponseSchema.safeParse(payload);
+    if (!parsed.success) {
+      return ephemeral(`/${command.name} sent an invalid response`);
+    }
+
+    const reply = parsed.data;
+    const attachments = toAttachments(reply);
+    if (reply.response_type === 'ephemeral') {
+      return { type: 'ephemeral', text: reply.text || '', attachments };
+    }
+
+    const content = renderContent(reply.text, attachments);
+    if (!content) {
+      return { type: 'none' };
+    }
+
+    // In-channel replies come from the command's bot account
+    const bot = await this.userRepository.findOrCreateBridgeUser('bot', command._id.toString(), `/${command.name}`);
+    const message = await this.messageRepository.create({
+      chatId: context.chat._id,
+      senderId: bot._id,
+      content,
+      type: 'text',
+      metadata: {
+        command: {
+          name: command.name,
+          invokedBy: new Types.ObjectId(context.userId),
+          username: reply.username,
+          avatarUrl: reply.icon_url,
+          attachments,
+        },
+      },
+    });
+
+    await this.chatRepository.updateLastActivity(context.chat._id, message._id);
+
+    const populatedMessage = await this.messageRepository.findById(message._id);
+    socketManager.emitToChat(context.chat._id.toString(), 'message:new', populatedMessage);
+
+    return { type: 'posted', message };
+  }
+
+  // Commands usable in a chat, for client autocomplete
+  async listForChat(chat: IChat): Promise<ReturnType<typeof serializeCommand>[]> {
+    const disabled = new Set(chat.disabledCommands || []);
+    const custom = chat.type === 'group'
+      ? await this.slashCommandRepository.findByChat(chat._id)
+      : [];
+
+    return [
+      ...this.getBuiltins().map(command => serializeCommand(command, !disabled.has(command.name))),
+      ...custom.map(command => serializeCommand(command, command.enabled)),
+    ];
+  }
+
+  // Turn built-ins off for a chat; names that aren't built-ins are rejected.
+  // With expectedVersion only that version of the chat is changed.
+  async setDisabledBuiltins(chatId: string, disabled: string[], expectedVersion?: number): Promise<IChat | null> {
+    const unknown = disabled.filter(name => !this.isBuiltin(name));
+    if (unknown.length > 0) {
+      throw new SlashCommandError(`Not a built-in command: ${unknown.map(name => `/${name}`).join(', ')}`, 400);
+    }
+
+    return await this.chatRepository.updateSettings(chatId, {
+      disabledCommands: Array.from(new Set(disabled)),
+    }, expectedVersion);
+  }
+
+  // Register a custom command; the signing secret is only returned here and
+  // on rotation
+  async create(chatId: string, createdBy: string, input: CreateSlashCommandInput): Promise<ISlashCommand> {
+    if (this.isBuiltin(input.name)) {
+      throw new SlashCommandError(`/${input.name} is a built-in command`, 409);
+    }
+    if (await this.slashCommandRepository.findByName(chatId, input.name)) {
+      throw new SlashCommandError(`/${input.name} already exists in this chat`, 409);
+    }
+
+    return await this.slashCommandRepository.create({
+      chatId: new Types.ObjectId(chatId),
+      name: input.name,
+      description: input.description,
+      usage: input.usage,
+      url: input.url,
+      signingSecret: crypto.randomBytes(32).toString('hex'),
+      createdBy: new Types.ObjectId(createdBy),
+    });
+  }
+
+  async update(command: ISlashCommand, input: UpdateSlashCommandInput): Promise<ISlashCommand | null> {
+    return await this.slashCommandRepository.update(command._id, {
+      ...(input.description !== undefined && { description: input.description ?? undefined }),
+      ...(input.usage !== undefined && { usage: input.usage ?? undefined }),
+      ...(input.url && { url: input.url }),
+      ...(input.enabled !== undefined && { enabled: input.enabled }),
+      ...(input.rotateSecret && { signingSecret: crypto.randomBytes(32).toString('hex') }),
+    } as Partial<ISlashCommand>);
+  }
+
+  async delete(command: ISlashCommand): Promise<boolean> {
+    return await this.slashCommandRepository.delete(command._id);
+  }
+}
+
+// Client-facing view of a built-in or custom command (never includes the
+// signing secret)
+export function serializeCommand(command: BuiltinCommand | ISlashCommand, enabled: boolean) {
+  const custom = '_id' in command;
+  return {
+    id: custom ? (command as ISlashCommand)._id.toString() : undefined,
+    name: command.name,
+    description: command.description,
+    usage: command.usage,
+    builtin: !custom,
+    enabled,
+    ...(custom && {
+      url: (command as ISlashCommand).url,
+      invocationCount: (command as ISlashCommand).invocationCount,
+      lastUsedAt: (command as ISlashCommand).lastUsedAt,
+      createdAt: (command as ISlashCommand).createdAt,
+    }),
+  };
+}
+
+export class SlashCommandError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'SlashCommandError';
+  }
+}
+
+export const slashCommandService = new SlashCommandService();
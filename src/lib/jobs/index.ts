@@ -0,0 +1,366 @@
+import os from 'os';
+import { randomBytes } from 'crypto';
+import { JobRepository } from '../database/repositories/job';
+import { Job, IJob, JobStatus } from '../database/models/job';
+import { changeStreamHub, ChangeStreamHandle, WakeTimer } from '../database/change-streams';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 5 * 1000;
+const SWEEP_INTERVAL = 60 * 1000; // while change streams report new jobs
+const HEARTBEAT_INTERVAL = 60 * 1000; // running jobs' locks are renewed this often
+const STALL_TIMEOUT = 10 * 60 * 1000; // without a heartbeat from the worker
+const CONCURRENCY = 10; // jobs running at once per process
+const DEFAULT_MAX_ATTEMPTS = 5;
+const DEFAULT_TIMEOUT = 60 * 1000;
+const DEFAULT_BACKOFF = 30 * 1000; // doubled after every failed attempt
+const MAX_BACKOFF = 60 * 60 * 1000;
+
+export interface JobDefinition {
+  handler: (payload: Record<string, any>, job: IJob) => Promise<void>;
+  maxAttempts?: number;
+  timeout?: number; // ms
+  backoff?: number; // ms before the first retry
+  concurrency?: number; // jobs of this kind running at once per process
+  onFailed?: (payload: Record<string, any>, reason: string) => Promise<void>; // once attempts run out
+}
+
+export interface EnqueueOptions {
+  runAt?: Date;
+  delay?: number; // ms; ignored when runAt is given
+  maxAttempts?: number;
+}
+
+// Background work that has to survive a restart: pushes, thumbnails, chat
+// imports, bulk moderation and the like. Jobs are stored in Mongo and
+// claimed atomically, so any number of processes can run workers; a process
+// only claims jobs it has a handler for. Failed runs are retried with
+// exponential backoff until maxAttempts, then kept as failed for an admin to
+// retry or cancel. A running job's lock is renewed every HEARTBEAT_INTERVAL;
+// jobs whose worker died are released after STALL_TIMEOUT and run again, so
+// handlers must be safe to repeat. Only the worker holding a job can finish
+// it.
+//
+// Like the reminder worker it wakes when the next job is due, learning of
+// new ones from a change stream, and polls while that is unavailable.
+export class JobQueue {
+  private jobRepository = new JobRepository();
+  private definitions = new Map<string, JobDefinition>();
+  private workerId = `${os.hostname()}:${process.pid}:${randomBytes(3).toString('hex')}`;
+  private timer: NodeJS.Timeout | null = null;
+  private wake = new WakeTimer(() => this.tick());
+  private changes: ChangeStreamHandle | null = null;
+  private ticking = false;
+  private running = 0;
+  private runningByName = new Map<string, number>();
+
+  constructor() {
+    this.register('push:reminder', {
+      handler: async ({ userId, reminderId, text, chatId }) => {
+        const user = await this.loadRecipient(userId);
+        if (!user) return;
+        const { pushNotificationService } = await import('../communication/push-notifications');
+        await pushNotificationService.sendReminderNotification(user, text, reminderId, chatId);
+      },
+      maxAttempts: 3,
+    });
+
+    this.register('push:scheduled-call', {
+      handler: async ({ userId, scheduledCallId, stage }) => {
+        const [user, { scheduledCallService }, { pushNotificationService }] = await Promise.all([
+          this.loadRecipient(userId),
+          import('../webrtc/scheduled-calls'),
+          import('../communication/push-notifications'),
+        ]);
+        const scheduledCall = await scheduledCallService.getScheduledCall(scheduledCallId);
+        if (!user || !scheduledCall || scheduledCall.status === 'cancelled') return;
+        await pushNotificationService.sendScheduledCallNotification(user, scheduledCallService.pushSummary(scheduledCall), stage);
+      },
+      maxAttempts: 3,
+    });
+
+    this.register('push:group-message-rejected', {
+      handler: async ({ userId, groupName, groupId, messageId, reason }) => {
+        const user = await this.loadRecipient(userId);
+        if (!user) return;
+        const { pushNotificationService } = await import('../communication/push-notifications');
+        await pushNotificationService.sendGroupMessageRejected(user, groupName, groupId, messageId, reason);
+      },
+      maxAttempts: 3,
+    });
+
+    this.register('push:call-rating', {
+      handler: async ({ userId, callId, callType, chatId }) => {
+        const user = await this.loadRecipient(userId);
+        if (!user) return;
+        const { pushNotificationService } = await import('../communication/push-notifications');
+        await pushNotificationService.sendCallRatingRequest(user, callId, callType, chatId);
+      },
+      maxAttempts: 3,
+    });
+
+    this.register('media:thumbnail', {
+      handler: async ({ mediaId }) => {
+        const { mediaUploadService } = await import('../media/upload');
+        await mediaUploadService.generateThumbnail(mediaId);
+      },
+      timeout: 5 * 60 * 1000,
+    });
+
+    // Archives of up to 500MB; a failed import is final, only a worker that
+    // died mid-import is retried
+    this.register('import:run', {
+      handler: async ({ importJobId }) => {
+        const { importService } = await import('../import');
+        await importService.process(importJobId);
+      },
+      maxAttempts: 3,
+      timeout: 2 * 60 * 60 * 1000,
+      concurrency: 2,
+      onFailed: async ({ importJobId }) => {
+        const { importService } = await import('../import');
+        await importService.abandon(importJobId);
+      },
+    });
+
+    this.register('moderation:bulk-action', {
+      handler: async ({ bulkActionId }) => {
+        const { bulkActionService } = await import('../moderation/bulk-actions');
+        await bulkActionService.process(bulkActionId);
+      },
+      maxAttempts: 3,
+      timeout: 2 * 60 * 60 * 1000,
+      concurrency: 1,
+      onFailed: async ({ bulkActionId }) => {
+        const { bulkActionService } = await import('../moderation/bulk-actions');
+        await bulkActionService.abandon(bulkActionId);
+      },
+    });
+  }
+
+  register(name: string, definition: JobDefinition): void {
+    this.definitions.set(name, definition);
+  }
+
+  async enqueue(name: string, payload: Record<string, any>, options: EnqueueOptions = {}): Promise<IJob> {
+    const definition = this.definitions.get(name);
+    if (!definition) {
+      throw new JobError(`Unknown job: ${name}`, 400);
+    }
+
+    const job = await this.jobRepository.create({
+      name,
+      payload,
+      runAt: options.runAt || new Date(Date.now() + (options.delay || 0)),
+      maxAttempts: options.maxAttempts || definition.maxAttempts || DEFAULT_MAX_ATTEMPTS,
+    });
+
+    metricsCollector.incrementCounter('jobs_enqueued', 1, { name });
+    if (this.timer) this.wake.schedule(job.runAt);
+    return job;
+  }
+
+  // Start the worker. Safe to run on several instances: jobs are claimed
+  // atomically.
+  start(): void {
+    if (this.timer) return;
+
+    this.schedulePolling(POLL_INTERVAL);
+    this.changes = changeStreamHub.watch({
+      name: 'jobs',
+      model: Job,
+      operations: ['insert'],
+      handler: change => {
+        const job = 'fullDocument' in change ? change.fullDocument : undefined;
+        if (job?.status === 'pending' && this.definitions.has(job.name)) this.wake.schedule(job.runAt);
+      },
+      onStatus: live => {
+        if (this.timer) this.schedulePolling(live ? SWEEP_INTERVAL : POLL_INTERVAL);
+      },
+    });
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+    this.wake.clear();
+    this.changes?.close();
+    this.changes = null;
+  }
+
+  async list(filter: { status?: JobStatus; name?: string }, limit?: number, offset?: number): Promise<IJob[]> {
+    return await this.jobRepository.list(filter, limit, offset);
+  }
+
+  async getStats() {
+    return {
+      workerId: this.workerId,
+      running: this.running,
+      handlers: Array.from(this.definitions.keys()),
+      counts: await this.jobRepository.countByStatus(),
+    };
+  }
+
+  async retry(jobId: string): Promise<IJob> {
+    const job = await this.jobRepository.retry(jobId);
+    if (!job) {
+      throw new JobError('Only failed or cancelled jobs can be retried', 409);
+    }
+    if (this.timer) this.wake.schedule(job.runAt);
+    return job;
+  }
+
+  async cancel(jobId: string): Promise<void> {
+    if (!(await this.jobRepository.cancel(jobId))) {
+      throw new JobError('Only pending or failed jobs can be cancelled', 409);
+    }
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const stalled = await this.jobRepository.requeueStalled(new Date(Date.now() - STALL_TIMEOUT));
+      if (stalled.requeued > 0 || stalled.failed.length > 0) {
+        logger.warn('Released stalled jobs', { requeued: stalled.requeued, failed: stalled.failed.length });
+      }
+      for (const job of stalled.failed) {
+        await this.notifyFailed(job, 'Worker stalled');
+      }
+
+      let names = this.claimableNames();
+      while (this.running < CONCURRENCY && names.length > 0) {
+        const job = await this.jobRepository.claimDue(names, this.workerId);
+        if (!job) break;
+
+        this.running++;
+        this.runningByName.set(job.name, (this.runningByName.get(job.name) || 0) + 1);
+        this.run(job).finally(() => {
+          this.running--;
+          this.runningByName.set(job.name, this.runningByName.get(job.name)! - 1);
+          // A slot freed up; there may be more due work
+          if (this.timer) this.wake.schedule(new Date());
+        });
+        names = this.claimableNames();
+      }
+
+      // While every slot is busy, finishing jobs wake the worker instead
+      if (this.running < CONCURRENCY && names.length > 0) {
+        this.wake.schedule(await this.jobRepository.findNextRunAt(names));
+      }
+    } catch (error) {
+      logger.error('Job worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  // Kinds this process has a handler for and a free slot to run
+  private claimableNames(): string[] {
+    return Array.from(this.definitions.entries())
+      .filter(([name, definition]) => !definition.concurrency || (this.runningByName.get(name) || 0) < definition.concurrency)
+      .map(([name]) => name);
+  }
+
+  private schedulePolling(interval: number): void {
+    if (this.timer) clearInterval(this.timer);
+    this.timer = setInterval(() => this.tick(), interval);
+    this.timer.unref();
+  }
+
+  private async run(job: IJob): Promise<void> {
+    const definition = this.definitions.get(job.name)!;
+    const startTime = Date.now();
+    metricsCollector.recordHistogram('jobs_wait_ms', startTime - job.runAt.getTime(), { name: job.name });
+
+    // Keep the lock while the job runs, so long jobs aren't taken for stalled
+    const heartbeat = setInterval(() => {
+      this.jobRepository.renewLock(job._id, this.workerId).catch(error => {
+        logger.error('Failed to renew job lock', error, { jobId: job._id.toString() });
+      });
+    }, HEARTBEAT_INTERVAL);
+    heartbeat.unref();
+
+    try {
+      await this.withTimeout(definition.handler(job.payload, job), definition.timeout || DEFAULT_TIMEOUT);
+      await this.jobRepository.markCompleted(job._id, this.workerId);
+      metricsCollector.incrementCounter('jobs_completed', 1, { name: job.name });
+    } catch (error) {
+      const retry = job.attempts < job.maxAttempts;
+      const backoff = Math.min((definition.backoff || DEFAULT_BACKOFF) * 2 ** (job.attempts - 1), MAX_BACKOFF);
+      logger.error('Job failed', error, { jobId: job._id.toString(), name: job.name, attempt: job.attempts, retry });
+      metricsCollector.incrementCounter('jobs_failed', 1, { name: job.name, final: String(!retry) });
+
+      const reason = error instanceof Error ? error.message : 'Unknown error';
+      const recorded = await this.jobRepository.markAttemptFailed(
+        job._id,
+        this.workerId,
+        reason,
+        retry ? new Date(Date.now() + backoff) : null
+      ).catch(markError => {
+        logger.error('Failed to record job failure', markError, { jobId: job._id.toString() });
+        return false;
+      });
+      if (recorded && !retry) {
+        await this.notifyFailed(job, reason);
+      }
+    } finally {
+      clearInterval(heartbeat);
+      metricsCollector.recordHistogram('jobs_duration_ms', Date.now() - startTime, { name: job.name });
+    }
+  }
+
+  // Let the job's owner clean up once it won't be tried again
+  private async notifyFailed(job: Pick<IJob, '_id' | 'name' | 'payload'>, reason: string): Promise<void> {
+    const onFailed = this.definitions.get(job.name)?.onFailed;
+    if (!onFailed) return;
+
+    await onFailed(job.payload, reason).catch(error => {
+      logger.error('Job failure handler error', error, { jobId: job._id.toString(), name: job.name });
+    });
+  }
+
+  private withTimeout(work: Promise<void>, timeout: number): Promise<void> {
+    let timer: NodeJS.Timeout;
+    const expired = new Promise<never>((_, reject) => {
+      timer = setTimeout(() => reject(new Error('Job timed out')), timeout);
+    });
+    return Promise.race([work, expired]).finally(() => clearTimeout(timer));
+  }
+
+  // Users who left or were banned since the job was queued get nothing
+  private async loadRecipient(userId: string) {
+    const { UserRepository } = await import('../database/repositories/user');
+    const user = await new UserRepository().findById(userId);
+    return user && !user.isBanned ? user : null;
+  }
+}
+
+export class JobError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'JobError';
+  }
+}
+
+export function serializeJob(job: IJob) {
+  return {
+    id: job._id.toString(),
+    name: job.name,
+    payload: job.payload,
+    status: job.status,
+    runAt: job.runAt,
+    attempts: job.attempts,
+    maxAttempts: job.maxAttempts,
+    lockedBy: job.lockedBy,
+    lastError: job.lastError,
+    finishedAt: job.finishedAt,
+    createdAt: job.createdAt,
+  };
+}
+
+export const jobQueue = new JobQueue();
@@ -0,0 +1,77 @@
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+interface CdnConfig {
+  baseUrl?: string;
+  purgeUrl?: string;
+  purgeToken?: string;
+}
+
+// Stored keys are never reused, so files can be cached for as long as they
+// exist. Originals are private to chat members; thumbnails are served from
+// the CDN.
+export const MEDIA_CACHE_CONTROL = 'private, max-age=86400, immutable';
+export const THUMBNAIL_CACHE_CONTROL = 'public, max-age=31536000, immutable';
+
+// Whether a conditional request already has this version
+export function etagMatches(ifNoneMatch: string | null, etag: string): boolean {
+  if (!ifNoneMatch) return false;
+  return ifNoneMatch.split(',').some(tag => {
+    const value = tag.trim().replace(/^W\//, '');
+    return value === etag || value === '*';
+  });
+}
+
+// Public URLs through a CDN in front of the bucket, and purging deleted
+// files from its cache. Without a base URL everything is served through
+// signed bucket URLs as before.
+class CdnService {
+  private baseUrl?: string;
+
+  constructor(private config: CdnConfig) {
+    this.baseUrl = config.baseUrl?.replace(/\/+$/, '');
+  }
+
+  isEnabled(): boolean {
+    return !!this.baseUrl;
+  }
+
+  // CDN URL for a stored key, or null without a CDN
+  getUrl(key: string): string | null {
+    if (!this.baseUrl) return null;
+    return `${this.baseUrl}/${key.split('/').map(encodeURIComponent).join('/')}`;
+  }
+
+  // Evict deleted files from the CDN cache. Best effort; a failure is only
+  // logged since the files are gone from the bucket either way.
+  async purge(keys: string[]): Promise<void> {
+    const files = keys.map(key => this.getUrl(key)).filter((url): url is string => !!url);
+    if (!this.config.purgeUrl || files.length === 0) return;
+
+    try {
+      const response = await fetch(this.config.purgeUrl, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          ...(this.config.purgeToken && { Authorization: `Bearer ${this.config.purgeToken}` }),
+        },
+        body: JSON.stringify({ files }),
+        signal: AbortSignal.timeout(10000),
+      });
+      if (!response.ok) {
+        throw new Error(`CDN purge failed with status ${response.status}`);
+      }
+
+      metricsCollector.incrementCounter('cdn_purged_files', files.length);
+    } catch (error) {
+      metricsCollector.incrementCounter('cdn_purge_errors', 1);
+      logger.error('CDN purge error', error, { files });
+    }
+  }
+}
+
+export const cdnService = new CdnService({
+  baseUrl: process.env.CDN_BASE_URL,
+  purgeUrl: process.env.CDN_PURGE_URL,
+  purgeToken: process.env.CDN_PURGE_TOKEN,
+});
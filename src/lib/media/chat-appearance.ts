@@ -0,0 +1,115 @@
+import { Types } from 'mongoose';
+import { ChatRepository } from '../database/repositories/chat';
+import { MediaRepository } from '../database/repositories/media';
+import { IChat } from '../database/models/chat';
+import { IMedia } from '../database/models/media';
+import { ChatAppearanceInput } from '../database/schemas/chat';
+import { findParticipantSettings } from '../communication/notification-preferences';
+import { storageService } from './storage';
+import { logger } from '../monitoring/logging';
+
+const WALLPAPER_URL_TTL = 24 * 60 * 60; // seconds
+
+export interface ChatAppearance {
+  wallpaper?: string;
+  wallpaperMedia?: { id: string; url: string; thumbnailUrl?: string };
+  themeColor?: string;
+}
+
+// Per-user look of a chat: a built-in or uploaded wallpaper and a theme
+// color. Kept with the user's other per-chat settings on the server so a new
+// device restores it with the chat list. Uploaded wallpapers go through the
+// normal image upload and are referenced here by media ID.
+export class ChatAppearanceService {
+  private chatRepository = new ChatRepository();
+  private mediaRepository = new MediaRepository();
+
+  // The user's appearance of one chat
+  async get(chat: IChat, userId: string): Promise<ChatAppearance> {
+    const resolved = await this.resolveMany([chat], userId);
+    return resolved.get(chat._id.toString()) || {};
+  }
+
+  // The user's appearance of each chat, by chat ID. Uploaded wallpapers are
+  // looked up together and get fresh signed URLs.
+  async resolveMany(chats: IChat[], userId: string): Promise<Map<string, ChatAppearance>> {
+    const settings = chats.map(chat => ({ chat, settings: findParticipantSettings(chat, userId) }));
+    const mediaIds = settings
+      .map(({ settings }) => settings?.wallpaperMedia)
+      .filter((id): id is Types.ObjectId => !!id);
+
+    const media = new Map<string, IMedia>();
+    if (mediaIds.length > 0) {
+      for (const item of await this.mediaRepository.findByIds(mediaIds)) {
+        media.set(item._id.toString(), item);
+      }
+    }
+
+    const appearances = new Map<string, ChatAppearance>();
+    for (const { chat, settings: entry } of settings) {
+      const wallpaperMedia = entry?.wallpaperMedia ? media.get(entry.wallpaperMedia.toString()) : undefined;
+      appearances.set(chat._id.toString(), {
+        ...(entry?.wallpaper && { wallpaper: entry.wallpaper }),
+        ...(wallpaperMedia && { wallpaperMedia: await this.describe(wallpaperMedia) }),
+        ...(entry?.themeColor && { themeColor: entry.themeColor }),
+      });
+    }
+    return appearances;
+  }
+
+  // Change the user's appearance of a chat and sync it to their other
+  // devices. Null clears a setting.
+  async update(chatId: string, userId: string, input: ChatAppearanceInput): Promise<{ chat: IChat; appearance: ChatAppearance } | null> {
+    const { version, wallpaperMediaId, ...rest } = input;
+
+    if (wallpaperMediaId) {
+      const media = Types.ObjectId.isValid(wallpaperMediaId) ? await this.mediaRepository.findById(wallpaperMediaId) : null;
+      if (!media || media.uploadedBy.toString() !== userId) {
+        throw new ChatAppearanceError('Wallpaper file not found', 404);
+      }
+      if (media.type !== 'image') {
+        throw new ChatAppearanceError('Wallpaper must be an image', 400);
+      }
+    }
+
+    const chat = await this.chatRepository.updateParticipantSettings(chatId, userId, {
+      ...rest,
+      ...(wallpaperMediaId !== undefined && {
+        wallpaperMedia: wallpaperMediaId ? new Types.ObjectId(wallpaperMediaId) : null,
+      }),
+    }, version);
+    if (!chat) return null;
+
+    const appearance = await this.get(chat, userId);
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToUser(userId, 'chat:appearance:updated', {
+      chatId,
+      appearance,
+      version: findParticipantSettings(chat, userId)?.version ?? 0,
+    });
+
+    logger.info('Chat appearance updated', { userId, chatId, fields: Object.keys(input).filter(field => field !== 'version') });
+
+    return { chat, appearance };
+  }
+
+  private async describe(media: IMedia): Promise<NonNullable<ChatAppearance['wallpaperMedia']>> {
+    let url = media.url;
+    try {
+      url = await storageService.getFileUrl(media.filename, WALLPAPER_URL_TTL);
+    } catch {
+      logger.warn('Could not sign wallpaper URL', { mediaId: media._id.toString() });
+    }
+    return { id: media._id.toString(), url, thumbnailUrl: media.thumbnailUrl };
+  }
+}
+
+export class ChatAppearanceError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ChatAppearanceError';
+  }
+}
+
+export const chatAppearanceService = new ChatAppearanceService();
@@ -4,6 +4,7 @@ import { promisify } from 'util';
 import fs from 'fs';
 import path from 'path';
 import os from 'os';
+import { IMedia } from '../database/models/media';
 
 interface CompressionOptions {
   quality?: number;
@@ -23,6 +24,14 @@ interface AudioCompressionOptions {
   maxDuration?: number; // in seconds
 }
 
+// ffprobe reports frame rates as fractions, e.g. "30000/1001"
+function parseFrameRate(value?: string): number | undefined {
+  if (!value) return undefined;
+  const [numerator, denominator = '1'] = value.split('/');
+  const rate = Number(numerator) / Number(denominator);
+  return isFinite(rate) && rate > 0 ? Math.round(rate * 100) / 100 : undefined;
+}
+
 export class MediaCompressor {
   private static tempDir = os.tmpdir();
 
@@ -251,6 +260,24 @@ export class MediaCompressor {
     });
   }
 
+  // Metadata in the shape stored on media records. Takes a path or URL;
+  // ffprobe only reads the headers, so a signed URL avoids a download.
+  static async getMediaInfo(source: string): Promise<NonNullable<IMedia['metadata']>> {
+    const probe = await this.getMediaMetadata(source);
+    const bitrate = parseInt(probe.bitRate, 10);
+
+    return {
+      duration: probe.duration ? Math.round(probe.duration * 1000) / 1000 : undefined,
+      ...(probe.video?.width && probe.video?.height && {
+        dimensions: { width: probe.video.width, height: probe.video.height },
+      }),
+      bitrate: isNaN(bitrate) ? undefined : bitrate,
+      videoCodec: probe.video?.codec,
+      audioCodec: probe.audio?.codec,
+      frameRate: parseFrameRate(probe.video?.frameRate),
+    };
+  }
+
   // Clean up temporary files
   static async cleanupTempFiles(filePaths: string[]): Promise<void> {
     const unlinkPromises = filePaths.map(async (filePath) => {
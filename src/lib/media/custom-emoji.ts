@@ -0,0 +1,247 @@
+import { Types } from 'mongoose';
+import { GroupEmojiRepository } from '../database/repositories/group-emoji';
+import { MediaRepository } from '../database/repositories/media';
+import { IGroupEmoji } from '../database/models/group-emoji';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { GROUP_CONSTANTS } from '../utils/constants';
+import { mediaUploadService } from './upload';
+import { storageService } from './storage';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const SHORTCODE_PATTERN = /^[a-z0-9_]{2,32}$/;
+const SHORTCODE_IN_TEXT = /:([a-z0-9_]{2,32}):/g;
+const MAX_EMOJI_PER_MESSAGE = 50;
+const EMOJI_URL_TTL = 24 * 60 * 60; // seconds
+
+export interface ResolvedEmoji {
+  id: string;
+  shortcode: string;
+  url?: string; // absent once removed by a moderator
+  removed?: boolean;
+}
+
+export interface EmojiUpload {
+  buffer: Buffer;
+  filename: string;
+  mimeType: string;
+}
+
+// Custom emoji of a group. Admins upload small images under a shortcode;
+// members write :shortcode: in messages and react with them. Messages keep
+// the IDs of the emoji they used, and responses resolve them to URLs, so
+// renaming or removing one doesn't rewrite history. Uploads go through the
+// image blocklist and platform moderators can take any emoji down.
+export class CustomEmojiService {
+  private groupEmojiRepository = new GroupEmojiRepository();
+  private mediaRepository = new MediaRepository();
+
+  async list(groupId: string): Promise<IGroupEmoji[]> {
+    return await this.groupEmojiRepository.listActive(groupId);
+  }
+
+  // Add an emoji to the group
+  async upload(groupId: string, userId: string, shortcode: string, file: EmojiUpload): Promise<IGroupEmoji> {
+    if (!SHORTCODE_PATTERN.test(shortcode)) {
+      throw new CustomEmojiError('Shortcode must be 2-32 lowercase letters, digits or underscores', 400);
+    }
+    if (!(GROUP_CONSTANTS.CUSTOM_EMOJI_MIME_TYPES as readonly string[]).includes(file.mimeType)) {
+      throw new CustomEmojiError('Emoji must be a PNG, GIF or WebP image', 400);
+    }
+    if (file.buffer.length > GROUP_CONSTANTS.CUSTOM_EMOJI_MAX_SIZE) {
+      throw new CustomEmojiError(`Emoji must be at most ${GROUP_CONSTANTS.CUSTOM_EMOJI_MAX_SIZE / 1024}KB`, 400);
+    }
+    if (await this.groupEmojiRepository.countActive(groupId) >= GROUP_CONSTANTS.MAX_CUSTOM_EMOJI) {
+      throw new CustomEmojiError(`Groups can have at most ${GROUP_CONSTANTS.MAX_CUSTOM_EMOJI} custom emoji`, 409);
+    }
+    if ((await this.groupEmojiRepository.findActiveByShortcodes(groupId, [shortcode])).length > 0) {
+      throw new CustomEmojiError(`:${shortcode}: is already taken`, 409);
+    }
+
+    let media;
+    try {
+      ({ media } = await mediaUploadService.uploadFile(file.buffer, file.filename, file.mimeType, userId, 'image', {
+        generateThumbnail: false,
+      }));
+    } catch (error) {
+      // Blocklisted images and invalid files
+      if (error instanceof Error && error.message.includes('File validation failed')) {
+        throw new CustomEmojiError(error.message.replace(/^Upload failed: /, ''), 400);
+      }
+      throw error;
+    }
+
+    let emoji: IGroupEmoji;
+    try {
+      emoji = await this.groupEmojiRepository.create({
+        chatId: new Types.ObjectId(groupId),
+        shortcode,
+        media: media._id,
+        createdBy: new Types.ObjectId(userId),
+      });
+    } catch (error: any) {
+      await mediaUploadService.deleteFile(media._id.toString(), userId).catch(() => undefined);
+      if (error?.code === 11000) {
+        throw new CustomEmojiError(`:${shortcode}: is already taken`, 409);
+      }
+      throw error;
+    }
+
+    metricsCollector.incrementCounter('custom_emoji_uploaded', 1);
+    logger.info('Custom emoji added', { groupId, userId, shortcode, emojiId: emoji._id.toString() });
+    await this.notifyChanged(groupId);
+
+    return emoji;
+  }
+
+  // Remove an emoji from the group (group admins)
+  async remove(groupId: string, emojiId: string, userId: string): Promise<IGroupEmoji> {
+    const emoji = Types.ObjectId.isValid(emojiId)
+      ? await this.groupEmojiRepository.remove(emojiId, { removedBy: userId }, groupId)
+      : null;
+    if (!emoji) {
+      throw new CustomEmojiError('Emoji not found', 404);
+    }
+
+    logger.info('Custom emoji removed', { groupId, userId, emojiId });
+    await this.notifyChanged(groupId);
+    return emoji;
+  }
+
+  // Take an emoji down (platform moderators). Its image stops resolving,
+  // also in messages that already used it.
+  async moderate(emojiId: string, adminId: string, reason?: string): Promise<IGroupEmoji> {
+    const emoji = Types.ObjectId.isValid(emojiId)
+      ? await this.groupEmojiRepository.remove(emojiId, { removedBy: adminId, removedByModerator: true, removedReason: reason })
+      : null;
+    if (!emoji) {
+      throw new CustomEmojiError('Emoji not found or already removed', 404);
+    }
+
+    metricsCollector.incrementCounter('custom_emoji_moderated', 1);
+    logger.info('Custom emoji taken down', { emojiId, adminId, reason, groupId: emoji.chatId.toString() });
+    await this.notifyChanged(emoji.chatId.toString());
+    return emoji;
+  }
+
+  async listForModeration(filters: { chatId?: string; status?: IGroupEmoji['status'] }, limit: number, offset: number) {
+    return await this.groupEmojiRepository.listForModeration(filters, limit, offset);
+  }
+
+  // IDs of the group's emoji written as :shortcode: in a message
+  async findInText(chat: IChat, content: string): Promise<Types.ObjectId[]> {
+    if (chat.type !== 'group' || !content) return [];
+
+    const shortcodes = Array.from(new Set(Array.from(content.matchAll(SHORTCODE_IN_TEXT), match => match[1])))
+      .slice(0, MAX_EMOJI_PER_MESSAGE);
+    if (shortcodes.length === 0) return [];
+
+    const emoji = await this.groupEmojiRepository.findActiveByShortcodes(chat._id, shortcodes);
+    return emoji.map(item => item._id);
+  }
+
+  // The reaction to store for a custom emoji given by ID or as :shortcode:;
+  // null when the reaction is a plain emoji
+  async resolveReaction(
+    chat: IChat,
+    emoji: string,
+    customEmojiId?: string
+  ): Promise<{ emoji: string; customEmojiId: Types.ObjectId } | null> {
+    const shortcode = /^:([a-z0-9_]{2,32}):$/.exec(emoji)?.[1];
+    if (!customEmojiId && !shortcode) return null;
+
+    const found = customEmojiId
+      ? (Types.ObjectId.isValid(customEmojiId) ? await this.groupEmojiRepository.findById(customEmojiId) : null)
+      : (await this.groupEmojiRepository.findActiveByShortcodes(chat._id, [shortcode!]))[0];
+    if (!found || found.status !== 'active' || found.chatId.toString() !== chat._id.toString()) {
+      if (customEmojiId) throw new CustomEmojiError('Emoji not found in this group', 404);
+      return null;
+    }
+
+    return { emoji: `:${found.shortcode}:`, customEmojiId: found._id };
+  }
+
+  // Resolve emoji IDs to shortcodes and image URLs
+  async resolve(ids: (string | Types.ObjectId)[]): Promise<Map<string, ResolvedEmoji>> {
+    const resolved = new Map<string, ResolvedEmoji>();
+    const unique = Array.from(new Set(ids.map(id => id.toString())));
+    if (unique.length === 0) return resolved;
+
+    const emoji = await this.groupEmojiRepository.findByIds(unique);
+    const media = new Map((await this.mediaRepository.findByIds(emoji.map(item => item.media)))
+      .map(item => [item._id.toString(), item]));
+
+    for (const item of emoji) {
+      const image = media.get(item.media.toString());
+      const takenDown = !!item.removedByModerator || !image;
+      resolved.set(item._id.toString(), {
+        id: item._id.toString(),
+        shortcode: item.shortcode,
+        ...(!takenDown && { url: await this.signUrl(image!.filename, image!.url) }),
+        ...(item.status === 'removed' && { removed: true }),
+      });
+    }
+    return resolved;
+  }
+
+  // Messages with a customEmoji map of every custom emoji they show, in the
+  // text or as reactions
+  async attach<T extends IMessage | Record<string, any>>(messages: T[]): Promise<Record<string, any>[]> {
+    const objects = messages.map(message =>
+      typeof (message as IMessage).toObject === 'function' ? (message as IMessage).toObject() : { ...message }
+    );
+    const idsOf = (message: Record<string, any>): string[] => [
+      ...(message.metadata?.customEmoji || []),
+      ...(message.reactions || []).map((reaction: any) => reaction.customEmojiId).filter(Boolean),
+    ].map((id: any) => id.toString());
+
+    const resolved = await this.resolve(objects.flatMap(idsOf));
+    if (resolved.size === 0) return objects;
+
+    return objects.map(message => {
+      const ids = idsOf(message).filter(id => resolved.has(id));
+      if (ids.length === 0) return message;
+      return { ...message, customEmoji: Object.fromEntries(ids.map(id => [id, resolved.get(id)!])) };
+    });
+  }
+
+  private async signUrl(key: string, fallback: string): Promise<string> {
+    try {
+      return await storageService.getFileUrl(key, EMOJI_URL_TTL);
+    } catch {
+      return fallback;
+    }
+  }
+
+  // Members refresh the group's emoji picker
+  private async notifyChanged(groupId: string): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToChat(groupId, 'group:emoji:updated', { groupId });
+  }
+}
+
+export function serializeGroupEmoji(emoji: IGroupEmoji, resolved?: ResolvedEmoji) {
+  return {
+    id: emoji._id.toString(),
+    shortcode: emoji.shortcode,
+    url: resolved?.url,
+    createdBy: emoji.createdBy,
+    status: emoji.status,
+    ...(emoji.status === 'removed' && {
+      removedAt: emoji.removedAt,
+      removedByModerator: !!emoji.removedByModerator,
+      removedReason: emoji.removedReason,
+    }),
+    createdAt: emoji.createdAt,
+  };
+}
+
+export class CustomEmojiError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'CustomEmojiError';
+  }
+}
+
+export const customEmojiService = new CustomEmojiService();
@@ -0,0 +1,167 @@
+import { Types } from 'mongoose';
+import { BlockedMediaHashRepository } from '../database/repositories/blocked-media-hash';
+import { MediaRepository } from '../database/repositories/media';
+import { IBlockedMediaHash } from '../database/models/blocked-media-hash';
+import { AddBlockedHashInput } from '../database/schemas/moderation';
+import { storageService } from './storage';
+import { computePerceptualHash, hammingDistance } from './perceptual-hash';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Differing bits still treated as the same picture. Re-encodes and light
+// edits stay well under this; unrelated images average around 32.
+export const MATCH_THRESHOLD = 10;
+const CACHE_TTL = 60 * 1000; // other instances pick up changes within this
+
+export interface HashMatch {
+  entryId: string;
+  hash: string;
+  distance: number;
+}
+
+// Blocklist of perceptual hashes of known abusive images. Every image upload
+// is hashed and refused when it is a near duplicate of a listed one, so the
+// same spam image can't get through by being re-saved or slightly altered.
+export class HashBlocklistService {
+  private blockedMediaHashRepository = new BlockedMediaHashRepository();
+  private mediaRepository = new MediaRepository();
+  private hashes: Array<{ id: string; hash: string }> = [];
+  private loadedAt = 0;
+
+  // Hash an image and check it against the blocklist. Images that can't be
+  // decoded get no hash and are let through to the usual validation.
+  async inspect(image: Buffer): Promise<{ hash?: string; match: HashMatch | null }> {
+    let hash: string;
+    try {
+      hash = await computePerceptualHash(image);
+    } catch (error) {
+      logger.warn('Perceptual hashing failed', { error: (error as Error).message });
+      return { match: null };
+    }
+
+    return { hash, match: await this.match(hash) };
+  }
+
+  // Closest listed hash within the threshold
+  async match(hash: string): Promise<HashMatch | null> {
+    const hashes = await this.getHashes();
+
+    let best: HashMatch | null = null;
+    for (const entry of hashes) {
+      const distance = hammingDistance(hash, entry.hash);
+      if (distance <= MATCH_THRESHOLD && (!best || distance < best.distance)) {
+        best = { entryId: entry.id, hash: entry.hash, distance };
+        if (distance === 0) break;
+      }
+    }
+    return best;
+  }
+
+  // Count and log a refused upload
+  async recordMatch(match: HashMatch, uploadedBy: string): Promise<void> {
+    metricsCollector.incrementCounter('media_blocked_hash_matches', 1);
+    logger.warn('Upload matched blocked media hash', {
+      entryId: match.entryId,
+      distance: match.distance,
+      userId: uploadedBy,
+    });
+
+    await this.blockedMediaHashRepository.recordMatch(match.entryId).catch(error => {
+      logger.error('Failed to record blocked hash match', error, { entryId: match.entryId });
+    });
+  }
+
+  // Add a hash given directly or taken from an uploaded image
+  async add(input: AddBlockedHashInput, adminId: string): Promise<{ entry: IBlockedMediaHash; existingUploads: number }> {
+    let hash = input.hash?.toLowerCase();
+    let sourceMediaId: Types.ObjectId | undefined;
+
+    if (input.mediaId) {
+      const media = await this.mediaRepository.findById(input.mediaId);
+      if (!media) {
+        throw new HashBlocklistError('Media not found', 404);
+      }
+      if (media.type !== 'image') {
+        throw new HashBlocklistError('Only images can be added to the blocklist', 400);
+      }
+
+      hash = media.perceptualHash;
+      if (!hash) {
+        // Uploaded before hashing was introduced
+        const image = await storageService.getFileBuffer(media.filename);
+        try {
+          hash = await computePerceptualHash(image);
+        } catch {
+          throw new HashBlocklistError('Media could not be decoded as an image', 422);
+        }
+      }
+      sourceMediaId = media._id;
+    }
+
+    if (await this.blockedMediaHashRepository.findByHash(hash!)) {
+      throw new HashBlocklistError('Hash is already on the blocklist', 409);
+    }
+
+    const entry = await this.blockedMediaHashRepository.create({
+      hash,
+      reason: input.reason,
+      category: input.category,
+      sourceMediaId,
+      addedBy: new Types.ObjectId(adminId),
+    });
+    this.loadedAt = 0;
+
+    metricsCollector.incrementCounter('media_blocked_hashes_added', 1);
+    logger.info('Media hash blocklisted', { entryId: entry._id.toString(), hash, sourceMediaId, adminId });
+
+    return { entry, existingUploads: await this.mediaRepository.countByPerceptualHash(hash!) };
+  }
+
+  async remove(entryId: string, adminId: string): Promise<void> {
+    const deleted = Types.ObjectId.isValid(entryId) && await this.blockedMediaHashRepository.delete(entryId);
+    if (!deleted) {
+      throw new HashBlocklistError('Blocklist entry not found', 404);
+    }
+    this.loadedAt = 0;
+
+    logger.info('Media hash removed from blocklist', { entryId, adminId });
+  }
+
+  async list(limit: number = 50, offset: number = 0) {
+    return await this.blockedMediaHashRepository.findAll(limit, offset);
+  }
+
+  private async getHashes(): Promise<Array<{ id: string; hash: string }>> {
+    if (Date.now() - this.loadedAt < CACHE_TTL) {
+      return this.hashes;
+    }
+
+    const entries = await this.blockedMediaHashRepository.findAllHashes();
+    this.hashes = entries.map(entry => ({ id: entry._id.toString(), hash: entry.hash }));
+    this.loadedAt = Date.now();
+    return this.hashes;
+  }
+}
+
+export function serializeBlockedMediaHash(entry: IBlockedMediaHash) {
+  return {
+    id: entry._id.toString(),
+    hash: entry.hash,
+    reason: entry.reason,
+    category: entry.category,
+    sourceMediaId: entry.sourceMediaId?.toString(),
+    addedBy: entry.addedBy,
+    matchCount: entry.matchCount,
+    lastMatchedAt: entry.lastMatchedAt,
+    createdAt: entry.createdAt,
+  };
+}
+
+export class HashBlocklistError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'HashBlocklistError';
+  }
+}
+
+export const hashBlocklistService = new HashBlocklistService();
@@ -0,0 +1,255 @@
+import { PassThrough, Readable } from 'stream';
+
+export interface StreamedFilePart {
+  fieldName: string;
+  filename: string;
+  mimeType: string;
+  stream: Readable;
+}
+
+export interface StreamedMultipart {
+  fields: Record<string, string>;
+  file: StreamedFilePart;
+}
+
+const MAX_FIELD_SIZE = 64 * 1024; // 64KB per text field
+const MAX_HEADER_SIZE = 16 * 1024; // 16KB per part header block
+const CRLF = Buffer.from('\r\n');
+const HEADER_END = Buffer.from('\r\n\r\n');
+
+// Extract the boundary from a multipart/form-data content type
+export function getMultipartBoundary(contentType: string | null): string | null {
+  if (!contentType || !contentType.toLowerCase().startsWith('multipart/form-data')) {
+    return null;
+  }
+  const match = contentType.match(/boundary=(?:"([^"]+)"|([^;]+))/i);
+  return match ? (match[1] || match[2]).trim() : null;
+}
+
+// Wait for a backpressured file stream to drain. Rejects if the consumer
+// errors or closes the stream, or the request is aborted, so a stalled
+// consumer cannot hold the reader (and its upload slot) forever.
+function waitForDrain(stream: PassThrough, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve, reject) => {
+    const cleanup = () => {
+      stream.off('drain', onDrain);
+      stream.off('error', onError);
+      stream.off('close', onClose);
+      signal?.removeEventListener('abort', onAbort);
+    };
+    const onDrain = () => {
+      cleanup();
+      resolve();
+    };
+    const onError = (error: Error) => {
+      cleanup();
+      reject(error);
+    };
+    const onClose = () => {
+      cleanup();
+      reject(new Error('Multipart file stream closed before the upload finished'));
+    };
+    const onAbort = () => {
+      cleanup();
+      reject(new Error('Multipart upload aborted'));
+    };
+
+    if (signal?.aborted) {
+      onAbort();
+      return;
+    }
+    stream.on('drain', onDrain);
+    stream.on('error', onError);
+    stream.on('close', onClose);
+    signal?.addEventListener('abort', onAbort);
+  });
+}
+
+function parsePartHeaders(raw: string): { name?: string; filename?: string; contentType?: string } {
+  const result: { name?: string; filename?: string; contentType?: string } = {};
+
+  raw.split('\r\n').forEach(line => {
+    const separator = line.indexOf(':');
+    if (separator === -1) return;
+
+    const key = line.slice(0, separator).trim().toLowerCase();
+    const value = line.slice(separator + 1).trim();
+
+    if (key === 'content-disposition') {
+      const name = value.match(/\bname="([^"]*)"/i);
+      const filename = value.match(/\bfilename="([^"]*)"/i);
+      if (name) result.name = name[1];
+      if (filename) result.filename = filename[1];
+    } else if (key === 'content-type') {
+      result.contentType = value;
+    }
+  });
+
+  return result;
+}
+
+/**
+ * Parse a multipart/form-data body incrementally and resolve as soon as the
+ * first file part starts. Text fields must precede the file part; parts after
+ * the file are drained and ignored. Only one part-sized window is held in
+ * memory, so large files are never fully buffered. Pass the request's abort
+ * signal so a disconnected client stops the reader while it waits on
+ * backpressure.
+ */
+export function parseMultipartStream(
+  body: ReadableStream<Uint8Array>,
+  boundary: string,
+  signal?: AbortSignal
+): Promise<StreamedMultipart> {
+  return new Promise((resolve, reject) => {
+    const delimiter = Buffer.from(`--${boundary}`);
+    const partDelimiter = Buffer.concat([CRLF, delimiter]);
+    const fields: Record<string, string> = {};
+
+    let state: 'preamble' | 'after-boundary' | 'headers' | 'body' | 'done' = 'preamble';
+    let buffer = Buffer.alloc(0);
+    let current: { name?: string; filename?: string; contentType?: string } = {};
+    let fieldValue = '';
+    let fileStream: PassThrough | null = null;
+    let fileSeen = false;
+    let settled = false;
+
+    const fail = (error: Error) => {
+      if (fileStream && !fileStream.destroyed) {
+        fileStream.destroy(error);
+      }
+      if (!settled) {
+        settled = true;
+        reject(error);
+      }
+    };
+
+    const writeFileChunk = async (chunk: Buffer) => {
+      if (!fileStream || chunk.length === 0) return;
+      if (fileStream.destroyed) {
+        throw new Error('Multipart file stream closed before the upload finished');
+      }
+      if (!fileStream.write(chunk)) {
+        await waitForDrain(fileStream, signal);
+      }
+    };
+
+    const endPart = () => {
+      if (fileStream) {
+        fileStream.end();
+        fileStream = null;
+      } else if (current.name && !current.filename) {
+        fields[current.name] = fieldValue;
+      }
+      current = {};
+      fieldValue = '';
+    };
+
+    // Route body bytes to the file stream or the current text field
+    const consumeBody = async (chunk: Buffer) => {
+      if (fileStream) {
+        await writeFileChunk(chunk);
+      } else if (current.filename === undefined) {
+        fieldValue += chunk.toString('utf8');
+        if (fieldValue.length > MAX_FIELD_SIZE) {
+          throw new Error(`Multipart field "${current.name}" too large`);
+        }
+      }
+      // Additional file parts after the first are discarded
+    };
+
+    const processBuffer = async () => {
+      while (true) {
+        if (state === 'preamble') {
+          const index = buffer.indexOf(delimiter);
+          if (index === -1) {
+            buffer = buffer.subarray(Math.max(0, buffer.length - delimiter.length));
+            return;
+          }
+          buffer = buffer.subarray(index + delimiter.length);
+          state = 'after-boundary';
+        }
+
+        if (state === 'after-boundary') {
+          if (buffer.length < 2) return;
+          if (buffer[0] === 0x2d && buffer[1] === 0x2d) { // "--" closes the body
+            state = 'done';
+            return;
+          }
+          buffer = buffer.subarray(buffer.indexOf(CRLF) === 0 ? 2 : 0);
+          state = 'headers';
+        }
+
+        if (state === 'headers') {
+          const index = buffer.indexOf(HEADER_END);
+          if (index === -1) {
+            if (buffer.length > MAX_HEADER_SIZE) {
+              throw new Error('Multipart part headers too large');
+            }
+            return;
+          }
+
+          current = parsePartHeaders(buffer.subarray(0, index).toString('utf8'));
+          buffer = buffer.subarray(index + HEADER_END.length);
+          state = 'body';
+
+          if (current.filename !== undefined && !fileSeen) {
+            fileSeen = true;
+            fileStream = new PassThrough();
+            settled = true;
+            resolve({
+              fields: { ...fields },
+              file: {
+                fieldName: current.name || 'file',
+                filename: current.filename,
+                mimeType: current.contentType || 'application/octet-stream',
+                stream: fileStream,
+              },
+            });
+          }
+        }
+
+        if (state === 'body') {
+          const index = buffer.indexOf(partDelimiter);
+          if (index === -1) {
+            // Keep enough bytes to detect a delimiter split across chunks
+            const safeLength = Math.max(0, buffer.length - partDelimiter.length);
+            const chunk = buffer.subarray(0, safeLength);
+            buffer = buffer.subarray(safeLength);
+            await consumeBody(chunk);
+            return;
+          }
+
+          await consumeBody(buffer.subarray(0, index));
+          buffer = buffer.subarray(index + partDelimiter.length);
+          endPart();
+          state = 'after-boundary';
+        }
+
+        if (state === 'done') return;
+      }
+    };
+
+    (async () => {
+      const reader = body.getReader();
+      try {
+        while (true) {
+          const { done, value } = await reader.read();
+          if (done) break;
+          buffer = Buffer.concat([buffer, Buffer.from(value)]);
+          await processBuffer();
+        }
+
+        if (fileStream) {
+          throw new Error('Unexpected end of multipart body');
+        }
+        if (!fileSeen) {
+          fail(new Error('No file part found in multipart body'));
+        }
+      } catch (error) {
+        reader.cancel().catch(() => {});
+        fail(error instanceof Error ? error : new Error(String(error)));
+      }
+    })();
+  });
+}
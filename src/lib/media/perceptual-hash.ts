@@ -0,0 +1,66 @@
+import sharp from 'sharp';
+
+const SAMPLE_SIZE = 32;
+const HASH_SIZE = 8;
+
+// Precomputed DCT-II basis for the low frequencies kept in the hash
+const DCT_BASIS: number[][] = Array.from({ length: HASH_SIZE }, (_, u) =>
+  Array.from({ length: SAMPLE_SIZE }, (_, x) =>
+    Math.cos(((2 * x + 1) * u * Math.PI) / (2 * SAMPLE_SIZE))
+  )
+);
+
+// 64-bit DCT perceptual hash as 16 hex characters. Re-encoding, resizing,
+// small crops or colour tweaks change it by a few bits at most, unlike a
+// checksum. Animated images are hashed on their first frame.
+export async function computePerceptualHash(image: Buffer): Promise<string> {
+  const pixels = await sharp(image, { animated: false })
+    .greyscale()
+    .resize(SAMPLE_SIZE, SAMPLE_SIZE, { fit: 'fill' })
+    .raw()
+    .toBuffer();
+
+  // Separable 2D DCT, keeping only the top-left HASH_SIZE x HASH_SIZE block
+  const rows: number[][] = [];
+  for (let y = 0; y < SAMPLE_SIZE; y++) {
+    rows.push(DCT_BASIS.map(basis => {
+      let sum = 0;
+      for (let x = 0; x < SAMPLE_SIZE; x++) sum += basis[x] * pixels[y * SAMPLE_SIZE + x];
+      return sum;
+    }));
+  }
+
+  const coefficients: number[] = [];
+  for (let v = 0; v < HASH_SIZE; v++) {
+    for (let u = 0; u < HASH_SIZE; u++) {
+      let sum = 0;
+      for (let y = 0; y < SAMPLE_SIZE; y++) sum += DCT_BASIS[v][y] * rows[y][u];
+      coefficients.push(sum);
+    }
+  }
+
+  // The DC term only reflects overall brightness; leave it out of the median
+  const sorted = coefficients.slice(1).sort((a, b) => a - b);
+  const median = (sorted[31] + sorted[32]) / 2;
+
+  let hash = BigInt(0);
+  for (const coefficient of coefficients) {
+    hash = (hash << BigInt(1)) | (coefficient > median ? BigInt(1) : BigInt(0));
+  }
+  return hash.toString(16).padStart(16, '0');
+}
+
+export function isPerceptualHash(value: string): boolean {
+  return /^[0-9a-f]{16}$/.test(value);
+}
+
+// Number of differing bits between two hashes
+export function hammingDistance(a: string, b: string): number {
+  let diff = BigInt(`0x${a}`) ^ BigInt(`0x${b}`);
+  let count = 0;
+  while (diff) {
+    diff &= diff - BigInt(1);
+    count++;
+  }
+  return count;
+}
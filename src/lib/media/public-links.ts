@@ -0,0 +1,192 @@
+import { Types } from 'mongoose';
+import { MediaLinkRepository } from '../database/repositories/media-link';
+import { MediaRepository } from '../database/repositories/media';
+import { IMediaLink } from '../database/models/media-link';
+import { IMedia } from '../database/models/media';
+import { CreateMediaLinkInput } from '../database/schemas/media';
+import { organizationService } from '../organizations';
+import { environmentConfig } from '../config/environment';
+import { CryptoUtils } from '../utils/crypto';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const MAX_PASSWORD_ATTEMPTS = 5;
+const PASSWORD_LOCK_MS = 15 * 60 * 1000; // 15 minutes
+
+// Public links let the uploader hand a file to people without an account.
+// A link can carry a password, an expiry and a download cap; admins can turn
+// the feature off, globally or for an organization, which stops existing
+// links working too.
+export class PublicLinkService {
+  private mediaLinkRepository = new MediaLinkRepository();
+  private mediaRepository = new MediaRepository();
+
+  // Enabled globally or, given the uploader, for their organization
+  async isEnabled(uploaderId?: string | Types.ObjectId): Promise<boolean> {
+    const features = uploaderId
+      ? await organizationService.getFeaturesForUser(uploaderId)
+      : await organizationService.getFeatures();
+    return features.publicFileLinks !== false;
+  }
+
+  // Create a link; uploader only
+  async create(mediaId: string, userId: string, input: CreateMediaLinkInput): Promise<IMediaLink> {
+    await this.assertEnabled(userId);
+    const media = await this.getOwnMedia(mediaId, userId);
+
+    const features = await organizationService.getFeaturesForUser(userId);
+    const maxHours = features.publicLinkMaxDays ? features.publicLinkMaxDays * 24 : undefined;
+    if (maxHours && input.expiresInHours && input.expiresInHours > maxHours) {
+      throw new PublicLinkError(`Links can last at most ${features.publicLinkMaxDays} days`, 400);
+    }
+    const expiresInHours = input.expiresInHours ?? maxHours;
+
+    const password = input.password
+      ? await CryptoUtils.hashPassword(input.password)
+      : undefined;
+
+    const link = await this.mediaLinkRepository.create({
+      mediaId: media._id,
+      createdBy: new Types.ObjectId(userId),
+      token: CryptoUtils.generateRandomBytes(24).toString('base64url'),
+      passwordHash: password?.hash,
+      passwordSalt: password?.salt,
+      expiresAt: expiresInHours ? new Date(Date.now() + expiresInHours * 60 * 60 * 1000) : undefined,
+      maxDownloads: input.maxDownloads,
+    });
+
+    metricsCollector.incrementCounter('media_public_links_created', 1);
+    logger.info('Public file link created', {
+      mediaId,
+      userId,
+      linkId: link._id.toString(),
+      password: !!password,
+      expiresAt: link.expiresAt,
+      maxDownloads: link.maxDownloads,
+    });
+
+    return link;
+  }
+
+  // Links of a file; uploader only
+  async list(mediaId: string, userId: string): Promise<IMediaLink[]> {
+    const media = await this.getOwnMedia(mediaId, userId);
+    return await this.mediaLinkRepository.findByMedia(media._id);
+  }
+
+  // Revoke a link; uploader only
+  async revoke(mediaId: string, linkId: string, userId: string): Promise<IMediaLink> {
+    const media = await this.getOwnMedia(mediaId, userId);
+    const link = Types.ObjectId.isValid(linkId) ? await this.mediaLinkRepository.findById(linkId) : null;
+    if (!link || !link.mediaId.equals(media._id)) {
+      throw new PublicLinkError('Link not found', 404);
+    }
+
+    const revoked = await this.mediaLinkRepository.revoke(link._id);
+    if (!revoked) {
+      throw new PublicLinkError('Link has already been revoked', 409);
+    }
+
+    logger.info('Public file link revoked', { mediaId, linkId, userId });
+    return revoked;
+  }
+
+  // What a visitor sees before downloading
+  async getInfo(token: string): Promise<{ link: IMediaLink; media: IMedia }> {
+    await this.assertEnabled();
+    const resolved = await this.resolve(token);
+    await this.assertEnabled(resolved.media.uploadedBy);
+    return resolved;
+  }
+
+  // Check the password and count the download. Every failure a visitor can
+  // see looks the same as a missing link, except a wrong or missing password.
+  async open(token: string, password?: string): Promise<{ link: IMediaLink; media: IMedia }> {
+    await this.assertEnabled();
+    const { link, media } = await this.resolve(token);
+    await this.assertEnabled(media.uploadedBy);
+
+    if (link.passwordHash && link.passwordSalt) {
+      if (link.lockedUntil && link.lockedUntil > new Date()) {
+        throw new PublicLinkError('Too many attempts, try again later', 429);
+      }
+      if (!password) {
+        throw new PublicLinkError('Password required', 401);
+      }
+      if (!(await CryptoUtils.verifyPassword(password, link.passwordHash, link.passwordSalt))) {
+        await this.mediaLinkRepository.recordFailedPassword(link._id, MAX_PASSWORD_ATTEMPTS, PASSWORD_LOCK_MS);
+        throw new PublicLinkError('Incorrect password', 401);
+      }
+    }
+
+    const claimed = await this.mediaLinkRepository.claimDownload(link._id);
+    if (!claimed) {
+      throw new PublicLinkError('Link not found', 404);
+    }
+
+    metricsCollector.incrementCounter('media_public_link_downloads', 1);
+    return { link: claimed, media };
+  }
+
+  getUrl(link: IMediaLink): string {
+    return `${environmentConfig.get().API_URL}/public/files/${link.token}`;
+  }
+
+  private async resolve(token: string): Promise<{ link: IMediaLink; media: IMedia }> {
+    const link = await this.mediaLinkRepository.findByToken(token);
+    if (!link || !isUsable(link)) {
+      throw new PublicLinkError('Link not found', 404);
+    }
+
+    const media = await this.mediaRepository.findById(link.mediaId);
+    if (!media) {
+      throw new PublicLinkError('Link not found', 404);
+    }
+    return { link, media };
+  }
+
+  private async assertEnabled(uploaderId?: string | Types.ObjectId): Promise<void> {
+    if (!(await this.isEnabled(uploaderId))) {
+      throw new PublicLinkError('Public links are disabled', 403);
+    }
+  }
+
+  private async getOwnMedia(mediaId: string, userId: string): Promise<IMedia> {
+    const media = Types.ObjectId.isValid(mediaId) ? await this.mediaRepository.findById(mediaId) : null;
+    if (!media || media.uploadedBy.toString() !== userId) {
+      throw new PublicLinkError('File not found', 404);
+    }
+    return media;
+  }
+}
+
+function isUsable(link: IMediaLink): boolean {
+  if (link.revokedAt) return false;
+  if (link.expiresAt && link.expiresAt <= new Date()) return false;
+  return !link.maxDownloads || link.downloadCount < link.maxDownloads;
+}
+
+export function serializeMediaLink(link: IMediaLink) {
+  return {
+    id: link._id.toString(),
+    mediaId: link.mediaId,
+    url: publicLinkService.getUrl(link),
+    hasPassword: !!link.passwordHash,
+    expiresAt: link.expiresAt,
+    maxDownloads: link.maxDownloads,
+    downloadCount: link.downloadCount,
+    lastDownloadedAt: link.lastDownloadedAt,
+    active: isUsable(link),
+    revokedAt: link.revokedAt,
+    createdAt: link.createdAt,
+  };
+}
+
+export class PublicLinkError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'PublicLinkError';
+  }
+}
+
+export const publicLinkService = new PublicLinkService();
@@ -0,0 +1,219 @@
+import { Types } from 'mongoose';
+import { MediaGrantRepository } from '../database/repositories/media-grant';
+import { MediaRepository } from '../database/repositories/media';
+import { ChatRepository } from '../database/repositories/chat';
+import { UserRepository } from '../database/repositories/user';
+import { IMediaGrant } from '../database/models/media-grant';
+import { IMedia } from '../database/models/media';
+import { ShareMediaInput } from '../database/schemas/media';
+import { permissionService } from '../security/permissions';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Sharing an uploaded file beyond the chat it was uploaded to: into other
+// chats or with specific users, without uploading it again. The uploader and
+// members of the original chat can share; people with a grant can read the
+// file but not pass it on. Reads through a grant are logged for the uploader.
+export class MediaSharingService {
+  private mediaGrantRepository = new MediaGrantRepository();
+  private mediaRepository = new MediaRepository();
+  private chatRepository = new ChatRepository();
+  private userRepository = new UserRepository();
+
+  // Whether the user may read the file
+  async authorize(
+    media: IMedia,
+    userId: string,
+    action: 'download' | 'thumbnail',
+    ipAddress?: string
+  ): Promise<boolean> {
+    if (await this.canShare(media, userId)) return true;
+
+    const grant = await this.findGrantFor(media, userId);
+    if (!grant) return false;
+
+    this.mediaGrantRepository.recordAccess({
+      mediaId: media._id,
+      userId: new Types.ObjectId(userId),
+      grantId: grant._id,
+      action,
+      ipAddress,
+    }).catch(error => {
+      logger.error('Failed to record shared file access', error, { mediaId: media._id.toString() });
+    });
+    return true;
+  }
+
+  // Share a file with chats and users. Targets that already have access
+  // through a grant in force keep that grant.
+  async share(mediaId: string, userId: string, input: ShareMediaInput): Promise<IMediaGrant[]> {
+    const media = await this.getMedia(mediaId);
+    if (!(await this.canShare(media, userId))) {
+      throw new MediaSharingError('File not found', 404);
+    }
+
+    const expiresAt = input.expiresInHours
+      ? new Date(Date.now() + input.expiresInHours * 60 * 60 * 1000)
+      : undefined;
+
+    for (const chatId of input.chatIds) {
+      if (!(await permissionService.canSendMessage(userId, chatId))) {
+        throw new MediaSharingError('Not allowed to share into this chat', 403);
+      }
+    }
+    for (const targetId of input.userIds) {
+      const target = targetId === userId ? null : await this.userRepository.findById(targetId);
+      // Blocked senders are told the user doesn't exist, as elsewhere
+      if (!target || target.isBanned || target.blockedUsers.some(id => id.toString() === userId)) {
+        throw new MediaSharingError('User not found', 404);
+      }
+    }
+
+    const targets = [
+      ...input.chatIds.map(chatId => ({ chatId })),
+      ...input.userIds.map(targetId => ({ userId: targetId })),
+    ];
+    const grants: IMediaGrant[] = [];
+
+    for (const target of targets) {
+      const existing = await this.mediaGrantRepository.findActiveTarget(media._id, target);
+      if (existing) {
+        grants.push(existing);
+        continue;
+      }
+
+      const grant = await this.mediaGrantRepository.create({
+        mediaId: media._id,
+        grantedBy: new Types.ObjectId(userId),
+        ...('chatId' in target ? { chatId: new Types.ObjectId(target.chatId) } : { userId: new Types.ObjectId(target.userId) }),
+        expiresAt,
+      });
+      grants.push(grant);
+      await this.announce(grant, media);
+    }
+
+    metricsCollector.incrementCounter('media_shares_created', targets.length);
+    logger.info('File shared', {
+      mediaId,
+      userId,
+      chats: input.chatIds.length,
+      users: input.userIds.length,
+    });
+
+    return grants;
+  }
+
+  // Revoke a grant; the uploader can revoke any, others only their own
+  async revoke(mediaId: string, grantId: string, userId: string): Promise<IMediaGrant> {
+    const media = await this.getMedia(mediaId);
+    const grant = Types.ObjectId.isValid(grantId) ? await this.mediaGrantRepository.findById(grantId) : null;
+    if (!grant || !grant.mediaId.equals(media._id) || !this.canManage(media, grant, userId)) {
+      throw new MediaSharingError('Share not found', 404);
+    }
+
+    const revoked = await this.mediaGrantRepository.revoke(grant._id, userId);
+    if (!revoked) {
+      throw new MediaSharingError('Share has already been revoked', 409);
+    }
+
+    logger.info('File share revoked', { mediaId, grantId, userId });
+    return revoked;
+  }
+
+  // Grants of a file: all of them for the uploader, otherwise the user's own
+  async listGrants(mediaId: string, userId: string): Promise<IMediaGrant[]> {
+    const media = await this.getMedia(mediaId);
+    if (!(await this.canShare(media, userId))) {
+      throw new MediaSharingError('File not found', 404);
+    }
+
+    const isUploader = media.uploadedBy.toString() === userId;
+    return await this.mediaGrantRepository.findByMedia(media._id, isUploader ? undefined : userId);
+  }
+
+  // Files shared with the user or into their chats by others
+  async listSharedWith(userId: string, limit: number = 20, offset: number = 0): Promise<IMediaGrant[]> {
+    const chatIds = await this.chatRepository.getUserChatIds(userId);
+    return await this.mediaGrantRepository.findSharedWith(userId, chatIds, limit, offset);
+  }
+
+  // Who read the file through a grant; uploader only
+  async getAccessLog(mediaId: string, userId: string, limit: number = 50, offset: number = 0) {
+    const media = await this.getMedia(mediaId);
+    if (media.uploadedBy.toString() !== userId) {
+      throw new MediaSharingError('File not found', 404);
+    }
+
+    return await this.mediaGrantRepository.getAccessLog(media._id, limit, offset);
+  }
+
+  // Uploader, or a member of the chat it was uploaded to
+  private async canShare(media: IMedia, userId: string): Promise<boolean> {
+    if (media.uploadedBy.toString() === userId) return true;
+    return !!media.chatId && await permissionService.canAccessChat(userId, media.chatId.toString());
+  }
+
+  private canManage(media: IMedia, grant: IMediaGrant, userId: string): boolean {
+    return media.uploadedBy.toString() === userId || grant.grantedBy.toString() === userId;
+  }
+
+  private async findGrantFor(media: IMedia, userId: string): Promise<IMediaGrant | null> {
+    const grants = await this.mediaGrantRepository.findActiveByMedia(media._id);
+
+    for (const grant of grants) {
+      if (grant.userId?.toString() === userId) return grant;
+      if (grant.chatId && await this.chatRepository.isParticipant(grant.chatId, userId)) return grant;
+    }
+    return null;
+  }
+
+  private async announce(grant: IMediaGrant, media: IMedia): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const payload = {
+      grantId: grant._id.toString(),
+      mediaId: media._id.toString(),
+      grantedBy: grant.grantedBy.toString(),
+      chatId: grant.chatId?.toString(),
+      type: media.type,
+      originalName: media.originalName,
+      expiresAt: grant.expiresAt?.toISOString(),
+    };
+
+    if (grant.chatId) {
+      socketManager.emitToChat(grant.chatId.toString(), 'media:shared', payload, grant.grantedBy.toString());
+    } else if (grant.userId) {
+      socketManager.emitToUser(grant.userId.toString(), 'media:shared', payload);
+    }
+  }
+
+  private async getMedia(mediaId: string): Promise<IMedia> {
+    const media = Types.ObjectId.isValid(mediaId) ? await this.mediaRepository.findById(mediaId) : null;
+    if (!media) {
+      throw new MediaSharingError('File not found', 404);
+    }
+    return media;
+  }
+}
+
+export function serializeMediaGrant(grant: IMediaGrant) {
+  return {
+    id: grant._id.toString(),
+    mediaId: grant.mediaId,
+    grantedBy: grant.grantedBy,
+    chatId: grant.chatId,
+    userId: grant.userId,
+    expiresAt: grant.expiresAt,
+    active: !grant.revokedAt && (!grant.expiresAt || grant.expiresAt > new Date()),
+    revokedAt: grant.revokedAt,
+    createdAt: grant.createdAt,
+  };
+}
+
+export class MediaSharingError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'MediaSharingError';
+  }
+}
+
+export const mediaSharingService = new MediaSharingService();
@@ -0,0 +1,204 @@
+import { StorageObjectRepository } from '../database/repositories/storage-object';
+import { MediaRepository } from '../database/repositories/media';
+import { IMedia } from '../database/models/media';
+import { storageService } from './storage';
+import { cdnService } from './cdn';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 60 * 60 * 1000;
+const VERIFY_EVERY = 7 * 24 * 60 * 60 * 1000;
+const BATCH_SIZE = 200;
+
+export interface ConsistencyReport {
+  checked: number;
+  corrected: number;
+  removed: number;
+}
+
+// Reference counts for stored files shared between media records. Uploading
+// a file that is already stored adds a record pointing at the same blob
+// instead of storing it again; deleting a record only removes the blob once
+// no other record uses it. A background check recounts references against
+// the media records and repairs any drift.
+export class StorageReferenceService {
+  private storageObjectRepository = new StorageObjectRepository();
+  private mediaRepository = new MediaRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Start counting references to a freshly stored file
+  async track(media: IMedia): Promise<void> {
+    await this.storageObjectRepository.create({
+      key: media.filename,
+      checksumSHA256: media.checksumSHA256,
+      size: media.size,
+      thumbnailKeys: this.getThumbnailKeys(media),
+      refCount: 1,
+    });
+  }
+
+  // Count references from records moved onto a copy of their file, e.g. in
+  // another data region. The copy may already be tracked from an earlier move.
+  async trackMoved(media: IMedia, count: number): Promise<void> {
+    if (await this.storageObjectRepository.increment(media.filename, count)) return;
+
+    try {
+      await this.storageObjectRepository.create({
+        key: media.filename,
+        checksumSHA256: media.checksumSHA256,
+        size: media.size,
+        thumbnailKeys: this.getThumbnailKeys(media),
+        refCount: count,
+      });
+    } catch (error: any) {
+      if (error?.code !== 11000) throw error;
+      await this.storageObjectRepository.increment(media.filename, count);
+    }
+  }
+
+  // Add a reference to the file behind an existing record. False when the
+  // file has just been removed, in which case the caller stores its own copy.
+  async acquire(media: IMedia): Promise<boolean> {
+    if (await this.storageObjectRepository.increment(media.filename)) {
+      return true;
+    }
+
+    // Stored before reference counting; every record using it is a reference
+    const existing = await this.mediaRepository.countByFilename(media.filename);
+    if (!existing) return false;
+
+    try {
+      await this.storageObjectRepository.create({
+        key: media.filename,
+        checksumSHA256: media.checksumSHA256,
+        size: media.size,
+        thumbnailKeys: this.getThumbnailKeys(media),
+        refCount: existing + 1,
+      });
+      return true;
+    } catch (error: any) {
+      // Another upload started tracking it first
+      if (error?.code === 11000) {
+        return !!(await this.storageObjectRepository.increment(media.filename));
+      }
+      throw error;
+    }
+  }
+
+  // Thumbnails generated later belong to the stored file too, so the
+  // consistency check removes them with it
+  async trackThumbnails(media: IMedia): Promise<void> {
+    await this.storageObjectRepository.addThumbnailKeys(media.filename, this.getThumbnailKeys(media));
+  }
+
+  // Drop the reference held by a deleted record, removing the file if it was
+  // the last. Returns whether the file was removed.
+  async release(media: IMedia): Promise<boolean> {
+    const object = await this.storageObjectRepository.decrement(media.filename);
+    if (object) {
+      if (object.refCount > 0 || !(await this.storageObjectRepository.deleteIfUnreferenced(object._id))) {
+        return false;
+      }
+    } else if (await this.mediaRepository.countByFilename(media.filename) > 0) {
+      // Not tracked; still used by other records
+      return false;
+    }
+
+    await this.removeFile(media.filename, this.getThumbnailKeys(media));
+    return true;
+  }
+
+  // Recount references for files not checked recently
+  async checkConsistency(limit: number = BATCH_SIZE): Promise<ConsistencyReport> {
+    const report: ConsistencyReport = { checked: 0, corrected: 0, removed: 0 };
+    const objects = await this.storageObjectRepository.findUnverifiedSince(new Date(Date.now() - VERIFY_EVERY), limit);
+
+    for (const object of objects) {
+      const actual = await this.mediaRepository.countByFilename(object.key);
+      report.checked++;
+
+      // Skipped if an upload or delete moved the count meanwhile; rechecked next run
+      if (!(await this.storageObjectRepository.markVerified(object._id, object.refCount, actual))) {
+        continue;
+      }
+
+      if (actual !== object.refCount) {
+        report.corrected++;
+        metricsCollector.incrementCounter('storage_refcount_corrected', 1);
+        logger.warn('Storage reference count corrected', {
+          key: object.key,
+          recorded: object.refCount,
+          actual,
+        });
+      }
+
+      if (actual === 0 && await this.storageObjectRepository.deleteIfUnreferenced(object._id)) {
+        await this.removeFile(object.key, object.thumbnailKeys);
+        report.removed++;
+      }
+    }
+
+    return report;
+  }
+
+  async getStats() {
+    return await this.storageObjectRepository.getStats();
+  }
+
+  // Every thumbnail variant; records from before thumbnail keys were stored
+  // only have the URL
+  private getThumbnailKeys(media: IMedia): string[] {
+    const keys = new Set(media.thumbnails?.map(variant => variant.key));
+    if (media.thumbnailKey) {
+      keys.add(media.thumbnailKey);
+    } else if (media.thumbnailUrl) {
+      keys.add(media.filename.replace('media/', 'thumbnail/'));
+    }
+    return Array.from(keys);
+  }
+
+  private async removeFile(key: string, thumbnailKeys: string[] = []): Promise<void> {
+    const keys = [key, ...thumbnailKeys];
+    for (const fileKey of keys) {
+      await storageService.deleteFile(fileKey);
+    }
+    await cdnService.purge(keys);
+
+    metricsCollector.incrementCounter('storage_objects_removed', 1);
+    logger.info('Stored file removed', { key });
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const report = await this.checkConsistency();
+      if (report.corrected || report.removed) {
+        logger.info('Storage consistency check', report);
+      }
+    } catch (error) {
+      logger.error('Storage consistency check error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+export const storageReferenceService = new StorageReferenceService();
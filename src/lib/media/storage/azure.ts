@@ -0,0 +1,222 @@
+import crypto from 'crypto';
+import { Readable } from 'stream';
+import {
+  StorageProvider,
+  PutObjectOptions,
+  StoredObject,
+  ObjectMetadata,
+  StorageObjectNotFoundError,
+  readParts,
+} from './provider';
+
+export interface AzureConfig {
+  account: string;
+  accountKey: string; // base64, as shown in the portal
+  container: string;
+  endpoint?: string; // defaults to https://<account>.blob.core.windows.net
+}
+
+const API_VERSION = '2021-08-06';
+const BLOCK_SIZE = 8 * 1024 * 1024;
+const REQUEST_TIMEOUT = 60 * 1000;
+const METADATA_PREFIX = 'x-ms-meta-';
+
+// Azure Blob Storage over its REST API with Shared Key authentication.
+// Access is set per container, so 'public-read' uploads are only public if
+// the container is; serve those through the CDN instead.
+export class AzureBlobStorageProvider implements StorageProvider {
+  readonly name = 'azure';
+  readonly bucket: string;
+  private endpoint: string;
+  private key: Buffer;
+
+  constructor(private config: AzureConfig) {
+    this.bucket = config.container;
+    this.endpoint = (config.endpoint || `https://${config.account}.blob.core.windows.net`).replace(/\/+$/, '');
+    this.key = Buffer.from(config.accountKey, 'base64');
+  }
+
+  async putObject(key: string, body: Buffer | Uint8Array, options: PutObjectOptions): Promise<StoredObject> {
+    const response = await this.request('PUT', key, {
+      headers: {
+        'x-ms-blob-type': 'BlockBlob',
+        ...this.blobHeaders(options),
+      },
+      body: Buffer.from(body),
+    });
+
+    return { etag: response.headers.get('etag') || '', size: body.length };
+  }
+
+  // Staged blocks committed with a block list at the end. Blocks that never
+  // get committed are discarded by Azure after a week.
+  async putStream(key: string, stream: Readable, options: PutObjectOptions): Promise<StoredObject> {
+    const blockIds: string[] = [];
+    let size = 0;
+
+    for await (const block of readParts(stream, BLOCK_SIZE)) {
+      // Block ids must all have the same length
+      const blockId = Buffer.from(`block-${blockIds.length.toString().padStart(6, '0')}`).toString('base64');
+      await this.request('PUT', key, {
+        query: { comp: 'block', blockid: blockId },
+        body: block,
+      });
+      blockIds.push(blockId);
+      size += block.length;
+    }
+
+    if (blockIds.length === 0) {
+      return await this.putObject(key, Buffer.alloc(0), options);
+    }
+
+    const blockList = '<?xml version="1.0" encoding="utf-8"?><BlockList>' +
+      blockIds.map(id => `<Latest>${id}</Latest>`).join('') +
+      '</BlockList>';
+    const response = await this.request('PUT', key, {
+      query: { comp: 'blocklist' },
+      headers: {
+        'Content-Type': 'application/xml',
+        ...this.blobHeaders(options),
+      },
+      body: Buffer.from(blockList),
+    });
+
+    return { etag: response.headers.get('etag') || '', size };
+  }
+
+  async getObject(key: string): Promise<Buffer> {
+    const response = await this.download(key);
+    return Buffer.from(await response.arrayBuffer());
+  }
+
+  async getStream(key: string): Promise<ReadableStream> {
+    const response = await this.download(key);
+    return response.body!;
+  }
+
+  async headObject(key: string): Promise<ObjectMetadata | null> {
+    const response = await this.request('HEAD', key, { allowed: [404] });
+    if (response.status === 404) return null;
+
+    const metadata: Record<string, string> = {};
+    response.headers.forEach((value, name) => {
+      if (name.startsWith(METADATA_PREFIX)) {
+        metadata[name.slice(METADATA_PREFIX.length)] = decodeURIComponent(value);
+      }
+    });
+    const lastModified = response.headers.get('last-modified');
+
+    return {
+      size: Number(response.headers.get('content-length') || 0),
+      contentType: response.headers.get('content-type') || undefined,
+      lastModified: lastModified ? new Date(lastModified) : undefined,
+      metadata,
+      etag: response.headers.get('etag') || undefined,
+    };
+  }
+
+  async deleteObject(key: string): Promise<void> {
+    await this.request('DELETE', key, { allowed: [404] });
+  }
+
+  // Read-only service SAS for the blob
+  async getSignedUrl(key: string, expiresIn: number): Promise<string> {
+    const expiry = new Date(Date.now() + expiresIn * 1000).toISOString().replace(/\.\d{3}Z$/, 'Z');
+    const resource = `/blob/${this.config.account}/${this.bucket}/${key}`;
+
+    // Permissions, start, expiry, resource, identifier, IP, protocol,
+    // version, resource type, snapshot, encryption scope and the five
+    // response header overrides
+    const stringToSign = ['r', '', expiry, resource, '', '', '', API_VERSION, 'b', '', '', '', '', '', '', ''].join('\n');
+    const query = new URLSearchParams({
+      sv: API_VERSION,
+      se: expiry,
+      sr: 'b',
+      sp: 'r',
+      sig: this.sign(stringToSign),
+    });
+
+    return `${this.blobUrl(key)}?${query}`;
+  }
+
+  private async download(key: string): Promise<Response> {
+    const response = await this.request('GET', key, { allowed: [404] });
+    if (response.status === 404) {
+      throw new StorageObjectNotFoundError(key);
+    }
+    return response;
+  }
+
+  private blobHeaders(options: PutObjectOptions): Record<string, string> {
+    const headers: Record<string, string> = { 'x-ms-blob-content-type': options.contentType };
+    for (const [name, value] of Object.entries(options.metadata || {})) {
+      // Metadata names must be C# identifiers and values plain ASCII
+      headers[`${METADATA_PREFIX}${name.toLowerCase()}`] = encodeURIComponent(value);
+    }
+    return headers;
+  }
+
+  private blobUrl(key: string): string {
+    return `${this.endpoint}/${this.bucket}/${key.split('/').map(encodeURIComponent).join('/')}`;
+  }
+
+  // Signed request; statuses other than 2xx and those in allowed throw
+  private async request(
+    method: string,
+    key: string,
+    options: { query?: Record<string, string>; headers?: Record<string, string>; body?: Buffer; allowed?: number[] } = {}
+  ): Promise<Response> {
+    const url = new URL(this.blobUrl(key));
+    for (const [name, value] of Object.entries(options.query || {})) {
+      url.searchParams.set(name, value);
+    }
+
+    const headers: Record<string, string> = {
+      ...options.headers,
+      'x-ms-date': new Date().toUTCString(),
+      'x-ms-version': API_VERSION,
+    };
+    headers.Authorization = `SharedKey ${this.config.account}:${this.sign(this.stringToSign(method, url, headers, options.body))}`;
+
+    const response = await fetch(url, {
+      method,
+      headers,
+      body: options.body,
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+
+    if (!response.ok && !(options.allowed || []).includes(response.status)) {
+      const detail = await response.text().catch(() => '');
+      throw new Error(`Azure Blob request failed with status ${response.status}: ${detail.slice(0, 200)}`);
+    }
+    return response;
+  }
+
+  private stringToSign(method: string, url: URL, headers: Record<string, string>, body?: Buffer): string {
+    const canonicalHeaders = Object.keys(headers)
+      .map(name => name.toLowerCase())
+      .filter(name => name.startsWith('x-ms-'))
+      .sort()
+      .map(name => `${name}:${Object.entries(headers).find(([key]) => key.toLowerCase() === name)![1].trim()}\n`)
+      .join('');
+
+    const params = [...url.searchParams.keys()].map(name => name.toLowerCase()).sort();
+    const canonicalResource = `/${this.config.account}${url.pathname}` +
+      params.map(name => `\n${name}:${url.searchParams.get(name)}`).join('');
+
+    return [
+      method,
+      '', // Content-Encoding
+      '', // Content-Language
+      body && body.length > 0 ? String(body.length) : '',
+      '', // Content-MD5
+      headers['Content-Type'] || '',
+      '', // Date, sent as x-ms-date
+      '', '', '', '', '', // conditional and range headers
+    ].join('\n') + '\n' + canonicalHeaders + canonicalResource;
+  }
+
+  private sign(value: string): string {
+    return crypto.createHmac('sha256', this.key).update(value, 'utf8').digest('base64');
+  }
+}
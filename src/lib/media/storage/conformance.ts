@@ -0,0 +1,191 @@
+import crypto from 'crypto';
+import { Readable } from 'stream';
+import { StorageProvider, StorageObjectNotFoundError } from './provider';
+
+export interface ConformanceResult {
+  name: string;
+  passed: boolean;
+  error?: string;
+  duration: number; // ms
+}
+
+interface ConformanceCase {
+  name: string;
+  run: (provider: StorageProvider, key: (name: string) => string) => Promise<void>;
+}
+
+// Large enough to span several parts on every provider
+const STREAM_SIZE = 17 * 1024 * 1024 + 123;
+const SIGNED_URL_EXPIRY = 300;
+
+function check(condition: boolean, message: string): asserts condition {
+  if (!condition) throw new Error(message);
+}
+
+async function readWebStream(stream: ReadableStream): Promise<Buffer> {
+  const chunks: Buffer[] = [];
+  for await (const chunk of stream as unknown as AsyncIterable<Uint8Array>) {
+    chunks.push(Buffer.from(chunk));
+  }
+  return Buffer.concat(chunks);
+}
+
+async function expectNotFound(operation: () => Promise<unknown>, what: string): Promise<void> {
+  try {
+    await operation();
+  } catch (error) {
+    check(error instanceof StorageObjectNotFoundError, `${what} threw ${error} instead of StorageObjectNotFoundError`);
+    return;
+  }
+  throw new Error(`${what} succeeded for a missing object`);
+}
+
+// The behaviour every StorageProvider has to share, see provider.ts
+const CASES: ConformanceCase[] = [
+  {
+    name: 'put and get return the same bytes',
+    run: async (provider, key) => {
+      const body = crypto.randomBytes(64 * 1024);
+      const stored = await provider.putObject(key('roundtrip'), body, { contentType: 'application/octet-stream' });
+      check(stored.size === body.length, `put reported ${stored.size} bytes, expected ${body.length}`);
+
+      const read = await provider.getObject(key('roundtrip'));
+      check(read.equals(body), 'getObject returned different bytes');
+      const streamed = await readWebStream(await provider.getStream(key('roundtrip')));
+      check(streamed.equals(body), 'getStream returned different bytes');
+    },
+  },
+  {
+    name: 'put replaces an existing object',
+    run: async (provider, key) => {
+      await provider.putObject(key('overwrite'), Buffer.from('first version'), { contentType: 'text/plain' });
+      await provider.putObject(key('overwrite'), Buffer.from('second'), { contentType: 'text/plain' });
+
+      const read = await provider.getObject(key('overwrite'));
+      check(read.toString() === 'second', `read "${read}" after overwriting`);
+    },
+  },
+  {
+    name: 'head returns size, content type and metadata',
+    run: async (provider, key) => {
+      await provider.putObject(key('head'), Buffer.from('hello'), {
+        contentType: 'text/plain',
+        metadata: { originalName: 'hello.txt', uploadedBy: 'conformance' },
+      });
+
+      const head = await provider.headObject(key('head'));
+      check(!!head, 'headObject returned null for an existing object');
+      check(head.size === 5, `head reported ${head.size} bytes, expected 5`);
+      check(!!head.contentType?.startsWith('text/plain'), `head reported content type ${head.contentType}`);
+      check(head.metadata.originalname === 'hello.txt', `metadata came back as ${JSON.stringify(head.metadata)}`);
+      check(head.metadata.uploadedby === 'conformance', `metadata came back as ${JSON.stringify(head.metadata)}`);
+    },
+  },
+  {
+    name: 'stream upload spanning several parts',
+    run: async (provider, key) => {
+      const body = crypto.randomBytes(STREAM_SIZE);
+      // Uneven chunks, as a network stream would deliver them
+      const chunks = function* () {
+        for (let offset = 0; offset < body.length; offset += 700 * 1024 + 17) {
+          yield body.subarray(offset, offset + 700 * 1024 + 17);
+        }
+      };
+
+      const stored = await provider.putStream(key('stream'), Readable.from(chunks()), { contentType: 'application/octet-stream' });
+      check(stored.size === body.length, `putStream reported ${stored.size} bytes, expected ${body.length}`);
+
+      const read = await provider.getObject(key('stream'));
+      check(read.length === body.length, `read ${read.length} bytes, expected ${body.length}`);
+      check(read.equals(body), 'stream upload stored different bytes');
+    },
+  },
+  {
+    name: 'stream upload of an empty stream',
+    run: async (provider, key) => {
+      const stored = await provider.putStream(key('empty'), Readable.from([]), { contentType: 'text/plain' });
+      check(stored.size === 0, `putStream reported ${stored.size} bytes for an empty stream`);
+
+      const head = await provider.headObject(key('empty'));
+      check(head?.size === 0, 'empty stream upload did not store an empty object');
+    },
+  },
+  {
+    name: 'missing objects',
+    run: async (provider, key) => {
+      await expectNotFound(() => provider.getObject(key('missing')), 'getObject');
+      await expectNotFound(() => provider.getStream(key('missing')), 'getStream');
+      check(await provider.headObject(key('missing')) === null, 'headObject did not return null for a missing object');
+    },
+  },
+  {
+    name: 'delete removes the object and tolerates missing ones',
+    run: async (provider, key) => {
+      await provider.putObject(key('delete'), Buffer.from('bye'), { contentType: 'text/plain' });
+      await provider.deleteObject(key('delete'));
+      check(await provider.headObject(key('delete')) === null, 'object still exists after delete');
+
+      await provider.deleteObject(key('delete'));
+    },
+  },
+  {
+    name: 'signed URLs allow a plain GET',
+    run: async (provider, key) => {
+      const body = Buffer.from('signed content');
+      await provider.putObject(key('signed'), body, { contentType: 'text/plain' });
+
+      const response = await fetch(await provider.getSignedUrl(key('signed'), SIGNED_URL_EXPIRY));
+      check(response.ok, `signed URL returned status ${response.status}`);
+      check(Buffer.from(await response.arrayBuffer()).equals(body), 'signed URL returned different bytes');
+    },
+  },
+  {
+    name: 'keys with spaces and non-ASCII characters',
+    run: async (provider, key) => {
+      const name = key('dir with space/ünïcödé (1).txt');
+      await provider.putObject(name, Buffer.from('odd key'), { contentType: 'text/plain' });
+
+      const read = await provider.getObject(name);
+      check(read.toString() === 'odd key', 'read back different bytes');
+      const response = await fetch(await provider.getSignedUrl(name, SIGNED_URL_EXPIRY));
+      check(response.ok, `signed URL returned status ${response.status}`);
+    },
+  },
+];
+
+// Run every case against a real bucket, writing under a fresh prefix and
+// removing what was written afterwards. Run it against each provider before
+// deploying on it: npm run broctl -- storage:check --provider <name>
+export async function runConformanceSuite(provider: StorageProvider): Promise<ConformanceResult[]> {
+  const prefix = `conformance/${Date.now()}_${crypto.randomBytes(4).toString('hex')}`;
+  const written = new Set<string>();
+  const key = (name: string) => {
+    const fullKey = `${prefix}/${name}`;
+    written.add(fullKey);
+    return fullKey;
+  };
+
+  const results: ConformanceResult[] = [];
+  try {
+    for (const testCase of CASES) {
+      const startTime = Date.now();
+      try {
+        await testCase.run(provider, key);
+        results.push({ name: testCase.name, passed: true, duration: Date.now() - startTime });
+      } catch (error) {
+        results.push({
+          name: testCase.name,
+          passed: false,
+          error: error instanceof Error ? error.message : String(error),
+          duration: Date.now() - startTime,
+        });
+      }
+    }
+  } finally {
+    for (const fullKey of written) {
+      await provider.deleteObject(fullKey).catch(() => {});
+    }
+  }
+
+  return results;
+}
@@ -0,0 +1,269 @@
+import crypto from 'crypto';
+import { Readable } from 'stream';
+import {
+  StorageProvider,
+  PutObjectOptions,
+  StoredObject,
+  ObjectMetadata,
+  StorageObjectNotFoundError,
+  readParts,
+  normalizeMetadata,
+} from './provider';
+
+export interface GcsConfig {
+  bucket: string;
+  // Service account key JSON. Without it requests go out unauthenticated,
+  // which only emulators such as fake-gcs-server accept.
+  credentials?: string;
+  endpoint?: string;
+}
+
+interface ServiceAccountKey {
+  client_email: string;
+  private_key: string;
+  token_uri?: string;
+}
+
+const DEFAULT_ENDPOINT = 'https://storage.googleapis.com';
+const TOKEN_URI = 'https://oauth2.googleapis.com/token';
+const SCOPE = 'https://www.googleapis.com/auth/devstorage.read_write';
+const RESUMABLE_CHUNK_SIZE = 8 * 1024 * 1024; // must be a multiple of 256 KiB
+const TOKEN_REFRESH_MARGIN = 60 * 1000;
+const MAX_SIGNED_URL_EXPIRY = 7 * 24 * 60 * 60; // seconds, V4 signing limit
+const REQUEST_TIMEOUT = 60 * 1000;
+
+// Google Cloud Storage over its JSON API, authenticating as a service
+// account. Public uploads use the publicRead predefined ACL, which buckets
+// with uniform bucket-level access reject; serve those through the CDN.
+export class GcsStorageProvider implements StorageProvider {
+  readonly name = 'gcs';
+  readonly bucket: string;
+  private endpoint: string;
+  private key: ServiceAccountKey | null;
+  private token: { value: string; expiresAt: number } | null = null;
+
+  constructor(config: GcsConfig) {
+    this.bucket = config.bucket;
+    this.endpoint = (config.endpoint || DEFAULT_ENDPOINT).replace(/\/+$/, '');
+    this.key = config.credentials ? JSON.parse(config.credentials) : null;
+  }
+
+  async putObject(key: string, body: Buffer | Uint8Array, options: PutObjectOptions): Promise<StoredObject> {
+    const boundary = crypto.randomBytes(16).toString('hex');
+    const multipart = Buffer.concat([
+      Buffer.from(
+        `--${boundary}\r\nContent-Type: application/json; charset=UTF-8\r\n\r\n` +
+        `${JSON.stringify(this.resource(key, options))}\r\n` +
+        `--${boundary}\r\nContent-Type: ${options.contentType}\r\n\r\n`
+      ),
+      Buffer.from(body),
+      Buffer.from(`\r\n--${boundary}--`),
+    ]);
+
+    const response = await this.request(this.uploadUrl(key, 'multipart', options), {
+      method: 'POST',
+      headers: { 'Content-Type': `multipart/related; boundary=${boundary}` },
+      body: multipart,
+    });
+    const object = await response.json();
+
+    return { etag: object.etag || '', size: Number(object.size) };
+  }
+
+  // Resumable upload, sending one chunk at a time. Each chunk but the last
+  // leaves the total open, so the stream never has to be measured upfront.
+  async putStream(key: string, stream: Readable, options: PutObjectOptions): Promise<StoredObject> {
+    const session = await this.request(this.uploadUrl(key, 'resumable', options), {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json; charset=UTF-8',
+        'X-Upload-Content-Type': options.contentType,
+      },
+      body: JSON.stringify(this.resource(key, options)),
+    });
+    const sessionUrl = session.headers.get('location')!;
+
+    let offset = 0;
+    let previous: Buffer | null = null;
+
+    const sendChunk = async (chunk: Buffer, last: boolean) => {
+      const total = last ? String(offset + chunk.length) : '*';
+      const range = chunk.length > 0
+        ? `bytes ${offset}-${offset + chunk.length - 1}/${total}`
+        : `bytes */${total}`;
+
+      const response = await this.request(sessionUrl, {
+        method: 'PUT',
+        headers: { 'Content-Range': range },
+        body: chunk,
+      }, [308]);
+      offset += chunk.length;
+      return response;
+    };
+
+    try {
+      // Hold one chunk back so the final one can carry the total size
+      for await (const chunk of readParts(stream, RESUMABLE_CHUNK_SIZE)) {
+        if (previous) await sendChunk(previous, false);
+        previous = chunk;
+      }
+
+      const response = await sendChunk(previous ?? Buffer.alloc(0), true);
+      const object = await response.json();
+      return { etag: object.etag || '', size: Number(object.size) };
+    } catch (error) {
+      await this.request(sessionUrl, { method: 'DELETE' }, [499]).catch(() => {});
+      throw error;
+    }
+  }
+
+  async getObject(key: string): Promise<Buffer> {
+    const response = await this.download(key);
+    return Buffer.from(await response.arrayBuffer());
+  }
+
+  async getStream(key: string): Promise<ReadableStream> {
+    const response = await this.download(key);
+    return response.body!;
+  }
+
+  async headObject(key: string): Promise<ObjectMetadata | null> {
+    const response = await this.request(this.objectUrl(key), { method: 'GET' }, [404]);
+    if (response.status === 404) return null;
+
+    const object = await response.json();
+    return {
+      size: Number(object.size),
+      contentType: object.contentType,
+      lastModified: object.updated ? new Date(object.updated) : undefined,
+      metadata: normalizeMetadata(object.metadata),
+      etag: object.etag,
+    };
+  }
+
+  async deleteObject(key: string): Promise<void> {
+    await this.request(this.objectUrl(key), { method: 'DELETE' }, [404]);
+  }
+
+  // V4 signed URL, signed locally with the service account key
+  async getSignedUrl(key: string, expiresIn: number): Promise<string> {
+    const path = `/${this.bucket}/${encodePath(key)}`;
+    if (!this.key) {
+      return `${this.endpoint}${path}`;
+    }
+
+    const host = new URL(this.endpoint).host;
+    const now = new Date();
+    const datetime = now.toISOString().replace(/[-:]/g, '').replace(/\.\d{3}/, '');
+    const scope = `${datetime.slice(0, 8)}/auto/storage/goog4_request`;
+
+    const query = new URLSearchParams({
+      'X-Goog-Algorithm': 'GOOG4-RSA-SHA256',
+      'X-Goog-Credential': `${this.key.client_email}/${scope}`,
+      'X-Goog-Date': datetime,
+      'X-Goog-Expires': String(Math.min(expiresIn, MAX_SIGNED_URL_EXPIRY)),
+      'X-Goog-SignedHeaders': 'host',
+    });
+    query.sort();
+    const canonicalQuery = query.toString().replace(/\+/g, '%20');
+
+    const canonicalRequest = ['GET', path, canonicalQuery, `host:${host}\n`, 'host', 'UNSIGNED-PAYLOAD'].join('\n');
+    const stringToSign = [
+      'GOOG4-RSA-SHA256',
+      datetime,
+      scope,
+      crypto.createHash('sha256').update(canonicalRequest).digest('hex'),
+    ].join('\n');
+    const signature = crypto.createSign('RSA-SHA256').update(stringToSign).sign(this.key.private_key, 'hex');
+
+    return `${this.endpoint}${path}?${canonicalQuery}&X-Goog-Signature=${signature}`;
+  }
+
+  private async download(key: string): Promise<Response> {
+    const response = await this.request(`${this.objectUrl(key)}?alt=media`, { method: 'GET' }, [404]);
+    if (response.status === 404) {
+      throw new StorageObjectNotFoundError(key);
+    }
+    return response;
+  }
+
+  private resource(key: string, options: PutObjectOptions) {
+    return { name: key, contentType: options.contentType, metadata: options.metadata };
+  }
+
+  private objectUrl(key: string): string {
+    return `${this.endpoint}/storage/v1/b/${encodeURIComponent(this.bucket)}/o/${encodeURIComponent(key)}`;
+  }
+
+  private uploadUrl(key: string, uploadType: 'multipart' | 'resumable', options: PutObjectOptions): string {
+    const query = new URLSearchParams({ uploadType, name: key });
+    if (options.acl === 'public-read') {
+      query.set('predefinedAcl', 'publicRead');
+    }
+    return `${this.endpoint}/upload/storage/v1/b/${encodeURIComponent(this.bucket)}/o?${query}`;
+  }
+
+  // Authorized request; statuses other than 2xx and those in allowed throw
+  private async request(url: string, init: RequestInit, allowed: number[] = []): Promise<Response> {
+    const token = await this.getAccessToken();
+    const response = await fetch(url, {
+      ...init,
+      headers: {
+        ...init.headers,
+        ...(token && { Authorization: `Bearer ${token}` }),
+      },
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+
+    if (!response.ok && !allowed.includes(response.status)) {
+      const detail = await response.text().catch(() => '');
+      throw new Error(`GCS request failed with status ${response.status}: ${detail.slice(0, 200)}`);
+    }
+    return response;
+  }
+
+  // OAuth access token from a self-signed JWT, reused until shortly before
+  // it expires
+  private async getAccessToken(): Promise<string | null> {
+    if (!this.key) return null;
+    if (this.token && this.token.expiresAt - TOKEN_REFRESH_MARGIN > Date.now()) {
+      return this.token.value;
+    }
+
+    const tokenUri = this.key.token_uri || TOKEN_URI;
+    const now = Math.floor(Date.now() / 1000);
+    const encode = (value: object) => Buffer.from(JSON.stringify(value)).toString('base64url');
+    const unsigned = `${encode({ alg: 'RS256', typ: 'JWT' })}.${encode({
+      iss: this.key.client_email,
+      scope: SCOPE,
+      aud: tokenUri,
+      iat: now,
+      exp: now + 3600,
+    })}`;
+    const signature = crypto.createSign('RSA-SHA256').update(unsigned).sign(this.key.private_key, 'base64url');
+
+    const response = await fetch(tokenUri, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+      body: new URLSearchParams({
+        grant_type: 'urn:ietf:params:oauth:grant-type:jwt-bearer',
+        assertion: `${unsigned}.${signature}`,
+      }),
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok) {
+      throw new Error(`GCS token request failed with status ${response.status}`);
+    }
+
+    const { access_token, expires_in } = await response.json();
+    this.token = { value: access_token, expiresAt: Date.now() + expires_in * 1000 };
+    return access_token;
+  }
+}
+
+// Percent-encode each segment of a key, keeping the slashes
+function encodePath(key: string): string {
+  return key.split('/').map(segment =>
+    encodeURIComponent(segment).replace(/[!'()*]/g, c => `%${c.charCodeAt(0).toString(16).toUpperCase()}`)
+  ).join('/');
+}
@@ -0,0 +1,280 @@
+import { Readable, Transform, pipeline } from 'stream';
+import crypto from 'crypto';
+import { cdnService } from '../cdn';
+import { StorageProvider, StorageObjectNotFoundError } from './provider';
+import { S3StorageProvider } from './s3';
+import { GcsStorageProvider } from './gcs';
+import { AzureBlobStorageProvider } from './azure';
+import { DATA_REGIONS, DataRegion } from '../../database/models/user';
+
+export type StorageProviderName = 's3' | 'gcs' | 'azure';
+
+// Files stored for a data region have keys starting with the region, e.g.
+// "eu/media/...", and live in that region's bucket. Other keys predate data
+// residency and live in the default bucket.
+export function regionOfKey(key: string): DataRegion | null {
+  const prefix = key.split('/', 1)[0];
+  return (DATA_REGIONS as readonly string[]).includes(prefix) ? prefix as DataRegion : null;
+}
+
+// The key a file gets when moved to a region
+export function regionalKey(key: string, region: DataRegion): string {
+  return regionOfKey(key) ? `${region}/${key.slice(key.indexOf('/') + 1)}` : `${region}/${key}`;
+}
+
+interface UploadOptions {
+  contentType: string;
+  metadata?: Record<string, string>;
+  acl?: 'private' | 'public-read';
+  expiresIn?: number; // For signed URLs
+  region?: DataRegion; // store in this region's bucket
+}
+
+interface UploadResult {
+  key: string;
+  url: string;
+  bucket: string;
+  etag: string;
+  size: number;
+}
+
+interface StreamUploadResult extends UploadResult {
+  checksum: string; // SHA-256 of the uploaded bytes
+}
+
+// Media storage on whichever provider is configured: naming keys, upload
+// metadata and CDN URLs, with the object store itself behind a
+// StorageProvider
+class StorageService {
+  private regionalProviders = new Map<DataRegion, StorageProvider>();
+
+  constructor(
+    private provider: StorageProvider,
+    private createRegionalProvider: (region: DataRegion) => StorageProvider
+  ) {}
+
+  // Generate unique file key
+  private generateFileKey(
+    originalName: string,
+    userId: string,
+    type: 'media' | 'avatar' | 'thumbnail' | 'import' | 'export' | 'branding',
+    region?: DataRegion
+  ): string {
+    const timestamp = Date.now();
+    const randomString = crypto.randomBytes(8).toString('hex');
+    const extension = originalName.split('.').pop();
+    const sanitizedName = originalName.replace(/[^a-zA-Z0-9.-]/g, '_');
+    const key = `${type}/${userId}/${timestamp}_${randomString}_${sanitizedName}`;
+
+    return region ? `${region}/${key}` : key;
+  }
+
+  // Provider holding a key, by its region prefix
+  private providerFor(key: string): StorageProvider {
+    const region = regionOfKey(key);
+    if (!region) return this.provider;
+
+    let provider = this.regionalProviders.get(region);
+    if (!provider) {
+      provider = this.createRegionalProvider(region);
+      this.regionalProviders.set(region, provider);
+    }
+    return provider;
+  }
+
+  // Upload file
+  async uploadFile(
+    file: Buffer | Uint8Array,
+    originalName: string,
+    userId: string,
+    options: UploadOptions,
+    type: 'media' | 'avatar' | 'thumbnail' | 'import' | 'export' | 'branding' = 'media'
+  ): Promise<UploadResult> {
+    try {
+      const key = this.generateFileKey(originalName, userId, type, options.region);
+
+      const result = await this.providerFor(key).putObject(key, file, {
+        contentType: options.contentType,
+        metadata: this.uploadMetadata(originalName, userId, options),
+        acl: options.acl || 'private',
+      });
+      const url = this.getPublicUrl(key, type, options) || await this.getFileUrl(key, options.expiresIn);
+
+      return {
+        key,
+        url,
+        bucket: this.providerFor(key).bucket,
+        etag: result.etag,
+        size: result.size,
+      };
+    } catch (error) {
+      console.error('Storage upload error:', error);
+      throw new Error('Failed to upload file');
+    }
+  }
+
+  // Upload a stream without buffering it whole
+  async uploadStream(
+    stream: Readable,
+    originalName: string,
+    userId: string,
+    options: UploadOptions,
+    type: 'media' | 'avatar' | 'thumbnail' | 'import' | 'export' | 'branding' = 'media'
+  ): Promise<StreamUploadResult> {
+    const key = this.generateFileKey(originalName, userId, type, options.region);
+    const hash = crypto.createHash('sha256');
+    // Errors in the source reach the provider through the pipeline
+    const hashed = pipeline(stream, new Transform({
+      transform(chunk, _encoding, callback) {
+        hash.update(chunk);
+        callback(null, chunk);
+      },
+    }), () => {});
+
+    const result = await this.providerFor(key).putStream(key, hashed, {
+      contentType: options.contentType,
+      metadata: this.uploadMetadata(originalName, userId, options),
+      acl: options.acl || 'private',
+    });
+
+    return {
+      key,
+      url: this.getPublicUrl(key, type, options) || await this.getFileUrl(key, options.expiresIn),
+      bucket: this.providerFor(key).bucket,
+      etag: result.etag,
+      size: result.size,
+      checksum: hash.digest('hex'),
+    };
+  }
+
+  private uploadMetadata(originalName: string, userId: string, options: UploadOptions): Record<string, string> {
+    return {
+      originalName,
+      uploadedBy: userId,
+      uploadedAt: new Date().toISOString(),
+      ...options.metadata,
+    };
+  }
+
+  // CDN URL for thumbnails and public files, when a CDN is configured
+  private getPublicUrl(key: string, type: string, options: UploadOptions): string | null {
+    if (type !== 'thumbnail' && options.acl !== 'public-read') return null;
+    return cdnService.getUrl(key);
+  }
+
+  // Get a signed download URL
+  async getFileUrl(key: string, expiresIn: number = 3600): Promise<string> {
+    try {
+      return await this.providerFor(key).getSignedUrl(key, expiresIn);
+    } catch (error) {
+      console.error('Storage URL generation error:', error);
+      throw new Error('Failed to generate file URL');
+    }
+  }
+
+  // Get file metadata
+  async getFileMetadata(key: string): Promise<any> {
+    try {
+      const result = await this.providerFor(key).headObject(key);
+      if (!result) {
+        throw new StorageObjectNotFoundError(key);
+      }
+      return result;
+    } catch (error) {
+      console.error('Storage metadata error:', error);
+      throw new Error('Failed to get file metadata');
+    }
+  }
+
+  // Delete file
+  async deleteFile(key: string): Promise<boolean> {
+    try {
+      await this.providerFor(key).deleteObject(key);
+      return true;
+    } catch (error) {
+      console.error('Storage delete error:', error);
+      return false;
+    }
+  }
+
+  // Get download stream, or undefined if the file is gone
+  async getFileStream(key: string): Promise<ReadableStream | undefined> {
+    try {
+      return await this.providerFor(key).getStream(key);
+    } catch (error) {
+      if (error instanceof StorageObjectNotFoundError) return undefined;
+      console.error('Storage stream error:', error);
+      throw new Error('Failed to get file stream');
+    }
+  }
+
+  // Copy a stored file to a region's bucket, returning its key there
+  async copyFile(key: string, region: DataRegion): Promise<string> {
+    const source = this.providerFor(key);
+    const head = await source.headObject(key);
+    if (!head) {
+      throw new StorageObjectNotFoundError(key);
+    }
+
+    const target = regionalKey(key, region);
+    await this.providerFor(target).putStream(target, Readable.fromWeb(await source.getStream(key) as any), {
+      contentType: head.contentType || 'application/octet-stream',
+      metadata: head.metadata,
+    });
+    return target;
+  }
+
+  // Read a whole object into memory (archives that need random access)
+  async getFileBuffer(key: string): Promise<Buffer> {
+    try {
+      return await this.providerFor(key).getObject(key);
+    } catch (error) {
+      console.error('Storage read error:', error);
+      throw new Error('Failed to read file');
+    }
+  }
+}
+
+// Provider from the environment; the name overrides STORAGE_PROVIDER. A
+// region's provider takes each setting from the variable suffixed with the
+// region where set, e.g. AWS_S3_BUCKET_EU and AWS_REGION_EU.
+export function createStorageProvider(
+  name: StorageProviderName = (process.env.STORAGE_PROVIDER as StorageProviderName) || 's3',
+  region?: DataRegion
+): StorageProvider {
+  const env = (key: string) => (region && process.env[`${key}_${region.toUpperCase()}`]) || process.env[key];
+
+  switch (name) {
+    case 'gcs':
+      return new GcsStorageProvider({
+        bucket: env('GCS_BUCKET') || '',
+        credentials: env('GCS_CREDENTIALS'),
+        endpoint: env('GCS_ENDPOINT'),
+      });
+    case 'azure':
+      return new AzureBlobStorageProvider({
+        account: env('AZURE_STORAGE_ACCOUNT') || '',
+        accountKey: env('AZURE_STORAGE_KEY') || '',
+        container: env('AZURE_STORAGE_CONTAINER') || '',
+        endpoint: env('AZURE_STORAGE_ENDPOINT'),
+      });
+    case 's3':
+      return new S3StorageProvider({
+        region: env('AWS_REGION') || 'us-east-1',
+        bucket: env('AWS_S3_BUCKET') || '',
+        accessKeyId: env('AWS_ACCESS_KEY_ID') || '',
+        secretAccessKey: env('AWS_SECRET_ACCESS_KEY') || '',
+        endpoint: env('AWS_S3_ENDPOINT'), // Optional for custom endpoints
+      });
+    default:
+      throw new Error(`Unknown storage provider: ${name}`);
+  }
+}
+
+export const storageService = new StorageService(
+  createStorageProvider(),
+  region => createStorageProvider(undefined, region)
+);
+export { StorageService };
+export type { UploadOptions, UploadResult };
+export type { StorageProvider } from './provider';
@@ -0,0 +1,85 @@
+import { Readable } from 'stream';
+
+export interface PutObjectOptions {
+  contentType: string;
+  metadata?: Record<string, string>;
+  acl?: 'private' | 'public-read';
+}
+
+export interface StoredObject {
+  etag: string;
+  size: number;
+}
+
+export interface ObjectMetadata {
+  size: number;
+  contentType?: string;
+  lastModified?: Date;
+  metadata: Record<string, string>;
+  etag?: string;
+}
+
+// A bucket of objects addressed by '/'-separated keys. Every provider has to
+// behave the same way, which conformance.ts checks against a real bucket:
+// - writing a key replaces any object stored under it
+// - reading a missing key throws StorageObjectNotFoundError
+// - headObject returns null for a missing key
+// - deleting a missing key succeeds
+// - signed URLs allow a plain GET until they expire
+// - user metadata keys are lower case on the way out
+export interface StorageProvider {
+  readonly name: string;
+  readonly bucket: string;
+  putObject(key: string, body: Buffer | Uint8Array, options: PutObjectOptions): Promise<StoredObject>;
+  // Upload without holding the whole stream in memory
+  putStream(key: string, stream: Readable, options: PutObjectOptions): Promise<StoredObject>;
+  getObject(key: string): Promise<Buffer>;
+  getStream(key: string): Promise<ReadableStream>;
+  headObject(key: string): Promise<ObjectMetadata | null>;
+  deleteObject(key: string): Promise<void>;
+  getSignedUrl(key: string, expiresIn: number): Promise<string>;
+}
+
+export class StorageObjectNotFoundError extends Error {
+  constructor(public key: string, public status: number = 404) {
+    super(`Object not found: ${key}`);
+    this.name = 'StorageObjectNotFoundError';
+  }
+}
+
+// Split a stream into parts of exactly partSize bytes, the last one possibly
+// smaller, for providers that upload large objects in parts. Yields nothing
+// for an empty stream.
+export async function* readParts(stream: Readable, partSize: number): AsyncGenerator<Buffer> {
+  let pending: Buffer[] = [];
+  let pendingSize = 0;
+
+  for await (const chunk of stream) {
+    const buffer = Buffer.isBuffer(chunk) ? chunk : Buffer.from(chunk);
+    pending.push(buffer);
+    pendingSize += buffer.length;
+
+    if (pendingSize >= partSize) {
+      let data = Buffer.concat(pending, pendingSize);
+      while (data.length >= partSize) {
+        yield data.subarray(0, partSize);
+        data = data.subarray(partSize);
+      }
+      pending = [data];
+      pendingSize = data.length;
+    }
+  }
+
+  if (pendingSize > 0) {
+    yield Buffer.concat(pending, pendingSize);
+  }
+}
+
+// Lower-cased user metadata, as S3 and Azure return it
+export function normalizeMetadata(metadata: Record<string, string | undefined> = {}): Record<string, string> {
+  const normalized: Record<string, string> = {};
+  for (const [key, value] of Object.entries(metadata)) {
+    if (value !== undefined) normalized[key.toLowerCase()] = value;
+  }
+  return normalized;
+}
@@ -0,0 +1,179 @@
+import {
+  S3Client,
+  PutObjectCommand,
+  GetObjectCommand,
+  DeleteObjectCommand,
+  HeadObjectCommand,
+  CreateMultipartUploadCommand,
+  UploadPartCommand,
+  CompleteMultipartUploadCommand,
+  AbortMultipartUploadCommand,
+} from '@aws-sdk/client-s3';
+import { getSignedUrl } from '@aws-sdk/s3-request-presigner';
+import { Readable } from 'stream';
+import {
+  StorageProvider,
+  PutObjectOptions,
+  StoredObject,
+  ObjectMetadata,
+  StorageObjectNotFoundError,
+  readParts,
+  normalizeMetadata,
+} from './provider';
+
+export interface S3Config {
+  region: string;
+  bucket: string;
+  accessKeyId: string;
+  secretAccessKey: string;
+  endpoint?: string; // For custom S3-compatible services
+}
+
+const MULTIPART_PART_SIZE = 5 * 1024 * 1024; // S3 minimum part size
+
+// Amazon S3 and S3-compatible stores (MinIO, R2, ...)
+export class S3StorageProvider implements StorageProvider {
+  readonly name = 's3';
+  readonly bucket: string;
+  private client: S3Client;
+
+  constructor(config: S3Config) {
+    this.client = new S3Client({
+      region: config.region,
+      credentials: {
+        accessKeyId: config.accessKeyId,
+        secretAccessKey: config.secretAccessKey,
+      },
+      endpoint: config.endpoint,
+    });
+    this.bucket = config.bucket;
+  }
+
+  async putObject(key: string, body: Buffer | Uint8Array, options: PutObjectOptions): Promise<StoredObject> {
+    const result = await this.client.send(new PutObjectCommand({
+      Bucket: this.bucket,
+      Key: key,
+      Body: body,
+      ContentType: options.contentType,
+      Metadata: options.metadata,
+      ACL: options.acl || 'private',
+    }));
+
+    return { etag: result.ETag || '', size: body.length };
+  }
+
+  // Multipart upload, holding at most one part in memory
+  async putStream(key: string, stream: Readable, options: PutObjectOptions): Promise<StoredObject> {
+    const created = await this.client.send(new CreateMultipartUploadCommand({
+      Bucket: this.bucket,
+      Key: key,
+      ContentType: options.contentType,
+      Metadata: options.metadata,
+      ACL: options.acl || 'private',
+    }));
+    const uploadId = created.UploadId!;
+
+    const parts: { ETag: string; PartNumber: number }[] = [];
+    let size = 0;
+
+    const uploadPart = async (body: Buffer) => {
+      const partNumber = parts.length + 1;
+      const result = await this.client.send(new UploadPartCommand({
+        Bucket: this.bucket,
+        Key: key,
+        UploadId: uploadId,
+        PartNumber: partNumber,
+        Body: body,
+      }));
+      parts.push({ ETag: result.ETag!, PartNumber: partNumber });
+      size += body.length;
+    };
+
+    try {
+      for await (const part of readParts(stream, MULTIPART_PART_SIZE)) {
+        await uploadPart(part);
+      }
+      // A multipart upload needs at least one part
+      if (parts.length === 0) {
+        await uploadPart(Buffer.alloc(0));
+      }
+
+      const result = await this.client.send(new CompleteMultipartUploadCommand({
+        Bucket: this.bucket,
+        Key: key,
+        UploadId: uploadId,
+        MultipartUpload: { Parts: parts },
+      }));
+
+      return { etag: result.ETag || '', size };
+    } catch (error) {
+      await this.client.send(new AbortMultipartUploadCommand({
+        Bucket: this.bucket,
+        Key: key,
+        UploadId: uploadId,
+      })).catch(() => {});
+      throw error;
+    }
+  }
+
+  async getObject(key: string): Promise<Buffer> {
+    const result = await this.get(key);
+    return Buffer.from(await result.Body!.transformToByteArray());
+  }
+
+  async getStream(key: string): Promise<ReadableStream> {
+    const result = await this.get(key);
+    return result.Body!.transformToWebStream();
+  }
+
+  async headObject(key: string): Promise<ObjectMetadata | null> {
+    try {
+      const result = await this.client.send(new HeadObjectCommand({
+        Bucket: this.bucket,
+        Key: key,
+      }));
+
+      return {
+        size: result.ContentLength ?? 0,
+        contentType: result.ContentType,
+        lastModified: result.LastModified,
+        metadata: normalizeMetadata(result.Metadata),
+        etag: result.ETag,
+      };
+    } catch (error) {
+      if (this.isNotFound(error)) return null;
+      throw error;
+    }
+  }
+
+  // S3 deletes are idempotent already
+  async deleteObject(key: string): Promise<void> {
+    await this.client.send(new DeleteObjectCommand({
+      Bucket: this.bucket,
+      Key: key,
+    }));
+  }
+
+  async getSignedUrl(key: string, expiresIn: number): Promise<string> {
+    return await getSignedUrl(this.client, new GetObjectCommand({
+      Bucket: this.bucket,
+      Key: key,
+    }), { expiresIn });
+  }
+
+  private async get(key: string) {
+    try {
+      return await this.client.send(new GetObjectCommand({
+        Bucket: this.bucket,
+        Key: key,
+      }));
+    } catch (error) {
+      if (this.isNotFound(error)) throw new StorageObjectNotFoundError(key);
+      throw error;
+    }
+  }
+
+  private isNotFound(error: any): boolean {
+    return error?.name === 'NoSuchKey' || error?.name === 'NotFound' || error?.$metadata?.httpStatusCode === 404;
+  }
+}
@@ -4,11 +4,92 @@ import path from 'path';
 import os from 'os';
 import { MediaCompressor } from './compression';
 
+export type ThumbnailFormat = 'jpeg' | 'png' | 'webp' | 'avif';
+
 interface ThumbnailOptions {
   width?: number;
   height?: number;
   quality?: number;
-  format?: 'jpeg' | 'png' | 'webp';
+  format?: ThumbnailFormat;
+  timestamp?: number | string; // video only: seconds, or e.g. "10%"
+}
+
+export interface ThumbnailSize {
+  name: string;
+  maxDimension: number;
+}
+
+export interface ThumbnailVariant {
+  size: string;
+  format: ThumbnailFormat;
+  buffer: Buffer;
+  width?: number;
+  height?: number;
+}
+
+export const THUMBNAIL_MIME_TYPES: Record<ThumbnailFormat, string> = {
+  jpeg: 'image/jpeg',
+  png: 'image/png',
+  webp: 'image/webp',
+  avif: 'image/avif',
+};
+
+// Comparable visual quality; AVIF and WebP reach it at lower settings
+const THUMBNAIL_QUALITY: Record<ThumbnailFormat, number> = {
+  jpeg: 80,
+  png: 80,
+  webp: 75,
+  avif: 50,
+};
+
+// Sizes as "name:pixels" pairs, the longest edge of each variant
+function parseThumbnailSizes(value: string): ThumbnailSize[] {
+  const sizes = value.split(',').map(entry => {
+    const [name, pixels] = entry.trim().split(':');
+    return { name: name?.trim(), maxDimension: parseInt(pixels, 10) };
+  }).filter(size => size.name && size.maxDimension > 0);
+  return sizes.length ? sizes : [{ name: 'medium', maxDimension: 300 }];
+}
+
+function parseThumbnailFormats(value: string): ThumbnailFormat[] {
+  const formats = value.split(',')
+    .map(format => format.trim().toLowerCase())
+    .filter((format): format is ThumbnailFormat => format in THUMBNAIL_MIME_TYPES);
+  // JPEG is always made so every client has something it can show
+  return formats.includes('jpeg') ? formats : ['jpeg', ...formats];
+}
+
+export const THUMBNAIL_SIZES = parseThumbnailSizes(process.env.THUMBNAIL_SIZES || 'small:96,medium:300,large:640');
+export const THUMBNAIL_FORMATS = parseThumbnailFormats(process.env.THUMBNAIL_FORMATS || 'jpeg,webp,avif');
+export const VIDEO_POSTER_TIMESTAMP = process.env.VIDEO_POSTER_TIMESTAMP || '10%';
+
+// Seconds into a video to take the poster frame from, kept inside the video
+export function resolvePosterTimestamp(duration?: number, timestamp: string = VIDEO_POSTER_TIMESTAMP): number {
+  const value = parseFloat(timestamp);
+  const seconds = timestamp.trim().endsWith('%')
+    ? (duration || 0) * (isNaN(value) ? 10 : value) / 100
+    : (isNaN(value) ? 0 : value);
+
+  // Past the end there is no frame to grab; fall back to the middle
+  if (duration && seconds >= duration) {
+    return Math.round(duration / 2 * 1000) / 1000;
+  }
+  return Math.max(Math.round(seconds * 1000) / 1000, 0);
+}
+
+// Size used for thumbnailUrl and when a request doesn't name one
+export const DEFAULT_THUMBNAIL_SIZE =
+  THUMBNAIL_SIZES.find(size => size.name === 'medium')?.name || THUMBNAIL_SIZES[0].name;
+
+// Best format in the Accept header among those available, JPEG otherwise
+export function negotiateThumbnailFormat(accept: string | null, available: ThumbnailFormat[]): ThumbnailFormat {
+  const accepted = (accept || '').toLowerCase();
+  for (const format of ['avif', 'webp'] as ThumbnailFormat[]) {
+    if (available.includes(format) && accepted.includes(THUMBNAIL_MIME_TYPES[format])) {
+      return format;
+    }
+  }
+  return 'jpeg';
 }
 
 export class ThumbnailGenerator {
@@ -29,23 +110,19 @@ export class ThumbnailGenerator {
 
       const originalMetadata = await sharp(buffer).metadata();
       
+      const resized = sharp(buffer)
+        .resize(width, height, { fit: 'inside', withoutEnlargement: true });
+
       let thumbnailBuffer: Buffer;
       
       if (format === 'jpeg') {
-        thumbnailBuffer = await sharp(buffer)
-          .resize(width, height, { fit: 'inside', withoutEnlargement: true })
-          .jpeg({ quality })
-          .toBuffer();
+        thumbnailBuffer = await resized.jpeg({ quality }).toBuffer();
       } else if (format === 'png') {
-        thumbnailBuffer = await sharp(buffer)
-          .resize(width, height, { fit: 'inside', withoutEnlargement: true })
-          .png({ quality })
-          .toBuffer();
+        thumbnailBuffer = await resized.png({ quality }).toBuffer();
+      } else if (format === 'avif') {
+        thumbnailBuffer = await resized.avif({ quality }).toBuffer();
       } else {
-        thumbnailBuffer = await sharp(buffer)
-          .resize(width, height, { fit: 'inside', withoutEnlargement: true })
-          .webp({ quality })
-          .toBuffer();
+        thumbnailBuffer = await resized.webp({ quality }).toBuffer();
       }
 
       const thumbnailMetadata = await sharp(thumbnailBuffer).metadata();
@@ -67,7 +144,37 @@ export class ThumbnailGenerator {
     }
   }
 
-  // Generate video thumbnail
+  // Generate every configured size in every configured format
+  static async generateImageVariants(
+    buffer: Buffer,
+    sizes: ThumbnailSize[] = THUMBNAIL_SIZES,
+    formats: ThumbnailFormat[] = THUMBNAIL_FORMATS
+  ): Promise<ThumbnailVariant[]> {
+    const variants: ThumbnailVariant[] = [];
+
+    for (const size of sizes) {
+      for (const format of formats) {
+        const thumbnail = await this.generateImageThumbnail(buffer, {
+          width: size.maxDimension,
+          height: size.maxDimension,
+          quality: THUMBNAIL_QUALITY[format],
+          format,
+        });
+
+        variants.push({
+          size: size.name,
+          format,
+          buffer: thumbnail.buffer,
+          width: thumbnail.metadata.thumbnailWidth,
+          height: thumbnail.metadata.thumbnailHeight,
+        });
+      }
+    }
+
+    return variants;
+  }
+
+  // Generate video thumbnail from a file path or URL
   static async generateVideoThumbnail(
     videoPath: string,
     options: ThumbnailOptions = {}
@@ -77,7 +184,8 @@ export class ThumbnailGenerator {
         const {
           width = 300,
           height = 300,
-          quality = 80
+          quality = 80,
+          timestamp = VIDEO_POSTER_TIMESTAMP,
         } = options;
 
         const thumbnailPath = path.join(
@@ -117,10 +225,10 @@ export class ThumbnailGenerator {
             reject(new Error('Failed to generate video thumbnail'));
           })
           .screenshots({
-            timestamps: ['10%'], // Take screenshot at 10% of video duration
+            timestamps: [timestamp],
             filename: path.basename(thumbnailPath),
             folder: path.dirname(thumbnailPath),
-            size: `${width}x${height}`,
+            // Full frame; scaled to fit above so the aspect ratio is kept
           });
 
       } catch (error) {
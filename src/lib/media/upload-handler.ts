@@ -0,0 +1,148 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { Readable } from 'stream';
+import { mediaUploadService } from './upload';
+import { parseMultipartStream, getMultipartBoundary } from './multipart-stream';
+import { uploadFileSchema } from './validation';
+import { uploadThrottle, UploadSlot, UploadThrottleError } from './upload-throttle';
+import {
+  PayloadTooLargeError,
+  checkContentLength,
+  getBodyLimit,
+  limitStream,
+  payloadTooLargeResponse,
+} from '../security/body-limit';
+import { permissionService } from '../security/permissions';
+import { logger } from '../monitoring/logging';
+import { analyticsService } from '../monitoring/analytics';
+import connectDB from '../database/mongodb';
+
+type UploadType = 'image' | 'video' | 'audio' | 'voice' | 'document';
+
+// Shared handler for the /media/upload/* routes: streams the multipart file
+// part straight to storage while enforcing the per-route body limit.
+export async function handleMediaUpload(request: NextRequest, type: UploadType): Promise<NextResponse> {
+  const limit = getBodyLimit(request.nextUrl.pathname);
+  let slot: UploadSlot | null = null;
+
+  try {
+    await connectDB();
+
+    const userId = (request as any).user?.userId;
+    if (!userId) {
+      return NextResponse.json(
+        { error: 'Authentication required' },
+        { status: 401 }
+      );
+    }
+
+    const tooLarge = checkContentLength(request, limit);
+    if (tooLarge) {
+      return tooLarge;
+    }
+
+    const boundary = getMultipartBoundary(request.headers.get('content-type'));
+    if (!boundary || !request.body) {
+      return NextResponse.json(
+        { error: 'Expected multipart/form-data body' },
+        { status: 400 }
+      );
+    }
+
+    // Held until the file is stored, so a user can't pile up slow uploads
+    slot = await uploadThrottle.acquire(userId);
+
+    const { fields, file } = await parseMultipartStream(slot.throttle(limitStream(request.body, limit)), boundary, request.signal);
+
+    const validationResult = uploadFileSchema.safeParse({ type, chatId: fields.chatId || undefined });
+    if (!validationResult.success) {
+      file.stream.resume();
+      return NextResponse.json(
+        {
+          error: 'Validation failed',
+          details: validationResult.error.errors.map(err => ({
+            field: err.path.join('.'),
+            message: err.message,
+          })),
+        },
+        { status: 400 }
+      );
+    }
+
+    const { chatId } = validationResult.data;
+    if (!(await permissionService.canUploadMedia(userId, chatId))) {
+      file.stream.resume();
+      return NextResponse.json(
+        { error: 'Not authorized to upload to this chat' },
+        { status: 403 }
+      );
+    }
+
+    const declaredSize = parseInt(request.headers.get('content-length') || '', 10);
+    const result = await mediaUploadService.uploadStream(
+      file.stream as Readable,
+      file.filename,
+      file.mimeType,
+      userId,
+      type,
+      {
+        chatId,
+        declaredSize: isNaN(declaredSize) ? undefined : declaredSize,
+      }
+    );
+
+    analyticsService.trackFileUpload(type, result.originalSize, userId);
+    logger.logMedia('File uploaded', {
+      userId,
+      fileType: type,
+      fileSize: result.originalSize,
+      chatId,
+    });
+
+    return NextResponse.json({
+      message: 'File uploaded successfully',
+      media: {
+        id: result.media._id.toString(),
+        url: result.media.url,
+        type: result.media.type,
+        mimeType: result.media.mimeType,
+        size: result.media.size,
+        originalName: result.media.originalName,
+        thumbnailUrl: result.media.thumbnailUrl,
+        // Duration, dimensions and codecs, for previews without downloading
+        metadata: result.media.metadata,
+      },
+    }, { status: 201 });
+
+  } catch (error) {
+    if (error instanceof UploadThrottleError) {
+      return NextResponse.json(
+        { error: error.message, retryAfter: error.retryAfter },
+        {
+          status: error.status,
+          headers: { 'Retry-After': error.retryAfter.toString() },
+        }
+      );
+    }
+
+    if (error instanceof PayloadTooLargeError) {
+      return payloadTooLargeResponse(error.limit, error.received);
+    }
+
+    if (error instanceof Error && error.message.startsWith('File validation failed')) {
+      return NextResponse.json(
+        { error: error.message },
+        { status: 400 }
+      );
+    }
+
+    logger.error('Media upload endpoint error', error, { fileType: type });
+    analyticsService.trackError(error as Error);
+
+    return NextResponse.json(
+      { error: 'Internal server error' },
+      { status: 500 }
+    );
+  } finally {
+    await slot?.release();
+  }
+}
@@ -0,0 +1,133 @@
+import { redisConfig } from '../config/redis';
+import { environmentConfig } from '../config/environment';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const ACTIVE_KEY_PREFIX = 'upload:active';
+const ACTIVE_TTL = 60 * 60; // seconds; bounds slots leaked by a crashed process
+const USER_RETRY_AFTER = 10; // seconds
+const SERVER_RETRY_AFTER = 30;
+const BANDWIDTH_BURST_MS = 1000; // up to a second's worth of bytes passes unthrottled
+
+export interface UploadSlot {
+  release(): Promise<void>;
+  // Pace a body stream to the user's bandwidth allowance
+  throttle(body: ReadableStream<Uint8Array>): ReadableStream<Uint8Array>;
+}
+
+// Keeps one user uploading many large files from saturating the server: caps
+// concurrent uploads per user (across processes when Redis is available) and
+// per process, and optionally paces each user's upload bandwidth. Limits come
+// from UPLOAD_MAX_CONCURRENT_PER_USER, UPLOAD_MAX_CONCURRENT and
+// UPLOAD_BANDWIDTH_PER_USER.
+export class UploadThrottle {
+  private redis = redisConfig.getClient();
+  private memoryActive = new Map<string, number>();
+  private serverActive = 0;
+  // When each user's bandwidth allowance is next free, shared by their uploads
+  private bandwidthClock = new Map<string, number>();
+
+  // Take an upload slot, or throw when the user or server is at capacity
+  async acquire(userId: string): Promise<UploadSlot> {
+    const env = environmentConfig.get();
+
+    if (env.UPLOAD_MAX_CONCURRENT > 0 && this.serverActive >= env.UPLOAD_MAX_CONCURRENT) {
+      metricsCollector.incrementCounter('upload_throttled', 1, { reason: 'server' });
+      throw new UploadThrottleError('Server is busy with other uploads, try again shortly', 503, SERVER_RETRY_AFTER);
+    }
+
+    if (env.UPLOAD_MAX_CONCURRENT_PER_USER > 0) {
+      const active = await this.increment(userId);
+      if (active > env.UPLOAD_MAX_CONCURRENT_PER_USER) {
+        await this.decrement(userId);
+        metricsCollector.incrementCounter('upload_throttled', 1, { reason: 'user' });
+        throw new UploadThrottleError(
+          `Too many uploads in progress, at most ${env.UPLOAD_MAX_CONCURRENT_PER_USER} at a time`,
+          429,
+          USER_RETRY_AFTER
+        );
+      }
+    }
+
+    this.serverActive++;
+    let released = false;
+
+    return {
+      release: async () => {
+        if (released) return;
+        released = true;
+        this.serverActive--;
+        if (env.UPLOAD_MAX_CONCURRENT_PER_USER > 0) {
+          await this.decrement(userId).catch(error => {
+            logger.error('Failed to release upload slot', error, { userId });
+          });
+        }
+      },
+      throttle: body => this.throttle(userId, body, env.UPLOAD_BANDWIDTH_PER_USER),
+    };
+  }
+
+  private throttle(userId: string, body: ReadableStream<Uint8Array>, bytesPerSecond: number): ReadableStream<Uint8Array> {
+    if (!bytesPerSecond || bytesPerSecond <= 0) {
+      return body;
+    }
+
+    return body.pipeThrough(new TransformStream<Uint8Array, Uint8Array>({
+      transform: async (chunk, controller) => {
+        const now = Date.now();
+        const freeAt = Math.max(this.bandwidthClock.get(userId) ?? now, now) + (chunk.byteLength / bytesPerSecond) * 1000;
+        this.bandwidthClock.set(userId, freeAt);
+
+        const wait = freeAt - now - BANDWIDTH_BURST_MS;
+        if (wait > 0) {
+          await new Promise(resolve => setTimeout(resolve, wait));
+        }
+        controller.enqueue(chunk);
+      },
+      flush: () => {
+        if ((this.bandwidthClock.get(userId) ?? 0) <= Date.now()) {
+          this.bandwidthClock.delete(userId);
+        }
+      },
+    }));
+  }
+
+  private async increment(userId: string): Promise<number> {
+    if (this.redis) {
+      const key = `${ACTIVE_KEY_PREFIX}:${userId}`;
+      const results = await this.redis.multi().incr(key).expire(key, ACTIVE_TTL).exec();
+      return Number(results?.[0]?.[1] ?? 0);
+    }
+
+    const active = (this.memoryActive.get(userId) ?? 0) + 1;
+    this.memoryActive.set(userId, active);
+    return active;
+  }
+
+  private async decrement(userId: string): Promise<void> {
+    if (this.redis) {
+      const key = `${ACTIVE_KEY_PREFIX}:${userId}`;
+      const remaining = await this.redis.decr(key);
+      if (remaining <= 0) {
+        await this.redis.del(key);
+      }
+      return;
+    }
+
+    const active = (this.memoryActive.get(userId) ?? 1) - 1;
+    if (active <= 0) {
+      this.memoryActive.delete(userId);
+    } else {
+      this.memoryActive.set(userId, active);
+    }
+  }
+}
+
+export class UploadThrottleError extends Error {
+  constructor(message: string, public status: number, public retryAfter: number) {
+    super(message);
+    this.name = 'UploadThrottleError';
+  }
+}
+
+export const uploadThrottle = new UploadThrottle();
@@ -1,10 +1,23 @@
 import { MediaRepository } from '../database/repositories/media';
 import { IMedia } from '../database/models/media';
-import { s3Service } from './s3';
+import { storageService, regionOfKey } from './storage';
 import { FileValidator, FILE_CONFIGS } from './validation';
 import { MediaCompressor } from './compression';
-import { ThumbnailGenerator } from './thumbnail';
+import {
+  ThumbnailGenerator,
+  THUMBNAIL_SIZES,
+  THUMBNAIL_MIME_TYPES,
+  DEFAULT_THUMBNAIL_SIZE,
+  resolvePosterTimestamp,
+} from './thumbnail';
+import { hashBlocklistService } from './hash-blocklist';
+import { storageReferenceService } from './storage-references';
+import { dataResidencyService } from '../compliance/data-residency';
+import { legalHoldService } from '../compliance/legal-holds';
+import { jobQueue } from '../jobs';
+import { DataRegion } from '../database/models/user';
 import crypto from 'crypto';
+import { Readable } from 'stream';
 import { Types } from 'mongoose';
 
 interface UploadFileOptions {
@@ -53,15 +66,24 @@ export class MediaUploadService {
         throw new Error('File appears to be malicious and cannot be uploaded');
       }
 
+      const perceptualHash = type === 'image' ? await this.checkBlockedImage(file, uploadedBy) : undefined;
+
       // Generate file checksum
       const checksum = crypto.createHash('sha256').update(file).digest('hex');
 
-      // Check for duplicate files
-      const existingMedia = await this.mediaRepository.findByChecksum(checksum);
-      if (existingMedia) {
-        // Return existing media instead of uploading duplicate
+      // Share the stored copy of a file that was uploaded before, as long
+      // as it is kept in the uploader's data region
+      const region = await dataResidencyService.getUserRegion(uploadedBy) ?? undefined;
+      const existingMedia = await this.mediaRepository.findByChecksum(checksum, region);
+      const sharedMedia = existingMedia && await this.createReference(
+        existingMedia,
+        validation.sanitizedName,
+        uploadedBy,
+        options
+      );
+      if (sharedMedia) {
         return {
-          media: existingMedia,
+          media: sharedMedia,
           originalSize: file.length,
         };
       }
@@ -77,8 +99,8 @@ export class MediaUploadService {
         metadata = { ...metadata, ...compressionResult.metadata };
       }
 
-      // Upload to S3
-      const uploadResult = await s3Service.uploadFile(
+      // Upload to storage
+      const uploadResult = await storageService.uploadFile(
         processedFile,
         validation.sanitizedName,
         uploadedBy,
@@ -89,34 +111,37 @@ export class MediaUploadService {
             fileType: type,
             compressed: options.compress ? 'true' : 'false',
           },
+          region,
         }
       );
 
-      let thumbnailUrl: string | undefined;
+      let thumbnail: { url: string; key: string; variants: NonNullable<IMedia['thumbnails']> } | undefined;
 
       // Generate thumbnail if requested
       if (options.generateThumbnail && (type === 'image' || type === 'video')) {
         try {
-          thumbnailUrl = await this.generateAndUploadThumbnail(
+          thumbnail = await this.generateAndUploadThumbnail(
             processedFile,
             originalName,
             type,
-            uploadedBy
+            uploadedBy,
+            region
           );
         } catch (error) {
-          console.warn('Thumbnail generation failed:', error);
-          // Continue without thumbnail
+          console.warn('Thumbnail generation failed, retrying in the background:', error);
         }
       }
 
       // Create media record in database
-      const media = await this.mediaRepository.create({
+      let media = await this.mediaRepository.create({
         filename: uploadResult.key,
         originalName: validation.sanitizedName,
         mimeType,
         size: processedFile.length,
         url: uploadResult.url,
-        thumbnailUrl,
+        thumbnailUrl: thumbnail?.url,
+        thumbnailKey: thumbnail?.key,
+        thumbnails: thumbnail?.variants,
         uploadedBy: uploadedBy as any,
         chatId: options.chatId as any,
         messageId: options.messageId as any,
@@ -124,7 +149,16 @@ export class MediaUploadService {
         metadata,
         isEncrypted: false, // TODO: Implement encryption
         checksumSHA256: checksum,
+        perceptualHash,
+        storageRegion: region,
       });
+      if (type === 'video') {
+        media = await this.describeVideo(media);
+      }
+      await storageReferenceService.track(media);
+      if (options.generateThumbnail && type === 'image' && !thumbnail) {
+        await this.queueThumbnail(media);
+      }
 
       return {
         media,
@@ -139,6 +173,234 @@ export class MediaUploadService {
     }
   }
 
+  // Upload a file from a stream without buffering it in memory.
+  // Compression needs the whole file, so it is skipped here; image
+  // thumbnails are made in the background from the stored copy.
+  async uploadStream(
+    stream: Readable,
+    originalName: string,
+    mimeType: string,
+    uploadedBy: string,
+    type: 'image' | 'video' | 'audio' | 'voice' | 'document',
+    options: UploadFileOptions & { declaredSize?: number } = {}
+  ): Promise<UploadResult> {
+    // Size is unknown until the stream ends; validate name and type up front
+    const validation = FileValidator.validateFile(
+      { name: originalName, size: options.declaredSize || 1, type: mimeType },
+      type
+    );
+
+    if (!validation.isValid) {
+      stream.resume();
+      throw new Error(`File validation failed: ${validation.errors.join(', ')}`);
+    }
+
+    if (FileValidator.checkMaliciousFile(originalName, mimeType)) {
+      stream.resume();
+      throw new Error('File appears to be malicious and cannot be uploaded');
+    }
+
+    const region = await dataResidencyService.getUserRegion(uploadedBy) ?? undefined;
+    const uploadResult = await storageService.uploadStream(
+      stream,
+      validation.sanitizedName,
+      uploadedBy,
+      {
+        contentType: mimeType,
+        metadata: {
+          fileType: type,
+          compressed: 'false',
+        },
+        region,
+      }
+    );
+
+    if (uploadResult.size === 0) {
+      await storageService.deleteFile(uploadResult.key);
+      throw new Error('File validation failed: File is empty');
+    }
+
+    // Images are small enough to read back for hashing. An image that can't
+    // be checked against the blocklist is not kept.
+    let perceptualHash: string | undefined;
+    if (type === 'image') {
+      try {
+        const image = await storageService.getFileBuffer(uploadResult.key);
+        perceptualHash = await this.checkBlockedImage(image, uploadedBy);
+      } catch (error) {
+        await storageService.deleteFile(uploadResult.key);
+        throw error;
+      }
+    }
+
+    // Deduplicate after the fact since the checksum is only known once streamed
+    const existingMedia = await this.mediaRepository.findByChecksum(uploadResult.checksum, region);
+    const sharedMedia = existingMedia && await this.createReference(
+      existingMedia,
+      validation.sanitizedName,
+      uploadedBy,
+      options
+    );
+    if (sharedMedia) {
+      await storageService.deleteFile(uploadResult.key);
+      return {
+        media: sharedMedia,
+        originalSize: uploadResult.size,
+      };
+    }
+
+    let media = await this.mediaRepository.create({
+      filename: uploadResult.key,
+      originalName: validation.sanitizedName,
+      mimeType,
+      size: uploadResult.size,
+      url: uploadResult.url,
+      uploadedBy: uploadedBy as any,
+      chatId: options.chatId as any,
+      messageId: options.messageId as any,
+      type,
+      isEncrypted: false,
+      checksumSHA256: uploadResult.checksum,
+      perceptualHash,
+      storageRegion: region,
+    });
+    if (type === 'video') {
+      media = await this.describeVideo(media);
+    }
+    await storageReferenceService.track(media);
+    if (options.generateThumbnail && type === 'image') {
+      await this.queueThumbnail(media);
+    }
+
+    return {
+      media,
+      originalSize: uploadResult.size,
+    };
+  }
+
+  // Probe a stored video for duration, resolution and codecs, and give it a
+  // poster frame, so clients can show a preview without downloading it. Both
+  // read the file through a signed URL; failures leave the record as it was.
+  private async describeVideo(media: IMedia): Promise<IMedia> {
+    try {
+      const source = await storageService.getFileUrl(media.filename, 15 * 60);
+      const info = await MediaCompressor.getMediaInfo(source);
+      const posterAt = resolvePosterTimestamp(info.duration);
+
+      let thumbnail: Awaited<ReturnType<MediaUploadService['generateAndUploadThumbnail']>> | undefined;
+      if (!media.thumbnailKey && info.dimensions) {
+        thumbnail = await this.generateAndUploadThumbnail(
+          source,
+          media.originalName,
+          'video',
+          media.uploadedBy.toString(),
+          regionOfKey(media.filename) ?? undefined,
+          posterAt
+        ).catch(error => {
+          console.warn('Video poster generation failed:', error);
+          return undefined;
+        });
+      }
+
+      const updated = await this.mediaRepository.update(media._id, {
+        metadata: {
+          ...media.toObject().metadata,
+          ...info,
+          ...(thumbnail && { posterAt }),
+        },
+        ...(thumbnail && {
+          thumbnailUrl: thumbnail.url,
+          thumbnailKey: thumbnail.key,
+          thumbnails: thumbnail.variants,
+        }),
+      });
+      return updated || media;
+    } catch (error) {
+      console.warn('Video probe failed:', error);
+      return media;
+    }
+  }
+
+  // Give a stored image or video its thumbnails, for uploads that couldn't
+  // make them inline. Run by the media:thumbnail job; does nothing when the
+  // record already has them.
+  async generateThumbnail(mediaId: string): Promise<void> {
+    const media = await this.mediaRepository.findById(mediaId);
+    if (!media || media.thumbnailKey || (media.type !== 'image' && media.type !== 'video')) {
+      return;
+    }
+
+    const source = media.type === 'image'
+      ? await storageService.getFileBuffer(media.filename)
+      : await storageService.getFileUrl(media.filename, 15 * 60);
+    const thumbnail = await this.generateAndUploadThumbnail(
+      source,
+      media.originalName,
+      media.type,
+      media.uploadedBy.toString(),
+      regionOfKey(media.filename) ?? undefined,
+      media.metadata?.posterAt
+    );
+
+    const updated = await this.mediaRepository.update(media._id, {
+      thumbnailUrl: thumbnail.url,
+      thumbnailKey: thumbnail.key,
+      thumbnails: thumbnail.variants,
+    });
+    if (updated) {
+      await storageReferenceService.trackThumbnails(updated);
+    }
+  }
+
+  private async queueThumbnail(media: IMedia): Promise<void> {
+    await jobQueue.enqueue('media:thumbnail', { mediaId: media._id.toString() }).catch(error => {
+      console.warn('Queueing thumbnail generation failed:', error);
+    });
+  }
+
+  // New record for this upload pointing at an already stored copy of the
+  // same file. Null when that copy is being removed.
+  private async createReference(
+    existing: IMedia,
+    originalName: string,
+    uploadedBy: string,
+    options: UploadFileOptions
+  ): Promise<IMedia | null> {
+    if (!(await storageReferenceService.acquire(existing))) {
+      return null;
+    }
+
+    return await this.mediaRepository.create({
+      filename: existing.filename,
+      originalName,
+      mimeType: existing.mimeType,
+      size: existing.size,
+      url: existing.url,
+      thumbnailUrl: existing.thumbnailUrl,
+      thumbnailKey: existing.thumbnailKey,
+      thumbnails: existing.thumbnails,
+      uploadedBy: uploadedBy as any,
+      chatId: options.chatId as any,
+      messageId: options.messageId as any,
+      type: existing.type,
+      metadata: existing.metadata,
+      isEncrypted: existing.isEncrypted,
+      checksumSHA256: existing.checksumSHA256,
+      perceptualHash: existing.perceptualHash,
+      storageRegion: existing.storageRegion,
+    });
+  }
+
+  // Refuse near duplicates of blocklisted images; returns the image's hash
+  private async checkBlockedImage(image: Buffer, uploadedBy: string): Promise<string | undefined> {
+    const { hash, match } = await hashBlocklistService.inspect(image);
+    if (match) {
+      await hashBlocklistService.recordMatch(match, uploadedBy);
+      throw new Error('File validation failed: File matches blocked content');
+    }
+    return hash;
+  }
+
   // Compress file based on type
   private async compressFile(
     file: Buffer,
@@ -196,65 +458,110 @@ export class MediaUploadService {
     }
   }
 
-  // Generate and upload thumbnail
+  // Generate and upload thumbnails in every configured size and format. The
+  // default size as JPEG becomes thumbnailUrl.
+  // A video can be given as a URL to take the poster frame from without
+  // downloading it.
   private async generateAndUploadThumbnail(
-    file: Buffer,
+    file: Buffer | string,
     originalName: string,
     type: 'image' | 'video',
-    uploadedBy: string
-  ): Promise<string> {
-    if (type === 'image') {
-      const thumbnail = await ThumbnailGenerator.generateImageThumbnail(file, {
-        width: 300,
-        height: 300,
-        quality: 80,
-      });
-
-      const uploadResult = await s3Service.uploadFile(
-        thumbnail.buffer,
-        `thumb_${originalName}`,
-        uploadedBy,
-        {
-          contentType: 'image/jpeg',
-        },
-        'thumbnail'
-      );
+    uploadedBy: string,
+    region?: DataRegion,
+    posterAt?: number
+  ): Promise<{ url: string; key: string; variants: NonNullable<IMedia['thumbnails']> }> {
+    let source = file;
+    const tempPaths: string[] = [];
 
-      return uploadResult.url;
-    } else if (type === 'video') {
-      // For video, write to temp file first
-      const tempPath = `/tmp/${Date.now()}_${originalName}`;
-      require('fs').writeFileSync(tempPath, file);
+    try {
+      if (type === 'video') {
+        let input = file;
+        if (Buffer.isBuffer(file)) {
+          input = `/tmp/${Date.now()}_${originalName}`;
+          require('fs').writeFileSync(input, file);
+          tempPaths.push(input);
+        }
 
-      try {
-        const thumbnail = await ThumbnailGenerator.generateVideoThumbnail(tempPath, {
-          width: 300,
-          height: 300,
+        // Take a frame at the largest size and scale it down from there
+        const largest = Math.max(...THUMBNAIL_SIZES.map(size => size.maxDimension));
+        const frame = await ThumbnailGenerator.generateVideoThumbnail(input as string, {
+          width: largest,
+          height: largest,
+          ...(posterAt !== undefined && { timestamp: posterAt }),
         });
+        tempPaths.push(frame.thumbnailPath);
+        source = require('fs').readFileSync(frame.thumbnailPath);
+      }
 
-        const thumbnailBuffer = require('fs').readFileSync(thumbnail.thumbnailPath);
-        
-        const uploadResult = await s3Service.uploadFile(
-          thumbnailBuffer,
-          `thumb_${originalName}.jpg`,
+      const generated = await ThumbnailGenerator.generateImageVariants(source as Buffer);
+      const variants: NonNullable<IMedia['thumbnails']> = [];
+      let primary: { url: string; key: string } | undefined;
+
+      for (const variant of generated) {
+        const uploadResult = await storageService.uploadFile(
+          variant.buffer,
+          `thumb_${variant.size}_${originalName}.${variant.format === 'jpeg' ? 'jpg' : variant.format}`,
           uploadedBy,
           {
-            contentType: 'image/jpeg',
+            contentType: THUMBNAIL_MIME_TYPES[variant.format],
+            region,
           },
           'thumbnail'
         );
 
-        await ThumbnailGenerator.cleanupThumbnails([tempPath, thumbnail.thumbnailPath]);
-        return uploadResult.url;
-      } catch (error) {
-        try {
-          require('fs').unlinkSync(tempPath);
-        } catch {}
-        throw error;
+        variants.push({
+          size: variant.size,
+          format: variant.format,
+          key: uploadResult.key,
+          width: variant.width,
+          height: variant.height,
+          bytes: variant.buffer.length,
+        });
+        if (variant.size === DEFAULT_THUMBNAIL_SIZE && variant.format === 'jpeg') {
+          primary = { url: uploadResult.url, key: uploadResult.key };
+        }
       }
+
+      if (!primary) {
+        throw new Error('Default thumbnail was not generated');
+      }
+      return { ...primary, variants };
+    } finally {
+      if (tempPaths.length) {
+        await ThumbnailGenerator.cleanupThumbnails(tempPaths);
+      }
+    }
+  }
+
+  // Fetch a remote file and store it, e.g. media bridged from another network.
+  // The media type is inferred from the MIME type and file name.
+  async uploadFromUrl(
+    url: string,
+    uploadedBy: string,
+    options: UploadFileOptions & { name?: string; mimeType?: string; headers?: Record<string, string> } = {}
+  ): Promise<UploadResult> {
+    const { name, mimeType, headers, ...uploadOptions } = options;
+
+    const response = await fetch(url, { headers });
+    if (!response.ok || !response.body) {
+      throw new Error(`Remote media download failed with status ${response.status}`);
     }
 
-    throw new Error('Unsupported file type for thumbnail generation');
+    const contentType = (mimeType || response.headers.get('content-type') || 'application/octet-stream')
+      .split(';')[0]
+      .trim();
+    const originalName = name || decodeURIComponent(new URL(url).pathname.split('/').pop() || 'file');
+    const type = FileValidator.detectFileType(contentType, FileValidator.getFileExtension(originalName));
+    const declaredSize = parseInt(response.headers.get('content-length') || '0', 10) || undefined;
+
+    return await this.uploadStream(
+      Readable.fromWeb(response.body as any),
+      originalName,
+      contentType,
+      uploadedBy,
+      type,
+      { ...uploadOptions, declaredSize }
+    );
   }
 
   // Download file
@@ -267,7 +574,7 @@ export class MediaUploadService {
 
     // TODO: Add permission check based on chat membership
     
-    const stream = await s3Service.getFileStream(media.filename);
+    const stream = await storageService.getFileStream(media.filename);
     
     if (!stream) {
       throw new Error('Failed to get file stream');
@@ -289,19 +596,19 @@ export class MediaUploadService {
       throw new Error('Not authorized to delete this file');
     }
 
-    // Delete from S3
-    const s3Deleted = await s3Service.deleteFile(media.filename);
-    
-    // Delete thumbnail if exists
-    if (media.thumbnailUrl) {
-      const thumbnailKey = media.filename.replace('media/', 'thumbnail/');
-      await s3Service.deleteFile(thumbnailKey);
+    if (await legalHoldService.isMediaHeld(media)) {
+      throw new Error('File is under legal hold');
     }
 
     // Delete from database
     const dbDeleted = await this.mediaRepository.delete(mediaId);
 
-    return s3Deleted && dbDeleted;
+    // Delete from storage and the thumbnail with it, unless other uploads share the file
+    if (dbDeleted) {
+      await storageReferenceService.release(media);
+    }
+
+    return dbDeleted;
   }
 
   // Get file URL with expiration
@@ -312,7 +619,7 @@ export class MediaUploadService {
       throw new Error('Media file not found');
     }
 
-    return await s3Service.getFileUrl(media.filename, expiresIn);
+    return await storageService.getFileUrl(media.filename, expiresIn);
   }
 
   // Batch upload files
@@ -0,0 +1,315 @@
+import { Types } from 'mongoose';
+import { ModerationActionRepository } from '../database/repositories/moderation-action';
+import { MessageRepository } from '../database/repositories/message';
+import { ChatRepository } from '../database/repositories/chat';
+import { UserRepository } from '../database/repositories/user';
+import { IModerationAction, ModerationActionKind } from '../database/models/moderation-action';
+import { IMessage } from '../database/models/message';
+import { IUser } from '../database/models/user';
+import { BanUserInput, RestrictUserInput } from '../database/schemas/moderation';
+import { pseudonymService } from '../security/pseudonyms';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 60 * 1000;
+const CACHE_TTL = 30 * 1000;
+const BATCH_SIZE = 100;
+
+export type UserRestriction = NonNullable<IUser['restriction']>;
+
+// Restriction the user is under right now; expired ones count as lifted even
+// before the worker gets to them
+export function getActiveRestriction(
+  user: Pick<IUser, 'restriction'>,
+  now: Date = new Date()
+): UserRestriction | null {
+  const restriction = user.restriction;
+  if (!restriction?.mode) return null;
+  if (restriction.expiresAt && restriction.expiresAt <= now) return null;
+  return restriction;
+}
+
+// Bans and restricted mode. A restricted user can keep using the app, but
+// their messages are only shown to themselves (shadow) or held until a
+// moderator approves them (review); they can't place or join calls and don't
+// show up in search. Every ban and restriction is recorded as a moderation
+// action and lifted automatically when it expires.
+export class ModerationService {
+  private moderationActionRepository = new ModerationActionRepository();
+  private messageRepository = new MessageRepository();
+  private chatRepository = new ChatRepository();
+  private userRepository = new UserRepository();
+  private restrictions: Map<string, { restriction: UserRestriction | null; loadedAt: number }> = new Map();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Start lifting actions as they expire. Safe to run on several instances:
+  // each action is lifted only once.
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Restriction in force for a user, cached briefly since every message and
+  // call checks it. Other instances see changes once their cache expires.
+  async getRestriction(userId: string): Promise<UserRestriction | null> {
+    const cached = this.restrictions.get(userId);
+    if (cached && Date.now() - cached.loadedAt < CACHE_TTL) {
+      return getActiveRestriction(cached);
+    }
+
+    const user = await this.userRepository.findById(userId);
+    const restriction = user ? getActiveRestriction(user) : null;
+    this.restrictions.set(userId, { restriction, loadedAt: Date.now() });
+    return restriction;
+  }
+
+  async ban(userId: string, input: BanUserInput, adminId: string): Promise<IModerationAction> {
+    const user = await this.requireUser(userId);
+    const action = await this.issue(user, 'ban', input, adminId);
+
+    await this.userRepository.banUser(user._id, input.reason, action.expiresAt);
+
+    // Sessions are refused from now on; drop the live connections too
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.disconnectUser(userId);
+
+    return action;
+  }
+
+  async unban(userId: string, reason: string, adminId: string): Promise<void> {
+    const user = await this.requireUser(userId);
+    if (!user.isBanned) {
+      throw new ModerationError('User is not banned', 409);
+    }
+
+    const action = await this.moderationActionRepository.findActive(user._id, 'ban');
+    if (action) {
+      await this.lift(action, reason, adminId);
+    } else {
+      // Banned before moderation actions were recorded
+      await this.userRepository.unbanUser(user._id);
+    }
+  }
+
+  async restrict(userId: string, input: RestrictUserInput, adminId: string): Promise<IModerationAction> {
+    const user = await this.requireUser(userId);
+    const action = await this.issue(user, 'restrict', input, adminId);
+
+    await this.userRepository.setRestriction(user._id, {
+      mode: input.mode,
+      actionId: action._id,
+      expiresAt: action.expiresAt,
+    });
+    this.restrictions.delete(userId);
+
+    return action;
+  }
+
+  async unrestrict(userId: string, reason: string, adminId: string): Promise<void> {
+    const user = await this.requireUser(userId);
+    const action = await this.moderationActionRepository.findActive(user._id, 'restrict');
+    if (!action) {
+      throw new ModerationError('User is not restricted', 409);
+    }
+
+    await this.lift(action, reason, adminId);
+  }
+
+  // Lift a specific action, e.g. when an appeal against it is approved
+  async liftAction(actionId: string | Types.ObjectId, reason: string, adminId: string): Promise<void> {
+    const action = await this.moderationActionRepository.findById(actionId);
+    if (!action) {
+      throw new ModerationError('Moderation action not found', 404);
+    }
+    if (action.liftedAt) {
+      throw new ModerationError('Moderation action is no longer in force', 409);
+    }
+
+    await this.lift(action, reason, adminId);
+  }
+
+  async getHistory(userId: string): Promise<IModerationAction[]> {
+    const user = await this.requireUser(userId);
+    return await this.moderationActionRepository.findByUser(user._id);
+  }
+
+  async listActive(action?: ModerationActionKind, limit: number = 50, offset: number = 0) {
+    return await this.moderationActionRepository.findActiveAll(action, limit, offset);
+  }
+
+  async getWithheldCounts(userId: string): Promise<Record<string, number>> {
+    return await this.messageRepository.countWithheldBySender(userId);
+  }
+
+  async getReviewQueue(limit: number = 50, offset: number = 0) {
+    return await this.messageRepository.getReviewQueue(limit, offset);
+  }
+
+  // Approve a held message, delivering it to the chat as if just sent, or
+  // reject it so it stays visible to the sender only
+  async reviewMessage(messageId: string, decision: 'approve' | 'reject', adminId: string): Promise<IMessage> {
+    const message = Types.ObjectId.isValid(messageId)
+      ? await this.messageRepository.review(messageId, decision === 'approve' ? 'approved' : 'rejected', adminId)
+      : null;
+    if (!message) {
+      throw new ModerationError('Message not found or already reviewed', 404);
+    }
+
+    metricsCollector.incrementCounter(`moderation_messages_${decision === 'approve' ? 'approved' : 'rejected'}`, 1);
+    logger.info('Held message reviewed', { messageId, decision, adminId });
+
+    if (decision === 'approve') {
+      await this.deliver(message);
+    }
+    return message;
+  }
+
+  private async issue(
+    user: IUser,
+    kind: ModerationActionKind,
+    input: BanUserInput & { mode?: RestrictUserInput['mode'] },
+    adminId: string
+  ): Promise<IModerationAction> {
+    if (user.bridge) {
+      throw new ModerationError('Bridged accounts can not be moderated', 400);
+    }
+
+    // A new action replaces the one in force
+    const current = await this.moderationActionRepository.findActive(user._id, kind);
+    if (current) {
+      await this.moderationActionRepository.lift(current._id, 'Superseded', adminId);
+    }
+
+    const action = await this.moderationActionRepository.create({
+      userId: user._id,
+      action: kind,
+      mode: input.mode,
+      reason: input.reason,
+      note: input.note,
+      ...(input.reportId && { reportId: new Types.ObjectId(input.reportId) }),
+      issuedBy: new Types.ObjectId(adminId),
+      ...(input.durationHours && { expiresAt: new Date(Date.now() + input.durationHours * 60 * 60 * 1000) }),
+    });
+
+    metricsCollector.incrementCounter(`moderation_${kind}_issued`, 1);
+    logger.info('Moderation action issued', {
+      actionId: action._id.toString(),
+      userId: user._id.toString(),
+      action: kind,
+      mode: input.mode,
+      expiresAt: action.expiresAt,
+      adminId,
+    });
+
+    return action;
+  }
+
+  // Lift an action and undo its effect on the user
+  private async lift(action: IModerationAction, reason: string, adminId?: string): Promise<void> {
+    const lifted = await this.moderationActionRepository.lift(action._id, reason, adminId);
+    if (!lifted) return;
+
+    if (action.action === 'ban') {
+      await this.userRepository.unbanUser(action.userId);
+    } else {
+      await this.userRepository.clearRestriction(action.userId, action._id);
+      this.restrictions.delete(action.userId.toString());
+    }
+
+    metricsCollector.incrementCounter(`moderation_${action.action}_lifted`, 1);
+    logger.info('Moderation action lifted', {
+      actionId: action._id.toString(),
+      userId: action.userId.toString(),
+      action: action.action,
+      reason,
+      adminId,
+    });
+  }
+
+  // Deliver a held message to its chat as if just sent
+  async deliver(message: IMessage): Promise<void> {
+    const chatId = message.chatId.toString();
+    await this.chatRepository.updateLastActivity(chatId, message._id);
+
+    const [{ socketManager }, { messageTaskQueue }] = await Promise.all([
+      import('../realtime/socket'),
+      import('../realtime/message-tasks'),
+    ]);
+
+    const populatedMessage = await this.messageRepository.findById(message._id);
+    if (populatedMessage?.pseudonym || populatedMessage?.metadata?.poll?.anonymous) {
+      const chat = await this.chatRepository.findById(chatId);
+      if (chat) await pseudonymService.emitMessage(chat, 'message:new', populatedMessage);
+    } else {
+      socketManager.emitToChat(chatId, 'message:new', populatedMessage);
+    }
+
+    messageTaskQueue.enqueue('push:message', { messageId: message._id.toString() });
+    messageTaskQueue.enqueue('relay:federation', { messageId: message._id.toString() });
+    messageTaskQueue.enqueue('relay:email', { messageId: message._id.toString() });
+    messageTaskQueue.enqueue('automation:message', { messageId: message._id.toString() });
+  }
+
+  private async requireUser(userId: string): Promise<IUser> {
+    const user = Types.ObjectId.isValid(userId) ? await this.userRepository.findById(userId) : null;
+    if (!user) {
+      throw new ModerationError('User not found', 404);
+    }
+    return user;
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const expired = await this.moderationActionRepository.findExpired(new Date(), BATCH_SIZE);
+      for (const action of expired) {
+        await this.lift(action, 'Expired');
+      }
+    } catch (error) {
+      logger.error('Moderation worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+export function serializeModerationAction(action: IModerationAction) {
+  return {
+    id: action._id.toString(),
+    userId: action.userId,
+    action: action.action,
+    mode: action.mode,
+    reason: action.reason,
+    note: action.note,
+    reportId: action.reportId?.toString(),
+    issuedBy: action.issuedBy,
+    expiresAt: action.expiresAt,
+    active: !action.liftedAt,
+    liftedAt: action.liftedAt,
+    liftedBy: action.liftedBy,
+    liftReason: action.liftReason,
+    createdAt: action.createdAt,
+  };
+}
+
+export class ModerationError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ModerationError';
+  }
+}
+
+export const moderationService = new ModerationService();
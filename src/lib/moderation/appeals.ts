@@ -0,0 +1,252 @@
+import { Types } from 'mongoose';
+import { AppealRepository } from '../database/repositories/appeal';
+import { ModerationActionRepository } from '../database/repositories/moderation-action';
+import { UserRepository } from '../database/repositories/user';
+import { IAppeal, AppealStatus } from '../database/models/appeal';
+import { IUser } from '../database/models/user';
+import { DecideAppealInput } from '../database/schemas/moderation';
+import { environmentConfig } from '../config/environment';
+import { otpService } from '../auth/otp';
+import { smsService } from '../communication/sms';
+import { smtpService } from '../communication/smtp';
+import { CryptoUtils } from '../utils/crypto';
+import { t } from '../i18n';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { moderationService, ModerationError } from './actions';
+
+const APPEAL_TOKEN_TTL = 60 * 60; // seconds
+
+// Banned users can't sign in, so they prove they own the account with a
+// one-time code and get a short-lived token that only works here
+function appealTokenSecret(): string {
+  return `${environmentConfig.get().JWT_SECRET}:appeal`;
+}
+
+// Appeals against bans. A banned user gets one appeal per ban; admins work
+// through the queue with the user's moderation history at hand, and the
+// user is told the outcome by email or SMS. Approving lifts the ban.
+export class AppealService {
+  private appealRepository = new AppealRepository();
+  private moderationActionRepository = new ModerationActionRepository();
+  private userRepository = new UserRepository();
+
+  // Send a code to a banned account. Says nothing about whether the number
+  // is registered or banned.
+  async requestCode(phoneNumber: string): Promise<{ expiresAt?: Date; cooldownUntil?: Date }> {
+    const user = await this.userRepository.findByPhoneNumber(phoneNumber);
+    if (!user || !user.isBanned || user.mergedInto) {
+      return {};
+    }
+
+    const result = user.email
+      ? await otpService.sendEmailOTP(user.email, user.displayName, 'appeal', undefined, user.language)
+      : await otpService.sendSMSOTP(phoneNumber, 'appeal', undefined, user.language);
+    if (!result.success && result.cooldownUntil) {
+      return { cooldownUntil: result.cooldownUntil };
+    }
+    if (!result.success) {
+      throw new AppealError('Failed to send verification code', 500);
+    }
+    return { expiresAt: result.expiresAt };
+  }
+
+  // Exchange a code for an appeal token
+  async verifyCode(phoneNumber: string, code: string): Promise<{ token: string; expiresAt: Date; user: IUser }> {
+    const user = await this.userRepository.findByPhoneNumber(phoneNumber);
+    if (!user || !user.isBanned) {
+      throw new AppealError('Invalid or expired verification code', 400);
+    }
+
+    const identifier = user.email ? `email:${user.email}` : `sms:${phoneNumber}`;
+    const result = await otpService.verifyOTP(identifier, code, 'appeal');
+    if (!result.success) {
+      throw new AppealError(result.error || 'Invalid or expired verification code', 400);
+    }
+
+    const token = CryptoUtils.generateToken(
+      { sub: user._id.toString(), purpose: 'appeal' },
+      appealTokenSecret(),
+      APPEAL_TOKEN_TTL
+    );
+    return { token, expiresAt: new Date(Date.now() + APPEAL_TOKEN_TTL * 1000), user };
+  }
+
+  // The banned user an appeal token belongs to
+  async authenticate(authorization: string | null): Promise<IUser> {
+    const token = authorization?.startsWith('Bearer ') ? authorization.slice(7) : null;
+    const payload = token
+      ? CryptoUtils.verifyToken(token, appealTokenSecret()) as { sub?: string; purpose?: string } | null
+      : null;
+    if (!payload?.sub || payload.purpose !== 'appeal') {
+      throw new AppealError('Invalid or expired appeal token', 401);
+    }
+
+    const user = await this.userRepository.findById(payload.sub);
+    if (!user) {
+      throw new AppealError('Invalid or expired appeal token', 401);
+    }
+    return user;
+  }
+
+  // The ban in force and the appeal against it, if any
+  async getStatus(user: IUser) {
+    const action = user.isBanned ? await this.moderationActionRepository.findActive(user._id, 'ban') : null;
+    const appeal = user.isBanned ? await this.appealRepository.findForAction(user._id, action?._id) : null;
+
+    return {
+      banned: user.isBanned,
+      ban: user.isBanned ? { reason: user.banReason, expiresAt: user.banExpiresAt } : null,
+      appeal: appeal && serializeAppeal(appeal),
+      canAppeal: user.isBanned && !appeal,
+    };
+  }
+
+  async submit(user: IUser, text: string): Promise<IAppeal> {
+    if (!user.isBanned) {
+      throw new AppealError('Account is not suspended', 409);
+    }
+
+    const action = await this.moderationActionRepository.findActive(user._id, 'ban');
+    if (await this.appealRepository.findForAction(user._id, action?._id)) {
+      throw new AppealError('You have already appealed this suspension', 409);
+    }
+
+    let appeal: IAppeal;
+    try {
+      appeal = await this.appealRepository.create({
+        userId: user._id,
+        actionId: action?._id,
+        text,
+        status: 'pending',
+        history: [{ status: 'pending', at: new Date() }],
+      });
+    } catch (error: any) {
+      // Two submissions racing each other
+      if (error?.code === 11000) {
+        throw new AppealError('You have already appealed this suspension', 409);
+      }
+      throw error;
+    }
+
+    metricsCollector.incrementCounter('moderation_appeals_submitted', 1);
+    logger.info('Appeal submitted', { appealId: appeal._id.toString(), userId: user._id.toString() });
+    return appeal;
+  }
+
+  async list(status: AppealStatus, limit: number = 20, offset: number = 0) {
+    return await this.appealRepository.findByStatus(status, limit, offset);
+  }
+
+  // An appeal with everything a reviewer needs: the user, the ban appealed,
+  // the user's whole moderation history and their earlier appeals
+  async getDetail(appealId: string) {
+    const appeal = await this.getById(appealId);
+    const [user, action, history, appeals] = await Promise.all([
+      this.userRepository.findById(appeal.userId),
+      appeal.actionId ? this.moderationActionRepository.findById(appeal.actionId) : null,
+      this.moderationActionRepository.findByUser(appeal.userId),
+      this.appealRepository.findByUser(appeal.userId),
+    ]);
+
+    return {
+      appeal,
+      user,
+      action,
+      history,
+      previousAppeals: appeals.filter(other => !other._id.equals(appeal._id)),
+    };
+  }
+
+  async decide(appealId: string, input: DecideAppealInput, adminId: string): Promise<IAppeal> {
+    const appeal = await this.getById(appealId);
+    if (appeal.status !== 'pending') {
+      throw new AppealError('Appeal has already been decided', 409);
+    }
+
+    const status = input.decision === 'approve' ? 'approved' : 'denied';
+    const decided = await this.appealRepository.decide(appeal._id, status, adminId, input.note);
+    if (!decided) {
+      throw new AppealError('Appeal has already been decided', 409);
+    }
+
+    if (status === 'approved') {
+      await this.liftBan(decided, adminId);
+    }
+
+    metricsCollector.incrementCounter(`moderation_appeals_${status}`, 1);
+    logger.info('Appeal decided', { appealId, status, adminId });
+
+    this.notify(decided).catch(error => {
+      logger.error('Appeal decision notification failed', error, { appealId });
+    });
+    return decided;
+  }
+
+  private async liftBan(appeal: IAppeal, adminId: string): Promise<void> {
+    const reason = `Appeal ${appeal._id.toString()} approved`;
+    try {
+      if (appeal.actionId) {
+        await moderationService.liftAction(appeal.actionId, reason, adminId);
+      } else {
+        await moderationService.unban(appeal.userId.toString(), reason, adminId);
+      }
+    } catch (error) {
+      // Expired or lifted while the appeal waited; nothing left to undo
+      if (!(error instanceof ModerationError) || error.status !== 409) {
+        throw error;
+      }
+    }
+  }
+
+  // Tell the user the outcome; they can't sign in, so by email or SMS
+  private async notify(appeal: IAppeal): Promise<void> {
+    const user = await this.userRepository.findById(appeal.userId);
+    if (!user) return;
+
+    const approved = appeal.status === 'approved';
+    const title = t(user.language, approved ? 'appeal.approved.title' : 'appeal.denied.title');
+    const body = [
+      t(user.language, approved ? 'appeal.approved.body' : 'appeal.denied.body'),
+      appeal.decisionNote && t(user.language, 'appeal.note', { note: appeal.decisionNote }),
+    ].filter(Boolean).join('\n\n');
+
+    if (user.email) {
+      await smtpService.sendNotificationEmail(user, { title, body });
+    } else {
+      await smsService.sendNotificationSMS(user.phoneNumber, title, body, user.language);
+    }
+  }
+
+  private async getById(appealId: string): Promise<IAppeal> {
+    const appeal = Types.ObjectId.isValid(appealId) ? await this.appealRepository.findById(appealId) : null;
+    if (!appeal) {
+      throw new AppealError('Appeal not found', 404);
+    }
+    return appeal;
+  }
+}
+
+// Pass includeHistory for the admin view
+export function serializeAppeal(appeal: IAppeal, includeHistory: boolean = false) {
+  return {
+    id: appeal._id.toString(),
+    userId: appeal.userId,
+    actionId: appeal.actionId?.toString(),
+    text: appeal.text,
+    status: appeal.status,
+    decisionNote: appeal.decisionNote,
+    decidedAt: appeal.decidedAt,
+    ...(includeHistory && { decidedBy: appeal.decidedBy?.toString(), history: appeal.history }),
+    createdAt: appeal.createdAt,
+  };
+}
+
+export class AppealError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'AppealError';
+  }
+}
+
+export const appealService = new AppealService();
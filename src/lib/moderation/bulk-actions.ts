@@ -0,0 +1,281 @@
+import { Types } from 'mongoose';
+import { BulkActionRepository } from '../database/repositories/bulk-action';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { MessageRepository } from '../database/repositories/message';
+import { MediaRepository } from '../database/repositories/media';
+import { ReportRepository } from '../database/repositories/report';
+import { IBulkAction, BulkActionKind } from '../database/models/bulk-action';
+import { AuditTargetType } from '../database/models/audit-entry';
+import { moderationService, ModerationError } from './actions';
+import { storageReferenceService } from '../media/storage-references';
+import { legalHoldService } from '../compliance/legal-holds';
+import { jobQueue } from '../jobs';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+type ItemResult = 'succeeded' | 'skipped';
+
+// Bulk moderation: banning or restricting many users, deleting many
+// messages or files, closing many reports. Each request is queued on the
+// shared job queue ('moderation:bulk-action') and worked through one item
+// at a time; every item gets its own result, and every change made an
+// audit entry. Items already done are not redone when an interrupted bulk
+// action resumes.
+export class BulkActionService {
+  private bulkActionRepository = new BulkActionRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private messageRepository = new MessageRepository();
+  private mediaRepository = new MediaRepository();
+  private reportRepository = new ReportRepository();
+
+  async request(
+    kind: BulkActionKind,
+    targetIds: string[],
+    params: IBulkAction['params'],
+    adminId: string
+  ): Promise<IBulkAction> {
+    const bulkAction = await this.bulkActionRepository.create({
+      kind,
+      params,
+      items: targetIds.map(targetId => ({ targetId: new Types.ObjectId(targetId), status: 'pending' as const })),
+      progress: { total: targetIds.length, succeeded: 0, failed: 0, skipped: 0 },
+      requestedBy: new Types.ObjectId(adminId),
+    });
+
+    metricsCollector.incrementCounter('bulk_actions_requested', 1, { kind });
+    logger.info('Bulk action requested', {
+      bulkActionId: bulkAction._id.toString(),
+      kind,
+      items: targetIds.length,
+      adminId,
+    });
+
+    try {
+      await jobQueue.enqueue('moderation:bulk-action', { bulkActionId: bulkAction._id.toString() });
+    } catch (error) {
+      await this.bulkActionRepository.update(bulkAction._id, { status: 'failed', error: 'Bulk action failed', completedAt: new Date() });
+      throw error;
+    }
+    return bulkAction;
+  }
+
+  async getBulkAction(bulkActionId: string): Promise<IBulkAction> {
+    const bulkAction = Types.ObjectId.isValid(bulkActionId)
+      ? await this.bulkActionRepository.findById(bulkActionId)
+      : null;
+    if (!bulkAction) {
+      throw new BulkActionError('Bulk action not found', 404);
+    }
+    return bulkAction;
+  }
+
+  async list(limit: number = 20, offset: number = 0) {
+    return await this.bulkActionRepository.list(limit, offset);
+  }
+
+  // Work through a queued bulk action; called by the job queue. One that
+  // was interrupted picks up at its first pending item.
+  async process(bulkActionId: string): Promise<void> {
+    const bulkAction = await this.bulkActionRepository.start(bulkActionId);
+    if (!bulkAction) return; // already finished
+
+    await this.run(bulkAction);
+  }
+
+  // The job queue gave up on a bulk action whose workers kept dying
+  async abandon(bulkActionId: string): Promise<void> {
+    const bulkAction = await this.bulkActionRepository.fail(bulkActionId, 'Bulk action failed');
+    if (bulkAction) {
+      metricsCollector.incrementCounter('bulk_actions_failed', 1, { kind: bulkAction.kind });
+    }
+  }
+
+  private async run(bulkAction: IBulkAction): Promise<void> {
+    const bulkActionId = bulkAction._id.toString();
+
+    try {
+      for (const item of bulkAction.items) {
+        if (item.status !== 'pending') continue;
+
+        try {
+          const result = await this.apply(bulkAction, item.targetId);
+          await this.bulkActionRepository.recordItem(bulkAction._id, item.targetId, result);
+        } catch (error) {
+          if (!(error instanceof BulkActionError || error instanceof ModerationError)) {
+            logger.error('Bulk action item failed', error, { bulkActionId, targetId: item.targetId.toString() });
+          }
+          await this.bulkActionRepository.recordItem(
+            bulkAction._id,
+            item.targetId,
+            'failed',
+            error instanceof BulkActionError || error instanceof ModerationError ? error.message : 'Action failed'
+          );
+        }
+      }
+
+      const completed = await this.bulkActionRepository.update(bulkAction._id, {
+        status: 'completed',
+        completedAt: new Date(),
+      });
+      metricsCollector.incrementCounter('bulk_actions_completed', 1, { kind: bulkAction.kind });
+      logger.info('Bulk action completed', {
+        bulkActionId,
+        kind: bulkAction.kind,
+        ...completed?.progress,
+      });
+    } catch (error) {
+      logger.error('Bulk action failed', error, { bulkActionId });
+      await this.bulkActionRepository.update(bulkAction._id, {
+        status: 'failed',
+        error: 'Bulk action failed',
+        completedAt: new Date(),
+      });
+      metricsCollector.incrementCounter('bulk_actions_failed', 1, { kind: bulkAction.kind });
+    }
+  }
+
+  private async apply(bulkAction: IBulkAction, targetId: Types.ObjectId): Promise<ItemResult> {
+    const adminId = bulkAction.requestedBy.toString();
+    const { params } = bulkAction;
+
+    switch (bulkAction.kind) {
+      case 'ban_users':
+      case 'restrict_users': {
+        const input = {
+          reason: params.reason!,
+          note: params.note,
+          durationHours: params.durationHours,
+          reportId: params.reportId?.toString(),
+        };
+        const action = bulkAction.kind === 'ban_users'
+          ? await moderationService.ban(targetId.toString(), input, adminId)
+          : await moderationService.restrict(targetId.toString(), { ...input, mode: params.mode || 'shadow' }, adminId);
+
+        await this.audit(bulkAction, bulkAction.kind === 'ban_users' ? 'user.ban' : 'user.restrict', 'user', targetId, {
+          moderationActionId: action._id.toString(),
+          expiresAt: action.expiresAt,
+        });
+        return 'succeeded';
+      }
+
+      case 'delete_messages': {
+        const message = await this.messageRepository.findById(targetId);
+        if (!message) {
+          throw new BulkActionError('Message not found', 404);
+        }
+        if (message.isDeleted) return 'skipped';
+
+        await legalHoldService.preserveMessage(message, { kind: 'admin', id: adminId });
+        await this.messageRepository.delete(targetId);
+        const { socketManager } = await import('../realtime/socket');
+        socketManager.emitToChat(message.chatId.toString(), 'message:deleted', {
+          messageId: targetId.toString(),
+          deletedForEveryone: true,
+        });
+
+        await this.audit(bulkAction, 'message.delete', 'message', targetId, {
+          chatId: message.chatId.toString(),
+          senderId: (message.senderId as any)?._id?.toString() ?? message.senderId?.toString(),
+        });
+        return 'succeeded';
+      }
+
+      case 'delete_files': {
+        const media = await this.mediaRepository.findById(targetId);
+        if (!media) {
+          throw new BulkActionError('File not found', 404);
+        }
+
+        if (await legalHoldService.isMediaHeld(media)) return 'skipped';
+        if (!await this.mediaRepository.delete(targetId)) return 'skipped';
+        // The stored file goes once no other upload shares it
+        await storageReferenceService.release(media);
+
+        await this.audit(bulkAction, 'media.delete', 'media', targetId, {
+          uploadedBy: media.uploadedBy.toString(),
+          originalName: media.originalName,
+          size: media.size,
+        });
+        return 'succeeded';
+      }
+
+      case 'resolve_reports': {
+        const report = await this.reportRepository.findById(targetId);
+        if (!report) {
+          throw new BulkActionError('Report not found', 404);
+        }
+
+        const status = params.reportStatus || 'resolved';
+        if (!await this.reportRepository.resolve(targetId, status, params.resolution, adminId)) return 'skipped';
+
+        await this.audit(bulkAction, `report.${status === 'resolved' ? 'resolve' : 'dismiss'}`, 'report', targetId, {
+          resolution: params.resolution,
+        });
+        return 'succeeded';
+      }
+    }
+  }
+
+  private async audit(
+    bulkAction: IBulkAction,
+    action: string,
+    targetType: AuditTargetType,
+    targetId: Types.ObjectId,
+    details: Record<string, unknown>
+  ): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: bulkAction.requestedBy,
+      action,
+      targetType,
+      targetId,
+      bulkActionId: bulkAction._id,
+      details,
+    });
+  }
+}
+
+export function serializeBulkAction(bulkAction: IBulkAction, includeItems: boolean = false) {
+  return {
+    id: bulkAction._id.toString(),
+    kind: bulkAction.kind,
+    status: bulkAction.status,
+    params: {
+      reason: bulkAction.params?.reason,
+      note: bulkAction.params?.note,
+      durationHours: bulkAction.params?.durationHours,
+      mode: bulkAction.params?.mode,
+      reportId: bulkAction.params?.reportId?.toString(),
+      resolution: bulkAction.params?.resolution,
+      reportStatus: bulkAction.params?.reportStatus,
+    },
+    progress: {
+      total: bulkAction.progress.total,
+      succeeded: bulkAction.progress.succeeded,
+      failed: bulkAction.progress.failed,
+      skipped: bulkAction.progress.skipped,
+      pending: bulkAction.progress.total - bulkAction.progress.succeeded - bulkAction.progress.failed - bulkAction.progress.skipped,
+    },
+    ...(includeItems && {
+      items: bulkAction.items.map(item => ({
+        targetId: item.targetId.toString(),
+        status: item.status,
+        error: item.error,
+        processedAt: item.processedAt,
+      })),
+    }),
+    requestedBy: bulkAction.requestedBy.toString(),
+    error: bulkAction.error,
+    startedAt: bulkAction.startedAt,
+    completedAt: bulkAction.completedAt,
+    createdAt: bulkAction.createdAt,
+  };
+}
+
+export class BulkActionError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'BulkActionError';
+  }
+}
+
+export const bulkActionService = new BulkActionService();
@@ -0,0 +1,319 @@
+import { Types } from 'mongoose';
+import { AdminNoteRepository } from '../database/repositories/admin-note';
+import { SupportCaseRepository } from '../database/repositories/support-case';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { ModerationActionRepository } from '../database/repositories/moderation-action';
+import { ReportRepository } from '../database/repositories/report';
+import { UserRepository } from '../database/repositories/user';
+import { SecurityEventRepository } from '../database/repositories/security-event';
+import { IAdminNote } from '../database/models/admin-note';
+import { ISupportCase } from '../database/models/support-case';
+import { IUser } from '../database/models/user';
+import {
+  AddAdminNoteInput,
+  UpdateAdminNoteInput,
+  OpenSupportCaseInput,
+  UpdateSupportCaseInput,
+  AddCaseCommunicationInput,
+} from '../database/schemas/moderation';
+import { adminUserRecord, adminReportRecord, adminSecurityEventRecord } from '../admin/lists';
+import { PiiPolicy, redactUserRecord } from '../admin/redaction';
+import { serializeModerationAction, getActiveRestriction } from './actions';
+import { logger } from '../monitoring/logging';
+
+const id = (value: any): string | undefined => value?._id?.toString() ?? value?.toString();
+
+// Staff-side records about a user, for support continuity: internal notes,
+// tags, and cases tying together the reports about them, the moderation
+// actions taken and what was said to them. None of it is ever visible to
+// the user.
+export class CaseService {
+  private adminNoteRepository = new AdminNoteRepository();
+  private supportCaseRepository = new SupportCaseRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private moderationActionRepository = new ModerationActionRepository();
+  private reportRepository = new ReportRepository();
+  private userRepository = new UserRepository();
+  private securityEventRepository = new SecurityEventRepository();
+
+  // Everything staff know about a user, for the admin user page
+  async getUserDetail(userId: string, policy: PiiPolicy) {
+    const user = await this.requireUser(userId);
+
+    const [tags, notes, cases, reports, actions, auditEntries, securityEvents] = await Promise.all([
+      this.userRepository.getStaffTags(user._id),
+      this.adminNoteRepository.findByUser(user._id),
+      this.supportCaseRepository.findByUser(user._id),
+      this.reportRepository.findAboutUser(user._id),
+      this.moderationActionRepository.findByUser(user._id),
+      this.auditEntryRepository.findByTarget('user', user._id),
+      this.securityEventRepository.findByUser(user._id, 20),
+    ]);
+
+    return {
+      user: redactUserRecord(adminUserRecord(user), policy),
+      restriction: getActiveRestriction(user),
+      // Signed-in devices and where they last signed in from; no push tokens
+      devices: (user.devices || []).map(device => ({
+        deviceId: device.deviceId,
+        platform: device.platform,
+        appVersion: device.appVersion,
+        deviceModel: device.deviceModel,
+        lastActive: device.lastActive,
+        location: device.location && {
+          ip: device.location.ip,
+          country: device.location.country,
+          city: device.location.city,
+          asn: device.location.asn,
+          asOrganization: device.location.asOrganization,
+          at: device.location.at,
+        },
+      })),
+      tags: tags || [],
+      notes: notes.map(serializeAdminNote),
+      cases: cases.map(supportCase => serializeSupportCase(supportCase)),
+      reports: reports.map(adminReportRecord),
+      moderationActions: actions.map(serializeModerationAction),
+      auditEntries: auditEntries.map(entry => ({
+        id: entry._id.toString(),
+        adminId: entry.adminId.toString(),
+        action: entry.action,
+        bulkActionId: entry.bulkActionId?.toString(),
+        details: entry.details,
+        createdAt: entry.createdAt,
+      })),
+      securityEvents: securityEvents.map(adminSecurityEventRecord),
+    };
+  }
+
+  async addNote(userId: string, input: AddAdminNoteInput, adminId: string): Promise<IAdminNote> {
+    const user = await this.requireUser(userId);
+    if (input.caseId) {
+      await this.requireCase(input.caseId, user._id);
+    }
+
+    const note = await this.adminNoteRepository.create({
+      userId: user._id,
+      authorId: new Types.ObjectId(adminId),
+      text: input.text,
+      pinned: input.pinned,
+      ...(input.caseId && { caseId: new Types.ObjectId(input.caseId) }),
+    });
+
+    logger.info('Admin note added', { noteId: note._id.toString(), userId, adminId });
+    return note;
+  }
+
+  // Anyone can pin or unpin a note; only its author can change the text
+  async updateNote(userId: string, noteId: string, input: UpdateAdminNoteInput, adminId: string): Promise<IAdminNote> {
+    const note = await this.requireNote(userId, noteId);
+    if (input.text !== undefined && note.authorId.toString() !== adminId) {
+      throw new CaseError('Only the author can edit a note', 403);
+    }
+
+    const updated = await this.adminNoteRepository.update(note._id, {
+      ...(input.text !== undefined && { text: input.text }),
+      ...(input.pinned !== undefined && { pinned: input.pinned }),
+    });
+    return updated!;
+  }
+
+  async deleteNote(userId: string, noteId: string, adminId: string): Promise<void> {
+    const note = await this.requireNote(userId, noteId);
+    if (note.authorId.toString() !== adminId) {
+      throw new CaseError('Only the author can delete a note', 403);
+    }
+
+    await this.adminNoteRepository.delete(note._id);
+    logger.info('Admin note deleted', { noteId, userId, adminId });
+  }
+
+  async setTags(userId: string, tags: string[], adminId: string): Promise<string[]> {
+    const user = await this.requireUser(userId);
+    const previous = await this.userRepository.getStaffTags(user._id) || [];
+
+    await this.userRepository.setStaffTags(user._id, tags);
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action: 'user.tags',
+      targetType: 'user',
+      targetId: user._id,
+      details: {
+        added: tags.filter(tag => !previous.includes(tag)),
+        removed: previous.filter(tag => !tags.includes(tag)),
+      },
+    });
+
+    return tags;
+  }
+
+  async openCase(userId: string, input: OpenSupportCaseInput, adminId: string): Promise<ISupportCase> {
+    const user = await this.requireUser(userId);
+    await this.checkLinks(user._id, input.reportIds, input.moderationActionIds);
+
+    const supportCase = await this.supportCaseRepository.create({
+      userId: user._id,
+      title: input.title,
+      openedBy: new Types.ObjectId(adminId),
+      assignedTo: new Types.ObjectId(input.assignedTo || adminId),
+      reportIds: input.reportIds.map(reportId => new Types.ObjectId(reportId)),
+      moderationActionIds: input.moderationActionIds.map(actionId => new Types.ObjectId(actionId)),
+    });
+
+    if (input.note) {
+      await this.adminNoteRepository.create({
+        userId: user._id,
+        authorId: new Types.ObjectId(adminId),
+        text: input.note,
+        caseId: supportCase._id,
+      });
+    }
+
+    logger.info('Support case opened', { caseId: supportCase._id.toString(), userId, adminId });
+    return supportCase;
+  }
+
+  async getCase(caseId: string): Promise<{ supportCase: ISupportCase; notes: IAdminNote[] }> {
+    const supportCase = await this.requireCase(caseId);
+    const notes = await this.adminNoteRepository.findByCase(supportCase._id);
+    return { supportCase, notes };
+  }
+
+  async updateCase(caseId: string, input: UpdateSupportCaseInput, adminId: string): Promise<ISupportCase> {
+    const supportCase = await this.requireCase(caseId);
+
+    if (input.reportIds || input.moderationActionIds) {
+      await this.checkLinks(supportCase.userId, input.reportIds || [], input.moderationActionIds || []);
+      await this.supportCaseRepository.link(
+        supportCase._id,
+        (input.reportIds || []).map(reportId => new Types.ObjectId(reportId)),
+        (input.moderationActionIds || []).map(actionId => new Types.ObjectId(actionId))
+      );
+    }
+
+    const closing = input.status === 'closed' && supportCase.status !== 'closed';
+    const reopening = !!input.status && input.status !== 'closed' && supportCase.status === 'closed';
+    const unset = {
+      ...(input.assignedTo === null && { assignedTo: 1 }),
+      ...(reopening && { closedAt: 1 }),
+    };
+    const updated = await this.supportCaseRepository.update(supportCase._id, {
+      ...(input.title && { title: input.title }),
+      ...(input.status && { status: input.status }),
+      ...(input.assignedTo && { assignedTo: new Types.ObjectId(input.assignedTo) }),
+      ...(closing && { closedAt: new Date() }),
+      ...(Object.keys(unset).length > 0 && { $unset: unset }),
+    });
+
+    if (input.status && input.status !== supportCase.status) {
+      logger.info('Support case status changed', { caseId, from: supportCase.status, to: input.status, adminId });
+    }
+    return updated!;
+  }
+
+  async addCommunication(caseId: string, input: AddCaseCommunicationInput, adminId: string): Promise<ISupportCase> {
+    const supportCase = await this.requireCase(caseId);
+
+    const updated = await this.supportCaseRepository.addCommunication(supportCase._id, {
+      channel: input.channel,
+      direction: input.direction,
+      summary: input.summary,
+      adminId: new Types.ObjectId(adminId),
+      at: input.at ? new Date(input.at) : new Date(),
+    });
+    return updated!;
+  }
+
+  // Reports and moderation actions linked to a case have to be about its user
+  private async checkLinks(userId: Types.ObjectId, reportIds: string[], actionIds: string[]): Promise<void> {
+    for (const reportId of reportIds) {
+      const report = await this.reportRepository.findById(reportId);
+      if (!report || !report.reportedUserId?.equals(userId)) {
+        throw new CaseError(`Report ${reportId} is not about this user`, 400);
+      }
+    }
+    for (const actionId of actionIds) {
+      const action = await this.moderationActionRepository.findById(actionId);
+      if (!action || !action.userId.equals(userId)) {
+        throw new CaseError(`Moderation action ${actionId} was not taken against this user`, 400);
+      }
+    }
+  }
+
+  private async requireUser(userId: string): Promise<IUser> {
+    const user = Types.ObjectId.isValid(userId) ? await this.userRepository.findById(userId) : null;
+    if (!user) {
+      throw new CaseError('User not found', 404);
+    }
+    return user;
+  }
+
+  private async requireNote(userId: string, noteId: string): Promise<IAdminNote> {
+    const note = Types.ObjectId.isValid(noteId) ? await this.adminNoteRepository.findById(noteId) : null;
+    if (!note || note.userId.toString() !== userId) {
+      throw new CaseError('Note not found', 404);
+    }
+    return note;
+  }
+
+  private async requireCase(caseId: string, userId?: Types.ObjectId): Promise<ISupportCase> {
+    const supportCase = Types.ObjectId.isValid(caseId) ? await this.supportCaseRepository.findById(caseId) : null;
+    if (!supportCase || (userId && !supportCase.userId.equals(userId))) {
+      throw new CaseError('Case not found', 404);
+    }
+    return supportCase;
+  }
+}
+
+export function serializeAdminNote(note: IAdminNote) {
+  const author = note.authorId as any;
+  return {
+    id: note._id.toString(),
+    userId: note.userId.toString(),
+    author: {
+      id: id(author),
+      displayName: author?.displayName,
+    },
+    text: note.text,
+    caseId: note.caseId?.toString(),
+    pinned: note.pinned,
+    createdAt: note.createdAt,
+    updatedAt: note.updatedAt,
+  };
+}
+
+export function serializeSupportCase(supportCase: ISupportCase, includeCommunications: boolean = false) {
+  return {
+    id: supportCase._id.toString(),
+    userId: supportCase.userId.toString(),
+    title: supportCase.title,
+    status: supportCase.status,
+    openedBy: supportCase.openedBy.toString(),
+    assignedTo: supportCase.assignedTo?.toString(),
+    reportIds: supportCase.reportIds.map(reportId => reportId.toString()),
+    moderationActionIds: supportCase.moderationActionIds.map(actionId => actionId.toString()),
+    communicationCount: supportCase.communications.length,
+    ...(includeCommunications && {
+      communications: supportCase.communications.map(communication => ({
+        id: communication._id.toString(),
+        channel: communication.channel,
+        direction: communication.direction,
+        summary: communication.summary,
+        adminId: communication.adminId.toString(),
+        at: communication.at,
+      })),
+    }),
+    closedAt: supportCase.closedAt,
+    createdAt: supportCase.createdAt,
+    updatedAt: supportCase.updatedAt,
+  };
+}
+
+export class CaseError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'CaseError';
+  }
+}
+
+export const caseService = new CaseService();
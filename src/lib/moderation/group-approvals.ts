@@ -0,0 +1,114 @@
+import { Types } from 'mongoose';
+import { MessageRepository } from '../database/repositories/message';
+import { ChatRepository } from '../database/repositories/chat';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { ReviewGroupMessageInput } from '../database/schemas/group';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { jobQueue } from '../jobs';
+import { moderationService } from './actions';
+
+// Moderated groups, for large announcement-style communities. With
+// messageApproval on, messages from members who aren't admins are held like
+// those of users restricted in review mode: only the author sees them until
+// one of the group's admins approves them. Approved messages are delivered as
+// if just sent; the author of a rejected one is told, with the reason if given.
+export class GroupApprovalService {
+  private messageRepository = new MessageRepository();
+  private chatRepository = new ChatRepository();
+
+  // Whether a message from this sender waits for the group's admins
+  isHeld(chat: IChat, senderId: string): boolean {
+    if (chat.type !== 'group' || !chat.groupInfo?.settings?.messageApproval) return false;
+    return !chat.groupInfo.admins.some(admin => admin.toString() === senderId);
+  }
+
+  // Let the group's admins know a message is waiting for them
+  async notifyPending(chat: IChat, message: IMessage): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const populatedMessage = await this.messageRepository.findById(message._id);
+
+    for (const admin of chat.groupInfo?.admins || []) {
+      socketManager.emitToUser(admin.toString(), 'group:message:pending', {
+        groupId: chat._id.toString(),
+        message: populatedMessage,
+      });
+    }
+    metricsCollector.incrementCounter('group_messages_held', 1);
+  }
+
+  async listPending(groupId: string, limit: number = 50, offset: number = 0) {
+    return await this.messageRepository.getGroupReviewQueue(groupId, limit, offset);
+  }
+
+  // Approve a held message, delivering it to the group, or reject it so it
+  // stays visible to its author only
+  async review(groupId: string, messageId: string, adminId: string, input: ReviewGroupMessageInput): Promise<IMessage> {
+    const { decision, reason } = input;
+    const message = Types.ObjectId.isValid(messageId)
+      ? await this.messageRepository.reviewInGroup(
+          messageId,
+          groupId,
+          decision === 'approve' ? 'approved' : 'rejected',
+          adminId,
+          decision === 'reject' ? reason : undefined
+        )
+      : null;
+    if (!message) {
+      throw new GroupApprovalError('Message not found or already reviewed', 404);
+    }
+
+    metricsCollector.incrementCounter(`group_messages_${decision === 'approve' ? 'approved' : 'rejected'}`, 1);
+    logger.info('Group message reviewed', { groupId, messageId, decision, adminId });
+
+    if (decision === 'approve') {
+      await moderationService.deliver(message);
+    }
+    await this.notifyReviewed(groupId, message, decision, adminId, decision === 'reject' ? reason : undefined);
+
+    return message;
+  }
+
+  // Tell the author and the other admins; a rejected author also gets a push
+  private async notifyReviewed(
+    groupId: string,
+    message: IMessage,
+    decision: ReviewGroupMessageInput['decision'],
+    adminId: string,
+    reason?: string
+  ): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const chat = await this.chatRepository.findById(groupId);
+    const authorId = message.senderId.toString();
+    const payload = { groupId, messageId: message._id.toString(), decision, reason, reviewedBy: adminId };
+
+    const recipients = new Set([authorId, ...(chat?.groupInfo?.admins || []).map(admin => admin.toString())]);
+    for (const userId of recipients) {
+      socketManager.emitToUser(userId, 'group:message:reviewed', payload);
+    }
+
+    if (decision !== 'reject') return;
+
+    try {
+      await jobQueue.enqueue('push:group-message-rejected', {
+        userId: authorId,
+        groupName: chat?.groupInfo?.name || '',
+        groupId,
+        messageId: message._id.toString(),
+        reason,
+      });
+    } catch (error) {
+      logger.error('Failed to notify author of rejected group message', error, { messageId: message._id.toString() });
+    }
+  }
+}
+
+export class GroupApprovalError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'GroupApprovalError';
+  }
+}
+
+export const groupApprovalService = new GroupApprovalService();
@@ -0,0 +1,95 @@
+import { Types } from 'mongoose';
+import { ReportRepository } from '../database/repositories/report';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { IReport } from '../database/models/report';
+import { SubmitReportInput } from '../database/schemas/moderation';
+import { automationService } from '../integrations/automation';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Reports filed by users. A report names a user, a message or a chat the
+// reporter can see; it lands in the admin report queue and runs the
+// report_filed automation rules.
+export class ReportService {
+  private reportRepository = new ReportRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+
+  async submit(reporterId: string, input: SubmitReportInput): Promise<IReport> {
+    let reportedUserId = input.userId ? new Types.ObjectId(input.userId) : undefined;
+    let reportedChatId = input.chatId ? new Types.ObjectId(input.chatId) : undefined;
+    let reportedMessageId: Types.ObjectId | undefined;
+
+    // A reported message stands for its sender and chat too
+    if (input.messageId) {
+      const message = await this.messageRepository.findById(input.messageId);
+      if (!message || !(await this.chatRepository.isParticipant(message.chatId, reporterId))) {
+        throw new ReportError('Message not found', 404);
+      }
+      reportedMessageId = message._id;
+      reportedChatId = message.chatId;
+      reportedUserId = reportedUserId ?? new Types.ObjectId(((message.senderId as any)._id || message.senderId).toString());
+    } else if (reportedChatId && !(await this.chatRepository.isParticipant(reportedChatId, reporterId))) {
+      throw new ReportError('Chat not found', 404);
+    }
+
+    if (reportedUserId) {
+      if (reportedUserId.equals(reporterId)) {
+        throw new ReportError('You cannot report yourself', 400);
+      }
+      if (!(await this.userRepository.findById(reportedUserId))) {
+        throw new ReportError('User not found', 404);
+      }
+    }
+
+    const target = { reportedUserId, reportedMessageId, reportedChatId };
+    if (await this.reportRepository.findOpenDuplicate(reporterId, target)) {
+      throw new ReportError('You have already reported this', 409);
+    }
+
+    const report = await this.reportRepository.create({
+      reporterId: new Types.ObjectId(reporterId),
+      ...target,
+      type: input.type,
+      reason: input.reason,
+      description: input.description,
+      evidence: reportedMessageId ? [{ type: 'message' }] : [],
+    });
+
+    metricsCollector.incrementCounter('reports_filed', 1, { type: input.type });
+    logger.info('Report filed', { reportId: report._id.toString(), reporterId, type: input.type });
+
+    automationService.reportFiled(report).catch(error => {
+      logger.error('Error running automation rules for a report', error, { reportId: report._id.toString() });
+    });
+
+    return report;
+  }
+}
+
+// What the reporter sees of their report
+export function serializeReport(report: IReport) {
+  return {
+    id: report._id.toString(),
+    type: report.type,
+    reason: report.reason,
+    description: report.description,
+    userId: report.reportedUserId?.toString(),
+    messageId: report.reportedMessageId?.toString(),
+    chatId: report.reportedChatId?.toString(),
+    status: report.status,
+    createdAt: report.createdAt,
+  };
+}
+
+export class ReportError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ReportError';
+  }
+}
+
+export const reportService = new ReportService();
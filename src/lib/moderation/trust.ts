@@ -0,0 +1,212 @@
+import { redisConfig } from '../config/redis';
+import { adminConfigService } from '../config/admin-config';
+import { UserRepository } from '../database/repositories/user';
+import { MessageRepository } from '../database/repositories/message';
+import { IModerationConfig } from '../database/models/admin-config';
+import { IChat } from '../database/models/chat';
+import { IUser } from '../database/models/user';
+import { getActiveRestriction } from './actions';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const CACHE_TTL = 5 * 60 * 1000; // how quickly accrued trust is noticed
+const DAY = 24 * 60 * 60 * 1000;
+const LINK_PATTERN = /(?:\bhttps?:\/\/|\bwww\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|co|me|ly|app|xyz|info|biz|ru|cn|link|click|top)\b(?:\/\S*)?/i;
+
+export const DEFAULT_MODERATION_CONFIG: Required<IModerationConfig> = {
+  newAccountLimits: true,
+  trustedAccountAgeDays: 7,
+  trustedMinReputation: 3,
+  maxGroupsPerDay: 3,
+  maxStrangersPerDay: 20,
+  allowLinksToStrangers: false,
+};
+
+export function resolveModerationConfig(config: Partial<IModerationConfig> = {}): Required<IModerationConfig> {
+  const resolved = { ...DEFAULT_MODERATION_CONFIG };
+  (Object.keys(resolved) as (keyof IModerationConfig)[]).forEach(key => {
+    if (config[key] !== undefined && config[key] !== null) {
+      (resolved as any)[key] = config[key];
+    }
+  });
+  return resolved;
+}
+
+export function containsLink(content: string, metadata?: { links?: unknown[] }): boolean {
+  return !!metadata?.links?.length || LINK_PATTERN.test(content);
+}
+
+export interface TrustStatus {
+  trusted: boolean;
+  trustedAt?: Date;
+  accountAgeDays: number;
+  reputation: number;
+  // Thresholds for earning trust
+  requirements: { accountAgeDays: number; reputation: number };
+}
+
+// Limits for accounts that haven't earned trust yet: a cap on new groups per
+// day, on how many strangers (people who haven't added them as a contact or
+// written to them) they can message per day, and no links to strangers.
+// Trust comes with account age plus reputation and, once earned, sticks;
+// restricted accounts don't earn it.
+export class TrustService {
+  private userRepository = new UserRepository();
+  private messageRepository = new MessageRepository();
+  private redis = redisConfig.getClient();
+  private memoryCounters = new Map<string, { members: Set<string>; count: number; resetAt: number }>();
+  private statuses = new Map<string, { status: TrustStatus; loadedAt: number }>();
+
+  async getConfig(): Promise<Required<IModerationConfig>> {
+    return resolveModerationConfig((await adminConfigService.get()).moderation);
+  }
+
+  // Evaluate, and record trust the moment it is earned
+  async getStatus(userId: string, config?: Required<IModerationConfig>): Promise<TrustStatus> {
+    const cached = this.statuses.get(userId);
+    if (cached && Date.now() - cached.loadedAt < CACHE_TTL) {
+      return cached.status;
+    }
+
+    const user = await this.userRepository.findById(userId);
+    if (!user) {
+      throw new TrustLimitError('User not found', 404);
+    }
+
+    const status = await this.evaluate(user, config || await this.getConfig());
+    this.statuses.set(userId, { status, loadedAt: Date.now() });
+    if (this.statuses.size > 10000) {
+      this.statuses.forEach((value, key) => {
+        if (Date.now() - value.loadedAt >= CACHE_TTL) this.statuses.delete(key);
+      });
+    }
+    return status;
+  }
+
+  // Refuse a message that breaks a new-account limit. Only direct chats are
+  // limited; group members have already accepted each other.
+  async checkMessage(
+    senderId: string,
+    chat: IChat,
+    content: string,
+    metadata?: { links?: unknown[] }
+  ): Promise<void> {
+    const config = await this.getConfig();
+    if (!config.newAccountLimits || chat.type !== 'direct') return;
+    if ((await this.getStatus(senderId, config)).trusted) return;
+
+    const recipientId = chat.participants.map((p: any) => (p._id || p).toString()).find(id => id !== senderId);
+    if (!recipientId || !(await this.isStranger(senderId, recipientId, chat))) return;
+
+    if (!config.allowLinksToStrangers && containsLink(content, metadata)) {
+      metricsCollector.incrementCounter('trust_limit_links', 1);
+      throw new TrustLimitError('New accounts can\'t send links to people who haven\'t added them as a contact', 403);
+    }
+
+    if (!(await this.admitToday(`trust:strangers:${senderId}`, recipientId, config.maxStrangersPerDay))) {
+      metricsCollector.incrementCounter('trust_limit_strangers', 1);
+      throw new TrustLimitError(
+        `New accounts can message up to ${config.maxStrangersPerDay} new people a day; try again tomorrow`,
+        429
+      );
+    }
+  }
+
+  // Refuse to create another group past the daily cap; counts the attempt
+  async checkGroupCreation(userId: string): Promise<void> {
+    const config = await this.getConfig();
+    if (!config.newAccountLimits) return;
+    if ((await this.getStatus(userId, config)).trusted) return;
+
+    if (!(await this.admitToday(`trust:groups:${userId}`, null, config.maxGroupsPerDay))) {
+      metricsCollector.incrementCounter('trust_limit_groups', 1);
+      throw new TrustLimitError(
+        `New accounts can create up to ${config.maxGroupsPerDay} groups a day; try again tomorrow`,
+        429
+      );
+    }
+  }
+
+  private async evaluate(user: IUser, config: Required<IModerationConfig>): Promise<TrustStatus> {
+    const accountAgeDays = Math.floor((Date.now() - user.createdAt.getTime()) / DAY);
+    const { savedBy, blockedBy } = await this.userRepository.getReputation(user._id);
+    const reputation = savedBy - blockedBy;
+    const requirements = { accountAgeDays: config.trustedAccountAgeDays, reputation: config.trustedMinReputation };
+
+    if (user.trustedAt) {
+      return { trusted: true, trustedAt: user.trustedAt, accountAgeDays, reputation, requirements };
+    }
+
+    const earned = accountAgeDays >= config.trustedAccountAgeDays
+      && reputation >= config.trustedMinReputation
+      && !getActiveRestriction(user);
+    if (!earned) {
+      return { trusted: false, accountAgeDays, reputation, requirements };
+    }
+
+    if (await this.userRepository.markTrusted(user._id)) {
+      metricsCollector.incrementCounter('trust_earned', 1);
+      logger.info('Account earned trust', { userId: user._id.toString(), accountAgeDays, reputation });
+    }
+    return { trusted: true, trustedAt: new Date(), accountAgeDays, reputation, requirements };
+  }
+
+  // The recipient hasn't saved the sender as a contact or written in the
+  // chat. Only the recipient's side counts; anyone can add contacts.
+  private async isStranger(senderId: string, recipientId: string, chat: IChat): Promise<boolean> {
+    const recipient = await this.userRepository.findById(recipientId);
+    if (!recipient || recipient.contacts.some(contact => contact.toString() === senderId)) {
+      return false;
+    }
+
+    return !(await this.messageRepository.hasMessageFrom(chat._id, recipientId));
+  }
+
+  // Admit one more use of a daily allowance. With a member, counts distinct
+  // members and always admits ones already counted today.
+  private async admitToday(prefix: string, member: string | null, max: number): Promise<boolean> {
+    const day = new Date().toISOString().slice(0, 10);
+    const key = `${prefix}:${day}`;
+    const ttlSeconds = 2 * 24 * 60 * 60;
+
+    if (this.redis) {
+      if (member) {
+        if (await this.redis.sismember(key, member)) return true;
+        if (await this.redis.scard(key) >= max) return false;
+        await this.redis.multi().sadd(key, member).expire(key, ttlSeconds).exec();
+        return true;
+      }
+
+      const results = await this.redis.multi().incr(key).expire(key, ttlSeconds).exec();
+      return Number(results?.[0]?.[1] ?? 0) <= max;
+    }
+
+    let entry = this.memoryCounters.get(key);
+    if (!entry || entry.resetAt <= Date.now()) {
+      entry = { members: new Set(), count: 0, resetAt: Date.now() + ttlSeconds * 1000 };
+      this.memoryCounters.set(key, entry);
+    }
+    if (this.memoryCounters.size > 10000) {
+      this.memoryCounters.forEach((value, counterKey) => {
+        if (value.resetAt <= Date.now()) this.memoryCounters.delete(counterKey);
+      });
+    }
+
+    if (member) {
+      if (entry.members.has(member)) return true;
+      if (entry.members.size >= max) return false;
+      entry.members.add(member);
+      return true;
+    }
+    return ++entry.count <= max;
+  }
+}
+
+export class TrustLimitError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'TrustLimitError';
+  }
+}
+
+export const trustService = new TrustService();
@@ -0,0 +1,59 @@
+import { Types } from 'mongoose';
+import { UserActivityRepository } from '../database/repositories/user-activity';
+import { analyticsService } from './analytics';
+import { logger } from './logging';
+
+const REFRESH_INTERVAL = 30 * 60 * 1000; // rewrite lastSeenAt at most every 30 minutes
+
+// Events that don't mean the user actually used the app
+const PASSIVE_EVENTS = new Set(['api_request', 'error_occurred', 'message_delivered']);
+
+export function startOfUtcDay(date: Date): Date {
+  return new Date(Date.UTC(date.getUTCFullYear(), date.getUTCMonth(), date.getUTCDate()));
+}
+
+// Records which days each user was active, for active user counts and
+// retention cohorts. Fed by socket connections, sent messages and analytics
+// events; writes are throttled per user so busy users cost one upsert per
+// half hour.
+export class ActivityTracker {
+  private userActivityRepository = new UserActivityRepository();
+  private lastWrites = new Map<string, number>();
+  private currentDay = startOfUtcDay(new Date()).getTime();
+  private started = false;
+
+  // Follow analytics events that belong to a user
+  start(): void {
+    if (this.started) return;
+    this.started = true;
+
+    analyticsService.on('event', (event: { type: string; userId?: string }) => {
+      if (event.userId && !PASSIVE_EVENTS.has(event.type)) {
+        this.record(event.userId);
+      }
+    });
+  }
+
+  record(userId: string | Types.ObjectId): void {
+    const id = userId.toString();
+    if (!Types.ObjectId.isValid(id)) return;
+
+    const now = new Date();
+    const day = startOfUtcDay(now);
+    if (day.getTime() !== this.currentDay) {
+      this.currentDay = day.getTime();
+      this.lastWrites.clear();
+    }
+
+    const lastWrite = this.lastWrites.get(id);
+    if (lastWrite && now.getTime() - lastWrite < REFRESH_INTERVAL) return;
+    this.lastWrites.set(id, now.getTime());
+
+    this.userActivityRepository.recordActivity(id, day, now).catch(error => {
+      this.lastWrites.delete(id);
+      logger.error('Failed to record user activity', error, { userId: id });
+    });
+  }
+}
+
+export const activityTracker = new ActivityTracker();
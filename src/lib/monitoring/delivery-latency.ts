@@ -0,0 +1,200 @@
+import { EventEmitter } from 'events';
+import { metricsCollector } from './metrics';
+import { logger } from './logging';
+
+export interface DeliverySegment {
+  region: string;
+  platform: string;
+}
+
+export interface LatencyPercentiles {
+  count: number;
+  p50: number;
+  p95: number;
+  p99: number;
+}
+
+export interface SegmentLatency extends DeliverySegment {
+  ingressToDispatch: LatencyPercentiles;
+  endToEnd: LatencyPercentiles;
+}
+
+export interface DeliverySLO {
+  p95Ms: number;
+  p99Ms: number;
+  minSamples: number;
+}
+
+export interface SLOBreach extends DeliverySegment {
+  percentile: 'p95' | 'p99';
+  observedMs: number;
+  targetMs: number;
+  detectedAt: Date;
+}
+
+interface InFlightMessage {
+  ingressAt: number;
+  dispatchAt?: number;
+}
+
+const DEFAULT_SLO: DeliverySLO = {
+  p95Ms: 500,
+  p99Ms: 1500,
+  minSamples: 50,
+};
+
+const IN_FLIGHT_TTL = 5 * 60 * 1000; // forget messages never acked after 5 minutes
+const WINDOW_SIZE = 1000; // samples kept per segment
+
+export class DeliveryLatencyTracker extends EventEmitter {
+  private inFlight = new Map<string, InFlightMessage>();
+  private dispatchSamples = new Map<string, number[]>();
+  private endToEndSamples = new Map<string, number[]>();
+  private slo: DeliverySLO;
+
+  constructor(slo: DeliverySLO = DEFAULT_SLO) {
+    super();
+    this.slo = slo;
+    this.startSLOEvaluation();
+  }
+
+  // Record the moment a message entered the server
+  recordIngress(messageId: string, ingressAt: number = Date.now()): void {
+    this.inFlight.set(messageId, { ingressAt });
+  }
+
+  // Record the moment the hub fanned the message out
+  recordDispatch(messageId: string, segment?: DeliverySegment): void {
+    const entry = this.inFlight.get(messageId);
+    if (!entry) return;
+
+    entry.dispatchAt = Date.now();
+    const latency = entry.dispatchAt - entry.ingressAt;
+    this.pushSample(this.dispatchSamples, this.segmentKey(segment), latency);
+    metricsCollector.recordHistogram('message_dispatch_latency', latency);
+  }
+
+  // Record a client delivery acknowledgment
+  recordAck(messageId: string, segment: DeliverySegment): number | null {
+    const entry = this.inFlight.get(messageId);
+    if (!entry) return null;
+
+    const latency = Date.now() - entry.ingressAt;
+    this.pushSample(this.endToEndSamples, this.segmentKey(segment), latency);
+    metricsCollector.recordHistogram('message_delivery_latency', latency, {
+      region: segment.region,
+      platform: segment.platform,
+    });
+
+    return latency;
+  }
+
+  // Get latency percentiles for every region/platform seen
+  getSummary(): SegmentLatency[] {
+    const keys = new Set([...this.dispatchSamples.keys(), ...this.endToEndSamples.keys()]);
+
+    return [...keys].map(key => {
+      const [region, platform] = key.split('|');
+      return {
+        region,
+        platform,
+        ingressToDispatch: this.percentiles(this.dispatchSamples.get(key) || []),
+        endToEnd: this.percentiles(this.endToEndSamples.get(key) || []),
+      };
+    });
+  }
+
+  getSLO(): DeliverySLO {
+    return { ...this.slo };
+  }
+
+  setSLO(slo: Partial<DeliverySLO>): void {
+    this.slo = { ...this.slo, ...slo };
+  }
+
+  // Compare current percentiles against the SLO and emit 'slo_breach' for offenders
+  evaluateSLO(): SLOBreach[] {
+    const breaches: SLOBreach[] = [];
+
+    for (const segment of this.getSummary()) {
+      const { endToEnd } = segment;
+      if (endToEnd.count < this.slo.minSamples) continue;
+
+      if (endToEnd.p95 > this.slo.p95Ms) {
+        breaches.push(this.breach(segment, 'p95', endToEnd.p95, this.slo.p95Ms));
+      }
+      if (endToEnd.p99 > this.slo.p99Ms) {
+        breaches.push(this.breach(segment, 'p99', endToEnd.p99, this.slo.p99Ms));
+      }
+    }
+
+    breaches.forEach(breach => {
+      logger.warn('Message delivery latency SLO breached', { ...breach });
+      metricsCollector.incrementCounter('message_delivery_slo_breaches', 1, {
+        region: breach.region,
+        platform: breach.platform,
+        percentile: breach.percentile,
+      });
+      this.emit('slo_breach', breach);
+    });
+
+    return breaches;
+  }
+
+  // Private methods
+  private breach(segment: DeliverySegment, percentile: 'p95' | 'p99', observedMs: number, targetMs: number): SLOBreach {
+    return {
+      region: segment.region,
+      platform: segment.platform,
+      percentile,
+      observedMs,
+      targetMs,
+      detectedAt: new Date(),
+    };
+  }
+
+  private segmentKey(segment?: DeliverySegment): string {
+    return `${segment?.region || 'unknown'}|${segment?.platform || 'unknown'}`;
+  }
+
+  private pushSample(store: Map<string, number[]>, key: string, value: number): void {
+    const values = store.get(key) || [];
+    values.push(value);
+    if (values.length > WINDOW_SIZE) {
+      values.splice(0, values.length - WINDOW_SIZE);
+    }
+    store.set(key, values);
+  }
+
+  private percentiles(values: number[]): LatencyPercentiles {
+    const sorted = [...values].sort((a, b) => a - b);
+    const at = (p: number) => {
+      if (sorted.length === 0) return 0;
+      const index = Math.ceil((p / 100) * sorted.length) - 1;
+      return sorted[Math.max(0, index)];
+    };
+
+    return {
+      count: sorted.length,
+      p50: at(50),
+      p95: at(95),
+      p99: at(99),
+    };
+  }
+
+  private startSLOEvaluation(): void {
+    setInterval(() => {
+      // Drop messages that were never acknowledged
+      const cutoff = Date.now() - IN_FLIGHT_TTL;
+      for (const [messageId, entry] of this.inFlight.entries()) {
+        if (entry.ingressAt < cutoff) {
+          this.inFlight.delete(messageId);
+        }
+      }
+
+      this.evaluateSLO();
+    }, 60000); // Every minute
+  }
+}
+
+export const deliveryLatencyTracker = new DeliveryLatencyTracker();
@@ -0,0 +1,185 @@
+import { Types } from 'mongoose';
+import { GroupAnalyticsRepository } from '../database/repositories/group-analytics';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { IGroupAnalytics } from '../database/models/group-analytics';
+import { permissionService } from '../security/permissions';
+import { startOfUtcDay } from './activity';
+import { logger } from './logging';
+
+const POLL_INTERVAL = 15 * 60 * 1000;
+const REFRESH_INTERVAL = 60 * 60 * 1000; // a busy group is recomputed at most hourly
+const DAY = 24 * 60 * 60 * 1000;
+const WINDOW_DAYS = 28; // within MESSAGE_BUCKET_AFTER_DAYS, so history is still live
+const TOP_COUNT = 10;
+const PREVIEW_LENGTH = 140;
+const PEAK_HOURS = 3;
+const BATCH_SIZE = 50;
+
+// Engagement analytics for group admins: most reacted messages, most active
+// members, daily message volume and the busiest hours of the day, over the
+// last four weeks. Figures are computed in the background for groups with
+// new activity and kept as a snapshot, so reading them never aggregates a
+// busy group's messages on the request path. A snapshot is recomputed at
+// most hourly, and at least daily so quiet groups' windows move on.
+export class GroupAnalyticsService {
+  private groupAnalyticsRepository = new GroupAnalyticsRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Safe to run on several instances: recomputing a snapshot only replaces it
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // The group's figures; group admins only. Computed on the spot the first
+  // time, or when the snapshot is more than a day old.
+  async get(groupId: string, userId: string) {
+    if (!Types.ObjectId.isValid(groupId) || !(await permissionService.canManageGroup(userId, groupId, 'view_analytics'))) {
+      throw new GroupAnalyticsError('Only group admins can see group analytics', 403);
+    }
+
+    let analytics = await this.groupAnalyticsRepository.findByChat(groupId);
+    if (!analytics || analytics.computedAt.getTime() <= Date.now() - DAY) {
+      analytics = await this.compute(groupId);
+    }
+
+    return await this.serialize(analytics);
+  }
+
+  async compute(groupId: string | Types.ObjectId): Promise<IGroupAnalytics> {
+    const windowStart = new Date(startOfUtcDay(new Date()).getTime() - (WINDOW_DAYS - 1) * DAY);
+    const engagement = await this.messageRepository.getGroupEngagement(groupId, windowStart, TOP_COUNT);
+
+    const reactionsGiven = new Map(engagement.reactors.map(row => [row._id?.toString(), row.count]));
+    const hourly = new Array(24).fill(0);
+    engagement.hourly.forEach(row => { hourly[row._id] = row.messages; });
+
+    return await this.groupAnalyticsRepository.upsert(groupId, {
+      windowDays: WINDOW_DAYS,
+      windowStart,
+      totals: engagement.totals,
+      topMessages: engagement.topMessages.map(message => ({
+        messageId: message._id,
+        senderId: message.senderId,
+        type: message.type,
+        preview: (message.content || '').slice(0, PREVIEW_LENGTH),
+        reactions: message.reactionCount,
+        emoji: countEmoji(message.emoji),
+        sentAt: message.createdAt,
+      })),
+      topMembers: engagement.topSenders.map(sender => ({
+        userId: sender._id,
+        messages: sender.messages,
+        reactionsGiven: reactionsGiven.get(sender._id.toString()) ?? 0,
+        reactionsReceived: sender.reactionsReceived,
+      })),
+      daily: engagement.daily.map(row => ({ day: row._id, messages: row.messages, reactions: row.reactions })),
+      hourly,
+    });
+  }
+
+  private async serialize(analytics: IGroupAnalytics) {
+    const profiles = await this.userRepository.getProfiles([
+      ...analytics.topMembers.map(member => member.userId),
+      ...analytics.topMessages.map(message => message.senderId),
+    ]);
+    const profile = (userId: Types.ObjectId) => ({
+      userId: userId.toString(),
+      displayName: profiles.get(userId.toString())?.displayName,
+      avatar: profiles.get(userId.toString())?.avatar,
+    });
+
+    // Days without messages are filled in so trends chart evenly
+    const byDay = new Map(analytics.daily.map(row => [row.day, row]));
+    const volume = [];
+    for (let i = 0; i < analytics.windowDays; i++) {
+      const day = new Date(analytics.windowStart.getTime() + i * DAY).toISOString().slice(0, 10);
+      volume.push({ day, messages: byDay.get(day)?.messages ?? 0, reactions: byDay.get(day)?.reactions ?? 0 });
+    }
+
+    return {
+      groupId: analytics.chatId.toString(),
+      windowDays: analytics.windowDays,
+      windowStart: analytics.windowStart,
+      totals: analytics.totals,
+      mostReacted: analytics.topMessages.map(message => ({
+        messageId: message.messageId.toString(),
+        sender: profile(message.senderId),
+        type: message.type,
+        preview: message.preview,
+        reactions: message.reactions,
+        emoji: message.emoji.map(({ emoji, count }) => ({ emoji, count })),
+        sentAt: message.sentAt,
+      })),
+      mostActive: analytics.topMembers.map(member => ({
+        ...profile(member.userId),
+        messages: member.messages,
+        reactionsGiven: member.reactionsGiven,
+        reactionsReceived: member.reactionsReceived,
+      })),
+      volume,
+      hourly: analytics.hourly, // UTC
+      peakHours: analytics.hourly
+        .map((messages, hour) => ({ hour, messages }))
+        .filter(row => row.messages > 0)
+        .sort((a, b) => b.messages - a.messages)
+        .slice(0, PEAK_HOURS),
+      computedAt: analytics.computedAt,
+    };
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const now = Date.now();
+      const groupIds = await this.groupAnalyticsRepository.findStaleGroupIds(
+        new Date(now - WINDOW_DAYS * DAY),
+        new Date(now - REFRESH_INTERVAL),
+        new Date(now - DAY),
+        BATCH_SIZE
+      );
+      for (const groupId of groupIds) {
+        await this.compute(groupId);
+      }
+      logger.debug('Group analytics recomputed', { groups: groupIds.length });
+    } catch (error) {
+      logger.error('Group analytics job error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+// Reactions per emoji, most used first
+function countEmoji(emoji: string[]): { emoji: string; count: number }[] {
+  const counts = new Map<string, number>();
+  emoji.forEach(value => counts.set(value, (counts.get(value) ?? 0) + 1));
+  return [...counts.entries()]
+    .map(([value, count]) => ({ emoji: value, count }))
+    .sort((a, b) => b.count - a.count);
+}
+
+export class GroupAnalyticsError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'GroupAnalyticsError';
+  }
+}
+
+export const groupAnalyticsService = new GroupAnalyticsService();
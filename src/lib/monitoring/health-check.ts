@@ -1,6 +1,7 @@
 import { logger } from './logging';
 import connectDB from '../database/mongodb';
 import { User } from '../database/models/user';
+import { changeStreamHub } from '../database/change-streams';
 
 interface HealthStatus {
   status: 'healthy' | 'degraded' | 'unhealthy';
@@ -217,6 +218,22 @@ export class HealthMonitor {
       });
     }
 
+    // Workers fall back to polling while their change streams are down
+    this.registerCheck('change_streams', async (): Promise<HealthCheck> => {
+      const subscriptions = changeStreamHub.getStatus();
+      const down = subscriptions.filter(subscription => !subscription.live);
+
+      return {
+        name: 'change_streams',
+        status: down.length === 0 ? 'pass' : 'warn',
+        duration: 0,
+        message: down.length === 0
+          ? `${subscriptions.length} change streams live`
+          : `Polling instead of change streams for: ${down.map(subscription => subscription.name).join(', ')}`,
+        details: subscriptions,
+      };
+    });
+
     // External service checks
     this.registerCheck('external_services', async (): Promise<HealthCheck> => {
       const services: Array<{ name: string; status: 'pass' | 'fail'; error?: string }> = [];
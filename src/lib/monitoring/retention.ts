@@ -0,0 +1,199 @@
+import { UserActivityRepository } from '../database/repositories/user-activity';
+import { RetentionCohortRepository } from '../database/repositories/retention-cohort';
+import { UserRepository } from '../database/repositories/user';
+import { IRetentionCohort } from '../database/models/retention-cohort';
+import { startOfUtcDay } from './activity';
+import { logger } from './logging';
+
+const POLL_INTERVAL = 60 * 60 * 1000; // hourly
+const DAY = 24 * 60 * 60 * 1000;
+const WEEK = 7 * DAY;
+const HISTORY_WEEKS = 26; // cohorts kept up to date
+const RETENTION_DAYS = [1, 7, 30] as const;
+
+type RetentionKey = `day${typeof RETENTION_DAYS[number]}`;
+
+// Monday 00:00 UTC of the week containing date
+export function startOfUtcWeek(date: Date): Date {
+  const day = startOfUtcDay(date);
+  return new Date(day.getTime() - ((day.getUTCDay() + 6) % 7) * DAY);
+}
+
+function rate(retained: number, eligible: number): number | null {
+  return eligible > 0 ? Math.round((retained / eligible) * 1000) / 10 : null; // percent
+}
+
+// Growth and retention metrics for the admin dashboard. Weekly signup
+// cohorts are recomputed in the background from daily user activity until
+// their day-30 window has passed, then frozen.
+export class RetentionService {
+  private userActivityRepository = new UserActivityRepository();
+  private retentionCohortRepository = new RetentionCohortRepository();
+  private userRepository = new UserRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), POLL_INTERVAL);
+    this.timer.unref();
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Recompute every open cohort now
+  async recompute(): Promise<number> {
+    const now = new Date();
+    const since = new Date(startOfUtcWeek(now).getTime() - (HISTORY_WEEKS - 1) * WEEK);
+    const complete = new Set(
+      (await this.retentionCohortRepository.findCompleteWeekStarts(since)).map(weekStart => weekStart.getTime())
+    );
+
+    let computed = 0;
+    for (let weekStart = since; weekStart <= now; weekStart = new Date(weekStart.getTime() + WEEK)) {
+      if (complete.has(weekStart.getTime())) continue;
+      await this.computeCohort(weekStart, now);
+      computed++;
+    }
+    return computed;
+  }
+
+  // Cohorts of the last weeks, oldest first
+  async getCohorts(weeks: number) {
+    const since = new Date(startOfUtcWeek(new Date()).getTime() - (weeks - 1) * WEEK);
+    const cohorts = await this.retentionCohortRepository.findSince(since);
+    return cohorts.map(serializeRetentionCohort);
+  }
+
+  // Users who were active within lookbackDays before going quiet for
+  // inactiveDays, most recently active first
+  async getChurnedUsers(options: { inactiveDays: number; lookbackDays: number; limit: number; offset: number }) {
+    const activeBefore = new Date(startOfUtcDay(new Date()).getTime() - (options.inactiveDays - 1) * DAY);
+    const activeFrom = new Date(activeBefore.getTime() - options.lookbackDays * DAY);
+
+    const { total, users } = await this.userActivityRepository.getChurnedUsers(
+      activeFrom,
+      activeBefore,
+      options.limit,
+      options.offset
+    );
+
+    return {
+      total,
+      users: users.map(row => ({
+        userId: row.userId.toString(),
+        displayName: row.user?.displayName,
+        phoneNumber: row.user?.phoneNumber,
+        signedUpAt: row.user?.createdAt,
+        banned: !!row.user?.isBanned,
+        lastActiveDay: row.lastActiveDay,
+        activeDays: row.activeDays, // within the lookback window
+        inactiveDays: Math.floor((startOfUtcDay(new Date()).getTime() - row.lastActiveDay.getTime()) / DAY),
+      })),
+    };
+  }
+
+  // Signups and active users per day, plus current WAU/MAU
+  async getGrowth(days: number) {
+    const today = startOfUtcDay(new Date());
+    const tomorrow = new Date(today.getTime() + DAY);
+    const from = new Date(tomorrow.getTime() - days * DAY);
+
+    const [signups, activeUsers, weeklyActive, monthlyActive, totalUsers] = await Promise.all([
+      this.userRepository.getSignupsByDay(from, tomorrow),
+      this.userActivityRepository.getDailyActiveUsers(from, tomorrow),
+      this.userActivityRepository.countActiveUsers(new Date(tomorrow.getTime() - 7 * DAY), tomorrow),
+      this.userActivityRepository.countActiveUsers(new Date(tomorrow.getTime() - 30 * DAY), tomorrow),
+      this.userRepository.countHumanUsers(),
+    ]);
+
+    const signupsByDay = new Map(signups.map(row => [row.day.getTime(), row.signups]));
+    const activeByDay = new Map(activeUsers.map(row => [row.day.getTime(), row.users]));
+    const series = [];
+    for (let day = from; day < tomorrow; day = new Date(day.getTime() + DAY)) {
+      series.push({
+        day: day.toISOString().slice(0, 10),
+        signups: signupsByDay.get(day.getTime()) ?? 0,
+        activeUsers: activeByDay.get(day.getTime()) ?? 0,
+      });
+    }
+
+    const dailyActive = activeByDay.get(today.getTime()) ?? 0;
+
+    return {
+      totalUsers,
+      dailyActive,
+      weeklyActive,
+      monthlyActive,
+      stickiness: monthlyActive > 0 ? Math.round((dailyActive / monthlyActive) * 1000) / 10 : null, // DAU/MAU percent
+      series,
+    };
+  }
+
+  private async computeCohort(weekStart: Date, now: Date): Promise<void> {
+    const weekEnd = new Date(weekStart.getTime() + WEEK);
+    const rows = await this.userActivityRepository.getSignupActivity(weekStart, weekEnd, [...RETENTION_DAYS]);
+
+    const retention = {} as IRetentionCohort['retention'];
+    RETENTION_DAYS.forEach(offset => {
+      const key: RetentionKey = `day${offset}`;
+      retention[key] = { eligible: 0, retained: 0 };
+
+      rows.forEach(row => {
+        // Day N counts once it has fully passed
+        if (row.signupDay.getTime() + (offset + 1) * DAY > now.getTime()) return;
+        retention[key].eligible++;
+        if (row.activeOffsets.includes(offset)) {
+          retention[key].retained++;
+        }
+      });
+    });
+
+    const lastDay = Math.max(...RETENTION_DAYS);
+    await this.retentionCohortRepository.upsert(weekStart, {
+      signups: rows.length,
+      retention,
+      complete: weekEnd.getTime() + lastDay * DAY <= now.getTime(),
+    });
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const computed = await this.recompute();
+      logger.debug('Retention cohorts recomputed', { cohorts: computed });
+    } catch (error) {
+      logger.error('Retention cohort job error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+export function serializeRetentionCohort(cohort: IRetentionCohort) {
+  const retention = {} as Record<RetentionKey, { eligible: number; retained: number; rate: number | null }>;
+  RETENTION_DAYS.forEach(offset => {
+    const key: RetentionKey = `day${offset}`;
+    const { eligible, retained } = cohort.retention[key];
+    retention[key] = { eligible, retained, rate: rate(retained, eligible) };
+  });
+
+  return {
+    weekStart: cohort.weekStart.toISOString().slice(0, 10),
+    signups: cohort.signups,
+    retention,
+    complete: cohort.complete,
+    computedAt: cohort.computedAt,
+  };
+}
+
+export const retentionService = new RetentionService();
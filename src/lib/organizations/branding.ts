@@ -0,0 +1,251 @@
+import { Types } from 'mongoose';
+import { OrganizationRepository } from '../database/repositories/organization';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { IOrganization } from '../database/models/organization';
+import { UpdateBrandingInput } from '../database/schemas/organization';
+import { BRANDING_CONSTANTS } from '../utils/constants';
+import { environmentConfig } from '../config/environment';
+import { storageService } from '../media/storage';
+import { cdnService } from '../media/cdn';
+import { organizationService } from './index';
+import { logger } from '../monitoring/logging';
+
+const DOMAIN_CACHE_TTL = 60 * 1000;
+const ASSET_URL_TTL = 24 * 60 * 60; // signed asset URLs, when there's no CDN
+
+export type BrandingAsset = 'logo' | 'favicon';
+
+export interface BrandingUpload {
+  buffer: Buffer;
+  filename: string;
+  mimeType: string;
+}
+
+export interface Branding {
+  organizationId: string | null;
+  appName: string;
+  logoUrl: string | null;
+  faviconUrl: string | null;
+  colors: {
+    primary: string | null;
+    accent: string | null;
+    background: string | null;
+  };
+}
+
+const ASSET_KEYS: Record<BrandingAsset, 'logoKey' | 'faviconKey'> = {
+  logo: 'logoKey',
+  favicon: 'faviconKey',
+};
+
+// Per-organization branding: an app name, colors and logo and favicon
+// images that clients fetch to theme themselves. Clients find their
+// organization by the host they were served from (one of its custom
+// domains) or by passing its ID or slug. Everyone else, and members of
+// suspended organizations, get the deployment's defaults.
+export class BrandingService {
+  private organizationRepository = new OrganizationRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private domainCache = new Map<string, { organizationId: string | null; loadedAt: number }>();
+
+  async resolve(lookup: { host?: string | null; organization?: string | null }): Promise<Branding> {
+    const organization = lookup.organization
+      ? await this.findByIdOrSlug(lookup.organization)
+      : await this.findByHost(lookup.host);
+
+    if (!organization || organization.status === 'suspended') {
+      return this.defaults();
+    }
+    return await this.serialize(organization);
+  }
+
+  // Change the app name, colors or custom domains; null clears a setting
+  async update(organizationId: string, adminId: string, input: UpdateBrandingInput): Promise<IOrganization> {
+    const current = await organizationService.get(organizationId);
+
+    const set: Record<string, unknown> = {};
+    const unset: string[] = [];
+    const apply = (field: string, value: unknown) => {
+      if (value === undefined) return;
+      if (value === null) unset.push(field);
+      else set[field] = value;
+    };
+
+    apply('branding.appName', input.appName);
+    for (const [key, value] of Object.entries(input.colors || {})) apply(`branding.colors.${key}`, value);
+
+    if (input.domains !== undefined) {
+      const domains = input.domains ? [...new Set(input.domains)] : [];
+      for (const domain of domains) {
+        const owner = await this.organizationRepository.findByDomain(domain);
+        if (owner && !owner._id.equals(current._id)) {
+          throw new BrandingError(`${domain} is already used by another organization`, 409);
+        }
+      }
+      apply('branding.domains', domains.length > 0 ? domains : null);
+    }
+
+    const organization = await this.organizationRepository.update(organizationId, set, unset);
+    if (!organization) {
+      throw new BrandingError('Organization not found', 404);
+    }
+    this.forgetDomains(current, organization);
+
+    await this.audit(adminId, 'organization.branding.update', organization._id, { ...input });
+    logger.info('Organization branding updated', { organizationId, adminId });
+    return organization;
+  }
+
+  // Replace the organization's logo or favicon. Assets are public so the
+  // sign-in page can show them before anyone has a session.
+  async uploadAsset(organizationId: string, adminId: string, asset: BrandingAsset, file: BrandingUpload): Promise<IOrganization> {
+    const current = await organizationService.get(organizationId);
+
+    if (!(BRANDING_CONSTANTS.ASSET_MIME_TYPES as readonly string[]).includes(file.mimeType)) {
+      throw new BrandingError('Branding images must be PNG, JPEG, WebP or ICO files', 400);
+    }
+    if (file.buffer.length === 0) {
+      throw new BrandingError('File is empty', 400);
+    }
+    if (file.buffer.length > BRANDING_CONSTANTS.ASSET_MAX_SIZE) {
+      throw new BrandingError(`Branding images must be at most ${BRANDING_CONSTANTS.ASSET_MAX_SIZE / 1024}KB`, 400);
+    }
+
+    const { key } = await storageService.uploadFile(file.buffer, file.filename, organizationId, {
+      contentType: file.mimeType,
+      acl: 'public-read',
+      metadata: { asset },
+    }, 'branding');
+
+    const field = `branding.${ASSET_KEYS[asset]}`;
+    const organization = await this.organizationRepository.update(organizationId, { [field]: key });
+    if (!organization) {
+      await storageService.deleteFile(key);
+      throw new BrandingError('Organization not found', 404);
+    }
+    organizationService.forget(organizationId);
+    await this.deleteAsset(current.branding?.[ASSET_KEYS[asset]]);
+
+    await this.audit(adminId, 'organization.branding.asset.upload', organization._id, { asset, size: file.buffer.length });
+    logger.info('Organization branding asset uploaded', { organizationId, adminId, asset });
+    return organization;
+  }
+
+  // Go back to the default logo or favicon
+  async removeAsset(organizationId: string, adminId: string, asset: BrandingAsset): Promise<IOrganization> {
+    const current = await organizationService.get(organizationId);
+    const key = current.branding?.[ASSET_KEYS[asset]];
+    if (!key) {
+      throw new BrandingError(`The organization has no custom ${asset}`, 404);
+    }
+
+    const organization = await this.organizationRepository.update(organizationId, {}, [`branding.${ASSET_KEYS[asset]}`]);
+    if (!organization) {
+      throw new BrandingError('Organization not found', 404);
+    }
+    organizationService.forget(organizationId);
+    await this.deleteAsset(key);
+
+    await this.audit(adminId, 'organization.branding.asset.remove', organization._id, { asset });
+    logger.info('Organization branding asset removed', { organizationId, adminId, asset });
+    return organization;
+  }
+
+  async serialize(organization: IOrganization): Promise<Branding> {
+    const branding = organization.branding || {};
+    const defaults = this.defaults();
+
+    return {
+      organizationId: organization._id.toString(),
+      appName: branding.appName || defaults.appName,
+      logoUrl: await this.assetUrl(branding.logoKey),
+      faviconUrl: await this.assetUrl(branding.faviconKey),
+      colors: {
+        primary: branding.colors?.primary || null,
+        accent: branding.colors?.accent || null,
+        background: branding.colors?.background || null,
+      },
+    };
+  }
+
+  private defaults(): Branding {
+    return {
+      organizationId: null,
+      appName: environmentConfig.getValue('APP_NAME'),
+      logoUrl: null,
+      faviconUrl: null,
+      colors: { primary: null, accent: null, background: null },
+    };
+  }
+
+  private async findByIdOrSlug(value: string): Promise<IOrganization | null> {
+    if (Types.ObjectId.isValid(value)) {
+      return await organizationService.getCached(value);
+    }
+    return await this.organizationRepository.findBySlug(value.toLowerCase());
+  }
+
+  // Custom domains are looked up on every page load, so the host to
+  // organization mapping is cached briefly, misses included
+  private async findByHost(host?: string | null): Promise<IOrganization | null> {
+    const domain = normalizeHost(host);
+    if (!domain) return null;
+
+    const cached = this.domainCache.get(domain);
+    if (cached && Date.now() - cached.loadedAt < DOMAIN_CACHE_TTL) {
+      return cached.organizationId ? await organizationService.getCached(cached.organizationId) : null;
+    }
+
+    const organization = await this.organizationRepository.findByDomain(domain);
+    this.domainCache.set(domain, { organizationId: organization?._id.toString() ?? null, loadedAt: Date.now() });
+    return organization;
+  }
+
+  private forgetDomains(...organizations: IOrganization[]): void {
+    for (const organization of organizations) {
+      for (const domain of organization.branding?.domains || []) this.domainCache.delete(domain);
+      organizationService.forget(organization._id);
+    }
+  }
+
+  private async assetUrl(key?: string): Promise<string | null> {
+    if (!key) return null;
+    return cdnService.getUrl(key) || await storageService.getFileUrl(key, ASSET_URL_TTL);
+  }
+
+  // Best effort; a replaced asset left behind is only wasted space
+  private async deleteAsset(key?: string): Promise<void> {
+    if (!key) return;
+    try {
+      await storageService.deleteFile(key);
+      await cdnService.purge([key]);
+    } catch (error) {
+      logger.warn('Failed to delete branding asset', { key, error });
+    }
+  }
+
+  private async audit(adminId: string, action: string, targetId: Types.ObjectId, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action,
+      targetType: 'organization',
+      targetId,
+      details,
+    });
+  }
+}
+
+// Host header without port or trailing dot, lowercased
+function normalizeHost(host?: string | null): string | null {
+  const value = host?.split(',')[0].trim().toLowerCase().replace(/:\d+$/, '').replace(/\.$/, '');
+  return value || null;
+}
+
+export class BrandingError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'BrandingError';
+  }
+}
+
+export const brandingService = new BrandingService();
@@ -0,0 +1,273 @@
+import { Types } from 'mongoose';
+import { OrganizationRepository } from '../database/repositories/organization';
+import { UserRepository } from '../database/repositories/user';
+import { AdminRepository } from '../database/repositories/admin';
+import { AuditEntryRepository } from '../database/repositories/audit-entry';
+import { IOrganization, OrganizationStatus } from '../database/models/organization';
+import { IFeatureConfig } from '../database/models/admin-config';
+import { CreateOrganizationInput, UpdateOrganizationInput } from '../database/schemas/organization';
+import { adminConfigService } from '../config/admin-config';
+import { logger } from '../monitoring/logging';
+
+const CACHE_TTL = 30 * 1000;
+
+// Organizations let one deployment serve several isolated tenants:
+//   - users, groups, org admins and API keys belong to at most one
+//     organization; users in none form the shared pool, as before
+//   - people only reach others in their own organization: groups can't mix
+//     members of different organizations and contact codes don't resolve
+//     across them
+//   - an organization can narrow the global feature switches and cap its
+//     users and groups
+//   - suspending an organization locks its members out
+// Org admins manage their own organization only; creating organizations and
+// moving people between them is for platform admins (those in none).
+export class OrganizationService {
+  private organizationRepository = new OrganizationRepository();
+  private userRepository = new UserRepository();
+  private adminRepository = new AdminRepository();
+  private auditEntryRepository = new AuditEntryRepository();
+  private cache = new Map<string, { organization: IOrganization | null; loadedAt: number }>();
+
+  async create(adminId: string, input: CreateOrganizationInput): Promise<IOrganization> {
+    if (await this.organizationRepository.findBySlug(input.slug)) {
+      throw new OrganizationError('An organization with this slug already exists', 409);
+    }
+
+    const organization = await this.organizationRepository.create({
+      name: input.name,
+      slug: input.slug,
+      features: input.features,
+      quotas: input.quotas,
+      createdBy: new Types.ObjectId(adminId),
+    });
+
+    await this.audit(adminId, 'organization.create', organization._id, { name: input.name, slug: input.slug });
+    logger.info('Organization created', { organizationId: organization._id.toString(), adminId });
+    return organization;
+  }
+
+  async update(organizationId: string, adminId: string, input: UpdateOrganizationInput): Promise<IOrganization> {
+    await this.get(organizationId);
+
+    const updates: Record<string, unknown> = {};
+    if (input.name !== undefined) updates.name = input.name;
+    if (input.status !== undefined) updates.status = input.status;
+    for (const [key, value] of Object.entries(input.features || {})) updates[`features.${key}`] = value;
+    for (const [key, value] of Object.entries(input.quotas || {})) updates[`quotas.${key}`] = value;
+
+    const organization = await this.organizationRepository.update(organizationId, updates);
+    if (!organization) {
+      throw new OrganizationError('Organization not found', 404);
+    }
+    this.cache.delete(organizationId);
+
+    await this.audit(adminId, 'organization.update', organization._id, { ...input });
+    logger.info('Organization updated', { organizationId, adminId, status: organization.status });
+    return organization;
+  }
+
+  async list(
+    filter: { status?: OrganizationStatus },
+    limit?: number,
+    offset?: number
+  ): Promise<{ organizations: IOrganization[]; total: number }> {
+    return await this.organizationRepository.list(filter, limit, offset);
+  }
+
+  async get(organizationId: string): Promise<IOrganization> {
+    const organization = Types.ObjectId.isValid(organizationId)
+      ? await this.organizationRepository.findById(organizationId)
+      : null;
+    if (!organization) {
+      throw new OrganizationError('Organization not found', 404);
+    }
+    return organization;
+  }
+
+  async getUsage(organizationId: string): Promise<{ users: number; groups: number }> {
+    const [users, groups] = await Promise.all([
+      this.organizationRepository.countMembers(organizationId),
+      this.organizationRepository.countGroups(organizationId),
+    ]);
+    return { users, groups };
+  }
+
+  // Move users from the shared pool into the organization. Users already in
+  // another organization have to be removed from it first.
+  async addMembers(organizationId: string, userIds: string[], adminId: string): Promise<number> {
+    const organization = await this.get(organizationId);
+
+    const current = await this.userRepository.getOrganizationIds(userIds);
+    if (current.size !== new Set(userIds).size) {
+      throw new OrganizationError('User not found', 404);
+    }
+    if ([...current.values()].some(id => id && id !== organizationId)) {
+      throw new OrganizationError('Some users belong to another organization', 409);
+    }
+
+    const joining = [...current.entries()].filter(([, id]) => !id).map(([userId]) => userId);
+    await this.checkUserQuota(organization, joining.length);
+
+    const added = await this.userRepository.setOrganization(joining, organization._id);
+
+    await this.audit(adminId, 'organization.members.add', organization._id, { userIds: joining });
+    logger.info('Users added to organization', { organizationId, adminId, count: added });
+    return added;
+  }
+
+  // Move users back to the shared pool
+  async removeMembers(organizationId: string, userIds: string[], adminId: string): Promise<number> {
+    const organization = await this.get(organizationId);
+
+    const current = await this.userRepository.getOrganizationIds(userIds);
+    const leaving = [...current.entries()].filter(([, id]) => id === organizationId).map(([userId]) => userId);
+    const removed = await this.userRepository.setOrganization(leaving, null);
+
+    await this.audit(adminId, 'organization.members.remove', organization._id, { userIds: leaving });
+    logger.info('Users removed from organization', { organizationId, adminId, count: removed });
+    return removed;
+  }
+
+  // Make an admin an org admin of this organization, or with null a
+  // platform admin again
+  async setAdminOrganization(targetAdminId: string, organizationId: string | null, adminId: string): Promise<void> {
+    const organization = organizationId ? await this.get(organizationId) : null;
+
+    const target = Types.ObjectId.isValid(targetAdminId) ? await this.adminRepository.findById(targetAdminId) : null;
+    if (!target) {
+      throw new OrganizationError('Admin not found', 404);
+    }
+    if (targetAdminId === adminId) {
+      throw new OrganizationError('You cannot change your own organization', 400);
+    }
+
+    await this.adminRepository.update(target._id, organization
+      ? { organizationId: organization._id }
+      : { $unset: { organizationId: 1 } } as any);
+
+    await this.audit(adminId, organization ? 'organization.admin.assign' : 'organization.admin.unassign',
+      organization?._id ?? target.organizationId ?? target._id, { adminId: targetAdminId });
+    logger.info('Admin organization changed', { targetAdminId, organizationId, adminId });
+  }
+
+  // Organization for request-time checks, cached briefly. Other instances
+  // see suspensions and feature changes once their cache expires.
+  async getCached(organizationId: string | Types.ObjectId): Promise<IOrganization | null> {
+    const key = organizationId.toString();
+    const cached = this.cache.get(key);
+    if (cached && Date.now() - cached.loadedAt < CACHE_TTL) {
+      return cached.organization;
+    }
+
+    const organization = await this.organizationRepository.findById(key);
+    this.cache.set(key, { organization, loadedAt: Date.now() });
+    return organization;
+  }
+
+  // Drop a cached organization after changing it
+  forget(organizationId: string | Types.ObjectId): void {
+    this.cache.delete(organizationId.toString());
+  }
+
+  async isSuspended(organizationId?: string | Types.ObjectId): Promise<boolean> {
+    if (!organizationId) return false;
+    return (await this.getCached(organizationId))?.status === 'suspended';
+  }
+
+  // Feature switches in force for an organization: the global ones,
+  // narrowed by the organization's. An organization can turn a feature
+  // off or shorten a limit, not undo a global switch.
+  async getFeatures(organizationId?: string | Types.ObjectId): Promise<Partial<IFeatureConfig>> {
+    const { features } = await adminConfigService.get();
+    const organization = organizationId ? await this.getCached(organizationId) : null;
+    if (!organization) return features;
+
+    const own = organization.features || {};
+    return {
+      publicFileLinks: features.publicFileLinks === false || own.publicFileLinks === false ? false : features.publicFileLinks,
+      publicLinkMaxDays: shortestLimit(features.publicLinkMaxDays, own.publicLinkMaxDays),
+      guestLinks: features.guestLinks === false || own.guestLinks === false ? false : features.guestLinks,
+    };
+  }
+
+  async getFeaturesForUser(userId: string | Types.ObjectId): Promise<Partial<IFeatureConfig>> {
+    const organizations = await this.userRepository.getOrganizationIds([userId]);
+    return await this.getFeatures(organizations.get(userId.toString()));
+  }
+
+  // Whether all the given users are in the organization (or, for
+  // undefined, in the shared pool)
+  async allInOrganization(organizationId: string | undefined, userIds: (string | Types.ObjectId)[]): Promise<boolean> {
+    if (userIds.length === 0) return true;
+    const organizations = await this.userRepository.getOrganizationIds(userIds);
+    return [...organizations.values()].every(id => id === organizationId);
+  }
+
+  // Refuse to create another group once the organization is at its cap
+  async checkGroupQuota(organizationId?: string | Types.ObjectId): Promise<void> {
+    if (!organizationId) return;
+
+    const organization = await this.getCached(organizationId);
+    const maxGroups = organization?.quotas?.maxGroups;
+    if (maxGroups && await this.organizationRepository.countGroups(organizationId) >= maxGroups) {
+      throw new OrganizationError(`Your organization can have at most ${maxGroups} groups`, 403);
+    }
+  }
+
+  // Refuse to add users past the organization's cap
+  async checkUserQuota(organization: IOrganization, adding: number): Promise<void> {
+    const maxUsers = organization.quotas?.maxUsers;
+    if (!maxUsers || adding === 0) return;
+
+    const users = await this.organizationRepository.countMembers(organization._id);
+    if (users + adding > maxUsers) {
+      throw new OrganizationError(`The organization can have at most ${maxUsers} users`, 403);
+    }
+  }
+
+  private async audit(adminId: string, action: string, targetId: Types.ObjectId, details: Record<string, unknown>): Promise<void> {
+    await this.auditEntryRepository.create({
+      adminId: new Types.ObjectId(adminId),
+      action,
+      targetType: 'organization',
+      targetId,
+      details,
+    });
+  }
+}
+
+// The stricter of two day limits, where 0 or unset means no limit
+function shortestLimit(a?: number, b?: number): number | undefined {
+  const limits = [a, b].filter((limit): limit is number => !!limit);
+  return limits.length > 0 ? Math.min(...limits) : a;
+}
+
+// Org admins may only act on their own organization
+export function canManageOrganization(adminOrganizationId: string | undefined, organizationId: string): boolean {
+  return !adminOrganizationId || adminOrganizationId === organizationId;
+}
+
+export function serializeOrganization(organization: IOrganization, usage?: { users: number; groups: number }) {
+  return {
+    id: organization._id.toString(),
+    name: organization.name,
+    slug: organization.slug,
+    status: organization.status,
+    features: organization.features || {},
+    quotas: organization.quotas || {},
+    ...(usage && { usage }),
+    createdBy: organization.createdBy.toString(),
+    createdAt: organization.createdAt,
+    updatedAt: organization.updatedAt,
+  };
+}
+
+export class OrganizationError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'OrganizationError';
+  }
+}
+
+export const organizationService = new OrganizationService();
@@ -0,0 +1,208 @@
+import crypto from 'crypto';
+import { environmentConfig } from '../config/environment';
+
+const DAY = 24 * 60 * 60 * 1000;
+
+// 'full' is what admins see; 'anonymized' is safe to hand to third parties
+export const EXPORT_MODES = ['full', 'anonymized'] as const;
+
+export type ExportMode = typeof EXPORT_MODES[number];
+
+// id: replaced by a salted hash; hour / day / month: timestamps truncated;
+// keep: as is. Fields without a rule are dropped, so a field added to a
+// record stays out of anonymized exports until someone decides it is safe.
+type FieldRule = 'id' | 'hour' | 'day' | 'month' | 'keep';
+
+const RULES = {
+  message: {
+    id: 'id', chatId: 'id', senderId: 'id', pseudonymId: 'id', type: 'keep',
+    mediaId: 'id', replyTo: 'id', forwardedFrom: 'id',
+    isEdited: 'keep', isDeleted: 'keep', isImported: 'keep', moderationState: 'keep',
+    mentionCount: 'keep', linkCount: 'keep', reactionCount: 'keep', deliveredCount: 'keep', readCount: 'keep',
+    via: 'keep', createdAt: 'hour', editedAt: 'hour', deletedAt: 'hour',
+  },
+  call: {
+    id: 'id', callId: 'id', chatId: 'id', initiator: 'id', participantCount: 'keep',
+    type: 'keep', status: 'keep', isGroupCall: 'keep', encrypted: 'keep', duration: 'keep',
+    endReason: 'keep', averageRating: 'keep', startTime: 'hour', endTime: 'hour',
+  },
+  user: {
+    id: 'id', countryCode: 'keep', dataRegion: 'keep', organizationId: 'id',
+    isVerified: 'keep', lastSeen: 'month', isBanned: 'keep', restriction: 'keep',
+    platforms: 'keep', bridge: 'keep', createdAt: 'month',
+  },
+  media: {
+    id: 'id', type: 'keep', mimeType: 'keep', size: 'keep', uploadedBy: 'id', chatId: 'id',
+    messageId: 'id', storageRegion: 'keep', isEncrypted: 'keep', createdAt: 'day',
+  },
+  report: {
+    id: 'id', type: 'keep', status: 'keep', priority: 'keep', reporterId: 'id', reportedUserId: 'id',
+    reportedMessageId: 'id', reportedChatId: 'id', assignedTo: 'id', evidenceCount: 'keep', labels: 'keep',
+    createdAt: 'day', resolvedAt: 'day',
+  },
+  securityEvent: {
+    id: 'id', type: 'keep', userId: 'id', deviceId: 'id', country: 'keep',
+    loginApprovalId: 'id', createdAt: 'hour',
+  },
+  churnedUser: {
+    userId: 'id', signedUpAt: 'day', banned: 'keep', lastActiveDay: 'keep',
+    activeDays: 'keep', inactiveDays: 'keep',
+  },
+} satisfies Record<string, Record<string, FieldRule>>;
+
+export type AnonymizedRecordKind = keyof typeof RULES;
+
+// Fields that single people out in combination, e.g. the only user in a
+// country who signed up in a given month. Row-level exports of these kinds
+// are bucketed on them, see bucketSmallCohortRows.
+const QUASI_IDENTIFIERS: Partial<Record<AnonymizedRecordKind, readonly string[]>> = {
+  user: ['countryCode', 'dataRegion', 'createdAt', 'lastSeen'],
+};
+
+export function isExportMode(value: unknown): value is ExportMode {
+  return typeof value === 'string' && (EXPORT_MODES as readonly string[]).includes(value);
+}
+
+// The salt for the period a time falls in. Derived from ENCRYPTION_KEY, so
+// every server agrees on it without storing it, and it changes every
+// ANALYTICS_SALT_ROTATION_DAYS: the same user has the same pseudonymous ID
+// across exports of one period and an unrelated one in the next.
+export function analyticsSalt(at: Date = new Date()): { salt: Buffer; period: number; rotatesAt: Date } {
+  const rotation = Math.max(environmentConfig.getValue('ANALYTICS_SALT_ROTATION_DAYS'), 1) * DAY;
+  const period = Math.floor(at.getTime() / rotation);
+
+  return {
+    salt: Buffer.from(crypto.hkdfSync(
+      'sha256',
+      environmentConfig.getValue('ENCRYPTION_KEY'),
+      Buffer.alloc(0),
+      `chatapp:analytics-salt:${period}`,
+      32
+    )),
+    period,
+    rotatesAt: new Date((period + 1) * rotation),
+  };
+}
+
+// Turns export records into their anonymized form. Make one per export so
+// a whole export uses one salt, even if it runs past a rotation.
+export class ExportAnonymizer {
+  private salt: Buffer;
+  readonly period: number;
+
+  constructor(at: Date = new Date()) {
+    const { salt, period } = analyticsSalt(at);
+    this.salt = salt;
+    this.period = period;
+  }
+
+  pseudonymize(value: unknown): string | undefined {
+    if (value === undefined || value === null || value === '') return undefined;
+    return crypto.createHmac('sha256', this.salt).update(String(value)).digest('hex').slice(0, 24);
+  }
+
+  record(kind: AnonymizedRecordKind, record: Record<string, unknown>): Record<string, unknown> {
+    const rules: Record<string, FieldRule> = RULES[kind];
+    const anonymized: Record<string, unknown> = {};
+
+    for (const [field, value] of Object.entries(record)) {
+      switch (rules[field]) {
+        case 'id':
+          anonymized[field] = this.pseudonymize(value);
+          break;
+        case 'hour':
+          anonymized[field] = truncateDate(value, 'hour');
+          break;
+        case 'day':
+          anonymized[field] = truncateDate(value, 'day');
+          break;
+        case 'month':
+          anonymized[field] = truncateDate(value, 'month');
+          break;
+        case 'keep':
+          anonymized[field] = value;
+          break;
+      }
+    }
+    return anonymized;
+  }
+
+  // CSV columns of a record kind, in record order
+  columns(kind: AnonymizedRecordKind, columns: readonly string[]): string[] {
+    const rules: Record<string, FieldRule> = RULES[kind];
+    return columns.filter(column => rules[column]);
+  }
+}
+
+function truncateDate(value: unknown, unit: 'hour' | 'day' | 'month'): string | undefined {
+  if (value === undefined || value === null || value === '') return undefined;
+  const date = new Date(value as string | number | Date);
+  if (isNaN(date.getTime())) return undefined;
+
+  const iso = date.toISOString();
+  switch (unit) {
+    case 'month':
+      return iso.slice(0, 7);
+    case 'day':
+      return iso.slice(0, 10);
+    default:
+      return `${iso.slice(0, 13)}:00:00Z`;
+  }
+}
+
+// Aggregates for anonymized exports: groups smaller than
+// ANALYTICS_MIN_COHORT_SIZE are merged into one "other" row, with the
+// counts in `sum` added up and everything else (rates, averages) left
+// empty. If "other" is still too small it is left out.
+export function bucketSmallCohorts<T extends Record<string, unknown>>(
+  rows: T[],
+  options: { size: keyof T & string; groupBy: readonly (keyof T & string)[]; sum: readonly (keyof T & string)[] },
+  minSize: number = environmentConfig.getValue('ANALYTICS_MIN_COHORT_SIZE')
+): Record<string, unknown>[] {
+  const sizeOf = (row: T) => Number(row[options.size]) || 0;
+  const large = rows.filter(row => sizeOf(row) >= minSize);
+  const small = rows.filter(row => sizeOf(row) < minSize);
+  if (small.length === 0) return large;
+
+  const other: Record<string, unknown> = {};
+  for (const field of Object.keys(small[0])) {
+    if (options.groupBy.includes(field)) {
+      other[field] = 'other';
+    } else if (options.sum.includes(field)) {
+      other[field] = small.reduce((total, row) => total + (Number(row[field]) || 0), 0);
+    } else {
+      other[field] = null;
+    }
+  }
+
+  return (other[options.size] as number) >= minSize ? [...large, other] : large;
+}
+
+export function hasQuasiIdentifiers(kind: AnonymizedRecordKind): boolean {
+  return !!QUASI_IDENTIFIERS[kind];
+}
+
+// bucketSmallCohorts for anonymized rows: rows whose quasi-identifiers,
+// taken together, are shared by fewer than ANALYTICS_MIN_COHORT_SIZE rows
+// get "other" in all of them. If fewer rows than that end up as "other",
+// they are left out. Needs the whole export, not one row at a time.
+export function bucketSmallCohortRows(
+  kind: AnonymizedRecordKind,
+  rows: Record<string, unknown>[],
+  minSize: number = environmentConfig.getValue('ANALYTICS_MIN_COHORT_SIZE')
+): Record<string, unknown>[] {
+  const fields = QUASI_IDENTIFIERS[kind];
+  if (!fields) return rows;
+
+  const keyOf = (row: Record<string, unknown>) => JSON.stringify(fields.map(field => row[field] ?? null));
+  const sizes = new Map<string, number>();
+  rows.forEach(row => sizes.set(keyOf(row), (sizes.get(keyOf(row)) || 0) + 1));
+
+  const isSmall = (row: Record<string, unknown>) => sizes.get(keyOf(row))! < minSize;
+  const small = rows.filter(isSmall).length;
+  if (small === 0) return rows;
+  if (small < minSize) return rows.filter(row => !isSmall(row));
+
+  const other = Object.fromEntries(fields.map(field => [field, 'other']));
+  return rows.map(row => isSmall(row) ? { ...row, ...other } : row);
+}
@@ -0,0 +1,167 @@
+import { Model } from 'mongoose';
+import type { ChangeStreamDocument } from 'mongodb';
+import { Message } from '../database/models/message';
+import { Chat } from '../database/models/chat';
+import { Call } from '../database/models/call';
+import { changeStreamHub, ChangeStreamHandle } from '../database/change-streams';
+import { environmentConfig } from '../config/environment';
+import { redisConfig } from '../config/redis';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { PipelineEvent, PipelinePublisher, KafkaRestPublisher, NatsPublisher } from './publishers';
+import { messageRecord, chatRecord, callRecord } from './records';
+
+const LEADER_KEY = 'cdc:leader';
+const LEADER_TTL = 30 * 1000;
+const LEADER_CHECK_INTERVAL = 10 * 1000;
+
+interface CaptureSource {
+  name: string; // topic suffix
+  entity: string; // event type prefix
+  model: Model<any>;
+  toRecord: (doc: any) => Record<string, unknown>;
+}
+
+const SOURCES: CaptureSource[] = [
+  { name: 'messages', entity: 'message', model: Message, toRecord: messageRecord },
+  { name: 'chats', entity: 'chat', model: Chat, toRecord: chatRecord },
+  { name: 'calls', entity: 'call', model: Call, toRecord: callRecord },
+];
+
+// Publishes message, chat and call changes to Kafka or NATS for external
+// pipelines, as redacted metadata records (see records.ts). Follows MongoDB
+// change streams whose resume tokens are checkpointed after the broker
+// accepts each event, so delivery is at-least-once across restarts. One
+// process publishes at a time, elected through Redis when available.
+export class ChangeDataCaptureService {
+  private redis = redisConfig.getClient();
+  private publisher: PipelinePublisher | null = null;
+  private streams: ChangeStreamHandle[] = [];
+  private leaderTimer: NodeJS.Timeout | null = null;
+  private instanceId = `${process.pid}:${Math.random().toString(36).slice(2)}`;
+  private running = false;
+  private stopped = true;
+
+  start(): void {
+    if (!this.stopped) return;
+
+    this.publisher = this.createPublisher();
+    if (!this.publisher) return;
+    this.stopped = false;
+
+    this.leaderTimer = setInterval(() => this.checkLeadership(), LEADER_CHECK_INTERVAL);
+    this.leaderTimer.unref();
+    this.checkLeadership();
+  }
+
+  async stop(): Promise<void> {
+    this.stopped = true;
+    if (this.leaderTimer) {
+      clearInterval(this.leaderTimer);
+      this.leaderTimer = null;
+    }
+    await this.closeStreams();
+    if (this.redis && (await this.redis.get(LEADER_KEY)) === this.instanceId) {
+      await this.redis.del(LEADER_KEY);
+    }
+    await this.publisher?.close();
+  }
+
+  private createPublisher(): PipelinePublisher | null {
+    const env = environmentConfig.get();
+
+    if (env.CDC_PUBLISHER === 'kafka' && env.CDC_KAFKA_REST_URL) {
+      return new KafkaRestPublisher(env.CDC_KAFKA_REST_URL);
+    }
+    if (env.CDC_PUBLISHER === 'nats' && env.CDC_NATS_URL) {
+      return new NatsPublisher(env.CDC_NATS_URL);
+    }
+    if (env.CDC_PUBLISHER !== 'none') {
+      logger.warn('Change data capture publisher is not configured', { publisher: env.CDC_PUBLISHER });
+    }
+    return null;
+  }
+
+  // Take or keep leadership and start or stop streams to match
+  private async checkLeadership(): Promise<void> {
+    try {
+      const leader = await this.holdLeadership();
+      if (leader && !this.running) {
+        await this.openStreams();
+      } else if (!leader && this.running) {
+        logger.info('Change data capture leadership lost');
+        await this.closeStreams();
+      }
+    } catch (error) {
+      logger.error('Change data capture leadership check failed', error);
+    }
+  }
+
+  private async holdLeadership(): Promise<boolean> {
+    if (!this.redis) return true;
+
+    const acquired = await this.redis.set(LEADER_KEY, this.instanceId, 'PX', LEADER_TTL, 'NX');
+    if (acquired) return true;
+
+    if ((await this.redis.get(LEADER_KEY)) === this.instanceId) {
+      await this.redis.pexpire(LEADER_KEY, LEADER_TTL);
+      return true;
+    }
+    return false;
+  }
+
+  private async openStreams(): Promise<void> {
+    this.running = true;
+    logger.info('Change data capture started', { publisher: this.publisher?.name });
+
+    const topicPrefix = environmentConfig.get().CDC_TOPIC_PREFIX;
+    for (const source of SOURCES) {
+      this.streams.push(changeStreamHub.watch({
+        name: `cdc-${source.name}`,
+        model: source.model,
+        operations: ['insert', 'update', 'replace', 'delete'],
+        resumable: true,
+        // A failed publish fails the stream, which resumes from the last
+        // published change
+        handler: async change => {
+          const event = this.toEvent(source, change);
+          if (!event) return;
+          await this.publisher!.publish(`${topicPrefix}.${source.name}`, [event]);
+          metricsCollector.incrementCounter('cdc_events_published', 1, { source: source.name });
+        },
+      }));
+    }
+  }
+
+  private async closeStreams(): Promise<void> {
+    this.running = false;
+    const streams = this.streams.splice(0);
+    await Promise.all(streams.map(stream => stream.close()));
+  }
+
+  private toEvent(source: CaptureSource, change: ChangeStreamDocument): PipelineEvent | null {
+    if (!('documentKey' in change)) return null;
+
+    const entityId = change.documentKey._id.toString();
+    const occurredAt = (change.wallTime ?? new Date()).toISOString();
+    const id = (change._id as { _data: string })._data;
+
+    if (change.operationType === 'delete') {
+      return { id, type: `${source.entity}.deleted`, entityId, occurredAt, data: null };
+    }
+
+    // The document was deleted before the update could be looked up
+    const fullDocument = 'fullDocument' in change ? change.fullDocument : undefined;
+    if (!fullDocument) return null;
+
+    return {
+      id,
+      type: `${source.entity}.${change.operationType === 'insert' ? 'created' : 'updated'}`,
+      entityId,
+      occurredAt,
+      data: source.toRecord(fullDocument),
+    };
+  }
+}
+
+export const changeDataCaptureService = new ChangeDataCaptureService();
@@ -0,0 +1,132 @@
+import net from 'net';
+import { logger } from '../monitoring/logging';
+
+export interface PipelineEvent {
+  id: string; // stable per change, for consumers to drop duplicates
+  type: string; // e.g. "message.created"
+  entityId: string;
+  occurredAt: string;
+  data: Record<string, unknown> | null; // null for deletions
+}
+
+// Where change events go. publish() resolves once the broker has accepted the
+// events, so the caller can move its checkpoint past them.
+export interface PipelinePublisher {
+  readonly name: string;
+  publish(topic: string, events: PipelineEvent[]): Promise<void>;
+  close(): Promise<void>;
+}
+
+// Kafka through a Kafka REST Proxy (v2 API), keyed by entity so events for
+// one message, chat or call stay in order on a partition
+export class KafkaRestPublisher implements PipelinePublisher {
+  readonly name = 'kafka';
+
+  constructor(private baseUrl: string) {}
+
+  async publish(topic: string, events: PipelineEvent[]): Promise<void> {
+    const response = await fetch(`${this.baseUrl.replace(/\/$/, '')}/topics/${encodeURIComponent(topic)}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/vnd.kafka.json.v2+json',
+        Accept: 'application/vnd.kafka.v2+json',
+      },
+      body: JSON.stringify({
+        records: events.map(event => ({ key: event.entityId, value: event })),
+      }),
+      signal: AbortSignal.timeout(10000),
+    });
+
+    if (!response.ok) {
+      throw new Error(`Kafka REST proxy responded ${response.status}: ${await response.text()}`);
+    }
+  }
+
+  async close(): Promise<void> {}
+}
+
+// Core NATS over a plain TCP connection. Publishing is fire-and-forget in
+// NATS, so each batch ends with a PING and is accepted once the PONG arrives.
+export class NatsPublisher implements PipelinePublisher {
+  readonly name = 'nats';
+  private socket: net.Socket | null = null;
+  private connecting: Promise<net.Socket> | null = null;
+  private pendingPongs: { resolve: () => void; reject: (error: Error) => void }[] = [];
+  private buffer = '';
+
+  constructor(private url: string) {}
+
+  async publish(topic: string, events: PipelineEvent[]): Promise<void> {
+    const socket = await this.connect();
+    const frames = events.map(event => {
+      const payload = Buffer.from(JSON.stringify(event));
+      return Buffer.concat([Buffer.from(`PUB ${topic} ${payload.length}\r\n`), payload, Buffer.from('\r\n')]);
+    });
+
+    await new Promise<void>((resolve, reject) => {
+      this.pendingPongs.push({ resolve, reject });
+      socket.write(Buffer.concat([...frames, Buffer.from('PING\r\n')]));
+    });
+  }
+
+  async close(): Promise<void> {
+    this.socket?.end();
+    this.socket = null;
+  }
+
+  private connect(): Promise<net.Socket> {
+    if (this.socket && !this.socket.destroyed) return Promise.resolve(this.socket);
+    if (this.connecting) return this.connecting;
+
+    const target = new URL(this.url.includes('://') ? this.url : `nats://${this.url}`);
+    const options = {
+      verbose: false,
+      pedantic: false,
+      name: 'bro-cdc',
+      ...(target.username && { user: decodeURIComponent(target.username), pass: decodeURIComponent(target.password) }),
+    };
+
+    this.connecting = new Promise<net.Socket>((resolve, reject) => {
+      const socket = net.connect(Number(target.port) || 4222, target.hostname);
+      let ready = false;
+
+      socket.setEncoding('utf8');
+      socket.on('data', (chunk: string) => {
+        this.buffer += chunk;
+        let index: number;
+        while ((index = this.buffer.indexOf('\r\n')) !== -1) {
+          const line = this.buffer.slice(0, index);
+          this.buffer = this.buffer.slice(index + 2);
+
+          if (line.startsWith('INFO') && !ready) {
+            ready = true;
+            socket.write(`CONNECT ${JSON.stringify(options)}\r\n`);
+            this.socket = socket;
+            resolve(socket);
+          } else if (line === 'PING') {
+            socket.write('PONG\r\n');
+          } else if (line === 'PONG') {
+            this.pendingPongs.shift()?.resolve();
+          } else if (line.startsWith('-ERR')) {
+            logger.error('NATS error', new Error(line));
+            socket.destroy(new Error(line));
+          }
+        }
+      });
+
+      socket.on('error', error => {
+        if (!ready) reject(error);
+      });
+      socket.on('close', () => {
+        const error = new Error('NATS connection closed');
+        this.pendingPongs.splice(0).forEach(pending => pending.reject(error));
+        this.socket = null;
+        this.buffer = '';
+      });
+    }).finally(() => {
+      this.connecting = null;
+    });
+
+    return this.connecting;
+  }
+}
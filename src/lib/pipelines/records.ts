@@ -0,0 +1,73 @@
+import { IMessage } from '../database/models/message';
+import { IChat } from '../database/models/chat';
+import { ICall } from '../database/models/call';
+
+// Records handed to external data pipelines. They carry metadata only:
+// message text, locations, contacts, group names and call signaling (SDP,
+// ICE candidates and key material for encrypted calls) never leave the
+// server, nor does the sender of a message posted under a group pseudonym.
+// Accepts hydrated documents, lean objects and change stream documents
+// alike.
+
+const id = (value: any): string | undefined => value?.toString();
+
+export function messageRecord(message: IMessage) {
+  return {
+    id: id(message._id)!,
+    chatId: id(message.chatId),
+    senderId: message.pseudonym ? undefined : id(message.senderId),
+    pseudonymId: id(message.pseudonym?.id),
+    type: message.type,
+    contentLength: message.content?.length ?? 0,
+    mediaId: id(message.media),
+    replyTo: id(message.replyTo),
+    forwardedFrom: id(message.forwardedFrom),
+    isEdited: !!message.isEdited,
+    isDeleted: !!message.isDeleted,
+    isImported: !!message.isImported,
+    moderationState: message.moderation?.state,
+    mentionCount: message.metadata?.mentions?.length ?? 0,
+    linkCount: message.metadata?.links?.length ?? 0,
+    reactionCount: message.reactions?.length ?? 0,
+    deliveredCount: message.deliveredTo?.length ?? 0,
+    readCount: message.readBy?.length ?? 0,
+    via: message.metadata?.webhook ? 'webhook' : message.metadata?.command ? 'command' : undefined,
+    createdAt: message.createdAt,
+    editedAt: message.editedAt,
+    deletedAt: message.deletedAt,
+  };
+}
+
+export function chatRecord(chat: IChat) {
+  return {
+    id: id(chat._id)!,
+    type: chat.type,
+    participantCount: chat.participants?.length ?? 0,
+    adminCount: chat.groupInfo?.admins?.length,
+    lastActivity: chat.lastActivity,
+    hasActiveCall: !!chat.activeCall?.callId,
+    createdAt: chat.createdAt,
+    updatedAt: chat.updatedAt,
+  };
+}
+
+export function callRecord(call: ICall) {
+  return {
+    id: id(call._id)!,
+    callId: call.callId,
+    chatId: id(call.chatId),
+    initiator: id(call.initiator),
+    participantCount: call.participants?.length ?? 0,
+    type: call.type,
+    status: call.status,
+    isGroupCall: !!call.isGroupCall,
+    encrypted: !!call.encrypted,
+    duration: call.duration,
+    endReason: call.endReason,
+    averageRating: call.quality?.length
+      ? call.quality.reduce((sum, entry) => sum + entry.rating, 0) / call.quality.length
+      : undefined,
+    startTime: call.startTime,
+    endTime: call.endTime,
+  };
+}
@@ -0,0 +1,101 @@
+import { Server as SocketIOServer } from 'socket.io';
+import { AuthenticatedSocket } from './socket';
+import { isDroppableEvent } from './protocol';
+import { SEND_QUEUE_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Encoded Socket.IO event packets: packet type, optional namespace and ack
+// id, then the JSON array whose first item is the event name
+const EVENT_NAME = /^\d+(?:\/[^,]*,)?\d*\["([^"]+)"/;
+
+interface EnginePacket {
+  type: string;
+  data?: unknown;
+}
+
+export interface SendQueueEvent {
+  type: 'dropped' | 'disconnected';
+  count: number;
+}
+
+// Backpressure for slow clients. Every connection has a send buffer, the
+// engine's writeBuffer, which grows while the client reads slower than the
+// hub writes to it. Past SOFT_LIMIT the oldest presence and typing updates
+// are dropped from it, newer ones supersede them anyway; messages and other
+// events are never discarded, so a connection still past HARD_LIMIT is
+// dropped instead. Its client reconnects and catches up through its offline
+// queue (realtime/delivery) and message seqs.
+export class SendQueueMonitor {
+  attach(socket: AuthenticatedSocket, onEvent: (event: SendQueueEvent) => void): void {
+    socket.onAnyOutgoing(() => this.check(socket, onEvent));
+  }
+
+  // Packets waiting in a connection's send buffer
+  depth(socket: { conn: unknown }): number {
+    return ((socket.conn as { writeBuffer?: EnginePacket[] }).writeBuffer || []).length;
+  }
+
+  // Deepest send buffer among this server's connections
+  maxDepth(io: SocketIOServer): number {
+    let max = 0;
+    for (const socket of io.of('/').sockets.values()) {
+      max = Math.max(max, this.depth(socket));
+    }
+    return max;
+  }
+
+  private check(socket: AuthenticatedSocket, onEvent: (event: SendQueueEvent) => void): void {
+    const depth = this.depth(socket);
+    if (depth < SEND_QUEUE_CONSTANTS.SOFT_LIMIT) return;
+
+    metricsCollector.recordHistogram('hub_send_queue_depth', depth);
+
+    const dropped = this.dropOldest(socket, depth - SEND_QUEUE_CONSTANTS.SOFT_LIMIT + 1);
+    if (dropped > 0) {
+      onEvent({ type: 'dropped', count: dropped });
+    }
+
+    if (this.depth(socket) >= SEND_QUEUE_CONSTANTS.HARD_LIMIT) {
+      logger.warn('Disconnecting slow socket', {
+        userId: socket.userId,
+        deviceId: socket.deviceId,
+        depth: this.depth(socket),
+      });
+      onEvent({ type: 'disconnected', count: 1 });
+      socket.disconnect(true);
+    }
+  }
+
+  // Remove up to count droppable packets from the front of the buffer
+  private dropOldest(socket: AuthenticatedSocket, count: number): number {
+    const buffer = (socket.conn as unknown as { writeBuffer?: EnginePacket[] }).writeBuffer;
+    if (!buffer) return 0;
+
+    let dropped = 0;
+    for (let index = 0; index < buffer.length && dropped < count;) {
+      const event = this.eventOf(buffer[index]);
+      if (event && isDroppableEvent(event)) {
+        buffer.splice(index, 1);
+        dropped++;
+        metricsCollector.incrementCounter('hub_send_queue_drops', 1, { event });
+      } else {
+        index++;
+      }
+    }
+    return dropped;
+  }
+
+  private eventOf(packet: EnginePacket): string | null {
+    if (packet.type !== 'message') return null;
+    return encodedEventName(packet.data);
+  }
+}
+
+// The event name of an encoded Socket.IO packet, null for other packets
+export function encodedEventName(data: unknown): string | null {
+  if (typeof data !== 'string') return null;
+  return EVENT_NAME.exec(data)?.[1] || null;
+}
+
+export const sendQueueMonitor = new SendQueueMonitor();
@@ -0,0 +1,159 @@
+import { redisConfig } from '../config/redis';
+import { DELIVERY_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { AuthenticatedSocket } from './socket';
+import { clientEvents, DeliveryMeta, isReliableEvent } from './protocol';
+
+interface PendingEvent {
+  event: string;
+  payload: unknown;
+  meta: DeliveryMeta;
+  attempts: number;
+  timer?: NodeJS.Timeout;
+}
+
+// At-least-once delivery of reliable events (new messages, incoming calls)
+// to sockets that opt in with auth.acks. Every reliable event carries a seq
+// after its payload; the client answers event:ack, and events not
+// acknowledged in time, or answered with ok: false, are sent again. After
+// the last attempt, and for events still pending when the socket
+// disconnects, the event goes to the device's offline queue and is replayed
+// when it reconnects. Clients drop events whose seq they already handled.
+//
+// Tracking is per socket and local to this server: Socket.IO reports every
+// packet leaving a socket, room broadcasts included, to onAnyOutgoing.
+export class DeliveryTracker {
+  private redis = redisConfig.getClient();
+
+  // Start tracking a new connection and replay what it missed
+  async attach(socket: AuthenticatedSocket): Promise<void> {
+    if (!socket.handshake.auth?.acks) return;
+
+    const events = new Map<string, PendingEvent>(); // by seq
+
+    socket.onAnyOutgoing((event: string, payload: unknown, meta?: DeliveryMeta) => {
+      if (isReliableEvent(event) && meta?.seq) {
+        this.track(socket, events, event, payload, meta);
+      }
+    });
+
+    socket.on('event:ack', (data) => {
+      const parsed = clientEvents['event:ack'].schema.safeParse(data);
+      if (parsed.success) {
+        this.acknowledge(socket, events, parsed.data.seq, parsed.data.ok);
+      }
+    });
+
+    socket.on('disconnect', () => {
+      const unacknowledged = Array.from(events.values());
+      unacknowledged.forEach(entry => clearTimeout(entry.timer));
+      events.clear();
+      if (unacknowledged.length > 0) {
+        this.park(socket, unacknowledged).catch(error => {
+          logger.error('Failed to queue unacknowledged events', error, { userId: socket.userId });
+        });
+      }
+    });
+
+    await this.replay(socket);
+  }
+
+  private track(socket: AuthenticatedSocket, events: Map<string, PendingEvent>, event: string, payload: unknown, meta: DeliveryMeta) {
+    const existing = events.get(meta.seq);
+    if (existing) {
+      // A resend of an event already tracked
+      clearTimeout(existing.timer);
+      existing.timer = this.startTimer(socket, events, existing);
+      return;
+    }
+
+    if (events.size >= DELIVERY_CONSTANTS.MAX_PENDING_PER_SOCKET) {
+      metricsCollector.incrementCounter('socket_delivery_overflow', 1, { event });
+      return;
+    }
+
+    const entry: PendingEvent = { event, payload, meta, attempts: meta.attempt || 1 };
+    entry.timer = this.startTimer(socket, events, entry);
+    events.set(meta.seq, entry);
+  }
+
+  private acknowledge(socket: AuthenticatedSocket, events: Map<string, PendingEvent>, seq: string, ok: boolean) {
+    const entry = events.get(seq);
+    if (!entry) return;
+
+    if (ok) {
+      clearTimeout(entry.timer);
+      events.delete(seq);
+      metricsCollector.incrementCounter('socket_events_acked', 1, { event: entry.event });
+      return;
+    }
+
+    // NACK: the client couldn't handle it, send it again right away
+    clearTimeout(entry.timer);
+    this.retry(socket, events, entry, 'nack');
+  }
+
+  private startTimer(socket: AuthenticatedSocket, events: Map<string, PendingEvent>, entry: PendingEvent): NodeJS.Timeout {
+    return setTimeout(() => this.retry(socket, events, entry, 'timeout'), DELIVERY_CONSTANTS.ACK_TIMEOUT);
+  }
+
+  private retry(socket: AuthenticatedSocket, events: Map<string, PendingEvent>, entry: PendingEvent, reason: 'nack' | 'timeout') {
+    if (entry.attempts >= DELIVERY_CONSTANTS.MAX_ATTEMPTS || !socket.connected) {
+      events.delete(entry.meta.seq);
+      this.park(socket, [entry]).catch(error => {
+        logger.error('Failed to queue undelivered event', error, { userId: socket.userId, event: entry.event });
+      });
+      return;
+    }
+
+    entry.attempts += 1;
+    metricsCollector.incrementCounter('socket_events_resent', 1, { event: entry.event, reason });
+    socket.emit(entry.event, entry.payload, { ...entry.meta, attempt: entry.attempts });
+  }
+
+  // Keep events for the device to receive when it reconnects
+  private async park(socket: AuthenticatedSocket, entries: PendingEvent[]): Promise<void> {
+    const key = this.queueKey(socket);
+    if (!key || !this.redis) {
+      metricsCollector.incrementCounter('socket_events_dropped', entries.length);
+      return;
+    }
+
+    await this.redis
+      .pipeline()
+      .rpush(key, ...entries.map(entry => JSON.stringify({ event: entry.event, payload: entry.payload, seq: entry.meta.seq, eventId: entry.meta.eventId })))
+      .ltrim(key, -DELIVERY_CONSTANTS.OFFLINE_QUEUE_LIMIT, -1)
+      .expire(key, DELIVERY_CONSTANTS.OFFLINE_QUEUE_TTL)
+      .exec();
+
+    metricsCollector.incrementCounter('socket_events_queued', entries.length);
+    logger.debug('Queued unacknowledged events', { userId: socket.userId, deviceId: socket.deviceId, count: entries.length });
+  }
+
+  private async replay(socket: AuthenticatedSocket): Promise<void> {
+    const key = this.queueKey(socket);
+    if (!key || !this.redis) return;
+
+    try {
+      const results = await this.redis.multi().lrange(key, 0, -1).del(key).exec();
+      const queued = (results?.[0]?.[1] as string[] | undefined) || [];
+      for (const item of queued) {
+        const { event, payload, seq, eventId } = JSON.parse(item);
+        socket.emit(event, payload, { seq, attempt: 1, ...(eventId && { eventId }) });
+      }
+      if (queued.length > 0) {
+        metricsCollector.incrementCounter('socket_events_replayed', queued.length);
+      }
+    } catch (error) {
+      logger.error('Failed to replay queued events', error, { userId: socket.userId, deviceId: socket.deviceId });
+    }
+  }
+
+  // Queued per device, so each device of the user gets what it missed
+  private queueKey(socket: AuthenticatedSocket): string | null {
+    return socket.deviceId ? `socket:undelivered:${socket.userId}:${socket.deviceId}` : null;
+  }
+}
+
+export const deliveryTracker = new DeliveryTracker();
@@ -0,0 +1,76 @@
+import { Server as SocketIOServer } from 'socket.io';
+import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
+import { liveBroadcastService, broadcastRoom, serializeBroadcast } from '../../webrtc/broadcasts';
+import { CallControlError } from '../../webrtc/signaling';
+
+export function registerBroadcastEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
+  // Broadcasts this socket watches: broadcastId -> callId
+  const watching = new Map<string, string>();
+
+  // Start watching. Viewers only receive; the media comes from playbackUrl.
+  socket.on('broadcast:watch', async (data) => {
+    try {
+      const { watchCode } = data || {};
+      if (typeof watchCode !== 'string') {
+        return emitEvent(socket, 'broadcast:error', { message: 'Broadcast not found' });
+      }
+
+      const broadcast = await liveBroadcastService.resolve(watchCode, socket.userId);
+      if (broadcast.status !== 'live') {
+        return emitEvent(socket, 'broadcast:error', { message: 'Broadcast has ended' });
+      }
+
+      const broadcastId = broadcast._id.toString();
+      emitEvent(socket, 'broadcast:watching', { broadcast: serializeBroadcast(broadcast) });
+      if (watching.has(broadcastId)) return;
+
+      socket.join(broadcastRoom(broadcastId));
+      watching.set(broadcastId, broadcast.callId);
+      await liveBroadcastService.viewerJoined(broadcast, {
+        userId: socket.userId,
+        displayName: socket.user.displayName,
+        avatar: socket.user.avatar,
+      });
+
+    } catch (error) {
+      if (error instanceof CallControlError) {
+        return emitEvent(socket, 'broadcast:error', { message: error.message });
+      }
+      console.error('Error watching broadcast:', error);
+      emitEvent(socket, 'broadcast:error', { message: 'Failed to watch broadcast' });
+    }
+  });
+
+  // Stop watching
+  socket.on('broadcast:unwatch', async (data) => {
+    try {
+      const { broadcastId } = data || {};
+      const callId = watching.get(broadcastId);
+      if (!callId) return;
+
+      watching.delete(broadcastId);
+      if (!socket.rooms.has(broadcastRoom(broadcastId))) return; // the broadcast ended
+      socket.leave(broadcastRoom(broadcastId));
+      await liveBroadcastService.viewerLeft(broadcastId, callId, socket.userId);
+
+    } catch (error) {
+      console.error('Error unwatching broadcast:', error);
+    }
+  });
+
+  // Closing the connection stops watching. Sockets were already taken out
+  // of the rooms of broadcasts that ended.
+  socket.on('disconnecting', async () => {
+    const left = Array.from(watching).filter(([broadcastId]) => socket.rooms.has(broadcastRoom(broadcastId)));
+    watching.clear();
+
+    for (const [broadcastId, callId] of left) {
+      try {
+        await liveBroadcastService.viewerLeft(broadcastId, callId, socket.userId);
+      } catch (error) {
+        console.error('Error leaving broadcast on disconnect:', error);
+      }
+    }
+  });
+}
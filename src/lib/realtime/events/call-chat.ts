@@ -0,0 +1,65 @@
+import { Server as SocketIOServer } from 'socket.io';
+import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
+import { callChatService } from '../../webrtc/call-chat';
+import { webrtcSignalingService, CallControlError } from '../../webrtc/signaling';
+import { callChatMessageSchema, callChatReactionSchema } from '../../database/schemas/call';
+
+function handleError(socket: AuthenticatedSocket, error: unknown, fallback: string): void {
+  if (error instanceof CallControlError) {
+    return emitEvent(socket, 'call:error', { message: error.message });
+  }
+  console.error(`${fallback}:`, error);
+  emitEvent(socket, 'call:error', { message: fallback });
+}
+
+export function registerCallChatEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
+  // In-call chat message
+  socket.on('call:chat:send', async (data) => {
+    try {
+      const validationResult = callChatMessageSchema.safeParse(data);
+      if (!validationResult.success) {
+        return emitEvent(socket, 'call:error', { message: 'Invalid chat message' });
+      }
+
+      const { callId, text, clientId } = validationResult.data;
+      await callChatService.send(callId, socket.userId, text, clientId);
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to send chat message');
+    }
+  });
+
+  // Emoji reaction to a message or to the call
+  socket.on('call:chat:react', async (data) => {
+    try {
+      const validationResult = callChatReactionSchema.safeParse(data);
+      if (!validationResult.success) {
+        return emitEvent(socket, 'call:error', { message: 'Invalid reaction' });
+      }
+
+      await callChatService.react(validationResult.data.callId, socket.userId, validationResult.data);
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to send reaction');
+    }
+  });
+
+  // Raise or lower your hand; the host can lower someone else's
+  socket.on('call:hand', async (data) => {
+    try {
+      const { callId, raised, userId } = data || {};
+      if (typeof callId !== 'string' || typeof raised !== 'boolean') return;
+
+      await webrtcSignalingService.setHandRaised(
+        callId,
+        socket.userId,
+        typeof userId === 'string' ? userId : socket.userId,
+        raised
+      );
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to change raised hand');
+    }
+  });
+}
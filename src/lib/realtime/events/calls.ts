@@ -1,21 +1,56 @@
 import { Server as SocketIOServer } from 'socket.io';
 import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
 import { CallRepository } from '../../database/repositories/call';
 import { ChatRepository } from '../../database/repositories/chat';
-import { socketManager } from '../socket';
+import { UserRepository } from '../../database/repositories/user';
+import { callRingingService } from '../../webrtc/ringing';
+import { webrtcSignalingService, CallControlError } from '../../webrtc/signaling';
+import { captionService } from '../../webrtc/captions';
+import { callQualityMonitor } from '../../webrtc/quality';
+import { activeCallService } from '../../webrtc/active-calls';
+import { liveBroadcastService } from '../../webrtc/broadcasts';
+import { callChatService } from '../../webrtc/call-chat';
+import { callRatingService } from '../../webrtc/call-ratings';
+import { callEventLog } from '../../webrtc/call-events';
+import { distributedLock } from '../../database/locks';
+import { getBitrateLimits, limitSessionDescription, resolveMediaConstraints } from '../../webrtc/media-constraints';
+import { lowDataMode } from '../low-data';
+import { describeCallEncryption, resolveCallEncryption } from '../../webrtc/e2ee';
+import { callKeyPacketSchema, callQualitySchema, callQualityReportSchema } from '../../database/schemas/call';
+import { moderationService } from '../../moderation/actions';
+import { guestCan } from '../../auth/guest-access';
 
 const callRepository = new CallRepository();
 const chatRepository = new ChatRepository();
+const userRepository = new UserRepository();
+
+// findByCallId populates users, so accept either form
+function refId(ref: any): string {
+  return (ref._id || ref).toString();
+}
 
 export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
   // Initiate call
   socket.on('call:initiate', async (data) => {
     try {
       const { participantId, type, chatId } = data; // type: 'voice' | 'video'
+      const encrypted = resolveCallEncryption(data.encrypted);
+
+      if (await moderationService.getRestriction(socket.userId)) {
+        return emitEvent(socket, 'call:error', { message: 'Calling is not available for your account' });
+      }
+      if (socket.guest && !socket.guest.calls) {
+        return emitEvent(socket, 'call:error', { message: 'Guests can\'t make calls in this chat' });
+      }
 
-      // Check if participant is online
-      if (!socketManager.isUserOnline(participantId)) {
-        return socket.emit('call:error', { message: 'User is offline' });
+      // Offline participants are still rung through VoIP / push
+      const participant = await userRepository.findById(participantId);
+      if (!participant) {
+        return emitEvent(socket, 'call:error', { message: 'User not found' });
+      }
+      if (!guestCan(participant, 'calls')) {
+        return emitEvent(socket, 'call:error', { message: 'This guest can\'t take calls' });
       }
 
       // Generate unique call ID
@@ -30,33 +65,42 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
         status: 'initiated',
         chatId: chatId || undefined,
         isGroupCall: false,
+        encrypted,
       });
 
       // Join call room
       socket.join(`call:${callId}`);
+      await activeCallService.started({ callId, chatId, type, initiatorId: socket.userId });
+      callEventLog.record(callId, 'initiated', {
+        userId: socket.userId,
+        data: { type, participants: [participantId], chatId, encrypted },
+      });
 
-      // Emit to initiator
-      socket.emit('call:initiated', {
+      // Emit to initiator, with the audio processing and bitrate settings
+      // negotiated for them (and their device's data mode)
+      const initiator = await userRepository.findById(socket.userId);
+      emitEvent(socket, 'call:initiated', {
         callId,
         type,
         participant: participantId,
+        media: resolveMediaConstraints(initiator, undefined, lowDataMode.isOn(socket)),
+        encryption: describeCallEncryption(encrypted),
       });
 
-      // Emit incoming call to participant
-      socketManager.emitToUser(participantId, 'call:incoming', {
+      // Ring the participant's devices, over the socket or by push
+      await callRingingService.ring(participant, {
         callId,
-        type,
-        initiator: {
-          userId: socket.userId,
-          displayName: socket.user.displayName,
-          avatar: socket.user.avatar,
-        },
+        callType: type,
+        callerId: socket.userId,
+        callerName: socket.user.displayName,
+        callerAvatar: socket.user.avatar,
         chatId,
+        encrypted,
       });
 
     } catch (error) {
       console.error('Error initiating call:', error);
-      socket.emit('call:error', { message: 'Failed to initiate call' });
+      emitEvent(socket, 'call:error', { message: 'Failed to initiate call' });
     }
   });
 
@@ -67,25 +111,32 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
 
       // Get call details
       const call = await callRepository.findByCallId(callId);
-      if (!call || !call.participants.includes(socket.userId as any)) {
-        return socket.emit('call:error', { message: 'Call not found or unauthorized' });
+      if (!call || !call.participants.some(participant => refId(participant) === socket.userId)) {
+        return emitEvent(socket, 'call:error', { message: 'Call not found or unauthorized' });
+      }
+
+      // First device to pick up wins; the others stop ringing
+      if (!(await callRingingService.settle(callId, socket, 'answered'))) {
+        return emitEvent(socket, 'call:error', { message: 'Call is no longer ringing' });
       }
 
       // Update call status
       await callRepository.update(call._id, { status: 'answered' });
+      await activeCallService.joined(callId, socket.userId);
+      callEventLog.record(callId, 'answered', { userId: socket.userId, data: { deviceId: socket.deviceId } });
 
       // Join call room
       socket.join(`call:${callId}`);
 
       // Notify all participants
-      io.to(`call:${callId}`).emit('call:answered', {
+      emitEvent(io.to(`call:${callId}`), 'call:answered', {
         callId,
         answeredBy: socket.userId,
       });
 
     } catch (error) {
       console.error('Error answering call:', error);
-      socket.emit('call:error', { message: 'Failed to answer call' });
+      emitEvent(socket, 'call:error', { message: 'Failed to answer call' });
     }
   });
 
@@ -94,11 +145,23 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
     try {
       const { callId } = data;
 
+      // A decline only counts while the call is still ringing here
+      if (!(await callRingingService.settle(callId, socket, 'declined'))) {
+        return emitEvent(socket, 'call:error', { message: 'Call is no longer ringing' });
+      }
+
       // End call with rejected status
       await callRepository.endCall(callId, 'rejected');
+      webrtcSignalingService.discardSession(callId);
+      captionService.stopCall(callId);
+      callQualityMonitor.stopCall(callId);
+      await activeCallService.ended(callId);
+      await liveBroadcastService.callEnded(callId);
+      await callChatService.callEnded(callId);
+      callEventLog.record(callId, 'rejected', { userId: socket.userId });
 
       // Notify all participants
-      io.to(`call:${callId}`).emit('call:rejected', {
+      emitEvent(io.to(`call:${callId}`), 'call:rejected', {
         callId,
         rejectedBy: socket.userId,
       });
@@ -108,7 +171,7 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
 
     } catch (error) {
       console.error('Error rejecting call:', error);
-      socket.emit('call:error', { message: 'Failed to reject call' });
+      emitEvent(socket, 'call:error', { message: 'Failed to reject call' });
     }
   });
 
@@ -117,21 +180,66 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
     try {
       const { callId } = data;
 
-      // End call
-      await callRepository.endCall(callId, 'ended');
-
-      // Notify all participants
-      io.to(`call:${callId}`).emit('call:ended', {
-        callId,
-        endedBy: socket.userId,
+      // End call; when both sides hang up at once, on different replicas,
+      // only the first one through the lock records and announces it
+      const ended = await distributedLock.withLock(`call:${callId}`, async () => {
+        if (!(await callRepository.endCall(callId, 'ended'))) {
+          return false;
+        }
+        await activeCallService.ended(callId);
+        await liveBroadcastService.callEnded(callId);
+        await callChatService.callEnded(callId);
+        callEventLog.record(callId, 'ended', { actorId: socket.userId, data: { endedBy: socket.userId, reason: 'normal' } });
+        return true;
       });
+      webrtcSignalingService.discardSession(callId);
+      captionService.stopCall(callId);
+      callQualityMonitor.stopCall(callId);
+
+      // Hung up before anyone answered: stop the other side ringing
+      await callRingingService.cancel(callId, 'missed');
+
+      // Notify all participants, then ask them how it went
+      if (ended) {
+        emitEvent(io.to(`call:${callId}`), 'call:ended', {
+          callId,
+          endedBy: socket.userId,
+        });
+        callRatingService.promptAfterCall(callId);
+      }
 
       // Clean up call room
       io.in(`call:${callId}`).socketsLeave(`call:${callId}`);
 
     } catch (error) {
       console.error('Error ending call:', error);
-      socket.emit('call:error', { message: 'Failed to end call' });
+      emitEvent(socket, 'call:error', { message: 'Failed to end call' });
+    }
+  });
+
+  // Back in a call after reconnecting; after a server restart, calls whose
+  // participants don't rejoin in time are ended
+  socket.on('call:rejoin', async (data) => {
+    try {
+      const { callId } = data;
+
+      const call = await callRepository.findByCallId(callId);
+      if (!call || !call.participants.some(participant => refId(participant) === socket.userId)) {
+        return emitEvent(socket, 'call:error', { message: 'Call not found or unauthorized' });
+      }
+      if (call.status !== 'answered') {
+        return emitEvent(socket, 'call:error', { message: 'Call is not in progress' });
+      }
+
+      socket.join(`call:${callId}`);
+      emitEvent(socket.to(`call:${callId}`), 'call:participant-joined', {
+        callId,
+        userId: socket.userId,
+      });
+
+    } catch (error) {
+      console.error('Error rejoining call:', error);
+      emitEvent(socket, 'call:error', { message: 'Failed to rejoin call' });
     }
   });
 
@@ -145,7 +253,7 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
       await callRepository.addIceCandidate(callId, socket.userId as any, candidate);
 
       // Forward to other participants
-      socket.to(`call:${callId}`).emit('call:ice-candidate', {
+      emitEvent(socket.to(`call:${callId}`), 'call:ice-candidate', {
         callId,
         candidate,
         from: socket.userId,
@@ -159,13 +267,16 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
   // SDP Offer
   socket.on('call:offer', async (data) => {
     try {
-      const { callId, sdp } = data;
+      const { callId } = data;
+
+      // A low-data device's SDP caps what it is sent at the lower ceilings
+      const sdp = limitSessionDescription(data.sdp, getBitrateLimits(undefined, lowDataMode.isOn(socket)));
 
       // Store offer
       await callRepository.addOffer(callId, socket.userId as any, sdp);
 
       // Forward to other participants
-      socket.to(`call:${callId}`).emit('call:offer', {
+      emitEvent(socket.to(`call:${callId}`), 'call:offer', {
         callId,
         sdp,
         from: socket.userId,
@@ -179,13 +290,14 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
   // SDP Answer
   socket.on('call:answer-sdp', async (data) => {
     try {
-      const { callId, sdp } = data;
+      const { callId } = data;
+      const sdp = limitSessionDescription(data.sdp, getBitrateLimits(undefined, lowDataMode.isOn(socket)));
 
       // Store answer
       await callRepository.addAnswer(callId, socket.userId as any, sdp);
 
       // Forward to other participants
-      socket.to(`call:${callId}`).emit('call:answer-sdp', {
+      emitEvent(socket.to(`call:${callId}`), 'call:answer-sdp', {
         callId,
         sdp,
         from: socket.userId,
@@ -196,16 +308,75 @@ export function registerCallEvents(socket: AuthenticatedSocket, io: SocketIOServ
     }
   });
 
+  // Live captions: each client uplinks its own microphone while captions
+  // are active in the call
+  socket.on('call:captions:audio', async (data) => {
+    try {
+      const { callId, audio } = data || {};
+      if (!callId || !Buffer.isBuffer(audio)) return;
+
+      await captionService.pushAudio(callId, socket.userId, audio);
+
+    } catch (error) {
+      console.error('Error handling caption audio:', error);
+    }
+  });
+
+  // End-to-end encrypted calls: hand a media key, wrapped with the chat key,
+  // to the other participants
+  socket.on('call:e2ee:key', async (data) => {
+    try {
+      const validationResult = callKeyPacketSchema.safeParse(data);
+      if (!validationResult.success) {
+        return emitEvent(socket, 'call:error', { message: 'Invalid key packet' });
+      }
+
+      await webrtcSignalingService.relayKey(validationResult.data.callId, socket.userId, validationResult.data);
+
+    } catch (error) {
+      if (error instanceof CallControlError) {
+        return emitEvent(socket, 'call:error', { message: error.message });
+      }
+      console.error('Error relaying call key:', error);
+    }
+  });
+
+  // Connection stats, sent every few seconds and on call:quality-request;
+  // poor ones may come back as a call:quality-action
+  socket.on('call:stats', async (data) => {
+    try {
+      const validationResult = callQualityReportSchema.safeParse(data);
+      if (!validationResult.success) return;
+
+      const { callId, ...report } = validationResult.data;
+      await callQualityMonitor.report(callId, socket.userId, report);
+
+    } catch (error) {
+      console.error('Error handling call stats:', error);
+    }
+  });
+
   // Call quality feedback
   socket.on('call:quality', async (data) => {
     try {
-      const { callId, rating, feedback } = data;
+      const validationResult = callQualitySchema.safeParse(data);
+      if (!validationResult.success) {
+        return emitEvent(socket, 'call:error', { message: 'Invalid call rating' });
+      }
 
-      await callRepository.addQualityRating(callId, socket.userId as any, rating, feedback);
+      const { callId, ...rating } = validationResult.data;
+      await callRatingService.submit(callId, socket.userId, rating, {
+        region: socket.clientInfo?.region,
+        platform: socket.clientInfo?.platform,
+        deviceId: socket.deviceId,
+      });
 
-      socket.emit('call:quality:saved', { callId });
+      emitEvent(socket, 'call:quality:saved', { callId });
 
     } catch (error) {
+      if (error instanceof CallControlError) {
+        return emitEvent(socket, 'call:error', { message: error.message });
+      }
       console.error('Error saving call quality:', error);
     }
   });
@@ -1,16 +1,46 @@
 import { Server as SocketIOServer } from 'socket.io';
 import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
 import { GroupRepository } from '../../database/repositories/group';
 import { socketManager } from '../socket';
+import { trustService, TrustLimitError } from '../../moderation/trust';
+import { organizationService, OrganizationError } from '../../organizations';
+import { automationService } from '../../integrations/automation';
+import { distributedLock, LockTimeoutError } from '../../database/locks';
+import { GROUP_CONSTANTS } from '../../utils/constants';
+import { VersionConflictError } from '../../database/concurrency';
 
 const groupRepository = new GroupRepository();
 
+// Membership is read and then changed, so changes to one group go through
+// its lock; replicas handling the same group would otherwise lose updates
+function withGroupLock<T>(groupId: string, fn: () => Promise<T>): Promise<T> {
+  return distributedLock.withLock(`group:${groupId}`, fn);
+}
+
+// findById populates participants, so accept either form
+function refId(ref: any): string {
+  return (ref._id || ref).toString();
+}
+
 export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
   // Create group
   socket.on('group:create', async (data) => {
     try {
       const { name, description, participants, avatar } = data;
 
+      if (socket.guest) {
+        return emitEvent(socket, 'group:create:error', { message: 'Guests can\'t create groups' });
+      }
+
+      await trustService.checkGroupCreation(socket.userId);
+
+      // Groups stay within the creator's organization
+      if (!(await organizationService.allInOrganization(socket.organizationId, participants))) {
+        return emitEvent(socket, 'group:create:error', { message: 'You can only add people from your organization' });
+      }
+      await organizationService.checkGroupQuota(socket.organizationId);
+
       // Create group
       const group = await groupRepository.createGroup({
         name,
@@ -18,6 +48,7 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
         participants,
         createdBy: socket.userId as any,
         avatar,
+        organizationId: socket.organizationId,
       });
 
       // Join creator to group room
@@ -34,11 +65,14 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
         });
       });
 
-      socket.emit('group:create:success', { group });
+      emitEvent(socket, 'group:create:success', { group });
 
     } catch (error) {
+      if (error instanceof TrustLimitError || error instanceof OrganizationError) {
+        return emitEvent(socket, 'group:create:error', { message: error.message });
+      }
       console.error('Error creating group:', error);
-      socket.emit('group:create:error', { message: 'Failed to create group' });
+      emitEvent(socket, 'group:create:error', { message: 'Failed to create group' });
     }
   });
 
@@ -50,11 +84,11 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
       // Verify group membership
       const group = await groupRepository.findById(groupId);
       if (!group || !group.participants.includes(socket.userId as any)) {
-        return socket.emit('error', { message: 'Not authorized to join this group' });
+        return emitEvent(socket, 'error', { message: 'Not authorized to join this group' });
       }
 
       socket.join(`chat:${groupId}`);
-      socket.emit('group:joined', { groupId });
+      emitEvent(socket, 'group:joined', { groupId });
 
     } catch (error) {
       console.error('Error joining group room:', error);
@@ -66,23 +100,45 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
     try {
       const { groupId, userIds } = data;
 
+      if (socket.guest) {
+        return emitEvent(socket, 'error', { message: 'Guests can\'t add members' });
+      }
+
       // Check if user is admin or has permission
       const isAdmin = await groupRepository.isUserAdmin(groupId, socket.userId as any);
       if (!isAdmin) {
         const group = await groupRepository.findById(groupId);
         if (group?.groupInfo?.settings.whoCanAddMembers === 'admins') {
-          return socket.emit('error', { message: 'Only admins can add members' });
+          return emitEvent(socket, 'error', { message: 'Only admins can add members' });
         }
       }
 
-      // Add members
-      await groupRepository.addParticipants(groupId, userIds);
+      // Members can only come from the group's organization
+      const target = await groupRepository.findById(groupId);
+      if (!(await organizationService.allInOrganization(target?.organizationId?.toString(), userIds))) {
+        return emitEvent(socket, 'error', { message: 'You can only add people from the group\'s organization' });
+      }
+
+      // Add members, within the group size limit
+      const added = await withGroupLock(groupId, async () => {
+        const group = await groupRepository.findById(groupId);
+        const memberIds = new Set((group?.participants || []).map(refId));
+        const newIds = userIds.filter((userId: string) => !memberIds.has(userId));
+        if (memberIds.size + newIds.length > GROUP_CONSTANTS.MAX_PARTICIPANTS) {
+          return false;
+        }
+        await groupRepository.addParticipants(groupId, userIds);
+        return true;
+      });
+      if (!added) {
+        return emitEvent(socket, 'error', { message: `Groups can have at most ${GROUP_CONSTANTS.MAX_PARTICIPANTS} members` });
+      }
 
       // Get updated group
       const updatedGroup = await groupRepository.findById(groupId);
 
       // Notify existing group members
-      io.to(`chat:${groupId}`).emit('group:members:added', {
+      socketManager.emitToChat(groupId, 'group:members:added', {
         groupId,
         addedUsers: userIds,
         addedBy: socket.userId,
@@ -100,9 +156,16 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
         });
       });
 
+      automationService.membersJoined(groupId, userIds).catch(error => {
+        console.error('Error running automation rules for new members:', error);
+      });
+
     } catch (error) {
+      if (error instanceof LockTimeoutError) {
+        return emitEvent(socket, 'error', { message: 'Group is busy, try again' });
+      }
       console.error('Error adding group members:', error);
-      socket.emit('error', { message: 'Failed to add members' });
+      emitEvent(socket, 'error', { message: 'Failed to add members' });
     }
   });
 
@@ -114,14 +177,14 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
       // Check if user is admin
       const isAdmin = await groupRepository.isUserAdmin(groupId, socket.userId as any);
       if (!isAdmin && socket.userId !== userId) {
-        return socket.emit('error', { message: 'Only admins can remove members' });
+        return emitEvent(socket, 'error', { message: 'Only admins can remove members' });
       }
 
       // Remove member
-      await groupRepository.removeParticipant(groupId, userId);
+      await withGroupLock(groupId, () => groupRepository.removeParticipant(groupId, userId));
 
       // Notify group members
-      io.to(`chat:${groupId}`).emit('group:member:removed', {
+      socketManager.emitToChat(groupId, 'group:member:removed', {
         groupId,
         removedUser: userId,
         removedBy: socket.userId,
@@ -138,7 +201,7 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
 
     } catch (error) {
       console.error('Error removing group member:', error);
-      socket.emit('error', { message: 'Failed to remove member' });
+      emitEvent(socket, 'error', { message: 'Failed to remove member' });
     }
   });
 
@@ -150,14 +213,24 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
       // Check if user is admin
       const isAdmin = await groupRepository.isUserAdmin(groupId, socket.userId as any);
       if (!isAdmin) {
-        return socket.emit('error', { message: 'Only admins can promote members' });
+        return emitEvent(socket, 'error', { message: 'Only admins can promote members' });
       }
 
-      // Promote user
-      await groupRepository.promoteToAdmin(groupId, userId);
+      // Promote user, within the admin limit
+      const promoted = await withGroupLock(groupId, async () => {
+        const admins = await groupRepository.getGroupAdmins(groupId);
+        if (admins.length >= GROUP_CONSTANTS.MAX_ADMINS && !admins.some(admin => refId(admin) === userId)) {
+          return false;
+        }
+        await groupRepository.promoteToAdmin(groupId, userId);
+        return true;
+      });
+      if (!promoted) {
+        return emitEvent(socket, 'error', { message: `Groups can have at most ${GROUP_CONSTANTS.MAX_ADMINS} admins` });
+      }
 
       // Notify group members
-      io.to(`chat:${groupId}`).emit('group:member:promoted', {
+      socketManager.emitToChat(groupId, 'group:member:promoted', {
         groupId,
         promotedUser: userId,
         promotedBy: socket.userId,
@@ -165,7 +238,7 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
 
     } catch (error) {
       console.error('Error promoting group member:', error);
-      socket.emit('error', { message: 'Failed to promote member' });
+      emitEvent(socket, 'error', { message: 'Failed to promote member' });
     }
   });
 
@@ -177,14 +250,25 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
       // Check if user is admin
       const isAdmin = await groupRepository.isUserAdmin(groupId, socket.userId as any);
       if (!isAdmin) {
-        return socket.emit('error', { message: 'Only admins can demote members' });
+        return emitEvent(socket, 'error', { message: 'Only admins can demote members' });
       }
 
-      // Demote admin
-      await groupRepository.demoteAdmin(groupId, userId);
+      // Demote admin; two admins demoting each other at once must not
+      // leave the group without one
+      const demoted = await withGroupLock(groupId, async () => {
+        const admins = await groupRepository.getGroupAdmins(groupId);
+        if (admins.length <= 1 && admins.some(admin => refId(admin) === userId)) {
+          return false;
+        }
+        await groupRepository.demoteAdmin(groupId, userId);
+        return true;
+      });
+      if (!demoted) {
+        return emitEvent(socket, 'error', { message: 'A group needs at least one admin' });
+      }
 
       // Notify group members
-      io.to(`chat:${groupId}`).emit('group:member:demoted', {
+      socketManager.emitToChat(groupId, 'group:member:demoted', {
         groupId,
         demotedUser: userId,
         demotedBy: socket.userId,
@@ -192,7 +276,7 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
 
     } catch (error) {
       console.error('Error demoting group member:', error);
-      socket.emit('error', { message: 'Failed to demote member' });
+      emitEvent(socket, 'error', { message: 'Failed to demote member' });
     }
   });
 
@@ -202,39 +286,39 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
       const { groupId } = data;
 
       // Leave group
-      await groupRepository.leaveGroup(groupId, socket.userId as any);
+      await withGroupLock(groupId, () => groupRepository.leaveGroup(groupId, socket.userId as any));
 
       // Leave group room
       socket.leave(`chat:${groupId}`);
 
       // Notify remaining group members
-      socket.to(`chat:${groupId}`).emit('group:member:left', {
+      socketManager.emitToChat(groupId, 'group:member:left', {
         groupId,
         leftUser: socket.userId,
         user: {
           displayName: socket.user.displayName,
         },
-      });
+      }, socket.userId);
 
-      socket.emit('group:left', { groupId });
+      emitEvent(socket, 'group:left', { groupId });
 
     } catch (error) {
       console.error('Error leaving group:', error);
-      socket.emit('error', { message: 'Failed to leave group' });
+      emitEvent(socket, 'error', { message: 'Failed to leave group' });
     }
   });
 
   // Update group info
   socket.on('group:update', async (data) => {
     try {
-      const { groupId, name, description, avatar } = data;
+      const { groupId, name, description, avatar, version } = data;
 
       // Check permissions
       const group = await groupRepository.findById(groupId);
       const isAdmin = await groupRepository.isUserAdmin(groupId, socket.userId as any);
       
       if (group?.groupInfo?.settings.whoCanEditGroupInfo === 'admins' && !isAdmin) {
-        return socket.emit('error', { message: 'Only admins can edit group info' });
+        return emitEvent(socket, 'error', { message: 'Only admins can edit group info' });
       }
 
       // Update group
@@ -242,17 +326,23 @@ export function registerGroupEvents(socket: AuthenticatedSocket, io: SocketIOSer
         name,
         description,
         avatar,
-      });
+      }, version);
 
       // Notify group members
-      io.to(`chat:${groupId}`).emit('group:updated', {
+      socketManager.emitToChat(groupId, 'group:updated', {
         group: updatedGroup,
         updatedBy: socket.userId,
       });
 
     } catch (error) {
+      if (error instanceof VersionConflictError) {
+        return emitEvent(socket, 'group:update:conflict', {
+          groupId: data?.groupId,
+          currentVersion: error.currentVersion,
+        });
+      }
       console.error('Error updating group:', error);
-      socket.emit('error', { message: 'Failed to update group' });
+      emitEvent(socket, 'error', { message: 'Failed to update group' });
     }
   });
 }
\ No newline at end of file
@@ -1,8 +1,23 @@
 import { Server as SocketIOServer } from 'socket.io';
-import { AuthenticatedSocket } from '../socket';
+import { AuthenticatedSocket, socketManager } from '../socket';
+import { emitEvent } from '../protocol';
 import { MessageRepository } from '../../database/repositories/message';
+import { IMessage, WITHHELD_MESSAGE_STATES } from '../../database/models/message';
 import { ChatRepository } from '../../database/repositories/chat';
 import { createEventRateLimit } from '../middleware/rate-limit';
+import { deliveryLatencyTracker } from '../../monitoring/delivery-latency';
+import { activityTracker } from '../../monitoring/activity';
+import { messageTaskQueue } from '../message-tasks';
+import { slashCommandService } from '../../integrations/slash-commands';
+import { moderationService } from '../../moderation/actions';
+import { trustService, TrustLimitError } from '../../moderation/trust';
+import { groupApprovalService } from '../../moderation/group-approvals';
+import { pseudonymService } from '../../security/pseudonyms';
+import { customEmojiService, CustomEmojiError } from '../../media/custom-emoji';
+import { legalHoldService } from '../../compliance/legal-holds';
+import { readPositionService } from '../../communication/read-positions';
+import { distributedLock } from '../../database/locks';
+import { logger } from '../../monitoring/logging';
 
 const messageRepository = new MessageRepository();
 const chatRepository = new ChatRepository();
@@ -11,46 +26,203 @@ const chatRepository = new ChatRepository();
 const messageRateLimit = createEventRateLimit({ maxRequests: 30, windowMs: 60000 }); // 30 messages per minute
 const reactionRateLimit = createEventRateLimit({ maxRequests: 60, windowMs: 60000 }); // 60 reactions per minute
 
+export interface SendMessageInput {
+  chatId: string;
+  content: string;
+  type?: IMessage['type'];
+  replyTo?: string;
+  mediaId?: string;
+  metadata?: Record<string, any>;
+  tempId?: string;
+  clientMessageId?: string;
+}
+
+export interface SendMessageResult {
+  message?: IMessage; // absent when a slash command replied privately or not at all
+  command?: string; // name of the slash command the text invoked
+  duplicate?: boolean; // clientMessageId matched a message already sent; nothing new was posted
+}
+
+// Persist a message and fan it out to the chat room. Shared by the socket
+// handler and the MQTT bridge; returns null if the sender isn't a participant.
+// Text starting with a slash command runs the command instead. Messages from
+// restricted users, and from members of moderated groups, are only echoed
+// back to them until approved; new-account limits throw TrustLimitError.
+// A resend with the clientMessageId of a message already posted returns
+// that message instead of posting it again.
+export async function sendChatMessage(
+  io: SocketIOServer,
+  senderId: string,
+  data: SendMessageInput,
+  ingressAt: number = Date.now(),
+  clientInfo?: { platform: string; region: string }
+): Promise<SendMessageResult | null> {
+  if (!data.clientMessageId) {
+    return await postChatMessage(io, senderId, data, ingressAt, clientInfo);
+  }
+
+  // Clients resend when an acknowledgement is lost, possibly over another
+  // connection at the same moment
+  return await distributedLock.withLock(`message:client:${senderId}:${data.clientMessageId}`, async () => {
+    const existing = await messageRepository.findByClientMessageId(data.chatId, senderId, data.clientMessageId!);
+    if (existing) {
+      return { message: existing, duplicate: true };
+    }
+    return await postChatMessage(io, senderId, data, ingressAt, clientInfo);
+  });
+}
+
+async function postChatMessage(
+  io: SocketIOServer,
+  senderId: string,
+  data: SendMessageInput,
+  ingressAt: number,
+  clientInfo?: { platform: string; region: string }
+): Promise<SendMessageResult | null> {
+  const { chatId, replyTo, clientMessageId } = data;
+  let { content, type = 'text', mediaId } = data;
+
+  // Validate chat membership
+  const chat = await chatRepository.findById(chatId);
+  if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === senderId)) {
+    return null;
+  }
+
+  // Command-owned metadata can only be set by the commands themselves
+  const { command: _command, poll: _poll, reminder: _reminder, webhook: _webhook, ...clientMetadata } = data.metadata || {};
+  let metadata: Record<string, any> | undefined = data.metadata ? clientMetadata : undefined;
+
+  // Restricted senders, and members whose messages wait for the group's
+  // admins, don't get commands that could post on their behalf
+  const restriction = await moderationService.getRestriction(senderId);
+  const heldForGroup = !restriction && groupApprovalService.isHeld(chat, senderId);
+  const invocation = type === 'text' && !restriction && !heldForGroup ? slashCommandService.parse(content) : null;
+  if (invocation) {
+    const result = await slashCommandService.execute(invocation, {
+      chat,
+      userId: senderId,
+      args: invocation.args,
+      replyTo,
+      tempId: data.tempId,
+    });
+    if (result.type !== 'post') {
+      return { message: result.type === 'posted' ? result.message : undefined, command: invocation.name };
+    }
+
+    // Built-ins like /me and /poll post as the sender
+    ({ content, type = 'text', mediaId } = result.message);
+    metadata = { ...metadata, ...result.message.metadata };
+  } else if (type === 'text') {
+    content = slashCommandService.unescape(content);
+  }
+
+  if (!restriction) {
+    await trustService.checkMessage(senderId, chat, content, metadata);
+  }
+
+  // Custom emoji of the group written as :shortcode:
+  const customEmoji = type === 'text' ? await customEmojiService.findInText(chat, content) : [];
+  if (customEmoji.length > 0) {
+    metadata = { ...metadata, customEmoji };
+  }
+
+  // Members of pseudonymous groups post under their pseudonym
+  const pseudonym = pseudonymService.appliesTo(chat, senderId) ? await pseudonymService.assign(chat, senderId) : null;
+
+  // Create message
+  const message = await messageRepository.create({
+    chatId,
+    senderId: senderId as any,
+    clientMessageId,
+    content,
+    type,
+    replyTo,
+    media: mediaId,
+    metadata,
+    ...(restriction && {
+      moderation: { state: restriction.mode === 'review' ? 'pending' : 'hidden', actionId: restriction.actionId },
+    }),
+    ...(heldForGroup && {
+      moderation: { state: 'pending', groupReview: true },
+    }),
+    ...(pseudonym && {
+      pseudonym: { id: pseudonym._id, name: pseudonym.name },
+    }),
+  } as any);
+
+  // Looks sent to the sender, but nobody else gets it unless a moderator or
+  // one of the group's admins approves it
+  if (restriction || heldForGroup) {
+    activityTracker.record(senderId);
+    socketManager.emitToUser(senderId, 'message:new', await messageRepository.findById(message._id));
+    if (heldForGroup) {
+      await groupApprovalService.notifyPending(chat, message);
+    }
+    return { message };
+  }
+
+  deliveryLatencyTracker.recordIngress(message._id.toString(), ingressAt);
+  activityTracker.record(senderId);
+
+  // Update chat last activity
+  await chatRepository.updateLastActivity(chatId, message._id);
+
+  // Populate message for response, with custom emoji resolved to URLs
+  const populated = await messageRepository.findById(message._id);
+  const populatedMessage = customEmoji.length > 0 ? (await customEmojiService.attach([populated!]))[0] : populated;
+
+  // Emit to all chat participants, without the sender's identity where it
+  // is hidden from them
+  if (populatedMessage!.pseudonym || populatedMessage!.metadata?.poll?.anonymous) {
+    await pseudonymService.emitMessage(chat, 'message:new', populatedMessage!);
+  } else {
+    socketManager.emitToChat(chatId, 'message:new', populatedMessage);
+  }
+  socketManager.recordMessageDispatched(chat.participants.length);
+  deliveryLatencyTracker.recordDispatch(message._id.toString(), clientInfo);
+
+  // Push to participants who aren't in the chat, relay to bridged Matrix/XMPP
+  // rooms and email threads, and run automation rules, without holding up
+  // the sender
+  messageTaskQueue.enqueue('push:message', { messageId: message._id.toString() });
+  messageTaskQueue.enqueue('relay:federation', { messageId: message._id.toString() });
+  messageTaskQueue.enqueue('relay:email', { messageId: message._id.toString() });
+  messageTaskQueue.enqueue('automation:message', { messageId: message._id.toString() });
+
+  return { message, command: invocation?.name };
+}
+
 export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
   // Send message
   socket.on('message:send', async (data) => {
     if (!messageRateLimit(socket, 'message:send')) return;
+    socketManager.recordMessageReceived();
+    const ingressAt = Date.now();
 
     try {
-      const { chatId, content, type = 'text', replyTo, mediaId, metadata } = data;
-
-      // Validate chat membership
-      const chat = await chatRepository.findById(chatId);
-      if (!chat || !chat.participants.includes(socket.userId as any)) {
-        return socket.emit('error', { message: 'Not authorized to send message to this chat' });
+      const result = await sendChatMessage(io, socket.userId, data, ingressAt, socket.clientInfo);
+      if (!result) {
+        return emitEvent(socket, 'error', { message: 'Not authorized to send message to this chat' });
       }
 
-      // Create message
-      const message = await messageRepository.create({
-        chatId,
-        senderId: socket.userId as any,
-        content,
-        type,
-        replyTo,
-        media: mediaId,
-        metadata,
-      });
-
-      // Update chat last activity
-      await chatRepository.updateLastActivity(chatId, message._id);
-
-      // Populate message for response
-      const populatedMessage = await messageRepository.findById(message._id);
-
-      // Emit to all chat participants
-      io.to(`chat:${chatId}`).emit('message:new', populatedMessage);
-
-      // Send delivery confirmations to sender
-      socket.emit('message:sent', { messageId: message._id, tempId: data.tempId });
+      // Send delivery confirmations to sender. Commands with a private reply
+      // are answered through command:response instead.
+      if (result.message) {
+        emitEvent(socket, 'message:sent', {
+          messageId: result.message._id,
+          tempId: data.tempId,
+          clientMessageId: data.clientMessageId,
+          ...(result.duplicate && { duplicate: true }),
+        });
+      }
 
     } catch (error) {
+      if (error instanceof TrustLimitError) {
+        return emitEvent(socket, 'error', { message: error.message });
+      }
       console.error('Error sending message:', error);
-      socket.emit('error', { message: 'Failed to send message' });
+      socketManager.recordMessageError();
+      emitEvent(socket, 'error', { message: 'Failed to send message' });
     }
   });
 
@@ -64,7 +236,7 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
       // Get message and verify ownership
       const message = await messageRepository.findById(messageId);
       if (!message || message.senderId.toString() !== socket.userId) {
-        return socket.emit('error', { message: 'Not authorized to edit this message' });
+        return emitEvent(socket, 'error', { message: 'Not authorized to edit this message' });
       }
 
       // Update message
@@ -74,12 +246,18 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
         editedAt: new Date(),
       });
 
-      // Emit to chat participants
-      io.to(`chat:${message.chatId}`).emit('message:edited', updatedMessage);
+      // Emit to chat participants; withheld messages only to the sender
+      const withheld = (WITHHELD_MESSAGE_STATES as readonly string[]).includes(message.moderation?.state as string);
+      if (!withheld && updatedMessage && (updatedMessage.pseudonym || updatedMessage.metadata?.poll?.anonymous)) {
+        const chat = await chatRepository.findById(message.chatId);
+        if (chat) await pseudonymService.emitMessage(chat, 'message:edited', updatedMessage);
+      } else {
+        emitEvent(io.to(withheld ? `user:${socket.userId}` : `chat:${message.chatId}`), 'message:edited', updatedMessage);
+      }
 
     } catch (error) {
       console.error('Error editing message:', error);
-      socket.emit('error', { message: 'Failed to edit message' });
+      emitEvent(socket, 'error', { message: 'Failed to edit message' });
     }
   });
 
@@ -91,7 +269,7 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
       // Get message and verify ownership
       const message = await messageRepository.findById(messageId);
       if (!message || message.senderId.toString() !== socket.userId) {
-        return socket.emit('error', { message: 'Not authorized to delete this message' });
+        return emitEvent(socket, 'error', { message: 'Not authorized to delete this message' });
       }
 
       if (deleteForEveryone) {
@@ -100,20 +278,22 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
         const deleteTimeLimit = 7 * 60 * 1000; // 7 minutes
 
         if (timeDiff > deleteTimeLimit) {
-          return socket.emit('error', { message: 'Time limit exceeded for deleting for everyone' });
+          return emitEvent(socket, 'error', { message: 'Time limit exceeded for deleting for everyone' });
         }
 
+        // Under a legal hold the content is kept in the hold vault
+        await legalHoldService.preserveMessage(message, { kind: 'user', id: socket.userId as string });
         await messageRepository.delete(messageId);
-        io.to(`chat:${message.chatId}`).emit('message:deleted', { messageId, deletedForEveryone: true });
+        socketManager.emitToChat(message.chatId.toString(), 'message:deleted', { messageId, deletedForEveryone: true });
       } else {
         // Delete for sender only
         await messageRepository.delete(messageId, socket.userId as any);
-        socket.emit('message:deleted', { messageId, deletedForEveryone: false });
+        emitEvent(socket, 'message:deleted', { messageId, deletedForEveryone: false });
       }
 
     } catch (error) {
       console.error('Error deleting message:', error);
-      socket.emit('error', { message: 'Failed to delete message' });
+      emitEvent(socket, 'error', { message: 'Failed to delete message' });
     }
   });
 
@@ -122,34 +302,46 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
     if (!reactionRateLimit(socket, 'message:react')) return;
 
     try {
-      const { messageId, emoji } = data;
+      const { messageId, customEmojiId } = data;
+      let { emoji } = data;
 
       // Get message to verify chat membership
       const message = await messageRepository.findById(messageId);
       if (!message) {
-        return socket.emit('error', { message: 'Message not found' });
+        return emitEvent(socket, 'error', { message: 'Message not found' });
       }
 
       // Verify chat membership
       const chat = await chatRepository.findById(message.chatId);
-      if (!chat || !chat.participants.includes(socket.userId as any)) {
-        return socket.emit('error', { message: 'Not authorized to react to this message' });
+      if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === socket.userId)) {
+        return emitEvent(socket, 'error', { message: 'Not authorized to react to this message' });
+      }
+
+      // Custom emoji of the group, by ID or :shortcode:
+      const custom = await customEmojiService.resolveReaction(chat, emoji, customEmojiId);
+      if (custom) {
+        emoji = custom.emoji;
       }
 
       // Add reaction
-      await messageRepository.addReaction(messageId, socket.userId as any, emoji);
+      await messageRepository.addReaction(messageId, socket.userId as any, emoji, custom?.customEmojiId);
 
       // Emit to chat participants
-      io.to(`chat:${message.chatId}`).emit('message:reaction:added', {
+      const resolved = custom ? (await customEmojiService.resolve([custom.customEmojiId])).get(custom.customEmojiId.toString()) : undefined;
+      socketManager.emitToChat(message.chatId.toString(), 'message:reaction:added', {
         messageId,
         userId: socket.userId,
         emoji,
+        ...(resolved && { customEmoji: { id: resolved.id, shortcode: resolved.shortcode, url: resolved.url } }),
         timestamp: new Date(),
       });
 
     } catch (error) {
+      if (error instanceof CustomEmojiError) {
+        return emitEvent(socket, 'error', { message: error.message });
+      }
       console.error('Error adding reaction:', error);
-      socket.emit('error', { message: 'Failed to add reaction' });
+      emitEvent(socket, 'error', { message: 'Failed to add reaction' });
     }
   });
 
@@ -161,21 +353,91 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
       // Get message to verify chat membership
       const message = await messageRepository.findById(messageId);
       if (!message) {
-        return socket.emit('error', { message: 'Message not found' });
+        return emitEvent(socket, 'error', { message: 'Message not found' });
       }
 
       // Remove reaction
       await messageRepository.removeReaction(messageId, socket.userId as any);
 
       // Emit to chat participants
-      io.to(`chat:${message.chatId}`).emit('message:reaction:removed', {
+      socketManager.emitToChat(message.chatId.toString(), 'message:reaction:removed', {
         messageId,
         userId: socket.userId,
       });
 
     } catch (error) {
       console.error('Error removing reaction:', error);
-      socket.emit('error', { message: 'Failed to remove reaction' });
+      emitEvent(socket, 'error', { message: 'Failed to remove reaction' });
+    }
+  });
+
+  // Vote on a /poll message
+  socket.on('poll:vote', async (data) => {
+    if (!reactionRateLimit(socket, 'poll:vote')) return;
+
+    try {
+      const { messageId, optionIndex } = data;
+
+      const message = await messageRepository.findById(messageId);
+      if (!message || !message.metadata?.poll) {
+        return emitEvent(socket, 'error', { message: 'Poll not found' });
+      }
+      if (message.metadata.poll.closed) {
+        return emitEvent(socket, 'error', { message: 'Poll is closed' });
+      }
+
+      // Verify chat membership
+      const chat = await chatRepository.findById(message.chatId);
+      if (!chat || !chat.participants.some((p: any) => (p._id || p).toString() === socket.userId)) {
+        return emitEvent(socket, 'error', { message: 'Not authorized to vote on this poll' });
+      }
+
+      const updated = await messageRepository.votePoll(messageId, socket.userId, optionIndex);
+      if (!updated?.metadata?.poll) {
+        return emitEvent(socket, 'error', { message: 'Invalid poll option' });
+      }
+
+      // Voters on anonymous polls are only shown to the group's admins
+      await pseudonymService.emitPollUpdate(chat, updated, socket.userId);
+
+    } catch (error) {
+      console.error('Error voting on poll:', error);
+      emitEvent(socket, 'error', { message: 'Failed to vote' });
+    }
+  });
+
+  // Client acknowledgment that a message reached the device
+  socket.on('message:delivered', async (data) => {
+    try {
+      const { messageId } = data;
+
+      const message = await messageRepository.findById(messageId);
+      if (!message) {
+        return emitEvent(socket, 'error', { message: 'Message not found' });
+      }
+
+      // Only members of the chat can acknowledge its messages
+      if (!(await chatRepository.isParticipant(message.chatId, socket.userId))) {
+        return emitEvent(socket, 'error', { message: 'Not authorized to acknowledge this message' });
+      }
+
+      await messageRepository.markAsDelivered(messageId, socket.userId as any);
+
+      deliveryLatencyTracker.recordAck(messageId, socket.clientInfo || { region: 'unknown', platform: 'unknown' });
+
+      // Let the sender know the message was delivered
+      const senderId = (message.senderId as any)._id?.toString() || message.senderId.toString();
+      if (senderId !== socket.userId) {
+        emitEvent(io.to(`user:${senderId}`), 'message:delivery:receipt', {
+          messageId,
+          deliveredTo: socket.userId,
+          deliveredAt: new Date(),
+        });
+      }
+
+    } catch (error) {
+      console.error('Error acknowledging delivery:', error);
+      emitEvent(socket, 'error', { message: 'Failed to acknowledge delivery' });
     }
   });
 
@@ -187,22 +449,25 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
       // Mark messages as read
       const readCount = await messageRepository.markMultipleAsRead(messageIds, socket.userId as any);
 
+      // Keep the user's other devices' unread dividers in step
+      await readPositionService.markRead(socket.userId, messageIds, socket.id);
+
       if (readCount > 0) {
         // Get first message to determine chat
         const firstMessage = await messageRepository.findById(messageIds[0]);
         if (firstMessage) {
-          // Emit read receipt to chat participants (except sender)
-          socket.to(`chat:${firstMessage.chatId}`).emit('message:read:receipt', {
+          // Emit read receipt to chat participants (except the reader)
+          socketManager.emitToChat(firstMessage.chatId.toString(), 'message:read:receipt', {
             messageIds,
             readBy: socket.userId,
             readAt: new Date(),
-          });
+          }, socket.userId);
         }
       }
 
     } catch (error) {
       console.error('Error marking messages as read:', error);
-      socket.emit('error', { message: 'Failed to mark messages as read' });
+      emitEvent(socket, 'error', { message: 'Failed to mark messages as read' });
     }
   });
 
@@ -214,16 +479,16 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
       // Verify chat membership
       const chat = await chatRepository.findById(chatId);
       if (!chat || !chat.participants.includes(socket.userId as any)) {
-        return socket.emit('error', { message: 'Not authorized to join this chat' });
+        return emitEvent(socket, 'error', { message: 'Not authorized to join this chat' });
       }
 
       // Join chat room
       socket.join(`chat:${chatId}`);
-      socket.emit('chat:joined', { chatId });
+      emitEvent(socket, 'chat:joined', { chatId });
 
     } catch (error) {
       console.error('Error joining chat:', error);
-      socket.emit('error', { message: 'Failed to join chat' });
+      emitEvent(socket, 'error', { message: 'Failed to join chat' });
     }
   });
 
@@ -231,6 +496,6 @@ export function registerMessagingEvents(socket: AuthenticatedSocket, io: SocketI
   socket.on('chat:leave', (data) => {
     const { chatId } = data;
     socket.leave(`chat:${chatId}`);
-    socket.emit('chat:left', { chatId });
+    emitEvent(socket, 'chat:left', { chatId });
   });
 }
\ No newline at end of file
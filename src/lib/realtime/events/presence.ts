@@ -1,5 +1,6 @@
 import { Server as SocketIOServer } from 'socket.io';
 import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
 import { UserRepository } from '../../database/repositories/user';
 
 const userRepository = new UserRepository();
@@ -17,7 +18,7 @@ export function registerPresenceEvents(socket: AuthenticatedSocket, io: SocketIO
       await userRepository.updateOnlineStatus(socket.userId as any, isOnline);
 
       // Broadcast presence to contacts
-      socket.broadcast.emit('presence:changed', {
+      emitEvent(socket.broadcast, 'presence:changed', {
         userId: socket.userId,
         status,
         lastSeen: new Date(),
@@ -34,7 +35,7 @@ export function registerPresenceEvents(socket: AuthenticatedSocket, io: SocketIO
       await userRepository.updateOnlineStatus(socket.userId as any, false);
 
       // Broadcast offline status
-      socket.broadcast.emit('presence:changed', {
+      emitEvent(socket.broadcast, 'presence:changed', {
         userId: socket.userId,
         status: 'offline',
         lastSeen: new Date(),
@@ -47,7 +48,7 @@ export function registerPresenceEvents(socket: AuthenticatedSocket, io: SocketIO
 
   // Handle ping/heartbeat for active status
   socket.on('presence:ping', () => {
-    socket.emit('presence:pong');
+    emitEvent(socket, 'presence:pong');
     userRepository.updateOnlineStatus(socket.userId as any, true);
   });
 }
@@ -1,5 +1,6 @@
 import { Server as SocketIOServer } from 'socket.io';
 import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
 import { ChatRepository } from '../../database/repositories/chat';
 import { createEventRateLimit } from '../middleware/rate-limit';
 
@@ -20,7 +21,7 @@ export function registerTypingEvents(socket: AuthenticatedSocket, io: SocketIOSe
       // Verify chat membership
       const chat = await chatRepository.findById(chatId);
       if (!chat || !chat.participants.includes(socket.userId as any)) {
-        return socket.emit('error', { message: 'Not authorized to access this chat' });
+        return emitEvent(socket, 'error', { message: 'Not authorized to access this chat' });
       }
 
       // Add user to typing set
@@ -30,7 +31,7 @@ export function registerTypingEvents(socket: AuthenticatedSocket, io: SocketIOSe
       typingUsers.get(chatId)!.add(socket.userId);
 
       // Broadcast to other chat participants
-      socket.to(`chat:${chatId}`).emit('typing:user:start', {
+      emitEvent(socket.to(`chat:${chatId}`), 'typing:user:start', {
         chatId,
         userId: socket.userId,
         user: {
@@ -59,7 +60,7 @@ export function registerTypingEvents(socket: AuthenticatedSocket, io: SocketIOSe
       }
 
       // Broadcast to other chat participants
-      socket.to(`chat:${chatId}`).emit('typing:user:stop', {
+      emitEvent(socket.to(`chat:${chatId}`), 'typing:user:stop', {
         chatId,
         userId: socket.userId,
       });
@@ -80,7 +81,7 @@ export function registerTypingEvents(socket: AuthenticatedSocket, io: SocketIOSe
         }
 
         // Broadcast typing stop
-        socket.to(`chat:${chatId}`).emit('typing:user:stop', {
+        emitEvent(socket.to(`chat:${chatId}`), 'typing:user:stop', {
           chatId,
           userId: socket.userId,
         });
@@ -93,7 +94,7 @@ export function registerTypingEvents(socket: AuthenticatedSocket, io: SocketIOSe
     const { chatId } = data;
     const currentTypingUsers = Array.from(typingUsers.get(chatId) || []);
     
-    socket.emit('typing:current', {
+    emitEvent(socket, 'typing:current', {
       chatId,
       typingUsers: currentTypingUsers,
     });
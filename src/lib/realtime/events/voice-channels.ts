@@ -0,0 +1,127 @@
+import { Server as SocketIOServer } from 'socket.io';
+import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
+import { lowDataMode } from '../low-data';
+import { UserRepository } from '../../database/repositories/user';
+import { voiceChannelService, voiceChannelRoom, serializeVoiceChannel, VoiceChannelError } from '../../webrtc/voice-channels';
+import { coturnManager } from '../../webrtc/coturn';
+import { getBitrateLimits, limitSessionDescription, resolveMediaConstraints } from '../../webrtc/media-constraints';
+
+const userRepository = new UserRepository();
+
+function handleError(socket: AuthenticatedSocket, error: unknown, fallback: string): void {
+  if (error instanceof VoiceChannelError) {
+    return emitEvent(socket, 'voice:error', { message: error.message });
+  }
+  console.error(`${fallback}:`, error);
+  emitEvent(socket, 'voice:error', { message: fallback });
+}
+
+export function registerVoiceChannelEvents(socket: AuthenticatedSocket, io: SocketIOServer) {
+  // Only connections in the channel's room can talk to its participants
+  const inChannel = (channelId: unknown): channelId is string =>
+    typeof channelId === 'string' && socket.rooms.has(voiceChannelRoom(channelId));
+
+  // Join a voice channel; nobody is rung
+  socket.on('voice:join', async (data) => {
+    try {
+      const { channelId } = data || {};
+
+      if (socket.guest && !socket.guest.calls) {
+        return emitEvent(socket, 'voice:error', { message: 'Guests can\'t join voice channels in this group' });
+      }
+
+      const { group, channel } = await voiceChannelService.join(String(channelId), {
+        userId: socket.userId,
+        socketId: socket.id,
+        deviceId: socket.deviceId,
+      });
+      socket.join(voiceChannelRoom(channel._id.toString()));
+
+      // Same TURN credentials and audio settings as a call
+      const user = await userRepository.findById(socket.userId);
+      emitEvent(socket, 'voice:joined', {
+        channel: serializeVoiceChannel(channel, group),
+        iceServers: coturnManager.getICEServers(socket.userId, socket.clientInfo?.region),
+        media: resolveMediaConstraints(user, undefined, lowDataMode.isOn(socket)),
+      });
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to join voice channel');
+    }
+  });
+
+  // Leave a voice channel
+  socket.on('voice:leave', async (data) => {
+    try {
+      const { channelId } = data || {};
+      if (typeof channelId !== 'string') return;
+
+      await voiceChannelService.leave(channelId, socket.userId, socket.id);
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to leave voice channel');
+    }
+  });
+
+  // Mute or unmute yourself, or switch push-to-talk
+  socket.on('voice:state', async (data) => {
+    try {
+      const { channelId, muted, pushToTalk } = data || {};
+
+      await voiceChannelService.setState(String(channelId), socket.userId, {
+        ...(typeof muted === 'boolean' && { muted }),
+        ...(typeof pushToTalk === 'boolean' && { pushToTalk }),
+      });
+
+    } catch (error) {
+      handleError(socket, error, 'Failed to update voice state');
+    }
+  });
+
+  // Push-to-talk key or voice activity; relayed as is, for speaking indicators
+  socket.on('voice:talking', (data) => {
+    const { channelId, talking } = data || {};
+    if (!inChannel(channelId)) return;
+
+    emitEvent(socket.to(voiceChannelRoom(channelId)), 'voice:talking', {
+      channelId,
+      userId: socket.userId,
+      talking: !!talking,
+    });
+  });
+
+  // WebRTC signaling. Audio is a mesh, so each offer, answer and candidate
+  // goes to the one participant it is meant for.
+  socket.on('voice:offer', (data) => {
+    const { channelId, to } = data || {};
+    if (!inChannel(channelId) || typeof to !== 'string') return;
+
+    const sdp = limitSessionDescription(data.sdp, getBitrateLimits(undefined, lowDataMode.isOn(socket)));
+    emitEvent(io.to(`user:${to}`), 'voice:offer', { channelId, sdp, from: socket.userId });
+  });
+
+  socket.on('voice:answer-sdp', (data) => {
+    const { channelId, to } = data || {};
+    if (!inChannel(channelId) || typeof to !== 'string') return;
+
+    const sdp = limitSessionDescription(data.sdp, getBitrateLimits(undefined, lowDataMode.isOn(socket)));
+    emitEvent(io.to(`user:${to}`), 'voice:answer-sdp', { channelId, sdp, from: socket.userId });
+  });
+
+  socket.on('voice:ice-candidate', (data) => {
+    const { channelId, to, candidate } = data || {};
+    if (!inChannel(channelId) || typeof to !== 'string') return;
+
+    emitEvent(io.to(`user:${to}`), 'voice:ice-candidate', { channelId, candidate, from: socket.userId });
+  });
+
+  // Dropping the connection leaves the channel
+  socket.on('disconnect', async () => {
+    try {
+      await voiceChannelService.disconnected(socket.id);
+    } catch (error) {
+      console.error('Error leaving voice channel on disconnect:', error);
+    }
+  });
+}
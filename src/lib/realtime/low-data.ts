@@ -0,0 +1,89 @@
+import { AuthenticatedSocket } from './socket';
+import { isReducibleEvent } from './protocol';
+import { logger } from '../monitoring/logging';
+
+// Encoded Socket.IO event packets: packet type, optional namespace and ack
+// id, then the JSON array of the event name and its arguments. Binary
+// packets (type 5, with attachments) don't match and go out as they are.
+const EVENT_PACKET = /^(\d+(?:\/[^,]*,)?\d*)(\[[\s\S]*\])$/;
+
+// Media fields a low-data device doesn't get inline; it fetches a preview
+// when the user opens the message
+const PREVIEW_FIELDS = ['thumbnails', 'thumbnailUrl', 'thumbnailKey', 'perceptualHash'];
+
+interface EnginePacket {
+  type: string;
+  options?: { compress?: boolean };
+  data?: unknown;
+}
+
+// Sockets of devices in low-data mode are in this room. A room rather than
+// a flag in socket.data, so the mode can be switched from any replica.
+export const LOW_DATA_ROOM = 'low-data';
+
+// Low-data mode for devices on constrained networks, turned on per device
+// (see auth/linked-devices). Every packet a socket sends passes through
+// here on its way into the engine's send buffer. For low-data sockets,
+// events marked reducible in the protocol lose their media previews and
+// carry autoDownload: false on their media, and frames are deflated
+// (polling responses are compressed per batch); other sockets' frames go
+// out uncompressed as before. Broadcasts are encoded once for all sockets,
+// so the rewrite happens on the encoded packet.
+export class LowDataMode {
+  attach(socket: AuthenticatedSocket, enabled: boolean): void {
+    this.set(socket, enabled);
+    socket.conn.on('packetCreate', (packet: EnginePacket) => {
+      const lowData = this.isOn(socket);
+
+      // Options are shared between the sockets of a broadcast
+      packet.options = { ...packet.options, compress: lowData };
+
+      if (lowData && packet.type === 'message' && typeof packet.data === 'string') {
+        packet.data = this.reduce(packet.data);
+      }
+    });
+  }
+
+  // Also takes the remote sockets from fetchSockets
+  set(socket: { join(room: string): unknown; leave(room: string): unknown }, enabled: boolean): void {
+    if (enabled) {
+      socket.join(LOW_DATA_ROOM);
+    } else {
+      socket.leave(LOW_DATA_ROOM);
+    }
+  }
+
+  isOn(socket: { rooms: Set<string> }): boolean {
+    return socket.rooms.has(LOW_DATA_ROOM);
+  }
+
+  private reduce(encoded: string): string {
+    const match = encoded.match(EVENT_PACKET);
+    if (!match) return encoded;
+
+    const event = match[2].match(/^\["([^"]+)"/)?.[1];
+    if (!event || !isReducibleEvent(event)) return encoded;
+
+    try {
+      const [name, payload, ...rest] = JSON.parse(match[2]);
+      return match[1] + JSON.stringify([name, reducePayload(payload), ...rest]);
+    } catch (error) {
+      logger.warn('Could not reduce packet for low-data socket', { event, error: (error as Error).message });
+      return encoded;
+    }
+  }
+}
+
+// A message payload without inline previews, with an auto-download hint
+// on its media
+function reducePayload(message: any): any {
+  if (!message || typeof message !== 'object' || !message.media || typeof message.media !== 'object') {
+    return message;
+  }
+
+  const media = { ...message.media, autoDownload: false };
+  PREVIEW_FIELDS.forEach(field => delete media[field]);
+  return { ...message, media };
+}
+
+export const lowDataMode = new LowDataMode();
@@ -0,0 +1,355 @@
+import crypto from 'crypto';
+import { redisConfig } from '../config/redis';
+import { MESSAGE_TASK_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const DEAD_LETTER_KEY = 'message-tasks:dead-letter';
+
+export type TaskLane = 'high' | 'normal' | 'low';
+
+const LANES: TaskLane[] = ['high', 'normal', 'low'];
+
+export type TaskHandler = (payload: Record<string, any>) => Promise<void>;
+
+interface TaskDefinition {
+  handler: TaskHandler;
+  lane: TaskLane;
+}
+
+interface QueuedTask {
+  id: string;
+  name: string;
+  lane: TaskLane;
+  payload: Record<string, any>;
+  attempts: number;
+  enqueuedAt: number;
+}
+
+export interface DeadLetter {
+  id: string;
+  name: string;
+  lane: TaskLane;
+  payload: Record<string, any>;
+  error: string;
+  attempts: number;
+  failedAt: Date;
+}
+
+export interface MessageTaskStats {
+  workers: number;
+  depth: Record<TaskLane, number>;
+  retrying: number;
+  completed: number;
+  failed: number;
+  deadLettered: number;
+}
+
+// Work that follows a sent message without holding up the sender, e.g.
+// pushing it to offline participants and relaying it to bridged rooms and
+// email threads. Tasks wait in three lanes
+// and are taken by weighted round robin, so low priority work still moves
+// while high priority work keeps arriving. Workers are started as the queue
+// grows, one per TASKS_PER_WORKER queued tasks up to MAX_WORKERS, and stop
+// when it runs empty. A task that keeps failing is retried with backoff and
+// then kept as a dead letter for admins to retry or discard.
+//
+// Queues are local to this server; payloads only carry ids so a dead letter
+// can be retried on any replica.
+export class MessageTaskQueue {
+  private redis = redisConfig.getClient();
+  private definitions = new Map<string, TaskDefinition>();
+  private lanes: Record<TaskLane, QueuedTask[]> = { high: [], normal: [], low: [] };
+  private schedule: TaskLane[] = LANES.flatMap(lane =>
+    Array<TaskLane>(MESSAGE_TASK_CONSTANTS.LANE_WEIGHTS[lane]).fill(lane)
+  );
+  private cursor = 0;
+  private workers = 0;
+  private retrying = 0;
+  private completed = 0;
+  private failed = 0;
+  private deadLettered = 0;
+  private memoryDeadLetters: DeadLetter[] = [];
+
+  constructor() {
+    // Participants already viewing the chat get it over the socket
+    this.register('push:message', 'high', async ({ messageId }) => {
+      const message = await this.loadMessage(messageId);
+      if (!message) return;
+      const [{ pushNotificationService }, { socketManager }] = await Promise.all([
+        import('../communication/push-notifications'),
+        import('./socket'),
+      ]);
+      const viewing = await socketManager.getRoomUserIds(`chat:${message.chatId}`);
+      await pushNotificationService.notifyChatMessage(message, viewing);
+    });
+
+    this.register('relay:federation', 'normal', async ({ messageId }) => {
+      const message = await this.loadMessage(messageId);
+      if (!message) return;
+      const { federationService } = await import('../federation');
+      await federationService.relayOutbound(message);
+    });
+
+    this.register('relay:email', 'low', async ({ messageId }) => {
+      const message = await this.loadMessage(messageId);
+      if (!message) return;
+      const { emailGatewayService } = await import('../communication/email-gateway');
+      await emailGatewayService.relayOutbound(message);
+    });
+
+    this.register('automation:message', 'low', async ({ messageId }) => {
+      const message = await this.loadMessage(messageId);
+      if (!message) return;
+      const { automationService } = await import('../integrations/automation');
+      await automationService.messageCreated(message);
+    });
+  }
+
+  register(name: string, lane: TaskLane, handler: TaskHandler): void {
+    this.definitions.set(name, { handler, lane });
+  }
+
+  // Queue a task in its registered lane, or in lane when given
+  enqueue(name: string, payload: Record<string, any>, lane?: TaskLane): boolean {
+    const definition = this.definitions.get(name);
+    if (!definition) {
+      logger.warn('Unknown message task', { name });
+      return false;
+    }
+
+    const task: QueuedTask = {
+      id: crypto.randomUUID(),
+      name,
+      lane: lane || definition.lane,
+      payload,
+      attempts: 0,
+      enqueuedAt: Date.now(),
+    };
+
+    if (!this.push(task)) {
+      logger.warn('Message task queue full, task dropped', { name, lane: task.lane });
+      metricsCollector.incrementCounter('message_tasks_rejected', 1, { name, lane: task.lane });
+      return false;
+    }
+    return true;
+  }
+
+  getStats(): MessageTaskStats {
+    return {
+      workers: this.workers,
+      depth: {
+        high: this.lanes.high.length,
+        normal: this.lanes.normal.length,
+        low: this.lanes.low.length,
+      },
+      retrying: this.retrying,
+      completed: this.completed,
+      failed: this.failed,
+      deadLettered: this.deadLettered,
+    };
+  }
+
+  async listDeadLetters(limit: number = 100): Promise<DeadLetter[]> {
+    if (!this.redis) {
+      return this.memoryDeadLetters.slice(-limit).reverse();
+    }
+
+    const items = await this.redis.lrange(DEAD_LETTER_KEY, 0, limit - 1);
+    return items.map(item => {
+      const letter = JSON.parse(item);
+      return { ...letter, failedAt: new Date(letter.failedAt) };
+    });
+  }
+
+  // Queue a dead letter again with a fresh set of attempts
+  async retryDeadLetter(id: string): Promise<void> {
+    const letter = await this.takeDeadLetter(id);
+    if (!this.definitions.has(letter.name)) {
+      throw new MessageTaskError('Task type no longer exists', 400);
+    }
+
+    this.push({
+      id: letter.id,
+      name: letter.name,
+      lane: letter.lane,
+      payload: letter.payload,
+      attempts: 0,
+      enqueuedAt: Date.now(),
+    });
+    metricsCollector.incrementCounter('message_tasks_dead_letter_retried', 1, { name: letter.name });
+  }
+
+  async discardDeadLetter(id: string): Promise<void> {
+    await this.takeDeadLetter(id);
+  }
+
+  private push(task: QueuedTask): boolean {
+    const lane = this.lanes[task.lane];
+    if (lane.length >= MESSAGE_TASK_CONSTANTS.MAX_QUEUE_DEPTH) return false;
+
+    lane.push(task);
+    metricsCollector.recordGauge('message_tasks_depth', lane.length, { lane: task.lane });
+    this.scale();
+    return true;
+  }
+
+  // Start workers until there are enough for the queued work
+  private scale(): void {
+    const desired = Math.min(
+      MESSAGE_TASK_CONSTANTS.MAX_WORKERS,
+      Math.max(MESSAGE_TASK_CONSTANTS.MIN_WORKERS, Math.ceil(this.depth() / MESSAGE_TASK_CONSTANTS.TASKS_PER_WORKER))
+    );
+
+    while (this.workers < desired && this.depth() > 0) {
+      this.workers++;
+      this.work().finally(() => {
+        this.workers--;
+        metricsCollector.recordGauge('message_tasks_workers', this.workers);
+      });
+    }
+    metricsCollector.recordGauge('message_tasks_workers', this.workers);
+  }
+
+  private async work(): Promise<void> {
+    let task: QueuedTask | null;
+    while ((task = this.next())) {
+      await this.run(task);
+    }
+  }
+
+  // Next lane in the weighted schedule that has work, skipping empty ones
+  private next(): QueuedTask | null {
+    for (let i = 0; i < this.schedule.length; i++) {
+      const lane = this.schedule[this.cursor];
+      this.cursor = (this.cursor + 1) % this.schedule.length;
+
+      const task = this.lanes[lane].shift();
+      if (task) {
+        metricsCollector.recordGauge('message_tasks_depth', this.lanes[lane].length, { lane });
+        return task;
+      }
+    }
+    return null;
+  }
+
+  private async run(task: QueuedTask): Promise<void> {
+    const definition = this.definitions.get(task.name);
+    if (!definition) return;
+
+    task.attempts++;
+    const startTime = Date.now();
+    metricsCollector.recordHistogram('message_tasks_wait', startTime - task.enqueuedAt, { lane: task.lane });
+
+    try {
+      await this.withTimeout(definition.handler(task.payload));
+      this.completed++;
+      metricsCollector.incrementCounter('message_tasks_completed', 1, { name: task.name, lane: task.lane });
+    } catch (error) {
+      this.failed++;
+      metricsCollector.incrementCounter('message_tasks_failed', 1, { name: task.name, lane: task.lane });
+      this.handleFailure(task, error);
+    } finally {
+      metricsCollector.recordHistogram('message_tasks_duration', Date.now() - startTime, { name: task.name });
+    }
+  }
+
+  private handleFailure(task: QueuedTask, error: unknown): void {
+    if (task.attempts < MESSAGE_TASK_CONSTANTS.MAX_ATTEMPTS) {
+      const delay = MESSAGE_TASK_CONSTANTS.RETRY_BASE_DELAY * 2 ** (task.attempts - 1);
+      logger.warn('Message task failed, retrying', {
+        name: task.name,
+        attempt: task.attempts,
+        delay,
+        error: error instanceof Error ? error.message : String(error),
+      });
+      metricsCollector.incrementCounter('message_tasks_retried', 1, { name: task.name });
+
+      this.retrying++;
+      setTimeout(() => {
+        this.retrying--;
+        task.enqueuedAt = Date.now();
+        if (!this.push(task)) {
+          this.deadLetter(task, new Error('Queue full on retry'));
+        }
+      }, delay);
+      return;
+    }
+
+    this.deadLetter(task, error);
+  }
+
+  private deadLetter(task: QueuedTask, error: unknown): void {
+    const letter: DeadLetter = {
+      id: task.id,
+      name: task.name,
+      lane: task.lane,
+      payload: task.payload,
+      error: error instanceof Error ? error.message : String(error),
+      attempts: task.attempts,
+      failedAt: new Date(),
+    };
+
+    this.deadLettered++;
+    metricsCollector.incrementCounter('message_tasks_dead_lettered', 1, { name: task.name });
+    logger.error('Message task moved to dead letters', error, { name: task.name, taskId: task.id, payload: task.payload });
+
+    if (!this.redis) {
+      this.memoryDeadLetters.push(letter);
+      this.memoryDeadLetters = this.memoryDeadLetters.slice(-MESSAGE_TASK_CONSTANTS.DEAD_LETTER_LIMIT);
+      return;
+    }
+
+    this.redis
+      .pipeline()
+      .lpush(DEAD_LETTER_KEY, JSON.stringify(letter))
+      .ltrim(DEAD_LETTER_KEY, 0, MESSAGE_TASK_CONSTANTS.DEAD_LETTER_LIMIT - 1)
+      .exec()
+      .catch(storeError => {
+        logger.error('Failed to store dead letter', storeError, { taskId: task.id });
+      });
+  }
+
+  private async takeDeadLetter(id: string): Promise<DeadLetter> {
+    if (!this.redis) {
+      const index = this.memoryDeadLetters.findIndex(letter => letter.id === id);
+      if (index === -1) {
+        throw new MessageTaskError('Dead letter not found', 404);
+      }
+      return this.memoryDeadLetters.splice(index, 1)[0];
+    }
+
+    const items = await this.redis.lrange(DEAD_LETTER_KEY, 0, -1);
+    const item = items.find(entry => JSON.parse(entry).id === id);
+    if (!item || (await this.redis.lrem(DEAD_LETTER_KEY, 1, item)) === 0) {
+      throw new MessageTaskError('Dead letter not found', 404);
+    }
+    return JSON.parse(item);
+  }
+
+  private depth(): number {
+    return this.lanes.high.length + this.lanes.normal.length + this.lanes.low.length;
+  }
+
+  private withTimeout(work: Promise<void>): Promise<void> {
+    let timer: NodeJS.Timeout;
+    const timeout = new Promise<never>((_, reject) => {
+      timer = setTimeout(() => reject(new Error('Task timed out')), MESSAGE_TASK_CONSTANTS.TASK_TIMEOUT);
+    });
+    return Promise.race([work, timeout]).finally(() => clearTimeout(timer));
+  }
+
+  private async loadMessage(messageId: string) {
+    const { MessageRepository } = await import('../database/repositories/message');
+    return new MessageRepository().findById(messageId);
+  }
+}
+
+export class MessageTaskError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'MessageTaskError';
+  }
+}
+
+export const messageTaskQueue = new MessageTaskQueue();
@@ -2,10 +2,26 @@ import { Socket } from 'socket.io';
 import jwt from 'jsonwebtoken';
 import { User } from '../../database/models/user';
 import { AuthenticatedSocket } from '../socket';
+import { organizationService } from '../../organizations';
+import { isGuestExpired } from '../../auth/guest-access';
+import { isLowDataDevice } from '../../auth/linked-devices';
 
 export const socketAuthMiddleware = async (socket: Socket, next: (err?: Error) => void) => {
   try {
     const token = socket.handshake.auth.token || socket.handshake.headers.authorization;
+
+    // A desktop waiting for its QR code to be scanned; it only receives the
+    // qr:* events of its own code
+    const { qrId, qrPollToken } = socket.handshake.auth;
+    if (!token && qrId && qrPollToken) {
+      const { qrAuthService } = await import('../../auth/qr-auth');
+      if (!(await qrAuthService.verifyPollToken(String(qrId), String(qrPollToken)))) {
+        return next(new Error('QR session not found or expired'));
+      }
+      (socket as AuthenticatedSocket).userId = `qr:${qrId}`;
+      (socket as AuthenticatedSocket).qrLogin = true;
+      return next();
+    }
     
     if (!token) {
       return next(new Error('Authentication token required'));
@@ -18,7 +34,7 @@ export const socketAuthMiddleware = async (socket: Socket, next: (err?: Error) =
     const decoded = jwt.verify(cleanToken, process.env.JWT_SECRET!) as any;
     
     // Get user from database
-    const user = await User.findById(decoded.userId).select('displayName avatar phoneNumber isVerified isBanned').exec();
+    const user = await User.findById(decoded.userId).select('displayName avatar phoneNumber isVerified isBanned organizationId guest devices.deviceId devices.lowDataMode').exec();
     
     if (!user) {
       return next(new Error('User not found'));
@@ -28,8 +44,21 @@ export const socketAuthMiddleware = async (socket: Socket, next: (err?: Error) =
       return next(new Error('User is banned'));
     }
 
+    if (await organizationService.isSuspended(user.organizationId)) {
+      return next(new Error('Organization suspended'));
+    }
+
+    if (isGuestExpired(user)) {
+      return next(new Error('Guest access ended'));
+    }
+
     // Attach user info to socket
     (socket as AuthenticatedSocket).userId = user._id.toString();
+    (socket as AuthenticatedSocket).deviceId = decoded.deviceId;
+    (socket as AuthenticatedSocket).tokenExpiresAt = decoded.exp ? new Date(decoded.exp * 1000) : undefined;
+    (socket as AuthenticatedSocket).lowData = isLowDataDevice(user, decoded.deviceId);
+    (socket as AuthenticatedSocket).organizationId = user.organizationId?.toString();
+    (socket as AuthenticatedSocket).guest = user.guest?.capabilities;
     (socket as AuthenticatedSocket).user = {
       _id: user._id.toString(),
       displayName: user.displayName,
@@ -1,5 +1,6 @@
 import { Socket } from 'socket.io';
 import { AuthenticatedSocket } from '../socket';
+import { emitEvent } from '../protocol';
 
 interface RateLimitConfig {
   windowMs: number;
@@ -66,7 +67,7 @@ export const createEventRateLimit = (config: Partial<RateLimitConfig> = {}) => {
     }
 
     if (userEventLimit.count >= finalConfig.maxRequests) {
-      socket.emit('error', { message: `Rate limit exceeded for ${eventName}` });
+      emitEvent(socket, 'error', { message: `Rate limit exceeded for ${eventName}` });
       return false;
     }
 
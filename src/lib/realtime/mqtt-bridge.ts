@@ -0,0 +1,410 @@
+import net from 'net';
+import { Aedes, AuthenticateError, Client, PublishPacket, Subscription } from 'aedes';
+import { Server as SocketIOServer } from 'socket.io';
+import { jwtService } from '../auth/jwt';
+import { environmentConfig } from '../config/environment';
+import { ChatRepository } from '../database/repositories/chat';
+import { UserRepository } from '../database/repositories/user';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { sendChatMessage, SendMessageInput } from './events/messaging';
+import { TrustLimitError } from '../moderation/trust';
+import { ServerEventName, validateServerEvent } from './protocol';
+
+const chatRepository = new ChatRepository();
+const userRepository = new UserRepository();
+
+const MEMBERSHIP_TTL = 60 * 1000; // 1 minute
+const MAX_TIMER_DELAY = 2 ** 31 - 1; // setTimeout fires at once past this
+const UUID_PATTERN = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i;
+
+// Mirrors hub events onto MQTT topics for clients that can't hold a
+// WebSocket open. Topic layout under the configured prefix:
+//
+//   <prefix>/user/<userId>/<event>      events for one user (subscribe: self only)
+//   <prefix>/chat/<chatId>/<event>      chat room events (subscribe: participants)
+//   <prefix>/broadcast/<event>          hub-wide events such as presence
+//   <prefix>/in/chat/<chatId>           publish a message (participants only)
+//
+// Clients connect with their access token as the MQTT password; the user id
+// in the token drives every ACL decision. The connection is closed when the
+// token expires or the user is banned, and chat subscriptions are dropped
+// when the user leaves or is removed from the chat.
+export class MqttBridge {
+  private broker: Aedes | null = null;
+  private server: net.Server | null = null;
+  private io: SocketIOServer | null = null;
+  private prefix = 'bro';
+  private clientUsers = new Map<string, string>(); // MQTT client id -> userId
+  private clients = new Map<string, Client>();
+  private expiryTimers = new Map<string, NodeJS.Timeout>(); // MQTT client id -> token expiry
+  private chatTopics = new Map<string, Map<string, Set<string>>>(); // MQTT client id -> chatId -> subscribed topics
+  private membership = new Map<string, number>(); // userId:chatId -> expiresAt
+
+  isEnabled(): boolean {
+    return environmentConfig.getValue('MQTT_ENABLED');
+  }
+
+  // Start the broker and mirror events broadcast through the Socket.IO adapter
+  start(io: SocketIOServer): void {
+    if (this.broker) return;
+
+    const port = environmentConfig.getValue('MQTT_PORT');
+    this.prefix = environmentConfig.getValue('MQTT_TOPIC_PREFIX');
+    this.io = io;
+
+    this.broker = new Aedes({
+      authenticate: (client, username, password, done) => {
+        this.authenticate(client, username, password).then(
+          session => {
+            if (session) {
+              this.clientUsers.set(client.id, session.userId);
+              this.clients.set(client.id, client);
+              this.scheduleExpiry(client, session.expiresAt);
+              return done(null, true);
+            }
+            const error = new Error('Authentication failed') as AuthenticateError;
+            error.returnCode = 4; // bad username or password
+            done(error, false);
+          },
+          error => {
+            logger.error('MQTT authentication error', error);
+            const authError = new Error('Authentication failed') as AuthenticateError;
+            authError.returnCode = 3; // server unavailable
+            done(authError, false);
+          }
+        );
+      },
+      authorizeSubscribe: (client, subscription, done) => {
+        this.authorizeSubscribe(client, subscription).then(
+          allowed => done(null, allowed ? subscription : null),
+          error => {
+            logger.error('MQTT subscribe authorization error', error);
+            done(null, null);
+          }
+        );
+      },
+      authorizePublish: (client, packet, done) => {
+        this.authorizePublish(client, packet).then(
+          allowed => done(allowed ? null : new Error('Publish not allowed')),
+          error => {
+            logger.error('MQTT publish authorization error', error);
+            done(new Error('Publish not allowed'));
+          }
+        );
+      },
+    });
+
+    this.broker.on('client', client => this.handleConnect(client));
+    this.broker.on('clientDisconnect', client => this.handleDisconnect(client));
+    this.broker.on('publish', (packet, client) => {
+      if (client) {
+        this.handleInbound(client, packet);
+      }
+    });
+
+    this.tapAdapter(io);
+
+    this.server = net.createServer(this.broker.handle);
+    this.server.listen(port, () => {
+      logger.info('MQTT bridge listening', { port, prefix: this.prefix });
+    });
+  }
+
+  async stop(): Promise<void> {
+    await new Promise<void>(resolve => this.server ? this.server.close(() => resolve()) : resolve());
+    await new Promise<void>(resolve => this.broker ? this.broker.close(() => resolve()) : resolve());
+    this.server = null;
+    this.broker = null;
+    this.expiryTimers.forEach(timer => clearTimeout(timer));
+    this.clientUsers.clear();
+    this.clients.clear();
+    this.expiryTimers.clear();
+    this.chatTopics.clear();
+    this.membership.clear();
+  }
+
+  getConnectedClients(): number {
+    return this.clientUsers.size;
+  }
+
+  // Close every MQTT connection a user has, e.g. after a ban
+  disconnectUser(userId: string): void {
+    this.clientUsers.forEach((clientUserId, clientId) => {
+      if (clientUserId === userId) {
+        this.clients.get(clientId)?.close();
+      }
+    });
+  }
+
+  // Forward every room broadcast to the matching topics. Socket.IO routes all
+  // io.to(room)/socket.to(room) emits through adapter.broadcast, so wrapping
+  // it covers the hub without touching individual handlers.
+  private tapAdapter(io: SocketIOServer): void {
+    const adapter = io.of('/').adapter;
+    const broadcast = adapter.broadcast.bind(adapter);
+
+    adapter.broadcast = (packet: any, opts: any) => {
+      broadcast(packet, opts);
+
+      try {
+        const [event, payload] = packet.data || [];
+        if (typeof event === 'string') {
+          this.mirror(event, payload, opts.rooms as Set<string>);
+        }
+      } catch (error) {
+        logger.error('MQTT mirror error', error);
+      }
+    };
+  }
+
+  private mirror(event: string, payload: unknown, rooms: Set<string>): void {
+    if (!this.broker) return;
+
+    const topics = new Set<string>();
+    if (rooms.size === 0) {
+      topics.add(`${this.prefix}/broadcast/${event}`);
+    }
+    rooms.forEach(room => {
+      const [kind, id] = room.split(':');
+      if ((kind === 'user' || kind === 'chat') && id) {
+        topics.add(`${this.prefix}/${kind}/${id}/${event}`);
+      }
+    });
+    if (topics.size === 0) return;
+
+    const body = Buffer.from(JSON.stringify(payload ?? null));
+    topics.forEach(topic => this.publish(topic, body));
+    metricsCollector.incrementCounter('mqtt_events_mirrored', topics.size, { event });
+
+    // The member's own clients get the event above, then lose the chat
+    const data = payload as Record<string, string> | null;
+    if (event === 'group:member:removed' && data) {
+      this.revokeChat(data.removedUser, data.groupId);
+    } else if (event === 'group:member:left' && data) {
+      this.revokeChat(data.leftUser, data.groupId);
+    }
+  }
+
+  // Drop a user's subscriptions to a chat they are no longer in
+  private revokeChat(userId: string, chatId: string): void {
+    this.membership.delete(`${userId}:${chatId}`);
+
+    this.clientUsers.forEach((clientUserId, clientId) => {
+      const topics = clientUserId === userId && this.chatTopics.get(clientId)?.get(chatId);
+      if (!topics) return;
+
+      this.chatTopics.get(clientId)!.delete(chatId);
+      this.clients.get(clientId)?.unsubscribe(
+        Array.from(topics).map(topic => ({ topic, qos: 0 as const })),
+        error => {
+          if (error) {
+            logger.error('MQTT unsubscribe failed', error, { userId, chatId });
+          }
+        }
+      );
+    });
+  }
+
+  private publish(topic: string, payload: Buffer): void {
+    this.broker?.publish({
+      cmd: 'publish',
+      topic,
+      payload,
+      qos: 1,
+      retain: false,
+      dup: false,
+    }, error => {
+      if (error) {
+        logger.error('MQTT publish failed', error, { topic });
+      }
+    });
+  }
+
+  // Send an event to one user's topics only (replies to their own publishes)
+  private publishToUser(userId: string, event: ServerEventName, payload: unknown): void {
+    validateServerEvent(event, payload);
+    this.publish(`${this.prefix}/user/${userId}/${event}`, Buffer.from(JSON.stringify(payload)));
+  }
+
+  private async authenticate(
+    client: Client,
+    username: string | undefined,
+    password: Buffer | undefined
+  ): Promise<{ userId: string; expiresAt: number } | null> {
+    if (!password) return null;
+
+    const result = await jwtService.verifyAccessToken(password.toString());
+    if (!result.valid || !result.payload) {
+      logger.warn('MQTT client rejected', { clientId: client.id, reason: result.error });
+      return null;
+    }
+
+    const { userId } = result.payload;
+    if (username && username !== userId) return null;
+
+    const user = await userRepository.findById(userId);
+    if (!user || user.isBanned) return null;
+
+    return { userId, expiresAt: result.payload.exp * 1000 };
+  }
+
+  // Close the connection when its token expires; the client reconnects
+  // with a fresh one
+  private scheduleExpiry(client: Client, expiresAt: number): void {
+    clearTimeout(this.expiryTimers.get(client.id));
+
+    const delay = Math.min(Math.max(expiresAt - Date.now(), 0), MAX_TIMER_DELAY);
+    this.expiryTimers.set(client.id, setTimeout(() => {
+      logger.info('MQTT session expired', { clientId: client.id, userId: this.clientUsers.get(client.id) });
+      metricsCollector.incrementCounter('mqtt_sessions_expired');
+      client.close();
+    }, delay));
+  }
+
+  private async authorizeSubscribe(client: Client | null, subscription: Subscription): Promise<boolean> {
+    const userId = client && this.clientUsers.get(client.id);
+    if (!userId) return false;
+
+    const levels = this.topicLevels(subscription.topic);
+    if (!levels) return false;
+
+    const [scope, id] = levels;
+    switch (scope) {
+      case 'broadcast':
+        return true;
+      case 'user':
+        return id === userId;
+      case 'chat': {
+        if (!id || this.isWildcard(id) || !(await this.isChatMember(userId, id))) return false;
+
+        // Remembered so the subscription can be dropped when the user leaves
+        const chats = this.chatTopics.get(client!.id) || new Map<string, Set<string>>();
+        chats.set(id, (chats.get(id) || new Set<string>()).add(subscription.topic));
+        this.chatTopics.set(client!.id, chats);
+        return true;
+      }
+      default:
+        return false;
+    }
+  }
+
+  private async authorizePublish(client: Client | null, packet: PublishPacket): Promise<boolean> {
+    // Publishes from the bridge itself
+    if (!client) return true;
+
+    const userId = this.clientUsers.get(client.id);
+    const levels = this.topicLevels(packet.topic);
+    if (!userId || !levels) return false;
+
+    const [scope, kind, chatId] = levels;
+    return scope === 'in' && kind === 'chat' && levels.length === 3 &&
+      this.isChatMember(userId, chatId);
+  }
+
+  private async handleInbound(client: Client, packet: PublishPacket): Promise<void> {
+    const userId = this.clientUsers.get(client.id);
+    const levels = this.topicLevels(packet.topic);
+    if (!userId || !levels || levels[0] !== 'in') return;
+
+    const chatId = levels[2];
+    let data: Omit<SendMessageInput, 'chatId'>;
+
+    try {
+      data = JSON.parse(packet.payload.toString());
+    } catch {
+      return this.publishToUser(userId, 'error', { message: 'Invalid message payload' });
+    }
+
+    if (typeof data?.content !== 'string') {
+      return this.publishToUser(userId, 'error', { message: 'Invalid message payload' });
+    }
+    if (data.clientMessageId !== undefined && (typeof data.clientMessageId !== 'string' || !UUID_PATTERN.test(data.clientMessageId))) {
+      return this.publishToUser(userId, 'error', { message: 'clientMessageId must be a UUID' });
+    }
+
+    metricsCollector.incrementCounter('mqtt_messages_received');
+
+    try {
+      const result = await sendChatMessage(this.io!, userId, { ...data, chatId }, Date.now(), {
+        platform: 'mqtt',
+        region: 'unknown',
+      });
+      if (!result) {
+        return this.publishToUser(userId, 'error', { message: 'Not authorized to send message to this chat' });
+      }
+
+      if (result.message) {
+        this.publishToUser(userId, 'message:sent', {
+          messageId: result.message._id.toString(),
+          tempId: data.tempId,
+          clientMessageId: data.clientMessageId,
+          ...(result.duplicate && { duplicate: true }),
+        });
+      }
+    } catch (error) {
+      if (error instanceof TrustLimitError) {
+        return this.publishToUser(userId, 'error', { message: error.message });
+      }
+      logger.error('MQTT message send failed', error, { userId, chatId });
+      this.publishToUser(userId, 'error', { message: 'Failed to send message' });
+    }
+  }
+
+  private handleConnect(client: Client): void {
+    const userId = this.clientUsers.get(client.id);
+    if (!userId) return;
+
+    metricsCollector.recordGauge('mqtt_connected_clients', this.clientUsers.size);
+    userRepository.updateOnlineStatus(userId, true).catch(error => {
+      logger.error('Failed to update MQTT client presence', error, { userId });
+    });
+  }
+
+  private handleDisconnect(client: Client): void {
+    const userId = this.clientUsers.get(client.id);
+    this.clientUsers.delete(client.id);
+    this.clients.delete(client.id);
+    this.chatTopics.delete(client.id);
+    clearTimeout(this.expiryTimers.get(client.id));
+    this.expiryTimers.delete(client.id);
+    if (!userId) return;
+
+    metricsCollector.recordGauge('mqtt_connected_clients', this.clientUsers.size);
+
+    // Stay online while the user has another MQTT connection
+    const stillConnected = Array.from(this.clientUsers.values()).includes(userId);
+    if (!stillConnected) {
+      userRepository.updateOnlineStatus(userId, false).catch(error => {
+        logger.error('Failed to update MQTT client presence', error, { userId });
+      });
+    }
+  }
+
+  // Chat membership, cached briefly since every subscribe/publish checks it
+  private async isChatMember(userId: string, chatId: string): Promise<boolean> {
+    const key = `${userId}:${chatId}`;
+    const expiresAt = this.membership.get(key);
+    if (expiresAt && expiresAt > Date.now()) return true;
+
+    const member = await chatRepository.isParticipant(chatId, userId);
+    if (member) {
+      this.membership.set(key, Date.now() + MEMBERSHIP_TTL);
+    } else {
+      this.membership.delete(key);
+    }
+    return member;
+  }
+
+  // Topic levels below the prefix, or null for topics outside it
+  private topicLevels(topic: string): string[] | null {
+    if (!topic.startsWith(`${this.prefix}/`)) return null;
+    return topic.slice(this.prefix.length + 1).split('/');
+  }
+
+  private isWildcard(level: string): boolean {
+    return level === '+' || level === '#';
+  }
+}
+
+export const mqttBridge = new MqttBridge();
@@ -0,0 +1,1022 @@
+import { randomBytes } from 'crypto';
+import { z } from 'zod';
+import { zodToJsonSchema } from 'zod-to-json-schema';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Bump when an event is removed or a field changes incompatibly. Additive
+// changes (new events, new optional fields) keep the version.
+export const EVENT_PROTOCOL_VERSION = 1;
+
+// Payloads are validated in their wire form (after JSON serialization), so
+// ObjectIds are strings and Dates are ISO-8601 strings.
+const id = z.string().min(1);
+const timestamp = z.string().datetime();
+const callType = z.enum(['voice', 'video']);
+
+const userSummary = z.object({
+  userId: id,
+  displayName: z.string(),
+  avatar: z.string().optional(),
+});
+
+// Capture and encoder settings negotiated for a call participant
+const mediaConstraints = z.object({
+  audio: z.object({
+    noiseSuppression: z.boolean(),
+    echoCancellation: z.boolean(),
+    autoGainControl: z.boolean(),
+  }),
+  maxAudioBitrate: z.number().optional(),
+  maxVideoBitrate: z.number().optional(),
+});
+
+// End-to-end encryption parameters for a call (insertable streams / SFrame)
+const callEncryption = z.object({
+  enabled: z.boolean(),
+  cipherSuites: z.array(z.string()),
+  epoch: z.number().int(),
+});
+
+// Populated references come through as objects, unpopulated ones as ids
+const ref = z.union([id, z.object({ _id: id }).passthrough()]);
+
+const message = z.object({
+  _id: id,
+  chatId: ref,
+  seq: z.number().int().optional(), // per chat, for gap detection; see GET messages/range
+  senderId: ref,
+  clientMessageId: z.string().optional(), // as sent by the sender's client
+  content: z.string(),
+  type: z.enum(['text', 'image', 'video', 'audio', 'document', 'voice', 'location', 'contact', 'sticker']),
+  createdAt: timestamp,
+}).passthrough();
+
+const group = z.object({
+  _id: id,
+  type: z.literal('group'),
+  participants: z.array(ref),
+  groupInfo: z.object({ name: z.string() }).passthrough().optional(),
+}).passthrough();
+
+// A group's always-on voice channel and who is in it
+const voiceChannel = z.object({
+  id,
+  groupId: id,
+  name: z.string(),
+  topic: z.string().optional(),
+  pushToTalk: z.boolean(),
+  speakerMode: z.enum(['open', 'speakers']),
+  speakers: z.array(id),
+  participants: z.array(z.object({
+    userId: id,
+    joinedAt: timestamp,
+    muted: z.boolean(),
+    mutedByAdmin: z.boolean(),
+    pushToTalk: z.boolean(),
+    canSpeak: z.boolean(),
+  })),
+  participantCount: z.number().int(),
+  createdBy: id,
+  createdAt: timestamp,
+});
+
+const iceServer = z.object({
+  urls: z.union([z.string(), z.array(z.string())]),
+  username: z.string().optional(),
+  credential: z.string().optional(),
+});
+
+// A live broadcast of a call, as viewers see it
+const broadcast = z.object({
+  id,
+  callId: id,
+  chatId: id.optional(),
+  hostId: id,
+  title: z.string().optional(),
+  audience: z.enum(['chat', 'link']),
+  status: z.enum(['live', 'ended']),
+  watchUrl: z.string(),
+  playbackUrl: z.string(), // WHEP or HLS
+  viewerCount: z.number().int(),
+  startedAt: timestamp,
+  endedAt: timestamp.optional(),
+});
+
+const errorPayload = z.object({ message: z.string() });
+
+export interface EventDefinition<T extends z.ZodTypeAny = z.ZodTypeAny> {
+  since: number; // protocol version that introduced the event
+  description: string;
+  schema: T;
+  reliable?: boolean; // acknowledged and resent, see realtime/delivery
+  droppable?: boolean; // may be dropped for slow clients, see realtime/backpressure
+  reducible?: boolean; // media previews stripped for low-data devices, see realtime/low-data
+  replayable?: boolean; // kept for reconnecting clients, see realtime/replay
+}
+
+function defineEvent<T extends z.ZodTypeAny>(
+  since: number,
+  description: string,
+  schema: T,
+  options: { reliable?: boolean; droppable?: boolean; reducible?: boolean; replayable?: boolean } = {}
+): EventDefinition<T> {
+  return {
+    since,
+    description,
+    schema,
+    ...(options.reliable && { reliable: true }),
+    ...(options.droppable && { droppable: true }),
+    ...(options.reducible && { reducible: true }),
+    ...(options.replayable && { replayable: true }),
+  };
+}
+
+// Every event the server emits to clients
+export const serverEvents = {
+  'error': defineEvent(1, 'Generic request failure', errorPayload),
+
+  // Messaging
+  'message:new': defineEvent(1, 'New message in a joined chat', message, { reliable: true, reducible: true, replayable: true }),
+  'message:sent': defineEvent(1, 'Send acknowledgement to the sender', z.object({
+    messageId: id,
+    tempId: z.string().optional(),
+    clientMessageId: z.string().optional(),
+    duplicate: z.boolean().optional(), // a resend of a message the server already had
+  })),
+  'message:edited': defineEvent(1, 'Message content changed', message, { reducible: true, replayable: true }),
+  'message:deleted': defineEvent(1, 'Message deleted', z.object({
+    messageId: id,
+    deletedForEveryone: z.boolean(),
+  }), { replayable: true }),
+  'message:reaction:added': defineEvent(1, 'Reaction added to a message', z.object({
+    messageId: id,
+    userId: id,
+    emoji: z.string(),
+    customEmoji: z.object({ id, shortcode: z.string(), url: z.string().optional() }).optional(),
+    timestamp,
+  }), { replayable: true }),
+  'message:reaction:removed': defineEvent(1, 'Reaction removed from a message', z.object({
+    messageId: id,
+    userId: id,
+  }), { replayable: true }),
+  'message:delivery:receipt': defineEvent(1, 'Recipient device acknowledged a message', z.object({
+    messageId: id,
+    deliveredTo: id,
+    deliveredAt: timestamp,
+  }), { replayable: true }),
+  'message:read:receipt': defineEvent(1, 'Messages read by a participant', z.object({
+    messageIds: z.array(id),
+    readBy: id,
+    readAt: timestamp,
+  }), { replayable: true }),
+  'chat:joined': defineEvent(1, 'Socket joined a chat room', z.object({ chatId: id })),
+  'chat:left': defineEvent(1, 'Socket left a chat room', z.object({ chatId: id })),
+  'chat:appearance:updated': defineEvent(1, 'Your wallpaper or theme color of a chat changed, on this or another device', z.object({
+    chatId: id,
+    appearance: z.object({
+      wallpaper: z.string().optional(),
+      wallpaperMedia: z.object({ id, url: z.string(), thumbnailUrl: z.string().optional() }).optional(),
+      themeColor: z.string().optional(),
+    }),
+    version: z.number().int(),
+  })),
+  'chat:read-position:updated': defineEvent(1, 'Where you have read up to in a chat moved, on another device', z.object({
+    chatId: id,
+    readPosition: z.object({
+      messageId: id,
+      seq: z.number().int().optional(),
+      sentAt: timestamp,
+      readAt: timestamp,
+    }).nullable(),
+  }), { replayable: true }),
+  'chat:active-call': defineEvent(1, 'Call in progress in a chat started, changed or ended (null)', z.object({
+    chatId: id,
+    activeCall: z.object({
+      callId: id,
+      type: callType,
+      startedBy: id,
+      startedAt: timestamp,
+      participants: z.array(id),
+      participantCount: z.number().int(),
+      joinUrl: z.string(),
+    }).nullable(),
+  })),
+
+  // Typing
+  'typing:user:start': defineEvent(1, 'Participant started typing', z.object({
+    chatId: id,
+    userId: id,
+    user: z.object({ displayName: z.string(), avatar: z.string().optional() }),
+  }), { droppable: true }),
+  'typing:user:stop': defineEvent(1, 'Participant stopped typing', z.object({
+    chatId: id,
+    userId: id,
+  }), { droppable: true }),
+  'typing:current': defineEvent(1, 'Users currently typing in a chat', z.object({
+    chatId: id,
+    typingUsers: z.array(id),
+  }), { droppable: true }),
+
+  // Presence
+  'presence:changed': defineEvent(1, 'User changed presence status', z.object({
+    userId: id,
+    status: z.string(),
+    lastSeen: timestamp,
+  }), { droppable: true }),
+  'presence:pong': defineEvent(1, 'Reply to presence:ping', z.undefined()),
+  'user:presence:changed': defineEvent(1, 'User connected or fully disconnected', z.object({
+    userId: id,
+    isOnline: z.boolean(),
+    lastSeen: timestamp,
+  }), { droppable: true }),
+  'contact:activity:updated': defineEvent(1, 'A contact set or cleared their activity status', z.object({
+    userId: id,
+    activityStatus: z.object({
+      emoji: z.string().optional(),
+      text: z.string().optional(),
+      expiresAt: timestamp.optional(),
+      updatedAt: timestamp,
+    }).nullable(),
+  })),
+
+  // Calls
+  'call:initiated': defineEvent(1, 'Call created, sent to the caller', z.object({
+    callId: id,
+    type: callType,
+    participant: id,
+    media: mediaConstraints.optional(),
+    encryption: callEncryption.optional(),
+  })),
+  'call:incoming': defineEvent(1, 'Incoming call invitation', z.object({
+    callId: id,
+    type: callType,
+    initiator: z.union([id, userSummary]),
+    chatId: id.optional(),
+    media: mediaConstraints.optional(),
+    encryption: callEncryption.optional(),
+  }), { reliable: true }),
+  'call:recovered': defineEvent(1, 'Server restarted mid-call; send call:rejoin to stay in it', z.object({
+    callId: id,
+    rejoinBy: timestamp,
+  })),
+  'call:answered': defineEvent(1, 'Call answered', z.object({ callId: id, answeredBy: id })),
+  'call:rejected': defineEvent(1, 'Call rejected', z.object({ callId: id, rejectedBy: id })),
+  'call:ended': defineEvent(1, 'Call ended', z.object({
+    callId: id,
+    endedBy: id,
+    reason: z.enum(['normal', 'busy', 'missed', 'rejected', 'orphaned']).optional(),
+    summary: z.string().optional(),
+  })),
+  // Signaling events are relayed by both the socket handlers ({sdp, from})
+  // and the signaling service ({offer|answer, senderId})
+  'call:offer': defineEvent(1, 'WebRTC offer from another participant', z.union([
+    z.object({ callId: id, sdp: z.unknown(), from: id }),
+    z.object({ callId: id, offer: z.unknown(), senderId: id }),
+  ])),
+  'call:answer': defineEvent(1, 'WebRTC answer from another participant', z.object({
+    callId: id,
+    answer: z.unknown(),
+    senderId: id,
+  })),
+  'call:answer-sdp': defineEvent(1, 'WebRTC answer SDP relayed by the socket handler', z.object({
+    callId: id,
+    sdp: z.unknown(),
+    from: id,
+  })),
+  'call:ice-candidate': defineEvent(1, 'ICE candidate from another participant', z.union([
+    z.object({ callId: id, candidate: z.unknown(), from: id }),
+    z.object({ callId: id, candidate: z.unknown(), senderId: id }),
+  ])),
+  'call:participant-joined': defineEvent(1, 'Participant joined the call', z.object({ callId: id, userId: id })),
+  'call:ice-gathering-complete': defineEvent(1, 'Participant finished ICE gathering', z.object({ callId: id, userId: id })),
+  'call:quality-request': defineEvent(1, 'Server asks for call quality stats', z.object({ callId: id })),
+  'call:quality-update': defineEvent(1, 'Call quality metrics echo', z.object({
+    callId: id,
+    userId: id,
+    timestamp,
+  }).passthrough()),
+  'call:quality-suggestions': defineEvent(1, 'Suggestions for poor call quality', z.object({
+    callId: id,
+    suggestions: z.array(z.string()),
+  })),
+  'call:quality-action': defineEvent(1, 'Adapt your media to your connection: send less, turn video off, relay through TURN or go back up', z.object({
+    callId: id,
+    action: z.enum(['reduce', 'audio_only', 'relay', 'restore']),
+    level: z.enum(['full', 'reduced', 'audio_only']),
+    maxVideoBitrate: z.number().optional(), // kbps
+    maxAudioBitrate: z.number().optional(),
+    scaleResolutionDownBy: z.number().optional(),
+    video: z.boolean().optional(), // false: stop sending video
+    iceServers: z.array(iceServer).optional(), // relay: restart ICE with these
+    iceTransportPolicy: z.literal('relay').optional(),
+  })),
+  'call:quality-changed': defineEvent(1, 'A participant\'s connection got worse or recovered; send them no more than this', z.object({
+    callId: id,
+    userId: id,
+    level: z.enum(['full', 'reduced', 'audio_only']),
+    maxVideoBitrate: z.number().optional(),
+    maxAudioBitrate: z.number().optional(),
+    scaleResolutionDownBy: z.number().optional(),
+    video: z.boolean().optional(),
+  })),
+  'call:quality:saved': defineEvent(1, 'Call quality report stored', z.object({ callId: id })),
+  'call:rating-requested': defineEvent(1, 'A call you were in ended; ask for a rating and send it as call:quality', z.object({
+    callId: id,
+    type: z.enum(['voice', 'video']),
+    duration: z.number().optional(), // seconds
+    issues: z.array(z.string()), // tags the rating can carry
+  })),
+  'call:hold-changed': defineEvent(1, 'Participant put the call on hold or resumed it', z.object({
+    callId: id,
+    userId: id,
+    onHold: z.boolean(),
+  })),
+  'call:mute-changed': defineEvent(1, 'Participants were muted or unmuted', z.object({
+    callId: id,
+    userIds: z.array(id),
+    muted: z.boolean(),
+    mutedBy: id.optional(), // set when the host muted them
+  })),
+  'call:participant-removed': defineEvent(1, 'Host removed a participant', z.object({
+    callId: id,
+    userId: id,
+    removedBy: id,
+  })),
+  'call:lock-changed': defineEvent(1, 'Host locked or unlocked the call', z.object({
+    callId: id,
+    locked: z.boolean(),
+    changedBy: id,
+  })),
+  'call:host-changed': defineEvent(1, 'Host role transferred', z.object({
+    callId: id,
+    hostId: id,
+    previousHostId: id,
+  })),
+  'call:caption': defineEvent(1, 'Live caption; partials for the same captionId are replaced by later ones', z.object({
+    callId: id,
+    captionId: id,
+    speakerId: id,
+    text: z.string(),
+    language: z.string(),
+    final: z.boolean(),
+  })),
+  'call:captions:changed': defineEvent(1, 'Participant changed caption settings; send audio while active', z.object({
+    callId: id,
+    userId: id,
+    enabled: z.boolean(),
+    language: z.string(),
+    optOut: z.boolean(),
+    active: z.boolean(),
+  })),
+  'call:hand-changed': defineEvent(1, 'Participant raised or lowered their hand', z.object({
+    callId: id,
+    userId: id,
+    raised: z.boolean(),
+    changedBy: id.optional(), // set when the host lowered it
+  })),
+  'call:speaker-queue': defineEvent(1, 'Raised hands waiting to speak, in order; sent to the host only', z.object({
+    callId: id,
+    queue: z.array(z.object({ userId: id, position: z.number().int(), raisedAt: timestamp })),
+  })),
+  'call:speaker-changed': defineEvent(1, 'Host gave a participant the floor or took it back', z.object({
+    callId: id,
+    userId: id,
+    canSpeak: z.boolean(),
+    changedBy: id,
+  })),
+  'call:listen-only-changed': defineEvent(1, 'Host made the call listen-only or opened it up', z.object({
+    callId: id,
+    listenOnly: z.boolean(),
+    changedBy: id,
+  })),
+  'call:chat:message': defineEvent(1, 'In-call chat message; kept only while the call lasts', z.object({
+    messageId: id,
+    callId: id,
+    senderId: id,
+    text: z.string(),
+    sentAt: timestamp,
+    clientId: z.string().optional(), // only to the sender
+  })),
+  'call:chat:reaction': defineEvent(1, 'Reaction to an in-call message, or to the call without messageId', z.object({
+    callId: id,
+    messageId: id.optional(),
+    userId: id,
+    emoji: z.string(),
+    removed: z.boolean(),
+  })),
+  'call:chat:settings': defineEvent(1, 'Host changed whether the in-call chat is posted to the chat when the call ends', z.object({
+    callId: id,
+    postSummary: z.boolean(),
+    changedBy: id,
+  })),
+  'call:e2ee:key': defineEvent(1, 'Media key from another participant, wrapped with the chat key', z.object({
+    callId: id,
+    senderId: id,
+    epoch: z.number().int(),
+    keyId: z.number().int(),
+    cipherSuite: z.string(),
+    wrappedKey: z.string(),
+    deviceId: z.string().optional(),
+  })),
+  'call:e2ee:rekey': defineEvent(1, 'Membership changed; send a fresh key for the new epoch', z.object({
+    callId: id,
+    epoch: z.number().int(),
+    reason: z.enum(['joined', 'left']),
+    participants: z.array(id),
+  })),
+  'call:ring-cancelled': defineEvent(1, 'Stop ringing: the call was handled on another device or is over', z.object({
+    callId: id,
+    reason: z.enum(['answered_elsewhere', 'declined_elsewhere', 'ended', 'missed']),
+  })),
+  'call:scheduled': defineEvent(1, 'Call scheduled or rescheduled in a chat', z.object({
+    chatId: id,
+    scheduledCall: z.record(z.unknown()),
+  })),
+  'call:scheduled:cancelled': defineEvent(1, 'Scheduled call cancelled', z.object({
+    chatId: id,
+    scheduledCallId: id,
+  })),
+  'call:scheduled:reminder': defineEvent(1, 'Scheduled call starts soon, sent to invitees', z.object({
+    chatId: id,
+    scheduledCallId: id,
+    title: z.string(),
+    startsAt: timestamp,
+  })),
+  'call:scheduled:started': defineEvent(1, 'Scheduled call is live; join with the callId', z.object({
+    chatId: id,
+    scheduledCallId: id,
+    callId: id,
+  })),
+  'call:error': defineEvent(1, 'Call operation failed', errorPayload),
+
+  // Live broadcasts
+  'broadcast:started': defineEvent(1, 'A call you are in, or a call in one of your chats, is being broadcast', z.object({ broadcast })),
+  'broadcast:ended': defineEvent(1, 'Broadcast ended, sent to its viewers and the call\'s participants', z.object({
+    broadcastId: id,
+    callId: id,
+  })),
+  'broadcast:watching': defineEvent(1, 'You are watching a broadcast; play it from playbackUrl', z.object({ broadcast })),
+  'broadcast:viewers': defineEvent(1, 'Number of people watching a broadcast changed', z.object({
+    broadcastId: id,
+    callId: id,
+    viewerCount: z.number().int(),
+  }), { droppable: true }),
+  'broadcast:viewer-joined': defineEvent(1, 'Someone started watching, sent to the call\'s participants', z.object({
+    broadcastId: id,
+    callId: id,
+    viewer: userSummary,
+  }), { droppable: true }),
+  'broadcast:viewer-left': defineEvent(1, 'Someone stopped watching, sent to the call\'s participants', z.object({
+    broadcastId: id,
+    callId: id,
+    userId: id,
+  }), { droppable: true }),
+  'broadcast:error': defineEvent(1, 'Broadcast operation failed', errorPayload),
+
+  // Voice channels
+  'voice:joined': defineEvent(1, 'You joined a voice channel; connect to each participant with voice:offer', z.object({
+    channel: voiceChannel,
+    iceServers: z.array(iceServer),
+    media: mediaConstraints,
+  })),
+  'voice:left': defineEvent(1, 'This connection is no longer in a voice channel', z.object({
+    channelId: id,
+    reason: z.enum(['left', 'moved', 'removed']), // moved: you joined a channel elsewhere
+  })),
+  'voice:channel:updated': defineEvent(1, 'A voice channel of one of your groups was created or changed, or someone joined or left it', z.object({
+    groupId: id,
+    channel: voiceChannel,
+  })),
+  'voice:channel:removed': defineEvent(1, 'A voice channel was removed from one of your groups', z.object({
+    groupId: id,
+    channelId: id,
+  })),
+  'voice:offer': defineEvent(1, 'WebRTC offer from another voice channel participant', z.object({
+    channelId: id,
+    sdp: z.unknown(),
+    from: id,
+  })),
+  'voice:answer-sdp': defineEvent(1, 'WebRTC answer from another voice channel participant', z.object({
+    channelId: id,
+    sdp: z.unknown(),
+    from: id,
+  })),
+  'voice:ice-candidate': defineEvent(1, 'ICE candidate from another voice channel participant', z.object({
+    channelId: id,
+    candidate: z.unknown(),
+    from: id,
+  })),
+  'voice:talking': defineEvent(1, 'Participant pressed or released push-to-talk, or started or stopped talking', z.object({
+    channelId: id,
+    userId: id,
+    talking: z.boolean(),
+  }), { droppable: true }),
+  'voice:error': defineEvent(1, 'Voice channel operation failed', errorPayload),
+
+  // Groups (member changes)
+  'group:created': defineEvent(1, 'Added to a newly created group', z.object({
+    group,
+    createdBy: userSummary.omit({ avatar: true }),
+  })),
+  'group:create:success': defineEvent(1, 'Group created, sent to the creator', z.object({ group })),
+  'group:create:error': defineEvent(1, 'Group creation failed', errorPayload),
+  'group:joined': defineEvent(1, 'Socket joined a group room', z.object({ groupId: id })),
+  'group:members:added': defineEvent(1, 'Members added to a group', z.object({
+    groupId: id,
+    addedUsers: z.array(id),
+    addedBy: id,
+    group: group.nullable(),
+  }), { replayable: true }),
+  'group:added': defineEvent(1, 'You were added to a group', z.object({
+    group: group.nullable(),
+    addedBy: userSummary.omit({ avatar: true }),
+  }), { replayable: true }),
+  'group:member:removed': defineEvent(1, 'Member removed from a group', z.object({
+    groupId: id,
+    removedUser: id,
+    removedBy: id,
+  }), { replayable: true }),
+  'group:removed': defineEvent(1, 'You were removed from a group', z.object({
+    groupId: id,
+    removedBy: userSummary.omit({ avatar: true }),
+  }), { replayable: true }),
+  'group:member:promoted': defineEvent(1, 'Member promoted to admin', z.object({
+    groupId: id,
+    promotedUser: id,
+    promotedBy: id,
+  }), { replayable: true }),
+  'group:member:demoted': defineEvent(1, 'Admin demoted to member', z.object({
+    groupId: id,
+    demotedUser: id,
+    demotedBy: id,
+  }), { replayable: true }),
+  'group:member:left': defineEvent(1, 'Member left a group', z.object({
+    groupId: id,
+    leftUser: id,
+    user: z.object({ displayName: z.string() }),
+  }), { replayable: true }),
+  'group:left': defineEvent(1, 'You left a group', z.object({ groupId: id })),
+  'group:guest:joined': defineEvent(1, 'A guest joined a group through a guest link', z.object({
+    groupId: id,
+    guest: z.object({
+      userId: id,
+      displayName: z.string(),
+      isGuest: z.literal(true),
+      expiresAt: timestamp, // removed from the group then
+      capabilities: z.object({ calls: z.boolean(), fileUpload: z.boolean() }),
+    }),
+  })),
+  'group:guest:left': defineEvent(1, 'A guest\'s access ended and they were removed from the group', z.object({
+    groupId: id,
+    guestId: id,
+    reason: z.enum(['expired', 'revoked']),
+  })),
+  'group:updated': defineEvent(1, 'Group info changed', z.object({
+    group: group.nullable(),
+    updatedBy: id,
+  }), { replayable: true }),
+  'group:update:conflict': defineEvent(1, 'Group changed since the version sent with group:update; reload and retry', z.object({
+    groupId: id,
+    currentVersion: z.number().int(),
+  })),
+  'group:emoji:updated': defineEvent(1, 'Custom emoji of a group added or removed; refetch the list', z.object({
+    groupId: id,
+  })),
+  'group:message:pending': defineEvent(1, 'Message in a moderated group waits for approval, sent to its admins', z.object({
+    groupId: id,
+    message,
+  })),
+  'group:message:reviewed': defineEvent(1, 'Held message approved or rejected, sent to its author and the admins', z.object({
+    groupId: id,
+    messageId: id,
+    decision: z.enum(['approve', 'reject']),
+    reason: z.string().optional(),
+    reviewedBy: id,
+  })),
+
+  // QR login (sent to the qr:<qrId> room of the waiting web client)
+  'qr:scanned': defineEvent(1, 'QR code scanned by a phone', z.object({
+    qrId: id,
+    userInfo: z.object({ displayName: z.string(), avatar: z.string().optional() }),
+  })),
+  'qr:confirmed': defineEvent(1, 'QR login approved', z.object({
+    qrId: id,
+    deviceId: id,
+    tokens: z.object({
+      accessToken: z.string(),
+      refreshToken: z.string(),
+      expiresIn: z.number(),
+    }),
+  })),
+  'qr:rejected': defineEvent(1, 'QR login rejected', z.object({ qrId: id })),
+
+  // Linked devices (sent to the user)
+  'device:linked': defineEvent(1, 'A desktop was linked to the account by QR code', z.object({
+    deviceId: id,
+    platform: z.string(),
+    deviceModel: z.string().optional(),
+    linkedBy: id,
+    linkedAt: timestamp,
+  })),
+  'device:revoked': defineEvent(1, 'A linked device was signed out', z.object({ deviceId: id })),
+  'device:low-data:changed': defineEvent(1, 'Low-data mode turned on or off for one of the account\'s devices', z.object({
+    deviceId: id,
+    lowDataMode: z.boolean(),
+  })),
+
+  // Replay for reconnecting clients (see realtime/replay), after the
+  // replayed events
+  'events:replayed': defineEvent(1, 'Events missed since auth.lastEventId were sent again', z.object({
+    count: z.number().int(),
+    lastEventId: z.string(),
+  })),
+  'events:resync-required': defineEvent(1, 'Events since auth.lastEventId are no longer kept; resync over REST', z.object({
+    reason: z.enum(['window_exceeded', 'invalid_event_id', 'unavailable']),
+  })),
+
+  // Socket session expiry (see realtime/session-auth)
+  'auth:expiring': defineEvent(1, 'The access token the socket signed in with expires soon; send auth:refresh', z.object({
+    expiresAt: timestamp,
+    expiresIn: z.number().int(), // seconds
+  })),
+  'auth:refreshed': defineEvent(1, 'auth:refresh accepted; the session now lasts until expiresAt', z.object({
+    expiresAt: timestamp,
+  })),
+  'auth:refresh:failed': defineEvent(1, 'auth:refresh rejected; the old token still holds until it expires', errorPayload),
+  'auth:expired': defineEvent(1, 'The access token expired without a refresh; the socket is disconnected next', z.object({
+    expiredAt: timestamp,
+  })),
+
+  // Chat history import jobs (sent to the importing user)
+  'import:progress': defineEvent(1, 'Import job progress', z.object({
+    jobId: id,
+    totalMessages: z.number(),
+    processedMessages: z.number(),
+    importedMedia: z.number(),
+    skippedMessages: z.number(),
+  })),
+  'import:completed': defineEvent(1, 'Import job finished', z.object({
+    jobId: id,
+    chatId: id,
+    importedMessages: z.number(),
+    importedMedia: z.number(),
+    skippedMessages: z.number(),
+  })),
+  'import:failed': defineEvent(1, 'Import job failed', z.object({ jobId: id, error: z.string() })),
+
+  // Reminders
+  'reminder:created': defineEvent(1, 'Reminder scheduled, sent to its owner', z.object({
+    reminderId: id,
+    text: z.string(),
+    dueAt: timestamp,
+    chatId: id.optional(),
+    messageId: id.optional(),
+  })),
+  'reminder:due': defineEvent(1, 'Reminder fired', z.object({
+    reminderId: id,
+    text: z.string(),
+    dueAt: timestamp,
+    chatId: id.optional(),
+    messageId: id.optional(),
+    deliveryMessageId: id.optional(),
+  })),
+  'reminder:cancelled': defineEvent(1, 'Reminder cancelled', z.object({ reminderId: id })),
+
+  // File sharing (sent to users given access, directly or through a chat)
+  'media:shared': defineEvent(1, 'A file was shared with you or one of your chats', z.object({
+    grantId: id,
+    mediaId: id,
+    grantedBy: id,
+    chatId: id.optional(),
+    type: z.string(),
+    originalName: z.string(),
+    expiresAt: timestamp.optional(),
+  })),
+
+  // Slash commands
+  'command:response': defineEvent(1, 'Reply to a slash command, visible only to the invoker', z.object({
+    chatId: id,
+    command: z.string(),
+    text: z.string(),
+    attachments: z.array(z.record(z.unknown())).optional(),
+    tempId: z.string().optional(),
+  })),
+  'poll:updated': defineEvent(1, 'Votes on a poll changed', z.object({
+    messageId: id,
+    chatId: id,
+    poll: z.object({
+      question: z.string(),
+      options: z.array(z.object({
+        text: z.string(),
+        votes: z.array(id), // on anonymous polls, only the recipient's own vote unless they are a group admin
+        voteCount: z.number().int(),
+      })),
+      closed: z.boolean(),
+      anonymous: z.boolean().optional(),
+    }),
+  }), { replayable: true }),
+
+  // Sign-in approvals (sent to the user's devices)
+  'security:login:pending': defineEvent(1, 'Sign-in from a new device or location waiting for approval', z.object({
+    loginApprovalId: id,
+    deviceId: z.string(),
+    anomalies: z.array(z.enum(['new_device', 'new_location'])),
+    country: z.string().optional(),
+    city: z.string().optional(),
+    platform: z.string().optional(),
+    expiresAt: timestamp,
+  })),
+  'security:login:decided': defineEvent(1, 'Sign-in approved or denied; a denied device has been signed out', z.object({
+    loginApprovalId: id,
+    deviceId: z.string(),
+    status: z.enum(['approved', 'denied']),
+  })),
+
+  // Legal notices (sent to everyone connected)
+  'legal:updated': defineEvent(1, 'New version of a legal notice published or now in force; review via /api/client/legal', z.object({
+    key: z.string(),
+    title: z.string(),
+    version: z.number().int(),
+    summary: z.string().optional(),
+    effectiveAt: timestamp,
+    inForce: z.boolean(),
+    mandatory: z.boolean(),
+  })),
+};
+
+// Every event clients may emit to the server
+export const clientEvents = {
+  'message:send': defineEvent(1, 'Send a message', z.object({
+    chatId: id,
+    content: z.string(),
+    type: message.shape.type.default('text'),
+    replyTo: id.optional(),
+    mediaId: id.optional(),
+    metadata: z.record(z.unknown()).optional(),
+    tempId: z.string().optional(),
+    clientMessageId: z.string().uuid().optional(), // stored and echoed back; resends with it are deduped
+  })),
+  'message:edit': defineEvent(1, 'Edit own message', z.object({ messageId: id, content: z.string() })),
+  'message:delete': defineEvent(1, 'Delete a message', z.object({
+    messageId: id,
+    deleteForEveryone: z.boolean().optional(),
+  })),
+  'message:react': defineEvent(1, 'React to a message; custom emoji by customEmojiId or as :shortcode:', z.object({
+    messageId: id,
+    emoji: z.string(),
+    customEmojiId: id.optional(),
+  })),
+  'message:unreact': defineEvent(1, 'Remove own reaction', z.object({ messageId: id })),
+  'poll:vote': defineEvent(1, 'Vote on a poll, or retract with optionIndex null', z.object({
+    messageId: id,
+    optionIndex: z.number().int().min(0).nullable(),
+  })),
+  'message:delivered': defineEvent(1, 'Acknowledge delivery to this device', z.object({ messageId: id })),
+  'message:read': defineEvent(1, 'Mark messages as read', z.object({ messageIds: z.array(id) })),
+  'chat:join': defineEvent(1, 'Join a chat room', z.object({ chatId: id })),
+  'chat:leave': defineEvent(1, 'Leave a chat room', z.object({ chatId: id })),
+  'typing:start': defineEvent(1, 'Start typing', z.object({ chatId: id })),
+  'typing:stop': defineEvent(1, 'Stop typing', z.object({ chatId: id })),
+  'typing:get': defineEvent(1, 'Get users currently typing', z.object({ chatId: id })),
+  'presence:update': defineEvent(1, 'Set presence status', z.object({ status: z.string() })),
+  'presence:ping': defineEvent(1, 'Keep presence alive', z.undefined()),
+  'event:ack': defineEvent(1, 'Acknowledge a reliable event by its seq; ok false asks for a resend', z.object({
+    seq: z.string(),
+    ok: z.boolean().default(true),
+  })),
+  'auth:refresh': defineEvent(1, 'Continue the session with a new access token for the same user and device', z.object({
+    token: z.string().min(1),
+  })),
+  'call:initiate': defineEvent(1, 'Start a call', z.object({
+    participantId: id,
+    type: callType,
+    chatId: id.optional(),
+    encrypted: z.boolean().optional(),
+  })),
+  'call:answer': defineEvent(1, 'Answer a call', z.object({ callId: id })),
+  'call:reject': defineEvent(1, 'Reject a call', z.object({ callId: id })),
+  'call:end': defineEvent(1, 'End a call', z.object({ callId: id })),
+  'call:rejoin': defineEvent(1, 'Reconnected to a call in progress, e.g. after a server restart', z.object({ callId: id })),
+  'call:ice-candidate': defineEvent(1, 'Send an ICE candidate', z.object({ callId: id, candidate: z.unknown() })),
+  'call:offer': defineEvent(1, 'Send a WebRTC offer', z.object({ callId: id, sdp: z.unknown() })),
+  'call:answer-sdp': defineEvent(1, 'Send a WebRTC answer', z.object({ callId: id, sdp: z.unknown() })),
+  'call:stats': defineEvent(1, 'Connection stats, sent every few seconds in a call and on call:quality-request', z.object({
+    callId: id,
+    packetLoss: z.number(),
+    latency: z.number(),
+    connectionQuality: z.enum(['poor', 'fair', 'good', 'excellent']).optional(),
+    videoQuality: z.enum(['low', 'medium', 'high']).optional(),
+    audioLevel: z.number().optional(),
+    candidateType: z.enum(['host', 'srflx', 'prflx', 'relay']).optional(),
+  })),
+  'call:quality': defineEvent(1, 'Rate a call once it ended', z.object({
+    callId: id,
+    rating: z.number(),
+    issues: z.array(z.string()).optional(), // from call:rating-requested
+    feedback: z.string().optional(),
+  })),
+  'call:captions:audio': defineEvent(1, 'Microphone audio for live captions (binary 16 kHz mono PCM16, up to 1s per chunk)', z.object({
+    callId: id,
+    audio: z.unknown(),
+  })),
+  'call:e2ee:key': defineEvent(1, 'Hand your media key for the current epoch to other participants', z.object({
+    callId: id,
+    epoch: z.number().int(),
+    keyId: z.number().int(),
+    cipherSuite: z.string(),
+    recipients: z.array(z.object({ userId: id, deviceId: z.string().optional(), wrappedKey: z.string() })),
+  })),
+  'call:hand': defineEvent(1, 'Raise or lower your hand; the host can lower anyone\'s', z.object({
+    callId: id,
+    raised: z.boolean(),
+    userId: id.optional(), // someone else's, host only
+  })),
+  'call:chat:send': defineEvent(1, 'Send an in-call chat message', z.object({
+    callId: id,
+    text: z.string(),
+    clientId: z.string().optional(),
+  })),
+  'call:chat:react': defineEvent(1, 'React to an in-call message, or to the call without messageId', z.object({
+    callId: id,
+    messageId: id.optional(),
+    emoji: z.string(),
+    removed: z.boolean().optional(),
+  })),
+  'broadcast:watch': defineEvent(1, 'Start watching a broadcast by the code from its watch link', z.object({ watchCode: z.string() })),
+  'broadcast:unwatch': defineEvent(1, 'Stop watching a broadcast', z.object({ broadcastId: id })),
+  'voice:join': defineEvent(1, 'Join a voice channel, leaving any other one', z.object({ channelId: id })),
+  'voice:leave': defineEvent(1, 'Leave a voice channel', z.object({ channelId: id })),
+  'voice:state': defineEvent(1, 'Mute or unmute yourself, or switch push-to-talk', z.object({
+    channelId: id,
+    muted: z.boolean().optional(),
+    pushToTalk: z.boolean().optional(),
+  })),
+  'voice:talking': defineEvent(1, 'Push-to-talk pressed or released, or voice activity started or stopped', z.object({
+    channelId: id,
+    talking: z.boolean(),
+  })),
+  'voice:offer': defineEvent(1, 'Send a WebRTC offer to one voice channel participant', z.object({ channelId: id, to: id, sdp: z.unknown() })),
+  'voice:answer-sdp': defineEvent(1, 'Send a WebRTC answer to one voice channel participant', z.object({ channelId: id, to: id, sdp: z.unknown() })),
+  'voice:ice-candidate': defineEvent(1, 'Send an ICE candidate to one voice channel participant', z.object({ channelId: id, to: id, candidate: z.unknown() })),
+  'group:create': defineEvent(1, 'Create a group', z.object({
+    name: z.string(),
+    description: z.string().optional(),
+    participants: z.array(id),
+    avatar: z.string().optional(),
+  })),
+  'group:join': defineEvent(1, 'Join a group room', z.object({ groupId: id })),
+  'group:add-members': defineEvent(1, 'Add members to a group', z.object({ groupId: id, userIds: z.array(id) })),
+  'group:remove-member': defineEvent(1, 'Remove a member', z.object({ groupId: id, userId: id })),
+  'group:promote': defineEvent(1, 'Promote a member to admin', z.object({ groupId: id, userId: id })),
+  'group:demote': defineEvent(1, 'Demote an admin', z.object({ groupId: id, userId: id })),
+  'group:leave': defineEvent(1, 'Leave a group', z.object({ groupId: id })),
+  'group:update': defineEvent(1, 'Update group info', z.object({
+    groupId: id,
+    name: z.string().optional(),
+    description: z.string().optional(),
+    avatar: z.string().optional(),
+    version: z.number().int().min(0).optional(), // the group version last seen
+  })),
+};
+
+export type ServerEventName = keyof typeof serverEvents;
+export type ClientEventName = keyof typeof clientEvents;
+export type ServerEventPayload<E extends ServerEventName> = z.infer<typeof serverEvents[E]['schema']>;
+export type ClientEventPayload<E extends ClientEventName> = z.infer<typeof clientEvents[E]['schema']>;
+
+export type EventValidationMode = 'off' | 'warn' | 'strict';
+
+// Read straight from process.env so the SDK generator can load this module
+// without a full server environment
+function getValidationMode(): EventValidationMode {
+  const mode = process.env.SOCKET_EVENT_VALIDATION;
+  if (mode === 'off' || mode === 'warn' || mode === 'strict') {
+    return mode;
+  }
+  return process.env.NODE_ENV === 'production' ? 'off' : 'warn';
+}
+
+// Check an outgoing payload against its schema. Unknown events and schema
+// mismatches are logged in 'warn' mode and thrown in 'strict' mode.
+export function validateServerEvent(event: string, payload: unknown): boolean {
+  const mode = getValidationMode();
+  if (mode === 'off') return true;
+
+  const definition = (serverEvents as Record<string, EventDefinition>)[event];
+  let problem: string | null = null;
+
+  if (!definition) {
+    problem = 'unregistered event';
+  } else {
+    const wire = payload === undefined ? undefined : JSON.parse(JSON.stringify(payload));
+    const result = definition.schema.safeParse(wire);
+    if (!result.success) {
+      problem = result.error.errors
+        .map(err => `${err.path.join('.') || '(root)'}: ${err.message}`)
+        .join('; ');
+    }
+  }
+
+  if (!problem) return true;
+
+  metricsCollector.incrementCounter('socket_event_validation_failures', 1, { event });
+  if (mode === 'strict') {
+    throw new Error(`Invalid payload for socket event "${event}": ${problem}`);
+  }
+  logger.warn('Socket event payload does not match schema', { event, problem });
+  return false;
+}
+
+export interface EmitTarget {
+  emit(event: string, ...args: any[]): unknown;
+}
+
+// Sent after the payload of reliable events. seq identifies the event for
+// acknowledgment; it grows with every event sent by this server process.
+export interface DeliveryMeta {
+  seq: string;
+  attempt?: number; // set on resends, from 2
+  eventId?: string; // when the event is also replayable
+}
+
+// Sent after the payload of replayable events (with DeliveryMeta for those
+// that are also reliable). Clients keep the newest eventId and hand it back
+// as auth.lastEventId when they reconnect.
+export interface ReplayMeta {
+  eventId: string;
+}
+
+const instanceId = randomBytes(4).toString('hex');
+let sequence = 0;
+
+export function isReliableEvent(event: string): boolean {
+  return !!(serverEvents as Record<string, EventDefinition>)[event]?.reliable;
+}
+
+export function isDroppableEvent(event: string): boolean {
+  return !!(serverEvents as Record<string, EventDefinition>)[event]?.droppable;
+}
+
+export function isReducibleEvent(event: string): boolean {
+  return !!(serverEvents as Record<string, EventDefinition>)[event]?.reducible;
+}
+
+export function isReplayableEvent(event: string): boolean {
+  return !!(serverEvents as Record<string, EventDefinition>)[event]?.replayable;
+}
+
+export function nextDeliveryMeta(): DeliveryMeta {
+  sequence += 1;
+  return { seq: `${instanceId}-${sequence}` };
+}
+
+// Validate and emit. Use instead of target.emit() for server events.
+export function emitEvent<E extends ServerEventName>(
+  target: EmitTarget,
+  event: E,
+  ...payload: ServerEventPayload<E> extends undefined ? [] : [unknown]
+): void {
+  validateServerEvent(event, payload[0]);
+  if (isReliableEvent(event)) {
+    target.emit(event, payload[0], nextDeliveryMeta());
+    return;
+  }
+  target.emit(event, ...payload);
+}
+
+// emitEvent for a replayable event recorded under eventId
+export function emitRecordedEvent(target: EmitTarget, event: ServerEventName, payload: unknown, eventId: string): void {
+  validateServerEvent(event, payload);
+  const meta: DeliveryMeta | ReplayMeta = isReliableEvent(event) ? { ...nextDeliveryMeta(), eventId } : { eventId };
+  target.emit(event, payload, meta);
+}
+
+function toJsonSchemas(registry: Record<string, EventDefinition>) {
+  return Object.fromEntries(
+    Object.entries(registry).map(([event, definition]) => {
+      const { $schema, ...schema } = zodToJsonSchema(definition.schema, { $refStrategy: 'none' }) as Record<string, unknown>;
+      return [event, {
+        description: definition.description,
+        since: definition.since,
+        ...(definition.reliable && { reliable: true }),
+        ...(definition.droppable && { droppable: true }),
+        ...(definition.reducible && { reducible: true }),
+        ...(definition.replayable && { replayable: true }),
+        // z.undefined() becomes {not: {}}; surface it as "no payload"
+        payload: 'not' in schema ? null : schema,
+      }];
+    })
+  );
+}
+
+// JSON Schema for every event, served to clients and used to generate SDKs
+export function buildEventJsonSchema() {
+  return {
+    $schema: 'http://json-schema.org/draft-07/schema#',
+    title: 'Socket events',
+    version: EVENT_PROTOCOL_VERSION,
+    serverEvents: toJsonSchemas(serverEvents),
+    clientEvents: toJsonSchemas(clientEvents),
+  };
+}
@@ -0,0 +1,64 @@
+import Redis from 'ioredis';
+import { redisConfig } from '../config/redis';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { ServerEventName } from './protocol';
+
+const CHANNEL = 'socket:relay';
+
+export type RelayMessage =
+  | {
+      type: 'emit';
+      to: 'user' | 'chat' | 'all';
+      id?: string; // the user or chat
+      event: ServerEventName;
+      data: unknown;
+      eventId?: string; // already recorded for replay by the sender
+      exclude?: string[]; // users left out of a chat emit
+    }
+  | { type: 'disconnect'; userId: string };
+
+// Carries socket emits and disconnects from processes that have no
+// Socket.IO server (worker processes, see lib/workers) to the ones that do.
+// They are published on a Redis channel; every web server applies them to
+// its own sockets. Delivery is best effort, like any emit: clients that miss
+// one pick it up through replay or their next sync.
+export class SocketRelay {
+  private publisher = redisConfig.getClient();
+  private subscriber: Redis | null = null;
+  private warned = false;
+
+  publish(message: RelayMessage): void {
+    if (!this.publisher) {
+      if (!this.warned) {
+        this.warned = true;
+        logger.warn('Redis not configured, socket events from this process are not delivered');
+      }
+      return;
+    }
+
+    this.publisher.publish(CHANNEL, JSON.stringify(message)).catch(error => {
+      logger.error('Failed to relay socket event', error, { type: message.type });
+    });
+    metricsCollector.incrementCounter('socket_events_relayed', 1, { type: message.type });
+  }
+
+  // Apply what other processes publish; called by the web server's socket manager
+  subscribe(handler: (message: RelayMessage) => void): void {
+    if (!this.publisher || this.subscriber) return;
+
+    this.subscriber = this.publisher.duplicate();
+    this.subscriber.on('message', (_channel: string, message: string) => {
+      try {
+        handler(JSON.parse(message));
+      } catch (error) {
+        logger.error('Failed to apply relayed socket event', error);
+      }
+    });
+    this.subscriber.subscribe(CHANNEL).catch(error => {
+      logger.error('Failed to subscribe to relayed socket events', error);
+    });
+  }
+}
+
+export const socketRelay = new SocketRelay();
@@ -0,0 +1,160 @@
+import { randomInt } from 'crypto';
+import { redisConfig } from '../config/redis';
+import { ChatRepository } from '../database/repositories/chat';
+import { REPLAY_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { AuthenticatedSocket } from './socket';
+import { emitEvent, emitRecordedEvent, ServerEventName } from './protocol';
+
+type EventId = [number, number]; // milliseconds, then order within them
+
+// Spreads IDs made in the same millisecond on different servers apart
+const instanceSlot = randomInt(1024);
+
+// A short replay window so reconnecting clients don't need a full REST
+// resync. Replayable events (see protocol) sent through socketManager are
+// stamped with an eventId and appended to a Redis stream per recipient,
+// capped at MAX_EVENTS and kept for WINDOW. A client that reconnects with
+// auth.lastEventId is sent everything after it, then events:replayed; if
+// its last event is older than the window, or trimmed away, it is told to
+// resync instead. Replay is per user, so each device picks up from its own
+// last event. Reliable events may also come through the device's offline
+// queue (realtime/delivery); clients drop events whose eventId or seq they
+// already handled.
+//
+// IDs are milliseconds-counter pairs made on the emitting server, so they
+// sort by time across servers without a round trip before each emit.
+export class EventReplayLog {
+  private redis = redisConfig.getClient();
+  private chatRepository = new ChatRepository();
+  private lastMs = 0;
+  private counter = 0;
+
+  nextEventId(): string {
+    const now = Date.now();
+    if (now > this.lastMs) {
+      this.lastMs = now;
+      this.counter = 0;
+    } else {
+      this.counter++;
+    }
+    return `${this.lastMs}-${this.counter * 1024 + instanceSlot}`;
+  }
+
+  // Keep an event sent to users. Runs in the background; a failure only
+  // means those users resync if they reconnect.
+  record(userIds: string[], event: ServerEventName, payload: unknown, eventId: string): void {
+    if (!this.redis || userIds.length === 0) return;
+
+    const pipeline = this.redis.pipeline();
+    const data = JSON.stringify(payload);
+    for (const userId of userIds) {
+      const key = this.streamKey(userId);
+      pipeline.xadd(key, 'MAXLEN', REPLAY_CONSTANTS.MAX_EVENTS, '*', 'id', eventId, 'event', event, 'payload', data);
+      pipeline.pexpire(key, REPLAY_CONSTANTS.WINDOW);
+    }
+
+    pipeline.exec().catch(error => {
+      logger.error('Failed to record events for replay', error, { event, recipients: userIds.length });
+    });
+  }
+
+  // The same for an event sent to a chat room, kept for every participant
+  // but the excluded ones
+  recordForChat(chatId: string, event: ServerEventName, payload: unknown, eventId: string, excludeUserIds: string[] = []): void {
+    if (!this.redis) return;
+
+    this.chatRepository.getParticipantIds(chatId)
+      .then(userIds => this.record(userIds.filter(userId => !excludeUserIds.includes(userId)), event, payload, eventId))
+      .catch(error => {
+        logger.error('Failed to look up chat participants for replay', error, { chatId, event });
+      });
+  }
+
+  // Send a reconnecting socket what it missed since auth.lastEventId
+  async replay(socket: AuthenticatedSocket): Promise<void> {
+    const lastEventId = socket.handshake.auth?.lastEventId;
+    if (!lastEventId) return;
+
+    const since = this.parse(String(lastEventId));
+    if (!since) {
+      this.resync(socket, 'invalid_event_id');
+      return;
+    }
+    if (!this.redis) {
+      this.resync(socket, 'unavailable');
+      return;
+    }
+    if (since[0] < Date.now() - REPLAY_CONSTANTS.WINDOW) {
+      this.resync(socket, 'window_exceeded');
+      return;
+    }
+
+    try {
+      const entries = (await this.redis.xrange(this.streamKey(socket.userId), '-', '+'))
+        .map(([, fields]) => this.entryOf(fields))
+        .filter((entry): entry is NonNullable<typeof entry> => !!entry);
+
+      // Trimmed past the client's last event: some of what it missed is gone
+      if (entries.length >= REPLAY_CONSTANTS.MAX_EVENTS && this.compare(entries[0].id, since) > 0) {
+        this.resync(socket, 'window_exceeded');
+        return;
+      }
+
+      const missed = entries
+        .filter(entry => this.compare(entry.id, since) > 0)
+        .sort((a, b) => this.compare(a.id, b.id));
+
+      for (const entry of missed) {
+        emitRecordedEvent(socket, entry.event, entry.payload, entry.eventId);
+      }
+      emitEvent(socket, 'events:replayed', {
+        count: missed.length,
+        lastEventId: missed.length > 0 ? missed[missed.length - 1].eventId : String(lastEventId),
+      });
+
+      metricsCollector.incrementCounter('hub_events_replayed', missed.length);
+    } catch (error) {
+      logger.error('Failed to replay events', error, { userId: socket.userId, deviceId: socket.deviceId });
+      this.resync(socket, 'unavailable');
+    }
+  }
+
+  private resync(socket: AuthenticatedSocket, reason: 'window_exceeded' | 'invalid_event_id' | 'unavailable'): void {
+    metricsCollector.incrementCounter('hub_replay_resyncs', 1, { reason });
+    emitEvent(socket, 'events:resync-required', { reason });
+  }
+
+  private entryOf(fields: string[]) {
+    const values: Record<string, string> = {};
+    for (let index = 0; index + 1 < fields.length; index += 2) {
+      values[fields[index]] = fields[index + 1];
+    }
+
+    const id = this.parse(values.id || '');
+    if (!id || !values.event) return null;
+
+    return {
+      id,
+      eventId: values.id,
+      event: values.event as ServerEventName,
+      payload: JSON.parse(values.payload ?? 'null'),
+    };
+  }
+
+  private parse(eventId: string): EventId | null {
+    const match = eventId.match(/^(\d+)-(\d+)$/);
+    return match ? [Number(match[1]), Number(match[2])] : null;
+  }
+
+  private compare(a: EventId, b: EventId): number {
+    return a[0] - b[0] || a[1] - b[1];
+  }
+
+  private streamKey(userId: string): string {
+    return `socket:events:${userId}`;
+  }
+}
+
+export const eventReplayLog = new EventReplayLog();
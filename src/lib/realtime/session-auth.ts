@@ -0,0 +1,126 @@
+import { jwtService } from '../auth/jwt';
+import { UserRepository } from '../database/repositories/user';
+import { AUTH_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { AuthenticatedSocket } from './socket';
+import { clientEvents, emitEvent } from './protocol';
+
+// setTimeout fires at once past this; longer waits are chained
+const MAX_TIMER_DELAY = 2 ** 31 - 1;
+
+interface SessionTimers {
+  warning?: NodeJS.Timeout;
+  expiry?: NodeJS.Timeout;
+}
+
+// A socket's session lasts as long as the access token it signed in with.
+// SOCKET_EXPIRY_WARNING before the token expires the socket gets
+// auth:expiring; the client answers auth:refresh with a new access token
+// (from its refresh token, over REST) for the same user and device, and the
+// session runs until that one expires. Sockets still on an expired token
+// get auth:expired and are disconnected, and reconnect with a fresh one.
+export class SocketSessionAuth {
+  private userRepository = new UserRepository();
+
+  attach(socket: AuthenticatedSocket): void {
+    const timers: SessionTimers = {};
+    this.schedule(socket, timers);
+
+    socket.on('auth:refresh', async (data) => {
+      const parsed = clientEvents['auth:refresh'].schema.safeParse(data);
+      if (!parsed.success) {
+        return emitEvent(socket, 'auth:refresh:failed', { message: 'Token required' });
+      }
+
+      try {
+        await this.refresh(socket, parsed.data.token);
+        this.schedule(socket, timers);
+        emitEvent(socket, 'auth:refreshed', { expiresAt: socket.tokenExpiresAt });
+      } catch (error) {
+        if (error instanceof SessionAuthError) {
+          metricsCollector.incrementCounter('socket_auth_refresh_failed', 1);
+          return emitEvent(socket, 'auth:refresh:failed', { message: error.message });
+        }
+        logger.error('Socket auth refresh failed', error, { userId: socket.userId });
+        emitEvent(socket, 'auth:refresh:failed', { message: 'Failed to refresh session' });
+      }
+    });
+
+    socket.on('disconnect', () => this.clear(timers));
+  }
+
+  // Check the new token and move the socket over to it
+  private async refresh(socket: AuthenticatedSocket, token: string): Promise<void> {
+    const result = await jwtService.verifyAccessToken(token.replace('Bearer ', ''));
+    if (!result.valid || !result.payload) {
+      throw new SessionAuthError(result.error || 'Invalid token');
+    }
+
+    const { payload } = result;
+    if (payload.userId !== socket.userId || payload.deviceId !== socket.deviceId) {
+      throw new SessionAuthError('Token belongs to another user or device');
+    }
+
+    const user = await this.userRepository.findById(payload.userId);
+    if (!user || user.isBanned) {
+      throw new SessionAuthError('User not found or banned');
+    }
+
+    socket.tokenExpiresAt = new Date(payload.exp * 1000);
+    metricsCollector.incrementCounter('socket_auth_refreshed', 1);
+    logger.debug('Socket session refreshed', {
+      userId: socket.userId,
+      deviceId: socket.deviceId,
+      expiresAt: socket.tokenExpiresAt,
+    });
+  }
+
+  private schedule(socket: AuthenticatedSocket, timers: SessionTimers): void {
+    this.clear(timers);
+    if (!socket.tokenExpiresAt) return;
+
+    const expiresAt = socket.tokenExpiresAt.getTime();
+
+    this.setTimer(timers, 'warning', expiresAt - AUTH_CONSTANTS.SOCKET_EXPIRY_WARNING, () => {
+      emitEvent(socket, 'auth:expiring', {
+        expiresAt: new Date(expiresAt),
+        expiresIn: Math.max(Math.round((expiresAt - Date.now()) / 1000), 0),
+      });
+    });
+
+    this.setTimer(timers, 'expiry', expiresAt, () => {
+      logger.info('Socket session expired', { userId: socket.userId, deviceId: socket.deviceId });
+      metricsCollector.incrementCounter('socket_auth_expired', 1);
+      emitEvent(socket, 'auth:expired', { expiredAt: new Date(expiresAt) });
+      socket.disconnect(true);
+    });
+  }
+
+  private setTimer(timers: SessionTimers, key: keyof SessionTimers, at: number, callback: () => void): void {
+    const delay = Math.min(Math.max(at - Date.now(), 0), MAX_TIMER_DELAY);
+    timers[key] = setTimeout(() => {
+      if (Date.now() < at) {
+        this.setTimer(timers, key, at, callback);
+        return;
+      }
+      callback();
+    }, delay);
+  }
+
+  private clear(timers: SessionTimers): void {
+    clearTimeout(timers.warning);
+    clearTimeout(timers.expiry);
+    timers.warning = undefined;
+    timers.expiry = undefined;
+  }
+}
+
+export class SessionAuthError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'SessionAuthError';
+  }
+}
+
+export const socketSessionAuth = new SocketSessionAuth();
@@ -6,32 +6,101 @@ import { registerMessagingEvents } from './events/messaging';
 import { registerPresenceEvents } from './events/presence';
 import { registerTypingEvents } from './events/typing';
 import { registerCallEvents } from './events/calls';
+import { registerCallChatEvents } from './events/call-chat';
 import { registerGroupEvents } from './events/groups';
+import { registerVoiceChannelEvents } from './events/voice-channels';
+import { registerBroadcastEvents } from './events/broadcasts';
+import { metricsCollector } from '../monitoring/metrics';
+import { activityTracker } from '../monitoring/activity';
+import { corsConfig } from '../config/cors';
+import { emitEvent, emitRecordedEvent, EmitTarget, isReplayableEvent, ServerEventName } from './protocol';
+import { mqttBridge } from './mqtt-bridge';
+import { deliveryTracker } from './delivery';
+import { sendQueueMonitor } from './backpressure';
+import { lowDataMode } from './low-data';
+import { eventReplayLog } from './replay';
+import { socketRelay, RelayMessage } from './relay';
+import { eventThrottle } from './throttle';
+import { socketSessionAuth } from './session-auth';
+import { LOW_DATA_CONSTANTS } from '../utils/constants';
+import { CLIENT_PLATFORMS } from '../config/client-versions';
+import { DATA_REGIONS } from '../database/models/user';
 
 export interface AuthenticatedSocket extends Socket {
   userId: string;
+  deviceId?: string;
+  lowData?: boolean; // the device's low-data mode at connect, see realtime/low-data
+  tokenExpiresAt?: Date; // of the access token, moved on by auth:refresh; see realtime/session-auth
+  qrLogin?: boolean; // waiting for a QR login; userId is qr:<qrId>
+  organizationId?: string; // the user's tenant; unset for the shared pool
+  guest?: { calls: boolean; fileUpload: boolean }; // set for guest accounts, see auth/guest-access
   user: {
     _id: string;
     displayName: string;
     avatar?: string;
     phoneNumber: string;
   };
+  clientInfo?: {
+    platform: string;
+    region: string;
+  };
+}
+
+export interface HubStats {
+  activeConnections: number;
+  activeUsers: number;
+  totalConnections: number;
+  totalDisconnections: number;
+  messagesReceived: number;
+  messagesDispatched: number;
+  messageErrors: number;
+  sendQueueDrops: number; // presence and typing updates dropped for slow clients
+  eventsCoalesced: number; // presence and typing updates superseded while held for slow clients
+  throttledConnections: number; // connections on this server slow enough to be throttled right now
+  slowConsumerDisconnects: number;
+  maxSendQueueDepth: number; // deepest send buffer right now, on this server
+  startedAt: Date;
+}
+
+// A handshake value as a latency segment: one of the known values, 'unknown'
+// when missing, 'other' for anything else
+function segmentValue(value: unknown, known: readonly string[]): string {
+  if (!value) return 'unknown';
+  const normalized = String(value).toLowerCase();
+  return known.includes(normalized) ? normalized : 'other';
 }
 
 class SocketManager {
   private io: SocketIOServer | null = null;
   private userSockets: Map<string, Set<string>> = new Map(); // userId -> Set of socketIds
   private socketUsers: Map<string, string> = new Map(); // socketId -> userId
+  private stats = {
+    totalConnections: 0,
+    totalDisconnections: 0,
+    messagesReceived: 0,
+    messagesDispatched: 0,
+    messageErrors: 0,
+    sendQueueDrops: 0,
+    eventsCoalesced: 0,
+    slowConsumerDisconnects: 0,
+    startedAt: new Date(),
+  };
 
   initialize(httpServer: HTTPServer): SocketIOServer {
     this.io = new SocketIOServer(httpServer, {
       cors: {
-        origin: process.env.FRONTEND_URL || "http://localhost:3000",
+        // Evaluated per handshake so admin config changes apply without restart
+        origin: (origin, callback) => {
+          callback(null, !origin || corsConfig.isOriginAllowed(origin));
+        },
         methods: ["GET", "POST"],
         credentials: true
       },
       pingTimeout: 60000,
       pingInterval: 25000,
+      // Negotiated with every client but only used for low-data devices,
+      // see realtime/low-data
+      perMessageDeflate: { threshold: LOW_DATA_CONSTANTS.COMPRESSION_THRESHOLD },
     });
 
     // Apply middleware
@@ -43,14 +112,35 @@ class SocketManager {
       this.handleConnection(socket as AuthenticatedSocket);
     });
 
+    // Mirror hub events to MQTT for low-power clients
+    if (mqttBridge.isEnabled()) {
+      mqttBridge.start(this.io);
+    }
+
+    // Emits and disconnects from worker processes
+    socketRelay.subscribe(message => this.applyRelayed(message));
+
     return this.io;
   }
 
   private handleConnection(socket: AuthenticatedSocket) {
     const userId = socket.userId;
+
+    // Desktops waiting for a QR login only join the room of their code
+    if (socket.qrLogin) {
+      socket.join(`user:${userId}`);
+      return;
+    }
     
     console.log(`User ${userId} connected with socket ${socket.id}`);
 
+    // Client segment used for latency reporting. Values come from the
+    // client, so anything outside the known set is counted as 'other'.
+    socket.clientInfo = {
+      platform: segmentValue(socket.handshake.auth.platform || socket.handshake.headers['x-client-platform'], CLIENT_PLATFORMS),
+      region: segmentValue(socket.handshake.auth.region || socket.handshake.headers['x-client-region'], DATA_REGIONS),
+    };
+
     // Track user connections
     if (!this.userSockets.has(userId)) {
       this.userSockets.set(userId, new Set());
@@ -58,7 +148,14 @@ class SocketManager {
     this.userSockets.get(userId)!.add(socket.id);
     this.socketUsers.set(socket.id, userId);
 
-    // Join user to their personal room
+    this.stats.totalConnections++;
+    metricsCollector.incrementCounter('hub_connections');
+    activityTracker.record(userId);
+    metricsCollector.recordGauge('hub_active_connections', this.socketUsers.size);
+
+    // Join user to their personal room; data is visible to other replicas
+    socket.data.userId = userId;
+    socket.data.deviceId = socket.deviceId;
     socket.join(`user:${userId}`);
 
     // Register event handlers
@@ -66,7 +163,41 @@ class SocketManager {
     registerPresenceEvents(socket, this.io!);
     registerTypingEvents(socket, this.io!);
     registerCallEvents(socket, this.io!);
+    registerCallChatEvents(socket, this.io!);
     registerGroupEvents(socket, this.io!);
+    registerVoiceChannelEvents(socket, this.io!);
+    registerBroadcastEvents(socket, this.io!);
+
+    // Keep the session open past the token's expiry when the client refreshes it
+    socketSessionAuth.attach(socket);
+
+    // Bounded send buffer; slow clients lose presence updates, then the connection
+    sendQueueMonitor.attach(socket, ({ type, count }) => {
+      if (type === 'dropped') {
+        this.stats.sendQueueDrops += count;
+      } else {
+        this.stats.slowConsumerDisconnects += count;
+        metricsCollector.incrementCounter('hub_slow_consumer_disconnects', count);
+      }
+    });
+
+    // Smaller payloads for devices in low-data mode
+    lowDataMode.attach(socket, !!socket.lowData);
+
+    // Slow connections get typing and presence updates coalesced
+    eventThrottle.attach(socket, () => {
+      this.stats.eventsCoalesced++;
+    });
+
+    // Acknowledged delivery for clients that opted in
+    deliveryTracker.attach(socket).catch(error => {
+      console.error('Error attaching delivery tracking:', error);
+    });
+
+    // Events missed while reconnecting, for clients that sent lastEventId
+    eventReplayLog.replay(socket).catch(error => {
+      console.error('Error replaying events:', error);
+    });
 
     // Handle disconnection
     socket.on('disconnect', () => {
@@ -84,6 +215,10 @@ class SocketManager {
 
     // Remove socket tracking
     this.socketUsers.delete(socket.id);
+    this.stats.totalDisconnections++;
+    metricsCollector.incrementCounter('hub_disconnections');
+    metricsCollector.recordGauge('hub_active_connections', this.socketUsers.size);
+
     const userSocketSet = this.userSockets.get(userId);
     if (userSocketSet) {
       userSocketSet.delete(socket.id);
@@ -95,34 +230,138 @@ class SocketManager {
     }
   }
 
-  // Public methods for emitting to users
-  emitToUser(userId: string, event: string, data: any) {
+  // Public methods for emitting to users. Replayable events are also kept
+  // for the recipients' reconnecting devices (see realtime/replay). In a
+  // process without a Socket.IO server they go to the web servers through
+  // the relay (see realtime/relay).
+  emitToUser(userId: string, event: ServerEventName, data: any, excludeSocketId?: string) {
+    let eventId: string | undefined;
+    if (isReplayableEvent(event)) {
+      eventId = eventReplayLog.nextEventId();
+      eventReplayLog.record([userId], event, data, eventId);
+    }
+
     if (this.io) {
-      this.io.to(`user:${userId}`).emit(event, data);
+      const room = this.io.to(`user:${userId}`);
+      this.deliver(excludeSocketId ? room.except(excludeSocketId) : room, event, data, eventId);
+    } else {
+      socketRelay.publish({ type: 'emit', to: 'user', id: userId, event, data, eventId });
     }
   }
 
-  emitToChat(chatId: string, event: string, data: any, excludeUserId?: string) {
+  emitToAll(event: ServerEventName, data: any) {
     if (this.io) {
-      const emitter = this.io.to(`chat:${chatId}`);
-      if (excludeUserId) {
-        emitter.except(`user:${excludeUserId}`);
-      }
-      emitter.emit(event, data);
+      emitEvent(this.io, event, data);
+    } else {
+      socketRelay.publish({ type: 'emit', to: 'all', event, data });
+    }
+  }
+
+  emitToChat(chatId: string, event: ServerEventName, data: any, excludeUserId?: string | string[]) {
+    const excluded = excludeUserId ? [excludeUserId].flat() : [];
+    let eventId: string | undefined;
+    if (isReplayableEvent(event)) {
+      eventId = eventReplayLog.nextEventId();
+      eventReplayLog.recordForChat(chatId, event, data, eventId, excluded);
+    }
+
+    if (this.io) {
+      this.deliver(this.io.to(`chat:${chatId}`).except(excluded.map(userId => `user:${userId}`)), event, data, eventId);
+    } else {
+      socketRelay.publish({ type: 'emit', to: 'chat', id: chatId, event, data, eventId, exclude: excluded });
     }
   }
 
+  private deliver(target: EmitTarget, event: ServerEventName, data: any, eventId?: string) {
+    if (eventId) {
+      emitRecordedEvent(target, event, data, eventId);
+    } else {
+      emitEvent(target, event, data);
+    }
+  }
+
+  // Apply what worker processes sent through the relay. They recorded
+  // replayable events already.
+  private applyRelayed(message: RelayMessage) {
+    if (!this.io) return;
+
+    if (message.type === 'disconnect') {
+      this.disconnectUser(message.userId);
+      return;
+    }
+
+    switch (message.to) {
+      case 'user':
+        this.deliver(this.io.to(`user:${message.id}`), message.event, message.data, message.eventId);
+        break;
+      case 'chat':
+        this.deliver(
+          this.io.to(`chat:${message.id}`).except((message.exclude || []).map(userId => `user:${userId}`)),
+          message.event,
+          message.data,
+          message.eventId
+        );
+        break;
+      case 'all':
+        emitEvent(this.io, message.event, message.data);
+        break;
+    }
+  }
+
+  // Drop every connection a user has, e.g. after a ban
+  disconnectUser(userId: string) {
+    if (!this.io) {
+      socketRelay.publish({ type: 'disconnect', userId });
+      return;
+    }
+    this.io.in(`user:${userId}`).disconnectSockets(true);
+    mqttBridge.disconnectUser(userId);
+  }
+
   isUserOnline(userId: string): boolean {
     return this.userSockets.has(userId);
   }
 
+  // Users with a socket in a room, on any replica
+  async getRoomUserIds(room: string): Promise<Set<string>> {
+    if (!this.io) return new Set();
+
+    const sockets = await this.io.in(room).fetchSockets();
+    return new Set(sockets.map(socket => socket.data.userId).filter(Boolean));
+  }
+
   getUserSocketCount(userId: string): number {
     return this.userSockets.get(userId)?.size || 0;
   }
 
+  // Devices the user currently has a socket open on
+  getUserDeviceIds(userId: string): Set<string> {
+    const deviceIds = new Set<string>();
+    for (const socketId of this.userSockets.get(userId) || []) {
+      const socket = this.io?.sockets.sockets.get(socketId) as AuthenticatedSocket | undefined;
+      if (socket?.deviceId) {
+        deviceIds.add(socket.deviceId);
+      }
+    }
+    return deviceIds;
+  }
+
+  // Switch a device's open sockets, on any replica, in or out of low-data
+  // mode
+  async setDeviceLowData(userId: string, deviceId: string, enabled: boolean): Promise<void> {
+    if (!this.io) return;
+
+    const sockets = await this.io.in(`user:${userId}`).fetchSockets();
+    for (const socket of sockets) {
+      if (socket.data.deviceId === deviceId) {
+        lowDataMode.set(socket, enabled);
+      }
+    }
+  }
+
   private broadcastUserPresence(userId: string, isOnline: boolean) {
     if (this.io) {
-      this.io.emit('user:presence:changed', {
+      emitEvent(this.io, 'user:presence:changed', {
         userId,
         isOnline,
         lastSeen: new Date()
@@ -130,6 +369,34 @@ class SocketManager {
     }
   }
 
+  // Hub counters, used by the load test harness and health checks
+  recordMessageReceived(): void {
+    this.stats.messagesReceived++;
+    metricsCollector.incrementCounter('hub_messages_received');
+  }
+
+  recordMessageDispatched(recipients: number): void {
+    this.stats.messagesDispatched += recipients;
+    metricsCollector.incrementCounter('hub_messages_dispatched', recipients);
+  }
+
+  recordMessageError(): void {
+    this.stats.messageErrors++;
+    metricsCollector.incrementCounter('hub_message_errors');
+  }
+
+  getHubStats(): HubStats {
+    return {
+      activeConnections: this.socketUsers.size,
+      activeUsers: this.userSockets.size,
+      maxSendQueueDepth: this.io ? sendQueueMonitor.maxDepth(this.io) : 0,
+      throttledConnections: this.io
+        ? eventThrottle.countSlow(this.io.of('/').sockets.values() as Iterable<AuthenticatedSocket>)
+        : 0,
+      ...this.stats,
+    };
+  }
+
   getIO(): SocketIOServer | null {
     return this.io;
   }
@@ -0,0 +1,137 @@
+import { adminConfigService } from '../config/admin-config';
+import { THROTTLE_CONSTANTS } from '../utils/constants';
+import { metricsCollector } from '../monitoring/metrics';
+import { AuthenticatedSocket } from './socket';
+import { isDroppableEvent } from './protocol';
+import { encodedEventName, sendQueueMonitor } from './backpressure';
+
+interface EngineConnection {
+  write(data: unknown, options?: unknown, callback?: () => void): unknown;
+  on(event: string, listener: (...args: any[]) => void): unknown;
+}
+
+interface HeldEvent {
+  data: string;
+  options?: unknown;
+  timer: NodeJS.Timeout;
+}
+
+export interface ThrottleSettings {
+  enabled: boolean;
+  slowRtt: number;
+  slowBacklog: number;
+  typingWindow: number;
+  presenceWindow: number;
+}
+
+// Adaptive coalescing of low-priority events (typing and presence, the
+// droppable ones in the protocol) for slow connections. A connection is
+// slow while its heartbeat round trip is at or above slowRtt, or its send
+// buffer holds slowBacklog packets or more. Its low-priority events are
+// then held for a window, longer the slower it is, and only the newest
+// per chat and user goes out when the window ends; the ones it replaced
+// are counted as suppressed. Fast connections get every event at once.
+// Backpressure (realtime/backpressure) still drops what piles up past its
+// limits.
+export class EventThrottle {
+  attach(socket: AuthenticatedSocket, onSuppressed: (event: string) => void): void {
+    const conn = socket.conn as unknown as EngineConnection;
+    const write = conn.write.bind(conn);
+    const held = new Map<string, HeldEvent>();
+    let pingSentAt = 0;
+
+    // Engine.IO heartbeats: the server pings, the client's pong is a heartbeat
+    conn.on('packetCreate', (packet: { type: string }) => {
+      if (packet.type === 'ping') pingSentAt = Date.now();
+    });
+    conn.on('heartbeat', () => {
+      if (!pingSentAt) return;
+      socket.data.rtt = Date.now() - pingSentAt;
+      pingSentAt = 0;
+      metricsCollector.recordHistogram('hub_connection_rtt', socket.data.rtt);
+    });
+
+    conn.write = (data: unknown, options?: unknown, callback?: () => void) => {
+      const event = encodedEventName(data);
+      const settings = this.settings();
+      if (!event || !isDroppableEvent(event) || !settings.enabled || !this.isSlow(socket, settings)) {
+        return write(data, options, callback);
+      }
+
+      const key = this.coalesceKey(event, data as string);
+      const existing = held.get(key);
+      if (existing) {
+        existing.data = data as string;
+        existing.options = options;
+        metricsCollector.incrementCounter('hub_events_coalesced', 1, { event });
+        onSuppressed(event);
+        return conn;
+      }
+
+      const entry: HeldEvent = {
+        data: data as string,
+        options,
+        timer: setTimeout(() => {
+          held.delete(key);
+          if (socket.connected) write(entry.data, entry.options);
+        }, this.windowFor(event, socket, settings)),
+      };
+      held.set(key, entry);
+      return conn;
+    };
+
+    socket.on('disconnect', () => {
+      held.forEach(entry => clearTimeout(entry.timer));
+      held.clear();
+    });
+  }
+
+  isSlow(socket: AuthenticatedSocket, settings: ThrottleSettings = this.settings()): boolean {
+    return (socket.data.rtt ?? 0) >= settings.slowRtt || sendQueueMonitor.depth(socket) >= settings.slowBacklog;
+  }
+
+  // Connections on this server currently being throttled
+  countSlow(sockets: Iterable<AuthenticatedSocket>): number {
+    const settings = this.settings();
+    if (!settings.enabled) return 0;
+
+    let count = 0;
+    for (const socket of sockets) {
+      if (this.isSlow(socket, settings)) count++;
+    }
+    return count;
+  }
+
+  // Admin overrides from the realtime settings, else the defaults
+  settings(): ThrottleSettings {
+    const config = adminConfigService.getCached().realtime;
+    return {
+      enabled: config.throttleSlowConnections !== false,
+      slowRtt: config.slowRtt ?? THROTTLE_CONSTANTS.SLOW_RTT,
+      slowBacklog: config.slowBacklog ?? THROTTLE_CONSTANTS.SLOW_BACKLOG,
+      typingWindow: config.typingWindow ?? THROTTLE_CONSTANTS.TYPING_WINDOW,
+      presenceWindow: config.presenceWindow ?? THROTTLE_CONSTANTS.PRESENCE_WINDOW,
+    };
+  }
+
+  // The configured window, stretched by how far the RTT is past slowRtt
+  private windowFor(event: string, socket: AuthenticatedSocket, settings: ThrottleSettings): number {
+    const base = event.startsWith('typing:') ? settings.typingWindow : settings.presenceWindow;
+    const factor = Math.min(Math.max((socket.data.rtt ?? 0) / settings.slowRtt, 1), THROTTLE_CONSTANTS.MAX_WINDOW_FACTOR);
+    return Math.round(base * factor);
+  }
+
+  // Typing start and stop for the same chat and user replace each other, as
+  // do presence updates for the same user
+  private coalesceKey(event: string, data: string): string {
+    const kind = event.startsWith('typing:user:') ? 'typing:user' : event;
+    try {
+      const payload = JSON.parse(data.slice(data.indexOf('[')))[1] || {};
+      return `${kind}:${payload.chatId ?? ''}:${payload.userId ?? ''}`;
+    } catch {
+      return kind;
+    }
+  }
+}
+
+export const eventThrottle = new EventThrottle();
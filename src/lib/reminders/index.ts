@@ -0,0 +1,328 @@
+import { Types } from 'mongoose';
+import { ReminderRepository } from '../database/repositories/reminder';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { Reminder, IReminder, ReminderStatus } from '../database/models/reminder';
+import { IChat } from '../database/models/chat';
+import { IUser } from '../database/models/user';
+import { changeStreamHub, ChangeStreamHandle, WakeTimer } from '../database/change-streams';
+import { jobQueue } from '../jobs';
+import { socketManager } from '../realtime/socket';
+import { t } from '../i18n';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+import { parseReminder, parseWhen } from './parse';
+
+const POLL_INTERVAL = 15 * 1000;
+const SWEEP_INTERVAL = 5 * 60 * 1000; // while change streams report new reminders
+const STALL_TIMEOUT = 5 * 60 * 1000;
+const BATCH_SIZE = 50; // deliveries per tick before yielding
+const MAX_ATTEMPTS = 3;
+const RETRY_DELAY = 60 * 1000;
+const MAX_PENDING_PER_USER = 100;
+const MAX_LEAD_TIME = 365 * 24 * 60 * 60 * 1000;
+
+// The bot account reminders are delivered from
+const REMINDER_BOT_ID = 'reminders';
+const REMINDER_BOT_NAME = 'Reminders';
+
+export type ReminderFilter = 'upcoming' | 'delivered' | 'cancelled' | 'failed';
+
+const FILTER_STATUSES: Record<ReminderFilter, ReminderStatus[]> = {
+  upcoming: ['pending', 'processing'],
+  delivered: ['delivered'],
+  cancelled: ['cancelled'],
+  failed: ['failed'],
+};
+
+export interface CreateReminderOptions {
+  dueAt: Date;
+  text?: string;
+  messageId?: string;
+  chatId?: string;
+  source: IReminder['source'];
+}
+
+// Reminders users set for themselves, free-form or on a message. A worker
+// claims due reminders and delivers each as a message from the Reminders bot
+// plus a push notification. It wakes when the next reminder is due, learning
+// of new and moved reminders from a change stream, and only polls while
+// change streams are unavailable.
+export class ReminderService {
+  private reminderRepository = new ReminderRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+  private wake = new WakeTimer(() => this.tick());
+  private changes: ChangeStreamHandle | null = null;
+
+  // Start the delivery worker. Safe to run on several instances: reminders
+  // are claimed atomically.
+  start(): void {
+    if (this.timer) return;
+
+    this.schedulePolling(POLL_INTERVAL);
+    this.changes = changeStreamHub.watch({
+      name: 'reminders',
+      model: Reminder,
+      handler: change => {
+        const reminder = 'fullDocument' in change ? change.fullDocument : undefined;
+        if (reminder?.status === 'pending') this.wake.schedule(reminder.dueAt);
+      },
+      onStatus: live => {
+        if (this.timer) this.schedulePolling(live ? SWEEP_INTERVAL : POLL_INTERVAL);
+      },
+    });
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+    this.wake.clear();
+    this.changes?.close();
+    this.changes = null;
+  }
+
+  // Resolve a natural-language time ("in 2h", "tomorrow at 9") in the
+  // user's time zone
+  resolveWhen(when: string, user: Pick<IUser, 'timezone'>): Date | null {
+    return parseWhen(when, new Date(), user.timezone || 'UTC');
+  }
+
+  async create(userId: string, options: CreateReminderOptions): Promise<IReminder> {
+    const now = Date.now();
+    if (options.dueAt.getTime() <= now) {
+      throw new ReminderError('Reminder time must be in the future', 400);
+    }
+    if (options.dueAt.getTime() > now + MAX_LEAD_TIME) {
+      throw new ReminderError('Reminders can be set at most one year ahead', 400);
+    }
+
+    if (await this.reminderRepository.countPending(userId) >= MAX_PENDING_PER_USER) {
+      throw new ReminderError(`You can have at most ${MAX_PENDING_PER_USER} pending reminders`, 429);
+    }
+
+    let text = options.text;
+    let chatId = options.chatId;
+    if (options.messageId) {
+      const message = Types.ObjectId.isValid(options.messageId)
+        ? await this.messageRepository.findById(options.messageId)
+        : null;
+      if (!message || message.isDeleted || !(await this.chatRepository.isParticipant(message.chatId, userId))) {
+        throw new ReminderError('Message not found', 404);
+      }
+
+      chatId = message.chatId.toString();
+      text = text || (message.content ? message.content.slice(0, 200) : `[${message.type}]`);
+    }
+
+    const reminder = await this.reminderRepository.create({
+      userId: new Types.ObjectId(userId),
+      text: text!,
+      chatId: chatId ? new Types.ObjectId(chatId) : undefined,
+      messageId: options.messageId ? new Types.ObjectId(options.messageId) : undefined,
+      dueAt: options.dueAt,
+      source: options.source,
+    });
+
+    socketManager.emitToUser(userId, 'reminder:created', {
+      reminderId: reminder._id.toString(),
+      text: reminder.text,
+      dueAt: reminder.dueAt.toISOString(),
+      chatId: reminder.chatId?.toString(),
+      messageId: reminder.messageId?.toString(),
+    });
+
+    metricsCollector.incrementCounter('reminders_created', 1, { source: options.source });
+    return reminder;
+  }
+
+  // Create a reminder from "/remind" arguments. Returns the reminder and a
+  // confirmation line for the user.
+  async createFromText(
+    userId: string,
+    input: string,
+    context: { chatId?: string; messageId?: string; source: IReminder['source'] }
+  ): Promise<{ reminder: IReminder; confirmation: string }> {
+    const user = await this.userRepository.findById(userId);
+    if (!user) {
+      throw new ReminderError('User not found', 404);
+    }
+
+    const timeZone = user.timezone || 'UTC';
+    const parsed = parseReminder(input, new Date(), timeZone);
+    if (!parsed || (!parsed.text && !context.messageId)) {
+      throw new ReminderError(
+        'Usage: /remind [me] <when> [to] <what>, e.g. "/remind me in 2h to call Ana" or "/remind tomorrow at 9am standup". ' +
+        'Reply to a message with "/remind in 1h" to be reminded of it.',
+        400
+      );
+    }
+
+    const reminder = await this.create(userId, {
+      dueAt: parsed.dueAt,
+      text: parsed.text || undefined,
+      messageId: context.messageId,
+      chatId: context.chatId,
+      source: context.source,
+    });
+
+    const dueAt = new Intl.DateTimeFormat(user.language, {
+      dateStyle: 'medium',
+      timeStyle: 'short',
+      timeZone,
+    }).format(reminder.dueAt);
+
+    return { reminder, confirmation: `I'll remind you on ${dueAt}: ${reminder.text}` };
+  }
+
+  async getReminder(reminderId: string): Promise<IReminder | null> {
+    return Types.ObjectId.isValid(reminderId) ? await this.reminderRepository.findById(reminderId) : null;
+  }
+
+  async getUserReminders(userId: string, filter: ReminderFilter, limit?: number, offset?: number): Promise<IReminder[]> {
+    return await this.reminderRepository.getUserReminders(userId, FILTER_STATUSES[filter], limit, offset);
+  }
+
+  // Cancel a reminder that hasn't fired yet
+  async cancel(reminder: IReminder): Promise<boolean> {
+    const cancelled = await this.reminderRepository.cancel(reminder._id);
+    if (cancelled) {
+      socketManager.emitToUser(reminder.userId.toString(), 'reminder:cancelled', {
+        reminderId: reminder._id.toString(),
+      });
+    }
+    return cancelled;
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const requeued = await this.reminderRepository.requeueStalled(new Date(Date.now() - STALL_TIMEOUT));
+      if (requeued > 0) {
+        logger.warn('Requeued stalled reminders', { count: requeued });
+      }
+
+      for (let delivered = 0; delivered < BATCH_SIZE; delivered++) {
+        const reminder = await this.reminderRepository.claimDue();
+        if (!reminder) break;
+        await this.deliver(reminder);
+      }
+
+      this.wake.schedule(await this.reminderRepository.findNextDueAt());
+    } catch (error) {
+      logger.error('Reminder worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  private schedulePolling(interval: number): void {
+    if (this.timer) clearInterval(this.timer);
+    this.timer = setInterval(() => this.tick(), interval);
+    this.timer.unref();
+  }
+
+  private async deliver(reminder: IReminder): Promise<void> {
+    const reminderId = reminder._id.toString();
+    const userId = reminder.userId.toString();
+
+    try {
+      const user = await this.userRepository.findById(userId);
+      if (!user || user.isBanned) {
+        await this.reminderRepository.markAttemptFailed(reminder._id, 'User unavailable', null);
+        return;
+      }
+
+      const bot = await this.userRepository.findOrCreateBridgeUser('bot', REMINDER_BOT_ID, REMINDER_BOT_NAME);
+      const chat = await this.getReminderChat(user, bot);
+
+      const message = await this.messageRepository.create({
+        chatId: chat._id,
+        senderId: bot._id,
+        content: t(user.language, 'reminder.message', { text: reminder.text }),
+        type: 'text',
+        metadata: {
+          reminder: {
+            reminderId: reminder._id,
+            chatId: reminder.chatId,
+            messageId: reminder.messageId,
+          },
+        },
+      });
+
+      await this.chatRepository.updateLastActivity(chat._id, message._id);
+      await this.reminderRepository.markDelivered(reminder._id, message._id);
+
+      // The reminders chat may be new, so address the user rather than the room
+      const populatedMessage = await this.messageRepository.findById(message._id);
+      socketManager.emitToUser(userId, 'message:new', populatedMessage);
+      socketManager.emitToUser(userId, 'reminder:due', {
+        reminderId,
+        text: reminder.text,
+        dueAt: reminder.dueAt.toISOString(),
+        chatId: reminder.chatId?.toString(),
+        messageId: reminder.messageId?.toString(),
+        deliveryMessageId: message._id.toString(),
+      });
+
+      await jobQueue.enqueue('push:reminder', { userId, reminderId, text: reminder.text, chatId: chat._id.toString() })
+        .catch(error => logger.error('Failed to queue reminder push', error, { reminderId }));
+
+      metricsCollector.incrementCounter('reminders_delivered', 1);
+      metricsCollector.recordGauge('reminder_delivery_lag_ms', Date.now() - reminder.dueAt.getTime());
+    } catch (error) {
+      const retry = reminder.attempts < MAX_ATTEMPTS;
+      logger.error('Reminder delivery failed', error, { reminderId, attempt: reminder.attempts, retry });
+      metricsCollector.incrementCounter('reminders_failed', 1, { final: String(!retry) });
+
+      await this.reminderRepository.markAttemptFailed(
+        reminder._id,
+        error instanceof Error ? error.message : 'Unknown error',
+        retry ? new Date(Date.now() + RETRY_DELAY) : null
+      );
+    }
+  }
+
+  // Direct chat between the user and the Reminders bot
+  private async getReminderChat(user: IUser, bot: IUser): Promise<IChat> {
+    const existing = await this.chatRepository.findDirectChat(user._id, bot._id);
+    return existing || await this.chatRepository.create({
+      participants: [user._id, bot._id],
+      type: 'direct',
+    });
+  }
+}
+
+// Client-facing view of a reminder
+export function serializeReminder(reminder: IReminder) {
+  return {
+    id: reminder._id.toString(),
+    text: reminder.text,
+    chatId: reminder.chatId?.toString(),
+    messageId: reminder.messageId?.toString(),
+    dueAt: reminder.dueAt,
+    source: reminder.source,
+    status: reminder.status,
+    deliveredAt: reminder.deliveredAt,
+    cancelledAt: reminder.cancelledAt,
+    createdAt: reminder.createdAt,
+  };
+}
+
+export class ReminderError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ReminderError';
+  }
+}
+
+export const reminderService = new ReminderService();
@@ -0,0 +1,105 @@
+import { DateUtils } from '../utils/date';
+
+const MINUTE = 60 * 1000;
+
+const UNIT_MS: Record<string, number> = {
+  m: MINUTE, min: MINUTE, mins: MINUTE, minute: MINUTE, minutes: MINUTE,
+  h: 60 * MINUTE, hr: 60 * MINUTE, hrs: 60 * MINUTE, hour: 60 * MINUTE, hours: 60 * MINUTE,
+  d: 24 * 60 * MINUTE, day: 24 * 60 * MINUTE, days: 24 * 60 * MINUTE,
+  w: 7 * 24 * 60 * MINUTE, week: 7 * 24 * 60 * MINUTE, weeks: 7 * 24 * 60 * MINUTE,
+};
+
+const DEFAULT_HOUR = 9; // "tomorrow" without a time
+const CLOCK = '(\\d{1,2})(?::(\\d{2}))?\\s*(am|pm)?';
+
+const DURATION_PART = /(\d+|an?)\s*([a-z]+)/gi;
+const DURATION = /^in\s+((?:(?:\d+|an?)\s*[a-z]+(?:\s*,\s*|\s+and\s+|\s+)?)+)$/i;
+const AT = new RegExp(`^(?:today\\s+)?at\\s+${CLOCK}$`, 'i');
+const TOMORROW = new RegExp(`^tomorrow(?:\\s+(?:at\\s+)?${CLOCK})?$`, 'i');
+const ON_DATE = new RegExp(`^on\\s+(\\d{4})-(\\d{2})-(\\d{2})(?:\\s+at\\s+${CLOCK})?$`, 'i');
+
+// Text glue between the time and the reminder text: "in 2h to call Ana"
+const LEADING_GLUE = /^(?:me\s+)?(?:to|about|that|:)\s*/i;
+
+function toHourMinute(hourText?: string, minuteText?: string, meridiem?: string): { hour: number; minute: number } | null {
+  if (hourText === undefined) return { hour: DEFAULT_HOUR, minute: 0 };
+
+  let hour = parseInt(hourText, 10);
+  const minute = minuteText ? parseInt(minuteText, 10) : 0;
+  if (meridiem) {
+    if (hour < 1 || hour > 12) return null;
+    hour = (hour % 12) + (meridiem.toLowerCase() === 'pm' ? 12 : 0);
+  }
+  return hour <= 23 && minute <= 59 ? { hour, minute } : null;
+}
+
+// Resolve a time expression to an instant, or null if it isn't one
+export function parseWhen(expression: string, now: Date = new Date(), timeZone: string = 'UTC'): Date | null {
+  const text = expression.trim().replace(/\s+/g, ' ');
+
+  const duration = DURATION.exec(text);
+  if (duration) {
+    let total = 0;
+    for (const [, amount, unit] of Array.from(duration[1].matchAll(DURATION_PART))) {
+      const unitMs = UNIT_MS[unit.toLowerCase()];
+      if (!unitMs) return null;
+      total += (/^an?$/i.test(amount) ? 1 : parseInt(amount, 10)) * unitMs;
+    }
+    return total > 0 ? new Date(now.getTime() + total) : null;
+  }
+
+  const today = DateUtils.getZonedParts(now, timeZone);
+
+  const at = AT.exec(text);
+  if (at) {
+    const time = toHourMinute(at[1], at[2], at[3]);
+    if (!time) return null;
+    const candidate = DateUtils.zonedTimeToUtc(today.year, today.month, today.day, time.hour, time.minute, timeZone);
+    // A time that already passed today means tomorrow
+    return candidate.getTime() > now.getTime()
+      ? candidate
+      : DateUtils.zonedTimeToUtc(today.year, today.month, today.day + 1, time.hour, time.minute, timeZone);
+  }
+
+  const tomorrow = TOMORROW.exec(text);
+  if (tomorrow) {
+    const time = toHourMinute(tomorrow[1], tomorrow[2], tomorrow[3]);
+    if (!time) return null;
+    return DateUtils.zonedTimeToUtc(today.year, today.month, today.day + 1, time.hour, time.minute, timeZone);
+  }
+
+  const onDate = ON_DATE.exec(text);
+  if (onDate) {
+    const time = toHourMinute(onDate[4], onDate[5], onDate[6]);
+    const month = parseInt(onDate[2], 10);
+    const day = parseInt(onDate[3], 10);
+    if (!time || month < 1 || month > 12 || day < 1 || day > 31) return null;
+    return DateUtils.zonedTimeToUtc(parseInt(onDate[1], 10), month, day, time.hour, time.minute, timeZone);
+  }
+
+  return null;
+}
+
+// Split "[me] <when> [to] <text>" or "[me] [to] <text> <when>" into the due
+// time and the reminder text. The longest time expression wins.
+export function parseReminder(
+  input: string,
+  now: Date = new Date(),
+  timeZone: string = 'UTC'
+): { dueAt: Date; text: string } | null {
+  const words = input.trim().replace(/^me\s+/i, '').split(/\s+/).filter(Boolean);
+
+  for (let length = Math.min(words.length, 8); length > 0; length--) {
+    const leading = parseWhen(words.slice(0, length).join(' '), now, timeZone);
+    if (leading) {
+      return { dueAt: leading, text: words.slice(length).join(' ').replace(LEADING_GLUE, '').trim() };
+    }
+
+    const trailing = parseWhen(words.slice(-length).join(' '), now, timeZone);
+    if (trailing && length < words.length) {
+      return { dueAt: trailing, text: words.slice(0, -length).join(' ').replace(LEADING_GLUE, '').trim() };
+    }
+  }
+
+  return null;
+}
@@ -0,0 +1,81 @@
+import { NextRequest, NextResponse } from 'next/server';
+import { adminConfigService } from '../config/admin-config';
+import { environmentConfig } from '../config/environment';
+import { getRouteBodyLimit, payloadTooLargeBody } from '../config/body-limits';
+
+export class PayloadTooLargeError extends Error {
+  limit: number;
+  received?: number;
+
+  constructor(limit: number, received?: number) {
+    super(`Request body exceeds ${limit} bytes`);
+    this.name = 'PayloadTooLargeError';
+    this.limit = limit;
+    this.received = received;
+  }
+}
+
+// Server-wide maximum: persisted admin config first, then environment
+export function getMaxRequestSize(): number {
+  return adminConfigService.getCached().server.maxRequestSize || environmentConfig.getValue('MAX_REQUEST_SIZE');
+}
+
+// Effective limit for a request path
+export function getBodyLimit(pathname: string): number {
+  return getRouteBodyLimit(pathname, getMaxRequestSize());
+}
+
+export function payloadTooLargeResponse(limit: number, received?: number): NextResponse {
+  return NextResponse.json(payloadTooLargeBody(limit, received), { status: 413 });
+}
+
+// Reject early when the declared Content-Length is already over the limit
+export function checkContentLength(request: NextRequest, limit: number): NextResponse | null {
+  const declared = parseInt(request.headers.get('content-length') || '', 10);
+  if (!isNaN(declared) && declared > limit) {
+    return payloadTooLargeResponse(limit, declared);
+  }
+  return null;
+}
+
+// Wrap a body stream so it errors as soon as more than `limit` bytes pass through
+export function limitStream(body: ReadableStream<Uint8Array>, limit: number): ReadableStream<Uint8Array> {
+  let received = 0;
+
+  return body.pipeThrough(new TransformStream<Uint8Array, Uint8Array>({
+    transform(chunk, controller) {
+      received += chunk.byteLength;
+      if (received > limit) {
+        controller.error(new PayloadTooLargeError(limit, received));
+        return;
+      }
+      controller.enqueue(chunk);
+    },
+  }));
+}
+
+// Read and parse a JSON body without buffering more than `limit` bytes
+export async function readJsonWithLimit<T = any>(request: NextRequest, limit: number = getBodyLimit(request.nextUrl.pathname)): Promise<T> {
+  const declared = parseInt(request.headers.get('content-length') || '', 10);
+  if (!isNaN(declared) && declared > limit) {
+    throw new PayloadTooLargeError(limit, declared);
+  }
+
+  if (!request.body) {
+    return {} as T;
+  }
+
+  const text = await new Response(limitStream(request.body, limit)).text();
+  return JSON.parse(text) as T;
+}
+
+// readJsonWithLimit for optional bodies: a missing or malformed body reads
+// as {}, one over the limit still throws
+export async function readOptionalJsonWithLimit<T = any>(request: NextRequest, limit?: number): Promise<T> {
+  try {
+    return await readJsonWithLimit<T>(request, limit);
+  } catch (error) {
+    if (error instanceof PayloadTooLargeError) throw error;
+    return {} as T;
+  }
+}
@@ -0,0 +1,417 @@
+import { NextRequest } from 'next/server';
+import { redisConfig } from '../config/redis';
+import { environmentConfig } from '../config/environment';
+import { adminConfigService } from '../config/admin-config';
+import { ChallengeProvider } from '../database/models/admin-config';
+import { CryptoUtils } from '../utils/crypto';
+import { ERROR_CODES } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+export type ChallengeEndpoint = 'register' | 'resend-otp';
+export type ChallengeOutcome = 'issued' | 'passed' | 'failed' | 'skipped';
+
+export interface ChallengeSettings {
+  provider: ChallengeProvider;
+  ipThreshold: number;
+  powDifficulty: number;
+}
+
+export interface ChallengeDescriptor {
+  required: boolean;
+  provider: ChallengeProvider;
+  siteKey?: string;
+  pow?: {
+    token: string;
+    difficulty: number;
+    expiresAt: Date;
+  };
+}
+
+export interface ChallengeResponse {
+  token?: string;
+  nonce?: string;
+}
+
+export interface ChallengeEnforcementResult {
+  passed: boolean;
+  status?: number;
+  error?: {
+    error: string;
+    code: string;
+    challenge: ChallengeDescriptor;
+  };
+}
+
+export interface ChallengeStats {
+  endpoint: ChallengeEndpoint;
+  issued: number;
+  passed: number;
+  failed: number;
+  skipped: number;
+  passRate: number;
+}
+
+const VERIFY_URLS: Record<'hcaptcha' | 'turnstile', string> = {
+  hcaptcha: 'https://hcaptcha.com/siteverify',
+  turnstile: 'https://challenges.cloudflare.com/turnstile/v0/siteverify',
+};
+
+const ENDPOINTS: ChallengeEndpoint[] = ['register', 'resend-otp'];
+const IP_SCORE_TTL = 60 * 60; // 1 hour
+const FAILURE_WEIGHT = 3;
+const POW_TTL = 5 * 60 * 1000; // 5 minutes
+const MAX_SPENT_PUZZLES = 10000; // remembered without Redis, until they expire
+const MAX_SPENT_PUZZLES_PER_IP = 100; // per POW_TTL window, without Redis
+const STATS_TTL = 31 * 24 * 60 * 60; // 31 days
+
+// Bot protection for unauthenticated endpoints. Clients from IPs with a clean
+// recent history pass straight through; once an IP's score reaches the
+// threshold they must solve a CAPTCHA or proof-of-work challenge.
+export class ChallengeService {
+  private redis = redisConfig.getClient();
+  private memoryScores = new Map<string, { score: number; resetAt: number }>();
+  private memoryStats = new Map<string, number>();
+  private spentPuzzles = new Map<string, { ip: string; expiresAt: number }>(); // by signature, without Redis
+  private spentPuzzlesByIp = new Map<string, number>();
+
+  // Effective settings: admin overrides on top of environment defaults
+  async getSettings(): Promise<ChallengeSettings> {
+    const env = environmentConfig.get();
+    const { security } = await adminConfigService.get();
+
+    return {
+      provider: security.challengeProvider ?? env.CHALLENGE_PROVIDER,
+      ipThreshold: security.challengeIpThreshold ?? env.CHALLENGE_IP_THRESHOLD,
+      powDifficulty: security.challengePowDifficulty ?? env.CHALLENGE_POW_DIFFICULTY,
+    };
+  }
+
+  // Describe the challenge a client must solve for this request, if any
+  async describe(request: NextRequest, endpoint: ChallengeEndpoint): Promise<ChallengeDescriptor> {
+    const settings = await this.getSettings();
+    const ip = this.getClientIp(request);
+    const required = settings.provider !== 'none' &&
+      (settings.ipThreshold === 0 || (await this.getIpScore(ip)) >= settings.ipThreshold);
+
+    return this.buildDescriptor(settings, required, endpoint);
+  }
+
+  // Verify the challenge response when one is required. Every call counts
+  // toward the caller's IP score.
+  async enforce(
+    request: NextRequest,
+    endpoint: ChallengeEndpoint,
+    response?: ChallengeResponse
+  ): Promise<ChallengeEnforcementResult> {
+    const settings = await this.getSettings();
+    if (settings.provider === 'none') {
+      return { passed: true };
+    }
+
+    const ip = this.getClientIp(request);
+    const score = await this.bumpIpScore(ip, 1);
+    const required = settings.ipThreshold === 0 || score > settings.ipThreshold;
+
+    if (!required) {
+      await this.recordOutcome(endpoint, settings.provider, 'skipped');
+      return { passed: true };
+    }
+
+    if (!response?.token) {
+      await this.recordOutcome(endpoint, settings.provider, 'issued');
+      return {
+        passed: false,
+        status: 403,
+        error: {
+          error: 'Challenge required',
+          code: ERROR_CODES.CHALLENGE_REQUIRED,
+          challenge: this.buildDescriptor(settings, true, endpoint),
+        },
+      };
+    }
+
+    const valid = settings.provider === 'pow'
+      ? await this.verifyProofOfWork(response.token, response.nonce, settings.powDifficulty, endpoint, ip)
+      : await this.verifyCaptcha(settings.provider, response.token, ip);
+
+    if (!valid) {
+      await this.bumpIpScore(ip, FAILURE_WEIGHT);
+      await this.recordOutcome(endpoint, settings.provider, 'failed');
+      logger.warn('Challenge verification failed', { ip, endpoint, provider: settings.provider });
+
+      return {
+        passed: false,
+        status: 403,
+        error: {
+          error: 'Challenge verification failed',
+          code: ERROR_CODES.CHALLENGE_FAILED,
+          challenge: this.buildDescriptor(settings, true, endpoint),
+        },
+      };
+    }
+
+    await this.recordOutcome(endpoint, settings.provider, 'passed');
+    return { passed: true };
+  }
+
+  // Pass/fail counts per endpoint over the last N days
+  async getStats(days: number = 7): Promise<ChallengeStats[]> {
+    const totals = new Map<string, number>();
+
+    for (let i = 0; i < days; i++) {
+      const day = new Date(Date.now() - i * 24 * 60 * 60 * 1000);
+      const counts = await this.getDayCounts(this.statsKey(day));
+      Object.entries(counts).forEach(([field, count]) => {
+        totals.set(field, (totals.get(field) || 0) + count);
+      });
+    }
+
+    return ENDPOINTS.map(endpoint => {
+      const get = (outcome: ChallengeOutcome) => totals.get(`${endpoint}:${outcome}`) || 0;
+      const passed = get('passed');
+      const failed = get('failed');
+
+      return {
+        endpoint,
+        issued: get('issued'),
+        passed,
+        failed,
+        skipped: get('skipped'),
+        passRate: passed + failed > 0 ? passed / (passed + failed) : 1,
+      };
+    });
+  }
+
+  private buildDescriptor(
+    settings: ChallengeSettings,
+    required: boolean,
+    endpoint: ChallengeEndpoint
+  ): ChallengeDescriptor {
+    if (!required) {
+      return { required: false, provider: settings.provider };
+    }
+
+    if (settings.provider === 'pow') {
+      return {
+        required: true,
+        provider: 'pow',
+        pow: this.issueProofOfWork(settings.powDifficulty, endpoint),
+      };
+    }
+
+    // getSettings() has loaded the configuration already
+    const { security } = adminConfigService.getCached();
+    return {
+      required: true,
+      provider: settings.provider,
+      siteKey: security.challengeSiteKey || environmentConfig.getValue('CHALLENGE_SITE_KEY'),
+    };
+  }
+
+  // Stateless, signed proof-of-work puzzle: find a nonce such that
+  // sha256(token + ':' + nonce) starts with `difficulty` zero bits
+  private issueProofOfWork(difficulty: number, endpoint: ChallengeEndpoint) {
+    const expiresAt = new Date(Date.now() + POW_TTL);
+    const payload = `${CryptoUtils.generateRandomBytes(16).toString('hex')}.${difficulty}.${expiresAt.getTime()}.${endpoint}`;
+    const signature = CryptoUtils.hmac(payload, environmentConfig.getValue('JWT_SECRET'));
+
+    return {
+      token: `${payload}.${signature}`,
+      difficulty,
+      expiresAt,
+    };
+  }
+
+  private async verifyProofOfWork(
+    token: string,
+    nonce: string | undefined,
+    minDifficulty: number,
+    endpoint: ChallengeEndpoint,
+    ip: string
+  ): Promise<boolean> {
+    if (!nonce || nonce.length > 64) return false;
+
+    const parts = token.split('.');
+    if (parts.length !== 5) return false;
+
+    const [, difficultyRaw, expiresRaw, tokenEndpoint, signature] = parts;
+    const payload = parts.slice(0, 4).join('.');
+    const expected = CryptoUtils.hmac(payload, environmentConfig.getValue('JWT_SECRET'));
+    if (!CryptoUtils.constantTimeEqual(signature, expected)) return false;
+
+    // A puzzle issued for one endpoint doesn't open another
+    if (tokenEndpoint !== endpoint) return false;
+
+    const difficulty = parseInt(difficultyRaw, 10);
+    const expiresAt = parseInt(expiresRaw, 10);
+    if (difficulty < minDifficulty || Date.now() > expiresAt) return false;
+
+    const digest = CryptoUtils.generateFileChecksum(Buffer.from(`${token}:${nonce}`));
+    if (!this.hasLeadingZeroBits(digest, difficulty)) return false;
+
+    // Each puzzle may only be redeemed once
+    if (this.redis) {
+      const stored = await this.redis.set(`challenge:pow:used:${signature}`, '1', 'PX', POW_TTL, 'NX');
+      return stored !== null;
+    }
+    return this.spendInMemory(signature, expiresAt, ip);
+  }
+
+  // Single use without Redis, on this server. Each IP may only hold
+  // MAX_SPENT_PUZZLES_PER_IP unexpired solutions, so one client can't crowd
+  // out everyone else; past MAX_SPENT_PUZZLES overall the oldest are
+  // forgotten, since they are closest to expiring anyway.
+  private spendInMemory(signature: string, expiresAt: number, ip: string): boolean {
+    const now = Date.now();
+    for (const [spent, entry] of this.spentPuzzles) {
+      if (entry.expiresAt > now) break;
+      this.forgetSpentPuzzle(spent);
+    }
+
+    if (this.spentPuzzles.has(signature)) return false;
+
+    const ipCount = this.spentPuzzlesByIp.get(ip) ?? 0;
+    if (ipCount >= MAX_SPENT_PUZZLES_PER_IP) {
+      logger.warn('Too many proof-of-work solutions from one IP', { ip });
+      return false;
+    }
+
+    if (this.spentPuzzles.size >= MAX_SPENT_PUZZLES) {
+      const oldest = this.spentPuzzles.keys().next().value;
+      if (oldest !== undefined) this.forgetSpentPuzzle(oldest);
+    }
+
+    this.spentPuzzles.set(signature, { ip, expiresAt });
+    this.spentPuzzlesByIp.set(ip, ipCount + 1);
+    return true;
+  }
+
+  private forgetSpentPuzzle(signature: string): void {
+    const entry = this.spentPuzzles.get(signature);
+    if (!entry) return;
+
+    this.spentPuzzles.delete(signature);
+    const remaining = (this.spentPuzzlesByIp.get(entry.ip) ?? 1) - 1;
+    if (remaining > 0) {
+      this.spentPuzzlesByIp.set(entry.ip, remaining);
+    } else {
+      this.spentPuzzlesByIp.delete(entry.ip);
+    }
+  }
+
+  private hasLeadingZeroBits(hexDigest: string, bits: number): boolean {
+    const fullNibbles = Math.floor(bits / 4);
+    for (let i = 0; i < fullNibbles; i++) {
+      if (hexDigest[i] !== '0') return false;
+    }
+
+    const remaining = bits % 4;
+    if (remaining === 0) return true;
+    return parseInt(hexDigest[fullNibbles], 16) < (1 << (4 - remaining));
+  }
+
+  private async verifyCaptcha(provider: 'hcaptcha' | 'turnstile', token: string, ip: string): Promise<boolean> {
+    const { security } = await adminConfigService.get();
+    const secret = security.challengeSecretKey || environmentConfig.getValue('CHALLENGE_SECRET_KEY');
+    if (!secret) {
+      logger.error('Challenge secret key not configured', undefined, { provider });
+      return false;
+    }
+
+    try {
+      const body = new URLSearchParams({ secret, response: token });
+      if (ip !== 'unknown') {
+        body.set('remoteip', ip);
+      }
+
+      const res = await fetch(VERIFY_URLS[provider], {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+        body,
+      });
+      const data = await res.json();
+
+      return data.success === true;
+    } catch (error) {
+      logger.error('Captcha verification request failed', error, { provider });
+      return false;
+    }
+  }
+
+  private getClientIp(request: NextRequest): string {
+    return request.headers.get('x-forwarded-for')?.split(',')[0].trim() ||
+      request.headers.get('x-real-ip') ||
+      'unknown';
+  }
+
+  private async getIpScore(ip: string): Promise<number> {
+    if (this.redis) {
+      const value = await this.redis.get(`challenge:ip:${ip}`);
+      return value ? parseInt(value, 10) : 0;
+    }
+
+    const entry = this.memoryScores.get(ip);
+    return entry && entry.resetAt > Date.now() ? entry.score : 0;
+  }
+
+  private async bumpIpScore(ip: string, amount: number): Promise<number> {
+    if (this.redis) {
+      const key = `challenge:ip:${ip}`;
+      const results = await this.redis.multi().incrby(key, amount).expire(key, IP_SCORE_TTL).exec();
+      return Number(results?.[0]?.[1] ?? 0);
+    }
+
+    const score = (await this.getIpScore(ip)) + amount;
+    this.memoryScores.set(ip, { score, resetAt: Date.now() + IP_SCORE_TTL * 1000 });
+    return score;
+  }
+
+  private async recordOutcome(
+    endpoint: ChallengeEndpoint,
+    provider: ChallengeProvider,
+    outcome: ChallengeOutcome
+  ): Promise<void> {
+    metricsCollector.incrementCounter('challenge_outcomes', 1, { endpoint, provider, outcome });
+
+    const key = this.statsKey(new Date());
+    const field = `${endpoint}:${outcome}`;
+
+    try {
+      if (this.redis) {
+        await this.redis.multi().hincrby(key, field, 1).expire(key, STATS_TTL).exec();
+      } else {
+        const memoryKey = `${key}|${field}`;
+        this.memoryStats.set(memoryKey, (this.memoryStats.get(memoryKey) || 0) + 1);
+      }
+    } catch (error) {
+      logger.error('Failed to record challenge outcome', error, { endpoint, outcome });
+    }
+  }
+
+  private async getDayCounts(key: string): Promise<Record<string, number>> {
+    const counts: Record<string, number> = {};
+
+    if (this.redis) {
+      const raw = await this.redis.hgetall(key);
+      Object.entries(raw).forEach(([field, value]) => {
+        counts[field] = parseInt(value, 10);
+      });
+      return counts;
+    }
+
+    this.memoryStats.forEach((count, memoryKey) => {
+      const [dayKey, field] = memoryKey.split('|');
+      if (dayKey === key) {
+        counts[field] = count;
+      }
+    });
+    return counts;
+  }
+
+  private statsKey(date: Date): string {
+    return `challenge:stats:${date.toISOString().slice(0, 10)}`;
+  }
+}
+
+export const challengeService = new ChallengeService();
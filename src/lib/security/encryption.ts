@@ -1,5 +1,8 @@
 import crypto from 'crypto';
 import { promisify } from 'util';
+import { environmentConfig } from '../config/environment';
+
+const SECRET_PREFIX = 'enc:v1:';
 
 interface EncryptionResult {
   encrypted: string;
@@ -149,6 +152,45 @@ export class EncryptionService {
     }
   }
 
+  // Encrypt a secret for storage (provider keys and tokens kept in the
+  // database) with a key derived from ENCRYPTION_KEY. The result is
+  // self-describing: "enc:v1:<iv>:<tag>:<ciphertext>", base64.
+  encryptSecret(plaintext: string): string {
+    const iv = crypto.randomBytes(12);
+    const cipher = crypto.createCipheriv('aes-256-gcm', this.secretKey(), iv);
+    const encrypted = Buffer.concat([cipher.update(plaintext, 'utf8'), cipher.final()]);
+    const tag = cipher.getAuthTag();
+
+    return `${SECRET_PREFIX}${iv.toString('base64')}:${tag.toString('base64')}:${encrypted.toString('base64')}`;
+  }
+
+  // Decrypt a value from encryptSecret; throws if it was tampered with or
+  // encrypted under another key
+  decryptSecret(value: string): string {
+    const [iv, tag, encrypted] = value.slice(SECRET_PREFIX.length).split(':');
+    if (!iv || !tag || encrypted === undefined) {
+      throw new Error('Malformed encrypted secret');
+    }
+
+    const decipher = crypto.createDecipheriv('aes-256-gcm', this.secretKey(), Buffer.from(iv, 'base64'));
+    decipher.setAuthTag(Buffer.from(tag, 'base64'));
+    return Buffer.concat([decipher.update(Buffer.from(encrypted, 'base64')), decipher.final()]).toString('utf8');
+  }
+
+  isEncryptedSecret(value: unknown): value is string {
+    return typeof value === 'string' && value.startsWith(SECRET_PREFIX);
+  }
+
+  private secretKey(): Buffer {
+    return Buffer.from(crypto.hkdfSync(
+      'sha256',
+      environmentConfig.getValue('ENCRYPTION_KEY'),
+      Buffer.alloc(0),
+      'chatapp:stored-secrets',
+      this.keyLength
+    ));
+  }
+
   // Generate secure random token
   generateSecureToken(length: number = 32): string {
     return crypto.randomBytes(length).toString('hex');
@@ -0,0 +1,152 @@
+import maxmind, { Reader, CityResponse, AsnResponse } from 'maxmind';
+import { NextRequest } from 'next/server';
+import { redisConfig } from '../config/redis';
+import { environmentConfig } from '../config/environment';
+import { adminConfigService } from '../config/admin-config';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+export interface GeoLocation {
+  country?: string; // ISO 3166-1 alpha-2
+  city?: string;
+  asn?: number;
+  asOrganization?: string;
+}
+
+const CACHE_TTL = 24 * 60 * 60; // 1 day
+const LOOKUP_TIMEOUT = 2000;
+
+// Country (and city where known) headers set by the CDN in front of us
+const COUNTRY_HEADERS = ['cf-ipcountry', 'x-vercel-ip-country', 'cloudfront-viewer-country', 'x-country-code'];
+const CITY_HEADERS = ['cf-ipcity', 'x-vercel-ip-city', 'cloudfront-viewer-city'];
+
+// Where a request comes from. The MaxMind databases (GEOIP_CITY_DB,
+// GEOIP_ASN_DB) are used when configured and reloaded when the files are
+// replaced; otherwise CDN headers, then GEOIP_LOOKUP_URL if set, cached for
+// a day. Never throws: an unknown location just means no location checks.
+export class GeoIpService {
+  private redis = redisConfig.getClient();
+  private cityReader: Promise<Reader<CityResponse> | null> | null = null;
+  private asnReader: Promise<Reader<AsnResponse> | null> | null = null;
+
+  // Client IP and location of a request
+  async locateRequest(request: NextRequest): Promise<{ ip: string | null; location: GeoLocation }> {
+    const ip = request.headers.get('x-forwarded-for')?.split(',')[0]?.trim() || request.headers.get('x-real-ip');
+    return { ip, location: await this.locate(request.headers, ip) };
+  }
+
+  async locate(headers: Headers, ip?: string | null): Promise<GeoLocation> {
+    // The ASN database may be configured without the city one
+    const resolved = ip ? await this.resolve(ip) : {};
+    if (resolved.country) return resolved;
+
+    const country = this.firstHeader(headers, COUNTRY_HEADERS)?.toUpperCase();
+    // 'XX' and 'T1' are Cloudflare's unknown and Tor
+    if (country && /^[A-Z]{2}$/.test(country) && country !== 'XX' && country !== 'T1') {
+      const city = this.firstHeader(headers, CITY_HEADERS);
+      return { ...resolved, country, ...(city && { city: decodeURIComponent(city) }) };
+    }
+
+    return ip ? { ...resolved, ...await this.lookup(ip) } : resolved;
+  }
+
+  // Location of an IP from the MaxMind databases; empty without them
+  async resolve(ip: string): Promise<GeoLocation> {
+    if (!maxmind.validate(ip)) return {};
+
+    const [cityReader, asnReader] = await Promise.all([this.getCityReader(), this.getAsnReader()]);
+    const city = cityReader?.get(ip);
+    const asn = asnReader?.get(ip);
+
+    return {
+      ...(city?.country?.iso_code && { country: city.country.iso_code }),
+      ...(city?.city?.names?.en && { city: city.city.names.en }),
+      ...(asn?.autonomous_system_number && { asn: asn.autonomous_system_number }),
+      ...(asn?.autonomous_system_organization && { asOrganization: asn.autonomous_system_organization }),
+    };
+  }
+
+  // Whether the country rules in the security settings shut this location
+  // out. Unknown locations are let through.
+  async isBlocked(location: GeoLocation): Promise<boolean> {
+    if (!location.country) return false;
+
+    const { security } = await adminConfigService.get();
+    const blocked = !!security.blockedCountries?.includes(location.country)
+      || (!!security.allowedCountries?.length && !security.allowedCountries.includes(location.country));
+
+    if (blocked) {
+      metricsCollector.incrementCounter('geoip_blocked_requests', 1, { country: location.country });
+    }
+    return blocked;
+  }
+
+  private getCityReader(): Promise<Reader<CityResponse> | null> {
+    if (!this.cityReader) {
+      this.cityReader = this.openDatabase<CityResponse>(environmentConfig.get().GEOIP_CITY_DB);
+    }
+    return this.cityReader;
+  }
+
+  private getAsnReader(): Promise<Reader<AsnResponse> | null> {
+    if (!this.asnReader) {
+      this.asnReader = this.openDatabase<AsnResponse>(environmentConfig.get().GEOIP_ASN_DB);
+    }
+    return this.asnReader;
+  }
+
+  private async openDatabase<T extends CityResponse | AsnResponse>(path?: string): Promise<Reader<T> | null> {
+    if (!path) return null;
+
+    try {
+      const reader = await maxmind.open<T>(path, { watchForUpdates: true, watchForUpdatesNonPersistent: true });
+      logger.info('GeoIP database loaded', { path });
+      return reader;
+    } catch (error) {
+      logger.error('Failed to open GeoIP database', error, { path });
+      return null;
+    }
+  }
+
+  private async lookup(ip: string): Promise<GeoLocation> {
+    const url = environmentConfig.get().GEOIP_LOOKUP_URL;
+    if (!url) return {};
+
+    const cacheKey = `geoip:${ip}`;
+    try {
+      const cached = await this.redis.get(cacheKey);
+      if (cached) return JSON.parse(cached);
+
+      const response = await fetch(url.replace('{ip}', encodeURIComponent(ip)), {
+        signal: AbortSignal.timeout(LOOKUP_TIMEOUT),
+      });
+      if (!response.ok) {
+        logger.warn('GeoIP lookup failed', { status: response.status });
+        return {};
+      }
+
+      const body = await response.json();
+      const country = String(body.country_code || body.countryCode || body.country || '').toUpperCase();
+      const location: GeoLocation = {
+        ...(/^[A-Z]{2}$/.test(country) && { country }),
+        ...(typeof body.city === 'string' && body.city && { city: body.city }),
+      };
+
+      await this.redis.setex(cacheKey, CACHE_TTL, JSON.stringify(location));
+      return location;
+    } catch (error) {
+      logger.warn('GeoIP lookup error', { error: error instanceof Error ? error.message : String(error) });
+      return {};
+    }
+  }
+
+  private firstHeader(headers: Headers, names: string[]): string | undefined {
+    for (const name of names) {
+      const value = headers.get(name);
+      if (value) return value;
+    }
+    return undefined;
+  }
+}
+
+export const geoIpService = new GeoIpService();
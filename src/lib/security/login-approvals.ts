@@ -0,0 +1,355 @@
+import { Types } from 'mongoose';
+import { LoginApprovalRepository } from '../database/repositories/login-approval';
+import { SecurityEventRepository } from '../database/repositories/security-event';
+import { UserRepository } from '../database/repositories/user';
+import { ILoginApproval, LoginAnomaly } from '../database/models/login-approval';
+import { ISecurityEvent, SecurityEventType } from '../database/models/security-event';
+import { IUser } from '../database/models/user';
+import { GeoLocation } from './geoip';
+import { jwtService } from '../auth/jwt';
+import { pushNotificationService } from '../communication/push-notifications';
+import { smsService } from '../communication/sms';
+import { environmentConfig } from '../config/environment';
+import { t } from '../i18n';
+import { CryptoUtils } from '../utils/crypto';
+import { APP_CONFIG } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+export interface LoginAttempt {
+  deviceId: string;
+  ip?: string | null;
+  userAgent?: string | null;
+  platform?: string;
+  location: GeoLocation;
+}
+
+// Sign-in anomaly detection. A successful sign-in from a device or country
+// the user hasn't signed in from before is flagged: the user's other
+// devices get a push (or, without any, an SMS link) to approve or deny it,
+// and until then the new session can't use endpoints marked sensitive.
+// Unanswered approvals lapse after LOGIN_APPROVAL_HOURS; denying one signs
+// the device out. Every sign-in, failed attempt and decision goes to the
+// user's security log.
+export class LoginApprovalService {
+  private loginApprovalRepository = new LoginApprovalRepository();
+  private securityEventRepository = new SecurityEventRepository();
+  private userRepository = new UserRepository();
+
+  // Check a successful sign-in; returns the approval it now waits for, if
+  // any. `user` must be loaded before the sign-in recorded its device.
+  async assessLogin(user: IUser, attempt: LoginAttempt): Promise<ILoginApproval | null> {
+    const anomalies = await this.detectAnomalies(user, attempt);
+
+    if (anomalies.length === 0) {
+      await this.record(user._id, 'login_succeeded', attempt);
+      return null;
+    }
+
+    const token = CryptoUtils.generateRandomString(32);
+    const hours = environmentConfig.get().LOGIN_APPROVAL_HOURS;
+    const approval = await this.loginApprovalRepository.create({
+      userId: user._id,
+      deviceId: attempt.deviceId,
+      anomalies,
+      ip: attempt.ip || undefined,
+      country: attempt.location.country,
+      city: attempt.location.city,
+      asn: attempt.location.asn,
+      asOrganization: attempt.location.asOrganization,
+      userAgent: attempt.userAgent || undefined,
+      platform: attempt.platform,
+      tokenHash: CryptoUtils.hash(token),
+      expiresAt: new Date(Date.now() + hours * 60 * 60 * 1000),
+    });
+
+    await this.record(user._id, 'login_flagged', attempt, approval._id, { anomalies });
+    await this.notify(user, approval, token);
+
+    metricsCollector.incrementCounter('login_approvals_requested', 1, { anomalies: anomalies.join(',') });
+    logger.info('Sign-in flagged for approval', {
+      userId: user._id.toString(),
+      loginApprovalId: approval._id.toString(),
+      deviceId: attempt.deviceId,
+      anomalies,
+      country: attempt.location.country,
+    });
+
+    return approval;
+  }
+
+  // Record a failed sign-in attempt
+  async recordFailedLogin(user: IUser, attempt: LoginAttempt, details: Record<string, unknown>): Promise<void> {
+    await this.record(user._id, 'login_failed', attempt, undefined, details);
+  }
+
+  // Whether the session's sign-in is still waiting for approval
+  async isPending(userId: string, deviceId: string): Promise<boolean> {
+    return await this.loginApprovalRepository.isPending(userId, deviceId);
+  }
+
+  async listPending(userId: string): Promise<ILoginApproval[]> {
+    return await this.loginApprovalRepository.findPending(userId);
+  }
+
+  async listEvents(userId: string, limit: number = 50, offset: number = 0, types?: SecurityEventType[]): Promise<ISecurityEvent[]> {
+    return await this.securityEventRepository.findByUser(userId, limit, offset, types);
+  }
+
+  // Sign-in outcomes per country over the last `days`, busiest first
+  async getCountryStats(days: number) {
+    const since = new Date(Date.now() - days * 24 * 60 * 60 * 1000);
+    const counts = await this.securityEventRepository.countByCountry(since);
+
+    const byCountry = new Map<string, Record<SecurityEventType, number> & { users: number }>();
+    for (const { country, type, count, users } of counts) {
+      const key = country || 'unknown';
+      const row = byCountry.get(key) || {
+        login_succeeded: 0, login_failed: 0, login_flagged: 0, login_approved: 0, login_denied: 0, users: 0,
+      };
+      row[type] += count;
+      row.users = Math.max(row.users, users); // distinct users of the busiest event type
+      byCountry.set(key, row);
+    }
+
+    return [...byCountry.entries()]
+      .map(([country, row]) => {
+        const attempts = row.login_succeeded + row.login_flagged + row.login_failed;
+        return {
+          country,
+          succeeded: row.login_succeeded,
+          failed: row.login_failed,
+          flagged: row.login_flagged,
+          approved: row.login_approved,
+          denied: row.login_denied,
+          users: row.users,
+          failureRate: attempts > 0 ? Math.round(row.login_failed / attempts * 1000) / 1000 : 0,
+        };
+      })
+      .sort((a, b) => (b.succeeded + b.failed + b.flagged) - (a.succeeded + a.failed + a.flagged));
+  }
+
+  // Answer from one of the user's signed-in devices
+  async decide(
+    userId: string,
+    approvalId: string,
+    decision: 'approve' | 'deny',
+    fromDeviceId: string
+  ): Promise<ILoginApproval> {
+    const approval = Types.ObjectId.isValid(approvalId)
+      ? await this.loginApprovalRepository.findById(approvalId)
+      : null;
+    if (!approval || approval.userId.toString() !== userId) {
+      throw new LoginApprovalError('Sign-in not found', 404);
+    }
+    if (approval.deviceId === fromDeviceId) {
+      throw new LoginApprovalError('A sign-in has to be answered from another device', 403);
+    }
+
+    return await this.applyDecision(approval, decision, fromDeviceId);
+  }
+
+  // Answer through the link in the SMS
+  async decideWithToken(token: string, decision: 'approve' | 'deny'): Promise<ILoginApproval> {
+    const approval = await this.loginApprovalRepository.findByTokenHash(CryptoUtils.hash(token));
+    if (!approval) {
+      throw new LoginApprovalError('Sign-in not found', 404);
+    }
+
+    return await this.applyDecision(approval, decision, 'sms');
+  }
+
+  // New device: neither a registered device nor one signed in from before.
+  // New location: a country not seen in earlier sign-ins. First sign-ins,
+  // and users with no located sign-ins yet, set the baseline instead.
+  private async detectAnomalies(user: IUser, attempt: LoginAttempt): Promise<LoginAnomaly[]> {
+    const hasHistory = (user.devices || []).length > 0
+      || await this.securityEventRepository.hasSucceededLogin(user._id);
+    if (!hasHistory) return [];
+
+    const anomalies: LoginAnomaly[] = [];
+
+    const knownDevice = (user.devices || []).some(device => device.deviceId === attempt.deviceId)
+      || await this.securityEventRepository.hasSucceededLogin(user._id, attempt.deviceId);
+    if (!knownDevice) {
+      anomalies.push('new_device');
+    }
+
+    const { country } = attempt.location;
+    if (country) {
+      const countries = await this.securityEventRepository.getLoginCountries(user._id);
+      if (countries.length > 0 && !countries.includes(country)) {
+        anomalies.push('new_location');
+      }
+    }
+
+    return anomalies;
+  }
+
+  private async applyDecision(
+    approval: ILoginApproval,
+    decision: 'approve' | 'deny',
+    decidedFrom: string
+  ): Promise<ILoginApproval> {
+    const status = decision === 'approve' ? 'approved' : 'denied';
+    const decided = await this.loginApprovalRepository.decide(approval._id, status, decidedFrom);
+    if (!decided) {
+      throw new LoginApprovalError(
+        approval.status === 'pending' ? 'This sign-in can no longer be answered' : `Sign-in already ${approval.status}`,
+        409
+      );
+    }
+
+    const userId = decided.userId.toString();
+
+    if (status === 'denied') {
+      await jwtService.invalidateDeviceTokens(userId, decided.deviceId);
+      await this.userRepository.clearDeviceTokens(decided.userId, decided.deviceId);
+    }
+
+    await this.record(decided.userId, status === 'approved' ? 'login_approved' : 'login_denied', {
+      deviceId: decided.deviceId,
+      ip: decided.ip,
+      userAgent: decided.userAgent,
+      platform: decided.platform,
+      location: {
+        country: decided.country,
+        city: decided.city,
+        asn: decided.asn,
+        asOrganization: decided.asOrganization,
+      },
+    }, decided._id, { decidedFrom });
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToUser(userId, 'security:login:decided', {
+      loginApprovalId: decided._id.toString(),
+      deviceId: decided.deviceId,
+      status,
+    });
+
+    metricsCollector.incrementCounter('login_approvals_decided', 1, { status, via: decidedFrom === 'sms' ? 'sms' : 'device' });
+    logger.info('Sign-in approval answered', {
+      userId,
+      loginApprovalId: decided._id.toString(),
+      status,
+      decidedFrom,
+    });
+
+    return decided;
+  }
+
+  // Push to the user's other devices; SMS when none of them could be reached
+  private async notify(user: IUser, approval: ILoginApproval, token: string): Promise<void> {
+    const userId = user._id.toString();
+    const device = approval.platform || approval.userAgent?.slice(0, 40) || t(user.language, 'login.device.unknown');
+    const location = [approval.city, approval.country].filter(Boolean).join(', ')
+      || t(user.language, 'login.location.unknown');
+    const notifiedBy: ILoginApproval['notifiedBy'] = [];
+
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToUser(userId, 'security:login:pending', {
+      loginApprovalId: approval._id.toString(),
+      deviceId: approval.deviceId,
+      anomalies: approval.anomalies,
+      country: approval.country,
+      city: approval.city,
+      platform: approval.platform,
+      expiresAt: approval.expiresAt.toISOString(),
+    });
+
+    try {
+      const results = await pushNotificationService.sendLoginApprovalRequest(user, {
+        approvalId: approval._id.toString(),
+        deviceId: approval.deviceId,
+        device,
+        location,
+      });
+      if (results.some(result => result.success)) {
+        notifiedBy.push('push');
+      }
+    } catch (error) {
+      logger.error('Sign-in approval push failed', error, { userId });
+    }
+
+    if (notifiedBy.length === 0) {
+      const link = `${APP_CONFIG.FRONTEND_URL}/security/sign-in?token=${token}`;
+      const result = await smsService.sendLoginApprovalSMS(user.phoneNumber, { device, location, link }, user.language);
+      if (result.success) {
+        notifiedBy.push('sms');
+      } else {
+        logger.warn('Sign-in approval SMS failed', { userId, error: result.error });
+      }
+    }
+
+    await this.loginApprovalRepository.setNotifiedBy(approval._id, notifiedBy);
+  }
+
+  private async record(
+    userId: Types.ObjectId,
+    type: SecurityEventType,
+    attempt: LoginAttempt,
+    loginApprovalId?: Types.ObjectId,
+    details?: Record<string, unknown>
+  ): Promise<void> {
+    await this.securityEventRepository.create({
+      userId,
+      type,
+      deviceId: attempt.deviceId,
+      ip: attempt.ip || undefined,
+      country: attempt.location.country,
+      city: attempt.location.city,
+      asn: attempt.location.asn,
+      asOrganization: attempt.location.asOrganization,
+      userAgent: attempt.userAgent || undefined,
+      loginApprovalId,
+      details: { ...(attempt.platform && { platform: attempt.platform }), ...details },
+    });
+
+    metricsCollector.incrementCounter('security_events', 1, { type, country: attempt.location.country || 'unknown' });
+  }
+}
+
+export function serializeLoginApproval(approval: ILoginApproval) {
+  return {
+    id: approval._id.toString(),
+    deviceId: approval.deviceId,
+    anomalies: approval.anomalies,
+    ip: approval.ip,
+    country: approval.country,
+    city: approval.city,
+    asn: approval.asn,
+    asOrganization: approval.asOrganization,
+    platform: approval.platform,
+    userAgent: approval.userAgent,
+    status: approval.status,
+    decidedAt: approval.decidedAt,
+    expiresAt: approval.expiresAt,
+    createdAt: approval.createdAt,
+  };
+}
+
+export function serializeSecurityEvent(event: ISecurityEvent) {
+  return {
+    id: event._id.toString(),
+    type: event.type,
+    deviceId: event.deviceId,
+    ip: event.ip,
+    country: event.country,
+    city: event.city,
+    asn: event.asn,
+    asOrganization: event.asOrganization,
+    userAgent: event.userAgent,
+    loginApprovalId: event.loginApprovalId?.toString(),
+    details: event.details,
+    createdAt: event.createdAt,
+  };
+}
+
+export class LoginApprovalError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'LoginApprovalError';
+  }
+}
+
+export const loginApprovalService = new LoginApprovalService();
@@ -36,6 +36,8 @@ export enum Permission {
   DELETE_ANY_MESSAGE = 'delete_any_message',
   VIEW_ANALYTICS = 'view_analytics',
   MANAGE_SYSTEM = 'manage_system',
+  VIEW_PHONE_NUMBERS = 'view_phone_numbers', // unmasked in admin views
+  REVEAL_PII = 'reveal_pii', // phone numbers and message content, with a justification
 }
 
 // Role definitions
@@ -63,6 +65,7 @@ export const ROLE_PERMISSIONS = {
     Permission.VIEW_USERS,
     Permission.VIEW_MESSAGES,
     Permission.DELETE_ANY_MESSAGE,
+    Permission.REVEAL_PII,
   ],
   
   admin: [
@@ -72,6 +75,7 @@ export const ROLE_PERMISSIONS = {
     Permission.VIEW_MESSAGES,
     Permission.DELETE_ANY_MESSAGE,
     Permission.VIEW_ANALYTICS,
+    Permission.REVEAL_PII,
   ],
   
   super_admin: [
@@ -82,6 +86,8 @@ export const ROLE_PERMISSIONS = {
     Permission.DELETE_ANY_MESSAGE,
     Permission.VIEW_ANALYTICS,
     Permission.MANAGE_SYSTEM,
+    Permission.VIEW_PHONE_NUMBERS,
+    Permission.REVEAL_PII,
   ],
 };
 
@@ -170,10 +176,11 @@ export class PermissionService {
   }
 
   // Check if user can manage group
-  async canManageGroup(userId: string, chatId: string, action: 'add_members' | 'remove_members' | 'edit_info' | 'promote'): Promise<boolean> {
+  async canManageGroup(userId: string, chatId: string, action: 'add_members' | 'remove_members' | 'edit_info' | 'promote' | 'manage_integrations' | 'moderate' | 'manage_emoji' | 'manage_guests' | 'view_analytics' | 'manage_voice_channels'): Promise<boolean> {
     try {
+      // Guests never manage the group they were let into
       const user = await this.userRepository.findById(userId);
-      if (!user || user.isBanned) {
+      if (!user || user.isBanned || user.guest) {
         return false;
       }
 
@@ -195,6 +202,12 @@ export class PermissionService {
         case 'edit_info':
           return isGroupAdmin || settings?.whoCanEditGroupInfo === 'everyone';
         case 'promote':
+        case 'manage_integrations':
+        case 'moderate':
+        case 'manage_emoji':
+        case 'manage_guests':
+        case 'view_analytics':
+        case 'manage_voice_channels':
           return isGroupAdmin;
         default:
           return false;
@@ -204,6 +217,19 @@ export class PermissionService {
     }
   }
 
+  // Check if a profile field the owner restricts in their privacy settings
+  // is visible to the viewer. Users the owner blocked see nothing.
+  canSeeProfileField(
+    owner: Pick<IUser, '_id' | 'contacts' | 'blockedUsers'>,
+    viewerId: string,
+    setting: IUser['privacySettings']['status'] = 'everyone'
+  ): boolean {
+    if (owner._id.toString() === viewerId) return true;
+    if ((owner.blockedUsers || []).some(id => id.toString() === viewerId)) return false;
+    if (setting === 'everyone') return true;
+    return setting === 'contacts' && (owner.contacts || []).some(id => id.toString() === viewerId);
+  }
+
   // Check if user can upload media
   async canUploadMedia(userId: string, chatId?: string): Promise<boolean> {
     try {
@@ -211,6 +237,10 @@ export class PermissionService {
       if (!user || user.isBanned || !this.hasPermission(user, Permission.UPLOAD_MEDIA)) {
         return false;
       }
+      // Guests only upload when their link allows it
+      if (user.guest && !user.guest.capabilities?.fileUpload) {
+        return false;
+      }
 
       if (chatId) {
         return await this.canAccessChat(userId, chatId);
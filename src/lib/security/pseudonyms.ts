@@ -0,0 +1,171 @@
+import { GroupPseudonymRepository } from '../database/repositories/group-pseudonym';
+import { IGroupPseudonym } from '../database/models/group-pseudonym';
+import { IChat } from '../database/models/chat';
+import { IMessage } from '../database/models/message';
+import { logger } from '../monitoring/logging';
+
+const ADJECTIVES = [
+  'Amber', 'Brave', 'Calm', 'Clever', 'Cosmic', 'Crimson', 'Dusty', 'Gentle',
+  'Golden', 'Hidden', 'Jolly', 'Lucky', 'Misty', 'Nimble', 'Quiet', 'Rapid',
+  'Silver', 'Sleepy', 'Sunny', 'Swift', 'Tidy', 'Velvet', 'Witty', 'Zesty',
+];
+const ANIMALS = [
+  'Badger', 'Beaver', 'Crane', 'Dolphin', 'Falcon', 'Ferret', 'Fox', 'Gecko',
+  'Heron', 'Ibis', 'Koala', 'Lynx', 'Marten', 'Moose', 'Otter', 'Owl',
+  'Panda', 'Puffin', 'Raven', 'Seal', 'Stoat', 'Tapir', 'Walrus', 'Wombat',
+];
+const MAX_ATTEMPTS = 5;
+
+type PollView = NonNullable<NonNullable<IMessage['metadata']>['poll']>;
+
+const idOf = (value: any): string | undefined => (value?._id ?? value)?.toString();
+
+// Pseudonymous groups and anonymous polls. In a group with
+// pseudonymousMembers on, messages from members who aren't admins carry a
+// pseudonym the server assigns once per member and group; other members see
+// it in place of the sender, while admins and the sender themselves see who
+// it really is. Voters on anonymous polls are hidden the same way. Every
+// message leaving the server for a member goes through forViewer or
+// emitMessage.
+export class PseudonymService {
+  private groupPseudonymRepository = new GroupPseudonymRepository();
+
+  // Whether messages from this sender go out under a pseudonym
+  appliesTo(chat: IChat, senderId: string): boolean {
+    if (chat.type !== 'group' || !chat.groupInfo?.settings?.pseudonymousMembers) return false;
+    return !this.isAdmin(chat, senderId);
+  }
+
+  // The member's pseudonym in the group, assigned on first use
+  async assign(chat: IChat, userId: string): Promise<IGroupPseudonym> {
+    const existing = await this.groupPseudonymRepository.findByMember(chat._id, userId);
+    if (existing) return existing;
+
+    for (let attempt = 0; attempt < MAX_ATTEMPTS; attempt++) {
+      // Names collide as the group grows; late attempts get a number
+      const name = attempt < MAX_ATTEMPTS - 1 ? this.generateName() : `${this.generateName()} ${Math.floor(1000 + Math.random() * 9000)}`;
+      try {
+        return await this.groupPseudonymRepository.create(chat._id, userId, name);
+      } catch (error: any) {
+        if (error?.code !== 11000) throw error;
+
+        // Assigned concurrently, by another message from the same member
+        const assigned = await this.groupPseudonymRepository.findByMember(chat._id, userId);
+        if (assigned) return assigned;
+      }
+    }
+
+    logger.warn('Could not assign a group pseudonym', { chatId: chat._id.toString(), userId });
+    throw new Error('Could not assign a pseudonym');
+  }
+
+  // Every pseudonym of the group with the member behind it, for its admins
+  async list(chatId: string): Promise<IGroupPseudonym[]> {
+    return await this.groupPseudonymRepository.listByChat(chatId);
+  }
+
+  // A message as the viewer may see it. Admins see it unchanged.
+  forViewer(chat: IChat, message: IMessage | Record<string, any>, viewerId?: string): Record<string, any> {
+    const object = typeof (message as IMessage).toObject === 'function' ? (message as IMessage).toObject() : { ...message };
+    if (viewerId && this.isAdmin(chat, viewerId)) return object;
+
+    if (object.pseudonym && idOf(object.senderId) !== viewerId) {
+      object.senderId = { _id: object.pseudonym.id, displayName: object.pseudonym.name };
+    }
+    if (object.replyTo?.pseudonym && idOf(object.replyTo.senderId) !== viewerId) {
+      object.replyTo = { ...object.replyTo, senderId: object.replyTo.pseudonym.id };
+    }
+    if (object.metadata?.poll?.anonymous) {
+      object.metadata = { ...object.metadata, poll: this.pollForViewer(chat, object.metadata.poll, viewerId) };
+    }
+    return object;
+  }
+
+  // A poll as the viewer may see it: anonymous ones only show counts and the
+  // viewer's own vote
+  pollForViewer(chat: IChat, poll: PollView, viewerId?: string) {
+    const open = !poll.anonymous || (!!viewerId && this.isAdmin(chat, viewerId));
+
+    return {
+      question: poll.question,
+      options: poll.options.map(option => ({
+        text: option.text,
+        votes: option.votes.map(vote => vote.toString()).filter(vote => open || vote === viewerId),
+        voteCount: option.votes.length,
+      })),
+      closed: poll.closed,
+      ...(poll.anonymous && { anonymous: true }),
+    };
+  }
+
+  // Send a message event to the chat room, masked for members who may not
+  // see the sender or the voters
+  async emitMessage(chat: IChat, event: 'message:new' | 'message:edited', message: IMessage | Record<string, any>): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const chatId = chat._id.toString();
+
+    if (!message.pseudonym && !message.metadata?.poll?.anonymous) {
+      socketManager.emitToChat(chatId, event, message);
+      return;
+    }
+
+    const insiders = this.insiders(chat, message);
+    for (const userId of insiders) {
+      socketManager.emitToUser(userId, event, this.forViewer(chat, message, userId));
+    }
+
+    await this.emitExcept(chatId, insiders, event, this.forViewer(chat, message));
+  }
+
+  // Send a poll's new tally, with voters only for those who may see them
+  async emitPollUpdate(chat: IChat, message: IMessage, voterId: string): Promise<void> {
+    const { socketManager } = await import('../realtime/socket');
+    const chatId = chat._id.toString();
+    const poll = message.metadata!.poll!;
+    const payload = (viewerId?: string) => ({
+      messageId: message._id.toString(),
+      chatId,
+      poll: this.pollForViewer(chat, poll, viewerId),
+    });
+
+    if (!poll.anonymous) {
+      socketManager.emitToChat(chatId, 'poll:updated', payload());
+      return;
+    }
+
+    const insiders = Array.from(new Set([voterId, ...this.adminIds(chat)]));
+    for (const userId of insiders) {
+      socketManager.emitToUser(userId, 'poll:updated', payload(userId));
+    }
+
+    await this.emitExcept(chatId, insiders, 'poll:updated', payload());
+  }
+
+  private async emitExcept(chatId: string, userIds: string[], event: 'message:new' | 'message:edited' | 'poll:updated', data: any) {
+    const { socketManager } = await import('../realtime/socket');
+    socketManager.emitToChat(chatId, event, data, userIds);
+  }
+
+  // Members who see the message unmasked: the group's admins and the sender
+  private insiders(chat: IChat, message: IMessage | Record<string, any>): string[] {
+    return Array.from(new Set([idOf(message.senderId)!, ...this.adminIds(chat)]));
+  }
+
+  private adminIds(chat: IChat): string[] {
+    return (chat.groupInfo?.admins || []).map(admin => idOf(admin)!);
+  }
+
+  // Group admins see who is behind each pseudonym
+  isAdmin(chat: IChat, userId: string): boolean {
+    return this.adminIds(chat).includes(userId);
+  }
+
+  private generateName(): string {
+    const adjective = ADJECTIVES[Math.floor(Math.random() * ADJECTIVES.length)];
+    const animal = ANIMALS[Math.floor(Math.random() * ANIMALS.length)];
+    return `${adjective} ${animal}`;
+  }
+}
+
+export const pseudonymService = new PseudonymService();
+
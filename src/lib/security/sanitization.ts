@@ -1,5 +1,6 @@
 import DOMPurify from 'isomorphic-dompurify';
 import validator from 'validator';
+import { PhoneUtils } from '../utils/phone';
 
 export class DataSanitizer {
   // Sanitize HTML content
@@ -37,8 +38,13 @@ export class DataSanitizer {
     return sanitized || 'unnamed_file';
   }
 
-  // Sanitize phone number
-  static sanitizePhoneNumber(phone: string): string {
+  // Sanitize phone number to E.164, resolving national numbers against defaultCountry
+  static sanitizePhoneNumber(phone: string, defaultCountry?: string): string {
+    const normalized = PhoneUtils.normalize(phone, defaultCountry);
+    if (normalized) {
+      return normalized;
+    }
+
     // Remove all non-digit characters except +
     let sanitized = phone.replace(/[^\d+]/g, '');
     
@@ -26,6 +26,18 @@ export const AUTH_CONSTANTS = {
   LOCKOUT_DURATION: 15 * 60 * 1000, // 15 minutes
   PASSWORD_MIN_LENGTH: 8,
   PASSWORD_MAX_LENGTH: 128,
+  PERSONAL_TOKEN_PREFIX: 'pat_',
+  PERSONAL_TOKEN_SCOPES: ['messages:read', 'messages:send'],
+  MAX_PERSONAL_TOKENS: 20,
+  PERSONAL_TOKEN_DEFAULT_DAYS: 90,
+  PERSONAL_TOKEN_MAX_DAYS: 365,
+  API_KEY_PREFIX: 'bsk_',
+  API_KEY_SCOPES: ['scim:users:read', 'scim:users:write', 'scim:groups:read', 'scim:groups:write'],
+  API_KEY_MAX_DAYS: 730,
+  ARCHIVE_TOKEN_PREFIX: 'bav_',
+  ARCHIVE_TOKEN_DEFAULT_HOURS: 72,
+  ARCHIVE_TOKEN_MAX_HOURS: 30 * 24,
+  SOCKET_EXPIRY_WARNING: 2 * 60 * 1000, // auth:expiring is sent this long before a socket's token expires
 } as const;
 
 // Message constants
@@ -45,6 +57,11 @@ export const MESSAGE_CONSTANTS = {
   ],
   DELETE_FOR_EVERYONE_TIME_LIMIT: 7 * 60 * 1000, // 7 minutes
   EDIT_TIME_LIMIT: 15 * 60 * 1000, // 15 minutes
+  MAX_SEQ_RANGE: 500, // messages per gap-fill request
+  MAX_AROUND_RADIUS: 50, // messages either side when opening a chat at a message or date
+  DEFAULT_AROUND_RADIUS: 25,
+  MIN_RETENTION_DAYS: 1,
+  MAX_RETENTION_DAYS: 3650,
 } as const;
 
 // Group constants
@@ -54,6 +71,19 @@ export const GROUP_CONSTANTS = {
   NAME_MAX_LENGTH: 50,
   DESCRIPTION_MAX_LENGTH: 200,
   INVITE_LINK_EXPIRES_IN: 24 * 60 * 60 * 1000, // 24 hours
+  MAX_CUSTOM_EMOJI: 200, // active custom emoji per group
+  CUSTOM_EMOJI_MAX_SIZE: 256 * 1024, // 256KB
+  CUSTOM_EMOJI_MIME_TYPES: ['image/png', 'image/gif', 'image/webp'],
+} as const;
+
+// Guest access constants
+export const GUEST_CONSTANTS = {
+  MAX_LINK_HOURS: 30 * 24, // longest a guest link stays open
+  DEFAULT_LINK_HOURS: 7 * 24,
+  MAX_GUEST_HOURS: 7 * 24, // longest a guest may stay in the chat
+  DEFAULT_GUEST_HOURS: 24,
+  MAX_ACTIVE_LINKS: 20, // per chat
+  MAX_USES: 500,
 } as const;
 
 // Call constants
@@ -63,6 +93,17 @@ export const CALL_CONSTANTS = {
   ICE_GATHERING_TIMEOUT: 10000, // 10 seconds
   TURN_CREDENTIALS_TTL: 24 * 60 * 60, // 24 hours in seconds
   QUALITY_CHECK_INTERVAL: 10000, // 10 seconds
+  RING_TIMEOUT: 45 * 1000, // call invite pushes expire after this
+  SFRAME_CIPHER_SUITES: ['AES_128_GCM_SHA256_128', 'AES_256_GCM_SHA512_128'], // RFC 9605, preferred first
+  LOW_DATA_MAX_AUDIO_BITRATE: 24, // kbps, for devices in low-data mode
+  LOW_DATA_MAX_VIDEO_BITRATE: 250, // kbps
+  MAX_VOICE_CHANNELS: 20, // per group
+  MAX_VOICE_CHANNEL_PARTICIPANTS: 16, // audio is peer-to-peer, so keep the mesh small
+  MAX_BROADCAST_EGRESS: 3, // RTMP destinations per broadcast
+  MAX_CHAT_MESSAGE_LENGTH: 1000, // in-call chat
+  MAX_CHAT_MESSAGES: 500, // kept per call, oldest dropped first
+  RATING_MIN_DURATION: 10, // seconds; shorter calls aren't worth rating
+  RATING_WINDOW: 24 * 60 * 60 * 1000, // after the call ends
 } as const;
 
 // Status constants
@@ -98,6 +139,13 @@ export const UPLOAD_CONSTANTS = {
   },
 } as const;
 
+// Organization branding constants
+export const BRANDING_CONSTANTS = {
+  ASSET_MAX_SIZE: 512 * 1024, // 512KB, logos and favicons
+  ASSET_MIME_TYPES: ['image/png', 'image/jpeg', 'image/webp', 'image/x-icon'],
+  MAX_DOMAINS: 10, // custom domains per organization
+} as const;
+
 // Email constants
 export const EMAIL_CONSTANTS = {
   FROM_ADDRESS: process.env.SMTP_FROM || 'noreply@chatapp.com',
@@ -118,6 +166,16 @@ export const PUSH_CONSTANTS = {
     GROUPS: 'group_updates',
     SYSTEM: 'system_notifications',
   },
+  // Bundled tones; clients ship these files under the same names
+  SOUNDS: ['default', 'message_tone', 'group_message', 'ringtone', 'chime', 'pop', 'bell', 'note', 'none'],
+  // Android vibration timings in ms (off, on, off, on, ...)
+  VIBRATION_PATTERNS: {
+    default: [0, 250, 250, 250],
+    short: [0, 150],
+    long: [0, 600, 200, 600],
+    heartbeat: [0, 100, 100, 100, 400, 100, 100, 100],
+    none: [],
+  },
 } as const;
 
 // Pagination constants
@@ -125,6 +183,7 @@ export const PAGINATION_CONSTANTS = {
   DEFAULT_PAGE_SIZE: 20,
   MAX_PAGE_SIZE: 100,
   DEFAULT_PAGE: 1,
+  MAX_EXPORT_ROWS: 100000, // CSV exports of admin lists
 } as const;
 
 // Cache constants
@@ -136,6 +195,83 @@ export const CACHE_CONSTANTS = {
   DEFAULT_TTL: 15 * 60, // 15 minutes
 } as const;
 
+// Acknowledged delivery of reliable socket events
+export const DELIVERY_CONSTANTS = {
+  ACK_TIMEOUT: 10 * 1000, // resend when not acknowledged within
+  MAX_ATTEMPTS: 3, // sends before the event goes to the device's offline queue
+  MAX_PENDING_PER_SOCKET: 500,
+  OFFLINE_QUEUE_LIMIT: 500, // per device; oldest events are dropped first
+  OFFLINE_QUEUE_TTL: 7 * 24 * 60 * 60, // seconds
+} as const;
+
+// Coalescing of typing and presence updates for slow connections, see
+// realtime/throttle; admins can override these under realtime settings
+export const THROTTLE_CONSTANTS = {
+  SLOW_RTT: 800, // ms
+  SLOW_BACKLOG: 64, // packets in the send buffer
+  TYPING_WINDOW: 2000, // ms
+  PRESENCE_WINDOW: 5000, // ms
+  MAX_WINDOW_FACTOR: 4, // windows grow with RTT up to this many times
+} as const;
+
+// Automatic responses to poor call quality, see webrtc/quality; admins can
+// override the thresholds under call settings
+export const CALL_QUALITY_CONSTANTS = {
+  DEGRADE_PACKET_LOSS: 0.05, // fraction of packets lost
+  DEGRADE_LATENCY: 300, // ms round trip
+  AUDIO_ONLY_PACKET_LOSS: 0.15,
+  AUDIO_ONLY_LATENCY: 800,
+  DEGRADE_AFTER_REPORTS: 2, // poor reports in a row before stepping down
+  RELAY_AFTER_REPORTS: 4,
+  RECOVER_AFTER_REPORTS: 3,
+  REDUCED_VIDEO_BITRATE: 300, // kbps
+  REDUCED_RESOLUTION_SCALE: 2, // scaleResolutionDownBy
+} as const;
+
+// Recent events kept per user for reconnecting clients, see realtime/replay
+export const REPLAY_CONSTANTS = {
+  WINDOW: 5 * 60 * 1000, // clients away longer resync over REST
+  MAX_EVENTS: 500, // per user; older events are trimmed first
+} as const;
+
+// Per-connection send buffers. Packets pile up when a client reads slower
+// than the hub writes; see realtime/backpressure.
+export const SEND_QUEUE_CONSTANTS = {
+  SOFT_LIMIT: 256, // buffered packets before presence and typing updates are dropped, oldest first
+  HARD_LIMIT: 1024, // buffered packets before the connection is dropped; messages are never discarded
+} as const;
+
+// Devices in low-data mode, see realtime/low-data
+export const LOW_DATA_CONSTANTS = {
+  COMPRESSION_THRESHOLD: 512, // bytes; smaller frames aren't worth deflating
+} as const;
+
+// Background work after a message is sent (bridge and email relays, automation rules)
+export const MESSAGE_TASK_CONSTANTS = {
+  MIN_WORKERS: 1,
+  MAX_WORKERS: 20,
+  TASKS_PER_WORKER: 10, // queued tasks before another worker starts
+  LANE_WEIGHTS: { high: 4, normal: 2, low: 1 }, // picks per round while every lane has work
+  MAX_QUEUE_DEPTH: 5000, // per lane; enqueue fails past it
+  TASK_TIMEOUT: 30 * 1000,
+  MAX_ATTEMPTS: 3,
+  RETRY_BASE_DELAY: 1000, // doubled after every failed attempt
+  DEAD_LETTER_LIMIT: 1000,
+} as const;
+
+// Admin automation rules, see integrations/automation
+export const AUTOMATION_CONSTANTS = {
+  MAX_RULES: 100,
+  MAX_CONDITIONS: 10, // per rule
+  MAX_ACTIONS: 5, // per rule
+  MAX_PATTERN_LENGTH: 200,
+  MAX_MATCH_LENGTH: 4000, // characters of a message a pattern is tested against
+  MAX_RUNS_PER_MINUTE: 60, // per rule; matches past it are counted as throttled
+  MAX_CONSECUTIVE_FAILURES: 20, // before the rule is turned off
+  WEBHOOK_TIMEOUT: 5000,
+  RULE_CACHE_TTL: 30 * 1000,
+} as const;
+
 // Error codes
 export const ERROR_CODES = {
   // Authentication errors
@@ -145,6 +281,8 @@ export const ERROR_CODES = {
   OTP_EXPIRED: 'OTP_EXPIRED',
   ACCOUNT_LOCKED: 'ACCOUNT_LOCKED',
   USER_BANNED: 'USER_BANNED',
+  LOGIN_APPROVAL_REQUIRED: 'LOGIN_APPROVAL_REQUIRED',
+  REGION_BLOCKED: 'REGION_BLOCKED',
   
   // Authorization errors
   INSUFFICIENT_PERMISSIONS: 'INSUFFICIENT_PERMISSIONS',
@@ -156,9 +294,21 @@ export const ERROR_CODES = {
   MISSING_REQUIRED_FIELD: 'MISSING_REQUIRED_FIELD',
   INVALID_FILE_TYPE: 'INVALID_FILE_TYPE',
   FILE_TOO_LARGE: 'FILE_TOO_LARGE',
+  PAYLOAD_TOO_LARGE: 'PAYLOAD_TOO_LARGE',
+  
+  // Concurrent edits
+  CONFLICT: 'CONFLICT',
   
   // Rate limiting
   RATE_LIMIT_EXCEEDED: 'RATE_LIMIT_EXCEEDED',
+  CHALLENGE_REQUIRED: 'CHALLENGE_REQUIRED',
+  CHALLENGE_FAILED: 'CHALLENGE_FAILED',
+  
+  // Clients
+  UPGRADE_REQUIRED: 'UPGRADE_REQUIRED',
+  
+  // Compliance
+  CONSENT_REQUIRED: 'CONSENT_REQUIRED',
   
   // Server errors
   INTERNAL_SERVER_ERROR: 'INTERNAL_SERVER_ERROR',
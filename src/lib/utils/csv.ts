@@ -0,0 +1,43 @@
+// Minimal CSV writing (RFC 4180) for admin exports
+
+export function csvField(value: unknown): string {
+  const text = value instanceof Date ? value.toISOString() : value == null ? '' : String(value);
+  return /[",\r\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+export function csvRow(values: unknown[]): string {
+  return `${values.map(csvField).join(',')}\r\n`;
+}
+
+// Whole document from rows of plain objects, in column order
+export function toCsv(columns: readonly string[], rows: Record<string, unknown>[]): string {
+  return csvRow(columns) + rows.map(row => csvRow(columns.map(column => row[column]))).join('');
+}
+
+// Streamed document from a database cursor, for exports too large to build
+// in memory
+export function csvStream<T>(
+  columns: readonly string[],
+  cursor: AsyncIterable<T> & { close(): Promise<unknown> },
+  toRecord: (item: T) => Record<string, unknown>
+): ReadableStream<Uint8Array> {
+  const encoder = new TextEncoder();
+
+  return new ReadableStream<Uint8Array>({
+    async start(controller) {
+      try {
+        controller.enqueue(encoder.encode(csvRow([...columns])));
+        for await (const item of cursor) {
+          const record = toRecord(item);
+          controller.enqueue(encoder.encode(csvRow(columns.map(column => record[column]))));
+        }
+        controller.close();
+      } catch (error) {
+        controller.error(error);
+      }
+    },
+    async cancel() {
+      await cursor.close();
+    },
+  });
+}
@@ -174,6 +174,71 @@ export class DateUtils {
     return new Date().getTimezoneOffset();
   }
 
+  // Check an IANA time zone name such as "Europe/Berlin"
+  static isValidTimeZone(timeZone: string): boolean {
+    try {
+      new Intl.DateTimeFormat('en-US', { timeZone });
+      return true;
+    } catch {
+      return false;
+    }
+  }
+
+  // Wall-clock fields of an instant in a time zone (month is 1-based,
+  // weekday 0 = Sunday)
+  static getZonedParts(date: Date, timeZone: string): {
+    year: number; month: number; day: number; hour: number; minute: number; second: number; weekday: number;
+  } {
+    const parts = new Intl.DateTimeFormat('en-US', {
+      timeZone,
+      year: 'numeric',
+      month: 'numeric',
+      day: 'numeric',
+      hour: 'numeric',
+      minute: 'numeric',
+      second: 'numeric',
+      weekday: 'short',
+      hourCycle: 'h23',
+    }).formatToParts(date);
+    const get = (type: string) => parts.find(part => part.type === type)?.value || '0';
+
+    return {
+      year: parseInt(get('year'), 10),
+      month: parseInt(get('month'), 10),
+      day: parseInt(get('day'), 10),
+      hour: parseInt(get('hour'), 10),
+      minute: parseInt(get('minute'), 10),
+      second: parseInt(get('second'), 10),
+      weekday: ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'].indexOf(get('weekday')),
+    };
+  }
+
+  // Offset of a time zone from UTC at an instant, in milliseconds
+  static getTimeZoneOffsetAt(date: Date, timeZone: string): number {
+    const p = this.getZonedParts(date, timeZone);
+    const asUtc = Date.UTC(p.year, p.month - 1, p.day, p.hour, p.minute, p.second);
+    return asUtc - Math.floor(date.getTime() / 1000) * 1000;
+  }
+
+  // Instant at which a wall-clock time occurs in a time zone. Day overflow
+  // (day 32, hour 24) rolls over like Date.UTC does.
+  static zonedTimeToUtc(
+    year: number,
+    month: number,
+    day: number,
+    hour: number,
+    minute: number,
+    timeZone: string
+  ): Date {
+    const wallClock = Date.UTC(year, month - 1, day, hour, minute);
+    const offset = this.getTimeZoneOffsetAt(new Date(wallClock), timeZone);
+    const candidate = wallClock - offset;
+
+    // Re-check in case a DST change lies between the guess and the answer
+    const correctedOffset = this.getTimeZoneOffsetAt(new Date(candidate), timeZone);
+    return new Date(correctedOffset === offset ? candidate : wallClock - correctedOffset);
+  }
+
   // Format for chat display (smart formatting)
   static formatForChat(date: Date | string): string {
     const d = typeof date === 'string' ? new Date(date) : date;
@@ -51,6 +51,32 @@ export function slugify(str: string): string {
     .replace(/^-+|-+$/g, '');
 }
 
+export function escapeRegex(str: string): string {
+  return str.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
+// An excerpt of text around the first match of any term, with the offsets
+// of every match within the excerpt so clients can highlight them
+export function highlightSnippet(
+  text: string,
+  terms: string[],
+  radius = 60
+): { snippet: string; highlights: { start: number; end: number }[] } {
+  const pattern = new RegExp(terms.map(escapeRegex).join('|'), 'gi');
+  const first = text.search(pattern);
+  const start = first > radius ? text.lastIndexOf(' ', first - radius) + 1 || first - radius : 0;
+  const end = Math.min(text.length, Math.max(first, 0) + radius * 2);
+
+  const prefix = start > 0 ? '...' : '';
+  const snippet = prefix + text.slice(start, end) + (end < text.length ? '...' : '');
+
+  const highlights: { start: number; end: number }[] = [];
+  for (const match of text.slice(start, end).matchAll(pattern)) {
+    highlights.push({ start: prefix.length + match.index!, end: prefix.length + match.index! + match[0].length });
+  }
+  return { snippet, highlights };
+}
+
 export function generateRandomString(length: number): string {
   const charset = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789';
   let result = '';
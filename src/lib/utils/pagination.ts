@@ -17,6 +17,13 @@ export interface PaginationResult<T> {
   };
 }
 
+// Page of a filtered list, as repositories take it
+export interface ListOptions {
+  sort: Record<string, 1 | -1>;
+  limit: number;
+  offset: number;
+}
+
 export class PaginationUtils {
   // Calculate skip value for database queries
   static getSkip(page: number, limit: number): number {
@@ -60,6 +67,17 @@ export class PaginationUtils {
     return { [sortBy]: sortOrder === 'asc' ? 1 : -1 };
   }
 
+  // Repository options for a page, with _id breaking ties so that pages
+  // neither repeat nor skip documents sharing a sort value
+  static toListOptions(options: Required<PaginationOptions>): ListOptions {
+    const direction = options.sortOrder === 'asc' ? 1 : -1;
+    return {
+      sort: { [options.sortBy]: direction, _id: direction },
+      limit: options.limit,
+      offset: this.getSkip(options.page, options.limit),
+    };
+  }
+
   // Calculate pagination metadata only
   static calculatePaginationMeta(totalCount: number, page: number, limit: number) {
     const totalPages = Math.ceil(totalCount / limit);
@@ -0,0 +1,46 @@
+import { parsePhoneNumberFromString, CountryCode } from 'libphonenumber-js';
+
+export interface NormalizedPhoneNumber {
+  e164: string;
+  countryCode?: string; // ISO 3166-1 alpha-2 region, when known
+  callingCode: string;
+  nationalNumber: string;
+}
+
+export class PhoneUtils {
+  // Parse a user-entered number into its canonical parts. National-format
+  // numbers need the caller's country code to be resolved.
+  static parse(input: string, defaultCountry?: string): NormalizedPhoneNumber | null {
+    if (!input) return null;
+
+    const phone = parsePhoneNumberFromString(
+      input.trim(),
+      defaultCountry ? (defaultCountry.toUpperCase() as CountryCode) : undefined
+    );
+    if (!phone || !phone.isValid()) {
+      return null;
+    }
+
+    return {
+      e164: phone.number,
+      countryCode: phone.country,
+      callingCode: phone.countryCallingCode,
+      nationalNumber: phone.nationalNumber,
+    };
+  }
+
+  // Get the E.164 form (+14155552671) or null if the number is invalid
+  static normalize(input: string, defaultCountry?: string): string | null {
+    return this.parse(input, defaultCountry)?.e164 ?? null;
+  }
+
+  // Check if a number is valid for its region
+  static isValid(input: string, defaultCountry?: string): boolean {
+    return this.parse(input, defaultCountry) !== null;
+  }
+
+  // Mask all but the last four digits for logs
+  static mask(e164: string): string {
+    return e164.length > 4 ? e164.slice(0, -4).replace(/\d/g, '*') + e164.slice(-4) : e164;
+  }
+}
@@ -0,0 +1,95 @@
+import { Types } from 'mongoose';
+import { ChatRepository } from '../database/repositories/chat';
+import { IChat } from '../database/models/chat';
+import { environmentConfig } from '../config/environment';
+import { socketManager } from '../realtime/socket';
+import { logger } from '../monitoring/logging';
+
+// Keeps the "call in progress" marker on group chats so members who aren't
+// in the call see it and can join. The marker lives on the chat document,
+// so chat responses carry it, and every change is broadcast to the chat.
+// Failures are logged and never break the call itself.
+export class ActiveCallService {
+  private chatRepository = new ChatRepository();
+
+  // A call started in a chat. Only group chats get a marker.
+  async started(call: { callId: string; chatId?: string; type: 'voice' | 'video'; initiatorId: string }): Promise<void> {
+    if (!call.chatId || !Types.ObjectId.isValid(call.chatId)) return;
+
+    try {
+      const chat = await this.chatRepository.findById(call.chatId);
+      if (!chat || chat.type !== 'group') return;
+
+      const updated = await this.chatRepository.setActiveCall(chat._id, {
+        callId: call.callId,
+        callType: call.type,
+        startedBy: new Types.ObjectId(call.initiatorId),
+        startedAt: new Date(),
+        participants: [new Types.ObjectId(call.initiatorId)],
+      });
+      this.broadcast(updated);
+    } catch (error) {
+      logger.error('Failed to mark active call', error, { callId: call.callId });
+    }
+  }
+
+  // Someone answered or joined
+  async joined(callId: string, userId: string): Promise<void> {
+    await this.updateParticipants(callId, userId, true);
+  }
+
+  // Someone dropped out or was removed
+  async left(callId: string, userId: string): Promise<void> {
+    await this.updateParticipants(callId, userId, false);
+  }
+
+  // The call is over: clear the marker
+  async ended(callId: string): Promise<void> {
+    try {
+      const chat = await this.chatRepository.clearActiveCall(callId);
+      this.broadcast(chat);
+    } catch (error) {
+      logger.error('Failed to clear active call', error, { callId });
+    }
+  }
+
+  private async updateParticipants(callId: string, userId: string, inCall: boolean): Promise<void> {
+    try {
+      const chat = await this.chatRepository.updateActiveCallParticipants(callId, userId, inCall);
+      this.broadcast(chat);
+    } catch (error) {
+      logger.error('Failed to update active call', error, { callId });
+    }
+  }
+
+  private broadcast(chat: IChat | null): void {
+    if (!chat) return;
+
+    // Every member, not just the chat room, so chat lists can show it too
+    const payload = { chatId: chat._id.toString(), activeCall: serializeActiveCall(chat) };
+    chat.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId.toString(), 'chat:active-call', payload);
+    });
+  }
+}
+
+// Client-facing view of a chat's running call, null when there is none
+export function serializeActiveCall(chat: Pick<IChat, 'activeCall'>) {
+  const activeCall = chat.activeCall;
+  if (!activeCall?.callId) {
+    return null;
+  }
+
+  return {
+    callId: activeCall.callId,
+    type: activeCall.callType,
+    startedBy: activeCall.startedBy.toString(),
+    startedAt: activeCall.startedAt.toISOString(),
+    participants: activeCall.participants.map(id => id.toString()),
+    participantCount: activeCall.participants.length,
+    // POST { callId } here to join
+    joinUrl: `${environmentConfig.getValue('API_URL')}/client/calls/group-call/join`,
+  };
+}
+
+export const activeCallService = new ActiveCallService();
@@ -0,0 +1,394 @@
+import { randomBytes } from 'crypto';
+import { Types } from 'mongoose';
+import { BroadcastRepository } from '../database/repositories/broadcast';
+import { CallRepository } from '../database/repositories/call';
+import { ChatRepository } from '../database/repositories/chat';
+import { IBroadcast } from '../database/models/broadcast';
+import { StartBroadcastInput } from '../database/schemas/call';
+import { environmentConfig } from '../config/environment';
+import { socketManager } from '../realtime/socket';
+import { emitEvent } from '../realtime/protocol';
+import { webrtcSignalingService, CallControlError } from './signaling';
+import { callEventLog } from './call-events';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const RECONCILE_INTERVAL = 60 * 1000;
+const VIEWER_COUNT_DELAY = 2000; // joins and leaves within this are counted together
+const REQUEST_TIMEOUT = 10 * 1000;
+
+export interface BroadcastEgressTarget {
+  url: string;
+  streamKey: string;
+}
+
+export interface MediaServerStream {
+  streamId: string;
+  ingestUrl: string; // WHIP
+  playbackUrl: string; // WHEP or HLS
+  egress: { url: string; ok: boolean; error?: string }[];
+}
+
+export interface BroadcastMediaServer {
+  createStream(name: string, egress: BroadcastEgressTarget[]): Promise<MediaServerStream>;
+  deleteStream(streamId: string): Promise<void>;
+}
+
+// Media server with a small REST API for streams: POST /streams creates
+// one with its RTMP egress, DELETE /streams/:id stops it
+export class HttpMediaServer implements BroadcastMediaServer {
+  constructor(private url: string, private apiKey?: string) {}
+
+  async createStream(name: string, egress: BroadcastEgressTarget[]): Promise<MediaServerStream> {
+    const response = await fetch(`${this.url.replace(/\/$/, '')}/streams`, {
+      method: 'POST',
+      headers: this.headers(),
+      body: JSON.stringify({ name, egress }),
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok) {
+      throw new Error(`Media server stream request failed with status ${response.status}`);
+    }
+
+    const result = await response.json();
+    return {
+      streamId: String(result.id),
+      ingestUrl: result.ingestUrl,
+      playbackUrl: result.playbackUrl,
+      egress: (result.egress || []).map((target: any) => ({
+        url: String(target.url),
+        ok: target.status !== 'failed',
+        error: target.error,
+      })),
+    };
+  }
+
+  async deleteStream(streamId: string): Promise<void> {
+    const response = await fetch(`${this.url.replace(/\/$/, '')}/streams/${encodeURIComponent(streamId)}`, {
+      method: 'DELETE',
+      headers: this.headers(),
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok && response.status !== 404) {
+      throw new Error(`Media server stream delete failed with status ${response.status}`);
+    }
+  }
+
+  private headers(): Record<string, string> {
+    return {
+      'Content-Type': 'application/json',
+      ...(this.apiKey && { Authorization: `Bearer ${this.apiKey}` }),
+    };
+  }
+}
+
+export function broadcastRoom(broadcastId: string): string {
+  return `broadcast:${broadcastId}`;
+}
+
+// Live broadcasts of calls. The host publishes the call, composited on
+// their client, to a media server over WHIP; viewers play it back from the
+// media server and never send anything, so the audience can be far larger
+// than a call. The media server also pushes the stream to external
+// platforms over RTMP. Viewers watch through a socket room, which is what
+// viewer counts are taken from. A broadcast ends with its call.
+export class LiveBroadcastService {
+  private broadcastRepository = new BroadcastRepository();
+  private callRepository = new CallRepository();
+  private chatRepository = new ChatRepository();
+  private mediaServer: BroadcastMediaServer | null = null;
+  private viewerCountTimers = new Map<string, NodeJS.Timeout>();
+  private timer: NodeJS.Timeout | null = null;
+
+  isEnabled(): boolean {
+    return environmentConfig.getValue('BROADCAST_ENABLED') && !!environmentConfig.getValue('BROADCAST_MEDIA_SERVER_URL');
+  }
+
+  // End broadcasts whose call ended while no server was around to see it
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.reconcile(), RECONCILE_INTERVAL);
+    this.timer.unref();
+    this.reconcile();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // Start broadcasting a call; the host only, one broadcast per call
+  async startBroadcast(callId: string, hostId: string, input: StartBroadcastInput): Promise<IBroadcast> {
+    if (!this.isEnabled()) {
+      throw new CallControlError('Live broadcasts are not available', 503);
+    }
+
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || session.status === 'ended') {
+      throw new CallControlError('Call not found', 404);
+    }
+    if (webrtcSignalingService.getHostId(session) !== hostId) {
+      throw new CallControlError('Only the call host can do this', 403);
+    }
+    if (await this.broadcastRepository.findLiveByCallId(callId)) {
+      throw new CallControlError('Call is already being broadcast', 409);
+    }
+
+    const call = await this.callRepository.findByCallId(callId);
+    if (input.audience === 'chat' && !call?.chatId) {
+      throw new CallControlError('Only calls in a chat can be broadcast to the chat', 400);
+    }
+
+    const stream = await this.getMediaServer().createStream(`call-${callId}`, input.rtmpEgress);
+
+    let broadcast: IBroadcast;
+    try {
+      broadcast = await this.broadcastRepository.create({
+        callId,
+        chatId: call?.chatId ? new Types.ObjectId(call.chatId.toString()) : undefined,
+        hostId: new Types.ObjectId(hostId),
+        title: input.title,
+        audience: input.audience,
+        watchCode: randomBytes(12).toString('base64url'),
+        streamId: stream.streamId,
+        ingestUrl: stream.ingestUrl,
+        playbackUrl: stream.playbackUrl,
+        // Stream keys stay with the media server
+        egress: input.rtmpEgress.map((target, index) => ({
+          url: target.url,
+          status: stream.egress[index]?.ok === false ? 'failed' : 'active',
+          error: stream.egress[index]?.error,
+        })),
+      });
+    } catch (error) {
+      // Don't leave the stream running. A duplicate means another start
+      // for the same call got there first.
+      await this.getMediaServer().deleteStream(stream.streamId).catch(() => undefined);
+      if ((error as any)?.code === 11000) {
+        throw new CallControlError('Call is already being broadcast', 409);
+      }
+      throw error;
+    }
+
+    callEventLog.record(callId, 'broadcast_started', {
+      actorId: hostId,
+      data: { broadcastId: broadcast._id.toString(), audience: input.audience, egress: input.rtmpEgress.length },
+    });
+    metricsCollector.incrementCounter('broadcasts_started', 1, { audience: input.audience });
+    logger.info('Broadcast started', { callId, broadcastId: broadcast._id.toString(), hostId });
+
+    await this.announce(broadcast, session.participants);
+    return broadcast;
+  }
+
+  // Stop broadcasting; the host only
+  async stopBroadcast(callId: string, userId: string): Promise<IBroadcast> {
+    const session = await webrtcSignalingService.loadSession(callId);
+    const broadcast = await this.broadcastRepository.findLiveByCallId(callId);
+    if (!broadcast) {
+      throw new CallControlError('Call is not being broadcast', 404);
+    }
+    const hostId = session ? webrtcSignalingService.getHostId(session) : broadcast.hostId.toString();
+    if (hostId !== userId) {
+      throw new CallControlError('Only the call host can do this', 403);
+    }
+
+    const ended = await this.end(broadcast, userId);
+    if (!ended) {
+      throw new CallControlError('Call is not being broadcast', 404);
+    }
+    return ended;
+  }
+
+  // The call ended, so does its broadcast
+  async callEnded(callId: string): Promise<void> {
+    try {
+      const broadcast = await this.broadcastRepository.findLiveByCallId(callId);
+      if (broadcast) {
+        await this.end(broadcast);
+      }
+    } catch (error) {
+      logger.error('Failed to end broadcast with its call', error, { callId });
+    }
+  }
+
+  // The live broadcast of a call, for its participants
+  async getForCall(callId: string, userId: string): Promise<IBroadcast | null> {
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || !session.participants.includes(userId)) {
+      throw new CallControlError('Call not found', 404);
+    }
+    return await this.broadcastRepository.findLiveByCallId(callId);
+  }
+
+  // A broadcast someone opened the watch link of. Chat broadcasts are only
+  // shown to the chat's members.
+  async resolve(watchCode: string, userId: string): Promise<IBroadcast> {
+    const broadcast = await this.broadcastRepository.findByWatchCode(watchCode);
+    if (!broadcast) {
+      throw new CallControlError('Broadcast not found', 404);
+    }
+    if (broadcast.audience === 'chat' && !(broadcast.chatId && await this.chatRepository.isParticipant(broadcast.chatId, userId))) {
+      throw new CallControlError('Broadcast not found', 404);
+    }
+    return broadcast;
+  }
+
+  // A viewer started or stopped watching. Call participants hear about each
+  // one; the count follows shortly after for everyone.
+  async viewerJoined(broadcast: IBroadcast, viewer: { userId: string; displayName: string; avatar?: string }): Promise<void> {
+    await this.broadcastRepository.incrementTotalViewers(broadcast._id);
+    await this.emitToParticipants(broadcast.callId, 'broadcast:viewer-joined', {
+      broadcastId: broadcast._id.toString(),
+      callId: broadcast.callId,
+      viewer,
+    });
+    this.scheduleViewerCount(broadcast._id.toString(), broadcast.callId);
+  }
+
+  async viewerLeft(broadcastId: string, callId: string, userId: string): Promise<void> {
+    await this.emitToParticipants(callId, 'broadcast:viewer-left', { broadcastId, callId, userId });
+    this.scheduleViewerCount(broadcastId, callId);
+  }
+
+  getWatchUrl(broadcast: IBroadcast): string {
+    return `${environmentConfig.getValue('FRONTEND_URL')}/watch/${broadcast.watchCode}`;
+  }
+
+  private async end(broadcast: IBroadcast, endedBy?: string): Promise<IBroadcast | null> {
+    const ended = await this.broadcastRepository.end(broadcast._id, endedBy);
+    if (!ended) {
+      return null;
+    }
+
+    try {
+      await this.getMediaServer().deleteStream(ended.streamId);
+    } catch (error) {
+      logger.error('Failed to stop broadcast stream', error, { broadcastId: ended._id.toString() });
+    }
+
+    const broadcastId = ended._id.toString();
+    const payload = { broadcastId, callId: ended.callId };
+    const io = socketManager.getIO();
+    if (io) {
+      emitEvent(io.to(broadcastRoom(broadcastId)), 'broadcast:ended', payload);
+      io.in(broadcastRoom(broadcastId)).socketsLeave(broadcastRoom(broadcastId));
+    }
+    await this.emitToParticipants(ended.callId, 'broadcast:ended', payload);
+
+    clearTimeout(this.viewerCountTimers.get(broadcastId));
+    this.viewerCountTimers.delete(broadcastId);
+
+    callEventLog.record(ended.callId, 'broadcast_ended', {
+      actorId: endedBy,
+      data: { broadcastId, peakViewers: ended.peakViewers, totalViewers: ended.totalViewers },
+    });
+    logger.info('Broadcast ended', { callId: ended.callId, broadcastId, peakViewers: ended.peakViewers });
+    return ended;
+  }
+
+  // Tell the call's participants, and for chat broadcasts the chat's
+  // members, that it is live
+  private async announce(broadcast: IBroadcast, participants: string[]): Promise<void> {
+    const recipients = new Set(participants);
+    if (broadcast.audience === 'chat' && broadcast.chatId) {
+      (await this.chatRepository.getParticipantIds(broadcast.chatId)).forEach(id => recipients.add(id));
+    }
+
+    recipients.forEach(userId => {
+      socketManager.emitToUser(userId, 'broadcast:started', { broadcast: serializeBroadcast(broadcast) });
+    });
+  }
+
+  private scheduleViewerCount(broadcastId: string, callId: string): void {
+    if (this.viewerCountTimers.has(broadcastId)) return;
+
+    const timer = setTimeout(async () => {
+      this.viewerCountTimers.delete(broadcastId);
+      try {
+        // Viewers on every replica, each counted once however many devices
+        const viewerCount = (await socketManager.getRoomUserIds(broadcastRoom(broadcastId))).size;
+        await this.broadcastRepository.recordViewers(broadcastId, viewerCount);
+
+        const payload = { broadcastId, callId, viewerCount };
+        const io = socketManager.getIO();
+        if (io) {
+          emitEvent(io.to(broadcastRoom(broadcastId)), 'broadcast:viewers', payload);
+        }
+        await this.emitToParticipants(callId, 'broadcast:viewers', payload);
+      } catch (error) {
+        logger.error('Failed to count broadcast viewers', error, { broadcastId });
+      }
+    }, VIEWER_COUNT_DELAY);
+    timer.unref();
+    this.viewerCountTimers.set(broadcastId, timer);
+  }
+
+  private async emitToParticipants(
+    callId: string,
+    event: 'broadcast:ended' | 'broadcast:viewers' | 'broadcast:viewer-joined' | 'broadcast:viewer-left',
+    payload: unknown
+  ): Promise<void> {
+    const session = await webrtcSignalingService.loadSession(callId);
+    session?.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, event, payload);
+    });
+  }
+
+  private async reconcile(): Promise<void> {
+    try {
+      for (const broadcast of await this.broadcastRepository.findLive()) {
+        const call = await this.callRepository.findByCallId(broadcast.callId);
+        if (!call || !['initiated', 'ringing', 'answered'].includes(call.status)) {
+          await this.end(broadcast);
+        }
+      }
+    } catch (error) {
+      logger.error('Broadcast reconcile error', error);
+    }
+  }
+
+  private getMediaServer(): BroadcastMediaServer {
+    if (!this.mediaServer) {
+      this.mediaServer = new HttpMediaServer(
+        environmentConfig.getValue('BROADCAST_MEDIA_SERVER_URL')!,
+        environmentConfig.getValue('BROADCAST_MEDIA_SERVER_API_KEY')
+      );
+    }
+    return this.mediaServer;
+  }
+}
+
+// What viewers and participants see of a broadcast
+export function serializeBroadcast(broadcast: IBroadcast) {
+  return {
+    id: broadcast._id.toString(),
+    callId: broadcast.callId,
+    chatId: broadcast.chatId?.toString(),
+    hostId: broadcast.hostId.toString(),
+    title: broadcast.title,
+    audience: broadcast.audience,
+    status: broadcast.status,
+    watchUrl: liveBroadcastService.getWatchUrl(broadcast),
+    playbackUrl: broadcast.playbackUrl,
+    viewerCount: broadcast.viewerCount,
+    startedAt: broadcast.startedAt.toISOString(),
+    endedAt: broadcast.endedAt?.toISOString(),
+  };
+}
+
+// The host's view, with where to publish and how the egress is doing
+export function serializeBroadcastForHost(broadcast: IBroadcast) {
+  return {
+    ...serializeBroadcast(broadcast),
+    ingestUrl: broadcast.ingestUrl,
+    egress: broadcast.egress.map(target => ({ url: target.url, status: target.status, error: target.error })),
+    peakViewers: broadcast.peakViewers,
+    totalViewers: broadcast.totalViewers,
+  };
+}
+
+export const liveBroadcastService = new LiveBroadcastService();
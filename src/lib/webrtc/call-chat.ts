@@ -0,0 +1,270 @@
+import { randomUUID } from 'crypto';
+import { Types } from 'mongoose';
+import { redisConfig } from '../config/redis';
+import { CallRepository } from '../database/repositories/call';
+import { ChatRepository } from '../database/repositories/chat';
+import { MessageRepository } from '../database/repositories/message';
+import { UserRepository } from '../database/repositories/user';
+import { CallChatReactionInput } from '../database/schemas/call';
+import { socketManager } from '../realtime/socket';
+import { webrtcSignalingService, CallControlError } from './signaling';
+import { t } from '../i18n';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const MAX_SUMMARY_LINES = 100;
+const MAX_SUMMARY_LENGTH = 4096; // a chat message's limit
+
+export interface CallChatMessage {
+  messageId: string;
+  callId: string;
+  senderId: string;
+  text: string;
+  sentAt: string;
+}
+
+// In-call chat: text messages and emoji reactions between the participants
+// of a call, sent over the socket and kept apart from the chat the call
+// was made in. Messages live in Redis only while the call does (and never
+// longer than a call can last); when it ends they are deleted, after being
+// posted to the chat as one summary message if the host asked for that.
+// Without Redis messages are still relayed, but there is no history or
+// summary.
+export class CallChatService {
+  private redis = redisConfig.getClient();
+  private callRepository = new CallRepository();
+  private chatRepository = new ChatRepository();
+  private messageRepository = new MessageRepository();
+  private userRepository = new UserRepository();
+
+  // Send a message to everyone in the call
+  async send(callId: string, senderId: string, text: string, clientId?: string): Promise<CallChatMessage> {
+    const session = await this.requireParticipant(callId, senderId);
+
+    const message: CallChatMessage = {
+      messageId: randomUUID(),
+      callId,
+      senderId,
+      text,
+      sentAt: new Date().toISOString(),
+    };
+
+    if (this.redis) {
+      const ids = this.key(callId, 'ids');
+      const messages = this.key(callId, 'messages');
+      const results = await this.redis.multi()
+        .rpush(ids, message.messageId)
+        .hset(messages, message.messageId, JSON.stringify(message))
+        .pexpire(ids, CALL_CONSTANTS.MAX_DURATION)
+        .pexpire(messages, CALL_CONSTANTS.MAX_DURATION)
+        .exec();
+
+      // Past the cap the oldest message goes
+      const length = Number(results?.[0]?.[1]) || 0;
+      if (length > CALL_CONSTANTS.MAX_CHAT_MESSAGES) {
+        const oldest = await this.redis.lpop(ids);
+        if (oldest) await this.redis.hdel(messages, oldest);
+      }
+    }
+
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, 'call:chat:message', {
+        ...message,
+        ...(participantId === senderId && clientId && { clientId }),
+      });
+    });
+    metricsCollector.incrementCounter('call_chat_messages', 1);
+
+    return message;
+  }
+
+  // React to a message, or take a reaction back. Without a messageId the
+  // reaction is to the call itself: shown briefly and not kept.
+  async react(callId: string, userId: string, reaction: CallChatReactionInput): Promise<void> {
+    const session = await this.requireParticipant(callId, userId);
+    const { messageId, emoji, removed } = reaction;
+
+    if (messageId && this.redis) {
+      if (!(await this.redis.hexists(this.key(callId, 'messages'), messageId))) {
+        throw new CallControlError('Message not found', 404);
+      }
+
+      const reactions = this.key(callId, 'reactions');
+      const field = JSON.stringify([messageId, emoji, userId]);
+      if (removed) {
+        await this.redis.hdel(reactions, field);
+      } else {
+        await this.redis.multi()
+          .hset(reactions, field, '1')
+          .pexpire(reactions, CALL_CONSTANTS.MAX_DURATION)
+          .exec();
+      }
+    }
+
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, 'call:chat:reaction', {
+        callId,
+        ...(messageId && { messageId }),
+        userId,
+        emoji,
+        removed,
+      });
+    });
+  }
+
+  // Messages so far, oldest first, with their reactions; for participants
+  // who joined late or reconnected
+  async getHistory(callId: string, userId: string) {
+    await this.requireParticipant(callId, userId);
+    if (!this.redis) {
+      return { available: false, postSummary: false, messages: [] };
+    }
+
+    const [messages, reactions, summaryBy] = await Promise.all([
+      this.getMessages(callId),
+      this.redis.hkeys(this.key(callId, 'reactions')),
+      this.redis.get(this.key(callId, 'summary')),
+    ]);
+
+    const byMessage = new Map<string, Record<string, string[]>>();
+    for (const field of reactions) {
+      const [messageId, emoji, reactorId] = JSON.parse(field) as string[];
+      const entry = byMessage.get(messageId) || {};
+      (entry[emoji] = entry[emoji] || []).push(reactorId);
+      byMessage.set(messageId, entry);
+    }
+
+    return {
+      available: true,
+      postSummary: !!summaryBy,
+      messages: messages.map(message => ({ ...message, reactions: byMessage.get(message.messageId) || {} })),
+    };
+  }
+
+  // Have the chat posted to the call's chat when the call ends, or not.
+  // Host only; the summary is posted in the name of whoever turned it on.
+  async setPostSummary(callId: string, hostId: string, postSummary: boolean): Promise<void> {
+    const session = await this.requireParticipant(callId, hostId);
+    if (webrtcSignalingService.getHostId(session) !== hostId) {
+      throw new CallControlError('Only the call host can do this', 403);
+    }
+    if (!this.redis) {
+      throw new CallControlError('Call chat summaries are not available', 503);
+    }
+
+    const call = await this.callRepository.findByCallId(callId);
+    if (postSummary && !call?.chatId) {
+      throw new CallControlError('This call has no chat to post a summary to', 400);
+    }
+
+    const key = this.key(callId, 'summary');
+    if (postSummary) {
+      await this.redis.set(key, hostId, 'PX', CALL_CONSTANTS.MAX_DURATION);
+    } else {
+      await this.redis.del(key);
+    }
+
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, 'call:chat:settings', { callId, postSummary, changedBy: hostId });
+    });
+  }
+
+  // The call is over: post the summary if one was asked for and drop the
+  // messages. Safe to call more than once.
+  async callEnded(callId: string): Promise<void> {
+    if (!this.redis) return;
+
+    try {
+      const [messages, summaryBy] = await Promise.all([
+        this.getMessages(callId),
+        this.redis.get(this.key(callId, 'summary')),
+      ]);
+      await this.redis.del(
+        this.key(callId, 'ids'),
+        this.key(callId, 'messages'),
+        this.key(callId, 'reactions'),
+        this.key(callId, 'summary')
+      );
+
+      if (summaryBy && messages.length > 0) {
+        await this.postSummary(callId, summaryBy, messages);
+      }
+    } catch (error) {
+      logger.error('Failed to wrap up call chat', error, { callId });
+    }
+  }
+
+  private async postSummary(callId: string, authorId: string, messages: CallChatMessage[]): Promise<void> {
+    const call = await this.callRepository.findByCallId(callId);
+    if (!call?.chatId) return;
+
+    // They may have left the chat during the call
+    const chatId = call.chatId.toString();
+    if (!(await this.chatRepository.isParticipant(chatId, authorId))) {
+      return;
+    }
+
+    const author = await this.userRepository.findById(authorId);
+    const senderIds = Array.from(new Set(messages.map(message => message.senderId)));
+    const names = new Map(
+      (await Promise.all(senderIds.map(id => this.userRepository.findById(id))))
+        .filter(user => !!user)
+        .map(user => [user!._id.toString(), user!.displayName])
+    );
+
+    const lines = messages.slice(0, MAX_SUMMARY_LINES)
+      .map(message => `${names.get(message.senderId) || '?'}: ${message.text}`);
+    if (messages.length > MAX_SUMMARY_LINES) {
+      lines.push(t(author?.language, 'call.chatSummary.more', { count: messages.length - MAX_SUMMARY_LINES }));
+    }
+
+    let content = [t(author?.language, 'call.chatSummary'), '', ...lines].join('\n');
+    if (content.length > MAX_SUMMARY_LENGTH) {
+      content = `${content.slice(0, MAX_SUMMARY_LENGTH - 1)}…`;
+    }
+
+    const message = await this.messageRepository.create({
+      chatId: call.chatId,
+      senderId: new Types.ObjectId(authorId),
+      content,
+      type: 'text',
+      metadata: {
+        callChat: { callId, messageCount: messages.length },
+      },
+    });
+
+    await this.chatRepository.updateLastActivity(chatId, message._id);
+
+    const populatedMessage = await this.messageRepository.findById(message._id);
+    socketManager.emitToChat(chatId, 'message:new', populatedMessage);
+    metricsCollector.incrementCounter('call_chat_summaries', 1);
+  }
+
+  private async getMessages(callId: string): Promise<CallChatMessage[]> {
+    const ids = await this.redis!.lrange(this.key(callId, 'ids'), 0, -1);
+    if (ids.length === 0) return [];
+
+    const values = await this.redis!.hmget(this.key(callId, 'messages'), ...ids);
+    return values
+      .filter((value): value is string => !!value)
+      .map(value => JSON.parse(value) as CallChatMessage);
+  }
+
+  private async requireParticipant(callId: string, userId: string) {
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || session.status === 'ended') {
+      throw new CallControlError('Call not found', 404);
+    }
+    if (!session.participants.includes(userId)) {
+      throw new CallControlError('Not a participant in this call', 403);
+    }
+    return session;
+  }
+
+  private key(callId: string, part: 'ids' | 'messages' | 'reactions' | 'summary'): string {
+    return `call:chat:${callId}:${part}`;
+  }
+}
+
+export const callChatService = new CallChatService();
@@ -0,0 +1,119 @@
+import { Types } from 'mongoose';
+import { CallEventRepository } from '../database/repositories/call-event';
+import { CallEventType, ICallEvent } from '../database/models/call-event';
+import { logger } from '../monitoring/logging';
+
+export interface CallEventFields {
+  userId?: string;
+  actorId?: string;
+  data?: Record<string, unknown>;
+}
+
+export interface ReplayedParticipantState {
+  muted: boolean;
+  mutedByHost: boolean;
+  onHold: boolean;
+  handRaised: boolean;
+  canSpeak: boolean;
+}
+
+// Session state that lives only in memory and isn't on the call record
+export interface ReplayedCallState {
+  participantStates: Map<string, ReplayedParticipantState>;
+  speakerQueue: { userId: string; raisedAt: Date }[];
+  keyEpoch: number;
+  events: number;
+}
+
+// Persistent log of what happened in each call: rings, answers, joins and
+// leaves, mute and hold changes, host controls, key rotations, quality
+// alerts and what was done about them, and the end. Admins read it as a timeline; the signaling service
+// replays it to rebuild a live session after a restart.
+export class CallEventLog {
+  private callEventRepository = new CallEventRepository();
+
+  // Append an event without holding up the call flow
+  record(callId: string, type: CallEventType, fields: CallEventFields = {}): void {
+    // The system ends calls too; only users are referenced
+    const toId = (id?: string) => id && Types.ObjectId.isValid(id) ? new Types.ObjectId(id) : undefined;
+
+    this.callEventRepository.create({
+      callId,
+      type,
+      userId: toId(fields.userId),
+      actorId: fields.actorId !== fields.userId ? toId(fields.actorId) : undefined,
+      data: fields.data,
+    }).catch(error => {
+      logger.error('Failed to record call event', error, { callId, type });
+    });
+  }
+
+  async getTimeline(callId: string): Promise<ICallEvent[]> {
+    return await this.callEventRepository.getTimeline(callId);
+  }
+
+  // Fold a call's events into the state a live session would hold
+  async replay(callId: string): Promise<ReplayedCallState> {
+    const events = await this.callEventRepository.findByCall(callId);
+    const state: ReplayedCallState = {
+      participantStates: new Map(),
+      speakerQueue: [],
+      keyEpoch: 0,
+      events: events.length,
+    };
+
+    const participant = (userId: string) => {
+      let entry = state.participantStates.get(userId);
+      if (!entry) {
+        entry = { muted: false, mutedByHost: false, onHold: false, handRaised: false, canSpeak: false };
+        state.participantStates.set(userId, entry);
+      }
+      return entry;
+    };
+
+    for (const event of events) {
+      const userId = event.userId?.toString();
+
+      switch (event.type) {
+        case 'media_changed':
+          if (!userId) break;
+          Object.assign(participant(userId), pickBooleans(event.data, ['muted', 'mutedByHost', 'onHold', 'handRaised', 'canSpeak']));
+          // Raised hands queue in the order they went up
+          if (typeof event.data?.handRaised === 'boolean') {
+            state.speakerQueue = state.speakerQueue.filter(entry => entry.userId !== userId);
+            if (event.data.handRaised) state.speakerQueue.push({ userId, raisedAt: event.createdAt });
+          }
+          break;
+        case 'participant_left':
+          if (!userId) break;
+          state.participantStates.delete(userId);
+          state.speakerQueue = state.speakerQueue.filter(entry => entry.userId !== userId);
+          break;
+        case 'keys_rotated':
+          state.keyEpoch = Math.max(state.keyEpoch, Number(event.data?.epoch) || 0);
+          break;
+      }
+    }
+
+    return state;
+  }
+}
+
+function pickBooleans(data: Record<string, unknown> | undefined, keys: string[]): Record<string, boolean> {
+  return Object.fromEntries(
+    keys.filter(key => typeof data?.[key] === 'boolean').map(key => [key, data![key] as boolean])
+  );
+}
+
+export function serializeCallEvent(event: ICallEvent) {
+  return {
+    id: event._id.toString(),
+    type: event.type,
+    user: event.userId,
+    actor: event.actorId,
+    data: event.data,
+    at: event.createdAt,
+  };
+}
+
+export const callEventLog = new CallEventLog();
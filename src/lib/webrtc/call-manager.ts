@@ -1,9 +1,12 @@
 import { webrtcSignalingService } from './signaling';
 import { iceCandidateManager } from './ice-candidates';
 import { coturnManager } from './coturn';
+import { callQualityMonitor } from './quality';
 import { CallRepository } from '../database/repositories/call';
 import { UserRepository } from '../database/repositories/user';
 import { socketManager } from '../realtime/socket';
+import { getActiveRestriction } from '../moderation/actions';
+import { guestCan } from '../auth/guest-access';
 
 interface CallOptions {
   type: 'voice' | 'video';
@@ -194,10 +197,9 @@ export class CallManager {
       // Emit quality update to monitoring systems
       socketManager.emitToUser(userId, 'call:quality-update', qualityData);
 
-      // If quality is poor, suggest quality improvements
-      if (quality.connectionQuality === 'poor' || quality.packetLoss > 0.05) {
-        this.suggestQualityImprovements(callId, userId, quality);
-      }
+      // Poor quality is logged and, past the thresholds in the call
+      // settings, answered with instructions to degrade or relay
+      await callQualityMonitor.report(callId, userId, quality);
 
     } catch (error) {
       console.error('Error updating call quality:', error);
@@ -212,6 +214,12 @@ export class CallManager {
     if (!initiator || initiator.isBanned) {
       throw new Error('Initiator not found or banned');
     }
+    if (getActiveRestriction(initiator)) {
+      throw new Error('Initiator is restricted from calling');
+    }
+    if (!guestCan(initiator, 'calls')) {
+      throw new Error('Initiator is a guest without calls');
+    }
 
     // Check if all participants exist and are not banned
     for (const participantId of participantIds) {
@@ -283,29 +291,7 @@ export class CallManager {
       clearInterval(interval);
       this.activeCallChecks.delete(callId);
     }
-  }
-
-  private suggestQualityImprovements(callId: string, userId: string, quality: CallQuality): void {
-    const suggestions: string[] = [];
-
-    if (quality.packetLoss > 0.05) {
-      suggestions.push('Check your internet connection stability');
-    }
-
-    if (quality.latency > 200) {
-      suggestions.push('Try moving closer to your router');
-    }
-
-    if (quality.connectionQuality === 'poor') {
-      suggestions.push('Consider switching to audio-only mode');
-    }
-
-    if (suggestions.length > 0) {
-      socketManager.emitToUser(userId, 'call:quality-suggestions', {
-        callId,
-        suggestions,
-      });
-    }
+    callQualityMonitor.stopCall(callId);
   }
 
   private startPeriodicCleanup(): void {
@@ -0,0 +1,152 @@
+import { Types } from 'mongoose';
+import { CallRepository } from '../database/repositories/call';
+import { CallEventRepository } from '../database/repositories/call-event';
+import { CallRatingRepository, CallQualityReportDimension } from '../database/repositories/call-rating';
+import { UserRepository } from '../database/repositories/user';
+import { CALL_ISSUE_TAGS, ICallRating } from '../database/models/call-rating';
+import { CallQualityInput } from '../database/schemas/call';
+import { jobQueue } from '../jobs';
+import { socketManager } from '../realtime/socket';
+import { CallControlError } from './signaling';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// findByCallId populates users, so accept either form
+function refId(ref: any): string {
+  return (ref._id || ref).toString();
+}
+
+// The client a rating was made on
+export interface CallRatingClient {
+  region?: string;
+  platform?: string;
+  appVersion?: string;
+  deviceId?: string; // to look the app version up when it wasn't sent
+}
+
+// Post-call ratings. When a call that connected ends, everyone who was in
+// it is asked to rate it: over the socket if they are online, else by push.
+// A rating is 1-5 stars with optional issue tags and a comment, and is kept
+// with what the quality monitor measured for the rater during the call and
+// the client it came from, so admins can see quality by region and app
+// version. Participants can rate until RATING_WINDOW after the call ended,
+// and rating again replaces their earlier rating.
+export class CallRatingService {
+  private callRepository = new CallRepository();
+  private callEventRepository = new CallEventRepository();
+  private callRatingRepository = new CallRatingRepository();
+  private userRepository = new UserRepository();
+
+  // Ask the participants of a call that just ended to rate it. Doesn't
+  // throw: a missed prompt shouldn't get in the way of ending the call.
+  async promptAfterCall(callId: string): Promise<void> {
+    try {
+      const call = await this.callRepository.findByCallId(callId);
+      if (!call || call.status !== 'ended' || (call.duration || 0) < CALL_CONSTANTS.RATING_MIN_DURATION) {
+        return;
+      }
+
+      // Only the people who were connected; nobody answered, nobody to ask
+      const connected = await this.callEventRepository.findUserIds(callId, ['answered', 'participant_joined']);
+      if (connected.length === 0) return;
+      const raters = Array.from(new Set([refId(call.initiator), ...connected]));
+
+      const chatId = call.chatId?.toString();
+      for (const userId of raters) {
+        if (socketManager.isUserOnline(userId)) {
+          socketManager.emitToUser(userId, 'call:rating-requested', {
+            callId,
+            type: call.type,
+            duration: call.duration,
+            issues: CALL_ISSUE_TAGS,
+          });
+          continue;
+        }
+
+        await jobQueue.enqueue('push:call-rating', { userId, callId, callType: call.type, chatId })
+          .catch(error => logger.error('Failed to queue call rating push', error, { callId }));
+      }
+
+      metricsCollector.incrementCounter('call_rating_prompts', raters.length);
+    } catch (error) {
+      logger.error('Failed to ask for call ratings', error, { callId });
+    }
+  }
+
+  // Rate a call you were in
+  async submit(
+    callId: string,
+    userId: string,
+    input: Omit<CallQualityInput, 'callId'>,
+    client: CallRatingClient = {}
+  ): Promise<ICallRating> {
+    const call = await this.callRepository.findByCallId(callId);
+    if (!call || !call.participants.some(participant => refId(participant) === userId)) {
+      throw new CallControlError('Call not found', 404);
+    }
+    if (!call.endTime) {
+      throw new CallControlError('Calls can be rated once they end', 409);
+    }
+    if (Date.now() - call.endTime.getTime() > CALL_CONSTANTS.RATING_WINDOW) {
+      throw new CallControlError('This call can no longer be rated', 410);
+    }
+
+    let appVersion = client.appVersion;
+    if (!appVersion && client.deviceId) {
+      const user = await this.userRepository.findById(userId);
+      appVersion = user?.devices?.find(device => device.deviceId === client.deviceId)?.appVersion;
+    }
+
+    const stats = call.qualityStats?.find(entry => entry.userId.toString() === userId);
+    const rating = await this.callRatingRepository.upsert({
+      callId,
+      userId: new Types.ObjectId(userId),
+      rating: input.rating,
+      issues: input.issues,
+      feedback: input.feedback,
+      callType: call.type,
+      region: client.region || 'unknown',
+      platform: client.platform || 'unknown',
+      appVersion,
+      metrics: stats && {
+        reports: stats.reports,
+        packetLoss: stats.packetLoss,
+        latency: stats.latency,
+        worstLevel: stats.worstLevel,
+        relayed: stats.relayed,
+      },
+    });
+
+    // Still on the call record for older reports
+    await this.callRepository.addQualityRating(callId, userId, input.rating, input.feedback);
+
+    metricsCollector.recordHistogram('call_rating', input.rating);
+    input.issues.forEach(issue => metricsCollector.incrementCounter('call_rating_issues', 1, { issue }));
+
+    return rating;
+  }
+
+  async getCallRatings(callId: string): Promise<ICallRating[]> {
+    return await this.callRatingRepository.findByCall(callId);
+  }
+
+  // Ratings in a period by region, app version, platform or call type
+  async getReport(from: Date, to: Date, groupBy: CallQualityReportDimension) {
+    const rows = await this.callRatingRepository.getReport(from, to, groupBy);
+    const ratings = rows.reduce((sum, row) => sum + row.ratings, 0);
+
+    return {
+      from,
+      to,
+      groupBy,
+      ratings,
+      averageRating: ratings > 0
+        ? Math.round(rows.reduce((sum, row) => sum + row.averageRating * row.ratings, 0) / ratings * 100) / 100
+        : null,
+      rows,
+    };
+  }
+}
+
+export const callRatingService = new CallRatingService();
@@ -0,0 +1,128 @@
+import { ICall } from '../database/models/call';
+import { CallRepository } from '../database/repositories/call';
+import { redisConfig } from '../config/redis';
+import { socketManager } from '../realtime/socket';
+import { webrtcSignalingService } from './signaling';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const LOCK_KEY = 'call:recovery';
+const LOCK_TTL = 60 * 1000;
+const RING_GRACE = 15 * 1000; // past the ring timeout before a ringing call counts as lost
+const REJOIN_GRACE = 90 * 1000; // for participants to reconnect and send call:rejoin
+
+// Call sessions and ring timers live in memory, so a restart leaves calls
+// open in the database that nothing will ever end. On startup this finds
+// them: ringing calls past their ring timeout are ended, the rest are
+// checked again once they would have timed out, and answered calls get a
+// session rebuilt from their event log and a window for participants to
+// rejoin. Calls nobody comes back to are ended with the 'orphaned' reason.
+export class CallRecoveryService {
+  private callRepository = new CallRepository();
+  private redis = redisConfig.getClient();
+  private timers = new Map<string, NodeJS.Timeout>();
+
+  async reconcile(): Promise<void> {
+    try {
+      // Replicas starting together would otherwise all end the same calls
+      if (this.redis && !(await this.redis.set(LOCK_KEY, String(process.pid), 'PX', LOCK_TTL, 'NX'))) {
+        return;
+      }
+
+      const calls = await this.callRepository.findUnfinished();
+      let orphaned = 0;
+      for (const call of calls) {
+        try {
+          if (await this.reconcileCall(call)) orphaned++;
+        } catch (error) {
+          logger.error('Failed to recover call', error, { callId: call.callId });
+        }
+      }
+
+      if (calls.length > 0) {
+        logger.info('Reconciled open calls', { open: calls.length, orphaned, awaiting: this.timers.size });
+      }
+    } catch (error) {
+      logger.error('Call recovery failed', error);
+    }
+  }
+
+  stop(): void {
+    this.timers.forEach(timer => clearTimeout(timer));
+    this.timers.clear();
+  }
+
+  // Returns true when the call was ended right away
+  private async reconcileCall(call: ICall): Promise<boolean> {
+    const callId = call.callId;
+    const age = Date.now() - call.startTime.getTime();
+
+    if (call.status !== 'answered') {
+      // The ring timers that would have ended it died with the old process
+      const deadline = CALL_CONSTANTS.RING_TIMEOUT + RING_GRACE;
+      if (age >= deadline) {
+        return await this.orphan(callId);
+      }
+
+      this.checkLater(callId, deadline - age, async () => {
+        const current = await this.callRepository.findByCallId(callId);
+        if (current && ['initiated', 'ringing'].includes(current.status)) {
+          await this.orphan(callId);
+        }
+      });
+      return false;
+    }
+
+    if (age >= CALL_CONSTANTS.MAX_DURATION) {
+      return await this.orphan(callId);
+    }
+
+    // Still running on another replica
+    if (await this.isAttended(callId)) {
+      return false;
+    }
+
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session) return false;
+
+    const rejoinBy = new Date(Date.now() + REJOIN_GRACE).toISOString();
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, 'call:recovered', { callId, rejoinBy });
+    });
+
+    this.checkLater(callId, REJOIN_GRACE, async () => {
+      if (!(await this.isAttended(callId))) {
+        await this.orphan(callId);
+      }
+    });
+    return false;
+  }
+
+  // At least two participants are connected to the call
+  private async isAttended(callId: string): Promise<boolean> {
+    return (await socketManager.getRoomUserIds(`call:${callId}`)).size >= 2;
+  }
+
+  private checkLater(callId: string, delay: number, check: () => Promise<void>): void {
+    const timer = setTimeout(() => {
+      this.timers.delete(callId);
+      check().catch(error => logger.error('Failed to recover call', error, { callId }));
+    }, delay);
+    timer.unref();
+    this.timers.set(callId, timer);
+  }
+
+  // End a call nobody is in any more and tell its participants
+  private async orphan(callId: string): Promise<boolean> {
+    // No session means the call has ended since
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session) return false;
+
+    await webrtcSignalingService.endCall(callId, 'system', 'orphaned');
+    metricsCollector.incrementCounter('calls_orphaned', 1);
+    return true;
+  }
+}
+
+export const callRecoveryService = new CallRecoveryService();
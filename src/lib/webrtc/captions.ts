@@ -0,0 +1,310 @@
+import { randomUUID } from 'crypto';
+import { environmentConfig } from '../config/environment';
+import { UserRepository } from '../database/repositories/user';
+import { socketManager } from '../realtime/socket';
+import { webrtcSignalingService, CallControlError } from './signaling';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// Audio arrives as 16 kHz mono 16-bit little-endian PCM
+const SAMPLE_RATE = 16000;
+const BYTES_PER_SECOND = SAMPLE_RATE * 2;
+const MAX_CHUNK_BYTES = BYTES_PER_SECOND; // one second per chunk at most
+const MAX_UTTERANCE_BYTES = 10 * BYTES_PER_SECOND;
+const PARTIAL_INTERVAL = 1500; // re-transcribe an open utterance this often
+const SILENCE_TIMEOUT = 800; // a gap this long closes the utterance
+const REQUEST_TIMEOUT = 10 * 1000;
+
+export interface SpeechToTextProvider {
+  transcribe(audio: Buffer, options: { language: string; sampleRate: number }): Promise<string>;
+}
+
+export interface CaptionPreferences {
+  enabled: boolean; // receive captions
+  language: string; // language this participant speaks
+  optOut: boolean; // never transcribe this participant
+}
+
+interface Utterance {
+  captionId: string;
+  chunks: Buffer[];
+  bytes: number;
+  lastText: string;
+  transcribing: boolean;
+  closed: boolean;
+  partialTimer: NodeJS.Timeout;
+  silenceTimer?: NodeJS.Timeout;
+}
+
+interface CaptionCall {
+  preferences: Map<string, CaptionPreferences>;
+  utterances: Map<string, Utterance>; // speakerId -> open utterance
+}
+
+// OpenAI-compatible transcription endpoint (multipart upload of a WAV file)
+export class HttpTranscriptionProvider implements SpeechToTextProvider {
+  constructor(private url: string, private apiKey: string, private model: string) {}
+
+  async transcribe(audio: Buffer, options: { language: string; sampleRate: number }): Promise<string> {
+    const form = new FormData();
+    form.append('file', new Blob([toWav(audio, options.sampleRate)], { type: 'audio/wav' }), 'audio.wav');
+    form.append('model', this.model);
+    form.append('language', options.language.split('-')[0]);
+    form.append('response_format', 'json');
+
+    const response = await fetch(this.url, {
+      method: 'POST',
+      headers: { Authorization: `Bearer ${this.apiKey}` },
+      body: form,
+      signal: AbortSignal.timeout(REQUEST_TIMEOUT),
+    });
+    if (!response.ok) {
+      throw new Error(`Transcription request failed with status ${response.status}`);
+    }
+
+    const result = await response.json();
+    return (result.text || '').trim();
+  }
+}
+
+// Wrap raw PCM16 mono in a WAV header
+function toWav(pcm: Buffer, sampleRate: number): Buffer {
+  const header = Buffer.alloc(44);
+  header.write('RIFF', 0);
+  header.writeUInt32LE(36 + pcm.length, 4);
+  header.write('WAVE', 8);
+  header.write('fmt ', 12);
+  header.writeUInt32LE(16, 16);
+  header.writeUInt16LE(1, 20); // PCM
+  header.writeUInt16LE(1, 22); // mono
+  header.writeUInt32LE(sampleRate, 24);
+  header.writeUInt32LE(sampleRate * 2, 28);
+  header.writeUInt16LE(2, 32);
+  header.writeUInt16LE(16, 34);
+  header.write('data', 36);
+  header.writeUInt32LE(pcm.length, 40);
+  return Buffer.concat([header, pcm]);
+}
+
+// Live captions. Speech is cut into utterances at pauses; an open utterance
+// is re-transcribed periodically for partial captions and once more when it
+// closes for the final one. Audio only flows while at least one participant
+// has captions on. Calls are peer-to-peer, so each client uplinks its own
+// microphone; an SFU can feed the forwarded tracks into pushAudio instead.
+export class CaptionService {
+  private calls = new Map<string, CaptionCall>();
+  private userRepository = new UserRepository();
+  private provider: SpeechToTextProvider | null = null;
+
+  isEnabled(): boolean {
+    return environmentConfig.getValue('CAPTIONS_ENABLED') && !!environmentConfig.getValue('CAPTIONS_STT_API_KEY');
+  }
+
+  // Whether anyone in the call wants captions, i.e. clients should send audio
+  isActive(callId: string): boolean {
+    const call = this.calls.get(callId);
+    return !!call && Array.from(call.preferences.values()).some(preferences => preferences.enabled);
+  }
+
+  async getPreferences(callId: string, userId: string): Promise<CaptionPreferences> {
+    await this.requireParticipant(callId, userId);
+    return this.calls.get(callId)?.preferences.get(userId) || await this.defaultPreferences(userId);
+  }
+
+  // Turn captions on or off, pick the spoken language, or opt out of being
+  // transcribed
+  async setPreferences(
+    callId: string,
+    userId: string,
+    update: Partial<CaptionPreferences>
+  ): Promise<CaptionPreferences> {
+    if (!this.isEnabled()) {
+      throw new CallControlError('Live captions are not available', 503);
+    }
+    await this.requireParticipant(callId, userId);
+
+    let call = this.calls.get(callId);
+    if (!call) {
+      call = { preferences: new Map(), utterances: new Map() };
+      this.calls.set(callId, call);
+    }
+
+    const preferences = {
+      ...(call.preferences.get(userId) || await this.defaultPreferences(userId)),
+      ...update,
+    };
+    call.preferences.set(userId, preferences);
+
+    if (preferences.optOut) {
+      this.discardUtterance(call, userId);
+    }
+
+    const session = webrtcSignalingService.getCallSession(callId)!;
+    const active = this.isActive(callId);
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, 'call:captions:changed', {
+        callId,
+        userId,
+        enabled: preferences.enabled,
+        language: preferences.language,
+        optOut: preferences.optOut,
+        active,
+      });
+    });
+
+    return preferences;
+  }
+
+  // Feed a chunk of a speaker's audio
+  async pushAudio(callId: string, speakerId: string, chunk: Buffer): Promise<void> {
+    const call = this.calls.get(callId);
+    if (!call || !this.isActive(callId) || chunk.length === 0 || chunk.length > MAX_CHUNK_BYTES) {
+      return;
+    }
+
+    const preferences = call.preferences.get(speakerId);
+    if (preferences?.optOut) {
+      return;
+    }
+
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || !session.participants.includes(speakerId)) {
+      return;
+    }
+
+    let utterance = call.utterances.get(speakerId);
+    if (!utterance) {
+      utterance = {
+        captionId: randomUUID(),
+        chunks: [],
+        bytes: 0,
+        lastText: '',
+        transcribing: false,
+        closed: false,
+        partialTimer: setInterval(() => this.transcribe(callId, speakerId, utterance!, false), PARTIAL_INTERVAL),
+      };
+      utterance.partialTimer.unref();
+      call.utterances.set(speakerId, utterance);
+    }
+
+    utterance.chunks.push(chunk);
+    utterance.bytes += chunk.length;
+
+    clearTimeout(utterance.silenceTimer);
+    if (utterance.bytes >= MAX_UTTERANCE_BYTES) {
+      this.closeUtterance(callId, speakerId);
+    } else {
+      utterance.silenceTimer = setTimeout(() => this.closeUtterance(callId, speakerId), SILENCE_TIMEOUT);
+      utterance.silenceTimer.unref();
+    }
+  }
+
+  // Drop all caption state for a call that ended
+  stopCall(callId: string): void {
+    const call = this.calls.get(callId);
+    if (!call) return;
+
+    Array.from(call.utterances.keys()).forEach(speakerId => this.discardUtterance(call, speakerId));
+    this.calls.delete(callId);
+  }
+
+  private closeUtterance(callId: string, speakerId: string): void {
+    const call = this.calls.get(callId);
+    const utterance = call?.utterances.get(speakerId);
+    if (!call || !utterance) return;
+
+    this.discardUtterance(call, speakerId);
+    this.transcribe(callId, speakerId, utterance, true);
+  }
+
+  private discardUtterance(call: CaptionCall, speakerId: string): void {
+    const utterance = call.utterances.get(speakerId);
+    if (!utterance) return;
+
+    utterance.closed = true;
+    clearInterval(utterance.partialTimer);
+    clearTimeout(utterance.silenceTimer);
+    call.utterances.delete(speakerId);
+  }
+
+  private async transcribe(callId: string, speakerId: string, utterance: Utterance, final: boolean): Promise<void> {
+    // Partials are skipped while a request is in flight; finals always run
+    if (!final && (utterance.transcribing || utterance.closed)) {
+      return;
+    }
+
+    const call = this.calls.get(callId);
+    const language = call?.preferences.get(speakerId)?.language || 'en';
+    utterance.transcribing = true;
+
+    try {
+      const startedAt = Date.now();
+      const text = await this.getProvider().transcribe(Buffer.concat(utterance.chunks), {
+        language,
+        sampleRate: SAMPLE_RATE,
+      });
+      metricsCollector.recordGauge('caption_transcription_latency_ms', Date.now() - startedAt);
+
+      // A partial that lost the race with the final is stale
+      if ((!final && utterance.closed) || !text || (!final && text === utterance.lastText)) {
+        return;
+      }
+      utterance.lastText = text;
+
+      this.broadcast(callId, {
+        callId,
+        captionId: utterance.captionId,
+        speakerId,
+        text,
+        language,
+        final,
+      });
+      metricsCollector.incrementCounter('captions_emitted', 1, { final: String(final) });
+    } catch (error) {
+      logger.error('Caption transcription failed', error, { callId, final });
+      metricsCollector.incrementCounter('caption_transcription_failures', 1);
+    } finally {
+      utterance.transcribing = false;
+    }
+  }
+
+  // Send a caption to everyone who has captions on
+  private broadcast(callId: string, caption: Record<string, unknown>): void {
+    const call = this.calls.get(callId);
+    if (!call) return;
+
+    call.preferences.forEach((preferences, userId) => {
+      if (preferences.enabled) {
+        socketManager.emitToUser(userId, 'call:caption', caption);
+      }
+    });
+  }
+
+  private async requireParticipant(callId: string, userId: string): Promise<void> {
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || session.status === 'ended') {
+      throw new CallControlError('Call not found', 404);
+    }
+    if (!session.participants.includes(userId)) {
+      throw new CallControlError('Not a participant in this call', 403);
+    }
+  }
+
+  private async defaultPreferences(userId: string): Promise<CaptionPreferences> {
+    const user = await this.userRepository.findById(userId);
+    return { enabled: false, language: user?.language || 'en', optOut: false };
+  }
+
+  private getProvider(): SpeechToTextProvider {
+    if (!this.provider) {
+      this.provider = new HttpTranscriptionProvider(
+        environmentConfig.getValue('CAPTIONS_STT_URL'),
+        environmentConfig.getValue('CAPTIONS_STT_API_KEY')!,
+        environmentConfig.getValue('CAPTIONS_STT_MODEL')
+      );
+    }
+    return this.provider;
+  }
+}
+
+export const captionService = new CaptionService();
@@ -0,0 +1,44 @@
+import { ICallConfig } from '../database/models/admin-config';
+import { adminConfigService } from '../config/admin-config';
+import { CALL_CONSTANTS } from '../utils/constants';
+
+// End-to-end encrypted calls. Media frames are encrypted on the clients
+// with insertable streams (SFrame). Each sender creates its own media key
+// and hands it to the others wrapped with the chat's end-to-end key, so the
+// server only routes opaque key packets over the signaling channel and
+// never sees key material. The key epoch moves on whenever someone joins or
+// leaves; senders then switch to a fresh key so new members can't decrypt
+// earlier media and departed ones can't decrypt later media.
+
+export type SFrameCipherSuite = typeof CALL_CONSTANTS.SFRAME_CIPHER_SUITES[number];
+
+// What clients need to set up encryption for a call
+export interface CallEncryption {
+  enabled: boolean;
+  cipherSuites: SFrameCipherSuite[];
+  epoch: number;
+}
+
+// Whether a new call is encrypted, from the admin mode and what the caller
+// asked for
+export function resolveCallEncryption(
+  requested: boolean | undefined,
+  config: Partial<ICallConfig> = adminConfigService.getCached().calls
+): boolean {
+  switch (config.e2ee ?? 'optional') {
+    case 'required':
+      return true;
+    case 'disabled':
+      return false;
+    default:
+      return requested ?? false;
+  }
+}
+
+export function describeCallEncryption(encrypted: boolean, epoch: number = 0): CallEncryption {
+  return {
+    enabled: encrypted,
+    cipherSuites: encrypted ? [...CALL_CONSTANTS.SFRAME_CIPHER_SUITES] : [],
+    epoch,
+  };
+}
@@ -0,0 +1,142 @@
+import { IUser } from '../database/models/user';
+import { ICallConfig } from '../database/models/admin-config';
+import { adminConfigService } from '../config/admin-config';
+import { CALL_CONSTANTS } from '../utils/constants';
+
+export type AudioProcessingFlag = 'noiseSuppression' | 'echoCancellation' | 'autoGainControl';
+
+const AUDIO_PROCESSING_FLAGS: AudioProcessingFlag[] = ['noiseSuppression', 'echoCancellation', 'autoGainControl'];
+
+// What a client should apply to its capture and encoders for a call. The
+// audio block maps directly onto getUserMedia audio constraints.
+export interface MediaConstraints {
+  audio: Record<AudioProcessingFlag, boolean>;
+  maxAudioBitrate?: number; // kbps
+  maxVideoBitrate?: number; // kbps
+}
+
+export interface BitrateLimits {
+  audio?: number; // kbps
+  video?: number; // kbps
+}
+
+// Resolve the constraints for one participant: the user's own choice when
+// the admin allows overrides, else the admin default, else on. Devices in
+// low-data mode get lower bitrate ceilings on top.
+export function resolveMediaConstraints(
+  user: Pick<IUser, 'callSettings'> | null,
+  config: Partial<ICallConfig> = adminConfigService.getCached().calls,
+  lowData = false
+): MediaConstraints {
+  const allowOverride = config.allowUserOverride ?? true;
+  const audio = {} as Record<AudioProcessingFlag, boolean>;
+
+  AUDIO_PROCESSING_FLAGS.forEach(flag => {
+    const preference = allowOverride ? user?.callSettings?.[flag] : undefined;
+    audio[flag] = preference ?? config[flag] ?? true;
+  });
+
+  const limits = getBitrateLimits(config, lowData);
+
+  return {
+    audio,
+    ...(limits.audio && { maxAudioBitrate: limits.audio }),
+    ...(limits.video && { maxVideoBitrate: limits.video }),
+  };
+}
+
+export function getBitrateLimits(
+  config: Partial<ICallConfig> = adminConfigService.getCached().calls,
+  lowData = false
+): BitrateLimits {
+  if (!lowData) {
+    return { audio: config.maxAudioBitrate, video: config.maxVideoBitrate };
+  }
+  return {
+    audio: Math.min(config.maxAudioBitrate || Infinity, CALL_CONSTANTS.LOW_DATA_MAX_AUDIO_BITRATE),
+    video: Math.min(config.maxVideoBitrate || Infinity, CALL_CONSTANTS.LOW_DATA_MAX_VIDEO_BITRATE),
+  };
+}
+
+// Cap bandwidth in an SDP. b=AS/b=TIAS tell the remote sender how much we
+// accept, and Opus maxaveragebitrate caps the audio encoder; existing lower
+// values are kept.
+export function applyBitrateLimits(sdp: string, limits: BitrateLimits): string {
+  if (!limits.audio && !limits.video) {
+    return sdp;
+  }
+
+  const lines = sdp.split(/\r\n|\n/);
+  const sections: string[][] = [[]];
+  lines.forEach(line => {
+    if (line.startsWith('m=')) {
+      sections.push([]);
+    }
+    sections[sections.length - 1].push(line);
+  });
+
+  const limited = sections.map((section, index) => {
+    if (index === 0) return section;
+
+    const kind = section[0].slice(2).split(' ')[0];
+    const limit = kind === 'audio' ? limits.audio : kind === 'video' ? limits.video : undefined;
+    return limit ? limitMediaSection(section, kind, limit) : section;
+  });
+
+  return limited.flat().join('\r\n');
+}
+
+// Apply limits to an SDP string or an RTCSessionDescriptionInit-like object
+export function limitSessionDescription<T>(description: T, limits: BitrateLimits = getBitrateLimits()): T {
+  if (typeof description === 'string') {
+    return applyBitrateLimits(description, limits) as T;
+  }
+  const sdp = (description as { sdp?: unknown } | null)?.sdp;
+  if (typeof sdp === 'string') {
+    return { ...description, sdp: applyBitrateLimits(sdp, limits) };
+  }
+  return description;
+}
+
+function limitMediaSection(section: string[], kind: string, limitKbps: number): string[] {
+  let kbps = limitKbps;
+  const existing = section.find(line => line.startsWith('b=AS:'));
+  if (existing) {
+    kbps = Math.min(kbps, parseInt(existing.slice(5), 10) || kbps);
+  }
+
+  const lines = section.filter(line => !line.startsWith('b=AS:') && !line.startsWith('b=TIAS:'));
+
+  // b= lines go after i= and c= (RFC 4566 ordering)
+  let insertAt = 1;
+  while (insertAt < lines.length && /^[ic]=/.test(lines[insertAt])) {
+    insertAt++;
+  }
+  lines.splice(insertAt, 0, `b=AS:${kbps}`, `b=TIAS:${kbps * 1000}`);
+
+  if (kind === 'audio') {
+    const opus = lines
+      .map(line => line.match(/^a=rtpmap:(\d+) opus\//i))
+      .find((match): match is RegExpMatchArray => !!match);
+    if (opus) {
+      const payloadType = opus[1];
+      const prefix = `a=fmtp:${payloadType} `;
+      const maxBps = kbps * 1000;
+      const fmtpIndex = lines.findIndex(line => line.startsWith(prefix));
+
+      if (fmtpIndex === -1) {
+        const rtpmapIndex = lines.findIndex(line => line.startsWith(`a=rtpmap:${payloadType} `));
+        lines.splice(rtpmapIndex + 1, 0, `${prefix}maxaveragebitrate=${maxBps}`);
+      } else {
+        const params = lines[fmtpIndex].slice(prefix.length).split(';').map(param => param.trim()).filter(Boolean);
+        const current = params.find(param => param.startsWith('maxaveragebitrate='));
+        const value = current ? Math.min(parseInt(current.split('=')[1], 10) || maxBps, maxBps) : maxBps;
+        const updated = params.filter(param => !param.startsWith('maxaveragebitrate='));
+        updated.push(`maxaveragebitrate=${value}`);
+        lines[fmtpIndex] = prefix + updated.join(';');
+      }
+    }
+  }
+
+  return lines;
+}
@@ -0,0 +1,262 @@
+import { Types } from 'mongoose';
+import { ICallConfig } from '../database/models/admin-config';
+import { CallRepository } from '../database/repositories/call';
+import { CallQualityReportInput } from '../database/schemas/call';
+import { adminConfigService } from '../config/admin-config';
+import { socketManager } from '../realtime/socket';
+import { webrtcSignalingService } from './signaling';
+import { coturnManager } from './coturn';
+import { callEventLog } from './call-events';
+import { CALL_CONSTANTS, CALL_QUALITY_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+// full: as negotiated; reduced: lower resolution and bitrate; audio_only:
+// video off
+export type CallQualityLevel = 'full' | 'reduced' | 'audio_only';
+
+const LEVELS: CallQualityLevel[] = ['full', 'reduced', 'audio_only'];
+
+export interface CallQualitySettings {
+  enabled: boolean;
+  degradePacketLoss: number;
+  degradeLatency: number;
+  audioOnlyPacketLoss: number;
+  audioOnlyLatency: number;
+  relayAfterReports: number;
+  recoverAfterReports: number;
+}
+
+interface ParticipantQuality {
+  level: CallQualityLevel;
+  poorReports: number; // in a row
+  goodReports: number;
+  relayRequested: boolean;
+  // Totals over the call, kept on the call record when it ends
+  reports: number;
+  packetLossTotal: number;
+  latencyTotal: number;
+  worstLevel: CallQualityLevel;
+}
+
+// Admin overrides from the call settings, else the defaults
+export function getCallQualitySettings(
+  config: Partial<ICallConfig> = adminConfigService.getCached().calls
+): CallQualitySettings {
+  return {
+    enabled: config.qualityResponses !== false,
+    degradePacketLoss: config.degradePacketLoss ?? CALL_QUALITY_CONSTANTS.DEGRADE_PACKET_LOSS,
+    degradeLatency: config.degradeLatency ?? CALL_QUALITY_CONSTANTS.DEGRADE_LATENCY,
+    audioOnlyPacketLoss: config.audioOnlyPacketLoss ?? CALL_QUALITY_CONSTANTS.AUDIO_ONLY_PACKET_LOSS,
+    audioOnlyLatency: config.audioOnlyLatency ?? CALL_QUALITY_CONSTANTS.AUDIO_ONLY_LATENCY,
+    relayAfterReports: config.relayAfterReports ?? CALL_QUALITY_CONSTANTS.RELAY_AFTER_REPORTS,
+    recoverAfterReports: config.recoverAfterReports ?? CALL_QUALITY_CONSTANTS.RECOVER_AFTER_REPORTS,
+  };
+}
+
+// Reacts to the connection stats participants report during a call. Poor
+// reports in a row step a participant down a level (video scaled down,
+// then off), and if they keep coming on a direct path the participant is
+// told to restart ICE through TURN. Good reports in a row step them back
+// up one level at a time. Each change goes to the participant as an
+// instruction, to the others so they can show it and send less, and to the
+// call's timeline. Voice calls only go as far as 'reduced', which lowers
+// the audio bitrate. When the call ends each participant's totals are
+// kept on the call record, where call ratings pick them up.
+export class CallQualityMonitor {
+  private calls = new Map<string, Map<string, ParticipantQuality>>();
+  private callRepository = new CallRepository();
+
+  async report(callId: string, userId: string, report: Omit<CallQualityReportInput, 'callId'>): Promise<void> {
+    const session = await webrtcSignalingService.loadSession(callId);
+    if (!session || session.status === 'ended' || !session.participants.includes(userId)) {
+      return;
+    }
+
+    metricsCollector.recordHistogram('call_packet_loss', report.packetLoss);
+    metricsCollector.recordHistogram('call_latency_ms', report.latency);
+
+    const settings = getCallQualitySettings();
+    const severe = report.packetLoss >= settings.audioOnlyPacketLoss || report.latency >= settings.audioOnlyLatency;
+    const poor = severe
+      || report.packetLoss >= settings.degradePacketLoss
+      || report.latency >= settings.degradeLatency
+      || report.connectionQuality === 'poor';
+    const measured = { packetLoss: report.packetLoss, latency: report.latency };
+
+    if (poor) {
+      callEventLog.record(callId, 'quality_alert', {
+        userId,
+        data: { connectionQuality: report.connectionQuality, ...measured },
+      });
+      this.suggestImprovements(callId, userId, report);
+    }
+
+    const state = this.getState(callId, userId);
+    state.reports++;
+    state.packetLossTotal += report.packetLoss;
+    state.latencyTotal += report.latency;
+    if (!settings.enabled) {
+      return;
+    }
+
+    const maxLevel = session.type === 'video' ? 'audio_only' : 'reduced';
+
+    if (!poor) {
+      state.poorReports = 0;
+      state.goodReports++;
+      if (state.level !== 'full' && state.goodReports >= settings.recoverAfterReports) {
+        state.goodReports = 0;
+        this.apply(session.participants, callId, userId, state, LEVELS[LEVELS.indexOf(state.level) - 1], measured);
+      }
+      return;
+    }
+
+    state.goodReports = 0;
+    state.poorReports++;
+
+    if (state.poorReports >= CALL_QUALITY_CONSTANTS.DEGRADE_AFTER_REPORTS) {
+      const wanted: CallQualityLevel = severe ? 'audio_only' : 'reduced';
+      const target = LEVELS[Math.min(LEVELS.indexOf(wanted), LEVELS.indexOf(maxLevel))];
+      if (LEVELS.indexOf(target) > LEVELS.indexOf(state.level)) {
+        this.apply(session.participants, callId, userId, state, target, measured);
+      }
+    }
+
+    // A path that stays poor may do better through a relay. Only asked for
+    // once per call: the client keeps relaying afterwards.
+    if (
+      state.poorReports >= settings.relayAfterReports
+      && !state.relayRequested
+      && report.candidateType
+      && report.candidateType !== 'relay'
+    ) {
+      state.relayRequested = true;
+      socketManager.emitToUser(userId, 'call:quality-action', {
+        callId,
+        action: 'relay',
+        level: state.level,
+        iceServers: coturnManager.getICEServers(userId),
+        iceTransportPolicy: 'relay',
+      });
+      callEventLog.record(callId, 'quality_action', { userId, data: { action: 'relay', ...measured } });
+      metricsCollector.incrementCounter('call_quality_actions', 1, { action: 'relay' });
+    }
+  }
+
+  // Keep the totals of a call that ended and forget it
+  stopCall(callId: string): void {
+    const call = this.calls.get(callId);
+    if (!call) return;
+    this.calls.delete(callId);
+
+    const stats = Array.from(call.entries())
+      .filter(([, state]) => state.reports > 0)
+      .map(([userId, state]) => ({
+        userId: new Types.ObjectId(userId),
+        reports: state.reports,
+        packetLoss: state.packetLossTotal / state.reports,
+        latency: Math.round(state.latencyTotal / state.reports),
+        worstLevel: state.worstLevel,
+        relayed: state.relayRequested,
+      }));
+    if (stats.length === 0) return;
+
+    this.callRepository.addQualityStats(callId, stats).catch(error => {
+      logger.error('Failed to keep call quality stats', error, { callId });
+    });
+  }
+
+  private apply(
+    participants: string[],
+    callId: string,
+    userId: string,
+    state: ParticipantQuality,
+    level: CallQualityLevel,
+    measured: { packetLoss: number; latency: number }
+  ): void {
+    const previous = state.level;
+    state.level = level;
+    if (LEVELS.indexOf(level) > LEVELS.indexOf(state.worstLevel)) {
+      state.worstLevel = level;
+    }
+    const action = LEVELS.indexOf(level) < LEVELS.indexOf(previous) ? 'restore' : level === 'audio_only' ? 'audio_only' : 'reduce';
+    const limits = this.limitsFor(level);
+
+    socketManager.emitToUser(userId, 'call:quality-action', { callId, action, level, ...limits });
+    participants
+      .filter(participantId => participantId !== userId)
+      .forEach(participantId => {
+        socketManager.emitToUser(participantId, 'call:quality-changed', { callId, userId, level, ...limits });
+      });
+
+    callEventLog.record(callId, 'quality_action', { userId, data: { action, level, previous, ...measured } });
+    metricsCollector.incrementCounter('call_quality_actions', 1, { action });
+  }
+
+  // What a level means for encoders: the participant applies it to what it
+  // sends, the others to what they send it
+  private limitsFor(level: CallQualityLevel) {
+    switch (level) {
+      case 'reduced':
+        return {
+          maxVideoBitrate: CALL_QUALITY_CONSTANTS.REDUCED_VIDEO_BITRATE,
+          maxAudioBitrate: CALL_CONSTANTS.LOW_DATA_MAX_AUDIO_BITRATE,
+          scaleResolutionDownBy: CALL_QUALITY_CONSTANTS.REDUCED_RESOLUTION_SCALE,
+        };
+      case 'audio_only':
+        return { video: false, maxAudioBitrate: CALL_CONSTANTS.LOW_DATA_MAX_AUDIO_BITRATE };
+      default:
+        return {};
+    }
+  }
+
+  private suggestImprovements(callId: string, userId: string, report: Omit<CallQualityReportInput, 'callId'>): void {
+    const suggestions: string[] = [];
+
+    if (report.packetLoss > 0.05) {
+      suggestions.push('Check your internet connection stability');
+    }
+
+    if (report.latency > 200) {
+      suggestions.push('Try moving closer to your router');
+    }
+
+    if (report.connectionQuality === 'poor') {
+      suggestions.push('Consider switching to audio-only mode');
+    }
+
+    if (suggestions.length > 0) {
+      socketManager.emitToUser(userId, 'call:quality-suggestions', {
+        callId,
+        suggestions,
+      });
+    }
+  }
+
+  private getState(callId: string, userId: string): ParticipantQuality {
+    let call = this.calls.get(callId);
+    if (!call) {
+      call = new Map();
+      this.calls.set(callId, call);
+    }
+
+    let state = call.get(userId);
+    if (!state) {
+      state = {
+        level: 'full',
+        poorReports: 0,
+        goodReports: 0,
+        relayRequested: false,
+        reports: 0,
+        packetLossTotal: 0,
+        latencyTotal: 0,
+        worstLevel: 'full',
+      };
+      call.set(userId, state);
+    }
+    return state;
+  }
+}
+
+export const callQualityMonitor = new CallQualityMonitor();
@@ -0,0 +1,172 @@
+import { IUser } from '../database/models/user';
+import { CallRepository } from '../database/repositories/call';
+import { UserRepository } from '../database/repositories/user';
+import {
+  CallCancelReason,
+  CallInvite,
+  pushNotificationService,
+} from '../communication/push-notifications';
+import { socketManager, AuthenticatedSocket } from '../realtime/socket';
+import { resolveMediaConstraints } from './media-constraints';
+import { describeCallEncryption } from './e2ee';
+import { activeCallService } from './active-calls';
+import { callEventLog } from './call-events';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+interface RingState {
+  callId: string;
+  userId: string;
+  callerId: string;
+  pushedDeviceIds: string[]; // devices that were only reached by push
+  timer: NodeJS.Timeout;
+}
+
+// Tracks which of a callee's devices are ringing. Connected devices ring
+// over the socket, the rest by push. The first device to answer or decline
+// settles the ring and every other device is told to stop, over the socket
+// or with a cancellation push for the devices that only got a push.
+export class CallRingingService {
+  private rings = new Map<string, RingState>(); // `${callId}:${userId}`
+  private callRepository = new CallRepository();
+  private userRepository = new UserRepository();
+
+  // Ring every device of the callee
+  async ring(callee: IUser, invite: CallInvite): Promise<void> {
+    const userId = callee._id.toString();
+    const key = this.key(invite.callId, userId);
+    const connectedDeviceIds = socketManager.getUserDeviceIds(userId);
+
+    socketManager.emitToUser(userId, 'call:incoming', {
+      callId: invite.callId,
+      type: invite.callType,
+      initiator: {
+        userId: invite.callerId,
+        displayName: invite.callerName,
+        avatar: invite.callerAvatar,
+      },
+      chatId: invite.chatId,
+      media: resolveMediaConstraints(callee),
+      encryption: describeCallEncryption(invite.encrypted ?? false),
+    });
+
+    const timer = setTimeout(() => {
+      this.expire(invite.callId, userId).catch(error =>
+        logger.error('Call ring timeout failed', error, { callId: invite.callId }));
+    }, CALL_CONSTANTS.RING_TIMEOUT);
+    timer.unref();
+
+    const state: RingState = {
+      callId: invite.callId,
+      userId,
+      callerId: invite.callerId,
+      pushedDeviceIds: [],
+      timer,
+    };
+    this.rings.set(key, state);
+
+    const offlineDeviceIds = callee.devices
+      .filter(device => !connectedDeviceIds.has(device.deviceId) && (device.pushToken || device.voipToken))
+      .map(device => device.deviceId);
+    if (offlineDeviceIds.length > 0) {
+      // Recorded up front so an answer that races the push still cancels it
+      state.pushedDeviceIds = offlineDeviceIds;
+      const results = await pushNotificationService.sendCallInvite(callee, invite, offlineDeviceIds);
+      // Nothing was sent when Do Not Disturb held the invite
+      if (results.length === 0) {
+        state.pushedDeviceIds = [];
+      }
+    }
+
+    callEventLog.record(invite.callId, 'ringing', {
+      userId,
+      data: { socketDevices: connectedDeviceIds.size, pushDevices: state.pushedDeviceIds.length },
+    });
+    metricsCollector.incrementCounter('call_rings_started', 1, {
+      socketDevices: String(connectedDeviceIds.size),
+      pushDevices: String(state.pushedDeviceIds.length),
+    });
+  }
+
+  // Answer or decline from one device. Only the first device wins; returns
+  // false when the ring was already settled elsewhere or timed out.
+  async settle(callId: string, socket: AuthenticatedSocket, outcome: 'answered' | 'declined'): Promise<boolean> {
+    const state = this.take(callId, socket.userId);
+    if (!state) {
+      return false;
+    }
+
+    const reason: CallCancelReason = outcome === 'answered' ? 'answered_elsewhere' : 'declined_elsewhere';
+    socketManager.emitToUser(socket.userId, 'call:ring-cancelled', { callId, reason }, socket.id);
+    await this.cancelPushes(state, reason, socket.deviceId);
+
+    metricsCollector.incrementCounter('call_rings_settled', 1, { outcome });
+    return true;
+  }
+
+  // Stop ringing for everyone still ringing (the caller hung up), or for
+  // one user only
+  async cancel(callId: string, reason: CallCancelReason, userId?: string): Promise<void> {
+    const states = Array.from(this.rings.values())
+      .filter(state => state.callId === callId && (!userId || state.userId === userId));
+
+    for (const state of states) {
+      this.take(callId, state.userId);
+      socketManager.emitToUser(state.userId, 'call:ring-cancelled', { callId, reason });
+      await this.cancelPushes(state, reason);
+    }
+  }
+
+  // Nobody picked up in time: the call is missed
+  private async expire(callId: string, userId: string): Promise<void> {
+    const state = this.take(callId, userId);
+    if (!state) return;
+
+    socketManager.emitToUser(userId, 'call:ring-cancelled', { callId, reason: 'missed' });
+    await this.cancelPushes(state, 'missed');
+
+    // Only end the call if no other callee is still ringing
+    if (!Array.from(this.rings.values()).some(other => other.callId === callId)) {
+      await this.callRepository.endCall(callId, 'missed');
+      await activeCallService.ended(callId);
+      callEventLog.record(callId, 'ended', { data: { endedBy: 'system', reason: 'missed' } });
+      socketManager.emitToUser(state.callerId, 'call:ended', {
+        callId,
+        endedBy: userId,
+        reason: 'missed',
+      });
+    }
+
+    metricsCollector.incrementCounter('call_rings_missed', 1);
+  }
+
+  private async cancelPushes(state: RingState, reason: CallCancelReason, exceptDeviceId?: string): Promise<void> {
+    const deviceIds = state.pushedDeviceIds.filter(deviceId => deviceId !== exceptDeviceId);
+    if (deviceIds.length === 0) return;
+
+    const user = await this.userRepository.findById(state.userId);
+    if (user) {
+      await pushNotificationService.sendCallCancel(user, {
+        callId: state.callId,
+        callerId: state.callerId,
+      }, reason, deviceIds);
+    }
+  }
+
+  private take(callId: string, userId: string): RingState | null {
+    const key = this.key(callId, userId);
+    const state = this.rings.get(key);
+    if (!state) return null;
+
+    clearTimeout(state.timer);
+    this.rings.delete(key);
+    return state;
+  }
+
+  private key(callId: string, userId: string): string {
+    return `${callId}:${userId}`;
+  }
+}
+
+export const callRingingService = new CallRingingService();
@@ -0,0 +1,414 @@
+import { randomBytes, randomUUID } from 'crypto';
+import { Types } from 'mongoose';
+import { ScheduledCallRepository } from '../database/repositories/scheduled-call';
+import { ChatRepository } from '../database/repositories/chat';
+import { ScheduledCall, IScheduledCall } from '../database/models/scheduled-call';
+import { IChat } from '../database/models/chat';
+import { changeStreamHub, ChangeStreamHandle, WakeTimer } from '../database/change-streams';
+import { environmentConfig } from '../config/environment';
+import { jobQueue } from '../jobs';
+import { socketManager } from '../realtime/socket';
+import { webrtcSignalingService, CallControlError } from './signaling';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const POLL_INTERVAL = 15 * 1000;
+const SWEEP_INTERVAL = 5 * 60 * 1000; // while change streams report new and moved calls
+const BATCH_SIZE = 50; // reminders or starts per tick before yielding
+const EARLY_JOIN = 10 * 60 * 1000; // the link opens the call this long before start
+const MAX_LEAD_TIME = 365 * 24 * 60 * 60 * 1000;
+const MAX_SCHEDULED_PER_CHAT = 50;
+const DEFAULT_DURATION = 60; // minutes
+const DEFAULT_REMIND_BEFORE = 10; // minutes
+
+export interface ScheduleCallOptions {
+  title: string;
+  description?: string;
+  type: 'voice' | 'video';
+  startsAt: Date;
+  durationMinutes?: number;
+  participantIds?: string[]; // defaults to everyone in the chat
+  remindBefore?: number | null; // minutes; null for no reminder
+}
+
+// Calls planned ahead in a chat. A worker reminds the invitees shortly
+// before and starts the live call at the scheduled time, ringing everyone
+// invited; the join link lets people in while it runs and opens the call a
+// little early for whoever arrives first. The worker wakes when it next has
+// something to do, following new and moved calls through a change stream,
+// and only polls while change streams are unavailable.
+export class ScheduledCallService {
+  private scheduledCallRepository = new ScheduledCallRepository();
+  private chatRepository = new ChatRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+  private wake = new WakeTimer(() => this.tick());
+  private changes: ChangeStreamHandle | null = null;
+
+  // Start the worker. Safe to run on several instances: reminders and starts
+  // are claimed atomically.
+  start(): void {
+    if (this.timer) return;
+
+    this.schedulePolling(POLL_INTERVAL);
+    this.changes = changeStreamHub.watch({
+      name: 'scheduled-calls',
+      model: ScheduledCall,
+      handler: change => {
+        const scheduledCall = 'fullDocument' in change ? change.fullDocument : undefined;
+        if (scheduledCall?.status !== 'scheduled') return;
+        this.wakeAt(scheduledCall.startsAt);
+        if (!scheduledCall.reminderSentAt) this.wakeAt(scheduledCall.remindAt);
+      },
+      onStatus: live => {
+        if (this.timer) this.schedulePolling(live ? SWEEP_INTERVAL : POLL_INTERVAL);
+      },
+    });
+    this.tick();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+    this.wake.clear();
+    this.changes?.close();
+    this.changes = null;
+  }
+
+  async create(userId: string, chatId: string, options: ScheduleCallOptions): Promise<IScheduledCall> {
+    const chat = await this.requireChatMember(chatId, userId);
+    const startsAt = options.startsAt;
+    this.validateStart(startsAt);
+
+    if (await this.scheduledCallRepository.countScheduled(chatId) >= MAX_SCHEDULED_PER_CHAT) {
+      throw new ScheduledCallError(`A chat can have at most ${MAX_SCHEDULED_PER_CHAT} scheduled calls`, 429);
+    }
+
+    const participants = this.resolveParticipants(chat, userId, options.participantIds);
+    const durationMinutes = options.durationMinutes ?? DEFAULT_DURATION;
+    const remindBefore = options.remindBefore === undefined ? DEFAULT_REMIND_BEFORE : options.remindBefore;
+
+    const scheduledCall = await this.scheduledCallRepository.create({
+      chatId: chat._id,
+      createdBy: new Types.ObjectId(userId),
+      title: options.title,
+      description: options.description,
+      type: options.type,
+      participants: participants.map(id => new Types.ObjectId(id)),
+      startsAt,
+      endsAt: new Date(startsAt.getTime() + durationMinutes * 60 * 1000),
+      remindAt: remindBefore === null ? null : new Date(startsAt.getTime() - remindBefore * 60 * 1000),
+      joinCode: randomBytes(12).toString('base64url'),
+    });
+
+    socketManager.emitToChat(chatId, 'call:scheduled', {
+      chatId,
+      scheduledCall: serializeScheduledCall(scheduledCall),
+    });
+
+    metricsCollector.incrementCounter('scheduled_calls_created', 1, { type: options.type });
+    return scheduledCall;
+  }
+
+  async getScheduledCall(scheduledCallId: string): Promise<IScheduledCall | null> {
+    return Types.ObjectId.isValid(scheduledCallId)
+      ? await this.scheduledCallRepository.findById(scheduledCallId)
+      : null;
+  }
+
+  async getByJoinCode(joinCode: string): Promise<IScheduledCall | null> {
+    return await this.scheduledCallRepository.findByJoinCode(joinCode);
+  }
+
+  // Calls in a chat that are still ahead or running
+  async getUpcoming(chatId: string, userId: string, limit?: number, offset?: number): Promise<IScheduledCall[]> {
+    await this.requireChatMember(chatId, userId);
+    return await this.scheduledCallRepository.getUpcomingForChat(chatId, limit, offset);
+  }
+
+  // Change a call that hasn't started. Moving it re-arms the reminder.
+  async update(
+    scheduledCall: IScheduledCall,
+    userId: string,
+    changes: Partial<ScheduleCallOptions>
+  ): Promise<IScheduledCall> {
+    const chat = await this.requireOrganizer(scheduledCall, userId);
+
+    const startsAt = changes.startsAt || scheduledCall.startsAt;
+    if (changes.startsAt) {
+      this.validateStart(startsAt);
+    }
+
+    const durationMinutes = changes.durationMinutes
+      ?? Math.round((scheduledCall.endsAt.getTime() - scheduledCall.startsAt.getTime()) / 60000);
+    const remindBefore = changes.remindBefore !== undefined
+      ? changes.remindBefore
+      : scheduledCall.remindAt
+        ? Math.round((scheduledCall.startsAt.getTime() - scheduledCall.remindAt.getTime()) / 60000)
+        : null;
+
+    const update: Partial<IScheduledCall> = {
+      startsAt,
+      endsAt: new Date(startsAt.getTime() + durationMinutes * 60 * 1000),
+      remindAt: remindBefore === null ? null : new Date(startsAt.getTime() - remindBefore * 60 * 1000),
+      reminderSentAt: changes.startsAt || changes.remindBefore !== undefined ? null : scheduledCall.reminderSentAt,
+    };
+    if (changes.title !== undefined) update.title = changes.title;
+    if (changes.description !== undefined) update.description = changes.description;
+    if (changes.type !== undefined) update.type = changes.type;
+    if (changes.participantIds) {
+      update.participants = this.resolveParticipants(chat, scheduledCall.createdBy.toString(), changes.participantIds)
+        .map(id => new Types.ObjectId(id));
+    }
+
+    const updated = await this.scheduledCallRepository.updateScheduled(scheduledCall._id, update);
+    if (!updated) {
+      throw new ScheduledCallError('Call has already started or was cancelled', 409);
+    }
+
+    socketManager.emitToChat(updated.chatId.toString(), 'call:scheduled', {
+      chatId: updated.chatId.toString(),
+      scheduledCall: serializeScheduledCall(updated),
+    });
+    return updated;
+  }
+
+  // Cancel a call that hasn't started
+  async cancel(scheduledCall: IScheduledCall, userId: string): Promise<void> {
+    await this.requireOrganizer(scheduledCall, userId);
+
+    if (!(await this.scheduledCallRepository.cancel(scheduledCall._id))) {
+      throw new ScheduledCallError('Call has already started or was cancelled', 409);
+    }
+
+    socketManager.emitToChat(scheduledCall.chatId.toString(), 'call:scheduled:cancelled', {
+      chatId: scheduledCall.chatId.toString(),
+      scheduledCallId: scheduledCall._id.toString(),
+    });
+    metricsCollector.incrementCounter('scheduled_calls_cancelled', 1);
+  }
+
+  // Join through the link. Opens the call early when it's close to the start
+  // time; anyone with the link may join unless the host locked the call.
+  async join(joinCode: string, userId: string) {
+    let scheduledCall = await this.scheduledCallRepository.findByJoinCode(joinCode);
+    if (!scheduledCall || scheduledCall.status === 'cancelled') {
+      throw new ScheduledCallError('Meeting link is not valid', 404);
+    }
+    if (scheduledCall.status === 'expired' || scheduledCall.endsAt.getTime() <= Date.now()) {
+      throw new ScheduledCallError('This call is over', 410);
+    }
+
+    if (scheduledCall.status === 'scheduled') {
+      if (scheduledCall.startsAt.getTime() - Date.now() > EARLY_JOIN) {
+        throw new ScheduledCallError('This call has not started yet', 409);
+      }
+      // Another request may have started it in the meantime
+      scheduledCall = await this.startCall(scheduledCall) || await this.scheduledCallRepository.findById(scheduledCall._id);
+      if (!scheduledCall?.callId) {
+        throw new ScheduledCallError('Call could not be started', 503);
+      }
+    }
+
+    try {
+      const session = await webrtcSignalingService.joinCall(scheduledCall.callId!, userId);
+      return { scheduledCall, session };
+    } catch (error) {
+      if (error instanceof CallControlError && error.status === 404) {
+        throw new ScheduledCallError('This call is over', 410);
+      }
+      throw error;
+    }
+  }
+
+  private async tick(): Promise<void> {
+    if (this.ticking) return;
+    this.ticking = true;
+
+    try {
+      const expired = await this.scheduledCallRepository.expireMissed();
+      if (expired > 0) {
+        logger.warn('Scheduled calls expired without starting', { count: expired });
+      }
+
+      for (let sent = 0; sent < BATCH_SIZE; sent++) {
+        const scheduledCall = await this.scheduledCallRepository.claimDueReminder();
+        if (!scheduledCall) break;
+        await this.sendReminders(scheduledCall);
+      }
+
+      for (let started = 0; started < BATCH_SIZE; started++) {
+        const scheduledCall = await this.startCall();
+        if (!scheduledCall) break;
+      }
+
+      this.wakeAt(await this.scheduledCallRepository.findNextDueAt());
+    } catch (error) {
+      logger.error('Scheduled call worker error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+
+  // Work that is already due but wasn't done, i.e. a call that failed to
+  // start, is retried at the polling pace rather than in a tight loop
+  private wakeAt(time: Date | null | undefined): void {
+    if (!time) return;
+    this.wake.schedule(time.getTime() > Date.now() ? time : new Date(Date.now() + POLL_INTERVAL));
+  }
+
+  private schedulePolling(interval: number): void {
+    if (this.timer) clearInterval(this.timer);
+    this.timer = setInterval(() => this.tick(), interval);
+    this.timer.unref();
+  }
+
+  private async sendReminders(scheduledCall: IScheduledCall): Promise<void> {
+    const scheduledCallId = scheduledCall._id.toString();
+    const chatId = scheduledCall.chatId.toString();
+
+    for (const participantId of scheduledCall.participants.map(id => id.toString())) {
+      socketManager.emitToUser(participantId, 'call:scheduled:reminder', {
+        chatId,
+        scheduledCallId,
+        title: scheduledCall.title,
+        startsAt: scheduledCall.startsAt.toISOString(),
+      });
+
+      // Banned users are skipped when the push goes out
+      await jobQueue.enqueue('push:scheduled-call', { userId: participantId, scheduledCallId, stage: 'reminder' })
+        .catch(error => logger.error('Failed to queue scheduled call reminder push', error, { scheduledCallId }));
+    }
+
+    metricsCollector.incrementCounter('scheduled_call_reminders_sent', 1);
+  }
+
+  // Start the next due call, or the given one early. Returns null when there
+  // was nothing to start or someone else started it first.
+  private async startCall(pending?: IScheduledCall): Promise<IScheduledCall | null> {
+    const callId = randomUUID();
+    const scheduledCall = await this.scheduledCallRepository.claimStart(callId, pending?._id);
+    if (!scheduledCall) {
+      return null;
+    }
+
+    const scheduledCallId = scheduledCall._id.toString();
+    const chatId = scheduledCall.chatId.toString();
+    const organizerId = scheduledCall.createdBy.toString();
+    const invitees = scheduledCall.participants
+      .map(id => id.toString())
+      .filter(id => id !== organizerId)
+      .slice(0, CALL_CONSTANTS.MAX_GROUP_PARTICIPANTS - 1);
+
+    try {
+      // The organizer hosts even when someone else opened the call early
+      await webrtcSignalingService.initiateCall(callId, organizerId, invitees, scheduledCall.type, chatId);
+      // A worker process has no sockets to signal; the web servers load the
+      // session from the call record when people join
+      if (!socketManager.getIO()) webrtcSignalingService.discardSession(callId);
+    } catch (error) {
+      logger.error('Scheduled call start failed', error, { scheduledCallId });
+      await this.scheduledCallRepository.releaseStart(scheduledCall._id);
+      return null;
+    }
+
+    socketManager.emitToChat(chatId, 'call:scheduled:started', { chatId, scheduledCallId, callId });
+
+    for (const participantId of invitees) {
+      // Banned users are skipped when the push goes out
+      await jobQueue.enqueue('push:scheduled-call', { userId: participantId, scheduledCallId, stage: 'started' })
+        .catch(error => logger.error('Failed to queue scheduled call start push', error, { scheduledCallId }));
+    }
+
+    metricsCollector.incrementCounter('scheduled_calls_started', 1, { early: String(!!pending) });
+    metricsCollector.recordGauge('scheduled_call_start_lag_ms', Date.now() - scheduledCall.startsAt.getTime());
+    return scheduledCall;
+  }
+
+  // What pushes about the call show; also used by queued push jobs
+  pushSummary(scheduledCall: IScheduledCall) {
+    return {
+      id: scheduledCall._id.toString(),
+      chatId: scheduledCall.chatId.toString(),
+      title: scheduledCall.title,
+      type: scheduledCall.type,
+      startsAt: scheduledCall.startsAt,
+      createdBy: scheduledCall.createdBy.toString(),
+    };
+  }
+
+  // Invitees must be in the chat; the organizer is always invited
+  private resolveParticipants(chat: IChat, organizerId: string, participantIds?: string[]): string[] {
+    const members = chat.participants.map(id => id.toString());
+    const invited = participantIds ? Array.from(new Set([organizerId, ...participantIds])) : members;
+
+    if (invited.some(id => !members.includes(id))) {
+      throw new ScheduledCallError('Participants must be members of the chat', 400);
+    }
+    return invited;
+  }
+
+  private validateStart(startsAt: Date): void {
+    const now = Date.now();
+    if (startsAt.getTime() <= now) {
+      throw new ScheduledCallError('Start time must be in the future', 400);
+    }
+    if (startsAt.getTime() > now + MAX_LEAD_TIME) {
+      throw new ScheduledCallError('Calls can be scheduled at most one year ahead', 400);
+    }
+  }
+
+  private async requireChatMember(chatId: string, userId: string): Promise<IChat> {
+    const chat = Types.ObjectId.isValid(chatId) ? await this.chatRepository.findById(chatId) : null;
+    if (!chat || !chat.participants.some(id => id.toString() === userId)) {
+      throw new ScheduledCallError('Chat not found', 404);
+    }
+    return chat;
+  }
+
+  // The organizer or a group admin may change or cancel a call
+  private async requireOrganizer(scheduledCall: IScheduledCall, userId: string): Promise<IChat> {
+    const chat = await this.requireChatMember(scheduledCall.chatId.toString(), userId);
+    const isAdmin = !!chat.groupInfo?.admins.some(id => id.toString() === userId);
+    if (scheduledCall.createdBy.toString() !== userId && !isAdmin) {
+      throw new ScheduledCallError('Only the organizer can change this call', 403);
+    }
+    return chat;
+  }
+}
+
+export function getJoinLink(joinCode: string): string {
+  return `${environmentConfig.getValue('FRONTEND_URL')}/calls/join/${joinCode}`;
+}
+
+// Client-facing view of a scheduled call
+export function serializeScheduledCall(scheduledCall: IScheduledCall) {
+  return {
+    id: scheduledCall._id.toString(),
+    chatId: scheduledCall.chatId.toString(),
+    createdBy: scheduledCall.createdBy.toString(),
+    title: scheduledCall.title,
+    description: scheduledCall.description,
+    type: scheduledCall.type,
+    participants: scheduledCall.participants.map(id => id.toString()),
+    startsAt: scheduledCall.startsAt,
+    endsAt: scheduledCall.endsAt,
+    remindAt: scheduledCall.remindAt || undefined,
+    status: scheduledCall.status,
+    callId: scheduledCall.callId,
+    joinLink: getJoinLink(scheduledCall.joinCode),
+    createdAt: scheduledCall.createdAt,
+  };
+}
+
+export class ScheduledCallError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'ScheduledCallError';
+  }
+}
+
+export const scheduledCallService = new ScheduledCallService();
@@ -1,7 +1,20 @@
 import { CallRepository } from '../database/repositories/call';
-import { ICall } from '../database/models/call';
+import { UserRepository } from '../database/repositories/user';
+import { CallEndReason, ICall } from '../database/models/call';
 import { socketManager } from '../realtime/socket';
+import { ServerEventName } from '../realtime/protocol';
+import { callRingingService } from './ringing';
+import { activeCallService } from './active-calls';
+import { callEventLog } from './call-events';
+import { distributedLock } from '../database/locks';
+import { limitSessionDescription } from './media-constraints';
+import { describeCallEncryption, resolveCallEncryption } from './e2ee';
+import { moderationService } from '../moderation/actions';
+import { guestAccessService } from '../auth/guest-access';
+import { CallKeyPacketInput } from '../database/schemas/call';
 import { Types } from 'mongoose';
+import { t, formatDuration } from '../i18n';
+import { CALL_CONSTANTS } from '../utils/constants';
 
 interface SignalingMessage {
   type: 'offer' | 'answer' | 'ice-candidate' | 'call-end';
@@ -11,10 +24,27 @@ interface SignalingMessage {
   data: any;
 }
 
+export type CallRole = 'host' | 'participant';
+
+interface ParticipantState {
+  role: CallRole;
+  muted: boolean;
+  mutedByHost: boolean;
+  onHold: boolean;
+  handRaised: boolean;
+  canSpeak: boolean; // given the floor by the host in a listen-only call
+}
+
+export interface SpeakerQueueEntry {
+  userId: string;
+  raisedAt: Date;
+}
+
 interface CallSession {
   callId: string;
   initiator: string;
   participants: string[];
+  type: 'voice' | 'video';
   status: 'initiating' | 'ringing' | 'connected' | 'ended';
   startTime: Date;
   signaling: {
@@ -22,14 +52,31 @@ interface CallSession {
     answers: Map<string, any>;
     iceCandidates: Map<string, any[]>;
   };
+  // Host controls; exactly one participant holds the host role
+  participantStates: Map<string, ParticipantState>;
+  locked: boolean;
+  removed: Set<string>;
+  // Listen-only conferences: only the host and participants given the floor
+  // can unmute. Raised hands queue up for the host, oldest first.
+  listenOnly: boolean;
+  speakerQueue: SpeakerQueueEntry[];
+  // End-to-end encryption; the epoch moves on with every membership change
+  encrypted: boolean;
+  keyEpoch: number;
+}
+
+function createParticipantState(role: CallRole): ParticipantState {
+  return { role, muted: false, mutedByHost: false, onHold: false, handRaised: false, canSpeak: false };
 }
 
 export class WebRTCSignalingService {
   private callRepository: CallRepository;
+  private userRepository: UserRepository;
   private activeCalls: Map<string, CallSession> = new Map();
 
   constructor() {
     this.callRepository = new CallRepository();
+    this.userRepository = new UserRepository();
   }
 
   // Initiate a new call
@@ -38,7 +85,8 @@ export class WebRTCSignalingService {
     initiatorId: string,
     participantIds: string[],
     type: 'voice' | 'video',
-    chatId?: string
+    chatId?: string,
+    encrypted: boolean = resolveCallEncryption(undefined)
   ): Promise<CallSession> {
     try {
       // Create call in database
@@ -50,6 +98,7 @@ export class WebRTCSignalingService {
         status: 'initiated',
         chatId: chatId as any,
         isGroupCall: participantIds.length > 1,
+        encrypted,
       });
 
       // Create local session
@@ -57,6 +106,7 @@ export class WebRTCSignalingService {
         callId,
         initiator: initiatorId,
         participants: [initiatorId, ...participantIds],
+        type,
         status: 'initiating',
         startTime: new Date(),
         signaling: {
@@ -64,9 +114,24 @@ export class WebRTCSignalingService {
           answers: new Map(),
           iceCandidates: new Map(),
         },
+        participantStates: new Map([
+          [initiatorId, createParticipantState('host')],
+          ...participantIds.map(id => [id, createParticipantState('participant')] as [string, ParticipantState]),
+        ]),
+        locked: false,
+        removed: new Set(),
+        listenOnly: false,
+        speakerQueue: [],
+        encrypted,
+        keyEpoch: 0,
       };
 
       this.activeCalls.set(callId, session);
+      await activeCallService.started({ callId, chatId, type, initiatorId });
+      callEventLog.record(callId, 'initiated', {
+        userId: initiatorId,
+        data: { type, participants: participantIds, chatId, encrypted },
+      });
 
       // Notify participants via Socket.IO
       participantIds.forEach(participantId => {
@@ -75,6 +140,7 @@ export class WebRTCSignalingService {
           type,
           initiator: initiatorId,
           chatId,
+          encryption: describeCallEncryption(encrypted),
         });
       });
 
@@ -88,6 +154,9 @@ export class WebRTCSignalingService {
   // Handle WebRTC offer
   async handleOffer(callId: string, senderId: string, offer: RTCSessionDescriptionInit): Promise<void> {
     try {
+      // Enforce the configured bitrate caps before the offer is relayed
+      offer = limitSessionDescription(offer);
+
       const session = this.activeCalls.get(callId);
       if (!session) {
         throw new Error('Call session not found');
@@ -119,6 +188,8 @@ export class WebRTCSignalingService {
   // Handle WebRTC answer
   async handleAnswer(callId: string, senderId: string, answer: RTCSessionDescriptionInit): Promise<void> {
     try {
+      answer = limitSessionDescription(answer);
+
       const session = this.activeCalls.get(callId);
       if (!session) {
         throw new Error('Call session not found');
@@ -131,6 +202,9 @@ export class WebRTCSignalingService {
       await this.callRepository.addAnswer(callId, senderId as any, JSON.stringify(answer));
 
       // Update call status to connected
+      if (session.status !== 'connected') {
+        callEventLog.record(callId, 'answered', { userId: senderId });
+      }
       session.status = 'connected';
       await this.callRepository.update(
         (await this.callRepository.findByCallId(callId))?._id!,
@@ -188,27 +262,46 @@ export class WebRTCSignalingService {
     }
   }
 
-  // End call
-  async endCall(callId: string, endedBy: string, reason: 'normal' | 'busy' | 'missed' | 'rejected' = 'normal'): Promise<void> {
+  // End call. Replicas can end the same call at once (both sides hanging
+  // up), so this holds the call's lock and only the first one notifies.
+  async endCall(callId: string, endedBy: string, reason: CallEndReason = 'normal'): Promise<void> {
     try {
       const session = this.activeCalls.get(callId);
       if (!session) {
         throw new Error('Call session not found');
       }
 
-      // Update session status
-      session.status = 'ended';
-
-      // Update database
-      const status = reason === 'normal' ? 'ended' : reason;
-      await this.callRepository.endCall(callId, status as any);
-
-      // Notify all participants
-      session.participants.forEach(participantId => {
-        socketManager.emitToUser(participantId, 'call:ended', {
-          callId,
-          endedBy,
-          reason,
+      await distributedLock.withLock(`call:${callId}`, async () => {
+        // Update database; an orphaned call counts as missed if nobody answered
+        const wasConnected = session.status === 'connected';
+        session.status = 'ended';
+        const status = reason === 'normal' ? 'ended'
+          : reason === 'orphaned' ? (wasConnected ? 'ended' : 'missed')
+          : reason;
+        if (!(await this.callRepository.endCall(callId, status, reason))) {
+          return;
+        }
+        await activeCallService.ended(callId);
+        const { liveBroadcastService } = await import('./broadcasts');
+        await liveBroadcastService.callEnded(callId);
+        const { callChatService } = await import('./call-chat');
+        await callChatService.callEnded(callId);
+        const { callQualityMonitor } = await import('./quality');
+        callQualityMonitor.stopCall(callId);
+        callEventLog.record(callId, 'ended', { actorId: endedBy, data: { endedBy, reason } });
+        const { callRatingService } = await import('./call-ratings');
+        callRatingService.promptAfterCall(callId);
+
+        // Notify all participants with a summary in their own language
+        const durationSeconds = (Date.now() - session.startTime.getTime()) / 1000;
+        const languages = await this.userRepository.getLanguages(session.participants);
+        session.participants.forEach(participantId => {
+          socketManager.emitToUser(participantId, 'call:ended', {
+            callId,
+            endedBy,
+            reason,
+            summary: this.getCallSummary(languages.get(participantId), session.type, reason, durationSeconds),
+          });
         });
       });
 
@@ -221,6 +314,452 @@ export class WebRTCSignalingService {
     }
   }
 
+  // Build the localized system line shown in the chat, e.g. "Call ended • 3:45"
+  private getCallSummary(
+    locale: string | undefined,
+    type: 'voice' | 'video',
+    reason: CallEndReason,
+    durationSeconds: number
+  ): string {
+    switch (reason) {
+      case 'missed':
+        return t(locale, 'call.missed', { callType: t(locale, `call.type.${type}`) });
+      case 'rejected':
+        return t(locale, 'call.rejected');
+      case 'busy':
+        return t(locale, 'call.busy');
+      case 'orphaned':
+        return t(locale, 'call.interrupted');
+      default:
+        return t(locale, 'call.ended', { duration: formatDuration(durationSeconds) });
+    }
+  }
+
+  // Join an ongoing call. Locked calls only admit existing participants,
+  // removed participants can't come back and restricted users and guests
+  // without calls can't join.
+  async joinCall(callId: string, userId: string): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+
+    if (await moderationService.getRestriction(userId)) {
+      throw new CallControlError('Calling is not available for your account', 403);
+    }
+    if (!(await guestAccessService.allows(userId, 'calls'))) {
+      throw new CallControlError('Guests can\'t join calls in this chat', 403);
+    }
+    if (session.removed.has(userId)) {
+      throw new CallControlError('You were removed from this call', 403);
+    }
+    if (session.participants.includes(userId)) {
+      return session;
+    }
+
+    // The lock and the call record cover joins and host changes handled by
+    // other replicas, whose sessions this one doesn't see
+    return await distributedLock.withLock(`call:${callId}`, async () => {
+      const call = await this.callRepository.findByCallId(callId);
+      if (!call || !['initiated', 'ringing', 'answered'].includes(call.status)) {
+        throw new CallControlError('Call not found', 404);
+      }
+      if (session.locked || call.locked) {
+        throw new CallControlError('Call is locked', 423);
+      }
+      if (call.participants.length >= CALL_CONSTANTS.MAX_GROUP_PARTICIPANTS) {
+        throw new CallControlError('Call is full', 409);
+      }
+
+      session.participants.push(userId);
+      session.participantStates.set(userId, createParticipantState('participant'));
+      await this.callRepository.addParticipant(callId, userId);
+      await activeCallService.joined(callId, userId);
+      callEventLog.record(callId, 'participant_joined', { userId });
+
+      this.broadcast(session, 'call:participant-joined', { callId, userId });
+      // Late joiners of a listen-only call come in muted like everyone else
+      if (session.listenOnly) {
+        this.muteListeners(session, [userId], this.getHostId(session)!);
+      }
+      this.rotateKeys(session, 'joined');
+      return session;
+    });
+  }
+
+  // Put the call on hold for yourself, or resume it
+  async setHold(callId: string, userId: string, onHold: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    const state = this.requireParticipant(session, userId);
+
+    state.onHold = onHold;
+    callEventLog.record(callId, 'media_changed', { userId, data: { onHold } });
+    this.broadcast(session, 'call:hold-changed', { callId, userId, onHold });
+    return session;
+  }
+
+  // Raise or lower your hand. The host can lower anyone's.
+  async setHandRaised(callId: string, actorId: string, targetId: string, raised: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireParticipant(session, actorId);
+    const state = this.requireParticipant(session, targetId);
+
+    if (actorId !== targetId) {
+      this.requireHost(session, actorId);
+      if (raised) {
+        throw new CallControlError('Participants raise their own hand', 403);
+      }
+    }
+    if (state.handRaised === raised) {
+      return session;
+    }
+
+    this.changeHand(session, targetId, raised, actorId);
+    return session;
+  }
+
+  // Mute or unmute someone. Anyone can change their own state, except that
+  // listeners in a listen-only call can't unmute; only the host can mute
+  // others, and nobody can be unmuted by someone else.
+  async setMuted(callId: string, actorId: string, targetId: string, muted: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireParticipant(session, actorId);
+    const state = this.requireParticipant(session, targetId);
+
+    if (actorId !== targetId) {
+      this.requireHost(session, actorId);
+      if (!muted) {
+        throw new CallControlError('Participants unmute themselves', 403);
+      }
+    } else if (!muted && session.listenOnly && state.role !== 'host' && !state.canSpeak) {
+      throw new CallControlError('Raise your hand to ask the host to speak', 403);
+    }
+
+    state.muted = muted;
+    state.mutedByHost = muted && actorId !== targetId;
+    callEventLog.record(callId, 'media_changed', {
+      userId: targetId,
+      actorId,
+      data: { muted, mutedByHost: state.mutedByHost },
+    });
+    this.broadcast(session, 'call:mute-changed', {
+      callId,
+      userIds: [targetId],
+      muted,
+      ...(state.mutedByHost && { mutedBy: actorId }),
+    });
+    return session;
+  }
+
+  // Mute everyone except the host
+  async muteAll(callId: string, hostId: string): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+
+    const userIds = session.participants.filter(id => id !== hostId);
+    userIds.forEach(id => {
+      const state = session.participantStates.get(id)!;
+      state.muted = true;
+      state.mutedByHost = true;
+      callEventLog.record(callId, 'media_changed', {
+        userId: id,
+        actorId: hostId,
+        data: { muted: true, mutedByHost: true },
+      });
+    });
+
+    this.broadcast(session, 'call:mute-changed', { callId, userIds, muted: true, mutedBy: hostId });
+    return session;
+  }
+
+  // Remove a participant from the call
+  async removeParticipant(callId: string, hostId: string, targetId: string): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+    this.requireParticipant(session, targetId);
+
+    if (targetId === hostId) {
+      throw new CallControlError('Transfer the host role before leaving', 400);
+    }
+
+    // Tell them before they drop out of the participant list
+    this.broadcast(session, 'call:participant-removed', { callId, userId: targetId, removedBy: hostId });
+
+    session.participants = session.participants.filter(id => id !== targetId);
+    session.participantStates.delete(targetId);
+    session.removed.add(targetId);
+    if (session.speakerQueue.some(entry => entry.userId === targetId)) {
+      session.speakerQueue = session.speakerQueue.filter(entry => entry.userId !== targetId);
+      this.sendSpeakerQueue(session);
+    }
+    await this.callRepository.removeParticipant(callId, targetId);
+    await activeCallService.left(callId, targetId);
+    callEventLog.record(callId, 'participant_left', { userId: targetId, actorId: hostId, data: { removed: true } });
+
+    socketManager.getIO()?.in(`user:${targetId}`).socketsLeave(`call:${callId}`);
+    await callRingingService.cancel(callId, 'ended', targetId);
+    this.rotateKeys(session, 'left');
+    return session;
+  }
+
+  // Lock or unlock the call to new joins
+  async setLocked(callId: string, hostId: string, locked: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+
+    session.locked = locked;
+    await this.callRepository.updateControls(callId, { locked });
+    callEventLog.record(callId, 'controls_changed', { actorId: hostId, data: { locked } });
+
+    this.broadcast(session, 'call:lock-changed', { callId, locked, changedBy: hostId });
+    return session;
+  }
+
+  // Make the call listen-only, muting everyone but the host and the
+  // participants who have the floor, or open it up again
+  async setListenOnly(callId: string, hostId: string, listenOnly: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+
+    session.listenOnly = listenOnly;
+    await this.callRepository.updateControls(callId, { listenOnly });
+    callEventLog.record(callId, 'controls_changed', { actorId: hostId, data: { listenOnly } });
+
+    this.broadcast(session, 'call:listen-only-changed', { callId, listenOnly, changedBy: hostId });
+    if (listenOnly) {
+      this.muteListeners(session, session.participants, hostId);
+    }
+    return session;
+  }
+
+  // Give a participant the floor, lowering their hand, or take it back.
+  // Taking it back in a listen-only call mutes them.
+  async setSpeaker(callId: string, hostId: string, targetId: string, canSpeak: boolean): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+    const state = this.requireParticipant(session, targetId);
+
+    if (targetId === hostId) {
+      throw new CallControlError('The host can always speak', 400);
+    }
+
+    state.canSpeak = canSpeak;
+    callEventLog.record(callId, 'media_changed', { userId: targetId, actorId: hostId, data: { canSpeak } });
+    this.broadcast(session, 'call:speaker-changed', { callId, userId: targetId, canSpeak, changedBy: hostId });
+
+    if (canSpeak && state.handRaised) {
+      this.changeHand(session, targetId, false, hostId);
+    }
+    if (!canSpeak && session.listenOnly) {
+      this.muteListeners(session, [targetId], hostId);
+    }
+    return session;
+  }
+
+  // Raised hands in the order they went up (host only)
+  async getSpeakerQueue(callId: string, hostId: string): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    this.requireHost(session, hostId);
+    return session;
+  }
+
+  // Hand the host role to another participant
+  async transferHost(callId: string, hostId: string, targetId: string): Promise<CallSession> {
+    const session = await this.requireSession(callId);
+    const hostState = this.requireHost(session, hostId);
+    const targetState = this.requireParticipant(session, targetId);
+
+    if (targetId === hostId) {
+      return session;
+    }
+
+    // The old host keeps the floor in a listen-only call
+    hostState.role = 'participant';
+    hostState.canSpeak = true;
+    targetState.role = 'host';
+    await this.callRepository.updateControls(callId, { host: new Types.ObjectId(targetId) });
+    callEventLog.record(callId, 'controls_changed', { userId: targetId, actorId: hostId, data: { hostId: targetId } });
+
+    this.broadcast(session, 'call:host-changed', { callId, hostId: targetId, previousHostId: hostId });
+    if (targetState.handRaised) {
+      this.changeHand(session, targetId, false, targetId);
+    } else {
+      this.sendSpeakerQueue(session);
+    }
+    return session;
+  }
+
+  getHostId(session: CallSession): string | undefined {
+    return Array.from(session.participantStates.entries()).find(([, state]) => state.role === 'host')?.[0];
+  }
+
+  // Relay a sender's wrapped media key to the recipients it was wrapped for.
+  // Packets for an old epoch are refused so a departed member's key can't
+  // be handed out again.
+  async relayKey(callId: string, senderId: string, packet: CallKeyPacketInput): Promise<void> {
+    const session = await this.requireSession(callId);
+    this.requireParticipant(session, senderId);
+
+    if (!session.encrypted) {
+      throw new CallControlError('Call is not end-to-end encrypted', 400);
+    }
+    if (packet.epoch !== session.keyEpoch) {
+      throw new CallControlError(`Stale key epoch, current is ${session.keyEpoch}`, 409);
+    }
+
+    packet.recipients
+      .filter(recipient => recipient.userId !== senderId && session.participants.includes(recipient.userId))
+      .forEach(recipient => {
+        socketManager.emitToUser(recipient.userId, 'call:e2ee:key', {
+          callId,
+          senderId,
+          epoch: packet.epoch,
+          keyId: packet.keyId,
+          cipherSuite: packet.cipherSuite,
+          wrappedKey: recipient.wrappedKey,
+          deviceId: recipient.deviceId,
+        });
+      });
+  }
+
+  // Session for a live call. Calls started by the socket handlers, and any
+  // call after a restart, have no session yet, so one is built from the call
+  // record and the state only sessions hold is replayed from its event log.
+  async loadSession(callId: string): Promise<CallSession | null> {
+    const existing = this.activeCalls.get(callId);
+    if (existing) {
+      return existing;
+    }
+
+    const call = await this.callRepository.findByCallId(callId);
+    if (!call || !['initiated', 'ringing', 'answered'].includes(call.status)) {
+      return null;
+    }
+
+    const participants = call.participants.map((p: any) => (p._id || p).toString());
+    const initiator = ((call.initiator as any)._id || call.initiator).toString();
+    const hostId = call.host ? call.host.toString() : initiator;
+    const replayed = await callEventLog.replay(callId);
+    const session: CallSession = {
+      callId,
+      initiator,
+      participants,
+      type: call.type,
+      status: call.status === 'answered' ? 'connected' : 'ringing',
+      startTime: call.startTime,
+      signaling: {
+        offers: new Map(),
+        answers: new Map(),
+        iceCandidates: new Map(),
+      },
+      participantStates: new Map(participants.map(id => [
+        id,
+        { ...createParticipantState(id === hostId ? 'host' : 'participant'), ...replayed.participantStates.get(id) },
+      ] as [string, ParticipantState])),
+      locked: call.locked ?? false,
+      removed: new Set((call.removedParticipants || []).map(id => id.toString())),
+      listenOnly: call.listenOnly ?? false,
+      speakerQueue: replayed.speakerQueue.filter(entry => participants.includes(entry.userId)),
+      encrypted: call.encrypted ?? false,
+      keyEpoch: replayed.keyEpoch,
+    };
+
+    this.activeCalls.set(callId, session);
+    return session;
+  }
+
+  // Drop the in-memory session of a call that ended outside this service
+  discardSession(callId: string): void {
+    this.activeCalls.delete(callId);
+  }
+
+  private async requireSession(callId: string): Promise<CallSession> {
+    const session = await this.loadSession(callId);
+    if (!session || session.status === 'ended') {
+      throw new CallControlError('Call not found', 404);
+    }
+    return session;
+  }
+
+  private requireParticipant(session: CallSession, userId: string): ParticipantState {
+    const state = session.participantStates.get(userId);
+    if (!state) {
+      throw new CallControlError('Not a participant in this call', 403);
+    }
+    return state;
+  }
+
+  private requireHost(session: CallSession, userId: string): ParticipantState {
+    const state = this.requireParticipant(session, userId);
+    if (state.role !== 'host') {
+      throw new CallControlError('Only the call host can do this', 403);
+    }
+    return state;
+  }
+
+  // Membership changed: start a new key epoch and have everyone rekey
+  private rotateKeys(session: CallSession, reason: 'joined' | 'left'): void {
+    if (!session.encrypted) return;
+
+    session.keyEpoch++;
+    callEventLog.record(session.callId, 'keys_rotated', { data: { epoch: session.keyEpoch, reason } });
+    this.broadcast(session, 'call:e2ee:rekey', {
+      callId: session.callId,
+      epoch: session.keyEpoch,
+      reason,
+      participants: session.participants,
+    });
+  }
+
+  private changeHand(session: CallSession, userId: string, raised: boolean, actorId: string): void {
+    session.participantStates.get(userId)!.handRaised = raised;
+    session.speakerQueue = session.speakerQueue.filter(entry => entry.userId !== userId);
+    if (raised) {
+      session.speakerQueue.push({ userId, raisedAt: new Date() });
+    }
+
+    callEventLog.record(session.callId, 'media_changed', { userId, actorId, data: { handRaised: raised } });
+    this.broadcast(session, 'call:hand-changed', {
+      callId: session.callId,
+      userId,
+      raised,
+      ...(actorId !== userId && { changedBy: actorId }),
+    });
+    this.sendSpeakerQueue(session);
+  }
+
+  // Mute whoever of these can't speak in a listen-only call
+  private muteListeners(session: CallSession, userIds: string[], hostId: string): void {
+    const muted = userIds.filter(id => {
+      const state = session.participantStates.get(id);
+      if (!state || state.role === 'host' || state.canSpeak || state.mutedByHost) return false;
+
+      state.muted = true;
+      state.mutedByHost = true;
+      callEventLog.record(session.callId, 'media_changed', {
+        userId: id,
+        actorId: hostId,
+        data: { muted: true, mutedByHost: true },
+      });
+      return true;
+    });
+
+    if (muted.length > 0) {
+      this.broadcast(session, 'call:mute-changed', { callId: session.callId, userIds: muted, muted: true, mutedBy: hostId });
+    }
+  }
+
+  // Only the host sees the queue
+  private sendSpeakerQueue(session: CallSession): void {
+    const hostId = this.getHostId(session);
+    if (hostId) {
+      socketManager.emitToUser(hostId, 'call:speaker-queue', serializeSpeakerQueue(session));
+    }
+  }
+
+  private broadcast(session: CallSession, event: ServerEventName, data: any): void {
+    session.participants.forEach(participantId => {
+      socketManager.emitToUser(participantId, event, data);
+    });
+  }
+
   // Get active call session
   getCallSession(callId: string): CallSession | undefined {
     return this.activeCalls.get(callId);
@@ -253,4 +792,46 @@ export class WebRTCSignalingService {
   }
 }
 
+// Client-facing view of a call's host controls
+export function serializeCallControls(session: CallSession) {
+  return {
+    callId: session.callId,
+    hostId: webrtcSignalingService.getHostId(session),
+    locked: session.locked,
+    listenOnly: session.listenOnly,
+    encryption: describeCallEncryption(session.encrypted, session.keyEpoch),
+    participants: session.participants.map(userId => {
+      const state = session.participantStates.get(userId)!;
+      return {
+        userId,
+        role: state.role,
+        muted: state.muted,
+        mutedByHost: state.mutedByHost,
+        onHold: state.onHold,
+        handRaised: state.handRaised,
+        canSpeak: state.role === 'host' || state.canSpeak,
+      };
+    }),
+  };
+}
+
+// The host's view of raised hands, first in line first
+export function serializeSpeakerQueue(session: CallSession) {
+  return {
+    callId: session.callId,
+    queue: session.speakerQueue.map((entry, index) => ({
+      userId: entry.userId,
+      position: index + 1,
+      raisedAt: entry.raisedAt.toISOString(),
+    })),
+  };
+}
+
+export class CallControlError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'CallControlError';
+  }
+}
+
 export const webrtcSignalingService = new WebRTCSignalingService();
@@ -0,0 +1,414 @@
+import { Types } from 'mongoose';
+import { VoiceChannelRepository } from '../database/repositories/voice-channel';
+import { ChatRepository } from '../database/repositories/chat';
+import { IVoiceChannel, IVoiceChannelParticipant } from '../database/models/voice-channel';
+import { IChat } from '../database/models/chat';
+import {
+  CreateVoiceChannelInput,
+  UpdateVoiceChannelInput,
+  VoiceChannelSpeakerInput,
+} from '../database/schemas/group';
+import { permissionService } from '../security/permissions';
+import { moderationService } from '../moderation/actions';
+import { guestAccessService } from '../auth/guest-access';
+import { socketManager } from '../realtime/socket';
+import { emitEvent } from '../realtime/protocol';
+import { CALL_CONSTANTS } from '../utils/constants';
+import { logger } from '../monitoring/logging';
+import { metricsCollector } from '../monitoring/metrics';
+
+const SWEEP_INTERVAL = 60 * 1000;
+const BATCH_SIZE = 100;
+
+export type VoiceChannelLeaveReason = 'left' | 'moved' | 'removed' | 'disconnected';
+
+// The connection someone joins a channel from
+export interface VoiceChannelConnection {
+  userId: string;
+  socketId: string;
+  deviceId?: string;
+}
+
+export function voiceChannelRoom(channelId: string): string {
+  return `voice:${channelId}`;
+}
+
+// Persistent voice channels of groups: always-on audio rooms members drop
+// in and out of without ringing anyone. They have nothing to do with calls:
+// no call record, no ringing, and a channel lives on, empty, until an admin
+// removes it. Audio runs peer to peer over the same TURN servers, bitrate
+// caps and socket relay as calls. Who is in a channel is kept on the
+// channel document, so every member sees it wherever they're connected;
+// entries are removed when the socket that joined disconnects, and a sweep
+// catches the ones left behind by a server that went away.
+export class VoiceChannelService {
+  private voiceChannelRepository = new VoiceChannelRepository();
+  private chatRepository = new ChatRepository();
+  private timer: NodeJS.Timeout | null = null;
+  private ticking = false;
+
+  // Start sweeping stale presence. Safe to run on several instances.
+  start(): void {
+    if (this.timer) return;
+
+    this.timer = setInterval(() => this.tick(), SWEEP_INTERVAL);
+    this.timer.unref();
+  }
+
+  stop(): void {
+    if (this.timer) {
+      clearInterval(this.timer);
+      this.timer = null;
+    }
+  }
+
+  // A group's channels with who is in them; members only
+  async list(groupId: string, userId: string): Promise<{ group: IChat; channels: IVoiceChannel[] }> {
+    const group = await this.requireMember(groupId, userId);
+    return { group, channels: await this.voiceChannelRepository.findByChat(group._id) };
+  }
+
+  // Create a channel; group admins only
+  async create(groupId: string, userId: string, input: CreateVoiceChannelInput): Promise<{ group: IChat; channel: IVoiceChannel }> {
+    const group = await this.requireAdmin(groupId, userId);
+
+    if (await this.voiceChannelRepository.countByChat(group._id) >= CALL_CONSTANTS.MAX_VOICE_CHANNELS) {
+      throw new VoiceChannelError(`Groups can have at most ${CALL_CONSTANTS.MAX_VOICE_CHANNELS} voice channels`, 409);
+    }
+
+    const channel = await this.voiceChannelRepository.create({
+      chatId: group._id,
+      name: input.name,
+      topic: input.topic,
+      createdBy: new Types.ObjectId(userId),
+      pushToTalk: input.pushToTalk,
+      speakerMode: input.speakerMode,
+    });
+
+    metricsCollector.incrementCounter('voice_channels_created', 1);
+    logger.info('Voice channel created', { groupId, channelId: channel._id.toString(), userId });
+
+    this.broadcast(group, channel);
+    return { group, channel };
+  }
+
+  // Change a channel's settings; group admins only. Switching to push-to-talk
+  // or to speakers only applies to the people already in it.
+  async update(groupId: string, channelId: string, userId: string, input: UpdateVoiceChannelInput): Promise<{ group: IChat; channel: IVoiceChannel }> {
+    const group = await this.requireAdmin(groupId, userId);
+    await this.requireChannel(group, channelId);
+
+    let channel = await this.voiceChannelRepository.update(channelId, input);
+    if (!channel) {
+      throw new VoiceChannelError('Voice channel not found', 404);
+    }
+
+    for (const participant of channel.participants) {
+      const state: Partial<IVoiceChannelParticipant> = {};
+      if (channel.pushToTalk && !participant.pushToTalk) state.pushToTalk = true;
+      if (!participant.muted && !canSpeak(group, channel, participant.userId.toString())) state.muted = true;
+      if (Object.keys(state).length > 0) {
+        channel = await this.voiceChannelRepository.updateParticipant(channel._id, participant.userId, state) || channel;
+      }
+    }
+
+    this.broadcast(group, channel);
+    return { group, channel };
+  }
+
+  // Remove a channel, disconnecting whoever is in it; group admins only
+  async remove(groupId: string, channelId: string, userId: string): Promise<void> {
+    const group = await this.requireAdmin(groupId, userId);
+    await this.requireChannel(group, channelId);
+
+    const removed = await this.voiceChannelRepository.remove(channelId);
+    if (!removed) {
+      throw new VoiceChannelError('Voice channel not found', 404);
+    }
+
+    removed.participants.forEach(participant => {
+      socketManager.emitToUser(participant.userId.toString(), 'voice:left', { channelId, reason: 'removed' });
+    });
+    socketManager.getIO()?.in(voiceChannelRoom(channelId)).socketsLeave(voiceChannelRoom(channelId));
+
+    this.emitToMembers(group, 'voice:channel:removed', { groupId: group._id.toString(), channelId });
+    logger.info('Voice channel removed', { groupId, channelId, userId, participants: removed.participants.length });
+  }
+
+  // Join from a connection. Being in another channel, on any device, means
+  // leaving it: you are in one channel at a time. Restricted users and
+  // guests without calls can't join, and in 'speakers' mode the others come
+  // in muted.
+  async join(channelId: string, connection: VoiceChannelConnection): Promise<{ group: IChat; channel: IVoiceChannel }> {
+    const { userId } = connection;
+    const channel = Types.ObjectId.isValid(channelId) ? await this.voiceChannelRepository.findActiveById(channelId) : null;
+    if (!channel) {
+      throw new VoiceChannelError('Voice channel not found', 404);
+    }
+    const group = await this.requireMember(channel.chatId.toString(), userId);
+
+    if (await moderationService.getRestriction(userId)) {
+      throw new VoiceChannelError('Voice channels are not available for your account', 403);
+    }
+    if (!(await guestAccessService.allows(userId, 'calls'))) {
+      throw new VoiceChannelError('Guests can\'t join voice channels in this group', 403);
+    }
+
+    if (channel.participants.some(p => p.userId.toString() === userId && p.socketId === connection.socketId)) {
+      return { group, channel };
+    }
+    for (const current of await this.voiceChannelRepository.findByParticipant(userId)) {
+      await this.leave(current._id.toString(), userId, undefined, 'moved');
+    }
+
+    const joined = await this.voiceChannelRepository.addParticipant(channel._id, {
+      userId: new Types.ObjectId(userId),
+      socketId: connection.socketId,
+      deviceId: connection.deviceId,
+      joinedAt: new Date(),
+      muted: !canSpeak(group, channel, userId),
+      mutedByAdmin: false,
+      pushToTalk: channel.pushToTalk,
+    }, CALL_CONSTANTS.MAX_VOICE_CHANNEL_PARTICIPANTS);
+    if (!joined) {
+      throw new VoiceChannelError('Voice channel is full', 409);
+    }
+
+    metricsCollector.incrementCounter('voice_channel_joins', 1);
+    this.broadcast(group, joined);
+    return { group, channel: joined };
+  }
+
+  // Leave a channel, from one connection or from wherever you joined. The
+  // connection that joined is taken out of the channel's room and told why.
+  async leave(
+    channelId: string,
+    userId: string,
+    socketId?: string,
+    reason: VoiceChannelLeaveReason = 'left'
+  ): Promise<IVoiceChannel | null> {
+    const before = Types.ObjectId.isValid(channelId) ? await this.voiceChannelRepository.findActiveById(channelId) : null;
+    const participant = before?.participants.find(p =>
+      p.userId.toString() === userId && (!socketId || p.socketId === socketId)
+    );
+    if (!before || !participant) {
+      return null;
+    }
+
+    const channel = await this.voiceChannelRepository.removeParticipant(channelId, userId, participant.socketId);
+    if (!channel) {
+      return null;
+    }
+
+    const io = socketManager.getIO();
+    if (io) {
+      io.in(participant.socketId).socketsLeave(voiceChannelRoom(channelId));
+      if (reason !== 'disconnected') {
+        emitEvent(io.to(participant.socketId), 'voice:left', { channelId, reason });
+      }
+    }
+
+    const group = await this.chatRepository.findById(channel.chatId);
+    if (group) {
+      this.broadcast(group, channel);
+    }
+    return channel;
+  }
+
+  // A socket went away: take it out of whatever channel it was in
+  async disconnected(socketId: string): Promise<void> {
+    for (const channel of await this.voiceChannelRepository.findBySocket(socketId)) {
+      const participant = channel.participants.find(p => p.socketId === socketId);
+      if (participant) {
+        await this.leave(channel._id.toString(), participant.userId.toString(), socketId, 'disconnected');
+      }
+    }
+  }
+
+  // Mute yourself or switch push-to-talk. You can't unmute when an admin
+  // muted you or the channel is speakers-only and you aren't one, and
+  // push-to-talk stays on in push-to-talk channels.
+  async setState(
+    channelId: string,
+    userId: string,
+    state: { muted?: boolean; pushToTalk?: boolean }
+  ): Promise<IVoiceChannel> {
+    const channel = Types.ObjectId.isValid(channelId) ? await this.voiceChannelRepository.findActiveById(channelId) : null;
+    const participant = channel?.participants.find(p => p.userId.toString() === userId);
+    if (!channel || !participant) {
+      throw new VoiceChannelError('You are not in this voice channel', 404);
+    }
+    const group = await this.requireMember(channel.chatId.toString(), userId);
+
+    if (state.muted === false) {
+      if (participant.mutedByAdmin) {
+        throw new VoiceChannelError('An admin muted you in this channel', 403);
+      }
+      if (!canSpeak(group, channel, userId)) {
+        throw new VoiceChannelError('Only speakers can talk in this channel', 403);
+      }
+    }
+    if (state.pushToTalk === false && channel.pushToTalk) {
+      throw new VoiceChannelError('This channel is push-to-talk', 403);
+    }
+
+    const updated = await this.voiceChannelRepository.updateParticipant(channel._id, userId, state);
+    if (!updated) {
+      throw new VoiceChannelError('You are not in this voice channel', 404);
+    }
+
+    this.broadcast(group, updated);
+    return updated;
+  }
+
+  // Speaker management; group admins only. canSpeak grants or takes away
+  // the right to talk in 'speakers' mode, muted mutes someone so only an
+  // admin can unmute them again.
+  async moderate(groupId: string, channelId: string, adminId: string, input: VoiceChannelSpeakerInput): Promise<{ group: IChat; channel: IVoiceChannel }> {
+    const group = await this.requireAdmin(groupId, adminId);
+    let channel = await this.requireChannel(group, channelId);
+
+    if (input.canSpeak !== undefined) {
+      channel = await this.voiceChannelRepository.setSpeaker(channel._id, input.userId, input.canSpeak) || channel;
+    }
+
+    const participant = channel.participants.find(p => p.userId.toString() === input.userId);
+    if (participant) {
+      const state: Partial<IVoiceChannelParticipant> = {};
+      if (input.muted !== undefined) {
+        state.muted = input.muted;
+        state.mutedByAdmin = input.muted;
+      }
+      if (!canSpeak(group, channel, input.userId)) {
+        state.muted = true;
+      }
+      if (Object.keys(state).length > 0) {
+        channel = await this.voiceChannelRepository.updateParticipant(channel._id, input.userId, state) || channel;
+      }
+    }
+
+    logger.info('Voice channel speaker changed', { groupId, channelId, adminId, ...input });
+    this.broadcast(group, channel);
+    return { group, channel };
+  }
+
+  private async requireMember(groupId: string, userId: string): Promise<IChat> {
+    const group = Types.ObjectId.isValid(groupId) ? await this.chatRepository.findById(groupId) : null;
+    if (!group || group.type !== 'group' || !getMemberIds(group).includes(userId)) {
+      throw new VoiceChannelError('Group not found', 404);
+    }
+    return group;
+  }
+
+  private async requireAdmin(groupId: string, userId: string): Promise<IChat> {
+    const group = await this.requireMember(groupId, userId);
+    if (!(await permissionService.canManageGroup(userId, groupId, 'manage_voice_channels'))) {
+      throw new VoiceChannelError('Only group admins can manage voice channels', 403);
+    }
+    return group;
+  }
+
+  private async requireChannel(group: IChat, channelId: string): Promise<IVoiceChannel> {
+    const channel = Types.ObjectId.isValid(channelId) ? await this.voiceChannelRepository.findActiveById(channelId) : null;
+    if (!channel || !channel.chatId.equals(group._id)) {
+      throw new VoiceChannelError('Voice channel not found', 404);
+    }
+    return channel;
+  }
+
+  // Every member, not just those in the channel, so the group can show
+  // who's talking where
+  private broadcast(group: IChat, channel: IVoiceChannel): void {
+    this.emitToMembers(group, 'voice:channel:updated', {
+      groupId: group._id.toString(),
+      channel: serializeVoiceChannel(channel, group),
+    });
+  }
+
+  private emitToMembers(group: IChat, event: 'voice:channel:updated' | 'voice:channel:removed', payload: unknown): void {
+    getMemberIds(group).forEach(memberId => {
+      socketManager.emitToUser(memberId, event, payload);
+    });
+  }
+
+  // Drop participants whose connection is gone, e.g. when the server they
+  // were on stopped, or who are no longer in the group
+  private async tick(): Promise<void> {
+    const io = socketManager.getIO();
+    if (this.ticking || !io) return;
+    this.ticking = true;
+
+    try {
+      let afterId: Types.ObjectId | undefined;
+      for (;;) {
+        const channels = await this.voiceChannelRepository.findOccupied(BATCH_SIZE, afterId);
+        for (const channel of channels) {
+          const channelId = channel._id.toString();
+          const sockets = await io.in(voiceChannelRoom(channelId)).fetchSockets();
+          const live = new Set(sockets.map(socket => socket.id));
+          const members = new Set(await this.chatRepository.getParticipantIds(channel.chatId));
+
+          for (const participant of channel.participants) {
+            const userId = participant.userId.toString();
+            if (!live.has(participant.socketId)) {
+              await this.leave(channelId, userId, participant.socketId, 'disconnected');
+            } else if (!members.has(userId)) {
+              await this.leave(channelId, userId, participant.socketId, 'removed');
+            }
+          }
+        }
+
+        if (channels.length < BATCH_SIZE) break;
+        afterId = channels[channels.length - 1]._id;
+      }
+    } catch (error) {
+      logger.error('Voice channel sweep error', error);
+    } finally {
+      this.ticking = false;
+    }
+  }
+}
+
+function getMemberIds(group: IChat): string[] {
+  return group.participants.map((participant: any) => (participant._id || participant).toString());
+}
+
+// Admins can always talk; in 'speakers' mode only they and the speakers can
+export function canSpeak(group: IChat, channel: IVoiceChannel, userId: string): boolean {
+  if (channel.speakerMode === 'open') return true;
+  if (group.groupInfo?.admins.some(adminId => adminId.toString() === userId)) return true;
+  return channel.speakers.some(speakerId => speakerId.toString() === userId);
+}
+
+// Client-facing view of a channel with who is in it
+export function serializeVoiceChannel(channel: IVoiceChannel, group: IChat) {
+  return {
+    id: channel._id.toString(),
+    groupId: channel.chatId.toString(),
+    name: channel.name,
+    topic: channel.topic,
+    pushToTalk: channel.pushToTalk,
+    speakerMode: channel.speakerMode,
+    speakers: channel.speakers.map(id => id.toString()),
+    participants: channel.participants.map(participant => ({
+      userId: participant.userId.toString(),
+      joinedAt: participant.joinedAt.toISOString(),
+      muted: participant.muted,
+      mutedByAdmin: participant.mutedByAdmin,
+      pushToTalk: participant.pushToTalk,
+      canSpeak: canSpeak(group, channel, participant.userId.toString()),
+    })),
+    participantCount: channel.participants.length,
+    createdBy: channel.createdBy.toString(),
+    createdAt: channel.createdAt.toISOString(),
+  };
+}
+
+export class VoiceChannelError extends Error {
+  constructor(message: string, public status: number) {
+    super(message);
+    this.name = 'VoiceChannelError';
+  }
+}
+
+export const voiceChannelService = new VoiceChannelService();
@@ -0,0 +1,143 @@
+// Background work a process runs, split by whether it needs the web server.
+// PROCESS_ROLE picks what each process does:
+//   all     one process does everything (the default)
+//   api     serves requests and sockets; background jobs are left to workers
+//   worker  scripts/worker.ts, runs only the background jobs below
+// Socket.IO emits only reach sockets connected to the same process; worker
+// processes send theirs to the web servers through the relay (see
+// realtime/relay). Work that keeps live connections or in-memory state of
+// its own stays with the server whatever the role.
+
+export type ProcessRole = 'all' | 'api' | 'worker';
+
+export function getProcessRole(): ProcessRole {
+  const role = process.env.PROCESS_ROLE;
+  return role === 'api' || role === 'worker' ? role : 'all';
+}
+
+// Jobs that only touch the database, storage and push providers, or notify
+// users through socket emits, and can run in a separate worker process
+export async function startBackgroundWorkers(): Promise<void> {
+  // Run queued background jobs (pushes, thumbnails, chat imports, bulk
+  // moderation), including any cut short by the previous shutdown
+  const { jobQueue } = await import('./jobs');
+  jobQueue.start();
+
+  // Finish moving users and chats between data regions
+  const { dataResidencyService } = await import('./compliance/data-residency');
+  await dataResidencyService.resumePending();
+
+  // Recount references to shared stored files and remove unreferenced ones
+  const { storageReferenceService } = await import('./media/storage-references');
+  storageReferenceService.start();
+
+  // Keep retention cohorts up to date
+  const { retentionService } = await import('./monitoring/retention');
+  retentionService.start();
+
+  // Delete messages older than each account's retention period
+  const { messageRetentionService } = await import('./compliance/message-retention');
+  messageRetentionService.start();
+
+  // Pack old history of very busy chats into message buckets
+  const { messageBucketService } = await import('./database/message-buckets');
+  messageBucketService.start();
+
+  // Keep group admins' engagement analytics up to date
+  const { groupAnalyticsService } = await import('./monitoring/group-analytics');
+  groupAnalyticsService.start();
+
+  // Deliver due reminders, including any that came due while we were down
+  const { reminderService } = await import('./reminders');
+  reminderService.start();
+
+  // Remind invitees of scheduled calls and start them on time
+  const { scheduledCallService } = await import('./webrtc/scheduled-calls');
+  scheduledCallService.start();
+
+  // Tell users when new versions of legal notices come into force
+  const { legalNoticeService } = await import('./compliance/legal-notices');
+  legalNoticeService.start();
+
+  // Send pushes held back by Do Not Disturb once quiet hours end
+  const { pushNotificationService } = await import('./communication/push-notifications');
+  pushNotificationService.startDigestWorker();
+
+  // Publish message, chat and call changes to external data pipelines
+  if (process.env.CDC_PUBLISHER && process.env.CDC_PUBLISHER !== 'none') {
+    const { changeDataCaptureService } = await import('./pipelines/cdc');
+    changeDataCaptureService.start();
+  }
+}
+
+export async function stopBackgroundWorkers(): Promise<void> {
+  const [
+    { jobQueue },
+    { storageReferenceService },
+    { retentionService },
+    { messageRetentionService },
+    { messageBucketService },
+    { groupAnalyticsService },
+    { reminderService },
+    { scheduledCallService },
+    { legalNoticeService },
+  ] = await Promise.all([
+    import('./jobs'),
+    import('./media/storage-references'),
+    import('./monitoring/retention'),
+    import('./compliance/message-retention'),
+    import('./database/message-buckets'),
+    import('./monitoring/group-analytics'),
+    import('./reminders'),
+    import('./webrtc/scheduled-calls'),
+    import('./compliance/legal-notices'),
+  ]);
+  jobQueue.stop();
+  storageReferenceService.stop();
+  retentionService.stop();
+  messageRetentionService.stop();
+  messageBucketService.stop();
+  groupAnalyticsService.stop();
+  reminderService.stop();
+  scheduledCallService.stop();
+  legalNoticeService.stop();
+
+  if (process.env.CDC_PUBLISHER && process.env.CDC_PUBLISHER !== 'none') {
+    const { changeDataCaptureService } = await import('./pipelines/cdc');
+    await changeDataCaptureService.stop();
+  }
+}
+
+// Work that emits to connected sockets or answers bridge HTTP callbacks,
+// and so runs in the web server
+export async function startServerWorkers(): Promise<void> {
+  // Long-lived bridge connections (XMPP component, Matrix room joins)
+  if (process.env.FEDERATION_ENABLED === 'true') {
+    const { federationService } = await import('./federation');
+    await federationService.start();
+  }
+
+  // Clear voice channel presence left behind by servers that went away
+  const { voiceChannelService } = await import('./webrtc/voice-channels');
+  voiceChannelService.start();
+
+  // End broadcasts whose call is over
+  const { liveBroadcastService } = await import('./webrtc/broadcasts');
+  liveBroadcastService.start();
+
+  // End or resume calls left open by the previous shutdown
+  const { callRecoveryService } = await import('./webrtc/call-recovery');
+  await callRecoveryService.reconcile();
+
+  // Lift bans and restrictions as they expire
+  const { moderationService } = await import('./moderation/actions');
+  moderationService.start();
+
+  // Take guests out of their group once their access ends
+  const { guestAccessService } = await import('./auth/guest-access');
+  guestAccessService.start();
+
+  // Record daily user activity from analytics events sent to this server
+  const { activityTracker } = await import('./monitoring/activity');
+  activityTracker.start();
+}
@@ -1,11 +1,72 @@
 import { NextRequest, NextResponse } from 'next/server';
 import { edgeLogger } from './lib/monitoring/edge-logger'; // Use edge logger
-import { environmentConfig } from './lib/config/environment';
+import { CorsPolicy, defaultCorsPolicy, buildCorsHeaders } from './lib/config/cors-policy';
+import { DEFAULT_MAX_REQUEST_SIZE, getRouteBodyLimit, payloadTooLargeBody } from './lib/config/body-limits';
+import {
+  ClientVersionPolicy,
+  isUpgradeRequired,
+  isVersionExempt,
+  readClientInfo,
+  upgradeRequiredBody,
+} from './lib/config/client-versions';
 
-export function middleware(request: NextRequest) {
+const CORS_POLICY_PATH = '/api/config/cors';
+const CORS_POLICY_TTL = 30 * 1000; // 30 seconds
+const CLIENT_VERSION_POLICY_PATH = '/api/config/client-versions';
+const CLIENT_VERSION_POLICY_TTL = 30 * 1000;
+const BODY_LIMIT_PATH = '/api/config/body-limits';
+const BODY_LIMIT_TTL = 30 * 1000;
+
+let cachedPolicy: { policy: CorsPolicy; fetchedAt: number } | null = null;
+let cachedVersionPolicy: { policy: ClientVersionPolicy; fetchedAt: number } | null = null;
+let cachedMaxRequestSize: { value: number; fetchedAt: number } | null = null;
+
+export async function middleware(request: NextRequest) {
   const startTime = Date.now();
   const requestId = request.headers.get('x-request-id') || crypto.randomUUID();
   
+  const isApiRoute = request.nextUrl.pathname.startsWith('/api/');
+  const corsHeaders = isApiRoute && request.nextUrl.pathname !== CORS_POLICY_PATH
+    ? buildCorsHeaders(await getCorsPolicy(request), request.headers.get('origin'))
+    : null;
+
+  // Answer preflight requests directly
+  if (corsHeaders && request.method === 'OPTIONS') {
+    const preflight = new NextResponse(null, { status: 204, headers: corsHeaders });
+    preflight.headers.set('X-Request-ID', requestId);
+    return preflight;
+  }
+
+  // Reject oversized bodies before they reach route handlers. Bodies sent
+  // without a Content-Length are capped by the routes as they read them.
+  const contentLength = parseInt(request.headers.get('content-length') || '', 10);
+  if (isApiRoute && !isNaN(contentLength)) {
+    const limit = getRouteBodyLimit(request.nextUrl.pathname, await getMaxRequestSize(request));
+    if (contentLength > limit) {
+      const rejected = NextResponse.json(payloadTooLargeBody(limit, contentLength), {
+        status: 413,
+        headers: corsHeaders || undefined,
+      });
+      rejected.headers.set('X-Request-ID', requestId);
+      return rejected;
+    }
+  }
+
+  // Turn away app versions below the supported minimum. Clients that don't
+  // identify themselves are let through.
+  if (isApiRoute && !isVersionExempt(request.nextUrl.pathname)) {
+    const client = readClientInfo(request.headers);
+    const versionPolicy = client && await getClientVersionPolicy(request);
+    if (client && versionPolicy && isUpgradeRequired(versionPolicy, client)) {
+      const rejected = NextResponse.json(upgradeRequiredBody(versionPolicy, client), {
+        status: 426,
+        headers: corsHeaders || undefined,
+      });
+      rejected.headers.set('X-Request-ID', requestId);
+      return rejected;
+    }
+  }
+
   // Create response
   const response = NextResponse.next();
   
@@ -15,9 +76,11 @@ export function middleware(request: NextRequest) {
   // Add security headers
   addSecurityHeaders(response);
   
-  // Handle CORS for API routes
-  if (request.nextUrl.pathname.startsWith('/api/')) {
-    handleCORS(request, response);
+  // Add CORS headers for API routes
+  if (corsHeaders) {
+    Object.entries(corsHeaders).forEach(([key, value]) => {
+      response.headers.set(key, value);
+    });
   }
   
   // Add monitoring headers
@@ -79,48 +142,80 @@ function addSecurityHeaders(response: NextResponse) {
   });
 }
 
-function handleCORS(request: NextRequest, response: NextResponse) {
-  const origin = request.headers.get('origin');
-  const corsHeaders = getCorsHeaders(origin || undefined);
-  
-  // Add CORS headers
-  Object.entries(corsHeaders).forEach(([key, value]) => {
-    response.headers.set(key, value);
+// Get the effective CORS policy from the API, falling back to environment defaults
+async function getCorsPolicy(request: NextRequest): Promise<CorsPolicy> {
+  if (cachedPolicy && Date.now() - cachedPolicy.fetchedAt < CORS_POLICY_TTL) {
+    return cachedPolicy.policy;
+  }
+
+  try {
+    const res = await fetch(new URL(CORS_POLICY_PATH, request.nextUrl.origin), {
+      cache: 'no-store',
+    });
+    if (res.ok) {
+      cachedPolicy = { policy: await res.json(), fetchedAt: Date.now() };
+      return cachedPolicy.policy;
+    }
+  } catch {
+    edgeLogger.warn('Failed to fetch CORS policy, using defaults');
+  }
+
+  // Keep serving the last known policy if the API is unavailable
+  return cachedPolicy?.policy ?? defaultCorsPolicy({
+    NODE_ENV: process.env.NODE_ENV,
+    FRONTEND_URL: process.env.FRONTEND_URL,
+    WEBSITE_URL: process.env.WEBSITE_URL,
+    CORS_ORIGIN: process.env.CORS_ORIGIN,
+    ALLOWED_ORIGINS: process.env.ALLOWED_ORIGINS,
   });
-  
-  // Handle preflight requests
-  if (request.method === 'OPTIONS') {
-    return new NextResponse(null, {
-      status: 204,
-      headers: corsHeaders,
+}
+
+// Get the minimum client versions from the API. Enforcement is skipped
+// while the policy has never been fetched.
+async function getClientVersionPolicy(request: NextRequest): Promise<ClientVersionPolicy | null> {
+  if (cachedVersionPolicy && Date.now() - cachedVersionPolicy.fetchedAt < CLIENT_VERSION_POLICY_TTL) {
+    return cachedVersionPolicy.policy;
+  }
+
+  try {
+    const res = await fetch(new URL(CLIENT_VERSION_POLICY_PATH, request.nextUrl.origin), {
+      cache: 'no-store',
     });
+    if (res.ok) {
+      cachedVersionPolicy = { policy: await res.json(), fetchedAt: Date.now() };
+      return cachedVersionPolicy.policy;
+    }
+  } catch {
+    edgeLogger.warn('Failed to fetch client version policy');
   }
+
+  return cachedVersionPolicy?.policy ?? null;
 }
 
-function getCorsHeaders(origin?: string): Record<string, string> {
-  const headers: Record<string, string> = {};
-  
-  // Simple CORS for development
-  if (process.env.NODE_ENV === 'development') {
-    headers['Access-Control-Allow-Origin'] = '*';
-  } else if (origin) {
-    // Add your production origins here
-    const allowedOrigins = [
-      process.env.FRONTEND_URL,
-      process.env.WEBSITE_URL,
-    ].filter(Boolean);
-    
-    if (allowedOrigins.includes(origin)) {
-      headers['Access-Control-Allow-Origin'] = origin;
+// Get the server-wide body maximum from the API, where admins can change it,
+// falling back to the environment
+async function getMaxRequestSize(request: NextRequest): Promise<number> {
+  if (cachedMaxRequestSize && Date.now() - cachedMaxRequestSize.fetchedAt < BODY_LIMIT_TTL) {
+    return cachedMaxRequestSize.value;
+  }
+
+  // The poll itself passes through here
+  if (request.nextUrl.pathname !== BODY_LIMIT_PATH) {
+    try {
+      const res = await fetch(new URL(BODY_LIMIT_PATH, request.nextUrl.origin), {
+        cache: 'no-store',
+      });
+      if (res.ok) {
+        const { maxRequestSize } = await res.json();
+        cachedMaxRequestSize = { value: maxRequestSize, fetchedAt: Date.now() };
+        return cachedMaxRequestSize.value;
+      }
+    } catch {
+      edgeLogger.warn('Failed to fetch request size limit, using defaults');
     }
   }
-  
-  headers['Access-Control-Allow-Methods'] = 'GET, POST, PUT, DELETE, PATCH, OPTIONS';
-  headers['Access-Control-Allow-Headers'] = 'Origin, X-Requested-With, Content-Type, Accept, Authorization, X-Request-ID';
-  headers['Access-Control-Allow-Credentials'] = 'true';
-  headers['Access-Control-Max-Age'] = '86400';
-  
-  return headers;
+
+  return cachedMaxRequestSize?.value ?? (Number(process.env.MAX_REQUEST_SIZE) || DEFAULT_MAX_REQUEST_SIZE);
 }
 
 // Configuration for middleware